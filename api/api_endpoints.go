@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 )
 
 // Endpoints provides methods for building API endpoint URLs.
@@ -84,6 +85,12 @@ func (e *Endpoints) Profiles() string {
 	return fmt.Sprintf("%s/profiles/", e.baseURL)
 }
 
+// DataFieldValues returns the URL for the values of a profile data field.
+// The endpoint is paginated; pageSize sets how many values each page holds.
+func (e *Endpoints) DataFieldValues(name string, pageSize int) string {
+	return fmt.Sprintf("%s/profiles/datafields/%s/values/?page_size=%d", e.baseURL, url.PathEscape(name), pageSize)
+}
+
 // Location returns the URL for a specific location by ID.
 // This endpoint is used for updating a single location.
 func (e *Endpoints) Location(id int) string {
@@ -2,6 +2,8 @@ package api
 
 import (
 	"badgermaps/api/models"
+	"badgermaps/utils"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +11,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/guregu/null/v6"
@@ -21,42 +24,124 @@ const (
 type APIConfig struct {
 	BaseURL string `yaml:"api_url"`
 	APIKey  string `yaml:"api_key"`
+	// CACert trusts an additional certificate authority (e.g. a corporate proxy).
+	CACert string `yaml:"ca_cert,omitempty"`
+	// ClientCert and ClientKey enable mutual TLS when both are set.
+	ClientCert string `yaml:"client_cert,omitempty"`
+	ClientKey  string `yaml:"client_key,omitempty"`
+	// SandboxURL is a secondary API that pushes can be sent to for rehearsal.
+	SandboxURL string `yaml:"sandbox_url,omitempty"`
+	// SandboxAPIKey authenticates against SandboxURL. Empty reuses APIKey.
+	SandboxAPIKey string `yaml:"sandbox_api_key,omitempty"`
+	// SecondaryAPIKey is the next key, staged for rotation.
+	SecondaryAPIKey string `yaml:"secondary_api_key,omitempty"`
+	// Headers are sent with every request, for proxies that require a
+	// source or tracing header.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// UserAgent is appended to the User-Agent, which always names the
+	// application, its version, and the platform.
+	UserAgent string `yaml:"user_agent,omitempty"`
 }
 
 // APIClient handles BadgerMaps API interactions
 type APIClient struct {
 	BaseURL   string `mapstructure:"API_URL"`
 	APIKey    string `mapstructure:"API_KEY"`
-	UserID    int
 	client    *http.Client
 	endpoints *Endpoints
-	connected bool
+	connected atomic.Bool
+	userID    atomic.Int64
+	tlsErr    error
+	// key, once set by SetAPIKey, replaces APIKey for requests so the key
+	// can be rotated while requests are in flight.
+	key atomic.Pointer[string]
+	// compress, set by SetCompressRequests, gzips large request bodies.
+	compress atomic.Bool
+	// decoration, set by SetRequestHeaders, is the User-Agent and extra
+	// headers added to every request.
+	decoration atomic.Pointer[requestDecoration]
 }
 
-// NewAPIClient creates a new BadgerMaps API client
+// NewAPIClient creates a new BadgerMaps API client. It does not contact
+// the API; IsConnected stays false until TestAPIConnection succeeds.
 func NewAPIClient(config *APIConfig) *APIClient {
+	return NewAPIClientWithLimiter(config, nil)
+}
+
+// NewAPIClientWithLimiter creates a client whose requests are gated by the
+// shared limiter. A nil limiter leaves requests unthrottled. Like
+// NewAPIClient it does not test the connection, so building one never
+// blocks on the network.
+func NewAPIClientWithLimiter(config *APIConfig, limiter *RateLimiter) *APIClient {
 	client := &APIClient{
 		BaseURL:   config.BaseURL,
 		APIKey:    config.APIKey,
-		client:    &http.Client{Timeout: 30 * time.Second},
 		endpoints: NewEndpoints(config.BaseURL),
 	}
-
-	if err := client.TestAPIConnection(); err == nil {
-		client.connected = true
-	}
-
+	client.client, client.tlsErr = newHTTPClient(config, limiter, &client.compress, &client.decoration)
+	client.SetAPIKey(config.APIKey)
+	client.SetRequestHeaders(UserAgent(ClientVersion, config.UserAgent), config.Headers)
 	return client
 }
 
+// newHTTPClient returns the HTTP client used for API calls, wiring in any
+// custom CA or client certificate, the shared rate limiter, and request
+// compression while compress is set, and the headers in decoration. When the TLS files cannot be loaded
+// the default transport is used and the error is returned so it can be
+// reported.
+func newHTTPClient(config *APIConfig, limiter *RateLimiter, compress *atomic.Bool, decoration *atomic.Pointer[requestDecoration]) (*http.Client, error) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	var transport http.RoundTripper = http.DefaultTransport
+	tlsConfig, err := utils.LoadTLSConfig(config.CACert, config.ClientCert, config.ClientKey)
+	if err == nil && tlsConfig != nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = tlsConfig
+		transport = t
+	}
+	if compress != nil {
+		transport = &gzipTransport{base: transport, enabled: compress}
+	}
+	if decoration != nil {
+		transport = &headerTransport{base: transport, decoration: decoration}
+	}
+	if limiter != nil {
+		transport = &limitedTransport{base: transport, limiter: limiter}
+	}
+	if transport != http.DefaultTransport {
+		httpClient.Transport = transport
+	}
+	return httpClient, err
+}
+
 // IsConnected returns true if the client has successfully connected to the API
 func (api *APIClient) IsConnected() bool {
-	return api.connected
+	return api.connected.Load()
 }
 
 // SetConnected sets the connected status of the API client
 func (api *APIClient) SetConnected(connected bool) {
-	api.connected = connected
+	api.connected.Store(connected)
+}
+
+// UserID returns the id of the profile the key belongs to, as reported by
+// the last successful connection test.
+func (api *APIClient) UserID() int {
+	return int(api.userID.Load())
+}
+
+// Key returns the key requests are authenticated with.
+func (api *APIClient) Key() string {
+	if key := api.key.Load(); key != nil {
+		return *key
+	}
+	return api.APIKey
+}
+
+// SetAPIKey switches the key used by later requests. Requests already sent
+// finish with the old key.
+func (api *APIClient) SetAPIKey(key string) {
+	api.key.Store(&key)
+	api.APIKey = key
 }
 
 // encodeFormData converts a map into a URL-encoded form body.
@@ -117,12 +202,20 @@ func parseRawExtraFields(raw string) (map[string]interface{}, error) {
 }
 
 func (api *APIClient) applyAuthHeaders(req *http.Request, contentType string) {
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", api.APIKey))
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", api.Key()))
 	if contentType != "" {
 		req.Header.Set("Content-Type", contentType)
 	}
 }
 
+// applyIdempotencyKey lets an API that honors the Idempotency-Key header
+// recognize a retried request. Nothing is sent when key is empty.
+func applyIdempotencyKey(req *http.Request, key string) {
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+}
+
 func responsePreview(body []byte, max int) string {
 	if len(body) <= max {
 		return string(body)
@@ -133,7 +226,7 @@ func responsePreview(body []byte, max int) string {
 func doJSON[T any](api *APIClient, req *http.Request, expectedStatus int, decodeErrPrefix string) (*APIResponse[T], error) {
 	resp, err := api.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, classifyTransport(err)
 	}
 	defer resp.Body.Close()
 
@@ -143,7 +236,7 @@ func doJSON[T any](api *APIClient, req *http.Request, expectedStatus int, decode
 	}
 
 	if resp.StatusCode != expectedStatus {
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, responsePreview(body, 500))
+		return nil, responseError(resp.StatusCode, body, fmt.Sprintf("unexpected status %d", resp.StatusCode))
 	}
 
 	var data T
@@ -209,6 +302,38 @@ func (api *APIClient) GetAccountIDs() (*APIResponse[[]int], error) {
 	}, nil
 }
 
+// GetAccountModifiedDates retrieves the last modified date of every account,
+// keyed by account ID, using the lightweight customers list.
+func (api *APIClient) GetAccountModifiedDates() (*APIResponse[map[int]string], error) {
+	endpoint := api.endpoints.Customers()
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	api.applyAuthHeaders(req, "application/json")
+
+	rawResult, err := doJSON[[]struct {
+		ID               int         `json:"id"`
+		LastModifiedDate null.String `json:"last_modified_date"`
+	}](api, req, http.StatusOK, "failed to decode customers response")
+	if err != nil {
+		return nil, fmt.Errorf("customers request failed: %w", err)
+	}
+
+	dates := make(map[int]string, len(rawResult.Data))
+	for _, acc := range rawResult.Data {
+		dates[acc.ID] = acc.LastModifiedDate.String
+	}
+
+	return &APIResponse[map[int]string]{
+		Data:       dates,
+		Raw:        rawResult.Raw,
+		StatusCode: rawResult.StatusCode,
+		Headers:    rawResult.Headers,
+	}, nil
+}
+
 // GetCheckinIDs retrieves all checkin IDs from the BadgerMaps API
 func (api *APIClient) GetCheckinIDs() (*APIResponse[[]int], error) {
 	endpoint := api.endpoints.Appointments()
@@ -297,8 +422,50 @@ func (api *APIClient) GetUserProfile() (*APIResponse[models.UserProfile], error)
 	return result, nil
 }
 
+// DataFieldValuesPageSize is how many data field values are requested per
+// page.
+const DataFieldValuesPageSize = 500
+
+// maxDataFieldValuePages stops a server that keeps returning a next link
+// from looping forever.
+const maxDataFieldValuePages = 10000
+
+// GetDataFieldValues retrieves every value of a profile data field, one page
+// at a time, and passes each page to onPage as it arrives so callers can
+// store values incrementally. It returns the number of values received.
+func (api *APIClient) GetDataFieldValues(name string, onPage func(values []models.FieldValue) error) (int, error) {
+	endpoint := api.endpoints.DataFieldValues(name, DataFieldValuesPageSize)
+	total := 0
+	for page := 0; endpoint != ""; page++ {
+		if page >= maxDataFieldValuePages {
+			return total, fmt.Errorf("data field %s: too many pages of values", name)
+		}
+		req, err := http.NewRequest("GET", endpoint, nil)
+		if err != nil {
+			return total, fmt.Errorf("failed to create request: %w", err)
+		}
+		api.applyAuthHeaders(req, "application/json")
+
+		result, err := doJSON[models.FieldValuePage](api, req, http.StatusOK, "failed to decode data field values response")
+		if err != nil {
+			return total, fmt.Errorf("data field %s values request failed: %w", name, err)
+		}
+		if len(result.Data.Results) > 0 {
+			if err := onPage(result.Data.Results); err != nil {
+				return total, err
+			}
+			total += len(result.Data.Results)
+		}
+		endpoint = result.Data.Next.ValueOrZero()
+	}
+	return total, nil
+}
+
 // TestAPIConnection tests the API connectivity
 func (api *APIClient) TestAPIConnection() error {
+	if api.tlsErr != nil {
+		return fmt.Errorf("invalid API TLS configuration: %w", api.tlsErr)
+	}
 	// Skip health endpoint test since it doesn't exist
 	// Instead, test with a simple API call to verify connectivity
 	url := api.endpoints.Profiles()
@@ -307,12 +474,12 @@ func (api *APIClient) TestAPIConnection() error {
 		return fmt.Errorf("failed to create API test request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", api.APIKey))
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", api.Key()))
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := api.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to API: %w", err)
+		return fmt.Errorf("failed to connect to API: %w", classifyTransport(err))
 	}
 	defer resp.Body.Close()
 
@@ -324,13 +491,13 @@ func (api *APIClient) TestAPIConnection() error {
 		if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
 			return fmt.Errorf("failed to decode profile response: %w", err)
 		}
-		api.UserID = profile.ID
-		api.connected = true
+		api.userID.Store(int64(profile.ID))
+		api.connected.Store(true)
 		return nil
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	return fmt.Errorf("API test failed with status %d: %s", resp.StatusCode, string(body))
+	return responseError(resp.StatusCode, body, fmt.Sprintf("API test failed with status %d", resp.StatusCode))
 }
 
 // GetAccountDetailed retrieves a specific account by ID
@@ -366,6 +533,7 @@ func (api *APIClient) UpdateAccount(accountID int, input models.AccountUpload) (
 	}
 
 	api.applyAuthHeaders(req, "application/x-www-form-urlencoded")
+	applyIdempotencyKey(req, input.IdempotencyKey)
 
 	result, err := doJSON[models.Account](api, req, http.StatusOK, "failed to decode customer response")
 	if err != nil {
@@ -383,17 +551,17 @@ func (api *APIClient) DeleteAccount(accountID int) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", api.APIKey))
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", api.Key()))
 
 	resp, err := api.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to delete customer %d: %w", accountID, err)
+		return fmt.Errorf("failed to delete customer %d: %w", accountID, classifyTransport(err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete customer %d failed with status %d: %s", accountID, resp.StatusCode, string(body))
+		return responseError(resp.StatusCode, body, fmt.Sprintf("delete customer %d failed with status %d", accountID, resp.StatusCode))
 	}
 
 	return nil
@@ -415,6 +583,7 @@ func (api *APIClient) CreateAccount(input models.AccountUpload) (*APIResponse[mo
 	}
 
 	api.applyAuthHeaders(req, "application/x-www-form-urlencoded")
+	applyIdempotencyKey(req, input.IdempotencyKey)
 
 	result, err := doJSON[models.Account](api, req, http.StatusCreated, "failed to decode customer response")
 	if err != nil {
@@ -440,6 +609,32 @@ func (api *APIClient) GetRoute(routeID int) (*APIResponse[models.Route], error)
 	return result, nil
 }
 
+// CreateRoute creates a route with its waypoints. Routes are sent as JSON
+// rather than a form, since waypoints are nested.
+func (api *APIClient) CreateRoute(input models.RouteUpload) (*APIResponse[models.Route], error) {
+	if strings.TrimSpace(input.Name) == "" || strings.TrimSpace(input.RouteDate) == "" {
+		return nil, fmt.Errorf("a route needs a name and a date")
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode route: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", api.endpoints.Routes(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	api.applyAuthHeaders(req, "application/json")
+	applyIdempotencyKey(req, input.IdempotencyKey)
+
+	result, err := doJSON[models.Route](api, req, http.StatusCreated, "failed to decode route response")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route: %w", err)
+	}
+	return result, nil
+}
+
 // GetCheckinsForAccount retrieves checkins for a specific account
 func (api *APIClient) GetCheckinsForAccount(customerID int) (*APIResponse[[]models.Checkin], error) {
 	endpoint := api.endpoints.AppointmentsForCustomer(customerID)
@@ -460,6 +655,31 @@ func (api *APIClient) GetCheckinsForAccount(customerID int) (*APIResponse[[]mode
 // CreateCheckin creates a new checkin for an account.
 // checkinType is required; fields with empty/null-like values are skipped.
 func (api *APIClient) CreateCheckin(input models.CheckinUpload) (*APIResponse[models.Checkin], error) {
+	form, err := checkinForm(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert data to form-encoded string
+	body := encodeFormData(form)
+
+	req, err := http.NewRequest("POST", api.endpoints.Appointments(), strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	api.applyAuthHeaders(req, "application/x-www-form-urlencoded")
+	applyIdempotencyKey(req, input.IdempotencyKey)
+
+	result, err := doJSON[models.Checkin](api, req, http.StatusCreated, "failed to decode appointment response")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create appointment: %w", err)
+	}
+	return result, nil
+}
+
+// checkinForm returns the form fields CreateCheckin sends.
+func checkinForm(input models.CheckinUpload) (map[string]string, error) {
 	customer := input.Customer
 	checkinType := input.Type
 	fields := input.Fields
@@ -471,7 +691,6 @@ func (api *APIClient) CreateCheckin(input models.CheckinUpload) (*APIResponse[mo
 		return nil, fmt.Errorf("checkin type is required")
 	}
 
-	endpoint := api.endpoints.Appointments()
 	form := map[string]string{
 		"customer": strconv.Itoa(customer),
 		"type":     strings.TrimSpace(checkinType),
@@ -486,16 +705,29 @@ func (api *APIClient) CreateCheckin(input models.CheckinUpload) (*APIResponse[mo
 		}
 		form[normalized] = value
 	}
+	return form, nil
+}
+
+// CreateCustomCheckin creates a new custom checkin for an account.
+// Do not use unless enables for Badger Account.
+// Format for extra_fields -> {"Log Type":"Phone Call","Meeting Notes":"Notes"}
+// Encoding for extra_fields -> customer=someNumericID&extra_fields=%7B%22Log%20Type%22%3A%22Phone%20Call%22%2C%22Meeting%20Notes%22%3A%22Notes%22%7D
+// checkinType is required; fields with empty/null-like values are skipped.
+func (api *APIClient) CreateCustomCheckin(input models.CustomCheckinUpload) (*APIResponse[models.Checkin], error) {
+	form, err := customCheckinForm(input)
+	if err != nil {
+		return nil, err
+	}
 
-	// Convert data to form-encoded string
 	body := encodeFormData(form)
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(body))
+	req, err := http.NewRequest("POST", api.endpoints.Appointments(), strings.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	api.applyAuthHeaders(req, "application/x-www-form-urlencoded")
+	applyIdempotencyKey(req, input.IdempotencyKey)
 
 	result, err := doJSON[models.Checkin](api, req, http.StatusCreated, "failed to decode appointment response")
 	if err != nil {
@@ -504,12 +736,9 @@ func (api *APIClient) CreateCheckin(input models.CheckinUpload) (*APIResponse[mo
 	return result, nil
 }
 
-// CreateCustomCheckin creates a new custom checkin for an account.
-// Do not use unless enables for Badger Account.
-// Format for extra_fields -> {"Log Type":"Phone Call","Meeting Notes":"Notes"}
-// Encoding for extra_fields -> customer=someNumericID&extra_fields=%7B%22Log%20Type%22%3A%22Phone%20Call%22%2C%22Meeting%20Notes%22%3A%22Notes%22%7D
-// checkinType is required; fields with empty/null-like values are skipped.
-func (api *APIClient) CreateCustomCheckin(input models.CustomCheckinUpload) (*APIResponse[models.Checkin], error) {
+// customCheckinForm returns the form fields CreateCustomCheckin sends,
+// with the extra fields merged into one extra_fields JSON value.
+func customCheckinForm(input models.CustomCheckinUpload) (map[string]string, error) {
 	customer := input.Customer
 	checkinType := input.Type
 	fields := input.Fields
@@ -522,8 +751,6 @@ func (api *APIClient) CreateCustomCheckin(input models.CustomCheckinUpload) (*AP
 		return nil, fmt.Errorf("checkin type is required")
 	}
 
-	endpoint := api.endpoints.Appointments()
-
 	form := map[string]string{
 		"customer": strconv.Itoa(customer),
 		"type":     strings.TrimSpace(checkinType),
@@ -588,21 +815,7 @@ func (api *APIClient) CreateCustomCheckin(input models.CustomCheckinUpload) (*AP
 		}
 		form["extra_fields"] = string(extraFieldsJSON)
 	}
-
-	body := encodeFormData(form)
-
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	api.applyAuthHeaders(req, "application/x-www-form-urlencoded")
-
-	result, err := doJSON[models.Checkin](api, req, http.StatusCreated, "failed to decode appointment response")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create appointment: %w", err)
-	}
-	return result, nil
+	return form, nil
 }
 
 // UpdateLocation updates a location
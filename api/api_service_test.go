@@ -2,12 +2,17 @@ package api
 
 import (
 	"badgermaps/api/models"
+	"badgermaps/errs"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -147,6 +152,88 @@ func TestResponsePreview(t *testing.T) {
 	}
 }
 
+func TestAPIErrorsAreClassified(t *testing.T) {
+	server := newTestAPIServer(t, map[string]http.HandlerFunc{
+		"GET /unauthorized": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusUnauthorized, `{"detail":"Invalid token."}`)
+		},
+		"GET /throttled": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusTooManyRequests, `{}`)
+		},
+		"GET /teapot": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusTeapot, `{}`)
+		},
+		"GET /gone": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusGone, `{}`)
+		},
+	})
+	client := newTestClient(server.URL)
+
+	for path, want := range map[string]error{"/unauthorized": errs.ErrAuth, "/throttled": errs.ErrRateLimit, "/teapot": nil, "/gone": nil} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		_, err := doJSON[map[string]any](client, req, http.StatusOK, "decode failed")
+		if err == nil || !strings.Contains(err.Error(), "unexpected status") {
+			t.Fatalf("%s: expected unexpected status error, got %v", path, err)
+		}
+		if got := errs.Kind(err); got != want {
+			t.Errorf("%s: kind = %v, want %v", path, got, want)
+		}
+		if IsGone(err) != (path == "/gone") {
+			t.Errorf("%s: IsGone = %v", path, IsGone(err))
+		}
+	}
+
+	server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/unauthorized", nil)
+	if _, err := doJSON[map[string]any](client, req, http.StatusOK, "decode failed"); !errors.Is(err, errs.ErrNetwork) {
+		t.Errorf("closed server: err = %v, want errs.ErrNetwork", err)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	server := newTestAPIServer(t, map[string]http.HandlerFunc{
+		"PATCH /customers/99/": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusBadRequest, `{"email":["Enter a valid email address."],"phone_number":"Too long.","non_field_errors":["Check the address."]}`)
+		},
+		"PATCH /customers/98/": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusUnprocessableEntity, `{"errors":{"last_name":["This field may not be blank."]}}`)
+		},
+		"PATCH /customers/97/": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusBadRequest, `not json`)
+		},
+	})
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	_, err := client.UpdateAccount(99, models.AccountUpload{Fields: map[string]string{"email": "nope"}})
+	fields, ok := ValidationErrors(err)
+	if !ok {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if got := fields["email"]; len(got) != 1 || got[0] != "Enter a valid email address." {
+		t.Errorf("email errors = %v", got)
+	}
+	if got := fields["phone_number"]; len(got) != 1 || got[0] != "Too long." {
+		t.Errorf("phone_number errors = %v", got)
+	}
+	if got := fields[""]; len(got) != 1 || got[0] != "Check the address." {
+		t.Errorf("general errors = %v", got)
+	}
+	if !errors.Is(err, errs.ErrValidation) || !strings.Contains(err.Error(), "email: Enter a valid email address.") {
+		t.Errorf("err = %v, want an errs.ErrValidation naming the field", err)
+	}
+
+	_, err = client.UpdateAccount(98, models.AccountUpload{Fields: map[string]string{"last_name": ""}})
+	if fields, ok := ValidationErrors(err); !ok || len(fields["last_name"]) != 1 {
+		t.Errorf("nested errors = %v, %v", fields, err)
+	}
+
+	_, err = client.UpdateAccount(97, models.AccountUpload{})
+	if _, ok := ValidationErrors(err); ok || err == nil {
+		t.Errorf("a body without field errors should be a plain status error, got %v", err)
+	}
+}
+
 func TestDoJSON(t *testing.T) {
 	server := newTestAPIServer(t, map[string]http.HandlerFunc{
 		"GET /ok": func(w http.ResponseWriter, r *http.Request) {
@@ -428,7 +515,7 @@ func TestAPIClient_TestAPIConnection(t *testing.T) {
 			if err != nil {
 				t.Fatalf("TestAPIConnection error: %v", err)
 			}
-			if !client.connected || client.UserID != 55 {
+			if !client.connected.Load() || client.UserID() != 55 {
 				t.Fatalf("expected connected true and user id 55")
 			}
 		})
@@ -586,6 +673,41 @@ func TestAPIClient_GetRoute(t *testing.T) {
 	}
 }
 
+func TestAPIClient_CreateRoute(t *testing.T) {
+	var gotReq requestCapture
+	server := newTestAPIServer(t, map[string]http.HandlerFunc{
+		"POST /routes/": func(w http.ResponseWriter, r *http.Request) {
+			gotReq = captureRequest(t, r)
+			writeJSON(t, w, http.StatusCreated, `{"id":77,"name":"Monday north"}`)
+		},
+	})
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	if _, err := client.CreateRoute(models.RouteUpload{RouteDate: "2026-10-19"}); err == nil {
+		t.Fatalf("expected an error for a route without a name")
+	}
+	res, err := client.CreateRoute(models.RouteUpload{
+		Name:      "Monday north",
+		RouteDate: "2026-10-19",
+		Waypoints: []models.WaypointUpload{{Name: "Acme", CustomerID: 42, Position: 1, ApptTime: "2026-10-19T09:30:00"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRoute error: %v", err)
+	}
+	assertRequestBasics(t, gotReq, http.MethodPost, "/routes/", "application/json")
+	var sent models.RouteUpload
+	if err := json.Unmarshal([]byte(gotReq.Body), &sent); err != nil {
+		t.Fatalf("route body is not JSON: %v", err)
+	}
+	if sent.Name != "Monday north" || len(sent.Waypoints) != 1 || sent.Waypoints[0].CustomerID != 42 {
+		t.Fatalf("unexpected route body: %s", gotReq.Body)
+	}
+	if !res.Data.RouteId.Valid || res.Data.RouteId.Int64 != 77 {
+		t.Fatalf("unexpected created route: %+v", res.Data)
+	}
+}
+
 func TestAPIClient_GetCheckinsForAccount(t *testing.T) {
 	var gotReq requestCapture
 	server := newTestAPIServer(t, map[string]http.HandlerFunc{
@@ -868,10 +990,50 @@ func TestNewAPIClient(t *testing.T) {
 	defer server.Close()
 
 	client := NewAPIClient(&APIConfig{BaseURL: server.URL, APIKey: "test-key"})
+	if client.IsConnected() {
+		t.Fatalf("expected the client to be untested until TestAPIConnection")
+	}
+	if err := client.TestAPIConnection(); err != nil {
+		t.Fatalf("TestAPIConnection error: %v", err)
+	}
 	if !client.IsConnected() {
 		t.Fatalf("expected client to be connected")
 	}
-	if client.UserID != 123 {
-		t.Fatalf("expected user id 123, got %d", client.UserID)
+	if client.UserID() != 123 {
+		t.Fatalf("expected user id 123, got %d", client.UserID())
+	}
+}
+
+func TestNewAPIClientCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, http.StatusOK, `{"id":7}`)
+	}))
+	defer server.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatalf("write CA file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		config    APIConfig
+		connected bool
+	}{
+		{name: "untrusted without CA", config: APIConfig{BaseURL: server.URL}, connected: false},
+		{name: "trusted with CA", config: APIConfig{BaseURL: server.URL, CACert: caPath}, connected: true},
+		{name: "missing CA file", config: APIConfig{BaseURL: server.URL, CACert: caPath + ".missing"}, connected: false},
+		{name: "cert without key", config: APIConfig{BaseURL: server.URL, CACert: caPath, ClientCert: caPath}, connected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewAPIClient(&tt.config)
+			err := client.TestAPIConnection()
+			if client.IsConnected() != tt.connected {
+				t.Fatalf("expected connected=%v, got %v (err: %v)", tt.connected, client.IsConnected(), err)
+			}
+		})
 	}
 }
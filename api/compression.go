@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// minCompressedBody is the smallest request body worth compressing; gzip
+// adds about 20 bytes, so short forms are sent as they are.
+const minCompressedBody = 1024
+
+// gzipTransport compresses request bodies while enabled is set. Responses
+// need nothing here: the standard transport already asks for gzip and
+// decompresses it. A server that answers 415 Unsupported Media Type to a
+// compressed body is sent the request again uncompressed, and no
+// compressed bodies after that.
+type gzipTransport struct {
+	base        http.RoundTripper
+	enabled     *atomic.Bool
+	unsupported atomic.Bool
+}
+
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.enabled.Load() || t.unsupported.Load() || req.Body == nil || req.GetBody == nil ||
+		req.ContentLength < minCompressedBody || req.Header.Get("Content-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return t.base.RoundTrip(req)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err = io.Copy(zw, body)
+	body.Close()
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return t.base.RoundTrip(req)
+	}
+
+	compressed := req.Clone(req.Context())
+	data := buf.Bytes()
+	compressed.Body = io.NopCloser(bytes.NewReader(data))
+	compressed.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+	compressed.ContentLength = int64(len(data))
+	compressed.Header.Set("Content-Encoding", "gzip")
+	resp, err := t.base.RoundTrip(compressed)
+	if err != nil || resp.StatusCode != http.StatusUnsupportedMediaType {
+		return resp, err
+	}
+	resp.Body.Close()
+	t.unsupported.Store(true)
+	retry := req.Clone(req.Context())
+	if retry.Body, err = req.GetBody(); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// SetCompressRequests turns gzip compression of large request bodies on or
+// off, for connections where upload bandwidth is scarce.
+func (api *APIClient) SetCompressRequests(on bool) {
+	api.compress.Store(on)
+}
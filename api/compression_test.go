@@ -0,0 +1,68 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGzipTransport(t *testing.T) {
+	var rejectGzip atomic.Bool
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			if rejectGzip.Load() {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip.NewReader: %v", err)
+				return
+			}
+			body = zr
+		}
+		data, _ := io.ReadAll(body)
+		w.Write([]byte(strings.TrimSpace(string(data))[:5]))
+	}))
+	defer server.Close()
+
+	var enabled atomic.Bool
+	client := &http.Client{Transport: &gzipTransport{base: http.DefaultTransport, enabled: &enabled}}
+	post := func(size int) string {
+		t.Helper()
+		resp, err := client.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader("notes="+strings.Repeat("x", size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status %d", resp.StatusCode)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		return string(data)
+	}
+
+	post(4096)
+	enabled.Store(true)
+	post(10)
+	if got := post(4096); got != "notes" {
+		t.Errorf("compressed body read back as %q", got)
+	}
+	rejectGzip.Store(true)
+	if got := post(4096); got != "notes" {
+		t.Errorf("body retried after 415 read back as %q", got)
+	}
+	post(4096)
+
+	want := []string{"", "", "gzip", "gzip", "", ""}
+	if strings.Join(encodings, ",") != strings.Join(want, ",") {
+		t.Errorf("Content-Encoding per request = %q, want %q", encodings, want)
+	}
+}
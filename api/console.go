@@ -0,0 +1,304 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConsoleMethods are the methods the API console sends.
+var ConsoleMethods = []string{"GET", "POST", "PATCH", "DELETE"}
+
+// EndpointParam is a parameter an endpoint takes.
+type EndpointParam struct {
+	Name string
+	// In is where the parameter goes: "path" for a {name} in the path,
+	// "query", or "form" for the URL-encoded body of writes.
+	In          string
+	Required    bool
+	Description string
+}
+
+// EndpointInfo describes a BadgerMaps endpoint for the API console.
+type EndpointInfo struct {
+	// Path is relative to the API base URL, with {name} for path
+	// parameters, such as "customers/{id}/".
+	Path        string
+	Methods     []string
+	Description string
+	Params      []EndpointParam
+}
+
+// Hint is a one-line summary of the endpoint's methods and parameters.
+func (e EndpointInfo) Hint() string {
+	hint := strings.Join(e.Methods, ", ") + " " + e.Path
+	var params []string
+	for _, p := range e.Params {
+		name := p.Name
+		if !p.Required {
+			name += "?"
+		}
+		params = append(params, p.In+":"+name)
+	}
+	if len(params) > 0 {
+		hint += " (" + strings.Join(params, ", ") + ")"
+	}
+	return hint
+}
+
+// Allows reports whether the endpoint takes method.
+func (e EndpointInfo) Allows(method string) bool {
+	for _, m := range e.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+var accountFormParams = []EndpointParam{
+	{Name: "first_name", In: "form", Description: "Contact first name"},
+	{Name: "last_name", In: "form", Description: "Contact last name or company"},
+	{Name: "phone_number", In: "form"},
+	{Name: "email", In: "form"},
+	{Name: "customer_id", In: "form", Description: "Your CRM's ID for the account"},
+	{Name: "notes", In: "form"},
+	{Name: "account_owner", In: "form"},
+	{Name: "crm_id", In: "form"},
+	{Name: "follow_up_date", In: "form", Description: "YYYY-MM-DD"},
+}
+
+// KnownEndpoints lists the endpoints this client uses, for the API
+// console's autocomplete and parameter hints.
+func KnownEndpoints() []EndpointInfo {
+	return []EndpointInfo{
+		{Path: "customers/", Methods: []string{"GET", "POST"}, Description: "List accounts, or create one",
+			Params: accountFormParams},
+		{Path: "customers/{id}/", Methods: []string{"GET", "PATCH", "DELETE"}, Description: "Read, update, or delete an account",
+			Params: append([]EndpointParam{{Name: "id", In: "path", Required: true, Description: "Account ID"}}, accountFormParams...)},
+		{Path: "routes/", Methods: []string{"GET", "POST"}, Description: "List routes, or create one from a JSON body with its waypoints"},
+		{Path: "routes/{id}/", Methods: []string{"GET"}, Description: "Read a route with its waypoints",
+			Params: []EndpointParam{{Name: "id", In: "path", Required: true, Description: "Route ID"}}},
+		{Path: "appointments/", Methods: []string{"GET", "POST"}, Description: "List check-ins, or log one",
+			Params: []EndpointParam{
+				{Name: "customer_id", In: "query", Description: "Only this account's check-ins (GET)"},
+				{Name: "customer", In: "form", Description: "Account ID to check in at (POST)"},
+				{Name: "type", In: "form", Description: "Check-in type, such as Phone Call"},
+				{Name: "comments", In: "form"},
+				{Name: "extra_fields", In: "form", Description: "JSON of custom check-in fields"},
+			}},
+		{Path: "profiles/", Methods: []string{"GET"}, Description: "The profile of the API key's user, with its data fields"},
+		{Path: "profiles/datafields/{name}/values/", Methods: []string{"GET"}, Description: "Values of a profile data field, paginated",
+			Params: []EndpointParam{
+				{Name: "name", In: "path", Required: true, Description: "Data field name"},
+				{Name: "page_size", In: "query"},
+				{Name: "page", In: "query"},
+			}},
+		{Path: "locations/{id}/", Methods: []string{"PATCH"}, Description: "Update an account location",
+			Params: []EndpointParam{
+				{Name: "id", In: "path", Required: true, Description: "Location ID"},
+				{Name: "address_line_1", In: "form"},
+				{Name: "city", In: "form"},
+				{Name: "state", In: "form"},
+				{Name: "zipcode", In: "form"},
+				{Name: "lat", In: "form"},
+				{Name: "long", In: "form"},
+			}},
+	}
+}
+
+// MatchEndpoints returns the known endpoints whose path starts with
+// prefix, or contains it when none do, ignoring case and a leading slash.
+// Numbers in prefix match {name} segments, so "customers/42" finds
+// "customers/{id}/".
+func MatchEndpoints(prefix string) []EndpointInfo {
+	prefix = strings.ToLower(strings.TrimLeft(strings.TrimSpace(prefix), "/"))
+	var starts, contains []EndpointInfo
+	for _, e := range KnownEndpoints() {
+		switch {
+		case prefix == "" || strings.HasPrefix(e.Path, prefix) || pathMatches(e.Path, prefix):
+			starts = append(starts, e)
+		case strings.Contains(e.Path, prefix) || strings.Contains(strings.ToLower(e.Description), prefix):
+			contains = append(contains, e)
+		}
+	}
+	if len(starts) > 0 {
+		return starts
+	}
+	return contains
+}
+
+// LookupEndpoint returns the known endpoint a request path is for.
+func LookupEndpoint(path string) (EndpointInfo, bool) {
+	path = strings.Trim(strings.ToLower(strings.TrimSpace(path)), "/")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = strings.Trim(path[:i], "/")
+	}
+	for _, e := range KnownEndpoints() {
+		if segmentsMatch(strings.Split(strings.Trim(e.Path, "/"), "/"), strings.Split(path, "/"), false) {
+			return e, true
+		}
+	}
+	return EndpointInfo{}, false
+}
+
+// pathMatches reports whether the typed prefix matches the start of the
+// endpoint template, with any value standing in for a {name} segment.
+func pathMatches(template, prefix string) bool {
+	return segmentsMatch(strings.Split(strings.Trim(template, "/"), "/"), strings.Split(strings.TrimRight(prefix, "/"), "/"), true)
+}
+
+func segmentsMatch(template, typed []string, prefix bool) bool {
+	if len(typed) > len(template) || (!prefix && len(typed) != len(template)) {
+		return false
+	}
+	for i, seg := range typed {
+		want := template[i]
+		if strings.HasPrefix(want, "{") {
+			if seg == "" {
+				return false
+			}
+			continue
+		}
+		last := prefix && i == len(typed)-1
+		if seg != want && !(last && strings.HasPrefix(want, seg)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConsoleRequest is a request composed in the API console.
+type ConsoleRequest struct {
+	Method string
+	// Path is relative to the API base URL and may carry a query string.
+	Path string
+	// Query is added to the URL; Form is sent URL-encoded as the body of
+	// POST and PATCH requests.
+	Query url.Values
+	Form  url.Values
+}
+
+// ConsoleResponse is the API's answer to a console request. Error
+// statuses are returned as responses, not errors, so they can be read.
+type ConsoleResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Headers    http.Header
+	Body       []byte
+	Duration   time.Duration
+}
+
+// PrettyBody returns the body indented when it is JSON, and as it came
+// otherwise.
+func (r *ConsoleResponse) PrettyBody() string {
+	return PrettyJSON(r.Body)
+}
+
+// PrettyJSON indents body when it is JSON and returns it unchanged
+// otherwise.
+func PrettyJSON(body []byte) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, bytes.TrimSpace(body), "", "  "); err != nil {
+		return string(body)
+	}
+	return out.String()
+}
+
+// ConsoleURL resolves a console path against the base URL. Absolute URLs
+// are refused so the API key is only ever sent to the configured API.
+func (api *APIClient) ConsoleURL(path string, query url.Values) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("enter an endpoint path, such as customers/")
+	}
+	if u, err := url.Parse(path); err != nil || u.IsAbs() || u.Host != "" || strings.HasPrefix(path, "//") {
+		return "", fmt.Errorf("%q must be a path relative to the API URL, such as customers/42/", path)
+	}
+	if strings.Contains(path, "{") {
+		return "", fmt.Errorf("fill in the path parameters of %s", path)
+	}
+	full := strings.TrimRight(api.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+	if len(query) > 0 {
+		sep := "?"
+		if strings.Contains(full, "?") {
+			sep = "&"
+		}
+		full += sep + query.Encode()
+	}
+	return full, nil
+}
+
+// Send sends a console request with the client's key, through the same
+// rate limiter and TLS settings as every other request.
+func (api *APIClient) Send(req ConsoleRequest) (*ConsoleResponse, error) {
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	allowed := false
+	for _, m := range ConsoleMethods {
+		allowed = allowed || m == method
+	}
+	if !allowed {
+		return nil, fmt.Errorf("method must be one of %s, got %q", strings.Join(ConsoleMethods, ", "), req.Method)
+	}
+	if api.tlsErr != nil {
+		return nil, fmt.Errorf("invalid API TLS configuration: %w", api.tlsErr)
+	}
+	query := req.Query
+	var body io.Reader
+	contentType := ""
+	if len(req.Form) > 0 {
+		if method == "GET" || method == "DELETE" {
+			return nil, fmt.Errorf("%s requests have no body; send the parameters as query parameters", method)
+		}
+		body = strings.NewReader(req.Form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+	target, err := api.ConsoleURL(req.Path, query)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	api.applyAuthHeaders(httpReq, contentType)
+	httpReq.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := api.client.Do(httpReq)
+	if err != nil {
+		return nil, classifyTransport(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return &ConsoleResponse{
+		Method:     method,
+		URL:        target,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    resp.Header.Clone(),
+		Body:       data,
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// SortedHeaderNames returns the response's header names in order.
+func (r *ConsoleResponse) SortedHeaderNames() []string {
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
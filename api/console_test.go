@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMatchEndpoints(t *testing.T) {
+	paths := func(endpoints []EndpointInfo) string {
+		var out []string
+		for _, e := range endpoints {
+			out = append(out, e.Path)
+		}
+		return strings.Join(out, " ")
+	}
+	for _, tc := range []struct{ prefix, want string }{
+		{"cust", "customers/ customers/{id}/"},
+		{"/customers/42", "customers/{id}/"},
+		{"profiles/datafields/Region/v", "profiles/datafields/{name}/values/"},
+		{"check-ins", "appointments/"},
+		{"nothing", ""},
+	} {
+		if got := paths(MatchEndpoints(tc.prefix)); got != tc.want {
+			t.Errorf("MatchEndpoints(%q) = %q, want %q", tc.prefix, got, tc.want)
+		}
+	}
+	if e, ok := LookupEndpoint("customers/42/?fields=id"); !ok || e.Path != "customers/{id}/" || !e.Allows("patch") {
+		t.Errorf("LookupEndpoint = %+v, %v", e, ok)
+	}
+	if _, ok := LookupEndpoint("customers/42/notes/"); ok {
+		t.Error("expected an unknown path to have no endpoint")
+	}
+}
+
+func TestSendConsoleRequest(t *testing.T) {
+	var got requestCapture
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = captureRequest(t, r)
+		writeJSON(t, w, http.StatusBadRequest, `{"phone_number":["Enter a valid phone number."]}`)
+	}))
+	defer srv.Close()
+	client := NewAPIClient(&APIConfig{BaseURL: srv.URL + "/api/2", APIKey: "k"})
+
+	resp, err := client.Send(ConsoleRequest{
+		Method: "patch",
+		Path:   "/customers/42/",
+		Query:  url.Values{"fields": {"id"}},
+		Form:   url.Values{"phone_number": {"nope"}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Method != "PATCH" || got.Path != "/api/2/customers/42/" || got.RawQuery != "fields=id" || got.Form.Get("phone_number") != "nope" || got.Authorization != "Token k" {
+		t.Errorf("request = %+v", got)
+	}
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(resp.PrettyBody(), "\n  \"phone_number\": [") {
+		t.Errorf("response %d with body:\n%s", resp.StatusCode, resp.PrettyBody())
+	}
+
+	for _, req := range []ConsoleRequest{
+		{Method: "GET", Path: "https://example.com/customers/"},
+		{Method: "GET", Path: "//example.com/customers/"},
+		{Method: "GET", Path: "customers/{id}/"},
+		{Method: "PUT", Path: "customers/"},
+		{Method: "GET", Path: "customers/", Form: url.Values{"a": {"b"}}},
+	} {
+		if _, err := client.Send(req); err == nil {
+			t.Errorf("Send(%+v) succeeded, want an error", req)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"badgermaps/errs"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// StatusError is an unexpected response from the API. Its message is Err's
+// message; the status is kept for callers that act on it, such as IsGone.
+type StatusError struct {
+	StatusCode int
+	// Body is the response's error body, or nil when it was not JSON.
+	Body *ErrorBody
+	Err  error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// IsGone reports whether err is a 410 Gone response, which BadgerMaps sends
+// for accounts that were merged into another one.
+func IsGone(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && status.StatusCode == http.StatusGone
+}
+
+// ValidationError is a 400 or 422 response whose body names the fields the
+// API rejected. It is an errs.ErrValidation and wraps the StatusError.
+type ValidationError struct {
+	// Fields maps each rejected API field to its messages. Messages that
+	// are not about one field, such as "detail", are under "".
+	Fields map[string][]string
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		messages := strings.Join(e.Fields[name], " ")
+		if name != "" {
+			messages = name + ": " + messages
+		}
+		parts = append(parts, messages)
+	}
+	return "the API rejected the request: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Is makes a ValidationError an errs.ErrValidation.
+func (e *ValidationError) Is(target error) bool { return target == errs.ErrValidation }
+
+// ValidationErrors returns the field errors of err when it is a
+// ValidationError.
+func ValidationErrors(err error) (map[string][]string, bool) {
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		return nil, false
+	}
+	return validation.Fields, true
+}
+
+// ErrorBody is the JSON error body of a response read into its parts, so
+// a failure reads as sentences instead of the raw body.
+type ErrorBody struct {
+	// Code is the body's machine-readable error code, if any.
+	Code string
+	// Detail holds the messages that are not about one field.
+	Detail []string
+	// Fields maps each rejected API field to its messages.
+	Fields map[string][]string
+}
+
+// Message returns the body's messages on one line: the details, then each
+// field's messages by field name. It is the code when there are none.
+func (b *ErrorBody) Message() string {
+	parts := append([]string(nil), b.Detail...)
+	names := make([]string, 0, len(b.Fields))
+	for name := range b.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+": "+strings.Join(b.Fields[name], " "))
+	}
+	if len(parts) == 0 {
+		return b.Code
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ErrorDetails returns the error body of the response err came from, when
+// the API sent one that could be read.
+func ErrorDetails(err error) (*ErrorBody, bool) {
+	var status *StatusError
+	if !errors.As(err, &status) || status.Body == nil {
+		return nil, false
+	}
+	return status.Body, true
+}
+
+// generalErrorKeys hold messages that are not about a single field.
+var generalErrorKeys = map[string]bool{"detail": true, "error": true, "message": true, "non_field_errors": true, "title": true}
+
+// codeKeys hold the error code; metaKeys hold values that describe the
+// response rather than the failure.
+var (
+	codeKeys = map[string]bool{"code": true, "error_code": true}
+	metaKeys = map[string]bool{"status": true, "status_code": true, "type": true, "instance": true}
+)
+
+// parseErrorBody reads an error body such as {"detail": "..."},
+// {"field": ["message", ...]}, either nested under "errors" or "error", or
+// a list of {"field": ..., "message": ...} under "errors". It returns nil when the body is not a JSON object or names no
+// code or message.
+func parseErrorBody(body []byte) *ErrorBody {
+	var parsed map[string]interface{}
+	if json.Unmarshal(body, &parsed) != nil {
+		return nil
+	}
+	b := &ErrorBody{Fields: make(map[string][]string)}
+	b.add(parsed)
+	if b.Code == "" && len(b.Detail) == 0 && len(b.Fields) == 0 {
+		return nil
+	}
+	return b
+}
+
+func (b *ErrorBody) add(parsed map[string]interface{}) {
+	for name, value := range parsed {
+		if nested, ok := value.(map[string]interface{}); ok && (name == "errors" || name == "error") {
+			b.add(nested)
+			continue
+		}
+		if list, ok := value.([]interface{}); ok && name == "errors" {
+			b.addList(list)
+			continue
+		}
+		switch {
+		case metaKeys[name]:
+		case codeKeys[name]:
+			if value != nil && b.Code == "" {
+				b.Code = fmt.Sprint(value)
+			}
+		case generalErrorKeys[name]:
+			b.Detail = append(b.Detail, errorMessages(value)...)
+		default:
+			if messages := errorMessages(value); len(messages) > 0 {
+				b.Fields[name] = append(b.Fields[name], messages...)
+			}
+		}
+	}
+	sort.Strings(b.Detail)
+}
+
+// addList reads errors sent as a list, where an object with a "field"
+// holds that field's message and anything else is a general message.
+func (b *ErrorBody) addList(list []interface{}) {
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			if field, ok := object["field"].(string); ok && field != "" {
+				b.Fields[field] = append(b.Fields[field], errorMessages(object)...)
+				continue
+			}
+			if code, ok := object["code"].(string); ok && b.Code == "" {
+				b.Code = code
+			}
+		}
+		b.Detail = append(b.Detail, errorMessages(item)...)
+	}
+}
+
+// responseMessage describes the body of an unexpected response in a few
+// words: the messages of a JSON error body, the title of an HTML error
+// page, or the start of any other text.
+func responseMessage(status int, body []byte) string {
+	if parsed := parseErrorBody(body); parsed != nil {
+		return parsed.Message()
+	}
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return http.StatusText(status)
+	}
+	if lower := strings.ToLower(text); strings.HasPrefix(lower, "<!doctype html") || strings.HasPrefix(lower, "<html") {
+		if start := strings.Index(lower, "<title>"); start >= 0 {
+			if end := strings.Index(lower[start:], "</title>"); end > 0 {
+				if title := strings.TrimSpace(text[start+len("<title>") : start+end]); title != "" {
+					return title
+				}
+			}
+		}
+		return http.StatusText(status)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 200 {
+		text = string(runes[:200]) + "..."
+	}
+	return text
+}
+
+// responseError is the error of an unexpected response: what, a description
+// such as "unexpected status 400", followed by responseMessage. A 400 or
+// 422 body naming messages is a ValidationError; any other response gets
+// the kind its status stands for.
+func responseError(status int, body []byte, what string) error {
+	err := fmt.Errorf("%s: %s", what, responseMessage(status, body))
+	parsed := parseErrorBody(body)
+	if validation := parseValidationError(status, parsed, err); validation != nil {
+		return validation
+	}
+	return classifyStatus(status, parsed, err)
+}
+
+// parseValidationError turns the error body of a 400 or 422 response into
+// a ValidationError. It returns nil when the body names no messages.
+func parseValidationError(status int, body *ErrorBody, err error) *ValidationError {
+	if status != http.StatusBadRequest && status != http.StatusUnprocessableEntity || body == nil {
+		return nil
+	}
+	fields := make(map[string][]string, len(body.Fields)+1)
+	for name, messages := range body.Fields {
+		fields[name] = messages
+	}
+	if len(body.Detail) > 0 {
+		fields[""] = body.Detail
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields, Err: &StatusError{StatusCode: status, Body: body, Err: err}}
+}
+
+// errorMessages flattens the value of one field of an error body.
+func errorMessages(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		var messages []string
+		for _, item := range v {
+			messages = append(messages, errorMessages(item)...)
+		}
+		return messages
+	case map[string]interface{}:
+		var messages []string
+		for _, key := range []string{"message", "detail", "msg"} {
+			messages = append(messages, errorMessages(v[key])...)
+		}
+		return messages
+	case nil:
+		return nil
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+// classifyStatus gives err, which describes an unexpected response, the
+// kind its status stands for, so callers can tell a rejected key from a
+// rate limit without parsing the message.
+func classifyStatus(status int, body *ErrorBody, err error) error {
+	err = &StatusError{StatusCode: status, Body: body, Err: err}
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errs.New(errs.ErrAuth, err)
+	case http.StatusTooManyRequests:
+		return errs.New(errs.ErrRateLimit, err)
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return errs.New(errs.ErrConflict, err)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errs.New(errs.ErrNetwork, err)
+	}
+	return err
+}
+
+// classifyTransport marks a request that never got a response as a
+// network error. Cancellation is left alone; nothing went wrong.
+func classifyTransport(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return errs.New(errs.ErrNetwork, err)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorBody(t *testing.T) {
+	for _, tc := range []struct {
+		body string
+		want *ErrorBody
+	}{
+		{`{"detail":"Not found.","code":"not_found"}`, &ErrorBody{Code: "not_found", Detail: []string{"Not found."}, Fields: map[string][]string{}}},
+		{`{"error":{"code":"invalid_token","message":"Token expired."},"status":401}`, &ErrorBody{Code: "invalid_token", Detail: []string{"Token expired."}, Fields: map[string][]string{}}},
+		{`{"errors":[{"field":"email","message":"Enter a valid email address."},{"message":"Check the address."}]}`, &ErrorBody{Detail: []string{"Check the address."}, Fields: map[string][]string{"email": {"Enter a valid email address."}}}},
+		{`{"phone_number":["Too long."],"non_field_errors":["Duplicate account."]}`, &ErrorBody{Detail: []string{"Duplicate account."}, Fields: map[string][]string{"phone_number": {"Too long."}}}},
+		{`{}`, nil},
+		{`[1,2]`, nil},
+		{`<html><body>Bad gateway</body></html>`, nil},
+	} {
+		if got := parseErrorBody([]byte(tc.body)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseErrorBody(%s) = %+v, want %+v", tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestResponseMessage(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	for _, tc := range []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{http.StatusBadRequest, `{"email":["Enter a valid email address."],"detail":"Fix the fields."}`, "Fix the fields.; email: Enter a valid email address."},
+		{http.StatusBadGateway, "<!DOCTYPE html>\n<html><head><title>502 Bad Gateway</title></head><body><h1>nginx</h1></body></html>", "502 Bad Gateway"},
+		{http.StatusInternalServerError, "<html><body><h1>Server Error</h1></body></html>", "Internal Server Error"},
+		{http.StatusNotFound, "", "Not Found"},
+		{http.StatusTeapot, "  short\n  and plain  ", "short and plain"},
+		{http.StatusTeapot, long, strings.TrimSpace(long)[:200] + "..."},
+	} {
+		if got := responseMessage(tc.status, []byte(tc.body)); got != tc.want {
+			t.Errorf("responseMessage(%d, %.40q) = %q, want %q", tc.status, tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestResponseErrorsAreReadable(t *testing.T) {
+	server := newTestAPIServer(t, map[string]http.HandlerFunc{
+		"GET /customers/5/": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusNotFound, `{"detail":"Not found.","code":"not_found"}`)
+		},
+		"DELETE /customers/6/": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("<html><head><title>Down for maintenance</title></head><body>...</body></html>"))
+		},
+	})
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	_, err := client.GetAccountDetailed(5)
+	if err == nil || !strings.HasSuffix(err.Error(), "unexpected status 404: Not found.") {
+		t.Fatalf("err = %v, want the body's detail", err)
+	}
+	if body, ok := ErrorDetails(err); !ok || body.Code != "not_found" {
+		t.Fatalf("ErrorDetails = %+v, %v", body, ok)
+	}
+
+	err = client.DeleteAccount(6)
+	if err == nil || strings.Contains(err.Error(), "<html") || !strings.Contains(err.Error(), "Down for maintenance") {
+		t.Fatalf("err = %v, want the page title instead of the HTML", err)
+	}
+	if _, ok := ErrorDetails(err); ok {
+		t.Fatal("an HTML page has no error details")
+	}
+}
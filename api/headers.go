@@ -0,0 +1,128 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// ClientVersion is the application version the User-Agent reports. The app
+// sets it at startup; builds that leave it empty send no version.
+var ClientVersion string
+
+// userAgentProduct names the application in the User-Agent.
+const userAgentProduct = "BadgerMapsSync"
+
+// reservedHeaders are set by the client for each request and cannot be
+// replaced from api.headers.
+var reservedHeaders = []string{
+	"Authorization", "Content-Type", "Content-Length", "Content-Encoding",
+	"Idempotency-Key", "Host", "User-Agent", "Transfer-Encoding", "Connection",
+}
+
+// UserAgent returns the User-Agent sent with API requests: the application
+// and its version, the operating system and architecture, and suffix, from
+// api.user_agent, when set, as in
+// "BadgerMapsSync/1.4.0 (linux; amd64) acme-sync/2".
+func UserAgent(version, suffix string) string {
+	product := userAgentProduct
+	if version = strings.TrimSpace(version); version != "" {
+		product += "/" + strings.TrimPrefix(version, "v")
+	}
+	ua := fmt.Sprintf("%s (%s; %s)", product, runtime.GOOS, runtime.GOARCH)
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		ua += " " + suffix
+	}
+	return ua
+}
+
+// ValidateHeaders checks the extra headers in api.headers: names must be
+// valid header names other than the ones the client sets itself, and values
+// must not contain line breaks.
+func ValidateHeaders(headers map[string]string) error {
+	for _, name := range sortedHeaderNames(headers) {
+		if err := validateHeader(name, headers[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateHeader(name, value string) error {
+	if !httpguts.ValidHeaderFieldName(name) {
+		return fmt.Errorf("api.headers: %q is not a valid header name", name)
+	}
+	for _, reserved := range reservedHeaders {
+		if strings.EqualFold(name, reserved) {
+			return fmt.Errorf("api.headers: %s is set by the client and cannot be configured", reserved)
+		}
+	}
+	if !httpguts.ValidHeaderFieldValue(value) {
+		return fmt.Errorf("api.headers: the value of %s contains a line break or control character", name)
+	}
+	return nil
+}
+
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requestDecoration is what headerTransport adds to every request.
+type requestDecoration struct {
+	userAgent string
+	headers   http.Header
+}
+
+// headerTransport is the request decorator: it sets the User-Agent and the
+// configured extra headers on every request the client sends, including
+// API console requests. Headers a request already carries are left alone.
+type headerTransport struct {
+	base       http.RoundTripper
+	decoration *atomic.Pointer[requestDecoration]
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	d := t.decoration.Load()
+	if d == nil {
+		return t.base.RoundTrip(req)
+	}
+	decorated := req.Clone(req.Context())
+	decorated.Header.Set("User-Agent", d.userAgent)
+	for name, values := range d.headers {
+		if _, ok := decorated.Header[name]; !ok {
+			decorated.Header[name] = values
+		}
+	}
+	return t.base.RoundTrip(decorated)
+}
+
+// SetRequestHeaders replaces the User-Agent and extra headers sent with
+// later requests. Invalid or reserved headers are skipped; LoadConfig
+// reports them with ValidateHeaders.
+func (api *APIClient) SetRequestHeaders(userAgent string, headers map[string]string) {
+	d := &requestDecoration{userAgent: userAgent, headers: make(http.Header, len(headers))}
+	for _, name := range sortedHeaderNames(headers) {
+		if validateHeader(name, headers[name]) == nil {
+			d.headers.Set(name, headers[name])
+		}
+	}
+	api.decoration.Store(d)
+}
+
+// UserAgent returns the User-Agent the client sends.
+func (api *APIClient) UserAgent() string {
+	if d := api.decoration.Load(); d != nil {
+		return d.userAgent
+	}
+	return ""
+}
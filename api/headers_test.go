@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestHeadersAreAddedToEveryRequest(t *testing.T) {
+	var got []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	defer func(version string) { ClientVersion = version }(ClientVersion)
+	ClientVersion = "v1.4.0"
+	client := NewAPIClient(&APIConfig{
+		BaseURL:   server.URL + "/api/2/",
+		APIKey:    "key",
+		UserAgent: "acme-sync/2",
+		Headers: map[string]string{
+			"X-Request-Source": "badgermaps-sync",
+			"Authorization":    "Bearer stolen",
+			"Bad Header":       "x",
+		},
+	})
+	if _, err := client.Send(ConsoleRequest{Method: "GET", Path: "profiles/"}); err != nil {
+		t.Fatal(err)
+	}
+	client.SetRequestHeaders(UserAgent("1.5.0", ""), map[string]string{"Traceparent": "00-abc-def-01"})
+	if _, err := client.Send(ConsoleRequest{Method: "GET", Path: "profiles/"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("server saw %d requests, want 2", len(got))
+	}
+	first := got[0]
+	if ua := first.Get("User-Agent"); !strings.HasPrefix(ua, "BadgerMapsSync/1.4.0 (") || !strings.HasSuffix(ua, ") acme-sync/2") {
+		t.Errorf("User-Agent = %q", ua)
+	}
+	if first.Get("X-Request-Source") != "badgermaps-sync" || first.Get("Authorization") != "Token key" || first.Get("Bad Header") != "" {
+		t.Errorf("unexpected headers: %v", first)
+	}
+	second := got[1]
+	if !strings.HasPrefix(second.Get("User-Agent"), "BadgerMapsSync/1.5.0 (") || second.Get("Traceparent") != "00-abc-def-01" || second.Get("X-Request-Source") != "" {
+		t.Errorf("headers after SetRequestHeaders: %v", second)
+	}
+}
+
+func TestValidateHeaders(t *testing.T) {
+	for _, tt := range []struct {
+		headers map[string]string
+		wantErr bool
+	}{
+		{map[string]string{"X-Request-Source": "sync", "Traceparent": "00-abc"}, false},
+		{nil, false},
+		{map[string]string{"content-type": "text/plain"}, true},
+		{map[string]string{"User-Agent": "curl"}, true},
+		{map[string]string{"X Source": "sync"}, true},
+		{map[string]string{"X-Source": "a\r\nInjected: b"}, true},
+	} {
+		if err := ValidateHeaders(tt.headers); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateHeaders(%v) = %v, wantErr %v", tt.headers, err, tt.wantErr)
+		}
+	}
+}
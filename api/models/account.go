@@ -0,0 +1,99 @@
+package models
+
+import "github.com/guregu/null/v6"
+
+// Account represents a BadgerMaps account (customer)
+type Account struct {
+	AccountId            null.Int    `json:"id"`
+	FirstName            null.String `json:"first_name"`
+	LastName             null.String `json:"last_name"`
+	FullName             null.String `json:"full_name"`
+	PhoneNumber          null.String `json:"phone_number"`
+	Email                null.String `json:"email"`
+	CustomerId           null.String `json:"customer_id"`
+	Notes                null.String `json:"notes"`
+	OriginalAddress      null.String `json:"original_address"`
+	CrmId                null.String `json:"crm_id"`
+	AccountOwner         null.String `json:"account_owner"`
+	DaysSinceLastCheckin null.Int    `json:"days_since_last_checkin"`
+	LastCheckinDate      Date        `json:"last_checkin_date"`
+	LastModifiedDate     Date        `json:"last_modified_date"`
+	FollowUpDate         Date        `json:"follow_up_date"`
+	Locations            []Location  `json:"locations"`
+	CustomNumeric        null.Float  `json:"custom_numeric"`
+	CustomText           null.String `json:"custom_text"`
+	CustomNumeric2       null.Float  `json:"custom_numeric2"`
+	CustomText2          null.String `json:"custom_text2"`
+	CustomNumeric3       null.Float  `json:"custom_numeric3"`
+	CustomText3          null.String `json:"custom_text3"`
+	CustomNumeric4       null.Float  `json:"custom_numeric4"`
+	CustomText4          null.String `json:"custom_text4"`
+	CustomNumeric5       null.Float  `json:"custom_numeric5"`
+	CustomText5          null.String `json:"custom_text5"`
+	CustomNumeric6       null.Float  `json:"custom_numeric6"`
+	CustomText6          null.String `json:"custom_text6"`
+	CustomNumeric7       null.Float  `json:"custom_numeric7"`
+	CustomText7          null.String `json:"custom_text7"`
+	CustomNumeric8       null.Float  `json:"custom_numeric8"`
+	CustomText8          null.String `json:"custom_text8"`
+	CustomNumeric9       null.Float  `json:"custom_numeric9"`
+	CustomText9          null.String `json:"custom_text9"`
+	CustomNumeric10      null.Float  `json:"custom_numeric10"`
+	CustomText10         null.String `json:"custom_text10"`
+	CustomNumeric11      null.Float  `json:"custom_numeric11"`
+	CustomText11         null.String `json:"custom_text11"`
+	CustomNumeric12      null.Float  `json:"custom_numeric12"`
+	CustomText12         null.String `json:"custom_text12"`
+	CustomNumeric13      null.Float  `json:"custom_numeric13"`
+	CustomText13         null.String `json:"custom_text13"`
+	CustomNumeric14      null.Float  `json:"custom_numeric14"`
+	CustomText14         null.String `json:"custom_text14"`
+	CustomNumeric15      null.Float  `json:"custom_numeric15"`
+	CustomText15         null.String `json:"custom_text15"`
+	CustomNumeric16      null.Float  `json:"custom_numeric16"`
+	CustomText16         null.String `json:"custom_text16"`
+	CustomNumeric17      null.Float  `json:"custom_numeric17"`
+	CustomText17         null.String `json:"custom_text17"`
+	CustomNumeric18      null.Float  `json:"custom_numeric18"`
+	CustomText18         null.String `json:"custom_text18"`
+	CustomNumeric19      null.Float  `json:"custom_numeric19"`
+	CustomText19         null.String `json:"custom_text19"`
+	CustomNumeric20      null.Float  `json:"custom_numeric20"`
+	CustomText20         null.String `json:"custom_text20"`
+	CustomNumeric21      null.Float  `json:"custom_numeric21"`
+	CustomText21         null.String `json:"custom_text21"`
+	CustomNumeric22      null.Float  `json:"custom_numeric22"`
+	CustomText22         null.String `json:"custom_text22"`
+	CustomNumeric23      null.Float  `json:"custom_numeric23"`
+	CustomText23         null.String `json:"custom_text23"`
+	CustomNumeric24      null.Float  `json:"custom_numeric24"`
+	CustomText24         null.String `json:"custom_text24"`
+	CustomNumeric25      null.Float  `json:"custom_numeric25"`
+	CustomText25         null.String `json:"custom_text25"`
+	CustomNumeric26      null.Float  `json:"custom_numeric26"`
+	CustomText26         null.String `json:"custom_text26"`
+	CustomNumeric27      null.Float  `json:"custom_numeric27"`
+	CustomText27         null.String `json:"custom_text27"`
+	CustomNumeric28      null.Float  `json:"custom_numeric28"`
+	CustomText28         null.String `json:"custom_text28"`
+	CustomNumeric29      null.Float  `json:"custom_numeric29"`
+	CustomText29         null.String `json:"custom_text29"`
+	CustomNumeric30      null.Float  `json:"custom_numeric30"`
+	CustomText30         null.String `json:"custom_text30"`
+	CreatedAt            Date        `json:"created_at"`
+	UpdatedAt            Date        `json:"updated_at"`
+}
+
+// Location represents a BadgerMaps location
+type Location struct {
+	LocationId    null.Int    `json:"id"`
+	City          null.String `json:"city"`
+	Name          null.String `json:"name"`
+	Zipcode       null.String `json:"zipcode"`
+	Long          null.Float  `json:"long"`
+	State         null.String `json:"state"`
+	Lat           null.Float  `json:"lat"`
+	AddressLine1  null.String `json:"address_line_1"`
+	Location      null.String `json:"location"`
+	IsApproximate null.Bool   `json:"is_approximate"`
+}
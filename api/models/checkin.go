@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/guregu/null/v6"
+)
+
+// Checkin represents a BadgerMaps checkin (appointment)
+type Checkin struct {
+	CheckinId    null.Int        `json:"id"`
+	CrmId        null.String     `json:"crm_id"`
+	AccountId    null.Int        `json:"customer"`
+	LogDatetime  Date            `json:"log_datetime"`
+	Type         null.String     `json:"type"`
+	Comments     null.String     `json:"comments"`
+	ExtraFields  json.RawMessage `json:"extra_fields"`
+	EndpointType null.String     `json:"endpoint_type"`
+	CreatedBy    null.String     `json:"created_by"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout used when a Date is built from a time.Time.
+const DateLayout = "2006-01-02T15:04:05"
+
+// dateLayouts lists the formats the API uses for dates and timestamps,
+// including the "2006-01-02-T15:04:05" form seen on check-in log times.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02-T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Date is a nullable API date or timestamp. It keeps the exact text it was
+// decoded from, so encoding it again does not change the format, and parses
+// the text on demand with Time.
+type Date struct {
+	sql.NullString
+}
+
+// NewDate returns a valid Date for t formatted with DateLayout.
+func NewDate(t time.Time) Date {
+	return DateFrom(t.Format(DateLayout))
+}
+
+// DateFrom returns a valid Date holding s as given.
+func DateFrom(s string) Date {
+	return Date{sql.NullString{String: s, Valid: true}}
+}
+
+// Time parses the date. It reports false when the date is null, empty, or in
+// a format the API is not known to use.
+func (d Date) Time() (time.Time, bool) {
+	if !d.Valid || d.String == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, d.String); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MarshalJSON encodes the stored text, or null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if !d.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String)
+}
+
+// UnmarshalJSON accepts a JSON string or null. The text is kept as sent and
+// is not required to parse, so an unfamiliar format is not lost.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		d.NullString = sql.NullString{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("models: date must be a string or null, got %s", data)
+	}
+	d.NullString = sql.NullString{String: s, Valid: true}
+	return nil
+}
+
+// ValueOrZero returns the stored text, or "" when the date is null.
+func (d Date) ValueOrZero() string {
+	if !d.Valid {
+		return ""
+	}
+	return d.String
+}
@@ -0,0 +1,11 @@
+// Package models holds the request and response types for the BadgerMaps
+// API.
+//
+// Nullable fields use the value types from guregu/null (null.String,
+// null.Int, null.Float, null.Bool), so a JSON null and a missing key both
+// decode to an invalid value and encode back as null. Pointers are only used
+// for optional fields tagged omitempty, which the API leaves out entirely.
+// Dates use Date, which keeps the text the API sent. Decoding a pulled
+// record and encoding it again yields the same JSON, so push payloads built
+// from stored data match what was pulled.
+package models
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestGoldenRoundTrip decodes each golden payload and encodes it again. The
+// output must match the file byte for byte, so nothing pulled is lost or
+// reformatted on the way back out.
+func TestGoldenRoundTrip(t *testing.T) {
+	tests := []struct {
+		file string
+		new  func() any
+	}{
+		{"account.json", func() any { return &Account{} }},
+		{"account_nulls.json", func() any { return &Account{} }},
+		{"checkin.json", func() any { return &Checkin{} }},
+		{"route.json", func() any { return &Route{} }},
+		{"profile.json", func() any { return &UserProfile{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.file)
+			golden, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := tt.new()
+			if err := json.Unmarshal(golden, v); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			got, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			got = append(got, '\n')
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !bytes.Equal(got, golden) {
+				t.Fatalf("round trip changed %s:\n%s", tt.file, got)
+			}
+		})
+	}
+}
+
+func TestDate(t *testing.T) {
+	var d Date
+	if err := json.Unmarshal([]byte(`"2020-01-02-T13:45:01.187"`), &d); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := d.Time()
+	if !ok || !got.Equal(time.Date(2020, 1, 2, 13, 45, 1, 187000000, time.UTC)) {
+		t.Fatalf("Time() = %v, %v", got, ok)
+	}
+	if out, _ := json.Marshal(d); string(out) != `"2020-01-02-T13:45:01.187"` {
+		t.Fatalf("Marshal = %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`null`), &d); err != nil || d.Valid {
+		t.Fatalf("null: %+v, %v", d, err)
+	}
+	if err := json.Unmarshal([]byte(`20200102`), &d); err == nil {
+		t.Fatal("expected an error for a numeric date")
+	}
+	if _, ok := DateFrom("next tuesday").Time(); ok {
+		t.Fatal("unknown format should not parse")
+	}
+	if d := NewDate(time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)); d.ValueOrZero() != "2024-03-05T09:00:00" {
+		t.Fatalf("NewDate = %q", d.ValueOrZero())
+	}
+}
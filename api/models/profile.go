@@ -0,0 +1,74 @@
+package models
+
+import "github.com/guregu/null/v6"
+
+// UserProfile represents a BadgerMaps user profile
+type UserProfile struct {
+	ProfileId                 null.Int      `json:"id"`
+	Email                     null.String   `json:"email"`
+	FirstName                 null.String   `json:"first_name"`
+	LastName                  null.String   `json:"last_name"`
+	IsManager                 null.Bool     `json:"is_manager"`
+	IsHideReferralIOSBanner   null.Bool     `json:"is_hide_referral_ios_banner"`
+	MarkerIcon                null.String   `json:"marker_icon"`
+	Manager                   null.String   `json:"manager"`
+	CRMEditableFieldsList     []null.String `json:"crm_editable_fields_list"`
+	CRMBaseURL                null.String   `json:"crm_base_url"`
+	CRMType                   null.String   `json:"crm_type"`
+	ReferralURL               null.String   `json:"referral_url"`
+	MapStartZoom              null.Int      `json:"map_start_zoom"`
+	MapStart                  null.String   `json:"map_start"`
+	IsUserCanEdit             null.Bool     `json:"is_user_can_edit"`
+	IsUserCanDeleteCheckins   null.Bool     `json:"is_user_can_delete_checkins"`
+	IsUserCanAddNewTextValues null.Bool     `json:"is_user_can_add_new_text_values"`
+	HasData                   null.Bool     `json:"has_data"`
+	DefaultApptLength         null.Int      `json:"default_appt_length"`
+	Completed                 null.Bool     `json:"completed"`
+	TrialDaysLeft             null.Int      `json:"trial_days_left"`
+	ApptlogFields             []DataField   `json:"apptlog_fields"`
+	AcctlogFields             []DataField   `json:"acctlog_fields"`
+	Datafields                []DataField   `json:"datafields"`
+	Company                   Company       `json:"company"`
+}
+
+// Company represents a BadgerMaps company
+type Company struct {
+	Id        null.Int    `json:"id"`
+	ShortName null.String `json:"short_name"`
+	Name      null.String `json:"name"`
+}
+
+// DataField represents a custom data field
+type DataField struct {
+	Name                      null.String  `json:"name"`
+	Filterable                null.Bool    `json:"filterable"`
+	Label                     null.String  `json:"label"`
+	Values                    []FieldValue `json:"values,omitempty"`
+	Position                  null.Int     `json:"position"`
+	Type                      null.String  `json:"type"`
+	HasData                   null.Bool    `json:"has_data"`
+	IsUserCanAddNewTextValues null.Bool    `json:"is_user_can_add_new_text_values"`
+	RawMin                    *null.Float  `json:"rawmin,omitempty"`
+	Min                       *null.Float  `json:"min,omitempty"`
+	Max                       *null.Float  `json:"max,omitempty"`
+	RawMax                    *null.Float  `json:"rawmax,omitempty"`
+	AccountField              null.String  `json:"account_field"`
+	// ValuesCount is the total number of values when the profile lists only
+	// the first part of a large picklist in Values.
+	ValuesCount *null.Int `json:"values_count,omitempty"`
+}
+
+// FieldValue represents a field value option
+type FieldValue struct {
+	Text  null.String `json:"text"`
+	Value interface{} `json:"value"`
+}
+
+// FieldValuePage is one page of a data field's values. Next is the URL of
+// the following page, or null on the last one.
+type FieldValuePage struct {
+	Count    int          `json:"count"`
+	Next     null.String  `json:"next"`
+	Previous null.String  `json:"previous"`
+	Results  []FieldValue `json:"results"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "github.com/guregu/null/v6"
+
+// Route represents a BadgerMaps route
+type Route struct {
+	RouteId            null.Int    `json:"id"`
+	Name               null.String `json:"name"`
+	RouteDate          Date        `json:"route_date"`
+	Duration           null.Int    `json:"duration"`
+	Waypoints          []Waypoint  `json:"waypoints"`
+	StartAddress       null.String `json:"start_address"`
+	DestinationAddress null.String `json:"destination_address"`
+	StartTime          null.String `json:"start_time"`
+}
+
+// Waypoint represents a route waypoint
+type Waypoint struct {
+	WaypointID      null.Int    `json:"id"`
+	Name            null.String `json:"name"`
+	Address         null.String `json:"address"`
+	Suite           null.String `json:"suite"`
+	City            null.String `json:"city"`
+	State           null.String `json:"state"`
+	Zipcode         null.String `json:"zipcode"`
+	Location        null.String `json:"location"`
+	Lat             null.Float  `json:"lat"`
+	Long            null.Float  `json:"long"`
+	LayoverMinutes  null.Int    `json:"layover_minutes"`
+	Position        null.Int    `json:"position"`
+	CompleteAddress null.String `json:"complete_address"`
+	LocationID      null.Int    `json:"location_id"`
+	CustomerID      null.Int    `json:"customer_id"`
+	ApptTime        null.String `json:"appt_time"`
+	Type            null.Int    `json:"type"`
+	PlaceID         null.String `json:"place_id"`
+}
@@ -0,0 +1,71 @@
+package models
+
+// AccountUpload contains form fields used for creating/updating an account.
+type AccountUpload struct {
+	Fields map[string]string `json:"fields"`
+	// IdempotencyKey, when set, is sent as the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
+}
+
+// CheckinUpload contains form fields used for creating a checkin.
+type CheckinUpload struct {
+	Customer int               `json:"customer"`
+	Type     string            `json:"type"`
+	Fields   map[string]string `json:"fields"`
+	// IdempotencyKey, when set, is sent as the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
+}
+
+// CustomCheckinUpload contains form fields used for creating a custom checkin.
+type CustomCheckinUpload struct {
+	Customer    int                       `json:"customer"`
+	Type        string                    `json:"type"`
+	Fields      map[string]string         `json:"fields"`
+	ExtraFields *CustomCheckinExtraFields `json:"extra_fields,omitempty"`
+	// IdempotencyKey, when set, is sent as the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
+}
+
+// CustomCheckinExtraFields contains typed custom checkin extra_fields values.
+// All fields are optional.
+type CustomCheckinExtraFields struct {
+	LogType      string `json:"Log Type,omitempty"`
+	MeetingNotes string `json:"Meeting Notes,omitempty"`
+}
+
+// LocationUpload contains form fields used for updating a location.
+type LocationUpload struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// RouteUpload is a route created from a local plan, sent as JSON since its
+// waypoints are nested.
+type RouteUpload struct {
+	Name               string           `json:"name"`
+	RouteDate          string           `json:"route_date"`
+	StartAddress       string           `json:"start_address,omitempty"`
+	DestinationAddress string           `json:"destination_address,omitempty"`
+	StartTime          string           `json:"start_time,omitempty"`
+	Waypoints          []WaypointUpload `json:"waypoints"`
+	// IdempotencyKey, when set, is sent as the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
+}
+
+// WaypointUpload is a stop of a RouteUpload.
+type WaypointUpload struct {
+	Name           string   `json:"name,omitempty"`
+	Address        string   `json:"address,omitempty"`
+	Suite          string   `json:"suite,omitempty"`
+	City           string   `json:"city,omitempty"`
+	State          string   `json:"state,omitempty"`
+	Zipcode        string   `json:"zipcode,omitempty"`
+	Lat            *float64 `json:"lat,omitempty"`
+	Long           *float64 `json:"long,omitempty"`
+	LayoverMinutes int      `json:"layover_minutes,omitempty"`
+	Position       int      `json:"position"`
+	LocationID     int      `json:"location_id,omitempty"`
+	CustomerID     int      `json:"customer_id,omitempty"`
+	ApptTime       string   `json:"appt_time,omitempty"`
+	Type           int      `json:"type,omitempty"`
+	PlaceID        string   `json:"place_id,omitempty"`
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"badgermaps/api/models"
+	"fmt"
+	"strings"
+)
+
+// RequestPreview is a write request as the client would send it, for
+// showing a staged change before it is pushed.
+type RequestPreview struct {
+	Method         string
+	URL            string
+	ContentType    string
+	IdempotencyKey string
+	// Body is the URL-encoded form, before any low-bandwidth compression.
+	Body string
+}
+
+// Curl renders the request as a cURL command. The API key is left as
+// $API_KEY so the command can be shared, with BadgerMaps support for
+// instance, and run after exporting the key.
+func (p RequestPreview) Curl() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "curl -X %s %s", p.Method, shellQuote(p.URL))
+	b.WriteString(" \\\n  -H \"Authorization: Token $API_KEY\"")
+	if p.ContentType != "" {
+		fmt.Fprintf(&b, " \\\n  -H %s", shellQuote("Content-Type: "+p.ContentType))
+	}
+	if p.IdempotencyKey != "" {
+		fmt.Fprintf(&b, " \\\n  -H %s", shellQuote("Idempotency-Key: "+p.IdempotencyKey))
+	}
+	if p.Body != "" {
+		fmt.Fprintf(&b, " \\\n  --data-raw %s", shellQuote(p.Body))
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func formPreview(method, endpoint string, form map[string]string, idempotencyKey string) RequestPreview {
+	if form == nil {
+		form = map[string]string{}
+	}
+	return RequestPreview{
+		Method:         method,
+		URL:            endpoint,
+		ContentType:    "application/x-www-form-urlencoded",
+		IdempotencyKey: idempotencyKey,
+		Body:           encodeFormData(form),
+	}
+}
+
+// PreviewCreateAccount returns the request CreateAccount would send.
+func (api *APIClient) PreviewCreateAccount(input models.AccountUpload) RequestPreview {
+	return formPreview("POST", api.endpoints.Customers(), input.Fields, input.IdempotencyKey)
+}
+
+// PreviewUpdateAccount returns the request UpdateAccount would send.
+func (api *APIClient) PreviewUpdateAccount(accountID int, input models.AccountUpload) RequestPreview {
+	return formPreview("PATCH", api.endpoints.Customer(accountID), input.Fields, input.IdempotencyKey)
+}
+
+// PreviewDeleteAccount returns the request DeleteAccount would send.
+func (api *APIClient) PreviewDeleteAccount(accountID int) RequestPreview {
+	return RequestPreview{Method: "DELETE", URL: api.endpoints.Customer(accountID)}
+}
+
+// PreviewCreateCheckin returns the request CreateCheckin would send.
+func (api *APIClient) PreviewCreateCheckin(input models.CheckinUpload) (RequestPreview, error) {
+	form, err := checkinForm(input)
+	if err != nil {
+		return RequestPreview{}, err
+	}
+	return formPreview("POST", api.endpoints.Appointments(), form, input.IdempotencyKey), nil
+}
+
+// PreviewCreateCustomCheckin returns the request CreateCustomCheckin would
+// send.
+func (api *APIClient) PreviewCreateCustomCheckin(input models.CustomCheckinUpload) (RequestPreview, error) {
+	form, err := customCheckinForm(input)
+	if err != nil {
+		return RequestPreview{}, err
+	}
+	return formPreview("POST", api.endpoints.Appointments(), form, input.IdempotencyKey), nil
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket combined with a concurrency cap. A single
+// instance is shared by every API client the application creates so pulls,
+// pushes, webhook handlers and connection tests draw from the same budget.
+type RateLimiter struct {
+	mu            sync.Mutex
+	rate          float64 // tokens per second; <= 0 disables rate limiting
+	burst         float64
+	tokens        float64
+	last          time.Time
+	maxConcurrent int // <= 0 disables the concurrency cap
+	inFlight      int
+	wake          chan struct{}
+	now           func() time.Time
+}
+
+// NewRateLimiter creates a limiter allowing requestsPerSecond sustained
+// requests with bursts of up to burst, and at most maxConcurrent requests in
+// flight. Zero values disable the corresponding limit.
+func NewRateLimiter(requestsPerSecond float64, burst, maxConcurrent int) *RateLimiter {
+	l := &RateLimiter{
+		wake: make(chan struct{}),
+		now:  time.Now,
+	}
+	l.Update(requestsPerSecond, burst, maxConcurrent)
+	return l
+}
+
+// Update changes the limits in place so existing clients pick them up. The
+// bucket is refilled only when the rate or burst changes, so reloading an
+// unchanged config does not grant a fresh burst.
+func (l *RateLimiter) Update(requestsPerSecond float64, burst, maxConcurrent int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if burst < 1 {
+		burst = 1
+	}
+	if requestsPerSecond != l.rate || float64(burst) != l.burst {
+		l.rate = requestsPerSecond
+		l.burst = float64(burst)
+		l.tokens = l.burst
+		l.last = l.now()
+	}
+	l.maxConcurrent = maxConcurrent
+	l.broadcastLocked()
+}
+
+// InFlight reports how many requests currently hold a slot.
+func (l *RateLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// Acquire blocks until a request may proceed or ctx is cancelled. The
+// returned release function must be called once the request finishes.
+func (l *RateLimiter) Acquire(ctx context.Context) (func(), error) {
+	for {
+		l.mu.Lock()
+		l.refillLocked()
+		concurrencyOK := l.maxConcurrent <= 0 || l.inFlight < l.maxConcurrent
+		rateOK := l.rate <= 0 || l.tokens >= 1
+		if concurrencyOK && rateOK {
+			if l.rate > 0 {
+				l.tokens--
+			}
+			l.inFlight++
+			l.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(l.release) }, nil
+		}
+
+		wake := l.wake
+		var timer *time.Timer
+		var tick <-chan time.Time
+		if concurrencyOK {
+			// Only the bucket is empty; sleep until the next token arrives.
+			timer = time.NewTimer(time.Duration((1 - l.tokens) / l.rate * float64(time.Second)))
+			tick = timer.C
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil, ctx.Err()
+		case <-wake:
+		case <-tick:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (l *RateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight > 0 {
+		l.inFlight--
+	}
+	l.broadcastLocked()
+}
+
+func (l *RateLimiter) refillLocked() {
+	now := l.now()
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+func (l *RateLimiter) broadcastLocked() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// limitedTransport gates every round trip through a shared RateLimiter. A
+// request holds its slot until its response body is read or closed, so
+// max_concurrent_requests also bounds downloads still in progress.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *RateLimiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.Acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		release()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody gives back a request's limiter slot once its body has been
+// read to the end or closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
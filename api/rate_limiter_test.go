@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	tests := []struct {
+		name          string
+		rps           float64
+		burst         int
+		maxConcurrent int
+		requests      int
+		wantMinTime   time.Duration
+	}{
+		{name: "unlimited", requests: 20},
+		{name: "burst covers all requests", rps: 1, burst: 5, requests: 5},
+		{name: "rate throttles beyond burst", rps: 50, burst: 1, requests: 4, wantMinTime: 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.rps, tt.burst, tt.maxConcurrent)
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				release, err := limiter.Acquire(context.Background())
+				if err != nil {
+					t.Fatalf("Acquire returned error: %v", err)
+				}
+				release()
+			}
+			elapsed := time.Since(start)
+			if elapsed < tt.wantMinTime {
+				t.Fatalf("expected at least %v, took %v", tt.wantMinTime, elapsed)
+			}
+			if tt.wantMinTime == 0 && elapsed > time.Second {
+				t.Fatalf("expected requests to proceed immediately, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimiterConcurrencyCap(t *testing.T) {
+	limiter := NewRateLimiter(0, 0, 2)
+	var current, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Acquire returned error: %v", err)
+				return
+			}
+			n := atomic.AddInt32(&current, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, saw %d", peak)
+	}
+	if limiter.InFlight() != 0 {
+		t.Fatalf("expected no requests in flight, got %d", limiter.InFlight())
+	}
+}
+
+func TestRateLimiterContextCancel(t *testing.T) {
+	limiter := NewRateLimiter(0, 0, 1)
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire returned error: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx); err == nil {
+		t.Fatal("expected Acquire to fail once the context expired")
+	}
+}
+
+func TestRateLimiterUpdateKeepsBucket(t *testing.T) {
+	limiter := NewRateLimiter(1, 2, 0)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		release, err := limiter.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire returned error: %v", err)
+		}
+		release()
+	}
+	tokens := func() float64 {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+		return limiter.tokens
+	}
+
+	limiter.Update(1, 2, 4)
+	if got := tokens(); got >= 1 {
+		t.Fatalf("an unchanged rate refilled the bucket to %v tokens", got)
+	}
+	if limiter.maxConcurrent != 4 {
+		t.Fatalf("maxConcurrent = %d, want 4", limiter.maxConcurrent)
+	}
+	limiter.Update(2, 3, 4)
+	if got := tokens(); got != 3 {
+		t.Fatalf("a new rate left %v tokens, want a full bucket of 3", got)
+	}
+}
+
+func TestLimitedTransportHoldsSlotUntilBodyClosed(t *testing.T) {
+	server := newTestAPIServer(t, map[string]http.HandlerFunc{
+		"GET /profiles/": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, http.StatusOK, `{"id":1}`)
+		},
+	})
+	defer server.Close()
+
+	limiter := NewRateLimiter(0, 0, 1)
+	client := &http.Client{Transport: &limitedTransport{base: http.DefaultTransport, limiter: limiter}}
+	resp, err := client.Get(server.URL + "/profiles/")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got := limiter.InFlight(); got != 1 {
+		t.Fatalf("in flight before the body is read = %d, want 1", got)
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if got := limiter.InFlight(); got != 0 {
+		t.Fatalf("in flight after the body is read = %d, want 0", got)
+	}
+	resp.Body.Close()
+	if got := limiter.InFlight(); got != 0 {
+		t.Fatalf("closing the body released the slot twice: in flight %d", got)
+	}
+}
+
+func TestAPIClientSharesLimiter(t *testing.T) {
+	var active, peak int32
+	server := newTestAPIServer(t, map[string]http.HandlerFunc{
+		"GET /profiles/": func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			writeJSON(t, w, http.StatusOK, `{"id":1}`)
+		},
+	})
+	defer server.Close()
+
+	limiter := NewRateLimiter(0, 0, 1)
+	clients := []*APIClient{
+		NewAPIClientWithLimiter(&APIConfig{BaseURL: server.URL}, limiter),
+		NewAPIClientWithLimiter(&APIConfig{BaseURL: server.URL}, limiter),
+	}
+	atomic.StoreInt32(&peak, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(c *APIClient) {
+			defer wg.Done()
+			if err := c.TestAPIConnection(); err != nil {
+				t.Errorf("TestAPIConnection returned error: %v", err)
+			}
+		}(clients[i%2])
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&peak); got != 1 {
+		t.Fatalf("expected shared limiter to serialize requests, peak concurrency %d", got)
+	}
+}
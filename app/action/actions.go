@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,7 +21,11 @@ import (
 type Executor struct {
 	DB  database.DB
 	API *api.APIClient
-	ctx *ExecutionContext
+	// Stage stages account changes for followup actions. Without it they
+	// fail.
+	Stage  StageFunc
+	ctx    *ExecutionContext
+	result *Result
 }
 
 // NewExecutor creates a new Executor.
@@ -49,6 +54,39 @@ func (e *Executor) Context() *ExecutionContext {
 	return e.ctx
 }
 
+// Result is what one action execution produced, for the run history.
+type Result struct {
+	// Args are the action's arguments with templates rendered.
+	Args map[string]interface{}
+	// ExitCode is the exit status of an exec action's command. It is nil
+	// when the command did not start.
+	ExitCode *int
+	// Output is what an exec action's command wrote to stdout and stderr.
+	Output string
+	// RowsAffected is what a db action's statement reported, or -1 when
+	// the driver cannot tell.
+	RowsAffected *int64
+}
+
+// WithResult returns a shallow copy that fills result as the action runs.
+func (e *Executor) WithResult(result *Result) *Executor {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.result = result
+	return &clone
+}
+
+// record returns the result to fill. Without one from WithResult it is a
+// throwaway, so actions can always write to it.
+func (e *Executor) record() *Result {
+	if e == nil || e.result == nil {
+		return &Result{}
+	}
+	return e.result
+}
+
 // Action is the interface for all event actions.
 type Action interface {
 	Execute(executor *Executor) error
@@ -177,10 +215,12 @@ func (c *ExecutionContext) PayloadFieldString(path string) (string, bool) {
 
 // EventAction is the top-level struct for an event-triggered action.
 type EventAction struct {
-	Name   string         `yaml:"name"`
-	Event  string         `yaml:"event"`
-	Source string         `yaml:"source,omitempty"`
-	Run    []ActionConfig `yaml:"run"`
+	Name   string `yaml:"name"`
+	Event  string `yaml:"event"`
+	Source string `yaml:"source,omitempty"`
+	// Disabled keeps the action in the config without running it.
+	Disabled bool           `yaml:"disabled,omitempty"`
+	Run      []ActionConfig `yaml:"run"`
 }
 
 // NewActionFromConfig creates a specific action implementation from a generic ActionConfig.
@@ -196,6 +236,14 @@ func NewActionFromConfig(config ActionConfig) (Action, error) {
 		action = &ExecAction{}
 	case "db":
 		action = &DbAction{}
+	case "backup":
+		action = &BackupAction{}
+	case "digest":
+		action = &DigestAction{}
+	case "archive":
+		action = &ArchiveAction{}
+	case "followup":
+		action = &FollowUpAction{}
 	default:
 		return nil, fmt.Errorf("unknown action type: %s", config.Type)
 	}
@@ -210,9 +258,10 @@ func NewActionFromConfig(config ActionConfig) (Action, error) {
 // ExecAction executes a command using the platform shell by default.
 // Set UseShell to false to execute the binary directly with Args.
 type ExecAction struct {
-	Command  string   `yaml:"command"`
-	Args     []string `yaml:"args"`
-	UseShell *bool    `yaml:"use_shell"`
+	Command   string   `yaml:"command"`
+	Args      []string `yaml:"args"`
+	UseShell  *bool    `yaml:"use_shell"`
+	Templates string   `yaml:"templates,omitempty"`
 }
 
 // Execute runs the command.
@@ -222,11 +271,16 @@ func (a *ExecAction) Execute(executor *Executor) error {
 	args := a.Args
 
 	if ctx != nil {
-		command = replaceEventTokens(command, ctx)
+		var err error
+		if command, err = RenderTemplate(command, ctx, a.Templates); err != nil {
+			return err
+		}
 		if !a.useShell() && len(args) > 0 {
 			replaced := make([]string, len(args))
 			for i, arg := range args {
-				replaced[i] = replaceEventTokens(arg, ctx)
+				if replaced[i], err = RenderTemplate(arg, ctx, a.Templates); err != nil {
+					return err
+				}
 			}
 			args = replaced
 		}
@@ -244,7 +298,17 @@ func (a *ExecAction) Execute(executor *Executor) error {
 		env = append(env, formatEventEnv(ctx)...)
 		cmd.Env = env
 	}
+	result := executor.record()
+	result.Args = map[string]interface{}{"command": command}
+	if len(args) > 0 {
+		result.Args["args"] = args
+	}
 	output, err := cmd.CombinedOutput()
+	result.Output = string(output)
+	if cmd.ProcessState != nil {
+		code := cmd.ProcessState.ExitCode()
+		result.ExitCode = &code
+	}
 	if err != nil {
 		trimmed := strings.TrimSpace(string(output))
 		if trimmed != "" {
@@ -263,15 +327,19 @@ func (a *ExecAction) Validate() error {
 	if a.useShell() && len(a.Args) > 0 {
 		return fmt.Errorf("exec action 'args' are only supported when use_shell is set to false")
 	}
+	if err := ValidateTemplateMode(a.Templates); err != nil {
+		return fmt.Errorf("exec action %w", err)
+	}
 	return nil
 }
 
 // UnmarshalYAML ensures backwards compatibility defaults when decoding from YAML.
 func (a *ExecAction) UnmarshalYAML(value *yaml.Node) error {
 	var raw struct {
-		Command  string   `yaml:"command"`
-		Args     []string `yaml:"args"`
-		UseShell *bool    `yaml:"use_shell"`
+		Command   string   `yaml:"command"`
+		Args      []string `yaml:"args"`
+		UseShell  *bool    `yaml:"use_shell"`
+		Templates string   `yaml:"templates"`
 	}
 	if err := value.Decode(&raw); err != nil {
 		return err
@@ -279,6 +347,7 @@ func (a *ExecAction) UnmarshalYAML(value *yaml.Node) error {
 	a.Command = raw.Command
 	a.Args = raw.Args
 	a.UseShell = raw.UseShell
+	a.Templates = raw.Templates
 	return nil
 }
 
@@ -311,16 +380,26 @@ type DbAction struct {
 func (a *DbAction) Execute(executor *Executor) error {
 	ctx := executor.Context()
 	args := cloneArgsMap(a.config.Args)
+	mode, _ := args["templates"].(string)
+	delete(args, "templates")
 
 	if ctx != nil {
-		applyContextToDBArgs(args, ctx)
+		if err := applyContextToDBArgs(args, ctx, mode); err != nil {
+			return err
+		}
 	}
 
 	dbActionConfig := database.ActionConfig{
 		Type: a.config.Type,
 		Args: args,
 	}
-	return executor.DB.RunAction(dbActionConfig)
+	result := executor.record()
+	result.Args = args
+	rows, err := executor.DB.RunAction(dbActionConfig)
+	if err == nil {
+		result.RowsAffected = &rows
+	}
+	return err
 }
 
 // Validate checks if the action is configured correctly.
@@ -334,6 +413,12 @@ func (a *DbAction) Validate() error {
 	if !hasCommand && !hasFunction && !hasProcedure && !hasQuery {
 		return fmt.Errorf("db action requires one of 'command', 'function', 'procedure', or 'query'")
 	}
+	if mode, ok := args["templates"]; ok {
+		text, _ := mode.(string)
+		if err := ValidateTemplateMode(text); err != nil {
+			return fmt.Errorf("db action %w", err)
+		}
+	}
 	return nil
 }
 
@@ -343,6 +428,59 @@ func (a *DbAction) UnmarshalYAML(value *yaml.Node) error {
 	return value.Decode(&a.config.Args)
 }
 
+// BackupAction writes a database backup, typically from a cron job. A
+// "{timestamp}" placeholder in Path is replaced with the current time so
+// scheduled runs do not overwrite each other.
+type BackupAction struct {
+	Path   string `yaml:"path"`
+	Format string `yaml:"format,omitempty"`
+}
+
+// Execute writes the backup.
+func (a *BackupAction) Execute(executor *Executor) error {
+	path := strings.ReplaceAll(a.Path, "{timestamp}", time.Now().Format("20060102-150405"))
+	executor.record().Args = map[string]interface{}{"path": path, "format": a.Format}
+	return database.BackupDatabase(executor.DB, path, a.Format)
+}
+
+// Validate checks if the action is configured correctly.
+func (a *BackupAction) Validate() error {
+	if strings.TrimSpace(a.Path) == "" {
+		return fmt.Errorf("backup action requires a 'path'")
+	}
+	switch a.Format {
+	case "", database.BackupFormatSQLite, database.BackupFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("backup action 'format' must be %q or %q", database.BackupFormatSQLite, database.BackupFormatJSON)
+}
+
+// ArchiveAction moves check-ins older than Months whole months to the
+// check-in archive in Dir, typically from a cron job.
+type ArchiveAction struct {
+	Months int    `yaml:"months"`
+	Dir    string `yaml:"dir,omitempty"`
+}
+
+// Execute archives the check-ins.
+func (a *ArchiveAction) Execute(executor *Executor) error {
+	dir := a.Dir
+	if dir == "" {
+		dir = database.DefaultCheckinArchiveDir()
+	}
+	executor.record().Args = map[string]interface{}{"months": a.Months, "dir": dir}
+	_, err := database.ArchiveCheckins(executor.DB, dir, database.CheckinArchiveCutoff(time.Now(), a.Months))
+	return err
+}
+
+// Validate checks if the action is configured correctly.
+func (a *ArchiveAction) Validate() error {
+	if a.Months <= 0 {
+		return fmt.Errorf("archive action requires 'months' greater than zero")
+	}
+	return nil
+}
+
 // ApiAction makes an API call.
 type ApiAction struct {
 	Endpoint string            `yaml:"endpoint"`
@@ -385,25 +523,27 @@ func replaceEventTokens(input string, ctx *ExecutionContext) string {
 	if ctx == nil {
 		return input
 	}
-	replacements := map[string]string{
-		"$EVENT_TYPE":   ctx.EventType,
-		"$EVENT_SOURCE": ctx.Source,
-	}
-	if eventJSON, err := ctx.EventJSON(); err == nil {
-		replacements["$EVENT_JSON"] = eventJSON
+	// Longer tokens come first so $EVENT_PAYLOAD does not clobber the
+	// prefix of $EVENT_PAYLOAD_JSON.
+	replacements := []struct{ token, value string }{
+		{"$EVENT_PAYLOAD_JSON", ""},
+		{"$EVENT_SOURCE", ctx.Source},
+		{"$EVENT_PAYLOAD", ctx.payloadText()},
+		{"$EVENT_JSON", ""},
+		{"$EVENT_TYPE", ctx.EventType},
 	}
 	if payloadJSON, err := ctx.PayloadJSON(); err == nil {
-		replacements["$EVENT_PAYLOAD_JSON"] = payloadJSON
+		replacements[0].value = payloadJSON
 	}
-	if payload := ctx.payloadText(); payload != "" {
-		replacements["$EVENT_PAYLOAD"] = payload
+	if eventJSON, err := ctx.EventJSON(); err == nil {
+		replacements[3].value = eventJSON
 	}
 	result := replacePayloadFieldTokens(input, ctx)
-	for token, value := range replacements {
-		if value == "" {
+	for _, r := range replacements {
+		if r.value == "" {
 			continue
 		}
-		result = strings.ReplaceAll(result, token, value)
+		result = strings.ReplaceAll(result, r.token, r.value)
 	}
 	return result
 }
@@ -438,17 +578,25 @@ func cloneValue(v interface{}) interface{} {
 	}
 }
 
-func applyContextToDBArgs(args map[string]interface{}, ctx *ExecutionContext) {
+func applyContextToDBArgs(args map[string]interface{}, ctx *ExecutionContext, mode string) error {
 	if args == nil || ctx == nil {
-		return
+		return nil
 	}
 	for _, key := range []string{"command", "function", "procedure", "query"} {
 		if value, ok := args[key].(string); ok {
-			args[key] = replaceEventTokens(value, ctx)
+			rendered, err := RenderTemplate(value, ctx, mode)
+			if err != nil {
+				return err
+			}
+			args[key] = rendered
 		}
 	}
 	if values, ok := args["args"].([]interface{}); ok {
-		args["args"] = applyContextToValue(values, ctx)
+		rendered, err := applyContextToValue(values, ctx, mode)
+		if err != nil {
+			return err
+		}
+		args["args"] = rendered
 	}
 	if _, ok := args["event_type"]; !ok && ctx.EventType != "" {
 		args["event_type"] = ctx.EventType
@@ -466,26 +614,35 @@ func applyContextToDBArgs(args map[string]interface{}, ctx *ExecutionContext) {
 			args["event_payload_json"] = payloadJSON
 		}
 	}
+	return nil
 }
 
-func applyContextToValue(value interface{}, ctx *ExecutionContext) interface{} {
+func applyContextToValue(value interface{}, ctx *ExecutionContext, mode string) (interface{}, error) {
 	switch typed := value.(type) {
 	case string:
-		return replaceEventTokens(typed, ctx)
+		return RenderTemplate(typed, ctx, mode)
 	case []interface{}:
 		copySlice := make([]interface{}, len(typed))
 		for i, item := range typed {
-			copySlice[i] = applyContextToValue(item, ctx)
+			rendered, err := applyContextToValue(item, ctx, mode)
+			if err != nil {
+				return nil, err
+			}
+			copySlice[i] = rendered
 		}
-		return copySlice
+		return copySlice, nil
 	case map[string]interface{}:
 		copyMap := make(map[string]interface{}, len(typed))
 		for k, item := range typed {
-			copyMap[k] = applyContextToValue(item, ctx)
+			rendered, err := applyContextToValue(item, ctx, mode)
+			if err != nil {
+				return nil, err
+			}
+			copyMap[k] = rendered
 		}
-		return copyMap
+		return copyMap, nil
 	default:
-		return typed
+		return typed, nil
 	}
 }
 
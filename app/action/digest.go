@@ -0,0 +1,330 @@
+package action
+
+import (
+	"badgermaps/database"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DigestRunType tags the SyncHistory rows that mark when each digest was
+// sent. The next digest for the same name covers runs from that point on.
+const DigestRunType = "digest"
+
+// defaultDigestLookback is how far back the first digest for a name looks.
+const defaultDigestLookback = 24 * time.Hour
+
+// DigestAction summarizes the sync runs since the previous digest with the
+// same Name: successes, failures with their first error line, and the
+// changes still waiting to be pushed. The digest is written to Path, mailed
+// to Email.To, or both, so each recipient gets its own digest action.
+type DigestAction struct {
+	Name string `yaml:"name"`
+	// Format is "text" (the default) or "csv".
+	Format string `yaml:"format,omitempty"`
+	// Path receives the digest; "{timestamp}" is replaced like in backups.
+	Path  string       `yaml:"path,omitempty"`
+	Email *DigestEmail `yaml:"email,omitempty"`
+	// FailuresOnly leaves successful runs out of the digest.
+	FailuresOnly bool `yaml:"failures_only,omitempty"`
+	// Lookback bounds the first digest for Name, e.g. "12h". Defaults to 24h.
+	Lookback string `yaml:"lookback,omitempty"`
+}
+
+// DigestEmail configures SMTP delivery. The password is read from the
+// environment variable named by PasswordEnv so it stays out of the config.
+type DigestEmail struct {
+	To          []string `yaml:"to"`
+	From        string   `yaml:"from"`
+	Subject     string   `yaml:"subject,omitempty"`
+	Host        string   `yaml:"smtp_host"`
+	Port        int      `yaml:"smtp_port,omitempty"`
+	Username    string   `yaml:"username,omitempty"`
+	PasswordEnv string   `yaml:"password_env,omitempty"`
+}
+
+// Digest is the content of one digest.
+type Digest struct {
+	Name           string
+	Since          time.Time
+	Until          time.Time
+	Succeeded      []database.SyncHistoryEntry
+	Failed         []database.SyncHistoryEntry
+	Running        []database.SyncHistoryEntry
+	PendingAccount int
+	PendingCheckin int
+}
+
+// Execute builds the digest, delivers it, and records it in SyncHistory.
+func (a *DigestAction) Execute(executor *Executor) error {
+	db := executor.DB
+	since := time.Now().UTC().Add(-a.lookback())
+	last, err := database.GetLastSyncHistoryRun(db, DigestRunType, a.Name, "completed")
+	if err != nil {
+		return fmt.Errorf("failed to find the previous digest: %w", err)
+	}
+	if last != nil {
+		since = last.StartedAt
+	}
+
+	// The marker row is written first so runs that start while the digest
+	// is being sent land in the next one.
+	marker := &database.SyncHistoryEntry{
+		CorrelationID: uuid.NewString(),
+		RunType:       DigestRunType,
+		Direction:     DigestRunType,
+		Source:        a.Name,
+		Initiator:     "scheduler",
+		Summary:       fmt.Sprintf("Building digest %s", a.Name),
+	}
+	if _, err := database.InsertSyncHistory(db, marker); err != nil {
+		return fmt.Errorf("failed to record digest: %w", err)
+	}
+	started := time.Now()
+
+	digest, err := BuildDigest(db, a.Name, since, a.FailuresOnly)
+	if err == nil {
+		err = a.deliver(digest)
+	}
+
+	status, summary, details := "completed", digest.Summary(), ""
+	if err != nil {
+		status, summary, details = "failed", fmt.Sprintf("Digest %s was not sent", a.Name), err.Error()
+	}
+	runs := len(digest.Succeeded) + len(digest.Failed) + len(digest.Running)
+	duration := int64(time.Since(started).Seconds())
+	if completeErr := database.CompleteSyncHistory(db, marker.CorrelationID, status, runs, len(digest.Failed), duration, summary, details); completeErr != nil && err == nil {
+		err = fmt.Errorf("digest sent but not recorded; the next digest will repeat these runs: %w", completeErr)
+	}
+	return err
+}
+
+// Validate checks if the action is configured correctly.
+func (a *DigestAction) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return fmt.Errorf("digest action requires a 'name'")
+	}
+	switch a.Format {
+	case "", "text", "csv":
+	default:
+		return fmt.Errorf("digest action 'format' must be \"text\" or \"csv\"")
+	}
+	if a.Lookback != "" {
+		if d, err := time.ParseDuration(a.Lookback); err != nil || d <= 0 {
+			return fmt.Errorf("digest action 'lookback' must be a positive duration like \"24h\"")
+		}
+	}
+	if strings.TrimSpace(a.Path) == "" && a.Email == nil {
+		return fmt.Errorf("digest action requires a 'path', an 'email', or both")
+	}
+	if a.Email != nil {
+		if len(a.Email.To) == 0 || a.Email.From == "" || a.Email.Host == "" {
+			return fmt.Errorf("digest email requires 'to', 'from', and 'smtp_host'")
+		}
+	}
+	return nil
+}
+
+func (a *DigestAction) lookback() time.Duration {
+	if d, err := time.ParseDuration(a.Lookback); err == nil && d > 0 {
+		return d
+	}
+	return defaultDigestLookback
+}
+
+// BuildDigest collects the runs started since since, other than digests,
+// and the number of changes still pending.
+func BuildDigest(db database.DB, name string, since time.Time, failuresOnly bool) (*Digest, error) {
+	digest := &Digest{Name: name, Since: since, Until: time.Now().UTC()}
+	entries, err := database.GetSyncHistorySince(db, since, DigestRunType)
+	if err != nil {
+		return digest, fmt.Errorf("failed to read sync history: %w", err)
+	}
+	for _, entry := range entries {
+		switch {
+		case entry.Status == "failed" || entry.Status == "completed_with_errors" || entry.ErrorCount > 0:
+			digest.Failed = append(digest.Failed, entry)
+		case entry.Status == "running":
+			digest.Running = append(digest.Running, entry)
+		case !failuresOnly:
+			digest.Succeeded = append(digest.Succeeded, entry)
+		}
+	}
+
+	accounts, err := database.GetPendingAccountChanges(db)
+	if err != nil {
+		return digest, fmt.Errorf("failed to count pending account changes: %w", err)
+	}
+	checkins, err := database.GetPendingCheckinChanges(db)
+	if err != nil {
+		return digest, fmt.Errorf("failed to count pending check-in changes: %w", err)
+	}
+	digest.PendingAccount = len(accounts)
+	digest.PendingCheckin = len(checkins)
+	return digest, nil
+}
+
+// Summary is the one-line form used for the email subject and SyncHistory.
+func (d *Digest) Summary() string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d succeeded, %d failed, %d pending changes",
+		len(d.Succeeded), len(d.Failed), d.PendingAccount+d.PendingCheckin)
+}
+
+// Text renders the digest for reading.
+func (d *Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BadgerMaps sync digest: %s\n", d.Name)
+	fmt.Fprintf(&b, "Runs from %s to %s\n", d.Since.Format("2006-01-02 15:04 MST"), d.Until.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "%s\n", d.Summary())
+
+	writeRuns := func(title string, entries []database.SyncHistoryEntry, withErrors bool) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d)\n", title, len(entries))
+		for _, e := range entries {
+			fmt.Fprintf(&b, "  %s  %-10s %-12s %s\n", e.StartedAt.Format("2006-01-02 15:04"), e.RunType, e.Source, e.Summary)
+			if e.Note != "" {
+				fmt.Fprintf(&b, "      Note: %s\n", e.Note)
+			}
+			if withErrors {
+				if line := firstLine(e.Details); line != "" {
+					fmt.Fprintf(&b, "      %s\n", line)
+				}
+			}
+		}
+	}
+	writeRuns("Failed", d.Failed, true)
+	writeRuns("Still running", d.Running, false)
+	writeRuns("Succeeded", d.Succeeded, false)
+
+	fmt.Fprintf(&b, "\nPending changes\n")
+	fmt.Fprintf(&b, "  Accounts:  %d\n", d.PendingAccount)
+	fmt.Fprintf(&b, "  Check-ins: %d\n", d.PendingCheckin)
+	return b.String()
+}
+
+// CSV renders one row per run followed by one row per pending change table.
+func (d *Digest) CSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"StartedAt", "RunType", "Source", "Status", "ItemsProcessed", "ErrorCount", "Summary", "FirstError", "Note"})
+	for _, group := range [][]database.SyncHistoryEntry{d.Failed, d.Running, d.Succeeded} {
+		for _, e := range group {
+			w.Write([]string{
+				e.StartedAt.UTC().Format(time.RFC3339), e.RunType, e.Source, e.Status,
+				strconv.Itoa(e.ItemsProcessed), strconv.Itoa(e.ErrorCount), e.Summary, firstLine(e.Details), e.Note,
+			})
+		}
+	}
+	w.Write([]string{"", "pending", "accounts", "pending", strconv.Itoa(d.PendingAccount), "0", "", "", ""})
+	w.Write([]string{"", "pending", "checkins", "pending", strconv.Itoa(d.PendingCheckin), "0", "", "", ""})
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func (a *DigestAction) deliver(d *Digest) error {
+	body := d.Text()
+	var attachment string
+	if a.Format == "csv" {
+		var err error
+		if attachment, err = d.CSV(); err != nil {
+			return err
+		}
+	}
+
+	if path := strings.TrimSpace(a.Path); path != "" {
+		path = strings.ReplaceAll(path, "{timestamp}", time.Now().Format("20060102-150405"))
+		content := body
+		if attachment != "" {
+			content = attachment
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write digest: %w", err)
+		}
+	}
+	if a.Email != nil {
+		msg, err := a.Email.message(d, body, attachment)
+		if err != nil {
+			return err
+		}
+		if err := a.Email.send(msg); err != nil {
+			return fmt.Errorf("failed to email digest: %w", err)
+		}
+	}
+	return nil
+}
+
+// message builds the email. A CSV digest is attached below the text one.
+func (e *DigestEmail) message(d *Digest, body, attachment string) ([]byte, error) {
+	subject := e.Subject
+	if subject == "" {
+		subject = fmt.Sprintf("BadgerMaps digest %s: %s", d.Name, d.Summary())
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	if attachment == "" {
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+		return msg.Bytes(), nil
+	}
+
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	text, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	text.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	file, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":        {"text/csv; charset=utf-8"},
+		"Content-Disposition": {fmt.Sprintf("attachment; filename=%q", "digest-"+d.Name+".csv")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	file.Write([]byte(attachment))
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	msg.Write(parts.Bytes())
+	return msg.Bytes(), nil
+}
+
+func (e *DigestEmail) send(msg []byte) error {
+	port := e.Port
+	if port == 0 {
+		port = 587
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, os.Getenv(e.PasswordEnv), e.Host)
+	}
+	return smtp.SendMail(fmt.Sprintf("%s:%d", e.Host, port), auth, e.From, e.To, msg)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
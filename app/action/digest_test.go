@@ -0,0 +1,104 @@
+package action_test
+
+import (
+	"badgermaps/app/action"
+	"badgermaps/database"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDigestActionCoversRunsSincePreviousDigest(t *testing.T) {
+	executor, teardown := setupTestExecutor(t)
+	defer teardown()
+	sqlDB := executor.DB.GetDB()
+
+	seed := []string{
+		`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, ItemsProcessed, ErrorCount, Summary, Details, StartedAt)
+		 VALUES ('a', 'pull', 'pull', 'accounts', 'manual', 'completed', 40, 0, 'Pulled 40 Accounts', '', datetime('now', '-2 hours'))`,
+		`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, ItemsProcessed, ErrorCount, Summary, Details, StartedAt)
+		 VALUES ('b', 'push', 'push', 'checkins', 'manual', 'failed', 0, 1, 'Push failed for Checkins', 'upstream returned 502
+stack line', datetime('now', '-1 hours'))`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (9, 'UPDATE', '{}')`,
+	}
+	for _, stmt := range seed {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "digest.txt")
+	digest := &action.DigestAction{Name: "ops", Path: out}
+	if err := digest.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := digest.Execute(executor); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{"1 succeeded, 1 failed, 1 pending changes", "upstream returned 502", "Pulled 40 Accounts", "Accounts:  1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("digest missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "stack line") {
+		t.Fatalf("digest should only keep the first error line:\n%s", text)
+	}
+
+	last, err := database.GetLastSyncHistoryRun(executor.DB, action.DigestRunType, "ops", "completed")
+	if err != nil || last == nil {
+		t.Fatalf("GetLastSyncHistoryRun = %v, %v", last, err)
+	}
+
+	// Older runs were covered by the first digest, so the next one is empty.
+	next, err := action.BuildDigest(executor.DB, "ops", last.StartedAt, false)
+	if err != nil {
+		t.Fatalf("BuildDigest: %v", err)
+	}
+	if len(next.Succeeded)+len(next.Failed) != 0 {
+		t.Fatalf("second digest repeated runs: %+v", next)
+	}
+}
+
+func TestDigestActionCSVFailuresOnly(t *testing.T) {
+	executor, teardown := setupTestExecutor(t)
+	defer teardown()
+	if _, err := executor.DB.GetDB().Exec(`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, ItemsProcessed, ErrorCount, Summary, Details)
+		VALUES ('a', 'pull', 'pull', 'accounts', 'manual', 'completed', 40, 0, 'Pulled 40 Accounts', '')`); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "digest.csv")
+	digest := &action.DigestAction{Name: "managers", Path: out, Format: "csv", FailuresOnly: true}
+	if err := digest.Execute(executor); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "StartedAt,RunType") || !strings.Contains(lines[1], "pending,accounts") {
+		t.Fatalf("csv digest = %q", data)
+	}
+}
+
+func TestDigestActionValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		action action.DigestAction
+	}{
+		{"no name", action.DigestAction{Path: "x"}},
+		{"no destination", action.DigestAction{Name: "ops"}},
+		{"bad format", action.DigestAction{Name: "ops", Path: "x", Format: "pdf"}},
+		{"bad lookback", action.DigestAction{Name: "ops", Path: "x", Lookback: "yesterday"}},
+		{"incomplete email", action.DigestAction{Name: "ops", Email: &action.DigestEmail{To: []string{"a@example.com"}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.action.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StageFunc stages an UPDATE of fields on an account as a pending change,
+// under idempotencyKey when it is set. The app provides it, with the checks
+// `push stage` makes, so staged changes are pushed like any other edit.
+type StageFunc func(accountID int, fields map[string]string, idempotencyKey string) error
+
+// dateOffsetPattern matches follow-up values such as "+14d" or "+2w".
+var dateOffsetPattern = regexp.MustCompile(`^([+-]\d+)([dw])$`)
+
+// FollowUpAction stages an account update after a check-in, such as a
+// follow-up date two weeks out, so the automation reaches BadgerMaps on the
+// next push. It is meant for the webhook.checkin event.
+type FollowUpAction struct {
+	// Account is the account to update. It defaults to the event's
+	// AccountID and may be a template.
+	Account string `yaml:"account,omitempty"`
+	// Set maps account fields, by API name (follow_up_date) or column
+	// (FollowUpDate), to their values. Values are templates, and a value
+	// like "+14d" or "+2w" is the date that many days or weeks after the
+	// check-in, or after today when the event has no check-in time.
+	Set       map[string]string `yaml:"set"`
+	Templates string            `yaml:"templates,omitempty"`
+}
+
+// Execute computes the values and stages them.
+func (a *FollowUpAction) Execute(executor *Executor) error {
+	if executor == nil || executor.Stage == nil {
+		return fmt.Errorf("followup action cannot stage changes here")
+	}
+	ctx := executor.Context()
+	accountID, err := a.accountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	base := time.Now()
+	if text, ok := ctx.PayloadFieldString("LogDatetime"); ok && text != "" {
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			base = t
+		}
+	}
+	fields := make(map[string]string, len(a.Set))
+	for name, value := range a.Set {
+		if fields[name], err = followUpValue(value, base, ctx, a.Templates); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	key := ""
+	if checkinID, ok := ctx.PayloadFieldString("CheckinID"); ok && checkinID != "" && checkinID != "0" {
+		key = a.idempotencyKey(checkinID)
+	}
+	executor.record().Args = map[string]interface{}{"account_id": accountID, "fields": fields, "idempotency_key": key}
+	return executor.Stage(accountID, fields, key)
+}
+
+// Validate checks if the action is configured correctly.
+func (a *FollowUpAction) Validate() error {
+	if len(a.Set) == 0 {
+		return fmt.Errorf("followup action requires fields to 'set'")
+	}
+	for name, value := range a.Set {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("followup action 'set' has an empty field name")
+		}
+		if strings.HasPrefix(value, "+") && !dateOffsetPattern.MatchString(value) {
+			return fmt.Errorf("followup action value %q for %s must be a date offset like \"+14d\" or \"+2w\"", value, name)
+		}
+	}
+	if err := ValidateTemplateMode(a.Templates); err != nil {
+		return fmt.Errorf("followup action %w", err)
+	}
+	return nil
+}
+
+func (a *FollowUpAction) accountID(ctx *ExecutionContext) (int, error) {
+	text, ok := ctx.PayloadFieldString("AccountID")
+	if a.Account != "" {
+		rendered, err := RenderTemplate(a.Account, ctx, a.Templates)
+		if err != nil {
+			return 0, err
+		}
+		text, ok = rendered, true
+	}
+	if !ok || strings.TrimSpace(text) == "" {
+		return 0, fmt.Errorf("followup action has no account; set 'account' or run it on webhook.checkin")
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("followup action account %q is not an account id", text)
+	}
+	return id, nil
+}
+
+// idempotencyKey ties the change to the check-in and the fields this action
+// sets, so a replayed webhook stages nothing new while two follow-up
+// actions on the same check-in stay apart.
+func (a *FollowUpAction) idempotencyKey(checkinID string) string {
+	names := make([]string, 0, len(a.Set))
+	for name := range a.Set {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	sum := sha256.Sum256([]byte(strings.Join(names, ",")))
+	return "followup-" + checkinID + "-" + hex.EncodeToString(sum[:6])
+}
+
+// followUpValue renders value, turning a date offset into the date that
+// far from base.
+func followUpValue(value string, base time.Time, ctx *ExecutionContext, mode string) (string, error) {
+	if m := dateOffsetPattern.FindStringSubmatch(strings.TrimSpace(value)); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return "", err
+		}
+		if m[2] == "w" {
+			n *= 7
+		}
+		return base.AddDate(0, 0, n).Format("2006-01-02"), nil
+	}
+	return RenderTemplate(value, ctx, mode)
+}
@@ -0,0 +1,67 @@
+package action_test
+
+import (
+	"badgermaps/app/action"
+	"badgermaps/events"
+	"strings"
+	"testing"
+)
+
+func TestFollowUpAction(t *testing.T) {
+	type staged struct {
+		account int
+		fields  map[string]string
+		key     string
+	}
+	var got []staged
+	executor := action.NewExecutor(nil, nil)
+	executor.Stage = func(accountID int, fields map[string]string, key string) error {
+		got = append(got, staged{accountID, fields, key})
+		return nil
+	}
+	ctx := &action.ExecutionContext{EventType: "webhook.checkin", Source: "webhook", Payload: events.CheckinWebhookPayload{
+		CheckinID:   77,
+		AccountID:   123,
+		Type:        "Visit",
+		LogDatetime: "2024-05-01T09:30:00Z",
+	}}
+	followUp := &action.FollowUpAction{Set: map[string]string{
+		"follow_up_date": "+2w",
+		"CustomText1":    "Last {{payload.Type}}",
+	}}
+	if err := followUp.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := followUp.Execute(executor.WithContext(ctx)); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+	if len(got) != 2 || got[0].account != 123 {
+		t.Fatalf("staged = %+v", got)
+	}
+	if f := got[0].fields; f["follow_up_date"] != "2024-05-15" || f["CustomText1"] != "Last Visit" {
+		t.Errorf("fields = %v", f)
+	}
+	if key := got[0].key; !strings.HasPrefix(key, "followup-77-") || key != got[1].key {
+		t.Errorf("keys = %q, %q; want the same key for the same check-in", key, got[1].key)
+	}
+
+	other := &action.FollowUpAction{Account: "{{payload.CheckinID}}", Set: map[string]string{"notes": "x"}}
+	if err := other.Execute(executor.WithContext(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if last := got[len(got)-1]; last.account != 77 || last.key == got[0].key {
+		t.Errorf("account override staged %+v", last)
+	}
+
+	if err := followUp.Execute(action.NewExecutor(nil, nil).WithContext(ctx)); err == nil {
+		t.Error("expected an error without a stage function")
+	}
+	if err := (&action.FollowUpAction{Set: map[string]string{"follow_up_date": "+14 days"}}).Validate(); err == nil {
+		t.Error("expected an invalid offset to fail validation")
+	}
+	if err := (&action.FollowUpAction{}).Validate(); err == nil {
+		t.Error("expected an action without fields to fail validation")
+	}
+}
@@ -0,0 +1,197 @@
+package action
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Template modes control what happens when a {{...}} expression cannot be
+// resolved against the event. Lenient substitutes an empty string; strict
+// fails the action before it runs.
+const (
+	TemplateModeLenient = "lenient"
+	TemplateModeStrict  = "strict"
+)
+
+// ValidateTemplateMode reports whether mode is a known template mode. An
+// empty mode means lenient.
+func ValidateTemplateMode(mode string) error {
+	switch mode {
+	case "", TemplateModeLenient, TemplateModeStrict:
+		return nil
+	}
+	return fmt.Errorf("templates must be %q or %q", TemplateModeLenient, TemplateModeStrict)
+}
+
+var templateExprPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// RenderTemplate expands {{...}} path expressions and the legacy $EVENT_*
+// tokens in input. Expressions are JSONPath-style paths rooted at "payload",
+// "event" (the type/source/payload envelope), or "$" (same as event), e.g.
+// {{payload.account.locations[0].city}} or {{$.payload["full_name"]}}.
+// Negative indexes count from the end of an array.
+func RenderTemplate(input string, ctx *ExecutionContext, mode string) (string, error) {
+	strict := mode == TemplateModeStrict
+	var errs []string
+	result := templateExprPattern.ReplaceAllStringFunc(input, func(match string) string {
+		expr := strings.TrimSpace(templateExprPattern.FindStringSubmatch(match)[1])
+		value, err := ctx.evaluatePath(expr)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("{{%s}}: %v", expr, err))
+			return ""
+		}
+		text, _ := stringifyValue(value)
+		return text
+	})
+	if strict && len(errs) > 0 {
+		return "", fmt.Errorf("unresolved template expressions: %s", strings.Join(errs, "; "))
+	}
+	return replaceEventTokens(result, ctx), nil
+}
+
+// evaluatePath resolves a template expression against the event.
+func (c *ExecutionContext) evaluatePath(expr string) (interface{}, error) {
+	if c == nil {
+		return nil, fmt.Errorf("no event context")
+	}
+	steps, err := parseTemplatePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	var current interface{}
+	switch steps[0].key {
+	case "payload":
+		current = c.payloadRoot()
+	case "event", "$":
+		current = map[string]interface{}{
+			"type":    c.EventType,
+			"source":  c.Source,
+			"payload": c.payloadRoot(),
+		}
+	default:
+		return nil, fmt.Errorf("expression must start with payload, event, or $")
+	}
+
+	walked := steps[0].key
+	for _, step := range steps[1:] {
+		next, ok := step.apply(current)
+		if !ok {
+			return nil, fmt.Errorf("%s has no %s", walked, step)
+		}
+		current = next
+		walked += step.String()
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%s is null", walked)
+	}
+	return current, nil
+}
+
+// templateStep is one field access or array index in a template path.
+type templateStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s templateStep) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return "." + s.key
+}
+
+func (s templateStep) apply(value interface{}) (interface{}, bool) {
+	if s.isIndex {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		idx := s.index
+		if idx < 0 {
+			idx += len(list)
+		}
+		if idx < 0 || idx >= len(list) {
+			return nil, false
+		}
+		return list[idx], true
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if v, ok := fields[s.key]; ok {
+		return v, true
+	}
+	// Payload structs marshal with Go field names, so allow payload.data.id
+	// to match a "Data" field.
+	for k, v := range fields {
+		if strings.EqualFold(k, s.key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// parseTemplatePath splits a path such as payload.items[0]["full name"] into
+// steps.
+func parseTemplatePath(expr string) ([]templateStep, error) {
+	var steps []templateStep
+	i := 0
+	readKey := func() string {
+		start := i
+		for i < len(expr) && expr[i] != '.' && expr[i] != '[' {
+			i++
+		}
+		return strings.TrimSpace(expr[start:i])
+	}
+
+	if strings.HasPrefix(expr, "$") {
+		steps = append(steps, templateStep{key: "$"})
+		i = 1
+	} else {
+		root := readKey()
+		if root == "" {
+			return nil, fmt.Errorf("empty expression")
+		}
+		steps = append(steps, templateStep{key: root})
+	}
+
+	for i < len(expr) {
+		switch expr[i] {
+		case '.':
+			i++
+			key := readKey()
+			if key == "" {
+				return nil, fmt.Errorf("empty field name at offset %d", i)
+			}
+			steps = append(steps, templateStep{key: key})
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ at offset %d", i)
+			}
+			inner := strings.TrimSpace(expr[i+1 : i+end])
+			i += end + 1
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, templateStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", inner)
+			}
+			steps = append(steps, templateStep{index: idx, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", expr[i], i)
+		}
+	}
+	return steps, nil
+}
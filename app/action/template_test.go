@@ -0,0 +1,75 @@
+package action_test
+
+import (
+	"badgermaps/app/action"
+	"strings"
+	"testing"
+)
+
+type templateTestPayload struct {
+	Data map[string]interface{}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	ctx := &action.ExecutionContext{
+		EventType: "pull.store.success",
+		Source:    "accounts",
+		Payload: templateTestPayload{Data: map[string]interface{}{
+			"full_name": "Ada Lovelace",
+			"locations": []interface{}{
+				map[string]interface{}{"city": "London"},
+				map[string]interface{}{"city": "Paris"},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"nested field and index", "{{payload.Data.locations[0].city}}", "London"},
+		{"case-insensitive field", "{{ payload.data.locations[-1].city }}", "Paris"},
+		{"quoted key from envelope root", `{{$.payload.Data["full_name"]}}`, "Ada Lovelace"},
+		{"event metadata", "{{event.type}}/{{event.source}}", "pull.store.success/accounts"},
+		{"object as json", "{{payload.Data.locations[1]}}", `{"city":"Paris"}`},
+		{"mixed with legacy tokens", "$EVENT_SOURCE:{{payload.Data.full_name}}", "accounts:Ada Lovelace"},
+		{"missing path is empty when lenient", "[{{payload.Data.phone}}]", "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := action.RenderTemplate(tt.input, ctx, action.TemplateModeLenient)
+			if err != nil {
+				t.Fatalf("RenderTemplate returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("RenderTemplate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"{{payload.Data.phone}}", "{{payload.Data.locations[5]}}", "{{account.id}}", "{{payload.Data[}}"} {
+		if _, err := action.RenderTemplate(input, ctx, action.TemplateModeStrict); err == nil {
+			t.Errorf("expected strict mode to reject %q", input)
+		}
+	}
+}
+
+func TestExecActionStrictTemplateFailsBeforeRunning(t *testing.T) {
+	executor, teardown := setupTestExecutor(t)
+	defer teardown()
+
+	execAction := &action.ExecAction{
+		Command:   "echo {{payload.missing}}",
+		Templates: action.TemplateModeStrict,
+	}
+	ctx := &action.ExecutionContext{EventType: "pull.complete", Payload: map[string]interface{}{}}
+	err := execAction.Execute(executor.WithContext(ctx))
+	if err == nil || !strings.Contains(err.Error(), "payload.missing") {
+		t.Fatalf("expected unresolved template error, got %v", err)
+	}
+
+	if err := (&action.ExecAction{Command: "echo", Templates: "loose"}).Validate(); err == nil {
+		t.Fatalf("expected unknown template mode to fail validation")
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"badgermaps/app/action"
+	"badgermaps/app/server"
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/telemetry"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Triggers recorded for actions not started by an event.
+const (
+	ActionTriggerManual = "manual"
+	ActionTriggerCron   = server.CronTrigger
+	ActionTriggerRerun  = "rerun"
+)
+
+// runAction executes a prepared action, records the run in ActionRuns, and
+// returns the record with the action's error.
+func (a *App) runAction(actionInstance action.Action, executor *action.Executor, actionConfig action.ActionConfig, execCtx *action.ExecutionContext, name, trigger string, rerunOf int) (database.ActionRun, error) {
+	logSource := resolveActionLogSource(execCtx)
+	actionType := actionConfig.Type
+	result := &action.Result{}
+	started := time.Now()
+
+	_, span := telemetry.Start(context.Background(), "action.execute", telemetry.ActionType.String(actionType))
+	err := actionInstance.Execute(executor.WithResult(result))
+	telemetry.End(span, err)
+	if err != nil {
+		a.Events.Dispatch(events.Errorf(logSource, "action '%s' failed: %v", actionType, err))
+	} else {
+		a.Events.Dispatch(events.Debugf(logSource, "Action '%s' completed successfully", actionType))
+	}
+
+	run := database.ActionRun{
+		ActionName:   name,
+		ActionType:   actionType,
+		TriggeredBy:  trigger,
+		Status:       database.ActionRunSuccess,
+		ExitCode:     result.ExitCode,
+		Output:       result.Output,
+		RowsAffected: result.RowsAffected,
+		RerunOf:      rerunOf,
+		StartedAt:    started,
+		Duration:     time.Since(started),
+	}
+	if err != nil {
+		run.Status = database.ActionRunFailed
+		run.ErrorMessage = err.Error()
+	}
+	if config, jsonErr := json.Marshal(actionConfig); jsonErr == nil {
+		run.Config = string(config)
+	}
+	args := result.Args
+	if args == nil {
+		args = actionConfig.Args
+	}
+	if len(args) > 0 {
+		if data, jsonErr := json.Marshal(args); jsonErr == nil {
+			run.Args = string(data)
+		}
+	}
+	if execCtx != nil {
+		run.Source = execCtx.Source
+		if execCtx.EventType != "" {
+			// Payloads that cannot be encoded are left out; the run is
+			// still recorded.
+			run.Event, _ = execCtx.EventJSON()
+		}
+	}
+	if a.DB != nil && a.DB.IsConnected() {
+		if recordErr := database.InsertActionRun(a.DB, run); recordErr != nil {
+			a.Events.Dispatch(events.Warningf(logSource, "Failed to record the run of action '%s': %v", actionType, recordErr))
+		}
+	}
+	return run, err
+}
+
+// ActionRuns returns the latest runs of the action named name, newest
+// first, or of every action when name is empty.
+func (a *App) ActionRuns(name string, limit int) ([]database.ActionRun, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	return database.GetActionRuns(a.DB, name, limit)
+}
+
+// RerunAction runs the action recorded as id again with the same config
+// and triggering event, waits for it, and returns the new run. The config
+// is the one recorded, so later edits to the action do not apply.
+func (a *App) RerunAction(id int) (database.ActionRun, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return database.ActionRun{}, fmt.Errorf("database is not connected")
+	}
+	previous, err := database.GetActionRun(a.DB, id)
+	if err != nil {
+		return database.ActionRun{}, err
+	}
+	if previous == nil {
+		return database.ActionRun{}, fmt.Errorf("no action run with ID %d", id)
+	}
+	var actionConfig action.ActionConfig
+	if err := json.Unmarshal([]byte(previous.Config), &actionConfig); err != nil || actionConfig.Type == "" {
+		return database.ActionRun{}, fmt.Errorf("run %d has no recorded action config to run again", id)
+	}
+	var execCtx *action.ExecutionContext
+	if previous.Event != "" {
+		var event struct {
+			Type    string      `json:"type"`
+			Source  string      `json:"source"`
+			Payload interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(previous.Event), &event); err != nil {
+			return database.ActionRun{}, fmt.Errorf("run %d has an unreadable event: %w", id, err)
+		}
+		execCtx = &action.ExecutionContext{EventType: event.Type, Source: event.Source, Payload: event.Payload}
+	}
+
+	actionInstance, executor, err := a.prepareAction(actionConfig, execCtx)
+	if err != nil {
+		return database.ActionRun{}, err
+	}
+	a.Events.Dispatch(events.Infof("action", "Re-running action run %d (%s)", id, actionConfig.Type))
+	return a.runAction(actionInstance, executor, actionConfig, execCtx, previous.ActionName, ActionTriggerRerun, id)
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"badgermaps/app/action"
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func waitForActionRuns(t *testing.T, a *App, name string, n int) []database.ActionRun {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runs, err := a.ActionRuns(name, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) >= n {
+			return runs
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d runs of %q recorded, want %d", len(runs), name, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestActionRunHistory(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "runs.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName) VALUES (1, 'A'), (2, 'B')`); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := action.ActionConfig{Type: "exec", Args: map[string]interface{}{"command": "echo {{event.source}}"}}
+	ctx := &action.ExecutionContext{EventType: "pull.complete", Source: "accounts", Payload: map[string]interface{}{"count": 2}}
+	if err := a.ExecuteNamedAction("notify", "", exec, ctx); err != nil {
+		t.Fatal(err)
+	}
+	run := waitForActionRuns(t, a, "notify", 1)[0]
+	if run.Status != database.ActionRunSuccess || run.TriggeredBy != "pull.complete" || run.Source != "accounts" ||
+		run.ExitCode == nil || *run.ExitCode != 0 || strings.TrimSpace(run.Output) != "accounts" ||
+		!strings.Contains(run.Args, `"echo accounts"`) || !strings.Contains(run.Event, `"count":2`) {
+		t.Fatalf("recorded run = %+v", run)
+	}
+
+	rerun, err := a.RerunAction(run.RunId)
+	if err != nil {
+		t.Fatalf("RerunAction: %v", err)
+	}
+	if rerun.TriggeredBy != ActionTriggerRerun || rerun.RerunOf != run.RunId || strings.TrimSpace(rerun.Output) != "accounts" {
+		t.Errorf("re-run = %+v", rerun)
+	}
+	waitForActionRuns(t, a, "notify", 2)
+
+	query := action.ActionConfig{Type: "db", Args: map[string]interface{}{"query": "UPDATE Accounts SET FirstName = 'x'"}}
+	if err := a.ExecuteNamedAction("touch", ActionTriggerManual, query, nil); err != nil {
+		t.Fatal(err)
+	}
+	run = waitForActionRuns(t, a, "touch", 1)[0]
+	if run.TriggeredBy != ActionTriggerManual || run.RowsAffected == nil || *run.RowsAffected != 2 || run.Event != "" {
+		t.Errorf("db run = %+v", run)
+	}
+
+	failing := action.ActionConfig{Type: "exec", Args: map[string]interface{}{"command": "echo oops; exit 3"}}
+	if err := a.ExecuteNamedAction("fail", "", failing, nil); err != nil {
+		t.Fatal(err)
+	}
+	run = waitForActionRuns(t, a, "fail", 1)[0]
+	if run.Status != database.ActionRunFailed || run.ExitCode == nil || *run.ExitCode != 3 || run.ErrorMessage == "" {
+		t.Errorf("failed run = %+v", run)
+	}
+}
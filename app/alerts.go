@@ -0,0 +1,241 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rules of the alert thresholds, used as AlertPayload.Rule.
+const (
+	AlertPushErrorRate = "push_error_rate"
+	AlertPullStale     = "pull_stale"
+)
+
+// Defaults of AlertsConfig.
+const (
+	DefaultAlertCheckInterval   = 5 * time.Minute
+	DefaultAlertPushErrorWindow = 24 * time.Hour
+	DefaultAlertPushMinChanges  = 10
+)
+
+// AlertsConfig sets the error budget the alert monitor checks. A threshold
+// left unset is not checked.
+type AlertsConfig struct {
+	// PushErrorRate alerts when more than this percentage of the changes
+	// pushed within PushErrorWindow failed, e.g. 5.
+	PushErrorRate   float64 `yaml:"push_error_rate,omitempty"`
+	PushErrorWindow string  `yaml:"push_error_window,omitempty"`
+	// PushMinChanges is how many changes the window must hold before the
+	// rate is checked, so one failure out of two does not alert.
+	PushMinChanges int `yaml:"push_min_changes,omitempty"`
+	// PullMaxAge alerts when no pull has completed within it, e.g. "24h".
+	PullMaxAge    string `yaml:"pull_max_age,omitempty"`
+	CheckInterval string `yaml:"check_interval,omitempty"`
+}
+
+// Validate checks the rate and the durations.
+func (c AlertsConfig) Validate() error {
+	if c.PushErrorRate < 0 || c.PushErrorRate > 100 {
+		return fmt.Errorf("alerts.push_error_rate must be a percentage between 0 and 100")
+	}
+	if c.PushMinChanges < 0 {
+		return fmt.Errorf("alerts.push_min_changes must not be negative")
+	}
+	for name, value := range map[string]string{
+		"push_error_window": c.PushErrorWindow,
+		"pull_max_age":      c.PullMaxAge,
+		"check_interval":    c.CheckInterval,
+	} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("alerts.%s must be a positive duration such as \"24h\", got %q", name, value)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether any threshold is set.
+func (c AlertsConfig) Enabled() bool {
+	return c.PushErrorRate > 0 || c.PullMaxAge != ""
+}
+
+// Interval returns how often the monitor checks the thresholds.
+func (c AlertsConfig) Interval() time.Duration {
+	return durationOr(c.CheckInterval, DefaultAlertCheckInterval)
+}
+
+func (c AlertsConfig) pushWindow() time.Duration {
+	return durationOr(c.PushErrorWindow, DefaultAlertPushErrorWindow)
+}
+
+func (c AlertsConfig) pushMinChanges() int {
+	if c.PushMinChanges > 0 {
+		return c.PushMinChanges
+	}
+	return DefaultAlertPushMinChanges
+}
+
+func durationOr(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// EvaluateAlerts returns the thresholds crossed at now, from the runs in
+// SyncHistory. Since is left for the caller to fill.
+func (a *App) EvaluateAlerts(now time.Time) ([]events.AlertPayload, error) {
+	if a.Config == nil || !a.Config.Alerts.Enabled() || a.Config.Alerts.Validate() != nil {
+		return nil, nil
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	config := a.Config.Alerts
+	var alerts []events.AlertPayload
+
+	if config.PushErrorRate > 0 {
+		window := config.pushWindow()
+		runs, err := database.GetSyncHistorySince(a.DB, now.Add(-window), "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read push history: %w", err)
+		}
+		var pushed, failed int
+		for _, run := range runs {
+			if run.RunType != "push" {
+				continue
+			}
+			errorCount := max(run.ErrorCount, 0)
+			// A push that failed before its changes were counted still
+			// spends the budget.
+			if run.Status == "failed" && errorCount == 0 {
+				errorCount = 1
+			}
+			failed += errorCount
+			pushed += max(run.ItemsProcessed, 0) + errorCount
+		}
+		if pushed >= config.pushMinChanges() {
+			rate := float64(failed) / float64(pushed) * 100
+			if rate > config.PushErrorRate {
+				alerts = append(alerts, events.AlertPayload{
+					Rule:      AlertPushErrorRate,
+					Message:   fmt.Sprintf("%.1f%% of pushed changes failed in the last %s (%d of %d); the threshold is %g%%", rate, window, failed, pushed, config.PushErrorRate),
+					Value:     rate,
+					Threshold: config.PushErrorRate,
+				})
+			}
+		}
+	}
+
+	if config.PullMaxAge != "" {
+		maxAge := durationOr(config.PullMaxAge, 0)
+		runs, err := database.GetSyncHistorySince(a.DB, now.Add(-maxAge), "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pull history: %w", err)
+		}
+		succeeded := false
+		for _, run := range runs {
+			if run.RunType == "pull" && (run.Status == "completed" || run.Status == "completed_with_errors") {
+				succeeded = true
+				break
+			}
+		}
+		if !succeeded {
+			alerts = append(alerts, events.AlertPayload{
+				Rule:      AlertPullStale,
+				Message:   fmt.Sprintf("No pull has completed in the last %s", maxAge),
+				Value:     maxAge.Hours(),
+				Threshold: maxAge.Hours(),
+			})
+		}
+	}
+	return alerts, nil
+}
+
+// CheckAlerts evaluates the thresholds and dispatches alert.fired for each
+// newly crossed one and alert.resolved for each one no longer crossed. It
+// returns the alerts still firing.
+func (a *App) CheckAlerts(now time.Time) ([]events.AlertPayload, error) {
+	firing, err := a.EvaluateAlerts(now)
+	if err != nil {
+		return a.ActiveAlerts(), err
+	}
+
+	var fired []events.AlertPayload
+	var resolved []events.AlertResolvedPayload
+	a.alertsMu.Lock()
+	next := make(map[string]events.AlertPayload, len(firing))
+	for _, alert := range firing {
+		if active, ok := a.alerts[alert.Rule]; ok {
+			alert.Since = active.Since
+		} else {
+			alert.Since = now
+			fired = append(fired, alert)
+		}
+		next[alert.Rule] = alert
+	}
+	for rule, active := range a.alerts {
+		if _, ok := next[rule]; !ok {
+			resolved = append(resolved, events.AlertResolvedPayload(active))
+		}
+	}
+	a.alerts = next
+	a.alertsMu.Unlock()
+
+	for _, alert := range fired {
+		a.Events.Dispatch(events.Event{Type: alert.EventType(), Source: "alerts", Payload: alert})
+		a.Events.Dispatch(events.Errorf("alerts", "Alert: %s", alert.Message))
+	}
+	for _, alert := range resolved {
+		a.Events.Dispatch(events.Event{Type: alert.EventType(), Source: "alerts", Payload: alert})
+		a.Events.Dispatch(events.Infof("alerts", "Alert resolved: %s", alert.Rule))
+	}
+	return a.ActiveAlerts(), nil
+}
+
+// ActiveAlerts returns the alerts firing as of the last check, oldest
+// first.
+func (a *App) ActiveAlerts() []events.AlertPayload {
+	a.alertsMu.Lock()
+	defer a.alertsMu.Unlock()
+	alerts := make([]events.AlertPayload, 0, len(a.alerts))
+	for _, alert := range a.alerts {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].Since.Equal(alerts[j].Since) {
+			return alerts[i].Since.Before(alerts[j].Since)
+		}
+		return alerts[i].Rule < alerts[j].Rule
+	})
+	return alerts
+}
+
+// StartAlertMonitor checks the alert thresholds now and then every
+// alerts.check_interval until stop is closed. The interval and thresholds
+// are read at each check, so a config reload applies to the next one.
+func (a *App) StartAlertMonitor(stop <-chan struct{}) {
+	config := func() AlertsConfig {
+		if a.Config == nil {
+			return AlertsConfig{}
+		}
+		return a.Config.Alerts
+	}
+	go func() {
+		for {
+			if _, err := a.CheckAlerts(time.Now()); err != nil && config().Enabled() {
+				a.Events.Dispatch(events.Debugf("alerts", "Could not check alert thresholds: %v", err))
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(config().Interval()):
+			}
+		}
+	}()
+}
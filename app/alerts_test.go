@@ -0,0 +1,112 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+func TestAlertsConfigValidate(t *testing.T) {
+	valid := AlertsConfig{PushErrorRate: 5, PushErrorWindow: "12h", PullMaxAge: "24h", CheckInterval: "1m"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate(%+v) = %v", valid, err)
+	}
+	for _, invalid := range []AlertsConfig{
+		{PushErrorRate: -1},
+		{PushErrorRate: 101},
+		{PushMinChanges: -1},
+		{PullMaxAge: "a day"},
+		{CheckInterval: "0s"},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", invalid)
+		}
+	}
+	if (AlertsConfig{}).Enabled() {
+		t.Error("empty config should not be enabled")
+	}
+}
+
+func TestCheckAlerts(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "alerts.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	a.Config.Alerts = AlertsConfig{PushErrorRate: 5, PushMinChanges: 10, PullMaxAge: "24h"}
+
+	received := make(chan events.Event, 4)
+	a.Events.Subscribe("alert.*", func(e events.Event) { received <- e })
+
+	insert := func(id, runType, status string, items, errorCount int) {
+		t.Helper()
+		entry := &database.SyncHistoryEntry{CorrelationID: id, RunType: runType, Status: status, ItemsProcessed: items, ErrorCount: errorCount}
+		if _, err := database.InsertSyncHistory(db, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 of 20 pushed changes failed and no pull has completed.
+	insert("push-1", "push", "completed_with_errors", 18, 2)
+	insert("sandbox-1", SandboxRunType, "failed", 0, 50)
+	insert("pull-1", "pull", "failed", 0, 1)
+
+	now := time.Now()
+	alerts, err := a.CheckAlerts(now)
+	if err != nil {
+		t.Fatalf("CheckAlerts: %v", err)
+	}
+	if len(alerts) != 2 || alerts[0].Rule != AlertPullStale || alerts[1].Rule != AlertPushErrorRate {
+		t.Fatalf("alerts = %+v, want pull_stale and push_error_rate", alerts)
+	}
+	if alerts[1].Value != 10 || !alerts[0].Since.Equal(now) {
+		t.Errorf("push error rate = %v since %v, want 10 since %v", alerts[1].Value, alerts[0].Since, now)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-received:
+			if e.Type != "alert.fired" {
+				t.Errorf("event %s, want alert.fired", e.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("alert.fired was not dispatched")
+		}
+	}
+
+	// A completed pull resolves its alert; the push alert keeps its start.
+	insert("pull-2", "pull", "completed", 5, 0)
+	later := now.Add(time.Minute)
+	alerts, err = a.CheckAlerts(later)
+	if err != nil {
+		t.Fatalf("CheckAlerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Rule != AlertPushErrorRate || !alerts[0].Since.Equal(now) {
+		t.Fatalf("alerts = %+v, want push_error_rate since the first check", alerts)
+	}
+	select {
+	case e := <-received:
+		if payload, ok := e.Payload.(events.AlertResolvedPayload); !ok || payload.Rule != AlertPullStale {
+			t.Errorf("event %s %+v, want pull_stale resolved", e.Type, e.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("alert.resolved was not dispatched")
+	}
+
+	// Below the minimum number of changes the rate is not checked.
+	a.Config.Alerts.PushMinChanges = 50
+	if alerts, _ := a.CheckAlerts(later); len(alerts) != 0 {
+		t.Errorf("alerts = %+v, want none", alerts)
+	}
+}
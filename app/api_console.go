@@ -0,0 +1,182 @@
+package app
+
+import (
+	"badgermaps/api"
+	"badgermaps/events"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// APIRequestTemplate is a request saved from the API console so it can be
+// sent again by name.
+type APIRequestTemplate struct {
+	Name   string            `yaml:"name"`
+	Method string            `yaml:"method"`
+	Path   string            `yaml:"path"`
+	Query  map[string]string `yaml:"query,omitempty"`
+	Form   map[string]string `yaml:"form,omitempty"`
+}
+
+// Request returns the console request the template describes.
+func (t APIRequestTemplate) Request() api.ConsoleRequest {
+	return api.ConsoleRequest{
+		Method: t.Method,
+		Path:   t.Path,
+		Query:  valuesFromMap(t.Query),
+		Form:   valuesFromMap(t.Form),
+	}
+}
+
+// NewAPIRequestTemplate saves req under name.
+func NewAPIRequestTemplate(name string, req api.ConsoleRequest) APIRequestTemplate {
+	return APIRequestTemplate{
+		Name:   strings.TrimSpace(name),
+		Method: strings.ToUpper(strings.TrimSpace(req.Method)),
+		Path:   strings.TrimSpace(req.Path),
+		Query:  mapFromValues(req.Query),
+		Form:   mapFromValues(req.Form),
+	}
+}
+
+// Validate checks the template has a name, a console method, and a path
+// relative to the API URL.
+func (t APIRequestTemplate) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("an API template needs a name")
+	}
+	method := strings.ToUpper(t.Method)
+	valid := false
+	for _, m := range api.ConsoleMethods {
+		valid = valid || m == method
+	}
+	if !valid {
+		return fmt.Errorf("API template %s: method must be one of %s", t.Name, strings.Join(api.ConsoleMethods, ", "))
+	}
+	if u, err := url.Parse(strings.TrimSpace(t.Path)); err != nil || t.Path == "" || u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("API template %s: path must be relative to the API URL, such as customers/", t.Name)
+	}
+	return nil
+}
+
+// ParseAPIParams reads key=value pairs, one per entry. Blank entries are
+// skipped and a key may repeat.
+func ParseAPIParams(pairs []string) (url.Values, error) {
+	values := url.Values{}
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("parameter %q is not key=value", pair)
+		}
+		values.Add(key, strings.TrimSpace(value))
+	}
+	return values, nil
+}
+
+// FormatAPIParams writes values as key=value lines, sorted by key.
+func FormatAPIParams(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, key := range keys {
+		for _, value := range values[key] {
+			lines = append(lines, key+"="+value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CallAPI sends a request composed in the API console with the configured
+// key. Responses with error statuses are returned, not reported as errors.
+func (a *App) CallAPI(req api.ConsoleRequest) (*api.ConsoleResponse, error) {
+	if a.API == nil || a.API.Key() == "" {
+		return nil, fmt.Errorf("the API key is not configured")
+	}
+	resp, err := a.API.Send(req)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("api", "API console %s %s failed: %v", strings.ToUpper(req.Method), req.Path, err))
+		return nil, err
+	}
+	a.Events.Dispatch(events.Infof("api", "API console %s %s answered %s in %s", resp.Method, req.Path, resp.Status, resp.Duration.Round(time.Millisecond)))
+	return resp, nil
+}
+
+// APITemplates returns the saved API console requests by name.
+func (a *App) APITemplates() []APIRequestTemplate {
+	templates := append([]APIRequestTemplate(nil), a.Config.APITemplates...)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// APITemplate returns the saved request called name.
+func (a *App) APITemplate(name string) (APIRequestTemplate, error) {
+	for _, t := range a.Config.APITemplates {
+		if strings.EqualFold(t.Name, strings.TrimSpace(name)) {
+			return t, nil
+		}
+	}
+	return APIRequestTemplate{}, fmt.Errorf("no API template named %q", name)
+}
+
+// SaveAPITemplate saves t, replacing a template of the same name.
+func (a *App) SaveAPITemplate(t APIRequestTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Method = strings.ToUpper(strings.TrimSpace(t.Method))
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	replaced := false
+	for i := range a.Config.APITemplates {
+		if strings.EqualFold(a.Config.APITemplates[i].Name, t.Name) {
+			a.Config.APITemplates[i] = t
+			replaced = true
+		}
+	}
+	if !replaced {
+		a.Config.APITemplates = append(a.Config.APITemplates, t)
+	}
+	return a.SaveConfig()
+}
+
+// DeleteAPITemplate removes the saved request called name.
+func (a *App) DeleteAPITemplate(name string) error {
+	for i, t := range a.Config.APITemplates {
+		if strings.EqualFold(t.Name, strings.TrimSpace(name)) {
+			a.Config.APITemplates = append(a.Config.APITemplates[:i], a.Config.APITemplates[i+1:]...)
+			return a.SaveConfig()
+		}
+	}
+	return fmt.Errorf("no API template named %q", name)
+}
+
+func valuesFromMap(m map[string]string) url.Values {
+	if len(m) == 0 {
+		return nil
+	}
+	values := url.Values{}
+	for key, value := range m {
+		values.Set(key, value)
+	}
+	return values
+}
+
+func mapFromValues(values url.Values) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(values))
+	for key := range values {
+		m[key] = values.Get(key)
+	}
+	return m
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"badgermaps/api"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAPITemplates(t *testing.T) {
+	a := NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+
+	params, err := ParseAPIParams([]string{"customer_id=42", "", " type = Phone Call "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Get("customer_id") != "42" || params.Get("type") != "Phone Call" {
+		t.Errorf("ParseAPIParams = %v", params)
+	}
+	if _, err := ParseAPIParams([]string{"customer_id"}); err == nil {
+		t.Error("expected a pair without = to be refused")
+	}
+
+	req := api.ConsoleRequest{Method: "get", Path: "appointments/", Query: params}
+	if err := a.SaveAPITemplate(NewAPIRequestTemplate("Acme check-ins", req)); err != nil {
+		t.Fatalf("SaveAPITemplate: %v", err)
+	}
+	req.Query.Set("customer_id", "43")
+	if err := a.SaveAPITemplate(NewAPIRequestTemplate("acme check-ins", req)); err != nil {
+		t.Fatalf("SaveAPITemplate: %v", err)
+	}
+	if err := a.SaveAPITemplate(APIRequestTemplate{Name: "Elsewhere", Method: "GET", Path: "https://example.com/"}); err == nil {
+		t.Error("expected an absolute URL to be refused")
+	}
+	if got := a.APITemplates(); len(got) != 1 {
+		t.Fatalf("APITemplates = %+v, want the renamed template to replace the first", got)
+	}
+
+	data, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Config = defaultConfig()
+	if err := yaml.Unmarshal(data, a.Config); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := a.APITemplate("ACME CHECK-INS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := saved.Request(); r.Method != "GET" || r.Query.Get("customer_id") != "43" || r.Form != nil {
+		t.Errorf("saved request = %+v", r)
+	}
+	if err := a.DeleteAPITemplate("acme check-ins"); err != nil || len(a.APITemplates()) != 0 {
+		t.Errorf("DeleteAPITemplate: %v, %d left", err, len(a.APITemplates()))
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"badgermaps/api"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"strings"
+)
+
+// APIKeyRotationCommand is the CommandLog entry written for each rotation.
+const APIKeyRotationCommand = "api_key_rotate"
+
+// APIKeyRotation describes a completed key rotation. Keys are only given as
+// fingerprints.
+type APIKeyRotation struct {
+	OldKey    string
+	NewKey    string
+	ProfileID int64
+	Saved     bool
+}
+
+// KeyFingerprint shortens an API key to its last four characters so it can
+// be logged.
+func KeyFingerprint(key string) string {
+	key = strings.TrimSpace(key)
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// RotateAPIKey switches to a new API key without interrupting the running
+// process. An empty newKey uses api.secondary_api_key. The key is checked
+// against the API first, and when the current key still works both must
+// belong to the same user. The new key is then set on the shared API client,
+// which the scheduler, webhook handlers, and pushes all use, and saved to the
+// config file with the secondary key cleared. Every attempt is recorded in
+// CommandLog.
+func (a *App) RotateAPIKey(newKey string) (rotation *APIKeyRotation, err error) {
+	newKey = strings.TrimSpace(newKey)
+	if newKey == "" {
+		newKey = strings.TrimSpace(a.Config.API.SecondaryAPIKey)
+	}
+	oldKey := a.Config.API.APIKey
+	defer func() {
+		a.logKeyRotation(oldKey, newKey, err)
+	}()
+
+	if newKey == "" {
+		return nil, fmt.Errorf("no new API key: pass one or set api.secondary_api_key")
+	}
+	if newKey == oldKey {
+		return nil, fmt.Errorf("the new API key is the key already in use")
+	}
+
+	cfg := a.Config.API
+	cfg.APIKey = newKey
+	candidate := api.NewAPIClientWithLimiter(&cfg, a.RateLimiter)
+	profile, err := candidate.GetUserProfile()
+	if err != nil {
+		return nil, fmt.Errorf("the new API key was rejected: %w", err)
+	}
+	if a.API != nil && oldKey != "" {
+		if current, err := a.API.GetUserProfile(); err == nil && current.Data.ProfileId.Int64 != profile.Data.ProfileId.Int64 {
+			return nil, fmt.Errorf("the new API key belongs to %s (profile %d), not %s (profile %d)",
+				profile.Data.Email.String, profile.Data.ProfileId.Int64, current.Data.Email.String, current.Data.ProfileId.Int64)
+		}
+	}
+
+	a.applyAPIKey(newKey)
+	a.Config.API.SecondaryAPIKey = ""
+	rotation = &APIKeyRotation{
+		OldKey:    KeyFingerprint(oldKey),
+		NewKey:    KeyFingerprint(newKey),
+		ProfileID: profile.Data.ProfileId.Int64,
+	}
+	if a.ConfigFile != "" {
+		if err := a.SaveConfig(); err != nil {
+			return rotation, fmt.Errorf("the new API key is in use but could not be saved: %w", err)
+		}
+		rotation.Saved = true
+	}
+	a.Events.Dispatch(events.Infof("config", "Rotated API key %s to %s", rotation.OldKey, rotation.NewKey))
+	return rotation, nil
+}
+
+// applyAPIKey puts key into the config and the shared API client. The
+// sandbox client picks it up on its next push when it reuses the production
+// key.
+func (a *App) applyAPIKey(key string) {
+	a.Config.API.APIKey = key
+	if a.API != nil {
+		a.API.SetAPIKey(key)
+	}
+}
+
+func (a *App) logKeyRotation(oldKey, newKey string, err error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return
+	}
+	args := []string{"from", KeyFingerprint(oldKey), "to", KeyFingerprint(newKey)}
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	if logErr := database.LogCommand(a.DB, APIKeyRotationCommand, args, err == nil, message); logErr != nil {
+		a.Events.Dispatch(events.Warningf("config", "Failed to record API key rotation: %v", logErr))
+	}
+}
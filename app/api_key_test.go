@@ -0,0 +1,108 @@
+package app
+
+import (
+	"badgermaps/api"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRotateAPIKey(t *testing.T) {
+	profiles := map[string]int{"old-key-1111": 1, "new-key-2222": 1, "other-key-3333": 2, "reload-key-4444": 1}
+	var mu sync.Mutex
+	var lastKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Token ")
+		mu.Lock()
+		lastKey = key
+		mu.Unlock()
+		id, ok := profiles[key]
+		if !ok {
+			http.Error(w, `{"detail": "Invalid token."}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %d, "email": "rep%d@example.com"}`, id, id)
+	}))
+	defer server.Close()
+
+	a := NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	a.Config.API = api.APIConfig{BaseURL: server.URL, APIKey: "old-key-1111"}
+	a.API = api.NewAPIClient(&a.Config.API)
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "rotate.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if _, err := a.RotateAPIKey("bad-key-0000"); err == nil {
+		t.Fatal("expected a rejected key to fail")
+	}
+	if _, err := a.RotateAPIKey("other-key-3333"); err == nil || !strings.Contains(err.Error(), "profile 2") {
+		t.Fatalf("expected a key for another user to fail, got %v", err)
+	}
+	if a.API.Key() != "old-key-1111" {
+		t.Fatalf("failed rotations changed the key to %s", a.API.Key())
+	}
+
+	a.Config.API.SecondaryAPIKey = "new-key-2222"
+	rotation, err := a.RotateAPIKey("")
+	if err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+	if rotation.OldKey != "****1111" || rotation.NewKey != "****2222" || !rotation.Saved {
+		t.Errorf("rotation = %+v", rotation)
+	}
+	_, err = a.API.GetUserProfile()
+	mu.Lock()
+	used := lastKey
+	mu.Unlock()
+	if err != nil || used != "new-key-2222" {
+		t.Fatalf("request after rotation used %q: %v", used, err)
+	}
+	saved, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), "new-key-2222") || strings.Contains(string(saved), "secondary_api_key") {
+		t.Errorf("saved config:\n%s", saved)
+	}
+
+	var failed, succeeded int
+	db.GetDB().QueryRow(`SELECT COUNT(*) FROM CommandLog WHERE Command = ? AND Success = 0`, APIKeyRotationCommand).Scan(&failed)
+	db.GetDB().QueryRow(`SELECT COUNT(*) FROM CommandLog WHERE Command = ? AND Success = 1 AND Args = 'from ****1111 to ****2222'`, APIKeyRotationCommand).Scan(&succeeded)
+	if failed != 2 || succeeded != 1 {
+		t.Errorf("CommandLog has %d failed and %d successful rotations, want 2 and 1", failed, succeeded)
+	}
+
+	// A running server picks up a key changed in the file on reload.
+	if err := os.WriteFile(a.ConfigFile, []byte(strings.Replace(string(saved), "new-key-2222", "reload-key-4444", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reload, err := a.ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if !strings.Contains(strings.Join(reload.Applied, ","), "api.api_key") || len(reload.RestartRequired) != 0 {
+		t.Errorf("reload = %+v", reload)
+	}
+	if a.API.Key() != "reload-key-4444" {
+		t.Errorf("key after reload = %s", a.API.Key())
+	}
+}
@@ -3,13 +3,18 @@ package app
 import (
 	"badgermaps/api"
 	"badgermaps/app/action"
+	"badgermaps/app/processor"
 	"badgermaps/app/server"
 	"badgermaps/app/state"
 	"badgermaps/database"
 	"badgermaps/events"
+	"badgermaps/telemetry"
 	"badgermaps/utils"
 	"bufio"
+	"context"
+	"crypto/cipher"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -37,6 +42,14 @@ type ServerConfig struct {
 	TLSKey      string          `yaml:"tls_key"`
 	LogRequests bool            `yaml:"log_requests"`
 	Webhooks    map[string]bool `yaml:"webhooks"`
+	// Tunnel receives webhooks through an outbound relay connection when
+	// the machine has no public address.
+	Tunnel server.TunnelConfig `yaml:"tunnel,omitempty"`
+	// WebhookBuffer deduplicates and batches account create webhooks.
+	WebhookBuffer server.WebhookBufferConfig `yaml:"webhook_buffer,omitempty"`
+	// ACME obtains the TLS certificate from Let's Encrypt instead of
+	// tls_cert and tls_key.
+	ACME server.ACMEConfig `yaml:"acme,omitempty"`
 }
 
 func defaultWebhookConfig() map[string]bool {
@@ -52,11 +65,106 @@ type Config struct {
 	Server                ServerConfig         `yaml:"server"`
 	ThemePreference       string               `yaml:"theme_preference"`
 	MaxConcurrentRequests int                  `yaml:"max_concurrent_requests"`
+	RequestsPerSecond     float64              `yaml:"requests_per_second,omitempty"`
+	RequestBurst          int                  `yaml:"request_burst,omitempty"`
 	CustomCheckins        bool                 `yaml:"custom_checkins"`
 	EventActions          []action.EventAction `yaml:"event_actions"`
 	CronJobs              []server.CronJob     `yaml:"cron_jobs"`
 	WebhookCatchAll       bool                 `yaml:"webhook_catch_all"`
 	LogFile               string               `yaml:"log_file"`
+	PushQuickFilter       string               `yaml:"push_quick_filter,omitempty"`
+	PushWindow            PushWindow           `yaml:"push_window,omitempty"`
+	PushThroughput        PushThroughputConfig `yaml:"push_throughput,omitempty"`
+	PushToSandbox         bool                 `yaml:"push_to_sandbox,omitempty"`
+	ConflictResolution    string               `yaml:"conflict_resolution,omitempty"`
+	Plugins               PluginConfig         `yaml:"plugins,omitempty"`
+	StaleAccountDays      int                  `yaml:"stale_account_days,omitempty"`
+	PullStaleAfter        string               `yaml:"pull_stale_after,omitempty"`
+	BatchSize             int                  `yaml:"batch_size,omitempty"`
+	PullRadius            string               `yaml:"pull_radius,omitempty"`
+	LowBandwidth          bool                 `yaml:"low_bandwidth,omitempty"`
+	PullCustomFields      []string             `yaml:"pull_custom_fields,omitempty"`
+	MergeSeparator        string               `yaml:"merge_separator,omitempty"`
+	DisplayTimezone       string               `yaml:"display_timezone,omitempty"`
+	AccountDisplayName    string               `yaml:"account_display_name,omitempty"`
+	ChangeCapture         bool                 `yaml:"change_capture,omitempty"`
+	LogLevel              string               `yaml:"log_level,omitempty"`
+	Archive               ArchiveConfig        `yaml:"archive,omitempty"`
+	Telemetry             telemetry.Config     `yaml:"telemetry,omitempty"`
+	RecycleBin            RecycleBinConfig     `yaml:"recycle_bin,omitempty"`
+	DisableUpdateCheck    bool                 `yaml:"disable_update_check,omitempty"`
+	GuiSession            GuiSession           `yaml:"gui_session,omitempty"`
+	GuiTabs               GuiTabsConfig        `yaml:"gui_tabs,omitempty"`
+	GuiTours              TourProgress         `yaml:"gui_tours,omitempty"`
+	Alerts                AlertsConfig         `yaml:"alerts,omitempty"`
+	Enrichment            EnrichmentConfig     `yaml:"enrichment,omitempty"`
+	CSVExport             CSVExportConfig      `yaml:"csv_export,omitempty"`
+	Environments          []DBEnvironment      `yaml:"environments,omitempty"`
+	Environment           string               `yaml:"environment,omitempty"`
+	// RemoteConfig names a shared base config that this file overrides.
+	RemoteConfig RemoteConfig `yaml:"remote_config,omitempty"`
+	// FieldRules sets account field sync directions and merge strategies
+	// by column.
+	FieldRules map[string]FieldRule `yaml:"field_rules,omitempty"`
+	// APITemplates are requests saved from the API console.
+	APITemplates []APIRequestTemplate `yaml:"api_templates,omitempty"`
+	// Territories assign account owners by zip, state, or radius; the
+	// first matching rule wins.
+	Territories []TerritoryRule `yaml:"territories,omitempty"`
+}
+
+// DefaultStaleAccountDays is used when stale_account_days is not configured.
+const DefaultStaleAccountDays = 90
+
+// StaleAccountDays returns how many days without a check-in make an account
+// stale for data quality reporting.
+func (a *App) StaleAccountDays() int {
+	if a.Config == nil || a.Config.StaleAccountDays <= 0 {
+		return DefaultStaleAccountDays
+	}
+	return a.Config.StaleAccountDays
+}
+
+// DefaultPullStaleAfter is used when pull_stale_after is not configured.
+const DefaultPullStaleAfter = 24 * time.Hour
+
+// PullStaleAfter returns how long after its last pull Smart Sync pulls an
+// entity again. An invalid pull_stale_after, which LoadConfig reports,
+// uses the default.
+func (a *App) PullStaleAfter() time.Duration {
+	if a.Config == nil {
+		return DefaultPullStaleAfter
+	}
+	return durationOr(a.Config.PullStaleAfter, DefaultPullStaleAfter)
+}
+
+// ValidatePullStaleAfter checks pull_stale_after, a duration such as "6h".
+func ValidatePullStaleAfter(value string) error {
+	if value == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+		return fmt.Errorf("pull_stale_after must be a positive duration such as \"6h\", got %q", value)
+	}
+	return nil
+}
+
+// DefaultBatchSize is used when batch_size is not configured.
+const DefaultBatchSize = 100
+
+// MaxBatchSize caps batch_size so one batch cannot hold a pull's worth of
+// rows in memory.
+const MaxBatchSize = 50000
+
+// BatchSize returns how many rows a pull writes to the database at once.
+func (a *App) BatchSize() int {
+	if a.Config == nil || a.Config.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	if a.Config.BatchSize > MaxBatchSize {
+		return MaxBatchSize
+	}
+	return a.Config.BatchSize
 }
 
 type App struct {
@@ -66,18 +174,51 @@ type App struct {
 	Config         *Config
 	DB             database.DB
 	API            *api.APIClient
+	RateLimiter    *api.RateLimiter
 	Events         *events.EventDispatcher
 	Server         *server.ServerManager
 	ActionExecutor *action.Executor
 	LogListener    *events.LogListener
+	Processors     *processor.Chain
 
 	MaxConcurrentRequests int
 
 	syncHistoryRuns map[string]*syncHistoryRun
 	syncHistoryMu   sync.Mutex
 	syncHistoryOnce bool
+	sandboxAPI      *api.APIClient
+	sandboxConfig   api.APIConfig
+	sandboxMu       sync.Mutex
+	archiveMu       sync.Mutex
+	archiveIndex    *database.CheckinArchiveIndex
+	archiveIndexDir string
+	archiveIndexMod time.Time
+	erasedMu        sync.Mutex
+	erasedAccounts  map[int]bool
+	erasedDB        database.DB
+	fieldLabelsMu   sync.Mutex
+	fieldLabels     map[string]string
+	fieldLabelsDB   database.DB
+	connections     *ConnectionManager
+	connectionsOnce sync.Once
+	pushLimiters    map[string]*api.RateLimiter
+	pushLimitersMu  sync.Mutex
 	closeOnce       sync.Once
 	shuttingDown    atomic.Bool
+	activeEnv       string
+	baseDB          database.DBConfig
+	stopTelemetry   func(context.Context) error
+	syncLock        syncLock
+	alertsMu        sync.Mutex
+	alerts          map[string]events.AlertPayload // by rule, as of the last check
+	columnCipherMu  sync.Mutex
+	columnAEAD      cipher.AEAD
+	columnAEADPath  string
+	startup         startupProfile
+	pprofMu         sync.Mutex
+	pprofServer     *http.Server
+	pprofAddr       string
+	remoteBase      *yaml.Node // the shared config, when remote_config loaded one
 }
 
 func (a *App) Close() {
@@ -89,6 +230,12 @@ func (a *App) Close() {
 			a.Events.WaitForDrain(2 * time.Second)
 		}
 
+		a.StopPprof()
+		if a.stopTelemetry != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			a.stopTelemetry(ctx)
+			cancel()
+		}
 		if a.DB != nil {
 			a.DB.Close()
 		}
@@ -112,30 +259,38 @@ func (a *App) GetDB() database.DB {
 func (a *App) GetAPI() *api.APIClient {
 	return a.API
 }
+
+// defaultConfig returns the settings used when the config file omits them.
+func defaultConfig() *Config {
+	return &Config{
+		API: api.APIConfig{
+			BaseURL: api.DefaultApiBaseURL,
+		},
+		DB: database.DBConfig{
+			Type: "sqlite3",
+			Path: utils.GetConfigDirFile("badgermaps.db"),
+		},
+		Server: ServerConfig{
+			Host:        "localhost",
+			Port:        8080,
+			LogRequests: true,
+			Webhooks:    defaultWebhookConfig(),
+		},
+		ThemePreference:       ThemePreferenceAuto,
+		MaxConcurrentRequests: 5,
+		CustomCheckins:        false,
+	}
+}
+
 func NewApp() *App {
 	a := &App{
-		State: state.NewState(),
-		Config: &Config{
-			API: api.APIConfig{
-				BaseURL: api.DefaultApiBaseURL,
-			},
-			DB: database.DBConfig{
-				Type: "sqlite3",
-				Path: utils.GetConfigDirFile("badgermaps.db"),
-			},
-			Server: ServerConfig{
-				Host:        "localhost",
-				Port:        8080,
-				LogRequests: true,
-				Webhooks:    defaultWebhookConfig(),
-			},
-			ThemePreference:       ThemePreferenceAuto,
-			MaxConcurrentRequests: 5,
-			CustomCheckins:        false,
-		},
+		State:   state.NewState(),
+		Config:  defaultConfig(),
+		startup: startupProfile{started: time.Now()},
 	}
 	a.State.PIDFile = utils.GetConfigDirFile(".badgermaps.pid")
 	a.Events = events.NewEventDispatcher()
+	a.RateLimiter = api.NewRateLimiter(0, 0, a.Config.MaxConcurrentRequests)
 	a.Server = server.NewServerManager(a.State)
 	a.syncHistoryRuns = make(map[string]*syncHistoryRun)
 
@@ -154,16 +309,37 @@ func (a *App) InitLogging() error {
 		return err
 	}
 
+	a.applyLogLevel()
 	a.Events.Subscribe("log", a.LogListener.Handle)
 	return nil
 }
 
+// applyLogLevel sets the log listener's minimum level from log_level. An
+// empty value leaves filtering to the --verbose and --debug flags.
+func (a *App) applyLogLevel() {
+	if a.LogListener == nil {
+		return
+	}
+	if strings.TrimSpace(a.Config.LogLevel) == "" {
+		a.LogListener.ClearMinLevel()
+		return
+	}
+	level, err := events.ParseLogLevel(a.Config.LogLevel)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("config", "Ignoring log_level: %v", err))
+		a.LogListener.ClearMinLevel()
+		return
+	}
+	a.LogListener.SetMinLevel(level)
+}
+
 func (a *App) LoadConfig() error {
 	path, ok, err := a.GetConfigFilePath()
 	if err != nil {
 		return err
 	}
 
+	endConfigLoad := a.StartPhase(StartupConfigLoad)
 	if ok {
 		a.ConfigFile = path
 		// Load from YAML file
@@ -171,13 +347,76 @@ func (a *App) LoadConfig() error {
 		if err != nil {
 			return err
 		}
-		err = yaml.Unmarshal(data, a.Config)
+		a.restoreBaseDB()
+		a.remoteBase, err = a.readConfig(data, a.Config)
 		if err != nil {
 			return err
 		}
 		a.migrateActionNames()
 		a.validateAndCleanActions()
 		a.ensureExecActionShellDefaults()
+		if err := a.Config.PushWindow.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "Push window is misconfigured and will block pushes: %v", err))
+		}
+		if err := a.Config.RecycleBin.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "Recycle bin is misconfigured; deletions will need confirmation: %v", err))
+		}
+		if err := ValidatePullCustomFields(a.Config.PullCustomFields); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; the entry is ignored", err))
+		}
+		if err := a.Config.Telemetry.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; traces are not exported", err))
+		}
+		if err := a.Config.GuiTabs.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; every tab is shown", err))
+		}
+		if err := a.Config.Server.WebhookBuffer.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; the default is used", err))
+		}
+		if err := api.ValidateHeaders(a.Config.API.Headers); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; the header is not sent", err))
+		}
+		if acme := a.Config.Server.ACME; acme.Enabled {
+			if err := acme.Validate(); err != nil {
+				a.Events.Dispatch(events.Warningf("config", "%v; the server will not start until it is fixed", err))
+			} else if a.Config.Server.TLSEnabled {
+				a.Events.Dispatch(events.Warningf("config", "server.acme is enabled; tls_cert and tls_key are ignored"))
+			}
+		}
+		if err := a.Config.Alerts.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; alerts are not checked", err))
+		}
+		if err := a.Config.Enrichment.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; account scores are not computed", err))
+		}
+		if err := a.Config.CSVExport.Validate(); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; exports fail until it is fixed", err))
+		}
+		if _, err := ParseConflictResolution(a.Config.ConflictResolution); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; conflicts are held until resolved", err))
+		}
+		if err := ValidateEncryptedColumns(a.Config.DB); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; pulls that store accounts will fail", err))
+		}
+		if err := database.ValidateMSSQLAuth(a.Config.DB); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; the database connection will fail", err))
+		}
+		if err := database.ValidateDBSchema(a.Config.DB); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; queries will fail", err))
+		}
+		if err := ValidateFieldRules(a.Config.FieldRules); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; invalid rules are ignored", err))
+		}
+		if err := ValidatePullStaleAfter(a.Config.PullStaleAfter); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; Smart Sync pulls after %s", err, DefaultPullStaleAfter))
+		}
+		if _, err := ParseDisplayNameTemplate(a.Config.AccountDisplayName); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; accounts are shown by full name", err))
+		}
+		if err := ValidateTerritories(a.Config.Territories); err != nil {
+			a.Events.Dispatch(events.Warningf("config", "%v; territories are not checked until it is fixed", err))
+		}
+		a.loadProcessors()
 	}
 	a.ensureServerWebhookDefaults()
 	a.ensureThemePreference()
@@ -190,24 +429,46 @@ func (a *App) LoadConfig() error {
 	a.State.TLSKey = a.Config.Server.TLSKey
 	a.State.ServerLogRequests = a.Config.Server.LogRequests
 
-	a.API = api.NewAPIClient(&a.Config.API)
+	a.applyMaxConcurrency()
+	a.applyRateLimits()
+	a.applyPushThroughput()
+	endConfigLoad()
+
+	a.API = api.NewAPIClientWithLimiter(&a.Config.API, a.RateLimiter)
+	a.API.SetCompressRequests(a.LowBandwidth())
+	// Commands need to know whether the API answers before they run; the
+	// GUI tests it in the background below so its window shows at once.
+	if !a.State.IsGui {
+		a.Connections().CheckAPI(true)
+	}
 
+	if err := a.applyEnvironment(); err != nil {
+		return err
+	}
+	endDBConnect := a.StartPhase(StartupDBConnect)
 	var dbErr error
 	a.DB, dbErr = database.NewDB(&a.Config.DB)
 	if dbErr != nil {
 		a.Events.Dispatch(events.Errorf("db", "Failed to initialize database handle: %v", dbErr))
 		a.DB = nil
 	} else if a.DB != nil {
+		a.unlockDB()
 		if err := a.DB.Connect(); err != nil {
-			a.Events.Dispatch(events.Errorf("db", "Failed to connect to database: %v", err))
+			a.Events.Dispatch(events.Errorf("db", "Failed to connect to database: %v", explainDBError(err)))
 			a.DB.Close()
 			a.DB = nil
 		} else {
 			a.DB.TestConnection()
+			a.applyFieldRules()
 		}
 	}
+	endDBConnect()
+	a.Connections().Refresh()
+	if a.State.IsGui {
+		a.Connections().CheckAPI(false)
+	}
 
-	a.ActionExecutor = action.NewExecutor(a.DB, a.API)
+	a.ActionExecutor = a.newActionExecutor()
 	a.Events.Subscribe("*", func(event events.Event) {
 		execCtx := &action.ExecutionContext{
 			EventType: string(event.Type),
@@ -215,32 +476,41 @@ func (a *App) LoadConfig() error {
 			Payload:   event.Payload,
 		}
 		for _, eventAction := range a.Config.EventActions {
+			if eventAction.Disabled {
+				continue
+			}
 			if eventAction.Event == string(event.Type) && (eventAction.Source == "" || eventAction.Source == event.Source) {
 				for _, actionConfig := range eventAction.Run {
 					ac := actionConfig
 					execCopy := execCtx
-					go func(cfg action.ActionConfig, ctx *action.ExecutionContext) {
-						if err := a.ExecuteActionWithContext(cfg, ctx); err != nil {
+					go func(name string, cfg action.ActionConfig, ctx *action.ExecutionContext) {
+						if err := a.ExecuteNamedAction(name, "", cfg, ctx); err != nil {
 							a.Events.Dispatch(events.Errorf("action", "Error executing action: %v", err))
 						}
-					}(ac, execCopy)
+					}(eventAction.Name, ac, execCopy)
 				}
 			}
 		}
 	})
 
-	// Respect configured concurrency before enforcing the default bounds.
-	a.MaxConcurrentRequests = a.Config.MaxConcurrentRequests
-	// Limit between
-	if a.MaxConcurrentRequests < 1 || a.MaxConcurrentRequests > 10 {
-		a.MaxConcurrentRequests = 5
-	}
-
 	a.ensureSyncHistoryTracking()
 
 	return nil
 }
 
+// applyRateLimits pushes the configured request budget into the shared
+// limiter used by every API client the app creates.
+func (a *App) applyRateLimits() {
+	if a.RateLimiter == nil {
+		a.RateLimiter = api.NewRateLimiter(0, 0, 0)
+	}
+	burst := a.Config.RequestBurst
+	if burst < 1 {
+		burst = a.MaxConcurrentRequests
+	}
+	a.RateLimiter.Update(a.Config.RequestsPerSecond, burst, a.MaxConcurrentRequests)
+}
+
 func (a *App) SaveConfig() error {
 	if a.ConfigFile == "" {
 		return fmt.Errorf("no configuration file loaded, cannot save")
@@ -249,7 +519,15 @@ func (a *App) SaveConfig() error {
 }
 
 func (a *App) writeYamlFile(path string) error {
-	data, err := yaml.Marshal(a.Config)
+	var saved interface{} = a.configToSave()
+	if a.remoteBase != nil {
+		local, err := withoutSharedValues(a.configToSave(), a.remoteBase)
+		if err != nil {
+			return err
+		}
+		saved = local
+	}
+	data, err := marshalPreservingComments(saved, path)
 	if err != nil {
 		return err
 	}
@@ -381,11 +659,15 @@ func (a *App) validateAndCleanActions() {
 }
 
 func (a *App) ReloadDB() error {
+	defer a.Connections().Refresh()
 	if a.DB != nil {
 		a.DB.Close()
 	}
 	var err error
 	a.DB, err = database.NewDB(&a.Config.DB)
+	if err == nil {
+		a.unlockDB()
+	}
 	return err
 }
 
@@ -425,7 +707,7 @@ func (a *App) AddEventAction(event, source string, actionConfig action.ActionCon
 			a.Config.EventActions[i].Run = append(a.Config.EventActions[i].Run, actionConfig)
 			err := a.SaveConfig()
 			if err == nil {
-				a.Events.Dispatch(events.Event{Type: "action.config.updated", Source: "events", Payload: events.ActionConfigUpdatedPayload{}})
+				a.Events.Dispatch(events.Event{Type: "action.config.updated", Source: "events", Payload: events.ActionConfigUpdatedPayload{Name: a.Config.EventActions[i].Name}})
 			}
 			return err
 		}
@@ -441,7 +723,7 @@ func (a *App) AddEventAction(event, source string, actionConfig action.ActionCon
 	a.Config.EventActions = append(a.Config.EventActions, newEventAction)
 	err := a.SaveConfig()
 	if err == nil {
-		a.Events.Dispatch(events.Event{Type: "action.config.created", Source: "events", Payload: events.ActionConfigCreatedPayload{}})
+		a.Events.Dispatch(events.Event{Type: "action.config.created", Source: "events", Payload: events.ActionConfigCreatedPayload{Name: newEventAction.Name}})
 	}
 	return err
 }
@@ -454,7 +736,7 @@ func (a *App) UpdateEventAction(eventName string, actionIndex int, actionConfig
 			a.Config.EventActions[i].Run[actionIndex] = actionConfig
 			err := a.SaveConfig()
 			if err == nil {
-				a.Events.Dispatch(events.Event{Type: "action.config.updated", Source: "events", Payload: events.ActionConfigUpdatedPayload{}})
+				a.Events.Dispatch(events.Event{Type: "action.config.updated", Source: "events", Payload: events.ActionConfigUpdatedPayload{Name: eventName}})
 			}
 			return err
 		}
@@ -477,7 +759,53 @@ func (a *App) RemoveEventAction(eventName string, actionIndex int) error {
 
 			err := a.SaveConfig()
 			if err == nil {
-				a.Events.Dispatch(events.Event{Type: "action.config.deleted", Source: "events", Payload: events.ActionConfigDeletedPayload{}})
+				a.Events.Dispatch(events.Event{Type: "action.config.deleted", Source: "events", Payload: events.ActionConfigDeletedPayload{Name: eventName}})
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("event action not found: %s", eventName)
+}
+
+// RemoveEventActionByName deletes an event action and all of its steps.
+func (a *App) RemoveEventActionByName(eventName string) error {
+	for i := range a.Config.EventActions {
+		if a.Config.EventActions[i].Name == eventName {
+			a.Config.EventActions = append(a.Config.EventActions[:i], a.Config.EventActions[i+1:]...)
+			err := a.SaveConfig()
+			if err == nil {
+				a.Events.Dispatch(events.Event{Type: "action.config.deleted", Source: "events", Payload: events.ActionConfigDeletedPayload{Name: eventName}})
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("event action not found: %s", eventName)
+}
+
+// UpdateEventActionTrigger changes the event and source an event action listens for.
+func (a *App) UpdateEventActionTrigger(eventName, event, source string) error {
+	for i := range a.Config.EventActions {
+		if a.Config.EventActions[i].Name == eventName {
+			a.Config.EventActions[i].Event = event
+			a.Config.EventActions[i].Source = source
+			err := a.SaveConfig()
+			if err == nil {
+				a.Events.Dispatch(events.Event{Type: "action.config.updated", Source: "events", Payload: events.ActionConfigUpdatedPayload{Name: eventName}})
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("event action not found: %s", eventName)
+}
+
+// SetEventActionEnabled toggles whether an event action runs when its event fires.
+func (a *App) SetEventActionEnabled(eventName string, enabled bool) error {
+	for i := range a.Config.EventActions {
+		if a.Config.EventActions[i].Name == eventName {
+			a.Config.EventActions[i].Disabled = !enabled
+			err := a.SaveConfig()
+			if err == nil {
+				a.Events.Dispatch(events.Event{Type: "action.config.updated", Source: "events", Payload: events.ActionConfigUpdatedPayload{Name: eventName}})
 			}
 			return err
 		}
@@ -490,37 +818,60 @@ func (a *App) ExecuteAction(actionConfig action.ActionConfig) error {
 }
 
 func (a *App) ExecuteActionWithContext(actionConfig action.ActionConfig, execCtx *action.ExecutionContext) error {
+	return a.ExecuteNamedAction("", "", actionConfig, execCtx)
+}
+
+// ExecuteNamedAction starts actionConfig in the background and records the
+// run in ActionRuns under name, the event action or cron job it belongs
+// to. trigger says what started it; empty uses the event type of execCtx,
+// or "manual" without one. Errors creating or validating the action are
+// returned; errors running it are dispatched as events.
+func (a *App) ExecuteNamedAction(name, trigger string, actionConfig action.ActionConfig, execCtx *action.ExecutionContext) error {
+	actionInstance, executor, err := a.prepareAction(actionConfig, execCtx)
+	if err != nil {
+		return err
+	}
+	if trigger == "" {
+		trigger = ActionTriggerManual
+		if execCtx != nil && execCtx.EventType != "" {
+			trigger = execCtx.EventType
+		}
+	}
+	go a.runAction(actionInstance, executor, actionConfig, execCtx, name, trigger, 0) // run in a goroutine to not block the GUI
+	return nil
+}
+
+// prepareAction creates and validates the action for actionConfig and the
+// executor it runs with.
+func (a *App) prepareAction(actionConfig action.ActionConfig, execCtx *action.ExecutionContext) (action.Action, *action.Executor, error) {
 	logSource := resolveActionLogSource(execCtx)
 	actionInstance, err := action.NewActionFromConfig(actionConfig)
 	if err != nil {
 		a.Events.Dispatch(events.Errorf(logSource, "error creating action: %v", err))
-		return err
+		return nil, nil, err
 	}
 
 	if err := actionInstance.Validate(); err != nil {
 		a.Events.Dispatch(events.Errorf(logSource, "invalid action configuration: %v", err))
-		return err
+		return nil, nil, err
 	}
 
 	a.Events.Dispatch(events.Debugf(logSource, "Executing action type '%s'", actionConfig.Type))
 
 	baseExecutor := a.ActionExecutor
 	if baseExecutor == nil {
-		baseExecutor = action.NewExecutor(a.DB, a.API)
+		baseExecutor = a.newActionExecutor()
 		a.ActionExecutor = baseExecutor
 	}
-	executorWithContext := baseExecutor.WithContext(execCtx)
-	actionType := actionConfig.Type
-
-	go func(execCopy *action.Executor) { // run in a goroutine to not block the GUI
-		if err := actionInstance.Execute(execCopy); err != nil {
-			a.Events.Dispatch(events.Errorf(logSource, "action '%s' failed: %v", actionType, err))
-		} else {
-			a.Events.Dispatch(events.Debugf(logSource, "Action '%s' completed successfully", actionType))
-		}
-	}(executorWithContext)
+	return actionInstance, baseExecutor.WithContext(execCtx), nil
+}
 
-	return nil
+// newActionExecutor returns an executor for the app's database and API
+// that stages followup changes through StageChanges.
+func (a *App) newActionExecutor() *action.Executor {
+	executor := action.NewExecutor(a.DB, a.API)
+	executor.Stage = a.stageFollowUp
+	return executor
 }
 
 func resolveActionLogSource(ctx *action.ExecutionContext) string {
@@ -566,6 +917,9 @@ func (a *App) TriggerEventAction(actionString string) error {
 }
 
 func (a *App) EnsureConfig(isGui bool) {
+	if isGui {
+		a.State.IsGui = true
+	}
 	if a.State.NoColor {
 		utils.InitColors(a.State)
 	}
@@ -588,6 +942,11 @@ func (a *App) EnsureConfig(isGui bool) {
 		fmt.Fprintf(os.Stderr, "Error initializing logger: %v\n", err)
 		os.Exit(1)
 	}
+	a.flushStartupProfile()
+	if !isGui {
+		a.LogStartupSummary("Ready")
+	}
+	a.StartTelemetry()
 
 	if ok {
 		a.Events.Dispatch(events.Infof("config", "Configuration detected: %s", path))
@@ -689,8 +1048,11 @@ func (a *App) InteractiveSetup() bool {
 		a.Config.DB.Host = utils.PromptString(reader, "Database Host", a.Config.DB.Host)
 		a.Config.DB.Port = utils.PromptInt(reader, "Database Port", a.Config.DB.Port)
 		a.Config.DB.Database = utils.PromptString(reader, "Database Name", a.Config.DB.Database)
-		a.Config.DB.Username = utils.PromptString(reader, "Database Username", a.Config.DB.Username)
-		a.Config.DB.Password = utils.PromptPassword(reader, "Database Password", a.Config.DB.Password)
+		database.PromptMSSQLCredentials(reader, &a.Config.DB)
+		if err := database.ValidateMSSQLAuth(a.Config.DB); err != nil {
+			fmt.Println(utils.Colors.Red("✗ %v", err))
+			return false
+		}
 	}
 
 	// Test the new connection before proceeding
@@ -702,8 +1064,9 @@ func (a *App) InteractiveSetup() bool {
 		fmt.Println(utils.Colors.Red("✗ Failed to load database settings: %v", err))
 		return false
 	}
+	a.unlockDB()
 	if err := a.DB.Connect(); err != nil {
-		fmt.Println(utils.Colors.Red("✗ Database connection failed: %v", err))
+		fmt.Println(utils.Colors.Red("✗ Database connection failed: %v", explainDBError(err)))
 		fmt.Println(utils.Colors.Yellow("Please check your database settings and try again."))
 		return false
 	}
@@ -733,8 +1096,14 @@ func (a *App) InteractiveSetup() bool {
 		fmt.Println(utils.Colors.Yellow("⚠ Database schema already exists and is valid."))
 		reinitialize := utils.PromptBool(reader, "Do you want to reinitialize the database? (This will delete all existing data)", false)
 		if reinitialize {
+			if err := a.ConfirmProduction("reinitializing the database"); err != nil {
+				fmt.Println(utils.Colors.Red("✗ %v", err))
+				return false
+			}
 			fmt.Println(utils.Colors.Yellow("Re-initializing database schema and deleting all existing data..."))
-			if err := a.DB.ResetSchema(a.State); err != nil {
+			err := a.DB.ResetSchema(a.State)
+			a.Connections().InvalidateSchema()
+			if err != nil {
 				fmt.Println(utils.Colors.Red("✗ Error resetting schema: %v", err))
 				return false
 			}
@@ -745,8 +1114,14 @@ func (a *App) InteractiveSetup() bool {
 		fmt.Println(utils.Colors.Yellow("⚠ Database schema is invalid or missing."))
 		enforce := utils.PromptBool(reader, "Do you want to create/update the database schema now?", true)
 		if enforce {
+			if err := a.ConfirmProduction("enforcing the schema"); err != nil {
+				fmt.Println(utils.Colors.Red("✗ %v", err))
+				return false
+			}
 			fmt.Println(utils.Colors.Cyan("Enforcing schema..."))
-			if err := a.DB.EnforceSchema(a.State); err != nil {
+			err := a.DB.EnforceSchema(a.State)
+			a.Connections().InvalidateSchema()
+			if err != nil {
 				fmt.Println(utils.Colors.Red("✗ Error enforcing schema: %v", err))
 				return false
 			}
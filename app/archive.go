@@ -0,0 +1,115 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchiveConfig is the check-in archival policy. With CheckinMonths set,
+// check-ins from before the last CheckinMonths whole months are moved to
+// compressed files in Dir.
+type ArchiveConfig struct {
+	CheckinMonths int    `yaml:"checkin_months,omitempty"`
+	Dir           string `yaml:"dir,omitempty"`
+}
+
+// CheckinArchiveDir returns the directory archived check-ins are kept in.
+func (a *App) CheckinArchiveDir() string {
+	if a.Config != nil && a.Config.Archive.Dir != "" {
+		return a.Config.Archive.Dir
+	}
+	return database.DefaultCheckinArchiveDir()
+}
+
+// ArchiveCheckins moves check-ins older than the given number of whole
+// months to the archive. Zero uses archive.checkin_months from the config.
+func (a *App) ArchiveCheckins(months int) (database.CheckinArchiveResult, error) {
+	if months <= 0 && a.Config != nil {
+		months = a.Config.Archive.CheckinMonths
+	}
+	if months <= 0 {
+		return database.CheckinArchiveResult{}, fmt.Errorf("no archive policy: set archive.checkin_months or pass a number of months")
+	}
+	if a.DB == nil {
+		return database.CheckinArchiveResult{}, fmt.Errorf("database is not configured")
+	}
+
+	dir := a.CheckinArchiveDir()
+	cutoff := database.CheckinArchiveCutoff(time.Now(), months)
+	result, err := database.ArchiveCheckins(a.DB, dir, cutoff)
+	if err != nil {
+		return result, err
+	}
+	if result.Checkins == 0 {
+		a.Events.Dispatch(events.Infof("db", "No check-ins before %s to archive.", cutoff.Format("2006-01-02")))
+		return result, nil
+	}
+	a.Events.Dispatch(events.Infof("db", "Archived %d check-ins from %d months to %s.", result.Checkins, len(result.Months), dir))
+	a.Events.Dispatch(events.Event{Type: "db.archive.complete", Source: "db", Payload: events.CheckinArchivePayload{Months: result.Months, Checkins: result.Checkins}})
+	return result, nil
+}
+
+// RestoreArchivedCheckins moves the archived check-ins for a "YYYY-MM" or
+// "YYYY-MM..YYYY-MM" range back into the database.
+func (a *App) RestoreArchivedCheckins(monthRange string) (database.CheckinArchiveResult, error) {
+	from, to, err := database.ParseMonthRange(monthRange)
+	if err != nil {
+		return database.CheckinArchiveResult{}, err
+	}
+	if a.DB == nil {
+		return database.CheckinArchiveResult{}, fmt.Errorf("database is not configured")
+	}
+
+	result, err := database.RestoreCheckins(a.DB, a.CheckinArchiveDir(), from, to)
+	if err != nil {
+		return result, err
+	}
+	if len(result.Months) == 0 {
+		return result, fmt.Errorf("no archived months between %s and %s", from, to)
+	}
+	a.Events.Dispatch(events.Infof("db", "Restored %d archived check-ins from %d months.", result.Checkins, len(result.Months)))
+	if result.Skipped > 0 {
+		a.Events.Dispatch(events.Warningf("db", "%d archived check-ins could not be restored; their accounts may no longer exist.", result.Skipped))
+	}
+	a.Events.Dispatch(events.Event{Type: "db.archive.restore.complete", Source: "db", Payload: events.CheckinArchiveRestorePayload{Months: result.Months, Checkins: result.Checkins, Skipped: result.Skipped}})
+	return result, nil
+}
+
+// CheckinArchiveIndex returns the archive index, re-reading it only when
+// the file has changed since the last call.
+func (a *App) CheckinArchiveIndex() (*database.CheckinArchiveIndex, error) {
+	dir := a.CheckinArchiveDir()
+	info, statErr := os.Stat(filepath.Join(dir, database.CheckinArchiveIndexFile))
+
+	a.archiveMu.Lock()
+	defer a.archiveMu.Unlock()
+	if statErr == nil && a.archiveIndex != nil && a.archiveIndexDir == dir && info.ModTime().Equal(a.archiveIndexMod) {
+		return a.archiveIndex, nil
+	}
+	index, err := database.LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	a.archiveIndex, a.archiveIndexDir = index, dir
+	a.archiveIndexMod = time.Time{}
+	if statErr == nil {
+		a.archiveIndexMod = info.ModTime()
+	}
+	return index, nil
+}
+
+// IsCheckinArchived reports whether a check-in logged at logDatetime belongs
+// to an archived month. Pulls skip such check-ins so archived months are not
+// filled back in.
+func (a *App) IsCheckinArchived(logDatetime string) bool {
+	month := database.CheckinMonth(logDatetime)
+	if month == "" {
+		return false
+	}
+	index, err := a.CheckinArchiveIndex()
+	return err == nil && index.Contains(month)
+}
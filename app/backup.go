@@ -0,0 +1,39 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+)
+
+// BackupDatabase writes a backup of the configured database to out and
+// announces it with a db.backup.complete event. An empty format selects the
+// default for the database type.
+func (a *App) BackupDatabase(out, format string) error {
+	if a.DB == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	if format == "" {
+		format = database.DefaultBackupFormat(a.DB)
+	}
+	if err := database.BackupDatabase(a.DB, out, format); err != nil {
+		return err
+	}
+	a.Events.Dispatch(events.Infof("db", "Database backup written to %s (%s).", out, format))
+	a.Events.Dispatch(events.Event{Type: "db.backup.complete", Source: "db", Payload: events.DatabaseBackupPayload{Path: out, Format: format}})
+	return nil
+}
+
+// RestoreDatabase replaces the configured database with the backup at in.
+func (a *App) RestoreDatabase(in string) error {
+	if a.DB == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	restore := func() error { return database.RestoreDatabase(a.DB, in) }
+	if err := a.WithoutChangeCapture(restore); err != nil {
+		return err
+	}
+	a.Events.Dispatch(events.Infof("db", "Database restored from %s.", in))
+	a.Events.Dispatch(events.Event{Type: "db.restore.complete", Source: "db", Payload: events.DatabaseRestorePayload{Path: in}})
+	return nil
+}
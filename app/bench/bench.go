@@ -0,0 +1,387 @@
+// Package bench measures how fast this environment talks to the API, writes
+// to the configured database, and runs a pull, and compares the numbers with
+// baselines from a typical setup so slow links or databases stand out.
+package bench
+
+import (
+	"badgermaps/api"
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guregu/null/v6"
+)
+
+// Default sizes of a run.
+const (
+	DefaultSamples = 3
+	DefaultRows    = 200
+)
+
+// SlowFactor is how many times worse than its baseline a result may be
+// before it is reported as slow.
+const SlowFactor = 2.0
+
+// Units of a result.
+const (
+	UnitMilliseconds = "ms"
+	UnitRowsPerSec   = "rows/s"
+)
+
+// Baseline latencies in milliseconds, measured on a broadband connection.
+var apiBaselines = map[string]float64{
+	"api.profile":  400,
+	"api.accounts": 1500,
+	"api.account":  400,
+}
+
+// Baseline throughputs in rows per second by database type, measured on a
+// laptop with the database on the same machine.
+var dbBaselines = map[string]map[string]float64{
+	"sqlite3":  {"db.insert": 1500, "db.merge": 1500, "pull.simulated": 800},
+	"postgres": {"db.insert": 800, "db.merge": 800, "pull.simulated": 500},
+	"mssql":    {"db.insert": 600, "db.merge": 600, "pull.simulated": 400},
+}
+
+// Options sets the size of a run.
+type Options struct {
+	// Samples is how many times each API request is timed.
+	Samples int
+	// Rows is how many accounts the database and pull benchmarks write.
+	Rows int
+	// SkipAPI leaves out the requests to the BadgerMaps API.
+	SkipAPI bool
+}
+
+// Result is one measurement. Value is the median for latencies.
+type Result struct {
+	Name     string  `json:"name"`
+	Label    string  `json:"label"`
+	Unit     string  `json:"unit"`
+	Value    float64 `json:"value"`
+	Min      float64 `json:"min,omitempty"`
+	Max      float64 `json:"max,omitempty"`
+	Count    int     `json:"count"`
+	Baseline float64 `json:"baseline,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Ratio reports how many times worse than its baseline the result is; 1 or
+// less is as fast or faster. It is 0 when there is no baseline.
+func (r Result) Ratio() float64 {
+	if r.Baseline == 0 || r.Value == 0 {
+		return 0
+	}
+	if r.Unit == UnitRowsPerSec {
+		return r.Baseline / r.Value
+	}
+	return r.Value / r.Baseline
+}
+
+// Slow reports whether the result is more than SlowFactor times worse than
+// its baseline.
+func (r Result) Slow() bool {
+	return r.Error == "" && r.Ratio() > SlowFactor
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	DBType  string   `json:"db_type"`
+	Results []Result `json:"results"`
+}
+
+// Slow returns the results that are slower than their baseline allows.
+func (r Report) Slow() []Result {
+	var slow []Result
+	for _, result := range r.Results {
+		if result.Slow() {
+			slow = append(slow, result)
+		}
+	}
+	return slow
+}
+
+// Run measures API latency, database write throughput, and a pull served by
+// a local stand-in for the API. The database benchmarks write accounts with
+// negative IDs, which the API never assigns, and delete them afterwards.
+func Run(a *app.App, opts Options) (*Report, error) {
+	if opts.Samples <= 0 {
+		opts.Samples = DefaultSamples
+	}
+	if opts.Rows <= 0 {
+		opts.Rows = DefaultRows
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{DBType: a.DB.GetType()}
+	if !opts.SkipAPI {
+		report.Results = append(report.Results, benchAPI(a, opts.Samples)...)
+	}
+	dbResults, err := benchDatabase(a, opts.Rows)
+	report.Results = append(report.Results, dbResults...)
+	for i := range report.Results {
+		r := &report.Results[i]
+		if baseline, ok := apiBaselines[r.Name]; ok {
+			r.Baseline = baseline
+		} else if baseline, ok := dbBaselines[report.DBType][r.Name]; ok {
+			r.Baseline = baseline
+		}
+	}
+	return report, err
+}
+
+func benchAPI(a *app.App, samples int) []Result {
+	if a.API == nil {
+		return []Result{{Name: "api.profile", Label: "API profile request", Unit: UnitMilliseconds, Error: "API is not configured"}}
+	}
+
+	profile := timeRequests("api.profile", "API profile request", samples, func() error {
+		_, err := a.API.GetUserProfile()
+		return err
+	})
+
+	var ids []int
+	accounts := timeRequests("api.accounts", "API account list request", samples, func() error {
+		resp, err := a.API.GetAccountIDs()
+		if err == nil {
+			ids = resp.Data
+		}
+		return err
+	})
+
+	// Sample accounts spread over the list rather than the first few.
+	var sampled []int
+	for i := 0; i < samples && i < len(ids); i++ {
+		sampled = append(sampled, ids[i*len(ids)/samples])
+	}
+	account := Result{Name: "api.account", Label: "API account request", Unit: UnitMilliseconds, Error: "no accounts to sample"}
+	if len(sampled) > 0 {
+		next := 0
+		account = timeRequests("api.account", "API account request", len(sampled), func() error {
+			id := sampled[next]
+			next++
+			_, err := a.API.GetAccountDetailed(id)
+			return err
+		})
+	}
+	return []Result{profile, accounts, account}
+}
+
+// timeRequests runs request samples times and reports the median, fastest,
+// and slowest latency. It stops at the first error.
+func timeRequests(name, label string, samples int, request func() error) Result {
+	result := Result{Name: name, Label: label, Unit: UnitMilliseconds}
+	var latencies []float64
+	for i := 0; i < samples; i++ {
+		start := time.Now()
+		if err := request(); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		latencies = append(latencies, float64(time.Since(start))/float64(time.Millisecond))
+	}
+	sort.Float64s(latencies)
+	result.Count = len(latencies)
+	result.Value = latencies[len(latencies)/2]
+	result.Min = latencies[0]
+	result.Max = latencies[len(latencies)-1]
+	return result
+}
+
+func benchDatabase(a *app.App, rows int) (results []Result, err error) {
+	if err := deleteBenchmarkAccounts(a); err != nil {
+		return nil, fmt.Errorf("failed to clear benchmark accounts: %w", err)
+	}
+	defer func() {
+		if cleanupErr := deleteBenchmarkAccounts(a); cleanupErr != nil && err == nil {
+			err = fmt.Errorf("failed to remove benchmark accounts: %w", cleanupErr)
+		}
+	}()
+
+	accounts := make([]models.Account, rows)
+	for i := range accounts {
+		accounts[i] = benchmarkAccount(-(i + 1))
+	}
+
+	// The first pass inserts the accounts and the second updates them, the
+	// two cases a pull's merge handles.
+	insert := timeStores("db.insert", "Database insert", accounts, func(acc models.Account) error {
+		return pull.StoreAccountDetailed(a, &acc)
+	})
+	results = append(results, insert)
+	if insert.Error != "" {
+		return results, nil
+	}
+	for i := range accounts {
+		accounts[i].Notes = null.StringFrom("Updated by badgermaps bench")
+	}
+	results = append(results, timeStores("db.merge", "Database merge", accounts, func(acc models.Account) error {
+		return pull.StoreAccountDetailed(a, &acc)
+	}))
+
+	if err := deleteBenchmarkAccounts(a); err != nil {
+		return results, fmt.Errorf("failed to clear benchmark accounts: %w", err)
+	}
+	results = append(results, simulatePull(a, accounts))
+	return results, nil
+}
+
+func timeStores(name, label string, accounts []models.Account, store func(models.Account) error) Result {
+	result := Result{Name: name, Label: label, Unit: UnitRowsPerSec}
+	start := time.Now()
+	for _, acc := range accounts {
+		if err := store(acc); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Count++
+	}
+	result.Value = rate(result.Count, time.Since(start))
+	return result
+}
+
+// simulatePull serves accounts from a local HTTP server that answers like
+// the customers endpoints and pulls them the way PullGroupAccounts does:
+// list the IDs, then fetch and store each account with the configured
+// concurrency. It measures JSON decoding, pull processors, and storage
+// without network latency.
+func simulatePull(a *app.App, accounts []models.Account) Result {
+	result := Result{Name: "pull.simulated", Label: "Simulated account pull", Unit: UnitRowsPerSec}
+
+	bodies := make(map[string][]byte, len(accounts))
+	ids := make([]map[string]int, len(accounts))
+	for i, acc := range accounts {
+		body, err := json.Marshal(acc)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		bodies[strconv.FormatInt(acc.AccountId.Int64, 10)] = body
+		ids[i] = map[string]int{"id": int(acc.AccountId.Int64)}
+	}
+	list, err := json.Marshal(ids)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/customers"), "/")
+		if id == "" {
+			w.Write(list)
+			return
+		}
+		body, ok := bodies[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+	start := time.Now()
+	resp, err := client.GetAccountIDs()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	concurrency := a.MaxConcurrentRequests
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, id := range resp.Data {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(accountID int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			accountResp, err := client.GetAccountDetailed(accountID)
+			if err == nil {
+				err = pull.StoreAccountDetailed(a, &accountResp.Data)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("account %d: %w", accountID, err)
+			} else if err == nil {
+				result.Count++
+			}
+		}(id)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		result.Error = firstErr.Error()
+		return result
+	}
+	result.Value = rate(result.Count, time.Since(start))
+	return result
+}
+
+func benchmarkAccount(id int) models.Account {
+	name := fmt.Sprintf("Benchmark Account %d", -id)
+	return models.Account{
+		AccountId:       null.IntFrom(int64(id)),
+		FirstName:       null.StringFrom("Benchmark"),
+		LastName:        null.StringFrom(fmt.Sprintf("Account %d", -id)),
+		FullName:        null.StringFrom(name),
+		PhoneNumber:     null.StringFrom("555-0100"),
+		Email:           null.StringFrom(fmt.Sprintf("bench%d@example.com", -id)),
+		OriginalAddress: null.StringFrom("1 Main St, Springfield"),
+		AccountOwner:    null.StringFrom("bench@example.com"),
+		CustomText:      null.StringFrom("benchmark"),
+		CustomNumeric:   null.FloatFrom(float64(-id)),
+		Locations: []models.Location{{
+			LocationId:   null.IntFrom(int64(id)),
+			City:         null.StringFrom("Springfield"),
+			Name:         null.StringFrom(name),
+			Zipcode:      null.StringFrom("00000"),
+			State:        null.StringFrom("IL"),
+			Lat:          null.FloatFrom(39.78),
+			Long:         null.FloatFrom(-89.65),
+			AddressLine1: null.StringFrom("1 Main St"),
+			Location:     null.StringFrom("1 Main St, Springfield, IL"),
+		}},
+	}
+}
+
+// deleteBenchmarkAccounts removes the accounts written by a run, without
+// queueing them as deletes when change capture is enabled.
+func deleteBenchmarkAccounts(a *app.App) error {
+	return a.WithoutChangeCapture(func() error {
+		if err := database.RunCommand(a.DB, "DeleteBenchmarkAccountLocations"); err != nil {
+			return err
+		}
+		return database.RunCommand(a.DB, "DeleteBenchmarkAccounts")
+	})
+}
+
+func rate(count int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(count) / elapsed.Seconds()
+}
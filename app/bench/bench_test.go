@@ -0,0 +1,93 @@
+package bench
+
+import (
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/profiles/":
+			w.Write([]byte(`{"id": 1, "email": "rep@example.com"}`))
+		case "/customers/":
+			w.Write([]byte(`[{"id": 10}, {"id": 11}, {"id": 12}, {"id": 13}]`))
+		default:
+			w.Write([]byte(`{"id": 10, "last_name": "Acme"}`))
+		}
+	}))
+	defer server.Close()
+
+	a := app.NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "bench.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	a.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName, FullName) VALUES (7, 'Keep', 'Keep')`); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(a, Options{Samples: 2, Rows: 25})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.DBType != "sqlite3" {
+		t.Errorf("DBType = %q, want sqlite3", report.DBType)
+	}
+	want := []string{"api.profile", "api.accounts", "api.account", "db.insert", "db.merge", "pull.simulated"}
+	if len(report.Results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(report.Results), len(want), report.Results)
+	}
+	for i, r := range report.Results {
+		if r.Name != want[i] {
+			t.Errorf("result %d = %s, want %s", i, r.Name, want[i])
+		}
+		if r.Error != "" {
+			t.Errorf("%s failed: %s", r.Name, r.Error)
+		}
+		if r.Value <= 0 || r.Baseline <= 0 {
+			t.Errorf("%s: value %v, baseline %v", r.Name, r.Value, r.Baseline)
+		}
+	}
+	if n := report.Results[5].Count; n != 25 {
+		t.Errorf("simulated pull stored %d accounts, want 25", n)
+	}
+
+	var accounts, benchRows int
+	db.GetDB().QueryRow(`SELECT COUNT(*) FROM Accounts`).Scan(&accounts)
+	db.GetDB().QueryRow(`SELECT COUNT(*) FROM AccountLocations WHERE AccountId < 0`).Scan(&benchRows)
+	if accounts != 1 || benchRows != 0 {
+		t.Errorf("after the run: %d accounts and %d benchmark locations, want only the original account", accounts, benchRows)
+	}
+}
+
+func TestResultSlow(t *testing.T) {
+	latency := Result{Unit: UnitMilliseconds, Value: 900, Baseline: 400}
+	if !latency.Slow() {
+		t.Errorf("%v ms against a %v ms baseline should be slow", latency.Value, latency.Baseline)
+	}
+	throughput := Result{Unit: UnitRowsPerSec, Value: 1000, Baseline: 1500}
+	if throughput.Slow() {
+		t.Errorf("%v rows/s against %v rows/s should not be slow", throughput.Value, throughput.Baseline)
+	}
+	throughput.Value = 500
+	if !throughput.Slow() {
+		t.Errorf("%v rows/s against %v rows/s should be slow", throughput.Value, throughput.Baseline)
+	}
+}
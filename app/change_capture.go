@@ -0,0 +1,50 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+)
+
+// EnableChangeCapture installs the database triggers that turn updates and
+// deletes made to Accounts by other systems into pending changes, and
+// records the setting as change_capture in the config.
+func (a *App) EnableChangeCapture() error {
+	if a.DB == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	if err := database.EnableChangeCapture(a.DB); err != nil {
+		return err
+	}
+	a.Config.ChangeCapture = true
+	if err := a.SaveConfig(); err != nil {
+		return err
+	}
+	a.Events.Dispatch(events.Infof("db", "Change capture enabled: external account changes will be queued for push."))
+	return nil
+}
+
+// DisableChangeCapture removes the change capture triggers.
+func (a *App) DisableChangeCapture() error {
+	if a.DB == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	if err := database.DisableChangeCapture(a.DB); err != nil {
+		return err
+	}
+	a.Config.ChangeCapture = false
+	if err := a.SaveConfig(); err != nil {
+		return err
+	}
+	a.Events.Dispatch(events.Infof("db", "Change capture disabled."))
+	return nil
+}
+
+// WithoutChangeCapture runs fn, a write the app makes to Accounts, so that
+// the change capture triggers do not queue it for push.
+func (a *App) WithoutChangeCapture(fn func() error) error {
+	if a.DB == nil || a.Config == nil || !a.Config.ChangeCapture {
+		return fn()
+	}
+	return database.WithoutChangeCapture(a.DB, fn)
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/database/repository"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of FieldDiff.
+const (
+	DiffAdded     = "added"
+	DiffRemoved   = "removed"
+	DiffChanged   = "changed"
+	DiffUnchanged = "unchanged"
+)
+
+// FieldDiff compares one field of a staged change with the local row.
+type FieldDiff struct {
+	// Field is the API field of an account change, or the column of a
+	// check-in change; it is what EditAccountChange and EditCheckinChange
+	// take.
+	Field string `json:"field"`
+	Label string `json:"label"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	// Type is text, number, date, or datetime.
+	Type string `json:"type"`
+	Kind string `json:"kind"`
+}
+
+// DiffKind classifies a change from old to new.
+func DiffKind(old, new string) string {
+	switch {
+	case old == new:
+		return DiffUnchanged
+	case old == "":
+		return DiffAdded
+	case new == "":
+		return DiffRemoved
+	}
+	return DiffChanged
+}
+
+// DiffJSON renders diffs as indented JSON for copying.
+func DiffJSON(diffs []FieldDiff) (string, error) {
+	if diffs == nil {
+		diffs = []FieldDiff{}
+	}
+	data, err := json.MarshalIndent(diffs, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// accountColumnsByField maps API fields back to Accounts columns.
+var accountColumnsByField = func() map[string]string {
+	columns := make(map[string]string, len(accountEditableFields))
+	for column, field := range accountEditableFields {
+		columns[field] = column
+	}
+	return columns
+}()
+
+// AccountChangeDiff compares the fields of a staged account change with the
+// account as stored locally. Custom fields are labeled with their data set
+// name.
+func (a *App) AccountChangeDiff(change database.AccountPendingChange) ([]FieldDiff, error) {
+	fields, err := parseAccountChanges(change.Changes)
+	if err != nil {
+		return nil, err
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	repo := repository.New(a.DB)
+	// A created account has no local row; every field is then an addition.
+	account, _ := repo.GetAccountWithLabels(change.AccountId)
+	if account != nil {
+		a.DecryptAccountForDisplay(&account.Account)
+	}
+
+	diffs := make([]FieldDiff, 0, len(fields))
+	for field, value := range fields {
+		diff := FieldDiff{Field: field, Label: field, New: value, Type: "text"}
+		if column, ok := accountColumnsByField[field]; ok {
+			diff.Label = column
+			diff.Type = accountColumnType(column)
+			if account != nil {
+				diff.Label = account.Label(column)
+				diff.Old, _ = account.Value(column)
+			}
+		}
+		diff.Kind = DiffKind(diff.Old, diff.New)
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Label < diffs[j].Label })
+	return diffs, nil
+}
+
+// CheckinChangeDiff compares the fields of a staged check-in change with the
+// stored check-in, if there is one.
+func (a *App) CheckinChangeDiff(change database.CheckinPendingChange) ([]FieldDiff, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	old := map[string]string{}
+	if change.CheckinId > 0 {
+		if checkin, err := database.GetCheckinByID(a.DB, change.CheckinId); err == nil {
+			old["CrmId"] = checkin.CrmId.ValueOrZero()
+			old["LogDatetime"] = checkin.LogDatetime.ValueOrZero()
+			old["Type"] = checkin.Type.ValueOrZero()
+			old["Comments"] = checkin.Comments.ValueOrZero()
+		}
+	}
+	staged := checkinChangeFields(change)
+	diffs := make([]FieldDiff, 0, len(checkinEditableColumns))
+	for _, column := range checkinEditableColumns {
+		diff := FieldDiff{Field: column, Label: column, Old: old[column], New: staged[column], Type: "text"}
+		if column == "LogDatetime" {
+			diff.Type = "datetime"
+		}
+		diff.Kind = DiffKind(diff.Old, diff.New)
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// EditAccountChange sets field of a pending account change to value before
+// it is pushed. The value is validated like an edit made in the Explorer.
+// Editing a change that failed validation clears its errors and queues it
+// for the next push.
+func (a *App) EditAccountChange(changeID int, field, value string) error {
+	change, err := a.findAccountChange(changeID)
+	if err != nil {
+		return err
+	}
+	column, ok := accountColumnsByField[field]
+	if !ok {
+		return fmt.Errorf("field %s cannot be edited", field)
+	}
+	value, err = validateAccountFieldValue(column, strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	fields, err := parseAccountChanges(change.Changes)
+	if err != nil {
+		return err
+	}
+	if _, ok := fields[field]; !ok {
+		return fmt.Errorf("change %d does not stage %s", changeID, field)
+	}
+	fields[field] = value
+	changes, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return database.UpdateAccountChangeChanges(a.DB, changeID, string(changes))
+}
+
+// EditCheckinChange sets one of CrmId, LogDatetime, Type, or Comments of a
+// pending check-in change before it is pushed.
+func (a *App) EditCheckinChange(changeID int, column, value string) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	changes, err := database.GetPendingCheckinChanges(a.DB)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.ChangeId != changeID {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		field := sql.NullString{String: value, Valid: value != ""}
+		switch column {
+		case "CrmId":
+			change.CrmId = field
+		case "LogDatetime":
+			change.LogDatetime = field
+		case "Type":
+			change.Type = field
+		case "Comments":
+			change.Comments = field
+		default:
+			return fmt.Errorf("column %s cannot be edited", column)
+		}
+		return database.UpdateCheckinChangeFields(a.DB, change)
+	}
+	return database.ErrChangeNotPending
+}
+
+func (a *App) findAccountChange(changeID int) (*database.AccountPendingChange, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	changes, err := database.GetAccountChanges(a.DB)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		if changes[i].ChangeId == changeID && AccountChangeEditable(changes[i]) {
+			return &changes[i], nil
+		}
+	}
+	return nil, database.ErrChangeNotPending
+}
+
+// AccountChangeEditable reports whether the staged fields of an account
+// change can still be edited: it is pending, or the API rejected its
+// values and an edit queues it again. Deletes stage no fields.
+func AccountChangeEditable(change database.AccountPendingChange) bool {
+	if change.ChangeType == "DELETE" {
+		return false
+	}
+	return change.Status == "pending" || change.Status == database.StatusValidationFailed
+}
+
+// checkinEditableColumns are the fields of a pending check-in change that
+// can be edited before push.
+var checkinEditableColumns = []string{"Type", "LogDatetime", "Comments", "CrmId"}
+
+func checkinChangeFields(change database.CheckinPendingChange) map[string]string {
+	return map[string]string{
+		"CrmId":       change.CrmId.String,
+		"LogDatetime": change.LogDatetime.String,
+		"Type":        change.Type.String,
+		"Comments":    change.Comments.String,
+	}
+}
+
+func parseAccountChanges(changes string) (map[string]string, error) {
+	fields := map[string]string{}
+	if strings.TrimSpace(changes) == "" {
+		return fields, nil
+	}
+	if err := json.Unmarshal([]byte(changes), &fields); err != nil {
+		return nil, fmt.Errorf("staged change is not a JSON object of strings: %w", err)
+	}
+	return fields, nil
+}
+
+func accountColumnType(column string) string {
+	switch {
+	case column == "FollowUpDate":
+		return "date"
+	case strings.HasPrefix(column, "CustomNumeric"):
+		return "number"
+	}
+	return "text"
+}
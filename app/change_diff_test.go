@@ -0,0 +1,94 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestAccountChangeDiffAndEdit(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "diff.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, FullName, PhoneNumber, Email) VALUES (42, 'Acme', '555-0100', 'old@example.com')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.StageAccountChange(db, 42, "UPDATE", `{"phone_number":"555-0199","email":"","notes":"call back"}`); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("GetPendingAccountChanges = %v, %v", changes, err)
+	}
+
+	diffs, err := a.AccountChangeDiff(changes[0])
+	if err != nil {
+		t.Fatalf("AccountChangeDiff: %v", err)
+	}
+	kinds := map[string]FieldDiff{}
+	for _, d := range diffs {
+		kinds[d.Field] = d
+	}
+	if d := kinds["phone_number"]; d.Kind != DiffChanged || d.Old != "555-0100" || d.Label != "PhoneNumber" {
+		t.Errorf("phone_number diff = %+v", d)
+	}
+	if d := kinds["email"]; d.Kind != DiffRemoved {
+		t.Errorf("email diff = %+v", d)
+	}
+	if d := kinds["notes"]; d.Kind != DiffAdded {
+		t.Errorf("notes diff = %+v", d)
+	}
+
+	if err := a.EditAccountChange(changes[0].ChangeId, "email", "not an address"); err == nil {
+		t.Error("EditAccountChange accepted an invalid email")
+	}
+	if err := a.EditAccountChange(changes[0].ChangeId, "email", "new@example.com"); err != nil {
+		t.Fatalf("EditAccountChange: %v", err)
+	}
+	changes, _ = database.GetPendingAccountChanges(db)
+	diffs, _ = a.AccountChangeDiff(changes[0])
+	for _, d := range diffs {
+		if d.Field == "email" && (d.New != "new@example.com" || d.Kind != DiffChanged) {
+			t.Errorf("edited email diff = %+v", d)
+		}
+	}
+
+	// A change the API rejected shows its errors and is queued again by an
+	// edit.
+	if err := database.SetAccountChangeValidationErrors(db, changes[0].ChangeId, map[string][]string{"phone_number": {"Enter a valid phone number."}}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := database.GetAccountChanges(db)
+	if err != nil || len(all) != 1 || all[0].Status != database.StatusValidationFailed || all[0].ValidationErrors["phone_number"][0] != "Enter a valid phone number." {
+		t.Fatalf("rejected change = %+v, %v", all, err)
+	}
+	if err := a.EditAccountChange(changes[0].ChangeId, "phone_number", "555-0142"); err != nil {
+		t.Fatalf("EditAccountChange on a rejected change: %v", err)
+	}
+	changes, _ = database.GetPendingAccountChanges(db)
+	if len(changes) != 1 || changes[0].ValidationErrors != nil {
+		t.Fatalf("edited change was not queued again: %+v", changes)
+	}
+
+	if err := database.UpdatePendingChangeStatus(db, "AccountsPendingChanges", changes[0].ChangeId, "completed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.EditAccountChange(changes[0].ChangeId, "email", "late@example.com"); !errors.Is(err, database.ErrChangeNotPending) {
+		t.Errorf("EditAccountChange on a pushed change = %v", err)
+	}
+}
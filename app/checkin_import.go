@@ -0,0 +1,297 @@
+package app
+
+import (
+	"badgermaps/database"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkinImportHeaders are the CSV headers each check-in field is read from
+// when the import is not told which column holds it. Headers are matched
+// ignoring case, with spaces and dashes read as underscores.
+var checkinImportHeaders = map[string][]string{
+	"account_id":   {"account_id", "accountid", "account"},
+	"log_datetime": {"log_datetime", "logdatetime", "datetime", "date", "logged_at", "checkin_date"},
+	"type":         {"type", "checkin_type", "activity"},
+	"comments":     {"comments", "comment", "notes"},
+	"crm_id":       {"crm_id", "crmid"},
+	"created_by":   {"created_by", "createdby", "rep"},
+}
+
+// legacyTimeLayouts are the date formats older exports use besides the ones
+// parseDateTime reads. They are read in the display timezone.
+var legacyTimeLayouts = []string{
+	"2006-01-02",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+	"1/2/2006",
+}
+
+// CheckinImportFields lists the check-in fields a CSV column can be mapped
+// to.
+func CheckinImportFields() []string {
+	fields := make([]string, 0, len(checkinImportHeaders))
+	for field := range checkinImportHeaders {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// CheckinImportOptions controls ImportCheckins.
+type CheckinImportOptions struct {
+	// Columns maps check-in fields to the CSV headers holding them. Fields
+	// left out are found by their usual header names.
+	Columns map[string]string
+	// SkipInvalid stages the valid rows even when others are invalid.
+	SkipInvalid bool
+	// DryRun reports what would be staged without staging it.
+	DryRun bool
+}
+
+// CheckinImportProblem is a row that could not be imported. Line counts the
+// header as line 1.
+type CheckinImportProblem struct {
+	Line int
+	Err  string
+}
+
+// CheckinImportReport is the outcome of an import. Rows is every data row;
+// each is new, a duplicate, or invalid.
+type CheckinImportReport struct {
+	Rows int
+	// New counts the rows that are staged, or would be on a dry run.
+	New int
+	// Existing counts rows matching a check-in stored or already staged for
+	// the account, and Repeated rows matching an earlier row of the file.
+	Existing int
+	Repeated int
+	Invalid  []CheckinImportProblem
+	// Staged holds the requests staged; it is empty on a dry run.
+	Staged []StageRequest
+}
+
+// ImportCheckins reads legacy check-in history from CSV and stages each new
+// row as a check-in CREATE, to be reviewed and pushed like any staged
+// change. A row is a duplicate when its account already has a check-in,
+// stored or staged, logged in the same minute with the same comments,
+// ignoring case and spacing. Nothing is staged when a row is invalid unless
+// SkipInvalid is set; the report lists the invalid rows either way.
+func (a *App) ImportCheckins(r io.Reader, opts CheckinImportOptions) (*CheckinImportReport, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header: %w", err)
+	}
+	columns, err := checkinImportColumns(header, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CheckinImportReport{}
+	loc := a.DisplayLocation()
+	known := make(map[int]map[string]bool)
+	seen := make(map[string]bool)
+	var requests []StageRequest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		report.Rows++
+		request, key, err := a.checkinImportRow(record, columns, loc)
+		if err == nil && known[request.ID] == nil {
+			known[request.ID], err = a.knownCheckinKeys(request.ID, loc)
+		}
+		if err != nil {
+			report.Invalid = append(report.Invalid, CheckinImportProblem{Line: line, Err: err.Error()})
+			continue
+		}
+		switch {
+		case known[request.ID][key]:
+			report.Existing++
+		case seen[key]:
+			report.Repeated++
+		default:
+			seen[key] = true
+			requests = append(requests, request)
+		}
+	}
+	report.New = len(requests)
+
+	if len(report.Invalid) > 0 && !opts.SkipInvalid {
+		return report, fmt.Errorf("%d row(s) are invalid; nothing was staged", len(report.Invalid))
+	}
+	if opts.DryRun || len(requests) == 0 {
+		return report, nil
+	}
+	staged, err := a.StageChanges(requests)
+	report.Staged = staged
+	return report, err
+}
+
+// checkinImportColumns finds the column index of each field in header.
+func checkinImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[normalizeImportHeader(name)] = i
+	}
+	columns := make(map[string]int)
+	for field, name := range mapping {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if _, ok := checkinImportHeaders[field]; !ok {
+			return nil, fmt.Errorf("unknown check-in field %q (expected %s)", field, strings.Join(CheckinImportFields(), ", "))
+		}
+		i, ok := index[normalizeImportHeader(name)]
+		if !ok {
+			return nil, fmt.Errorf("the file has no column %q for %s", name, field)
+		}
+		columns[field] = i
+	}
+	for field, names := range checkinImportHeaders {
+		if _, mapped := columns[field]; mapped {
+			continue
+		}
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	var missing []string
+	for _, field := range []string{"account_id", "log_datetime", "type"} {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no column found for %s; map one with field=Header", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+func normalizeImportHeader(name string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkinImportRow turns a record into a stage request and its duplicate
+// key.
+func (a *App) checkinImportRow(record []string, columns map[string]int, loc *time.Location) (StageRequest, string, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	accountID, err := strconv.Atoi(value("account_id"))
+	if err != nil || accountID <= 0 {
+		return StageRequest{}, "", fmt.Errorf("account_id %q is not an account id", value("account_id"))
+	}
+	if value("type") == "" {
+		return StageRequest{}, "", fmt.Errorf("type is empty")
+	}
+	logged, err := parseImportTime(value("log_datetime"), loc)
+	if err != nil {
+		return StageRequest{}, "", fmt.Errorf("log_datetime: %w", err)
+	}
+	fields := map[string]string{
+		"type":         value("type"),
+		"log_datetime": logged.UTC().Format(StoredTimeLayout),
+	}
+	for _, field := range []string{"comments", "crm_id", "created_by"} {
+		if v := value(field); v != "" {
+			fields[field] = v
+		}
+	}
+	key := checkinImportKey(accountID, logged, fields["comments"])
+	return StageRequest{
+		Entity:         StageEntityCheckin,
+		ID:             accountID,
+		ChangeType:     "CREATE",
+		Fields:         fields,
+		IdempotencyKey: "import-" + key[:32],
+	}, key, nil
+}
+
+// knownCheckinKeys returns the duplicate keys of the account's stored and
+// staged check-ins. Check-ins with an unreadable time are left out.
+func (a *App) knownCheckinKeys(accountID int, loc *time.Location) (map[string]bool, error) {
+	if err := a.requireAccount(accountID); err != nil {
+		return nil, err
+	}
+	existing, err := database.GetAccountCheckinKeys(a.DB, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the check-ins of account %d: %w", accountID, err)
+	}
+	keys := make(map[string]bool, len(existing))
+	for _, checkin := range existing {
+		if logged, _, err := parseDateTime(strings.TrimSpace(checkin.LogDatetime), loc); err == nil {
+			keys[checkinImportKey(accountID, logged, checkin.Comments)] = true
+		}
+	}
+	return keys, nil
+}
+
+// checkinImportKey identifies a check-in by its account, the minute it was
+// logged, and a hash of its comments with case and spacing ignored.
+func checkinImportKey(accountID int, logged time.Time, comments string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(comments)), " ")
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s", accountID, logged.UTC().Truncate(time.Minute).Format(StoredTimeLayout), normalized)))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseImportTime reads a timestamp in any format parseDateTime accepts or
+// a legacy format such as 3/14/2021 2:30 PM.
+func parseImportTime(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("is empty")
+	}
+	if t, _, err := parseDateTime(value, loc); err == nil {
+		return t, nil
+	}
+	for _, layout := range legacyTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a recognized timestamp", value)
+}
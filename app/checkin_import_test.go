@@ -0,0 +1,78 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestImportCheckins(t *testing.T) {
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "import.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	for _, stmt := range []string{
+		`INSERT INTO Accounts (AccountId, FullName) VALUES (1, 'Acme'), (2, 'Globex')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, Type, Comments) VALUES
+			(10, 1, '2021-03-14T14:30:00Z', 'Visit', 'Dropped off  samples')`,
+	} {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	csv := "Customer,When,Activity,Notes\n" +
+		"1,2021-03-14T14:30:45Z,Visit,dropped off samples\n" +
+		"1,3/15/2021 9:00 AM,Call,Follow up\n" +
+		"1,2021-03-15T09:00:00,Call,follow  up\n" +
+		"2,2021-04-01,Visit,\n" +
+		"\n"
+	columns := map[string]string{"account_id": "customer", "log_datetime": "When", "type": "activity", "comments": "notes"}
+	report, err := a.ImportCheckins(strings.NewReader(csv), CheckinImportOptions{Columns: columns, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 4 || report.New != 2 || report.Existing != 1 || report.Repeated != 1 || len(report.Staged) != 0 {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+
+	report, err = a.ImportCheckins(strings.NewReader(csv+"3,2021-04-02,Visit,x\n1,yesterday,Visit,x\n"), CheckinImportOptions{Columns: columns})
+	if err == nil || len(report.Invalid) != 2 || report.Invalid[0].Line != 7 || report.Invalid[1].Line != 8 {
+		t.Fatalf("want the invalid rows reported and nothing staged, got %+v, %v", report, err)
+	}
+	if changes, err := database.GetPendingCheckinChanges(db); err != nil || len(changes) != 0 {
+		t.Fatalf("staged %d changes despite invalid rows (%v)", len(changes), err)
+	}
+
+	report, err = a.ImportCheckins(strings.NewReader(csv), CheckinImportOptions{Columns: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Staged) != 2 || report.Staged[0].Fields["log_datetime"] != "2021-03-15T09:00:00Z" {
+		t.Fatalf("unexpected staged changes: %+v", report.Staged)
+	}
+	report, err = a.ImportCheckins(strings.NewReader(csv), CheckinImportOptions{Columns: columns})
+	if err != nil || report.New != 0 || report.Existing != 4 {
+		t.Fatalf("re-import = %+v, %v; want every row already known", report, err)
+	}
+
+	if _, err := a.ImportCheckins(strings.NewReader("Account,Notes\n1,x\n"), CheckinImportOptions{}); err == nil {
+		t.Fatal("want an error when required columns are missing")
+	}
+	if _, err := a.ImportCheckins(strings.NewReader(csv), CheckinImportOptions{Columns: map[string]string{"owner": "Notes"}}); err == nil {
+		t.Fatal("want an error for an unknown field")
+	}
+}
@@ -0,0 +1,335 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/guregu/null/v6"
+)
+
+// ColumnKeyEnv names the environment variable that supplies the key for
+// encrypted columns, base64-encoded. Without it the key is kept in the OS
+// keychain.
+const ColumnKeyEnv = "BADGERMAPS_COLUMN_KEY"
+
+// DefaultEncryptedColumns are the Accounts columns 'db encrypt-columns'
+// encrypts when none are named.
+var DefaultEncryptedColumns = []string{"PhoneNumber", "Email", "Notes"}
+
+// encryptedValuePrefix marks a stored value as AES-GCM ciphertext, so
+// values are never encrypted twice and plain ones are told apart.
+const encryptedValuePrefix = "enc:v1:"
+
+// EncryptedPlaceholder stands in for an encrypted value in sessions
+// without the key.
+const EncryptedPlaceholder = "(encrypted)"
+
+// columnKeychainAccount names the keychain entry for the column key of the
+// database at path.
+func columnKeychainAccount(path string) string {
+	return "columns:" + strings.TrimPrefix(dbKeychainAccount(path), "db:")
+}
+
+// EncryptedColumns returns the Accounts columns stored encrypted, from
+// db.encrypted_columns.
+func (a *App) EncryptedColumns() []string {
+	if a.Config == nil {
+		return nil
+	}
+	return a.Config.DB.EncryptedColumns
+}
+
+// columnCipher returns the AES-GCM cipher for encrypted columns. The key
+// comes from BADGERMAPS_COLUMN_KEY or the keychain; with create, a missing
+// key is generated and stored in the keychain. Sessions that cannot get
+// the key are not authorized to read the columns.
+func (a *App) columnCipher(create bool) (cipher.AEAD, error) {
+	path, err := a.sqlitePath()
+	if err != nil {
+		return nil, err
+	}
+	a.columnCipherMu.Lock()
+	defer a.columnCipherMu.Unlock()
+	if a.columnAEAD != nil && a.columnAEADPath == path {
+		return a.columnAEAD, nil
+	}
+
+	var key []byte
+	if encoded := strings.TrimSpace(os.Getenv(ColumnKeyEnv)); encoded != "" {
+		if key, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("%s is not base64: %w", ColumnKeyEnv, err)
+		}
+	} else {
+		account := columnKeychainAccount(path)
+		stored, err := utils.KeychainGet(account)
+		if err != nil && !errors.Is(err, utils.ErrKeychainUnavailable) {
+			return nil, fmt.Errorf("could not read the column key from the keychain: %w", err)
+		}
+		switch {
+		case stored != "":
+			if key, err = base64.StdEncoding.DecodeString(stored); err != nil {
+				return nil, fmt.Errorf("the column key in the keychain is not base64: %w", err)
+			}
+		case create:
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			if err := utils.KeychainSet(account, base64.StdEncoding.EncodeToString(key)); err != nil {
+				return nil, fmt.Errorf("could not store a new column key in the keychain; set %s to a base64-encoded 32-byte key instead: %w", ColumnKeyEnv, err)
+			}
+			a.Events.Dispatch(events.Infof("db", "Stored a new column encryption key in the keychain."))
+		default:
+			return nil, fmt.Errorf("no column encryption key; set %s or run on a machine whose keychain holds it", ColumnKeyEnv)
+		}
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("the column key must be 32 bytes, not %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	a.columnAEAD, a.columnAEADPath = aead, path
+	return aead, nil
+}
+
+func encryptColumnValue(aead cipher.AEAD, value string) (string, error) {
+	if value == "" || strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptColumnValue(aead cipher.AEAD, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedValuePrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt a value; it was encrypted with another key")
+	}
+	return string(plain), nil
+}
+
+// IsEncryptedValue reports whether a stored value is encrypted.
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// accountStringColumn returns the field of acc for an Accounts text column.
+func accountStringColumn(acc *models.Account, column string) (*null.String, bool) {
+	field := reflect.ValueOf(acc).Elem().FieldByName(column)
+	if !field.IsValid() {
+		return nil, false
+	}
+	value, ok := field.Addr().Interface().(*null.String)
+	return value, ok
+}
+
+// checkEncryptableColumns rejects columns that are not Accounts text
+// columns.
+func checkEncryptableColumns(columns []string) error {
+	for _, column := range columns {
+		if _, ok := accountStringColumn(&models.Account{}, column); !ok {
+			return fmt.Errorf("%s is not a text column of Accounts", column)
+		}
+	}
+	return nil
+}
+
+// ValidateEncryptedColumns checks db.encrypted_columns: the database must
+// be SQLite and each entry a text column of Accounts.
+func ValidateEncryptedColumns(cfg database.DBConfig) error {
+	if len(cfg.EncryptedColumns) == 0 {
+		return nil
+	}
+	if cfg.Type != "" && cfg.Type != "sqlite3" {
+		return fmt.Errorf("db.encrypted_columns is only supported for sqlite3 databases, not %s", cfg.Type)
+	}
+	if err := checkEncryptableColumns(cfg.EncryptedColumns); err != nil {
+		return fmt.Errorf("db.encrypted_columns: %w", err)
+	}
+	return nil
+}
+
+// EncryptAccountColumns encrypts the encrypted columns of an account before
+// it is stored. It fails when the key is not available, so a session
+// without it never stores those columns in the clear.
+func (a *App) EncryptAccountColumns(acc *models.Account) error {
+	return a.rewriteAccountColumns(acc, encryptColumnValue)
+}
+
+// DecryptAccountColumns decrypts the encrypted columns of a stored account,
+// for code that compares them with pulled or staged values.
+func (a *App) DecryptAccountColumns(acc *models.Account) error {
+	return a.rewriteAccountColumns(acc, decryptColumnValue)
+}
+
+// DecryptAccountForDisplay decrypts the encrypted columns of a stored
+// account to show it, replacing each with EncryptedPlaceholder in a
+// session without the key.
+func (a *App) DecryptAccountForDisplay(acc *models.Account) {
+	if err := a.DecryptAccountColumns(acc); err == nil {
+		return
+	}
+	for _, column := range a.EncryptedColumns() {
+		if value, ok := accountStringColumn(acc, column); ok && IsEncryptedValue(value.String) {
+			value.String = EncryptedPlaceholder
+		}
+	}
+}
+
+func (a *App) rewriteAccountColumns(acc *models.Account, fn func(cipher.AEAD, string) (string, error)) error {
+	columns := a.EncryptedColumns()
+	if len(columns) == 0 || acc == nil {
+		return nil
+	}
+	aead, err := a.columnCipher(false)
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		value, ok := accountStringColumn(acc, column)
+		if !ok || !value.Valid {
+			continue
+		}
+		rewritten, err := fn(aead, value.String)
+		if err != nil {
+			return fmt.Errorf("account %d, %s: %w", acc.AccountId.Int64, column, err)
+		}
+		value.String = rewritten
+	}
+	return nil
+}
+
+// DecryptTableRows decrypts the encrypted columns in rows read from the
+// Accounts table or a view of it, as the Explorer shows and exports them.
+// Without the key each encrypted value is replaced with
+// EncryptedPlaceholder.
+func (a *App) DecryptTableRows(headers []string, rows [][]string) {
+	encrypted := a.EncryptedColumns()
+	if len(encrypted) == 0 {
+		return
+	}
+	aead, _ := a.columnCipher(false)
+	for i, header := range headers {
+		if !containsFold(encrypted, header) {
+			continue
+		}
+		for _, row := range rows {
+			if i >= len(row) || !IsEncryptedValue(row[i]) {
+				continue
+			}
+			plain := EncryptedPlaceholder
+			if aead != nil {
+				if value, err := decryptColumnValue(aead, row[i]); err == nil {
+					plain = value
+				}
+			}
+			row[i] = plain
+		}
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncryptColumns encrypts the stored values of columns, adds them to
+// db.encrypted_columns, and saves the config. The key is generated on
+// first use. Only SQLite databases are supported.
+func (a *App) EncryptColumns(columns []string) (int, error) {
+	if err := checkEncryptableColumns(columns); err != nil {
+		return 0, err
+	}
+	aead, err := a.columnCipher(true)
+	if err != nil {
+		return 0, err
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return 0, fmt.Errorf("database is not connected")
+	}
+	var changed int
+	err = a.WithoutChangeCapture(func() error {
+		changed, err = database.RewriteAccountColumns(a.DB, columns, func(value string) (string, error) {
+			return encryptColumnValue(aead, value)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, column := range columns {
+		if !containsFold(a.Config.DB.EncryptedColumns, column) {
+			a.Config.DB.EncryptedColumns = append(a.Config.DB.EncryptedColumns, column)
+		}
+	}
+	if err := a.SaveConfig(); err != nil {
+		return changed, fmt.Errorf("values were encrypted but the config could not be saved; add them to db.encrypted_columns manually: %w", err)
+	}
+	a.Events.Dispatch(events.Infof("db", "Encrypted %d value(s) in %s.", changed, strings.Join(columns, ", ")))
+	return changed, nil
+}
+
+// DecryptColumns stores the values of every encrypted column in the clear
+// again and clears db.encrypted_columns.
+func (a *App) DecryptColumns() (int, error) {
+	columns := a.EncryptedColumns()
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	aead, err := a.columnCipher(false)
+	if err != nil {
+		return 0, err
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return 0, fmt.Errorf("database is not connected")
+	}
+	var changed int
+	err = a.WithoutChangeCapture(func() error {
+		changed, err = database.RewriteAccountColumns(a.DB, columns, func(value string) (string, error) {
+			return decryptColumnValue(aead, value)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	a.Config.DB.EncryptedColumns = nil
+	if err := a.SaveConfig(); err != nil {
+		return changed, fmt.Errorf("values were decrypted but the config could not be saved; clear db.encrypted_columns manually: %w", err)
+	}
+	a.Events.Dispatch(events.Infof("db", "Decrypted %d value(s) in %s.", changed, strings.Join(columns, ", ")))
+	return changed, nil
+}
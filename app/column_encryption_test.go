@@ -0,0 +1,81 @@
+package app
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestEncryptColumns(t *testing.T) {
+	t.Setenv(ColumnKeyEnv, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	dir := t.TempDir()
+	cfg := database.DBConfig{Type: "sqlite3", Path: filepath.Join(dir, "columns.db")}
+	db, err := database.NewDB(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	a.Config.DB = cfg
+	a.ConfigFile = filepath.Join(dir, "config.yaml")
+
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, LastName, PhoneNumber, Email, Notes) VALUES (1, 'Acme', '555-0100', 'ann@example.com', NULL)"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := a.EncryptColumns(DefaultEncryptedColumns); err != nil || changed != 2 {
+		t.Fatalf("EncryptColumns = %d, %v; want 2 values encrypted", changed, err)
+	}
+	if changed, err := a.EncryptColumns(DefaultEncryptedColumns); err != nil || changed != 0 {
+		t.Fatalf("EncryptColumns again = %d, %v; want nothing encrypted twice", changed, err)
+	}
+	var email, lastName string
+	if err := sqlDB.QueryRow("SELECT Email, LastName FROM Accounts WHERE AccountId = 1").Scan(&email, &lastName); err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedValue(email) || strings.Contains(email, "ann@") || lastName != "Acme" {
+		t.Fatalf("stored Email %q, LastName %q; want only Email encrypted", email, lastName)
+	}
+
+	rows := [][]string{{"1", email}}
+	a.DecryptTableRows([]string{"AccountId", "Email"}, rows)
+	if rows[0][1] != "ann@example.com" {
+		t.Errorf("DecryptTableRows gave %q", rows[0][1])
+	}
+	stored, err := database.GetAccountByID(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := NewApp()
+	locked.Config.DB = a.Config.DB
+	t.Setenv(ColumnKeyEnv, "")
+	locked.DecryptAccountForDisplay(stored)
+	if stored.Email.String != EncryptedPlaceholder {
+		t.Errorf("Email without the key = %q, want %q", stored.Email.String, EncryptedPlaceholder)
+	}
+
+	if changed, err := a.DecryptColumns(); err != nil || changed != 2 {
+		t.Fatalf("DecryptColumns = %d, %v; want 2", changed, err)
+	}
+	if err := sqlDB.QueryRow("SELECT Email FROM Accounts WHERE AccountId = 1").Scan(&email); err != nil || email != "ann@example.com" {
+		t.Errorf("Email after DecryptColumns = %q, %v", email, err)
+	}
+	if len(a.Config.DB.EncryptedColumns) != 0 {
+		t.Errorf("encrypted_columns = %v after DecryptColumns", a.Config.DB.EncryptedColumns)
+	}
+	if err := ValidateEncryptedColumns(database.DBConfig{Type: "sqlite3", EncryptedColumns: []string{"CustomNumeric"}}); err == nil {
+		t.Error("expected a numeric column to be rejected")
+	}
+}
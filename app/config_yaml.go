@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marshalPreservingComments encodes v as YAML and carries over comments from
+// the file currently at path so hand-written notes survive a save. Mapping
+// entries are matched by key and sequence items by their "name" field (falling
+// back to position), which keeps comments attached to event actions when
+// entries are added or removed.
+func marshalPreservingComments(v interface{}, path string) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil || len(existing) == 0 {
+		return yaml.Marshal(&doc)
+	}
+	var previous yaml.Node
+	if err := yaml.Unmarshal(existing, &previous); err != nil {
+		// A file we cannot parse has no comments worth keeping.
+		return yaml.Marshal(&doc)
+	}
+
+	out := &doc
+	if previous.Kind == yaml.DocumentNode {
+		out = &yaml.Node{
+			Kind:        yaml.DocumentNode,
+			HeadComment: previous.HeadComment,
+			FootComment: previous.FootComment,
+			Content:     []*yaml.Node{&doc},
+		}
+		if len(previous.Content) > 0 {
+			copyYAMLComments(&doc, previous.Content[0])
+		}
+	} else {
+		copyYAMLComments(&doc, &previous)
+	}
+	return yaml.Marshal(out)
+}
+
+func copyYAMLComments(dst, src *yaml.Node) {
+	if dst == nil || src == nil {
+		return
+	}
+	if dst.HeadComment == "" {
+		dst.HeadComment = src.HeadComment
+	}
+	if dst.LineComment == "" {
+		dst.LineComment = src.LineComment
+	}
+	if dst.FootComment == "" {
+		dst.FootComment = src.FootComment
+	}
+	if dst.Kind != src.Kind {
+		return
+	}
+
+	switch dst.Kind {
+	case yaml.MappingNode:
+		keys := make(map[string]int, len(src.Content)/2)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			keys[src.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			j, ok := keys[dst.Content[i].Value]
+			if !ok {
+				continue
+			}
+			copyYAMLComments(dst.Content[i], src.Content[j])
+			copyYAMLComments(dst.Content[i+1], src.Content[j+1])
+		}
+	case yaml.SequenceNode:
+		named := make(map[string]*yaml.Node)
+		for _, item := range src.Content {
+			if name := yamlItemName(item); name != "" {
+				named[name] = item
+			}
+		}
+		for i, item := range dst.Content {
+			if name := yamlItemName(item); name != "" {
+				copyYAMLComments(item, named[name])
+			} else if i < len(src.Content) {
+				copyYAMLComments(item, src.Content[i])
+			}
+		}
+	}
+}
+
+// yamlItemName returns the "name" value of a mapping node, if present.
+func yamlItemName(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "name" {
+			return n.Content[i+1].Value
+		}
+	}
+	return ""
+}
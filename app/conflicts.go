@@ -0,0 +1,214 @@
+package app
+
+import (
+	"badgermaps/database"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strategies of conflict_resolution, which settles a staged account change
+// whose account was changed in BadgerMaps after it was staged.
+const (
+	// ConflictAsk holds the change until the user picks, field by field,
+	// which values to keep.
+	ConflictAsk = "ask"
+	// ConflictLocal pushes the staged values over the remote ones.
+	ConflictLocal = "local"
+	// ConflictRemote discards the staged change.
+	ConflictRemote = "remote"
+	// ConflictRecent keeps whichever side was changed last: the staged
+	// change when it was staged after the account's last modified date in
+	// BadgerMaps, the remote values otherwise.
+	ConflictRecent = "recent"
+)
+
+// ConflictDeleteField stands for the staged delete in the fields of a
+// conflicting DELETE change, which stages no fields of its own.
+const ConflictDeleteField = "_delete"
+
+// ConflictResolutions lists the strategies of conflict_resolution.
+func ConflictResolutions() []string {
+	return []string{ConflictAsk, ConflictLocal, ConflictRemote, ConflictRecent}
+}
+
+// ParseConflictResolution checks a conflict_resolution value; "" is ask.
+func ParseConflictResolution(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ConflictAsk, nil
+	}
+	for _, strategy := range ConflictResolutions() {
+		if value == strategy {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("conflict_resolution must be one of %s, got %q", strings.Join(ConflictResolutions(), ", "), value)
+}
+
+// ConflictResolution returns the configured strategy. A misconfigured
+// conflict_resolution asks.
+func (a *App) ConflictResolution() string {
+	if a.Config == nil {
+		return ConflictAsk
+	}
+	strategy, err := ParseConflictResolution(a.Config.ConflictResolution)
+	if err != nil {
+		return ConflictAsk
+	}
+	return strategy
+}
+
+// ConflictWinner returns which side of a conflicting change the configured
+// strategy keeps: ConflictLocal, ConflictRemote, or ConflictAsk when the
+// user has to pick. Under ConflictRecent a change whose account has no
+// readable last modified date is left to the user.
+func (a *App) ConflictWinner(change database.AccountPendingChange) string {
+	strategy := a.ConflictResolution()
+	if strategy != ConflictRecent {
+		return strategy
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return ConflictAsk
+	}
+	account, err := database.GetAccountByID(a.DB, change.AccountId)
+	if err != nil || account == nil {
+		return ConflictAsk
+	}
+	modified, ok := account.LastModifiedDate.Time()
+	if !ok {
+		return ConflictAsk
+	}
+	if change.CreatedAt.After(modified) {
+		return ConflictLocal
+	}
+	return ConflictRemote
+}
+
+// AccountConflict is a staged account change held back because its account
+// changed after it was staged. Fields lists the staged fields whose value
+// differs from the account as now stored, with Old the remote value and New
+// the staged one.
+type AccountConflict struct {
+	Change         database.AccountPendingChange
+	Fields         []FieldDiff
+	BaseVersion    string
+	CurrentVersion string
+}
+
+// AccountConflicts returns the staged account changes waiting on a conflict
+// to be resolved, oldest first.
+func (a *App) AccountConflicts() ([]AccountConflict, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	changes, err := database.GetAccountChanges(a.DB)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []AccountConflict
+	for _, change := range changes {
+		if !conflictResolvable(change) {
+			continue
+		}
+		conflict, err := a.accountConflict(change)
+		if err != nil {
+			return nil, err
+		}
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Change.CreatedAt.Before(conflicts[j].Change.CreatedAt)
+	})
+	return conflicts, nil
+}
+
+// conflictResolvable reports whether change can be resolved: it is queued,
+// or a push failed it, but not because BadgerMaps rejected its values.
+func conflictResolvable(change database.AccountPendingChange) bool {
+	if change.ChangeType != "UPDATE" && change.ChangeType != "DELETE" {
+		return false
+	}
+	return change.Status == "pending" || change.Status == "failed"
+}
+
+func (a *App) accountConflict(change database.AccountPendingChange) (*AccountConflict, error) {
+	version, err := database.GetAccountChangeConflict(a.DB, change.ChangeId)
+	if err != nil || version == nil {
+		return nil, err
+	}
+	conflict := &AccountConflict{Change: change, BaseVersion: version.BaseVersion, CurrentVersion: version.CurrentVersion}
+	if change.ChangeType == "DELETE" {
+		conflict.Fields = []FieldDiff{{Field: ConflictDeleteField, Label: "Delete account", Old: "keep", New: "delete", Type: "text", Kind: DiffChanged}}
+		return conflict, nil
+	}
+	diffs, err := a.AccountChangeDiff(change)
+	if err != nil {
+		return nil, fmt.Errorf("change %d: %w", change.ChangeId, err)
+	}
+	for _, diff := range diffs {
+		if diff.Kind != DiffUnchanged {
+			conflict.Fields = append(conflict.Fields, diff)
+		}
+	}
+	sort.Slice(conflict.Fields, func(i, j int) bool { return conflict.Fields[i].Label < conflict.Fields[j].Label })
+	return conflict, nil
+}
+
+// ResolveAccountConflict settles a conflicting change with the side picked
+// for each field: the fields in keepLocal set to true stay staged, and the
+// rest take the remote value. A change left with no fields, or a delete
+// not kept, is discarded; otherwise it is rebased on the account as now
+// stored and queued for the next push. It reports whether the change was
+// kept.
+func (a *App) ResolveAccountConflict(changeID int, keepLocal map[string]bool) (bool, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return false, fmt.Errorf("database is not connected")
+	}
+	changes, err := database.GetAccountChanges(a.DB)
+	if err != nil {
+		return false, err
+	}
+	var change *database.AccountPendingChange
+	for i := range changes {
+		if changes[i].ChangeId == changeID && conflictResolvable(changes[i]) {
+			change = &changes[i]
+			break
+		}
+	}
+	if change == nil {
+		return false, database.ErrChangeNotPending
+	}
+
+	staged := change.Changes
+	keep := keepLocal[ConflictDeleteField]
+	if change.ChangeType != "DELETE" {
+		fields, err := parseAccountChanges(change.Changes)
+		if err != nil {
+			return false, err
+		}
+		for field := range fields {
+			if !keepLocal[field] {
+				delete(fields, field)
+			}
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return false, err
+		}
+		staged, keep = string(data), len(fields) > 0
+	}
+	if !keep {
+		if err := database.DeleteAccountPendingChange(a.DB, changeID); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	if err := database.RebaseAccountPendingChange(a.DB, changeID, staged); err != nil {
+		return false, err
+	}
+	return true, nil
+}
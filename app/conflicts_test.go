@@ -0,0 +1,85 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestParseConflictResolution(t *testing.T) {
+	for value, want := range map[string]string{"": ConflictAsk, "Local": ConflictLocal, " recent ": ConflictRecent} {
+		if got, err := ParseConflictResolution(value); err != nil || got != want {
+			t.Errorf("ParseConflictResolution(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseConflictResolution("newest"); err == nil {
+		t.Error("expected an unknown strategy to be rejected")
+	}
+}
+
+func TestResolveAccountConflict(t *testing.T) {
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "conflicts.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, FirstName, LastName, UpdatedAt) VALUES (1, 'Ann', 'Old', '2024-01-01 00:00:00')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.StageAccountChange(db, 1, "UPDATE", `{"first_name":"Anna","last_name":"Mine"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.StageAccountChange(db, 1, "UPDATE", `{"last_name":"Other"}`); err != nil {
+		t.Fatal(err)
+	}
+	conflicts, err := a.AccountConflicts()
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("AccountConflicts before the remote change = %v, %v; want none", conflicts, err)
+	}
+
+	// A pull stores a remote edit of both fields.
+	if _, err := sqlDB.Exec("UPDATE Accounts SET FirstName = 'Anne', LastName = 'Theirs', UpdatedAt = '2024-03-01 00:00:00' WHERE AccountId = 1"); err != nil {
+		t.Fatal(err)
+	}
+	conflicts, err = a.AccountConflicts()
+	if err != nil || len(conflicts) != 2 {
+		t.Fatalf("AccountConflicts = %v, %v; want 2", conflicts, err)
+	}
+	first := conflicts[0]
+	if len(first.Fields) != 2 || first.Fields[1].Field != "last_name" || first.Fields[1].Old != "Theirs" || first.Fields[1].New != "Mine" {
+		t.Fatalf("conflicting fields = %+v", first.Fields)
+	}
+
+	kept, err := a.ResolveAccountConflict(first.Change.ChangeId, map[string]bool{"last_name": true})
+	if err != nil || !kept {
+		t.Fatalf("ResolveAccountConflict = %v, %v; want kept", kept, err)
+	}
+	kept, err = a.ResolveAccountConflict(conflicts[1].Change.ChangeId, map[string]bool{"last_name": false})
+	if err != nil || kept {
+		t.Fatalf("ResolveAccountConflict keeping remote = %v, %v; want discarded", kept, err)
+	}
+
+	changes, err := database.GetAccountChanges(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Changes != `{"last_name":"Mine"}` || changes[0].Status != "pending" {
+		t.Fatalf("changes after resolving = %+v", changes)
+	}
+	if conflicts, err := a.AccountConflicts(); err != nil || len(conflicts) != 0 {
+		t.Fatalf("AccountConflicts after resolving = %v, %v; want none", conflicts, err)
+	}
+}
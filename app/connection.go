@@ -0,0 +1,264 @@
+package app
+
+import (
+	"badgermaps/api"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"badgermaps/events"
+	"sync"
+)
+
+// SchemaStatus is the result of the last schema validation of the
+// connected database.
+type SchemaStatus string
+
+const (
+	// SchemaUnchecked means no validation has run since the database
+	// connected or its schema last changed.
+	SchemaUnchecked SchemaStatus = ""
+	SchemaChecking  SchemaStatus = "checking"
+	SchemaValid     SchemaStatus = "valid"
+	SchemaInvalid   SchemaStatus = "invalid"
+)
+
+// ConnectionStatus is a snapshot of the API and database connection flags.
+// Seq increases each time any field changes. APIChecking is set while
+// CheckAPI tests the API in the background. Schema is the cached result of
+// CheckSchema for the connected database.
+type ConnectionStatus struct {
+	API         bool
+	APIChecking bool
+	Database    bool
+	Schema      SchemaStatus
+	Seq         uint64
+}
+
+// ConnectionManager owns the connection status shown across the app. Changes
+// go through it so every listener sees them in the order they happened, as
+// one connection.status.changed event each.
+type ConnectionManager struct {
+	app *App
+
+	// mu guards status; deliverMu is taken before mu is released so events
+	// are delivered in the same order the changes were made.
+	mu        sync.Mutex
+	deliverMu sync.Mutex
+	status    ConnectionStatus
+	// checkingAPI is the client a background CheckAPI is testing.
+	checkingAPI *api.APIClient
+	// schemaDB is the database the cached schema result belongs to, and
+	// schemaGen increases each time the result is invalidated, so a check
+	// that finishes after an invalidation is dropped.
+	schemaDB  database.DB
+	schemaGen uint64
+	schema    SchemaStatus
+	schemaErr error
+
+	subscribers map[int]func(ConnectionStatus)
+	nextID      int
+}
+
+// Connections returns the app's connection manager.
+func (a *App) Connections() *ConnectionManager {
+	a.connectionsOnce.Do(func() {
+		a.connections = &ConnectionManager{app: a, subscribers: make(map[int]func(ConnectionStatus))}
+	})
+	return a.connections
+}
+
+// Status returns the latest connection status.
+func (m *ConnectionManager) Status() ConnectionStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// Subscribe calls fn with each new status, in order, until the returned
+// function is called. fn must not change the connection status itself.
+func (m *ConnectionManager) Subscribe(fn func(ConnectionStatus)) (unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.subscribers[id] = fn
+	return func() {
+		m.mu.Lock()
+		delete(m.subscribers, id)
+		m.mu.Unlock()
+	}
+}
+
+// SetAPIConnected records whether the API answered.
+func (m *ConnectionManager) SetAPIConnected(connected bool) {
+	m.update(func() {
+		if m.app.API != nil {
+			m.app.API.SetConnected(connected)
+		}
+	})
+}
+
+// SetDBConnected records whether the database answered.
+func (m *ConnectionManager) SetDBConnected(connected bool) {
+	m.update(func() {
+		if m.app.DB != nil {
+			m.app.DB.SetConnected(connected)
+		}
+	})
+}
+
+// CheckAPI tests the API connection and records the result. With wait set
+// it returns once the test does. Otherwise the test runs in the background
+// and the status reports APIChecking until it returns, so the GUI can show
+// its window without waiting on the network.
+func (m *ConnectionManager) CheckAPI(wait bool) {
+	client := m.app.API
+	if client == nil {
+		return
+	}
+	test := func() {
+		end := m.app.StartPhase(StartupAPITest)
+		err := client.TestAPIConnection()
+		end()
+		if err != nil {
+			m.app.Events.Dispatch(events.Debugf("api", "API connection test failed: %v", err))
+		}
+		m.update(func() {
+			if m.checkingAPI == client {
+				m.checkingAPI = nil
+			}
+		})
+	}
+	if wait {
+		test()
+		return
+	}
+	m.update(func() { m.checkingAPI = client })
+	go test()
+}
+
+// Schema returns the cached schema validation result and the error of an
+// invalid schema. When the database is connected and nothing is cached, it
+// starts a check in the background and returns SchemaChecking, so views can
+// render at once and update when the connection.status.changed event for
+// the result arrives.
+func (m *ConnectionManager) Schema() (SchemaStatus, error) {
+	m.mu.Lock()
+	m.syncSchemaDB()
+	status, err, db := m.schema, m.schemaErr, m.schemaDB
+	m.mu.Unlock()
+	if status == SchemaUnchecked && db != nil {
+		return m.CheckSchema(false)
+	}
+	return status, err
+}
+
+// CheckSchema validates the schema of the connected database and caches the
+// result. With wait set it returns once the validation does. Otherwise the
+// validation runs in the background, unless one already is, and the status
+// reports SchemaChecking until it finishes.
+func (m *ConnectionManager) CheckSchema(wait bool) (SchemaStatus, error) {
+	var db database.DB
+	var gen uint64
+	var current SchemaStatus
+	var currentErr error
+	m.update(func() {
+		current, currentErr = m.schema, m.schemaErr
+		if m.schemaDB == nil || (m.schema == SchemaChecking && !wait) {
+			return
+		}
+		m.schema, m.schemaErr = SchemaChecking, nil
+		db, gen = m.schemaDB, m.schemaGen
+	})
+	if db == nil {
+		return current, currentErr
+	}
+	validate := func() (SchemaStatus, error) {
+		err := db.ValidateSchema(&state.State{Quiet: true})
+		status := SchemaValid
+		if err != nil {
+			status = SchemaInvalid
+			m.app.Events.Dispatch(events.Debugf("db", "Schema validation failed: %v", err))
+		}
+		m.update(func() {
+			if m.schemaDB == db && m.schemaGen == gen {
+				m.schema, m.schemaErr = status, err
+			}
+		})
+		return status, err
+	}
+	if wait {
+		return validate()
+	}
+	go validate()
+	return SchemaChecking, nil
+}
+
+// InvalidateSchema drops the cached schema result, as after the schema was
+// enforced, reset, or migrated. The next Schema call checks it again.
+func (m *ConnectionManager) InvalidateSchema() {
+	m.update(func() {
+		m.schemaGen++
+		m.schema, m.schemaErr = SchemaUnchecked, nil
+	})
+}
+
+// syncSchemaDB drops the cached schema result when the database was
+// replaced or disconnected. m.mu must be held.
+func (m *ConnectionManager) syncSchemaDB() {
+	var db database.DB
+	if m.app.DB != nil && m.app.DB.IsConnected() {
+		db = m.app.DB
+	}
+	if db == m.schemaDB {
+		return
+	}
+	m.schemaDB = db
+	m.schemaGen++
+	m.schema, m.schemaErr = SchemaUnchecked, nil
+}
+
+// Refresh re-reads the client flags, for example after a client was replaced
+// or tested, and announces the status if it changed.
+func (m *ConnectionManager) Refresh() {
+	m.update(nil)
+}
+
+func (m *ConnectionManager) update(apply func()) {
+	m.mu.Lock()
+	m.syncSchemaDB()
+	if apply != nil {
+		apply()
+	}
+	next := ConnectionStatus{
+		API:         m.app.API != nil && m.app.API.IsConnected(),
+		APIChecking: m.checkingAPI != nil && m.checkingAPI == m.app.API,
+		Database:    m.app.DB != nil && m.app.DB.IsConnected(),
+		Seq:         m.status.Seq,
+	}
+	m.syncSchemaDB()
+	next.Schema = m.schema
+	if next == m.status {
+		m.mu.Unlock()
+		return
+	}
+	next.Seq++
+	m.status = next
+	subscribers := make([]func(ConnectionStatus), 0, len(m.subscribers))
+	for _, fn := range m.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	m.deliverMu.Lock()
+	m.mu.Unlock()
+	defer m.deliverMu.Unlock()
+
+	if m.app.Events != nil {
+		m.app.Events.Dispatch(events.Event{
+			Type:    "connection.status.changed",
+			Source:  "app",
+			Payload: events.ConnectionStatusPayload{API: next.API, APIChecking: next.APIChecking, Database: next.Database, Schema: string(next.Schema), Seq: next.Seq},
+		})
+	}
+	for _, fn := range subscribers {
+		fn(next)
+	}
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"badgermaps/api"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+func TestConnectionManagerOrdersChanges(t *testing.T) {
+	a := NewApp()
+	a.API = &api.APIClient{}
+	m := a.Connections()
+
+	var mu sync.Mutex
+	var seen []ConnectionStatus
+	unsubscribe := m.Subscribe(func(s ConnectionStatus) {
+		mu.Lock()
+		seen = append(seen, s)
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(connected bool) {
+			defer wg.Done()
+			m.SetAPIConnected(connected)
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	if len(seen) == 0 {
+		t.Fatal("expected at least one status change")
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i].Seq != seen[i-1].Seq+1 {
+			t.Fatalf("changes delivered out of order: %+v", seen)
+		}
+		if seen[i].API == seen[i-1].API {
+			t.Fatalf("unchanged status was announced: %+v", seen)
+		}
+	}
+	last, delivered := seen[len(seen)-1], len(seen)
+	mu.Unlock()
+	if last != m.Status() || last.API != a.API.IsConnected() {
+		t.Fatalf("last delivered %+v, status %+v, client %v", last, m.Status(), a.API.IsConnected())
+	}
+
+	unsubscribe()
+	m.SetAPIConnected(!last.API)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != delivered {
+		t.Fatal("unsubscribed listener was called")
+	}
+}
+
+func TestConnectionManagerDispatchesEvent(t *testing.T) {
+	a := NewApp()
+	a.API = &api.APIClient{}
+	got := make(chan events.ConnectionStatusPayload, 2)
+	a.Events.Subscribe("connection.status.changed", func(e events.Event) {
+		got <- e.Payload.(events.ConnectionStatusPayload)
+	})
+
+	a.Connections().SetAPIConnected(true)
+	a.Connections().SetAPIConnected(true)
+	a.Events.WaitForDrain(time.Second)
+
+	if len(got) != 1 {
+		t.Fatalf("expected one event for one change, got %d", len(got))
+	}
+	if p := <-got; !p.API || p.Database || p.Seq != 1 {
+		t.Fatalf("payload = %+v", p)
+	}
+}
+
+func TestCheckAPIInBackground(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"id":7}`))
+	}))
+	defer server.Close()
+
+	a := NewApp()
+	a.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL, APIKey: "key"})
+	m := a.Connections()
+	done := make(chan ConnectionStatus, 1)
+	m.Subscribe(func(s ConnectionStatus) {
+		if !s.APIChecking {
+			done <- s
+		}
+	})
+
+	m.CheckAPI(false)
+	if s := m.Status(); !s.APIChecking || s.API {
+		t.Fatalf("status while the test runs = %+v, want APIChecking", s)
+	}
+	close(release)
+	select {
+	case s := <-done:
+		if !s.API {
+			t.Fatalf("status after the test = %+v, want the API connected", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the background API test never finished")
+	}
+	if a.API.UserID() != 7 {
+		t.Errorf("UserID = %d, want 7", a.API.UserID())
+	}
+	phases := a.StartupPhases()
+	if len(phases) != 1 || phases[0].Name != StartupAPITest {
+		t.Errorf("startup phases = %+v, want the API test", phases)
+	}
+
+	// Once startup is over, later tests are not timed as startup phases.
+	a.LogStartupSummary("Ready")
+	m.CheckAPI(true)
+	if got := len(a.StartupPhases()); got != 1 {
+		t.Errorf("%d startup phases after startup ended, want 1", got)
+	}
+}
+
+// gatedSchemaDB counts schema validations and holds each one until release
+// is signalled.
+type gatedSchemaDB struct {
+	database.DB
+	release chan struct{}
+	mu      sync.Mutex
+	calls   int
+}
+
+func (db *gatedSchemaDB) ValidateSchema(s *state.State) error {
+	db.mu.Lock()
+	db.calls++
+	db.mu.Unlock()
+	<-db.release
+	return db.DB.ValidateSchema(s)
+}
+
+func TestConnectionManagerCachesSchema(t *testing.T) {
+	inner, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "schema.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer inner.Close()
+	inner.SetConnected(true)
+	db := &gatedSchemaDB{DB: inner, release: make(chan struct{})}
+	a := NewApp()
+	a.DB = db
+	m := a.Connections()
+
+	results := make(chan ConnectionStatus, 10)
+	m.Subscribe(func(s ConnectionStatus) {
+		if s.Schema == SchemaValid || s.Schema == SchemaInvalid {
+			results <- s
+		}
+	})
+	if status, _ := m.Schema(); status != SchemaChecking {
+		t.Fatalf("first Schema() = %q, want a background check", status)
+	}
+	if status, _ := m.Schema(); status != SchemaChecking {
+		t.Fatalf("Schema() during the check = %q", status)
+	}
+	db.release <- struct{}{}
+	select {
+	case s := <-results:
+		if s.Schema != SchemaInvalid {
+			t.Fatalf("empty database checked as %q", s.Schema)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status change after the check finished")
+	}
+	if status, err := m.Schema(); status != SchemaInvalid || err == nil {
+		t.Fatalf("cached result = %q, %v", status, err)
+	}
+	if db.calls != 1 {
+		t.Fatalf("schema validated %d times, want once", db.calls)
+	}
+
+	if err := inner.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	m.InvalidateSchema()
+	if m.Status().Schema != SchemaUnchecked {
+		t.Fatalf("status after invalidation = %q", m.Status().Schema)
+	}
+	close(db.release)
+	if status, err := m.CheckSchema(true); status != SchemaValid || err != nil {
+		t.Fatalf("check after enforcing = %q, %v", status, err)
+	}
+
+	a.DB = nil
+	m.Refresh()
+	if status, _ := m.Schema(); status != SchemaUnchecked {
+		t.Fatalf("status without a database = %q", status)
+	}
+}
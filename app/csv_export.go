@@ -0,0 +1,261 @@
+package app
+
+import (
+	"badgermaps/database"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// CSV export delimiters, encodings and date formats, as csv_export takes
+// them.
+const (
+	CSVDelimiterComma     = "comma"
+	CSVDelimiterSemicolon = "semicolon"
+	CSVDelimiterTab       = "tab"
+
+	CSVEncodingUTF8    = "utf-8"
+	CSVEncodingUTF16LE = "utf-16le"
+
+	CSVDateAsStored = "stored"
+	CSVDateISO      = "iso"
+	CSVDateUS       = "us"
+	CSVDateEU       = "eu"
+)
+
+// CSVDelimiters, CSVEncodings and CSVDateFormats list the choices, in the
+// order the GUI offers them.
+var (
+	CSVDelimiters  = []string{CSVDelimiterComma, CSVDelimiterSemicolon, CSVDelimiterTab}
+	CSVEncodings   = []string{CSVEncodingUTF8, CSVEncodingUTF16LE}
+	CSVDateFormats = []string{CSVDateAsStored, CSVDateISO, CSVDateUS, CSVDateEU}
+)
+
+// CSVExportConfig sets how tables are written to CSV. Excel in locales
+// that use a decimal comma expects semicolons, and only detects UTF-8 with
+// a byte order mark; UTF-16LE always has one.
+type CSVExportConfig struct {
+	Delimiter    string `yaml:"delimiter,omitempty"`
+	Encoding     string `yaml:"encoding,omitempty"`
+	BOM          bool   `yaml:"bom,omitempty"`
+	QuoteHeaders bool   `yaml:"quote_headers,omitempty"`
+	// DateFormat rewrites dates and timestamps: stored (the default) leaves
+	// them as the database has them, iso writes 2006-01-02 15:04:05, us
+	// 01/02/2006 and eu 02/01/2006, each with the time when there is one.
+	DateFormat string `yaml:"date_format,omitempty"`
+}
+
+// Validate checks the choices.
+func (c CSVExportConfig) Validate() error {
+	if c.Delimiter != "" && !containsFold(CSVDelimiters, c.Delimiter) {
+		return fmt.Errorf("csv_export.delimiter must be one of %s, got %q", strings.Join(CSVDelimiters, ", "), c.Delimiter)
+	}
+	if c.Encoding != "" && !containsFold(CSVEncodings, c.Encoding) {
+		return fmt.Errorf("csv_export.encoding must be one of %s, got %q", strings.Join(CSVEncodings, ", "), c.Encoding)
+	}
+	if c.DateFormat != "" && !containsFold(CSVDateFormats, c.DateFormat) {
+		return fmt.Errorf("csv_export.date_format must be one of %s, got %q", strings.Join(CSVDateFormats, ", "), c.DateFormat)
+	}
+	return nil
+}
+
+// Comma returns the delimiter rune.
+func (c CSVExportConfig) Comma() rune {
+	switch strings.ToLower(c.Delimiter) {
+	case CSVDelimiterSemicolon:
+		return ';'
+	case CSVDelimiterTab:
+		return '\t'
+	}
+	return ','
+}
+
+// CSVExport returns the configured export options.
+func (a *App) CSVExport() CSVExportConfig {
+	if a.Config == nil {
+		return CSVExportConfig{}
+	}
+	return a.Config.CSVExport
+}
+
+// storedTimeLayouts are the ways dates and timestamps come out of the
+// supported databases, including time.Time printed with %v.
+var storedTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// formatCSVDate rewrites value in the date format, or returns it unchanged
+// when it is not a date.
+func formatCSVDate(value, format string) string {
+	var layout string
+	switch strings.ToLower(format) {
+	case CSVDateISO:
+		layout = "2006-01-02"
+	case CSVDateUS:
+		layout = "01/02/2006"
+	case CSVDateEU:
+		layout = "02/01/2006"
+	default:
+		return value
+	}
+	if len(value) < len("2006-01-02") || value[4] != '-' {
+		return value
+	}
+	for _, stored := range storedTimeLayouts {
+		t, err := time.Parse(stored, value)
+		if err != nil {
+			continue
+		}
+		if stored == "2006-01-02" {
+			return t.Format(layout)
+		}
+		return t.Format(layout + " 15:04:05")
+	}
+	return value
+}
+
+// WriteCSV writes headers and rows as CSV with the options in c.
+func WriteCSV(w io.Writer, headers []string, rows [][]string, c CSVExportConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	utf16le := strings.EqualFold(c.Encoding, CSVEncodingUTF16LE)
+	var buf bytes.Buffer
+	if c.BOM && !utf16le {
+		buf.WriteString("\ufeff")
+	}
+	writer := csv.NewWriter(&buf)
+	writer.Comma = c.Comma()
+	// Excel, the reason for a BOM or UTF-16, expects CRLF line endings.
+	writer.UseCRLF = utf16le || c.BOM
+	if c.QuoteHeaders {
+		// encoding/csv only quotes fields that need it.
+		quoted := make([]string, len(headers))
+		for i, header := range headers {
+			quoted[i] = `"` + strings.ReplaceAll(header, `"`, `""`) + `"`
+		}
+		buf.WriteString(strings.Join(quoted, string(writer.Comma)))
+		if writer.UseCRLF {
+			buf.WriteString("\r\n")
+		} else {
+			buf.WriteString("\n")
+		}
+	} else if err := writer.Write(headers); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := row
+		if c.DateFormat != "" && !strings.EqualFold(c.DateFormat, CSVDateAsStored) {
+			record = make([]string, len(row))
+			for i, value := range row {
+				record[i] = formatCSVDate(value, c.DateFormat)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	if !utf16le {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	units := utf16.Encode([]rune("\ufeff" + buf.String()))
+	encoded := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		encoded = append(encoded, byte(unit), byte(unit>>8))
+	}
+	_, err := w.Write(encoded)
+	return err
+}
+
+// ExportTable writes every row of a table or view as CSV and returns how
+// many it wrote. Accounts tables leave out the custom fields that are not
+// pulled and have their encrypted columns decrypted, as in the Explorer.
+func (a *App) ExportTable(w io.Writer, table string, c CSVExportConfig) (int, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return 0, fmt.Errorf("database is not connected")
+	}
+	tables, err := a.DB.GetTables()
+	if err != nil {
+		return 0, err
+	}
+	name := ""
+	for _, t := range tables {
+		if strings.EqualFold(t, table) {
+			name = t
+		}
+	}
+	if name == "" {
+		return 0, fmt.Errorf("no table or view named %q", table)
+	}
+	rows, err := database.SelectTableRows(a.DB, name)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	headers, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	var data [][]string
+	for rows.Next() {
+		values := make([]any, len(headers))
+		for i := range values {
+			values[i] = new(any)
+		}
+		if err := rows.Scan(values...); err != nil {
+			return 0, err
+		}
+		record := make([]string, len(headers))
+		for i, value := range values {
+			switch v := (*value.(*any)).(type) {
+			case nil:
+			case []byte:
+				record[i] = string(v)
+			case time.Time:
+				record[i] = v.UTC().Format("2006-01-02 15:04:05")
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		data = append(data, record)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if strings.EqualFold(name, "Accounts") || strings.EqualFold(name, "AccountsWithLabels") {
+		hidden := a.HiddenAccountColumns()
+		var keep []int
+		for i, header := range headers {
+			if !hidden[header] {
+				keep = append(keep, i)
+			}
+		}
+		pick := func(row []string) []string {
+			kept := make([]string, len(keep))
+			for j, i := range keep {
+				kept[j] = row[i]
+			}
+			return kept
+		}
+		headers = pick(headers)
+		for i, row := range data {
+			data[i] = pick(row)
+		}
+		a.DecryptTableRows(headers, data)
+	}
+	return len(data), WriteCSV(w, headers, data, c)
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestWriteCSV(t *testing.T) {
+	headers := []string{"AccountId", "FullName", "LastModified"}
+	rows := [][]string{
+		{"1", "Acme; Inc", "2025-03-04 05:06:07 +0000 UTC"},
+		{"2", `The "Best" Co`, "2025-12-31"},
+	}
+	cases := []struct {
+		name    string
+		options CSVExportConfig
+		want    string
+	}{
+		{"defaults", CSVExportConfig{},
+			"AccountId,FullName,LastModified\n1,Acme; Inc,2025-03-04 05:06:07 +0000 UTC\n2,\"The \"\"Best\"\" Co\",2025-12-31\n"},
+		{"excel", CSVExportConfig{Delimiter: "semicolon", BOM: true, QuoteHeaders: true, DateFormat: "eu"},
+			"\ufeff\"AccountId\";\"FullName\";\"LastModified\"\r\n1;\"Acme; Inc\";04/03/2025 05:06:07\r\n2;\"The \"\"Best\"\" Co\";31/12/2025\r\n"},
+		{"tab", CSVExportConfig{Delimiter: "tab", DateFormat: "us"},
+			"AccountId\tFullName\tLastModified\n1\tAcme; Inc\t03/04/2025 05:06:07\n2\t\"The \"\"Best\"\" Co\"\t12/31/2025\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := WriteCSV(&buf, headers, rows, c.options); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if buf.String() != c.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", c.name, buf.String(), c.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []string{"Name"}, [][]string{{"Café"}}, CSVExportConfig{Encoding: "utf-16le"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0xFF, 0xFE, 'N', 0, 'a', 0, 'm', 0, 'e', 0, '\r', 0, '\n', 0, 'C', 0, 'a', 0, 'f', 0, 0xE9, 0, '\r', 0, '\n', 0}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("utf-16le = % x, want % x", buf.Bytes(), want)
+	}
+
+	if err := WriteCSV(&buf, headers, rows, CSVExportConfig{Delimiter: "pipe"}); err == nil {
+		t.Error("an unknown delimiter was accepted")
+	}
+}
+
+func TestExportTable(t *testing.T) {
+	cfg := database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "export.db")}
+	db, err := database.NewDB(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	if _, err := db.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (1, 'Acme'), (2, 'Globex')"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	count, err := a.ExportTable(&buf, "accounts", CSVExportConfig{Delimiter: "semicolon"})
+	if err != nil || count != 2 {
+		t.Fatalf("ExportTable = %d, %v; want 2 rows", count, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "AccountId;") || !strings.Contains(lines[2], ";Globex;") {
+		t.Errorf("export = %q", buf.String())
+	}
+	if _, err := a.ExportTable(&buf, "Accounts; DROP TABLE Accounts", CSVExportConfig{}); err == nil {
+		t.Error("an unknown table was exported")
+	}
+}
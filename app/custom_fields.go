@@ -0,0 +1,131 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PullMappedCustomFields in pull_custom_fields selects every custom field
+// that a data field of the user profile is stored in.
+const PullMappedCustomFields = "mapped"
+
+// CustomAccountColumns returns the 60 custom Accounts columns in the order
+// the detail editor shows them.
+func CustomAccountColumns() []string {
+	var columns []string
+	for _, column := range sortedAccountColumns() {
+		if strings.HasPrefix(column, "CustomText") || strings.HasPrefix(column, "CustomNumeric") {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// ParseCustomColumn returns the Accounts column of a custom field given as
+// a column (CustomText2) or API field (custom_text2). custom_text1 is the
+// same as custom_text.
+func ParseCustomColumn(name string) (string, bool) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+	for _, column := range CustomAccountColumns() {
+		normalized := strings.ToLower(column)
+		if key == normalized || (key == normalized+"1" && !strings.ContainsAny(normalized, "0123456789")) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// ValidatePullCustomFields checks that every entry of pull_custom_fields
+// is a custom field or "mapped".
+func ValidatePullCustomFields(fields []string) error {
+	for _, field := range fields {
+		if strings.EqualFold(strings.TrimSpace(field), PullMappedCustomFields) {
+			continue
+		}
+		if _, ok := ParseCustomColumn(field); !ok {
+			return fmt.Errorf("pull_custom_fields: %q is not a custom field (expected custom_text, custom_numeric2, ..., or %q)", field, PullMappedCustomFields)
+		}
+	}
+	return nil
+}
+
+// PulledCustomColumns returns the custom columns pulls store, or nil when
+// pull_custom_fields is empty and every custom field is pulled. Entries
+// that are not custom fields are ignored.
+func (a *App) PulledCustomColumns() (map[string]bool, error) {
+	if a.Config == nil || len(a.Config.PullCustomFields) == 0 {
+		return nil, nil
+	}
+	pulled := make(map[string]bool)
+	for _, field := range a.Config.PullCustomFields {
+		if strings.EqualFold(strings.TrimSpace(field), PullMappedCustomFields) {
+			if a.DB == nil || a.DB.GetDB() == nil {
+				continue
+			}
+			mapped, err := database.GetMappedAccountFields(a.DB)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range mapped {
+				if column, ok := ParseCustomColumn(name); ok {
+					pulled[column] = true
+				}
+			}
+			continue
+		}
+		if column, ok := ParseCustomColumn(field); ok {
+			pulled[column] = true
+		}
+	}
+	return pulled, nil
+}
+
+// DropUnpulledCustomFields clears the custom fields of a pulled account that
+// pull_custom_fields leaves out, so they are stored empty. Bulk pulls
+// resolve the selection once with PulledCustomColumns and call
+// DropCustomFields instead.
+func (a *App) DropUnpulledCustomFields(acc *models.Account) error {
+	pulled, err := a.PulledCustomColumns()
+	if err != nil {
+		return err
+	}
+	DropCustomFields(acc, pulled)
+	return nil
+}
+
+// DropCustomFields clears the custom fields of acc that are not in pulled.
+// A nil pulled keeps every field.
+func DropCustomFields(acc *models.Account, pulled map[string]bool) {
+	if pulled == nil {
+		return
+	}
+	value := reflect.ValueOf(acc).Elem()
+	for _, column := range CustomAccountColumns() {
+		if pulled[column] {
+			continue
+		}
+		if field := value.FieldByName(column); field.IsValid() && field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
+
+// HiddenAccountColumns returns the custom columns the GUI leaves out of
+// account views because pulls do not store them. It is empty when every
+// custom field is pulled or the selection cannot be read.
+func (a *App) HiddenAccountColumns() map[string]bool {
+	pulled, err := a.PulledCustomColumns()
+	if err != nil || pulled == nil {
+		return nil
+	}
+	hidden := make(map[string]bool)
+	for _, column := range CustomAccountColumns() {
+		if !pulled[column] {
+			hidden[column] = true
+		}
+	}
+	return hidden
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/api/models"
+	"badgermaps/app/state"
+	"badgermaps/database"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestParseCustomColumn(t *testing.T) {
+	for name, want := range map[string]string{
+		"custom_text":     "CustomText",
+		"custom_text1":    "CustomText",
+		"CustomNumeric12": "CustomNumeric12",
+		" custom_text30 ": "CustomText30",
+	} {
+		if got, ok := ParseCustomColumn(name); !ok || got != want {
+			t.Errorf("ParseCustomColumn(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+	for _, name := range []string{"notes", "custom_text31", "custom_numeric0"} {
+		if got, ok := ParseCustomColumn(name); ok {
+			t.Errorf("ParseCustomColumn(%q) = %q, want no match", name, got)
+		}
+	}
+	if err := ValidatePullCustomFields([]string{"mapped", "custom_text2"}); err != nil {
+		t.Errorf("ValidatePullCustomFields: %v", err)
+	}
+	if err := ValidatePullCustomFields([]string{"phone"}); err == nil {
+		t.Error("expected a non-custom field to be refused")
+	}
+}
+
+func TestDropUnpulledCustomFields(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "custom.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	a.DB = db
+	if _, err := db.GetDB().Exec(`INSERT INTO DataSets (Name, ProfileId, AccountField) VALUES ('segment', 1, 'custom_numeric')`); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled := func() *models.Account {
+		return &models.Account{
+			CustomText:     null.StringFrom("a"),
+			CustomText2:    null.StringFrom("b"),
+			CustomNumeric:  null.FloatFrom(1),
+			CustomNumeric2: null.FloatFrom(2),
+		}
+	}
+
+	// Nothing is dropped or hidden until fields are chosen.
+	acc := pulled()
+	if err := a.DropUnpulledCustomFields(acc); err != nil {
+		t.Fatal(err)
+	}
+	if !acc.CustomText2.Valid || a.HiddenAccountColumns() != nil {
+		t.Fatalf("an empty pull_custom_fields dropped fields: %+v", acc)
+	}
+
+	a.Config.PullCustomFields = []string{"custom_text2", PullMappedCustomFields}
+	acc = pulled()
+	if err := a.DropUnpulledCustomFields(acc); err != nil {
+		t.Fatal(err)
+	}
+	if acc.CustomText.Valid || acc.CustomNumeric2.Valid {
+		t.Errorf("unselected fields were kept: %+v", acc)
+	}
+	if acc.CustomText2.String != "b" || acc.CustomNumeric.Float64 != 1 {
+		t.Errorf("selected and mapped fields were dropped: %+v", acc)
+	}
+	hidden := a.HiddenAccountColumns()
+	if len(hidden) != 58 || hidden["CustomText2"] || hidden["CustomNumeric"] || !hidden["CustomText"] {
+		t.Errorf("hidden columns = %d, want every custom column but CustomText2 and CustomNumeric", len(hidden))
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DBKeyEnv names the environment variable that supplies the passphrase for
+// an encrypted SQLite database.
+const DBKeyEnv = "BADGERMAPS_DB_KEY"
+
+// dbKeychainAccount names the keychain entry for the database at path, so
+// separate databases keep separate passphrases.
+func dbKeychainAccount(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return "db:" + path
+}
+
+// unlockDB gives an encrypted SQLite handle its passphrase before it
+// connects. Other databases are left alone.
+func (a *App) unlockDB() {
+	sqlite, ok := a.DB.(*database.SQLiteConfig)
+	if !ok || !sqlite.Encrypted || sqlite.Key != "" {
+		return
+	}
+	// Connect reports a plain file on its own, so only ask for a passphrase
+	// the file needs. BADGERMAPS_DB_KEY can still create a new database.
+	if encrypted, _ := database.IsSQLiteFileEncrypted(sqlite.Path); !encrypted && os.Getenv(DBKeyEnv) == "" {
+		return
+	}
+	sqlite.Key = a.lookupDBKey(sqlite.Path)
+}
+
+// lookupDBKey finds the passphrase for the database at path. It checks
+// BADGERMAPS_DB_KEY, then the OS keychain, and finally prompts when running
+// interactively in the terminal.
+func (a *App) lookupDBKey(path string) string {
+	if key := os.Getenv(DBKeyEnv); key != "" {
+		return key
+	}
+	key, err := utils.KeychainGet(dbKeychainAccount(path))
+	if err != nil && !errors.Is(err, utils.ErrKeychainUnavailable) {
+		a.Events.Dispatch(events.Warningf("db", "Could not read database passphrase from keychain: %v", err))
+	}
+	if key != "" {
+		return key
+	}
+	if a.State.NoInput || a.State.IsGui {
+		return ""
+	}
+	key, err = utils.PromptSecret(bufio.NewReader(os.Stdin), "Database passphrase")
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// explainDBError adds a next step to SQLCipher connection errors.
+func explainDBError(err error) error {
+	if errors.Is(err, database.ErrSQLiteKeyRequired) {
+		return fmt.Errorf("%w; set %s, save it to the keychain with 'badgermaps db rekey --keychain', or run interactively", err, DBKeyEnv)
+	}
+	return err
+}
+
+// sqlitePath returns the configured SQLite path, or an error when the
+// database is not SQLite.
+func (a *App) sqlitePath() (string, error) {
+	if a.Config.DB.Type != "" && a.Config.DB.Type != "sqlite3" {
+		return "", fmt.Errorf("encryption is only supported for sqlite3 databases, not %s", a.Config.DB.Type)
+	}
+	if a.Config.DB.Path == "" {
+		return "", fmt.Errorf("no SQLite database path is configured")
+	}
+	return a.Config.DB.Path, nil
+}
+
+// reconnectDB closes the current handle and opens the configured database
+// again with key.
+func (a *App) reconnectDB(key string) error {
+	if a.DB != nil {
+		a.DB.Close()
+	}
+	db, err := database.NewDB(&a.Config.DB)
+	if err != nil {
+		return err
+	}
+	if sqlite, ok := db.(*database.SQLiteConfig); ok {
+		sqlite.Key = key
+	}
+	defer a.Connections().Refresh()
+	if err := db.Connect(); err != nil {
+		a.DB = nil
+		return err
+	}
+	a.DB = db
+	return a.DB.TestConnection()
+}
+
+// EncryptDatabase converts the unencrypted SQLite database to SQLCipher with
+// key, marks it encrypted in the config, and reconnects. When storeKey is
+// set the passphrase is also saved to the OS keychain.
+func (a *App) EncryptDatabase(key string, storeKey bool) error {
+	path, err := a.sqlitePath()
+	if err != nil {
+		return err
+	}
+	if a.DB != nil {
+		a.DB.Close()
+	}
+	if err := database.EncryptSQLiteFile(path, key); err != nil {
+		return err
+	}
+
+	a.Config.DB.Encrypted = true
+	if err := a.SaveConfig(); err != nil {
+		return fmt.Errorf("database was encrypted but the config could not be saved; set db.encrypted to true manually: %w", err)
+	}
+	if storeKey {
+		if err := utils.KeychainSet(dbKeychainAccount(path), key); err != nil {
+			a.Events.Dispatch(events.Warningf("db", "Database encrypted, but the passphrase was not stored: %v", err))
+		}
+	}
+	a.Events.Dispatch(events.Infof("db", "Encrypted database %s.", path))
+	a.Events.Dispatch(events.Event{Type: "db.encrypt.complete", Source: "db", Payload: events.DatabaseEncryptPayload{Path: path}})
+	return a.reconnectDB(key)
+}
+
+// RotateDatabaseKey re-encrypts the SQLite database with newKey and
+// reconnects. A passphrase already in the keychain is replaced so the next
+// start does not pick up the old one; storeKey saves it there otherwise.
+func (a *App) RotateDatabaseKey(newKey string, storeKey bool) error {
+	path, err := a.sqlitePath()
+	if err != nil {
+		return err
+	}
+	if !a.Config.DB.Encrypted {
+		return fmt.Errorf("database is not encrypted; run 'badgermaps db encrypt' first")
+	}
+
+	var oldKey string
+	if sqlite, ok := a.DB.(*database.SQLiteConfig); ok {
+		oldKey = sqlite.Key
+	}
+	if oldKey == "" {
+		oldKey = a.lookupDBKey(path)
+	}
+	if a.DB != nil {
+		a.DB.Close()
+	}
+	if err := database.RekeySQLiteFile(path, oldKey, newKey); err != nil {
+		return err
+	}
+
+	account := dbKeychainAccount(path)
+	if stored, _ := utils.KeychainGet(account); stored != "" || storeKey {
+		if err := utils.KeychainSet(account, newKey); err != nil {
+			a.Events.Dispatch(events.Warningf("db", "Passphrase changed, but the keychain was not updated: %v", err))
+		}
+	}
+	a.Events.Dispatch(events.Infof("db", "Changed the passphrase for %s.", path))
+	a.Events.Dispatch(events.Event{Type: "db.rekey.complete", Source: "db", Payload: events.DatabaseRekeyPayload{Path: path}})
+	return a.reconnectDB(newKey)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"badgermaps/app/server"
+	"badgermaps/events"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DeepLinkScheme is the URL scheme the desktop app registers, as in
+// badgermaps://account/123.
+const DeepLinkScheme = "badgermaps"
+
+// Deep link kinds, the first part of the link after the scheme.
+const (
+	// DeepLinkAccount opens an account: badgermaps://account/<id>.
+	DeepLinkAccount = "account"
+	// DeepLinkCheckin opens a check-in: badgermaps://checkin/<id>.
+	DeepLinkCheckin = "checkin"
+	// DeepLinkRoute opens a route: badgermaps://route/<id>.
+	DeepLinkRoute = "route"
+	// DeepLinkTable opens a table in Explorer: badgermaps://table/<name>.
+	DeepLinkTable = "table"
+	// DeepLinkTab switches to a tab: badgermaps://tab/<name>.
+	DeepLinkTab = "tab"
+	// DeepLinkRun runs the scheduled job with the given name:
+	// badgermaps://run/<name>.
+	DeepLinkRun = "run"
+)
+
+// DeepLink is a parsed badgermaps:// URL.
+type DeepLink struct {
+	Kind string
+	// Target is the rest of the path, unescaped, such as "123" or "nightly".
+	Target string
+	// ID is Target as a number for account, check-in, and route links.
+	ID  int
+	Raw string
+}
+
+// ParseDeepLink parses a badgermaps:// URL. Both badgermaps://account/1 and
+// badgermaps:account/1 are accepted, and trailing slashes are ignored.
+func ParseDeepLink(raw string) (DeepLink, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return DeepLink{}, fmt.Errorf("invalid link %q: %w", raw, err)
+	}
+	if !strings.EqualFold(u.Scheme, DeepLinkScheme) {
+		return DeepLink{}, fmt.Errorf("invalid link %q: scheme must be %s://", raw, DeepLinkScheme)
+	}
+
+	var parts []string
+	if u.Opaque != "" {
+		parts = strings.Split(u.Opaque, "/")
+	} else {
+		parts = append([]string{u.Host}, strings.Split(u.Path, "/")...)
+	}
+	var path []string
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return DeepLink{}, fmt.Errorf("invalid link %q: %w", raw, err)
+		}
+		path = append(path, unescaped)
+	}
+	if len(path) != 2 {
+		return DeepLink{}, fmt.Errorf("invalid link %q: expected %s://<kind>/<target>", raw, DeepLinkScheme)
+	}
+
+	link := DeepLink{Kind: strings.ToLower(path[0]), Target: path[1], Raw: raw}
+	switch link.Kind {
+	case DeepLinkAccount, DeepLinkCheckin, DeepLinkRoute:
+		id, err := strconv.Atoi(link.Target)
+		if err != nil || id <= 0 {
+			return DeepLink{}, fmt.Errorf("invalid link %q: %s ID must be a positive number", raw, link.Kind)
+		}
+		link.ID = id
+	case DeepLinkTable, DeepLinkTab, DeepLinkRun:
+	default:
+		return DeepLink{}, fmt.Errorf("invalid link %q: unknown kind %q", raw, link.Kind)
+	}
+	return link, nil
+}
+
+// URL returns the canonical form of the link.
+func (l DeepLink) URL() string {
+	return fmt.Sprintf("%s://%s/%s", DeepLinkScheme, l.Kind, url.PathEscape(l.Target))
+}
+
+// CronJobByName returns the configured job named name, ignoring case.
+func (a *App) CronJobByName(name string) (server.CronJob, bool) {
+	for _, job := range a.Config.CronJobs {
+		if strings.EqualFold(job.Name, name) {
+			return job, true
+		}
+	}
+	return server.CronJob{}, false
+}
+
+// RunCronJob runs the action of the configured job named name now, outside
+// its schedule.
+func (a *App) RunCronJob(name string) error {
+	job, ok := a.CronJobByName(name)
+	if !ok {
+		return fmt.Errorf("no scheduled job named %q", name)
+	}
+	a.Events.Dispatch(events.Infof("scheduler", "Running job %s on request", job.Name))
+	if err := a.ExecuteNamedAction(job.Name, ActionTriggerManual, job.Action, nil); err != nil {
+		return fmt.Errorf("job %s failed: %w", job.Name, err)
+	}
+	return nil
+}
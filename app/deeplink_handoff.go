@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"badgermaps/utils"
+)
+
+// ErrNoDeepLinkListener is returned by ForwardDeepLink when no running GUI
+// accepts links.
+var ErrNoDeepLinkListener = errors.New("no running GUI accepts links")
+
+// deepLinkAddrFile names the file, in the config directory, holding the
+// address and token of the running GUI's link listener.
+const deepLinkAddrFile = "deeplink.addr"
+
+// ListenDeepLinks accepts links forwarded by later launches, so opening a
+// link while the GUI runs reuses its window. It listens on a loopback port
+// and writes the address with a random token to a file only the user can
+// read. Forwarded links must carry the token. handle is called from the
+// listener's goroutine. The returned stop closes the listener and removes the
+// file.
+func ListenDeepLinks(handle func(DeepLink)) (stop func(), err error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	secret := hex.EncodeToString(token)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for links: %w", err)
+	}
+	path := utils.GetConfigDirFile(deepLinkAddrFile)
+	if err := utils.EnsureDirExists(utils.GetUserDefaultConfigDir()); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(listener.Addr().String()+"\n"+secret+"\n"), 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveDeepLink(conn, secret, handle)
+		}
+	}()
+	return func() {
+		listener.Close()
+		os.Remove(path)
+	}, nil
+}
+
+func serveDeepLink(conn net.Conn, secret string, handle func(DeepLink)) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	token, _ := reader.ReadString('\n')
+	raw, _ := reader.ReadString('\n')
+	if strings.TrimSpace(token) != secret {
+		fmt.Fprintln(conn, "error: bad token")
+		return
+	}
+	link, err := ParseDeepLink(raw)
+	if err != nil {
+		fmt.Fprintf(conn, "error: %v\n", err)
+		return
+	}
+	fmt.Fprintln(conn, "ok")
+	handle(link)
+}
+
+// ForwardDeepLink hands link to the running GUI. It returns
+// ErrNoDeepLinkListener when no GUI is running, in which case the caller
+// should open the link itself.
+func ForwardDeepLink(link DeepLink) error {
+	data, err := os.ReadFile(utils.GetConfigDirFile(deepLinkAddrFile))
+	if err != nil {
+		return ErrNoDeepLinkListener
+	}
+	lines := strings.Fields(string(data))
+	if len(lines) != 2 {
+		return ErrNoDeepLinkListener
+	}
+	conn, err := net.DialTimeout("tcp", lines[0], 2*time.Second)
+	if err != nil {
+		// The GUI exited without removing the file.
+		return ErrNoDeepLinkListener
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "%s\n%s\n", lines[1], link.URL())
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("running GUI did not answer: %w", err)
+	}
+	if reply = strings.TrimSpace(reply); reply != "ok" {
+		return fmt.Errorf("running GUI refused the link: %s", strings.TrimPrefix(reply, "error: "))
+	}
+	return nil
+}
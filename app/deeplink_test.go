@@ -0,0 +1,79 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDeepLink(t *testing.T) {
+	tests := []struct {
+		raw    string
+		kind   string
+		target string
+		id     int
+	}{
+		{raw: "badgermaps://account/123", kind: DeepLinkAccount, target: "123", id: 123},
+		{raw: "BadgerMaps://Account/123/", kind: DeepLinkAccount, target: "123", id: 123},
+		{raw: "badgermaps:checkin/9", kind: DeepLinkCheckin, target: "9", id: 9},
+		{raw: "badgermaps://run/nightly%20pull", kind: DeepLinkRun, target: "nightly pull"},
+		{raw: "badgermaps://tab/sync-center", kind: DeepLinkTab, target: "sync-center"},
+	}
+	for _, tt := range tests {
+		link, err := ParseDeepLink(tt.raw)
+		if err != nil {
+			t.Errorf("ParseDeepLink(%q): %v", tt.raw, err)
+			continue
+		}
+		if link.Kind != tt.kind || link.Target != tt.target || link.ID != tt.id {
+			t.Errorf("ParseDeepLink(%q) = %+v", tt.raw, link)
+		}
+		again, err := ParseDeepLink(link.URL())
+		if err != nil || again.Target != link.Target {
+			t.Errorf("URL() of %q does not round-trip: %q", tt.raw, link.URL())
+		}
+	}
+
+	for _, raw := range []string{"https://account/1", "badgermaps://account/abc", "badgermaps://account/0", "badgermaps://account", "badgermaps://delete/1", "badgermaps://table/a/b"} {
+		if _, err := ParseDeepLink(raw); err == nil {
+			t.Errorf("expected ParseDeepLink(%q) to fail", raw)
+		}
+	}
+}
+
+func TestForwardDeepLink(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	link, _ := ParseDeepLink("badgermaps://account/7")
+	if err := ForwardDeepLink(link); !errors.Is(err, ErrNoDeepLinkListener) {
+		t.Fatalf("expected no listener, got %v", err)
+	}
+
+	received := make(chan DeepLink, 1)
+	stop, err := ListenDeepLinks(func(l DeepLink) { received <- l })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ForwardDeepLink(link); err != nil {
+		t.Fatalf("ForwardDeepLink: %v", err)
+	}
+	select {
+	case got := <-received:
+		if got.Kind != DeepLinkAccount || got.ID != 7 {
+			t.Errorf("received %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("link was not received")
+	}
+
+	stop()
+	if err := ForwardDeepLink(link); !errors.Is(err, ErrNoDeepLinkListener) {
+		t.Errorf("expected no listener after stop, got %v", err)
+	}
+}
+
+func TestRunCronJobUnknown(t *testing.T) {
+	a := NewApp()
+	if err := a.RunCronJob("nightly"); err == nil {
+		t.Error("expected an unknown job to fail")
+	}
+}
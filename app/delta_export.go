@@ -0,0 +1,241 @@
+package app
+
+import (
+	"badgermaps/database"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delta export entities and operations.
+const (
+	DeltaEntityAccounts = "accounts"
+	DeltaEntityCheckins = "checkins"
+	DeltaEntityRoutes   = "routes"
+
+	DeltaUpsert = "upsert"
+	DeltaDelete = "delete"
+)
+
+// DeltaEntities lists the entities ExportChanges can write.
+var DeltaEntities = []string{DeltaEntityAccounts, DeltaEntityCheckins, DeltaEntityRoutes}
+
+// deltaTables maps each entity to its table and key column.
+var deltaTables = map[string]struct{ table, key string }{
+	DeltaEntityAccounts: {"Accounts", "AccountId"},
+	DeltaEntityCheckins: {"AccountCheckins", "CheckinId"},
+	DeltaEntityRoutes:   {"Routes", "RouteId"},
+}
+
+// DeltaRecord is one line of a delta export: the current row of an entity
+// that changed, or an account that was deleted. Data holds the row's
+// columns by name, with NULL as null; for a delete it is the account as it
+// was stored before the delete was pushed.
+type DeltaRecord struct {
+	Entity    string         `json:"entity"`
+	Op        string         `json:"op"`
+	ID        int            `json:"id"`
+	ChangedAt time.Time      `json:"changed_at"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// DeltaExportReport counts what ExportChanges wrote. Cursor is the latest
+// change written; passing it as the next run's since repeats only the rows
+// changed at that instant. Undated counts rows skipped because neither
+// UpdatedAt nor CreatedAt could be read.
+type DeltaExportReport struct {
+	Since    time.Time      `json:"since"`
+	Records  int            `json:"records"`
+	ByEntity map[string]int `json:"by_entity"`
+	Deleted  int            `json:"deleted"`
+	Undated  int            `json:"undated,omitempty"`
+	Cursor   time.Time      `json:"cursor"`
+}
+
+// ExportChanges writes, as JSON lines ordered by time, every account,
+// check-in and route whose UpdatedAt is at or after since, and every
+// account deleted since then that was not restored. entities limits the
+// export to some of DeltaEntities; deletes are written with accounts. The
+// timestamps are the ones pulled from BadgerMaps, so rows change when a
+// pull brings a newer version.
+func (a *App) ExportChanges(w io.Writer, since time.Time, entities []string) (*DeltaExportReport, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	if len(entities) == 0 {
+		entities = DeltaEntities
+	}
+	for _, entity := range entities {
+		if _, ok := deltaTables[strings.ToLower(entity)]; !ok {
+			return nil, fmt.Errorf("unknown entity %q; choose from %s", entity, strings.Join(DeltaEntities, ", "))
+		}
+	}
+
+	report := &DeltaExportReport{Since: since.UTC(), ByEntity: make(map[string]int)}
+	var records []DeltaRecord
+	for _, entity := range DeltaEntities {
+		if !containsFold(entities, entity) {
+			continue
+		}
+		changed, undated, err := a.changedRows(entity, since)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, changed...)
+		report.ByEntity[entity] += len(changed)
+		report.Undated += undated
+		if entity == DeltaEntityAccounts {
+			deleted, err := a.deletedAccountRecords(since)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, deleted...)
+			report.Deleted = len(deleted)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].ChangedAt.Equal(records[j].ChangedAt) {
+			return records[i].ChangedAt.Before(records[j].ChangedAt)
+		}
+		if records[i].Entity != records[j].Entity {
+			return records[i].Entity < records[j].Entity
+		}
+		return records[i].ID < records[j].ID
+	})
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return report, err
+		}
+		report.Records++
+		report.Cursor = record.ChangedAt
+	}
+	return report, nil
+}
+
+// changedRows reads the rows of entity's table changed at or after since.
+// Timestamps are compared after parsing, since BadgerMaps and the local
+// defaults store them in different formats.
+func (a *App) changedRows(entity string, since time.Time) ([]DeltaRecord, int, error) {
+	table := deltaTables[entity]
+	rows, err := database.SelectTableRows(a.DB, table.table)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	headers, err := rows.Columns()
+	if err != nil {
+		return nil, 0, err
+	}
+	hidden := map[string]bool{}
+	if entity == DeltaEntityAccounts {
+		hidden = a.HiddenAccountColumns()
+	}
+
+	var records []DeltaRecord
+	var values [][]string
+	var nulls [][]bool
+	undated := 0
+	for rows.Next() {
+		scanned := make([]any, len(headers))
+		for i := range scanned {
+			scanned[i] = new(any)
+		}
+		if err := rows.Scan(scanned...); err != nil {
+			return nil, 0, err
+		}
+		row := make([]string, len(headers))
+		null := make([]bool, len(headers))
+		for i, value := range scanned {
+			switch v := (*value.(*any)).(type) {
+			case nil:
+				null[i] = true
+			case []byte:
+				row[i] = string(v)
+			case time.Time:
+				row[i] = v.UTC().Format(time.RFC3339Nano)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		changedAt, ok := deltaChangedAt(headers, row, null)
+		if !ok {
+			undated++
+			continue
+		}
+		if changedAt.Before(since) {
+			continue
+		}
+		record := DeltaRecord{Entity: entity, Op: DeltaUpsert, ChangedAt: changedAt}
+		for i, header := range headers {
+			if strings.EqualFold(header, table.key) {
+				record.ID, _ = strconv.Atoi(row[i])
+			}
+		}
+		records = append(records, record)
+		values = append(values, row)
+		nulls = append(nulls, null)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if entity == DeltaEntityAccounts {
+		a.DecryptTableRows(headers, values)
+	}
+	for r := range records {
+		records[r].Data = make(map[string]any, len(headers))
+		for i, header := range headers {
+			switch {
+			case hidden[header]:
+			case nulls[r][i]:
+				records[r].Data[header] = nil
+			default:
+				records[r].Data[header] = values[r][i]
+			}
+		}
+	}
+	return records, undated, nil
+}
+
+// deltaChangedAt returns the row's UpdatedAt, or its CreatedAt when the
+// row has none, in UTC.
+func deltaChangedAt(headers, row []string, null []bool) (time.Time, bool) {
+	for _, column := range []string{"UpdatedAt", "CreatedAt"} {
+		for i, header := range headers {
+			if !strings.EqualFold(header, column) || null[i] || row[i] == "" {
+				continue
+			}
+			if t, _, err := parseDateTime(row[i], time.UTC); err == nil {
+				return t.UTC(), true
+			}
+			if t, err := time.Parse("2006-01-02", row[i]); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// deletedAccountRecords returns the accounts deleted at or after since
+// that were not restored, as delete records.
+func (a *App) deletedAccountRecords(since time.Time) ([]DeltaRecord, error) {
+	deleted, err := database.GetDeletedAccounts(a.DB, since)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]DeltaRecord, 0, len(deleted))
+	for _, entry := range deleted {
+		record := DeltaRecord{Entity: DeltaEntityAccounts, Op: DeltaDelete, ID: entry.AccountId, ChangedAt: entry.DeletedAt.UTC()}
+		var data map[string]any
+		if json.Unmarshal([]byte(entry.Data), &data) == nil {
+			record.Data = data
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
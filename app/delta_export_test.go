@@ -0,0 +1,97 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestExportChanges(t *testing.T) {
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "delta.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	for _, stmt := range []string{
+		`INSERT INTO Accounts (AccountId, FullName, UpdatedAt) VALUES
+			(1, 'Old Co', '2024-01-01T10:00:00Z'), (2, 'New Co', '2024-03-02 09:30:00'),
+			(3, 'Newer Co', '2024-03-05T08:00:00.123Z'), (4, 'Undated Co', NULL)`,
+		`UPDATE Accounts SET CreatedAt = NULL WHERE AccountId = 4`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, Comments, UpdatedAt) VALUES
+			(10, 1, 'before', '2024-02-01T00:00:00Z'), (11, 2, 'after', '2024-03-03T12:00:00Z')`,
+	} {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	deletedAt := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	if err := database.ArchiveDeletedAccount(db, database.DeletedAccount{AccountId: 5, FullName: "Gone Co", Data: `{"full_name":"Gone Co"}`, DeletedAt: deletedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	report, err := a.ExportChanges(&out, since, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []DeltaRecord
+	scanner := bufio.NewScanner(&out)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var record DeltaRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, record)
+	}
+	want := []struct {
+		entity, op string
+		id         int
+	}{
+		{DeltaEntityAccounts, DeltaUpsert, 2},
+		{DeltaEntityCheckins, DeltaUpsert, 11},
+		{DeltaEntityAccounts, DeltaDelete, 5},
+		{DeltaEntityAccounts, DeltaUpsert, 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Entity != w.entity || got[i].Op != w.op || got[i].ID != w.id {
+			t.Errorf("record %d = %s %s #%d, want %s %s #%d", i, got[i].Entity, got[i].Op, got[i].ID, w.entity, w.op, w.id)
+		}
+	}
+	if got[0].Data["FullName"] != "New Co" || got[2].Data["full_name"] != "Gone Co" {
+		t.Errorf("unexpected data: %v / %v", got[0].Data, got[2].Data)
+	}
+	if report.Records != 4 || report.Deleted != 1 || report.ByEntity[DeltaEntityAccounts] != 2 || report.Undated != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if !report.Cursor.Equal(time.Date(2024, 3, 5, 8, 0, 0, 123e6, time.UTC)) {
+		t.Errorf("cursor = %s", report.Cursor)
+	}
+
+	out.Reset()
+	if report, err := a.ExportChanges(&out, since, []string{"Checkins"}); err != nil || report.Records != 1 || report.Deleted != 0 {
+		t.Fatalf("checkins only = %+v, %v", report, err)
+	}
+	if _, err := a.ExportChanges(&out, since, []string{"profiles"}); err == nil {
+		t.Fatal("want an error for an unknown entity")
+	}
+}
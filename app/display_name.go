@@ -0,0 +1,163 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/database/repository"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultAccountDisplayName shows accounts by their full name.
+const DefaultAccountDisplayName = "{{full_name}}"
+
+// AccountDisplayNameExamples are offered by the GUI as starting points.
+var AccountDisplayNameExamples = []string{
+	DefaultAccountDisplayName,
+	"{{last_name}}, {{first_name}}",
+	"{{first_name}} {{last_name}}",
+	"{{full_name}} ({{customer_id}})",
+}
+
+var displayNameFieldPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// displayNameTrim is removed from both ends of a rendered name, so that
+// "{{last_name}}, {{first_name}}" shows "Smith" rather than "Smith, " when
+// the first name is empty.
+const displayNameTrim = " ,;:-/|·()[]"
+
+// DisplayNameTemplate is a parsed account_display_name: text with account
+// fields in {{...}}, named by column (LastName) or API field (last_name).
+type DisplayNameTemplate struct {
+	text    string
+	columns []string
+}
+
+// ParseDisplayNameTemplate parses an account display name template. Empty
+// is the default, the full name.
+func ParseDisplayNameTemplate(text string) (*DisplayNameTemplate, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultAccountDisplayName
+	}
+	t := &DisplayNameTemplate{text: text}
+	for _, match := range displayNameFieldPattern.FindAllStringSubmatch(text, -1) {
+		column, ok := accountColumn(match[1])
+		if !ok {
+			return nil, fmt.Errorf("account_display_name: %q is not an account field", match[1])
+		}
+		t.columns = append(t.columns, column)
+	}
+	if len(t.columns) == 0 {
+		return nil, fmt.Errorf("account_display_name must name at least one account field, such as {{full_name}}")
+	}
+	return t, nil
+}
+
+// accountColumn returns the Accounts column for a field given as a column
+// or API field name.
+func accountColumn(field string) (string, bool) {
+	if column, ok := ParseCustomColumn(field); ok {
+		return column, true
+	}
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(field), "_", ""))
+	for _, column := range database.GetExpectedSchema()["Accounts"] {
+		if strings.ToLower(column) == key {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// Columns returns the Accounts columns the template reads.
+func (t *DisplayNameTemplate) Columns() []string {
+	return t.columns
+}
+
+// Render fills in the template with value, which returns a column's value
+// as text. Separators left at either end by empty fields are dropped; the
+// result is "" when every field is empty.
+func (t *DisplayNameTemplate) Render(value func(column string) string) string {
+	i := 0
+	empty := true
+	text := displayNameFieldPattern.ReplaceAllStringFunc(t.text, func(string) string {
+		v := strings.TrimSpace(value(t.columns[i]))
+		i++
+		if v != "" {
+			empty = false
+		}
+		return v
+	})
+	if empty {
+		return ""
+	}
+	return strings.Trim(strings.Join(strings.Fields(text), " "), displayNameTrim)
+}
+
+// accountDisplayTemplate returns the configured template, or the default
+// when account_display_name is not valid. LoadConfig reports that case.
+func (a *App) accountDisplayTemplate() *DisplayNameTemplate {
+	text := ""
+	if a.Config != nil {
+		text = a.Config.AccountDisplayName
+	}
+	t, err := ParseDisplayNameTemplate(text)
+	if err != nil {
+		t, _ = ParseDisplayNameTemplate("")
+	}
+	return t
+}
+
+// AccountDisplayName returns how the GUI names account: the configured
+// template evaluated from its fields, falling back to the full name and
+// then "Account <id>". Encrypted fields should be decrypted first.
+func (a *App) AccountDisplayName(account *repository.AccountWithLabels) string {
+	name := a.accountDisplayTemplate().Render(func(column string) string {
+		value, _ := account.Value(column)
+		return value
+	})
+	return accountNameFallback(name, account.FullName.String, int(account.AccountId.Int64))
+}
+
+// AccountDisplayNames returns the display names of the accounts with ids,
+// read in one query. Accounts that are not stored locally are left out, so
+// callers fall back to the name they have.
+func (a *App) AccountDisplayNames(ids []int) (map[int]string, error) {
+	names := make(map[int]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	t := a.accountDisplayTemplate()
+	columns := append([]string{"AccountId", "FullName"}, t.Columns()...)
+	values, err := database.GetAccountColumnsByIDs(a.DB, columns, ids)
+	if err != nil {
+		return nil, err
+	}
+	a.DecryptTableRows(columns, values)
+	for _, row := range values {
+		id, _ := strconv.Atoi(row[0])
+		name := t.Render(func(column string) string {
+			for i := 2; i < len(columns); i++ {
+				if columns[i] == column {
+					return row[i]
+				}
+			}
+			return ""
+		})
+		names[id] = accountNameFallback(name, row[1], id)
+	}
+	return names, nil
+}
+
+func accountNameFallback(name, fullName string, id int) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	if fullName = strings.TrimSpace(fullName); fullName != "" {
+		return fullName
+	}
+	return fmt.Sprintf("Account %d", id)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestParseDisplayNameTemplate(t *testing.T) {
+	for _, tt := range []struct {
+		text    string
+		columns []string
+		wantErr bool
+	}{
+		{"", []string{"FullName"}, false},
+		{"{{last_name}}, {{first_name}}", []string{"LastName", "FirstName"}, false},
+		{"{{ FullName }} ({{customer_id}})", []string{"FullName", "CustomerId"}, false},
+		{"{{nickname}}", nil, true},
+		{"Accounts", nil, true},
+	} {
+		tmpl, err := ParseDisplayNameTemplate(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDisplayNameTemplate(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := tmpl.Columns(); strings.Join(got, ",") != strings.Join(tt.columns, ",") {
+			t.Errorf("ParseDisplayNameTemplate(%q) columns = %v, want %v", tt.text, got, tt.columns)
+		}
+	}
+}
+
+func TestDisplayNameTemplateRender(t *testing.T) {
+	tmpl, err := ParseDisplayNameTemplate("{{last_name}}, {{first_name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		values map[string]string
+		want   string
+	}{
+		{map[string]string{"LastName": "Smith", "FirstName": "Ann"}, "Smith, Ann"},
+		{map[string]string{"LastName": "Smith"}, "Smith"},
+		{map[string]string{"FirstName": "Ann"}, "Ann"},
+		{map[string]string{}, ""},
+	} {
+		if got := tmpl.Render(func(column string) string { return tt.values[column] }); got != tt.want {
+			t.Errorf("Render(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestAccountDisplayNames(t *testing.T) {
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "names.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	a.Config.AccountDisplayName = "{{last_name}}, {{first_name}}"
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, FullName, FirstName, LastName) VALUES
+		(1, 'Ann Smith', 'Ann', 'Smith'), (2, 'Acme Corp', NULL, NULL), (3, NULL, NULL, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := a.AccountDisplayNames([]int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]string{1: "Smith, Ann", 2: "Acme Corp", 3: "Account 3"}
+	if len(names) != len(want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	for id, name := range want {
+		if names[id] != name {
+			t.Errorf("name of %d = %q, want %q", id, names[id], name)
+		}
+	}
+
+	// An invalid template falls back to the full name.
+	a.Config.AccountDisplayName = "{{nickname}}"
+	if names, err = a.AccountDisplayNames([]int{1}); err != nil || names[1] != "Ann Smith" {
+		t.Errorf("names with an invalid template = %v, %v; want Ann Smith", names, err)
+	}
+}
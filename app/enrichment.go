@@ -0,0 +1,315 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"badgermaps/events"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"reflect"
+	"time"
+
+	"github.com/guregu/null/v6"
+)
+
+// DefaultEnrichmentScriptTimeout bounds an enrichment script run when
+// enrichment.script_timeout is not configured.
+const DefaultEnrichmentScriptTimeout = time.Minute
+
+// EnrichmentConfig sets the scores computed for accounts after they are
+// pulled. Scores are stored in AccountScores, apart from the pulled
+// columns, so pushes never send them and a pull never overwrites them.
+type EnrichmentConfig struct {
+	Scores []ScoreRule `yaml:"scores,omitempty"`
+	// Script is a command that reads the stored accounts as JSON lines on
+	// stdin and writes one {"id": ..., "scores": {...}} line per account to
+	// stdout. Its scores are stored next to those of Scores.
+	Script        string `yaml:"script,omitempty"`
+	ScriptTimeout string `yaml:"script_timeout,omitempty"`
+	// Ranking names the score the dashboard ranks accounts by; the first
+	// of Scores by default.
+	Ranking string `yaml:"ranking,omitempty"`
+}
+
+// ScoreRule computes a score as the weighted sum of numeric Accounts
+// columns.
+type ScoreRule struct {
+	Name  string      `yaml:"name"`
+	Terms []ScoreTerm `yaml:"terms"`
+}
+
+// ScoreTerm adds Weight times the value of Column to a score. A column
+// without a value adds nothing. With Cap set, larger values count as Cap,
+// so one very stale account does not outweigh everything else.
+type ScoreTerm struct {
+	Column string  `yaml:"column"`
+	Weight float64 `yaml:"weight"`
+	Cap    float64 `yaml:"cap,omitempty"`
+}
+
+// Enabled reports whether any score is configured.
+func (c EnrichmentConfig) Enabled() bool {
+	return len(c.Scores) > 0 || c.Script != ""
+}
+
+// RankingScore returns the name of the score the dashboard ranks by, or ""
+// when there is none.
+func (c EnrichmentConfig) RankingScore() string {
+	if c.Ranking != "" {
+		return c.Ranking
+	}
+	if len(c.Scores) > 0 {
+		return c.Scores[0].Name
+	}
+	return ""
+}
+
+func (c EnrichmentConfig) scriptTimeout() time.Duration {
+	return durationOr(c.ScriptTimeout, DefaultEnrichmentScriptTimeout)
+}
+
+// Validate checks that scores have unique names and that terms name numeric
+// Accounts columns.
+func (c EnrichmentConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Scores))
+	for i, rule := range c.Scores {
+		if rule.Name == "" {
+			return fmt.Errorf("enrichment.scores[%d] has no name", i)
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("enrichment.scores has two scores named %q", rule.Name)
+		}
+		seen[rule.Name] = true
+		if len(rule.Terms) == 0 {
+			return fmt.Errorf("enrichment score %q has no terms", rule.Name)
+		}
+		for _, term := range rule.Terms {
+			if _, ok := accountNumericColumn(&models.Account{}, term.Column); !ok {
+				return fmt.Errorf("enrichment score %q: %s is not a numeric column of Accounts", rule.Name, term.Column)
+			}
+			if term.Cap < 0 {
+				return fmt.Errorf("enrichment score %q: the cap of %s must not be negative", rule.Name, term.Column)
+			}
+		}
+	}
+	if c.ScriptTimeout != "" {
+		if d, err := time.ParseDuration(c.ScriptTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("enrichment.script_timeout must be a positive duration such as \"30s\", got %q", c.ScriptTimeout)
+		}
+	}
+	if c.Script != "" && len(c.Scores) == 0 && c.Ranking == "" {
+		return fmt.Errorf("enrichment.ranking must name a score the script computes")
+	}
+	return nil
+}
+
+// accountNumericColumn returns the value of acc for a numeric Accounts
+// column, and whether column is one.
+func accountNumericColumn(acc *models.Account, column string) (null.Float, bool) {
+	field := reflect.ValueOf(acc).Elem().FieldByName(column)
+	if !field.IsValid() {
+		return null.Float{}, false
+	}
+	switch value := field.Interface().(type) {
+	case null.Float:
+		return value, true
+	case null.Int:
+		return null.NewFloat(float64(value.Int64), value.Valid), true
+	}
+	return null.Float{}, false
+}
+
+// Score computes the rule for acc.
+func (r ScoreRule) Score(acc *models.Account) float64 {
+	var score float64
+	for _, term := range r.Terms {
+		value, ok := accountNumericColumn(acc, term.Column)
+		if !ok || !value.Valid {
+			continue
+		}
+		v := value.Float64
+		if term.Cap > 0 {
+			v = math.Min(v, term.Cap)
+		}
+		score += term.Weight * v
+	}
+	return score
+}
+
+// accountScores is one account's line of enrichment script output.
+type accountScores struct {
+	ID     int                `json:"id"`
+	Scores map[string]float64 `json:"scores"`
+}
+
+// EnrichAccounts computes the configured scores of the stored accounts with
+// ids, or of every account when ids is nil, and stores them in one
+// transaction. A run over every account also removes scores it did not
+// compute, such as those of a rule that was removed. It returns how many
+// scores were stored.
+func (a *App) EnrichAccounts(ids []int) (int, error) {
+	if a.Config == nil || !a.Config.Enrichment.Enabled() {
+		return 0, nil
+	}
+	config := a.Config.Enrichment
+	if err := config.Validate(); err != nil {
+		return 0, err
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return 0, fmt.Errorf("database is not connected")
+	}
+	started := time.Now()
+	accounts, err := a.accountsToEnrich(ids)
+	if err != nil {
+		return 0, err
+	}
+	if len(accounts) == 0 {
+		return 0, nil
+	}
+
+	var commands []database.Command
+	for _, acc := range accounts {
+		for _, rule := range config.Scores {
+			commands = append(commands, database.MergeAccountScoreCommand(int(acc.AccountId.Int64), rule.Name, rule.Score(acc), started))
+		}
+	}
+	if config.Script != "" {
+		for _, acc := range accounts {
+			a.DecryptAccountForDisplay(acc)
+		}
+		scripted, err := runEnrichmentScript(config.Script, config.scriptTimeout(), accounts)
+		if err != nil {
+			return 0, err
+		}
+		for _, line := range scripted {
+			for name, score := range line.Scores {
+				commands = append(commands, database.MergeAccountScoreCommand(line.ID, name, score, started))
+			}
+		}
+	}
+	if ids == nil {
+		commands = append(commands, database.DeleteAccountScoresBeforeCommand(started))
+	}
+	if err := database.WorkCommands(a.DB, "store.account_scores", commands); err != nil {
+		return 0, fmt.Errorf("failed to store account scores: %w", err)
+	}
+	stored := len(commands)
+	if ids == nil {
+		stored--
+	}
+	a.Events.Dispatch(events.Debugf("enrichment", "Stored %d score(s) for %d account(s)", stored, len(accounts)))
+	return stored, nil
+}
+
+func (a *App) accountsToEnrich(ids []int) ([]*models.Account, error) {
+	if ids == nil {
+		accounts, err := database.GetAllAccounts(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read accounts: %w", err)
+		}
+		return accounts, nil
+	}
+	accounts := make([]*models.Account, 0, len(ids))
+	for _, id := range ids {
+		acc, err := database.GetAccountByID(a.DB, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read account %d: %w", id, err)
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts, nil
+}
+
+// runEnrichmentScript passes accounts to the script and reads back its
+// scores. Scores for accounts that were not passed in are rejected, so a
+// script cannot score accounts a single-account pull did not touch.
+func runEnrichmentScript(script string, timeout time.Duration, accounts []*models.Account) ([]accountScores, error) {
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	passed := make(map[int]bool, len(accounts))
+	for _, acc := range accounts {
+		if err := enc.Encode(acc); err != nil {
+			return nil, fmt.Errorf("failed to encode account %d for the enrichment script: %w", acc.AccountId.Int64, err)
+		}
+		passed[int(acc.AccountId.Int64)] = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("enrichment script %s did not finish within %s", script, timeout)
+	}
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("enrichment script %s failed: %w: %s", script, err, msg)
+		}
+		return nil, fmt.Errorf("enrichment script %s failed: %w", script, err)
+	}
+
+	var lines []accountScores
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line accountScores
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("enrichment script output line %d: %w", n, err)
+		}
+		if !passed[line.ID] {
+			return nil, fmt.Errorf("enrichment script output line %d scores account %d, which it was not given", n, line.ID)
+		}
+		for name := range line.Scores {
+			if name == "" {
+				return nil, fmt.Errorf("enrichment script output line %d has a score without a name", n)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// ScoreRanking returns the accounts with the highest ranking score, highest
+// first, and the name of that score. Both are empty when no score is
+// configured.
+func (a *App) ScoreRanking(limit int) (string, []database.RankedAccount, error) {
+	if a.Config == nil {
+		return "", nil, nil
+	}
+	name := a.Config.Enrichment.RankingScore()
+	if name == "" {
+		return "", nil, nil
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return name, nil, fmt.Errorf("database is not connected")
+	}
+	ranking, err := database.GetAccountScoreRanking(a.DB, name, limit)
+	if err != nil {
+		return name, nil, err
+	}
+	names := make([][]string, len(ranking))
+	for i := range ranking {
+		names[i] = []string{ranking[i].FullName}
+	}
+	a.DecryptTableRows([]string{"FullName"}, names)
+	for i := range ranking {
+		ranking[i].FullName = names[i][0]
+	}
+	return name, ranking, nil
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestEnrichmentConfigValidate(t *testing.T) {
+	valid := EnrichmentConfig{Scores: []ScoreRule{{Name: "priority", Terms: []ScoreTerm{
+		{Column: "DaysSinceLastCheckin", Weight: 1, Cap: 365},
+		{Column: "CustomNumeric", Weight: 0.001},
+	}}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	for name, c := range map[string]EnrichmentConfig{
+		"text column":  {Scores: []ScoreRule{{Name: "x", Terms: []ScoreTerm{{Column: "Email", Weight: 1}}}}},
+		"unknown":      {Scores: []ScoreRule{{Name: "x", Terms: []ScoreTerm{{Column: "Revenue", Weight: 1}}}}},
+		"no name":      {Scores: []ScoreRule{{Terms: []ScoreTerm{{Column: "CustomNumeric", Weight: 1}}}}},
+		"duplicate":    {Scores: []ScoreRule{valid.Scores[0], valid.Scores[0]}},
+		"no terms":     {Scores: []ScoreRule{{Name: "x"}}},
+		"script alone": {Script: "score.sh"},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestEnrichAccounts(t *testing.T) {
+	dir := t.TempDir()
+	cfg := database.DBConfig{Type: "sqlite3", Path: filepath.Join(dir, "scores.db")}
+	db, err := database.NewDB(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	a.Config.Enrichment = EnrichmentConfig{Scores: []ScoreRule{
+		{Name: "priority", Terms: []ScoreTerm{
+			{Column: "DaysSinceLastCheckin", Weight: 1, Cap: 100},
+			{Column: "CustomNumeric", Weight: 0.01},
+		}},
+		{Name: "old", Terms: []ScoreTerm{{Column: "DaysSinceLastCheckin", Weight: 1}}},
+	}}
+
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec(`INSERT INTO Accounts (AccountId, FullName, DaysSinceLastCheckin, CustomNumeric) VALUES
+		(1, 'Acme', 400, 1000),
+		(2, 'Globex', 10, 5000),
+		(3, 'Initech', NULL, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := a.EnrichAccounts(nil); err != nil || stored != 6 {
+		t.Fatalf("EnrichAccounts = %d, %v; want 6 scores", stored, err)
+	}
+	name, ranking, err := a.ScoreRanking(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Acme's 400 days are capped at 100.
+	want := []struct {
+		id    int
+		score float64
+	}{{1, 110}, {2, 60}, {3, 0}}
+	if name != "priority" || len(ranking) != len(want) {
+		t.Fatalf("ScoreRanking = %q, %+v", name, ranking)
+	}
+	for i, w := range want {
+		if ranking[i].AccountId != w.id || ranking[i].Score != w.score {
+			t.Errorf("rank %d = account %d scoring %v, want account %d scoring %v", i+1, ranking[i].AccountId, ranking[i].Score, w.id, w.score)
+		}
+	}
+
+	// A removed rule's scores go with the next full run.
+	a.Config.Enrichment.Scores = a.Config.Enrichment.Scores[:1]
+	if _, err := a.EnrichAccounts(nil); err != nil {
+		t.Fatal(err)
+	}
+	var old int
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM AccountScores WHERE ScoreName = 'old'").Scan(&old); err != nil {
+		t.Fatal(err)
+	}
+	if old != 0 {
+		t.Errorf("%d scores of the removed rule are left", old)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	script := filepath.Join(dir, "score.sh")
+	body := "#!/bin/sh\nwhile read -r line; do\n  id=$(printf '%s' \"$line\" | sed 's/.*\"id\":\\([0-9]*\\).*/\\1/')\n  echo \"{\\\"id\\\":$id,\\\"scores\\\":{\\\"scripted\\\":$id}}\"\ndone\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	a.Config.Enrichment.Script = script
+	if stored, err := a.EnrichAccounts([]int{2}); err != nil || stored != 2 {
+		t.Fatalf("EnrichAccounts with a script = %d, %v; want 2 scores", stored, err)
+	}
+	ranking, err = database.GetAccountScoreRanking(db, "scripted", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranking) != 1 || ranking[0].AccountId != 2 || ranking[0].Score != 2 {
+		t.Errorf("scripted ranking = %+v, want account 2 scoring 2", ranking)
+	}
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/utils"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DBEnvironment is a named database target, such as dev, stage, or prod.
+// Destructive operations against an environment tagged production require
+// typing its name.
+type DBEnvironment struct {
+	Name       string            `yaml:"name"`
+	Production bool              `yaml:"production,omitempty"`
+	Color      string            `yaml:"color,omitempty"`
+	DB         database.DBConfig `yaml:"db"`
+}
+
+// Banner colors used when an environment does not set its own.
+const (
+	EnvironmentColorProduction = "#c62828"
+	EnvironmentColorStaging    = "#ef6c00"
+	EnvironmentColorDev        = "#2e7d32"
+	EnvironmentColorOther      = "#1565c0"
+)
+
+// BannerColor is the environment's color, or one picked from its name and
+// production tag: red for production, orange for staging, green for
+// development, and blue otherwise.
+func (e DBEnvironment) BannerColor() string {
+	if e.Color != "" {
+		return e.Color
+	}
+	name := strings.ToLower(e.Name)
+	switch {
+	case e.Production:
+		return EnvironmentColorProduction
+	case strings.Contains(name, "stag"):
+		return EnvironmentColorStaging
+	case strings.Contains(name, "dev") || strings.Contains(name, "test") || strings.Contains(name, "local"):
+		return EnvironmentColorDev
+	}
+	return EnvironmentColorOther
+}
+
+// environment returns the environment called name.
+func (c *Config) environment(name string) (*DBEnvironment, error) {
+	for i := range c.Environments {
+		if strings.EqualFold(c.Environments[i].Name, name) {
+			return &c.Environments[i], nil
+		}
+	}
+	names := make([]string, len(c.Environments))
+	for i, env := range c.Environments {
+		names[i] = env.Name
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown environment %q: no environments are configured", name)
+	}
+	return nil, fmt.Errorf("unknown environment %q; configured environments: %s", name, strings.Join(names, ", "))
+}
+
+// selectedEnvironment is the environment named by --env, or by the
+// environment key when the flag is not set.
+func (a *App) selectedEnvironment() string {
+	if a.State != nil && a.State.Environment != "" {
+		return a.State.Environment
+	}
+	return a.Config.Environment
+}
+
+// applyEnvironment points Config.DB at the selected environment's database,
+// keeping the top-level db settings so SaveConfig can write them back.
+func (a *App) applyEnvironment() error {
+	name := a.selectedEnvironment()
+	if name == "" {
+		return nil
+	}
+	env, err := a.Config.environment(name)
+	if err != nil {
+		return err
+	}
+	a.baseDB = a.Config.DB
+	a.activeEnv = env.Name
+	a.Config.DB = env.DB
+	return nil
+}
+
+// restoreBaseDB undoes applyEnvironment before the config is read again.
+func (a *App) restoreBaseDB() {
+	if a.activeEnv == "" {
+		return
+	}
+	a.Config.DB = a.baseDB
+	a.activeEnv = ""
+}
+
+// configToSave is the config as it belongs on disk: edits to the database
+// settings are written to the active environment, not the top-level db.
+func (a *App) configToSave() *Config {
+	if a.activeEnv == "" {
+		return a.Config
+	}
+	saved := *a.Config
+	saved.DB = a.baseDB
+	saved.Environments = append([]DBEnvironment(nil), a.Config.Environments...)
+	if env, err := saved.environment(a.activeEnv); err == nil {
+		env.DB = a.Config.DB
+	}
+	return &saved
+}
+
+// ActiveEnvironment returns the environment the database settings come
+// from, or nil when the top-level db settings are in use.
+func (a *App) ActiveEnvironment() *DBEnvironment {
+	if a.activeEnv == "" {
+		return nil
+	}
+	env, err := a.Config.environment(a.activeEnv)
+	if err != nil {
+		return nil
+	}
+	active := *env
+	active.DB = a.Config.DB
+	return &active
+}
+
+// UseEnvironment saves name as the environment to use and reconnects to its
+// database. An empty name goes back to the top-level db settings.
+func (a *App) UseEnvironment(name string) error {
+	if name != "" {
+		env, err := a.Config.environment(name)
+		if err != nil {
+			return err
+		}
+		name = env.Name
+	}
+	a.Config.Environment = name
+	if a.State != nil {
+		a.State.Environment = ""
+	}
+	if err := a.SaveConfig(); err != nil {
+		return err
+	}
+	return a.LoadConfig()
+}
+
+// ProductionConfirmationError is returned when a destructive operation
+// against a production environment was not confirmed with its name.
+type ProductionConfirmationError struct {
+	Environment string
+	Operation   string
+}
+
+func (e *ProductionConfirmationError) Error() string {
+	return fmt.Sprintf("%s targets the production environment %q; type the environment name to confirm (--confirm-env %s)", e.Operation, e.Environment, e.Environment)
+}
+
+// GuardProduction checks that typed names the active environment when it is
+// tagged production. Other environments need no confirmation.
+func (a *App) GuardProduction(operation, typed string) error {
+	env := a.ActiveEnvironment()
+	if env == nil || !env.Production {
+		return nil
+	}
+	if strings.TrimSpace(typed) == env.Name {
+		return nil
+	}
+	return &ProductionConfirmationError{Environment: env.Name, Operation: operation}
+}
+
+// ConfirmProduction asks for the name of a production environment before a
+// destructive operation from the command line. --confirm-env answers
+// without a prompt.
+func (a *App) ConfirmProduction(operation string) error {
+	env := a.ActiveEnvironment()
+	if env == nil || !env.Production {
+		return nil
+	}
+	if a.State.ConfirmEnvironment != "" || a.State.NoInput {
+		return a.GuardProduction(operation, a.State.ConfirmEnvironment)
+	}
+	fmt.Println(utils.Colors.Red("Warning: %s targets the production environment %q.", operation, env.Name))
+	reader := bufio.NewReader(os.Stdin)
+	return a.GuardProduction(operation, utils.PromptString(reader, "Type the environment name to continue", ""))
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func loadEnvironmentConfig(t *testing.T, env string) (*App, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, path, `
+db:
+  type: sqlite3
+  path: `+filepath.Join(dir, "base.db")+`
+environment: dev
+environments:
+  - name: dev
+    db:
+      type: sqlite3
+      path: `+filepath.Join(dir, "dev.db")+`
+  - name: prod
+    production: true
+    db:
+      type: sqlite3
+      path: `+filepath.Join(dir, "prod.db")+`
+`)
+	a := NewApp()
+	*a.State.ConfigFile = path
+	a.State.Environment = env
+	if err := a.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	t.Cleanup(func() {
+		if a.DB != nil {
+			a.DB.Close()
+		}
+	})
+	return a, dir
+}
+
+func TestLoadConfigUsesSelectedEnvironment(t *testing.T) {
+	a, dir := loadEnvironmentConfig(t, "")
+	if got := a.Config.DB.Path; got != filepath.Join(dir, "dev.db") {
+		t.Fatalf("DB.Path = %q, want the dev database", got)
+	}
+	if env := a.ActiveEnvironment(); env == nil || env.Name != "dev" || env.Production {
+		t.Fatalf("ActiveEnvironment = %+v, want dev", env)
+	}
+
+	a, dir = loadEnvironmentConfig(t, "PROD")
+	if got := a.Config.DB.Path; got != filepath.Join(dir, "prod.db") {
+		t.Fatalf("--env PROD: DB.Path = %q, want the prod database", got)
+	}
+	if env := a.ActiveEnvironment(); env == nil || env.Name != "prod" || !env.Production {
+		t.Fatalf("ActiveEnvironment = %+v, want prod", env)
+	}
+}
+
+func TestLoadConfigRejectsUnknownEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, path, "environment: qa\nenvironments:\n  - name: dev\n")
+	a := NewApp()
+	*a.State.ConfigFile = path
+	err := a.LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), `unknown environment "qa"`) {
+		t.Fatalf("LoadConfig error = %v, want unknown environment", err)
+	}
+}
+
+func TestSaveConfigWritesEnvironmentDB(t *testing.T) {
+	a, dir := loadEnvironmentConfig(t, "")
+	a.Config.DB.Path = filepath.Join(dir, "dev2.db")
+	if err := a.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.DB.Path != filepath.Join(dir, "base.db") {
+		t.Errorf("top-level db.path = %q, want it unchanged", saved.DB.Path)
+	}
+	if saved.Environments[0].DB.Path != filepath.Join(dir, "dev2.db") {
+		t.Errorf("dev db.path = %q, want the edited path", saved.Environments[0].DB.Path)
+	}
+
+	if err := a.UseEnvironment(""); err != nil {
+		t.Fatalf("UseEnvironment: %v", err)
+	}
+	if a.ActiveEnvironment() != nil || a.Config.DB.Path != filepath.Join(dir, "base.db") {
+		t.Fatalf("after UseEnvironment(\"\"): env %+v, DB.Path %q", a.ActiveEnvironment(), a.Config.DB.Path)
+	}
+}
+
+func TestGuardProduction(t *testing.T) {
+	a, _ := loadEnvironmentConfig(t, "dev")
+	if err := a.GuardProduction("db migrate", ""); err != nil {
+		t.Fatalf("dev environment: %v", err)
+	}
+
+	a, _ = loadEnvironmentConfig(t, "prod")
+	err := a.GuardProduction("db migrate", "dev")
+	var confirmErr *ProductionConfirmationError
+	if !errors.As(err, &confirmErr) || confirmErr.Environment != "prod" {
+		t.Fatalf("wrong name: err = %v, want a ProductionConfirmationError", err)
+	}
+	if err := a.GuardProduction("db migrate", " prod "); err != nil {
+		t.Fatalf("typed name: %v", err)
+	}
+
+	a.State.NoInput = true
+	if err := a.ConfirmProduction("db migrate"); !errors.As(err, &confirmErr) {
+		t.Fatalf("--no-input without --confirm-env: err = %v", err)
+	}
+	a.State.ConfirmEnvironment = "prod"
+	if err := a.ConfirmProduction("db migrate"); err != nil {
+		t.Fatalf("--confirm-env prod: %v", err)
+	}
+}
+
+func TestBannerColor(t *testing.T) {
+	tests := []struct {
+		env  DBEnvironment
+		want string
+	}{
+		{DBEnvironment{Name: "prod", Production: true}, EnvironmentColorProduction},
+		{DBEnvironment{Name: "Staging"}, EnvironmentColorStaging},
+		{DBEnvironment{Name: "dev"}, EnvironmentColorDev},
+		{DBEnvironment{Name: "reporting"}, EnvironmentColorOther},
+		{DBEnvironment{Name: "prod", Production: true, Color: "#000000"}, "#000000"},
+	}
+	for _, tt := range tests {
+		if got := tt.env.BannerColor(); got != tt.want {
+			t.Errorf("BannerColor(%+v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestReloadConfigComparesActiveEnvironmentDB(t *testing.T) {
+	a, _ := loadEnvironmentConfig(t, "")
+	reload, err := a.ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if reload.Changed() {
+		t.Fatalf("unchanged file reported applied %v, restart %v", reload.Applied, reload.RestartRequired)
+	}
+}
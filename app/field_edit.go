@@ -0,0 +1,113 @@
+package app
+
+import (
+	"badgermaps/database"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// accountEditableFields maps Accounts columns that can be edited from the
+// Explorer to the API field sent on push. Identifiers, computed columns and
+// sync timestamps are left out.
+var accountEditableFields = map[string]string{
+	"FirstName":    "first_name",
+	"LastName":     "last_name",
+	"PhoneNumber":  "phone_number",
+	"Email":        "email",
+	"CustomerId":   "customer_id",
+	"Notes":        "notes",
+	"CrmId":        "crm_id",
+	"AccountOwner": "account_owner",
+	"FollowUpDate": "follow_up_date",
+}
+
+func init() {
+	for i := 1; i <= 30; i++ {
+		suffix := ""
+		if i > 1 {
+			suffix = strconv.Itoa(i)
+		}
+		accountEditableFields["CustomText"+suffix] = "custom_text" + suffix
+		accountEditableFields["CustomNumeric"+suffix] = "custom_numeric" + suffix
+	}
+}
+
+// ErrFieldUnchanged is returned when a field edit does not change the value.
+var ErrFieldUnchanged = errors.New("value is unchanged")
+
+// AccountFieldForColumn returns the API field for an editable Accounts
+// column.
+func AccountFieldForColumn(column string) (string, bool) {
+	field, ok := accountEditableFields[column]
+	return field, ok
+}
+
+// ValidateAccountFieldEdit checks a single-field edit and returns the value
+// to stage.
+func ValidateAccountFieldEdit(column, oldValue, newValue string) (string, error) {
+	if _, ok := accountEditableFields[column]; !ok {
+		return "", fmt.Errorf("column %s cannot be edited", column)
+	}
+	value := strings.TrimSpace(newValue)
+	if value == strings.TrimSpace(oldValue) {
+		return "", ErrFieldUnchanged
+	}
+	return validateAccountFieldValue(column, value)
+}
+
+// validateAccountFieldValue checks a trimmed value for an editable column.
+func validateAccountFieldValue(column, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	switch {
+	case column == "Email":
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return "", fmt.Errorf("%q is not a valid email address", value)
+		}
+	case column == "FollowUpDate":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("follow-up date must be YYYY-MM-DD")
+		}
+	case strings.HasPrefix(column, "CustomNumeric"):
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("%s must be a number", column)
+		}
+	}
+	return value, nil
+}
+
+// StageAccountFieldEdit validates a single-field edit made while browsing
+// and stages it as a pending UPDATE containing only that field.
+func (a *App) StageAccountFieldEdit(accountID int, column, oldValue, newValue string) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	if accountID <= 0 {
+		return fmt.Errorf("invalid account id %d", accountID)
+	}
+	value, err := ValidateAccountFieldEdit(column, oldValue, newValue)
+	if err != nil {
+		return err
+	}
+	direction, err := a.accountSyncDirection(column)
+	if err != nil {
+		return err
+	}
+	if direction == SyncPullOnly {
+		return fmt.Errorf("%s is pull-only; local edits of it are never pushed", column)
+	}
+
+	changes, err := json.Marshal(map[string]string{accountEditableFields[column]: value})
+	if err != nil {
+		return err
+	}
+	return database.StageAccountChange(a.DB, accountID, "UPDATE", string(changes))
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestValidateAccountFieldEdit(t *testing.T) {
+	tests := []struct {
+		column, old, new string
+		wantErr          bool
+	}{
+		{"LastName", "Smith", "Smyth", false},
+		{"Notes", "call back", "", false},
+		{"Email", "", "jo@example.com", false},
+		{"Email", "", "not an email", true},
+		{"FollowUpDate", "", "2024-02-30", true},
+		{"CustomNumeric3", "", "12.5", false},
+		{"CustomNumeric3", "", "twelve", true},
+		{"AccountId", "1", "2", true},
+		{"FullName", "Jo Smith", "Jo Smyth", true},
+	}
+	for _, tt := range tests {
+		_, err := ValidateAccountFieldEdit(tt.column, tt.old, tt.new)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %q -> %q: err = %v, wantErr %v", tt.column, tt.old, tt.new, err, tt.wantErr)
+		}
+	}
+	if _, err := ValidateAccountFieldEdit("LastName", "Smith", " Smith "); !errors.Is(err, ErrFieldUnchanged) {
+		t.Fatalf("unchanged edit err = %v", err)
+	}
+}
+
+func TestStageAccountFieldEditStagesOnlyThatField(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "edit.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if err := a.StageAccountFieldEdit(42, "PhoneNumber", "555-0100", "555-0199"); err != nil {
+		t.Fatalf("StageAccountFieldEdit: %v", err)
+	}
+	changes, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("GetPendingAccountChanges = %v, %v", changes, err)
+	}
+	if changes[0].AccountId != 42 || changes[0].ChangeType != "UPDATE" || changes[0].Changes != `{"phone_number":"555-0199"}` {
+		t.Fatalf("staged change = %+v", changes[0])
+	}
+}
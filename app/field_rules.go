@@ -0,0 +1,98 @@
+package app
+
+import (
+	"badgermaps/app/state"
+	"badgermaps/events"
+	"fmt"
+	"sort"
+)
+
+// FieldRule sets the sync direction and merge strategy of an account field
+// from the config, so a shared config can manage them for every machine.
+// Empty values leave the field's setting alone.
+type FieldRule struct {
+	SyncDirection string `yaml:"sync_direction,omitempty"`
+	MergeStrategy string `yaml:"merge_strategy,omitempty"`
+}
+
+// ValidateFieldRules checks that every field_rules entry names an editable
+// account column with a known direction and strategy.
+func ValidateFieldRules(rules map[string]FieldRule) error {
+	for _, column := range sortedFieldRuleColumns(rules) {
+		rule := rules[column]
+		if _, ok := accountEditableFields[column]; !ok {
+			return fmt.Errorf("field_rules: column %s is not synced", column)
+		}
+		if rule.SyncDirection != "" {
+			if _, err := ParseSyncDirection(rule.SyncDirection); err != nil {
+				return fmt.Errorf("field_rules.%s: %w", column, err)
+			}
+		}
+		if rule.MergeStrategy != "" {
+			if _, err := ParseMergeStrategy(rule.MergeStrategy); err != nil {
+				return fmt.Errorf("field_rules.%s: %w", column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyFieldRules writes the configured field rules to the database where
+// they differ from the stored settings. Rules that fail are warnings, the
+// same as an invalid entry in the rest of the config. A database without
+// the schema yet gets them once it is migrated.
+func (a *App) applyFieldRules() {
+	if len(a.Config.FieldRules) == 0 || a.DB == nil || !a.DB.IsConnected() {
+		return
+	}
+	if a.DB.ValidateSchema(&state.State{Quiet: true}) != nil {
+		return
+	}
+	stored, err := a.AccountSyncRules()
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("config", "Failed to read field rules: %v", err))
+		return
+	}
+	current := make(map[string]FieldRule, len(stored))
+	for _, rule := range stored {
+		current[rule.FieldName] = FieldRule{SyncDirection: rule.Direction, MergeStrategy: rule.MergeStrategy}
+	}
+	applied := 0
+	for _, column := range sortedFieldRuleColumns(a.Config.FieldRules) {
+		rule := a.Config.FieldRules[column]
+		have := current[column]
+		if rule.SyncDirection != "" {
+			direction, err := ParseSyncDirection(rule.SyncDirection)
+			if err == nil && direction != have.SyncDirection {
+				err = a.SetAccountFieldSyncDirection(column, direction)
+				applied++
+			}
+			if err != nil {
+				a.Events.Dispatch(events.Warningf("config", "field_rules.%s: %v; the rule is ignored", column, err))
+				continue
+			}
+		}
+		if rule.MergeStrategy != "" {
+			strategy, err := ParseMergeStrategy(rule.MergeStrategy)
+			if err == nil && strategy != have.MergeStrategy {
+				err = a.SetAccountFieldMergeStrategy(column, strategy)
+				applied++
+			}
+			if err != nil {
+				a.Events.Dispatch(events.Warningf("config", "field_rules.%s: %v; the rule is ignored", column, err))
+			}
+		}
+	}
+	if applied > 0 {
+		a.Events.Dispatch(events.Debugf("config", "Applied %d field rule change(s) from the config", applied))
+	}
+}
+
+func sortedFieldRuleColumns(rules map[string]FieldRule) []string {
+	columns := make([]string, 0, len(rules))
+	for column := range rules {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"strings"
+)
+
+// FieldSchema is what ApplyProfileSchema learned from a profile's data
+// fields.
+type FieldSchema struct {
+	// Labels maps Accounts columns, such as CustomText3, to the label of the
+	// data field stored in them, such as "Territory".
+	Labels map[string]string
+	// Unmapped lists the data fields that have no Accounts column, so their
+	// values are not stored.
+	Unmapped []string
+}
+
+// ApplyProfileSchema maps each data field of profile to the Accounts column
+// it is stored in, records the mapping in FieldMaps so the GUI labels the
+// columns, and warns about data fields with no column. It replaces any
+// earlier mapping.
+func (a *App) ApplyProfileSchema(profile *models.UserProfile) (FieldSchema, error) {
+	schema := FieldSchema{Labels: make(map[string]string)}
+	if a.DB == nil || a.DB.GetDB() == nil {
+		return schema, fmt.Errorf("database is not connected")
+	}
+	rules, err := database.GetFieldSyncRules(a.DB, "Account")
+	if err != nil {
+		return schema, fmt.Errorf("failed to read field maps: %w", err)
+	}
+
+	dataSets := make(map[string]database.FieldMapDataSet)
+	for _, datafield := range profile.Datafields {
+		name := strings.TrimSpace(datafield.Name.String)
+		if name == "" {
+			continue
+		}
+		column, ok := dataFieldColumn(datafield, rules)
+		if !ok {
+			schema.Unmapped = append(schema.Unmapped, name)
+			a.Events.Dispatch(events.Warningf("profile", "Data field %q (%s) has no Accounts column; its values are not stored", datafield.Label.String, name))
+			continue
+		}
+		if _, taken := dataSets[column]; taken {
+			// The first data field for a column wins, as in the labels read
+			// from DataSets.
+			continue
+		}
+		label := strings.TrimSpace(datafield.Label.String)
+		dataSets[column] = database.FieldMapDataSet{Name: name, Label: label}
+		if label != "" {
+			schema.Labels[column] = label
+		}
+	}
+	if err := database.SetFieldMapDataSets(a.DB, dataSets); err != nil {
+		return schema, fmt.Errorf("failed to update field maps: %w", err)
+	}
+
+	a.fieldLabelsMu.Lock()
+	a.fieldLabels, a.fieldLabelsDB = schema.Labels, a.DB
+	a.fieldLabelsMu.Unlock()
+	a.Events.Dispatch(events.Debugf("profile", "Mapped %d data fields to Accounts columns", len(dataSets)))
+	return schema, nil
+}
+
+// dataFieldColumn returns the Accounts column a data field is stored in,
+// from its account_field or, failing that, its name. Custom fields may be
+// written as custom_text2, CustomText2, or CustomText1 for CustomText.
+func dataFieldColumn(datafield models.DataField, rules []database.FieldSyncRule) (string, bool) {
+	for _, candidate := range []string{datafield.AccountField.String, datafield.Name.String} {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		if column, ok := ParseCustomColumn(candidate); ok {
+			return column, true
+		}
+		key := strings.ToLower(strings.ReplaceAll(candidate, "_", ""))
+		for _, rule := range rules {
+			if key == strings.ToLower(rule.FieldName) || key == strings.ToLower(strings.ReplaceAll(rule.JsonField, "_", "")) {
+				return rule.FieldName, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AccountFieldLabels maps Accounts columns to the labels the profile gives
+// them, as last recorded by ApplyProfileSchema. It is empty before the
+// profile has been read.
+func (a *App) AccountFieldLabels() map[string]string {
+	if a.DB == nil || a.DB.GetDB() == nil {
+		return nil
+	}
+	a.fieldLabelsMu.Lock()
+	defer a.fieldLabelsMu.Unlock()
+	if a.fieldLabels == nil || a.fieldLabelsDB != a.DB {
+		rules, err := database.GetFieldSyncRules(a.DB, "Account")
+		if err != nil {
+			return nil
+		}
+		labels := make(map[string]string)
+		for _, rule := range rules {
+			if rule.Label != "" {
+				labels[rule.FieldName] = rule.Label
+			}
+		}
+		a.fieldLabels, a.fieldLabelsDB = labels, a.DB
+	}
+	return a.fieldLabels
+}
+
+// AccountColumnLabel returns the profile's label for an Accounts column, or
+// the column itself when it has none.
+func (a *App) AccountColumnLabel(column string) string {
+	if label := a.AccountFieldLabels()[column]; label != "" {
+		return label
+	}
+	return column
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/api/models"
+	"badgermaps/app/state"
+	"badgermaps/database"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestApplyProfileSchema(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "schema.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if labels := a.AccountFieldLabels(); len(labels) != 0 {
+		t.Fatalf("labels before the profile was read: %v", labels)
+	}
+	profile := &models.UserProfile{Datafields: []models.DataField{
+		{Name: null.StringFrom("ct3"), Label: null.StringFrom("Territory"), AccountField: null.StringFrom("CustomText3")},
+		{Name: null.StringFrom("cn1"), Label: null.StringFrom("Revenue"), AccountField: null.StringFrom("CustomNumeric1")},
+		{Name: null.StringFrom("custom_text7"), Label: null.StringFrom("Segment")},
+		{Name: null.StringFrom("dufu"), Label: null.StringFrom("Next Visit"), AccountField: null.StringFrom("FollowUpDate")},
+		{Name: null.StringFrom("region_code"), Label: null.StringFrom("Region")},
+	}}
+	schema, err := a.ApplyProfileSchema(profile)
+	if err != nil {
+		t.Fatalf("ApplyProfileSchema: %v", err)
+	}
+	want := map[string]string{"CustomText3": "Territory", "CustomNumeric": "Revenue", "CustomText7": "Segment", "FollowUpDate": "Next Visit"}
+	for column, label := range want {
+		if schema.Labels[column] != label {
+			t.Errorf("label of %s = %q, want %q", column, schema.Labels[column], label)
+		}
+	}
+	if len(schema.Unmapped) != 1 || schema.Unmapped[0] != "region_code" {
+		t.Errorf("Unmapped = %v", schema.Unmapped)
+	}
+
+	// The labels are read back from FieldMaps by a fresh app.
+	fresh := NewApp()
+	fresh.DB = db
+	if got := fresh.AccountColumnLabel("CustomText3"); got != "Territory" {
+		t.Errorf("AccountColumnLabel(CustomText3) = %q", got)
+	}
+	if got := fresh.AccountColumnLabel("CustomText2"); got != "CustomText2" {
+		t.Errorf("unlabeled column = %q", got)
+	}
+
+	// A profile without a field clears its label.
+	profile.Datafields = profile.Datafields[1:]
+	if _, err := a.ApplyProfileSchema(profile); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.AccountColumnLabel("CustomText3"); got != "CustomText3" {
+		t.Errorf("removed data field still labels CustomText3 as %q", got)
+	}
+	rules, err := database.GetFieldSyncRules(db, "Account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range rules {
+		if rule.FieldName == "CustomText3" && rule.Label != "" {
+			t.Errorf("FieldMaps still labels CustomText3 %q", rule.Label)
+		}
+	}
+}
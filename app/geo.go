@@ -0,0 +1,151 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RadiusFilter keeps accounts whose stored location lies within Km of a
+// point. The point is given as coordinates or as an address, which
+// ResolveRadiusFilter looks up among synced locations.
+type RadiusFilter struct {
+	Km      float64
+	Center  database.GeoPoint
+	Address string
+}
+
+var (
+	radiusPattern = regexp.MustCompile(`(?i)^(?:within\s+)?([0-9]*\.?[0-9]+)\s*(.*)$`)
+	radiusWords   = regexp.MustCompile(`(?i)^(?:km\b\s*)?(?:(?:of|from|around)\b\s*)?`)
+	pointPattern  = regexp.MustCompile(`^\s*(-?[0-9]*\.?[0-9]+)\s*,\s*(-?[0-9]*\.?[0-9]+)\s*$`)
+)
+
+// ParseRadiusFilter reads a radius such as "25 km of 40.7128,-74.0060" or
+// "10 km of 123 Main St, Springfield". "km" and "of" are optional.
+func ParseRadiusFilter(value string) (RadiusFilter, error) {
+	match := radiusPattern.FindStringSubmatch(strings.TrimSpace(value))
+	var where string
+	if match != nil {
+		where = strings.TrimSpace(radiusWords.ReplaceAllString(match[2], ""))
+	}
+	if where == "" {
+		return RadiusFilter{}, fmt.Errorf("invalid radius %q (expected e.g. \"25 km of 40.7128,-74.0060\" or \"25 km of <address>\")", value)
+	}
+	km, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || km <= 0 {
+		return RadiusFilter{}, fmt.Errorf("invalid radius %q: the distance must be greater than zero", value)
+	}
+	filter := RadiusFilter{Km: km}
+	if point := pointPattern.FindStringSubmatch(where); point != nil {
+		lat, _ := strconv.ParseFloat(point[1], 64)
+		lon, _ := strconv.ParseFloat(point[2], 64)
+		filter.Center = database.GeoPoint{Lat: lat, Lon: lon}
+		if !filter.Center.Valid() {
+			return RadiusFilter{}, fmt.Errorf("invalid radius %q: %s is not a latitude,longitude", value, where)
+		}
+		return filter, nil
+	}
+	filter.Address = where
+	return filter, nil
+}
+
+func (f RadiusFilter) String() string {
+	km := strconv.FormatFloat(f.Km, 'f', -1, 64)
+	if f.Address != "" {
+		return fmt.Sprintf("%s km of %s", km, f.Address)
+	}
+	return fmt.Sprintf("%s km of %s", km, f.Center)
+}
+
+// Contains reports whether p lies within the radius. The filter must be
+// resolved.
+func (f RadiusFilter) Contains(p database.GeoPoint) bool {
+	return database.DistanceKm(f.Center, p) <= f.Km
+}
+
+// ResolveRadiusFilter sets the center of an address filter to the middle of
+// the stored locations matching the address, falling back to the given
+// locations (such as a customers list) when none are stored. An address
+// whose matches are spread wider than the radius is rejected as ambiguous.
+func (a *App) ResolveRadiusFilter(f RadiusFilter, fallback []models.Location) (RadiusFilter, error) {
+	if f.Address == "" {
+		return f, nil
+	}
+	var points []database.GeoPoint
+	if a.DB != nil && a.DB.IsConnected() {
+		stored, err := database.FindLocationsByAddress(a.DB, f.Address)
+		if err != nil {
+			return f, fmt.Errorf("failed to look up %q: %w", f.Address, err)
+		}
+		points = stored
+	}
+	if len(points) == 0 {
+		points = LocationsMatchingAddress(fallback, f.Address)
+	}
+	if len(points) == 0 {
+		return f, fmt.Errorf("no synced location matches %q; use latitude,longitude instead", f.Address)
+	}
+
+	var center database.GeoPoint
+	for _, p := range points {
+		center.Lat += p.Lat
+		center.Lon += p.Lon
+	}
+	center.Lat /= float64(len(points))
+	center.Lon /= float64(len(points))
+	for _, p := range points {
+		if database.DistanceKm(center, p) > f.Km {
+			return f, fmt.Errorf("%q matches %d locations too far apart for a %s km radius; add a state or zip code, or use latitude,longitude",
+				f.Address, len(points), strconv.FormatFloat(f.Km, 'f', -1, 64))
+		}
+	}
+	f.Center = center
+	return f, nil
+}
+
+// LocationsMatchingAddress returns the coordinates of locations matched the
+// way database.FindLocationsByAddress matches stored ones.
+func LocationsMatchingAddress(locations []models.Location, address string) []database.GeoPoint {
+	term := strings.ToLower(strings.TrimSpace(address))
+	if term == "" {
+		return nil
+	}
+	var points []database.GeoPoint
+	for _, loc := range locations {
+		if !loc.Lat.Valid || !loc.Long.Valid {
+			continue
+		}
+		if strings.Contains(strings.ToLower(loc.Location.String), term) ||
+			strings.Contains(strings.ToLower(loc.AddressLine1.String), term) ||
+			strings.ToLower(loc.Zipcode.String) == term ||
+			strings.ToLower(loc.City.String) == term {
+			points = append(points, database.GeoPoint{Lat: loc.Lat.Float64, Lon: loc.Long.Float64})
+		}
+	}
+	return points
+}
+
+// PullRadius returns the territory group pulls are limited to: the --within
+// flag when given, otherwise pull_radius. ok is false when pulls cover every
+// account.
+func (a *App) PullRadius() (f RadiusFilter, ok bool, err error) {
+	value := ""
+	if a.State != nil {
+		value = strings.TrimSpace(a.State.PullRadius)
+	}
+	if value == "" && a.Config != nil {
+		value = strings.TrimSpace(a.Config.PullRadius)
+	}
+	if value == "" {
+		return RadiusFilter{}, false, nil
+	}
+	f, err = ParseRadiusFilter(value)
+	if err != nil {
+		return RadiusFilter{}, true, fmt.Errorf("pull_radius: %w", err)
+	}
+	return f, true, nil
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"badgermaps/database"
+	"testing"
+)
+
+func TestParseRadiusFilter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  RadiusFilter
+	}{
+		{"25 km of 40.7128,-74.0060", RadiusFilter{Km: 25, Center: database.GeoPoint{Lat: 40.7128, Lon: -74.006}}},
+		{"within 2.5km from 40.7128, -74.0060", RadiusFilter{Km: 2.5, Center: database.GeoPoint{Lat: 40.7128, Lon: -74.006}}},
+		{"10 Springfield, IL", RadiusFilter{Km: 10, Address: "Springfield, IL"}},
+		{"10 KM OF 123 Main St", RadiusFilter{Km: 10, Address: "123 Main St"}},
+	}
+	for _, tc := range tests {
+		got, err := ParseRadiusFilter(tc.value)
+		if err != nil {
+			t.Errorf("ParseRadiusFilter(%q): %v", tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseRadiusFilter(%q) = %+v, want %+v", tc.value, got, tc.want)
+		}
+	}
+
+	for _, value := range []string{"", "25 km", "0 km of 40,-74", "25 km of 91,0", "km of Denver"} {
+		if _, err := ParseRadiusFilter(value); err == nil {
+			t.Errorf("ParseRadiusFilter(%q) succeeded, want an error", value)
+		}
+	}
+}
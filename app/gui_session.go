@@ -0,0 +1,44 @@
+package app
+
+import (
+	"badgermaps/events"
+	"reflect"
+)
+
+// GuiSession is where the GUI was left when it last closed, so the next
+// start returns there instead of the Home tab.
+type GuiSession struct {
+	// Width and Height are the window's content size.
+	Width  float32 `yaml:"width,omitempty"`
+	Height float32 `yaml:"height,omitempty"`
+	// Tab is the title of the selected tab.
+	Tab string `yaml:"tab,omitempty"`
+	// ExplorerTable is the table last opened in Explorer.
+	ExplorerTable string `yaml:"explorer_table,omitempty"`
+	// RightPaneVisible is set when the details pane was open.
+	RightPaneVisible bool `yaml:"right_pane_visible,omitempty"`
+}
+
+// MinGuiWindowSize is the smallest restored window edge. Anything smaller
+// is taken as a bad value and the default size is used instead.
+const MinGuiWindowSize = 400
+
+// HasWindowSize reports whether s holds a usable window size.
+func (s GuiSession) HasWindowSize() bool {
+	return s.Width >= MinGuiWindowSize && s.Height >= MinGuiWindowSize
+}
+
+// SaveGuiSession stores session in the config file. Nothing is written when
+// it has not changed or no config file is loaded yet, as during first-time
+// setup.
+func (a *App) SaveGuiSession(session GuiSession) error {
+	if a.Config == nil || a.ConfigFile == "" || reflect.DeepEqual(a.Config.GuiSession, session) {
+		return nil
+	}
+	a.Config.GuiSession = session
+	if err := a.SaveConfig(); err != nil {
+		a.Events.Dispatch(events.Warningf("gui", "Failed to save the window layout: %v", err))
+		return err
+	}
+	return nil
+}
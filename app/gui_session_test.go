@@ -0,0 +1,37 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSaveGuiSession(t *testing.T) {
+	a := NewApp()
+	session := GuiSession{Width: 1280, Height: 800, Tab: "Explorer", ExplorerTable: "Routes", RightPaneVisible: true}
+	if err := a.SaveGuiSession(session); err != nil {
+		t.Fatalf("SaveGuiSession without a config file: %v", err)
+	}
+
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := a.SaveGuiSession(session); err != nil {
+		t.Fatalf("SaveGuiSession: %v", err)
+	}
+	data, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.GuiSession != session {
+		t.Fatalf("saved session = %+v, want %+v", saved.GuiSession, session)
+	}
+
+	if (GuiSession{Width: 120, Height: 800}).HasWindowSize() {
+		t.Error("a 120px wide window was accepted")
+	}
+}
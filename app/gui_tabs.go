@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The titles of the GUI's main tabs, in display order.
+const (
+	GuiTabHome          = "Home"
+	GuiTabSyncCenter    = "Sync Center"
+	GuiTabExplorer      = "Explorer"
+	GuiTabActions       = "Actions"
+	GuiTabServer        = "Server"
+	GuiTabConfiguration = "Configuration"
+)
+
+// GuiTabs lists the main tabs in display order. The Debug tab, shown with
+// --debug, is not listed and cannot be hidden.
+func GuiTabs() []string {
+	return []string{GuiTabHome, GuiTabSyncCenter, GuiTabExplorer, GuiTabActions, GuiTabServer, GuiTabConfiguration}
+}
+
+// GuiTabsConfig lets an admin simplify the GUI for field staff: Hidden tabs
+// are left out of the window, and StartTab is selected at every start
+// instead of the tab open when the GUI last closed.
+type GuiTabsConfig struct {
+	StartTab string   `yaml:"start_tab,omitempty"`
+	Hidden   []string `yaml:"hidden,omitempty"`
+}
+
+// ParseGuiTab returns the title of the tab name refers to. Case, spaces,
+// dashes, and underscores are ignored, and "sync" and "config" are
+// accepted for short.
+func ParseGuiTab(name string) (string, error) {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+	switch key {
+	case "sync":
+		return GuiTabSyncCenter, nil
+	case "config", "settings":
+		return GuiTabConfiguration, nil
+	}
+	for _, tab := range GuiTabs() {
+		if strings.ReplaceAll(strings.ToLower(tab), " ", "") == key {
+			return tab, nil
+		}
+	}
+	return "", fmt.Errorf("unknown tab %q (expected one of %s)", name, strings.Join(GuiTabs(), ", "))
+}
+
+// Validate checks the tab names, that at least one tab is left, and that
+// the start tab is not hidden.
+func (c GuiTabsConfig) Validate() error {
+	hidden := make(map[string]bool, len(c.Hidden))
+	for _, name := range c.Hidden {
+		tab, err := ParseGuiTab(name)
+		if err != nil {
+			return fmt.Errorf("gui_tabs.hidden: %w", err)
+		}
+		hidden[tab] = true
+	}
+	if len(hidden) == len(GuiTabs()) {
+		return fmt.Errorf("gui_tabs.hidden hides every tab")
+	}
+	if c.StartTab == "" {
+		return nil
+	}
+	start, err := ParseGuiTab(c.StartTab)
+	if err != nil {
+		return fmt.Errorf("gui_tabs.start_tab: %w", err)
+	}
+	if hidden[start] {
+		return fmt.Errorf("gui_tabs.start_tab %s is hidden", start)
+	}
+	return nil
+}
+
+// VisibleGuiTabs returns the main tabs the GUI shows, in display order. A
+// misconfigured gui_tabs shows every tab.
+func (a *App) VisibleGuiTabs() []string {
+	if a.Config == nil || len(a.Config.GuiTabs.Hidden) == 0 || a.Config.GuiTabs.Validate() != nil {
+		return GuiTabs()
+	}
+	hidden := make(map[string]bool)
+	for _, name := range a.Config.GuiTabs.Hidden {
+		tab, _ := ParseGuiTab(name)
+		hidden[tab] = true
+	}
+	var visible []string
+	for _, tab := range GuiTabs() {
+		if !hidden[tab] {
+			visible = append(visible, tab)
+		}
+	}
+	return visible
+}
+
+// GuiTabVisible reports whether the GUI shows tab.
+func (a *App) GuiTabVisible(tab string) bool {
+	for _, visible := range a.VisibleGuiTabs() {
+		if visible == tab {
+			return true
+		}
+	}
+	return false
+}
+
+// GuiStartTab returns the configured start tab, or "" when none is set or
+// gui_tabs is misconfigured.
+func (a *App) GuiStartTab() string {
+	if a.Config == nil || a.Config.GuiTabs.StartTab == "" || a.Config.GuiTabs.Validate() != nil {
+		return ""
+	}
+	tab, _ := ParseGuiTab(a.Config.GuiTabs.StartTab)
+	return tab
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuiTabsConfig(t *testing.T) {
+	for name, want := range map[string]string{"sync": GuiTabSyncCenter, "sync-center": GuiTabSyncCenter, "Config": GuiTabConfiguration, " SERVER ": GuiTabServer} {
+		if got, err := ParseGuiTab(name); err != nil || got != want {
+			t.Errorf("ParseGuiTab(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseGuiTab("Debug"); err == nil {
+		t.Error("ParseGuiTab accepted the Debug tab")
+	}
+
+	invalid := []GuiTabsConfig{
+		{Hidden: []string{"Reports"}},
+		{StartTab: "Server", Hidden: []string{"server"}},
+		{Hidden: GuiTabs()},
+	}
+	for _, c := range invalid {
+		if c.Validate() == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", c)
+		}
+	}
+
+	a := NewApp()
+	a.Config.GuiTabs = GuiTabsConfig{StartTab: "sync", Hidden: []string{"Server", "actions"}}
+	if got, want := a.VisibleGuiTabs(), []string{GuiTabHome, GuiTabSyncCenter, GuiTabExplorer, GuiTabConfiguration}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VisibleGuiTabs = %v, want %v", got, want)
+	}
+	if got := a.GuiStartTab(); got != GuiTabSyncCenter {
+		t.Errorf("GuiStartTab = %q", got)
+	}
+
+	// A misconfigured gui_tabs shows every tab and keeps the saved one.
+	a.Config.GuiTabs = GuiTabsConfig{StartTab: "Server", Hidden: []string{"Server"}}
+	if len(a.VisibleGuiTabs()) != len(GuiTabs()) || a.GuiStartTab() != "" {
+		t.Errorf("misconfigured tabs = %v, start %q", a.VisibleGuiTabs(), a.GuiStartTab())
+	}
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"badgermaps/app/update"
+	"badgermaps/events"
+	"slices"
+)
+
+// TourStep is one coach mark of a GUI tour: the tab it selects and what it
+// says about it.
+type TourStep struct {
+	Tab   string
+	Title string
+	Text  string
+}
+
+// Tour is a sequence of coach marks shown once, until it is dismissed.
+type Tour struct {
+	ID string
+	// Version is the release whose changes a "what's new" tour shows. It is
+	// only shown to a GUI last used with an earlier release; an onboarding
+	// tour leaves it empty.
+	Version string
+	Steps   []TourStep
+}
+
+// OnboardingTourID is the tour shown on the first start after setup.
+const OnboardingTourID = "onboarding"
+
+// Tours lists the GUI tours in the order they are shown. Add a tour with
+// a Version to highlight the changes of a release after an update.
+var Tours = []Tour{
+	{
+		ID: OnboardingTourID,
+		Steps: []TourStep{
+			{Tab: GuiTabSyncCenter, Title: "Sync Center", Text: "Pull accounts, check-ins, and routes from BadgerMaps, push the changes you staged, and follow each run as it happens."},
+			{Tab: GuiTabExplorer, Title: "Explorer", Text: "Browse, filter, and export every table of the local database, and open an account to see its details."},
+			{Tab: GuiTabActions, Title: "Actions", Text: "Run commands, queries, or API calls automatically when a pull, push, or webhook event happens."},
+			{Tab: GuiTabServer, Title: "Server", Text: "Run the sync server to receive webhooks and run scheduled jobs while this window is closed."},
+		},
+	},
+}
+
+// TourProgress records the tours the user dismissed.
+type TourProgress struct {
+	Seen []string `yaml:"seen,omitempty"`
+	// Version is the release that was running when a tour was last
+	// dismissed; "what's new" tours for later releases are shown after an
+	// update. It is empty until the onboarding tour is dismissed.
+	Version string `yaml:"version,omitempty"`
+}
+
+// PendingTours returns the tours to show at this start: the onboarding tour
+// until it is dismissed, and after an update the tours of the releases
+// since the last one dismissed. Steps on hidden tabs are left out, and so
+// are tours left without steps.
+func (a *App) PendingTours() []Tour {
+	if a.Config == nil {
+		return nil
+	}
+	progress := a.Config.GuiTours
+	var pending []Tour
+	for _, tour := range Tours {
+		if slices.Contains(progress.Seen, tour.ID) {
+			continue
+		}
+		if tour.Version != "" {
+			if progress.Version == "" || update.CompareVersions(tour.Version, progress.Version) <= 0 || update.CompareVersions(tour.Version, Version) > 0 {
+				continue
+			}
+		}
+		if visible := a.visibleTour(tour); len(visible.Steps) > 0 {
+			pending = append(pending, visible)
+		}
+	}
+	return pending
+}
+
+// DismissTour records that the tour was finished or skipped so it is not
+// shown again, and saves the config file when one is loaded.
+func (a *App) DismissTour(id string) error {
+	if a.Config == nil {
+		return nil
+	}
+	progress := a.Config.GuiTours
+	if !slices.Contains(progress.Seen, id) {
+		progress.Seen = append(slices.Clone(progress.Seen), id)
+	}
+	progress.Version = Version
+	a.Config.GuiTours = progress
+	if a.ConfigFile == "" {
+		return nil
+	}
+	if err := a.SaveConfig(); err != nil {
+		a.Events.Dispatch(events.Warningf("gui", "Failed to save the dismissed tour: %v", err))
+		return err
+	}
+	return nil
+}
+
+// Tour returns the tour named id with its steps on hidden tabs left out,
+// so the GUI can replay it on request.
+func (a *App) Tour(id string) (Tour, bool) {
+	for _, tour := range Tours {
+		if tour.ID == id {
+			tour = a.visibleTour(tour)
+			return tour, len(tour.Steps) > 0
+		}
+	}
+	return Tour{}, false
+}
+
+func (a *App) visibleTour(tour Tour) Tour {
+	visible := tour
+	visible.Steps = nil
+	for _, step := range tour.Steps {
+		if a.GuiTabVisible(step.Tab) {
+			visible.Steps = append(visible.Steps, step)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func tourIDs(tours []Tour) []string {
+	ids := make([]string, len(tours))
+	for i, tour := range tours {
+		ids[i] = tour.ID
+	}
+	return ids
+}
+
+func TestPendingTours(t *testing.T) {
+	defer func(tours []Tour, version string) { Tours, Version = tours, version }(Tours, Version)
+	Version = "1.3.0"
+	Tours = []Tour{
+		{ID: OnboardingTourID, Steps: []TourStep{{Tab: GuiTabSyncCenter}, {Tab: GuiTabServer}}},
+		{ID: "whats-new-1.2", Version: "1.2.0", Steps: []TourStep{{Tab: GuiTabExplorer}}},
+		{ID: "whats-new-1.4", Version: "1.4.0", Steps: []TourStep{{Tab: GuiTabExplorer}}},
+	}
+	a := NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+
+	if got := tourIDs(a.PendingTours()); len(got) != 1 || got[0] != OnboardingTourID {
+		t.Fatalf("new install pending tours = %v, want only onboarding", got)
+	}
+	if err := a.DismissTour(OnboardingTourID); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.PendingTours(); len(got) != 0 {
+		t.Fatalf("pending tours after onboarding = %v, want none for this release", tourIDs(got))
+	}
+	data, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.GuiTours.Seen) != 1 || saved.GuiTours.Version != "1.3.0" {
+		t.Fatalf("saved tour progress = %+v", saved.GuiTours)
+	}
+
+	// An update from 1.1 shows what is new in the releases up to this one.
+	a.Config.GuiTours = TourProgress{Seen: []string{OnboardingTourID}, Version: "1.1.0"}
+	if got := tourIDs(a.PendingTours()); len(got) != 1 || got[0] != "whats-new-1.2" {
+		t.Fatalf("pending tours after an update = %v, want whats-new-1.2", got)
+	}
+
+	a.Config.GuiTours = TourProgress{}
+	a.Config.GuiTabs.Hidden = []string{GuiTabServer}
+	pending := a.PendingTours()
+	if len(pending) != 1 || len(pending[0].Steps) != 1 || pending[0].Steps[0].Tab != GuiTabSyncCenter {
+		t.Fatalf("steps on hidden tabs were kept: %+v", pending)
+	}
+	if tour, ok := a.Tour(OnboardingTourID); !ok || len(tour.Steps) != 1 {
+		t.Fatalf("Tour(onboarding) = %+v, %v", tour, ok)
+	}
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"badgermaps/database"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// rerunnableCommands are the commands the history may run again. They only
+// read the database or refresh it from the API, so running one twice does
+// no harm.
+var rerunnableCommands = []string{"pull", "status", "version", "db stats", "db check-times", "db fsck", "db remaps", "archive list"}
+
+// unsafeRerunFlags turn an otherwise rerunnable command into one that
+// changes data.
+var unsafeRerunFlags = map[string]bool{"delete": true, "confirm-env": true}
+
+// RecordCommand writes a finished command to CommandLog with the flags that
+// were set and how long it took.
+func (a *App) RecordCommand(command string, args, flags []string, duration time.Duration, runErr error) error {
+	if a.DB == nil {
+		return nil
+	}
+	entry := database.CommandLogEntry{
+		Command:  command,
+		Args:     strings.Join(args, " "),
+		Flags:    flags,
+		Success:  runErr == nil,
+		Duration: duration,
+	}
+	if runErr != nil {
+		entry.ErrorMessage = runErr.Error()
+	}
+	return database.InsertCommandLog(a.DB, entry)
+}
+
+// CommandHistory returns the recorded commands matching filter, newest
+// first.
+func (a *App) CommandHistory(filter database.CommandLogFilter) ([]database.CommandLogEntry, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	return database.GetCommandLog(a.DB, filter)
+}
+
+// CommandLine shows entry as it was typed, without the program name.
+func CommandLine(entry database.CommandLogEntry) string {
+	parts := []string{entry.Command}
+	if entry.Args != "" {
+		parts = append(parts, entry.Args)
+	}
+	parts = append(parts, entry.Flags...)
+	return strings.Join(parts, " ")
+}
+
+// CanRerun reports whether entry is a command that is safe to run again.
+func CanRerun(entry database.CommandLogEntry) bool {
+	rerunnable := false
+	for _, safe := range rerunnableCommands {
+		if entry.Command == safe || strings.HasPrefix(entry.Command, safe+" ") {
+			rerunnable = true
+			break
+		}
+	}
+	if !rerunnable {
+		return false
+	}
+	for _, flag := range entry.Flags {
+		name, _, _ := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		if unsafeRerunFlags[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// RerunArgs returns the command line that runs entry again. Prompts are
+// disabled since nobody may be there to answer them.
+func RerunArgs(entry database.CommandLogEntry) ([]string, error) {
+	if !CanRerun(entry) {
+		return nil, fmt.Errorf("%q cannot be re-run from the history; only commands that do not change data can", entry.Command)
+	}
+	args := strings.Fields(entry.Command)
+	args = append(args, entry.Flags...)
+	noInput := false
+	for _, flag := range entry.Flags {
+		noInput = noInput || flag == "--no-input=true"
+	}
+	if !noInput {
+		args = append(args, "--no-input")
+	}
+	return append(args, strings.Fields(entry.Args)...), nil
+}
+
+// RerunCommand runs the command recorded as id again in a new process,
+// writing its output to stdout and stderr. The new run is recorded too.
+func (a *App) RerunCommand(ctx context.Context, id int, stdout, stderr io.Writer) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	entry, err := database.GetCommandLogEntry(a.DB, id)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("no command with ID %d in the history", id)
+	}
+	args, err := RerunArgs(*entry)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"badgermaps/database"
+	"reflect"
+	"testing"
+)
+
+func TestRerunArgs(t *testing.T) {
+	tests := []struct {
+		entry database.CommandLogEntry
+		want  []string
+	}{
+		{
+			database.CommandLogEntry{Command: "pull account", Args: "42", Flags: []string{"--config=/tmp/c.yaml"}},
+			[]string{"pull", "account", "--config=/tmp/c.yaml", "--no-input", "42"},
+		},
+		{
+			database.CommandLogEntry{Command: "db fsck", Flags: []string{"--fetch=true", "--no-input=true"}},
+			[]string{"db", "fsck", "--fetch=true", "--no-input=true"},
+		},
+		{database.CommandLogEntry{Command: "status"}, []string{"status", "--no-input"}},
+		{database.CommandLogEntry{Command: "push accounts"}, nil},
+		{database.CommandLogEntry{Command: "db migrate"}, nil},
+		{database.CommandLogEntry{Command: "db fsck", Flags: []string{"--delete=true"}}, nil},
+		{database.CommandLogEntry{Command: "pullx"}, nil},
+	}
+	for _, tt := range tests {
+		got, err := RerunArgs(tt.entry)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("RerunArgs(%s) = %v, want an error", CommandLine(tt.entry), got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RerunArgs(%s) = %v, %v; want %v", CommandLine(tt.entry), got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"time"
+)
+
+// RemapAccount replaces the local account oldId with newId after
+// BadgerMaps merged the two: check-ins, route waypoints, and staged changes
+// move to newId, the stale account is removed, and the mapping is recorded
+// in IdRemap. reason is one of the database.RemapReason constants.
+func (a *App) RemapAccount(oldId, newId int, reason string) (database.IdRemap, error) {
+	var remap database.IdRemap
+	err := a.WithoutChangeCapture(func() error {
+		var err error
+		remap, err = database.RemapAccountId(a.DB, oldId, newId, reason, time.Now())
+		return err
+	})
+	if err != nil {
+		return remap, fmt.Errorf("failed to remap account %d to %d: %w", oldId, newId, err)
+	}
+	a.Events.Dispatch(events.Warningf("pull", "Account %d was merged into %d in BadgerMaps (%s); moved %d local row(s) to %d and removed %d",
+		oldId, newId, reason, remap.RowsRewritten, newId, oldId))
+	return remap, nil
+}
+
+// AccountRemaps returns the recorded ID remaps, newest first.
+func (a *App) AccountRemaps() ([]database.IdRemap, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	return database.GetIdRemaps(a.DB)
+}
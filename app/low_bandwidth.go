@@ -0,0 +1,55 @@
+package app
+
+import (
+	"badgermaps/database"
+	"fmt"
+)
+
+// LowBandwidthConcurrency caps concurrent API requests in low-bandwidth
+// mode, so a slow link is not split between many transfers at once.
+const LowBandwidthConcurrency = 2
+
+// LowBandwidth reports whether low-bandwidth mode is on, from
+// --low-bandwidth or low_bandwidth in the config. In it, requests are fewer
+// and compressed, and an account pull fetches the customers list only,
+// leaving the details of new and changed accounts to be fetched when they
+// are opened.
+func (a *App) LowBandwidth() bool {
+	if a.State != nil && a.State.LowBandwidth {
+		return true
+	}
+	return a.Config != nil && a.Config.LowBandwidth
+}
+
+// applyMaxConcurrency sets MaxConcurrentRequests from the config, keeping
+// it between 1 and 10 and within LowBandwidthConcurrency in low-bandwidth
+// mode.
+func (a *App) applyMaxConcurrency() {
+	a.MaxConcurrentRequests = a.Config.MaxConcurrentRequests
+	if a.MaxConcurrentRequests < 1 || a.MaxConcurrentRequests > 10 {
+		a.MaxConcurrentRequests = 5
+	}
+	if a.LowBandwidth() && a.MaxConcurrentRequests > LowBandwidthConcurrency {
+		a.MaxConcurrentRequests = LowBandwidthConcurrency
+	}
+}
+
+// AccountStale reports whether a low-bandwidth pull left the details of an
+// account to be fetched.
+func (a *App) AccountStale(accountID int) (bool, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return false, fmt.Errorf("database is not connected")
+	}
+	return database.IsAccountStale(a.DB, accountID)
+}
+
+// SetLowBandwidth turns low_bandwidth on or off for this run and later
+// ones; the caller saves the config.
+func (a *App) SetLowBandwidth(on bool) {
+	a.Config.LowBandwidth = on
+	if a.API != nil {
+		a.API.SetCompressRequests(a.LowBandwidth())
+	}
+	a.applyMaxConcurrency()
+	a.applyRateLimits()
+}
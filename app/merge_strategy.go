@@ -0,0 +1,198 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/guregu/null/v6"
+)
+
+// Merge strategies of a FieldMaps entry, applied when a pull merges into a
+// stored account.
+const (
+	// MergeOverwrite stores the pulled value. It is the default.
+	MergeOverwrite = "overwrite"
+	// MergePreferLocal keeps the stored value unless it is empty.
+	MergePreferLocal = "prefer-local"
+	// MergeAppend adds the pulled text after the stored text, joined by the
+	// merge separator, unless one already contains the other.
+	MergeAppend = "append"
+	// MergeLongest keeps whichever of the stored and pulled text is longer.
+	MergeLongest = "longest"
+)
+
+// DefaultMergeSeparator joins stored and pulled text when merge_separator
+// is not configured.
+const DefaultMergeSeparator = "\n---\n"
+
+// MergeStrategies lists the strategies in the order the GUI offers them.
+func MergeStrategies() []string {
+	return []string{MergeOverwrite, MergePreferLocal, MergeAppend, MergeLongest}
+}
+
+// ParseMergeStrategy accepts a strategy and its long forms, such as
+// "append-with-separator" or "local".
+func ParseMergeStrategy(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "overwrite", "remote", "":
+		return MergeOverwrite, nil
+	case "prefer-local", "prefer_local", "local":
+		return MergePreferLocal, nil
+	case "append", "append-with-separator", "append_with_separator":
+		return MergeAppend, nil
+	case "longest":
+		return MergeLongest, nil
+	}
+	return "", fmt.Errorf("invalid merge strategy %q (expected overwrite, prefer-local, append, or longest)", value)
+}
+
+// MergeStrategyLabel describes a strategy for display.
+func MergeStrategyLabel(strategy string) string {
+	switch strategy {
+	case MergePreferLocal:
+		return "Prefer local"
+	case MergeAppend:
+		return "Append"
+	case MergeLongest:
+		return "Keep longest"
+	}
+	return "Overwrite"
+}
+
+// MergeSeparator returns the text placed between stored and pulled values
+// of append fields.
+func (a *App) MergeSeparator() string {
+	if a.Config == nil || a.Config.MergeSeparator == "" {
+		return DefaultMergeSeparator
+	}
+	return a.Config.MergeSeparator
+}
+
+// SetAccountFieldMergeStrategy sets how pulls merge into an editable
+// account field. Append and longest only apply to text fields.
+func (a *App) SetAccountFieldMergeStrategy(column, strategy string) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	if _, ok := accountEditableFields[column]; !ok {
+		return fmt.Errorf("column %s is not synced", column)
+	}
+	strategy, err := ParseMergeStrategy(strategy)
+	if err != nil {
+		return err
+	}
+	if (strategy == MergeAppend || strategy == MergeLongest) && !isAccountTextColumn(column) {
+		return fmt.Errorf("%s is not a text field; %s only applies to text", column, strategy)
+	}
+	return database.SetFieldMergeStrategy(a.DB, "Account", column, strategy)
+}
+
+// ApplyAccountMergeStrategies merges the stored value of every field with a
+// merge strategy other than overwrite into acc before a pull stores it.
+// Accounts that are not stored yet take the pulled values.
+func (a *App) ApplyAccountMergeStrategies(acc *models.Account) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil
+	}
+	rules, err := database.GetFieldSyncRules(a.DB, "Account")
+	if err != nil {
+		return fmt.Errorf("failed to read field merge strategies: %w", err)
+	}
+	strategies := make(map[string]string)
+	for _, rule := range rules {
+		if rule.MergeStrategy != MergeOverwrite && rule.Direction != SyncPushOnly {
+			strategies[rule.FieldName] = rule.MergeStrategy
+		}
+	}
+	if len(strategies) == 0 {
+		return nil
+	}
+	stored, err := database.GetAccountByID(a.DB, int(acc.AccountId.Int64))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stored account %d: %w", acc.AccountId.Int64, err)
+	}
+	if err := a.DecryptAccountColumns(stored); err != nil {
+		return err
+	}
+	pulled := reflect.ValueOf(acc).Elem()
+	local := reflect.ValueOf(stored).Elem()
+	separator := a.MergeSeparator()
+	for column, strategy := range strategies {
+		field := pulled.FieldByName(column)
+		localField := local.FieldByName(column)
+		if !field.IsValid() || !field.CanSet() || !localField.IsValid() {
+			continue
+		}
+		if strategy == MergePreferLocal {
+			if !isEmptyValue(localField) {
+				field.Set(localField)
+			}
+			continue
+		}
+		pulledText, ok := field.Interface().(null.String)
+		if !ok {
+			continue
+		}
+		localText := localField.Interface().(null.String)
+		merged := mergeText(strategy, localText.String, pulledText.String, separator)
+		if merged != pulledText.String {
+			field.Set(reflect.ValueOf(null.StringFrom(merged)))
+		}
+	}
+	return nil
+}
+
+// mergeText merges stored and pulled text with the append or longest
+// strategy.
+func mergeText(strategy, local, pulled, separator string) string {
+	l, p := strings.TrimSpace(local), strings.TrimSpace(pulled)
+	switch {
+	case l == "":
+		return pulled
+	case p == "":
+		return local
+	}
+	switch strategy {
+	case MergeAppend:
+		// A pull of a value pushed earlier, or of one extended in
+		// BadgerMaps, must not repeat the text on every pull.
+		if strings.Contains(l, p) {
+			return local
+		}
+		if strings.Contains(p, l) {
+			return pulled
+		}
+		return local + separator + pulled
+	case MergeLongest:
+		if len([]rune(l)) > len([]rune(p)) {
+			return local
+		}
+	}
+	return pulled
+}
+
+// isEmptyValue reports whether a stored account field holds no value. Null
+// and blank text are empty.
+func isEmptyValue(v reflect.Value) bool {
+	if text, ok := v.Interface().(null.String); ok {
+		return strings.TrimSpace(text.String) == ""
+	}
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+// isAccountTextColumn reports whether an Accounts column holds text.
+func isAccountTextColumn(column string) bool {
+	field, ok := reflect.TypeOf(models.Account{}).FieldByName(column)
+	return ok && field.Type == reflect.TypeOf(null.String{})
+}
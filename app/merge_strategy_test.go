@@ -0,0 +1,139 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/api/models"
+	"badgermaps/app/state"
+	"badgermaps/database"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestParseMergeStrategy(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", MergeOverwrite, false},
+		{"Overwrite", MergeOverwrite, false},
+		{"prefer_local", MergePreferLocal, false},
+		{"append-with-separator", MergeAppend, false},
+		{" longest ", MergeLongest, false},
+		{"shortest", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMergeStrategy(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseMergeStrategy(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	tests := []struct {
+		strategy, local, pulled, want string
+	}{
+		{MergeAppend, "gate code 1234", "call before visiting", "gate code 1234 | call before visiting"},
+		{MergeAppend, "", "call before visiting", "call before visiting"},
+		{MergeAppend, "gate code 1234", "", "gate code 1234"},
+		// A value pushed earlier comes back unchanged and is not repeated.
+		{MergeAppend, "gate code 1234 | call first", "call first", "gate code 1234 | call first"},
+		// A value extended in BadgerMaps replaces the shorter local one.
+		{MergeAppend, "gate code", "gate code 1234", "gate code 1234"},
+		{MergeLongest, "a long local note", "short", "a long local note"},
+		{MergeLongest, "short", "a long pulled note", "a long pulled note"},
+		{MergeLongest, "same", "size", "size"},
+	}
+	for _, tt := range tests {
+		if got := mergeText(tt.strategy, tt.local, tt.pulled, " | "); got != tt.want {
+			t.Errorf("mergeText(%s, %q, %q) = %q, want %q", tt.strategy, tt.local, tt.pulled, got, tt.want)
+		}
+	}
+}
+
+func TestApplyAccountMergeStrategies(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "merge.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	a.Config.MergeSeparator = "\n"
+
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, FullName, Notes, Email, CustomText, PhoneNumber) VALUES (7, 'Acme', 'gate code 1234', 'local@example.com', 'a long local value', '')`); err != nil {
+		t.Fatal(err)
+	}
+	for column, strategy := range map[string]string{
+		"Notes":       "append-with-separator",
+		"Email":       MergePreferLocal,
+		"PhoneNumber": MergePreferLocal,
+		"CustomText":  MergeLongest,
+	} {
+		if err := a.SetAccountFieldMergeStrategy(column, strategy); err != nil {
+			t.Fatalf("SetAccountFieldMergeStrategy(%s): %v", column, err)
+		}
+	}
+	if err := a.SetAccountFieldMergeStrategy("CustomNumeric", MergeAppend); err == nil {
+		t.Error("expected append to be refused for a numeric field")
+	}
+	if err := a.SetAccountFieldMergeStrategy("Notes", "sideways"); err == nil {
+		t.Error("expected an invalid strategy to be refused")
+	}
+
+	rules, err := a.AccountSyncRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, rule := range rules {
+		got[rule.FieldName] = rule.MergeStrategy
+	}
+	if got["Notes"] != MergeAppend || got["Email"] != MergePreferLocal || got["FirstName"] != MergeOverwrite {
+		t.Errorf("strategies = Notes:%s Email:%s FirstName:%s", got["Notes"], got["Email"], got["FirstName"])
+	}
+
+	pulled := &models.Account{
+		AccountId:   null.IntFrom(7),
+		Notes:       null.StringFrom("call first"),
+		Email:       null.StringFrom("api@example.com"),
+		PhoneNumber: null.StringFrom("555-0200"),
+		CustomText:  null.StringFrom("short"),
+		FirstName:   null.StringFrom("Ada"),
+	}
+	if err := a.ApplyAccountMergeStrategies(pulled); err != nil {
+		t.Fatal(err)
+	}
+	if pulled.Notes.String != "gate code 1234\ncall first" {
+		t.Errorf("Notes = %q", pulled.Notes.String)
+	}
+	if pulled.Email.String != "local@example.com" || pulled.PhoneNumber.String != "555-0200" {
+		t.Errorf("prefer-local fields Email=%q PhoneNumber=%q", pulled.Email.String, pulled.PhoneNumber.String)
+	}
+	if pulled.CustomText.String != "a long local value" || pulled.FirstName.String != "Ada" {
+		t.Errorf("CustomText=%q FirstName=%q", pulled.CustomText.String, pulled.FirstName.String)
+	}
+
+	// Push-only fields keep the local value and are not merged.
+	if err := a.SetAccountFieldSyncDirection("Notes", SyncPushOnly); err != nil {
+		t.Fatal(err)
+	}
+	again := &models.Account{AccountId: null.IntFrom(7), Notes: null.StringFrom("from the API")}
+	if err := a.ApplyAccountMergeStrategies(again); err != nil || again.Notes.String != "from the API" {
+		t.Errorf("push-only Notes = %q, %v; want it left to KeepPushOnlyAccountFields", again.Notes.String, err)
+	}
+
+	fresh := &models.Account{AccountId: null.IntFrom(8), Email: null.StringFrom("new@example.com")}
+	if err := a.ApplyAccountMergeStrategies(fresh); err != nil || fresh.Email.String != "new@example.com" {
+		t.Errorf("new account Email=%q, err %v", fresh.Email.String, err)
+	}
+}
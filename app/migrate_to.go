@@ -0,0 +1,173 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"strings"
+)
+
+// MigrateTargetTypes are the database types `db migrate-to` copies into.
+var MigrateTargetTypes = []string{"postgres", "mssql"}
+
+// DatabaseMigration is the outcome of MigrateDatabaseTo.
+type DatabaseMigration struct {
+	Target database.DBConfig
+	Tables []database.TableCopy
+	// Switched is set when the config now points at the target.
+	Switched bool
+}
+
+// Verified reports whether every table matched after the copy.
+func (m *DatabaseMigration) Verified() bool {
+	for _, table := range m.Tables {
+		if !table.Verified() {
+			return false
+		}
+	}
+	return true
+}
+
+// Rows is the number of rows copied.
+func (m *DatabaseMigration) Rows() int {
+	rows := 0
+	for _, table := range m.Tables {
+		rows += table.TargetRows
+	}
+	return rows
+}
+
+// OpenMigrationTarget connects to the database described by target and
+// initializes its schema, ready for MigrateDatabaseTo. It also returns how
+// many accounts the target already holds, since the copy replaces them.
+// The caller closes the database when it does not migrate.
+func (a *App) OpenMigrationTarget(target database.DBConfig) (database.DB, int, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, 0, fmt.Errorf("database is not connected")
+	}
+	if !containsFold(MigrateTargetTypes, target.Type) {
+		return nil, 0, fmt.Errorf("--target must be one of %s, got %q", strings.Join(MigrateTargetTypes, ", "), target.Type)
+	}
+	target.Type = strings.ToLower(target.Type)
+	if len(a.Config.DB.EncryptedColumns) > 0 {
+		return nil, 0, fmt.Errorf("the source database has encrypted columns, which only SQLite supports; run 'db decrypt-columns' first")
+	}
+	for _, validate := range []func(database.DBConfig) error{database.ValidateDBSchema, database.ValidateMSSQLAuth} {
+		if err := validate(target); err != nil {
+			return nil, 0, err
+		}
+	}
+	current := a.Config.DB
+	if strings.EqualFold(current.Type, target.Type) && strings.EqualFold(current.Host, target.Host) &&
+		current.Port == target.Port && strings.EqualFold(current.Database, target.Database) && current.Schema == target.Schema {
+		return nil, 0, fmt.Errorf("the target is the database already in use")
+	}
+
+	dst, err := database.NewDB(&target)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := dst.Connect(); err != nil {
+		return nil, 0, fmt.Errorf("failed to connect to the target: %w", err)
+	}
+	if err := dst.TestConnection(); err != nil {
+		dst.Close()
+		return nil, 0, fmt.Errorf("failed to connect to the target: %w", err)
+	}
+	if err := dst.EnforceSchema(a.State); err != nil {
+		dst.Close()
+		return nil, 0, fmt.Errorf("failed to initialize the target schema: %w", err)
+	}
+	accounts, err := database.CountRows(dst, "Accounts")
+	if err != nil {
+		dst.Close()
+		return nil, 0, err
+	}
+	return dst, accounts, nil
+}
+
+// MigrateDatabaseTo copies every table from the configured database into
+// dst, opened with OpenMigrationTarget, and verifies the row counts and
+// checksums of each table. Progress is reported as db.migrate.progress
+// events, one table at a time. When every table matches and switchConfig
+// is set, the config is pointed at target and the app reconnects to it;
+// the source is left as it was either way. dst is closed on return.
+//
+// The sync lock is held throughout, so no pull or push writes to the source
+// while it is copied.
+func (a *App) MigrateDatabaseTo(dst database.DB, target database.DBConfig, switchConfig bool) (*DatabaseMigration, error) {
+	defer dst.Close()
+	release, err := a.AcquireSyncLock("migrate database")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { release() }()
+
+	target.Type = dst.GetType()
+	migration := &DatabaseMigration{Target: target}
+	tables, err := database.CopyDatabase(a.DB, dst, &migrationProgress{app: a})
+	if err != nil {
+		return migration, fmt.Errorf("migration failed; the target was left as it was: %w", err)
+	}
+	migration.Tables, err = database.VerifyCopy(a.DB, dst, tables)
+	if err != nil {
+		return migration, fmt.Errorf("copied, but the copy could not be verified: %w", err)
+	}
+	if !migration.Verified() {
+		return migration, fmt.Errorf("the copy does not match the source; the config was not switched")
+	}
+	if capture, err := database.ChangeCaptureEnabled(a.DB); err == nil && capture {
+		if err := database.EnableChangeCapture(dst); err != nil {
+			a.Events.Dispatch(events.Warningf("db", "Change capture is on in the source but could not be enabled in the target: %v", err))
+		}
+	}
+	a.Events.Dispatch(events.Infof("db", "Copied %d rows in %d tables to %s; row counts and checksums match.", migration.Rows(), len(migration.Tables), target.Type))
+
+	if switchConfig {
+		dst.Close()
+		release()
+		release = func() {}
+		if err := a.switchDatabase(target); err != nil {
+			return migration, err
+		}
+		migration.Switched = true
+	}
+	a.Events.Dispatch(events.Event{Type: "db.migrate.complete", Source: "db", Payload: events.DatabaseMigratePayload{
+		Type: target.Type, Tables: len(migration.Tables), Rows: migration.Rows(), Switched: migration.Switched,
+	}})
+	return migration, nil
+}
+
+// switchDatabase saves target as the database settings, in the active
+// environment when there is one, and reconnects.
+func (a *App) switchDatabase(target database.DBConfig) error {
+	previous := a.Config.DB
+	a.Config.DB = target
+	if err := a.SaveConfig(); err != nil {
+		a.Config.DB = previous
+		return fmt.Errorf("the copy is verified, but the config could not be saved: %w", err)
+	}
+	if err := a.LoadConfig(); err != nil {
+		return fmt.Errorf("the config now uses %s, but reloading it failed: %w", target.Type, err)
+	}
+	a.Events.Dispatch(events.Infof("db", "The config now uses the %s database.", target.Type))
+	return nil
+}
+
+// migrationProgress reports each table of a copy as its own progress.
+type migrationProgress struct {
+	app      *App
+	progress *Progress
+}
+
+func (p *migrationProgress) StartTable(table string, rows int) {
+	p.progress = p.app.StartProgress("db.migrate", table, rows)
+}
+
+func (p *migrationProgress) CopiedRow() {
+	p.progress.Succeeded()
+}
+
+func (p *migrationProgress) FinishTable() {
+	p.progress.Finish()
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+func openMigrateTestDB(t *testing.T, name string) database.DB {
+	t.Helper()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), name)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	return db
+}
+
+func TestMigrateDatabaseTo(t *testing.T) {
+	a := NewApp()
+	a.DB = openMigrateTestDB(t, "source.db")
+	if _, err := a.DB.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (1, 'Acme'), (2, 'Globex')"); err != nil {
+		t.Fatal(err)
+	}
+	dst := openMigrateTestDB(t, "target.db")
+
+	completed := make(chan events.Event, 1)
+	a.Events.Subscribe("db.migrate.complete", func(e events.Event) { completed <- e })
+
+	migration, err := a.MigrateDatabaseTo(dst, database.DBConfig{Type: "sqlite3"}, false)
+	if err != nil {
+		t.Fatalf("MigrateDatabaseTo: %v", err)
+	}
+	if !migration.Verified() || migration.Switched || len(migration.Tables) == 0 {
+		t.Fatalf("migration = %+v, want verified tables without a switch", migration)
+	}
+	if dst.IsConnected() {
+		t.Error("expected the target to be closed")
+	}
+	if lock, err := a.SyncLock(); err != nil || lock != nil {
+		t.Errorf("expected the sync lock released, got %+v (%v)", lock, err)
+	}
+	select {
+	case e := <-completed:
+		payload := e.Payload.(events.DatabaseMigratePayload)
+		if payload.Rows != migration.Rows() || payload.Switched {
+			t.Errorf("payload = %+v, want %d rows without a switch", payload, migration.Rows())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no db.migrate.complete event")
+	}
+}
+
+func TestOpenMigrationTargetRejects(t *testing.T) {
+	a := NewApp()
+	a.DB = openMigrateTestDB(t, "source.db")
+	if _, _, err := a.OpenMigrationTarget(database.DBConfig{Type: "sqlite3"}); err == nil || !strings.Contains(err.Error(), "--target") {
+		t.Errorf("expected SQLite to be refused as a target, got %v", err)
+	}
+	a.Config.DB.EncryptedColumns = []string{"Email"}
+	if _, _, err := a.OpenMigrationTarget(database.DBConfig{Type: "postgres"}); err == nil || !strings.Contains(err.Error(), "decrypt-columns") {
+		t.Errorf("expected encrypted columns to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// erasureKeyFile holds the ed25519 key erasure reports are signed with. It
+// is created in the config directory on first use.
+const erasureKeyFile = "erasure_signing.key"
+
+// ErasureOptions selects what EraseAccount erases and how.
+type ErasureOptions struct {
+	// Subject is an account ID or the email address of one account.
+	Subject string
+	// Mode is database.ErasureDelete or database.ErasureAnonymize.
+	Mode string
+	// StageDelete stages a DELETE of the account so the next push removes
+	// it from BadgerMaps too.
+	StageDelete bool
+	// ReportPath is where the report is written. Empty writes it to the
+	// erasures directory next to the config file.
+	ReportPath string
+}
+
+// ErasureReport is the signed record of an erasure kept for compliance. It
+// names the account only by ID; the subject the erasure was requested for
+// is kept as a hash.
+type ErasureReport struct {
+	ReportId         string           `json:"report_id"`
+	AccountId        int              `json:"account_id"`
+	SubjectSHA256    string           `json:"subject_sha256"`
+	Mode             string           `json:"mode"`
+	Rows             map[string]int64 `json:"rows"`
+	ArchivedCheckins int              `json:"archived_checkins"`
+	DeleteChangeId   int              `json:"delete_change_id,omitempty"`
+	Database         string           `json:"database"`
+	Environment      string           `json:"environment,omitempty"`
+	ErasedAt         time.Time        `json:"erased_at"`
+	PublicKey        string           `json:"public_key"`
+	Signature        string           `json:"signature,omitempty"`
+}
+
+// signedPayload is what the signature covers: the report without it.
+func (r ErasureReport) signedPayload() ([]byte, error) {
+	r.Signature = ""
+	return json.Marshal(r)
+}
+
+// ErasureReportDir is the default directory for erasure reports.
+func ErasureReportDir() string {
+	return utils.GetConfigDirFile("erasures")
+}
+
+// EraseAccount deletes or anonymizes everything stored locally about one
+// account, including its archived check-ins, and writes a signed report.
+// Backups taken before the erasure are not changed. It returns the report
+// and the path it was written to.
+func (a *App) EraseAccount(opts ErasureOptions) (ErasureReport, string, error) {
+	var report ErasureReport
+	if a.DB == nil || !a.DB.IsConnected() {
+		return report, "", fmt.Errorf("database is not connected")
+	}
+	if opts.Mode == "" {
+		opts.Mode = database.ErasureDelete
+	}
+	if opts.Mode != database.ErasureDelete && opts.Mode != database.ErasureAnonymize {
+		return report, "", fmt.Errorf("unknown erasure mode %q (expected %s or %s)", opts.Mode, database.ErasureDelete, database.ErasureAnonymize)
+	}
+	accountID, err := a.resolveErasureSubject(opts.Subject)
+	if err != nil {
+		return report, "", err
+	}
+	key, err := loadErasureKey(true)
+	if err != nil {
+		return report, "", err
+	}
+
+	report = ErasureReport{
+		ReportId:      uuid.NewString(),
+		AccountId:     accountID,
+		SubjectSHA256: subjectHash(opts.Subject),
+		Mode:          opts.Mode,
+		Database:      a.DB.GetType(),
+		ErasedAt:      time.Now().UTC(),
+		PublicKey:     base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+	}
+	if env := a.ActiveEnvironment(); env != nil {
+		report.Environment = env.Name
+	}
+
+	// The archives are rewritten once the erasure commits; the report
+	// records how many check-ins they hold now.
+	if report.ArchivedCheckins, err = database.CountArchivedCheckins(a.CheckinArchiveDir(), accountID); err != nil {
+		return report, "", fmt.Errorf("failed to read archived check-ins: %w", err)
+	}
+	if opts.StageDelete {
+		// The delete is staged first: staging needs the account, and the
+		// erasure leaves this change in place. It is removed again if the
+		// erasure fails.
+		if report.DeleteChangeId, err = a.stageErasureDelete(accountID, report.ReportId); err != nil {
+			return report, "", err
+		}
+	}
+
+	path := opts.ReportPath
+	if path == "" {
+		path = filepath.Join(ErasureReportDir(), report.ReportId+".json")
+	}
+	seal := func(rows map[string]int64) (database.AccountErasure, error) {
+		report.Rows = rows
+		data, err := signErasureReport(&report, key)
+		if err != nil {
+			return database.AccountErasure{}, err
+		}
+		// The report is written before the erasure commits so an erasure
+		// is never left without one.
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return database.AccountErasure{}, fmt.Errorf("failed to create report directory: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return database.AccountErasure{}, fmt.Errorf("failed to write erasure report: %w", err)
+		}
+		digest := sha256.Sum256(data)
+		return database.AccountErasure{
+			AccountId:      accountID,
+			Mode:           opts.Mode,
+			ReportId:       report.ReportId,
+			ReportDigest:   hex.EncodeToString(digest[:]),
+			DeleteChangeId: report.DeleteChangeId,
+			ErasedAt:       report.ErasedAt,
+		}, nil
+	}
+	err = a.WithoutChangeCapture(func() error {
+		_, err := database.EraseAccount(a.DB, accountID, opts.Mode, report.DeleteChangeId, seal)
+		return err
+	})
+	if err != nil {
+		if report.Signature != "" {
+			os.Remove(path)
+		}
+		if report.DeleteChangeId != 0 {
+			if undoErr := database.DeleteAccountPendingChange(a.DB, report.DeleteChangeId); undoErr != nil {
+				a.Events.Dispatch(events.Errorf("privacy", "Failed to remove the staged delete %d of account %d: %v", report.DeleteChangeId, accountID, undoErr))
+			}
+		}
+		return report, "", fmt.Errorf("failed to erase account %d: %w", accountID, err)
+	}
+	if _, err := database.EraseArchivedCheckins(a.CheckinArchiveDir(), accountID, opts.Mode); err != nil {
+		// The database erasure is committed; erasing the ID again finishes
+		// the archives.
+		return report, path, fmt.Errorf("account %d was erased, but its archived check-ins were not: %w; run the erasure again", accountID, err)
+	}
+
+	a.erasedMu.Lock()
+	a.erasedAccounts = nil
+	a.erasedMu.Unlock()
+	a.Events.Dispatch(events.Infof("privacy", "Erased account %d (%s); report %s", accountID, opts.Mode, report.ReportId))
+	return report, path, nil
+}
+
+// resolveErasureSubject returns the account an ID or email refers to. An
+// ID need not be stored any more, so leftovers of a removed account can be
+// erased; an email must match exactly one account.
+func (a *App) resolveErasureSubject(subject string) (int, error) {
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return 0, fmt.Errorf("an account ID or email is required")
+	}
+	if id, err := strconv.Atoi(subject); err == nil {
+		if id <= 0 {
+			return 0, fmt.Errorf("invalid account ID %d", id)
+		}
+		return id, nil
+	}
+	if !strings.Contains(subject, "@") {
+		return 0, fmt.Errorf("%q is neither an account ID nor an email address", subject)
+	}
+	ids, err := database.FindAccountIdsByEmail(a.DB, subject)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up the email: %w", err)
+	}
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("no local account has that email")
+	case 1:
+		return ids[0], nil
+	}
+	return 0, fmt.Errorf("%d local accounts have that email (%v); erase them one at a time by ID", len(ids), ids)
+}
+
+// stageErasureDelete stages a DELETE of accountID keyed by the report ID and
+// returns its change ID.
+func (a *App) stageErasureDelete(accountID int, reportID string) (int, error) {
+	key := "erase-" + reportID
+	if _, err := a.StageChanges([]StageRequest{{Entity: StageEntityAccount, ID: accountID, ChangeType: "DELETE", IdempotencyKey: key}}); err != nil {
+		return 0, fmt.Errorf("failed to stage the API delete: %w", err)
+	}
+	changes, err := database.GetChangesByIdempotencyKey(a.DB, "AccountsPendingChanges", key)
+	if err != nil {
+		return 0, err
+	}
+	if len(changes) == 0 {
+		return 0, fmt.Errorf("the staged delete of account %d was not found", accountID)
+	}
+	return changes[0].ChangeId, nil
+}
+
+// IsAccountErased reports whether accountID was erased. Pulls skip erased
+// accounts and their check-ins so the data is not fetched back.
+func (a *App) IsAccountErased(accountID int) bool {
+	if a.DB == nil || a.DB.GetDB() == nil {
+		return false
+	}
+	a.erasedMu.Lock()
+	defer a.erasedMu.Unlock()
+	if a.erasedAccounts == nil || a.erasedDB != a.DB {
+		erased, err := database.GetErasedAccountIds(a.DB)
+		if err != nil {
+			return false
+		}
+		a.erasedAccounts, a.erasedDB = erased, a.DB
+	}
+	return a.erasedAccounts[accountID]
+}
+
+// VerifyErasureReport checks the signature of the report at path. ownKey
+// is true when it was signed with this installation's key.
+func VerifyErasureReport(path string) (report ErasureReport, ownKey bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, false, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, false, fmt.Errorf("not an erasure report: %w", err)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(report.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return report, false, fmt.Errorf("report has no valid public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(report.Signature)
+	if err != nil {
+		return report, false, fmt.Errorf("report signature is malformed")
+	}
+	payload, err := report.signedPayload()
+	if err != nil {
+		return report, false, err
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return report, false, fmt.Errorf("signature does not match: the report was changed after it was signed")
+	}
+	if key, err := loadErasureKey(false); err == nil && key != nil {
+		ownKey = key.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(publicKey))
+	}
+	return report, ownKey, nil
+}
+
+// signErasureReport signs report in place and returns it as written.
+func signErasureReport(report *ErasureReport, key ed25519.PrivateKey) ([]byte, error) {
+	payload, err := report.signedPayload()
+	if err != nil {
+		return nil, err
+	}
+	report.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func subjectHash(subject string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(subject))))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadErasureKey reads the report signing key, creating it when create is
+// set. Without create a missing key returns nil.
+func loadErasureKey(create bool) (ed25519.PrivateKey, error) {
+	path := utils.GetConfigDirFile(erasureKeyFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s is not a PEM key", path)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		key, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an ed25519 key", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if !create {
+		return nil, nil
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to save the erasure signing key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestEraseAccountReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a := NewApp()
+	a.Config.Archive.Dir = filepath.Join(t.TempDir(), "archive")
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "erase.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, LastName, FullName, Email) VALUES (7, 'Lovelace', 'Ada Lovelace', 'ada@example.com'), (8, 'Twin', 'Twin', 'twin@example.com'), (9, 'Twin', 'Twin', 'twin@example.com')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, Comments) VALUES (70, 7, 'met Ada')`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := a.EraseAccount(ErasureOptions{Subject: "twin@example.com"}); err == nil || !strings.Contains(err.Error(), "2 local accounts") {
+		t.Fatalf("ambiguous email: %v", err)
+	}
+	if a.IsAccountErased(7) {
+		t.Fatal("account 7 reported erased before the erasure")
+	}
+
+	// A failed erasure leaves no staged delete behind.
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.EraseAccount(ErasureOptions{Subject: "8", StageDelete: true, ReportPath: filepath.Join(blocker, "report.json")}); err == nil {
+		t.Fatal("expected the erasure to fail when the report cannot be written")
+	}
+	if changes, err := database.GetPendingAccountChanges(db); err != nil || len(changes) != 0 {
+		t.Fatalf("pending changes after a failed erasure = %+v, %v", changes, err)
+	}
+
+	report, path, err := a.EraseAccount(ErasureOptions{Subject: "ada@example.com", StageDelete: true})
+	if err != nil {
+		t.Fatalf("EraseAccount: %v", err)
+	}
+	if report.AccountId != 7 || report.Mode != database.ErasureDelete || report.DeleteChangeId == 0 || report.Rows["AccountCheckins"] != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ada@example.com") || strings.Contains(string(data), "Lovelace") {
+		t.Errorf("report holds personal data: %s", data)
+	}
+	if !a.IsAccountErased(7) || a.IsAccountErased(8) {
+		t.Error("erased accounts are not tracked")
+	}
+	changes, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(changes) != 1 || changes[0].ChangeType != "DELETE" || changes[0].ChangeId != report.DeleteChangeId {
+		t.Errorf("pending changes = %+v, %v; want the staged delete", changes, err)
+	}
+
+	if _, ownKey, err := VerifyErasureReport(path); err != nil || !ownKey {
+		t.Fatalf("VerifyErasureReport = %v, own key %v", err, ownKey)
+	}
+	var tampered map[string]any
+	if err := json.Unmarshal(data, &tampered); err != nil {
+		t.Fatal(err)
+	}
+	tampered["account_id"] = 8
+	data, _ = json.Marshal(tampered)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyErasureReport(path); err == nil {
+		t.Error("a changed report verified")
+	}
+}
@@ -0,0 +1,30 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// pluginFiles returns the shared objects in dir, sorted so plugins load in a
+// stable order. A missing directory has no plugins.
+func pluginFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".so" {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,35 @@
+//go:build plugins
+
+package processor
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadDir opens every Go plugin (*.so) in dir. Plugins register their
+// processors from init; a plugin may also export a Processors variable of
+// type []processor.Processor, which is registered here. It returns the files
+// that were loaded.
+func LoadDir(dir string) ([]string, error) {
+	files, err := pluginFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var loaded []string
+	for _, file := range files {
+		p, err := plugin.Open(file)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to load plugin %s: %w", file, err)
+		}
+		if sym, err := p.Lookup("Processors"); err == nil {
+			if list, ok := sym.(*[]Processor); ok {
+				for _, proc := range *list {
+					Register(proc)
+				}
+			}
+		}
+		loaded = append(loaded, file)
+	}
+	return loaded, nil
+}
@@ -0,0 +1,19 @@
+//go:build !plugins
+
+package processor
+
+import "fmt"
+
+// LoadDir reports an error when dir contains plugins, because this binary
+// was built without the plugins build tag. Processors compiled into the
+// binary can still call Register directly.
+func LoadDir(dir string) ([]string, error) {
+	files, err := pluginFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		return nil, fmt.Errorf("found %d plugins in %s but this build has no plugin support; rebuild with -tags plugins", len(files), dir)
+	}
+	return nil, nil
+}
@@ -0,0 +1,168 @@
+// Package processor lets customer-specific code transform, enrich, or veto
+// entities as they are pulled from or pushed to BadgerMaps without forking
+// the application. Processors register themselves by name, usually from the
+// init function of a Go plugin loaded from the plugins directory, and the
+// config selects which ones run for each entity type and in what order.
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Stage identifies when a processor is invoked.
+type Stage string
+
+const (
+	// StagePull runs after an entity is fetched and before it is stored.
+	StagePull Stage = "pull"
+	// StagePush runs before a pending change is sent to the API.
+	StagePush Stage = "push"
+)
+
+// Entity types processors can be configured for.
+const (
+	EntityAccount = "account"
+	EntityCheckin = "checkin"
+	EntityRoute   = "route"
+)
+
+// Entity is the generic view of a record handed to processors. Fields holds
+// the JSON representation of the model on pull, or the pending change fields
+// on push; processors transform or enrich it in place.
+type Entity struct {
+	Type   string
+	Stage  Stage
+	ID     int
+	Fields map[string]interface{}
+}
+
+// Processor handles entities of the types it is configured for. Returning a
+// *VetoError skips the entity; any other error fails it.
+type Processor interface {
+	Name() string
+	Process(entity *Entity) error
+}
+
+// Func adapts a function to the Processor interface.
+type Func struct {
+	ID string
+	Fn func(entity *Entity) error
+}
+
+// Name returns the processor name.
+func (f Func) Name() string { return f.ID }
+
+// Process calls the wrapped function.
+func (f Func) Process(entity *Entity) error { return f.Fn(entity) }
+
+// VetoError reports that a processor rejected an entity.
+type VetoError struct {
+	Processor string
+	Reason    string
+}
+
+func (e *VetoError) Error() string {
+	return fmt.Sprintf("vetoed by %s: %s", e.Processor, e.Reason)
+}
+
+// Veto returns an error that tells the caller to skip the entity.
+func Veto(reason string) error {
+	return &VetoError{Reason: reason}
+}
+
+// IsVeto reports whether err is a veto, returning it when it is.
+func IsVeto(err error) (*VetoError, bool) {
+	var veto *VetoError
+	if errors.As(err, &veto) {
+		return veto, true
+	}
+	return nil, false
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Processor{}
+)
+
+// Register makes a processor available by name. Registering the same name
+// twice replaces the earlier processor.
+func Register(p Processor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[p.Name()] = p
+}
+
+// Registered returns the names of all registered processors, sorted.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(name string) (Processor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Chain runs the processors configured for each entity type in order.
+type Chain struct {
+	byType map[string][]Processor
+}
+
+// NewChain resolves the configured processor names per entity type. Unknown
+// names are skipped and reported in the returned error so the caller can
+// warn without disabling the processors that did resolve.
+func NewChain(config map[string][]string) (*Chain, error) {
+	chain := &Chain{byType: make(map[string][]Processor)}
+	var missing []string
+	for entityType, names := range config {
+		for _, name := range names {
+			p, ok := lookup(name)
+			if !ok {
+				missing = append(missing, fmt.Sprintf("%s (%s)", name, entityType))
+				continue
+			}
+			chain.byType[entityType] = append(chain.byType[entityType], p)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return chain, fmt.Errorf("unknown processors: %v", missing)
+	}
+	return chain, nil
+}
+
+// Has reports whether any processor is configured for entityType.
+func (c *Chain) Has(entityType string) bool {
+	return c != nil && len(c.byType[entityType]) > 0
+}
+
+// Run passes entity through each processor configured for its type, stopping
+// at the first veto or error.
+func (c *Chain) Run(entity *Entity) error {
+	if c == nil {
+		return nil
+	}
+	for _, p := range c.byType[entity.Type] {
+		if err := p.Process(entity); err != nil {
+			if veto, ok := IsVeto(err); ok {
+				if veto.Processor == "" {
+					veto.Processor = p.Name()
+				}
+				return veto
+			}
+			return fmt.Errorf("processor %s failed: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChainRunsConfiguredProcessorsInOrder(t *testing.T) {
+	Register(Func{ID: "test.upper", Fn: func(e *Entity) error {
+		e.Fields["name"] = e.Fields["name"].(string) + "!"
+		return nil
+	}})
+	Register(Func{ID: "test.tag", Fn: func(e *Entity) error {
+		e.Fields["tag"] = string(e.Stage) + ":" + e.Fields["name"].(string)
+		return nil
+	}})
+	Register(Func{ID: "test.veto", Fn: func(e *Entity) error {
+		if e.ID == 13 {
+			return Veto("unlucky")
+		}
+		return nil
+	}})
+	Register(Func{ID: "test.fail", Fn: func(e *Entity) error { return errors.New("boom") }})
+
+	chain, err := NewChain(map[string][]string{
+		EntityAccount: {"test.upper", "test.tag", "test.veto"},
+		EntityRoute:   {"test.fail", "test.missing"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for the unknown processor")
+	}
+	if !chain.Has(EntityAccount) || chain.Has(EntityCheckin) {
+		t.Fatal("chain should only have processors for configured entity types")
+	}
+
+	entity := &Entity{Type: EntityAccount, Stage: StagePull, ID: 1, Fields: map[string]interface{}{"name": "Acme"}}
+	if err := chain.Run(entity); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if entity.Fields["tag"] != "pull:Acme!" {
+		t.Fatalf("processors ran out of order: %v", entity.Fields)
+	}
+
+	entity = &Entity{Type: EntityAccount, Stage: StagePush, ID: 13, Fields: map[string]interface{}{"name": "Acme"}}
+	veto, ok := IsVeto(chain.Run(entity))
+	if !ok || veto.Processor != "test.veto" || veto.Reason != "unlucky" {
+		t.Fatalf("expected veto from test.veto, got %+v", veto)
+	}
+
+	err = chain.Run(&Entity{Type: EntityRoute, Fields: map[string]interface{}{}})
+	if _, isVeto := IsVeto(err); err == nil || isVeto {
+		t.Fatalf("expected processor failure, got %v", err)
+	}
+}
+
+func TestLoadDirWithoutPlugins(t *testing.T) {
+	dir := t.TempDir()
+	if files, err := LoadDir(dir); err != nil || len(files) != 0 {
+		t.Fatalf("empty dir: files=%v err=%v", files, err)
+	}
+	if files, err := LoadDir(filepath.Join(dir, "missing")); err != nil || len(files) != 0 {
+		t.Fatalf("missing dir: files=%v err=%v", files, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "readme.txt"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := pluginFiles(dir); err != nil || len(files) != 0 {
+		t.Fatalf("non-plugin files should be ignored: files=%v err=%v", files, err)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"badgermaps/app/processor"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// PluginConfig selects the entity processors that run during pull and push.
+// Processors maps an entity type (account, checkin, route) to processor
+// names, which run in order.
+type PluginConfig struct {
+	Dir        string              `yaml:"dir,omitempty"`
+	Processors map[string][]string `yaml:"processors,omitempty"`
+}
+
+// PluginDir returns the configured plugins directory, defaulting to
+// "plugins" in the config directory.
+func (c PluginConfig) PluginDir() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return utils.GetConfigDirFile("plugins")
+}
+
+// loadProcessors loads plugins and builds the processor chain from config.
+// Problems are reported as warnings so a broken plugin does not stop the app
+// from starting; processors that did resolve still run.
+func (a *App) loadProcessors() {
+	a.Processors = nil
+	if len(a.Config.Plugins.Processors) == 0 {
+		return
+	}
+	dir := a.Config.Plugins.PluginDir()
+	loaded, err := processor.LoadDir(dir)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("plugins", "%v", err))
+	}
+	for _, file := range loaded {
+		a.Events.Dispatch(events.Debugf("plugins", "Loaded plugin %s", file))
+	}
+	chain, err := processor.NewChain(a.Config.Plugins.Processors)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("plugins", "%v; registered processors: %v", err, processor.Registered()))
+	}
+	a.Processors = chain
+}
+
+// ProcessFields runs the processors configured for entityType over fields,
+// which they may modify in place. A vetoed entity returns a
+// *processor.VetoError.
+func (a *App) ProcessFields(stage processor.Stage, entityType string, id int, fields map[string]interface{}) error {
+	if !a.Processors.Has(entityType) {
+		return nil
+	}
+	return a.Processors.Run(&processor.Entity{Type: entityType, Stage: stage, ID: id, Fields: fields})
+}
+
+// ProcessEntity runs the configured processors over model, a pointer to an
+// API model, by round-tripping it through its JSON representation.
+func (a *App) ProcessEntity(stage processor.Stage, entityType string, id int, model interface{}) error {
+	if !a.Processors.Has(entityType) {
+		return nil
+	}
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s %d for processors: %w", entityType, id, err)
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to decode %s %d for processors: %w", entityType, id, err)
+	}
+	if err := a.ProcessFields(stage, entityType, id, fields); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return fmt.Errorf("failed to encode processed %s %d: %w", entityType, id, err)
+	}
+	// Reset the model first so fields a processor removed are cleared.
+	target := reflect.ValueOf(model).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if err := json.Unmarshal(data, model); err != nil {
+		return fmt.Errorf("processed %s %d no longer matches its model: %w", entityType, id, err)
+	}
+	return nil
+}
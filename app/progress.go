@@ -0,0 +1,123 @@
+package app
+
+import (
+	"badgermaps/events"
+	"sync"
+	"time"
+)
+
+// ProgressInterval is the minimum time between two progress events for the
+// same operation, so large pulls do not flood listeners.
+var ProgressInterval = 250 * time.Millisecond
+
+// Progress counts the items of one pull or push group and reports them as
+// throttled <operation>.progress events. It is safe for concurrent use.
+type Progress struct {
+	app       *App
+	eventType events.EventType
+	entity    string
+	started   time.Time
+
+	mu        sync.Mutex
+	total     int
+	processed int
+	failed    int
+	lastEmit  time.Time
+	done      bool
+}
+
+// StartProgress begins tracking operation ("pull" or "push") for entity and
+// sends the first event. A total of 0 means it is not known yet.
+func (a *App) StartProgress(operation, entity string, total int) *Progress {
+	p := &Progress{
+		app:       a,
+		eventType: events.EventType(operation + ".progress"),
+		entity:    entity,
+		started:   time.Now(),
+		total:     total,
+	}
+	p.mu.Lock()
+	p.emitLocked(true)
+	p.mu.Unlock()
+	return p
+}
+
+// SetTotal records the number of items once it is known.
+func (p *Progress) SetTotal(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+	p.emitLocked(true)
+}
+
+// Succeeded counts one item handled without error.
+func (p *Progress) Succeeded() {
+	p.record(false)
+}
+
+// Skipped counts one item that needed no work, such as a record without an
+// ID.
+func (p *Progress) Skipped() {
+	p.record(false)
+}
+
+// Failed counts one item that could not be handled.
+func (p *Progress) Failed() {
+	p.record(true)
+}
+
+func (p *Progress) record(failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed++
+	if failed {
+		p.failed++
+	}
+	p.emitLocked(false)
+}
+
+// Finish sends the final event. Later calls do nothing.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+	p.emitLocked(true)
+}
+
+// Snapshot returns the current counters.
+func (p *Progress) Snapshot() events.ProgressPayload {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.payloadLocked()
+}
+
+func (p *Progress) payloadLocked() events.ProgressPayload {
+	payload := events.ProgressPayload{
+		Entity:    p.entity,
+		Processed: p.processed,
+		Total:     p.total,
+		Failed:    p.failed,
+		Done:      p.done,
+	}
+	if elapsed := time.Since(p.started).Seconds(); elapsed > 0 {
+		payload.Rate = float64(p.processed) / elapsed
+	}
+	return payload
+}
+
+// emitLocked dispatches while p.mu is held so listeners see events in the
+// order the counters changed.
+func (p *Progress) emitLocked(force bool) {
+	now := time.Now()
+	if !force && (p.done || now.Sub(p.lastEmit) < ProgressInterval) {
+		return
+	}
+	p.lastEmit = now
+	if p.app == nil || p.app.Events == nil {
+		return
+	}
+	p.app.Events.Dispatch(events.Event{Type: p.eventType, Source: p.entity, Payload: p.payloadLocked()})
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"badgermaps/events"
+)
+
+func TestProgressThrottlesAndFinishes(t *testing.T) {
+	saved := ProgressInterval
+	ProgressInterval = time.Hour
+	defer func() { ProgressInterval = saved }()
+
+	a := NewApp()
+	var mu sync.Mutex
+	var seen []events.ProgressPayload
+	a.Events.Subscribe("pull.progress", func(e events.Event) {
+		mu.Lock()
+		seen = append(seen, e.Payload.(events.ProgressPayload))
+		mu.Unlock()
+	})
+
+	progress := a.StartProgress("pull", "accounts", 0)
+	progress.SetTotal(100)
+	for i := 0; i < 100; i++ {
+		if i%10 == 0 {
+			progress.Failed()
+		} else {
+			progress.Succeeded()
+		}
+	}
+	progress.Finish()
+	progress.Finish()
+	if !a.Events.WaitForDrain(time.Second) {
+		t.Fatal("events were not delivered")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 3 {
+		t.Fatalf("got %d events, want start, total and final: %+v", len(seen), seen)
+	}
+	if seen[1].Total != 100 || seen[1].Processed != 0 {
+		t.Fatalf("total event = %+v", seen[1])
+	}
+	last := seen[2]
+	if !last.Done || last.Entity != "accounts" || last.Processed != 100 || last.Failed != 10 || last.Fraction() != 1 {
+		t.Fatalf("final event = %+v", last)
+	}
+}
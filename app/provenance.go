@@ -0,0 +1,159 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/database/repository"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sources of an account field value.
+const (
+	// ProvenancePulled is a value as BadgerMaps returned it on the last pull.
+	ProvenancePulled = "pulled"
+	// ProvenanceLocal is a value edited locally that has not been pushed.
+	ProvenanceLocal = "local"
+)
+
+// FieldProvenance tells where the value of one editable account field comes
+// from.
+type FieldProvenance struct {
+	Column string
+	Label  string
+	// Pulled is the value stored by the last pull.
+	Pulled string
+	Source string
+	// Staged is the value awaiting push when Source is ProvenanceLocal. It
+	// comes from the newest unpushed change that sets the field.
+	Staged   string
+	ChangeID int
+	// Status is the status of that change: pending, processing, or failed.
+	Status string
+}
+
+// Value returns the value the field will have once unpushed edits reach
+// BadgerMaps.
+func (f FieldProvenance) Value() string {
+	if f.Source == ProvenanceLocal {
+		return f.Staged
+	}
+	return f.Pulled
+}
+
+// AccountProvenance is an account's editable fields with the source of each.
+type AccountProvenance struct {
+	AccountID int
+	Name      string
+	// PulledAt is when the account was last stored by a pull.
+	PulledAt string
+	// PendingDelete is set when a delete of the account has not been pushed.
+	PendingDelete bool
+	Fields        []FieldProvenance
+}
+
+// LocalCount returns how many fields have edits that are not in BadgerMaps.
+func (p *AccountProvenance) LocalCount() int {
+	count := 0
+	for _, field := range p.Fields {
+		if field.Source == ProvenanceLocal {
+			count++
+		}
+	}
+	return count
+}
+
+// accountFieldOrder lists the standard editable columns in the order the
+// detail editor shows them; custom fields follow by number.
+var accountFieldOrder = []string{"FirstName", "LastName", "PhoneNumber", "Email", "CustomerId", "AccountOwner", "CrmId", "FollowUpDate", "Notes"}
+
+// AccountFieldProvenance reports, for every editable field of an account,
+// whether its value is the one last pulled from BadgerMaps or a local edit
+// still waiting to be pushed. Edits count until their change completes, so
+// failed pushes stay marked as local.
+func (a *App) AccountFieldProvenance(accountID int) (*AccountProvenance, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	account, err := repository.New(a.DB).GetAccountWithLabels(accountID)
+	if err != nil {
+		return nil, err
+	}
+	a.DecryptAccountForDisplay(&account.Account)
+	changes, err := database.GetUnpushedAccountChanges(a.DB, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read unpushed changes: %w", err)
+	}
+
+	result := &AccountProvenance{
+		AccountID: accountID,
+		Name:      a.AccountDisplayName(account),
+		PulledAt:  account.UpdatedAt.ValueOrZero(),
+	}
+	columns := sortedAccountColumns()
+	result.Fields = make([]FieldProvenance, len(columns))
+	fields := make(map[string]*FieldProvenance, len(columns))
+	for i, column := range columns {
+		pulled, _ := account.Value(column)
+		result.Fields[i] = FieldProvenance{
+			Column: column,
+			Label:  account.Label(column),
+			Pulled: pulled,
+			Source: ProvenancePulled,
+		}
+		fields[column] = &result.Fields[i]
+	}
+	// Later changes win, as they do when pushed in order.
+	for _, change := range changes {
+		if change.ChangeType == "DELETE" {
+			result.PendingDelete = true
+			continue
+		}
+		staged, err := parseAccountChanges(change.Changes)
+		if err != nil {
+			continue
+		}
+		for apiField, value := range staged {
+			field := fields[accountColumnsByField[apiField]]
+			if field == nil {
+				continue
+			}
+			field.Source = ProvenanceLocal
+			field.Staged = value
+			field.ChangeID = change.ChangeId
+			field.Status = change.Status
+		}
+	}
+	return result, nil
+}
+
+// sortedAccountColumns returns the editable Accounts columns in display
+// order.
+func sortedAccountColumns() []string {
+	columns := append([]string{}, accountFieldOrder...)
+	var custom []string
+	for column := range accountEditableFields {
+		if strings.HasPrefix(column, "Custom") {
+			custom = append(custom, column)
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool {
+		ki, ni := splitCustomColumn(custom[i])
+		kj, nj := splitCustomColumn(custom[j])
+		if ki != kj {
+			return ki > kj // CustomText before CustomNumeric
+		}
+		return ni < nj
+	})
+	return append(columns, custom...)
+}
+
+func splitCustomColumn(column string) (kind string, n int) {
+	kind = strings.TrimRight(column, "0123456789")
+	n = 1
+	if digits := column[len(kind):]; digits != "" {
+		n, _ = strconv.Atoi(digits)
+	}
+	return kind, n
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestAccountFieldProvenance(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "provenance.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, FullName, PhoneNumber, Email, Notes) VALUES (42, 'Acme', '555-0100', 'old@example.com', 'pulled note')`); err != nil {
+		t.Fatal(err)
+	}
+	for _, changes := range []string{`{"phone_number":"555-0199","email":"first@example.com"}`, `{"email":"second@example.com"}`, `{"notes":"pushed"}`} {
+		if err := database.StageAccountChange(db, 42, "UPDATE", changes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The last change was pushed, so its value is what was pulled back.
+	if _, err := db.GetDB().Exec(`UPDATE AccountsPendingChanges SET Status = 'completed' WHERE Changes LIKE '%pushed%'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetDB().Exec(`UPDATE AccountsPendingChanges SET Status = 'failed' WHERE Changes LIKE '%second%'`); err != nil {
+		t.Fatal(err)
+	}
+
+	provenance, err := a.AccountFieldProvenance(42)
+	if err != nil {
+		t.Fatalf("AccountFieldProvenance: %v", err)
+	}
+	fields := map[string]FieldProvenance{}
+	for _, f := range provenance.Fields {
+		fields[f.Column] = f
+	}
+	if f := fields["PhoneNumber"]; f.Source != ProvenanceLocal || f.Pulled != "555-0100" || f.Value() != "555-0199" || f.Status != "pending" {
+		t.Errorf("PhoneNumber = %+v", f)
+	}
+	if f := fields["Email"]; f.Source != ProvenanceLocal || f.Staged != "second@example.com" || f.Status != "failed" {
+		t.Errorf("Email = %+v, want the newer failed edit", f)
+	}
+	if f := fields["Notes"]; f.Source != ProvenancePulled || f.Value() != "pulled note" {
+		t.Errorf("Notes = %+v", f)
+	}
+	if provenance.LocalCount() != 2 || provenance.PendingDelete {
+		t.Errorf("LocalCount = %d, PendingDelete = %v", provenance.LocalCount(), provenance.PendingDelete)
+	}
+	if provenance.Fields[0].Column != "FirstName" || fields["CustomText30"].Column == "" {
+		t.Errorf("unexpected field order or set: first %q", provenance.Fields[0].Column)
+	}
+}
@@ -0,0 +1,162 @@
+package pull
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/telemetry"
+	"context"
+	"fmt"
+)
+
+// BundleOptions picks the entities related to an account that
+// PullAccountBundle fetches along with it.
+type BundleOptions struct {
+	Checkins bool
+	Routes   bool
+}
+
+// AccountBundle is what PullAccountBundle stored.
+type AccountBundle struct {
+	Account  *models.Account
+	Checkins int
+	// Routes are the IDs of the routes with a waypoint at the account.
+	Routes []int
+}
+
+// String summarizes the bundle for terminal or toast output.
+func (b *AccountBundle) String() string {
+	text := fmt.Sprintf("account %d", b.Account.AccountId.Int64)
+	if b.Checkins > 0 {
+		text += fmt.Sprintf(", %d check-in(s)", b.Checkins)
+	}
+	if len(b.Routes) > 0 {
+		text += fmt.Sprintf(", %d route(s)", len(b.Routes))
+	}
+	return text
+}
+
+// PullAccountBundle fetches an account and, as opts asks, its check-ins and
+// the routes that visit it, then stores them all in one transaction, so the
+// database never holds the account from one moment and its check-ins from
+// another. Nothing is stored when a fetch fails. Routes are found by their
+// waypoints, which takes the route list and the details of each route the
+// list does not give waypoints for.
+func PullAccountBundle(a *app.App, accountID int, opts BundleOptions) (bundle *AccountBundle, err error) {
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
+	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "account", Payload: events.PullStartPayload{ResourceID: accountID}})
+	a.Events.Dispatch(events.Infof("pull", "Pulling account %d with its related records", accountID))
+	ctx, span := telemetry.Start(context.Background(), "pull.account_bundle", telemetry.EntityID.Int(accountID))
+
+	defer func() {
+		telemetry.End(span, err)
+		payload := events.CompletionPayload{Success: err == nil, ResourceID: accountID}
+		if err == nil {
+			payload.Count = 1 + bundle.Checkins + len(bundle.Routes)
+		} else {
+			payload.Error = err
+			a.Events.Dispatch(events.Event{Type: "pull.error", Source: "account", Payload: events.ErrorPayload{Error: err, ResourceID: accountID}})
+		}
+		a.Events.Dispatch(events.Event{Type: "pull.complete", Source: "account", Payload: payload})
+	}()
+
+	account, err := fetchAccountFollowingMerges(ctx, a, accountID)
+	if err != nil {
+		return nil, err
+	}
+	id := int(account.AccountId.Int64)
+	var checkins []models.Checkin
+	if opts.Checkins {
+		resp, err := fetchAccountCheckins(ctx, a, id)
+		if err != nil {
+			return nil, fmt.Errorf("error getting check-ins for account %d: %w", id, err)
+		}
+		checkins = resp.Data
+	}
+	var routes []models.Route
+	if opts.Routes {
+		if routes, err = fetchAccountRoutes(ctx, a, id); err != nil {
+			return nil, err
+		}
+	}
+
+	pulled, err := a.PulledCustomColumns()
+	if err != nil {
+		return nil, fmt.Errorf("error reading pulled custom fields: %w", err)
+	}
+	commands, err := accountCommands(a, account, pulled)
+	if err != nil {
+		return nil, fmt.Errorf("error storing account: %w", err)
+	}
+	bundle = &AccountBundle{Account: account}
+	for _, checkin := range checkins {
+		record, ok, err := checkinRecord(a, checkin)
+		if err != nil {
+			return nil, fmt.Errorf("error storing check-in %d: %w", checkin.CheckinId.Int64, err)
+		}
+		if ok {
+			commands = append(commands, database.MergeCheckinCommand(record))
+			bundle.Checkins++
+		}
+	}
+	for _, route := range routes {
+		routeCmds, err := routeCommands(a, route)
+		if err != nil {
+			return nil, fmt.Errorf("error storing route %d: %w", route.RouteId.Int64, err)
+		}
+		if len(routeCmds) > 0 {
+			commands = append(commands, routeCmds...)
+			bundle.Routes = append(bundle.Routes, int(route.RouteId.Int64))
+		}
+	}
+	if len(commands) > 0 {
+		_, dbSpan := telemetry.Start(ctx, "db.StoreAccountBundle", telemetry.EntityID.Int(id))
+		err = a.WithoutChangeCapture(func() error {
+			return database.WorkCommands(a.DB, "store.account_bundle", commands)
+		})
+		telemetry.End(dbSpan, err)
+		if err != nil {
+			return nil, fmt.Errorf("error storing account %d and its related records: %w", id, err)
+		}
+	}
+	if err := followRedirect(a, accountID, account); err != nil {
+		return nil, fmt.Errorf("error remapping merged account: %w", err)
+	}
+	enrichAccounts(a, []int{id})
+
+	a.Events.Dispatch(events.Infof("pull", "Successfully pulled %s", bundle))
+	return bundle, nil
+}
+
+// fetchAccountRoutes returns the routes with a waypoint at the account.
+func fetchAccountRoutes(ctx context.Context, a *app.App, accountID int) ([]models.Route, error) {
+	_, listSpan := telemetry.Start(ctx, "api.GetRoutes")
+	list, err := a.API.GetRoutes()
+	telemetry.End(listSpan, err)
+	if err != nil {
+		return nil, fmt.Errorf("error getting routes: %w", err)
+	}
+	var routes []models.Route
+	for _, route := range list.Data {
+		if len(route.Waypoints) == 0 {
+			routeID := int(route.RouteId.Int64)
+			_, apiSpan := telemetry.Start(ctx, "api.GetRoute", telemetry.EntityID.Int(routeID))
+			resp, err := a.API.GetRoute(routeID)
+			telemetry.End(apiSpan, err)
+			if err != nil {
+				return nil, fmt.Errorf("error getting route %d: %w", routeID, err)
+			}
+			route = resp.Data
+		}
+		for _, waypoint := range route.Waypoints {
+			if waypoint.CustomerID.Valid && int(waypoint.CustomerID.Int64) == accountID {
+				routes = append(routes, route)
+				break
+			}
+		}
+	}
+	return routes, nil
+}
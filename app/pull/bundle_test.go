@@ -0,0 +1,52 @@
+package pull_test
+
+import (
+	"badgermaps/app/pull"
+	"net/http"
+	"testing"
+)
+
+func TestPullAccountBundle(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/customers/7/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 7, "full_name": "Acme", "last_name": "Acme"}`))
+	})
+	mux.HandleFunc("/appointments/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("customer_id") != "7" {
+			t.Errorf("check-ins requested for customer %q", r.URL.Query().Get("customer_id"))
+		}
+		w.Write([]byte(`[{"id": 701, "customer": 7, "log_datetime": "2024-05-01T10:00:00Z", "type": "Drop-in"},
+			{"id": 702, "customer": 7, "log_datetime": "2024-05-02T10:00:00Z", "type": "Drop-in"}]`))
+	})
+	mux.HandleFunc("/routes/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "name": "Visits Acme", "waypoints": [{"id": 11, "customer_id": 7}]},
+			{"id": 2, "name": "Listed without waypoints"},
+			{"id": 3, "name": "Elsewhere", "waypoints": [{"id": 31, "customer_id": 9}]}]`))
+	})
+	mux.HandleFunc("/routes/2/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 2, "name": "Listed without waypoints", "waypoints": [{"id": 21, "customer_id": 7}]}`))
+	})
+	testApp, teardown := setupTestApp(t, mux)
+	defer teardown()
+
+	bundle, err := pull.PullAccountBundle(testApp, 7, pull.BundleOptions{Checkins: true, Routes: true})
+	if err != nil {
+		t.Fatalf("PullAccountBundle returned error: %v", err)
+	}
+	if bundle.Checkins != 2 || len(bundle.Routes) != 2 || bundle.Routes[0] != 1 || bundle.Routes[1] != 2 {
+		t.Fatalf("bundle = %s with routes %v, want 2 check-ins and routes [1 2]", bundle, bundle.Routes)
+	}
+
+	db := testApp.DB.GetDB()
+	for query, want := range map[string]int{
+		`SELECT COUNT(*) FROM Accounts WHERE AccountId = 7`:        1,
+		`SELECT COUNT(*) FROM AccountCheckins WHERE AccountId = 7`: 2,
+		`SELECT COUNT(*) FROM Routes`:                              2,
+		`SELECT COUNT(*) FROM RouteWaypoints WHERE CustomerId = 7`: 2,
+	} {
+		var got int
+		if err := db.QueryRow(query).Scan(&got); err != nil || got != want {
+			t.Errorf("%s = %d, %v; want %d", query, got, err, want)
+		}
+	}
+}
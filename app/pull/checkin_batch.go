@@ -0,0 +1,86 @@
+package pull
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/telemetry"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// checkinBatch collects pulled check-ins from concurrent fetches and writes
+// them batch_size rows at a time with database.BulkMergeCheckins. It is safe
+// for concurrent use.
+type checkinBatch struct {
+	app  *app.App
+	ctx  context.Context
+	size int
+
+	mu       sync.Mutex
+	records  []database.CheckinRecord
+	checkins []models.Checkin
+}
+
+// newCheckinBatch returns a batch whose writes are traced under ctx.
+func newCheckinBatch(ctx context.Context, a *app.App) *checkinBatch {
+	return &checkinBatch{app: a, ctx: ctx, size: a.BatchSize()}
+}
+
+// Add queues checkins and writes a batch once enough rows are queued. The
+// pull processors run here, outside the lock.
+func (b *checkinBatch) Add(checkins []models.Checkin) error {
+	records := make([]database.CheckinRecord, 0, len(checkins))
+	kept := make([]models.Checkin, 0, len(checkins))
+	for _, checkin := range checkins {
+		record, ok, err := checkinRecord(b.app, checkin)
+		if err != nil {
+			return fmt.Errorf("error storing checkin %d: %w", checkin.CheckinId.Int64, err)
+		}
+		if ok {
+			records = append(records, record)
+			kept = append(kept, checkin)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records = append(b.records, records...)
+	b.checkins = append(b.checkins, kept...)
+	for len(b.records) >= b.size {
+		if err := b.writeLocked(b.size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes whatever is still queued.
+func (b *checkinBatch) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.records) == 0 {
+		return nil
+	}
+	return b.writeLocked(len(b.records))
+}
+
+// writeLocked writes the first n queued rows. They are dropped from the
+// queue whether or not the write succeeds, so a failed batch is reported
+// once.
+func (b *checkinBatch) writeLocked(n int) error {
+	records, checkins := b.records[:n], b.checkins[:n]
+	b.records, b.checkins = b.records[n:], b.checkins[n:]
+	_, span := telemetry.Start(b.ctx, "db.BulkMergeCheckins", telemetry.Count.Int(n))
+	err := database.BulkMergeCheckins(b.app.DB, records)
+	telemetry.End(span, err)
+	if err != nil {
+		return fmt.Errorf("error storing %d check-ins: %w", n, err)
+	}
+	for _, checkin := range checkins {
+		b.app.Events.Dispatch(events.Event{Type: "pull.store.success", Source: "checkins", Payload: events.StoreSuccessPayload{Data: checkin}})
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package pull
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultSecondsPerItem is used when no completed pulls exist in SyncHistory.
+const defaultSecondsPerItem = 0.5
+
+// EntityImpact summarizes how a full pull would affect one resource type.
+type EntityImpact struct {
+	Remote    int
+	New       int
+	Updated   int
+	Unchanged int
+}
+
+// PullImpact is a dry-run estimate of a full pull.
+type PullImpact struct {
+	Accounts EntityImpact
+	Routes   EntityImpact
+	// CheckinAccounts is the number of accounts whose check-ins would be fetched.
+	CheckinAccounts int
+	// APICalls counts every request the full pull would make.
+	APICalls          int
+	EstimatedDuration time.Duration
+	// FromHistory reports whether the estimate uses recorded throughput.
+	FromHistory bool
+}
+
+// Items returns the number of records the pull would store or re-check.
+func (p *PullImpact) Items() int {
+	return p.Accounts.Remote + p.CheckinAccounts + p.Routes.Remote + 1
+}
+
+// String renders the preview for terminal or dialog output.
+func (p *PullImpact) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Accounts: %d new, %d updated, %d unchanged (%d total)\n", p.Accounts.New, p.Accounts.Updated, p.Accounts.Unchanged, p.Accounts.Remote)
+	fmt.Fprintf(&b, "Routes:   %d new, %d existing (%d total)\n", p.Routes.New, p.Routes.Unchanged, p.Routes.Remote)
+	fmt.Fprintf(&b, "Check-ins will be fetched for %d accounts\n", p.CheckinAccounts)
+	fmt.Fprintf(&b, "Required API calls: %d\n", p.APICalls)
+	basis := "default rate"
+	if p.FromHistory {
+		basis = "historical throughput"
+	}
+	fmt.Fprintf(&b, "Estimated duration: %s (%s)", p.EstimatedDuration.Round(time.Second), basis)
+	return b.String()
+}
+
+// EstimatePullImpact compares the remote ID/modified lists with the local
+// database without downloading full records. top limits the accounts
+// considered, mirroring PullGroupAccounts.
+func EstimatePullImpact(a *app.App, top int) (*PullImpact, error) {
+	if a.API == nil {
+		return nil, fmt.Errorf("api client is not initialized")
+	}
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+
+	remoteAccounts, err := a.API.GetAccountModifiedDates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account list: %w", err)
+	}
+	localAccounts, err := database.GetAccountModifiedDates(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local accounts: %w", err)
+	}
+
+	accountIDs := make([]int, 0, len(remoteAccounts.Data))
+	for id := range remoteAccounts.Data {
+		accountIDs = append(accountIDs, id)
+	}
+	if _, limited, _ := a.PullRadius(); limited {
+		territory, err := pullAccountIDs(a, "accounts")
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch account list: %w", err)
+		}
+		accountIDs = territory
+	}
+	sort.Ints(accountIDs)
+	if top > 0 && top < len(accountIDs) {
+		accountIDs = accountIDs[:top]
+	}
+
+	impact := &PullImpact{}
+	impact.Accounts.Remote = len(accountIDs)
+	for _, id := range accountIDs {
+		localModified, exists := localAccounts[id]
+		switch {
+		case !exists:
+			impact.Accounts.New++
+		case remoteAccounts.Data[id] == "" || remoteAccounts.Data[id] != localModified:
+			impact.Accounts.Updated++
+		default:
+			impact.Accounts.Unchanged++
+		}
+	}
+	impact.CheckinAccounts = len(accountIDs)
+
+	remoteRoutes, err := a.API.GetRoutes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch route list: %w", err)
+	}
+	localRouteIDs, err := database.GetAllRouteIDs(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local routes: %w", err)
+	}
+	known := make(map[int]bool, len(localRouteIDs))
+	for _, id := range localRouteIDs {
+		known[id] = true
+	}
+	impact.Routes.Remote = len(remoteRoutes.Data)
+	for _, route := range remoteRoutes.Data {
+		if known[int(route.RouteId.Int64)] {
+			impact.Routes.Unchanged++
+		} else {
+			impact.Routes.New++
+		}
+	}
+
+	// accounts: list + detail per account; check-ins: list + one per account;
+	// routes: list + detail per route; profile: one call.
+	impact.APICalls = (1 + len(accountIDs)) + (1 + len(accountIDs)) + (1 + impact.Routes.Remote) + 1
+
+	secondsPerItem := defaultSecondsPerItem
+	if items, seconds, err := database.GetPullThroughput(a.DB); err == nil && items > 0 && seconds > 0 {
+		secondsPerItem = float64(seconds) / float64(items)
+		impact.FromHistory = true
+	}
+	workers := a.MaxConcurrentRequests
+	if workers < 1 {
+		workers = 1
+	}
+	estimate := float64(impact.Items()) * secondsPerItem
+	if !impact.FromHistory {
+		// Recorded throughput already reflects concurrency; the default does not.
+		estimate /= float64(workers)
+	}
+	impact.EstimatedDuration = time.Duration(estimate * float64(time.Second))
+
+	return impact, nil
+}
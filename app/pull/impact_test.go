@@ -0,0 +1,83 @@
+package pull_test
+
+import (
+	"badgermaps/app/pull"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEstimatePullImpact(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/customers/":
+			w.Write([]byte(`[
+				{"id": 1, "last_modified_date": "2024-01-01"},
+				{"id": 2, "last_modified_date": "2024-02-02"},
+				{"id": 3, "last_modified_date": "2024-03-03"}
+			]`))
+		case "/routes/":
+			w.Write([]byte(`[{"id": 10, "name": "Known"}, {"id": 11, "name": "Fresh"}]`))
+		case "/profiles/":
+			w.Write([]byte(`{"id": 1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+	testApp.DB.SetConnected(true)
+
+	sqlDB := testApp.DB.GetDB()
+	if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, LastModifiedDate) VALUES (1, '2024-01-01'), (2, '2023-12-31')"); err != nil {
+		t.Fatalf("seed accounts: %v", err)
+	}
+	if _, err := sqlDB.Exec("INSERT INTO Routes (RouteId, Name) VALUES (10, 'Known')"); err != nil {
+		t.Fatalf("seed routes: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		top          int
+		wantAccounts pull.EntityImpact
+		wantCalls    int
+	}{
+		{
+			name:         "full pull",
+			wantAccounts: pull.EntityImpact{Remote: 3, New: 1, Updated: 1, Unchanged: 1},
+			wantCalls:    (1 + 3) + (1 + 3) + (1 + 2) + 1,
+		},
+		{
+			name:         "narrowed to top 1",
+			top:          1,
+			wantAccounts: pull.EntityImpact{Remote: 1, Unchanged: 1},
+			wantCalls:    (1 + 1) + (1 + 1) + (1 + 2) + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impact, err := pull.EstimatePullImpact(testApp, tt.top)
+			if err != nil {
+				t.Fatalf("EstimatePullImpact returned error: %v", err)
+			}
+			if impact.Accounts != tt.wantAccounts {
+				t.Errorf("accounts impact = %+v, want %+v", impact.Accounts, tt.wantAccounts)
+			}
+			if impact.Routes.New != 1 || impact.Routes.Unchanged != 1 {
+				t.Errorf("unexpected routes impact: %+v", impact.Routes)
+			}
+			if impact.APICalls != tt.wantCalls {
+				t.Errorf("APICalls = %d, want %d", impact.APICalls, tt.wantCalls)
+			}
+			if impact.EstimatedDuration <= 0 || impact.FromHistory {
+				t.Errorf("expected default-rate estimate, got %v (history=%v)", impact.EstimatedDuration, impact.FromHistory)
+			}
+			if !strings.Contains(impact.String(), "Required API calls") {
+				t.Errorf("preview text missing API call count: %q", impact.String())
+			}
+		})
+	}
+}
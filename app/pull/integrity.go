@@ -0,0 +1,145 @@
+package pull
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"sort"
+)
+
+// orphanTables is the order tables are reported and repaired in.
+var orphanTables = []string{"AccountLocations", "AccountCheckins", "RouteWaypoints"}
+
+// IntegrityReport lists child rows that reference missing parents.
+type IntegrityReport struct {
+	Orphans []database.OrphanedRow
+}
+
+// Tables returns the child tables that have orphans, in report order.
+func (r *IntegrityReport) Tables() []string {
+	var tables []string
+	for _, table := range orphanTables {
+		if len(r.ForTable(table)) > 0 {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// ForTable returns the orphans found in table.
+func (r *IntegrityReport) ForTable(table string) []database.OrphanedRow {
+	var rows []database.OrphanedRow
+	for _, o := range r.Orphans {
+		if o.Table == table {
+			rows = append(rows, o)
+		}
+	}
+	return rows
+}
+
+// MissingParents returns the sorted, distinct parent IDs referenced by the
+// orphans in table.
+func (r *IntegrityReport) MissingParents(table string) []int {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, o := range r.ForTable(table) {
+		if !seen[o.ParentId] {
+			seen[o.ParentId] = true
+			ids = append(ids, o.ParentId)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// RepairOptions selects how RepairIntegrity resolves orphans. When both are
+// set, missing parents are fetched first and whatever remains is deleted.
+type RepairOptions struct {
+	FetchParents  bool
+	DeleteOrphans bool
+}
+
+// RepairResult summarizes a repair run.
+type RepairResult struct {
+	ParentsFetched int
+	FetchFailures  int
+	RowsDeleted    int64
+	Remaining      *IntegrityReport
+}
+
+// CheckIntegrity scans the local database for orphaned child rows.
+func CheckIntegrity(a *app.App) (*IntegrityReport, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	orphans, err := database.GetOrphanedRows(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for orphaned rows: %w", err)
+	}
+	return &IntegrityReport{Orphans: orphans}, nil
+}
+
+// RepairIntegrity resolves the orphans in report according to opts and
+// returns what is left afterwards.
+func RepairIntegrity(a *app.App, report *IntegrityReport, opts RepairOptions) (*RepairResult, error) {
+	result := &RepairResult{Remaining: report}
+	if opts.FetchParents {
+		if a.API == nil || a.API.APIKey == "" {
+			return nil, fmt.Errorf("api key is not configured; cannot fetch missing parents")
+		}
+		accounts := mergeIDs(report.MissingParents("AccountLocations"), report.MissingParents("AccountCheckins"))
+		for _, id := range accounts {
+			if _, err := PullAccount(a, id); err != nil {
+				a.Events.Dispatch(events.Warningf("fsck", "Could not fetch missing account %d: %v", id, err))
+				result.FetchFailures++
+				continue
+			}
+			result.ParentsFetched++
+		}
+		for _, id := range report.MissingParents("RouteWaypoints") {
+			if _, err := PullRoute(a, id); err != nil {
+				a.Events.Dispatch(events.Warningf("fsck", "Could not fetch missing route %d: %v", id, err))
+				result.FetchFailures++
+				continue
+			}
+			result.ParentsFetched++
+		}
+	}
+
+	if opts.DeleteOrphans {
+		// Re-scan so rows whose parents were just fetched are kept.
+		remaining, err := CheckIntegrity(a)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range remaining.Tables() {
+			n, err := database.DeleteOrphanedRows(a.DB, table)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete orphans from %s: %w", table, err)
+			}
+			a.Events.Dispatch(events.Infof("fsck", "Deleted %d orphaned rows from %s", n, table))
+			result.RowsDeleted += n
+		}
+	}
+
+	remaining, err := CheckIntegrity(a)
+	if err != nil {
+		return nil, err
+	}
+	result.Remaining = remaining
+	return result, nil
+}
+
+func mergeIDs(a, b []int) []int {
+	seen := make(map[int]bool, len(a)+len(b))
+	var ids []int
+	for _, id := range append(append([]int{}, a...), b...) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
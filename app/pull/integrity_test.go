@@ -0,0 +1,71 @@
+package pull_test
+
+import (
+	"badgermaps/app/pull"
+	"net/http"
+	"testing"
+)
+
+func TestCheckAndRepairIntegrity(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/customers/5/":
+			w.Write([]byte(`{"id": 5, "last_name": "Recovered"}`))
+		case "/routes/20/":
+			w.Write([]byte(`{"id": 20, "name": "Recovered route"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+	testApp.DB.SetConnected(true)
+
+	sqlDB := testApp.DB.GetDB()
+	seed := []string{
+		"INSERT INTO Accounts (AccountId, LastName) VALUES (1, 'Present')",
+		"INSERT INTO AccountLocations (AccountId, City) VALUES (1, 'Kept'), (5, 'Orphan')",
+		"INSERT INTO AccountCheckins (CheckinId, AccountId) VALUES (100, 1), (101, 6), (102, 6)",
+		"INSERT INTO RouteWaypoints (WaypointId, RouteId, Name) VALUES (200, 20, 'Orphan')",
+	}
+	for _, stmt := range seed {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+
+	report, err := pull.CheckIntegrity(testApp)
+	if err != nil {
+		t.Fatalf("CheckIntegrity returned error: %v", err)
+	}
+	if len(report.Orphans) != 4 {
+		t.Fatalf("expected 4 orphans, got %+v", report.Orphans)
+	}
+	if got := report.MissingParents("AccountCheckins"); len(got) != 1 || got[0] != 6 {
+		t.Fatalf("unexpected missing check-in parents: %v", got)
+	}
+	if got := report.Tables(); len(got) != 3 {
+		t.Fatalf("expected orphans in 3 tables, got %v", got)
+	}
+
+	result, err := pull.RepairIntegrity(testApp, report, pull.RepairOptions{FetchParents: true, DeleteOrphans: true})
+	if err != nil {
+		t.Fatalf("RepairIntegrity returned error: %v", err)
+	}
+	if result.ParentsFetched != 2 || result.FetchFailures != 1 {
+		t.Errorf("fetched %d parents with %d failures, want 2 and 1", result.ParentsFetched, result.FetchFailures)
+	}
+	if result.RowsDeleted != 2 {
+		t.Errorf("deleted %d rows, want 2", result.RowsDeleted)
+	}
+	if len(result.Remaining.Orphans) != 0 {
+		t.Errorf("expected no remaining orphans, got %+v", result.Remaining.Orphans)
+	}
+
+	var kept int
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM AccountCheckins WHERE CheckinId = 100").Scan(&kept); err != nil || kept != 1 {
+		t.Errorf("check-in with a valid parent should be kept (count=%d, err=%v)", kept, err)
+	}
+}
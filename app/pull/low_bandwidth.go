@@ -0,0 +1,96 @@
+package pull
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"time"
+)
+
+// pullAccountList is the account pull of low-bandwidth mode. It fetches
+// only the customers list, one request, and compares each account's last
+// modified date with the stored one. New accounts are stored with their
+// name, and new and changed accounts are marked stale; their details are
+// fetched when they are opened in the Explorer or by the next full pull.
+// top and the pull radius limit the accounts as in PullGroupAccounts.
+func pullAccountList(a *app.App, top int) error {
+	resp, err := a.API.GetAccounts()
+	if err != nil {
+		err = fmt.Errorf("error getting the account list: %w", err)
+		a.Events.Dispatch(events.Event{Type: "pull.error", Source: "accounts", Payload: events.ErrorPayload{Error: err}})
+		return err
+	}
+	listed := resp.Data
+	radius, limited, err := a.PullRadius()
+	if err != nil {
+		return err
+	}
+	if limited {
+		inside, err := territoryAccounts(a, radius, listed)
+		if err != nil {
+			return err
+		}
+		kept := listed[:0]
+		for _, account := range listed {
+			if inside[int(account.AccountId.Int64)] {
+				kept = append(kept, account)
+			}
+		}
+		listed = kept
+	}
+	if top > 0 && top < len(listed) {
+		listed = listed[:top]
+	}
+	a.Events.Dispatch(events.Event{Type: "pull.ids_fetched", Source: "accounts", Payload: events.ResourceIDsFetchedPayload{Count: len(listed)}})
+
+	stored, err := database.GetAccountModifiedDates(a.DB)
+	if err != nil {
+		return fmt.Errorf("error reading stored accounts: %w", err)
+	}
+	now := time.Now()
+	var commands []database.Command
+	var added, changed int
+	ids := make([]int, 0, len(listed))
+	for _, account := range listed {
+		id := int(account.AccountId.Int64)
+		ids = append(ids, id)
+		if a.IsAccountErased(id) {
+			continue
+		}
+		remoteModified := account.LastModifiedDate.ValueOrZero()
+		storedModified, exists := stored[id]
+		switch {
+		case !exists:
+			if err := a.EncryptAccountColumns(&account); err != nil {
+				return err
+			}
+			commands = append(commands, database.Command{Name: "MergeAccountsBasic", Args: []any{id, account.FullName}})
+			added++
+		case remoteModified == "" || remoteModified != storedModified:
+			changed++
+		default:
+			continue
+		}
+		commands = append(commands, database.MarkAccountStaleCommand(id, remoteModified, now))
+	}
+	if len(commands) > 0 {
+		err = a.WithoutChangeCapture(func() error {
+			return database.WorkCommands(a.DB, "store.account_list", commands)
+		})
+		if err != nil {
+			return fmt.Errorf("error storing the account list: %w", err)
+		}
+	}
+
+	if _, limited, _ := a.PullRadius(); top == 0 && !limited {
+		if _, err := remapCrmDuplicates(a, ids); err != nil {
+			return fmt.Errorf("error remapping merged accounts: %w", err)
+		}
+	}
+
+	a.Events.Dispatch(events.Event{Type: "pull.group.complete", Source: "accounts", Payload: events.CompletionPayload{Success: true, Count: added + changed}})
+	a.Events.Dispatch(events.Infof("pull", "Low bandwidth: listed %d accounts; %d new and %d changed are fetched when opened, %d are unchanged",
+		len(listed), added, changed, len(listed)-added-changed))
+	return nil
+}
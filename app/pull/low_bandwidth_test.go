@@ -0,0 +1,71 @@
+package pull_test
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestPullGroupAccountsLowBandwidth(t *testing.T) {
+	customers := []map[string]interface{}{
+		{"id": 1, "full_name": "Unchanged", "last_modified_date": "2024-01-01T00:00:00"},
+		{"id": 2, "full_name": "Changed", "last_modified_date": "2024-02-01T00:00:00"},
+		{"id": 3, "full_name": "New", "last_modified_date": "2024-03-01T00:00:00"},
+	}
+	var detailRequests int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/customers/") {
+			json.NewEncoder(w).Encode(customers)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/customers/") {
+			detailRequests++
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 2, "full_name": "Changed", "last_modified_date": "2024-02-01T00:00:00"})
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+	testApp.Config.LowBandwidth = true
+
+	for id, modified := range map[int]string{1: "2024-01-01T00:00:00", 2: "2024-01-15T00:00:00"} {
+		acc := &models.Account{AccountId: null.IntFrom(int64(id)), LastModifiedDate: models.DateFrom(modified)}
+		if err := pull.StoreAccountDetailed(testApp, acc); err != nil {
+			t.Fatalf("StoreAccountDetailed returned error: %v", err)
+		}
+	}
+
+	if err := pull.PullGroupAccounts(testApp, 0); err != nil {
+		t.Fatalf("PullGroupAccounts returned error: %v", err)
+	}
+	if detailRequests != 0 {
+		t.Errorf("expected no detail requests, got %d", detailRequests)
+	}
+	stale, err := database.GetStaleAccountIDs(testApp.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 2 || stale[0] != 2 || stale[1] != 3 {
+		t.Fatalf("stale accounts = %v, want [2 3]", stale)
+	}
+	var name string
+	if err := testApp.DB.GetDB().QueryRow("SELECT FullName FROM Accounts WHERE AccountId = 3").Scan(&name); err != nil || name != "New" {
+		t.Fatalf("new account row = %q, %v; want its name stored", name, err)
+	}
+
+	// Opening the account fetches its details and clears the mark.
+	if _, err := pull.PullAccount(testApp, 2); err != nil {
+		t.Fatalf("PullAccount returned error: %v", err)
+	}
+	if isStale, err := database.IsAccountStale(testApp.DB, 2); err != nil || isStale {
+		t.Errorf("IsAccountStale(2) after fetching = %v, %v; want false", isStale, err)
+	}
+}
@@ -0,0 +1,115 @@
+package pull
+
+import (
+	"badgermaps/api"
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"database/sql"
+	"errors"
+	"slices"
+	"sort"
+)
+
+// BadgerMaps merges duplicate customers on the server without telling
+// clients, which leaves the merged-away account behind locally with its
+// check-ins and waypoints. The functions here spot the signs of a merge
+// during a pull and remap the stale ID to the surviving one.
+
+// followRedirect remaps requestedID when the API answered for it with a
+// different account, which is how BadgerMaps redirects a merged ID. It is
+// a no-op when the answer matches or requestedID is not stored locally.
+func followRedirect(a *app.App, requestedID int, account *models.Account) error {
+	if account == nil || !account.AccountId.Valid {
+		return nil
+	}
+	newID := int(account.AccountId.Int64)
+	if newID == requestedID {
+		return nil
+	}
+	if _, err := database.GetAccountByID(a.DB, requestedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	_, err := a.RemapAccount(requestedID, newID, database.RemapReasonRedirect)
+	return err
+}
+
+// remapGoneAccount handles a 410 for id: when exactly one other local
+// account shares its CRM ID, id is remapped to it and that account's ID is
+// returned. Otherwise it returns 0.
+func remapGoneAccount(a *app.App, id int, pullErr error) (int, error) {
+	if !api.IsGone(pullErr) {
+		return 0, nil
+	}
+	byCrmId, err := database.GetAccountIdsByCrmId(a.DB)
+	if err != nil {
+		return 0, err
+	}
+	for _, ids := range byCrmId {
+		if !slices.Contains(ids, id) || len(ids) != 2 {
+			continue
+		}
+		newID := ids[0]
+		if newID == id {
+			newID = ids[1]
+		}
+		if _, err := a.RemapAccount(id, newID, database.RemapReasonGone); err != nil {
+			return 0, err
+		}
+		return newID, nil
+	}
+	return 0, nil
+}
+
+// remapCrmDuplicates runs after a full account pull. listed are the IDs
+// BadgerMaps returned; a local account missing from it whose CRM ID is
+// shared with exactly one listed account was merged into that account.
+// It returns the number of accounts remapped.
+func remapCrmDuplicates(a *app.App, listed []int) (int, error) {
+	byCrmId, err := database.GetAccountIdsByCrmId(a.DB)
+	if err != nil {
+		return 0, err
+	}
+	isListed := make(map[int]bool, len(listed))
+	for _, id := range listed {
+		isListed[id] = true
+	}
+	crmIds := make([]string, 0, len(byCrmId))
+	for crmId := range byCrmId {
+		crmIds = append(crmIds, crmId)
+	}
+	sort.Strings(crmIds)
+
+	remapped := 0
+	for _, crmId := range crmIds {
+		ids := byCrmId[crmId]
+		var survivors, stale []int
+		for _, id := range ids {
+			if isListed[id] {
+				survivors = append(survivors, id)
+			} else {
+				stale = append(stale, id)
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+		if len(survivors) != 1 {
+			if len(survivors) > 1 {
+				a.Events.Dispatch(events.Warningf("pull", "CRM ID %q belongs to %d accounts in BadgerMaps; not remapping %v", crmId, len(survivors), stale))
+			}
+			continue
+		}
+		for _, id := range stale {
+			if _, err := a.RemapAccount(id, survivors[0], database.RemapReasonCrmId); err != nil {
+				return remapped, err
+			}
+			remapped++
+		}
+	}
+	return remapped, nil
+}
@@ -0,0 +1,98 @@
+package pull_test
+
+import (
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPullRemapsMergedAccounts(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/customers/"):
+			w.Write([]byte(`[{"id": 2}, {"id": 5}]`))
+		case strings.HasSuffix(r.URL.Path, "/customers/7/"):
+			// 7 redirects to the account it was merged into.
+			w.Write([]byte(`{"id": 5, "last_name": "Initech", "crm_id": "CRM-5"}`))
+		case strings.HasSuffix(r.URL.Path, "/customers/9/"):
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"detail": "merged"}`))
+		default:
+			var id int
+			fmt.Sscanf(r.URL.Path[strings.LastIndex(strings.TrimSuffix(r.URL.Path, "/"), "/")+1:], "%d", &id)
+			fmt.Fprintf(w, `{"id": %d, "last_name": "Account %d", "crm_id": "CRM-%d"}`, id, id, id)
+		}
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+	testApp.MaxConcurrentRequests = 2
+
+	sqlDB := testApp.DB.GetDB()
+	seed := []string{
+		// 1 shares its CRM ID with 2, which BadgerMaps still lists.
+		`INSERT INTO Accounts (AccountId, LastName, CrmId) VALUES (1, 'Acme (old)', 'CRM-2'), (7, 'Initech (old)', NULL), (9, 'Hooli (old)', 'CRM-10'), (10, 'Hooli', 'CRM-10')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId) VALUES (100, 1), (101, 7), (102, 9)`,
+		`INSERT INTO Routes (RouteId, Name) VALUES (50, 'Monday')`,
+		`INSERT INTO RouteWaypoints (WaypointId, RouteId, CustomerId) VALUES (500, 50, 1)`,
+	}
+	for _, stmt := range seed {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+
+	if account, err := pull.PullAccount(testApp, 7); err != nil || account.AccountId.Int64 != 5 {
+		t.Fatalf("PullAccount(7) = %v, %v; want account 5", account, err)
+	}
+	if account, err := pull.PullAccount(testApp, 9); err != nil || account.AccountId.Int64 != 10 {
+		t.Fatalf("PullAccount(9) = %v, %v; want account 10", account, err)
+	}
+	// A limited pull does not list every account, so nothing is remapped.
+	if err := pull.PullGroupAccounts(testApp, 1); err != nil {
+		t.Fatalf("PullGroupAccounts(1): %v", err)
+	}
+	if _, err := database.GetAccountByID(testApp.DB, 1); err != nil {
+		t.Fatalf("account 1 was removed by a limited pull: %v", err)
+	}
+	if err := pull.PullGroupAccounts(testApp, 0); err != nil {
+		t.Fatalf("PullGroupAccounts: %v", err)
+	}
+
+	owner := func(query string) int {
+		t.Helper()
+		var id int
+		if err := sqlDB.QueryRow(query).Scan(&id); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return id
+	}
+	for query, want := range map[string]int{
+		`SELECT AccountId FROM AccountCheckins WHERE CheckinId = 100`:  2,
+		`SELECT AccountId FROM AccountCheckins WHERE CheckinId = 101`:  5,
+		`SELECT AccountId FROM AccountCheckins WHERE CheckinId = 102`:  10,
+		`SELECT CustomerId FROM RouteWaypoints WHERE WaypointId = 500`: 2,
+		`SELECT COUNT(*) FROM Accounts WHERE AccountId IN (1, 7, 9)`:   0,
+		`SELECT COUNT(*) FROM Accounts WHERE AccountId IN (2, 5, 10)`:  3,
+	} {
+		if got := owner(query); got != want {
+			t.Errorf("%s = %d, want %d", query, got, want)
+		}
+	}
+
+	remaps, err := database.GetIdRemaps(testApp.DB)
+	if err != nil {
+		t.Fatalf("GetIdRemaps: %v", err)
+	}
+	reasons := make(map[int]string)
+	for _, remap := range remaps {
+		reasons[remap.OldId] = fmt.Sprintf("%d %s", remap.NewId, remap.Reason)
+	}
+	want := map[int]string{1: "2 crm_id", 7: "5 redirect", 9: "10 gone"}
+	if fmt.Sprint(reasons) != fmt.Sprint(want) {
+		t.Errorf("remaps = %v, want %v", reasons, want)
+	}
+}
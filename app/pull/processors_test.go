@@ -0,0 +1,53 @@
+package pull_test
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app/processor"
+	"badgermaps/app/pull"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestStoreAccountRunsProcessors(t *testing.T) {
+	testApp, teardown := setupTestApp(t, http.NotFoundHandler())
+	defer teardown()
+	testApp.DB.SetConnected(true)
+
+	processor.Register(processor.Func{ID: "pull_test.enrich", Fn: func(e *processor.Entity) error {
+		if e.Stage != processor.StagePull {
+			t.Errorf("expected pull stage, got %s", e.Stage)
+		}
+		if e.ID == 2 {
+			return processor.Veto("internal account")
+		}
+		e.Fields["last_name"] = strings.ToUpper(e.Fields["last_name"].(string))
+		return nil
+	}})
+	chain, err := processor.NewChain(map[string][]string{processor.EntityAccount: {"pull_test.enrich"}})
+	if err != nil {
+		t.Fatalf("NewChain returned error: %v", err)
+	}
+	testApp.Processors = chain
+
+	for id, name := range map[int64]string{1: "smith", 2: "internal"} {
+		acc := &models.Account{AccountId: null.IntFrom(id), LastName: null.StringFrom(name)}
+		if err := pull.StoreAccountDetailed(testApp, acc); err != nil {
+			t.Fatalf("StoreAccountDetailed(%d) returned error: %v", id, err)
+		}
+	}
+
+	var lastName string
+	if err := testApp.DB.GetDB().QueryRow("SELECT LastName FROM Accounts WHERE AccountId = 1").Scan(&lastName); err != nil {
+		t.Fatalf("failed to read stored account: %v", err)
+	}
+	if lastName != "SMITH" {
+		t.Errorf("expected processor to transform last name, got %q", lastName)
+	}
+	var vetoed int
+	if err := testApp.DB.GetDB().QueryRow("SELECT COUNT(*) FROM Accounts WHERE AccountId = 2").Scan(&vetoed); err != nil || vetoed != 0 {
+		t.Errorf("expected vetoed account to be skipped (count=%d, err=%v)", vetoed, err)
+	}
+}
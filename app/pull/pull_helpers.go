@@ -3,11 +3,15 @@ package pull
 import (
 	"badgermaps/api/models"
 	"badgermaps/app"
+	"badgermaps/app/processor"
 	"badgermaps/database"
+	"badgermaps/errs"
 	"badgermaps/events"
+	"badgermaps/telemetry"
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,10 +20,15 @@ import (
 )
 
 func PullAccount(a *app.App, accountID int) (account *models.Account, err error) {
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
 	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "account", Payload: events.PullStartPayload{ResourceID: accountID}})
 	a.Events.Dispatch(events.Infof("pull", "Pulling account with ID: %d", accountID))
+	ctx, span := telemetry.Start(context.Background(), "pull.account", telemetry.EntityID.Int(accountID))
 
 	defer func() {
+		telemetry.End(span, err)
 		success := err == nil
 		payload := events.CompletionPayload{Success: success, ResourceID: accountID}
 		if success {
@@ -31,94 +40,168 @@ func PullAccount(a *app.App, accountID int) (account *models.Account, err error)
 		a.Events.Dispatch(events.Event{Type: "pull.complete", Source: "account", Payload: payload})
 	}()
 
-	accountResp, err := a.API.GetAccountDetailed(accountID)
+	account, err = fetchAccountFollowingMerges(ctx, a, accountID)
 	if err != nil {
-		return nil, fmt.Errorf("error pulling account: %w", err)
+		return nil, err
 	}
-	account = &accountResp.Data
 
-	if err = StoreAccountDetailed(a, account); err != nil {
+	pulled, err := a.PulledCustomColumns()
+	if err != nil {
+		return nil, fmt.Errorf("error reading pulled custom fields: %w", err)
+	}
+	if err = storeAccount(ctx, a, account, pulled); err != nil {
 		return nil, fmt.Errorf("error storing account: %w", err)
 	}
+	if err = followRedirect(a, accountID, account); err != nil {
+		return nil, fmt.Errorf("error remapping merged account: %w", err)
+	}
+	enrichAccounts(a, []int{int(account.AccountId.Int64)})
 
 	a.Events.Dispatch(events.Infof("pull", "Successfully pulled account with ID: %d", accountID))
 	return account, nil
 }
 
-func PullGroupAccounts(a *app.App, top int, progressCallback func(current, total int)) (err error) {
+// enrichAccounts computes the configured account scores after a pull
+// stored accounts, every account's when ids is nil. A failure is reported
+// but does not fail the pull, which has already stored its data.
+func enrichAccounts(a *app.App, ids []int) {
+	if _, err := a.EnrichAccounts(ids); err != nil {
+		a.Events.Dispatch(events.Warningf("pull", "Account scores were not updated: %v", err))
+	}
+}
+
+// fetchAccountFollowingMerges fetches an account. An account BadgerMaps no
+// longer has is remapped to the account it was merged into, which is
+// fetched instead.
+func fetchAccountFollowingMerges(ctx context.Context, a *app.App, accountID int) (*models.Account, error) {
+	accountResp, err := fetchAccount(ctx, a, accountID)
+	if err != nil {
+		newID, remapErr := remapGoneAccount(a, accountID, err)
+		if remapErr != nil {
+			return nil, fmt.Errorf("error pulling account: %w (remapping it failed: %v)", err, remapErr)
+		}
+		if newID == 0 {
+			return nil, fmt.Errorf("error pulling account: %w", err)
+		}
+		if accountResp, err = fetchAccount(ctx, a, newID); err != nil {
+			return nil, fmt.Errorf("error pulling account %d, which %d was merged into: %w", newID, accountID, err)
+		}
+	}
+	return &accountResp.Data, nil
+}
+
+func PullGroupAccounts(a *app.App, top int) (err error) {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
+	release, err := a.AcquireSyncLock("pull accounts")
+	if err != nil {
+		return err
+	}
+	defer release()
 	a.Events.Dispatch(events.Event{Type: "pull.group.start", Source: "accounts"})
+	ctx, span := telemetry.Start(context.Background(), "pull.accounts")
 
 	defer func() {
+		telemetry.End(span, err)
 		if err != nil {
 			a.Events.Dispatch(events.Event{Type: "pull.group.error", Source: "accounts", Payload: events.ErrorPayload{Error: err}})
 		}
 	}()
 
-	accountIDsResp, err := a.API.GetAccountIDs()
+	if a.LowBandwidth() {
+		if err = pullAccountList(a, top); err == nil {
+			enrichAccounts(a, nil)
+		}
+		return err
+	}
+
+	accountIDs, err := pullAccountIDs(a, "accounts")
 	if err != nil {
 		err = fmt.Errorf("error getting account IDs: %w", err)
 		a.Events.Dispatch(events.Event{Type: "pull.error", Source: "accounts", Payload: events.ErrorPayload{Error: err}})
 		return err
 	}
-	accountIDs := accountIDsResp.Data
 
 	if top > 0 && top < len(accountIDs) {
 		accountIDs = accountIDs[:top]
 	}
 	total := len(accountIDs)
+	span.SetAttributes(telemetry.Count.Int(total))
 	a.Events.Dispatch(events.Event{Type: "pull.ids_fetched", Source: "accounts", Payload: events.ResourceIDsFetchedPayload{Count: total}})
+	pulled, err := a.PulledCustomColumns()
+	if err != nil {
+		return fmt.Errorf("error reading pulled custom fields: %w", err)
+	}
+	progress := a.StartProgress("pull", "accounts", total)
+	defer progress.Finish()
 
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, a.MaxConcurrentRequests)
 	errorChan := make(chan error, total)
 	var successCount atomic.Int64
 
-	for i, id := range accountIDs {
+	for _, id := range accountIDs {
 		wg.Add(1)
 		sem <- struct{}{}
 
-		go func(accountID int, index int) {
+		go func(accountID int) {
 			defer wg.Done()
 			defer func() { <-sem }()
 
+			ctx, span := telemetry.Start(ctx, "pull.account", telemetry.EntityID.Int(accountID))
+			var err error
+			defer func() { telemetry.End(span, err) }()
+
 			a.Events.Dispatch(events.Event{Type: "pull.fetch_detail.start", Source: "accounts", Payload: events.FetchDetailStartPayload{ResourceID: accountID}})
-			accountResp, err := a.API.GetAccountDetailed(accountID)
+			accountResp, err := fetchAccount(ctx, a, accountID)
 			if err != nil {
 				err = fmt.Errorf("error getting detailed account info for ID %d: %w", accountID, err)
 				a.Events.Dispatch(events.Event{Type: "pull.error", Source: "accounts", Payload: events.ErrorPayload{Error: err, ResourceID: accountID}})
 				errorChan <- err
+				progress.Failed()
 				return
 			}
 			account := &accountResp.Data
 			a.Events.Dispatch(events.Event{Type: "pull.fetch_detail.success", Source: "accounts", Payload: events.FetchDetailSuccessPayload{Data: account}})
 
-			if err := StoreAccountDetailed(a, account); err != nil {
+			if err = storeAccount(ctx, a, account, pulled); err != nil {
 				err = fmt.Errorf("error storing account %d: %w", accountID, err)
 				a.Events.Dispatch(events.Event{Type: "pull.error", Source: "accounts", Payload: events.ErrorPayload{Error: err, ResourceID: accountID}})
 				errorChan <- err
+				progress.Failed()
 			} else {
 				a.Events.Dispatch(events.Event{Type: "pull.store.success", Source: "accounts", Payload: events.StoreSuccessPayload{Data: account}})
 				successCount.Add(1)
+				progress.Succeeded()
 			}
-			if progressCallback != nil {
-				progressCallback(index+1, total)
-			}
-		}(id, i)
+		}(id)
 	}
 
 	wg.Wait()
 	close(errorChan)
 
-	var pullErrors []string
+	var pullErrors []error
 	for err := range errorChan {
-		pullErrors = append(pullErrors, err.Error())
+		pullErrors = append(pullErrors, err)
+	}
+
+	// Only a full, unfiltered list shows which local accounts BadgerMaps no
+	// longer has.
+	if _, limited, _ := a.PullRadius(); top == 0 && !limited {
+		if _, err := remapCrmDuplicates(a, accountIDs); err != nil {
+			pullErrors = append(pullErrors, fmt.Errorf("error remapping merged accounts: %w", err))
+		}
 	}
 
 	if len(pullErrors) > 0 {
-		err = fmt.Errorf("encountered errors during account pull:\n- %s", strings.Join(pullErrors, "\n- "))
+		err = joinPullErrors("account", pullErrors)
 	}
 
 	successTotal := int(successCount.Load())
+	if successTotal > 0 {
+		enrichAccounts(a, nil)
+	}
 	success := err == nil
 	a.Events.Dispatch(events.Event{Type: "pull.group.complete", Source: "accounts", Payload: events.CompletionPayload{Success: success, Error: err, Count: successTotal}})
 	if success {
@@ -130,6 +213,9 @@ func PullGroupAccounts(a *app.App, top int, progressCallback func(current, total
 }
 
 func PullCheckin(a *app.App, checkinID int) (checkin *models.Checkin, err error) {
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
 	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "check-in", Payload: events.PullStartPayload{ResourceID: checkinID}})
 	a.Events.Dispatch(events.Infof("pull", "Pulling checkin with ID: %d", checkinID))
 
@@ -150,11 +236,16 @@ func PullCheckin(a *app.App, checkinID int) (checkin *models.Checkin, err error)
 
 // PullCheckinsForAccount pulls all check-ins for a specific account ID.
 func PullCheckinsForAccount(a *app.App, accountID int) (err error) {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
 	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "checkins", Payload: events.PullStartPayload{ResourceID: accountID}})
 	a.Events.Dispatch(events.Infof("pull", "Pulling check-ins for account ID: %d", accountID))
+	ctx, span := telemetry.Start(context.Background(), "pull.account_checkins", telemetry.EntityID.Int(accountID))
 
 	count := 0
 	defer func() {
+		telemetry.End(span, err)
 		success := err == nil
 		payload := events.CompletionPayload{Success: success, ResourceID: accountID}
 		if success {
@@ -166,55 +257,71 @@ func PullCheckinsForAccount(a *app.App, accountID int) (err error) {
 		a.Events.Dispatch(events.Event{Type: "pull.complete", Source: "checkins", Payload: payload})
 	}()
 
-	checkinsResp, err := a.API.GetCheckinsForAccount(accountID)
+	checkinsResp, err := fetchAccountCheckins(ctx, a, accountID)
 	if err != nil {
 		return fmt.Errorf("error getting check-ins for account %d: %w", accountID, err)
 	}
 	checkins := checkinsResp.Data
 
 	count = len(checkins)
-	for _, checkin := range checkins {
-		if err := StoreCheckin(a, checkin); err != nil {
-			return fmt.Errorf("error storing check-in %d for account %d: %w", checkin.CheckinId.Int64, accountID, err)
-		}
+	batch := newCheckinBatch(ctx, a)
+	if err := batch.Add(checkins); err != nil {
+		return fmt.Errorf("account %d: %w", accountID, err)
+	}
+	if err := batch.Flush(); err != nil {
+		return fmt.Errorf("account %d: %w", accountID, err)
 	}
 
 	a.Events.Dispatch(events.Infof("pull", "Successfully pulled %d check-ins for account %d", count, accountID))
 	return nil
 }
 
-func PullGroupCheckins(a *app.App, progressCallback func(current, total int)) (err error) {
+func PullGroupCheckins(a *app.App) (err error) {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
+	release, err := a.AcquireSyncLock("pull checkins")
+	if err != nil {
+		return err
+	}
+	defer release()
 	a.Events.Dispatch(events.Event{Type: "pull.group.start", Source: "checkins"})
+	ctx, span := telemetry.Start(context.Background(), "pull.checkins")
 
 	defer func() {
+		telemetry.End(span, err)
 		if err != nil {
 			a.Events.Dispatch(events.Event{Type: "pull.group.error", Source: "checkins", Payload: events.ErrorPayload{Error: err}})
 		}
 	}()
 
-	accountIDsResp, err := a.API.GetAccountIDs()
+	accountIDs, err := pullAccountIDs(a, "checkins")
 	if err != nil {
 		err = fmt.Errorf("error getting account IDs: %w", err)
 		a.Events.Dispatch(events.Event{Type: "pull.error", Source: "checkins", Payload: events.ErrorPayload{Error: err}})
 		return err
 	}
-	accountIDs := accountIDsResp.Data
 	total := len(accountIDs)
+	span.SetAttributes(telemetry.Count.Int(total))
 	a.Events.Dispatch(events.Event{Type: "pull.ids_fetched", Source: "checkins", Payload: events.ResourceIDsFetchedPayload{Count: total}})
+	progress := a.StartProgress("pull", "checkins", total)
+	defer progress.Finish()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, a.MaxConcurrentRequests)
-	errorChan := make(chan error, total)
+	errorChan := make(chan error, total+1)
 	var successCount atomic.Int64
+	// Check-ins are written batch_size rows at a time rather than per row.
+	batch := newCheckinBatch(ctx, a)
 
-	for i, id := range accountIDs {
+	for _, id := range accountIDs {
 		wg.Add(1)
 		sem <- struct{}{}
 
-		go func(accountID int, index int) {
+		go func(accountID int) {
 			defer wg.Done()
 			defer func() { <-sem }()
 
@@ -225,49 +332,51 @@ func PullGroupCheckins(a *app.App, progressCallback func(current, total int)) (e
 			}
 
 			a.Events.Dispatch(events.Event{Type: "pull.fetch_detail.start", Source: "checkins", Payload: events.FetchDetailStartPayload{ResourceID: accountID}})
-			checkinsResp, err := a.API.GetCheckinsForAccount(accountID)
+			checkinsResp, err := fetchAccountCheckins(ctx, a, accountID)
 			if err != nil {
 				err = fmt.Errorf("error getting checkins for account ID %d: %w", accountID, err)
 				a.Events.Dispatch(events.Event{Type: "pull.error", Source: "checkins", Payload: events.ErrorPayload{Error: err, ResourceID: accountID}})
 				errorChan <- err
+				progress.Failed()
 				cancel() // Cancel context on first error
 				return
 			}
 			checkins := checkinsResp.Data
 			a.Events.Dispatch(events.Event{Type: "pull.fetch_detail.success", Source: "checkins", Payload: events.FetchDetailSuccessPayload{Data: checkins}})
 
-			for _, checkin := range checkins {
-				select {
-				case <-ctx.Done():
-					return // Stop processing if context is cancelled
-				default:
-				}
-				if err := StoreCheckin(a, checkin); err != nil {
-					err = fmt.Errorf("error storing checkin %d: %w", checkin.CheckinId.Int64, err)
-					a.Events.Dispatch(events.Event{Type: "pull.error", Source: "checkins", Payload: events.ErrorPayload{Error: err, ResourceID: checkin.CheckinId.Int64}})
-					errorChan <- err
-					cancel() // Cancel context on first error
-				} else {
-					a.Events.Dispatch(events.Event{Type: "pull.store.success", Source: "checkins", Payload: events.StoreSuccessPayload{Data: checkin}})
-				}
+			select {
+			case <-ctx.Done():
+				return // Stop processing if context is cancelled
+			default:
 			}
-			successCount.Add(1)
-			if progressCallback != nil {
-				progressCallback(index+1, total)
+			if err := batch.Add(checkins); err != nil {
+				a.Events.Dispatch(events.Event{Type: "pull.error", Source: "checkins", Payload: events.ErrorPayload{Error: err, ResourceID: accountID}})
+				errorChan <- err
+				progress.Failed()
+				cancel() // Cancel context on first error
+				return
 			}
-		}(id, i)
+			successCount.Add(1)
+			progress.Succeeded()
+		}(id)
 	}
 
 	wg.Wait()
+	// Store what the accounts fetched before any error, as a per-row pull
+	// would have.
+	if err := batch.Flush(); err != nil {
+		a.Events.Dispatch(events.Event{Type: "pull.error", Source: "checkins", Payload: events.ErrorPayload{Error: err}})
+		errorChan <- err
+	}
 	close(errorChan)
 
-	var pullErrors []string
+	var pullErrors []error
 	for err := range errorChan {
-		pullErrors = append(pullErrors, err.Error())
+		pullErrors = append(pullErrors, err)
 	}
 
 	if len(pullErrors) > 0 {
-		err = fmt.Errorf("encountered errors during check-in pull:\n- %s", strings.Join(pullErrors, "\n- "))
+		err = joinPullErrors("check-in", pullErrors)
 	}
 
 	successTotal := int(successCount.Load())
@@ -282,10 +391,15 @@ func PullGroupCheckins(a *app.App, progressCallback func(current, total int)) (e
 }
 
 func PullRoute(a *app.App, routeID int) (route *models.Route, err error) {
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
 	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "route", Payload: events.PullStartPayload{ResourceID: routeID}})
 	a.Events.Dispatch(events.Infof("pull", "Pulling route with ID: %d", routeID))
+	ctx, span := telemetry.Start(context.Background(), "pull.route", telemetry.EntityID.Int(routeID))
 
 	defer func() {
+		telemetry.End(span, err)
 		success := err == nil
 		payload := events.CompletionPayload{Success: success, ResourceID: routeID}
 		if success {
@@ -297,13 +411,18 @@ func PullRoute(a *app.App, routeID int) (route *models.Route, err error) {
 		a.Events.Dispatch(events.Event{Type: "pull.complete", Source: "route", Payload: payload})
 	}()
 
+	_, apiSpan := telemetry.Start(ctx, "api.GetRoute", telemetry.EntityID.Int(routeID))
 	routeResp, err := a.API.GetRoute(routeID)
+	telemetry.End(apiSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("error pulling route: %w", err)
 	}
 	route = &routeResp.Data
 
-	if err = StoreRoute(a, *route); err != nil {
+	_, dbSpan := telemetry.Start(ctx, "db.StoreRoute", telemetry.EntityID.Int(routeID))
+	err = StoreRoute(a, *route)
+	telemetry.End(dbSpan, err)
+	if err != nil {
 		return nil, fmt.Errorf("error storing route: %w", err)
 	}
 
@@ -311,16 +430,28 @@ func PullRoute(a *app.App, routeID int) (route *models.Route, err error) {
 	return route, nil
 }
 
-func PullGroupRoutes(a *app.App, progressCallback func(current, total int)) (err error) {
+func PullGroupRoutes(a *app.App) (err error) {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
+	release, err := a.AcquireSyncLock("pull routes")
+	if err != nil {
+		return err
+	}
+	defer release()
 	a.Events.Dispatch(events.Event{Type: "pull.group.start", Source: "routes"})
+	ctx, span := telemetry.Start(context.Background(), "pull.routes")
 
 	defer func() {
+		telemetry.End(span, err)
 		if err != nil {
 			a.Events.Dispatch(events.Event{Type: "pull.group.error", Source: "routes", Payload: events.ErrorPayload{Error: err}})
 		}
 	}()
 
+	_, apiSpan := telemetry.Start(ctx, "api.GetRoutes")
 	routesResp, err := a.API.GetRoutes()
+	telemetry.End(apiSpan, err)
 	if err != nil {
 		err = fmt.Errorf("error getting routes: %w", err)
 		a.Events.Dispatch(events.Event{Type: "pull.error", Source: "routes", Payload: events.ErrorPayload{Error: err}})
@@ -328,34 +459,40 @@ func PullGroupRoutes(a *app.App, progressCallback func(current, total int)) (err
 	}
 	routes := routesResp.Data
 	total := len(routes)
+	span.SetAttributes(telemetry.Count.Int(total))
 	a.Events.Dispatch(events.Event{Type: "pull.ids_fetched", Source: "routes", Payload: events.ResourceIDsFetchedPayload{Count: total}})
+	progress := a.StartProgress("pull", "routes", total)
+	defer progress.Finish()
 
 	successCount := 0
-	var routeErrors []string
+	var routeErrors []error
 	for i, route := range routes {
 		if !route.RouteId.Valid {
 			if a.State.Verbose {
 				a.Events.Dispatch(events.Debugf("pull", "Skipping route %d of %d with null ID", i+1, total))
 			}
+			progress.Skipped()
 			continue
 		}
 
 		a.Events.Dispatch(events.Event{Type: "pull.fetch_detail.success", Source: "routes", Payload: events.FetchDetailSuccessPayload{Data: route}})
-		if storeErr := StoreRoute(a, route); storeErr != nil {
+		_, dbSpan := telemetry.Start(ctx, "db.StoreRoute", telemetry.EntityID.Int64(route.RouteId.Int64))
+		storeErr := StoreRoute(a, route)
+		telemetry.End(dbSpan, storeErr)
+		if storeErr != nil {
 			wrappedErr := fmt.Errorf("error storing route %d: %w", route.RouteId.Int64, storeErr)
 			a.Events.Dispatch(events.Event{Type: "pull.error", Source: "routes", Payload: events.ErrorPayload{Error: wrappedErr, ResourceID: route.RouteId.Int64}})
-			routeErrors = append(routeErrors, wrappedErr.Error())
+			routeErrors = append(routeErrors, wrappedErr)
+			progress.Failed()
 		} else {
 			a.Events.Dispatch(events.Event{Type: "pull.store.success", Source: "routes", Payload: events.StoreSuccessPayload{Data: route}})
 			successCount++
-		}
-		if progressCallback != nil {
-			progressCallback(i+1, total)
+			progress.Succeeded()
 		}
 	}
 
 	if len(routeErrors) > 0 {
-		err = fmt.Errorf("encountered errors during route pull:\n- %s", strings.Join(routeErrors, "\n- "))
+		err = joinPullErrors("route", routeErrors)
 	}
 
 	success := len(routeErrors) == 0
@@ -369,10 +506,20 @@ func PullGroupRoutes(a *app.App, progressCallback func(current, total int)) (err
 }
 
 func PullProfile(a *app.App, progressCallback func(current, total int)) (profile *models.UserProfile, err error) {
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
+	release, err := a.AcquireSyncLock("pull profile")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "user profile"})
 	a.Events.Dispatch(events.Infof("pull", "Pulling user profile..."))
+	ctx, span := telemetry.Start(context.Background(), "pull.profile")
 
 	defer func() {
+		telemetry.End(span, err)
 		var profileID interface{}
 		if profile != nil && profile.ProfileId.Valid {
 			profileID = profile.ProfileId.Int64
@@ -391,7 +538,9 @@ func PullProfile(a *app.App, progressCallback func(current, total int)) (profile
 	totalSteps := 3 // 1. Get profile, 2. Store profile, 3. Update configs
 	currentStep := 0
 
+	_, apiSpan := telemetry.Start(ctx, "api.GetUserProfile")
 	profileResp, err := a.API.GetUserProfile()
+	telemetry.End(apiSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("error pulling user profile: %w", err)
 	}
@@ -401,7 +550,10 @@ func PullProfile(a *app.App, progressCallback func(current, total int)) (profile
 		progressCallback(currentStep, totalSteps)
 	}
 
-	if err = StoreProfile(a, profile); err != nil {
+	_, dbSpan := telemetry.Start(ctx, "db.StoreProfile")
+	err = StoreProfile(a, profile)
+	telemetry.End(dbSpan, err)
+	if err != nil {
 		return nil, fmt.Errorf("error storing profile: %w", err)
 	}
 	currentStep++
@@ -418,32 +570,154 @@ func PullProfile(a *app.App, progressCallback func(current, total int)) (profile
 }
 
 func StoreAccountDetailed(a *app.App, acc *models.Account) error {
+	pulled, err := a.PulledCustomColumns()
+	if err != nil {
+		return err
+	}
+	return storeAccountDetailed(a, acc, pulled)
+}
+
+// storeAccountDetailed merges a pulled account, keeping only the custom
+// fields in pulled (all of them when it is nil). Bulk pulls resolve pulled
+// once instead of for every account.
+func storeAccountDetailed(a *app.App, acc *models.Account, pulled map[string]bool) error {
+	commands, err := accountCommands(a, acc, pulled)
+	if err != nil || len(commands) == 0 {
+		return err
+	}
+	return a.WithoutChangeCapture(func() error {
+		return database.WorkCommands(a.DB, "store.accounts", commands)
+	})
+}
+
+// StoreAccountsDetailed merges several pulled accounts in one transaction,
+// keeping the custom fields in pulled.
+func StoreAccountsDetailed(a *app.App, accounts []*models.Account, pulled map[string]bool) error {
+	var commands []database.Command
+	for _, acc := range accounts {
+		accCommands, err := accountCommands(a, acc, pulled)
+		if err != nil {
+			return fmt.Errorf("account %d: %w", acc.AccountId.Int64, err)
+		}
+		commands = append(commands, accCommands...)
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	return a.WithoutChangeCapture(func() error {
+		return database.WorkCommands(a.DB, "store.accounts", commands)
+	})
+}
+
+// accountCommands prepares a pulled account for storing and returns the
+// commands that merge it and its location. It returns none when the account
+// is not stored: it was erased or a processor skipped it.
+func accountCommands(a *app.App, acc *models.Account, pulled map[string]bool) ([]database.Command, error) {
 	if a.State.Verbose {
 		a.Events.Dispatch(events.Debugf("pull", "Storing account: %s", acc.FullName.String))
 	}
-	return database.RunCommand(a.DB, "MergeAccountsDetailed",
-		acc.AccountId, acc.FirstName, acc.LastName, acc.FullName, acc.PhoneNumber, acc.Email, acc.CustomerId, acc.Notes,
-		acc.OriginalAddress, acc.CrmId, acc.AccountOwner, acc.DaysSinceLastCheckin, acc.LastCheckinDate,
-		acc.LastModifiedDate, acc.FollowUpDate, acc.CustomNumeric, acc.CustomText, acc.CustomNumeric2,
-		acc.CustomText2, acc.CustomNumeric3, acc.CustomText3, acc.CustomNumeric4, acc.CustomText4,
-		acc.CustomNumeric5, acc.CustomText5, acc.CustomNumeric6, acc.CustomText6, acc.CustomNumeric7,
-		acc.CustomText7, acc.CustomNumeric8, acc.CustomText8, acc.CustomNumeric9, acc.CustomText9,
-		acc.CustomNumeric10, acc.CustomText10, acc.CustomNumeric11, acc.CustomText11, acc.CustomNumeric12,
-		acc.CustomText12, acc.CustomNumeric13, acc.CustomText13, acc.CustomNumeric14, acc.CustomText14,
-		acc.CustomNumeric15, acc.CustomText15, acc.CustomNumeric16, acc.CustomText16, acc.CustomNumeric17,
-		acc.CustomText17, acc.CustomNumeric18, acc.CustomText18, acc.CustomNumeric19, acc.CustomText19,
-		acc.CustomNumeric20, acc.CustomText20, acc.CustomNumeric21, acc.CustomText21, acc.CustomNumeric22,
-		acc.CustomText22, acc.CustomNumeric23, acc.CustomText23, acc.CustomNumeric24, acc.CustomText24,
-		acc.CustomNumeric25, acc.CustomText25, acc.CustomNumeric26, acc.CustomText26, acc.CustomNumeric27,
-		acc.CustomText27, acc.CustomNumeric28, acc.CustomText28, acc.CustomNumeric29, acc.CustomText29,
-		acc.CustomNumeric30, acc.CustomText30, acc.CreatedAt, acc.UpdatedAt,
-	)
+	if a.IsAccountErased(int(acc.AccountId.Int64)) {
+		// Erased with 'privacy erase'; its data is not stored again.
+		return nil, nil
+	}
+	if skip, err := runPullProcessors(a, processor.EntityAccount, int(acc.AccountId.Int64), acc); skip || err != nil {
+		return nil, err
+	}
+	app.DropCustomFields(acc, pulled)
+	if err := a.KeepPushOnlyAccountFields(acc); err != nil {
+		return nil, err
+	}
+	if err := a.ApplyAccountMergeStrategies(acc); err != nil {
+		return nil, err
+	}
+	if acc.FollowUpDate.ValueOrZero() != "" {
+		if date, err := app.NormalizeFollowUpDate(acc.FollowUpDate.String); err == nil {
+			acc.FollowUpDate = models.DateFrom(date)
+		} else {
+			a.Events.Dispatch(events.Warningf("pull", "Account %d: follow-up date %v; stored as sent", acc.AccountId.Int64, err))
+		}
+	}
+	if len(a.EncryptedColumns()) > 0 {
+		// The caller keeps the account in the clear.
+		encrypted := *acc
+		if err := a.EncryptAccountColumns(&encrypted); err != nil {
+			return nil, err
+		}
+		acc = &encrypted
+	}
+	accountID := int(acc.AccountId.Int64)
+	commands := []database.Command{
+		{Name: "MergeAccountsDetailed", Args: []any{
+			acc.AccountId, acc.FirstName, acc.LastName, acc.FullName, acc.PhoneNumber, acc.Email, acc.CustomerId, acc.Notes,
+			acc.OriginalAddress, acc.CrmId, acc.AccountOwner, acc.DaysSinceLastCheckin, acc.LastCheckinDate,
+			acc.LastModifiedDate, acc.FollowUpDate, acc.CustomNumeric, acc.CustomText, acc.CustomNumeric2,
+			acc.CustomText2, acc.CustomNumeric3, acc.CustomText3, acc.CustomNumeric4, acc.CustomText4,
+			acc.CustomNumeric5, acc.CustomText5, acc.CustomNumeric6, acc.CustomText6, acc.CustomNumeric7,
+			acc.CustomText7, acc.CustomNumeric8, acc.CustomText8, acc.CustomNumeric9, acc.CustomText9,
+			acc.CustomNumeric10, acc.CustomText10, acc.CustomNumeric11, acc.CustomText11, acc.CustomNumeric12,
+			acc.CustomText12, acc.CustomNumeric13, acc.CustomText13, acc.CustomNumeric14, acc.CustomText14,
+			acc.CustomNumeric15, acc.CustomText15, acc.CustomNumeric16, acc.CustomText16, acc.CustomNumeric17,
+			acc.CustomText17, acc.CustomNumeric18, acc.CustomText18, acc.CustomNumeric19, acc.CustomText19,
+			acc.CustomNumeric20, acc.CustomText20, acc.CustomNumeric21, acc.CustomText21, acc.CustomNumeric22,
+			acc.CustomText22, acc.CustomNumeric23, acc.CustomText23, acc.CustomNumeric24, acc.CustomText24,
+			acc.CustomNumeric25, acc.CustomText25, acc.CustomNumeric26, acc.CustomText26, acc.CustomNumeric27,
+			acc.CustomText27, acc.CustomNumeric28, acc.CustomText28, acc.CustomNumeric29, acc.CustomText29,
+			acc.CustomNumeric30, acc.CustomText30, acc.CreatedAt, acc.UpdatedAt,
+		}},
+		{Name: "DeleteAccountLocations", Args: []any{accountID}},
+		database.DeleteStaleAccountCommand(accountID),
+	}
+	// The schema keeps one location per account, so only the first one is
+	// stored.
+	if len(acc.Locations) > 0 {
+		loc := acc.Locations[0]
+		commands = append(commands, database.Command{Name: "InsertAccountLocations", Args: []any{
+			accountID, loc.City, loc.Name, loc.Zipcode, loc.Long, loc.State, loc.Lat, loc.AddressLine1, loc.Location,
+			loc.IsApproximate.ValueOrZero(),
+		}})
+	}
+	return commands, nil
+}
+
+// StoreAccountLocations replaces the stored location of an account. The
+// schema keeps one location per account, so only the first one is stored.
+func StoreAccountLocations(a *app.App, accountID int, locations []models.Location) error {
+	commands := []database.Command{{Name: "DeleteAccountLocations", Args: []any{accountID}}}
+	if len(locations) > 0 {
+		loc := locations[0]
+		commands = append(commands, database.Command{Name: "InsertAccountLocations", Args: []any{
+			accountID, loc.City, loc.Name, loc.Zipcode, loc.Long, loc.State, loc.Lat, loc.AddressLine1, loc.Location,
+			loc.IsApproximate.ValueOrZero(),
+		}})
+	}
+	return database.WorkCommands(a.DB, "store.locations", commands)
 }
 
 func StoreCheckin(a *app.App, checkin models.Checkin) error {
+	record, ok, err := checkinRecord(a, checkin)
+	if !ok || err != nil {
+		return err
+	}
+	return database.MergeCheckin(a.DB, record)
+}
+
+// checkinRecord runs the pull processors on a check-in and returns the row
+// to store. ok is false when the check-in should not be stored: a processor
+// skipped it, its month is archived, or its account was erased.
+func checkinRecord(a *app.App, checkin models.Checkin) (record database.CheckinRecord, ok bool, err error) {
 	if a.State.Verbose {
 		a.Events.Dispatch(events.Debugf("pull", "Storing checkin: %d", checkin.CheckinId.Int64))
 	}
+	if checkin.LogDatetime.Valid && a.IsCheckinArchived(checkin.LogDatetime.String) {
+		// Restore the month with 'archive restore' to bring it back.
+		return record, false, nil
+	}
+	if checkin.AccountId.Valid && a.IsAccountErased(int(checkin.AccountId.Int64)) {
+		return record, false, nil
+	}
+	if skip, err := runPullProcessors(a, processor.EntityCheckin, int(checkin.CheckinId.Int64), &checkin); skip || err != nil {
+		return record, false, err
+	}
 
 	endpointType := "standard"
 	storedType := checkin.Type
@@ -478,20 +752,94 @@ func StoreCheckin(a *app.App, checkin models.Checkin) error {
 		}
 	}
 
-	return database.RunCommand(a.DB, "MergeAccountCheckins",
-		checkin.CheckinId, checkin.CrmId, checkin.AccountId, checkin.LogDatetime, storedType, storedComments,
-		extraFieldsStr, endpointType, checkin.CreatedBy,
-	)
+	return database.CheckinRecord{
+		CheckinId:    checkin.CheckinId.Int64,
+		CrmId:        checkin.CrmId.NullString,
+		AccountId:    checkin.AccountId.NullInt64,
+		LogDatetime:  checkin.LogDatetime.NullString,
+		Type:         storedType.NullString,
+		Comments:     storedComments.NullString,
+		ExtraFields:  extraFieldsStr,
+		EndpointType: endpointType,
+		CreatedBy:    checkin.CreatedBy.NullString,
+	}, true, nil
 }
 
 func StoreRoute(a *app.App, route models.Route) error {
+	commands, err := routeCommands(a, route)
+	if err != nil || len(commands) == 0 {
+		return err
+	}
+	return database.WorkCommands(a.DB, "store.route", commands)
+}
+
+// routeCommands prepares a pulled route for storing and returns the
+// commands that merge it and replace its waypoints. It returns none when a
+// processor skipped the route.
+func routeCommands(a *app.App, route models.Route) ([]database.Command, error) {
 	if a.State.Verbose {
 		a.Events.Dispatch(events.Debugf("pull", "Storing route: %s", route.Name.String))
 	}
-	return database.RunCommand(a.DB, "MergeRoutes",
+	if skip, err := runPullProcessors(a, processor.EntityRoute, int(route.RouteId.Int64), &route); skip || err != nil {
+		return nil, err
+	}
+	commands := []database.Command{{Name: "MergeRoutes", Args: []any{
 		route.RouteId, route.Name, route.RouteDate, route.Duration, route.StartAddress, route.DestinationAddress,
 		route.StartTime,
-	)
+	}}}
+	waypoints, err := routeWaypointCommands(a, route)
+	if err != nil {
+		return nil, err
+	}
+	return append(commands, waypoints...), nil
+}
+
+// routeWaypointCommands returns the commands that replace the stored
+// waypoints of a route. Appointment times are stored in UTC, reading those
+// without an offset in the display timezone. A time that moved by exactly
+// the DST offset since the last pull is reported, since that usually means
+// it was read in the wrong timezone rather than rescheduled.
+func routeWaypointCommands(a *app.App, route models.Route) ([]database.Command, error) {
+	routeID := int(route.RouteId.Int64)
+	loc := a.DisplayLocation()
+	previous, err := database.GetRouteAppointmentTimes(a.DB, routeID)
+	if err != nil {
+		return nil, err
+	}
+	commands := []database.Command{{Name: "DeleteRouteWaypoints", Args: []any{routeID}}}
+
+	for _, w := range route.Waypoints {
+		if !w.WaypointID.Valid {
+			continue
+		}
+		id := int(w.WaypointID.Int64)
+		apptTime := w.ApptTime
+		if w.ApptTime.String != "" {
+			stored, problem, err := app.NormalizeApptTime(w.ApptTime.String, route.RouteDate.ValueOrZero(), loc)
+			if err != nil {
+				a.Events.Dispatch(events.Warningf("pull", "Waypoint %d on route %d: %v; stored as sent", id, routeID, err))
+			} else {
+				if problem != "" {
+					a.Events.Dispatch(events.Warningf("pull", "Appointment time %q at waypoint %d on route %d %s", w.ApptTime.String, id, routeID, problem))
+				}
+				if old, ok := previous[id]; ok {
+					before, _, beforeErr := app.ParseApptTime(old, "", loc)
+					after, _, _ := app.ParseApptTime(stored, "", loc)
+					if beforeErr == nil && app.DSTShift(before, after, loc) {
+						a.Events.Dispatch(events.Warningf("pull", "Appointment at waypoint %d on route %d moved from %s to %s, exactly the DST offset in %s; check the timezone it was entered in",
+							id, routeID, before.In(loc).Format("2006-01-02 15:04 MST"), after.In(loc).Format("2006-01-02 15:04 MST"), loc))
+					}
+				}
+				apptTime = null.StringFrom(stored)
+			}
+		}
+		commands = append(commands, database.Command{Name: "InsertRouteWaypoints", Args: []any{
+			w.WaypointID, routeID, w.Name, w.Address, w.Suite, w.City, w.State, w.Zipcode,
+			w.Location, w.Lat, w.Long, w.LayoverMinutes, w.Position, w.CompleteAddress,
+			w.LocationID, w.CustomerID, apptTime, w.Type, w.PlaceID,
+		}})
+	}
+	return commands, nil
 }
 
 func StoreProfile(a *app.App, profile *models.UserProfile) error {
@@ -507,60 +855,214 @@ func StoreProfile(a *app.App, profile *models.UserProfile) error {
 	}
 	crmEditableFieldsListStr := strings.Join(crmFields, ",")
 
-	err := database.RunCommand(a.DB, "MergeUserProfiles",
-		profile.ProfileId, profile.Email, profile.FirstName, profile.LastName, profile.IsManager,
-		profile.IsHideReferralIOSBanner, profile.MarkerIcon, profile.Manager, crmEditableFieldsListStr,
-		profile.CRMBaseURL, profile.CRMType, profile.ReferralURL, profile.MapStartZoom, profile.MapStart,
-		profile.IsUserCanEdit, profile.IsUserCanDeleteCheckins, profile.IsUserCanAddNewTextValues,
-		profile.HasData, profile.DefaultApptLength, profile.Completed, profile.TrialDaysLeft,
-		profile.Company.Id, profile.Company.Name, profile.Company.ShortName,
-	)
+	// The profile and the configuration rows naming it are written together.
+	err := database.Work(a.DB, "store.profile", func(u *database.UnitOfWork) error {
+		_, err := u.Exec("MergeUserProfiles",
+			profile.ProfileId, profile.Email, profile.FirstName, profile.LastName, profile.IsManager,
+			profile.IsHideReferralIOSBanner, profile.MarkerIcon, profile.Manager, crmEditableFieldsListStr,
+			profile.CRMBaseURL, profile.CRMType, profile.ReferralURL, profile.MapStartZoom, profile.MapStart,
+			profile.IsUserCanEdit, profile.IsUserCanDeleteCheckins, profile.IsUserCanAddNewTextValues,
+			profile.HasData, profile.DefaultApptLength, profile.Completed, profile.TrialDaysLeft,
+			profile.Company.Id, profile.Company.Name, profile.Company.ShortName,
+		)
+		if err != nil {
+			return err
+		}
+		for _, setting := range [][2]string{
+			{"ApiProfileId", fmt.Sprintf("%d", profile.ProfileId.Int64)},
+			{"ApiProfileName", fmt.Sprintf("%s %s", profile.FirstName.String, profile.LastName.String)},
+			{"CompanyId", fmt.Sprintf("%d", profile.Company.Id.Int64)},
+			{"CompanyName", profile.Company.Name.String},
+			{"SqlDbUserName", a.DB.GetUsername()},
+		} {
+			if _, err := u.Exec("UpdateConfiguration", setting[1], setting[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	// Update Configurations table
-	if err := database.UpdateConfiguration(a.DB, "ApiProfileId", fmt.Sprintf("%d", profile.ProfileId.Int64)); err != nil {
+	if err := StoreDatasets(a, profile); err != nil {
 		return err
 	}
-	if err := database.UpdateConfiguration(a.DB, "ApiProfileName", fmt.Sprintf("%s %s", profile.FirstName.String, profile.LastName.String)); err != nil {
-		return err
-	}
-	if err := database.UpdateConfiguration(a.DB, "CompanyId", fmt.Sprintf("%d", profile.Company.Id.Int64)); err != nil {
-		return err
-	}
-	if err := database.UpdateConfiguration(a.DB, "CompanyName", profile.Company.Name.String); err != nil {
-		return err
+	_, err = a.ApplyProfileSchema(profile)
+	return err
+}
+
+// DetectFieldSchema reads the profile and maps its data fields to Accounts
+// columns without storing the data sets and their values, so columns are
+// labeled as soon as the API connects. A full profile pull does the same.
+func DetectFieldSchema(a *app.App) (app.FieldSchema, error) {
+	if a.API == nil {
+		return app.FieldSchema{}, fmt.Errorf("API is not configured")
 	}
-	if err := database.UpdateConfiguration(a.DB, "SqlDbUserName", a.DB.GetUsername()); err != nil {
-		return err
+	resp, err := a.API.GetUserProfile()
+	if err != nil {
+		return app.FieldSchema{}, fmt.Errorf("error reading user profile: %w", err)
 	}
+	return a.ApplyProfileSchema(&resp.Data)
+}
 
-	if err := database.RunCommand(a.DB, "DeleteDataSetValues", profile.ProfileId); err != nil {
+// StoreDatasets updates the profile's data fields and their values in
+// place. Values are matched on data set and value, so a re-pull only
+// rewrites what changed. When the profile lists only the first part of a
+// large picklist, the rest is fetched page by page and stored as each page
+// arrives. Values and data fields the API no longer returns are deleted at
+// the end; a data set whose pages could not all be read keeps its old
+// values. Each data set, each fetched page, and the final deletes are one
+// unit of work, so no transaction is held open while a page downloads.
+func StoreDatasets(a *app.App, profile *models.UserProfile) error {
+	profileID := profile.ProfileId.Int64
+	storedSets, err := database.GetDataSetNames(a.DB, profileID)
+	if err != nil {
 		return err
 	}
-	if err := database.RunCommand(a.DB, "DeleteDataSets", profile.ProfileId); err != nil {
+	stale, err := database.GetDataSetValueKeys(a.DB, profileID)
+	if err != nil {
 		return err
 	}
 
 	for _, datafield := range profile.Datafields {
-		err := database.RunCommand(a.DB, "InsertDataSets",
-			datafield.Name, profile.ProfileId, datafield.Filterable, datafield.Label, datafield.Position, datafield.Type,
+		name := datafield.Name.String
+		command := "InsertDataSets"
+		args := []any{datafield.Name, profile.ProfileId, datafield.Filterable, datafield.Label, datafield.Position, datafield.Type,
 			datafield.HasData, datafield.IsUserCanAddNewTextValues, datafield.RawMin, datafield.Min, datafield.Max,
-			datafield.RawMax, datafield.AccountField,
-		)
+			datafield.RawMax, datafield.AccountField}
+		if storedSets[name] {
+			command = "UpdateDataSet"
+			args = append(args[2:], datafield.Name, profile.ProfileId)
+		}
+
+		existing := stale[name]
+		seen := make(map[string]bool)
+		err := storeDataSetValues(a, profile.ProfileId, datafield, datafield.Values, existing, seen, func(u *database.UnitOfWork) error {
+			_, err := u.Exec(command, args...)
+			return err
+		})
 		if err != nil {
 			return err
 		}
-		for _, value := range datafield.Values {
-			err := database.RunCommand(a.DB, "InsertDataSetValues",
-				datafield.Name, profile.ProfileId, value.Text, value.Value, datafield.Position,
-			)
-			if err != nil {
+		delete(storedSets, name)
+		delete(stale, name)
+		if datafield.ValuesCount == nil || int(datafield.ValuesCount.Int64) <= len(datafield.Values) {
+			stale[name] = existing
+			continue
+		}
+		if a.API == nil {
+			a.Events.Dispatch(events.Warningf("pull", "Kept stored values for data set %s: API is not configured", name))
+			continue
+		}
+		fetched, err := a.API.GetDataFieldValues(name, func(values []models.FieldValue) error {
+			return storeDataSetValues(a, profile.ProfileId, datafield, values, existing, seen, nil)
+		})
+		if err != nil {
+			a.Events.Dispatch(events.Warningf("pull", "Kept stored values for data set %s: %v", name, err))
+			continue
+		}
+		a.Events.Dispatch(events.Debugf("pull", "Fetched %d values for data set %s", fetched, name))
+		stale[name] = existing
+	}
+
+	return database.Work(a.DB, "store.datasets", func(u *database.UnitOfWork) error {
+		for _, values := range stale {
+			for _, id := range values {
+				if _, err := u.Exec("DeleteDataSetValue", id); err != nil {
+					return err
+				}
+			}
+		}
+		for name := range storedSets {
+			if _, err := u.Exec("DeleteDataSet", name, profile.ProfileId); err != nil {
 				return err
 			}
 		}
-	}
+		return nil
+	})
+}
 
+// storeDataSetValues stores one batch of a data set's values in one unit of
+// work, after first when it is set. Stored rows found in existing are
+// updated, and once the unit commits they are removed from it, so that what
+// is left afterwards is stale. Values already in seen were stored from an
+// earlier batch of the same pull and are skipped. The maps are only changed
+// after the commit, so a unit retried after a deadlock starts over cleanly.
+func storeDataSetValues(a *app.App, profileID null.Int, datafield models.DataField, values []models.FieldValue, existing map[string]int, seen map[string]bool, first func(u *database.UnitOfWork) error) error {
+	var stored []string
+	err := database.Work(a.DB, "store.datasets", func(u *database.UnitOfWork) error {
+		stored = stored[:0]
+		if first != nil {
+			if err := first(u); err != nil {
+				return err
+			}
+		}
+		batch := make(map[string]bool)
+		for _, value := range values {
+			key, text := dataSetValueText(value.Value)
+			if seen[key] || batch[key] {
+				continue
+			}
+			batch[key] = true
+			stored = append(stored, key)
+			if id, ok := existing[key]; ok {
+				if _, err := u.Exec("UpdateDataSetValue", value.Text, datafield.Position, id); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err := u.Exec("InsertDataSetValues", datafield.Name, profileID, value.Text, text, datafield.Position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stored {
+		seen[key] = true
+		delete(existing, key)
+	}
 	return nil
 }
+
+// dataSetValueText returns the text a picklist value is stored and matched
+// as. JSON numbers are written without a trailing ".0" so they compare equal
+// to the text read back.
+func dataSetValueText(value interface{}) (string, any) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, v
+	case float64:
+		text := strconv.FormatFloat(v, 'f', -1, 64)
+		return text, text
+	default:
+		text := fmt.Sprint(v)
+		return text, text
+	}
+}
+
+// runPullProcessors applies the configured entity processors before a pulled
+// entity is stored. skip is true when a processor vetoed the entity, which is
+// then left out of the local database.
+func runPullProcessors(a *app.App, entityType string, id int, model interface{}) (skip bool, err error) {
+	if err := a.ProcessEntity(processor.StagePull, entityType, id, model); err != nil {
+		if veto, ok := processor.IsVeto(err); ok {
+			a.Events.Dispatch(events.Infof("pull", "Skipping %s %d: %v", entityType, id, veto))
+			return true, nil
+		}
+		return true, err
+	}
+	return false, nil
+}
+
+// joinPullErrors returns the error of a pull in which some items failed.
+// It is an errs.ErrPartial that keeps each failure, so errors.Is still
+// finds a rejected key or rate limit.
+func joinPullErrors(what string, failures []error) error {
+	return errs.Partial(fmt.Sprintf("encountered errors during %s pull", what), failures)
+}
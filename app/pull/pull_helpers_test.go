@@ -8,13 +8,14 @@ import (
 	"badgermaps/app/state"
 	"badgermaps/database"
 	"badgermaps/events"
-	"database/sql"
 	"encoding/json"
+	"fmt"
 	"github.com/guregu/null/v6"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -138,6 +139,52 @@ func TestPullAccount(t *testing.T) {
 	}
 }
 
+// TestStoredAccountRoundTrip stores the golden account and reads it back.
+// Apart from locations and the row timestamps, which live elsewhere or are
+// set by the database, the stored account must encode to the JSON that was
+// pulled.
+func TestStoredAccountRoundTrip(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+
+	golden, err := os.ReadFile(filepath.Join("..", "..", "api", "models", "testdata", "account.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pulled models.Account
+	if err := json.Unmarshal(golden, &pulled); err != nil {
+		t.Fatal(err)
+	}
+	if err := pull.StoreAccountDetailed(testApp, &pulled); err != nil {
+		t.Fatalf("StoreAccountDetailed: %v", err)
+	}
+	stored, err := database.GetAccountByID(testApp.DB, int(pulled.AccountId.Int64))
+	if err != nil {
+		t.Fatalf("GetAccountByID: %v", err)
+	}
+
+	comparable := func(acc models.Account) map[string]any {
+		acc.Locations = nil
+		acc.CreatedAt, acc.UpdatedAt = models.Date{}, models.Date{}
+		data, err := json.Marshal(acc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatal(err)
+		}
+		return fields
+	}
+	want, got := comparable(pulled), comparable(*stored)
+	for key, value := range want {
+		if !reflect.DeepEqual(got[key], value) {
+			t.Errorf("%s: stored %v, pulled %v", key, got[key], value)
+		}
+	}
+}
+
 func TestStoreAccountDetailed(t *testing.T) {
 	// Setup a test app with an in-memory database
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
@@ -147,7 +194,7 @@ func TestStoreAccountDetailed(t *testing.T) {
 	// Create a mock account object
 	mockAccount := &models.Account{
 		AccountId: null.NewInt(456, true),
-		FirstName: &null.String{NullString: sql.NullString{String: "Jane", Valid: true}},
+		FirstName: null.NewString("Jane", true),
 		LastName:  null.NewString("Smith", true),
 		FullName:  null.NewString("Jane Smith", true),
 	}
@@ -172,3 +219,45 @@ func TestStoreAccountDetailed(t *testing.T) {
 		t.Errorf("Expected lastName to be 'Smith', got '%s'", lastName)
 	}
 }
+
+func TestPullGroupCheckinsWritesInBatches(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/customers/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1}, {"id": 2}, {"id": 3}]`))
+	})
+	mux.HandleFunc("/appointments/", func(w http.ResponseWriter, r *http.Request) {
+		var customer int
+		fmt.Sscan(r.URL.Query().Get("customer_id"), &customer)
+		var checkins []map[string]interface{}
+		for i := 1; i <= 3; i++ {
+			checkins = append(checkins, map[string]interface{}{
+				"id":           customer*100 + i,
+				"customer":     customer,
+				"log_datetime": "2024-05-01T10:00:00Z",
+				"type":         "Drop-in",
+				"extra_fields": map[string]string{"Log Type": "Phone Call"},
+			})
+		}
+		json.NewEncoder(w).Encode(checkins)
+	})
+	testApp, teardown := setupTestApp(t, mux)
+	defer teardown()
+	testApp.Config.BatchSize = 2
+	testApp.MaxConcurrentRequests = 2
+	for id := 1; id <= 3; id++ {
+		if _, err := testApp.DB.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName) VALUES (?, 'Acme')`, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pull.PullGroupCheckins(testApp); err != nil {
+		t.Fatalf("PullGroupCheckins: %v", err)
+	}
+
+	var count, custom int
+	testApp.DB.GetDB().QueryRow(`SELECT COUNT(*) FROM AccountCheckins`).Scan(&count)
+	testApp.DB.GetDB().QueryRow(`SELECT COUNT(*) FROM AccountCheckins WHERE Type = 'Phone Call' AND EndpointType = 'custom'`).Scan(&custom)
+	if count != 9 || custom != 9 {
+		t.Errorf("stored %d check-ins (%d custom), want 9", count, custom)
+	}
+}
@@ -0,0 +1,118 @@
+package pull
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+)
+
+// PullGroupLocations refreshes AccountLocations from the customers list
+// without fetching each account's details. Only accounts that already exist
+// locally are updated; run a full account pull to add new accounts.
+func PullGroupLocations(a *app.App) (err error) {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
+	release, err := a.AcquireSyncLock("pull locations")
+	if err != nil {
+		return err
+	}
+	defer release()
+	a.Events.Dispatch(events.Event{Type: "pull.group.start", Source: "locations"})
+
+	defer func() {
+		if err != nil {
+			a.Events.Dispatch(events.Event{Type: "pull.group.error", Source: "locations", Payload: events.ErrorPayload{Error: err}})
+		}
+	}()
+
+	accountsResp, err := a.API.GetAccounts()
+	if err != nil {
+		err = fmt.Errorf("error getting accounts: %w", err)
+		a.Events.Dispatch(events.Event{Type: "pull.error", Source: "locations", Payload: events.ErrorPayload{Error: err}})
+		return err
+	}
+	localIDs, err := database.GetAllAccountIDs(a.DB)
+	if err != nil {
+		return fmt.Errorf("error reading local accounts: %w", err)
+	}
+	local := make(map[int]bool, len(localIDs))
+	for _, id := range localIDs {
+		local[id] = true
+	}
+
+	accounts := accountsResp.Data[:0:0]
+	for _, account := range accountsResp.Data {
+		if local[int(account.AccountId.Int64)] {
+			accounts = append(accounts, account)
+		}
+	}
+	total := len(accounts)
+	a.Events.Dispatch(events.Event{Type: "pull.ids_fetched", Source: "locations", Payload: events.ResourceIDsFetchedPayload{Count: total}})
+	progress := a.StartProgress("pull", "locations", total)
+	defer progress.Finish()
+
+	var pullErrors []error
+	successCount := 0
+	for _, account := range accounts {
+		accountID := int(account.AccountId.Int64)
+		if storeErr := StoreAccountLocations(a, accountID, account.Locations); storeErr != nil {
+			storeErr = fmt.Errorf("error storing locations for account %d: %w", accountID, storeErr)
+			a.Events.Dispatch(events.Event{Type: "pull.error", Source: "locations", Payload: events.ErrorPayload{Error: storeErr, ResourceID: accountID}})
+			pullErrors = append(pullErrors, storeErr)
+			progress.Failed()
+		} else {
+			a.Events.Dispatch(events.Event{Type: "pull.store.success", Source: "locations", Payload: events.StoreSuccessPayload{Data: account.Locations}})
+			successCount++
+			progress.Succeeded()
+		}
+	}
+
+	if len(pullErrors) > 0 {
+		err = joinPullErrors("location", pullErrors)
+	}
+
+	success := err == nil
+	a.Events.Dispatch(events.Event{Type: "pull.group.complete", Source: "locations", Payload: events.CompletionPayload{Success: success, Error: err, Count: successCount}})
+	if success {
+		a.Events.Dispatch(events.Infof("pull", "Refreshed locations for %d accounts", successCount))
+	} else {
+		a.Events.Dispatch(events.Warningf("pull", "Finished refreshing locations with %d success(es) and %d error(s)", successCount, len(pullErrors)))
+	}
+	return err
+}
+
+// PullDatasets refreshes the profile data fields and their values without
+// touching the rest of the stored profile.
+func PullDatasets(a *app.App) (count int, err error) {
+	if err := a.CheckSchema(); err != nil {
+		return 0, err
+	}
+	release, err := a.AcquireSyncLock("pull datasets")
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	a.Events.Dispatch(events.Event{Type: "pull.group.start", Source: "datasets"})
+
+	defer func() {
+		if err != nil {
+			a.Events.Dispatch(events.Event{Type: "pull.group.error", Source: "datasets", Payload: events.ErrorPayload{Error: err}})
+		}
+		a.Events.Dispatch(events.Event{Type: "pull.group.complete", Source: "datasets", Payload: events.CompletionPayload{Success: err == nil, Error: err, Count: count}})
+	}()
+
+	profileResp, err := a.API.GetUserProfile()
+	if err != nil {
+		return 0, fmt.Errorf("error pulling user profile: %w", err)
+	}
+	profile := &profileResp.Data
+	if err := StoreDatasets(a, profile); err != nil {
+		return 0, fmt.Errorf("error storing datasets: %w", err)
+	}
+
+	count = len(profile.Datafields)
+	a.Events.Dispatch(events.Infof("pull", "Refreshed %d datasets", count))
+	return count, nil
+}
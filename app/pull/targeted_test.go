@@ -0,0 +1,176 @@
+package pull_test
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestPullGroupLocationsUpdatesOnlyLocalAccounts(t *testing.T) {
+	customers := []map[string]interface{}{
+		{"id": 1, "last_name": "Local", "locations": []map[string]interface{}{
+			{"id": 10, "city": "Denver", "lat": 39.7, "long": -104.9, "is_approximate": true},
+		}},
+		{"id": 2, "last_name": "Remote only", "locations": []map[string]interface{}{{"id": 20, "city": "Austin"}}},
+	}
+	var detailRequests int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/customers/") {
+			json.NewEncoder(w).Encode(customers)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/customers/") {
+			detailRequests++
+		}
+		w.Write([]byte("{}"))
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+
+	acc := &models.Account{AccountId: null.IntFrom(1), LastName: null.StringFrom("Local")}
+	if err := pull.StoreAccountDetailed(testApp, acc); err != nil {
+		t.Fatalf("StoreAccountDetailed returned error: %v", err)
+	}
+
+	if err := pull.PullGroupLocations(testApp); err != nil {
+		t.Fatalf("PullGroupLocations returned error: %v", err)
+	}
+	if detailRequests != 0 {
+		t.Errorf("expected no per-account requests, got %d", detailRequests)
+	}
+
+	var city string
+	var approximate bool
+	row := testApp.DB.GetDB().QueryRow("SELECT City, IsApproximate FROM AccountLocations WHERE AccountId = 1")
+	if err := row.Scan(&city, &approximate); err != nil {
+		t.Fatalf("failed to read stored location: %v", err)
+	}
+	if city != "Denver" || !approximate {
+		t.Errorf("unexpected stored location: city=%q approximate=%v", city, approximate)
+	}
+	var count int
+	testApp.DB.GetDB().QueryRow("SELECT COUNT(*) FROM AccountLocations").Scan(&count)
+	if count != 1 {
+		t.Errorf("expected only the local account's location to be stored, got %d rows", count)
+	}
+
+	// A second refresh replaces rather than duplicates the location.
+	customers[0]["locations"] = []map[string]interface{}{{"id": 10, "city": "Boulder"}}
+	if err := pull.PullGroupLocations(testApp); err != nil {
+		t.Fatalf("second PullGroupLocations returned error: %v", err)
+	}
+	testApp.DB.GetDB().QueryRow("SELECT City FROM AccountLocations WHERE AccountId = 1").Scan(&city)
+	if city != "Boulder" {
+		t.Errorf("expected refreshed city Boulder, got %q", city)
+	}
+}
+
+func TestPullDatasetsReplacesDataFields(t *testing.T) {
+	profile := map[string]interface{}{
+		"id":    7,
+		"email": "rep@example.com",
+		"datafields": []map[string]interface{}{
+			{"name": "segment", "label": "Segment", "account_field": "custom_text", "values": []map[string]interface{}{
+				{"text": "Gold", "value": "1"},
+				{"text": "Silver", "value": "2"},
+			}},
+		},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profile)
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+
+	count, err := pull.PullDatasets(testApp)
+	if err != nil {
+		t.Fatalf("PullDatasets returned error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 dataset, got %d", count)
+	}
+	var sets, values int
+	testApp.DB.GetDB().QueryRow("SELECT COUNT(*) FROM DataSets").Scan(&sets)
+	testApp.DB.GetDB().QueryRow("SELECT COUNT(*) FROM DataSetValues").Scan(&values)
+	if sets != 1 || values != 2 {
+		t.Errorf("expected 1 dataset with 2 values, got %d and %d", sets, values)
+	}
+	var profiles int
+	testApp.DB.GetDB().QueryRow("SELECT COUNT(*) FROM UserProfiles").Scan(&profiles)
+	if profiles != 0 {
+		t.Errorf("expected the profile row to be left alone, got %d profiles", profiles)
+	}
+}
+
+func TestPullDatasetsPagesLargePicklists(t *testing.T) {
+	dropTier := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/profiles/datafields/territory/values/"):
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"count": 3, "next": "http://" + r.Host + "/profiles/datafields/territory/values/?page=2",
+					"results": []map[string]interface{}{{"text": "North", "value": "n"}, {"text": "South", "value": "s"}},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"count": 3, "next": nil,
+				"results": []map[string]interface{}{{"text": "East", "value": "e"}},
+			})
+		default:
+			values := []map[string]interface{}{{"text": "North", "value": "n"}}
+			datafields := []map[string]interface{}{
+				{"name": "territory", "label": "Territory", "values": values, "values_count": 3},
+				{"name": "tier", "label": "Tier", "values": []map[string]interface{}{{"text": "Gold", "value": 1}}},
+			}
+			if dropTier {
+				datafields = datafields[:1]
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 7, "datafields": datafields})
+		}
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+
+	if _, err := pull.PullDatasets(testApp); err != nil {
+		t.Fatalf("PullDatasets: %v", err)
+	}
+	sqlDB := testApp.DB.GetDB()
+	var firstID int
+	sqlDB.QueryRow("SELECT DataSetValueId FROM DataSetValues WHERE DataSetName = 'territory' AND Value = 'n'").Scan(&firstID)
+	var territory, tier int
+	sqlDB.QueryRow("SELECT COUNT(*) FROM DataSetValues WHERE DataSetName = 'territory'").Scan(&territory)
+	sqlDB.QueryRow("SELECT COUNT(*) FROM DataSetValues WHERE DataSetName = 'tier' AND Value = '1'").Scan(&tier)
+	if territory != 3 || tier != 1 {
+		t.Fatalf("expected 3 territory values and tier value '1', got %d and %d", territory, tier)
+	}
+
+	// A second pull updates values in place and drops the removed data set.
+	dropTier = true
+	if _, err := pull.PullDatasets(testApp); err != nil {
+		t.Fatalf("second PullDatasets: %v", err)
+	}
+	var sameID, sets, values int
+	sqlDB.QueryRow("SELECT DataSetValueId FROM DataSetValues WHERE DataSetName = 'territory' AND Value = 'n'").Scan(&sameID)
+	sqlDB.QueryRow("SELECT COUNT(*) FROM DataSets").Scan(&sets)
+	sqlDB.QueryRow("SELECT COUNT(*) FROM DataSetValues").Scan(&values)
+	if sameID != firstID || sets != 1 || values != 3 {
+		t.Fatalf("after re-pull: id %d (was %d), %d sets, %d values", sameID, firstID, sets, values)
+	}
+
+	page, err := database.GetDataSetValuesPage(testApp.DB, "territory", "th", 1, 0)
+	if err != nil || len(page) != 1 || page[0].Text != "North" {
+		t.Fatalf("GetDataSetValuesPage = %+v, %v", page, err)
+	}
+}
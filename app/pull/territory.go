@@ -0,0 +1,67 @@
+package pull
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+)
+
+// pullAccountIDs lists the accounts a group pull covers. Without a pull
+// radius that is every account. With one, only accounts whose location in
+// the customers list lies inside it are kept, so a regional manager syncs
+// just their territory; accounts without coordinates are left out.
+func pullAccountIDs(a *app.App, source string) ([]int, error) {
+	radius, limited, err := a.PullRadius()
+	if err != nil {
+		return nil, err
+	}
+	if !limited {
+		resp, err := a.API.GetAccountIDs()
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	}
+
+	resp, err := a.API.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+	inside, err := territoryAccounts(a, radius, resp.Data)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(inside))
+	for _, account := range resp.Data {
+		if id := int(account.AccountId.Int64); inside[id] {
+			ids = append(ids, id)
+		}
+	}
+	a.Events.Dispatch(events.Infof("pull", "Pulling %s for %d of %d accounts within %s", source, len(ids), len(resp.Data), radius))
+	return ids, nil
+}
+
+// territoryAccounts resolves radius, using the customers list for addresses
+// that are not stored yet, and returns the accounts inside it.
+func territoryAccounts(a *app.App, radius app.RadiusFilter, accounts []models.Account) (map[int]bool, error) {
+	var locations []models.Location
+	for _, account := range accounts {
+		locations = append(locations, account.Locations...)
+	}
+	radius, err := a.ResolveRadiusFilter(radius, locations)
+	if err != nil {
+		return nil, fmt.Errorf("pull_radius: %w", err)
+	}
+	inside := make(map[int]bool)
+	for _, account := range accounts {
+		for _, loc := range account.Locations {
+			if loc.Lat.Valid && loc.Long.Valid && radius.Contains(database.GeoPoint{Lat: loc.Lat.Float64, Lon: loc.Long.Float64}) {
+				inside[int(account.AccountId.Int64)] = true
+				break
+			}
+		}
+	}
+	return inside, nil
+}
@@ -0,0 +1,73 @@
+package pull_test
+
+import (
+	"badgermaps/app/pull"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPullGroupAccountsWithinRadius(t *testing.T) {
+	customers := []map[string]interface{}{
+		{"id": 1, "last_name": "Denver", "locations": []map[string]interface{}{
+			{"id": 10, "city": "Denver", "zipcode": "80202", "lat": 39.7439, "long": -104.9907},
+		}},
+		{"id": 2, "last_name": "Boulder", "locations": []map[string]interface{}{
+			{"id": 20, "city": "Boulder", "zipcode": "80302", "lat": 40.0150, "long": -105.2705},
+		}},
+		{"id": 3, "last_name": "Austin", "locations": []map[string]interface{}{
+			{"id": 30, "city": "Austin", "zipcode": "78701", "lat": 30.2672, "long": -97.7431},
+		}},
+		{"id": 4, "last_name": "Unmapped", "locations": []map[string]interface{}{{"id": 40, "city": "Denver"}}},
+	}
+	var mu sync.Mutex
+	var fetched []int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/customers/") {
+			json.NewEncoder(w).Encode(customers)
+			return
+		}
+		var id int
+		if _, err := fmt.Sscanf(r.URL.Path[strings.LastIndex(strings.TrimSuffix(r.URL.Path, "/"), "/")+1:], "%d", &id); err == nil {
+			mu.Lock()
+			fetched = append(fetched, id)
+			mu.Unlock()
+			fmt.Fprintf(w, `{"id": %d, "last_name": "Account %d"}`, id, id)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+	testApp.MaxConcurrentRequests = 2
+
+	// An address is resolved from the customers list when nothing is stored.
+	testApp.Config.PullRadius = "50 km of Denver"
+	if err := pull.PullGroupAccounts(testApp, 0); err != nil {
+		t.Fatalf("PullGroupAccounts returned error: %v", err)
+	}
+	sort.Ints(fetched)
+	if fmt.Sprint(fetched) != "[1 2]" {
+		t.Errorf("fetched accounts %v, want [1 2]", fetched)
+	}
+
+	// --within overrides the configured radius.
+	fetched = nil
+	testApp.State.PullRadius = "10 km of 30.2672,-97.7431"
+	if err := pull.PullGroupAccounts(testApp, 0); err != nil {
+		t.Fatalf("PullGroupAccounts returned error: %v", err)
+	}
+	if fmt.Sprint(fetched) != "[3]" {
+		t.Errorf("fetched accounts %v, want [3]", fetched)
+	}
+
+	testApp.State.PullRadius = "50 km of Nowhere"
+	if err := pull.PullGroupAccounts(testApp, 0); err == nil {
+		t.Error("expected an error for an address that matches no location")
+	}
+}
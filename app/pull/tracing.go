@@ -0,0 +1,36 @@
+package pull
+
+import (
+	"badgermaps/api"
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/telemetry"
+	"context"
+)
+
+// fetchAccount gets one account from the API in an api.GetAccountDetailed
+// span under ctx.
+func fetchAccount(ctx context.Context, a *app.App, accountID int) (*api.APIResponse[models.Account], error) {
+	_, span := telemetry.Start(ctx, "api.GetAccountDetailed", telemetry.EntityID.Int(accountID))
+	resp, err := a.API.GetAccountDetailed(accountID)
+	telemetry.End(span, err)
+	return resp, err
+}
+
+// storeAccount merges a pulled account in a db.StoreAccountDetailed span
+// under ctx, keeping the custom fields in pulled.
+func storeAccount(ctx context.Context, a *app.App, account *models.Account, pulled map[string]bool) error {
+	_, span := telemetry.Start(ctx, "db.StoreAccountDetailed", telemetry.EntityID.Int64(account.AccountId.Int64))
+	err := storeAccountDetailed(a, account, pulled)
+	telemetry.End(span, err)
+	return err
+}
+
+// fetchAccountCheckins gets the check-ins of one account in an
+// api.GetCheckinsForAccount span under ctx.
+func fetchAccountCheckins(ctx context.Context, a *app.App, accountID int) (*api.APIResponse[[]models.Checkin], error) {
+	_, span := telemetry.Start(ctx, "api.GetCheckinsForAccount", telemetry.EntityID.Int(accountID))
+	resp, err := a.API.GetCheckinsForAccount(accountID)
+	telemetry.End(span, err)
+	return resp, err
+}
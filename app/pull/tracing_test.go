@@ -0,0 +1,49 @@
+package pull_test
+
+import (
+	"badgermaps/app/pull"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPullAccountSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 123, "last_name": "Doe", "full_name": "John Doe"})
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+
+	if _, err := pull.PullAccount(testApp, 123); err != nil {
+		t.Fatalf("PullAccount: %v", err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	root, ok := spans["pull.account"]
+	if !ok {
+		t.Fatalf("no pull.account span among %v", spans)
+	}
+	for _, name := range []string{"api.GetAccountDetailed", "db.StoreAccountDetailed"} {
+		child, ok := spans[name]
+		if !ok {
+			t.Errorf("no %s span", name)
+			continue
+		}
+		if child.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("%s is not a child of pull.account", name)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package pull
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/events"
+	"badgermaps/telemetry"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// StoreWebhookAccounts fetches the accounts named by a batch of account
+// webhooks and stores them in one transaction. An account that cannot be
+// fetched is stored from its webhook payload instead.
+func StoreWebhookAccounts(a *app.App, payloads map[int]*models.Account) (err error) {
+	ctx, span := telemetry.Start(context.Background(), "webhook.accounts", telemetry.Count.Int(len(payloads)))
+	defer func() { telemetry.End(span, err) }()
+
+	ids := make([]int, 0, len(payloads))
+	for id := range payloads {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	accounts := make([]*models.Account, 0, len(ids))
+	for _, id := range ids {
+		account := payloads[id]
+		if a.API != nil {
+			resp, fetchErr := fetchAccount(ctx, a, id)
+			if fetchErr == nil {
+				account = &resp.Data
+			} else {
+				a.Events.Dispatch(events.Warningf("server", "Could not fetch account %d named by a webhook; storing the webhook payload: %v", id, fetchErr))
+			}
+		}
+		accounts = append(accounts, account)
+	}
+
+	pulled, err := a.PulledCustomColumns()
+	if err != nil {
+		return fmt.Errorf("error reading pulled custom fields: %w", err)
+	}
+	if err := StoreAccountsDetailed(a, accounts, pulled); err != nil {
+		return fmt.Errorf("error storing %d webhook account(s): %w", len(accounts), err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package pull_test
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app/pull"
+	"net/http"
+	"testing"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestStoreWebhookAccounts(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/customers/7/" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": 7, "full_name": "Fetched", "locations": [{"id": 70, "city": "Reno"}]}`))
+			return
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	testApp, teardown := setupTestApp(t, handler)
+	defer teardown()
+
+	err := pull.StoreWebhookAccounts(testApp, map[int]*models.Account{
+		7: {AccountId: null.IntFrom(7), FullName: null.StringFrom("From webhook")},
+		8: {AccountId: null.IntFrom(8), FullName: null.StringFrom("Payload only")},
+	})
+	if err != nil {
+		t.Fatalf("StoreWebhookAccounts: %v", err)
+	}
+	for id, want := range map[int]string{7: "Fetched", 8: "Payload only"} {
+		var name string
+		if err := testApp.DB.GetDB().QueryRow("SELECT FullName FROM Accounts WHERE AccountId = ?", id).Scan(&name); err != nil {
+			t.Fatalf("account %d: %v", id, err)
+		}
+		if name != want {
+			t.Errorf("account %d FullName = %q, want %q", id, name, want)
+		}
+	}
+	var city string
+	if err := testApp.DB.GetDB().QueryRow("SELECT City FROM AccountLocations WHERE AccountId = 7").Scan(&city); err != nil || city != "Reno" {
+		t.Errorf("location of account 7 = %q, %v", city, err)
+	}
+}
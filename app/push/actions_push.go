@@ -1,10 +1,14 @@
 package push
 
 import (
+	"badgermaps/api"
 	"badgermaps/api/models"
 	"badgermaps/app"
 	"badgermaps/database"
+	"badgermaps/errs"
 	"badgermaps/events"
+	"badgermaps/telemetry"
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -12,14 +16,42 @@ import (
 
 // RunPushAccounts orchestrates pushing pending account changes to the API.
 func RunPushAccounts(a *app.App) error {
+	return RunPushAccountsSelected(a, PushSelection{})
+}
+
+// RunPushAccountsSelected pushes the pending account changes sel selects
+// and leaves the rest queued.
+func RunPushAccountsSelected(a *app.App, sel PushSelection) error {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
+	if err := checkWindowForPush(a, "accounts"); err != nil {
+		return err
+	}
+	release, err := a.AcquireSyncLock("push accounts")
+	if err != nil {
+		return err
+	}
+	defer release()
+	client, err := pushClient(a, "accounts")
+	if err != nil {
+		return err
+	}
+	ctx, span := telemetry.Start(context.Background(), "push.accounts")
+	defer span.End()
 	a.Events.Dispatch(events.Event{Type: "push.scan.start", Source: "accounts", Payload: events.PushScanStartPayload{}})
 	changes, err := database.GetPendingAccountChanges(a.DB)
 	if err != nil {
 		err = fmt.Errorf("error getting pending account changes: %w", err)
+		telemetry.End(span, err)
 		a.Events.Dispatch(events.Event{Type: "push.error", Source: "accounts", Payload: events.ErrorPayload{Error: err}})
 		return err
 	}
 
+	changes, queued := selectAccountChanges(changes, sel)
+	if len(queued) > 0 {
+		a.Events.Dispatch(events.Infof("push", "Leaving %d account change(s) queued for a later push.", len(queued)))
+	}
 	a.Events.Dispatch(events.Event{Type: "push.scan.complete", Source: "accounts", Payload: events.PushScanCompletePayload{Changes: changes}})
 	if len(changes) == 0 {
 		a.Events.Dispatch(events.Infof("push", "No pending account changes to push."))
@@ -27,54 +59,193 @@ func RunPushAccounts(a *app.App) error {
 		return nil
 	}
 
-	errorCount := 0
-	for _, change := range changes {
-		a.Events.Dispatch(events.Event{Type: "push.item.start", Source: "accounts", Payload: events.PushItemStartPayload{Change: change}})
-		database.UpdatePendingChangeStatus(a.DB, "AccountsPendingChanges", change.ChangeId, "processing")
+	throughput := a.PushThroughput("accounts")
+	limiter := a.PushLimiter("accounts")
+	progress := a.StartProgress("push", "accounts", len(changes))
+	defer progress.Finish()
+	errorCount := pushLanes(throughput.Workers, accountLanes(changes), func(change database.AccountPendingChange) bool {
+		return pushAccountChange(ctx, a, client, limiter, change, progress)
+	})
+	span.SetAttributes(telemetry.Count.Int(len(changes)), telemetry.Errors.Int(errorCount))
+	// Deletes add to the recycle bin, so expired copies are purged here.
+	if _, err := a.PurgeRecycleBin(); err != nil {
+		a.Events.Dispatch(events.Warningf("push", "%v", err))
+	}
+	a.Events.Dispatch(events.Event{Type: "push.complete", Source: "accounts", Payload: events.PushCompletePayload{ErrorCount: errorCount}})
+	a.Events.Dispatch(events.Infof("push", "Finished pushing account changes."))
+	return nil
+}
+
+// pushAccountChange pushes one staged account change and settles its
+// status in a push.account span under ctx. It reports whether the push
+// failed.
+func pushAccountChange(ctx context.Context, a *app.App, client *api.APIClient, limiter *api.RateLimiter, change database.AccountPendingChange, progress *app.Progress) (failed bool) {
+	ctx, span := telemetry.Start(ctx, "push.account", telemetry.ChangeID.Int(change.ChangeId), telemetry.ChangeType.String(change.ChangeType), telemetry.EntityID.Int(change.AccountId))
+	defer func() { telemetry.EndFailed(span, failed) }()
+	if change.ChangeType == "DELETE" && a.DeletesNeedConfirmation() {
+		a.Events.Dispatch(events.Warningf("push", "Holding delete of account %d (change %d) until it is confirmed; pass --confirm-deletes or set recycle_bin.confirm_deletes to never.", change.AccountId, change.ChangeId))
+		progress.Skipped()
+		return false
+	}
+	a.Events.Dispatch(events.Event{Type: "push.item.start", Source: "accounts", Payload: events.PushItemStartPayload{Change: change}})
+	database.UpdatePendingChangeStatus(a.DB, "AccountsPendingChanges", change.ChangeId, "processing")
+
+	var idempotencyKey string
+	if change.ChangeType != "DELETE" {
+		hash := database.AccountChangeHash(change.AccountId, change.ChangeType, change.Changes)
+		var duplicateOf int
+		idempotencyKey, duplicateOf = claimIdempotencyKey(a, "AccountsPendingChanges", change.ChangeId, change.IdempotencyKey.String, change.ContentHash.String, hash)
+		if duplicateOf != 0 {
+			skipDuplicate(a, "AccountsPendingChanges", change.ChangeId, duplicateOf, progress)
+			return false
+		}
+	}
+
+	data := make(map[string]string)
+	if err := json.Unmarshal([]byte(change.Changes), &data); err != nil {
+		parseErr := fmt.Errorf("invalid pending change payload (change_id=%d): %w", change.ChangeId, err)
+		a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: parseErr}})
+		settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "failed")
+		progress.Failed()
+		return true
+	}
 
-		data := make(map[string]string)
-		if err := json.Unmarshal([]byte(change.Changes), &data); err != nil {
-			parseErr := fmt.Errorf("invalid pending change payload (change_id=%d): %w", change.ChangeId, err)
-			a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: parseErr}})
-			database.UpdatePendingChangeStatus(a.DB, "AccountsPendingChanges", change.ChangeId, "failed")
-			errorCount++
-			continue
+	if change.ChangeType == "UPDATE" || change.ChangeType == "DELETE" {
+		conflict, err := database.GetAccountChangeConflict(a.DB, change.ChangeId)
+		if err != nil {
+			a.Events.Dispatch(events.Debugf("push", "Skipping version check for change %d: %v", change.ChangeId, err))
+		} else if conflict != nil {
+			switch a.ConflictWinner(change) {
+			case app.ConflictLocal:
+				a.Events.Dispatch(events.Infof("push", "Account %d changed after change %d was staged; pushing the staged values over it", change.AccountId, change.ChangeId))
+			case app.ConflictRemote:
+				return discardConflictingChange(a, change, progress)
+			default:
+				a.Events.Dispatch(events.Event{Type: "push.conflict", Source: "accounts", Payload: events.PushConflictPayload{
+					Change:         change,
+					BaseVersion:    conflict.BaseVersion,
+					CurrentVersion: conflict.CurrentVersion,
+				}})
+				conflictErr := errs.New(errs.ErrConflict, fmt.Errorf("account %d changed after change %d was staged; skipping push", change.AccountId, change.ChangeId))
+				a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: conflictErr}})
+				settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "failed")
+				progress.Failed()
+				return true
+			}
 		}
+	}
 
-		var apiErr error
-		switch change.ChangeType {
-		case "CREATE":
-			_, apiErr = a.API.CreateAccount(models.AccountUpload{Fields: data})
-		case "UPDATE":
-			_, apiErr = a.API.UpdateAccount(change.AccountId, models.AccountUpload{Fields: data})
-		case "DELETE":
-			apiErr = a.API.DeleteAccount(change.AccountId)
+	data, err := processAccountChange(a, change, data)
+	if err != nil {
+		reportProcessorFailure(a, "accounts", "AccountsPendingChanges", change.ChangeId, err)
+		progress.Failed()
+		return true
+	}
+	if change.ChangeType != "DELETE" {
+		dropped, err := a.DropPullOnlyAccountFields(data)
+		if err != nil {
+			a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: err}})
+			settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "failed")
+			progress.Failed()
+			return true
+		}
+		if len(dropped) > 0 {
+			a.Events.Dispatch(events.Infof("push", "Not pushing pull-only field(s) %s of change %d", strings.Join(dropped, ", "), change.ChangeId))
+		}
+		if len(data) == 0 && change.ChangeType == "UPDATE" {
+			settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "completed")
+			progress.Skipped()
+			return false
 		}
+	}
+
+	release := throttle(ctx, limiter)
+	_, apiSpan := telemetry.Start(ctx, "api."+strings.ToLower(change.ChangeType)+"_account")
+	var apiErr error
+	switch change.ChangeType {
+	case "CREATE":
+		_, apiErr = client.CreateAccount(models.AccountUpload{Fields: data, IdempotencyKey: idempotencyKey})
+	case "UPDATE":
+		_, apiErr = client.UpdateAccount(change.AccountId, models.AccountUpload{Fields: data, IdempotencyKey: idempotencyKey})
+	case "DELETE":
+		apiErr = deleteAccount(a, client, change)
+	}
+	telemetry.End(apiSpan, apiErr)
+	release()
 
-		if apiErr != nil {
-			a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: apiErr}})
-			database.UpdatePendingChangeStatus(a.DB, "AccountsPendingChanges", change.ChangeId, "failed")
-			errorCount++
-		} else {
-			a.Events.Dispatch(events.Event{Type: "push.item.success", Source: "accounts", Payload: events.PushItemSuccessPayload{Change: change}})
-			database.UpdatePendingChangeStatus(a.DB, "AccountsPendingChanges", change.ChangeId, "completed")
+	if apiErr != nil {
+		a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: apiErr}})
+		if !settleValidationFailure(a, change.ChangeId, apiErr) {
+			settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "failed")
 		}
+		progress.Failed()
+		return true
 	}
-	a.Events.Dispatch(events.Event{Type: "push.complete", Source: "accounts", Payload: events.PushCompletePayload{ErrorCount: errorCount}})
-	a.Events.Dispatch(events.Infof("push", "Finished pushing account changes."))
-	return nil
+	a.Events.Dispatch(events.Event{Type: "push.item.success", Source: "accounts", Payload: events.PushItemSuccessPayload{Change: change}})
+	settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "completed")
+	progress.Succeeded()
+	return false
+}
+
+// discardConflictingChange drops a staged change whose account changed
+// after it was staged, keeping the remote values. Sandbox pushes leave it
+// queued for production. It reports whether the change could not be
+// dropped.
+func discardConflictingChange(a *app.App, change database.AccountPendingChange, progress *app.Progress) (failed bool) {
+	if a.PushToSandbox() {
+		settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "pending")
+		progress.Skipped()
+		return false
+	}
+	if err := database.DeleteAccountPendingChange(a.DB, change.ChangeId); err != nil {
+		a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "accounts", Payload: events.PushItemErrorPayload{Error: err}})
+		settlePendingChange(a, "AccountsPendingChanges", change.ChangeId, "failed")
+		progress.Failed()
+		return true
+	}
+	a.Events.Dispatch(events.Infof("push", "Account %d changed after change %d was staged; discarded the change and kept the remote values", change.AccountId, change.ChangeId))
+	progress.Skipped()
+	return false
 }
 
 // RunPushCheckins orchestrates pushing pending check-in changes to the API.
 func RunPushCheckins(a *app.App) error {
+	return RunPushCheckinsSelected(a, PushSelection{})
+}
+
+// RunPushCheckinsSelected pushes the pending check-in changes sel selects
+// and leaves the rest queued.
+func RunPushCheckinsSelected(a *app.App, sel PushSelection) error {
+	if err := a.CheckSchema(); err != nil {
+		return err
+	}
+	if err := checkWindowForPush(a, "checkins"); err != nil {
+		return err
+	}
+	release, err := a.AcquireSyncLock("push checkins")
+	if err != nil {
+		return err
+	}
+	defer release()
+	client, err := pushClient(a, "checkins")
+	if err != nil {
+		return err
+	}
+	ctx, span := telemetry.Start(context.Background(), "push.checkins")
+	defer span.End()
 	a.Events.Dispatch(events.Event{Type: "push.scan.start", Source: "checkins", Payload: events.PushScanStartPayload{}})
 	changes, err := database.GetPendingCheckinChanges(a.DB)
 	if err != nil {
 		err = fmt.Errorf("error getting pending check-in changes: %w", err)
+		telemetry.End(span, err)
 		a.Events.Dispatch(events.Event{Type: "push.error", Source: "checkins", Payload: events.ErrorPayload{Error: err}})
 		return err
 	}
 
+	changes, queued := selectCheckinChanges(changes, sel)
+	if len(queued) > 0 {
+		a.Events.Dispatch(events.Infof("push", "Leaving %d check-in change(s) queued for a later push.", len(queued)))
+	}
 	a.Events.Dispatch(events.Event{Type: "push.scan.complete", Source: "checkins", Payload: events.PushScanCompletePayload{Changes: changes}})
 	if len(changes) == 0 {
 		a.Events.Dispatch(events.Infof("push", "No pending check-in changes to push."))
@@ -82,94 +253,136 @@ func RunPushCheckins(a *app.App) error {
 		return nil
 	}
 
-	errorCount := 0
-	for _, change := range changes {
-		a.Events.Dispatch(events.Event{Type: "push.item.start", Source: "checkins", Payload: events.PushItemStartPayload{Change: change}})
-		database.UpdatePendingChangeStatus(a.DB, "AccountCheckinsPendingChanges", change.ChangeId, "processing")
-
-		var apiErr error
-		switch change.ChangeType {
-		case "CREATE":
-			endpointType := "standard"
-			if change.EndpointType.Valid {
-				candidate := strings.ToLower(strings.TrimSpace(change.EndpointType.String))
-				if candidate != "" {
-					endpointType = candidate
-				} else if strings.TrimSpace(change.ExtraFields.String) != "" {
-					endpointType = "custom"
-				}
-			} else if strings.TrimSpace(change.ExtraFields.String) != "" {
-				endpointType = "custom"
-			}
+	throughput := a.PushThroughput("checkins")
+	limiter := a.PushLimiter("checkins")
+	progress := a.StartProgress("push", "checkins", len(changes))
+	defer progress.Finish()
+	errorCount := pushLanes(throughput.Workers, checkinLanes(changes), func(change database.CheckinPendingChange) bool {
+		return pushCheckinChange(ctx, a, client, limiter, change, progress)
+	})
+	span.SetAttributes(telemetry.Count.Int(len(changes)), telemetry.Errors.Int(errorCount))
+	a.Events.Dispatch(events.Event{Type: "push.complete", Source: "checkins", Payload: events.PushCompletePayload{ErrorCount: errorCount}})
+	a.Events.Dispatch(events.Infof("push", "Finished pushing check-in changes."))
+	return nil
+}
 
-			checkinType := strings.TrimSpace(change.Type.String)
-
-			switch endpointType {
-			case "standard":
-				fields := map[string]string{}
-				if value := strings.TrimSpace(change.Comments.String); value != "" {
-					fields["comments"] = value
-				}
-				if value := strings.TrimSpace(change.LogDatetime.String); value != "" {
-					fields["log_datetime"] = value
-				}
-				if value := strings.TrimSpace(change.CrmId.String); value != "" {
-					fields["crm_id"] = value
-				}
-				if value := strings.TrimSpace(change.CreatedBy.String); value != "" {
-					fields["created_by"] = value
-				}
-
-				_, apiErr = a.API.CreateCheckin(models.CheckinUpload{
-					Customer: change.AccountId,
-					Type:     checkinType,
-					Fields:   fields,
-				})
-			case "custom":
-				fields := map[string]string{}
-				if value := strings.TrimSpace(change.LogDatetime.String); value != "" {
-					fields["log_datetime"] = value
-				}
-				if value := strings.TrimSpace(change.CrmId.String); value != "" {
-					fields["crm_id"] = value
-				}
-				if value := strings.TrimSpace(change.CreatedBy.String); value != "" {
-					fields["created_by"] = value
-				}
-				if value := strings.TrimSpace(change.ExtraFields.String); value != "" {
-					fields["extra_fields"] = value
-				}
-
-				customInput := models.CustomCheckinUpload{
-					Customer: change.AccountId,
-					Type:     checkinType,
-					Fields:   fields,
-				}
-
-				if meetingNotes := strings.TrimSpace(change.Comments.String); meetingNotes != "" {
-					customInput.ExtraFields = &models.CustomCheckinExtraFields{
-						MeetingNotes: meetingNotes,
-					}
-				}
-
-				_, apiErr = a.API.CreateCustomCheckin(customInput)
-			default:
-				apiErr = fmt.Errorf("unsupported endpoint type %q for checkin change_id=%d", endpointType, change.ChangeId)
-			}
-		default:
-			apiErr = fmt.Errorf("unsupported checkin change type %q for change_id=%d", change.ChangeType, change.ChangeId)
+// pushCheckinChange pushes one staged check-in and settles its status in a
+// push.checkin span under ctx. It reports whether the push failed.
+func pushCheckinChange(ctx context.Context, a *app.App, client *api.APIClient, limiter *api.RateLimiter, change database.CheckinPendingChange, progress *app.Progress) (failed bool) {
+	ctx, span := telemetry.Start(ctx, "push.checkin", telemetry.ChangeID.Int(change.ChangeId), telemetry.ChangeType.String(change.ChangeType), telemetry.EntityID.Int(change.AccountId))
+	defer func() { telemetry.EndFailed(span, failed) }()
+	a.Events.Dispatch(events.Event{Type: "push.item.start", Source: "checkins", Payload: events.PushItemStartPayload{Change: change}})
+	database.UpdatePendingChangeStatus(a.DB, "AccountCheckinsPendingChanges", change.ChangeId, "processing")
+
+	idempotencyKey, duplicateOf := claimIdempotencyKey(a, "AccountCheckinsPendingChanges", change.ChangeId, change.IdempotencyKey.String, change.ContentHash.String, database.CheckinChangeHash(change))
+	if duplicateOf != 0 {
+		skipDuplicate(a, "AccountCheckinsPendingChanges", change.ChangeId, duplicateOf, progress)
+		return false
+	}
+
+	if err := processCheckinChange(a, &change); err != nil {
+		reportProcessorFailure(a, "checkins", "AccountCheckinsPendingChanges", change.ChangeId, err)
+		progress.Failed()
+		return true
+	}
+
+	release := throttle(ctx, limiter)
+	_, apiSpan := telemetry.Start(ctx, "api.create_checkin")
+	var apiErr error
+	upload, apiErr := checkinUpload(change, idempotencyKey)
+	switch upload := upload.(type) {
+	case models.CheckinUpload:
+		_, apiErr = client.CreateCheckin(upload)
+	case models.CustomCheckinUpload:
+		_, apiErr = client.CreateCustomCheckin(upload)
+	}
+	telemetry.End(apiSpan, apiErr)
+	release()
+
+	if apiErr != nil {
+		a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "checkins", Payload: events.PushItemErrorPayload{Error: apiErr}})
+		settlePendingChange(a, "AccountCheckinsPendingChanges", change.ChangeId, "failed")
+		progress.Failed()
+		return true
+	}
+	a.Events.Dispatch(events.Event{Type: "push.item.success", Source: "checkins", Payload: events.PushItemSuccessPayload{Change: change}})
+	settlePendingChange(a, "AccountCheckinsPendingChanges", change.ChangeId, "completed")
+	progress.Succeeded()
+	return false
+}
+
+// checkinUpload returns what a staged check-in is pushed as: a
+// models.CheckinUpload for the standard endpoint or a
+// models.CustomCheckinUpload for the custom one.
+func checkinUpload(change database.CheckinPendingChange, idempotencyKey string) (any, error) {
+	if change.ChangeType != "CREATE" {
+		return nil, fmt.Errorf("unsupported checkin change type %q for change_id=%d", change.ChangeType, change.ChangeId)
+	}
+	endpointType := "standard"
+	if change.EndpointType.Valid {
+		candidate := strings.ToLower(strings.TrimSpace(change.EndpointType.String))
+		if candidate != "" {
+			endpointType = candidate
+		} else if strings.TrimSpace(change.ExtraFields.String) != "" {
+			endpointType = "custom"
+		}
+	} else if strings.TrimSpace(change.ExtraFields.String) != "" {
+		endpointType = "custom"
+	}
+
+	checkinType := strings.TrimSpace(change.Type.String)
+
+	switch endpointType {
+	case "standard":
+		fields := map[string]string{}
+		if value := strings.TrimSpace(change.Comments.String); value != "" {
+			fields["comments"] = value
+		}
+		if value := strings.TrimSpace(change.LogDatetime.String); value != "" {
+			fields["log_datetime"] = value
+		}
+		if value := strings.TrimSpace(change.CrmId.String); value != "" {
+			fields["crm_id"] = value
+		}
+		if value := strings.TrimSpace(change.CreatedBy.String); value != "" {
+			fields["created_by"] = value
 		}
 
-		if apiErr != nil {
-			a.Events.Dispatch(events.Event{Type: "push.item.error", Source: "checkins", Payload: events.PushItemErrorPayload{Error: apiErr}})
-			database.UpdatePendingChangeStatus(a.DB, "AccountCheckinsPendingChanges", change.ChangeId, "failed")
-			errorCount++
-		} else {
-			a.Events.Dispatch(events.Event{Type: "push.item.success", Source: "checkins", Payload: events.PushItemSuccessPayload{Change: change}})
-			database.UpdatePendingChangeStatus(a.DB, "AccountCheckinsPendingChanges", change.ChangeId, "completed")
+		return models.CheckinUpload{
+			Customer:       change.AccountId,
+			Type:           checkinType,
+			Fields:         fields,
+			IdempotencyKey: idempotencyKey,
+		}, nil
+	case "custom":
+		fields := map[string]string{}
+		if value := strings.TrimSpace(change.LogDatetime.String); value != "" {
+			fields["log_datetime"] = value
 		}
+		if value := strings.TrimSpace(change.CrmId.String); value != "" {
+			fields["crm_id"] = value
+		}
+		if value := strings.TrimSpace(change.CreatedBy.String); value != "" {
+			fields["created_by"] = value
+		}
+		if value := strings.TrimSpace(change.ExtraFields.String); value != "" {
+			fields["extra_fields"] = value
+		}
+
+		customInput := models.CustomCheckinUpload{
+			Customer:       change.AccountId,
+			Type:           checkinType,
+			Fields:         fields,
+			IdempotencyKey: idempotencyKey,
+		}
+
+		if meetingNotes := strings.TrimSpace(change.Comments.String); meetingNotes != "" {
+			customInput.ExtraFields = &models.CustomCheckinExtraFields{
+				MeetingNotes: meetingNotes,
+			}
+		}
+		return customInput, nil
+	default:
+		return nil, fmt.Errorf("unsupported endpoint type %q for checkin change_id=%d", endpointType, change.ChangeId)
 	}
-	a.Events.Dispatch(events.Event{Type: "push.complete", Source: "checkins", Payload: events.PushCompletePayload{ErrorCount: errorCount}})
-	a.Events.Dispatch(events.Infof("push", "Finished pushing check-in changes."))
-	return nil
 }
@@ -0,0 +1,40 @@
+package push
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+// claimIdempotencyKey returns the key to send with a change whose current
+// content hashes to hash. A change staged without a key, or edited after
+// its key was issued, gets a new key so one key never names two different
+// requests. duplicateOf is the ID of another change with the same key and
+// content that was already pushed, or 0.
+func claimIdempotencyKey(a *app.App, table string, changeID int, key, issuedHash, hash string) (claimed string, duplicateOf int) {
+	if key == "" || issuedHash != hash {
+		key = database.NewIdempotencyKey()
+		if err := database.SetIdempotencyKey(a.DB, table, changeID, key, hash); err != nil {
+			a.Events.Dispatch(events.Debugf("push", "Could not save the idempotency key of change %d: %v", changeID, err))
+		}
+		return key, 0
+	}
+	keyed, err := database.GetChangesByIdempotencyKey(a.DB, table, key)
+	if err != nil {
+		a.Events.Dispatch(events.Debugf("push", "Skipping duplicate check for change %d: %v", changeID, err))
+		return key, 0
+	}
+	for _, other := range keyed {
+		if other.ChangeId != changeID && other.Status == "completed" && other.ContentHash == hash {
+			return key, other.ChangeId
+		}
+	}
+	return key, 0
+}
+
+// skipDuplicate settles a change that was already pushed as duplicateOf.
+func skipDuplicate(a *app.App, table string, changeID, duplicateOf int, progress *app.Progress) {
+	a.Events.Dispatch(events.Infof("push", "Change %d was already pushed as change %d with the same idempotency key; skipping.", changeID, duplicateOf))
+	settlePendingChange(a, table, changeID, "completed")
+	progress.Skipped()
+}
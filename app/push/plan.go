@@ -0,0 +1,259 @@
+package push
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/database"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EstimatedRequestTime is how long one API request is assumed to take when
+// estimating a push.
+const EstimatedRequestTime = 500 * time.Millisecond
+
+// PushSelection picks the pending changes a push sends now; the others
+// stay queued. The zero value selects every change.
+type PushSelection struct {
+	// Limit caps how many changes are pushed; 0 pushes every match.
+	Limit int
+	// Filter keeps only the matching changes. Its Status and OrderBy are
+	// ignored: pending changes go out in the order they were staged.
+	Filter PushFilterOptions
+}
+
+// IsZero reports whether sel selects every change.
+func (sel PushSelection) IsZero() bool {
+	return sel.Limit <= 0 && sel.Filter == (PushFilterOptions{})
+}
+
+func (sel PushSelection) filter() PushFilterOptions {
+	options := sel.Filter
+	options.Status = ""
+	options.OrderBy = ""
+	return options
+}
+
+// selectAccountChanges splits changes into those sel pushes now and those
+// left queued. A change whose account has an earlier change left queued is
+// left queued too, so an account's changes still go out in order.
+func selectAccountChanges(changes []database.AccountPendingChange, sel PushSelection) (now, later []database.AccountPendingChange) {
+	if sel.IsZero() {
+		return changes, nil
+	}
+	matches := make(map[int]bool)
+	for _, change := range filterAndSortAccountChanges(changes, sel.filter()) {
+		matches[change.ChangeId] = true
+	}
+	held := make(map[int]bool)
+	for _, change := range changes {
+		if held[change.AccountId] || !matches[change.ChangeId] || (sel.Limit > 0 && len(now) >= sel.Limit) {
+			held[change.AccountId] = true
+			later = append(later, change)
+			continue
+		}
+		now = append(now, change)
+	}
+	return now, later
+}
+
+// selectCheckinChanges splits changes into those sel pushes now and those
+// left queued.
+func selectCheckinChanges(changes []database.CheckinPendingChange, sel PushSelection) (now, later []database.CheckinPendingChange) {
+	if sel.IsZero() {
+		return changes, nil
+	}
+	matches := make(map[int]bool)
+	for _, change := range filterAndSortCheckinChanges(changes, sel.filter()) {
+		matches[change.ChangeId] = true
+	}
+	for _, change := range changes {
+		if !matches[change.ChangeId] || (sel.Limit > 0 && len(now) >= sel.Limit) {
+			later = append(later, change)
+			continue
+		}
+		now = append(now, change)
+	}
+	return now, later
+}
+
+// PushOperation counts the changes of one kind in a push and the API calls
+// they take.
+type PushOperation struct {
+	Operation string `json:"operation"`
+	Changes   int    `json:"changes"`
+	Calls     int    `json:"calls"`
+}
+
+// PushEstimate describes a set of pending changes of one entity type: the
+// API calls pushing them takes and about how long, given the configured
+// workers and request rates. Held counts account deletes waiting for
+// confirmation, which take no call; an update whose every field is
+// pull-only takes none either.
+type PushEstimate struct {
+	Entity     string          `json:"entity"`
+	Changes    int             `json:"changes"`
+	Calls      int             `json:"calls"`
+	Held       int             `json:"held,omitempty"`
+	Operations []PushOperation `json:"operations,omitempty"`
+	Workers    int             `json:"workers"`
+	// RequestsPerSecond is the lower of the entity's push rate and the
+	// app-wide rate; 0 means neither is limited.
+	RequestsPerSecond float64       `json:"requests_per_second,omitempty"`
+	Duration          time.Duration `json:"duration"`
+}
+
+// PushPlan splits the pending changes of an entity type into those a
+// selection pushes now and those it leaves queued. LaterAt is when the push
+// window next opens to flush the queued ones; it is zero when no window is
+// enabled, and they wait for the next push.
+type PushPlan struct {
+	Now     PushEstimate `json:"now"`
+	Later   PushEstimate `json:"later"`
+	LaterAt time.Time    `json:"later_at,omitempty"`
+}
+
+// PlanPush estimates pushing the pending changes of entity ("accounts" or
+// "checkins") that sel selects, and the changes it leaves queued.
+func PlanPush(a *app.App, entity string, sel PushSelection) (*PushPlan, error) {
+	var now, later []operationCall
+	var lanes, laterLanes int
+	var longest, laterLongest int
+	switch entity {
+	case "accounts":
+		changes, err := database.GetPendingAccountChanges(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("error getting pending account changes: %w", err)
+		}
+		selected, queued := selectAccountChanges(changes, sel)
+		now, later = accountCalls(a, selected), accountCalls(a, queued)
+		lanes, longest = laneShape(accountLanes(selected))
+		laterLanes, laterLongest = laneShape(accountLanes(queued))
+	case "checkins":
+		changes, err := database.GetPendingCheckinChanges(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("error getting pending check-in changes: %w", err)
+		}
+		selected, queued := selectCheckinChanges(changes, sel)
+		now, later = checkinCalls(selected), checkinCalls(queued)
+		lanes, longest = len(selected), 1
+		laterLanes, laterLongest = len(queued), 1
+	default:
+		return nil, fmt.Errorf("unsupported entity type for a push plan: %s", entity)
+	}
+
+	plan := &PushPlan{
+		Now:   estimatePush(a, entity, now, lanes, longest),
+		Later: estimatePush(a, entity, later, laterLanes, laterLongest),
+	}
+	if plan.Later.Changes > 0 && a.Config.PushWindow.Enabled {
+		if next, err := a.Config.PushWindow.NextOpen(time.Now()); err == nil {
+			plan.LaterAt = next
+		}
+	}
+	return plan, nil
+}
+
+// operationCall is one change with its operation and the calls it takes;
+// held marks a delete waiting for confirmation.
+type operationCall struct {
+	operation string
+	calls     int
+	held      bool
+}
+
+func accountCalls(a *app.App, changes []database.AccountPendingChange) []operationCall {
+	calls := make([]operationCall, 0, len(changes))
+	for _, change := range changes {
+		call := operationCall{operation: "account " + change.ChangeType, calls: 1}
+		switch change.ChangeType {
+		case "DELETE":
+			if a.DeletesNeedConfirmation() {
+				call.calls, call.held = 0, true
+			}
+		case "UPDATE":
+			data := make(map[string]string)
+			if json.Unmarshal([]byte(change.Changes), &data) == nil {
+				if _, err := a.DropPullOnlyAccountFields(data); err == nil && len(data) == 0 {
+					call.calls = 0
+				}
+			}
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+func checkinCalls(changes []database.CheckinPendingChange) []operationCall {
+	calls := make([]operationCall, 0, len(changes))
+	for _, change := range changes {
+		operation := "check-in " + change.ChangeType
+		if upload, err := checkinUpload(change, ""); err == nil {
+			if _, custom := upload.(models.CustomCheckinUpload); custom {
+				operation += " (custom endpoint)"
+			}
+		}
+		calls = append(calls, operationCall{operation: operation, calls: 1})
+	}
+	return calls
+}
+
+// laneShape returns how many lanes there are and the length of the longest.
+func laneShape[T any](lanes [][]T) (count, longest int) {
+	for _, lane := range lanes {
+		longest = max(longest, len(lane))
+	}
+	return len(lanes), longest
+}
+
+func estimatePush(a *app.App, entity string, calls []operationCall, lanes, longest int) PushEstimate {
+	throughput := a.PushThroughput(entity)
+	estimate := PushEstimate{
+		Entity:            entity,
+		Changes:           len(calls),
+		Workers:           throughput.Workers,
+		RequestsPerSecond: throughput.RequestsPerSecond,
+	}
+	if rate := a.Config.RequestsPerSecond; rate > 0 && (estimate.RequestsPerSecond == 0 || rate < estimate.RequestsPerSecond) {
+		estimate.RequestsPerSecond = rate
+	}
+	byOperation := make(map[string]*PushOperation)
+	for _, call := range calls {
+		estimate.Calls += call.calls
+		if call.held {
+			estimate.Held++
+		}
+		op := byOperation[call.operation]
+		if op == nil {
+			op = &PushOperation{Operation: call.operation}
+			byOperation[call.operation] = op
+		}
+		op.Changes++
+		op.Calls += call.calls
+	}
+	for _, op := range byOperation {
+		estimate.Operations = append(estimate.Operations, *op)
+	}
+	sort.Slice(estimate.Operations, func(i, j int) bool { return estimate.Operations[i].Operation < estimate.Operations[j].Operation })
+	estimate.Duration = estimateDuration(estimate.Calls, min(estimate.Workers, max(lanes, 1)), longest, estimate.RequestsPerSecond)
+	return estimate
+}
+
+// estimateDuration is the time calls take with parallel workers, when one
+// lane holds longest changes that go out one at a time, paced at rate
+// requests per second (0 for unlimited).
+func estimateDuration(calls, parallel, longest int, rate float64) time.Duration {
+	if calls == 0 {
+		return 0
+	}
+	rounds := max((calls+parallel-1)/parallel, min(longest, calls))
+	duration := time.Duration(rounds) * EstimatedRequestTime
+	if rate > 0 {
+		if paced := time.Duration(float64(calls) / rate * float64(time.Second)); paced > duration {
+			duration = paced
+		}
+	}
+	return duration
+}
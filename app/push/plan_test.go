@@ -0,0 +1,75 @@
+package push
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"badgermaps/app"
+	"badgermaps/database"
+)
+
+func changeIDs(changes []database.AccountPendingChange) []int {
+	ids := make([]int, len(changes))
+	for i, change := range changes {
+		ids[i] = change.ChangeId
+	}
+	return ids
+}
+
+func TestSelectAccountChangesKeepsAccountOrder(t *testing.T) {
+	changes := []database.AccountPendingChange{
+		{ChangeId: 1, AccountId: 10, ChangeType: "UPDATE"},
+		{ChangeId: 2, AccountId: 20, ChangeType: "CREATE"},
+		{ChangeId: 3, AccountId: 10, ChangeType: "DELETE"},
+		{ChangeId: 4, AccountId: 30, ChangeType: "DELETE"},
+		{ChangeId: 5, AccountId: 40, ChangeType: "UPDATE"},
+	}
+	for _, tc := range []struct {
+		sel        PushSelection
+		now, later []int
+	}{
+		{PushSelection{}, []int{1, 2, 3, 4, 5}, nil},
+		{PushSelection{Limit: 2}, []int{1, 2}, []int{3, 4, 5}},
+		{PushSelection{Filter: PushFilterOptions{Type: "delete"}}, []int{4}, []int{1, 2, 3, 5}},
+		{PushSelection{Limit: 1, Filter: PushFilterOptions{Type: "UPDATE", Status: "failed", OrderBy: "account_desc"}}, []int{1}, []int{2, 3, 4, 5}},
+	} {
+		now, later := selectAccountChanges(changes, tc.sel)
+		if got := changeIDs(now); !slices.Equal(got, tc.now) {
+			t.Errorf("%+v: now = %v, want %v", tc.sel, got, tc.now)
+		}
+		if got := changeIDs(later); !slices.Equal(got, tc.later) {
+			t.Errorf("%+v: later = %v, want %v", tc.sel, got, tc.later)
+		}
+	}
+}
+
+func TestEstimatePush(t *testing.T) {
+	a := app.NewApp()
+	a.Config.PushThroughput.Checkins = app.EntityThroughput{Workers: 4, RequestsPerSecond: 2}
+	a.Config.RequestsPerSecond = 5
+	checkins := []database.CheckinPendingChange{
+		{ChangeId: 1, AccountId: 1, ChangeType: "CREATE"},
+		{ChangeId: 2, AccountId: 2, ChangeType: "CREATE"},
+		{ChangeId: 3, AccountId: 2, ChangeType: "CREATE"},
+	}
+	checkins[2].ExtraFields.String, checkins[2].ExtraFields.Valid = `{"x":1}`, true
+	estimate := estimatePush(a, "checkins", checkinCalls(checkins), len(checkins), 1)
+	if estimate.Calls != 3 || estimate.RequestsPerSecond != 2 || estimate.Workers != 4 || estimate.Duration != 1500*time.Millisecond {
+		t.Fatalf("unexpected estimate: %+v", estimate)
+	}
+	if len(estimate.Operations) != 2 || estimate.Operations[0].Operation != "check-in CREATE" || estimate.Operations[1].Changes != 1 {
+		t.Fatalf("unexpected operations: %+v", estimate.Operations)
+	}
+
+	// One account's changes go out one at a time whatever the workers.
+	if d := estimateDuration(6, 4, 5, 0); d != 5*EstimatedRequestTime {
+		t.Fatalf("estimateDuration = %v, want five requests in a row", d)
+	}
+	if d := estimateDuration(8, 4, 1, 0); d != 2*EstimatedRequestTime {
+		t.Fatalf("estimateDuration = %v, want two rounds of four", d)
+	}
+	if d := estimateDuration(0, 1, 0, 1); d != 0 {
+		t.Fatalf("estimateDuration of nothing = %v", d)
+	}
+}
@@ -0,0 +1,87 @@
+package push
+
+import (
+	"badgermaps/api"
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/database"
+	"encoding/json"
+	"fmt"
+)
+
+// PreviewAccountChange returns the request that pushing a staged account
+// change would send, after processors and sync directions have had their
+// say. It is nil when the change would be settled without a request. The
+// idempotency key is the change's own while its content is unchanged; a
+// change without one gets a new key when it is pushed.
+func PreviewAccountChange(a *app.App, change database.AccountPendingChange) (*api.RequestPreview, error) {
+	client, err := a.PushAPI()
+	if err != nil {
+		return nil, err
+	}
+	if change.ChangeType == "DELETE" {
+		preview := client.PreviewDeleteAccount(change.AccountId)
+		return &preview, nil
+	}
+	data := make(map[string]string)
+	if err := json.Unmarshal([]byte(change.Changes), &data); err != nil {
+		return nil, fmt.Errorf("invalid pending change payload (change_id=%d): %w", change.ChangeId, err)
+	}
+	if data, err = processAccountChange(a, change, data); err != nil {
+		return nil, err
+	}
+	if _, err := a.DropPullOnlyAccountFields(data); err != nil {
+		return nil, err
+	}
+	key := previewIdempotencyKey(change.IdempotencyKey.String, change.ContentHash.String, database.AccountChangeHash(change.AccountId, change.ChangeType, change.Changes))
+	var preview api.RequestPreview
+	switch change.ChangeType {
+	case "CREATE":
+		preview = client.PreviewCreateAccount(models.AccountUpload{Fields: data, IdempotencyKey: key})
+	case "UPDATE":
+		if len(data) == 0 {
+			return nil, nil
+		}
+		preview = client.PreviewUpdateAccount(change.AccountId, models.AccountUpload{Fields: data, IdempotencyKey: key})
+	default:
+		return nil, fmt.Errorf("unsupported account change type %q for change_id=%d", change.ChangeType, change.ChangeId)
+	}
+	return &preview, nil
+}
+
+// PreviewCheckinChange returns the request that pushing a staged check-in
+// would send, like PreviewAccountChange.
+func PreviewCheckinChange(a *app.App, change database.CheckinPendingChange) (*api.RequestPreview, error) {
+	client, err := a.PushAPI()
+	if err != nil {
+		return nil, err
+	}
+	key := previewIdempotencyKey(change.IdempotencyKey.String, change.ContentHash.String, database.CheckinChangeHash(change))
+	if err := processCheckinChange(a, &change); err != nil {
+		return nil, err
+	}
+	upload, err := checkinUpload(change, key)
+	if err != nil {
+		return nil, err
+	}
+	var preview api.RequestPreview
+	switch upload := upload.(type) {
+	case models.CheckinUpload:
+		preview, err = client.PreviewCreateCheckin(upload)
+	case models.CustomCheckinUpload:
+		preview, err = client.PreviewCreateCustomCheckin(upload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// previewIdempotencyKey is the key claimIdempotencyKey would reuse, or ""
+// when it would issue a new one.
+func previewIdempotencyKey(key, issuedHash, hash string) string {
+	if issuedHash != hash {
+		return ""
+	}
+	return key
+}
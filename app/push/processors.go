@@ -0,0 +1,101 @@
+package push
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/processor"
+	"badgermaps/database"
+	"badgermaps/events"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// processAccountChange runs the account processors over the fields of a
+// pending change and returns the fields to send.
+func processAccountChange(a *app.App, change database.AccountPendingChange, data map[string]string) (map[string]string, error) {
+	if !a.Processors.Has(processor.EntityAccount) {
+		return data, nil
+	}
+	fields := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		fields[k] = v
+	}
+	if err := a.ProcessFields(processor.StagePush, processor.EntityAccount, change.AccountId, fields); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(fields))
+	for k, v := range fields {
+		out[k] = fieldString(v)
+	}
+	return out, nil
+}
+
+// checkinFieldTargets maps the processor field names of a pending check-in to
+// the change columns they are written back to.
+func checkinFieldTargets(change *database.CheckinPendingChange) map[string]*sql.NullString {
+	return map[string]*sql.NullString{
+		"type":          &change.Type,
+		"comments":      &change.Comments,
+		"log_datetime":  &change.LogDatetime,
+		"crm_id":        &change.CrmId,
+		"created_by":    &change.CreatedBy,
+		"extra_fields":  &change.ExtraFields,
+		"endpoint_type": &change.EndpointType,
+	}
+}
+
+// processCheckinChange runs the check-in processors over a pending check-in
+// and applies their changes to it.
+func processCheckinChange(a *app.App, change *database.CheckinPendingChange) error {
+	if !a.Processors.Has(processor.EntityCheckin) {
+		return nil
+	}
+	targets := checkinFieldTargets(change)
+	fields := map[string]interface{}{"customer": change.AccountId}
+	for name, target := range targets {
+		if target.Valid {
+			fields[name] = target.String
+		}
+	}
+	if err := a.ProcessFields(processor.StagePush, processor.EntityCheckin, change.CheckinId, fields); err != nil {
+		return err
+	}
+	for name, target := range targets {
+		if value, ok := fields[name]; ok && value != nil {
+			*target = sql.NullString{String: fieldString(value), Valid: true}
+		} else {
+			*target = sql.NullString{}
+		}
+	}
+	return nil
+}
+
+// reportProcessorFailure marks a change failed after a processor vetoed or
+// rejected it.
+func reportProcessorFailure(a *app.App, source, table string, changeID int, err error) {
+	if veto, ok := processor.IsVeto(err); ok {
+		a.Events.Dispatch(events.Warningf("push", "Change %d was not pushed: %v", changeID, veto))
+	} else {
+		a.Events.Dispatch(events.Event{Type: "push.item.error", Source: source, Payload: events.PushItemErrorPayload{Error: err}})
+	}
+	settlePendingChange(a, table, changeID, "failed")
+}
+
+// fieldString converts a processed field value to the string form the API
+// upload models expect.
+func fieldString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64, bool, int, int64:
+		return fmt.Sprint(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
@@ -16,6 +16,8 @@ type PushFilterOptions struct {
 	Date      string
 	AccountID int
 	OrderBy   string
+	// OlderThan keeps only changes created at least this long ago.
+	OlderThan time.Duration
 }
 
 // GetFilteredPendingChanges retrieves pending account or check-in changes based on the provided filters.
@@ -62,6 +64,9 @@ func filterAndSortAccountChanges(changes []database.AccountPendingChange, option
 		if options.AccountID != 0 && change.AccountId != options.AccountID {
 			continue
 		}
+		if options.OlderThan > 0 && time.Since(change.CreatedAt) < options.OlderThan {
+			continue
+		}
 		filtered = append(filtered, change)
 	}
 
@@ -113,6 +118,9 @@ func filterAndSortCheckinChanges(changes []database.CheckinPendingChange, option
 		if options.AccountID != 0 && change.AccountId != options.AccountID {
 			continue
 		}
+		if options.OlderThan > 0 && time.Since(change.CreatedAt) < options.OlderThan {
+			continue
+		}
 		filtered = append(filtered, change)
 	}
 
@@ -148,4 +156,3 @@ func filterAndSortCheckinChanges(changes []database.CheckinPendingChange, option
 func isSameDay(t1, t2 time.Time) bool {
 	return t1.Year() == t2.Year() && t1.Month() == t2.Month() && t1.Day() == t2.Day()
 }
-
@@ -0,0 +1,153 @@
+package push
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuickFilter is a named preset for browsing pending changes.
+type QuickFilter string
+
+const (
+	QuickFilterAll       QuickFilter = "all"
+	QuickFilterPending   QuickFilter = "pending"
+	QuickFilterFailed    QuickFilter = "failed"
+	QuickFilterInvalid   QuickFilter = database.StatusValidationFailed
+	QuickFilterCompleted QuickFilter = "completed"
+	QuickFilterCreate    QuickFilter = "create"
+	QuickFilterUpdate    QuickFilter = "update"
+	QuickFilterDelete    QuickFilter = "delete"
+	QuickFilterStale     QuickFilter = "stale"
+
+	// StaleChangeAge is the age after which a change counts as stale.
+	StaleChangeAge = 24 * time.Hour
+)
+
+var quickFilterOrder = []QuickFilter{
+	QuickFilterAll,
+	QuickFilterPending,
+	QuickFilterFailed,
+	QuickFilterInvalid,
+	QuickFilterCompleted,
+	QuickFilterCreate,
+	QuickFilterUpdate,
+	QuickFilterDelete,
+	QuickFilterStale,
+}
+
+// QuickFilters returns the available presets in display order.
+func QuickFilters() []QuickFilter {
+	out := make([]QuickFilter, len(quickFilterOrder))
+	copy(out, quickFilterOrder)
+	return out
+}
+
+// ParseQuickFilter normalizes a stored preset name, falling back to pending.
+func ParseQuickFilter(value string) QuickFilter {
+	candidate := QuickFilter(strings.ToLower(strings.TrimSpace(value)))
+	for _, f := range quickFilterOrder {
+		if f == candidate {
+			return f
+		}
+	}
+	return QuickFilterPending
+}
+
+// Label returns the human readable name for the preset.
+func (f QuickFilter) Label() string {
+	switch f {
+	case QuickFilterAll:
+		return "All"
+	case QuickFilterInvalid:
+		return "Validation failed"
+	case QuickFilterStale:
+		return "Older than 24h"
+	default:
+		s := string(f)
+		if s == "" {
+			return ""
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+}
+
+// Options converts the preset into filter options, newest first.
+func (f QuickFilter) Options() PushFilterOptions {
+	options := PushFilterOptions{OrderBy: "date_desc"}
+	switch f {
+	case QuickFilterPending, QuickFilterFailed, QuickFilterInvalid, QuickFilterCompleted:
+		options.Status = string(f)
+	case QuickFilterCreate, QuickFilterUpdate, QuickFilterDelete:
+		options.Type = string(f)
+	case QuickFilterStale:
+		options.OlderThan = StaleChangeAge
+	}
+	return options
+}
+
+// GetQuickFilteredChanges returns account or check-in changes matching the
+// preset. Unlike GetFilteredPendingChanges it includes processed changes so
+// failed and completed rows can be reviewed.
+func GetQuickFilteredChanges(a *app.App, entityType string, filter QuickFilter) (interface{}, error) {
+	options := filter.Options()
+	switch strings.ToLower(entityType) {
+	case "accounts":
+		changes, err := database.GetAccountChanges(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("error getting account changes: %w", err)
+		}
+		return filterAndSortAccountChanges(changes, options), nil
+	case "checkins":
+		changes, err := database.GetCheckinChanges(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("error getting check-in changes: %w", err)
+		}
+		return filterAndSortCheckinChanges(changes, options), nil
+	default:
+		return nil, fmt.Errorf("unsupported entity type for filtering: %s", entityType)
+	}
+}
+
+// CountQuickFilters returns how many changes of the given entity type match each preset.
+func CountQuickFilters(a *app.App, entityType string) (map[QuickFilter]int, error) {
+	counts := make(map[QuickFilter]int, len(quickFilterOrder))
+	tally := func(status, changeType string, createdAt time.Time) {
+		counts[QuickFilterAll]++
+		switch f := QuickFilter(strings.ToLower(status)); f {
+		case QuickFilterPending, QuickFilterFailed, QuickFilterInvalid, QuickFilterCompleted:
+			counts[f]++
+		}
+		switch f := QuickFilter(strings.ToLower(changeType)); f {
+		case QuickFilterCreate, QuickFilterUpdate, QuickFilterDelete:
+			counts[f]++
+		}
+		if time.Since(createdAt) >= StaleChangeAge {
+			counts[QuickFilterStale]++
+		}
+	}
+
+	switch strings.ToLower(entityType) {
+	case "accounts":
+		changes, err := database.GetAccountChanges(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("error getting account changes: %w", err)
+		}
+		for _, c := range changes {
+			tally(c.Status, c.ChangeType, c.CreatedAt)
+		}
+	case "checkins":
+		changes, err := database.GetCheckinChanges(a.DB)
+		if err != nil {
+			return nil, fmt.Errorf("error getting check-in changes: %w", err)
+		}
+		for _, c := range changes {
+			tally(c.Status, c.ChangeType, c.CreatedAt)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported entity type for filtering: %s", entityType)
+	}
+	return counts, nil
+}
@@ -0,0 +1,126 @@
+package push
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func newQuickFilterTestApp(t *testing.T) *app.App {
+	t.Helper()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "filters.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	for _, stmt := range []string{
+		`INSERT INTO AccountsPendingChanges (ChangeId, AccountId, ChangeType, Changes, Status, CreatedAt) VALUES
+			(1, 10, 'UPDATE', '{"phone":"555"}', 'pending', datetime('now')),
+			(2, 0, 'CREATE', '{"last_name":"New"}', 'failed', datetime('now', '-1 hour')),
+			(3, 30, 'DELETE', '{}', 'completed', datetime('now', '-48 hours')),
+			(5, 50, 'UPDATE', '{"notes":"x"}', 'pending', datetime('now', '-30 hours'))`,
+		`INSERT INTO AccountsPendingChanges (ChangeId, AccountId, ChangeType, Changes, Status, ValidationErrors, CreatedAt) VALUES
+			(4, 40, 'UPDATE', '{"email":"x"}', 'failed', '{"email":["invalid"]}', datetime('now', '-2 hours'))`,
+		`INSERT INTO AccountCheckinsPendingChanges (ChangeId, CheckinId, AccountId, Type, ChangeType, Status, CreatedAt) VALUES
+			(1, 0, 10, 'Visit', 'CREATE', 'pending', datetime('now', '-72 hours')),
+			(2, 0, 20, 'Call', 'CREATE', 'failed', datetime('now'))`,
+	} {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	a := app.NewApp()
+	a.DB = db
+	return a
+}
+
+func TestParseQuickFilter(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  QuickFilter
+	}{
+		{"failed", QuickFilterFailed},
+		{" Stale ", QuickFilterStale},
+		{"VALIDATION_FAILED", QuickFilterInvalid},
+		{"", QuickFilterPending},
+		{"archived", QuickFilterPending},
+	} {
+		if got := ParseQuickFilter(tt.value); got != tt.want {
+			t.Errorf("ParseQuickFilter(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQuickFilters(t *testing.T) {
+	a := newQuickFilterTestApp(t)
+	tests := []struct {
+		filter       QuickFilter
+		accountIDs   []int
+		checkinIDs   []int
+		accountCount int
+		checkinCount int
+	}{
+		{QuickFilterAll, []int{1, 2, 4, 5, 3}, []int{2, 1}, 5, 2},
+		{QuickFilterPending, []int{1, 5}, []int{1}, 2, 1},
+		{QuickFilterFailed, []int{2}, []int{2}, 1, 1},
+		{QuickFilterInvalid, []int{4}, nil, 1, 0},
+		{QuickFilterCompleted, []int{3}, nil, 1, 0},
+		{QuickFilterCreate, []int{2}, []int{2, 1}, 1, 2},
+		{QuickFilterUpdate, []int{1, 4, 5}, nil, 3, 0},
+		{QuickFilterDelete, []int{3}, nil, 1, 0},
+		{QuickFilterStale, []int{5, 3}, []int{1}, 2, 1},
+	}
+
+	accountCounts, err := CountQuickFilters(a, "accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkinCounts, err := CountQuickFilters(a, "checkins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.filter), func(t *testing.T) {
+			if got := accountCounts[tt.filter]; got != tt.accountCount {
+				t.Errorf("account count = %d, want %d", got, tt.accountCount)
+			}
+			if got := checkinCounts[tt.filter]; got != tt.checkinCount {
+				t.Errorf("check-in count = %d, want %d", got, tt.checkinCount)
+			}
+
+			accounts, err := GetQuickFilteredChanges(a, "accounts", tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := changeIDs(accounts.([]database.AccountPendingChange)); !slices.Equal(got, tt.accountIDs) {
+				t.Errorf("account changes = %v, want %v", got, tt.accountIDs)
+			}
+			checkins, err := GetQuickFilteredChanges(a, "checkins", tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, change := range checkins.([]database.CheckinPendingChange) {
+				got = append(got, change.ChangeId)
+			}
+			if !slices.Equal(got, tt.checkinIDs) {
+				t.Errorf("check-in changes = %v, want %v", got, tt.checkinIDs)
+			}
+		})
+	}
+
+	if _, err := CountQuickFilters(a, "routes"); err == nil {
+		t.Error("expected routes to be rejected")
+	}
+}
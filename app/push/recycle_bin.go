@@ -0,0 +1,93 @@
+package push
+
+import (
+	"badgermaps/api"
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"badgermaps/events"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// deleteAccount copies the account into the recycle bin and then deletes it
+// through the API. The copy is dropped again if the API call fails. Sandbox
+// pushes leave the production account alone, so nothing is copied, and
+// neither is an account erased with 'privacy erase'.
+func deleteAccount(a *app.App, client *api.APIClient, change database.AccountPendingChange) error {
+	if a.PushToSandbox() || a.IsAccountErased(change.AccountId) {
+		return client.DeleteAccount(change.AccountId)
+	}
+	if err := a.ArchiveAccountForDelete(change.AccountId, change.ChangeId); err != nil {
+		return err
+	}
+	if err := client.DeleteAccount(change.AccountId); err != nil {
+		if discardErr := database.DiscardDeletedAccount(a.DB, change.ChangeId); discardErr != nil {
+			a.Events.Dispatch(events.Warningf("push", "Could not remove recycle bin copy of account %d: %v", change.AccountId, discardErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// CountPendingDeletes returns how many pending account changes are deletes.
+func CountPendingDeletes(a *app.App) (int, error) {
+	changes, err := database.GetPendingAccountChanges(a.DB)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, change := range changes {
+		if change.ChangeType == "DELETE" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// RestoreDeletedAccount re-creates the most recently deleted copy of
+// accountID from the recycle bin and stores the new account locally. The
+// API assigns the restored account a new ID.
+func RestoreDeletedAccount(a *app.App, accountID int) (*models.Account, error) {
+	if a.API == nil {
+		return nil, fmt.Errorf("API is not configured")
+	}
+	entries, err := a.DeletedAccounts()
+	if err != nil {
+		return nil, err
+	}
+	var entry *database.DeletedAccount
+	for i := range entries {
+		if entries[i].AccountId == accountID {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("account %d is not in the recycle bin; deleted accounts can be restored for %d days", accountID, int(a.RecycleBinRetention().Hours()/24))
+	}
+
+	var account models.Account
+	if err := json.Unmarshal([]byte(entry.Data), &account); err != nil {
+		return nil, fmt.Errorf("recycle bin copy of account %d is unreadable: %w", accountID, err)
+	}
+	if err := a.DecryptAccountColumns(&account); err != nil {
+		return nil, fmt.Errorf("recycle bin copy of account %d: %w", accountID, err)
+	}
+	resp, err := a.API.CreateAccount(models.AccountUpload{Fields: app.AccountCreateFields(account)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore account %d: %w", accountID, err)
+	}
+	restored := resp.Data
+	newID := int(restored.AccountId.Int64)
+	if err := database.MarkDeletedAccountRestored(a.DB, entry.DeletedId, newID, time.Now()); err != nil {
+		a.Events.Dispatch(events.Warningf("push", "Account %d was restored as %d but the recycle bin was not updated: %v", accountID, newID, err))
+	}
+	if err := pull.StoreAccountDetailed(a, &restored); err != nil {
+		a.Events.Dispatch(events.Warningf("push", "Account %d was restored as %d but could not be stored locally; the next pull adds it: %v", accountID, newID, err))
+	}
+	a.Events.Dispatch(events.Infof("push", "Restored account %d (%s) as account %d.", accountID, entry.FullName, newID))
+	return &restored, nil
+}
@@ -0,0 +1,60 @@
+package push
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"time"
+)
+
+// PushRoutePlan creates the route a draft plan describes in BadgerMaps,
+// stores the created route as if it had been pulled, and marks the plan
+// pushed. Sandbox pushes create the route in the sandbox and leave the
+// plan a draft.
+func PushRoutePlan(a *app.App, planID int) (*models.Route, error) {
+	if err := a.CheckSchema(); err != nil {
+		return nil, err
+	}
+	plan, err := a.RoutePlan(planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status != database.RoutePlanDraft {
+		return nil, fmt.Errorf("route plan %d was already pushed as route %d", planID, plan.RouteId)
+	}
+	upload, err := a.RouteUpload(*plan)
+	if err != nil {
+		return nil, err
+	}
+	client, err := pushClient(a, "routes")
+	if err != nil {
+		return nil, err
+	}
+	a.Events.Dispatch(events.Infof("push", "Creating route %q for %s with %d stops", plan.Name, plan.RouteDate, len(upload.Waypoints)))
+	resp, err := client.CreateRoute(upload)
+	if err != nil {
+		err = fmt.Errorf("pushing route plan %d: %w", planID, err)
+		a.Events.Dispatch(events.Event{Type: "push.error", Source: "routes", Payload: events.ErrorPayload{Error: err}})
+		return nil, err
+	}
+	route := resp.Data
+	if !route.RouteId.Valid {
+		return nil, fmt.Errorf("pushing route plan %d: the API did not return the new route's ID", planID)
+	}
+	routeID := int(route.RouteId.Int64)
+	if a.PushToSandbox() {
+		a.Events.Dispatch(events.Infof("push", "Created route %d in the sandbox; plan %d stays a draft for production.", routeID, planID))
+		return &route, nil
+	}
+	if err := database.MarkRoutePlanPushed(a.DB, planID, routeID, time.Now()); err != nil {
+		return nil, fmt.Errorf("route %d was created but plan %d could not be marked pushed: %w", routeID, planID, err)
+	}
+	if err := pull.StoreRoute(a, route); err != nil {
+		a.Events.Dispatch(events.Warningf("push", "Created route %d but could not store it; pull routes to see it: %v", routeID, err))
+	}
+	a.Events.Dispatch(events.Infof("push", "Pushed route plan %d as route %d.", planID, routeID))
+	return &route, nil
+}
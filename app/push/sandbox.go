@@ -0,0 +1,48 @@
+package push
+
+import (
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+// pushClient returns the API client for a push of source, dispatching a
+// push.error when the sandbox is selected but not configured.
+func pushClient(a *app.App, source string) (*api.APIClient, error) {
+	client, err := a.PushAPI()
+	if err != nil {
+		a.Events.Dispatch(events.Event{Type: "push.error", Source: source, Payload: events.ErrorPayload{Error: err}})
+		return nil, err
+	}
+	if a.PushToSandbox() {
+		a.Events.Dispatch(events.Infof("push", "Pushing %s to the sandbox API at %s; changes stay queued for production.", source, client.BaseURL))
+	}
+	return client, nil
+}
+
+// settlePendingChange records the outcome of pushing a change. Sandbox
+// pushes are rehearsals, so the change goes back to pending and is still
+// pushed to production later.
+func settlePendingChange(a *app.App, table string, changeID int, status string) {
+	if a.PushToSandbox() {
+		status = "pending"
+	}
+	database.UpdatePendingChangeStatus(a.DB, table, changeID, status)
+}
+
+// settleValidationFailure records the field errors of an account change the
+// API rejected, so the pending-change editor can show them and the change
+// can be corrected. It reports whether apiErr was a validation error that
+// was recorded. Sandbox pushes leave the change pending as usual.
+func settleValidationFailure(a *app.App, changeID int, apiErr error) bool {
+	fields, ok := api.ValidationErrors(apiErr)
+	if !ok || a.PushToSandbox() {
+		return false
+	}
+	if err := database.SetAccountChangeValidationErrors(a.DB, changeID, fields); err != nil {
+		a.Events.Dispatch(events.Warningf("push", "Could not record the validation errors of change %d: %v", changeID, err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package push
+
+import (
+	"badgermaps/api"
+	"badgermaps/database"
+	"badgermaps/telemetry"
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// pushLanes pushes every change with up to workers lanes running at once.
+// The changes of one lane are pushed in order by a single worker. push
+// reports whether the change failed; pushLanes returns the failure count.
+func pushLanes[T any](workers int, lanes [][]T, push func(T) bool) int {
+	if workers < 1 {
+		workers = 1
+	}
+	var failed atomic.Int64
+	queue := make(chan []T)
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(lanes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for lane := range queue {
+				for _, change := range lane {
+					if push(change) {
+						failed.Add(1)
+					}
+				}
+			}
+		}()
+	}
+	for _, lane := range lanes {
+		queue <- lane
+	}
+	close(queue)
+	wg.Wait()
+	return int(failed.Load())
+}
+
+// accountLanes groups account changes by account so the changes of one
+// account are never pushed concurrently or out of order. Lanes keep the
+// order in which each account first appears.
+func accountLanes(changes []database.AccountPendingChange) [][]database.AccountPendingChange {
+	index := make(map[int]int)
+	var lanes [][]database.AccountPendingChange
+	for _, change := range changes {
+		i, ok := index[change.AccountId]
+		if !ok {
+			i = len(lanes)
+			index[change.AccountId] = i
+			lanes = append(lanes, nil)
+		}
+		lanes[i] = append(lanes[i], change)
+	}
+	return lanes
+}
+
+// checkinLanes puts every check-in in its own lane; check-ins do not
+// depend on each other.
+func checkinLanes(changes []database.CheckinPendingChange) [][]database.CheckinPendingChange {
+	lanes := make([][]database.CheckinPendingChange, len(changes))
+	for i, change := range changes {
+		lanes[i] = []database.CheckinPendingChange{change}
+	}
+	return lanes
+}
+
+// throttle waits for the entity's push limiter before an API request, in a
+// push.throttle span under ctx. The returned function releases the slot.
+func throttle(ctx context.Context, limiter *api.RateLimiter) func() {
+	ctx, span := telemetry.Start(ctx, "push.throttle")
+	release, err := limiter.Acquire(ctx)
+	span.End()
+	if err != nil {
+		return func() {}
+	}
+	return release
+}
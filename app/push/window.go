@@ -0,0 +1,161 @@
+package push
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"time"
+)
+
+// OutsideWindowError is returned when a push is attempted outside the
+// configured push window. The pending changes are left queued.
+type OutsideWindowError struct {
+	NextOpen time.Time
+}
+
+func (e *OutsideWindowError) Error() string {
+	return fmt.Sprintf("outside push window; changes queued until %s", e.NextOpen.Format("Mon Jan 2 15:04 MST"))
+}
+
+// CheckPushWindow returns an *OutsideWindowError when pushes are not allowed
+// at now, or an error when the window is misconfigured.
+func CheckPushWindow(a *app.App, now time.Time) error {
+	if a.State != nil && a.State.IgnorePushWindow {
+		return nil
+	}
+	// Sandbox pushes never reach production, so the window does not apply.
+	if a.PushToSandbox() {
+		return nil
+	}
+	window := a.Config.PushWindow
+	open, err := window.IsOpen(now)
+	if err != nil {
+		return err
+	}
+	if open {
+		return nil
+	}
+	next, err := window.NextOpen(now)
+	if err != nil {
+		return err
+	}
+	return &OutsideWindowError{NextOpen: next}
+}
+
+// checkWindowForPush dispatches an info event for queued pushes so listeners
+// see why nothing was sent.
+func checkWindowForPush(a *app.App, source string) error {
+	err := CheckPushWindow(a, time.Now())
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*OutsideWindowError); ok {
+		a.Events.Dispatch(events.Infof("push", "Skipping %s push: %v", source, err))
+		return err
+	}
+	err = fmt.Errorf("invalid push window: %w", err)
+	a.Events.Dispatch(events.Event{Type: "push.error", Source: source, Payload: events.ErrorPayload{Error: err}})
+	return err
+}
+
+// windowRecheckInterval is how often a flusher with no window enabled checks
+// whether a config reload turned one on.
+const windowRecheckInterval = time.Minute
+
+// StartWindowFlusher pushes queued changes each time the push window opens
+// until stop is closed. While no window is enabled it idles, so a window
+// added by a config reload takes effect without a restart.
+func StartWindowFlusher(a *app.App, stop <-chan struct{}) {
+	go func() {
+		if open, _ := a.Config.PushWindow.IsOpen(time.Now()); open && a.Config.PushWindow.Enabled {
+			FlushQueued(a)
+		}
+		for {
+			if !a.Config.PushWindow.Enabled {
+				select {
+				case <-stop:
+					return
+				case <-time.After(windowRecheckInterval):
+				}
+				continue
+			}
+			next, err := a.Config.PushWindow.NextOpen(time.Now())
+			if err != nil {
+				a.Events.Dispatch(events.Warningf("push", "Push window flusher stopped: %v", err))
+				return
+			}
+			// Wait for the next opening when already inside the window so the
+			// flush happens once per window rather than continuously.
+			if !next.After(time.Now()) {
+				next, err = nextOpeningAfterClose(a.Config.PushWindow, time.Now())
+				if err != nil {
+					a.Events.Dispatch(events.Warningf("push", "Push window flusher stopped: %v", err))
+					return
+				}
+			}
+			// Re-evaluate at least every windowRecheckInterval so a reloaded
+			// window replaces the one being waited on.
+			wait := time.Until(next)
+			recheck := wait > windowRecheckInterval
+			if recheck {
+				wait = windowRecheckInterval
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if !recheck {
+				FlushQueued(a)
+			}
+		}
+	}()
+}
+
+// FlushQueued pushes any pending account and check-in changes.
+func FlushQueued(a *app.App) {
+	accounts, err := database.GetPendingAccountChanges(a.DB)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("push", "Could not read queued account changes: %v", err))
+		return
+	}
+	checkins, err := database.GetPendingCheckinChanges(a.DB)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("push", "Could not read queued check-in changes: %v", err))
+		return
+	}
+	if len(accounts) == 0 && len(checkins) == 0 {
+		return
+	}
+	a.Events.Dispatch(events.Infof("push", "Push window opened; flushing %d account and %d check-in changes", len(accounts), len(checkins)))
+	if len(accounts) > 0 {
+		if err := RunPushAccounts(a); err != nil {
+			a.Events.Dispatch(events.Warningf("push", "Queued account push failed: %v", err))
+		}
+	}
+	if len(checkins) > 0 {
+		if err := RunPushCheckins(a); err != nil {
+			a.Events.Dispatch(events.Warningf("push", "Queued check-in push failed: %v", err))
+		}
+	}
+}
+
+// nextOpeningAfterClose finds the start of the window following the one that
+// contains now, stepping forward a minute at a time until the window closes.
+func nextOpeningAfterClose(window app.PushWindow, now time.Time) (time.Time, error) {
+	t := now
+	for i := 0; i < 8*24*60; i++ {
+		t = t.Add(time.Minute)
+		open, err := window.IsOpen(t)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !open {
+			return window.NextOpen(t)
+		}
+	}
+	return time.Time{}, fmt.Errorf("push window never closes")
+}
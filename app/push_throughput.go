@@ -0,0 +1,97 @@
+package app
+
+import (
+	"badgermaps/api"
+)
+
+// EntityThroughput limits how fast one kind of staged change is pushed.
+// Zero values keep the default of one change at a time with no rate limit
+// beyond the shared request budget.
+type EntityThroughput struct {
+	Workers           int     `yaml:"workers,omitempty"`
+	RequestsPerSecond float64 `yaml:"requests_per_second,omitempty"`
+}
+
+// PushThroughputConfig sets the push throughput per entity type. Check-ins
+// are independent of each other and safe to push in parallel; account
+// changes are pushed in parallel across accounts, but the changes of one
+// account always go out one at a time and in the order they were staged.
+type PushThroughputConfig struct {
+	Accounts EntityThroughput `yaml:"accounts,omitempty"`
+	Checkins EntityThroughput `yaml:"checkins,omitempty"`
+}
+
+// MaxPushWorkers caps the workers of one push so it cannot open more
+// connections than the API tolerates.
+const MaxPushWorkers = 10
+
+// PushEntities are the entity types with their own push throughput.
+var PushEntities = []string{"accounts", "checkins"}
+
+func (c PushThroughputConfig) entity(entity string) EntityThroughput {
+	if entity == "checkins" {
+		return c.Checkins
+	}
+	return c.Accounts
+}
+
+// PushThroughput returns the throughput settings for pushing entity
+// ("accounts" or "checkins"), with workers clamped to 1..MaxPushWorkers and
+// negative rates treated as unlimited.
+func (a *App) PushThroughput(entity string) EntityThroughput {
+	var t EntityThroughput
+	if a.Config != nil {
+		t = a.Config.PushThroughput.entity(entity)
+	}
+	if t.Workers < 1 {
+		t.Workers = 1
+	}
+	if t.Workers > MaxPushWorkers {
+		t.Workers = MaxPushWorkers
+	}
+	if t.RequestsPerSecond < 0 {
+		t.RequestsPerSecond = 0
+	}
+	return t
+}
+
+// SetPushThroughput changes the throughput of entity and applies it to
+// running pushes. The caller saves the config.
+func (a *App) SetPushThroughput(entity string, t EntityThroughput) {
+	if entity == "checkins" {
+		a.Config.PushThroughput.Checkins = t
+	} else {
+		a.Config.PushThroughput.Accounts = t
+	}
+	a.applyPushThroughput()
+}
+
+// PushLimiter returns the limiter pacing pushes of entity. It is shared by
+// every push of that entity, so a scheduled push and a manual one together
+// stay within the configured rate. Requests also draw from the app-wide
+// RateLimiter.
+func (a *App) PushLimiter(entity string) *api.RateLimiter {
+	a.pushLimitersMu.Lock()
+	defer a.pushLimitersMu.Unlock()
+	if a.pushLimiters == nil {
+		a.pushLimiters = make(map[string]*api.RateLimiter)
+	}
+	limiter, ok := a.pushLimiters[entity]
+	if !ok {
+		t := a.PushThroughput(entity)
+		limiter = api.NewRateLimiter(t.RequestsPerSecond, 1, 0)
+		a.pushLimiters[entity] = limiter
+	}
+	return limiter
+}
+
+// applyPushThroughput pushes the configured rates into existing push
+// limiters. Worker counts are read when each push starts.
+func (a *App) applyPushThroughput() {
+	a.pushLimitersMu.Lock()
+	defer a.pushLimitersMu.Unlock()
+	for entity, limiter := range a.pushLimiters {
+		t := a.PushThroughput(entity)
+		limiter.Update(t.RequestsPerSecond, 1, 0)
+	}
+}
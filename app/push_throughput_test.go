@@ -0,0 +1,54 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPushThroughputDefaultsAndBounds(t *testing.T) {
+	a := NewApp()
+	if got := a.PushThroughput("accounts"); got.Workers != 1 || got.RequestsPerSecond != 0 {
+		t.Fatalf("default accounts throughput = %+v, want one worker and no rate limit", got)
+	}
+	a.Config.PushThroughput = PushThroughputConfig{
+		Accounts: EntityThroughput{Workers: 50, RequestsPerSecond: -2},
+		Checkins: EntityThroughput{Workers: 4, RequestsPerSecond: 2.5},
+	}
+	if got := a.PushThroughput("accounts"); got.Workers != MaxPushWorkers || got.RequestsPerSecond != 0 {
+		t.Errorf("accounts throughput = %+v, want workers capped and rate unlimited", got)
+	}
+	if got := a.PushThroughput("checkins"); got.Workers != 4 || got.RequestsPerSecond != 2.5 {
+		t.Errorf("checkins throughput = %+v, want the configured values", got)
+	}
+}
+
+func TestReloadConfigAppliesPushThroughput(t *testing.T) {
+	a := NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	limiter := a.PushLimiter("checkins")
+
+	writeReloadConfig(t, a.ConfigFile, `
+max_concurrent_requests: 5
+push_throughput:
+  checkins:
+    workers: 6
+    requests_per_second: 4
+`)
+	reload, err := a.ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	found := false
+	for _, name := range reload.Applied {
+		found = found || name == "push_throughput"
+	}
+	if !found {
+		t.Fatalf("applied = %v, want push_throughput", reload.Applied)
+	}
+	if got := a.PushThroughput("checkins"); got.Workers != 6 || got.RequestsPerSecond != 4 {
+		t.Fatalf("checkins throughput after reload = %+v", got)
+	}
+	if a.PushLimiter("checkins") != limiter {
+		t.Fatal("reload replaced the check-in limiter; running pushes would not see the new rate")
+	}
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PushWindow restricts when staged changes may be pushed to BadgerMaps.
+// Outside the window changes stay queued as pending. When End is not after
+// Start the window runs overnight, e.g. 22:00-06:00, and belongs to the day
+// it starts on.
+type PushWindow struct {
+	Enabled  bool     `yaml:"enabled"`
+	Days     []string `yaml:"days,omitempty"`     // mon..sun; empty means every day
+	Start    string   `yaml:"start,omitempty"`    // HH:MM, inclusive
+	End      string   `yaml:"end,omitempty"`      // HH:MM, exclusive
+	Timezone string   `yaml:"timezone,omitempty"` // IANA name; empty uses local time
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// resolvedPushWindow is a validated PushWindow.
+type resolvedPushWindow struct {
+	days       map[time.Weekday]bool
+	start, end time.Duration
+	loc        *time.Location
+}
+
+func (w PushWindow) resolve() (*resolvedPushWindow, error) {
+	r := &resolvedPushWindow{days: make(map[time.Weekday]bool), loc: time.Local}
+	if w.Timezone != "" {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid push window timezone %q: %w", w.Timezone, err)
+		}
+		r.loc = loc
+	}
+	for _, day := range w.Days {
+		key := strings.ToLower(strings.TrimSpace(day))
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		weekday, ok := weekdayNames[key]
+		if !ok {
+			return nil, fmt.Errorf("invalid push window day %q", day)
+		}
+		r.days[weekday] = true
+	}
+	if len(r.days) == 0 {
+		for _, weekday := range weekdayNames {
+			r.days[weekday] = true
+		}
+	}
+
+	var err error
+	if r.start, err = parseClock(w.Start, 0); err != nil {
+		return nil, fmt.Errorf("invalid push window start: %w", err)
+	}
+	if r.end, err = parseClock(w.End, 24*time.Hour); err != nil {
+		return nil, fmt.Errorf("invalid push window end: %w", err)
+	}
+	return r, nil
+}
+
+func parseClock(value string, fallback time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not in HH:MM format", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Validate reports configuration errors without evaluating the window.
+func (w PushWindow) Validate() error {
+	if !w.Enabled {
+		return nil
+	}
+	_, err := w.resolve()
+	return err
+}
+
+// IsOpen reports whether pushes are allowed at t. A disabled window is always open.
+func (w PushWindow) IsOpen(t time.Time) (bool, error) {
+	if !w.Enabled {
+		return true, nil
+	}
+	r, err := w.resolve()
+	if err != nil {
+		return false, err
+	}
+	return r.isOpen(t), nil
+}
+
+// NextOpen returns the earliest time at or after t when the window is open.
+func (w PushWindow) NextOpen(t time.Time) (time.Time, error) {
+	if !w.Enabled {
+		return t, nil
+	}
+	r, err := w.resolve()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if r.isOpen(t) {
+		return t, nil
+	}
+	local := t.In(r.loc)
+	for i := 0; i <= 7; i++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, r.loc)
+		if !r.days[day.Weekday()] {
+			continue
+		}
+		open := r.at(day, r.start)
+		if open.After(t) {
+			return open, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("push window never opens")
+}
+
+// at returns the wall-clock offset on day, honouring DST transitions.
+func (r *resolvedPushWindow) at(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, r.loc)
+}
+
+func (r *resolvedPushWindow) isOpen(t time.Time) bool {
+	local := t.In(r.loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, r.loc)
+	if r.end > r.start {
+		return r.days[today.Weekday()] && !local.Before(r.at(today, r.start)) && local.Before(r.at(today, r.end))
+	}
+	// Overnight window: open from start today, or until end if it began yesterday.
+	if r.days[today.Weekday()] && !local.Before(r.at(today, r.start)) {
+		return true
+	}
+	yesterday := today.AddDate(0, 0, -1)
+	return r.days[yesterday.Weekday()] && local.Before(r.at(today, r.end))
+}
+
+// String describes the window for logs and the UI.
+func (w PushWindow) String() string {
+	if !w.Enabled {
+		return "any time"
+	}
+	days := "every day"
+	if len(w.Days) > 0 {
+		days = strings.Join(w.Days, ", ")
+	}
+	start, end := w.Start, w.End
+	if start == "" {
+		start = "00:00"
+	}
+	if end == "" {
+		end = "24:00"
+	}
+	zone := w.Timezone
+	if zone == "" {
+		zone = "local time"
+	}
+	return fmt.Sprintf("%s %s-%s (%s)", days, start, end, zone)
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPushWindowIsOpen(t *testing.T) {
+	businessHours := PushWindow{Enabled: true, Days: []string{"mon", "tue", "wed", "thu", "friday"}, Start: "09:00", End: "17:00", Timezone: "America/New_York"}
+	overnight := PushWindow{Enabled: true, Days: []string{"fri"}, Start: "22:00", End: "06:00", Timezone: "UTC"}
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		window PushWindow
+		at     time.Time
+		want   bool
+	}{
+		{"disabled is always open", PushWindow{}, time.Date(2024, 3, 9, 3, 0, 0, 0, time.UTC), true},
+		{"weekday inside hours", businessHours, time.Date(2024, 3, 6, 9, 0, 0, 0, ny), true},
+		{"weekday at end is closed", businessHours, time.Date(2024, 3, 6, 17, 0, 0, 0, ny), false},
+		{"evaluated in window timezone", businessHours, time.Date(2024, 3, 6, 13, 30, 0, 0, time.UTC), false},
+		{"weekend is closed", businessHours, time.Date(2024, 3, 9, 12, 0, 0, 0, ny), false},
+		{"overnight start day", overnight, time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC), true},
+		{"overnight spills into next day", overnight, time.Date(2024, 3, 9, 5, 59, 0, 0, time.UTC), true},
+		{"overnight closed after end", overnight, time.Date(2024, 3, 9, 6, 0, 0, 0, time.UTC), false},
+		{"overnight does not start on other days", overnight, time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.IsOpen(tt.at)
+			if err != nil {
+				t.Fatalf("IsOpen returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("IsOpen(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushWindowNextOpen(t *testing.T) {
+	window := PushWindow{Enabled: true, Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00", Timezone: "UTC"}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{"inside window", time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC), time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)},
+		{"before opening", time.Date(2024, 3, 6, 7, 0, 0, 0, time.UTC), time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC)},
+		{"after closing", time.Date(2024, 3, 6, 18, 0, 0, 0, time.UTC), time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC)},
+		{"friday evening skips weekend", time.Date(2024, 3, 8, 18, 0, 0, 0, time.UTC), time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := window.NextOpen(tt.at)
+			if err != nil {
+				t.Fatalf("NextOpen returned error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("NextOpen(%s) = %s, want %s", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushWindowValidate(t *testing.T) {
+	invalid := []PushWindow{
+		{Enabled: true, Days: []string{"someday"}},
+		{Enabled: true, Start: "9am"},
+		{Enabled: true, Timezone: "Nowhere/Special"},
+	}
+	for _, w := range invalid {
+		if err := w.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", w)
+		}
+	}
+	if err := (PushWindow{Start: "bad"}).Validate(); err != nil {
+		t.Errorf("disabled window should not be validated: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"badgermaps/database/repository"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Confirmation policies for pushing account deletions.
+const (
+	// ConfirmDeletesAlways holds DELETE changes until the push is confirmed
+	// with --confirm-deletes, a prompt, or the GUI dialog. It is the default.
+	ConfirmDeletesAlways = "always"
+	// ConfirmDeletesNever pushes DELETE changes like any other change.
+	ConfirmDeletesNever = "never"
+)
+
+// DefaultRecycleBinRetentionDays is used when recycle_bin.retention_days is
+// not configured.
+const DefaultRecycleBinRetentionDays = 30
+
+// RecycleBinConfig controls how account deletions are pushed and how long
+// deleted accounts can be restored.
+type RecycleBinConfig struct {
+	ConfirmDeletes string `yaml:"confirm_deletes,omitempty"`
+	RetentionDays  int    `yaml:"retention_days,omitempty"`
+}
+
+// Validate checks the confirmation policy.
+func (c RecycleBinConfig) Validate() error {
+	switch c.ConfirmDeletes {
+	case "", ConfirmDeletesAlways, ConfirmDeletesNever:
+	default:
+		return fmt.Errorf("recycle_bin.confirm_deletes must be %q or %q, got %q", ConfirmDeletesAlways, ConfirmDeletesNever, c.ConfirmDeletes)
+	}
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("recycle_bin.retention_days must not be negative")
+	}
+	return nil
+}
+
+// DeletesNeedConfirmation reports whether DELETE changes are held until the
+// push is confirmed. A misconfigured policy also holds them.
+func (a *App) DeletesNeedConfirmation() bool {
+	if a.State != nil && a.State.ConfirmDeletes {
+		return false
+	}
+	return a.Config == nil || a.Config.RecycleBin.ConfirmDeletes != ConfirmDeletesNever
+}
+
+// RecycleBinRetention returns how long deleted accounts can be restored.
+func (a *App) RecycleBinRetention() time.Duration {
+	days := DefaultRecycleBinRetentionDays
+	if a.Config != nil && a.Config.RecycleBin.RetentionDays > 0 {
+		days = a.Config.RecycleBin.RetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ArchiveAccountForDelete copies the locally stored account into the
+// recycle bin before its DELETE change is pushed. It fails when the account
+// is not stored locally, since it could not be restored.
+func (a *App) ArchiveAccountForDelete(accountID, changeID int) error {
+	if a.DB == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	account, err := database.GetAccountByID(a.DB, accountID)
+	if err != nil {
+		return fmt.Errorf("account %d is not in the local database, so it could not be restored; pull it before pushing the delete: %w", accountID, err)
+	}
+	data, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	return database.ArchiveDeletedAccount(a.DB, database.DeletedAccount{
+		AccountId: accountID,
+		ChangeId:  changeID,
+		FullName:  account.FullName.String,
+		Data:      string(data),
+		DeletedAt: time.Now(),
+	})
+}
+
+// DeletedAccounts returns the deleted accounts that can still be restored,
+// newest first. Copies older than the retention window are left out but
+// stay stored until PurgeRecycleBin removes them.
+func (a *App) DeletedAccounts() ([]database.DeletedAccount, error) {
+	if a.DB == nil {
+		return nil, fmt.Errorf("database is not configured")
+	}
+	return database.GetDeletedAccounts(a.DB, time.Now().Add(-a.RecycleBinRetention()))
+}
+
+// PurgeRecycleBin removes the copies of accounts deleted before the
+// retention window and returns how many were removed.
+func (a *App) PurgeRecycleBin() (int64, error) {
+	if a.DB == nil {
+		return 0, fmt.Errorf("database is not configured")
+	}
+	n, err := database.PurgeDeletedAccounts(a.DB, time.Now().Add(-a.RecycleBinRetention()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired deleted accounts: %w", err)
+	}
+	return n, nil
+}
+
+// AccountCreateFields returns the API fields that re-create account: its
+// editable fields and its address. Empty fields are left out.
+func AccountCreateFields(account models.Account) map[string]string {
+	stored := repository.AccountWithLabels{Account: account}
+	fields := make(map[string]string)
+	for column, field := range accountEditableFields {
+		if value, ok := stored.Value(column); ok && value != "" {
+			fields[field] = value
+		}
+	}
+	if address := account.OriginalAddress.ValueOrZero(); address != "" {
+		fields["address"] = address
+	}
+	return fields
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestRecycleBinPurge(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "bin.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	a.DB = db
+
+	for _, entry := range []database.DeletedAccount{
+		{AccountId: 1, ChangeId: 1, FullName: "Old", Data: "{}", DeletedAt: time.Now().Add(-60 * 24 * time.Hour)},
+		{AccountId: 2, ChangeId: 2, FullName: "New", Data: "{}", DeletedAt: time.Now()},
+	} {
+		if err := database.ArchiveDeletedAccount(db, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Listing leaves expired copies out without removing them.
+	for i := 0; i < 2; i++ {
+		entries, err := a.DeletedAccounts()
+		if err != nil || len(entries) != 1 || entries[0].AccountId != 2 {
+			t.Fatalf("DeletedAccounts = %+v, %v", entries, err)
+		}
+	}
+	n, err := a.PurgeRecycleBin()
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeRecycleBin = %d, %v; want 1 expired copy removed", n, err)
+	}
+	if n, err := a.PurgeRecycleBin(); err != nil || n != 0 {
+		t.Fatalf("second PurgeRecycleBin = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,321 @@
+package app
+
+import (
+	"badgermaps/api"
+	"badgermaps/events"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// ConfigReload describes which changed settings ReloadConfig applied to the
+// running process and which only take effect after a restart.
+type ConfigReload struct {
+	Applied         []string
+	RestartRequired []string
+}
+
+// Changed reports whether the reload found any differences.
+func (r *ConfigReload) Changed() bool {
+	return len(r.Applied) > 0 || len(r.RestartRequired) > 0
+}
+
+// ReloadConfig re-reads the config file and applies what can change without
+// a restart: cron jobs, webhook toggles, request logging, log level, rate
+// limits, event actions, push settings, and the API key. Connections, the listen address,
+// plugins, and telemetry keep their current values until the process restarts. Work
+// already in flight is not interrupted. On error the current config is kept.
+func (a *App) ReloadConfig() (reload *ConfigReload, err error) {
+	defer func() {
+		if err != nil {
+			a.Events.Dispatch(events.Event{Type: "config.reload.error", Source: "config", Payload: events.ErrorPayload{Error: err}})
+			return
+		}
+		a.Events.Dispatch(events.Event{Type: "config.reload", Source: "config", Payload: events.ConfigReloadPayload{
+			Applied:         reload.Applied,
+			RestartRequired: reload.RestartRequired,
+		}})
+	}()
+
+	if a.ConfigFile == "" {
+		return nil, fmt.Errorf("no configuration file loaded, cannot reload")
+	}
+	data, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	next := defaultConfig()
+	remoteBase, err := a.readConfig(data, next)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	// Run the same clean-up LoadConfig does against the new settings.
+	staged := &App{ConfigFile: a.ConfigFile, Config: next, Events: a.Events, remoteBase: remoteBase}
+	staged.migrateActionNames()
+	staged.validateAndCleanActions()
+	staged.ensureExecActionShellDefaults()
+	staged.ensureServerWebhookDefaults()
+	staged.ensureThemePreference()
+	if err := next.PushWindow.Validate(); err != nil {
+		return nil, err
+	}
+	if err := next.RecycleBin.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidatePullCustomFields(next.PullCustomFields); err != nil {
+		return nil, err
+	}
+	if err := next.Telemetry.Validate(); err != nil {
+		return nil, err
+	}
+	if err := next.Server.WebhookBuffer.Validate(); err != nil {
+		return nil, err
+	}
+	if err := api.ValidateHeaders(next.API.Headers); err != nil {
+		return nil, err
+	}
+	if _, err := ParseDisplayNameTemplate(next.AccountDisplayName); err != nil {
+		return nil, err
+	}
+	if err := ValidatePullStaleAfter(next.PullStaleAfter); err != nil {
+		return nil, err
+	}
+	if next.Server.ACME.Enabled {
+		if err := next.Server.ACME.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := next.GuiTabs.Validate(); err != nil {
+		return nil, err
+	}
+	if err := next.Alerts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := next.Enrichment.Validate(); err != nil {
+		return nil, err
+	}
+	if err := next.CSVExport.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := ParseConflictResolution(next.ConflictResolution); err != nil {
+		return nil, err
+	}
+	if err := ValidateFieldRules(next.FieldRules); err != nil {
+		return nil, err
+	}
+	if err := ValidateTerritories(next.Territories); err != nil {
+		return nil, err
+	}
+	if next.LogLevel != "" {
+		if _, err := events.ParseLogLevel(next.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	cur := a.Config
+	reload = &ConfigReload{}
+	changed := func(name string, old, new interface{}) bool {
+		if reflect.DeepEqual(old, new) {
+			return false
+		}
+		reload.Applied = append(reload.Applied, name)
+		return true
+	}
+	restartOnly := func(name string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			reload.RestartRequired = append(reload.RestartRequired, name)
+		}
+	}
+
+	// Reschedule first so an invalid cron expression rejects the whole
+	// reload before anything else is applied.
+	if changed("cron_jobs", cur.CronJobs, next.CronJobs) && a.Server != nil && a.Server.IsScheduling() {
+		if err := a.Server.Reschedule(next.CronJobs, a); err != nil {
+			return nil, err
+		}
+	}
+	cur.CronJobs = next.CronJobs
+
+	rateLimits := changed("max_concurrent_requests", cur.MaxConcurrentRequests, next.MaxConcurrentRequests)
+	rateLimits = changed("requests_per_second", cur.RequestsPerSecond, next.RequestsPerSecond) || rateLimits
+	rateLimits = changed("request_burst", cur.RequestBurst, next.RequestBurst) || rateLimits
+	cur.MaxConcurrentRequests = next.MaxConcurrentRequests
+	cur.RequestsPerSecond = next.RequestsPerSecond
+	cur.RequestBurst = next.RequestBurst
+	if changed("low_bandwidth", cur.LowBandwidth, next.LowBandwidth) {
+		cur.LowBandwidth = next.LowBandwidth
+		if a.API != nil {
+			a.API.SetCompressRequests(a.LowBandwidth())
+		}
+		rateLimits = true
+	}
+	if rateLimits {
+		a.applyMaxConcurrency()
+		a.applyRateLimits()
+	}
+
+	changed("server.webhooks", cur.Server.Webhooks, next.Server.Webhooks)
+	cur.Server.Webhooks = next.Server.Webhooks
+	changed("webhook_catch_all", cur.WebhookCatchAll, next.WebhookCatchAll)
+	cur.WebhookCatchAll = next.WebhookCatchAll
+	changed("server.log_requests", cur.Server.LogRequests, next.Server.LogRequests)
+	cur.Server.LogRequests = next.Server.LogRequests
+	if a.State != nil {
+		a.State.ServerLogRequests = next.Server.LogRequests
+	}
+
+	if changed("log_level", cur.LogLevel, next.LogLevel) {
+		cur.LogLevel = next.LogLevel
+		a.applyLogLevel()
+	}
+
+	changed("event_actions", cur.EventActions, next.EventActions)
+	cur.EventActions = next.EventActions
+	changed("push_window", cur.PushWindow, next.PushWindow)
+	cur.PushWindow = next.PushWindow
+	if changed("push_throughput", cur.PushThroughput, next.PushThroughput) {
+		cur.PushThroughput = next.PushThroughput
+		a.applyPushThroughput()
+	}
+	changed("push_quick_filter", cur.PushQuickFilter, next.PushQuickFilter)
+	cur.PushQuickFilter = next.PushQuickFilter
+	changed("custom_checkins", cur.CustomCheckins, next.CustomCheckins)
+	cur.CustomCheckins = next.CustomCheckins
+	changed("stale_account_days", cur.StaleAccountDays, next.StaleAccountDays)
+	cur.StaleAccountDays = next.StaleAccountDays
+	changed("pull_stale_after", cur.PullStaleAfter, next.PullStaleAfter)
+	cur.PullStaleAfter = next.PullStaleAfter
+	changed("batch_size", cur.BatchSize, next.BatchSize)
+	cur.BatchSize = next.BatchSize
+	changed("pull_radius", cur.PullRadius, next.PullRadius)
+	cur.PullRadius = next.PullRadius
+	changed("pull_custom_fields", cur.PullCustomFields, next.PullCustomFields)
+	cur.PullCustomFields = next.PullCustomFields
+	changed("archive", cur.Archive, next.Archive)
+	cur.Archive = next.Archive
+	changed("recycle_bin", cur.RecycleBin, next.RecycleBin)
+	cur.RecycleBin = next.RecycleBin
+	changed("display_timezone", cur.DisplayTimezone, next.DisplayTimezone)
+	cur.DisplayTimezone = next.DisplayTimezone
+	changed("account_display_name", cur.AccountDisplayName, next.AccountDisplayName)
+	cur.AccountDisplayName = next.AccountDisplayName
+	changed("change_capture", cur.ChangeCapture, next.ChangeCapture)
+	cur.ChangeCapture = next.ChangeCapture
+	changed("theme_preference", cur.ThemePreference, next.ThemePreference)
+	cur.ThemePreference = next.ThemePreference
+	changed("disable_update_check", cur.DisableUpdateCheck, next.DisableUpdateCheck)
+	cur.DisableUpdateCheck = next.DisableUpdateCheck
+	changed("alerts", cur.Alerts, next.Alerts)
+	cur.Alerts = next.Alerts
+	changed("enrichment", cur.Enrichment, next.Enrichment)
+	cur.Enrichment = next.Enrichment
+	changed("csv_export", cur.CSVExport, next.CSVExport)
+	cur.CSVExport = next.CSVExport
+	changed("conflict_resolution", cur.ConflictResolution, next.ConflictResolution)
+	cur.ConflictResolution = next.ConflictResolution
+	if changed("field_rules", cur.FieldRules, next.FieldRules) {
+		cur.FieldRules = next.FieldRules
+		a.applyFieldRules()
+	}
+	changed("api_templates", cur.APITemplates, next.APITemplates)
+	cur.APITemplates = next.APITemplates
+	changed("territories", cur.Territories, next.Territories)
+	cur.Territories = next.Territories
+	changed("remote_config", cur.RemoteConfig, next.RemoteConfig)
+	cur.RemoteConfig = next.RemoteConfig
+	a.remoteBase = remoteBase
+
+	// A new key is swapped into the shared client so a rotation needs no
+	// restart; the rest of the API settings do.
+	if changed("api.api_key", cur.API.APIKey, next.API.APIKey) {
+		a.applyAPIKey(next.API.APIKey)
+	}
+	changed("api.secondary_api_key", cur.API.SecondaryAPIKey, next.API.SecondaryAPIKey)
+	cur.API.SecondaryAPIKey = next.API.SecondaryAPIKey
+	headers := changed("api.headers", cur.API.Headers, next.API.Headers)
+	if changed("api.user_agent", cur.API.UserAgent, next.API.UserAgent) || headers {
+		cur.API.Headers, cur.API.UserAgent = next.API.Headers, next.API.UserAgent
+		if a.API != nil {
+			a.API.SetRequestHeaders(api.UserAgent(Version, cur.API.UserAgent), cur.API.Headers)
+		}
+	}
+	curAPI, nextAPI := cur.API, next.API
+	nextAPI.APIKey, nextAPI.SecondaryAPIKey = curAPI.APIKey, curAPI.SecondaryAPIKey
+	restartOnly("api", curAPI, nextAPI)
+	// The running database comes from the active environment, so compare
+	// against that environment's settings in the new file.
+	nextDB := next.DB
+	if a.activeEnv != "" {
+		if env, err := next.environment(a.activeEnv); err == nil {
+			nextDB = env.DB
+		}
+	}
+	restartOnly("db", cur.DB, nextDB)
+	restartOnly("environment", cur.Environment, next.Environment)
+	changed("environments", cur.Environments, next.Environments)
+	cur.Environments = next.Environments
+	restartOnly("server.host", cur.Server.Host, next.Server.Host)
+	restartOnly("server.port", cur.Server.Port, next.Server.Port)
+	restartOnly("server.tls", []interface{}{cur.Server.TLSEnabled, cur.Server.TLSCert, cur.Server.TLSKey},
+		[]interface{}{next.Server.TLSEnabled, next.Server.TLSCert, next.Server.TLSKey})
+	restartOnly("server.tunnel", cur.Server.Tunnel, next.Server.Tunnel)
+	restartOnly("server.webhook_buffer", cur.Server.WebhookBuffer, next.Server.WebhookBuffer)
+	restartOnly("server.acme", cur.Server.ACME, next.Server.ACME)
+	restartOnly("log_file", cur.LogFile, next.LogFile)
+	restartOnly("plugins", cur.Plugins, next.Plugins)
+	restartOnly("telemetry", cur.Telemetry, next.Telemetry)
+	restartOnly("gui_tabs", cur.GuiTabs, next.GuiTabs)
+
+	if reload.Changed() {
+		a.Events.Dispatch(events.Infof("config", "Reloaded %s: applied %v", a.ConfigFile, reload.Applied))
+		if len(reload.RestartRequired) > 0 {
+			a.Events.Dispatch(events.Warningf("config", "Restart to apply changes to %v", reload.RestartRequired))
+		}
+	}
+	return reload, nil
+}
+
+// WatchConfigFile reloads the config whenever the file changes on disk until
+// stop is closed. It polls so it works the same on every platform and with
+// editors that replace the file instead of writing it in place.
+func (a *App) WatchConfigFile(interval time.Duration, stop <-chan struct{}) {
+	if a.ConfigFile == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	stamp := func() (time.Time, int64) {
+		info, err := os.Stat(a.ConfigFile)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+	lastMod, lastSize := stamp()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			mod, size := stamp()
+			if size < 0 || (mod.Equal(lastMod) && size == lastSize) {
+				continue
+			}
+			lastMod, lastSize = mod, size
+			if _, err := a.ReloadConfig(); err != nil {
+				a.Events.Dispatch(events.Warningf("config", "Config change not applied: %v", err))
+			}
+			// Clean-up during reload may rewrite the file; don't treat that
+			// as another change.
+			lastMod, lastSize = stamp()
+		}
+	}()
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"badgermaps/events"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeReloadConfig(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestReloadConfigAppliesLiveSettings(t *testing.T) {
+	a := NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	a.Config.DB.Path = "old.db"
+	a.Config.MaxConcurrentRequests = 5
+	a.MaxConcurrentRequests = 5
+	a.Config.CronJobs = nil
+
+	listener, err := events.NewLogListener(a.State, filepath.Join(t.TempDir(), "log.txt"))
+	if err != nil {
+		t.Fatalf("NewLogListener: %v", err)
+	}
+	defer listener.Close()
+	a.LogListener = listener
+
+	var reloaded events.ConfigReloadPayload
+	done := make(chan struct{}, 1)
+	a.Events.Subscribe("config.reload", func(e events.Event) {
+		reloaded = e.Payload.(events.ConfigReloadPayload)
+		done <- struct{}{}
+	})
+
+	writeReloadConfig(t, a.ConfigFile, `
+db:
+  type: sqlite3
+  path: new.db
+max_concurrent_requests: 3
+log_level: warn
+webhook_catch_all: true
+server:
+  webhooks:
+    checkin: false
+cron_jobs:
+  - name: nightly
+    schedule: "0 2 * * *"
+    action:
+      type: exec
+      args:
+        command: "true"
+`)
+	reload, err := a.ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+
+	if a.MaxConcurrentRequests != 3 || !a.Config.WebhookCatchAll || a.Config.Server.Webhooks[WebhookCheckin] {
+		t.Errorf("live settings not applied: max=%d catchAll=%v webhooks=%v", a.MaxConcurrentRequests, a.Config.WebhookCatchAll, a.Config.Server.Webhooks)
+	}
+	if !a.Config.Server.Webhooks[WebhookAccountCreate] {
+		t.Error("expected omitted webhook to keep its default")
+	}
+	if len(a.Config.CronJobs) != 1 || a.Config.LogLevel != "warn" {
+		t.Errorf("expected cron jobs and log level to update, got %d jobs and %q", len(a.Config.CronJobs), a.Config.LogLevel)
+	}
+	if a.Config.DB.Path != "old.db" {
+		t.Errorf("db settings must wait for a restart, got path %q", a.Config.DB.Path)
+	}
+	if strings.Join(reload.RestartRequired, ",") != "db" {
+		t.Errorf("RestartRequired = %v, want [db]", reload.RestartRequired)
+	}
+	for _, want := range []string{"cron_jobs", "max_concurrent_requests", "server.webhooks", "webhook_catch_all", "log_level"} {
+		if !containsSetting(reload.Applied, want) {
+			t.Errorf("Applied = %v, missing %s", reload.Applied, want)
+		}
+	}
+
+	select {
+	case <-done:
+		if len(reloaded.Applied) != len(reload.Applied) {
+			t.Errorf("event payload %v does not match result %v", reloaded.Applied, reload.Applied)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for config.reload event")
+	}
+}
+
+func TestReloadConfigRejectsInvalidSettings(t *testing.T) {
+	a := NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	a.Config.MaxConcurrentRequests = 5
+	if err := a.Server.Start(nil, a); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	writeReloadConfig(t, a.ConfigFile, `
+max_concurrent_requests: 2
+cron_jobs:
+  - name: broken
+    schedule: "not a schedule"
+    action:
+      type: exec
+      args:
+        command: "true"
+`)
+	if _, err := a.ReloadConfig(); err == nil {
+		t.Fatal("expected invalid cron schedule to fail the reload")
+	}
+	if a.Config.MaxConcurrentRequests != 5 || len(a.Config.CronJobs) != 0 {
+		t.Errorf("config changed despite failed reload: max=%d jobs=%d", a.Config.MaxConcurrentRequests, len(a.Config.CronJobs))
+	}
+
+	writeReloadConfig(t, a.ConfigFile, "log_level: loud\n")
+	if _, err := a.ReloadConfig(); err == nil {
+		t.Fatal("expected unknown log level to fail the reload")
+	}
+}
+
+func containsSetting(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+package app
+
+import (
+	"badgermaps/events"
+	"badgermaps/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RemoteConfig points at a base config that IT publishes over HTTPS, so the
+// API base URL, field rules, webhook settings and the like are managed in
+// one place for every machine. The local config file overrides it key by
+// key.
+type RemoteConfig struct {
+	URL string `yaml:"url,omitempty"`
+	// TokenEnv names an environment variable holding a bearer token sent
+	// with the request, for URLs that need one.
+	TokenEnv string `yaml:"token_env,omitempty"`
+	// Timeout bounds the request, such as "5s".
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// DefaultRemoteConfigTimeout bounds the request when remote_config.timeout
+// is not set. It is short since startup waits for it.
+const DefaultRemoteConfigTimeout = 5 * time.Second
+
+// maxRemoteConfigSize caps the shared config read from the URL.
+const maxRemoteConfigSize = 1 << 20
+
+// remoteConfigClient fetches the shared config. Tests replace it.
+var remoteConfigClient = &http.Client{}
+
+// Enabled reports whether a URL is configured.
+func (c RemoteConfig) Enabled() bool {
+	return strings.TrimSpace(c.URL) != ""
+}
+
+// Validate checks the URL is HTTPS and the timeout parses.
+func (c RemoteConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(strings.TrimSpace(c.URL))
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("remote_config.url %q is not a URL", c.URL)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("remote_config.url must use https, got %q", u.Scheme)
+	}
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("remote_config.timeout %q is not a positive duration", c.Timeout)
+		}
+	}
+	return nil
+}
+
+func (c RemoteConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultRemoteConfigTimeout
+}
+
+// cachePath is where the last copy fetched from the URL is kept, so a
+// machine that starts offline still gets the shared settings.
+func (c RemoteConfig) cachePath() string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(c.URL)))
+	return utils.GetConfigDirFile("remote-config-" + hex.EncodeToString(sum[:6]) + ".yaml")
+}
+
+// readConfig fills cfg from the contents of the local config file, on top
+// of the shared config its remote_config points at. It returns the shared
+// config's top-level mapping, or nil when there is none, so SaveConfig can
+// leave the shared values out of the local file.
+func (a *App) readConfig(data []byte, cfg *Config) (*yaml.Node, error) {
+	base := a.loadRemoteConfig(data)
+	if base != nil {
+		if err := base.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("invalid shared config: %w", err)
+		}
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+// loadRemoteConfig fetches the shared config named by the local config, or
+// reads the cached copy when the URL cannot be reached. Failures are
+// warnings: the app then runs on the local config alone.
+func (a *App) loadRemoteConfig(local []byte) *yaml.Node {
+	var peek struct {
+		Remote RemoteConfig `yaml:"remote_config"`
+	}
+	if err := yaml.Unmarshal(local, &peek); err != nil || !peek.Remote.Enabled() {
+		return nil
+	}
+	remote := peek.Remote
+	if err := remote.Validate(); err != nil {
+		a.Events.Dispatch(events.Warningf("config", "%v; using the local config only", err))
+		return nil
+	}
+
+	cache := remote.cachePath()
+	data, err := fetchRemoteConfig(remote)
+	if err == nil {
+		writeErr := os.MkdirAll(filepath.Dir(cache), 0755)
+		if writeErr == nil {
+			writeErr = os.WriteFile(cache, data, 0600)
+		}
+		if writeErr != nil {
+			a.Events.Dispatch(events.Debugf("config", "Failed to cache the shared config: %v", writeErr))
+		}
+		a.Events.Dispatch(events.Debugf("config", "Loaded the shared config from %s", remote.URL))
+	} else {
+		cached, readErr := os.ReadFile(cache)
+		if readErr != nil {
+			a.Events.Dispatch(events.Warningf("config", "Failed to fetch the shared config: %v; using the local config only", err))
+			return nil
+		}
+		fetched := "an earlier run"
+		if info, statErr := os.Stat(cache); statErr == nil {
+			fetched = info.ModTime().Format("2006-01-02 15:04")
+		}
+		a.Events.Dispatch(events.Warningf("config", "Failed to fetch the shared config: %v; using the copy fetched at %s", err, fetched))
+		data = cached
+	}
+
+	base, err := remoteConfigRoot(data)
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("config", "The shared config from %s is invalid: %v; using the local config only", remote.URL, err))
+		return nil
+	}
+	return base
+}
+
+// fetchRemoteConfig downloads the shared config and checks that it parses.
+func fetchRemoteConfig(remote RemoteConfig) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remote.timeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSpace(remote.URL), nil)
+	if err != nil {
+		return nil, err
+	}
+	if remote.TokenEnv != "" {
+		token := os.Getenv(remote.TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("%s is not set", remote.TokenEnv)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/yaml, text/yaml, text/plain")
+	resp, err := remoteConfigClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", remote.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRemoteConfigSize {
+		return nil, fmt.Errorf("the shared config is larger than %d bytes", maxRemoteConfigSize)
+	}
+	if _, err := remoteConfigRoot(data); err != nil {
+		return nil, fmt.Errorf("the shared config is invalid: %w", err)
+	}
+	return data, nil
+}
+
+// remoteConfigRoot parses the shared config into its top-level mapping.
+// Its own remote_config key is dropped: the local file decides where the
+// shared config comes from.
+func remoteConfigRoot(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a YAML mapping of config keys")
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "remote_config" {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			break
+		}
+	}
+	if err := root.Decode(&Config{}); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// withoutSharedValues encodes cfg for the local config file, leaving out
+// the values that match the shared config so later changes to it still
+// apply. Values changed locally are kept as overrides.
+func withoutSharedValues(cfg *Config, base *yaml.Node) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	pruneSharedValues(&doc, base)
+	return &doc, nil
+}
+
+func pruneSharedValues(local, base *yaml.Node) {
+	kept := local.Content[:0]
+	for i := 0; i+1 < len(local.Content); i += 2 {
+		key, value := local.Content[i], local.Content[i+1]
+		shared := mappingValue(base, key.Value)
+		if key.Value != "remote_config" && shared != nil {
+			if value.Kind == yaml.MappingNode && shared.Kind == yaml.MappingNode {
+				pruneSharedValues(value, shared)
+				if len(value.Content) == 0 {
+					continue
+				}
+			} else if sameYAMLValue(value, shared) {
+				continue
+			}
+		}
+		kept = append(kept, key, value)
+	}
+	local.Content = kept
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sameYAMLValue(a, b *yaml.Node) bool {
+	var av, bv interface{}
+	if a.Decode(&av) != nil || b.Decode(&bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// SharedConfigURL returns the URL of the shared config in use, or "" when
+// the app runs on the local config alone.
+func (a *App) SharedConfigURL() string {
+	if a.remoteBase == nil {
+		return ""
+	}
+	return a.Config.RemoteConfig.URL
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+
+	"gopkg.in/yaml.v3"
+)
+
+const sharedConfig = `
+api:
+  api_url: %s/api/
+max_concurrent_requests: 4
+server:
+  webhooks:
+    checkin: false
+field_rules:
+  Notes:
+    sync_direction: push-only
+remote_config:
+  url: https://elsewhere.example/config.yaml
+`
+
+// serveSharedConfig serves body at /config.yaml over TLS and points the
+// remote config client at the server until the test ends.
+func serveSharedConfig(t *testing.T, body func(url string) string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(body(srv.URL)))
+	}))
+	t.Cleanup(srv.Close)
+	old := remoteConfigClient
+	remoteConfigClient = srv.Client()
+	t.Cleanup(func() { remoteConfigClient = old })
+	return srv
+}
+
+func loadWithSharedConfig(t *testing.T, path string) *App {
+	t.Helper()
+	a := NewApp()
+	*a.State.ConfigFile = path
+	if err := a.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	t.Cleanup(func() {
+		if a.DB != nil {
+			a.DB.Close()
+		}
+	})
+	return a
+}
+
+func TestRemoteConfigMergesUnderLocalFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SHARED_CONFIG_TOKEN", "s3cret")
+	srv := serveSharedConfig(t, func(url string) string { return strings.ReplaceAll(sharedConfig, "%s", url) })
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "local.db")
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: dbPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	path := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, path, `
+remote_config:
+  url: `+srv.URL+`/config.yaml
+  token_env: SHARED_CONFIG_TOKEN
+db:
+  type: sqlite3
+  path: `+dbPath+`
+max_concurrent_requests: 2
+`)
+	a := loadWithSharedConfig(t, path)
+
+	if a.Config.API.BaseURL != srv.URL+"/api/" {
+		t.Errorf("api_url = %q, want the shared value", a.Config.API.BaseURL)
+	}
+	if a.Config.MaxConcurrentRequests != 2 {
+		t.Errorf("max_concurrent_requests = %d, want the local override", a.Config.MaxConcurrentRequests)
+	}
+	if a.Config.Server.Webhooks[WebhookCheckin] || !a.Config.Server.Webhooks[WebhookAccountCreate] {
+		t.Errorf("webhooks = %v, want checkin off from the shared config and account_create on by default", a.Config.Server.Webhooks)
+	}
+	if a.Config.RemoteConfig.URL != srv.URL+"/config.yaml" || a.SharedConfigURL() == "" {
+		t.Errorf("remote_config.url = %q, want the local value", a.Config.RemoteConfig.URL)
+	}
+	rules, err := a.AccountSyncRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range rules {
+		if rule.FieldName == "Notes" && rule.Direction != SyncPushOnly {
+			t.Errorf("Notes direction = %q, want the shared field rule", rule.Direction)
+		}
+	}
+
+	// Saving keeps the shared values out of the local file.
+	a.Config.LogLevel = "warn"
+	if err := a.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]interface{}
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved["field_rules"]; ok {
+		t.Error("saved config copied field_rules from the shared config")
+	}
+	if api, _ := saved["api"].(map[string]interface{}); api["api_url"] != nil {
+		t.Errorf("saved config copied api_url from the shared config: %v", api["api_url"])
+	}
+	if saved["max_concurrent_requests"] != 2 || saved["log_level"] != "warn" || saved["remote_config"] == nil {
+		t.Errorf("saved config lost local settings:\n%s", data)
+	}
+
+	// With the URL down the cached copy is used.
+	srv.Close()
+	a = loadWithSharedConfig(t, path)
+	if a.Config.API.BaseURL != srv.URL+"/api/" || a.SharedConfigURL() == "" {
+		t.Errorf("offline api_url = %q, want the cached shared value", a.Config.API.BaseURL)
+	}
+}
+
+func TestRemoteConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config RemoteConfig
+		valid  bool
+	}{
+		{RemoteConfig{}, true},
+		{RemoteConfig{URL: "https://it.example.com/badgermaps.yaml", Timeout: "10s"}, true},
+		{RemoteConfig{URL: "http://it.example.com/badgermaps.yaml"}, false},
+		{RemoteConfig{URL: "it.example.com/badgermaps.yaml"}, false},
+		{RemoteConfig{URL: "https://it.example.com/badgermaps.yaml", Timeout: "soon"}, false},
+	} {
+		if err := tc.config.Validate(); (err == nil) != tc.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tc.config, err, tc.valid)
+		}
+	}
+}
+
+func TestRemoteConfigFallsBackToLocalFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, path, `
+remote_config:
+  url: http://it.example.com/badgermaps.yaml
+db:
+  type: sqlite3
+  path: `+filepath.Join(dir, "local.db")+`
+max_concurrent_requests: 3
+`)
+	a := loadWithSharedConfig(t, path)
+	if a.SharedConfigURL() != "" || a.Config.MaxConcurrentRequests != 3 {
+		t.Errorf("shared URL %q, max %d; want the local config alone", a.SharedConfigURL(), a.Config.MaxConcurrentRequests)
+	}
+}
+
+func TestValidateFieldRules(t *testing.T) {
+	if err := ValidateFieldRules(map[string]FieldRule{"Notes": {SyncDirection: "pull", MergeStrategy: "append"}}); err != nil {
+		t.Errorf("valid rules: %v", err)
+	}
+	for name, rules := range map[string]map[string]FieldRule{
+		"column":    {"AccountId": {SyncDirection: "pull"}},
+		"direction": {"Notes": {SyncDirection: "sideways"}},
+		"strategy":  {"Notes": {MergeStrategy: "shortest"}},
+	} {
+		if err := ValidateFieldRules(rules); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
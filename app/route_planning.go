@@ -0,0 +1,352 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// planDateLayout and planClockLayout are the formats of route plan dates
+// and times.
+const (
+	planDateLayout  = "2006-01-02"
+	planClockLayout = "15:04"
+)
+
+// RouteDay is one day of a RouteWeek: the routes pulled for it and the
+// plans made for it.
+type RouteDay struct {
+	Date   time.Time
+	Routes []models.Route
+	Plans  []database.RoutePlan
+}
+
+// RouteWeek is a week of routes, Monday first, for planning.
+type RouteWeek struct {
+	Start time.Time
+	Days  []RouteDay
+}
+
+// WeekStart returns the Monday of the week t is in, at midnight in t's
+// location.
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// ParseWeek reads a date as YYYY-MM-DD and returns the start of its week.
+// An empty value is the current week in the display timezone.
+func (a *App) ParseWeek(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return WeekStart(time.Now().In(a.DisplayLocation())), nil
+	}
+	day, err := time.ParseInLocation(planDateLayout, value, a.DisplayLocation())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date as YYYY-MM-DD", value)
+	}
+	return WeekStart(day), nil
+}
+
+// RouteWeek returns the pulled routes and the plans of the week starting
+// start, grouped by day. Pushed plans are left out once their route has
+// been pulled.
+func (a *App) RouteWeek(start time.Time) (*RouteWeek, error) {
+	start = WeekStart(start)
+	from, to := start.Format(planDateLayout), start.AddDate(0, 0, 7).Format(planDateLayout)
+	routes, err := database.GetRoutesBetween(a.DB, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("reading routes: %w", err)
+	}
+	plans, err := database.GetRoutePlansBetween(a.DB, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("reading route plans: %w", err)
+	}
+	week := &RouteWeek{Start: start}
+	for i := 0; i < 7; i++ {
+		week.Days = append(week.Days, RouteDay{Date: start.AddDate(0, 0, i)})
+	}
+	dayOf := func(date string) int {
+		if len(date) < 10 {
+			return -1
+		}
+		day, err := time.ParseInLocation(planDateLayout, date[:10], start.Location())
+		if err != nil {
+			return -1
+		}
+		return int(day.Sub(start).Hours()+12) / 24
+	}
+	pulled := make(map[int]bool)
+	for _, route := range routes {
+		if i := dayOf(route.RouteDate.String); i >= 0 && i < 7 {
+			week.Days[i].Routes = append(week.Days[i].Routes, route)
+			pulled[int(route.RouteId.Int64)] = true
+		}
+	}
+	for _, plan := range plans {
+		if plan.RouteId > 0 && pulled[plan.RouteId] {
+			continue
+		}
+		if i := dayOf(plan.RouteDate); i >= 0 && i < 7 {
+			week.Days[i].Plans = append(week.Days[i].Plans, plan)
+		}
+	}
+	return week, nil
+}
+
+// RoutePlan returns the plan with the given ID.
+func (a *App) RoutePlan(planID int) (*database.RoutePlan, error) {
+	plan, err := database.GetRoutePlan(a.DB, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, fmt.Errorf("no route plan %d", planID)
+	}
+	return plan, nil
+}
+
+// DuplicateRoute copies a pulled route and its waypoints into a new plan
+// dated date. Appointment times keep their time of day in the display
+// timezone.
+func (a *App) DuplicateRoute(routeID int, date time.Time) (*database.RoutePlan, error) {
+	route, err := database.GetRouteByID(a.DB, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("route %d is not stored; pull routes first: %w", routeID, err)
+	}
+	waypoints, err := database.GetRouteWaypoints(a.DB, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("reading the waypoints of route %d: %w", routeID, err)
+	}
+	plan := database.RoutePlan{
+		Name:               route.Name.String,
+		RouteDate:          date.Format(planDateLayout),
+		StartAddress:       route.StartAddress.String,
+		DestinationAddress: route.DestinationAddress.String,
+		StartTime:          planClock(route.StartTime.String),
+		TemplateRouteId:    routeID,
+	}
+	if plan.Name == "" {
+		plan.Name = fmt.Sprintf("Route %d", routeID)
+	}
+	loc := a.DisplayLocation()
+	for _, w := range waypoints {
+		stop := database.PlanWaypoint{
+			Name:           w.Name.String,
+			Address:        w.Address.String,
+			Suite:          w.Suite.String,
+			City:           w.City.String,
+			State:          w.State.String,
+			Zipcode:        w.Zipcode.String,
+			LayoverMinutes: int(w.LayoverMinutes.Int64),
+			LocationId:     int(w.LocationID.Int64),
+			CustomerId:     int(w.CustomerID.Int64),
+			Type:           int(w.Type.Int64),
+			PlaceId:        w.PlaceID.String,
+		}
+		if w.Lat.Valid && w.Long.Valid {
+			lat, long := w.Lat.Float64, w.Long.Float64
+			stop.Lat, stop.Long = &lat, &long
+		}
+		if w.ApptTime.String != "" {
+			if t, _, err := ParseApptTime(w.ApptTime.String, route.RouteDate.String, loc); err == nil {
+				stop.ApptTime = t.In(loc).Format(planClockLayout)
+			}
+		}
+		plan.Waypoints = append(plan.Waypoints, stop)
+	}
+	if plan.PlanId, err = database.InsertRoutePlan(a.DB, plan); err != nil {
+		return nil, fmt.Errorf("saving the route plan: %w", err)
+	}
+	plan.Status = database.RoutePlanDraft
+	a.Events.Dispatch(events.Infof("routes", "Copied route %d to plan %d for %s", routeID, plan.PlanId, plan.RouteDate))
+	return &plan, nil
+}
+
+// DuplicateWeek copies every route pulled for the week starting from into
+// plans on the same weekdays of the week starting to.
+func (a *App) DuplicateWeek(from, to time.Time) ([]database.RoutePlan, error) {
+	week, err := a.RouteWeek(from)
+	if err != nil {
+		return nil, err
+	}
+	to = WeekStart(to)
+	var plans []database.RoutePlan
+	for i, day := range week.Days {
+		for _, route := range day.Routes {
+			plan, err := a.DuplicateRoute(int(route.RouteId.Int64), to.AddDate(0, 0, i))
+			if err != nil {
+				return plans, err
+			}
+			plans = append(plans, *plan)
+		}
+	}
+	return plans, nil
+}
+
+// ValidateRoutePlan checks a plan has a name, a date, and times as HH:MM.
+func ValidateRoutePlan(plan database.RoutePlan) error {
+	if strings.TrimSpace(plan.Name) == "" {
+		return fmt.Errorf("a route plan needs a name")
+	}
+	if _, err := time.Parse(planDateLayout, plan.RouteDate); err != nil {
+		return fmt.Errorf("route date %q is not a date as YYYY-MM-DD", plan.RouteDate)
+	}
+	if plan.StartTime != "" {
+		if _, err := time.Parse(planClockLayout, plan.StartTime); err != nil {
+			return fmt.Errorf("start time %q is not a time as HH:MM", plan.StartTime)
+		}
+	}
+	for i, stop := range plan.Waypoints {
+		if stop.ApptTime == "" {
+			continue
+		}
+		if _, err := time.Parse(planClockLayout, stop.ApptTime); err != nil {
+			return fmt.Errorf("stop %d: appointment time %q is not a time as HH:MM", i+1, stop.ApptTime)
+		}
+	}
+	return nil
+}
+
+// SaveRoutePlan saves the edits of a draft plan.
+func (a *App) SaveRoutePlan(plan database.RoutePlan) error {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if err := ValidateRoutePlan(plan); err != nil {
+		return err
+	}
+	stored, err := a.RoutePlan(plan.PlanId)
+	if err != nil {
+		return err
+	}
+	if stored.Status != database.RoutePlanDraft {
+		return fmt.Errorf("route plan %d was already pushed as route %d", plan.PlanId, stored.RouteId)
+	}
+	return database.UpdateRoutePlan(a.DB, plan)
+}
+
+// DeleteRoutePlan removes a plan. Pushed plans can be removed too; the
+// route created from them stays in BadgerMaps.
+func (a *App) DeleteRoutePlan(planID int) error {
+	if _, err := a.RoutePlan(planID); err != nil {
+		return err
+	}
+	return database.DeleteRoutePlan(a.DB, planID)
+}
+
+// EditRoutePlanStops applies edit to the stops of a draft plan and saves
+// them.
+func (a *App) EditRoutePlanStops(planID int, edit func([]database.PlanWaypoint) ([]database.PlanWaypoint, error)) (*database.RoutePlan, error) {
+	plan, err := a.RoutePlan(planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Waypoints, err = edit(plan.Waypoints); err != nil {
+		return nil, err
+	}
+	if err := a.SaveRoutePlan(*plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// AccountStop returns a stop at the stored location of an account.
+func (a *App) AccountStop(accountID int) (database.PlanWaypoint, error) {
+	stop, err := database.GetAccountStop(a.DB, accountID)
+	if err != nil {
+		return database.PlanWaypoint{}, err
+	}
+	if stop == nil {
+		return database.PlanWaypoint{}, fmt.Errorf("account %d is not stored; pull it first", accountID)
+	}
+	return *stop, nil
+}
+
+// InsertStop adds stop at position, counted from 1. A position of zero or
+// past the end appends it.
+func InsertStop(stops []database.PlanWaypoint, stop database.PlanWaypoint, position int) []database.PlanWaypoint {
+	if position <= 0 || position > len(stops) {
+		return append(stops, stop)
+	}
+	stops = append(stops[:position-1], append([]database.PlanWaypoint{stop}, stops[position-1:]...)...)
+	return stops
+}
+
+// RemoveStop removes the stop at position, counted from 1.
+func RemoveStop(stops []database.PlanWaypoint, position int) ([]database.PlanWaypoint, error) {
+	if position < 1 || position > len(stops) {
+		return nil, fmt.Errorf("no stop %d; the plan has %d", position, len(stops))
+	}
+	return append(stops[:position-1], stops[position:]...), nil
+}
+
+// MoveStop moves the stop at from to to, both counted from 1.
+func MoveStop(stops []database.PlanWaypoint, from, to int) ([]database.PlanWaypoint, error) {
+	if from < 1 || from > len(stops) || to < 1 || to > len(stops) {
+		return nil, fmt.Errorf("stops are numbered 1 to %d", len(stops))
+	}
+	stop := stops[from-1]
+	stops = append(stops[:from-1], stops[from:]...)
+	return InsertStop(stops, stop, to), nil
+}
+
+// RouteUpload returns the route a plan creates in BadgerMaps. Appointment
+// times are sent with the display timezone's offset on the plan's date.
+func (a *App) RouteUpload(plan database.RoutePlan) (models.RouteUpload, error) {
+	if err := ValidateRoutePlan(plan); err != nil {
+		return models.RouteUpload{}, err
+	}
+	if len(plan.Waypoints) == 0 {
+		return models.RouteUpload{}, fmt.Errorf("route plan %d has no stops", plan.PlanId)
+	}
+	upload := models.RouteUpload{
+		Name:               plan.Name,
+		RouteDate:          plan.RouteDate,
+		StartAddress:       plan.StartAddress,
+		DestinationAddress: plan.DestinationAddress,
+		StartTime:          plan.StartTime,
+		IdempotencyKey:     fmt.Sprintf("route-plan-%d-%d", plan.PlanId, plan.UpdatedAt.Unix()),
+	}
+	loc := a.DisplayLocation()
+	for i, stop := range plan.Waypoints {
+		w := models.WaypointUpload{
+			Name:           stop.Name,
+			Address:        stop.Address,
+			Suite:          stop.Suite,
+			City:           stop.City,
+			State:          stop.State,
+			Zipcode:        stop.Zipcode,
+			Lat:            stop.Lat,
+			Long:           stop.Long,
+			LayoverMinutes: stop.LayoverMinutes,
+			Position:       i + 1,
+			LocationID:     stop.LocationId,
+			CustomerID:     stop.CustomerId,
+			Type:           stop.Type,
+			PlaceID:        stop.PlaceId,
+		}
+		if stop.ApptTime != "" {
+			t, _, err := ParseApptTime(stop.ApptTime, plan.RouteDate, loc)
+			if err != nil {
+				return models.RouteUpload{}, fmt.Errorf("stop %d: %w", i+1, err)
+			}
+			w.ApptTime = t.Format(time.RFC3339)
+		}
+		upload.Waypoints = append(upload.Waypoints, w)
+	}
+	return upload, nil
+}
+
+// planClock reduces a pulled start time such as 08:30:00 to HH:MM, or
+// returns "" when it is not a time of day.
+func planClock(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"15:04:05", planClockLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(planClockLayout)
+		}
+	}
+	return ""
+}
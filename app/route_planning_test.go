@@ -0,0 +1,152 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestWeekStart(t *testing.T) {
+	for day, want := range map[string]string{
+		"2026-10-19": "2026-10-19", // Monday
+		"2026-10-21": "2026-10-19",
+		"2026-10-25": "2026-10-19", // Sunday
+	} {
+		d, _ := time.Parse("2006-01-02", day)
+		if got := WeekStart(d).Format("2006-01-02"); got != want {
+			t.Errorf("WeekStart(%s) = %s, want %s", day, got, want)
+		}
+	}
+}
+
+func TestStopEdits(t *testing.T) {
+	stops := []database.PlanWaypoint{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	names := func(stops []database.PlanWaypoint) string {
+		s := ""
+		for _, stop := range stops {
+			s += stop.Name
+		}
+		return s
+	}
+	stops = InsertStop(stops, database.PlanWaypoint{Name: "x"}, 2)
+	if got := names(stops); got != "axbc" {
+		t.Fatalf("after insert: %s", got)
+	}
+	stops, err := MoveStop(stops, 4, 1)
+	if err != nil || names(stops) != "caxb" {
+		t.Fatalf("after move: %s, %v", names(stops), err)
+	}
+	stops, err = RemoveStop(stops, 3)
+	if err != nil || names(stops) != "cab" {
+		t.Fatalf("after remove: %s, %v", names(stops), err)
+	}
+	if _, err := RemoveStop(stops, 4); err == nil {
+		t.Fatal("want an error removing a stop past the end")
+	}
+	if got := names(InsertStop(stops, database.PlanWaypoint{Name: "z"}, 0)); got != "cabz" {
+		t.Fatalf("insert at 0 should append, got %s", got)
+	}
+}
+
+func TestRoutePlanning(t *testing.T) {
+	cfg := database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "routes.db")}
+	db, err := database.NewDB(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	a.Config.DisplayTimezone = "America/Chicago"
+
+	sqlDB := db.GetDB()
+	for _, stmt := range []string{
+		`INSERT INTO Routes (RouteId, Name, RouteDate, StartTime) VALUES (10, 'Monday north', '2026-10-12', '08:00:00'), (11, 'Friday south', '2026-10-16', NULL)`,
+		`INSERT INTO RouteWaypoints (WaypointId, RouteId, Name, CustomerId, Position, ApptTime) VALUES
+			(100, 10, 'Acme', 42, 2, '2026-10-12T14:30:00Z'),
+			(101, 10, 'Globex', 43, 1, NULL)`,
+		`INSERT INTO Accounts (AccountId, FullName) VALUES (44, 'Initech')`,
+		`INSERT INTO AccountLocations (AccountId, AddressLine1, City, Latitude, Longitude) VALUES (44, '1 Main St', 'Austin', 30.2, -97.7)`,
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	loc := a.DisplayLocation()
+	lastWeek := time.Date(2026, 10, 12, 0, 0, 0, 0, loc)
+	thisWeek := lastWeek.AddDate(0, 0, 7)
+	plans, err := a.DuplicateWeek(lastWeek, thisWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != 2 || plans[0].RouteDate != "2026-10-19" || plans[1].RouteDate != "2026-10-23" {
+		t.Fatalf("unexpected plans: %+v", plans)
+	}
+	monday := plans[0]
+	if monday.StartTime != "08:00" || len(monday.Waypoints) != 2 || monday.Waypoints[0].Name != "Globex" {
+		t.Fatalf("unexpected copy of route 10: %+v", monday)
+	}
+	if got := monday.Waypoints[1].ApptTime; got != "09:30" {
+		t.Fatalf("appointment time = %q, want 09:30 in Chicago", got)
+	}
+
+	week, err := a.RouteWeek(thisWeek.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(week.Days) != 7 || len(week.Days[0].Plans) != 1 || len(week.Days[4].Plans) != 1 || len(week.Days[0].Routes) != 0 {
+		t.Fatalf("unexpected week: %+v", week.Days)
+	}
+
+	edited, err := a.EditRoutePlanStops(monday.PlanId, func(stops []database.PlanWaypoint) ([]database.PlanWaypoint, error) {
+		stop, err := a.AccountStop(44)
+		if err != nil {
+			return nil, err
+		}
+		stop.ApptTime = "11:00"
+		return InsertStop(stops, stop, 1), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := a.RoutePlan(monday.PlanId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Waypoints) != 3 || stored.Waypoints[0].Name != "Initech" || stored.Waypoints[0].Lat == nil {
+		t.Fatalf("stops were not saved: %+v", stored.Waypoints)
+	}
+
+	upload, err := a.RouteUpload(*stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Waypoints[0].Position != 1 || upload.Waypoints[2].Position != 3 {
+		t.Fatalf("positions not renumbered: %+v", upload.Waypoints)
+	}
+	if got := upload.Waypoints[0].ApptTime; got != "2026-10-19T11:00:00-05:00" {
+		t.Fatalf("uploaded appointment time = %q", got)
+	}
+
+	edited.Waypoints[0].ApptTime = "11am"
+	if err := a.SaveRoutePlan(*edited); err == nil {
+		t.Fatal("want an error saving a time that is not HH:MM")
+	}
+	if err := database.MarkRoutePlanPushed(db, monday.PlanId, 900, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveRoutePlan(*stored); err == nil {
+		t.Fatal("want an error editing a pushed plan")
+	}
+}
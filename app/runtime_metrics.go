@@ -0,0 +1,115 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// RuntimeMetrics is a snapshot of the running process for the Debug tab,
+// to tell a frozen GUI or a leak apart from a slow sync in the field.
+type RuntimeMetrics struct {
+	Goroutines int
+	// HeapAlloc is the memory in live heap objects, and Sys all the memory
+	// the Go runtime holds from the OS.
+	HeapAlloc   uint64
+	Sys         uint64
+	NumGC       uint32
+	LastGCPause time.Duration
+	// PendingEvents counts the event listener calls queued or running.
+	PendingEvents int64
+	// APIInFlight counts the API requests holding a slot of the shared
+	// rate limiter.
+	APIInFlight int
+	// DBStats is the database pool, or nil when there is no connection.
+	DBStats *sql.DBStats
+	// UnitsOfWork are the store transactions run so far, by name.
+	UnitsOfWork []database.UnitOfWorkStats
+	// PprofAddr is where the profiling server listens, empty when it is off.
+	PprofAddr string
+}
+
+// RuntimeMetrics reads the current metrics. Reading the memory statistics
+// briefly stops the world, so callers poll every few seconds at most.
+func (a *App) RuntimeMetrics() RuntimeMetrics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	m := RuntimeMetrics{
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		Sys:        mem.Sys,
+		NumGC:      mem.NumGC,
+	}
+	if mem.NumGC > 0 {
+		m.LastGCPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+	}
+	if a.Events != nil {
+		m.PendingEvents = a.Events.PendingEvents()
+	}
+	if a.RateLimiter != nil {
+		m.APIInFlight = a.RateLimiter.InFlight()
+	}
+	if a.DB != nil && a.DB.IsConnected() {
+		if db := a.DB.GetDB(); db != nil {
+			stats := db.Stats()
+			m.DBStats = &stats
+		}
+	}
+	m.UnitsOfWork = database.UnitOfWorkMetrics()
+	a.pprofMu.Lock()
+	m.PprofAddr = a.pprofAddr
+	a.pprofMu.Unlock()
+	return m
+}
+
+// StartPprof serves net/http/pprof on a free loopback port and returns its
+// address. Profiles hold no secrets but cost CPU, so the server only runs
+// while the Debug tab's toggle is on.
+func (a *App) StartPprof() (string, error) {
+	a.pprofMu.Lock()
+	defer a.pprofMu.Unlock()
+	if a.pprofServer != nil {
+		return a.pprofAddr, nil
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to listen for pprof: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.Events.Dispatch(events.Errorf("debug", "pprof server stopped: %v", err))
+		}
+	}()
+	a.pprofServer = srv
+	a.pprofAddr = listener.Addr().String()
+	return a.pprofAddr, nil
+}
+
+// StopPprof shuts the profiling server down, if it runs.
+func (a *App) StopPprof() error {
+	a.pprofMu.Lock()
+	srv := a.pprofServer
+	a.pprofServer, a.pprofAddr = nil, ""
+	a.pprofMu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/database"
+)
+
+func TestRuntimeMetrics(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "metrics.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetConnected(true)
+	a.DB = db
+
+	m := a.RuntimeMetrics()
+	if m.Goroutines < 1 || m.HeapAlloc == 0 || m.Sys < m.HeapAlloc {
+		t.Errorf("process metrics = %+v", m)
+	}
+	if m.DBStats == nil {
+		t.Error("expected pool stats for a connected database")
+	}
+	if m.PprofAddr != "" {
+		t.Errorf("pprof should be off, got %s", m.PprofAddr)
+	}
+
+	addr, err := a.StartPprof()
+	if err != nil {
+		t.Fatalf("StartPprof: %v", err)
+	}
+	defer a.StopPprof()
+	if again, _ := a.StartPprof(); again != addr {
+		t.Errorf("second start moved the server from %s to %s", addr, again)
+	}
+	if got := a.RuntimeMetrics().PprofAddr; got != addr || !strings.HasPrefix(addr, "127.0.0.1:") {
+		t.Errorf("PprofAddr = %q, started on %q", got, addr)
+	}
+	resp, err := http.Get("http://" + addr + "/debug/pprof/goroutine?debug=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine profile") {
+		t.Errorf("goroutine profile: %d %.80s", resp.StatusCode, body)
+	}
+
+	if err := a.StopPprof(); err != nil {
+		t.Fatalf("StopPprof: %v", err)
+	}
+	if a.RuntimeMetrics().PprofAddr != "" {
+		t.Error("expected pprof off after StopPprof")
+	}
+	if _, err := http.Get("http://" + addr + "/debug/pprof/"); err == nil {
+		t.Error("expected the pprof server to be closed")
+	}
+}
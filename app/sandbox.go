@@ -0,0 +1,57 @@
+package app
+
+import (
+	"badgermaps/api"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// SandboxRunType tags SyncHistory rows for pushes sent to the sandbox API.
+const SandboxRunType = "push_sandbox"
+
+// ErrSandboxNotConfigured is returned when pushes are directed to the
+// sandbox but no api.sandbox_url is set. Pushes never fall back to
+// production in that case.
+var ErrSandboxNotConfigured = errors.New("push to sandbox is enabled but api.sandbox_url is not set")
+
+// PushToSandbox reports whether push operations go to the sandbox API,
+// either from push_to_sandbox in the config or --sandbox for this run.
+func (a *App) PushToSandbox() bool {
+	if a.State != nil && a.State.PushToSandbox {
+		return true
+	}
+	return a.Config != nil && a.Config.PushToSandbox
+}
+
+// sandboxAPIConfig returns the API config for the sandbox: the production
+// settings with the sandbox URL and key swapped in.
+func (a *App) sandboxAPIConfig() api.APIConfig {
+	cfg := a.Config.API
+	cfg.BaseURL = strings.TrimSpace(cfg.SandboxURL)
+	if key := strings.TrimSpace(cfg.SandboxAPIKey); key != "" {
+		cfg.APIKey = key
+	}
+	return cfg
+}
+
+// PushAPI returns the client push operations should use. Pulls always use
+// a.API; pushes use a sandbox client while PushToSandbox is on. The sandbox
+// client is rebuilt when its settings change.
+func (a *App) PushAPI() (*api.APIClient, error) {
+	if !a.PushToSandbox() {
+		return a.API, nil
+	}
+	cfg := a.sandboxAPIConfig()
+	if cfg.BaseURL == "" {
+		return nil, ErrSandboxNotConfigured
+	}
+
+	a.sandboxMu.Lock()
+	defer a.sandboxMu.Unlock()
+	if a.sandboxAPI == nil || !reflect.DeepEqual(a.sandboxConfig, cfg) {
+		a.sandboxAPI = api.NewAPIClientWithLimiter(&cfg, a.RateLimiter)
+		a.sandboxConfig = cfg
+	}
+	return a.sandboxAPI, nil
+}
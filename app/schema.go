@@ -0,0 +1,124 @@
+package app
+
+import (
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"badgermaps/errs"
+	"badgermaps/events"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaError is returned by CheckSchema when the database does not match
+// the schema this version writes. The pull or push is not started.
+type SchemaError struct {
+	Err error
+	// MigrationPending is true when only tables, views, or columns that
+	// MigrateSchema adds are missing. Existing data is kept.
+	MigrationPending bool
+	// MissingColumns lists existing tables that lack columns MigrateSchema
+	// cannot add; these have to be re-created.
+	MissingColumns map[string][]string
+}
+
+func (e *SchemaError) Error() string {
+	if e.MigrationPending {
+		return fmt.Sprintf("database schema needs a migration (%v); run 'badgermaps db migrate', or pass --force to continue anyway", e.Err)
+	}
+	return fmt.Sprintf("database schema is not compatible (%v); back up with 'badgermaps db backup', re-initialize the schema with 'badgermaps config', and restore the backup, or pass --force to continue anyway", e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes a SchemaError an errs.ErrSchema.
+func (e *SchemaError) Is(target error) bool {
+	return target == errs.ErrSchema
+}
+
+// Hint returns the command that fixes the schema.
+func (e *SchemaError) Hint() string {
+	if e.MigrationPending {
+		return "Run 'badgermaps db migrate' to add the missing tables, views, and columns; existing data is kept."
+	}
+	return "Back up with 'badgermaps db backup', re-initialize the schema with 'badgermaps config', then restore the backup with 'badgermaps db restore'."
+}
+
+// CheckSchema returns a *SchemaError when the database schema is invalid or a
+// migration is pending, so pull and push do not merge into tables they do
+// not match. With State.SkipSchemaCheck (--force) the problem is logged as a
+// warning instead.
+func (a *App) CheckSchema() error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil
+	}
+	err := a.schemaError()
+	if err == nil {
+		return nil
+	}
+	if a.State != nil && a.State.SkipSchemaCheck {
+		a.Events.Dispatch(events.Warningf("db", "Continuing despite schema problem (--force): %v", err.Err))
+		return nil
+	}
+	return err
+}
+
+func (a *App) schemaError() *SchemaError {
+	validateErr := a.DB.ValidateSchema(&state.State{Quiet: true})
+	if validateErr == nil {
+		return nil
+	}
+	missing, err := database.MissingColumns(a.DB)
+	if err != nil {
+		return &SchemaError{Err: validateErr}
+	}
+	fixed := make(map[string][]string)
+	for table, columns := range missing {
+		for _, column := range columns {
+			if !database.CanAddColumn(table, column) {
+				fixed[table] = append(fixed[table], column)
+			}
+		}
+	}
+	if len(fixed) == 0 {
+		return &SchemaError{Err: validateErr, MigrationPending: true}
+	}
+	tables := make([]string, 0, len(fixed))
+	for table, columns := range fixed {
+		tables = append(tables, fmt.Sprintf("%s (%s)", table, strings.Join(columns, ", ")))
+	}
+	sort.Strings(tables)
+	return &SchemaError{
+		Err:            fmt.Errorf("missing columns in %s", strings.Join(tables, "; ")),
+		MissingColumns: fixed,
+	}
+}
+
+// MigrateSchema adds the columns existing tables are missing, creates the
+// missing tables and views, and checks the result. Existing data is kept.
+func (a *App) MigrateSchema() error {
+	if a.DB == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	// Columns come first: the views EnforceSchema creates may use them.
+	added, err := database.AddMissingColumns(a.DB)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	for table, columns := range added {
+		a.Events.Dispatch(events.Infof("db", "Added %s to %s.", strings.Join(columns, ", "), table))
+	}
+	err = a.DB.EnforceSchema(a.State)
+	a.Connections().InvalidateSchema()
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if err := a.schemaError(); err != nil {
+		return err
+	}
+	a.Events.Dispatch(events.Infof("db", "Database schema is up to date."))
+	a.applyFieldRules()
+	return nil
+}
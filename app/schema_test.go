@@ -0,0 +1,170 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"badgermaps/errs"
+)
+
+func TestCheckSchema(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "schema.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if err := a.CheckSchema(); err != nil {
+		t.Fatalf("CheckSchema on a fresh schema: %v", err)
+	}
+
+	// A missing table is a pending migration.
+	if _, err := db.GetDB().Exec(`DROP TABLE SyncHistory`); err != nil {
+		t.Fatal(err)
+	}
+	var schemaErr *SchemaError
+	if err := a.CheckSchema(); !errors.As(err, &schemaErr) || !schemaErr.MigrationPending {
+		t.Fatalf("CheckSchema with a missing table = %v, want a pending migration", err)
+	}
+	if !errors.Is(schemaErr, errs.ErrSchema) || !strings.Contains(errs.Hint(schemaErr), "badgermaps db migrate") {
+		t.Fatalf("schema error kind %v, hint %q", errs.Kind(schemaErr), errs.Hint(schemaErr))
+	}
+	a.State.SkipSchemaCheck = true
+	if err := a.CheckSchema(); err != nil {
+		t.Fatalf("CheckSchema with --force = %v", err)
+	}
+	a.State.SkipSchemaCheck = false
+	if err := a.MigrateSchema(); err != nil {
+		t.Fatalf("MigrateSchema: %v", err)
+	}
+	if err := a.CheckSchema(); err != nil {
+		t.Fatalf("CheckSchema after migration: %v", err)
+	}
+
+	// A table missing columns cannot be migrated in place.
+	if _, err := db.GetDB().Exec(`DROP TABLE Routes; CREATE TABLE Routes (RouteId INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckSchema(); !errors.As(err, &schemaErr) || schemaErr.MigrationPending || len(schemaErr.MissingColumns["Routes"]) == 0 {
+		t.Fatalf("CheckSchema with missing columns = %v", err)
+	}
+	if err := a.MigrateSchema(); err == nil {
+		t.Fatal("MigrateSchema should not fix missing columns")
+	}
+}
+
+func TestMigrateSchemaAddsColumns(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "upgrade.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetConnected(true)
+	a.DB = db
+
+	// The tables as the first release created them, before columns were
+	// added to them.
+	baseline := []string{
+		`CREATE TABLE AccountsPendingChanges (
+			ChangeId INTEGER PRIMARY KEY AUTOINCREMENT,
+			AccountId INTEGER NOT NULL,
+			ChangeType TEXT NOT NULL CHECK(ChangeType IN ('CREATE', 'UPDATE', 'DELETE')),
+			Changes TEXT,
+			Status TEXT NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
+			CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+			ProcessedAt DATETIME
+		)`,
+		`CREATE TABLE AccountCheckinsPendingChanges (
+			ChangeId INTEGER PRIMARY KEY AUTOINCREMENT,
+			CheckinId INTEGER NOT NULL,
+			AccountId INTEGER NOT NULL,
+			CrmId TEXT,
+			LogDatetime TEXT,
+			Type TEXT,
+			Comments TEXT,
+			ExtraFields TEXT,
+			EndpointType TEXT NOT NULL DEFAULT 'standard' CHECK(EndpointType IN ('standard', 'custom')),
+			CreatedBy TEXT,
+			ChangeType TEXT NOT NULL CHECK(ChangeType IN ('CREATE', 'UPDATE', 'DELETE')),
+			Status TEXT NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
+			CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+			ProcessedAt DATETIME
+		)`,
+		`CREATE TABLE FieldMaps (
+			FieldName TEXT,
+			ObjectType TEXT,
+			JsonField TEXT,
+			DataSetName TEXT,
+			DataSetLabel TEXT,
+			PRIMARY KEY (FieldName, ObjectType)
+		)`,
+		`CREATE TABLE CommandLog (
+			LogId INTEGER PRIMARY KEY AUTOINCREMENT,
+			Command TEXT NOT NULL,
+			Args TEXT,
+			Timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			Success BOOLEAN NOT NULL,
+			ErrorMessage TEXT
+		)`,
+		`CREATE TABLE WebhookLog (
+			Id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ReceivedAt DATETIME NOT NULL,
+			Method TEXT NOT NULL,
+			Uri TEXT NOT NULL,
+			Headers TEXT,
+			Body TEXT
+		)`,
+		`INSERT INTO FieldMaps (FieldName, ObjectType, JsonField) VALUES ('Notes', 'Account', 'notes')`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (7, 'UPDATE', '{"notes":"hi"}')`,
+	}
+	for _, stmt := range baseline {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var schemaErr *SchemaError
+	if err := a.CheckSchema(); !errors.As(err, &schemaErr) || !schemaErr.MigrationPending {
+		t.Fatalf("CheckSchema on an upgraded database = %v, want a pending migration", err)
+	}
+	if err := a.MigrateSchema(); err != nil {
+		t.Fatalf("MigrateSchema: %v", err)
+	}
+	if err := a.CheckSchema(); err != nil {
+		t.Fatalf("CheckSchema after migration: %v", err)
+	}
+	if missing, err := database.MissingColumns(db); err != nil || len(missing) != 0 {
+		t.Fatalf("MissingColumns after migration = %v, %v", missing, err)
+	}
+
+	var direction string
+	if err := db.GetDB().QueryRow(`SELECT SyncDirection FROM FieldMaps WHERE FieldName = 'Notes'`).Scan(&direction); err != nil || direction != SyncBoth {
+		t.Errorf("SyncDirection of an existing field = %q, %v; want %q", direction, err, SyncBoth)
+	}
+	changes, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(changes) != 1 || changes[0].AccountId != 7 {
+		t.Errorf("pending changes after migration = %+v, %v", changes, err)
+	}
+
+	// A second migration has nothing left to add.
+	if err := a.MigrateSchema(); err != nil {
+		t.Fatalf("second MigrateSchema: %v", err)
+	}
+}
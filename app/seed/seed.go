@@ -0,0 +1,315 @@
+// Package seed fills the local database with made-up accounts, locations,
+// and check-ins so the GUI can be demonstrated and the Explorer and
+// pagination tested at volume without a BadgerMaps account.
+package seed
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"badgermaps/events"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/guregu/null/v6"
+)
+
+// IdBase is the first account and check-in ID a seed uses. It is far above
+// the IDs BadgerMaps hands out, so seeded rows do not overwrite pulled ones
+// and can be removed on their own. Bench uses negative IDs.
+const IdBase = 900000000
+
+// Default sizes of a seed.
+const (
+	DefaultAccounts = 500
+	DefaultCheckins = 2000
+	DefaultSeed     = 1
+)
+
+// MaxRows caps each count, keeping the IDs inside a 32-bit column.
+const MaxRows = 1000000
+
+// checkinSpan is how far back seeded check-ins go.
+const checkinSpan = 2 * 365 * 24 * time.Hour
+
+// Options sets the size and shape of a seed.
+type Options struct {
+	// Accounts is how many accounts, each with one location, are written.
+	Accounts int
+	// Checkins is how many check-ins are spread over the accounts.
+	Checkins int
+	// Seed drives the generator; the same seed writes the same data.
+	Seed int64
+	// Now is the latest check-in time. Zero means the current time.
+	Now time.Time
+}
+
+// Summary reports what a seed wrote.
+type Summary struct {
+	Accounts int           `json:"accounts"`
+	Checkins int           `json:"checkins"`
+	Removed  int64         `json:"removed"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Run replaces any earlier seeded rows with new ones. Accounts go through the
+// pull's store path, so pull processors and custom field settings apply as
+// they would to pulled data; check-ins are written in batches of the
+// configured batch size. Nothing is staged for push.
+func Run(a *app.App, opts Options) (Summary, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return Summary{}, fmt.Errorf("database is not connected")
+	}
+	if opts.Accounts < 1 || opts.Accounts > MaxRows {
+		return Summary{}, fmt.Errorf("accounts must be between 1 and %d, got %d", MaxRows, opts.Accounts)
+	}
+	if opts.Checkins < 0 || opts.Checkins > MaxRows {
+		return Summary{}, fmt.Errorf("checkins must be between 0 and %d, got %d", MaxRows, opts.Checkins)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	start := time.Now()
+
+	summary, err := write(a, opts)
+	summary.Duration = time.Since(start)
+	if err != nil {
+		return summary, err
+	}
+	a.Events.Dispatch(events.Infof("seed", "Seeded %d accounts and %d check-ins in %s", summary.Accounts, summary.Checkins, summary.Duration.Round(time.Millisecond)))
+	return summary, nil
+}
+
+// write clears earlier seeded rows and stores new ones. The account store
+// pauses change capture itself and check-ins are not captured.
+func write(a *app.App, opts Options) (Summary, error) {
+	var summary Summary
+	var err error
+	if summary.Removed, err = Clear(a); err != nil {
+		return summary, err
+	}
+
+	// Accounts are generated before check-ins, so the same seed writes the
+	// same accounts whatever the number of check-ins.
+	g := generator{rng: rand.New(rand.NewSource(opts.Seed)), now: opts.Now.UTC().Truncate(time.Second)}
+	accounts := make([]models.Account, opts.Accounts)
+	for i := range accounts {
+		accounts[i] = g.account(IdBase + int64(i))
+	}
+	checkins := g.checkins(opts.Accounts, opts.Checkins)
+	last := make(map[int64]time.Time, opts.Accounts)
+	for _, c := range checkins {
+		if id := c.record.AccountId.Int64; c.logged.After(last[id]) {
+			last[id] = c.logged
+		}
+	}
+
+	for _, acc := range accounts {
+		if t, ok := last[acc.AccountId.Int64]; ok {
+			acc.LastCheckinDate = models.NewDate(t)
+			acc.DaysSinceLastCheckin = null.IntFrom(int64(g.now.Sub(t) / (24 * time.Hour)))
+		}
+		if err := pull.StoreAccountDetailed(a, &acc); err != nil {
+			return summary, fmt.Errorf("failed to store account %d: %w", acc.AccountId.Int64, err)
+		}
+		summary.Accounts++
+	}
+
+	records := make([]database.CheckinRecord, len(checkins))
+	for i, c := range checkins {
+		records[i] = c.record
+	}
+	for size := a.BatchSize(); len(records) > 0; records = records[min(size, len(records)):] {
+		batch := records[:min(size, len(records))]
+		if err := database.BulkMergeCheckins(a.DB, batch); err != nil {
+			return summary, fmt.Errorf("failed to store check-ins: %w", err)
+		}
+		summary.Checkins += len(batch)
+	}
+	return summary, nil
+}
+
+// Clear removes seeded accounts with their locations and check-ins and
+// returns how many rows went.
+func Clear(a *app.App) (int64, error) {
+	var removed int64
+	err := a.WithoutChangeCapture(func() error {
+		var err error
+		removed, err = database.DeleteSeedRows(a.DB, IdBase)
+		return err
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to clear seeded rows: %w", err)
+	}
+	return removed, nil
+}
+
+type seededCheckin struct {
+	record database.CheckinRecord
+	logged time.Time
+}
+
+// generator builds the rows from a seeded source so a seed is repeatable.
+type generator struct {
+	rng *rand.Rand
+	now time.Time
+}
+
+func (g generator) pick(values []string) string {
+	return values[g.rng.Intn(len(values))]
+}
+
+func (g generator) account(id int64) models.Account {
+	first, last := g.pick(firstNames), g.pick(lastNames)
+	company := fmt.Sprintf("%s %s", last, g.pick(companySuffixes))
+	c := cities[g.rng.Intn(len(cities))]
+	street := fmt.Sprintf("%d %s", 100+g.rng.Intn(9900), g.pick(streets))
+	address := fmt.Sprintf("%s, %s, %s %s", street, c.name, c.state, c.zip)
+	domain := strings.ToLower(strings.ReplaceAll(last, "'", "")) + ".example.com"
+
+	acc := models.Account{
+		AccountId:        null.IntFrom(id),
+		FirstName:        null.StringFrom(first),
+		LastName:         null.StringFrom(company),
+		FullName:         null.StringFrom(company),
+		PhoneNumber:      null.StringFrom(fmt.Sprintf("(%03d) 555-%04d", 200+g.rng.Intn(800), g.rng.Intn(10000))),
+		Email:            null.StringFrom(fmt.Sprintf("%s@%s", strings.ToLower(first), domain)),
+		CustomerId:       null.StringFrom(fmt.Sprintf("CUST-%06d", id-IdBase+1)),
+		OriginalAddress:  null.StringFrom(address),
+		AccountOwner:     null.StringFrom(g.pick(owners)),
+		CustomText:       null.StringFrom(g.pick(segments)),
+		CustomNumeric:    null.FloatFrom(float64(g.rng.Intn(500000)) / 100),
+		LastModifiedDate: models.NewDate(g.now.Add(-time.Duration(g.rng.Int63n(int64(checkinSpan))))),
+		Locations: []models.Location{{
+			LocationId:   null.IntFrom(id),
+			Name:         null.StringFrom(company),
+			AddressLine1: null.StringFrom(street),
+			City:         null.StringFrom(c.name),
+			State:        null.StringFrom(c.state),
+			Zipcode:      null.StringFrom(c.zip),
+			// About 10 km around the city centre.
+			Lat:      null.FloatFrom(c.lat + (g.rng.Float64()-0.5)*0.18),
+			Long:     null.FloatFrom(c.long + (g.rng.Float64()-0.5)*0.18),
+			Location: null.StringFrom(address),
+		}},
+	}
+	if g.rng.Intn(4) == 0 {
+		acc.Notes = null.StringFrom(g.pick(accountNotes))
+	}
+	if g.rng.Intn(5) == 0 {
+		acc.FollowUpDate = models.DateFrom(g.now.AddDate(0, 0, 1+g.rng.Intn(60)).Format("2006-01-02"))
+	}
+	return acc
+}
+
+// checkins spreads n check-ins over the accounts, favouring lower IDs so
+// some accounts are visited far more than others, as in real data.
+func (g generator) checkins(accounts, n int) []seededCheckin {
+	out := make([]seededCheckin, n)
+	for i := range out {
+		account := int(float64(accounts) * g.rng.Float64() * g.rng.Float64())
+		logged := g.now.Add(-time.Duration(g.rng.Int63n(int64(checkinSpan))))
+		// Visits happen in working hours.
+		logged = time.Date(logged.Year(), logged.Month(), logged.Day(), 8+g.rng.Intn(10), g.rng.Intn(60), 0, 0, time.UTC)
+		if logged.After(g.now) {
+			logged = logged.AddDate(0, 0, -1)
+		}
+		out[i] = seededCheckin{
+			logged: logged,
+			record: database.CheckinRecord{
+				CheckinId:    IdBase + int64(i),
+				AccountId:    sql.NullInt64{Int64: IdBase + int64(account), Valid: true},
+				LogDatetime:  sql.NullString{String: logged.Format(models.DateLayout), Valid: true},
+				Type:         sql.NullString{String: g.pick(checkinTypes), Valid: true},
+				Comments:     sql.NullString{String: g.pick(checkinComments), Valid: true},
+				EndpointType: "standard",
+				CreatedBy:    sql.NullString{String: g.pick(owners), Valid: true},
+			},
+		}
+	}
+	return out
+}
+
+type city struct {
+	name, state, zip string
+	lat, long        float64
+}
+
+var cities = []city{
+	{"Austin", "TX", "78701", 30.2672, -97.7431},
+	{"Dallas", "TX", "75201", 32.7767, -96.7970},
+	{"Houston", "TX", "77002", 29.7604, -95.3698},
+	{"Phoenix", "AZ", "85004", 33.4484, -112.0740},
+	{"Denver", "CO", "80202", 39.7392, -104.9903},
+	{"Chicago", "IL", "60601", 41.8781, -87.6298},
+	{"Springfield", "IL", "62701", 39.7817, -89.6501},
+	{"Columbus", "OH", "43215", 39.9612, -82.9988},
+	{"Atlanta", "GA", "30303", 33.7490, -84.3880},
+	{"Nashville", "TN", "37203", 36.1627, -86.7816},
+	{"Charlotte", "NC", "28202", 35.2271, -80.8431},
+	{"Orlando", "FL", "32801", 28.5383, -81.3792},
+	{"Tampa", "FL", "33602", 27.9506, -82.4572},
+	{"Seattle", "WA", "98101", 47.6062, -122.3321},
+	{"Portland", "OR", "97204", 45.5152, -122.6784},
+	{"Sacramento", "CA", "95814", 38.5816, -121.4944},
+	{"San Diego", "CA", "92101", 32.7157, -117.1611},
+	{"Minneapolis", "MN", "55401", 44.9778, -93.2650},
+	{"Kansas City", "MO", "64105", 39.0997, -94.5786},
+	{"Boston", "MA", "02108", 42.3601, -71.0589},
+	{"Pittsburgh", "PA", "15222", 40.4406, -79.9959},
+	{"Salt Lake City", "UT", "84101", 40.7608, -111.8910},
+}
+
+var firstNames = []string{
+	"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth",
+	"William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Carlos", "Maria",
+	"Daniel", "Karen", "Matthew", "Nancy", "Anthony", "Lisa", "Mark", "Priya", "Wei", "Aisha",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+	"Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee",
+	"Thompson", "White", "Harris", "Clark", "Lewis", "Robinson", "Walker", "Young", "Allen", "King",
+	"Wright", "Scott", "Nguyen", "Hill", "Green", "Adams", "Baker", "Nelson", "Carter", "Mitchell",
+}
+
+var companySuffixes = []string{
+	"Hardware", "Dental", "Family Clinic", "Auto Repair", "Farms", "Pharmacy", "Bakery", "Builders",
+	"Plumbing", "Veterinary", "Supply Co", "Market", "Electric", "Landscaping", "Optometry", "Cafe",
+}
+
+var streets = []string{
+	"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Park Blvd", "Elm St", "Washington Ave", "Lake Rd",
+	"Hill St", "2nd St", "Pine St", "Sunset Blvd", "Church St", "Highland Ave", "Mill Rd", "River Rd",
+}
+
+var owners = []string{
+	"alex.rep@example.com", "jordan.rep@example.com", "sam.rep@example.com", "taylor.rep@example.com", "casey.rep@example.com",
+}
+
+var segments = []string{"Prospect", "Customer", "Key Account", "Lapsed", "Partner"}
+
+var accountNotes = []string{
+	"Prefers morning visits.",
+	"Ask for the office manager.",
+	"Interested in the spring promotion.",
+	"Parking behind the building.",
+	"Send the quote by email before visiting.",
+}
+
+var checkinTypes = []string{"Visit", "Phone Call", "Email", "Meeting", "Drop-in"}
+
+var checkinComments = []string{
+	"Discussed new product line.",
+	"Left samples with the front desk.",
+	"Owner out; follow up next week.",
+	"Placed a reorder.",
+	"Reviewed pricing and delivery schedule.",
+	"Introduced the regional manager.",
+	"Collected feedback on the last order.",
+	"Demo scheduled for next month.",
+}
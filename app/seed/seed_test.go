@@ -0,0 +1,83 @@
+package seed
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	a := app.NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "seed.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName, FullName) VALUES (7, 'Keep', 'Keep')`); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(query string) int {
+		t.Helper()
+		var n int
+		if err := db.GetDB().QueryRow(query).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return n
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	opts := Options{Accounts: 40, Checkins: 150, Seed: 3, Now: now}
+	summary, err := Run(a, opts)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if summary.Accounts != 40 || summary.Checkins != 150 || summary.Removed != 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if n := count(`SELECT COUNT(*) FROM AccountLocations WHERE AccountId >= 900000000 AND Latitude IS NOT NULL AND City IS NOT NULL`); n != 40 {
+		t.Errorf("%d seeded locations, want 40", n)
+	}
+	if n := count(`SELECT COUNT(*) FROM AccountCheckins WHERE LogDatetime > '2025-06-01T12:00:00' OR LogDatetime < '2023-05-01'`); n != 0 {
+		t.Errorf("%d check-ins outside the last two years", n)
+	}
+	if n := count(`SELECT COUNT(*) FROM Accounts a WHERE LastCheckinDate IS NOT NULL AND LastCheckinDate <> (SELECT MAX(LogDatetime) FROM AccountCheckins c WHERE c.AccountId = a.AccountId)`); n != 0 {
+		t.Errorf("%d accounts with a last check-in date that is not their latest check-in", n)
+	}
+	var first string
+	if err := db.GetDB().QueryRow(`SELECT FullName FROM Accounts WHERE AccountId = 900000000`).Scan(&first); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Accounts, opts.Checkins = 10, 5
+	if summary, err = Run(a, opts); err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if summary.Removed != 40+40+150 {
+		t.Errorf("second run removed %d rows, want 230", summary.Removed)
+	}
+	var again string
+	if err := db.GetDB().QueryRow(`SELECT FullName FROM Accounts WHERE AccountId = 900000000`).Scan(&again); err != nil || again != first {
+		t.Errorf("same seed wrote %q, want %q (%v)", again, first, err)
+	}
+	if n := count(`SELECT COUNT(*) FROM Accounts`); n != 11 {
+		t.Errorf("%d accounts after reseeding, want 11", n)
+	}
+
+	if _, err := Clear(a); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(`SELECT COUNT(*) FROM Accounts`); n != 1 {
+		t.Errorf("%d accounts after Clear, want only the pulled one", n)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"badgermaps/app/audit"
 	"badgermaps/database"
 	"badgermaps/events"
+	"badgermaps/utils"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -261,7 +262,7 @@ func (hc *HealthChecker) checkDiskSpace() *ComponentHealth {
 
 	start := time.Now()
 
-	available, total, err := diskUsage(".")
+	available, total, err := utils.DiskUsage(".")
 	if err != nil {
 		health.Status = StatusUnhealthy
 		health.Error = err.Error()
@@ -7,7 +7,9 @@ import (
 	"io/ioutil"
 	"os"
 	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -18,12 +20,26 @@ type CronJob struct {
 	Action   action.ActionConfig `yaml:"action"`
 }
 
+// NextRun returns when the job next fires after t.
+func (j CronJob) NextRun(t time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(j.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(t), nil
+}
+
+// CronTrigger is recorded as what started the actions cron jobs run.
+const CronTrigger = "cron"
+
+// ActionExecutor runs the action of a cron job under the job's name.
 type ActionExecutor interface {
-	ExecuteAction(action.ActionConfig) error
+	ExecuteNamedAction(name, trigger string, actionConfig action.ActionConfig, execCtx *action.ExecutionContext) error
 }
 
 type ServerManager struct {
 	state *state.State
+	mu    sync.Mutex
 	cron  *cron.Cron
 }
 
@@ -34,17 +50,54 @@ func NewServerManager(state *state.State) *ServerManager {
 }
 
 func (sm *ServerManager) Start(cronJobs []CronJob, actionExecutor ActionExecutor) error {
-	sm.cron = cron.New()
+	c, err := newCron(cronJobs, actionExecutor)
+	if err != nil {
+		return err
+	}
+	sm.mu.Lock()
+	sm.cron = c
+	sm.mu.Unlock()
+	c.Start()
+	return nil
+}
+
+// Reschedule replaces the running cron jobs. The new schedule is built before
+// the old one is stopped, so an invalid schedule leaves the current jobs in
+// place. Jobs already running finish normally.
+func (sm *ServerManager) Reschedule(cronJobs []CronJob, actionExecutor ActionExecutor) error {
+	c, err := newCron(cronJobs, actionExecutor)
+	if err != nil {
+		return err
+	}
+	sm.mu.Lock()
+	old := sm.cron
+	sm.cron = c
+	sm.mu.Unlock()
+	if old != nil {
+		old.Stop()
+	}
+	c.Start()
+	return nil
+}
+
+// IsScheduling reports whether Start has been called in this process.
+func (sm *ServerManager) IsScheduling() bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.cron != nil
+}
+
+func newCron(cronJobs []CronJob, actionExecutor ActionExecutor) (*cron.Cron, error) {
+	c := cron.New()
 	for _, job := range cronJobs {
 		job := job // capture loop variable for closures
-		if _, err := sm.cron.AddFunc(job.Schedule, func() {
-			actionExecutor.ExecuteAction(job.Action)
+		if _, err := c.AddFunc(job.Schedule, func() {
+			actionExecutor.ExecuteNamedAction(job.Name, CronTrigger, job.Action, nil)
 		}); err != nil {
-			return fmt.Errorf("failed to schedule cron job '%s': %w", job.Name, err)
+			return nil, fmt.Errorf("failed to schedule cron job '%s': %w", job.Name, err)
 		}
 	}
-	sm.cron.Start()
-	return nil
+	return c, nil
 }
 
 // GetServerStatus checks if the server process is running.
@@ -73,9 +126,11 @@ func (sm *ServerManager) GetServerStatus() (int, bool) {
 
 // StopServer stops the running server process.
 func (sm *ServerManager) StopServer() error {
+	sm.mu.Lock()
 	if sm.cron != nil {
 		sm.cron.Stop()
 	}
+	sm.mu.Unlock()
 	pid, running := sm.GetServerStatus()
 	if !running {
 		// If we have a PID but the process isn't running, clean up the stale PID file.
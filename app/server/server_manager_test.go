@@ -11,7 +11,7 @@ type mockActionExecutor struct {
 	executed chan bool
 }
 
-func (m *mockActionExecutor) ExecuteAction(actionConfig action.ActionConfig) error {
+func (m *mockActionExecutor) ExecuteNamedAction(name, trigger string, actionConfig action.ActionConfig, execCtx *action.ExecutionContext) error {
 	m.executed <- true
 	return nil
 }
@@ -45,3 +45,16 @@ func (sm *ServerManager) StartServer() error {
 	// Disown the process
 	return cmd.Process.Release()
 }
+
+// ReloadServer asks the running background server to re-read its config file.
+func (sm *ServerManager) ReloadServer() error {
+	pid, running := sm.GetServerStatus()
+	if !running {
+		return fmt.Errorf("server is not running")
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("could not find process: %w", err)
+	}
+	return process.Signal(syscall.SIGHUP)
+}
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"badgermaps/events"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// CertReloadInterval is how often a CertReloader checks the certificate
+// and key files for changes.
+const CertReloadInterval = 30 * time.Second
+
+// CertReloader serves a TLS certificate loaded from a certificate and key
+// file and loads them again when either file changes, so a renewed
+// certificate is picked up without restarting the server. Connections
+// already open keep the certificate they were made with.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	events   *events.EventDispatcher
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	loaded fileStamp
+	failed fileStamp
+}
+
+// fileStamp identifies the versions of the certificate and key files that
+// were read, by modification time and size.
+type fileStamp struct {
+	certMod, keyMod   time.Time
+	certSize, keySize int64
+}
+
+// NewCertReloader loads the certificate and key. It fails when the pair
+// cannot be loaded, since the server has nothing to serve without it.
+func NewCertReloader(certFile, keyFile string, dispatcher *events.EventDispatcher) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, events: dispatcher}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It is meant for
+// tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Certificate returns the parsed leaf of the current certificate.
+func (r *CertReloader) Certificate() *x509.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil {
+		return nil
+	}
+	return r.cert.Leaf
+}
+
+// Reload reads the certificate and key again. When they do not load, as
+// when only one of them has been replaced so far, the current certificate
+// is kept and the error returned.
+func (r *CertReloader) Reload() error {
+	stamp, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		r.mu.Lock()
+		r.failed = stamp
+		r.mu.Unlock()
+		return fmt.Errorf("failed to load TLS certificate %s: %w", r.certFile, err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return fmt.Errorf("failed to parse TLS certificate %s: %w", r.certFile, err)
+		}
+	}
+	r.mu.Lock()
+	replaced := r.cert != nil
+	r.cert = &cert
+	r.loaded = stamp
+	r.failed = fileStamp{}
+	r.mu.Unlock()
+
+	verb := "Loaded"
+	if replaced {
+		verb = "Reloaded"
+	}
+	r.dispatch(events.Infof("server", "%s TLS certificate for %s, valid until %s", verb,
+		certificateName(cert.Leaf), cert.Leaf.NotAfter.Format(time.RFC3339)))
+	if remaining := time.Until(cert.Leaf.NotAfter); remaining < 7*24*time.Hour {
+		r.dispatch(events.Warningf("server", "TLS certificate %s expires in %s", r.certFile, remaining.Round(time.Hour)))
+	}
+	return nil
+}
+
+// Watch checks the files every interval until ctx is cancelled and reloads
+// them when either one changed. A pair that fails to load is reported once
+// and tried again after the next change.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = CertReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+func (r *CertReloader) check() {
+	stamp, err := r.stat()
+	if err != nil {
+		r.dispatch(events.Warningf("server", "Cannot check TLS certificate for changes: %v", err))
+		return
+	}
+	r.mu.RLock()
+	unchanged := stamp == r.loaded || stamp == r.failed
+	r.mu.RUnlock()
+	if unchanged {
+		return
+	}
+	if err := r.Reload(); err != nil {
+		r.dispatch(events.Warningf("server", "%v; keeping the current certificate", err))
+	}
+}
+
+func (r *CertReloader) stat() (fileStamp, error) {
+	cert, err := os.Stat(r.certFile)
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("failed to read TLS certificate: %w", err)
+	}
+	key, err := os.Stat(r.keyFile)
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("failed to read TLS key: %w", err)
+	}
+	return fileStamp{certMod: cert.ModTime(), keyMod: key.ModTime(), certSize: cert.Size(), keySize: key.Size()}, nil
+}
+
+func (r *CertReloader) dispatch(e events.Event) {
+	if r.events != nil {
+		r.events.Dispatch(e)
+	}
+}
+
+func certificateName(leaf *x509.Certificate) string {
+	if len(leaf.DNSNames) > 0 {
+		return strings.Join(leaf.DNSNames, ", ")
+	}
+	return leaf.Subject.CommonName
+}
+
+// ACMEConfig has the server obtain and renew its certificate from Let's
+// Encrypt, or another ACME directory, for the listed domains. The
+// certificates are kept in CacheDir so restarts do not request new ones.
+type ACMEConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Domains []string `yaml:"domains,omitempty"`
+	Email   string   `yaml:"email,omitempty"`
+	// CacheDir holds the account key and certificates. The server
+	// defaults it to acme in the config directory.
+	CacheDir string `yaml:"cache_dir,omitempty"`
+	// DirectoryURL is the ACME directory; empty is Let's Encrypt's
+	// production directory. Point it at the staging directory to test.
+	DirectoryURL string `yaml:"directory_url,omitempty"`
+	// HTTPPort, when set, serves HTTP-01 challenges on that port and
+	// redirects other plain HTTP requests to HTTPS. Without it only the
+	// TLS-ALPN-01 challenge on the server's own port is used, which needs
+	// that port to be reachable as 443.
+	HTTPPort int `yaml:"http_port,omitempty"`
+}
+
+// Validate checks that there is at least one domain name and that the
+// port and directory URL are usable.
+func (c ACMEConfig) Validate() error {
+	if len(c.Domains) == 0 {
+		return fmt.Errorf("server.acme needs at least one domain")
+	}
+	for _, domain := range c.Domains {
+		domain = strings.TrimSpace(domain)
+		if domain == "" || strings.ContainsAny(domain, " /:*") {
+			return fmt.Errorf("server.acme domain %q is not a host name", domain)
+		}
+	}
+	if c.HTTPPort < 0 || c.HTTPPort > 65535 {
+		return fmt.Errorf("server.acme.http_port must be between 0 and 65535")
+	}
+	if c.DirectoryURL != "" && !strings.HasPrefix(c.DirectoryURL, "https://") {
+		return fmt.Errorf("server.acme.directory_url must start with https://")
+	}
+	return nil
+}
+
+// ACMEManager obtains and renews certificates for an ACMEConfig.
+type ACMEManager struct {
+	manager *autocert.Manager
+}
+
+// NewACMEManager creates the manager. defaultCacheDir is used when the
+// config does not name a cache directory.
+func NewACMEManager(c ACMEConfig, defaultCacheDir string) (*ACMEManager, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	cacheDir := c.CacheDir
+	if cacheDir == "" {
+		cacheDir = defaultCacheDir
+	}
+	domains := make([]string, len(c.Domains))
+	for i, domain := range c.Domains {
+		domains[i] = strings.TrimSpace(domain)
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cacheDir),
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Email:      c.Email,
+	}
+	if c.DirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: c.DirectoryURL}
+	}
+	return &ACMEManager{manager: m}, nil
+}
+
+// TLSConfig returns the TLS config that serves the managed certificates
+// and answers TLS-ALPN-01 challenges.
+func (m *ACMEManager) TLSConfig() *tls.Config {
+	return m.manager.TLSConfig()
+}
+
+// HTTPHandler answers HTTP-01 challenges and redirects every other request
+// to HTTPS.
+func (m *ACMEManager) HTTPHandler() http.Handler {
+	return m.manager.HTTPHandler(nil)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate for host and its key.
+func writeTestCert(t *testing.T, certFile, keyFile, host string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, certFile, "CERTIFICATE", der, modTime)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER, modTime)
+}
+
+func writePEM(t *testing.T, path, kind string, der []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertReloaderPicksUpRenewedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Minute)
+	writeTestCert(t, certFile, keyFile, "old.example.com", start)
+
+	r, err := NewCertReloader(certFile, keyFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := r.Certificate().Subject.CommonName; name != "old.example.com" {
+		t.Fatalf("loaded %q, want old.example.com", name)
+	}
+
+	// A half-written renewal keeps the old certificate.
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r.check()
+	if name := r.Certificate().Subject.CommonName; name != "old.example.com" {
+		t.Fatalf("serving %q after a failed reload, want the old certificate", name)
+	}
+
+	writeTestCert(t, certFile, keyFile, "new.example.com", start.Add(time.Second))
+	r.check()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := cert.Leaf.Subject.CommonName; name != "new.example.com" {
+		t.Fatalf("serving %q after the renewal, want new.example.com", name)
+	}
+
+	if _, err := NewCertReloader(filepath.Join(dir, "missing.pem"), keyFile, nil); err == nil {
+		t.Fatal("want an error for a missing certificate")
+	}
+}
+
+func TestACMEConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		config  ACMEConfig
+		wantErr bool
+	}{
+		{ACMEConfig{Domains: []string{"hooks.example.com"}}, false},
+		{ACMEConfig{Domains: []string{"hooks.example.com"}, HTTPPort: 80, DirectoryURL: "https://acme-staging-v02.api.letsencrypt.org/directory"}, false},
+		{ACMEConfig{}, true},
+		{ACMEConfig{Domains: []string{"https://hooks.example.com"}}, true},
+		{ACMEConfig{Domains: []string{"*.example.com"}}, true},
+		{ACMEConfig{Domains: []string{"hooks.example.com"}, HTTPPort: 70000}, true},
+		{ACMEConfig{Domains: []string{"hooks.example.com"}, DirectoryURL: "http://localhost"}, true},
+	} {
+		if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) = %v, wantErr %v", tt.config, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"badgermaps/events"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// TunnelConfig configures the outbound webhook tunnel. When enabled, the
+// server dials the relay at URL and serves the webhooks it forwards, so
+// BadgerMaps can reach a machine without a public address.
+type TunnelConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url,omitempty"`
+	Token   string `yaml:"token,omitempty"`
+}
+
+// Validate checks that the relay URL is a ws:// or wss:// URL.
+func (c TunnelConfig) Validate() error {
+	u, err := url.Parse(strings.TrimSpace(c.URL))
+	if err != nil {
+		return fmt.Errorf("invalid tunnel relay URL: %w", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return fmt.Errorf("tunnel relay URL must start with ws:// or wss://")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("tunnel relay URL has no host")
+	}
+	return nil
+}
+
+// TunnelRequest is a webhook the relay received and forwards over the
+// tunnel. Body is base64 encoded on the wire.
+type TunnelRequest struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"headers,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// TunnelResponse answers the TunnelRequest with the same ID.
+type TunnelResponse struct {
+	ID     string      `json:"id"`
+	Status int         `json:"status"`
+	Header http.Header `json:"headers,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+const (
+	tunnelMinBackoff  = time.Second
+	tunnelMaxBackoff  = time.Minute
+	tunnelDialTimeout = 15 * time.Second
+	// tunnelPathPrefix keeps the relay away from /reload and other local
+	// endpoints; only webhooks are forwarded.
+	tunnelPathPrefix = "/webhook/"
+	// tunnelMaxInFlight caps the forwarded requests handled at once. The
+	// relay is not read while every slot is taken.
+	tunnelMaxInFlight = 16
+)
+
+// TunnelClient keeps a WebSocket connection open to a webhook relay and
+// passes each forwarded request to handler, reconnecting with backoff when
+// the connection drops.
+type TunnelClient struct {
+	config     TunnelConfig
+	handler    http.Handler
+	events     *events.EventDispatcher
+	connected  atomic.Bool
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewTunnelClient returns a client for the relay in config. handler serves
+// the forwarded webhooks, normally the server's own mux.
+func NewTunnelClient(config TunnelConfig, handler http.Handler, dispatcher *events.EventDispatcher) (*TunnelClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	config.URL = strings.TrimSpace(config.URL)
+	return &TunnelClient{
+		config:     config,
+		handler:    handler,
+		events:     dispatcher,
+		minBackoff: tunnelMinBackoff,
+		maxBackoff: tunnelMaxBackoff,
+	}, nil
+}
+
+// Connected reports whether the relay connection is currently open.
+func (t *TunnelClient) Connected() bool {
+	return t.connected.Load()
+}
+
+// Run connects to the relay and serves forwarded webhooks until ctx is
+// cancelled.
+func (t *TunnelClient) Run(ctx context.Context) {
+	backoff := t.minBackoff
+	for ctx.Err() == nil {
+		conn, err := t.dial()
+		if err != nil {
+			t.dispatch(events.Warningf("tunnel", "Could not connect to webhook relay %s: %v; retrying in %s", t.config.URL, err, backoff))
+		} else {
+			backoff = t.minBackoff
+			t.connected.Store(true)
+			t.dispatch(events.Infof("tunnel", "Connected to webhook relay %s", t.config.URL))
+			err = t.serve(ctx, conn)
+			t.connected.Store(false)
+			if ctx.Err() != nil {
+				return
+			}
+			t.dispatch(events.Warningf("tunnel", "Webhook relay connection lost: %v; reconnecting in %s", err, backoff))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > t.maxBackoff {
+			backoff = t.maxBackoff
+		}
+	}
+}
+
+func (t *TunnelClient) dial() (*websocket.Conn, error) {
+	cfg, err := websocket.NewConfig(t.config.URL, "http://localhost/")
+	if err != nil {
+		return nil, err
+	}
+	if t.config.Token != "" {
+		cfg.Header.Set("Authorization", "Bearer "+t.config.Token)
+	}
+	cfg.Dialer = &net.Dialer{Timeout: tunnelDialTimeout}
+	return websocket.DialConfig(cfg)
+}
+
+// serve reads forwarded requests until the connection closes. Up to
+// tunnelMaxInFlight requests are handled concurrently; replies are written
+// one at a time.
+func (t *TunnelClient) serve(ctx context.Context, conn *websocket.Conn) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	sem := make(chan struct{}, tunnelMaxInFlight)
+	for {
+		var req TunnelRequest
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return err
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp := t.forward(ctx, req)
+			writeMu.Lock()
+			err := websocket.JSON.Send(conn, resp)
+			writeMu.Unlock()
+			if err != nil {
+				t.dispatch(events.Warningf("tunnel", "Could not reply to relay request %s: %v", req.ID, err))
+			}
+		}()
+	}
+}
+
+// forward replays req against the local handler and captures the reply.
+func (t *TunnelClient) forward(ctx context.Context, req TunnelRequest) TunnelResponse {
+	resp := TunnelResponse{ID: req.ID}
+	u, err := url.ParseRequestURI(req.Path)
+	if err != nil || !strings.HasPrefix(u.Path, tunnelPathPrefix) {
+		resp.Status = http.StatusNotFound
+		return resp
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.RequestURI(), bytes.NewReader(req.Body))
+	if err != nil {
+		resp.Status = http.StatusBadRequest
+		return resp
+	}
+	for key, values := range req.Header {
+		httpReq.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	httpReq.RequestURI = u.RequestURI()
+	httpReq.RemoteAddr = "tunnel"
+
+	rec := &tunnelRecorder{header: make(http.Header)}
+	t.handler.ServeHTTP(rec, httpReq)
+	resp.Status = rec.status
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	resp.Header = rec.header
+	resp.Body = rec.body.Bytes()
+	t.dispatch(events.Debugf("tunnel", "Forwarded %s %s from relay: %d", method, u.Path, resp.Status))
+	return resp
+}
+
+// tunnelRecorder captures the reply of the local handler for the relay.
+type tunnelRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *tunnelRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *tunnelRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *tunnelRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
+
+func (t *TunnelClient) dispatch(e events.Event) {
+	if t.events != nil {
+		t.events.Dispatch(e)
+	}
+}
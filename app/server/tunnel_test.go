@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestTunnelConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		url     string
+		wantErr bool
+	}{
+		{"wss://relay.example.com/tunnel", false},
+		{"ws://localhost:9000", false},
+		{"https://relay.example.com", true},
+		{"wss://", true},
+		{"", true},
+	} {
+		if err := (TunnelConfig{URL: tt.url}).Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTunnelClientForwardsWebhooks(t *testing.T) {
+	var gotBody, gotHeader string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotHeader = string(body), r.Header.Get("X-Webhook-Signature")
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "processed "+r.URL.Path)
+	})
+
+	authHeader := make(chan string, 1)
+	replies := make(chan []TunnelResponse, 1)
+	relay := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		authHeader <- conn.Request().Header.Get("Authorization")
+		requests := []TunnelRequest{
+			{ID: "1", Method: http.MethodPost, Path: "/webhook/checkin", Header: http.Header{"X-Webhook-Signature": {"sha256=abc"}}, Body: []byte(`{"id":7}`)},
+			{ID: "2", Method: http.MethodPost, Path: "/reload"},
+		}
+		got := make([]TunnelResponse, 0, len(requests))
+		for _, req := range requests {
+			if err := websocket.JSON.Send(conn, req); err != nil {
+				t.Errorf("send: %v", err)
+				return
+			}
+			var resp TunnelResponse
+			if err := websocket.JSON.Receive(conn, &resp); err != nil {
+				t.Errorf("receive: %v", err)
+				return
+			}
+			got = append(got, resp)
+		}
+		replies <- got
+	}))
+	defer relay.Close()
+
+	client, err := NewTunnelClient(TunnelConfig{Enabled: true, URL: "ws" + strings.TrimPrefix(relay.URL, "http"), Token: "secret"}, handler, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Run(ctx)
+
+	select {
+	case got := <-replies:
+		if got[0].ID != "1" || got[0].Status != http.StatusOK || string(got[0].Body) != "processed /webhook/checkin" {
+			t.Errorf("webhook reply = %+v", got[0])
+		}
+		if got[1].ID != "2" || got[1].Status != http.StatusNotFound {
+			t.Errorf("non-webhook path should not be forwarded, got %+v", got[1])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not receive replies")
+	}
+	if auth := <-authHeader; auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if gotBody != `{"id":7}` || gotHeader != "sha256=abc" {
+		t.Errorf("handler got body %q header %q", gotBody, gotHeader)
+	}
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults of WebhookBufferConfig.
+const (
+	DefaultWebhookBufferWindow     = 5 * time.Second
+	DefaultWebhookBufferMaxPending = 1000
+)
+
+// WebhookBufferConfig configures buffering of account create webhooks.
+// When enabled, webhooks for the same account within the window are
+// applied once, and the accounts of a window are fetched and stored
+// together.
+type WebhookBufferConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	WindowSeconds int  `yaml:"window_seconds,omitempty"`
+	// MaxPending caps the accounts waiting in the buffer. Webhooks beyond
+	// it are refused with 503 so the sender retries them.
+	MaxPending int `yaml:"max_pending,omitempty"`
+}
+
+// Validate checks that the window and cap are not negative.
+func (c WebhookBufferConfig) Validate() error {
+	if c.WindowSeconds < 0 {
+		return fmt.Errorf("server.webhook_buffer.window_seconds must not be negative")
+	}
+	if c.MaxPending < 0 {
+		return fmt.Errorf("server.webhook_buffer.max_pending must not be negative")
+	}
+	return nil
+}
+
+// Window returns the configured window or the default.
+func (c WebhookBufferConfig) Window() time.Duration {
+	if c.WindowSeconds > 0 {
+		return time.Duration(c.WindowSeconds) * time.Second
+	}
+	return DefaultWebhookBufferWindow
+}
+
+// Limit returns the configured cap or the default.
+func (c WebhookBufferConfig) Limit() int {
+	if c.MaxPending > 0 {
+		return c.MaxPending
+	}
+	return DefaultWebhookBufferMaxPending
+}
+
+// WebhookBufferStats counts what a WebhookBuffer did with the webhooks it
+// was given.
+type WebhookBufferStats struct {
+	Received int64 `json:"received"`
+	// Deduplicated webhooks named an entity already waiting or applied
+	// within the window.
+	Deduplicated int64 `json:"deduplicated"`
+	// Dropped webhooks were refused because the buffer was full.
+	Dropped int64 `json:"dropped"`
+	Batches int64 `json:"batches"`
+	Applied int64 `json:"applied"`
+	Failed  int64 `json:"failed"`
+}
+
+// WebhookBuffer collects webhooks by entity ID and hands each window's
+// entities to flush in one batch. The latest payload of an entity wins.
+type WebhookBuffer[T any] struct {
+	window     time.Duration
+	maxPending int
+	flush      func(items map[int]T) error
+
+	mu      sync.Mutex
+	pending map[int]T
+	// recent holds when each entity was last flushed, so a duplicate that
+	// arrives just after its batch is not fetched again.
+	recent  map[int]time.Time
+	timer   *time.Timer
+	stopped bool
+	stats   WebhookBufferStats
+	// flushing serializes flushes so batches are applied in order.
+	flushing sync.Mutex
+	now      func() time.Time
+}
+
+// NewWebhookBuffer returns a buffer that calls flush with the entities
+// collected during each window.
+func NewWebhookBuffer[T any](config WebhookBufferConfig, flush func(items map[int]T) error) *WebhookBuffer[T] {
+	return &WebhookBuffer[T]{
+		window:     config.Window(),
+		maxPending: config.Limit(),
+		flush:      flush,
+		pending:    make(map[int]T),
+		recent:     make(map[int]time.Time),
+		now:        time.Now,
+	}
+}
+
+// Add queues item for entity id. It returns false when the buffer is full
+// or stopped and the webhook was dropped.
+func (b *WebhookBuffer[T]) Add(id int, item T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Received++
+	if b.stopped {
+		b.stats.Dropped++
+		return false
+	}
+	if _, waiting := b.pending[id]; waiting {
+		b.stats.Deduplicated++
+		b.pending[id] = item
+		return true
+	}
+	if at, ok := b.recent[id]; ok && b.now().Sub(at) < b.window {
+		b.stats.Deduplicated++
+		return true
+	}
+	if len(b.pending) >= b.maxPending {
+		b.stats.Dropped++
+		return false
+	}
+	b.pending[id] = item
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.Flush)
+	}
+	return true
+}
+
+// Flush hands the waiting entities to flush now.
+func (b *WebhookBuffer[T]) Flush() {
+	b.flushing.Lock()
+	defer b.flushing.Unlock()
+
+	b.mu.Lock()
+	items := b.pending
+	b.pending = make(map[int]T)
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	now := b.now()
+	for id, at := range b.recent {
+		if now.Sub(at) >= b.window {
+			delete(b.recent, id)
+		}
+	}
+	for id := range items {
+		b.recent[id] = now
+	}
+	b.mu.Unlock()
+
+	if len(items) == 0 {
+		return
+	}
+	err := b.flush(items)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Batches++
+	if err != nil {
+		b.stats.Failed += int64(len(items))
+		// A failed batch may be sent again right away.
+		for id := range items {
+			delete(b.recent, id)
+		}
+		return
+	}
+	b.stats.Applied += int64(len(items))
+}
+
+// Stop flushes the waiting entities and refuses later webhooks.
+func (b *WebhookBuffer[T]) Stop() {
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+	b.Flush()
+}
+
+// Stats returns the counts so far.
+func (b *WebhookBuffer[T]) Stats() WebhookBufferStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// SortedIDs returns the entity IDs of a batch in ascending order.
+func SortedIDs[T any](items map[int]T) []int {
+	ids := make([]int, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
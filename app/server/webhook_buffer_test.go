@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookBuffer(t *testing.T) {
+	var mu sync.Mutex
+	var batches []map[int]string
+	fail := false
+	buf := NewWebhookBuffer(WebhookBufferConfig{Enabled: true, WindowSeconds: 60, MaxPending: 2}, func(items map[int]string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, items)
+		if fail {
+			return errors.New("store failed")
+		}
+		return nil
+	})
+	now := time.Now()
+	buf.now = func() time.Time { return now }
+
+	for _, add := range []struct {
+		id   int
+		item string
+		want bool
+	}{
+		{1, "first", true},
+		{1, "second", true}, // waiting: the later payload replaces it
+		{2, "other", true},
+		{3, "over the cap", false},
+	} {
+		if got := buf.Add(add.id, add.item); got != add.want {
+			t.Errorf("Add(%d) = %v, want %v", add.id, got, add.want)
+		}
+	}
+	buf.Flush()
+	if len(batches) != 1 || len(batches[0]) != 2 || batches[0][1] != "second" {
+		t.Fatalf("batches = %v", batches)
+	}
+
+	// Applied within the window: deduplicated without another batch.
+	buf.Add(1, "again")
+	buf.Flush()
+	if len(batches) != 1 {
+		t.Fatalf("duplicate within the window was flushed: %v", batches)
+	}
+
+	// After the window it is applied again; a failed batch may be resent.
+	now = now.Add(2 * time.Minute)
+	fail = true
+	buf.Add(1, "later")
+	buf.Flush()
+	buf.Add(1, "retry")
+	fail = false
+	buf.Stop()
+	if len(batches) != 3 || batches[2][1] != "retry" {
+		t.Fatalf("batches = %v", batches)
+	}
+	if buf.Add(4, "after stop") {
+		t.Error("Add after Stop was accepted")
+	}
+
+	want := WebhookBufferStats{Received: 8, Deduplicated: 2, Dropped: 2, Batches: 3, Applied: 3, Failed: 1}
+	if got := buf.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestWebhookBufferFlushesAfterWindow(t *testing.T) {
+	done := make(chan map[int]int, 1)
+	buf := NewWebhookBuffer(WebhookBufferConfig{}, func(items map[int]int) error {
+		done <- items
+		return nil
+	})
+	buf.window = 10 * time.Millisecond
+	buf.Add(5, 50)
+	select {
+	case items := <-done:
+		if items[5] != 50 {
+			t.Errorf("items = %v", items)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the window elapsed without a flush")
+	}
+}
@@ -0,0 +1,415 @@
+// Package smartsync works out which pulls, pushes and retries the local
+// database needs and runs only those, in order.
+package smartsync
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/app/push"
+	"badgermaps/database"
+	"badgermaps/events"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Steps Smart Sync can run, in the order it runs them. Failed changes are
+// queued again before the pushes that send them, accounts are pushed
+// before check-ins that may belong to new accounts, and pulls come last so
+// they bring back what was pushed.
+const (
+	StepRetryFailed  = "retry_failed"
+	StepPushAccounts = "push_accounts"
+	StepPushCheckins = "push_checkins"
+	StepPullAccounts = "pull_accounts"
+	StepPullCheckins = "pull_checkins"
+	StepPullRoutes   = "pull_routes"
+	StepPullProfile  = "pull_profile"
+)
+
+// Statuses of a StepResult.
+const (
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// stepLabels names the steps for people.
+var stepLabels = map[string]string{
+	StepRetryFailed:  "Retry failed changes",
+	StepPushAccounts: "Push account changes",
+	StepPushCheckins: "Push check-in changes",
+	StepPullAccounts: "Pull accounts",
+	StepPullCheckins: "Pull check-ins",
+	StepPullRoutes:   "Pull routes",
+	StepPullProfile:  "Pull profile",
+}
+
+// pullSources maps each pull step to the SyncHistory source of its runs.
+var pullSources = []struct{ step, source string }{
+	{StepPullAccounts, "accounts"},
+	{StepPullCheckins, "checkins"},
+	{StepPullRoutes, "routes"},
+	{StepPullProfile, "user profile"},
+}
+
+// Step is one piece of work in a Plan and why it is needed.
+type Step struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Label returns the step's name for people, such as "Pull accounts".
+func (s Step) Label() string {
+	if label, ok := stepLabels[s.Name]; ok {
+		return label
+	}
+	return s.Name
+}
+
+// Plan is the work Smart Sync found to do. Deferred lists work it leaves
+// for later and why, such as pushes outside the push window or failed
+// changes that need the user.
+type Plan struct {
+	Steps      []Step        `json:"steps"`
+	Deferred   []string      `json:"deferred,omitempty"`
+	StaleAfter time.Duration `json:"-"`
+	// RetryAccounts and RetryCheckins are the failed changes the retry
+	// step queues again.
+	RetryAccounts []int `json:"retry_accounts,omitempty"`
+	RetryCheckins []int `json:"retry_checkins,omitempty"`
+}
+
+// Empty reports whether there is nothing to do.
+func (p *Plan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// Has reports whether the plan includes the step.
+func (p *Plan) Has(name string) bool {
+	for _, step := range p.Steps {
+		if step.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanSync inspects the local database at now: failed changes that a push
+// can retry, changes waiting to be pushed, and entities whose last pull
+// failed or finished more than staleAfter ago. A staleAfter of zero uses
+// the app's pull_stale_after.
+func PlanSync(a *app.App, now time.Time, staleAfter time.Duration) (*Plan, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	if staleAfter <= 0 {
+		staleAfter = a.PullStaleAfter()
+	}
+	plan := &Plan{StaleAfter: staleAfter}
+
+	accounts, err := database.GetAccountChanges(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account changes: %w", err)
+	}
+	checkins, err := database.GetCheckinChanges(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read check-in changes: %w", err)
+	}
+	pendingAccounts, pendingCheckins := 0, 0
+	rejected, held := 0, 0
+	for _, change := range accounts {
+		switch change.Status {
+		case "pending":
+			pendingAccounts++
+		case database.StatusValidationFailed:
+			rejected++
+		case "failed":
+			if retryable(a, change) {
+				plan.RetryAccounts = append(plan.RetryAccounts, change.ChangeId)
+			} else {
+				held++
+			}
+		}
+	}
+	for _, change := range checkins {
+		switch change.Status {
+		case "pending":
+			pendingCheckins++
+		case "failed":
+			plan.RetryCheckins = append(plan.RetryCheckins, change.ChangeId)
+		}
+	}
+	if rejected > 0 {
+		plan.Deferred = append(plan.Deferred, fmt.Sprintf("%d account change(s) rejected by BadgerMaps need editing before they are pushed again", rejected))
+	}
+	if held > 0 {
+		plan.Deferred = append(plan.Deferred, fmt.Sprintf("%d failed account change(s) conflict with newer remote values; resolve them in Conflicts", held))
+	}
+
+	toPushAccounts := pendingAccounts + len(plan.RetryAccounts)
+	toPushCheckins := pendingCheckins + len(plan.RetryCheckins)
+	if toPushAccounts+toPushCheckins > 0 {
+		if err := push.CheckPushWindow(a, now); err != nil {
+			var outside *push.OutsideWindowError
+			if errors.As(err, &outside) {
+				plan.Deferred = append(plan.Deferred, fmt.Sprintf("%d change(s) wait for the push window: %v", toPushAccounts+toPushCheckins, err))
+			} else {
+				plan.Deferred = append(plan.Deferred, fmt.Sprintf("%d change(s) are not pushed until the push window is fixed: %v", toPushAccounts+toPushCheckins, err))
+			}
+			plan.RetryAccounts, plan.RetryCheckins = nil, nil
+			toPushAccounts, toPushCheckins = 0, 0
+		}
+	}
+	if retries := len(plan.RetryAccounts) + len(plan.RetryCheckins); retries > 0 {
+		plan.Steps = append(plan.Steps, Step{StepRetryFailed, fmt.Sprintf("%d failed change(s) can be retried", retries)})
+	}
+	if toPushAccounts > 0 {
+		plan.Steps = append(plan.Steps, Step{StepPushAccounts, fmt.Sprintf("%d account change(s) to push", toPushAccounts)})
+	}
+	if toPushCheckins > 0 {
+		plan.Steps = append(plan.Steps, Step{StepPushCheckins, fmt.Sprintf("%d check-in change(s) to push", toPushCheckins)})
+	}
+
+	runs, err := database.GetLatestSyncRuns(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync history: %w", err)
+	}
+	last := make(map[string]database.SyncHistoryEntry)
+	for _, run := range runs {
+		if run.Direction == "pull" {
+			last[run.Source] = run
+		}
+	}
+	for _, p := range pullSources {
+		if reason := staleReason(last, p.source, now, staleAfter); reason != "" {
+			plan.Steps = append(plan.Steps, Step{p.step, reason})
+		}
+	}
+	return plan, nil
+}
+
+// retryable reports whether a failed account change can be pushed again
+// as it is. A change that conflicts with newer remote values is left for
+// the user when the conflict strategy asks them.
+func retryable(a *app.App, change database.AccountPendingChange) bool {
+	if change.ChangeType != "UPDATE" && change.ChangeType != "DELETE" {
+		return true
+	}
+	conflict, err := database.GetAccountChangeConflict(a.DB, change.ChangeId)
+	if err != nil || conflict == nil {
+		return true
+	}
+	return a.ConflictWinner(change) != app.ConflictAsk
+}
+
+// staleReason returns why source needs pulling, or "" when its last pull
+// is recent and succeeded.
+func staleReason(last map[string]database.SyncHistoryEntry, source string, now time.Time, staleAfter time.Duration) string {
+	run, ok := last[source]
+	if !ok {
+		return "never pulled"
+	}
+	if run.Status == "failed" {
+		return "the last pull failed"
+	}
+	finished := run.StartedAt
+	if run.CompletedAt != nil {
+		finished = *run.CompletedAt
+	}
+	if age := now.Sub(finished); age > staleAfter {
+		return fmt.Sprintf("last pulled %s ago", age.Round(time.Minute))
+	}
+	return ""
+}
+
+// StepResult is how one step went. Detail counts what a push sent, or
+// holds the error of a failed step.
+type StepResult struct {
+	Step
+	Status   string        `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"-"`
+	Seconds  float64       `json:"seconds"`
+}
+
+// Result is the consolidated outcome of a Smart Sync run.
+type Result struct {
+	Steps    []StepResult `json:"steps"`
+	Deferred []string     `json:"deferred,omitempty"`
+	// Pushed, PushFailed and Held count the changes the push steps sent,
+	// that failed, and that stayed queued, such as unconfirmed deletes.
+	Pushed     int `json:"pushed"`
+	PushFailed int `json:"push_failed"`
+	Held       int `json:"held"`
+	err        error
+}
+
+// Err returns the error of the step that stopped the run, or nil.
+func (r *Result) Err() error {
+	return r.err
+}
+
+// Summary describes the run in one line.
+func (r *Result) Summary() string {
+	if len(r.Steps) == 0 {
+		return "Everything is up to date."
+	}
+	var parts []string
+	for _, step := range r.Steps {
+		part := strings.ToLower(step.Label()[:1]) + step.Label()[1:]
+		switch step.Status {
+		case StatusFailed:
+			part += " failed"
+		case StatusSkipped:
+			part += " skipped"
+		}
+		if step.Detail != "" && step.Status == StatusDone {
+			part += " (" + step.Detail + ")"
+		}
+		parts = append(parts, part)
+	}
+	summary := strings.Join(parts, ", ")
+	return strings.ToUpper(summary[:1]) + summary[1:] + "."
+}
+
+// Run carries out plan under the sync lock, stopping at the first step
+// that fails and skipping the rest. onStep, when set, is called before
+// each step with its position.
+func Run(a *app.App, plan *Plan, onStep func(step Step, index, total int)) *Result {
+	result := &Result{Deferred: plan.Deferred}
+	if plan.Empty() {
+		return result
+	}
+	release, err := a.AcquireSyncLock("smart sync")
+	if err != nil {
+		result.err = err
+		for _, step := range plan.Steps {
+			result.Steps = append(result.Steps, StepResult{Step: step, Status: StatusSkipped})
+		}
+		return result
+	}
+	defer release()
+
+	a.Events.Dispatch(events.Infof("sync", "Smart Sync: %d step(s) to run", len(plan.Steps)))
+	for i, step := range plan.Steps {
+		if result.err != nil {
+			result.Steps = append(result.Steps, StepResult{Step: step, Status: StatusSkipped})
+			continue
+		}
+		if onStep != nil {
+			onStep(step, i, len(plan.Steps))
+		}
+		a.Events.Dispatch(events.Infof("sync", "Smart Sync: %s (%s)", step.Label(), step.Reason))
+		start := time.Now()
+		detail, err := runStep(a, plan, step, result)
+		elapsed := time.Since(start)
+		stepResult := StepResult{Step: step, Status: StatusDone, Detail: detail, Duration: elapsed, Seconds: elapsed.Seconds()}
+		if err != nil {
+			stepResult.Status = StatusFailed
+			stepResult.Detail = err.Error()
+			result.err = fmt.Errorf("%s: %w", strings.ToLower(step.Label()), err)
+			a.Events.Dispatch(events.Errorf("sync", "Smart Sync: %s failed: %v", step.Label(), err))
+		}
+		result.Steps = append(result.Steps, stepResult)
+	}
+	a.Events.Dispatch(events.Infof("sync", "Smart Sync: %s", result.Summary()))
+	return result
+}
+
+func runStep(a *app.App, plan *Plan, step Step, result *Result) (string, error) {
+	switch step.Name {
+	case StepRetryFailed:
+		for _, id := range plan.RetryAccounts {
+			if err := database.UpdatePendingChangeStatus(a.DB, "AccountsPendingChanges", id, "pending"); err != nil {
+				return "", err
+			}
+		}
+		for _, id := range plan.RetryCheckins {
+			if err := database.UpdatePendingChangeStatus(a.DB, "AccountCheckinsPendingChanges", id, "pending"); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%d queued again", len(plan.RetryAccounts)+len(plan.RetryCheckins)), nil
+	case StepPushAccounts:
+		return pushAndCount(a, result, accountStatuses, push.RunPushAccounts)
+	case StepPushCheckins:
+		return pushAndCount(a, result, checkinStatuses, push.RunPushCheckins)
+	case StepPullAccounts:
+		return "", pull.PullGroupAccounts(a, 0)
+	case StepPullCheckins:
+		return "", pull.PullGroupCheckins(a)
+	case StepPullRoutes:
+		return "", pull.PullGroupRoutes(a)
+	case StepPullProfile:
+		_, err := pull.PullProfile(a, nil)
+		return "", err
+	}
+	return "", fmt.Errorf("unknown step %q", step.Name)
+}
+
+// pushAndCount runs a push and counts, from the statuses of the changes
+// that were pending before it, how many were sent, failed, or stayed.
+func pushAndCount(a *app.App, result *Result, statuses func(*app.App) (map[int]string, error), run func(*app.App) error) (string, error) {
+	before, err := statuses(a)
+	if err != nil {
+		return "", err
+	}
+	if err := run(a); err != nil {
+		return "", err
+	}
+	after, err := statuses(a)
+	if err != nil {
+		return "", err
+	}
+	pushed, failed, held := 0, 0, 0
+	for id, status := range before {
+		if status != "pending" {
+			continue
+		}
+		switch status, ok := after[id]; {
+		case !ok, status == "completed":
+			pushed++
+		case status == "failed", status == database.StatusValidationFailed:
+			failed++
+		default:
+			held++
+		}
+	}
+	result.Pushed += pushed
+	result.PushFailed += failed
+	result.Held += held
+	detail := fmt.Sprintf("%d pushed", pushed)
+	if failed > 0 {
+		detail += fmt.Sprintf(", %d failed", failed)
+	}
+	if held > 0 {
+		detail += fmt.Sprintf(", %d held", held)
+	}
+	return detail, nil
+}
+
+func accountStatuses(a *app.App) (map[int]string, error) {
+	changes, err := database.GetAccountChanges(a.DB)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[int]string, len(changes))
+	for _, change := range changes {
+		statuses[change.ChangeId] = change.Status
+	}
+	return statuses, nil
+}
+
+func checkinStatuses(a *app.App) (map[int]string, error) {
+	changes, err := database.GetCheckinChanges(a.DB)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[int]string, len(changes))
+	for _, change := range changes {
+		statuses[change.ChangeId] = change.Status
+	}
+	return statuses, nil
+}
@@ -0,0 +1,121 @@
+package smartsync
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func newTestApp(t *testing.T) *app.App {
+	t.Helper()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "sync.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := app.NewApp()
+	a.DB = db
+	return a
+}
+
+func TestPlanSyncAndRetry(t *testing.T) {
+	a := newTestApp(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	stamp := func(d time.Duration) string { return now.Add(-d).Format("2006-01-02 15:04:05") }
+	for _, stmt := range []string{
+		`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, Summary, Details, StartedAt, CompletedAt) VALUES
+			('1', 'pull', 'pull', 'accounts', 'manual', 'completed', '', '', '` + stamp(time.Hour) + `', '` + stamp(time.Hour) + `'),
+			('2', 'pull', 'pull', 'checkins', 'manual', 'completed', '', '', '` + stamp(48*time.Hour) + `', '` + stamp(48*time.Hour) + `'),
+			('3', 'pull', 'pull', 'routes', 'manual', 'failed', '', '', '` + stamp(time.Hour) + `', '` + stamp(time.Hour) + `')`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, Status) VALUES
+			(5, 'UPDATE', '{"phone":"555"}', 'pending'), (0, 'CREATE', '{"last_name":"New"}', 'failed')`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, Status, ValidationErrors) VALUES
+			(6, 'UPDATE', '{"email":"x"}', 'failed', '{"email":["invalid"]}')`,
+		`INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, Type, ChangeType, Status) VALUES (0, 5, 'Visit', 'CREATE', 'failed')`,
+	} {
+		if _, err := a.DB.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	plan, err := PlanSync(a, now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, step := range plan.Steps {
+		got = append(got, step.Name+": "+step.Reason)
+	}
+	want := []string{
+		"retry_failed: 2 failed change(s) can be retried",
+		"push_accounts: 2 account change(s) to push",
+		"push_checkins: 1 check-in change(s) to push",
+		"pull_checkins: last pulled 48h0m0s ago",
+		"pull_routes: the last pull failed",
+		"pull_profile: never pulled",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("steps:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(plan.Deferred) != 1 || !strings.Contains(plan.Deferred[0], "1 account change(s) rejected") {
+		t.Errorf("deferred = %q", plan.Deferred)
+	}
+
+	// Only the retry step runs here; the others need the API.
+	retry := &Plan{Steps: plan.Steps[:1], RetryAccounts: plan.RetryAccounts, RetryCheckins: plan.RetryCheckins}
+	result := Run(a, retry, nil)
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if summary := result.Summary(); summary != "Retry failed changes (2 queued again)." {
+		t.Errorf("summary = %q", summary)
+	}
+	accounts, err := database.GetPendingAccountChanges(a.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkins, err := database.GetPendingCheckinChanges(a.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || len(checkins) != 1 {
+		t.Errorf("pending after retry = %d accounts, %d check-ins; want 2 and 1", len(accounts), len(checkins))
+	}
+
+	// With a fresh pull of everything and nothing queued there is nothing
+	// left to do.
+	a.Config.PullStaleAfter = "72h"
+	if _, err := a.DB.GetDB().Exec(`DELETE FROM AccountsPendingChanges WHERE Status = 'pending'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.DB.GetDB().Exec(`DELETE FROM AccountCheckinsPendingChanges`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.DB.GetDB().Exec(`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, Summary, Details, StartedAt, CompletedAt) VALUES
+		('4', 'pull', 'pull', 'routes', 'manual', 'completed', '', '', '` + stamp(time.Hour) + `', '` + stamp(time.Hour) + `'),
+		('5', 'pull', 'pull', 'user profile', 'manual', 'completed', '', '', '` + stamp(time.Hour) + `', '` + stamp(time.Hour) + `')`); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = PlanSync(a, now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() {
+		t.Errorf("expected nothing to do, got %+v", plan.Steps)
+	}
+	if summary := Run(a, plan, nil).Summary(); summary != "Everything is up to date." {
+		t.Errorf("summary = %q", summary)
+	}
+}
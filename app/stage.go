@@ -0,0 +1,368 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entities a change can be staged for.
+const (
+	StageEntityAccount = "account"
+	StageEntityCheckin = "checkin"
+)
+
+// checkinStageFields are the fields a staged check-in may set.
+var checkinStageFields = map[string]bool{
+	"type":         true,
+	"comments":     true,
+	"log_datetime": true,
+	"crm_id":       true,
+	"created_by":   true,
+	"extra_fields": true,
+}
+
+// StageRequest is a change authored outside the app, such as by a script
+// running `push stage`. For accounts, ID is the account and ChangeType
+// defaults to UPDATE; CREATE takes no ID. For check-ins, ID is the account
+// the check-in is logged against and only CREATE is supported.
+//
+// IdempotencyKey lets a script retry safely: staging the same change under
+// the same key again is a no-op reported as Duplicate. A key is generated
+// when none is given.
+type StageRequest struct {
+	Entity         string            `json:"entity"`
+	ID             int               `json:"id,omitempty"`
+	ChangeType     string            `json:"change_type,omitempty"`
+	Fields         map[string]string `json:"fields,omitempty"`
+	IdempotencyKey string            `json:"idempotency_key,omitempty"`
+	Duplicate      bool              `json:"duplicate,omitempty"`
+}
+
+// maxIdempotencyKeyLength is the longest key the pending change tables hold.
+const maxIdempotencyKeyLength = 64
+
+// String describes the request for messages.
+func (r StageRequest) String() string {
+	if r.ID == 0 {
+		return fmt.Sprintf("%s %s", r.ChangeType, r.Entity)
+	}
+	return fmt.Sprintf("%s %s %d", r.ChangeType, r.Entity, r.ID)
+}
+
+// stagedChange is a validated request ready to be written. hash is the
+// content hash its idempotency key is matched against.
+type stagedChange struct {
+	request StageRequest
+	hash    string
+	stage   func() error
+}
+
+// ParseStageRequests reads one request object or an array of them.
+func ParseStageRequests(data []byte) ([]StageRequest, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil, fmt.Errorf("no changes to stage")
+	}
+	var requests []StageRequest
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &requests); err != nil {
+			return nil, fmt.Errorf("invalid change list: %w", err)
+		}
+	} else {
+		var request StageRequest
+		if err := json.Unmarshal([]byte(trimmed), &request); err != nil {
+			return nil, fmt.Errorf("invalid change: %w", err)
+		}
+		requests = append(requests, request)
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no changes to stage")
+	}
+	return requests, nil
+}
+
+// StageChanges validates every request and stages them as pending changes,
+// with the same field checks as edits made in the app. Nothing is staged
+// when any request is invalid. It returns the requests as staged, with
+// defaults filled in.
+func (a *App) StageChanges(requests []StageRequest) ([]StageRequest, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	var staged []stagedChange
+	var problems []string
+	hashes := make(map[string]string)
+	for i, request := range requests {
+		change, err := a.prepareStage(request)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("change %d: %v", i+1, err))
+			continue
+		}
+		if key := change.request.IdempotencyKey; !change.request.Duplicate {
+			if hash, seen := hashes[key]; seen {
+				if hash != change.hash {
+					problems = append(problems, fmt.Sprintf("change %d: idempotency key %q is used for different changes", i+1, key))
+					continue
+				}
+				change.request.Duplicate = true
+				change.stage = func() error { return nil }
+			}
+			hashes[key] = change.hash
+		}
+		staged = append(staged, change)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("nothing was staged:\n- %s", strings.Join(problems, "\n- "))
+	}
+
+	done := make([]StageRequest, 0, len(staged))
+	for _, change := range staged {
+		if err := change.stage(); err != nil {
+			return done, fmt.Errorf("failed to stage %s: %w", change.request, err)
+		}
+		done = append(done, change.request)
+	}
+	return done, nil
+}
+
+func (a *App) prepareStage(r StageRequest) (stagedChange, error) {
+	r.Entity = strings.ToLower(strings.TrimSpace(r.Entity))
+	r.ChangeType = strings.ToUpper(strings.TrimSpace(r.ChangeType))
+	switch r.Entity {
+	case StageEntityAccount:
+		return a.prepareAccountStage(r)
+	case StageEntityCheckin:
+		return a.prepareCheckinStage(r)
+	case "":
+		return stagedChange{}, fmt.Errorf("entity is required (account or checkin)")
+	}
+	return stagedChange{}, fmt.Errorf("unknown entity %q (expected account or checkin)", r.Entity)
+}
+
+func (a *App) prepareAccountStage(r StageRequest) (stagedChange, error) {
+	if r.ChangeType == "" {
+		r.ChangeType = "UPDATE"
+	}
+	switch r.ChangeType {
+	case "CREATE":
+		if r.ID != 0 {
+			return stagedChange{}, fmt.Errorf("CREATE takes no account id; the API assigns one")
+		}
+	case "UPDATE", "DELETE":
+		if r.ID <= 0 {
+			return stagedChange{}, fmt.Errorf("%s needs an account id", r.ChangeType)
+		}
+		if err := a.requireAccount(r.ID); err != nil {
+			return stagedChange{}, err
+		}
+	default:
+		return stagedChange{}, fmt.Errorf("unknown change type %q (expected CREATE, UPDATE, or DELETE)", r.ChangeType)
+	}
+
+	fields := make(map[string]string, len(r.Fields))
+	for name, value := range r.Fields {
+		field, column, ok := accountStageField(name)
+		if !ok && !(r.ChangeType == "CREATE" && field == "address") {
+			return stagedChange{}, fmt.Errorf("field %q cannot be staged for accounts", name)
+		}
+		value = strings.TrimSpace(value)
+		if ok {
+			checked, err := validateAccountFieldValue(column, value)
+			if err != nil {
+				return stagedChange{}, err
+			}
+			value = checked
+		}
+		if _, dup := fields[field]; dup {
+			return stagedChange{}, fmt.Errorf("field %q is set more than once", field)
+		}
+		fields[field] = value
+	}
+	switch {
+	case r.ChangeType == "DELETE" && len(fields) > 0:
+		return stagedChange{}, fmt.Errorf("DELETE takes no fields")
+	case r.ChangeType == "UPDATE" && len(fields) == 0:
+		return stagedChange{}, fmt.Errorf("UPDATE needs at least one field")
+	case r.ChangeType == "CREATE" && fields["last_name"] == "":
+		return stagedChange{}, fmt.Errorf("CREATE needs last_name")
+	}
+	r.Fields = fields
+
+	changes := "{}"
+	if len(fields) > 0 {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return stagedChange{}, err
+		}
+		changes = string(data)
+	}
+	return a.keyedStage(r, "AccountsPendingChanges", database.AccountChangeHash(r.ID, r.ChangeType, changes), func(key string) error {
+		return database.StageKeyedAccountChange(a.DB, key, r.ID, r.ChangeType, changes)
+	})
+}
+
+// stageFollowUp stages the account UPDATE a followup action computed. A
+// change already staged under key, as when a check-in webhook is replayed,
+// is left alone.
+func (a *App) stageFollowUp(accountID int, fields map[string]string, key string) error {
+	staged, err := a.StageChanges([]StageRequest{{
+		Entity:         StageEntityAccount,
+		ID:             accountID,
+		ChangeType:     "UPDATE",
+		Fields:         fields,
+		IdempotencyKey: key,
+	}})
+	if err != nil {
+		return err
+	}
+	if staged[0].Duplicate {
+		a.Events.Dispatch(events.Debugf("action", "Follow-up for account %d is already staged under %s", accountID, key))
+		return nil
+	}
+	a.Events.Dispatch(events.Infof("action", "Staged follow-up for account %d: %s", accountID, strings.Join(sortedFieldNames(fields), ", ")))
+	return nil
+}
+
+// accountStageField resolves a field given as an API name (custom_text5) or
+// an Accounts column (CustomText5) to both. ok is false when the field is
+// not editable; field is still the lower-cased name then.
+func accountStageField(name string) (field, column string, ok bool) {
+	name = strings.TrimSpace(name)
+	if f, found := accountEditableFields[name]; found {
+		return f, name, true
+	}
+	field = strings.ToLower(name)
+	for c, f := range accountEditableFields {
+		if f == field {
+			return f, c, true
+		}
+	}
+	return field, "", false
+}
+
+func (a *App) prepareCheckinStage(r StageRequest) (stagedChange, error) {
+	if r.ChangeType == "" {
+		r.ChangeType = "CREATE"
+	}
+	if r.ChangeType != "CREATE" {
+		return stagedChange{}, fmt.Errorf("check-ins can only be created")
+	}
+	if r.ID <= 0 {
+		return stagedChange{}, fmt.Errorf("a check-in needs the id of its account")
+	}
+	if err := a.requireAccount(r.ID); err != nil {
+		return stagedChange{}, err
+	}
+
+	fields := make(map[string]string, len(r.Fields))
+	for name, value := range r.Fields {
+		field := strings.ToLower(strings.TrimSpace(name))
+		if !checkinStageFields[field] {
+			return stagedChange{}, fmt.Errorf("field %q cannot be staged for check-ins (expected %s)", name, strings.Join(sortedKeys(checkinStageFields), ", "))
+		}
+		fields[field] = strings.TrimSpace(value)
+	}
+	if fields["type"] == "" {
+		return stagedChange{}, fmt.Errorf("a check-in needs a type")
+	}
+
+	change := database.CheckinPendingChange{
+		AccountId:    r.ID,
+		CrmId:        nullString(fields["crm_id"]),
+		Type:         nullString(fields["type"]),
+		Comments:     nullString(fields["comments"]),
+		CreatedBy:    nullString(fields["created_by"]),
+		EndpointType: nullString("standard"),
+		ChangeType:   r.ChangeType,
+	}
+	if value := fields["log_datetime"]; value != "" {
+		t, _, err := parseDateTime(value, a.DisplayLocation())
+		if err != nil {
+			return stagedChange{}, fmt.Errorf("log_datetime: %w", err)
+		}
+		fields["log_datetime"] = t.UTC().Format(StoredTimeLayout)
+		change.LogDatetime = nullString(fields["log_datetime"])
+	}
+	if value := fields["extra_fields"]; value != "" {
+		var extra map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &extra); err != nil {
+			return stagedChange{}, fmt.Errorf("extra_fields must be a JSON object: %w", err)
+		}
+		change.ExtraFields = nullString(value)
+		change.EndpointType = nullString("custom")
+	}
+	r.Fields = fields
+	return a.keyedStage(r, "AccountCheckinsPendingChanges", database.CheckinChangeHash(change), func(key string) error {
+		change.IdempotencyKey = nullString(key)
+		return database.StageCheckinChange(a.DB, change)
+	})
+}
+
+// keyedStage completes a validated request whose change hashes to hash. A
+// request without a key gets a new one. A key already staged in table for
+// the same change makes the request a Duplicate that stages nothing, and
+// one staged for a different change is an error.
+func (a *App) keyedStage(r StageRequest, table, hash string, stage func(key string) error) (stagedChange, error) {
+	key := strings.TrimSpace(r.IdempotencyKey)
+	if key == "" {
+		key = database.NewIdempotencyKey()
+	} else {
+		if len(key) > maxIdempotencyKeyLength {
+			return stagedChange{}, fmt.Errorf("idempotency_key is longer than %d characters", maxIdempotencyKeyLength)
+		}
+		existing, err := database.GetChangesByIdempotencyKey(a.DB, table, key)
+		if err != nil {
+			return stagedChange{}, fmt.Errorf("failed to look up idempotency key %q: %w", key, err)
+		}
+		for _, change := range existing {
+			if change.ContentHash != hash {
+				return stagedChange{}, fmt.Errorf("idempotency key %q was already used for a different change (change %d)", key, change.ChangeId)
+			}
+		}
+		if len(existing) > 0 {
+			r.IdempotencyKey = key
+			r.Duplicate = true
+			return stagedChange{request: r, hash: hash, stage: func() error { return nil }}, nil
+		}
+	}
+	r.IdempotencyKey = key
+	return stagedChange{request: r, hash: hash, stage: func() error { return stage(key) }}, nil
+}
+
+func (a *App) requireAccount(accountID int) error {
+	if _, err := database.GetAccountByID(a.DB, accountID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("account %d is not in the local database; pull it first", accountID)
+		}
+		return fmt.Errorf("failed to read account %d: %w", accountID, err)
+	}
+	return nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func sortedFieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
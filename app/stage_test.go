@@ -0,0 +1,165 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestStageChanges(t *testing.T) {
+	a := NewApp()
+	a.Config.DisplayTimezone = "UTC"
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "stage.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName, FullName) VALUES (123, 'Acme', 'Acme')`); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := ParseStageRequests([]byte(`[
+		{"entity": "account", "id": 123, "fields": {"notes": " Called back ", "CustomText5": "foo"}},
+		{"entity": "checkin", "id": 123, "fields": {"type": "Phone Call", "log_datetime": "2024-05-01 09:30", "extra_fields": "{\"Log Type\": \"Call\"}"}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	staged, err := a.StageChanges(requests)
+	if err != nil {
+		t.Fatalf("StageChanges: %v", err)
+	}
+	if len(staged) != 2 || staged[0].ChangeType != "UPDATE" || staged[1].ChangeType != "CREATE" {
+		t.Fatalf("staged = %+v", staged)
+	}
+
+	accounts, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(accounts) != 1 {
+		t.Fatalf("pending account changes = %+v, %v", accounts, err)
+	}
+	if got := accounts[0].Changes; got != `{"custom_text5":"foo","notes":"Called back"}` {
+		t.Errorf("account changes = %s", got)
+	}
+	checkins, err := database.GetPendingCheckinChanges(db)
+	if err != nil || len(checkins) != 1 {
+		t.Fatalf("pending check-in changes = %+v, %v", checkins, err)
+	}
+	if c := checkins[0]; c.AccountId != 123 || c.Type.String != "Phone Call" || c.EndpointType.String != "custom" || !strings.HasPrefix(c.LogDatetime.String, "2024-05-01") {
+		t.Errorf("check-in change = %+v", c)
+	}
+
+	invalid := []StageRequest{
+		{Entity: "account", ID: 123, Fields: map[string]string{"notes": "ok"}},
+		{Entity: "account", ID: 123, Fields: map[string]string{"email": "not an email"}},
+		{Entity: "account", ID: 999, Fields: map[string]string{"notes": "missing"}},
+		{Entity: "account", ID: 123, Fields: map[string]string{"full_name": "computed"}},
+		{Entity: "checkin", ID: 123, Fields: map[string]string{"comments": "no type"}},
+		{Entity: "route", ID: 1},
+	}
+	_, err = a.StageChanges(invalid)
+	if err == nil {
+		t.Fatal("expected invalid changes to be rejected")
+	}
+	for _, want := range []string{"change 2", "change 3", "change 4", "change 5", "change 6"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not report %s: %v", want, err)
+		}
+	}
+	if accounts, _ := database.GetPendingAccountChanges(db); len(accounts) != 1 {
+		t.Errorf("a rejected batch staged %d account changes", len(accounts)-1)
+	}
+}
+
+func TestStageChangesIdempotencyKey(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "stage.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName, FullName) VALUES (123, 'Acme', 'Acme')`); err != nil {
+		t.Fatal(err)
+	}
+
+	checkin := StageRequest{Entity: "checkin", ID: 123, IdempotencyKey: "visit-1", Fields: map[string]string{"type": "Phone Call"}}
+	staged, err := a.StageChanges([]StageRequest{checkin, checkin})
+	if err != nil {
+		t.Fatalf("StageChanges: %v", err)
+	}
+	if staged[0].Duplicate || !staged[1].Duplicate {
+		t.Errorf("a repeat within a batch should be the only duplicate: %+v", staged)
+	}
+	staged, err = a.StageChanges([]StageRequest{checkin})
+	if err != nil || !staged[0].Duplicate {
+		t.Errorf("a retried change should be a duplicate: %+v, %v", staged, err)
+	}
+	if changes, _ := database.GetPendingCheckinChanges(db); len(changes) != 1 || changes[0].IdempotencyKey.String != "visit-1" {
+		t.Errorf("pending check-in changes = %+v", changes)
+	}
+
+	checkin.Fields = map[string]string{"type": "Drop-in"}
+	if _, err := a.StageChanges([]StageRequest{checkin}); err == nil || !strings.Contains(err.Error(), "different change") {
+		t.Errorf("reusing a key for a different change: err = %v", err)
+	}
+
+	account, err := a.StageChanges([]StageRequest{{Entity: "account", ID: 123, Fields: map[string]string{"notes": "x"}}})
+	if err != nil || account[0].IdempotencyKey == "" {
+		t.Errorf("a change without a key should get one: %+v, %v", account, err)
+	}
+}
+
+func TestStageFollowUp(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "followup.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName, FullName) VALUES (123, 'Acme', 'Acme')`); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := map[string]string{"follow_up_date": "2024-05-15"}
+	for i := 0; i < 2; i++ {
+		if err := a.stageFollowUp(123, fields, "followup-77-abc"); err != nil {
+			t.Fatalf("stageFollowUp: %v", err)
+		}
+	}
+	changes, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("pending account changes = %+v, %v; want one for a replayed check-in", changes, err)
+	}
+	if c := changes[0]; c.AccountId != 123 || c.ChangeType != "UPDATE" || c.Changes != `{"follow_up_date":"2024-05-15"}` {
+		t.Errorf("change = %+v", c)
+	}
+	if err := a.stageFollowUp(123, map[string]string{"follow_up_date": "soon"}, ""); err == nil {
+		t.Error("expected an invalid follow-up date to be refused")
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"badgermaps/events"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StartupPhase is a timed step of starting the app, such as loading the
+// config or building the GUI.
+type StartupPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Startup phase names.
+const (
+	StartupConfigLoad = "config load"
+	StartupDBConnect  = "database connect"
+	StartupAPITest    = "API test"
+	StartupGUIBuild   = "GUI build"
+)
+
+// startupProfile records the startup phases. Phases that end before
+// logging is set up are logged once it is.
+type startupProfile struct {
+	mu      sync.Mutex
+	started time.Time
+	phases  []StartupPhase
+	logging bool
+	// done is set by LogStartupSummary; phases started later, such as
+	// those of a config reload, are not startup phases and are not timed.
+	done bool
+}
+
+// StartPhase times a startup phase until the returned function is called,
+// and logs how long it took at debug level.
+func (a *App) StartPhase(name string) (end func()) {
+	a.startup.mu.Lock()
+	done := a.startup.done
+	a.startup.mu.Unlock()
+	if done {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		phase := StartupPhase{Name: name, Duration: time.Since(start)}
+		a.startup.mu.Lock()
+		a.startup.phases = append(a.startup.phases, phase)
+		logging := a.startup.logging
+		a.startup.mu.Unlock()
+		if logging {
+			a.logStartupPhase(phase)
+		}
+	}
+}
+
+// StartupPhases returns the phases timed so far, in the order they ended.
+func (a *App) StartupPhases() []StartupPhase {
+	a.startup.mu.Lock()
+	defer a.startup.mu.Unlock()
+	return append([]StartupPhase(nil), a.startup.phases...)
+}
+
+// SinceStart returns how long ago the app was created.
+func (a *App) SinceStart() time.Duration {
+	return time.Since(a.startup.started)
+}
+
+// flushStartupProfile logs the phases that ended before logging was set
+// up, and every later one as it ends.
+func (a *App) flushStartupProfile() {
+	a.startup.mu.Lock()
+	phases := append([]StartupPhase(nil), a.startup.phases...)
+	a.startup.logging = true
+	a.startup.mu.Unlock()
+	for _, phase := range phases {
+		a.logStartupPhase(phase)
+	}
+}
+
+func (a *App) logStartupPhase(phase StartupPhase) {
+	a.Events.Dispatch(events.Debugf("startup", "%s took %s", phase.Name, phase.Duration.Round(time.Millisecond)))
+}
+
+// LogStartupSummary logs, at debug level, how long the app took to become
+// ready and the phases that took that time.
+func (a *App) LogStartupSummary(ready string) {
+	a.startup.mu.Lock()
+	if a.startup.done {
+		a.startup.mu.Unlock()
+		return
+	}
+	a.startup.done = true
+	a.startup.mu.Unlock()
+	phases := a.StartupPhases()
+	parts := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		parts = append(parts, fmt.Sprintf("%s %s", phase.Name, phase.Duration.Round(time.Millisecond)))
+	}
+	a.Events.Dispatch(events.Debugf("startup", "%s %s after start (%s)", ready, a.SinceStart().Round(time.Millisecond), strings.Join(parts, ", ")))
+}
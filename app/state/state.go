@@ -3,21 +3,29 @@ package state
 // State represents flags passed in. Do not set defaults for these.
 
 type State struct {
-	Verbose           bool
-	Quiet             bool
-	Debug             bool
-	NoColor           bool
-	NoInput           bool
-	ConfigFile        *string
-	IsGui             bool
-	LogFile           string
-	PIDFile           string
-	ServerHost        string
-	ServerPort        int
-	TLSEnabled        bool
-	TLSCert           string
-	TLSKey            string
-	ServerLogRequests bool
+	Verbose            bool
+	Quiet              bool
+	Debug              bool
+	NoColor            bool
+	NoInput            bool
+	ConfigFile         *string
+	IsGui              bool
+	LogFile            string
+	PIDFile            string
+	ServerHost         string
+	ServerPort         int
+	TLSEnabled         bool
+	TLSCert            string
+	TLSKey             string
+	ServerLogRequests  bool
+	IgnorePushWindow   bool
+	PushToSandbox      bool
+	SkipSchemaCheck    bool
+	ConfirmDeletes     bool
+	PullRadius         string
+	LowBandwidth       bool
+	Environment        string
+	ConfirmEnvironment string
 }
 
 // NewState creates a new State object with default values
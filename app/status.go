@@ -0,0 +1,169 @@
+package app
+
+import (
+	"badgermaps/database"
+	"time"
+)
+
+// StatusReport is the health summary shown by `badgermaps status`. It
+// mirrors the Home tab so scripts can check the same things.
+type StatusReport struct {
+	API       APIStatus       `json:"api"`
+	Database  DatabaseStatus  `json:"database"`
+	LastRuns  []LastRunStatus `json:"last_runs"`
+	Pending   PendingStatus   `json:"pending_changes"`
+	Scheduler SchedulerStatus `json:"scheduler"`
+	Server    ServerStatus    `json:"server"`
+}
+
+// APIStatus reports whether the BadgerMaps API answered.
+type APIStatus struct {
+	URL       string `json:"url"`
+	Connected bool   `json:"connected"`
+	Sandbox   string `json:"push_sandbox,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DatabaseStatus reports the database connection and whether the schema
+// matches what this version expects.
+type DatabaseStatus struct {
+	Type        string `json:"type"`
+	Connected   bool   `json:"connected"`
+	SchemaValid bool   `json:"schema_valid"`
+	Error       string `json:"error,omitempty"`
+}
+
+// LastRunStatus is the latest finished pull or push for one source.
+type LastRunStatus struct {
+	Direction  string     `json:"direction"`
+	Source     string     `json:"source"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Summary    string     `json:"summary,omitempty"`
+	Note       string     `json:"note,omitempty"`
+}
+
+// PendingStatus counts the changes waiting to be pushed.
+type PendingStatus struct {
+	Accounts int `json:"accounts"`
+	Checkins int `json:"checkins"`
+}
+
+// SchedulerStatus lists the configured cron jobs and when the next one runs.
+type SchedulerStatus struct {
+	Jobs    []ScheduledJobStatus `json:"jobs"`
+	NextJob string               `json:"next_job,omitempty"`
+	NextRun *time.Time           `json:"next_run,omitempty"`
+}
+
+// ScheduledJobStatus is one cron job and its next run.
+type ScheduledJobStatus struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// ServerStatus reports the webhook server process.
+type ServerStatus struct {
+	Running bool `json:"running"`
+	PID     int  `json:"pid,omitempty"`
+}
+
+// Healthy reports whether the API and database are reachable and the schema
+// is valid.
+func (r StatusReport) Healthy() bool {
+	return r.API.Connected && r.Database.Connected && r.Database.SchemaValid
+}
+
+// Status gathers the current health summary.
+func (a *App) Status() StatusReport {
+	var report StatusReport
+	now := time.Now()
+
+	report.API.URL = a.Config.API.BaseURL
+	if a.PushToSandbox() {
+		report.API.Sandbox = a.Config.API.SandboxURL
+	}
+	if a.API != nil {
+		// The client checked the connection when it was built; test again
+		// only to explain a failure.
+		if a.API.IsConnected() {
+			report.API.Connected = true
+		} else if err := a.API.TestAPIConnection(); err != nil {
+			report.API.Error = err.Error()
+		} else {
+			report.API.Connected = true
+		}
+	} else {
+		report.API.Error = "API is not configured"
+	}
+
+	report.Database.Type = a.Config.DB.Type
+	if a.DB != nil && a.DB.IsConnected() {
+		report.Database.Connected = true
+		if err := a.DB.ValidateSchema(a.State); err != nil {
+			report.Database.Error = err.Error()
+		} else {
+			report.Database.SchemaValid = true
+		}
+		a.addDatabaseStatus(&report)
+	} else {
+		report.Database.Error = "database is not connected"
+	}
+
+	for _, job := range a.Config.CronJobs {
+		status := ScheduledJobStatus{Name: job.Name, Schedule: job.Schedule}
+		next, err := job.NextRun(now)
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.NextRun = &next
+			if report.Scheduler.NextRun == nil || next.Before(*report.Scheduler.NextRun) {
+				report.Scheduler.NextRun = &next
+				report.Scheduler.NextJob = job.Name
+			}
+		}
+		report.Scheduler.Jobs = append(report.Scheduler.Jobs, status)
+	}
+
+	if a.Server != nil {
+		report.Server.PID, report.Server.Running = a.Server.GetServerStatus()
+		if !report.Server.Running {
+			report.Server.PID = 0
+		}
+	}
+	return report
+}
+
+// addDatabaseStatus fills in the parts of the report read from the
+// database. Failures are noted on the database status.
+func (a *App) addDatabaseStatus(report *StatusReport) {
+	runs, err := database.GetLatestSyncRuns(a.DB)
+	if err != nil {
+		report.Database.Error = err.Error()
+	}
+	for _, run := range runs {
+		report.LastRuns = append(report.LastRuns, LastRunStatus{
+			Direction:  run.Direction,
+			Source:     run.Source,
+			Status:     run.Status,
+			StartedAt:  run.StartedAt,
+			FinishedAt: run.CompletedAt,
+			Summary:    run.Summary,
+			Note:       run.Note,
+		})
+	}
+
+	if accounts, err := database.GetPendingAccountChanges(a.DB); err == nil {
+		report.Pending.Accounts = len(accounts)
+	} else {
+		report.Database.Error = err.Error()
+	}
+	if checkins, err := database.GetPendingCheckinChanges(a.DB); err == nil {
+		report.Pending.Checkins = len(checkins)
+	} else {
+		report.Database.Error = err.Error()
+	}
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Sync directions of a FieldMaps entry.
+const (
+	// SyncBoth pulls and pushes the field. It is the default.
+	SyncBoth = "both"
+	// SyncPullOnly takes the field from BadgerMaps and never pushes local
+	// edits of it.
+	SyncPullOnly = "pull"
+	// SyncPushOnly keeps the local value: pulls never overwrite it once the
+	// account is stored, and local edits are pushed.
+	SyncPushOnly = "push"
+)
+
+// SyncDirections lists the directions in the order the GUI offers them.
+func SyncDirections() []string {
+	return []string{SyncBoth, SyncPullOnly, SyncPushOnly}
+}
+
+// ParseSyncDirection accepts a direction and its long forms, such as
+// "pull-only" or "bidirectional".
+func ParseSyncDirection(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "both", "bidirectional", "":
+		return SyncBoth, nil
+	case "pull", "pull-only", "pull_only":
+		return SyncPullOnly, nil
+	case "push", "push-only", "push_only":
+		return SyncPushOnly, nil
+	}
+	return "", fmt.Errorf("invalid sync direction %q (expected both, pull, or push)", value)
+}
+
+// SyncDirectionLabel describes a direction for display.
+func SyncDirectionLabel(direction string) string {
+	switch direction {
+	case SyncPullOnly:
+		return "Pull only"
+	case SyncPushOnly:
+		return "Push only"
+	}
+	return "Both ways"
+}
+
+// AccountSyncRules returns the editable account fields with their sync
+// direction, in the order the detail editor shows them.
+func (a *App) AccountSyncRules() ([]database.FieldSyncRule, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	stored, err := database.GetFieldSyncRules(a.DB, "Account")
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]database.FieldSyncRule, len(stored))
+	for _, rule := range stored {
+		byName[rule.FieldName] = rule
+	}
+	var rules []database.FieldSyncRule
+	for _, column := range sortedAccountColumns() {
+		if rule, ok := byName[column]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// SetAccountFieldSyncDirection sets the sync direction of an editable
+// account field.
+func (a *App) SetAccountFieldSyncDirection(column, direction string) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	if _, ok := accountEditableFields[column]; !ok {
+		return fmt.Errorf("column %s is not synced", column)
+	}
+	direction, err := ParseSyncDirection(direction)
+	if err != nil {
+		return err
+	}
+	return database.SetFieldSyncDirection(a.DB, "Account", column, direction)
+}
+
+// accountColumnsWithDirection returns the account columns set to direction.
+func (a *App) accountColumnsWithDirection(direction string) ([]string, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, nil
+	}
+	rules, err := database.GetFieldSyncRules(a.DB, "Account")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read field sync directions: %w", err)
+	}
+	var columns []string
+	for _, rule := range rules {
+		if rule.Direction == direction {
+			columns = append(columns, rule.FieldName)
+		}
+	}
+	return columns, nil
+}
+
+// KeepPushOnlyAccountFields copies the stored value of every push-only
+// field into acc before a pull merges it, so pulls never overwrite those
+// fields locally. Accounts that are not stored yet take the pulled values.
+func (a *App) KeepPushOnlyAccountFields(acc *models.Account) error {
+	columns, err := a.accountColumnsWithDirection(SyncPushOnly)
+	if err != nil || len(columns) == 0 {
+		return err
+	}
+	stored, err := database.GetAccountByID(a.DB, int(acc.AccountId.Int64))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stored account %d: %w", acc.AccountId.Int64, err)
+	}
+	if err := a.DecryptAccountColumns(stored); err != nil {
+		return err
+	}
+	pulled := reflect.ValueOf(acc).Elem()
+	local := reflect.ValueOf(stored).Elem()
+	for _, column := range columns {
+		field := pulled.FieldByName(column)
+		if field.IsValid() && field.CanSet() {
+			field.Set(local.FieldByName(column))
+		}
+	}
+	return nil
+}
+
+// DropPullOnlyAccountFields removes pull-only fields from the API fields of
+// a pending change and returns the removed fields, sorted.
+func (a *App) DropPullOnlyAccountFields(data map[string]string) ([]string, error) {
+	columns, err := a.accountColumnsWithDirection(SyncPullOnly)
+	if err != nil {
+		return nil, err
+	}
+	var dropped []string
+	for _, column := range columns {
+		field, ok := accountEditableFields[column]
+		if !ok {
+			continue
+		}
+		if _, staged := data[field]; staged {
+			delete(data, field)
+			dropped = append(dropped, field)
+		}
+	}
+	sort.Strings(dropped)
+	return dropped, nil
+}
+
+// accountSyncDirection returns the sync direction of an account column.
+func (a *App) accountSyncDirection(column string) (string, error) {
+	rules, err := database.GetFieldSyncRules(a.DB, "Account")
+	if err != nil {
+		return "", fmt.Errorf("failed to read field sync directions: %w", err)
+	}
+	for _, rule := range rules {
+		if rule.FieldName == column {
+			return rule.Direction, nil
+		}
+	}
+	return SyncBoth, nil
+}
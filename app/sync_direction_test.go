@@ -0,0 +1,88 @@
+package app
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"badgermaps/api/models"
+	"badgermaps/app/state"
+	"badgermaps/database"
+
+	"github.com/guregu/null/v6"
+)
+
+func TestFieldSyncDirections(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "direction.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, FullName, Notes, PhoneNumber) VALUES (7, 'Acme', 'gate code 1234', '555-0100')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetAccountFieldSyncDirection("Notes", "push-only"); err != nil {
+		t.Fatalf("SetAccountFieldSyncDirection(Notes): %v", err)
+	}
+	if err := a.SetAccountFieldSyncDirection("PhoneNumber", "pull-only"); err != nil {
+		t.Fatalf("SetAccountFieldSyncDirection(PhoneNumber): %v", err)
+	}
+	if err := a.SetAccountFieldSyncDirection("FullName", "both"); err == nil {
+		t.Error("expected computed columns to be refused")
+	}
+	if err := a.SetAccountFieldSyncDirection("Notes", "sideways"); err == nil {
+		t.Error("expected an invalid direction to be refused")
+	}
+
+	rules, err := a.AccountSyncRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, rule := range rules {
+		got[rule.FieldName] = rule.Direction
+	}
+	if got["Notes"] != SyncPushOnly || got["PhoneNumber"] != SyncPullOnly || got["Email"] != SyncBoth {
+		t.Errorf("directions = Notes:%s PhoneNumber:%s Email:%s", got["Notes"], got["PhoneNumber"], got["Email"])
+	}
+
+	// Pulls keep the local value of push-only fields.
+	pulled := &models.Account{
+		AccountId:   null.IntFrom(7),
+		Notes:       null.StringFrom("from the API"),
+		PhoneNumber: null.StringFrom("555-0200"),
+	}
+	if err := a.KeepPushOnlyAccountFields(pulled); err != nil {
+		t.Fatal(err)
+	}
+	if pulled.Notes.String != "gate code 1234" || pulled.PhoneNumber.String != "555-0200" {
+		t.Errorf("merged account Notes=%q PhoneNumber=%q", pulled.Notes.String, pulled.PhoneNumber.String)
+	}
+	fresh := &models.Account{AccountId: null.IntFrom(8), Notes: null.StringFrom("new")}
+	if err := a.KeepPushOnlyAccountFields(fresh); err != nil || fresh.Notes.String != "new" {
+		t.Errorf("new account Notes=%q, err %v", fresh.Notes.String, err)
+	}
+
+	// Pull-only fields are neither staged nor pushed.
+	if err := a.StageAccountFieldEdit(7, "PhoneNumber", "555-0100", "555-0300"); err == nil {
+		t.Error("expected a pull-only field edit to be refused")
+	}
+	data := map[string]string{"phone_number": "555-0300", "notes": "hi"}
+	dropped, err := a.DropPullOnlyAccountFields(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dropped, []string{"phone_number"}) || !reflect.DeepEqual(data, map[string]string{"notes": "hi"}) {
+		t.Errorf("dropped %v, left %v", dropped, data)
+	}
+}
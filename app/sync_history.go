@@ -1,6 +1,8 @@
 package app
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -12,6 +14,44 @@ import (
 	"badgermaps/events"
 )
 
+// MaxSyncRunNoteLength is the longest note AnnotateSyncRun accepts, in
+// characters.
+const MaxSyncRunNoteLength = 500
+
+// SyncRuns returns the latest limit runs in SyncHistory, newest first.
+func (a *App) SyncRuns(limit int) ([]database.SyncHistoryEntry, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	return database.GetRecentSyncHistory(a.DB, limit)
+}
+
+// AnnotateSyncRun sets the operator's note on a run in SyncHistory, such as
+// "re-ran after fixing the API key", so it shows wherever the run is listed.
+// An empty note clears it.
+func (a *App) AnnotateSyncRun(historyID int64, note string) error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	note = strings.TrimSpace(note)
+	if n := len([]rune(note)); n > MaxSyncRunNoteLength {
+		return fmt.Errorf("note is %d characters; the limit is %d", n, MaxSyncRunNoteLength)
+	}
+	if err := database.SetSyncHistoryNote(a.DB, historyID, note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no sync run with ID %d", historyID)
+		}
+		return fmt.Errorf("failed to save the note: %w", err)
+	}
+	if note == "" {
+		a.Events.Dispatch(events.Infof("sync_history", "Cleared the note on sync run %d", historyID))
+	} else {
+		a.Events.Dispatch(events.Infof("sync_history", "Noted sync run %d: %s", historyID, note))
+	}
+	a.Events.Dispatch(events.Event{Type: "sync.history.updated", Source: "note"})
+	return nil
+}
+
 type syncHistoryRun struct {
 	correlationID string
 	startedAt     time.Time
@@ -115,7 +155,11 @@ func (a *App) recordSyncHistoryEvent(e events.Event) {
 			a.completeSyncHistoryRun(key, status, count, errorCount, summary, details)
 		}
 	case "push.scan.start":
-		a.startSyncHistoryRun(syncHistoryKey("push", source), "push", "push", source, fmt.Sprintf("Scanning %s pending changes", friendlyResourceLabel(source)))
+		runType := "push"
+		if a.PushToSandbox() {
+			runType = SandboxRunType
+		}
+		a.startSyncHistoryRun(syncHistoryKey("push", source), runType, "push", source, fmt.Sprintf("Scanning %s pending changes", friendlyResourceLabel(source)))
 	case "push.scan.complete":
 		if payload, ok := e.Payload.(events.PushScanCompletePayload); ok {
 			total := countChanges(payload.Changes)
@@ -181,6 +225,7 @@ func (a *App) startSyncHistoryRun(key, runType, direction, source, summary strin
 		source:        source,
 	}
 
+	summary = sandboxSummary(runType, summary)
 	entry := &database.SyncHistoryEntry{
 		CorrelationID:  run.correlationID,
 		RunType:        runType,
@@ -217,7 +262,7 @@ func (a *App) updateSyncHistoryMetrics(key string, items int, summary string) {
 		if runSummary == "" {
 			runSummary = fmt.Sprintf("Processed %d items", items)
 		}
-		summary = runSummary
+		summary = sandboxSummary(run.runType, runSummary)
 		correlationID = run.correlationID
 	}
 	a.syncHistoryMu.Unlock()
@@ -241,6 +286,7 @@ func (a *App) completeSyncHistoryRun(key, status string, itemsProcessed, errorCo
 		startedAt     time.Time
 		expected      int
 		savedErrors   int
+		runType       string
 	)
 
 	a.syncHistoryMu.Lock()
@@ -250,6 +296,7 @@ func (a *App) completeSyncHistoryRun(key, status string, itemsProcessed, errorCo
 		startedAt = run.startedAt
 		expected = run.expectedItems
 		savedErrors = run.errorCount
+		runType = run.runType
 		delete(a.syncHistoryRuns, key)
 	}
 	a.syncHistoryMu.Unlock()
@@ -283,6 +330,7 @@ func (a *App) completeSyncHistoryRun(key, status string, itemsProcessed, errorCo
 	if summary == "" {
 		summary = fmt.Sprintf("Sync %s", status)
 	}
+	summary = sandboxSummary(runType, summary)
 
 	if err := database.CompleteSyncHistory(a.DB, correlationID, status, itemsProcessed, errorCount, durationSeconds, summary, details); err != nil {
 		if a.shouldSuppressSyncHistoryFinalizeError(err) {
@@ -312,6 +360,15 @@ func (a *App) syncHistoryExpected(key string) int {
 	return 0
 }
 
+// sandboxSummary marks summaries of sandbox pushes so they stand apart
+// from production runs in SyncHistory.
+func sandboxSummary(runType, summary string) string {
+	if runType != SandboxRunType || summary == "" {
+		return summary
+	}
+	return "[Sandbox] " + summary
+}
+
 func syncHistoryKey(direction, source string) string {
 	return fmt.Sprintf("%s:%s", direction, source)
 }
@@ -2,9 +2,13 @@ package app
 
 import (
 	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"badgermaps/app/state"
+	"badgermaps/database"
 	"badgermaps/events"
 )
 
@@ -60,3 +64,79 @@ func TestAppCloseWaitsForEventDrain(t *testing.T) {
 		t.Fatal("app close timed out waiting for event drain")
 	}
 }
+
+func TestSandboxPushRunsAreTagged(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "history.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	a.syncHistoryRuns = make(map[string]*syncHistoryRun)
+	a.State.PushToSandbox = true
+
+	a.recordSyncHistoryEvent(events.Event{Type: "push.scan.start", Source: "accounts"})
+	a.recordSyncHistoryEvent(events.Event{Type: "push.complete", Source: "accounts", Payload: events.PushCompletePayload{}})
+
+	entries, err := database.GetRecentSyncHistory(db, 1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("GetRecentSyncHistory = %v, %v", entries, err)
+	}
+	if entries[0].RunType != SandboxRunType || entries[0].Direction != "push" {
+		t.Fatalf("run type/direction = %s/%s", entries[0].RunType, entries[0].Direction)
+	}
+	if !strings.HasPrefix(entries[0].Summary, "[Sandbox] ") {
+		t.Fatalf("summary = %q", entries[0].Summary)
+	}
+}
+
+func TestAnnotateSyncRun(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "history.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	id, err := database.InsertSyncHistory(db, &database.SyncHistoryEntry{CorrelationID: "run-1", RunType: "pull", Status: "completed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.AnnotateSyncRun(id, "  re-ran after fixing the API key "); err != nil {
+		t.Fatalf("AnnotateSyncRun: %v", err)
+	}
+	runs, err := a.SyncRuns(5)
+	if err != nil || len(runs) != 1 || runs[0].Note != "re-ran after fixing the API key" {
+		t.Fatalf("SyncRuns = %+v, %v; want the trimmed note", runs, err)
+	}
+
+	if err := a.AnnotateSyncRun(id+1, "missing"); err == nil || !strings.Contains(err.Error(), "no sync run") {
+		t.Errorf("annotating a missing run = %v, want a no sync run error", err)
+	}
+	if err := a.AnnotateSyncRun(id, strings.Repeat("x", MaxSyncRunNoteLength+1)); err == nil {
+		t.Error("expected a note over the limit to be refused")
+	}
+
+	if err := a.AnnotateSyncRun(id, ""); err != nil {
+		t.Fatalf("clearing the note: %v", err)
+	}
+	if runs, _ := a.SyncRuns(5); len(runs) != 1 || runs[0].Note != "" {
+		t.Fatalf("SyncRuns after clearing = %+v", runs)
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SyncLockName is the lock every pull and push run holds, so instances
+// sharing a database never interleave their runs.
+const SyncLockName = "sync"
+
+const (
+	// SyncLockStaleAfter is how long a lock may go without a heartbeat
+	// before another instance takes it over. The hosts' clocks must agree
+	// to well within it.
+	SyncLockStaleAfter = 2 * time.Minute
+	syncLockHeartbeat  = 30 * time.Second
+)
+
+// SyncLockHeldError reports that another instance holds the sync lock.
+type SyncLockHeldError struct {
+	Lock database.SyncLock
+}
+
+func (e *SyncLockHeldError) Error() string {
+	operation := e.Lock.Operation
+	if operation == "" {
+		operation = "a sync"
+	}
+	return fmt.Sprintf("%s is running elsewhere: the sync lock is held by host %s (pid %d) since %s", operation, e.Lock.Host, e.Lock.Pid, e.Lock.AcquiredAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+// Hint tells the user how to get past the lock.
+func (e *SyncLockHeldError) Hint() string {
+	return fmt.Sprintf("Wait for the run on %s to finish and try again. If that instance is gone, the lock frees itself %s after its last heartbeat, or run 'badgermaps db unlock' to release it now.", e.Lock.Host, SyncLockStaleAfter)
+}
+
+// syncLock is the state of the sync lock held by this instance. Runs of one
+// instance share the lock, so only runs of different instances exclude
+// each other.
+type syncLock struct {
+	mu     sync.Mutex
+	holder string
+	depth  int
+	stop   chan struct{}
+}
+
+func (a *App) syncLockHolder() string {
+	if a.syncLock.holder == "" {
+		a.syncLock.holder = uuid.NewString()
+	}
+	return a.syncLock.holder
+}
+
+// AcquireSyncLock takes the sync lock in the database for a pull or push
+// run described by operation, such as "pull accounts". It returns a
+// SyncLockHeldError when another instance holds the lock, and takes over a
+// lock whose holder stopped sending heartbeats. Call release when the run
+// ends. Without a connected database there is nothing to lock.
+func (a *App) AcquireSyncLock(operation string) (release func(), err error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return func() {}, nil
+	}
+	a.syncLock.mu.Lock()
+	defer a.syncLock.mu.Unlock()
+	if a.syncLock.depth > 0 {
+		a.syncLock.depth++
+		return a.releaseSyncLock, nil
+	}
+
+	holder := a.syncLockHolder()
+	now := time.Now()
+	held, err := database.GetSyncLock(a.DB, SyncLockName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the sync lock: %w", err)
+	}
+	if held != nil && held.HolderId != holder {
+		if now.Sub(held.HeartbeatAt) < SyncLockStaleAfter {
+			return nil, &SyncLockHeldError{Lock: *held}
+		}
+		a.Events.Dispatch(events.Warningf("sync", "Taking over the sync lock held by host %s (pid %d) since %s; its last heartbeat was %s ago",
+			held.Host, held.Pid, held.AcquiredAt.Local().Format("2006-01-02 15:04:05"), now.Sub(held.HeartbeatAt).Round(time.Second)))
+	}
+	if held != nil {
+		if err := database.ReleaseSyncLock(a.DB, SyncLockName, held.HolderId); err != nil {
+			return nil, fmt.Errorf("failed to release the stale sync lock: %w", err)
+		}
+	}
+
+	host, _ := os.Hostname()
+	lock := database.SyncLock{Name: SyncLockName, HolderId: holder, Host: host, Pid: os.Getpid(), Operation: operation, AcquiredAt: now, HeartbeatAt: now}
+	if err := database.InsertSyncLock(a.DB, lock); err != nil {
+		// Another instance took the lock between the read and the insert.
+		if held, readErr := database.GetSyncLock(a.DB, SyncLockName); readErr == nil && held != nil && held.HolderId != holder {
+			return nil, &SyncLockHeldError{Lock: *held}
+		}
+		return nil, fmt.Errorf("failed to take the sync lock: %w", err)
+	}
+	a.syncLock.depth = 1
+	a.syncLock.stop = make(chan struct{})
+	go a.syncLockHeartbeats(a.syncLock.stop, holder)
+	return a.releaseSyncLock, nil
+}
+
+func (a *App) releaseSyncLock() {
+	a.syncLock.mu.Lock()
+	defer a.syncLock.mu.Unlock()
+	if a.syncLock.depth == 0 {
+		return
+	}
+	a.syncLock.depth--
+	if a.syncLock.depth > 0 {
+		return
+	}
+	close(a.syncLock.stop)
+	if a.DB == nil || !a.DB.IsConnected() {
+		return
+	}
+	if err := database.ReleaseSyncLock(a.DB, SyncLockName, a.syncLock.holder); err != nil {
+		a.Events.Dispatch(events.Warningf("sync", "Could not release the sync lock; it frees itself after %s: %v", SyncLockStaleAfter, err))
+	}
+}
+
+// syncLockHeartbeats keeps the lock alive until stop is closed or the lock
+// is released from elsewhere.
+func (a *App) syncLockHeartbeats(stop <-chan struct{}, holder string) {
+	ticker := time.NewTicker(syncLockHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if a.DB == nil || !a.DB.IsConnected() {
+			continue
+		}
+		held, err := database.HeartbeatSyncLock(a.DB, SyncLockName, holder, time.Now())
+		if err != nil {
+			a.Events.Dispatch(events.Warningf("sync", "Could not renew the sync lock: %v", err))
+			continue
+		}
+		if !held {
+			a.Events.Dispatch(events.Warningf("sync", "The sync lock was released by another instance while a run was in progress"))
+			return
+		}
+	}
+}
+
+// SyncLock returns the holder of the sync lock, or nil when it is free.
+func (a *App) SyncLock() (*database.SyncLock, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	return database.GetSyncLock(a.DB, SyncLockName)
+}
+
+// ForceReleaseSyncLock frees the sync lock whoever holds it, for when its
+// holder is gone. The holder's run, if still going, is not stopped.
+func (a *App) ForceReleaseSyncLock() (bool, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return false, fmt.Errorf("database is not connected")
+	}
+	released, err := database.ForceReleaseSyncLock(a.DB, SyncLockName)
+	if err != nil {
+		return false, err
+	}
+	if released {
+		a.Events.Dispatch(events.Warningf("sync", "Sync lock force-released"))
+	}
+	return released, nil
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestSyncLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock.db")
+	open := func() *App {
+		t.Helper()
+		db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := db.EnforceSchema(&state.State{}); err != nil {
+			t.Fatal(err)
+		}
+		db.SetConnected(true)
+		a := NewApp()
+		a.DB = db
+		return a
+	}
+	first, second := open(), open()
+
+	release, err := first.AcquireSyncLock("pull accounts")
+	if err != nil {
+		t.Fatalf("AcquireSyncLock: %v", err)
+	}
+	// Runs of one instance share the lock.
+	releaseNested, err := first.AcquireSyncLock("push accounts")
+	if err != nil {
+		t.Fatalf("nested AcquireSyncLock: %v", err)
+	}
+
+	_, err = second.AcquireSyncLock("pull checkins")
+	var held *SyncLockHeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("second AcquireSyncLock = %v, want SyncLockHeldError", err)
+	}
+	if held.Lock.Operation != "pull accounts" || held.Lock.Host == "" || held.Hint() == "" {
+		t.Errorf("held lock = %+v", held.Lock)
+	}
+
+	releaseNested()
+	if lock, _ := second.SyncLock(); lock == nil {
+		t.Fatal("lock was released while a run still held it")
+	}
+	release()
+	if lock, _ := second.SyncLock(); lock != nil {
+		t.Fatalf("lock = %+v after the last release, want free", lock)
+	}
+
+	// A lock without recent heartbeats is taken over.
+	if _, err := first.AcquireSyncLock("pull routes"); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * SyncLockStaleAfter).UTC()
+	if _, err := first.DB.GetDB().Exec(`UPDATE SyncLocks SET HeartbeatAt = ?`, stale); err != nil {
+		t.Fatal(err)
+	}
+	releaseSecond, err := second.AcquireSyncLock("pull checkins")
+	if err != nil {
+		t.Fatalf("taking over a stale lock: %v", err)
+	}
+	if lock, _ := first.SyncLock(); lock == nil || lock.Operation != "pull checkins" {
+		t.Fatalf("lock = %+v, want the second instance's", lock)
+	}
+
+	released, err := first.ForceReleaseSyncLock()
+	if err != nil || !released {
+		t.Fatalf("ForceReleaseSyncLock = %v, %v", released, err)
+	}
+	releaseSecond()
+	if released, _ := first.ForceReleaseSyncLock(); released {
+		t.Error("a free lock was reported released")
+	}
+}
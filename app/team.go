@@ -0,0 +1,113 @@
+package app
+
+import (
+	"badgermaps/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TeamActivityDays is how far back the team dashboard counts check-ins.
+const TeamActivityDays = 30
+
+// IsManager reports whether the pulled user profile belongs to a manager.
+// It is false until a profile has been pulled, and it is never read from
+// the config, so the role always matches what BadgerMaps reports.
+func (a *App) IsManager() bool {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return false
+	}
+	role, err := database.GetProfileRole(a.DB)
+	return err == nil && role.IsManager
+}
+
+// TeamActivity returns the accounts, stale accounts, and recent check-ins of
+// every rep on the team.
+func (a *App) TeamActivity() ([]database.RepActivity, error) {
+	if err := a.requireManager(); err != nil {
+		return nil, err
+	}
+	since := time.Now().AddDate(0, 0, -TeamActivityDays).Format("2006-01-02")
+	return database.GetTeamActivity(a.DB, a.StaleAccountDays(), since)
+}
+
+// RepPendingChanges are the unpushed changes to one rep's accounts and
+// check-ins.
+type RepPendingChanges struct {
+	Rep      string
+	Accounts []database.AccountPendingChange
+	Checkins []database.CheckinPendingChange
+}
+
+// TeamPendingChanges groups the pending account and check-in changes by
+// rep: account changes by the account's owner and check-in changes by
+// their author. Reps are ordered by name, with unassigned changes last.
+func (a *App) TeamPendingChanges() ([]RepPendingChanges, error) {
+	if err := a.requireManager(); err != nil {
+		return nil, err
+	}
+	accountChanges, err := database.GetPendingAccountChanges(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending account changes: %w", err)
+	}
+	checkinChanges, err := database.GetPendingCheckinChanges(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending check-in changes: %w", err)
+	}
+	owners, err := database.GetAccountOwners(a.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	byRep := make(map[string]*RepPendingChanges)
+	group := func(rep string) *RepPendingChanges {
+		if byRep[rep] == nil {
+			byRep[rep] = &RepPendingChanges{Rep: rep}
+		}
+		return byRep[rep]
+	}
+	for _, change := range accountChanges {
+		g := group(owners[change.AccountId])
+		g.Accounts = append(g.Accounts, change)
+	}
+	for _, change := range checkinChanges {
+		rep := change.CreatedBy.String
+		if rep == "" {
+			rep = owners[change.AccountId]
+		}
+		g := group(rep)
+		g.Checkins = append(g.Checkins, change)
+	}
+
+	groups := make([]RepPendingChanges, 0, len(byRep))
+	for _, g := range byRep {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].Rep == "") != (groups[j].Rep == "") {
+			return groups[j].Rep == ""
+		}
+		return groups[i].Rep < groups[j].Rep
+	})
+	return groups, nil
+}
+
+// requireManager refuses team features to profiles that are not managers.
+func (a *App) requireManager() error {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	role, err := database.GetProfileRole(a.DB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("pull the user profile first")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the user profile: %w", err)
+	}
+	if !role.IsManager {
+		return fmt.Errorf("team features need a manager profile")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestTeamFeaturesFollowPulledProfile(t *testing.T) {
+	a := NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "team.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.GetDB().Exec(query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+
+	if a.IsManager() {
+		t.Error("expected no manager features before a profile is pulled")
+	}
+	if _, err := a.TeamActivity(); err == nil {
+		t.Error("expected team activity to need a profile")
+	}
+	exec(`INSERT INTO UserProfiles (ProfileId, Email, IsManager) VALUES (1, 'rep@example.com', 0)`)
+	if a.IsManager() {
+		t.Error("expected a rep profile to hide manager features")
+	}
+	exec(`UPDATE UserProfiles SET IsManager = 1`)
+	if !a.IsManager() {
+		t.Fatal("expected a manager profile to unlock manager features")
+	}
+
+	recent := time.Now().AddDate(0, 0, -2).Format("2006-01-02T15:04:05")
+	old := time.Now().AddDate(0, 0, -90).Format("2006-01-02T15:04:05")
+	exec(`INSERT INTO Accounts (AccountId, FullName, AccountOwner, DaysSinceLastCheckin) VALUES
+		(1, 'A', 'ann@example.com', 2), (2, 'B', 'ann@example.com', 400), (3, 'C', 'bob@example.com', 1), (4, 'D', NULL, 0)`)
+	exec(`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, CreatedBy) VALUES
+		(10, 1, ?, 'ann@example.com'), (11, 2, ?, 'ann@example.com'), (12, 3, ?, 'bob@example.com')`, recent, old, recent)
+	exec(`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (1, 'UPDATE', '{}'), (4, 'UPDATE', '{}')`)
+	exec(`INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, ChangeType, CreatedBy) VALUES (13, 3, 'CREATE', 'bob@example.com')`)
+
+	team, err := a.TeamActivity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(team) != 3 {
+		t.Fatalf("expected ann, bob, and unassigned, got %+v", team)
+	}
+	ann := team[1]
+	if ann.Rep != "ann@example.com" || ann.Accounts != 2 || ann.StaleAccounts != 1 || ann.RecentCheckins != 1 || ann.LastCheckin != recent {
+		t.Errorf("ann = %+v", ann)
+	}
+	if team[0].Rep != "" || team[0].Accounts != 1 {
+		t.Errorf("unassigned = %+v", team[0])
+	}
+
+	pending, err := a.TeamPendingChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 || pending[0].Rep != "ann@example.com" || len(pending[0].Accounts) != 1 ||
+		pending[1].Rep != "bob@example.com" || len(pending[1].Checkins) != 1 || pending[2].Rep != "" {
+		t.Errorf("pending changes grouped as %+v", pending)
+	}
+}
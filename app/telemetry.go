@@ -0,0 +1,27 @@
+package app
+
+import (
+	"badgermaps/events"
+	"badgermaps/telemetry"
+)
+
+// StartTelemetry exports OpenTelemetry traces of pulls, pushes, webhooks,
+// and actions when the telemetry section or the OTEL_EXPORTER_OTLP
+// environment variables name a collector. It runs once; Close flushes the
+// spans still buffered.
+func (a *App) StartTelemetry() {
+	if a.stopTelemetry != nil || a.Config == nil {
+		return
+	}
+	stop, err := telemetry.Setup(a.Config.Telemetry, Version, func(err error) {
+		a.Events.Dispatch(events.Debugf("telemetry", "Trace export failed: %v", err))
+	})
+	a.stopTelemetry = stop
+	if err != nil {
+		a.Events.Dispatch(events.Warningf("telemetry", "Traces are not exported: %v", err))
+		return
+	}
+	if a.Config.Telemetry.Enabled() {
+		a.Events.Dispatch(events.Debugf("telemetry", "Exporting traces over OTLP"))
+	}
+}
@@ -0,0 +1,315 @@
+package app
+
+import (
+	"badgermaps/database"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TerritoryRule assigns an owner to the accounts in a territory. An account
+// is in the territory when it matches every criterion the rule sets: one of
+// its zip codes or ranges, one of its states, and its radius.
+type TerritoryRule struct {
+	Name   string   `yaml:"name"`
+	Owner  string   `yaml:"owner"`
+	Zips   []string `yaml:"zips,omitempty"`
+	States []string `yaml:"states,omitempty"`
+	Within string   `yaml:"within,omitempty"`
+}
+
+// Label names the rule in reports, falling back to its owner.
+func (r TerritoryRule) Label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Owner
+}
+
+// zipRange is an inclusive range of zip codes of the same length. A single
+// code is a range of one.
+type zipRange struct {
+	from, to string
+}
+
+func parseZipRange(value string) (zipRange, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	from, to, isRange := strings.Cut(value, "-")
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !isRange {
+		to = from
+	}
+	if from == "" || to == "" {
+		return zipRange{}, fmt.Errorf("invalid zip %q", value)
+	}
+	if isRange {
+		_, errFrom := strconv.Atoi(from)
+		_, errTo := strconv.Atoi(to)
+		if errFrom != nil || errTo != nil || len(from) != len(to) || from > to {
+			return zipRange{}, fmt.Errorf("invalid zip range %q (expected e.g. 75000-75999)", value)
+		}
+	}
+	return zipRange{from: from, to: to}, nil
+}
+
+// contains compares zip's leading digits with the range, so ZIP+4 codes
+// fall in their five-digit range.
+func (r zipRange) contains(zip string) bool {
+	zip = strings.ToUpper(strings.TrimSpace(zip))
+	if len(zip) > len(r.from) && zip[len(r.from)] == '-' {
+		zip = zip[:len(r.from)]
+	}
+	return len(zip) == len(r.from) && zip >= r.from && zip <= r.to
+}
+
+// territory is a rule parsed for evaluation.
+type territory struct {
+	rule   TerritoryRule
+	zips   []zipRange
+	states map[string]bool
+	radius *RadiusFilter
+}
+
+func parseTerritory(rule TerritoryRule) (territory, error) {
+	t := territory{rule: rule}
+	if strings.TrimSpace(rule.Owner) == "" {
+		return t, fmt.Errorf("territory %q has no owner", rule.Label())
+	}
+	for _, zip := range rule.Zips {
+		r, err := parseZipRange(zip)
+		if err != nil {
+			return t, fmt.Errorf("territory %q: %w", rule.Label(), err)
+		}
+		t.zips = append(t.zips, r)
+	}
+	for _, state := range rule.States {
+		state = strings.ToUpper(strings.TrimSpace(state))
+		if state == "" {
+			return t, fmt.Errorf("territory %q has an empty state", rule.Label())
+		}
+		if t.states == nil {
+			t.states = make(map[string]bool)
+		}
+		t.states[state] = true
+	}
+	if strings.TrimSpace(rule.Within) != "" {
+		radius, err := ParseRadiusFilter(rule.Within)
+		if err != nil {
+			return t, fmt.Errorf("territory %q: %w", rule.Label(), err)
+		}
+		t.radius = &radius
+	}
+	if len(t.zips) == 0 && len(t.states) == 0 && t.radius == nil {
+		return t, fmt.Errorf("territory %q needs zips, states, or within", rule.Label())
+	}
+	return t, nil
+}
+
+func (t territory) matches(acc database.TerritoryAccount) bool {
+	if len(t.zips) > 0 {
+		in := false
+		for _, r := range t.zips {
+			if r.contains(acc.Zipcode) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	if len(t.states) > 0 && !t.states[strings.ToUpper(strings.TrimSpace(acc.State))] {
+		return false
+	}
+	if t.radius != nil && (acc.Point == nil || !t.radius.Contains(*acc.Point)) {
+		return false
+	}
+	return true
+}
+
+// ValidateTerritories checks that every territory rule has an owner and at
+// least one valid criterion, and that rule names are unique.
+func ValidateTerritories(rules []TerritoryRule) error {
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		if _, err := parseTerritory(rule); err != nil {
+			return err
+		}
+		if rule.Name == "" {
+			continue
+		}
+		name := strings.ToLower(rule.Name)
+		if names[name] {
+			return fmt.Errorf("territory %q is defined more than once", rule.Name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// TerritoryMismatch is an account whose owner differs from the one its
+// territory assigns. Staged is set when a pending change already assigns
+// the expected owner.
+type TerritoryMismatch struct {
+	AccountId int    `json:"account_id"`
+	Name      string `json:"name"`
+	Current   string `json:"current_owner"`
+	Expected  string `json:"expected_owner"`
+	Rule      string `json:"rule"`
+	Staged    bool   `json:"staged,omitempty"`
+}
+
+// TerritoryReport is the result of evaluating the territory rules against
+// the local accounts.
+type TerritoryReport struct {
+	Accounts   int                 `json:"accounts"`
+	Matched    int                 `json:"matched"`
+	Unmatched  int                 `json:"unmatched"`
+	NoLocation int                 `json:"no_location"`
+	ByRule     map[string]int      `json:"by_rule"`
+	Mismatches []TerritoryMismatch `json:"mismatches"`
+}
+
+// Pending returns the mismatches that have no owner change staged yet.
+func (r *TerritoryReport) Pending() []TerritoryMismatch {
+	var pending []TerritoryMismatch
+	for _, m := range r.Mismatches {
+		if !m.Staged {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// EvaluateTerritories assigns every local account to the first territory
+// rule it matches and reports the accounts whose owner differs. Accounts
+// matching no rule keep their owner.
+func (a *App) EvaluateTerritories() (*TerritoryReport, error) {
+	if a.DB == nil || !a.DB.IsConnected() {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	if len(a.Config.Territories) == 0 {
+		return nil, fmt.Errorf("no territories are configured; add them under territories in the config")
+	}
+	territories := make([]territory, 0, len(a.Config.Territories))
+	for _, rule := range a.Config.Territories {
+		t, err := parseTerritory(rule)
+		if err != nil {
+			return nil, err
+		}
+		if t.radius != nil {
+			resolved, err := a.ResolveRadiusFilter(*t.radius, nil)
+			if err != nil {
+				return nil, fmt.Errorf("territory %q: %w", rule.Label(), err)
+			}
+			t.radius = &resolved
+		}
+		territories = append(territories, t)
+	}
+
+	accounts, err := database.GetTerritoryAccounts(a.DB)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := a.stagedOwners()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TerritoryReport{Accounts: len(accounts), ByRule: make(map[string]int)}
+	for _, acc := range accounts {
+		if acc.Zipcode == "" && acc.State == "" && acc.Point == nil {
+			report.NoLocation++
+			continue
+		}
+		var match *territory
+		for i := range territories {
+			if territories[i].matches(acc) {
+				match = &territories[i]
+				break
+			}
+		}
+		if match == nil {
+			report.Unmatched++
+			continue
+		}
+		report.Matched++
+		report.ByRule[match.rule.Label()]++
+		expected := strings.TrimSpace(match.rule.Owner)
+		if strings.EqualFold(strings.TrimSpace(acc.Owner), expected) {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, TerritoryMismatch{
+			AccountId: acc.AccountId,
+			Name:      acc.Name,
+			Current:   acc.Owner,
+			Expected:  expected,
+			Rule:      match.rule.Label(),
+			Staged:    strings.EqualFold(staged[acc.AccountId], expected),
+		})
+	}
+	sort.SliceStable(report.Mismatches, func(i, j int) bool {
+		return report.Mismatches[i].AccountId < report.Mismatches[j].AccountId
+	})
+	return report, nil
+}
+
+// stagedOwners maps accounts to the owner their latest pending change
+// assigns.
+func (a *App) stagedOwners() (map[int]string, error) {
+	changes, err := database.GetPendingAccountChanges(a.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending account changes: %w", err)
+	}
+	owners := make(map[int]string)
+	for _, change := range changes {
+		var fields map[string]any
+		if change.ChangeType != "UPDATE" || json.Unmarshal([]byte(change.Changes), &fields) != nil {
+			continue
+		}
+		if owner, ok := fields[accountEditableFields["AccountOwner"]].(string); ok {
+			owners[change.AccountId] = owner
+		}
+	}
+	return owners, nil
+}
+
+// StageTerritoryOwners stages an owner UPDATE for every mismatched account
+// that has none staged, limited to one rule when rule is not empty. The
+// changes wait in the push queue for review like any other edit.
+func (a *App) StageTerritoryOwners(rule string) ([]StageRequest, error) {
+	report, err := a.EvaluateTerritories()
+	if err != nil {
+		return nil, err
+	}
+	if rule != "" {
+		found := false
+		for _, r := range a.Config.Territories {
+			if strings.EqualFold(r.Label(), rule) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no territory named %q", rule)
+		}
+	}
+	var requests []StageRequest
+	for _, m := range report.Pending() {
+		if rule != "" && !strings.EqualFold(m.Rule, rule) {
+			continue
+		}
+		requests = append(requests, StageRequest{
+			Entity:     StageEntityAccount,
+			ID:         m.AccountId,
+			ChangeType: "UPDATE",
+			Fields:     map[string]string{accountEditableFields["AccountOwner"]: m.Expected},
+		})
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return a.StageChanges(requests)
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func TestValidateTerritories(t *testing.T) {
+	for _, tc := range []struct {
+		rule TerritoryRule
+		ok   bool
+	}{
+		{TerritoryRule{Owner: "ann@example.com", Zips: []string{"75000-75999", "76001"}}, true},
+		{TerritoryRule{Owner: "ann@example.com", States: []string{"tx"}, Within: "50 km of 32.7,-96.8"}, true},
+		{TerritoryRule{Owner: "ann@example.com"}, false},
+		{TerritoryRule{Zips: []string{"75001"}}, false},
+		{TerritoryRule{Owner: "ann@example.com", Zips: []string{"75999-75000"}}, false},
+		{TerritoryRule{Owner: "ann@example.com", Zips: []string{"750-75999"}}, false},
+		{TerritoryRule{Owner: "ann@example.com", Within: "far away"}, false},
+	} {
+		if err := ValidateTerritories([]TerritoryRule{tc.rule}); (err == nil) != tc.ok {
+			t.Errorf("ValidateTerritories(%+v) = %v, want ok=%v", tc.rule, err, tc.ok)
+		}
+	}
+	dup := []TerritoryRule{{Name: "North", Owner: "a", States: []string{"TX"}}, {Name: "north", Owner: "b", States: []string{"OK"}}}
+	if err := ValidateTerritories(dup); err == nil {
+		t.Error("want an error for duplicate territory names")
+	}
+}
+
+func TestZipRangeContains(t *testing.T) {
+	r, err := parseZipRange("75000-75999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for zip, want := range map[string]bool{"75001": true, "75001-1234": true, "76001": false, "7500": false, "": false} {
+		if got := r.contains(zip); got != want {
+			t.Errorf("contains(%q) = %v, want %v", zip, got, want)
+		}
+	}
+}
+
+func TestTerritoryAssignment(t *testing.T) {
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "territory.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetConnected(true)
+	a := NewApp()
+	a.DB = db
+	for _, stmt := range []string{
+		`INSERT INTO Accounts (AccountId, FullName, AccountOwner) VALUES
+			(1, 'Dallas Co', 'ann@example.com'), (2, 'Plano Co', 'bob@example.com'),
+			(3, 'Austin Co', 'ann@example.com'), (4, 'Tulsa Co', 'bob@example.com'), (5, 'Nowhere Co', NULL)`,
+		`INSERT INTO AccountLocations (AccountId, Zipcode, State, Latitude, Longitude) VALUES
+			(1, '75201', 'TX', 32.78, -96.80), (2, '75024-1234', 'TX', 33.07, -96.80),
+			(3, '78701', 'tx', 30.27, -97.74), (4, '74103', 'OK', 36.15, -95.99)`,
+	} {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	a.Config.Territories = []TerritoryRule{
+		{Name: "DFW", Owner: "ann@example.com", Zips: []string{"75000-75999"}},
+		{Name: "Texas", Owner: "cat@example.com", States: []string{"TX"}},
+	}
+
+	report, err := a.EvaluateTerritories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Matched != 3 || report.Unmatched != 1 || report.NoLocation != 1 || report.ByRule["DFW"] != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Mismatches) != 2 || report.Mismatches[0].AccountId != 2 || report.Mismatches[1].Expected != "cat@example.com" {
+		t.Fatalf("unexpected mismatches: %+v", report.Mismatches)
+	}
+
+	staged, err := a.StageTerritoryOwners("dfw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(staged) != 1 || staged[0].ID != 2 || staged[0].Fields["account_owner"] != "ann@example.com" {
+		t.Fatalf("unexpected staged changes: %+v", staged)
+	}
+	report, err = a.EvaluateTerritories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Mismatches[0].Staged || len(report.Pending()) != 1 {
+		t.Fatalf("staged change not recognised: %+v", report.Mismatches)
+	}
+	if staged, err := a.StageTerritoryOwners(""); err != nil || len(staged) != 1 || staged[0].ID != 3 {
+		t.Fatalf("second staging = %+v, %v", staged, err)
+	}
+	if _, err := a.StageTerritoryOwners("Oklahoma"); err == nil {
+		t.Fatal("want an error for an unknown territory")
+	}
+}
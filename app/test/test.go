@@ -74,6 +74,12 @@ func TestApi(App *app.App, save bool) error {
 	start := time.Now()
 	if !api.IsConnected() {
 		App.Events.Dispatch(events.Errorf("test", "FAILED: Could not connect to API"))
+		if err := api.TestAPIConnection(); err != nil {
+			App.Events.Dispatch(events.Errorf("test", "Error: %v", err))
+			if hint := utils.TLSErrorHint(err); hint != "" {
+				App.Events.Dispatch(events.Warningf("test", "Hint: %s", hint))
+			}
+		}
 		return fmt.Errorf("could not connect to API")
 	}
 	duration := time.Since(start)
@@ -304,7 +310,7 @@ func testCreateAccount(App *app.App) EndpointTestResult {
 		"address":       "123 Test St, Test City, TS 12345",
 		"email":         "test@example.com",
 		"phone_number":  "",
-		"account_owner": strconv.Itoa(App.API.UserID),
+		"account_owner": strconv.Itoa(App.API.UserID()),
 	}
 	result, err := App.API.CreateAccount(models.AccountUpload{Fields: data})
 	duration := time.Since(start)
@@ -605,6 +611,9 @@ func TestDatabase(App *app.App) error {
 	if err := db.TestConnection(); err != nil {
 		App.Events.Dispatch(events.Errorf("test", "FAILED: Could not connect to database"))
 		App.Events.Dispatch(events.Errorf("test", "Error: %v", err))
+		if hint := utils.TLSErrorHint(err); hint != "" {
+			App.Events.Dispatch(events.Warningf("test", "Hint: %s", hint))
+		}
 		return fmt.Errorf("could not connect to database: %w", err)
 	}
 	App.Events.Dispatch(events.Infof("test", "PASSED: Database connection successful"))
@@ -618,6 +627,9 @@ func TestDatabase(App *app.App) error {
 
 		reader := bufio.NewReader(os.Stdin)
 		if utils.PromptBool(reader, "Would you like to drop all tables and re-initialize the schema?", false) {
+			if err := App.ConfirmProduction("resetting the schema"); err != nil {
+				return err
+			}
 			App.Events.Dispatch(events.Warningf("test", "Resetting schema and deleting all existing data..."))
 			if err := db.ResetSchema(App.State); err != nil {
 				App.Events.Dispatch(events.Errorf("test", "FAILED: Could not enforce schema"))
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"badgermaps/database"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StoredTimeLayout is the layout appointment times are stored in: UTC, so
+// every database type keeps the same instant whatever its column type.
+const StoredTimeLayout = "2006-01-02T15:04:05Z"
+
+// naiveTimeLayouts are the timestamp formats seen without a UTC offset.
+// They are read as wall-clock times in the display timezone.
+var naiveTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02-T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// clockLayouts are the time-of-day formats an appointment may be sent in,
+// in which case the date comes from the route.
+var clockLayouts = []string{"15:04:05", "15:04", "3:04 PM", "3:04PM"}
+
+// ValidateTimezone checks an IANA timezone name. Empty means local time.
+func ValidateTimezone(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(strings.TrimSpace(name)); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return nil
+}
+
+// DisplayLocation returns the timezone dates and times are shown in:
+// display_timezone from the config, or local time when it is unset or
+// invalid.
+func (a *App) DisplayLocation() *time.Location {
+	if a.Config != nil && a.Config.DisplayTimezone != "" {
+		if loc, err := time.LoadLocation(strings.TrimSpace(a.Config.DisplayTimezone)); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// NormalizeFollowUpDate reduces a follow-up date to YYYY-MM-DD. The date is
+// taken from the text as sent rather than converted between timezones, so a
+// date sent as midnight UTC does not move to the previous day.
+func NormalizeFollowUpDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if _, _, err := parseDateTime(value, time.UTC); err != nil {
+		if _, dateErr := time.Parse("2006-01-02", value); dateErr != nil {
+			return "", fmt.Errorf("%q is not a recognized date", value)
+		}
+	}
+	return value[:10], nil
+}
+
+// ParseApptTime parses an appointment time. Values without a UTC offset are
+// wall-clock times in loc; a bare time of day is placed on routeDate. zoned
+// reports whether the value carried its own offset.
+func ParseApptTime(value, routeDate string, loc *time.Location) (t time.Time, zoned bool, err error) {
+	value = strings.TrimSpace(value)
+	if t, zoned, err = parseDateTime(value, loc); err == nil {
+		return t, zoned, nil
+	}
+	for _, layout := range clockLayouts {
+		clock, clockErr := time.Parse(layout, value)
+		if clockErr != nil {
+			continue
+		}
+		if len(routeDate) < 10 {
+			return time.Time{}, false, fmt.Errorf("appointment time %q has no date", value)
+		}
+		day, dayErr := time.Parse("2006-01-02", routeDate[:10])
+		if dayErr != nil {
+			return time.Time{}, false, fmt.Errorf("route date %q is not a recognized date", routeDate)
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc), false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a recognized time", value)
+}
+
+// NormalizeApptTime converts an appointment time to StoredTimeLayout. For a
+// value without a UTC offset it also returns the WallClockProblem of reading
+// it in loc, if any.
+func NormalizeApptTime(value, routeDate string, loc *time.Location) (stored, problem string, err error) {
+	wall, zoned, err := ParseApptTime(value, routeDate, time.UTC)
+	if err != nil {
+		return "", "", err
+	}
+	t := wall
+	if !zoned {
+		problem = WallClockProblem(wall, loc)
+		t = inLocation(wall, loc)
+	}
+	return t.UTC().Format(StoredTimeLayout), problem, nil
+}
+
+func parseDateTime(value string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range naiveTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a recognized timestamp", value)
+}
+
+// WallClockProblem reads the date and clock of wall, ignoring its location,
+// as a time in loc and describes why that cannot be done unambiguously: the
+// time was skipped by a DST change or occurs twice. It returns "" when the
+// time is unambiguous.
+func WallClockProblem(wall time.Time, loc *time.Location) string {
+	const clock = "2006-01-02 15:04:05"
+	local := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+	if local.Format(clock) != wall.Format(clock) {
+		return fmt.Sprintf("does not exist in %s (skipped by a DST change)", loc)
+	}
+	for _, shift := range []time.Duration{-time.Hour, time.Hour} {
+		if local.Add(shift).In(loc).Format(clock) == local.Format(clock) {
+			return fmt.Sprintf("occurs twice in %s (repeated by a DST change)", loc)
+		}
+	}
+	return ""
+}
+
+// inLocation returns the time in loc with the same date and clock as wall.
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+}
+
+// DSTShift reports whether an appointment that moved from before to after
+// moved by exactly the DST offset of loc, which usually means one side read
+// the time in the wrong half of the year rather than that it was rescheduled.
+func DSTShift(before, after time.Time, loc *time.Location) bool {
+	moved := after.Sub(before)
+	if moved < 0 {
+		moved = -moved
+	}
+	return moved != 0 && moved == dstOffset(loc, after.Year())
+}
+
+// dstOffset returns how far DST moves the clocks in loc during year, or 0
+// when the zone has no DST.
+func dstOffset(loc *time.Location, year int) time.Duration {
+	_, winter := time.Date(year, time.January, 1, 12, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(year, time.July, 1, 12, 0, 0, 0, loc).Zone()
+	offset := time.Duration(summer-winter) * time.Second
+	if offset < 0 {
+		offset = -offset
+	}
+	return offset
+}
+
+// DateTimeIssue is a stored follow-up date or appointment time that may be
+// shown at the wrong day or hour.
+type DateTimeIssue struct {
+	database.DateTimeValue
+	Problem string
+}
+
+// CheckDateTimes looks for follow-up dates and appointment times that are
+// unreadable, carry a time that changes the day shown in the display
+// timezone, or fall in a DST gap or overlap there.
+func (a *App) CheckDateTimes() ([]DateTimeIssue, error) {
+	if a.DB == nil {
+		return nil, fmt.Errorf("database is not configured")
+	}
+	values, err := database.GetDateTimeValues(a.DB)
+	if err != nil {
+		return nil, err
+	}
+	loc := a.DisplayLocation()
+	var issues []DateTimeIssue
+	for _, v := range values {
+		if problem := checkDateTime(v, loc); problem != "" {
+			issues = append(issues, DateTimeIssue{DateTimeValue: v, Problem: problem})
+		}
+	}
+	return issues, nil
+}
+
+func checkDateTime(v database.DateTimeValue, loc *time.Location) string {
+	if v.Field == "FollowUpDate" {
+		date, err := NormalizeFollowUpDate(v.Value)
+		if err != nil {
+			return "not a recognized date"
+		}
+		if len(strings.TrimSpace(v.Value)) == len(date) {
+			return ""
+		}
+		if t, zoned, err := parseDateTime(strings.TrimSpace(v.Value), loc); err == nil && zoned && t.In(loc).Format("2006-01-02") != date {
+			return fmt.Sprintf("includes a time and shows as %s in %s; the next pull stores %s", t.In(loc).Format("2006-01-02"), loc, date)
+		}
+		return fmt.Sprintf("includes a time; the next pull stores %s", date)
+	}
+
+	wall, zoned, err := ParseApptTime(v.Value, "", time.UTC)
+	if err != nil {
+		return "not a recognized time"
+	}
+	if zoned {
+		return ""
+	}
+	if problem := WallClockProblem(wall, loc); problem != "" {
+		return "has no timezone and " + problem
+	}
+	return fmt.Sprintf("has no timezone; read as %s", inLocation(wall, loc).Format("2006-01-02 15:04 MST"))
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNormalizeFollowUpDate(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"2024-03-10", "2024-03-10", false},
+		{"2024-03-10T00:00:00Z", "2024-03-10", false},
+		{"2024-03-10-T05:30:00", "2024-03-10", false},
+		{"next tuesday", "", true},
+	} {
+		got, err := NormalizeFollowUpDate(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeFollowUpDate(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestNormalizeApptTime(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		value, routeDate string
+		want, problem    string
+	}{
+		{"2024-01-15T09:30:00", "", "2024-01-15T15:30:00Z", ""},
+		{"2024-07-15T09:30:00-05:00", "", "2024-07-15T14:30:00Z", ""},
+		{"09:30", "2024-07-15", "2024-07-15T14:30:00Z", ""},
+		{"2024-03-10T02:30:00", "", "", "does not exist"},
+		{"2024-11-03T01:30:00", "", "", "occurs twice"},
+	} {
+		got, problem, err := NormalizeApptTime(tt.value, tt.routeDate, chicago)
+		if err != nil {
+			t.Errorf("NormalizeApptTime(%q): %v", tt.value, err)
+			continue
+		}
+		if tt.want != "" && got != tt.want {
+			t.Errorf("NormalizeApptTime(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if (tt.problem == "") != (problem == "") || !strings.Contains(problem, tt.problem) {
+			t.Errorf("NormalizeApptTime(%q) problem = %q, want %q", tt.value, problem, tt.problem)
+		}
+	}
+	if _, _, err := NormalizeApptTime("09:30", "", chicago); err == nil {
+		t.Error("a time of day without a route date was accepted")
+	}
+}
+
+func TestDSTShift(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	before := time.Date(2024, 3, 12, 14, 30, 0, 0, time.UTC)
+	if !DSTShift(before, before.Add(-time.Hour), chicago) {
+		t.Error("a one hour move was not reported in a DST zone")
+	}
+	if DSTShift(before, before.Add(2*time.Hour), chicago) || DSTShift(before, before, chicago) {
+		t.Error("a reschedule or no change was reported as a DST shift")
+	}
+	if DSTShift(before, before.Add(time.Hour), time.UTC) {
+		t.Error("a DST shift was reported in a zone without DST")
+	}
+}
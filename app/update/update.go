@@ -0,0 +1,356 @@
+// Package update checks GitHub releases for a newer build and replaces the
+// running desktop binary with it.
+package update
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReleasesURL returns the latest published release of the app.
+const DefaultReleasesURL = "https://api.github.com/repos/vamuscari/BadgerMapsSync/releases/latest"
+
+// ErrManualUpdate is returned by Apply when the build cannot replace
+// itself, such as a macOS app bundle, or the release cannot be verified.
+// The release page has the download.
+var ErrManualUpdate = errors.New("this build cannot update itself; download the release instead")
+
+// ChecksumsAsset is the release file listing the SHA-256 of every other
+// asset, in the format sha256sum writes.
+const ChecksumsAsset = "SHA256SUMS"
+
+// downloadTimeout bounds an update download when Apply is given no client.
+const downloadTimeout = 10 * time.Minute
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag         string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Notes       string    `json:"body"`
+	PageURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Version is the release tag without its leading v.
+func (r Release) Version() string {
+	return strings.TrimPrefix(strings.TrimSpace(r.Tag), "v")
+}
+
+// DesktopAsset returns the GUI build for goos and goarch. Server builds
+// are skipped, and macOS builds match any architecture.
+func (r Release) DesktopAsset(goos, goarch string) (Asset, bool) {
+	names := map[string][]string{
+		"darwin":  {"darwin", "macos"},
+		"windows": {"windows"},
+		"linux":   {"linux"},
+	}[goos]
+	for _, asset := range r.Assets {
+		name := strings.ToLower(asset.Name)
+		if strings.Contains(name, "server") || !containsAny(name, names) {
+			continue
+		}
+		if goos != "darwin" && !strings.Contains(name, goarch) {
+			continue
+		}
+		return asset, true
+	}
+	return Asset{}, false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Current   string
+	Latest    Release
+	Available bool
+}
+
+// Checker looks up the latest release.
+type Checker struct {
+	Client *http.Client
+	// URL is the releases endpoint; DefaultReleasesURL when empty.
+	URL string
+}
+
+// Check compares current with the latest release.
+func (c Checker) Check(ctx context.Context, current string) (*Result, error) {
+	endpoint := c.URL
+	if endpoint == "" {
+		endpoint = DefaultReleasesURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for updates: %s", resp.Status)
+	}
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to read the latest release: %w", err)
+	}
+	if release.Version() == "" {
+		return nil, fmt.Errorf("the latest release has no version tag")
+	}
+	return &Result{
+		Current:   current,
+		Latest:    release,
+		Available: CompareVersions(release.Version(), current) > 0,
+	}, nil
+}
+
+func (c Checker) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// CompareVersions compares dotted versions such as 1.4.0 or v1.5.0-beta.1,
+// returning -1, 0, or 1. Numeric parts compare as numbers, and a
+// pre-release sorts before the release it precedes.
+func CompareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(a), "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(b), "v"), "-")
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		if c := comparePart(part(aParts, i), part(bParts, i)); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePart(aPre, bPre)
+}
+
+func part(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return "0"
+}
+
+func comparePart(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// CanApply reports whether builds for goos can replace themselves. macOS
+// builds ship as app bundles, which are installed by hand.
+func CanApply(goos string) bool {
+	return goos == "windows" || goos == "linux"
+}
+
+// Apply downloads asset from release, checks it against the release's
+// SHA256SUMS, and replaces the executable at exe with it. The old binary is
+// kept beside it with an .old suffix until RemovePrevious, since a running
+// executable cannot be deleted on Windows. Zip assets must hold one
+// executable.
+func Apply(ctx context.Context, client *http.Client, release Release, asset Asset, exe string) error {
+	if client == nil {
+		client = &http.Client{Timeout: downloadTimeout}
+	}
+	want, err := release.Checksum(ctx, client, asset.Name)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", asset.Name, resp.Status)
+	}
+
+	dir := filepath.Dir(exe)
+	download, err := os.CreateTemp(dir, ".badgermaps-download-*")
+	if err != nil {
+		return fmt.Errorf("failed to save the update: %w", err)
+	}
+	defer os.Remove(download.Name())
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(download, hash), resp.Body)
+	download.Close()
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	if asset.Size > 0 && size != asset.Size {
+		return fmt.Errorf("downloaded %d bytes of %s, expected %d", size, asset.Name, asset.Size)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("%s does not match its published SHA-256 (got %s, expected %s)", asset.Name, got, want)
+	}
+
+	binary := download.Name()
+	if strings.EqualFold(filepath.Ext(asset.Name), ".zip") {
+		extracted, err := extractExecutable(download.Name(), dir)
+		if err != nil {
+			return fmt.Errorf("failed to unpack %s: %w", asset.Name, err)
+		}
+		defer os.Remove(extracted)
+		binary = extracted
+	}
+	if err := os.Chmod(binary, 0o755); err != nil {
+		return err
+	}
+
+	previous := exe + ".old"
+	os.Remove(previous)
+	if err := os.Rename(exe, previous); err != nil {
+		return fmt.Errorf("failed to move the current binary aside: %w", err)
+	}
+	if err := os.Rename(binary, exe); err != nil {
+		os.Rename(previous, exe)
+		return fmt.Errorf("failed to install the update: %w", err)
+	}
+	return nil
+}
+
+// Checksum returns the SHA-256 the release's SHA256SUMS lists for name. A
+// release without the file, or without an entry for name, cannot be
+// verified and is refused with ErrManualUpdate.
+func (r Release) Checksum(ctx context.Context, client *http.Client, name string) (string, error) {
+	var sums *Asset
+	for i := range r.Assets {
+		if r.Assets[i].Name == ChecksumsAsset {
+			sums = &r.Assets[i]
+			break
+		}
+	}
+	if sums == nil {
+		return "", fmt.Errorf("%w (release %s publishes no %s)", ErrManualUpdate, r.Version(), ChecksumsAsset)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sums.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", ChecksumsAsset, resp.Status)
+	}
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		if sum, err := hex.DecodeString(fields[0]); err != nil || len(sum) != sha256.Size {
+			return "", fmt.Errorf("%s has a malformed entry for %s", ChecksumsAsset, name)
+		}
+		return strings.ToLower(fields[0]), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", ChecksumsAsset, err)
+	}
+	return "", fmt.Errorf("%w (%s lists no checksum for %s)", ErrManualUpdate, ChecksumsAsset, name)
+}
+
+// RemovePrevious deletes the binary an earlier Apply moved aside.
+func RemovePrevious(exe string) {
+	os.Remove(exe + ".old")
+}
+
+// extractExecutable writes the one executable in the zip at path to a
+// temporary file in dir. App bundles are refused.
+func extractExecutable(path, dir string) (string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	var found *zip.File
+	for _, file := range archive.File {
+		if strings.Contains(file.Name, ".app/") {
+			return "", ErrManualUpdate
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(file.Name), ".exe") || file.Mode()&0o111 != 0 {
+			if found != nil {
+				return "", fmt.Errorf("it holds more than one executable")
+			}
+			found = file
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("it holds no executable")
+	}
+
+	src, err := found.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp(dir, ".badgermaps-update-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), dst.Close()
+}
@@ -0,0 +1,111 @@
+package update
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"v1.10.0", "1.9.3", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0-beta.1", "2.0.0", -1},
+		{"2.0.0-beta.2", "2.0.0-beta.1", 1},
+		{"1.0.0", "v1.0", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDesktopAsset(t *testing.T) {
+	release := Release{Assets: []Asset{
+		{Name: "badgermaps-server_linux_amd64"},
+		{Name: "badgermaps_linux_amd64"},
+		{Name: "BadgerMapsSync_windows_amd64.zip"},
+		{Name: "BadgerMapsSync_macOS.zip"},
+	}}
+	for _, tt := range []struct{ goos, goarch, want string }{
+		{"linux", "amd64", "badgermaps_linux_amd64"},
+		{"windows", "amd64", "BadgerMapsSync_windows_amd64.zip"},
+		{"darwin", "arm64", "BadgerMapsSync_macOS.zip"},
+		{"linux", "arm64", ""},
+	} {
+		asset, _ := release.DesktopAsset(tt.goos, tt.goarch)
+		if asset.Name != tt.want {
+			t.Errorf("DesktopAsset(%s, %s) = %q, want %q", tt.goos, tt.goarch, asset.Name, tt.want)
+		}
+	}
+}
+
+func TestApplyReplacesBinaryFromZip(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, _ := zw.Create("badgermaps.exe")
+	w.Write([]byte("new build"))
+	zw.Close()
+	sum := sha256.Sum256(archive.Bytes())
+	sums := hex.EncodeToString(sum[:]) + "  BadgerMapsSync_windows_amd64.zip\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/SHA256SUMS" {
+			w.Write([]byte(sums))
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	exe := filepath.Join(t.TempDir(), "badgermaps.exe")
+	if err := os.WriteFile(exe, []byte("old build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	asset := Asset{Name: "BadgerMapsSync_windows_amd64.zip", URL: server.URL + "/asset", Size: int64(archive.Len())}
+	release := Release{Tag: "v1.1.0", Assets: []Asset{asset, {Name: ChecksumsAsset, URL: server.URL + "/SHA256SUMS"}}}
+	if err := Apply(context.Background(), server.Client(), release, asset, exe); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new build" {
+		t.Errorf("binary = %q after the update", data)
+	}
+	if data, _ := os.ReadFile(exe + ".old"); string(data) != "old build" {
+		t.Errorf("previous binary = %q", data)
+	}
+	RemovePrevious(exe)
+	if _, err := os.Stat(exe + ".old"); !os.IsNotExist(err) {
+		t.Errorf("previous binary was not removed: %v", err)
+	}
+
+	short := asset
+	short.Size++
+	if err := Apply(context.Background(), server.Client(), release, short, exe); err == nil {
+		t.Error("a short download was installed")
+	}
+
+	sums = strings.Repeat("0", 64) + "  BadgerMapsSync_windows_amd64.zip\n"
+	if err := Apply(context.Background(), server.Client(), release, asset, exe); err == nil || !strings.Contains(err.Error(), "SHA-256") {
+		t.Errorf("a download with the wrong checksum was installed: %v", err)
+	}
+	unsigned := Release{Tag: "v1.1.0", Assets: []Asset{asset}}
+	if err := Apply(context.Background(), server.Client(), unsigned, asset, exe); !errors.Is(err, ErrManualUpdate) {
+		t.Errorf("Apply without SHA256SUMS = %v, want ErrManualUpdate", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new build" {
+		t.Errorf("binary = %q after refused updates", data)
+	}
+}
@@ -0,0 +1,22 @@
+package app
+
+import "badgermaps/api"
+
+var (
+	// Version information - these will be set during build
+	Version = "0.1.0"
+	Commit  = "development"
+	Date    = "unknown"
+)
+
+// The API client reports the version in its User-Agent.
+func init() {
+	api.ClientVersion = Version
+}
+
+// UpdateCheckEnabled reports whether the GUI looks for a new release at
+// startup. disable_update_check opts out; `version --check` and the
+// Configuration tab still check on request.
+func (a *App) UpdateCheckEnabled() bool {
+	return a.Config == nil || !a.Config.DisableUpdateCheck
+}
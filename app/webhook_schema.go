@@ -0,0 +1,206 @@
+package app
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Parse statuses stored in WebhookLog.ParseStatus.
+const (
+	WebhookParseValid   = "valid"
+	WebhookParseInvalid = "invalid"
+	// WebhookParseUnchecked marks requests to paths without a schema, such
+	// as those logged by the catch-all handler.
+	WebhookParseUnchecked = "unchecked"
+)
+
+//go:embed webhook_schemas/*.json
+var webhookSchemaFiles embed.FS
+
+// webhookSchema is the subset of JSON Schema the webhook schemas use: type
+// (one or several), required, properties, items, and minimum. Properties
+// not listed are allowed.
+type webhookSchema struct {
+	Type       schemaTypes              `json:"type"`
+	Required   []string                 `json:"required"`
+	Properties map[string]webhookSchema `json:"properties"`
+	Items      *webhookSchema           `json:"items"`
+	Minimum    *float64                 `json:"minimum"`
+}
+
+// schemaTypes accepts "type": "string" as well as "type": ["string", "null"].
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// webhookSchemas holds the schema of each webhook by name.
+var webhookSchemas = func() map[string]webhookSchema {
+	schemas := make(map[string]webhookSchema)
+	for _, name := range []string{WebhookAccountCreate, WebhookCheckin} {
+		data, err := webhookSchemaFiles.ReadFile("webhook_schemas/" + name + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("missing webhook schema %s: %v", name, err))
+		}
+		var schema webhookSchema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			panic(fmt.Sprintf("invalid webhook schema %s: %v", name, err))
+		}
+		schemas[name] = schema
+	}
+	return schemas
+}()
+
+// webhookPaths maps the server paths to the webhooks they receive.
+var webhookPaths = map[string]string{
+	"/webhook/account/create": WebhookAccountCreate,
+	"/webhook/checkin":        WebhookCheckin,
+}
+
+// WebhookForPath returns the webhook served at path, or "" when the path
+// has no webhook.
+func WebhookForPath(path string) string {
+	return webhookPaths[path]
+}
+
+// WebhookValidation is the result of checking a webhook body against the
+// schema of its webhook.
+type WebhookValidation struct {
+	Webhook string
+	Status  string
+	// EntityID is the account or check-in ID the body carries, or 0.
+	EntityID int64
+	Errors   []string
+}
+
+// Error joins the validation errors.
+func (v WebhookValidation) Error() string {
+	return strings.Join(v.Errors, "; ")
+}
+
+// ValidateWebhookPayload checks body against the schema of webhook and
+// extracts the ID of the entity it carries. Webhooks without a schema are
+// reported as unchecked.
+func ValidateWebhookPayload(webhook string, body []byte) WebhookValidation {
+	result := WebhookValidation{Webhook: webhook, Status: WebhookParseUnchecked}
+	schema, ok := webhookSchemas[webhook]
+	if !ok {
+		return result
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		result.Status = WebhookParseInvalid
+		result.Errors = []string{fmt.Sprintf("body is not valid JSON: %v", err)}
+		return result
+	}
+	result.Errors = schema.validate("", payload)
+	if len(result.Errors) > 0 {
+		result.Status = WebhookParseInvalid
+	} else {
+		result.Status = WebhookParseValid
+	}
+	if object, ok := payload.(map[string]interface{}); ok {
+		if id, ok := object["id"].(json.Number); ok {
+			result.EntityID, _ = id.Int64()
+		}
+	}
+	return result
+}
+
+// validate returns the problems of value at path, sorted by path.
+func (s webhookSchema) validate(path string, value interface{}) []string {
+	name := path
+	if name == "" {
+		name = "body"
+	}
+	if len(s.Type) > 0 && !s.allows(value) {
+		return []string{fmt.Sprintf("%s must be %s, got %s", name, strings.Join(s.Type, " or "), jsonKind(value))}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range s.Required {
+			if _, ok := v[field]; !ok {
+				problems = append(problems, fmt.Sprintf("%s is required", joinPath(path, field)))
+			}
+		}
+		for field, property := range s.Properties {
+			if fieldValue, ok := v[field]; ok {
+				problems = append(problems, property.validate(joinPath(path, field), fieldValue)...)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				problems = append(problems, s.Items.validate(fmt.Sprintf("%s[%d]", name, i), item)...)
+			}
+		}
+	case json.Number:
+		if s.Minimum != nil {
+			if f, err := v.Float64(); err == nil && f < *s.Minimum {
+				problems = append(problems, fmt.Sprintf("%s must be at least %v, got %s", name, *s.Minimum, v))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func (s webhookSchema) allows(value interface{}) bool {
+	kind := jsonKind(value)
+	for _, t := range s.Type {
+		if t == kind || (t == "number" && kind == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonKind names the JSON Schema type of a decoded value.
+func jsonKind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
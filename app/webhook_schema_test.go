@@ -0,0 +1,74 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateWebhookPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhook  string
+		body     string
+		status   string
+		entityID int64
+		errors   []string
+	}{
+		{
+			name:     "valid account",
+			webhook:  WebhookAccountCreate,
+			body:     `{"id": 42, "full_name": "Acme", "notes": null, "locations": [{"id": 1, "lat": 39.7, "long": -105}], "custom_text": "extra"}`,
+			status:   WebhookParseValid,
+			entityID: 42,
+		},
+		{
+			name:    "not JSON",
+			webhook: WebhookAccountCreate,
+			body:    `id=42`,
+			status:  WebhookParseInvalid,
+		},
+		{
+			name:     "wrong types",
+			webhook:  WebhookAccountCreate,
+			body:     `{"id": 42, "email": 7, "locations": [{"lat": "north"}]}`,
+			status:   WebhookParseInvalid,
+			entityID: 42,
+			errors:   []string{"email must be string or null, got integer", "locations[0].lat must be number or null, got string"},
+		},
+		{
+			name:     "missing customer",
+			webhook:  WebhookCheckin,
+			body:     `{"id": 9, "type": "Visit"}`,
+			status:   WebhookParseInvalid,
+			entityID: 9,
+			errors:   []string{"customer is required"},
+		},
+		{
+			name:    "fractional id",
+			webhook: WebhookCheckin,
+			body:    `{"id": 1.5, "customer": 0}`,
+			status:  WebhookParseInvalid,
+			errors:  []string{"customer must be at least 1, got 0", "id must be integer, got number"},
+		},
+		{
+			name:    "no schema",
+			webhook: "",
+			body:    `anything`,
+			status:  WebhookParseUnchecked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateWebhookPayload(tt.webhook, []byte(tt.body))
+			if got.Status != tt.status || got.EntityID != tt.entityID {
+				t.Fatalf("status %s, entity %d; want %s, %d (errors %v)", got.Status, got.EntityID, tt.status, tt.entityID, got.Errors)
+			}
+			if tt.errors != nil && !reflect.DeepEqual(got.Errors, tt.errors) {
+				t.Errorf("errors = %q, want %q", got.Errors, tt.errors)
+			}
+		})
+	}
+	if WebhookForPath("/webhook/checkin") != WebhookCheckin || WebhookForPath("/other") != "" {
+		t.Error("WebhookForPath does not match the server routes")
+	}
+}
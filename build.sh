@@ -2,6 +2,8 @@
 
 # This script cross-compiles the application for macOS, Linux, and Windows
 # using the Fyne CLI, and places the binaries in the build directory.
+# The "server" target builds a slim CLI/server binary for the host platform
+# with the GUI compiled out (-tags nogui); it needs no Fyne toolchain.
 # It assumes you have the necessary cross-compilation toolchains installed.
 # For Windows: mingw-w64 (e.g., `brew install mingw-w64`)
 # For Linux: a Linux GCC toolchain (e.g., `brew install x86_64-unknown-linux-gnu`)
@@ -13,8 +15,8 @@ usage() {
 Usage: $0 [-k] [-o targets]
 
   -k           Keep intermediate files after packaging.
-  -o targets   Comma-separated list of operating systems to build (darwin, windows).
-               Defaults to building both targets.
+  -o targets   Comma-separated list of targets to build (darwin, windows, server).
+               Defaults to building darwin and windows.
 EOF
   exit "${1:-0}"
 }
@@ -46,6 +48,9 @@ while getopts ":ko:h" opt; do
       windows|win)
         TARGETS+=("windows")
         ;;
+      server|cli|nogui)
+        TARGETS+=("server")
+        ;;
       *)
         echo "Unsupported target \"${raw_target}\"." 1>&2
         usage 1
@@ -74,6 +79,7 @@ fi
 
 TARGET_DARWIN=false
 TARGET_WINDOWS=false
+TARGET_SERVER=false
 for target in "${TARGETS[@]}"; do
   case ${target} in
   darwin)
@@ -82,6 +88,9 @@ for target in "${TARGETS[@]}"; do
   windows)
     TARGET_WINDOWS=true
     ;;
+  server)
+    TARGET_SERVER=true
+    ;;
   esac
 done
 
@@ -104,10 +113,28 @@ fi
 
 mkdir -p dist
 
+# Stamp the release version into every binary so the update check compares
+# against the real version. VERSION defaults to the latest git tag.
+VERSION="${VERSION:-$(git describe --tags --abbrev=0 2>/dev/null || true)}"
+VERSION="${VERSION#v}"
+COMMIT="$(git rev-parse --short HEAD 2>/dev/null || echo development)"
+BUILD_DATE="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+VERSION_LDFLAGS="-X badgermaps/app.Commit=${COMMIT} -X badgermaps/app.Date=${BUILD_DATE}"
+# fyne package takes no -ldflags, so it gets the version through GOFLAGS,
+# which cannot hold spaces.
+FYNE_GOFLAGS=""
+if [ -n "${VERSION}" ]; then
+  VERSION_LDFLAGS="-X badgermaps/app.Version=${VERSION} ${VERSION_LDFLAGS}"
+  FYNE_GOFLAGS="-ldflags=-X=badgermaps/app.Version=${VERSION}"
+  echo "Building version ${VERSION} (${COMMIT})."
+else
+  echo "Warning: no VERSION and no git tag; binaries report the default version." 1>&2
+fi
+
 if [ "$TARGET_DARWIN" = true ]; then
   # Compile for macOS
   echo "Compiling for macOS..."
-  fyne package -os darwin -release
+  GOFLAGS="${FYNE_GOFLAGS}" fyne package -os darwin -release
   echo "Compressing macOS app..."
   zip -r dist/BadgerMapsSync_macOS.zip BadgerMapsSync.app
   if [ "$CLEAN_UP" = true ]; then
@@ -137,10 +164,10 @@ if [ "$TARGET_WINDOWS" = true ]; then
   # NOTE: Requires a Windows cross-compiler like mingw-w64
   echo "Compiling for Windows..."
   env CGO_ENABLED=1 GOOS=windows GOARCH=amd64 CC=x86_64-w64-mingw32-gcc \
-    go build -o BadgerMapsSync.exe -ldflags="-H windowsgui -s -w" -tags release
+    go build -o BadgerMapsSync.exe -ldflags="-H windowsgui -s -w ${VERSION_LDFLAGS}" -tags release
 
   # This command embeds the icon and metadata from FyneApp.toml into the .exe
-  env CGO_ENABLED=1 GOOS=windows GOARCH=amd64 CC=x86_64-w64-mingw32-gcc \
+  env CGO_ENABLED=1 GOOS=windows GOARCH=amd64 CC=x86_64-w64-mingw32-gcc GOFLAGS="${FYNE_GOFLAGS}" \
     fyne package --os windows --executable BadgerMapsSync.exe -release
 
   # Create a temporary directory for packaging the final zip
@@ -166,4 +193,29 @@ if [ "$TARGET_WINDOWS" = true ]; then
     rm -rf "$TEMP_DIR"
   fi
 fi
+if [ "$TARGET_SERVER" = true ]; then
+  # Slim CLI/server binary without the GUI
+  SERVER_BIN="dist/badgermaps-server_$(go env GOOS)_$(go env GOARCH)"
+  echo "Compiling slim server binary ${SERVER_BIN}..."
+  go build -tags nogui -trimpath -ldflags="-s -w ${VERSION_LDFLAGS}" -o "${SERVER_BIN}" .
+fi
+
+# The updater refuses downloads that SHA256SUMS does not vouch for, so it
+# is published with every release.
+echo "Writing dist/SHA256SUMS..."
+if command -v sha256sum >/dev/null 2>&1; then
+  SHA256="sha256sum"
+else
+  SHA256="shasum -a 256"
+fi
+(
+  cd dist
+  rm -f SHA256SUMS
+  for file in *; do
+    if [ -f "${file}" ]; then
+      ${SHA256} "${file}" >> SHA256SUMS
+    fi
+  done
+)
+
 echo "Build complete."
@@ -0,0 +1,240 @@
+package action
+
+import (
+	"badgermaps/app"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// stepFlags holds the flags shared by add and edit that describe a single
+// action step.
+type stepFlags struct {
+	actionType string
+	command    string
+	args       []string
+	noShell    bool
+	function   string
+	procedure  string
+	query      string
+	params     []string
+	path       string
+	format     string
+	templates  string
+	months     int
+	dir        string
+	account    string
+	set        []string
+
+	digestName      string
+	failuresOnly    bool
+	lookback        string
+	emailTo         []string
+	emailFrom       string
+	emailSubject    string
+	smtpHost        string
+	smtpPort        int
+	smtpUsername    string
+	smtpPasswordEnv string
+}
+
+func (f *stepFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.actionType, "type", "t", "", "Action type (exec, db, backup, digest, archive, or followup)")
+	cmd.Flags().StringVar(&f.command, "command", "", "exec: command to run; db: name of a bundled SQL command")
+	cmd.Flags().StringArrayVar(&f.args, "arg", nil, "exec: argument passed to the command (requires --no-shell, repeatable)")
+	cmd.Flags().BoolVar(&f.noShell, "no-shell", false, "exec: run the binary directly instead of through the shell")
+	cmd.Flags().StringVar(&f.function, "function", "", "db: database function to call")
+	cmd.Flags().StringVar(&f.procedure, "procedure", "", "db: stored procedure to call")
+	cmd.Flags().StringVar(&f.query, "query", "", "db: raw SQL query to execute")
+	cmd.Flags().StringArrayVar(&f.params, "param", nil, "db: query parameter (repeatable)")
+	cmd.Flags().StringVar(&f.path, "path", "", "backup, digest: file to write; {timestamp} is replaced with the time of the run")
+	cmd.Flags().StringVar(&f.format, "format", "", "backup: sqlite or json (default: sqlite for SQLite databases, json otherwise); digest: text or csv")
+	cmd.Flags().IntVar(&f.months, "months", 0, "archive: move check-ins older than this many whole months")
+	cmd.Flags().StringVar(&f.dir, "dir", "", "archive: directory of the check-in archive (default: archive in the config directory)")
+	cmd.Flags().StringVar(&f.account, "account", "", "followup: account to update (default: the event's account; may be a template)")
+	cmd.Flags().StringArrayVar(&f.set, "set", nil, "followup: field=value to stage, e.g. follow_up_date=+14d (repeatable)")
+	cmd.Flags().StringVar(&f.digestName, "digest-name", "", "digest: name the digest is tracked by, so each run covers what the last one did not")
+	cmd.Flags().BoolVar(&f.failuresOnly, "failures-only", false, "digest: leave successful runs out")
+	cmd.Flags().StringVar(&f.lookback, "lookback", "", "digest: how far back the first digest reaches (default 24h)")
+	cmd.Flags().StringArrayVar(&f.emailTo, "email-to", nil, "digest: email recipient (repeatable)")
+	cmd.Flags().StringVar(&f.emailFrom, "email-from", "", "digest: sender address")
+	cmd.Flags().StringVar(&f.emailSubject, "email-subject", "", "digest: email subject")
+	cmd.Flags().StringVar(&f.smtpHost, "smtp-host", "", "digest: SMTP server host")
+	cmd.Flags().IntVar(&f.smtpPort, "smtp-port", 0, "digest: SMTP server port (default 587)")
+	cmd.Flags().StringVar(&f.smtpUsername, "smtp-username", "", "digest: SMTP user name")
+	cmd.Flags().StringVar(&f.smtpPasswordEnv, "smtp-password-env", "", "digest: environment variable holding the SMTP password")
+	cmd.Flags().StringVar(&f.templates, "templates", "", "How unresolved {{payload.path}} templates are handled: lenient or strict")
+}
+
+// ActionCmd creates the action command for managing event actions.
+func ActionCmd(a *app.App) *cobra.Command {
+	presenter := NewCliPresenter(a)
+
+	cmd := &cobra.Command{
+		Use:   "action",
+		Short: "Manage event actions",
+		Long:  `List, add, edit, remove, enable, and disable the actions that run when application events fire. Changes are written to the loaded config file.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(listCmd(presenter))
+	cmd.AddCommand(addCmd(presenter))
+	cmd.AddCommand(editCmd(presenter))
+	cmd.AddCommand(removeCmd(presenter))
+	cmd.AddCommand(enableCmd(presenter, true))
+	cmd.AddCommand(enableCmd(presenter, false))
+	cmd.AddCommand(runsCmd(presenter))
+	cmd.AddCommand(rerunCmd(presenter))
+	return cmd
+}
+
+func listCmd(presenter *CliPresenter) *cobra.Command {
+	var event string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured event actions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return presenter.HandleList(event)
+		},
+	}
+	cmd.Flags().StringVarP(&event, "event", "e", "", "Only show actions for this event")
+	return cmd
+}
+
+func addCmd(presenter *CliPresenter) *cobra.Command {
+	var event, source string
+	var step stepFlags
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add an action step to an event",
+		Long:  `Adds an action step that runs when the given event fires. Steps for the same event and source are grouped under one event action.`,
+		Example: `  badgermaps action add --event pull.complete --type exec --command "echo done"
+  badgermaps action add --event push.item.error --type db --query "INSERT INTO Alerts (Message) VALUES (?)" --param '$EVENT_PAYLOAD'
+  badgermaps action add --event pull.complete --type backup --path "backups/badgermaps-{timestamp}.db"
+  badgermaps action add --event pull.complete --type digest --digest-name daily --path "digests/{timestamp}.txt" --failures-only
+  badgermaps action add --event webhook.checkin --type followup --set follow_up_date=+14d
+  badgermaps action add --event pull.store.success --source accounts --type exec --command "notify {{payload.Data.locations[0].city}}" --templates strict`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return presenter.HandleAdd(event, source, step, cmd.Flags().Changed)
+		},
+	}
+	cmd.Flags().StringVarP(&event, "event", "e", "", "Event that triggers the action (e.g. pull.complete)")
+	cmd.Flags().StringVarP(&source, "source", "s", "", "Only run for events from this source")
+	step.register(cmd)
+	cmd.MarkFlagRequired("event")
+	cmd.MarkFlagRequired("type")
+	return cmd
+}
+
+func editCmd(presenter *CliPresenter) *cobra.Command {
+	var event, source string
+	var step stepFlags
+	cmd := &cobra.Command{
+		Use:   "edit <name> [step]",
+		Short: "Edit an event action or one of its steps",
+		Long:  `Updates the event or source of an event action, and the flags given for the selected step (0-based, default 0). Flags that are not given keep their current values.`,
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := stepIndex(args)
+			if err != nil {
+				return err
+			}
+			return presenter.HandleEdit(args[0], index, event, source, step, cmd.Flags().Changed)
+		},
+	}
+	cmd.Flags().StringVarP(&event, "event", "e", "", "Move the action to a different event")
+	cmd.Flags().StringVarP(&source, "source", "s", "", "Change the event source filter (empty for any)")
+	step.register(cmd)
+	return cmd
+}
+
+func removeCmd(presenter *CliPresenter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name> [step]",
+		Short: "Remove an event action or a single step",
+		Long:  `Removes the whole event action, or only the given step (0-based) when one is specified.`,
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return presenter.HandleRemove(args[0], -1)
+			}
+			index, err := stepIndex(args)
+			if err != nil {
+				return err
+			}
+			return presenter.HandleRemove(args[0], index)
+		},
+	}
+	return cmd
+}
+
+func enableCmd(presenter *CliPresenter, enabled bool) *cobra.Command {
+	use, short := "enable <name>", "Enable an event action"
+	if !enabled {
+		use, short = "disable <name>", "Disable an event action without removing it"
+	}
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return presenter.HandleSetEnabled(args[0], enabled)
+		},
+	}
+}
+
+func runsCmd(presenter *CliPresenter) *cobra.Command {
+	var limit int
+	var verbose bool
+	cmd := &cobra.Command{
+		Use:   "runs [name]",
+		Short: "List recent action runs",
+		Long:  `Lists the latest recorded runs of the named event action or cron job, or of every action, newest first. --verbose adds each run's rendered arguments, output, and error.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return presenter.HandleRuns(name, limit, verbose)
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Number of runs to show")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show arguments, output, and errors")
+	return cmd
+}
+
+func rerunCmd(presenter *CliPresenter) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "rerun <run-id>",
+		Short: "Run a recorded action again",
+		Long:  `Runs the action of a recorded run again with the config and triggering event it was recorded with, waits for it, and prints the result. The new run is recorded too.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid run ID %q", args[0])
+			}
+			return presenter.HandleRerun(id, yes)
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+func stepIndex(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, nil
+	}
+	index, err := strconv.Atoi(args[1])
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid step index %q", args[1])
+	}
+	return index, nil
+}
@@ -0,0 +1,194 @@
+package action
+
+import (
+	"badgermaps/app"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testConfig = `# BadgerMaps settings
+max_concurrent_requests: 5 # keep low for the shared API key
+event_actions:
+    # notify ops when a pull finishes
+    - name: pull.complete
+      event: pull.complete
+      run:
+        - type: exec
+          args:
+            command: echo done
+            use_shell: true
+`
+
+func newTestApp(t *testing.T) *app.App {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	a := app.NewApp()
+	a.State.NoColor = true
+	a.State.Quiet = true
+	a.ConfigFile = path
+	if err := yaml.Unmarshal([]byte(testConfig), a.Config); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	return a
+}
+
+func run(t *testing.T, a *app.App, args ...string) error {
+	t.Helper()
+	cmd := ActionCmd(a)
+	cmd.SetArgs(args)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return cmd.Execute()
+}
+
+func TestActionCommandsRoundTrip(t *testing.T) {
+	a := newTestApp(t)
+
+	if err := run(t, a, "add", "--event", "push.complete", "--type", "db", "--query", "DELETE FROM Staging", "--param", "1"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := run(t, a, "edit", "pull.complete", "--command", "echo finished"); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if err := run(t, a, "disable", "pull.complete"); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+
+	if len(a.Config.EventActions) != 2 {
+		t.Fatalf("expected 2 event actions, got %d", len(a.Config.EventActions))
+	}
+	pull := a.Config.EventActions[0]
+	if !pull.Disabled {
+		t.Fatal("expected pull.complete to be disabled")
+	}
+	if got := pull.Run[0].Args["command"]; got != "echo finished" {
+		t.Fatalf("expected edited command, got %v", got)
+	}
+	pushStep := a.Config.EventActions[1].Run[0]
+	if pushStep.Type != "db" || pushStep.Args["query"] != "DELETE FROM Staging" {
+		t.Fatalf("unexpected db step: %+v", pushStep)
+	}
+
+	data, err := os.ReadFile(a.ConfigFile)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	saved := string(data)
+	for _, comment := range []string{"# BadgerMaps settings", "# keep low for the shared API key", "# notify ops when a pull finishes"} {
+		if !strings.Contains(saved, comment) {
+			t.Errorf("expected saved config to keep %q, got:\n%s", comment, saved)
+		}
+	}
+
+	if err := run(t, a, "enable", "pull.complete"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if err := run(t, a, "remove", "push.complete"); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if len(a.Config.EventActions) != 1 || a.Config.EventActions[0].Disabled {
+		t.Fatalf("unexpected actions after enable/remove: %+v", a.Config.EventActions)
+	}
+}
+
+func TestActionAddStepTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantArgs map[string]interface{}
+		describe string
+	}{
+		{
+			name:     "backup",
+			args:     []string{"--type", "backup", "--path", "backups/{timestamp}.json", "--format", "json"},
+			wantArgs: map[string]interface{}{"path": "backups/{timestamp}.json", "format": "json"},
+			describe: "backups/{timestamp}.json (json)",
+		},
+		{
+			name: "digest",
+			args: []string{"--type", "digest", "--digest-name", "daily", "--path", "digest.txt", "--failures-only",
+				"--email-to", "ops@example.com", "--email-from", "badgermaps@example.com", "--smtp-host", "smtp.example.com", "--smtp-port", "2525"},
+			wantArgs: map[string]interface{}{
+				"name": "daily", "path": "digest.txt", "failures_only": true,
+				"email": map[string]interface{}{
+					"to": []interface{}{"ops@example.com"}, "from": "badgermaps@example.com", "smtp_host": "smtp.example.com", "smtp_port": 2525,
+				},
+			},
+			describe: "daily -> digest.txt, ops@example.com",
+		},
+		{
+			name:     "archive",
+			args:     []string{"--type", "archive", "--months", "18"},
+			wantArgs: map[string]interface{}{"months": 18},
+			describe: "check-ins older than 18 months",
+		},
+		{
+			name:     "followup",
+			args:     []string{"--type", "followup", "--account", "{{payload.AccountID}}", "--set", "follow_up_date=+14d", "--set", "Notes=call back"},
+			wantArgs: map[string]interface{}{"account": "{{payload.AccountID}}", "set": map[string]interface{}{"follow_up_date": "+14d", "Notes": "call back"}},
+			describe: "account {{payload.AccountID}}: Notes=call back, follow_up_date=+14d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			if err := run(t, a, append([]string{"add", "--event", "push.complete"}, tt.args...)...); err != nil {
+				t.Fatalf("add failed: %v", err)
+			}
+			if len(a.Config.EventActions) != 2 {
+				t.Fatalf("expected 2 event actions, got %d", len(a.Config.EventActions))
+			}
+			step := a.Config.EventActions[1].Run[0]
+			if !reflect.DeepEqual(step.Args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", step.Args, tt.wantArgs)
+			}
+			if got := describeStep(step); got != tt.describe {
+				t.Errorf("description = %q, want %q", got, tt.describe)
+			}
+		})
+	}
+}
+
+func TestActionCommandsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "unknown event", args: []string{"add", "--event", "pull.finished", "--type", "exec", "--command", "true"}, want: "unknown event"},
+		{name: "unknown type", args: []string{"add", "--event", "pull.complete", "--type", "ftp"}, want: "unknown action type"},
+		{name: "missing command", args: []string{"add", "--event", "pull.complete", "--type", "exec"}, want: "requires a 'command'"},
+		{name: "wrong flag for type", args: []string{"add", "--event", "pull.complete", "--type", "exec", "--command", "true", "--query", "SELECT 1"}, want: "--query is not supported"},
+		{name: "backup needs a path", args: []string{"add", "--event", "pull.complete", "--type", "backup"}, want: "requires a 'path'"},
+		{name: "exec flag for backup", args: []string{"add", "--event", "pull.complete", "--type", "backup", "--path", "out.db", "--command", "true"}, want: "--command is not supported for backup actions"},
+		{name: "digest needs a destination", args: []string{"add", "--event", "pull.complete", "--type", "digest", "--digest-name", "daily"}, want: "requires a 'path', an 'email'"},
+		{name: "archive needs months", args: []string{"add", "--event", "pull.complete", "--type", "archive", "--dir", "archive"}, want: "requires 'months'"},
+		{name: "followup set needs a value", args: []string{"add", "--event", "webhook.checkin", "--type", "followup", "--set", "follow_up_date"}, want: "must be field=value"},
+		{name: "followup bad offset", args: []string{"add", "--event", "webhook.checkin", "--type", "followup", "--set", "follow_up_date=+2x"}, want: "date offset"},
+		{name: "args need no-shell", args: []string{"add", "--event", "pull.complete", "--type", "exec", "--command", "ls", "--arg", "-l"}, want: "use_shell"},
+		{name: "missing action", args: []string{"disable", "nope"}, want: "not found"},
+		{name: "missing step", args: []string{"edit", "pull.complete", "3", "--command", "true"}, want: "has no step 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			err := run(t, a, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if len(a.Config.EventActions) != 1 || len(a.Config.EventActions[0].Run) != 1 {
+				t.Fatalf("config should be unchanged after a rejected command: %+v", a.Config.EventActions)
+			}
+		})
+	}
+}
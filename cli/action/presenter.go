@@ -0,0 +1,494 @@
+package action
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/action"
+	"badgermaps/events"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	// typeFlags lists the step flags each action type accepts besides
+	// --type and --templates.
+	typeFlags = map[string][]string{
+		"exec":     {"command", "arg", "no-shell"},
+		"db":       {"command", "function", "procedure", "query", "param"},
+		"backup":   {"path", "format"},
+		"archive":  {"months", "dir"},
+		"followup": {"account", "set"},
+		"digest":   append([]string{"digest-name", "path", "format", "failures-only", "lookback"}, emailFlags...),
+	}
+	// emailFlags configure a digest's email delivery.
+	emailFlags = []string{"email-to", "email-from", "email-subject", "smtp-host", "smtp-port", "smtp-username", "smtp-password-env"}
+	// dbTargets are mutually exclusive; setting one clears the others.
+	dbTargets = []string{"command", "function", "procedure", "query"}
+	// stepFlagNames lists every flag that modifies a step rather than the trigger.
+	stepFlagNames = []string{"type", "command", "arg", "no-shell", "function", "procedure", "query", "param", "path", "format", "months", "dir", "account", "set", "digest-name", "failures-only", "lookback",
+		"email-to", "email-from", "email-subject", "smtp-host", "smtp-port", "smtp-username", "smtp-password-env", "templates"}
+)
+
+// CliPresenter handles the presentation logic for the action command.
+type CliPresenter struct {
+	App *app.App
+}
+
+// NewCliPresenter creates a new presenter for the action command.
+func NewCliPresenter(a *app.App) *CliPresenter {
+	return &CliPresenter{App: a}
+}
+
+// HandleList prints every configured event action and its steps.
+func (p *CliPresenter) HandleList(event string) error {
+	if event != "" {
+		if err := validateEvent(event); err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	found := false
+	for _, ea := range p.App.Config.EventActions {
+		if event != "" && ea.Event != event {
+			continue
+		}
+		if !found {
+			fmt.Fprintln(w, "Name\tEvent\tSource\tStatus\tStep\tType\tDetails")
+			found = true
+		}
+		source := ea.Source
+		if source == "" {
+			source = "any"
+		}
+		status := "enabled"
+		if ea.Disabled {
+			status = "disabled"
+		}
+		for i, step := range ea.Run {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", ea.Name, ea.Event, source, status, i, step.Type, describeStep(step))
+		}
+	}
+	if !found {
+		p.App.Events.Dispatch(events.Infof("action", "No event actions configured."))
+	}
+	return nil
+}
+
+// HandleAdd validates and appends a new step for event/source.
+func (p *CliPresenter) HandleAdd(event, source string, flags stepFlags, changed func(string) bool) error {
+	if err := p.requireConfigFile(); err != nil {
+		return err
+	}
+	if err := validateEvent(event); err != nil {
+		return err
+	}
+	step := action.ActionConfig{}
+	if err := applyStepFlags(&step, flags, changed); err != nil {
+		return err
+	}
+	if err := p.App.AddEventAction(event, source, step); err != nil {
+		return err
+	}
+	p.App.Events.Dispatch(events.Infof("action", "Added %s action for event '%s'.", step.Type, event))
+	return nil
+}
+
+// HandleEdit updates the event/source of an event action and the flags that
+// were explicitly given for one of its steps.
+func (p *CliPresenter) HandleEdit(name string, index int, event, source string, flags stepFlags, changed func(string) bool) error {
+	if err := p.requireConfigFile(); err != nil {
+		return err
+	}
+	ea := p.findEventAction(name)
+	if ea == nil {
+		return fmt.Errorf("event action not found: %s", name)
+	}
+	if index >= len(ea.Run) {
+		return fmt.Errorf("event action '%s' has no step %d", name, index)
+	}
+	if changed("event") {
+		if err := validateEvent(event); err != nil {
+			return err
+		}
+	}
+
+	step := action.ActionConfig{Type: ea.Run[index].Type, Args: cloneArgs(ea.Run[index].Args)}
+	if err := applyStepFlags(&step, flags, changed); err != nil {
+		return err
+	}
+
+	if changed("event") || changed("source") {
+		if !changed("event") {
+			event = ea.Event
+		}
+		if !changed("source") {
+			source = ea.Source
+		}
+		if err := p.App.UpdateEventActionTrigger(name, event, source); err != nil {
+			return err
+		}
+	}
+	if firstChanged(changed, stepFlagNames) != "" {
+		if err := p.App.UpdateEventAction(name, index, step); err != nil {
+			return err
+		}
+	}
+	p.App.Events.Dispatch(events.Infof("action", "Updated event action '%s'.", name))
+	return nil
+}
+
+// HandleRemove deletes a whole event action, or a single step when index >= 0.
+func (p *CliPresenter) HandleRemove(name string, index int) error {
+	if err := p.requireConfigFile(); err != nil {
+		return err
+	}
+	var err error
+	if index < 0 {
+		err = p.App.RemoveEventActionByName(name)
+	} else {
+		err = p.App.RemoveEventAction(name, index)
+	}
+	if err != nil {
+		return err
+	}
+	p.App.Events.Dispatch(events.Infof("action", "Removed event action '%s'.", name))
+	return nil
+}
+
+// HandleSetEnabled enables or disables an event action.
+func (p *CliPresenter) HandleSetEnabled(name string, enabled bool) error {
+	if err := p.requireConfigFile(); err != nil {
+		return err
+	}
+	if err := p.App.SetEventActionEnabled(name, enabled); err != nil {
+		return err
+	}
+	state := "enabled"
+	if !enabled {
+		state = "disabled"
+	}
+	p.App.Events.Dispatch(events.Infof("action", "Event action '%s' %s.", name, state))
+	return nil
+}
+
+func (p *CliPresenter) requireConfigFile() error {
+	if p.App.ConfigFile == "" {
+		return fmt.Errorf("no configuration file loaded; run 'badgermaps config' first")
+	}
+	return nil
+}
+
+func (p *CliPresenter) findEventAction(name string) *action.EventAction {
+	for i := range p.App.Config.EventActions {
+		if p.App.Config.EventActions[i].Name == name {
+			return &p.App.Config.EventActions[i]
+		}
+	}
+	return nil
+}
+
+func validateEvent(event string) error {
+	for _, known := range events.AllEventTypes() {
+		if known == event {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown event '%s'; valid events: %s", event, strings.Join(events.AllEventTypes(), ", "))
+}
+
+// applyStepFlags writes the changed flags into step and validates the result.
+func applyStepFlags(step *action.ActionConfig, flags stepFlags, changed func(string) bool) error {
+	if changed("type") && flags.actionType != step.Type {
+		step.Type = flags.actionType
+		step.Args = nil
+	}
+	if step.Args == nil {
+		step.Args = make(map[string]interface{})
+	}
+
+	if name := unsupportedFlag(step.Type, changed); name != "" {
+		return fmt.Errorf("--%s is not supported for %s actions", name, step.Type)
+	}
+
+	switch step.Type {
+	case "exec":
+		if changed("command") {
+			step.Args["command"] = flags.command
+		}
+		if changed("arg") {
+			step.Args["args"] = toInterfaces(flags.args)
+		}
+		if changed("no-shell") {
+			step.Args["use_shell"] = !flags.noShell
+		} else if _, ok := step.Args["use_shell"]; !ok {
+			step.Args["use_shell"] = true
+		}
+	case "db":
+		values := map[string]string{
+			"command":   flags.command,
+			"function":  flags.function,
+			"procedure": flags.procedure,
+			"query":     flags.query,
+		}
+		if target := firstChanged(changed, dbTargets); target != "" {
+			for _, key := range dbTargets {
+				delete(step.Args, key)
+			}
+			step.Args[target] = values[target]
+		}
+		if changed("param") {
+			step.Args["args"] = toInterfaces(flags.params)
+		}
+	case "backup":
+		if changed("path") {
+			step.Args["path"] = flags.path
+		}
+		if changed("format") {
+			setOrDelete(step.Args, "format", flags.format)
+		}
+	case "archive":
+		if changed("months") {
+			step.Args["months"] = flags.months
+		}
+		if changed("dir") {
+			setOrDelete(step.Args, "dir", flags.dir)
+		}
+	case "followup":
+		if changed("account") {
+			setOrDelete(step.Args, "account", flags.account)
+		}
+		if changed("set") {
+			set, err := parseAssignments(flags.set)
+			if err != nil {
+				return err
+			}
+			step.Args["set"] = set
+		}
+	case "digest":
+		if changed("digest-name") {
+			step.Args["name"] = flags.digestName
+		}
+		if changed("path") {
+			setOrDelete(step.Args, "path", flags.path)
+		}
+		if changed("format") {
+			setOrDelete(step.Args, "format", flags.format)
+		}
+		if changed("failures-only") {
+			step.Args["failures_only"] = flags.failuresOnly
+		}
+		if changed("lookback") {
+			setOrDelete(step.Args, "lookback", flags.lookback)
+		}
+		if firstChanged(changed, emailFlags) != "" {
+			applyEmailFlags(step.Args, flags, changed)
+		}
+	}
+	if changed("templates") {
+		if flags.templates == "" || flags.templates == action.TemplateModeLenient {
+			delete(step.Args, "templates")
+		} else {
+			step.Args["templates"] = flags.templates
+		}
+	}
+
+	instance, err := action.NewActionFromConfig(*step)
+	if err != nil {
+		return err
+	}
+	return instance.Validate()
+}
+
+// parseAssignments reads repeated --set field=value flags.
+func parseAssignments(values []string) (map[string]interface{}, error) {
+	set := make(map[string]interface{}, len(values))
+	for _, value := range values {
+		field, v, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(field) == "" {
+			return nil, fmt.Errorf("--set %q must be field=value", value)
+		}
+		set[strings.TrimSpace(field)] = v
+	}
+	return set, nil
+}
+
+// applyEmailFlags merges the changed email flags into the digest's email
+// settings. Clearing --email-to removes email delivery.
+func applyEmailFlags(args map[string]interface{}, flags stepFlags, changed func(string) bool) {
+	email := nestedArgs(args["email"])
+	if changed("email-to") {
+		email["to"] = toInterfaces(flags.emailTo)
+	}
+	values := map[string]string{
+		"email-from":        flags.emailFrom,
+		"email-subject":     flags.emailSubject,
+		"smtp-host":         flags.smtpHost,
+		"smtp-username":     flags.smtpUsername,
+		"smtp-password-env": flags.smtpPasswordEnv,
+	}
+	keys := map[string]string{
+		"email-from":        "from",
+		"email-subject":     "subject",
+		"smtp-host":         "smtp_host",
+		"smtp-username":     "username",
+		"smtp-password-env": "password_env",
+	}
+	for name, value := range values {
+		if changed(name) {
+			setOrDelete(email, keys[name], value)
+		}
+	}
+	if changed("smtp-port") {
+		if flags.smtpPort > 0 {
+			email["smtp_port"] = flags.smtpPort
+		} else {
+			delete(email, "smtp_port")
+		}
+	}
+	if to, ok := email["to"].([]interface{}); ok && len(to) == 0 {
+		delete(email, "to")
+	}
+	if _, ok := email["to"]; !ok {
+		delete(args, "email")
+		return
+	}
+	args["email"] = email
+}
+
+// nestedArgs copies a nested args map as decoded from YAML, which may use
+// either string or interface{} keys, so it can be edited.
+func nestedArgs(value interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	switch m := value.(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			out[k] = v
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+	}
+	return out
+}
+
+// unsupportedFlag returns the first changed step flag that actionType does
+// not accept. Unknown types are left to NewActionFromConfig to report.
+func unsupportedFlag(actionType string, changed func(string) bool) string {
+	accepted, ok := typeFlags[actionType]
+	if !ok {
+		return ""
+	}
+	for _, name := range stepFlagNames {
+		if name == "type" || name == "templates" || !changed(name) {
+			continue
+		}
+		if !slices.Contains(accepted, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// setOrDelete stores value under key, or removes the key when value is
+// empty so the action's default applies.
+func setOrDelete(args map[string]interface{}, key, value string) {
+	if value == "" {
+		delete(args, key)
+		return
+	}
+	args[key] = value
+}
+
+func firstChanged(changed func(string) bool, names []string) string {
+	for _, name := range names {
+		if changed(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func toInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func cloneArgs(args map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+	return out
+}
+
+func describeStep(step action.ActionConfig) string {
+	switch step.Type {
+	case "exec":
+		parts := []string{fmt.Sprint(step.Args["command"])}
+		if args, ok := step.Args["args"].([]interface{}); ok {
+			for _, arg := range args {
+				parts = append(parts, fmt.Sprint(arg))
+			}
+		}
+		return strings.Join(parts, " ")
+	case "db":
+		for _, key := range dbTargets {
+			if value, ok := step.Args[key].(string); ok && value != "" {
+				return fmt.Sprintf("%s: %s", key, strings.Join(strings.Fields(value), " "))
+			}
+		}
+	case "backup":
+		return withFormat(fmt.Sprint(step.Args["path"]), step.Args["format"])
+	case "archive":
+		description := fmt.Sprintf("check-ins older than %v months", step.Args["months"])
+		if dir, ok := step.Args["dir"].(string); ok && dir != "" {
+			description += " -> " + dir
+		}
+		return description
+	case "followup":
+		set := nestedArgs(step.Args["set"])
+		fields := make([]string, 0, len(set))
+		for field := range set {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for i, field := range fields {
+			fields[i] = fmt.Sprintf("%s=%v", field, set[field])
+		}
+		description := strings.Join(fields, ", ")
+		if account, ok := step.Args["account"].(string); ok && account != "" {
+			description = fmt.Sprintf("account %s: %s", account, description)
+		}
+		return description
+	case "digest":
+		var targets []string
+		if path, ok := step.Args["path"].(string); ok && path != "" {
+			targets = append(targets, path)
+		}
+		if to, ok := nestedArgs(step.Args["email"])["to"].([]interface{}); ok {
+			for _, address := range to {
+				targets = append(targets, fmt.Sprint(address))
+			}
+		}
+		return withFormat(fmt.Sprintf("%v -> %s", step.Args["name"], strings.Join(targets, ", ")), step.Args["format"])
+	}
+	return ""
+}
+
+// withFormat appends a non-default output format to a step description.
+func withFormat(description string, format interface{}) string {
+	if value, ok := format.(string); ok && value != "" {
+		return fmt.Sprintf("%s (%s)", description, value)
+	}
+	return description
+}
@@ -0,0 +1,92 @@
+package action
+
+import (
+	"badgermaps/database"
+	"badgermaps/utils"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// HandleRuns prints the latest runs of the action named name, or of every
+// action when name is empty.
+func (p *CliPresenter) HandleRuns(name string, limit int, verbose bool) error {
+	runs, err := p.App.ActionRuns(name, limit)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No action runs recorded.")
+		return nil
+	}
+	p.printRuns(os.Stdout, runs, verbose)
+	return nil
+}
+
+// HandleRerun runs the action recorded as id again and prints the new run.
+func (p *CliPresenter) HandleRerun(id int, yes bool) error {
+	if !yes {
+		if p.App.State.NoInput {
+			return fmt.Errorf("re-running an action runs its command or query again; pass --yes to confirm when --no-input is set")
+		}
+		reader := bufio.NewReader(os.Stdin)
+		if !utils.PromptBool(reader, fmt.Sprintf("Run the action of run %d again?", id), false) {
+			fmt.Println("Re-run cancelled.")
+			return nil
+		}
+	}
+	run, err := p.App.RerunAction(id)
+	if run.ActionType != "" {
+		p.printRuns(os.Stdout, []database.ActionRun{run}, true)
+	}
+	return err
+}
+
+func (p *CliPresenter) printRuns(out io.Writer, runs []database.ActionRun, verbose bool) {
+	loc := p.App.DisplayLocation()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTime\tAction\tType\tTrigger\tStatus\tDuration\tResult")
+	for _, run := range runs {
+		name := run.ActionName
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", run.RunId, run.StartedAt.In(loc).Format("2006-01-02 15:04:05"),
+			name, run.ActionType, run.TriggeredBy, run.Status, run.Duration.Round(time.Millisecond), runResult(run))
+	}
+	w.Flush()
+	if !verbose {
+		return
+	}
+	for _, run := range runs {
+		fmt.Fprintf(out, "\nRun %d", run.RunId)
+		if run.RerunOf > 0 {
+			fmt.Fprintf(out, " (re-run of %d)", run.RerunOf)
+		}
+		fmt.Fprintln(out, ":")
+		for _, field := range []struct{ label, text string }{
+			{"Arguments", run.Args},
+			{"Output", run.Output},
+			{"Error", run.ErrorMessage},
+		} {
+			if text := strings.TrimSpace(field.text); text != "" {
+				fmt.Fprintf(out, "  %s:\n    %s\n", field.label, strings.ReplaceAll(text, "\n", "\n    "))
+			}
+		}
+	}
+}
+
+// runResult is the exit code or row count of a run, when there is one.
+func runResult(run database.ActionRun) string {
+	switch {
+	case run.ExitCode != nil:
+		return fmt.Sprintf("exit %d", *run.ExitCode)
+	case run.RowsAffected != nil && *run.RowsAffected >= 0:
+		return fmt.Sprintf("%d rows", *run.RowsAffected)
+	}
+	return "-"
+}
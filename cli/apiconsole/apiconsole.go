@@ -0,0 +1,264 @@
+package apiconsole
+
+import (
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/utils"
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// APICmd creates the api command, a console for sending requests to the
+// BadgerMaps API by hand.
+func APICmd(App *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "api",
+		Short: "Send requests to the BadgerMaps API by hand",
+		Long: `A console for the BadgerMaps API: list the endpoints this tool knows with
+their parameters, send GET, POST, PATCH, and DELETE requests with the
+configured API key, and save requests as templates to send again.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(endpointsCmd())
+	cmd.AddCommand(callCmd(App))
+	cmd.AddCommand(templatesCmd(App))
+	return cmd
+}
+
+func endpointsCmd() *cobra.Command {
+	var params bool
+	cmd := &cobra.Command{
+		Use:   "endpoints [filter]",
+		Short: "List the known API endpoints",
+		Long: `Lists the endpoints with their methods. A filter keeps the paths that start
+with it, such as 'customers/42', or else those that mention it. --params also
+lists each endpoint's path, query, and form parameters.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+			endpoints := api.MatchEndpoints(filter)
+			if len(endpoints) == 0 {
+				return fmt.Errorf("no known endpoint matches %q", filter)
+			}
+			return writeEndpoints(cmd.OutOrStdout(), endpoints, params)
+		},
+	}
+	cmd.Flags().BoolVar(&params, "params", false, "Also list each endpoint's parameters")
+	return cmd
+}
+
+func writeEndpoints(out io.Writer, endpoints []api.EndpointInfo, params bool) error {
+	c := utils.Colors
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Methods\tPath\tDescription")
+	for _, e := range endpoints {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", strings.Join(e.Methods, ","), e.Path, e.Description)
+		if !params {
+			continue
+		}
+		for _, p := range e.Params {
+			name := p.Name
+			if p.Required {
+				name += " (required)"
+			}
+			fmt.Fprintf(w, "\t%s\t%s\n", c.Gray("  %s: %s", p.In, name), c.Gray("%s", p.Description))
+		}
+	}
+	return w.Flush()
+}
+
+func callCmd(App *app.App) *cobra.Command {
+	var (
+		query, form    []string
+		template, save string
+		include, raw   bool
+		yes            bool
+	)
+	cmd := &cobra.Command{
+		Use:   "call [method] [path]",
+		Short: "Send a request to the API",
+		Long: `Sends a request to a path relative to the configured API URL, with the API
+key, and prints the response, indenting JSON. Query parameters are given with
+--query and form fields, sent URL-encoded with POST and PATCH, with --form.
+--template sends a saved request; the method, path, and parameters given
+override the saved ones. --save saves the request under a name.
+
+POST, PATCH, and DELETE change data in BadgerMaps and ask first unless --yes
+is given. Paths complete from the known endpoints in shells with completion
+set up; see 'api endpoints'.`,
+		Example: `  badgermaps api call GET customers/42/
+  badgermaps api call GET appointments/ --query customer_id=42 --save "Acme check-ins"
+  badgermaps api call PATCH customers/42/ --form phone_number=555-0100
+  badgermaps api call --template "Acme check-ins"`,
+		Args: cobra.MaximumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return api.ConsoleMethods, cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				var paths []string
+				for _, e := range api.MatchEndpoints(toComplete) {
+					if e.Allows(args[0]) {
+						paths = append(paths, e.Path+"\t"+e.Description)
+					}
+				}
+				return paths, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req api.ConsoleRequest
+			if template != "" {
+				saved, err := App.APITemplate(template)
+				if err != nil {
+					return err
+				}
+				req = saved.Request()
+			}
+			if len(args) > 0 {
+				req.Method = args[0]
+			}
+			if len(args) > 1 {
+				req.Path = args[1]
+			}
+			if req.Method == "" || req.Path == "" {
+				return fmt.Errorf("give a method and a path, or --template")
+			}
+			req.Method = strings.ToUpper(req.Method)
+			if err := mergeParams(&req.Query, query); err != nil {
+				return fmt.Errorf("--query: %w", err)
+			}
+			if err := mergeParams(&req.Form, form); err != nil {
+				return fmt.Errorf("--form: %w", err)
+			}
+			cmd.SilenceUsage = true
+
+			if req.Method != "GET" && !yes {
+				if App.State.NoInput {
+					return fmt.Errorf("%s changes data in BadgerMaps; pass --yes to send it when --no-input is set", req.Method)
+				}
+				reader := bufio.NewReader(os.Stdin)
+				if !utils.PromptBool(reader, fmt.Sprintf("Send %s %s to BadgerMaps?", req.Method, req.Path), false) {
+					fmt.Fprintln(cmd.OutOrStdout(), "Request cancelled.")
+					return nil
+				}
+			}
+			resp, err := App.CallAPI(req)
+			if err != nil {
+				return err
+			}
+			writeResponse(cmd.OutOrStdout(), resp, include, raw)
+			if save != "" {
+				if err := App.SaveAPITemplate(app.NewAPIRequestTemplate(save, req)); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Saved the request as %q.\n", save)
+			}
+			if resp.StatusCode >= 400 {
+				return fmt.Errorf("the API answered %s", resp.Status)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVarP(&query, "query", "q", nil, "Query parameter as key=value (repeatable)")
+	cmd.Flags().StringArrayVarP(&form, "form", "f", nil, "Form field as key=value (repeatable)")
+	cmd.Flags().StringVarP(&template, "template", "t", "", "Send the saved request with this name")
+	cmd.Flags().StringVar(&save, "save", "", "Save the request under this name")
+	cmd.Flags().BoolVarP(&include, "include", "i", false, "Print the status line and response headers")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the body as received, without indenting JSON")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Send POST, PATCH, and DELETE requests without asking")
+	cmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, t := range App.APITemplates() {
+			names = append(names, t.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// mergeParams adds key=value pairs to values, replacing saved values of
+// the same keys.
+func mergeParams(values *url.Values, pairs []string) error {
+	parsed, err := app.ParseAPIParams(pairs)
+	if err != nil || len(parsed) == 0 {
+		return err
+	}
+	if *values == nil {
+		*values = url.Values{}
+	}
+	for key, vals := range parsed {
+		(*values)[key] = vals
+	}
+	return nil
+}
+
+func writeResponse(out io.Writer, resp *api.ConsoleResponse, include, raw bool) {
+	c := utils.Colors
+	if include {
+		status := c.Green("%s", resp.Status)
+		if resp.StatusCode >= 400 {
+			status = c.Red("%s", resp.Status)
+		}
+		fmt.Fprintf(out, "%s %s\n%s\n", resp.Method, resp.URL, status)
+		for _, name := range resp.SortedHeaderNames() {
+			fmt.Fprintf(out, "%s\n", c.Gray("%s: %s", name, strings.Join(resp.Headers[name], ", ")))
+		}
+		fmt.Fprintln(out)
+	}
+	body := resp.PrettyBody()
+	if raw {
+		body = string(resp.Body)
+	}
+	fmt.Fprintln(out, body)
+}
+
+func templatesCmd(App *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "List the saved API requests",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			templates := App.APITemplates()
+			out := cmd.OutOrStdout()
+			if len(templates) == 0 {
+				fmt.Fprintln(out, "No saved requests. Save one with 'api call ... --save <name>'.")
+				return
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Name\tMethod\tPath\tParameters")
+			for _, t := range templates {
+				req := t.Request()
+				params := strings.TrimSpace(strings.ReplaceAll(app.FormatAPIParams(req.Query)+"\n"+app.FormatAPIParams(req.Form), "\n", " "))
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, t.Method, t.Path, params)
+			}
+			w.Flush()
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a saved API request",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := App.DeleteAPITemplate(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted %q.\n", args[0])
+			return nil
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,112 @@
+package archive
+
+import (
+	"badgermaps/app"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// ArchiveCmd creates the archive command for moving old check-ins out of
+// the database and back.
+func ArchiveCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Archive and restore old check-ins",
+		Long: `Moves check-ins older than a number of months to compressed files in the archive
+directory (archive.dir, by default "archive" next to the config file) and brings
+them back when they are needed. Monthly counts stay available while archived.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(runCmd(a))
+	cmd.AddCommand(restoreCmd(a))
+	cmd.AddCommand(listCmd(a))
+	return cmd
+}
+
+func runCmd(a *app.App) *cobra.Command {
+	var months int
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Archive check-ins older than the configured number of months",
+		Long: `Archives check-ins from before the last --months whole months, or
+archive.checkin_months from the config. Check-ins with unsent changes are kept.
+Pulls skip check-ins from archived months until they are restored.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.ConfirmProduction("archive run"); err != nil {
+				return err
+			}
+			result, err := a.ArchiveCheckins(months)
+			if err != nil {
+				return err
+			}
+			if result.Checkins == 0 {
+				fmt.Println("Nothing to archive.")
+				return nil
+			}
+			fmt.Printf("Archived %d check-ins from %d months (%s to %s).\n", result.Checkins, len(result.Months), result.Months[0], result.Months[len(result.Months)-1])
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&months, "months", 0, "Keep this many recent whole months in the database (default archive.checkin_months)")
+	return cmd
+}
+
+func restoreCmd(a *app.App) *cobra.Command {
+	var monthRange string
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore archived check-ins to the database",
+		Long: `Writes the archived check-ins for --range back into the database and removes
+those months from the archive. The range is one month (2023-04) or an
+inclusive span (2023-01..2023-06).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := a.RestoreArchivedCheckins(monthRange)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Restored %d check-ins from %d months.\n", result.Checkins, len(result.Months))
+			if result.Skipped > 0 {
+				fmt.Printf("%d check-ins could not be restored.\n", result.Skipped)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&monthRange, "range", "", "Months to restore: YYYY-MM or YYYY-MM..YYYY-MM")
+	cmd.MarkFlagRequired("range")
+	return cmd
+}
+
+func listCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List archived months",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := a.CheckinArchiveIndex()
+			if err != nil {
+				return err
+			}
+			months := index.SortedMonths()
+			if len(months) == 0 {
+				fmt.Println("No archived check-ins.")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Month\tCheck-ins\tAccounts\tArchived")
+			for _, month := range months {
+				m := index.Months[month]
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", month, m.Count, len(m.ByAccount), m.ArchivedAt.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Fprintf(w, "Total\t%d\t\t\n", index.Count())
+			return w.Flush()
+		},
+	}
+}
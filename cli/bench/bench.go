@@ -0,0 +1,114 @@
+package bench
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/bench"
+	"badgermaps/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// BenchCmd creates the bench command, which measures API latency, database
+// throughput, and pull speed to diagnose slow environments.
+func BenchCmd(App *app.App) *cobra.Command {
+	var opts bench.Options
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure API latency, database throughput, and pull speed",
+		Long: `Time requests to the BadgerMaps API (profile, account list, and a sample of
+accounts), inserts and merges into the configured database, and a pull served
+by a local stand-in for the API, then compare each result with a baseline from
+a typical setup. A result more than twice as slow as its baseline is marked
+slow.
+
+The database benchmarks write temporary accounts with negative IDs and delete
+them when they finish.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := bench.Run(App, opts)
+			if report != nil {
+				out := cmd.OutOrStdout()
+				if asJSON {
+					enc := json.NewEncoder(out)
+					enc.SetIndent("", "  ")
+					if encErr := enc.Encode(report); encErr != nil {
+						return encErr
+					}
+				} else {
+					writeReport(out, *report)
+				}
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.Samples, "samples", bench.DefaultSamples, "Number of times each API request is timed")
+	cmd.Flags().IntVar(&opts.Rows, "rows", bench.DefaultRows, "Number of accounts written by the database and pull benchmarks")
+	cmd.Flags().BoolVar(&opts.SkipAPI, "skip-api", false, "Do not send requests to the BadgerMaps API")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the results as JSON")
+	return cmd
+}
+
+func writeReport(out io.Writer, r bench.Report) {
+	c := utils.Colors
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Bold("Benchmark"), c.Bold("Result"), c.Bold("Baseline"), c.Bold("Verdict"))
+	for _, result := range r.Results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Label, "-", formatValue(result.Baseline, result.Unit), c.Red("failed: %s", result.Error))
+			continue
+		}
+		value := formatValue(result.Value, result.Unit)
+		if result.Unit == bench.UnitMilliseconds && result.Count > 1 {
+			value = fmt.Sprintf("%s (%.0f-%.0f)", value, result.Min, result.Max)
+		}
+		verdict := c.Green("ok")
+		if result.Slow() {
+			verdict = c.Red("slow (%.1fx baseline)", result.Ratio())
+		} else if result.Baseline == 0 {
+			verdict = c.Gray("no baseline")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Label, value, formatValue(result.Baseline, result.Unit), verdict)
+	}
+	w.Flush()
+
+	if slow := r.Slow(); len(slow) > 0 {
+		fmt.Fprintln(out)
+		shown := make(map[string]bool)
+		for _, result := range slow {
+			if h := hint(result.Name, r.DBType); !shown[h] {
+				shown[h] = true
+				fmt.Fprintln(out, h)
+			}
+		}
+	}
+}
+
+func formatValue(value float64, unit string) string {
+	if value == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f %s", value, unit)
+}
+
+// hint suggests where to look when a benchmark is slow.
+func hint(name, dbType string) string {
+	switch name {
+	case "api.profile", "api.accounts", "api.account":
+		return "API requests are slow: check the network path to the API, proxies, and requests_per_second."
+	case "db.insert", "db.merge":
+		if dbType == "sqlite3" {
+			return "Database writes are slow: check that the SQLite file is on a local disk, not a network share."
+		}
+		return fmt.Sprintf("Database writes are slow: check the latency to the %s server and its load.", dbType)
+	case "pull.simulated":
+		return "Pulls are slow even without the network: check pull processors and max_concurrent_requests."
+	}
+	return name + " is slow."
+}
@@ -0,0 +1,818 @@
+package db
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+)
+
+// DbCmd creates the db command for local database maintenance.
+func DbCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the local database",
+		Long:  `Back up, restore, encrypt, and check the integrity of the database configured for BadgerMapsSync.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(backupCmd(a))
+	cmd.AddCommand(restoreCmd(a))
+	cmd.AddCommand(migrateCmd(a))
+	cmd.AddCommand(migrateToCmd(a))
+	cmd.AddCommand(fsckCmd(a))
+	cmd.AddCommand(checkTimesCmd(a))
+	cmd.AddCommand(encryptCmd(a))
+	cmd.AddCommand(rekeyCmd(a))
+	cmd.AddCommand(encryptColumnsCmd(a))
+	cmd.AddCommand(decryptColumnsCmd(a))
+	cmd.AddCommand(captureCmd(a))
+	cmd.AddCommand(statsCmd(a))
+	cmd.AddCommand(remapsCmd(a))
+	cmd.AddCommand(docsCmd(a))
+	cmd.AddCommand(unlockCmd(a))
+	cmd.AddCommand(scoresCmd(a))
+	cmd.AddCommand(exportCmd(a))
+	return cmd
+}
+
+func backupCmd(a *app.App) *cobra.Command {
+	var out, format string
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write a backup of the database",
+		Long: `Writes a consistent backup of the database. SQLite databases are copied as a
+database file after a WAL checkpoint; PostgreSQL and SQL Server databases are
+exported as a portable JSON dump that can be restored into any database type.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "" && format != database.BackupFormatSQLite && format != database.BackupFormatJSON {
+				return fmt.Errorf("--format must be %q or %q", database.BackupFormatSQLite, database.BackupFormatJSON)
+			}
+			return a.BackupDatabase(out, format)
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Path of the backup file to write")
+	cmd.Flags().StringVar(&format, "format", "", "Backup format: sqlite (SQLite only) or json (default depends on database type)")
+	cmd.MarkFlagRequired("out")
+	return cmd
+}
+
+func restoreCmd(a *app.App) *cobra.Command {
+	var in string
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Replace the database with a backup",
+		Long:  `Replaces all data in the configured database with the contents of a backup created by 'db backup'.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stat(in); err != nil {
+				return fmt.Errorf("cannot read backup: %w", err)
+			}
+			if err := a.ConfirmProduction("db restore"); err != nil {
+				return err
+			}
+			if !yes {
+				if a.State.NoInput {
+					return fmt.Errorf("restore replaces all local data; pass --yes to confirm when --no-input is set")
+				}
+				reader := bufio.NewReader(os.Stdin)
+				if !utils.PromptBool(reader, fmt.Sprintf("Replace all local data with %s?", in), false) {
+					fmt.Println("Restore cancelled.")
+					return nil
+				}
+			}
+			return a.RestoreDatabase(in)
+		},
+	}
+	cmd.Flags().StringVarP(&in, "in", "i", "", "Path of the backup file to restore")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.MarkFlagRequired("in")
+	return cmd
+}
+
+func migrateCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Add missing columns, tables, and views",
+		Long: `Brings the database schema up to date by adding the columns newer versions
+added to existing tables and creating the tables and views this version
+expects, keeping existing data. Pull and push refuse to run while a
+migration is pending unless --force is passed. Tables that lack other
+columns cannot be migrated in place; back up the database, re-initialize the
+schema with 'badgermaps config', and restore the backup.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.ConfirmProduction("db migrate"); err != nil {
+				return err
+			}
+			if err := a.MigrateSchema(); err != nil {
+				return err
+			}
+			fmt.Println("Database schema is up to date.")
+			return nil
+		},
+	}
+}
+
+func migrateToCmd(a *app.App) *cobra.Command {
+	var target database.DBConfig
+	var yes, noSwitch bool
+	cmd := &cobra.Command{
+		Use:   "migrate-to",
+		Short: "Copy the database to PostgreSQL or SQL Server and switch to it",
+		Long: `Creates the schema in the target database, copies every table from the
+configured database into it table by table, and compares the row count and a
+checksum of each table on both sides. When they all match the config is
+switched to the target, in the active environment when there is one; pass
+--no-switch to only copy. The source is never changed, so it stays a fallback
+until it is deleted. Data already in the target is replaced. The password is
+prompted for unless --password is given.`,
+		Example: `  badgermaps db migrate-to --target postgres --host db.example.com --database badgermaps --user sync`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target.Port == 0 {
+				switch strings.ToLower(target.Type) {
+				case "postgres":
+					target.Port = 5432
+				case "mssql":
+					target.Port = 1433
+				}
+			}
+			reader := bufio.NewReader(os.Stdin)
+			if target.Password == "" && !a.State.NoInput && target.Auth != "windows" && target.Auth != "azure-ad" {
+				password, err := utils.PromptSecret(reader, fmt.Sprintf("Password for %s on %s", target.Username, target.Host))
+				if err != nil {
+					return err
+				}
+				target.Password = password
+			}
+			if err := a.ConfirmProduction("db migrate-to"); err != nil {
+				return err
+			}
+			dst, accounts, err := a.OpenMigrationTarget(target)
+			if err != nil {
+				return err
+			}
+			if accounts > 0 && !yes {
+				if a.State.NoInput {
+					dst.Close()
+					return fmt.Errorf("the target already holds %d accounts, which the copy replaces; pass --yes to confirm when --no-input is set", accounts)
+				}
+				if !utils.PromptBool(reader, fmt.Sprintf("The target already holds %d accounts. Replace all of its data?", accounts), false) {
+					dst.Close()
+					fmt.Println("Migration cancelled.")
+					return nil
+				}
+			}
+
+			var bar *progressbar.ProgressBar
+			cancel := a.Events.SubscribeCancelable("db.migrate.progress", func(e events.Event) {
+				payload, ok := e.Payload.(events.ProgressPayload)
+				if !ok {
+					return
+				}
+				if bar == nil || payload.Processed == 0 {
+					bar = progressbar.NewOptions(payload.Total,
+						progressbar.OptionSetDescription(payload.Entity),
+						progressbar.OptionSetWriter(utils.ProgressWriter(a.State)),
+						progressbar.OptionClearOnFinish(),
+					)
+				}
+				bar.Set(payload.Processed)
+				bar.Describe(payload.Summary())
+				if payload.Done {
+					bar.Finish()
+				}
+			})
+			migration, err := a.MigrateDatabaseTo(dst, target, !noSwitch)
+			cancel()
+			if migration != nil && len(migration.Tables) > 0 {
+				out := cmd.OutOrStdout()
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TABLE\tSOURCE ROWS\tTARGET ROWS\tCHECKSUM")
+				for _, table := range migration.Tables {
+					checksum := "match"
+					if table.SourceChecksum != table.TargetChecksum {
+						checksum = fmt.Sprintf("differs (%s vs %s)", table.SourceChecksum, table.TargetChecksum)
+					}
+					fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", table.Table, table.SourceRows, table.TargetRows, checksum)
+				}
+				w.Flush()
+			}
+			if err != nil {
+				return err
+			}
+			if migration.Switched {
+				fmt.Printf("Copied %d rows; the config now uses the %s database.\n", migration.Rows(), migration.Target.Type)
+			} else {
+				fmt.Printf("Copied %d rows; the config was left as it was.\n", migration.Rows())
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&target.Type, "target", "", "Target database type: "+strings.Join(app.MigrateTargetTypes, " or "))
+	cmd.Flags().StringVar(&target.Host, "host", "localhost", "Target database host")
+	cmd.Flags().IntVar(&target.Port, "port", 0, "Target database port (default 5432 for postgres, 1433 for mssql)")
+	cmd.Flags().StringVar(&target.Database, "database", "badgermaps", "Target database name")
+	cmd.Flags().StringVar(&target.Username, "user", "", "Target database user")
+	cmd.Flags().StringVar(&target.Password, "password", "", "Target database password (prompted for when empty)")
+	cmd.Flags().StringVar(&target.Schema, "schema", "", "Schema for the app's objects in the target")
+	cmd.Flags().StringVar(&target.SSLMode, "ssl-mode", "", "Target SSL mode")
+	cmd.Flags().StringVar(&target.Auth, "auth", "", "SQL Server authentication: sql, windows or azure-ad")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Replace data already in the target without asking")
+	cmd.Flags().BoolVar(&noSwitch, "no-switch", false, "Copy and verify without switching the config to the target")
+	cmd.MarkFlagRequired("target")
+	return cmd
+}
+
+func fsckCmd(a *app.App) *cobra.Command {
+	var fetch, remove bool
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Find and repair orphaned rows",
+		Long: `Checks for account locations, check-ins, and route waypoints that reference
+accounts or routes missing from the local database, which can happen after an
+interrupted pull. Without flags the problems are only reported. --fetch pulls
+the missing parents from the API; --delete removes orphans that remain.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := pull.CheckIntegrity(a)
+			if err != nil {
+				return err
+			}
+			if len(report.Orphans) == 0 {
+				fmt.Println("No orphaned rows found.")
+				return nil
+			}
+			printIntegrityReport(report)
+			if !fetch && !remove {
+				fmt.Println("\nRun with --fetch to pull the missing parents or --delete to remove the orphans.")
+				return nil
+			}
+
+			if remove {
+				if err := a.ConfirmProduction("db fsck --delete"); err != nil {
+					return err
+				}
+			}
+			result, err := pull.RepairIntegrity(a, report, pull.RepairOptions{FetchParents: fetch, DeleteOrphans: remove})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\nFetched %d missing parents (%d failed), deleted %d orphaned rows.\n", result.ParentsFetched, result.FetchFailures, result.RowsDeleted)
+			if len(result.Remaining.Orphans) > 0 {
+				fmt.Println("Remaining problems:")
+				printIntegrityReport(result.Remaining)
+				return fmt.Errorf("%d orphaned rows remain", len(result.Remaining.Orphans))
+			}
+			fmt.Println("Database is consistent.")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&fetch, "fetch", false, "Fetch missing accounts and routes from the API")
+	cmd.Flags().BoolVar(&remove, "delete", false, "Delete orphaned rows that cannot be resolved")
+	return cmd
+}
+
+func checkTimesCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-times",
+		Short: "Find follow-up dates and appointment times with timezone problems",
+		Long: `Checks stored follow-up dates and route appointment times against the display
+timezone (display_timezone, or local time). It reports values that cannot be
+read, follow-up dates that carry a time and show on another day, and
+appointment times without an offset, including those skipped or repeated by a
+DST change. The next pull stores follow-up dates as YYYY-MM-DD and appointment
+times in UTC.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issues, err := a.CheckDateTimes()
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				fmt.Printf("No date or time problems found (display timezone %s).\n", a.DisplayLocation())
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Table\tID\tField\tValue\tProblem")
+			for _, issue := range issues {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", issue.Table, issue.ID, issue.Field, issue.Value, issue.Problem)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func statsCmd(a *app.App) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show row counts and approximate sizes of every table",
+		Long: `Lists each table with its row count and approximate size, largest first.
+PostgreSQL and SQL Server report each table with its indexes. A SQLite file
+is shared among its tables by how much data each stores. For SQLite the
+command also warns when the file nears max_page_count, the 4 GiB FAT32 file
+limit, or the free space on its drive.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.DB == nil || !a.DB.IsConnected() {
+				return fmt.Errorf("database is not connected")
+			}
+			report, err := database.GetStorageReport(a.DB)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Table\tRows\tSize")
+			for _, table := range report.Tables {
+				fmt.Fprintf(w, "%s\t%d\t%s\n", table.Name, table.Rows, database.FormatBytes(table.Bytes))
+			}
+			fmt.Fprintf(w, "Total\t%d\t%s\n", report.TotalRows, database.FormatBytes(report.TotalBytes))
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			for _, warning := range report.Warnings {
+				fmt.Fprintf(cmd.OutOrStdout(), "\nWarning: %s\n", warning)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	return cmd
+}
+
+func exportCmd(a *app.App) *cobra.Command {
+	var out string
+	var opts app.CSVExportConfig
+	cmd := &cobra.Command{
+		Use:   "export <table>",
+		Short: "Export a table or view as CSV",
+		Long: `Writes every row of a table or view as CSV, to stdout or --output. The options
+default to csv_export in the config, which the GUI's Export Page also uses.
+For Excel in locales with a decimal comma use --delimiter semicolon --bom;
+--encoding utf-16le also opens correctly in older Excel versions. Accounts
+leave out custom fields that are not pulled and show encrypted columns
+decrypted when the key is available.`,
+		Example: `  badgermaps db export Accounts -o accounts.csv --delimiter semicolon --bom
+  badgermaps db export AccountCheckins --date-format eu`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := a.CSVExport()
+			flags := cmd.Flags()
+			if flags.Changed("delimiter") {
+				c.Delimiter = opts.Delimiter
+			}
+			if flags.Changed("encoding") {
+				c.Encoding = opts.Encoding
+			}
+			if flags.Changed("bom") {
+				c.BOM = opts.BOM
+			}
+			if flags.Changed("quote-headers") {
+				c.QuoteHeaders = opts.QuoteHeaders
+			}
+			if flags.Changed("date-format") {
+				c.DateFormat = opts.DateFormat
+			}
+			if err := c.Validate(); err != nil {
+				return err
+			}
+			if out == "" || out == "-" {
+				_, err := a.ExportTable(cmd.OutOrStdout(), args[0], c)
+				return err
+			}
+			file, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			count, err := a.ExportTable(file, args[0], c)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d row(s) to %s.\n", count, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "output", "o", "", "File to write, stdout when empty")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "", "Field delimiter: "+strings.Join(app.CSVDelimiters, ", "))
+	cmd.Flags().StringVar(&opts.Encoding, "encoding", "", "Text encoding: "+strings.Join(app.CSVEncodings, ", "))
+	cmd.Flags().BoolVar(&opts.BOM, "bom", false, "Start UTF-8 output with a byte order mark, for Excel")
+	cmd.Flags().BoolVar(&opts.QuoteHeaders, "quote-headers", false, "Quote every header")
+	cmd.Flags().StringVar(&opts.DateFormat, "date-format", "", "Date format: "+strings.Join(app.CSVDateFormats, ", "))
+	return cmd
+}
+
+func scoresCmd(a *app.App) *cobra.Command {
+	var limit int
+	var recompute bool
+	cmd := &cobra.Command{
+		Use:   "scores",
+		Short: "List the accounts ranked by their enrichment score",
+		Long: `Lists the accounts with the highest score of enrichment.ranking, or of the
+first of enrichment.scores. Scores are computed after each accounts pull from
+the configured rules, weighted sums of numeric Accounts columns such as
+DaysSinceLastCheckin and CustomNumeric, and from enrichment.script if set.
+With --recompute they are computed for every stored account first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if recompute {
+				stored, err := a.EnrichAccounts(nil)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Stored %d score(s).\n", stored)
+			}
+			name, ranking, err := a.ScoreRanking(limit)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				fmt.Fprintln(out, "No account scores are configured; add enrichment.scores to the config.")
+				return nil
+			}
+			if len(ranking) == 0 {
+				fmt.Fprintf(out, "No accounts have a %s score yet.\n", name)
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Rank\tAccount\tName\t%s\n", name)
+			for i, ranked := range ranking {
+				fmt.Fprintf(w, "%d\t%d\t%s\t%.2f\n", i+1, ranked.AccountId, ranked.FullName, ranked.Score)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", database.DefaultScoreRankingLimit, "How many accounts to list")
+	cmd.Flags().BoolVar(&recompute, "recompute", false, "Compute the scores of every account first")
+	return cmd
+}
+
+func docsCmd(a *app.App) *cobra.Command {
+	var out, format string
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Write documentation of the database schema",
+		Long: `Writes the tables and columns BadgerMapsSync creates, with the column types of
+the configured database type, so reporting and BI tools can be built without
+reading the embedded SQL. The markdown format (default) has a Mermaid ER
+diagram and a table per database table; mermaid and dot write the diagram
+alone, for Mermaid or Graphviz. Relationships declared as foreign keys are
+drawn solid and those inferred from ID column names dashed. When the
+database is connected, Accounts columns are described with their BadgerMaps
+field labels and API names.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.DB == nil {
+				return fmt.Errorf("database is not configured")
+			}
+			write, ok := map[string]func(io.Writer, []database.TableDoc) error{
+				"markdown": database.WriteSchemaMarkdown,
+				"mermaid":  database.WriteSchemaMermaid,
+				"dot":      database.WriteSchemaDot,
+			}[format]
+			if !ok {
+				return fmt.Errorf("--format must be markdown, mermaid, or dot")
+			}
+			docs, err := database.SchemaDocs(a.DB)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				return write(cmd.OutOrStdout(), docs)
+			}
+			file, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			if err := write(file, docs); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote schema documentation of %d tables to %s\n", len(docs), out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Path of the file to write (default stdout)")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, mermaid, or dot")
+	return cmd
+}
+
+func unlockCmd(a *app.App) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Release the sync lock held by another instance",
+		Long: `Every pull and push run holds a lock in the database so instances sharing it,
+such as two machines syncing into one PostgreSQL database, do not sync at
+once. The holder renews the lock every 30 seconds, and another instance
+takes over a lock left without a heartbeat for two minutes. Use this
+command to release a lock right away when its holder is known to be gone.
+A run still going on the holder is not stopped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lock, err := a.SyncLock()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if lock == nil {
+				fmt.Fprintln(out, "The sync lock is not held.")
+				return nil
+			}
+			fmt.Fprintf(out, "The sync lock is held by host %s (pid %d) for %s since %s; last heartbeat %s.\n",
+				lock.Host, lock.Pid, lock.Operation, lock.AcquiredAt.In(a.DisplayLocation()).Format("2006-01-02 15:04:05"),
+				lock.HeartbeatAt.In(a.DisplayLocation()).Format("2006-01-02 15:04:05"))
+			if !yes {
+				if a.State.NoInput {
+					return fmt.Errorf("pass --yes to release the lock when --no-input is set")
+				}
+				reader := bufio.NewReader(os.Stdin)
+				if !utils.PromptBool(reader, "Release it?", false) {
+					fmt.Fprintln(out, "Lock kept.")
+					return nil
+				}
+			}
+			released, err := a.ForceReleaseSyncLock()
+			if err != nil {
+				return err
+			}
+			if released {
+				fmt.Fprintln(out, "Sync lock released.")
+			} else {
+				fmt.Fprintln(out, "The lock was released before this command got to it.")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+func remapsCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remaps",
+		Short: "List accounts remapped after BadgerMaps merged them",
+		Long: `Lists the local account IDs that pulls replaced because BadgerMaps merged the
+account into another one, newest first. A pull remaps an account when the API
+answers for its ID with a different account (redirect), when the ID is gone
+and exactly one other local account has the same CRM ID (gone), or when a full
+accounts pull no longer lists it and exactly one listed account has the same
+CRM ID (crm_id). Check-ins, route waypoints, and staged changes are moved to
+the new ID and the old account is removed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remaps, err := a.AccountRemaps()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(remaps) == 0 {
+				fmt.Fprintln(out, "No accounts have been remapped.")
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Time\tOld ID\tNew ID\tReason\tRows moved")
+			for _, remap := range remaps {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%d\n", remap.RemappedAt.In(a.DisplayLocation()).Format("2006-01-02 15:04:05"), remap.OldId, remap.NewId, remap.Reason, remap.RowsRewritten)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func captureCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capture",
+		Short: "Queue account changes made by other systems for push",
+		Long: `Manages database triggers that record updates and deletes made to the Accounts
+table by other systems, such as an ETL job or a direct SQL update, as pending
+changes that 'push accounts' sends to BadgerMaps. Changes written by
+BadgerMapsSync itself are not captured. New accounts are not captured.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "enable",
+		Short: "Install the change capture triggers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.EnableChangeCapture()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "disable",
+		Short: "Remove the change capture triggers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.DisableChangeCapture()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show whether change capture is enabled",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.DB == nil {
+				return fmt.Errorf("database is not configured")
+			}
+			enabled, err := database.ChangeCaptureEnabled(a.DB)
+			if err != nil {
+				return err
+			}
+			if enabled {
+				fmt.Println("Change capture is enabled.")
+			} else {
+				fmt.Println("Change capture is disabled.")
+			}
+			if enabled != a.Config.ChangeCapture {
+				fmt.Printf("The config has change_capture: %t; run 'db capture enable' or 'db capture disable' to match it.\n", a.Config.ChangeCapture)
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+func encryptCmd(a *app.App) *cobra.Command {
+	var keychain bool
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt the SQLite database with SQLCipher",
+		Long: `Converts an unencrypted SQLite database to SQLCipher and sets db.encrypted in the
+config. The passphrase is read from ` + app.DBKeyEnv + ` or prompted for, and is
+never written to the config; pass --keychain to keep it in the OS keychain so
+the GUI and server can open the database. Requires a build with -tags sqlcipher.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := newPassphrase(a, app.DBKeyEnv)
+			if err != nil {
+				return err
+			}
+			if err := a.EncryptDatabase(key, keychain); err != nil {
+				return err
+			}
+			fmt.Println("Database encrypted.")
+			if !keychain && os.Getenv(app.DBKeyEnv) == "" {
+				fmt.Printf("Set %s or pass --keychain next time so the database can be opened without a prompt.\n", app.DBKeyEnv)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&keychain, "keychain", false, "Store the passphrase in the OS keychain")
+	return cmd
+}
+
+func rekeyCmd(a *app.App) *cobra.Command {
+	var keychain bool
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Change the passphrase of an encrypted SQLite database",
+		Long: `Re-encrypts the SQLCipher database with a new passphrase. The current passphrase
+comes from ` + app.DBKeyEnv + `, the keychain, or a prompt; the new one is prompted
+for, or read from ` + newDBKeyEnv + ` with --no-input. A passphrase already in the
+keychain is updated.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.DB == nil {
+				return fmt.Errorf("cannot open the database with the current passphrase")
+			}
+			key, err := newPassphrase(a, newDBKeyEnv)
+			if err != nil {
+				return err
+			}
+			if err := a.RotateDatabaseKey(key, keychain); err != nil {
+				return err
+			}
+			fmt.Println("Database passphrase changed.")
+			if os.Getenv(app.DBKeyEnv) != "" {
+				fmt.Printf("Update %s to the new passphrase.\n", app.DBKeyEnv)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&keychain, "keychain", false, "Store the new passphrase in the OS keychain")
+	return cmd
+}
+
+func encryptColumnsCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt-columns [column...]",
+		Short: "Encrypt sensitive Accounts columns with an app-managed key",
+		Long: `Encrypts the stored values of Accounts columns with AES-GCM and adds them to
+db.encrypted_columns, so later pulls store them encrypted too. Without columns,
+` + strings.Join(app.DefaultEncryptedColumns, ", ") + ` are encrypted. The key is generated on first use and
+kept in the OS keychain, or read from ` + app.ColumnKeyEnv + ` (base64, 32 bytes).
+Sessions with the key see the values in the GUI and its exports; others see
+` + app.EncryptedPlaceholder + `. SQLite only; for encrypting the whole file see 'db encrypt'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			columns := args
+			if len(columns) == 0 {
+				columns = app.DefaultEncryptedColumns
+			}
+			changed, err := a.EncryptColumns(columns)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Encrypted %d value(s) in %s.\n", changed, strings.Join(columns, ", "))
+			return nil
+		},
+	}
+}
+
+func decryptColumnsCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt-columns",
+		Short: "Store encrypted Accounts columns in the clear again",
+		Long:  `Decrypts every column in db.encrypted_columns and clears the setting. Needs the column key.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changed, err := a.DecryptColumns()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Decrypted %d value(s).\n", changed)
+			return nil
+		},
+	}
+}
+
+// newDBKeyEnv supplies the new passphrase to 'db rekey' when prompts are
+// disabled.
+const newDBKeyEnv = "BADGERMAPS_DB_NEW_KEY"
+
+// newPassphrase reads a new passphrase from env, or prompts for it twice.
+func newPassphrase(a *app.App, env string) (string, error) {
+	if key := os.Getenv(env); key != "" {
+		return key, nil
+	}
+	if a.State.NoInput {
+		return "", fmt.Errorf("set %s to the passphrase when --no-input is set", env)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	key, err := utils.PromptSecret(reader, "New database passphrase")
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	confirm, err := utils.PromptSecret(reader, "Confirm passphrase")
+	if err != nil {
+		return "", err
+	}
+	if confirm != key {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return key, nil
+}
+
+// printIntegrityReport prints one line per child table with a sample of the
+// missing parent IDs.
+func printIntegrityReport(report *pull.IntegrityReport) {
+	const maxIDs = 10
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "Table\tOrphans\tMissing Parents")
+	for _, table := range report.Tables() {
+		parents := report.MissingParents(table)
+		ids := make([]string, 0, maxIDs)
+		for i, id := range parents {
+			if i == maxIDs {
+				ids = append(ids, fmt.Sprintf("... (+%d)", len(parents)-maxIDs))
+				break
+			}
+			ids = append(ids, strconv.Itoa(id))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s %s\n", table, len(report.ForTable(table)), database.OrphanParentTable(table), strings.Join(ids, ", "))
+	}
+}
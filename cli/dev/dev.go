@@ -0,0 +1,89 @@
+package dev
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/seed"
+	"badgermaps/utils"
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DevCmd creates the dev command, which holds tools for demos and
+// development.
+func DevCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for demos and development",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(seedCmd(a))
+	return cmd
+}
+
+func seedCmd(a *app.App) *cobra.Command {
+	var opts seed.Options
+	var clear, yes bool
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the local database with generated accounts and check-ins",
+		Long: fmt.Sprintf(`Writes made-up accounts with addresses and coordinates around US cities, and
+check-ins spread over the last two years, straight into the local database. It
+needs no BadgerMaps account, so the GUI can be shown and the Explorer and
+pagination tested with realistic volumes.
+
+Seeded rows use IDs from %d up and replace the rows of any earlier seed;
+pulled data is left alone. The same --seed writes the same data. --clear only
+removes seeded rows. Nothing is staged for push.`, seed.IdBase),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.ConfirmProduction("dev seed"); err != nil {
+				return err
+			}
+			if a.DB == nil || !a.DB.IsConnected() {
+				return fmt.Errorf("database is not connected")
+			}
+			out := cmd.OutOrStdout()
+			if clear {
+				removed, err := seed.Clear(a)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Removed %d seeded rows.\n", removed)
+				return nil
+			}
+			if !yes {
+				if a.State.NoInput {
+					return fmt.Errorf("seeding replaces earlier seeded rows; pass --yes to confirm when --no-input is set")
+				}
+				reader := bufio.NewReader(os.Stdin)
+				prompt := fmt.Sprintf("Write %d accounts and %d check-ins of generated data to the %s database?", opts.Accounts, opts.Checkins, a.DB.GetType())
+				if !utils.PromptBool(reader, prompt, true) {
+					fmt.Fprintln(out, "Seed cancelled.")
+					return nil
+				}
+			}
+			summary, err := seed.Run(a, opts)
+			if err != nil {
+				return err
+			}
+			if summary.Removed > 0 {
+				fmt.Fprintf(out, "Removed %d rows of an earlier seed.\n", summary.Removed)
+			}
+			fmt.Fprintf(out, "Seeded %d accounts and %d check-ins in %s.\n", summary.Accounts, summary.Checkins, summary.Duration.Round(time.Millisecond))
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&opts.Accounts, "accounts", seed.DefaultAccounts, "Number of accounts to generate")
+	cmd.Flags().IntVar(&opts.Checkins, "checkins", seed.DefaultCheckins, "Number of check-ins to generate")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", seed.DefaultSeed, "Seed of the generator; the same seed writes the same data")
+	cmd.Flags().BoolVar(&clear, "clear", false, "Remove seeded rows instead of writing new ones")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
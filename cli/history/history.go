@@ -0,0 +1,188 @@
+package history
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd creates the history command, which lists the commands recorded
+// in CommandLog and runs safe ones again.
+func HistoryCmd(App *app.App) *cobra.Command {
+	var (
+		filter       database.CommandLogFilter
+		status       string
+		since, until string
+		asJSON       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the commands that were run",
+		Long: `List recorded commands, newest first, with their arguments, the flags that were
+set, whether they succeeded, and how long they took. Filter by command
+(--command db also matches db stats), by --status success or failed, and by
+time with --since and --until, which take a date (2024-05-01), a date and
+time (2024-05-01 14:30) in the display timezone, or an age such as 36h or 7d.
+
+Commands that only read data or refresh it from the API can be run again
+with 'history rerun <id>'. Pull and push runs, and the notes left on them, are
+listed by 'history runs'.`,
+		Example: `  badgermaps history --command pull --since 7d
+  badgermaps history --status failed --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			loc := App.DisplayLocation()
+			switch status {
+			case "":
+			case "success", "failed":
+				succeeded := status == "success"
+				filter.Success = &succeeded
+			default:
+				return fmt.Errorf("--status must be success or failed, got %q", status)
+			}
+			var err error
+			if filter.Since, err = parseHistoryTime(since, loc, time.Now()); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			if filter.Until, err = parseHistoryTime(until, loc, time.Now()); err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+
+			entries, err := App.CommandHistory(filter)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(historyRecords(entries))
+			}
+			return writeHistory(out, entries, loc)
+		},
+	}
+	cmd.Flags().StringVar(&filter.Command, "command", "", "Only show this command and its subcommands")
+	cmd.Flags().StringVar(&status, "status", "", "Only show commands that ended with: success or failed")
+	cmd.Flags().StringVar(&since, "since", "", "Only show commands run at or after this date, time, or age")
+	cmd.Flags().StringVar(&until, "until", "", "Only show commands run before this date, time, or age")
+	cmd.Flags().IntVar(&filter.Limit, "limit", database.DefaultCommandLogLimit, "Maximum number of commands to show")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the history as JSON")
+
+	cmd.AddCommand(rerunCmd(App))
+	cmd.AddCommand(runsCmd(App))
+	return cmd
+}
+
+func rerunCmd(App *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rerun <id>",
+		Short: "Run a recorded command again",
+		Long: `Runs the command with the given history ID again, with the same arguments and
+flags and with prompts disabled. Only commands that do not change data can be
+re-run: pull, status, version, db stats, db check-times, db fsck without
+--delete, db remaps, and archive list.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid history ID %q", args[0])
+			}
+			cmd.SilenceUsage = true
+			return App.RerunCommand(cmd.Context(), id, cmd.OutOrStdout(), os.Stderr)
+		},
+	}
+}
+
+// parseHistoryTime reads a --since or --until value: an age before now, or
+// a date with an optional time in loc. Empty returns the zero time.
+func parseHistoryTime(value string, loc *time.Location, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (2024-05-01), date and time (2024-05-01 14:30), or age (36h, 7d)", value)
+}
+
+// historyRecord is the JSON form of a command log entry.
+type historyRecord struct {
+	ID         int       `json:"id"`
+	Command    string    `json:"command"`
+	Args       string    `json:"args,omitempty"`
+	Flags      []string  `json:"flags,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Rerunnable bool      `json:"rerunnable"`
+}
+
+func historyRecords(entries []database.CommandLogEntry) []historyRecord {
+	records := make([]historyRecord, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, historyRecord{
+			ID:         entry.LogId,
+			Command:    entry.Command,
+			Args:       entry.Args,
+			Flags:      entry.Flags,
+			Timestamp:  entry.Timestamp,
+			Success:    entry.Success,
+			Error:      entry.ErrorMessage,
+			DurationMs: entry.Duration.Milliseconds(),
+			Rerunnable: app.CanRerun(entry),
+		})
+	}
+	return records
+}
+
+func writeHistory(out io.Writer, entries []database.CommandLogEntry, loc *time.Location) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No commands found.")
+		return nil
+	}
+	c := utils.Colors
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTime\tCommand\tStatus\tDuration")
+	for _, entry := range entries {
+		status := c.Green("ok")
+		if !entry.Success {
+			status = c.Red("failed")
+		}
+		duration := "-"
+		if entry.Duration > 0 {
+			duration = entry.Duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", entry.LogId, entry.Timestamp.In(loc).Format("2006-01-02 15:04:05"), app.CommandLine(entry), status, duration)
+		if entry.ErrorMessage != "" {
+			fmt.Fprintf(w, "\t\t%s\t\t\n", c.Gray("%s", entry.ErrorMessage))
+		}
+	}
+	return w.Flush()
+}
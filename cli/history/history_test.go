@@ -0,0 +1,31 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHistoryTime(t *testing.T) {
+	loc := time.FixedZone("test", -5*3600)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, loc)},
+		{"2024-05-01 14:30", time.Date(2024, 5, 1, 14, 30, 0, 0, loc)},
+		{"2024-05-01T14:30:00Z", time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseHistoryTime(tt.value, loc, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseHistoryTime(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := parseHistoryTime("last week", loc, now); err == nil {
+		t.Error("parseHistoryTime(\"last week\") did not fail")
+	}
+}
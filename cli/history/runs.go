@@ -0,0 +1,148 @@
+package history
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func runsCmd(App *app.App) *cobra.Command {
+	var (
+		limit  int
+		asJSON bool
+	)
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "List recent pull and push runs with their notes",
+		Long: `List the runs recorded in SyncHistory, newest first, with their status, counts,
+summary, and the note an operator left on them. Add or change a note with
+'history runs note <run-id> <text>'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			runs, err := App.SyncRuns(limit)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(runRecords(runs))
+			}
+			return writeRuns(out, runs, App.DisplayLocation())
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of runs to show")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the runs as JSON")
+	cmd.AddCommand(noteCmd(App))
+	return cmd
+}
+
+func noteCmd(App *app.App) *cobra.Command {
+	var clear bool
+	cmd := &cobra.Command{
+		Use:   "note <run-id> [text...]",
+		Short: "Annotate a pull or push run",
+		Long: fmt.Sprintf(`Sets the note on a run listed by 'history runs', such as "re-ran after fixing
+the API key" or "large import from the trade show". The note replaces any
+earlier one and shows in the run lists, the status report, and digests. Pass
+--clear to remove it. Notes are limited to %d characters.`, app.MaxSyncRunNoteLength),
+		Example: `  badgermaps history runs note 42 re-ran after fixing the API key
+  badgermaps history runs note 42 --clear`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid run ID %q", args[0])
+			}
+			note := strings.Join(args[1:], " ")
+			if clear == (strings.TrimSpace(note) != "") {
+				return fmt.Errorf("give the note text or --clear, not both or neither")
+			}
+			cmd.SilenceUsage = true
+			if err := App.AnnotateSyncRun(id, note); err != nil {
+				return err
+			}
+			if clear {
+				fmt.Fprintf(cmd.OutOrStdout(), "Cleared the note on run %d.\n", id)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Noted run %d.\n", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&clear, "clear", false, "Remove the run's note")
+	return cmd
+}
+
+// runRecord is the JSON form of a SyncHistory run.
+type runRecord struct {
+	ID              int64      `json:"id"`
+	RunType         string     `json:"run_type"`
+	Direction       string     `json:"direction"`
+	Source          string     `json:"source"`
+	Status          string     `json:"status"`
+	ItemsProcessed  int        `json:"items_processed"`
+	ErrorCount      int        `json:"error_count"`
+	StartedAt       time.Time  `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	DurationSeconds int        `json:"duration_seconds,omitempty"`
+	Summary         string     `json:"summary,omitempty"`
+	Note            string     `json:"note,omitempty"`
+}
+
+func runRecords(runs []database.SyncHistoryEntry) []runRecord {
+	records := make([]runRecord, 0, len(runs))
+	for _, run := range runs {
+		records = append(records, runRecord{
+			ID:              run.HistoryID,
+			RunType:         run.RunType,
+			Direction:       run.Direction,
+			Source:          run.Source,
+			Status:          run.Status,
+			ItemsProcessed:  run.ItemsProcessed,
+			ErrorCount:      run.ErrorCount,
+			StartedAt:       run.StartedAt,
+			CompletedAt:     run.CompletedAt,
+			DurationSeconds: run.DurationSeconds,
+			Summary:         run.Summary,
+			Note:            run.Note,
+		})
+	}
+	return records
+}
+
+func writeRuns(out io.Writer, runs []database.SyncHistoryEntry, loc *time.Location) error {
+	if len(runs) == 0 {
+		fmt.Fprintln(out, "No runs recorded yet.")
+		return nil
+	}
+	c := utils.Colors
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tStarted\tType\tSource\tStatus\tItems\tErrors\tSummary")
+	for _, run := range runs {
+		status := run.Status
+		switch run.Status {
+		case "completed":
+			status = c.Green("%s", status)
+		case "failed", "completed_with_errors":
+			status = c.Red("%s", status)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n", run.HistoryID, run.StartedAt.In(loc).Format("2006-01-02 15:04:05"),
+			run.RunType, run.Source, status, run.ItemsProcessed, run.ErrorCount, run.Summary)
+		if run.Note != "" {
+			fmt.Fprintf(w, "\t\t\t\t\t\t\t%s\n", c.Gray("Note: %s", run.Note))
+		}
+	}
+	return w.Flush()
+}
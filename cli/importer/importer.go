@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"badgermaps/app"
+	"badgermaps/utils"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd creates the import command for bringing legacy records into the
+// push queue.
+func ImportCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import legacy records from files into the push queue",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(checkinsCmd(a))
+	return cmd
+}
+
+func checkinsCmd(a *app.App) *cobra.Command {
+	var mappings []string
+	var dryRun, skipInvalid, yes, asJSON bool
+	cmd := &cobra.Command{
+		Use:   "checkins <file.csv>",
+		Short: "Stage check-in history from a CSV file",
+		Long: `Reads check-ins from a CSV file with a header row and stages the new ones
+as check-in creates, to be reviewed with 'push list' and sent with
+'push checkins'. Columns are found by their usual names (account_id, date,
+type, notes, ...); map others with --map field=Header. A row is a duplicate
+when its account already has a check-in, pulled or staged, logged in the same
+minute with the same comments, ignoring case and spacing.
+
+Fields: ` + strings.Join(app.CheckinImportFields(), ", "),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			columns := make(map[string]string, len(mappings))
+			for _, mapping := range mappings {
+				field, header, ok := strings.Cut(mapping, "=")
+				if !ok || strings.TrimSpace(header) == "" {
+					return fmt.Errorf("invalid --map %q (expected field=Header)", mapping)
+				}
+				columns[strings.TrimSpace(field)] = strings.TrimSpace(header)
+			}
+			opts := app.CheckinImportOptions{Columns: columns, SkipInvalid: skipInvalid, DryRun: true}
+
+			preview, err := readCheckins(a, args[0], opts)
+			if err != nil && preview == nil {
+				return err
+			}
+			if dryRun || err != nil || preview.New == 0 {
+				return printImport(preview, asJSON, true, err)
+			}
+			if !yes {
+				if a.State.NoInput {
+					return fmt.Errorf("pass --yes to stage check-ins when --no-input is set")
+				}
+				printSummary(preview)
+				if !utils.PromptBool(bufio.NewReader(os.Stdin), fmt.Sprintf("Stage %d check-in(s)?", preview.New), false) {
+					fmt.Println("Nothing was staged.")
+					return nil
+				}
+			}
+			opts.DryRun = false
+			report, err := readCheckins(a, args[0], opts)
+			if report == nil {
+				return err
+			}
+			return printImport(report, asJSON, yes, err)
+		},
+	}
+	cmd.Flags().StringArrayVar(&mappings, "map", nil, "Read a field from a column, as field=Header (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would be staged without staging it")
+	cmd.Flags().BoolVar(&skipInvalid, "skip-invalid", false, "Stage the valid rows even when others are invalid")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	return cmd
+}
+
+func readCheckins(a *app.App, path string, opts app.CheckinImportOptions) (*app.CheckinImportReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return a.ImportCheckins(f, opts)
+}
+
+// printImport prints the report, with its summary unless that was shown at
+// the prompt, and returns err so invalid rows are listed before the command
+// fails.
+func printImport(report *app.CheckinImportReport, asJSON, summary bool, err error) error {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(report); encErr != nil {
+			return encErr
+		}
+		return err
+	}
+	if summary {
+		printSummary(report)
+	}
+	switch {
+	case err != nil:
+	case len(report.Staged) > 0:
+		fmt.Printf("Staged %d check-in(s); review them with 'badgermaps push list' before pushing.\n", len(report.Staged))
+	case report.New > 0:
+		fmt.Printf("%d check-in(s) would be staged.\n", report.New)
+	default:
+		fmt.Println("No new check-ins to stage.")
+	}
+	return err
+}
+
+func printSummary(report *app.CheckinImportReport) {
+	fmt.Printf("%d row(s): %d new, %d already known, %d repeated in the file, %d invalid.\n",
+		report.Rows, report.New, report.Existing, report.Repeated, len(report.Invalid))
+	for _, problem := range report.Invalid {
+		fmt.Printf("  line %d: %s\n", problem.Line, utils.Colors.Red("%s", problem.Err))
+	}
+}
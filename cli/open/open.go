@@ -0,0 +1,79 @@
+package open
+
+import (
+	"badgermaps/app"
+	"badgermaps/utils"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// OpenCmd creates the open command, which the desktop runs for
+// badgermaps:// links. openGUI opens the link in a new GUI window; it is nil
+// in builds without the GUI.
+func OpenCmd(App *app.App, openGUI func(app.DeepLink)) *cobra.Command {
+	var register bool
+
+	cmd := &cobra.Command{
+		Use:   "open [badgermaps://link]",
+		Short: "Open a badgermaps:// link in the GUI",
+		Long: `Open a badgermaps:// link. When the GUI is already running, the link is handed to its window; otherwise a new window opens at the link.
+
+Links:
+  badgermaps://account/<id>   show an account
+  badgermaps://checkin/<id>   show a check-in
+  badgermaps://route/<id>     show a route
+  badgermaps://table/<name>   open a table in Explorer
+  badgermaps://tab/<name>     switch to a tab, such as push or server
+  badgermaps://run/<job>      run the scheduled job with that name
+
+Without a GUI, run links execute the job directly and other links fail.
+
+Use --register to make this executable the handler of badgermaps:// links for the current user.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if register {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if register {
+				exe, err := os.Executable()
+				if err != nil {
+					return err
+				}
+				if err := utils.RegisterURLScheme(app.DeepLinkScheme, exe, "open"); err != nil {
+					if errors.Is(err, utils.ErrURLSchemeUnsupported) {
+						return fmt.Errorf("%w; the macOS app bundle declares %s:// in its Info.plist", err, app.DeepLinkScheme)
+					}
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Registered %s:// links to open with %s\n", app.DeepLinkScheme, exe)
+				return nil
+			}
+
+			link, err := app.ParseDeepLink(args[0])
+			if err != nil {
+				return err
+			}
+			if err := app.ForwardDeepLink(link); !errors.Is(err, app.ErrNoDeepLinkListener) {
+				return err
+			}
+			if openGUI != nil {
+				openGUI(link)
+				return nil
+			}
+			if link.Kind != app.DeepLinkRun {
+				return fmt.Errorf("%s links need the GUI, which this build does not include", link.Kind)
+			}
+			App.EnsureConfig(false)
+			return App.RunCronJob(link.Target)
+		},
+	}
+
+	cmd.Flags().BoolVar(&register, "register", false, "Register this executable as the badgermaps:// link handler")
+	return cmd
+}
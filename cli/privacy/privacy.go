@@ -0,0 +1,130 @@
+package privacy
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/utils"
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// PrivacyCmd creates the privacy command for erasing personal data.
+func PrivacyCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "privacy",
+		Short: "Erase personal data for data protection requests",
+		Long: `Removes or anonymizes what is stored locally about an account and keeps a
+signed report of each erasure for compliance records.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(eraseCmd(a))
+	cmd.AddCommand(verifyCmd())
+	return cmd
+}
+
+func eraseCmd(a *app.App) *cobra.Command {
+	var account, report string
+	var anonymize, stageDelete, yes bool
+	cmd := &cobra.Command{
+		Use:   "erase",
+		Short: "Erase an account and its check-ins and locations",
+		Long: `Deletes an account with its check-ins, location, staged changes, recycle bin
+copies, webhook logs, and archived check-ins, and clears it from route
+waypoints. --anonymize keeps the account and its check-ins for counts but
+clears every personal field instead. --account is an account ID or the email
+of one account.
+
+--stage-delete also stages a delete of the account that the next push sends
+to BadgerMaps. Later pulls skip erased accounts and their check-ins.
+
+A report signed with a key kept in the config directory is written to
+--report, or to the erasures directory next to the config file. Check it
+with 'privacy verify'. Backups taken before the erasure still hold the data.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.ConfirmProduction("privacy erase"); err != nil {
+				return err
+			}
+			mode := database.ErasureDelete
+			if anonymize {
+				mode = database.ErasureAnonymize
+			}
+			if !yes {
+				if a.State.NoInput {
+					return fmt.Errorf("erasing cannot be undone; pass --yes to confirm when --no-input is set")
+				}
+				reader := bufio.NewReader(os.Stdin)
+				if !utils.PromptBool(reader, fmt.Sprintf("Erase (%s) all local data for account %s? This cannot be undone.", mode, account), false) {
+					fmt.Println("Erasure cancelled.")
+					return nil
+				}
+			}
+			result, path, err := a.EraseAccount(app.ErasureOptions{
+				Subject:     account,
+				Mode:        mode,
+				StageDelete: stageDelete,
+				ReportPath:  report,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Erased account %d (%s).\n", result.AccountId, result.Mode)
+			printRows(result)
+			if result.DeleteChangeId > 0 {
+				fmt.Printf("Staged delete %d; run 'badgermaps push' to delete the account in BadgerMaps.\n", result.DeleteChangeId)
+			}
+			fmt.Printf("Report: %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&account, "account", "", "Account ID or email of the account to erase")
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "Clear personal fields but keep the account and its check-ins")
+	cmd.Flags().BoolVar(&stageDelete, "stage-delete", false, "Also stage a delete of the account for the next push")
+	cmd.Flags().StringVar(&report, "report", "", "Path to write the signed erasure report to")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.MarkFlagRequired("account")
+	return cmd
+}
+
+func verifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify <report>",
+		Short: "Check the signature of an erasure report",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, ownKey, err := app.VerifyErasureReport(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Signature is valid: account %d erased (%s) at %s, report %s.\n",
+				report.AccountId, report.Mode, report.ErasedAt.Local().Format("2006-01-02 15:04"), report.ReportId)
+			if !ownKey {
+				fmt.Println("The report was signed with a key other than this installation's.")
+			}
+			return nil
+		},
+	}
+}
+
+func printRows(report app.ErasureReport) {
+	tables := make([]string, 0, len(report.Rows))
+	for table := range report.Rows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if report.Rows[table] > 0 {
+			fmt.Printf("  %-30s %d\n", table, report.Rows[table])
+		}
+	}
+	if report.ArchivedCheckins > 0 {
+		fmt.Printf("  %-30s %d\n", "archived check-ins", report.ArchivedCheckins)
+	}
+}
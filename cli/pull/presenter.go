@@ -4,8 +4,8 @@ import (
 	"badgermaps/app"
 	"badgermaps/app/pull"
 	"badgermaps/events"
+	"badgermaps/utils"
 	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/schollz/progressbar/v3"
@@ -47,6 +47,18 @@ func (p *CliPresenter) HandlePullAccount(accountID int, opts ResponseSaveOptions
 	return p.saveResponse("account", strconv.Itoa(accountID), account, opts)
 }
 
+// HandlePullAccountBundle pulls an account with the related records with
+// asks for and reports what was stored.
+func (p *CliPresenter) HandlePullAccountBundle(accountID int, with pull.BundleOptions, opts ResponseSaveOptions) error {
+	bundle, err := pull.PullAccountBundle(p.App, accountID, with)
+	if err != nil {
+		p.App.Events.Dispatch(events.Errorf("pull", "Error: Failed to pull account %d with its related records.", accountID))
+		return err
+	}
+	p.App.Events.Dispatch(events.Infof("pull", "Pulled %s.", bundle))
+	return p.saveResponse("account", strconv.Itoa(accountID), bundle, opts)
+}
+
 // HandlePullAccounts orchestrates pulling all accounts.
 func (p *CliPresenter) HandlePullAccounts() error {
 	var bar *progressbar.ProgressBar
@@ -59,7 +71,7 @@ func (p *CliPresenter) HandlePullAccounts() error {
 		case "pull.group.start":
 			bar = progressbar.NewOptions(-1,
 				progressbar.OptionSetDescription("Pulling accounts..."),
-				progressbar.OptionSetWriter(os.Stderr),
+				progressbar.OptionSetWriter(utils.ProgressWriter(p.App.State)),
 				progressbar.OptionSpinnerType(14),
 				progressbar.OptionEnableColorCodes(true),
 			)
@@ -69,10 +81,8 @@ func (p *CliPresenter) HandlePullAccounts() error {
 				bar.ChangeMax(payload.Count)
 				bar.Describe(fmt.Sprintf("Found %d accounts to pull.", payload.Count))
 			}
-		case "pull.store.success":
-			if bar != nil {
-				bar.Add(1)
-			}
+		case "pull.progress":
+			showProgress(bar, e.Payload.(events.ProgressPayload))
 		case "pull.group.error":
 			payload := e.Payload.(events.ErrorPayload)
 			if bar != nil {
@@ -90,7 +100,7 @@ func (p *CliPresenter) HandlePullAccounts() error {
 	// Subscribe the listener to all relevant events
 	p.App.Events.Subscribe("pull.*", pullListener)
 
-	err := pull.PullGroupAccounts(p.App, 0, nil)
+	err := pull.PullGroupAccounts(p.App, 0)
 	if bar != nil && !bar.IsFinished() {
 		bar.Finish()
 	}
@@ -134,7 +144,7 @@ func (p *CliPresenter) HandlePullCheckins() error {
 		case "pull.group.start":
 			bar = progressbar.NewOptions(-1,
 				progressbar.OptionSetDescription("Pulling checkins..."),
-				progressbar.OptionSetWriter(os.Stderr),
+				progressbar.OptionSetWriter(utils.ProgressWriter(p.App.State)),
 				progressbar.OptionSpinnerType(14),
 				progressbar.OptionEnableColorCodes(true),
 			)
@@ -144,10 +154,8 @@ func (p *CliPresenter) HandlePullCheckins() error {
 				bar.ChangeMax(payload.Count)
 				bar.Describe(fmt.Sprintf("Found %d accounts to pull checkins from.", payload.Count))
 			}
-		case "pull.store.success":
-			if bar != nil {
-				bar.Add(1)
-			}
+		case "pull.progress":
+			showProgress(bar, e.Payload.(events.ProgressPayload))
 		case "pull.group.error":
 			payload := e.Payload.(events.ErrorPayload)
 			if bar != nil {
@@ -165,7 +173,7 @@ func (p *CliPresenter) HandlePullCheckins() error {
 	// Subscribe the listener to all relevant events
 	p.App.Events.Subscribe("pull.*", pullListener)
 
-	err := pull.PullGroupCheckins(p.App, nil)
+	err := pull.PullGroupCheckins(p.App)
 	if bar != nil && !bar.IsFinished() {
 		bar.Finish()
 	}
@@ -209,7 +217,7 @@ func (p *CliPresenter) HandlePullRoutes() error {
 		case "pull.group.start":
 			bar = progressbar.NewOptions(-1,
 				progressbar.OptionSetDescription("Pulling routes..."),
-				progressbar.OptionSetWriter(os.Stderr),
+				progressbar.OptionSetWriter(utils.ProgressWriter(p.App.State)),
 				progressbar.OptionSpinnerType(14),
 				progressbar.OptionEnableColorCodes(true),
 			)
@@ -219,10 +227,56 @@ func (p *CliPresenter) HandlePullRoutes() error {
 				bar.ChangeMax(payload.Count)
 				bar.Describe(fmt.Sprintf("Found %d routes to pull.", payload.Count))
 			}
-		case "pull.store.success":
+		case "pull.progress":
+			showProgress(bar, e.Payload.(events.ProgressPayload))
+		case "pull.group.error":
+			payload := e.Payload.(events.ErrorPayload)
+			if bar != nil {
+				bar.Clear()
+			}
+			p.App.Events.Dispatch(events.Errorf("pull", "An error occurred during pull: %v", payload.Error))
+		case "pull.group.complete":
+			if bar != nil {
+				bar.Finish()
+				p.App.Events.Dispatch(events.Infof("pull", "✔ Pull for %s complete.", e.Source))
+			}
+		}
+	}
+
+	// Subscribe the listener to all relevant events
+	p.App.Events.Subscribe("pull.*", pullListener)
+
+	err := pull.PullGroupRoutes(p.App)
+	if bar != nil && !bar.IsFinished() {
+		bar.Finish()
+	}
+	return err
+}
+
+// HandlePullLocations orchestrates refreshing account locations.
+func (p *CliPresenter) HandlePullLocations() error {
+	var bar *progressbar.ProgressBar
+
+	pullListener := func(e events.Event) {
+		if e.Source != "locations" {
+			return
+		}
+		switch e.Type {
+		case "pull.group.start":
+			bar = progressbar.NewOptions(-1,
+				progressbar.OptionSetDescription("Refreshing locations..."),
+				progressbar.OptionSetWriter(utils.ProgressWriter(p.App.State)),
+				progressbar.OptionSpinnerType(14),
+				progressbar.OptionEnableColorCodes(true),
+			)
+		case "pull.ids_fetched":
+			payload := e.Payload.(events.ResourceIDsFetchedPayload)
 			if bar != nil {
-				bar.Add(1)
+				bar.ChangeMax(payload.Count)
+				bar.Describe(fmt.Sprintf("Updating locations for %d accounts.", payload.Count))
 			}
+		case "pull.progress":
+			showProgress(bar, e.Payload.(events.ProgressPayload))
 		case "pull.group.error":
 			payload := e.Payload.(events.ErrorPayload)
 			if bar != nil {
@@ -237,16 +291,26 @@ func (p *CliPresenter) HandlePullRoutes() error {
 		}
 	}
 
-	// Subscribe the listener to all relevant events
 	p.App.Events.Subscribe("pull.*", pullListener)
 
-	err := pull.PullGroupRoutes(p.App, nil)
+	err := pull.PullGroupLocations(p.App)
 	if bar != nil && !bar.IsFinished() {
 		bar.Finish()
 	}
 	return err
 }
 
+// HandlePullDatasets orchestrates refreshing the profile datasets.
+func (p *CliPresenter) HandlePullDatasets() error {
+	count, err := pull.PullDatasets(p.App)
+	if err != nil {
+		p.App.Events.Dispatch(events.Errorf("pull", "Error: Failed to refresh datasets: %v", err))
+		return err
+	}
+	p.App.Events.Dispatch(events.Infof("pull", "✔ Refreshed %d datasets.", count))
+	return nil
+}
+
 // HandlePullProfile orchestrates pulling the user profile.
 func (p *CliPresenter) HandlePullProfile(opts ResponseSaveOptions) error {
 	listener := func(e events.Event) {
@@ -276,3 +340,15 @@ func (p *CliPresenter) HandlePullProfile(opts ResponseSaveOptions) error {
 	}
 	return p.saveResponse("profile", identifier, profile, opts)
 }
+
+// showProgress moves bar to the latest progress event.
+func showProgress(bar *progressbar.ProgressBar, payload events.ProgressPayload) {
+	if bar == nil {
+		return
+	}
+	if payload.Total > 0 {
+		bar.ChangeMax(payload.Total)
+	}
+	bar.Set(payload.Processed)
+	bar.Describe(payload.Summary())
+}
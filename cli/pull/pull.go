@@ -2,6 +2,7 @@ package pull
 
 import (
 	"badgermaps/app"
+	"badgermaps/app/pull"
 	"fmt"
 	"os"
 	"strconv"
@@ -23,6 +24,10 @@ func PullCmd(App *app.App) *cobra.Command {
 		},
 	}
 
+	pullCmd.PersistentFlags().BoolVar(&App.State.SkipSchemaCheck, "force", false, "Pull even when the database schema is invalid or a migration is pending")
+	pullCmd.PersistentFlags().StringVar(&App.State.PullRadius, "within", "", `Only pull accounts located within a radius, e.g. "50 km of 40.7128,-74.0060" or "50 km of Springfield, IL" (overrides pull_radius)`)
+	pullCmd.PersistentFlags().BoolVar(&App.State.LowBandwidth, "low-bandwidth", false, "Use fewer, compressed requests and pull only the account list; account details are fetched when opened (overrides low_bandwidth)")
+
 	pullCmd.AddCommand(pullAccountCmd(presenter))
 	pullCmd.AddCommand(pullAccountsCmd(presenter))
 	pullCmd.AddCommand(pullCheckinCmd(presenter))
@@ -30,6 +35,8 @@ func PullCmd(App *app.App) *cobra.Command {
 	pullCmd.AddCommand(pullRouteCmd(presenter))
 	pullCmd.AddCommand(pullRoutesCmd(presenter))
 	pullCmd.AddCommand(pullProfileCmd(presenter))
+	pullCmd.AddCommand(pullLocationsCmd(presenter))
+	pullCmd.AddCommand(pullDatasetsCmd(presenter))
 	pullCmd.AddCommand(PullAllCmd(App)) // Assuming PullAllCmd will be refactored similarly
 
 	return pullCmd
@@ -39,15 +46,24 @@ func pullAccountCmd(presenter *CliPresenter) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "account [id]",
 		Short: "Pull a single account from BadgerMaps",
-		Long:  `Pull a single account from the BadgerMaps API and store it in the local database.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Pull a single account from the BadgerMaps API and store it in the local database.
+
+With --with-checkins or --with-routes, its check-ins or the routes that
+visit it are fetched as well and everything is stored in one transaction.`,
+		Args: cobra.ExactArgs(1),
 	}
 	opts := bindResponseSaveFlags(cmd)
+	var with pull.BundleOptions
+	cmd.Flags().BoolVar(&with.Checkins, "with-checkins", false, "Also pull the account's check-ins")
+	cmd.Flags().BoolVar(&with.Routes, "with-routes", false, "Also pull the routes with a stop at the account")
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		accountID, err := strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid account ID: %s", args[0])
 		}
+		if with.Checkins || with.Routes {
+			return presenter.HandlePullAccountBundle(accountID, with, *opts)
+		}
 		return presenter.HandlePullAccount(accountID, *opts)
 	}
 	return cmd
@@ -137,3 +153,29 @@ func pullProfileCmd(presenter *CliPresenter) *cobra.Command {
 	}
 	return cmd
 }
+
+func pullLocationsCmd(presenter *CliPresenter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "locations",
+		Short: "Refresh account locations from BadgerMaps",
+		Long: `Refresh the stored location and geocode of every local account from the BadgerMaps customers list.
+Only the AccountLocations table is updated, using a single API request instead of a full account pull.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return presenter.HandlePullLocations()
+		},
+	}
+	return cmd
+}
+
+func pullDatasetsCmd(presenter *CliPresenter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datasets",
+		Short: "Refresh profile datasets from BadgerMaps",
+		Long: `Refresh the DataSets and DataSetValues tables from the user profile without updating the rest of the profile.
+Pull the profile once first so the datasets have a profile to belong to.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return presenter.HandlePullDatasets()
+		},
+	}
+	return cmd
+}
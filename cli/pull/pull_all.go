@@ -4,6 +4,8 @@ import (
 	"badgermaps/app"
 	"badgermaps/app/pull"
 	"badgermaps/events"
+	"badgermaps/utils"
+	"bufio"
 	"fmt"
 	"log"
 	"os"
@@ -16,39 +18,80 @@ var bar *progressbar.ProgressBar
 
 func PullAllCmd(a *app.App) *cobra.Command {
 	var top int
+	var preview, confirm bool
 
 	cmd := &cobra.Command{
 		Use:   "all",
 		Short: "Pull all accounts, checkins, and routes from BadgerMaps.",
 		Long:  `Pulls all data including accounts, check-ins, and routes from the BadgerMaps API and stores it in the local database.`,
-		Run: func(cmd *cobra.Command, args []string) {
-			runPullGroup(a, top)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if preview || confirm {
+				var proceed bool
+				top, proceed = previewPullImpact(a, top, confirm && !preview)
+				if !proceed {
+					return nil
+				}
+			}
+			return runPullGroup(a, top)
 		},
 	}
 
 	cmd.Flags().IntVar(&top, "top", 0, "Pull only the top N accounts (for testing).")
+	cmd.Flags().BoolVar(&preview, "preview", false, "Show the estimated impact of the pull and exit without pulling.")
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "Show the estimated impact and ask for confirmation before pulling.")
 
 	return cmd
 }
 
-func runPullGroup(a *app.App, top int) {
+// previewPullImpact prints the impact estimate and, when ask is set, lets the
+// user confirm or narrow the number of accounts. It returns the (possibly
+// narrowed) top value and whether the pull should proceed.
+func previewPullImpact(a *app.App, top int, ask bool) (int, bool) {
+	impact, err := pull.EstimatePullImpact(a, top)
+	if err != nil {
+		a.Events.Dispatch(events.Errorf("pull", "Failed to estimate pull impact: %v", err))
+		os.Exit(1)
+	}
+	fmt.Println(impact.String())
+	if !ask {
+		return top, false
+	}
+	if a.State.NoInput {
+		a.Events.Dispatch(events.Warningf("pull", "--no-input set; skipping confirmation and pulling."))
+		return top, true
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if !utils.PromptBool(reader, "Proceed with the pull?", true) {
+		a.Events.Dispatch(events.Infof("pull", "Pull cancelled."))
+		return top, false
+	}
+	narrowed := utils.PromptInt(reader, "Limit to the top N accounts (0 for all)", top)
+	if narrowed < 0 {
+		narrowed = 0
+	}
+	return narrowed, true
+}
+
+// runPullGroup pulls accounts, check-ins, routes, and the profile, stopping
+// at the first that fails. Its error keeps the failure's kind so the
+// command exits with the matching code.
+func runPullGroup(a *app.App, top int) error {
 	// Validate prerequisites before attempting to pull.
 	// Without these checks, the pull command would silently fail when API calls return errors
 	// due to missing credentials or database connection, making it difficult for users to
 	// understand why the command isn't working.
 	if a.API == nil || a.API.APIKey == "" {
-		fmt.Fprintf(os.Stderr, "Error: API key is not configured. Please run 'badgermaps config' to set up your API credentials.\n")
-		os.Exit(1)
+		return fmt.Errorf("API key is not configured. Please run 'badgermaps config' to set up your API credentials")
 	}
 
 	if a.DB == nil {
-		fmt.Fprintf(os.Stderr, "Error: Database is not configured. Please run 'badgermaps config' to set up your database.\n")
-		os.Exit(1)
+		return fmt.Errorf("database is not configured. Please run 'badgermaps config' to set up your database")
 	}
 
 	if !a.DB.IsConnected() {
-		fmt.Fprintf(os.Stderr, "Error: Database is not connected. Please check your database configuration.\n")
-		os.Exit(1)
+		return fmt.Errorf("database is not connected. Please check your database configuration")
 	}
 
 	log.SetOutput(os.Stderr) // Configure logger to write to stderr
@@ -58,7 +101,7 @@ func runPullGroup(a *app.App, top int) {
 		case "pull.group.start":
 			bar = progressbar.NewOptions(-1,
 				progressbar.OptionSetDescription(fmt.Sprintf("Starting pull for %s...", e.Source)),
-				progressbar.OptionSetWriter(os.Stderr),
+				progressbar.OptionSetWriter(utils.ProgressWriter(a.State)),
 				progressbar.OptionSpinnerType(14),
 				progressbar.OptionEnableColorCodes(true),
 			)
@@ -68,10 +111,8 @@ func runPullGroup(a *app.App, top int) {
 				bar.ChangeMax(payload.Count)
 				bar.Describe(fmt.Sprintf("Found %d %s to pull.", payload.Count, e.Source))
 			}
-		case "pull.store.success":
-			if bar != nil {
-				bar.Add(1)
-			}
+		case "pull.progress":
+			showProgress(bar, e.Payload.(events.ProgressPayload))
 		case "pull.group.error":
 			payload := e.Payload.(events.ErrorPayload)
 			if bar != nil {
@@ -92,25 +133,22 @@ func runPullGroup(a *app.App, top int) {
 	// --- Execute Pull Operations ---
 	a.Events.Dispatch(events.Infof("pull", "Starting data pull from BadgerMaps API..."))
 
-	if err := pull.PullGroupAccounts(a, top, nil); err != nil {
-		a.Events.Dispatch(events.Errorf("pull", "Failed to pull accounts: %v", err))
-		os.Exit(1)
+	if err := pull.PullGroupAccounts(a, top); err != nil {
+		return fmt.Errorf("failed to pull accounts: %w", err)
 	}
 
-	if err := pull.PullGroupCheckins(a, nil); err != nil {
-		a.Events.Dispatch(events.Errorf("pull", "Failed to pull checkins: %v", err))
-		os.Exit(1)
+	if err := pull.PullGroupCheckins(a); err != nil {
+		return fmt.Errorf("failed to pull checkins: %w", err)
 	}
 
-	if err := pull.PullGroupRoutes(a, nil); err != nil {
-		a.Events.Dispatch(events.Errorf("pull", "Failed to pull routes: %v", err))
-		os.Exit(1)
+	if err := pull.PullGroupRoutes(a); err != nil {
+		return fmt.Errorf("failed to pull routes: %w", err)
 	}
 
 	if _, err := pull.PullProfile(a, nil); err != nil {
-		a.Events.Dispatch(events.Errorf("pull", "Failed to pull user profile: %v", err))
-		os.Exit(1)
+		return fmt.Errorf("failed to pull user profile: %w", err)
 	}
 
 	a.Events.Dispatch(events.Infof("pull", "✔ All data pulled successfully!"))
+	return nil
 }
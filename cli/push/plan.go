@@ -0,0 +1,42 @@
+package push
+
+import (
+	"badgermaps/app/push"
+
+	"github.com/spf13/cobra"
+)
+
+// selectionFlags adds the flags that push part of the pending changes now
+// and leave the rest queued.
+func selectionFlags(cmd *cobra.Command, sel *push.PushSelection) {
+	cmd.Flags().IntVar(&sel.Limit, "limit", 0, "Push only the first N matching changes; the rest stay queued")
+	cmd.Flags().StringVar(&sel.Filter.Type, "change-type", "", "Push only changes of this type (CREATE, UPDATE, or DELETE)")
+	cmd.Flags().IntVarP(&sel.Filter.AccountID, "account", "a", 0, "Push only changes to this account")
+	cmd.Flags().DurationVar(&sel.Filter.OlderThan, "older-than", 0, "Push only changes staged at least this long ago (e.g. 24h)")
+}
+
+func planCmd(presenter *CliPresenter) *cobra.Command {
+	var entityType string
+	var sel push.PushSelection
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Estimate the API calls and time a push would take",
+		Long: `Counts the API calls pushing the pending changes takes, by operation, and
+estimates how long it runs with the configured push workers and request
+rates. With --limit or a filter, the plan splits the changes into those the
+same flags on 'push accounts' or 'push checkins' send now and those they leave
+queued, and says when the push window next opens to send the rest.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return presenter.HandlePlan(entityType, sel, asJSON)
+		},
+	}
+
+	cmd.Flags().StringVarP(&entityType, "type", "t", "all", "Type of entity to plan (accounts, checkins, or all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the plan as JSON")
+	selectionFlags(cmd, &sel)
+	return cmd
+}
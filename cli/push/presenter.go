@@ -1,12 +1,21 @@
 package push
 
 import (
+	"badgermaps/api"
 	"badgermaps/app"
 	"badgermaps/app/push"
 	"badgermaps/database"
+	"badgermaps/errs"
 	"badgermaps/events"
+	"badgermaps/utils"
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -92,9 +101,148 @@ func (p *CliPresenter) HandleList(entityType, status, date string, accountID int
 	return nil
 }
 
-// HandlePushAccounts orchestrates pushing pending account changes.
-func (p *CliPresenter) HandlePushAccounts() error {
+// HandlePreview prints the request each pending change of entityType would
+// be pushed as, or a cURL command for it when curl is set.
+func (p *CliPresenter) HandlePreview(entityType string, accountID int, curl bool) error {
+	results, err := push.GetFilteredPendingChanges(p.App, entityType, push.PushFilterOptions{Status: "pending", AccountID: accountID, OrderBy: "date"})
+	if err != nil {
+		return err
+	}
+	type previewed struct {
+		title   string
+		preview func() (*api.RequestPreview, error)
+	}
+	var changes []previewed
+	switch pending := results.(type) {
+	case []database.AccountPendingChange:
+		for _, c := range pending {
+			changes = append(changes, previewed{
+				title:   fmt.Sprintf("Change %d: %s account %d", c.ChangeId, c.ChangeType, c.AccountId),
+				preview: func() (*api.RequestPreview, error) { return push.PreviewAccountChange(p.App, c) },
+			})
+		}
+	case []database.CheckinPendingChange:
+		for _, c := range pending {
+			changes = append(changes, previewed{
+				title:   fmt.Sprintf("Change %d: %s check-in for account %d", c.ChangeId, c.ChangeType, c.AccountId),
+				preview: func() (*api.RequestPreview, error) { return push.PreviewCheckinChange(p.App, c) },
+			})
+		}
+	}
+	if len(changes) == 0 {
+		p.App.Events.Dispatch(events.Infof("push", "No pending %s changes found.", entityType))
+		return nil
+	}
+
+	for i, c := range changes {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s\n", c.title)
+		preview, err := c.preview()
+		switch {
+		case err != nil:
+			fmt.Printf("# not pushable: %v\n", err)
+		case preview == nil:
+			fmt.Println("# nothing to send; every staged field is pull-only")
+		case curl:
+			fmt.Println(preview.Curl())
+		default:
+			fmt.Printf("%s %s\n", preview.Method, preview.URL)
+			if preview.IdempotencyKey != "" {
+				fmt.Printf("Idempotency-Key: %s\n", preview.IdempotencyKey)
+			}
+			if preview.Body != "" {
+				fmt.Println(preview.Body)
+			}
+		}
+	}
+	return nil
+}
+
+// HandlePlan prints the API calls and time pushing the pending changes of
+// entityType ("accounts", "checkins", or "all") takes, split into the
+// changes sel pushes now and those it leaves queued.
+func (p *CliPresenter) HandlePlan(entityType string, sel push.PushSelection, asJSON bool) error {
+	entities := []string{entityType}
+	if entityType == "all" {
+		entities = app.PushEntities
+	}
+	plans := make([]*push.PushPlan, 0, len(entities))
+	for _, entity := range entities {
+		plan, err := push.PlanPush(p.App, entity, sel)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, plan)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plans)
+	}
+	for i, plan := range plans {
+		if i > 0 {
+			fmt.Println()
+		}
+		printPlan(plan, !sel.IsZero())
+	}
+	return nil
+}
+
+func printPlan(plan *push.PushPlan, selected bool) {
+	now := plan.Now
+	fmt.Printf("%s: %d pending change(s)\n", now.Entity, now.Changes+plan.Later.Changes)
+	if now.Changes+plan.Later.Changes == 0 {
+		return
+	}
+	label := "Push"
+	if selected {
+		label = "Now"
+	}
+	printEstimate(label, now)
+	if !selected {
+		return
+	}
+	printEstimate("Later", plan.Later)
+	switch {
+	case plan.Later.Changes == 0:
+	case !plan.LaterAt.IsZero():
+		fmt.Printf("  The queued changes are pushed when the push window opens at %s.\n", plan.LaterAt.Format("Mon Jan 2 15:04 MST"))
+	default:
+		fmt.Println("  The queued changes wait for the next push.")
+	}
+}
+
+// roughDuration rounds d to the second for an estimate.
+func roughDuration(d time.Duration) string {
+	if d > 0 && d < time.Second {
+		return "a second"
+	}
+	return d.Round(time.Second).String()
+}
+
+func printEstimate(label string, estimate push.PushEstimate) {
+	rate := "no rate limit"
+	if estimate.RequestsPerSecond > 0 {
+		rate = fmt.Sprintf("%g request(s)/s", estimate.RequestsPerSecond)
+	}
+	fmt.Printf("  %-5s %d change(s), %d API call(s), about %s with %d worker(s) and %s\n",
+		label, estimate.Changes, estimate.Calls, roughDuration(estimate.Duration), estimate.Workers, rate)
+	for _, op := range estimate.Operations {
+		fmt.Printf("        %-34s %5d change(s) %5d call(s)\n", op.Operation, op.Changes, op.Calls)
+	}
+	if estimate.Held > 0 {
+		fmt.Printf("        %d delete(s) are held until confirmed (--confirm-deletes)\n", estimate.Held)
+	}
+}
+
+// HandlePushAccounts orchestrates pushing the pending account changes sel
+// selects.
+func (p *CliPresenter) HandlePushAccounts(sel push.PushSelection) error {
 	var bar *progressbar.ProgressBar
+	var hints remediation
+	var failed failures
 
 	pushListener := func(e events.Event) {
 		if e.Source != "accounts" {
@@ -103,9 +251,13 @@ func (p *CliPresenter) HandlePushAccounts() error {
 		switch e.Type {
 		case "push.scan.start":
 			p.App.Events.Dispatch(events.Infof("push", "Scanning for pending %s changes...", e.Source))
+		case "push.progress":
+			bar = showProgress(p.App, bar, e.Payload.(events.ProgressPayload))
 		case "push.item.error":
 			payload := e.Payload.(events.PushItemErrorPayload)
 			p.App.Events.Dispatch(events.Errorf("push", "An error occurred during push: %v", payload.Error))
+			hints.add(payload.Error)
+			failed.add(payload.Error)
 		case "push.error":
 			payload := e.Payload.(events.ErrorPayload)
 			p.App.Events.Dispatch(events.Errorf("push", "An error occurred during push scan: %v", payload.Error))
@@ -115,17 +267,46 @@ func (p *CliPresenter) HandlePushAccounts() error {
 			}
 			payload := e.Payload.(events.PushCompletePayload)
 			p.App.Events.Dispatch(events.Infof("push", "✔ Push for %s complete. Encountered %d errors.", e.Source, payload.ErrorCount))
+			hints.report(p.App)
 		}
 	}
 
 	p.App.Events.Subscribe("push.*", pushListener)
 
-	return push.RunPushAccounts(p.App)
+	if err := p.confirmDeletes(); err != nil {
+		return err
+	}
+	if err := queuedIsSuccess(push.RunPushAccountsSelected(p.App, sel)); err != nil {
+		return err
+	}
+	return failed.err(p.App, "account")
 }
 
-// HandlePushCheckins orchestrates pushing pending check-in changes.
-func (p *CliPresenter) HandlePushCheckins() error {
+// confirmDeletes asks before pending account deletions are pushed when the
+// recycle bin policy requires it. Without an answer the deletions stay
+// queued and the rest of the changes are pushed.
+func (p *CliPresenter) confirmDeletes() error {
+	if !p.App.DeletesNeedConfirmation() || p.App.State.NoInput {
+		return nil
+	}
+	count, err := push.CountPendingDeletes(p.App)
+	if err != nil || count == 0 {
+		return err
+	}
+	days := int(p.App.RecycleBinRetention().Hours() / 24)
+	question := fmt.Sprintf("Push %d account deletion(s)? Deleted accounts can be restored for %d days with 'badgermaps restore account <id>'.", count, days)
+	if utils.PromptBool(bufio.NewReader(os.Stdin), question, false) {
+		p.App.State.ConfirmDeletes = true
+	}
+	return nil
+}
+
+// HandlePushCheckins orchestrates pushing the pending check-in changes sel
+// selects.
+func (p *CliPresenter) HandlePushCheckins(sel push.PushSelection) error {
 	var bar *progressbar.ProgressBar
+	var hints remediation
+	var failed failures
 
 	pushListener := func(e events.Event) {
 		// Only listen for checkin events
@@ -136,23 +317,13 @@ func (p *CliPresenter) HandlePushCheckins() error {
 		switch e.Type {
 		case "push.scan.start":
 			p.App.Events.Dispatch(events.Infof("push", "Scanning for pending %s changes...", e.Source))
-		case "push.scan.complete":
-			payload := e.Payload.(events.PushScanCompletePayload)
-			changes := payload.Changes.([]database.CheckinPendingChange)
-			if len(changes) > 0 {
-				bar = progressbar.NewOptions(len(changes),
-					progressbar.OptionSetDescription(fmt.Sprintf("Pushing %d %s changes", len(changes), e.Source)),
-					progressbar.OptionSetWriter(os.Stderr),
-					progressbar.OptionEnableColorCodes(true),
-				)
-			}
-		case "push.item.success":
-			if bar != nil {
-				bar.Add(1)
-			}
+		case "push.progress":
+			bar = showProgress(p.App, bar, e.Payload.(events.ProgressPayload))
 		case "push.item.error":
 			payload := e.Payload.(events.PushItemErrorPayload)
 			p.App.Events.Dispatch(events.Errorf("push", "An error occurred during push: %v", payload.Error))
+			hints.add(payload.Error)
+			failed.add(payload.Error)
 		case "push.error":
 			payload := e.Payload.(events.ErrorPayload)
 			p.App.Events.Dispatch(events.Errorf("push", "An error occurred during push scan: %v", payload.Error))
@@ -162,18 +333,125 @@ func (p *CliPresenter) HandlePushCheckins() error {
 			}
 			payload := e.Payload.(events.PushCompletePayload)
 			p.App.Events.Dispatch(events.Infof("push", "✔ Push for %s complete. Encountered %d errors.", e.Source, payload.ErrorCount))
+			hints.report(p.App)
 		}
 	}
 
 	p.App.Events.Subscribe("push.*", pushListener)
 
-	return push.RunPushCheckins(p.App)
+	if err := queuedIsSuccess(push.RunPushCheckinsSelected(p.App, sel)); err != nil {
+		return err
+	}
+	return failed.err(p.App, "check-in")
 }
 
-// HandlePushAll orchestrates pushing all pending changes.
+// HandlePushAll orchestrates pushing all pending changes. Check-ins are
+// still pushed when only some account changes failed.
 func (p *CliPresenter) HandlePushAll() error {
-	if err := p.HandlePushAccounts(); err != nil {
+	accountsErr := p.HandlePushAccounts(push.PushSelection{})
+	if accountsErr != nil && !errors.Is(accountsErr, errs.ErrPartial) {
+		return accountsErr
+	}
+	return errors.Join(accountsErr, p.HandlePushCheckins(push.PushSelection{}))
+}
+
+// HandleStage validates and stages changes authored outside the app.
+func (p *CliPresenter) HandleStage(requests []app.StageRequest) error {
+	staged, err := p.App.StageChanges(requests)
+	for _, request := range staged {
+		if request.Duplicate {
+			fmt.Printf("Already staged %s under idempotency key %s; skipped.\n", request, request.IdempotencyKey)
+			continue
+		}
+		fmt.Printf("Staged %s (%d field(s)).\n", request, len(request.Fields))
+	}
+	return err
+}
+
+// HandleStageJSON stages the changes read from path, or from stdin when
+// path is "-".
+func (p *CliPresenter) HandleStageJSON(path string, stdin io.Reader) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read changes: %w", err)
+	}
+	requests, err := app.ParseStageRequests(data)
+	if err != nil {
 		return err
 	}
-	return p.HandlePushCheckins()
+	return p.HandleStage(requests)
+}
+
+// showProgress moves bar to the latest progress event, creating it on the
+// first event with a known total.
+func showProgress(a *app.App, bar *progressbar.ProgressBar, payload events.ProgressPayload) *progressbar.ProgressBar {
+	if bar == nil {
+		if payload.Total == 0 {
+			return nil
+		}
+		bar = progressbar.NewOptions(payload.Total,
+			progressbar.OptionSetDescription(fmt.Sprintf("Pushing %d %s changes", payload.Total, payload.Entity)),
+			progressbar.OptionSetWriter(utils.ProgressWriter(a.State)),
+			progressbar.OptionEnableColorCodes(true),
+		)
+	}
+	bar.Set(payload.Processed)
+	bar.Describe(payload.Summary())
+	return bar
+}
+
+// queuedIsSuccess treats a push deferred by the push window as success; the
+// changes stay pending and the reason has already been reported.
+func queuedIsSuccess(err error) error {
+	var outside *push.OutsideWindowError
+	if errors.As(err, &outside) {
+		return nil
+	}
+	return err
+}
+
+// failures collects the errors of the changes that fail in one push so the
+// command can exit with errs.ExitPartial.
+type failures struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (f *failures) add(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs = append(f.errs, err)
+}
+
+// err waits for queued events and returns the failures as one
+// errs.ErrPartial error, or nil when every change was pushed.
+func (f *failures) err(a *app.App, what string) error {
+	a.Events.WaitForDrain(time.Second)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return errs.Partial(fmt.Sprintf("%d %s change(s) failed to push", len(f.errs), what), f.errs)
+}
+
+// remediation collects the distinct hints of the errors in one push so each
+// is shown once at the end instead of after every failed change.
+type remediation []string
+
+func (r *remediation) add(err error) {
+	hint := errs.Hint(err)
+	if hint == "" || slices.Contains(*r, hint) {
+		return
+	}
+	*r = append(*r, hint)
+}
+
+func (r remediation) report(a *app.App) {
+	for _, hint := range r {
+		a.Events.Dispatch(events.Infof("push", "Hint: %s", hint))
+	}
 }
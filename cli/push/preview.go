@@ -0,0 +1,26 @@
+package push
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func previewCmd(presenter *CliPresenter) *cobra.Command {
+	var entityType string
+	var accountID int
+	var curl bool
+
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Show the API requests that pushing staged changes would send",
+		Long:  `Renders, for each pending change, the HTTP method, URL, and encoded form body that a push would send, without sending anything. With --curl each request is printed as a cURL command, with the API key left as $API_KEY, for reproducing a request with BadgerMaps support.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return presenter.HandlePreview(entityType, accountID, curl)
+		},
+	}
+
+	cmd.Flags().StringVarP(&entityType, "type", "t", "accounts", "Type of entity to preview (accounts or checkins)")
+	cmd.Flags().IntVarP(&accountID, "account", "a", 0, "Filter by Account ID")
+	cmd.Flags().BoolVar(&curl, "curl", false, "Print each request as a cURL command")
+
+	return cmd
+}
@@ -2,6 +2,7 @@ package push
 
 import (
 	"badgermaps/app"
+	"badgermaps/app/push"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -21,34 +22,48 @@ func PushCmd(App *app.App) *cobra.Command {
 		},
 	}
 
+	pushCmd.PersistentFlags().BoolVar(&App.State.IgnorePushWindow, "ignore-window", false, "Push immediately even outside the configured push window")
+	pushCmd.PersistentFlags().BoolVar(&App.State.PushToSandbox, "sandbox", false, "Send pushes to api.sandbox_url instead of production; changes stay queued")
+	pushCmd.PersistentFlags().BoolVar(&App.State.ConfirmDeletes, "confirm-deletes", false, "Push pending account deletions without asking; deleted accounts go to the recycle bin")
+	pushCmd.PersistentFlags().BoolVar(&App.State.SkipSchemaCheck, "force", false, "Push even when the database schema is invalid or a migration is pending")
+
 	pushCmd.AddCommand(pushAccountsCmd(presenter))
 	pushCmd.AddCommand(pushCheckinsCmd(presenter))
 	pushCmd.AddCommand(pushAllCmd(presenter))
 	pushCmd.AddCommand(listCmd(presenter))
+	pushCmd.AddCommand(previewCmd(presenter))
+	pushCmd.AddCommand(planCmd(presenter))
+	pushCmd.AddCommand(stageCmd(presenter))
 	return pushCmd
 }
 
 func pushAccountsCmd(presenter *CliPresenter) *cobra.Command {
+	var sel push.PushSelection
 	cmd := &cobra.Command{
 		Use:   "accounts",
 		Short: "Push pending account changes to BadgerMaps",
-		Long:  `Push pending account changes from your local database to the BadgerMaps API.`,
+		Long:  `Push pending account changes from your local database to the BadgerMaps API. With --limit or a filter only part of them is pushed and the rest stay queued; an account's changes always go out in order, so a change waits while an earlier one to its account is queued. 'push plan' shows the split first.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return presenter.HandlePushAccounts()
+			cmd.SilenceUsage = true
+			return presenter.HandlePushAccounts(sel)
 		},
 	}
+	selectionFlags(cmd, &sel)
 	return cmd
 }
 
 func pushCheckinsCmd(presenter *CliPresenter) *cobra.Command {
+	var sel push.PushSelection
 	cmd := &cobra.Command{
 		Use:   "checkins",
 		Short: "Push pending check-in changes to BadgerMaps",
-		Long:  `Push pending check-in changes from your local database to the BadgerMaps API.`,
+		Long:  `Push pending check-in changes from your local database to the BadgerMaps API. With --limit or a filter only part of them is pushed and the rest stay queued; 'push plan' shows the split first.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return presenter.HandlePushCheckins()
+			cmd.SilenceUsage = true
+			return presenter.HandlePushCheckins(sel)
 		},
 	}
+	selectionFlags(cmd, &sel)
 	return cmd
 }
 
@@ -58,6 +73,7 @@ func pushAllCmd(presenter *CliPresenter) *cobra.Command {
 		Short: "Push all pending changes to BadgerMaps",
 		Long:  `Push all pending changes (accounts and check-ins) from your local database to the BadgerMaps API.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
 			return presenter.HandlePushAll()
 		},
 	}
@@ -3,17 +3,23 @@ package push
 import (
 	"badgermaps/api"
 	"badgermaps/app"
+	"badgermaps/app/push"
 	"badgermaps/app/state"
 	"badgermaps/database"
+	"badgermaps/errs"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestPushAccountsCmd(t *testing.T) {
@@ -119,6 +125,79 @@ func TestPushCheckinsCmd(t *testing.T) {
 	}
 }
 
+func TestPushCheckinsSendsIdempotencyKeys(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 456, "customer": 123}`))
+	}))
+	defer server.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+
+	checkin := database.CheckinPendingChange{
+		AccountId:      123,
+		Type:           sql.NullString{String: "Phone Call", Valid: true},
+		Comments:       sql.NullString{String: "Left a message", Valid: true},
+		EndpointType:   sql.NullString{String: "standard", Valid: true},
+		ChangeType:     "CREATE",
+		IdempotencyKey: sql.NullString{String: "visit-1", Valid: true},
+	}
+	// The same check-in staged twice under one key, as a retried import
+	// would, is only sent once.
+	for i := 0; i < 2; i++ {
+		if err := database.StageCheckinChange(db, checkin); err != nil {
+			t.Fatalf("Failed to stage check-in: %v", err)
+		}
+	}
+	// A check-in staged by a trigger has no key and gets one at push time.
+	if _, err := db.GetDB().Exec("INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, Type, EndpointType, ChangeType) VALUES (0, 123, 'Drop-in', 'standard', 'CREATE')"); err != nil {
+		t.Fatalf("Failed to insert unkeyed check-in: %v", err)
+	}
+
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"checkins"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push checkins failed with error: %v", err)
+	}
+
+	if len(keys) != 2 || keys[0] != "visit-1" || keys[1] == "" || keys[1] == "visit-1" {
+		t.Fatalf("Idempotency-Key headers = %q, want visit-1 then a generated key", keys)
+	}
+	changes, err := database.GetCheckinChanges(db)
+	if err != nil {
+		t.Fatalf("Failed to read check-in changes: %v", err)
+	}
+	for _, change := range changes {
+		if change.Status != "completed" {
+			t.Errorf("change %d status = %q, want completed", change.ChangeId, change.Status)
+		}
+	}
+	if key := changes[2].IdempotencyKey.String; key != keys[1] {
+		t.Errorf("stored key of the unkeyed check-in = %q, want the sent %q", key, keys[1])
+	}
+}
+
 func TestPushCheckinsCmd_CustomEndpoint(t *testing.T) {
 	var postedForm url.Values
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -239,3 +318,503 @@ func TestMain(m *testing.M) {
 	}
 	os.Exit(m.Run())
 }
+
+func TestPushAccountsSkipsConflicts(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, FullName, UpdatedAt) VALUES (123, 'Test', '2024-01-01 00:00:00')"); err != nil {
+		t.Fatalf("Failed to seed account: %v", err)
+	}
+	if err := database.StageAccountChange(db, 123, "UPDATE", `{"last_name":"Staged"}`); err != nil {
+		t.Fatalf("Failed to stage change: %v", err)
+	}
+	if _, err := sqlDB.Exec("UPDATE Accounts SET UpdatedAt = '2024-03-01 00:00:00' WHERE AccountId = 123"); err != nil {
+		t.Fatalf("Failed to update account: %v", err)
+	}
+
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+	requests = 0
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"accounts"})
+	err = cmd.Execute()
+	if !errors.Is(err, errs.ErrConflict) || errs.ExitCode(err) != errs.ExitPartial {
+		t.Fatalf("push accounts = %v, want a partial failure caused by the conflict", err)
+	}
+
+	if requests != 0 {
+		t.Fatalf("expected conflicting change not to reach the API, got %d requests", requests)
+	}
+	var status string
+	if err := sqlDB.QueryRow("SELECT Status FROM AccountsPendingChanges WHERE AccountId = 123").Scan(&status); err != nil {
+		t.Fatalf("Failed to read change status: %v", err)
+	}
+	if status != "failed" {
+		t.Fatalf("expected conflicting change to be marked failed, got %q", status)
+	}
+}
+
+func TestPushAccountsQueuedOutsideWindow(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			requests++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+	if err := database.StageAccountChange(db, 123, "UPDATE", `{"last_name":"Queued"}`); err != nil {
+		t.Fatalf("Failed to stage change: %v", err)
+	}
+
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+	// A window on a day that never matches the current one keeps it closed.
+	closedDay := strings.ToLower(time.Now().UTC().AddDate(0, 0, 3).Weekday().String())
+	app.Config.PushWindow.Enabled = true
+	app.Config.PushWindow.Days = []string{closedDay}
+	app.Config.PushWindow.Timezone = "UTC"
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"accounts"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push outside window should not fail: %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("expected no API requests outside the window, got %d", requests)
+	}
+	var status string
+	if err := db.GetDB().QueryRow("SELECT Status FROM AccountsPendingChanges WHERE AccountId = 123").Scan(&status); err != nil {
+		t.Fatalf("Failed to read change status: %v", err)
+	}
+	if status != "pending" {
+		t.Fatalf("expected change to stay pending, got %q", status)
+	}
+
+	cmd = PushCmd(app)
+	cmd.SetArgs([]string{"accounts", "--ignore-window"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push with --ignore-window failed: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected --ignore-window to push the queued change, got %d requests", requests)
+	}
+}
+
+func TestPushAccountsToSandbox(t *testing.T) {
+	productionWrites := 0
+	production := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			productionWrites++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer production.Close()
+	sandboxWrites := 0
+	sandbox := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			sandboxWrites++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer sandbox.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+	if err := database.StageAccountChange(db, 123, "UPDATE", `{"last_name":"Rehearsed"}`); err != nil {
+		t.Fatalf("Failed to stage change: %v", err)
+	}
+
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: production.URL})
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"accounts", "--sandbox"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --sandbox without api.sandbox_url to fail")
+	}
+	if productionWrites != 0 {
+		t.Fatalf("expected an unconfigured sandbox not to fall back to production, got %d writes", productionWrites)
+	}
+
+	app.Config.API.SandboxURL = sandbox.URL
+	cmd = PushCmd(app)
+	cmd.SetArgs([]string{"accounts", "--sandbox"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push accounts --sandbox failed: %v", err)
+	}
+	if sandboxWrites != 1 || productionWrites != 0 {
+		t.Fatalf("expected the push to reach only the sandbox, got sandbox=%d production=%d", sandboxWrites, productionWrites)
+	}
+	var status string
+	if err := db.GetDB().QueryRow("SELECT Status FROM AccountsPendingChanges WHERE AccountId = 123").Scan(&status); err != nil {
+		t.Fatalf("Failed to read change status: %v", err)
+	}
+	if status != "pending" {
+		t.Fatalf("expected sandbox push to leave the change pending, got %q", status)
+	}
+}
+
+func TestPushAccountDeleteUsesRecycleBin(t *testing.T) {
+	var deletes, creates int
+	var created url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			creates++
+			body, _ := io.ReadAll(r.Body)
+			created, _ = url.ParseQuery(string(body))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 999, "last_name": "Acme", "full_name": "Acme"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	a := app.NewApp()
+	a.State.NoColor = true
+	a.State.NoInput = true
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec(`INSERT INTO Accounts (AccountId, LastName, FullName, PhoneNumber, OriginalAddress) VALUES (123, 'Acme', 'Acme', '555-0100', '1 Main St')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (123, 'DELETE', '{}')`); err != nil {
+		t.Fatal(err)
+	}
+	a.DB = db
+	a.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+
+	// Without confirmation the delete stays queued.
+	cmd := PushCmd(a)
+	cmd.SetArgs([]string{"accounts"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push accounts: %v", err)
+	}
+	if pending, _ := database.GetPendingAccountChanges(db); deletes != 0 || len(pending) != 1 {
+		t.Fatalf("unconfirmed delete was pushed: %d deletes, %d pending", deletes, len(pending))
+	}
+
+	cmd = PushCmd(a)
+	cmd.SetArgs([]string{"accounts", "--confirm-deletes"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push accounts --confirm-deletes: %v", err)
+	}
+	if deletes != 1 {
+		t.Fatalf("deletes = %d, want 1", deletes)
+	}
+	bin, err := a.DeletedAccounts()
+	if err != nil || len(bin) != 1 || bin[0].AccountId != 123 || bin[0].FullName != "Acme" {
+		t.Fatalf("recycle bin = %+v, %v", bin, err)
+	}
+
+	restored, err := push.RestoreDeletedAccount(a, 123)
+	if err != nil {
+		t.Fatalf("RestoreDeletedAccount: %v", err)
+	}
+	if creates != 1 || restored.AccountId.Int64 != 999 || created.Get("phone_number") != "555-0100" || created.Get("address") != "1 Main St" {
+		t.Fatalf("restore sent %v and returned %d", created, restored.AccountId.Int64)
+	}
+	if bin, _ := a.DeletedAccounts(); len(bin) != 0 {
+		t.Fatalf("restored account is still in the recycle bin: %+v", bin)
+	}
+}
+
+func TestPushAccountsSerializesEachAccount(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight = map[string]int{}
+		overlap  []string
+		total    int
+		maxTotal int
+		order    = map[string][]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPatch {
+			w.Write([]byte(`{}`))
+			return
+		}
+		r.ParseForm()
+		mu.Lock()
+		inFlight[r.URL.Path]++
+		if inFlight[r.URL.Path] > 1 {
+			overlap = append(overlap, r.URL.Path)
+		}
+		total++
+		if total > maxTotal {
+			maxTotal = total
+		}
+		order[r.URL.Path] = append(order[r.URL.Path], r.PostForm.Get("last_name"))
+		mu.Unlock()
+
+		time.Sleep(30 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[r.URL.Path]--
+		total--
+		mu.Unlock()
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+	app.Config.PushThroughput.Accounts.Workers = 3
+
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+	for _, step := range []string{"first", "second", "third"} {
+		for _, id := range []int{101, 102, 103} {
+			if err := database.StageAccountChange(db, id, "UPDATE", `{"last_name":"`+step+`"}`); err != nil {
+				t.Fatalf("Failed to stage change: %v", err)
+			}
+		}
+	}
+
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"accounts"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push accounts failed with error: %v", err)
+	}
+
+	if len(overlap) > 0 {
+		t.Fatalf("changes of one account were pushed concurrently: %v", overlap)
+	}
+	if maxTotal < 2 {
+		t.Errorf("at most %d request in flight, want accounts pushed in parallel", maxTotal)
+	}
+	if len(order) != 3 {
+		t.Fatalf("pushed accounts = %v, want 3", order)
+	}
+	for path, steps := range order {
+		if strings.Join(steps, ",") != "first,second,third" {
+			t.Errorf("%s pushed %v, want the staged order", path, steps)
+		}
+	}
+}
+
+func TestPreviewMatchesPushedRequests(t *testing.T) {
+	type sent struct{ method, url, key, body string }
+	var requests []sent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodGet {
+			requests = append(requests, sent{r.Method, "http://" + r.Host + r.URL.String(), r.Header.Get("Idempotency-Key"), string(body)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+	if _, err := db.GetDB().Exec("INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (123, 'UPDATE', ?)", `{"last_name":"O'Brien & Sons"}`); err != nil {
+		t.Fatalf("Failed to insert account change: %v", err)
+	}
+	err = database.StageCheckinChange(db, database.CheckinPendingChange{
+		AccountId:      123,
+		Type:           sql.NullString{String: "Phone Call", Valid: true},
+		Comments:       sql.NullString{String: "Left a message", Valid: true},
+		EndpointType:   sql.NullString{String: "standard", Valid: true},
+		ChangeType:     "CREATE",
+		IdempotencyKey: sql.NullString{String: "visit-1", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to stage check-in: %v", err)
+	}
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+
+	var previews []*api.RequestPreview
+	accountChanges, err := database.GetPendingAccountChanges(db)
+	if err != nil || len(accountChanges) != 1 {
+		t.Fatalf("pending account changes = %v, %v", accountChanges, err)
+	}
+	checkinChanges, err := database.GetPendingCheckinChanges(db)
+	if err != nil || len(checkinChanges) != 1 {
+		t.Fatalf("pending check-in changes = %v, %v", checkinChanges, err)
+	}
+	preview, err := push.PreviewAccountChange(app, accountChanges[0])
+	if err != nil {
+		t.Fatalf("PreviewAccountChange: %v", err)
+	}
+	previews = append(previews, preview)
+	if preview, err = push.PreviewCheckinChange(app, checkinChanges[0]); err != nil {
+		t.Fatalf("PreviewCheckinChange: %v", err)
+	}
+	previews = append(previews, preview)
+	if !strings.Contains(previews[0].Curl(), `'last_name=O%27Brien+%26+Sons'`) {
+		t.Errorf("cURL command does not carry the encoded body:\n%s", previews[0].Curl())
+	}
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"all"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push all failed with error: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(requests))
+	}
+	for i, p := range previews {
+		want := sent{p.Method, p.URL, p.IdempotencyKey, p.Body}
+		if i == 0 {
+			// The account change had no key, so the push issued one.
+			want.key = requests[i].key
+		}
+		if requests[i] != want {
+			t.Errorf("request %d = %+v, previewed %+v", i, requests[i], want)
+		}
+	}
+}
+
+func TestPushAccountsLimitLeavesRestQueued(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			requests++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	app := app.NewApp()
+	app.State.NoColor = true
+
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+	for _, accountID := range []int{1, 2, 3} {
+		if err := database.StageAccountChange(db, accountID, "UPDATE", `{"last_name":"Batch"}`); err != nil {
+			t.Fatalf("Failed to stage change: %v", err)
+		}
+	}
+	app.DB = db
+	app.API = api.NewAPIClient(&api.APIConfig{BaseURL: server.URL})
+
+	plan, err := push.PlanPush(app, "accounts", push.PushSelection{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Now.Changes != 2 || plan.Now.Calls != 2 || plan.Later.Changes != 1 || plan.Now.Duration <= 0 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	cmd := PushCmd(app)
+	cmd.SetArgs([]string{"accounts", "--limit", "2"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("push accounts --limit 2 failed: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 API requests, got %d", requests)
+	}
+	pending, err := database.GetPendingAccountChanges(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].AccountId != 3 {
+		t.Fatalf("expected the third change to stay queued, got %+v", pending)
+	}
+}
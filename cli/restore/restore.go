@@ -0,0 +1,94 @@
+package restore
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/push"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RestoreCmd creates the restore command for bringing back deleted accounts
+// from the recycle bin.
+func RestoreCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore deleted accounts from the recycle bin",
+		Long: `Accounts are copied to the recycle bin before their deletion is pushed and can
+be restored for recycle_bin.retention_days (default 30) days. Restoring
+re-creates the account through the API, so it gets a new account ID.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(accountCmd(a))
+	cmd.AddCommand(listCmd(a))
+	cmd.AddCommand(purgeCmd(a))
+	return cmd
+}
+
+func accountCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "account [id]",
+		Short: "Re-create a deleted account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid account ID: %s", args[0])
+			}
+			restored, err := push.RestoreDeletedAccount(a, accountID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Account %d restored as account %d.\n", accountID, restored.AccountId.Int64)
+			return nil
+		},
+	}
+}
+
+func listCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List deleted accounts that can be restored",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := a.DeletedAccounts()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("The recycle bin is empty.")
+				return nil
+			}
+			expires := a.RecycleBinRetention()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Account ID\tName\tDeleted At\tRestorable Until")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", e.AccountId, e.FullName, e.DeletedAt.Local().Format(time.RFC3339), e.DeletedAt.Add(expires).Local().Format("2006-01-02"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func purgeCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "purge",
+		Short: "Remove deleted accounts older than the retention window",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := a.PurgeRecycleBin()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Purged %d expired account(s) from the recycle bin.\n", n)
+			return nil
+		},
+	}
+}
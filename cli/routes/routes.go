@@ -0,0 +1,477 @@
+package routes
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/app/push"
+	"badgermaps/database"
+	"badgermaps/utils"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RoutesCmd creates the routes command, which plans a week of routes from
+// the routes pulled before and pushes the new ones.
+func RoutesCmd(App *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "Plan routes by week and push new ones",
+		Long: `Plan routes a week at a time. 'routes week' shows the pulled routes and the
+local plans of a week by weekday. 'routes copy' and 'routes copy-week' copy
+pulled routes, with their stops, into plans for another day, such as the same
+day next week. Plans are edited locally with 'routes plan' and created in
+BadgerMaps with 'routes push'.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(weekCmd(App))
+	cmd.AddCommand(copyCmd(App))
+	cmd.AddCommand(copyWeekCmd(App))
+	cmd.AddCommand(planCmd(App))
+	cmd.AddCommand(pushCmd(App))
+	return cmd
+}
+
+func weekCmd(App *app.App) *cobra.Command {
+	var (
+		week     string
+		pullFrom bool
+	)
+	cmd := &cobra.Command{
+		Use:   "week",
+		Short: "Show a week of routes and plans by weekday",
+		Long: `Shows the routes pulled for a week and the plans made for it, Monday first.
+--week takes any date in the week (2026-10-19) and defaults to this week in
+the display timezone. --pull pulls all routes from BadgerMaps first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			start, err := App.ParseWeek(week)
+			if err != nil {
+				return fmt.Errorf("--week: %w", err)
+			}
+			if pullFrom {
+				if err := pull.PullGroupRoutes(App); err != nil {
+					return err
+				}
+			}
+			plan, err := App.RouteWeek(start)
+			if err != nil {
+				return err
+			}
+			writeWeek(cmd.OutOrStdout(), plan)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&week, "week", "", "A date in the week to show, as YYYY-MM-DD (default this week)")
+	cmd.Flags().BoolVar(&pullFrom, "pull", false, "Pull all routes from BadgerMaps first")
+	return cmd
+}
+
+func writeWeek(out io.Writer, week *app.RouteWeek) {
+	c := utils.Colors
+	fmt.Fprintf(out, "Week of %s\n", week.Start.Format("Mon Jan 2, 2006"))
+	for _, day := range week.Days {
+		fmt.Fprintf(out, "\n%s\n", c.Bold("%s", day.Date.Format("Monday, Jan 2")))
+		if len(day.Routes) == 0 && len(day.Plans) == 0 {
+			fmt.Fprintln(out, c.Gray("  No routes"))
+			continue
+		}
+		for _, route := range day.Routes {
+			line := fmt.Sprintf("  route %d  %s", route.RouteId.Int64, route.Name.String)
+			if route.StartTime.String != "" {
+				line += "  starts " + route.StartTime.String
+			}
+			fmt.Fprintln(out, line)
+		}
+		for _, plan := range day.Plans {
+			status := c.Yellow("draft")
+			if plan.Status == database.RoutePlanPushed {
+				status = c.Green("pushed as route %d", plan.RouteId)
+			}
+			fmt.Fprintf(out, "  plan %d   %s  %d stops, %s\n", plan.PlanId, plan.Name, len(plan.Waypoints), status)
+		}
+	}
+}
+
+func copyCmd(App *app.App) *cobra.Command {
+	var to string
+	cmd := &cobra.Command{
+		Use:   "copy <route-id>",
+		Short: "Copy a pulled route into a plan",
+		Long: `Copies a pulled route and its stops into a new plan. --to sets the plan's date
+and defaults to a week after the route's date. Appointment times keep their
+time of day.`,
+		Example: `  badgermaps routes copy 812
+  badgermaps routes copy 812 --to 2026-10-21`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			routeID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid route ID: %s", args[0])
+			}
+			cmd.SilenceUsage = true
+			var date time.Time
+			if to != "" {
+				if date, err = time.Parse("2006-01-02", to); err != nil {
+					return fmt.Errorf("--to: %q is not a date as YYYY-MM-DD", to)
+				}
+			} else {
+				route, err := database.GetRouteByID(App.DB, routeID)
+				if err != nil {
+					return fmt.Errorf("route %d is not stored; pull routes first: %w", routeID, err)
+				}
+				day, ok := route.RouteDate.Time()
+				if !ok {
+					return fmt.Errorf("route %d has no date; give --to", routeID)
+				}
+				date = day.AddDate(0, 0, 7)
+			}
+			plan, err := App.DuplicateRoute(routeID, date)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Copied route %d to plan %d for %s with %d stops.\n", routeID, plan.PlanId, plan.RouteDate, len(plan.Waypoints))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "Date of the plan as YYYY-MM-DD (default a week after the route)")
+	return cmd
+}
+
+func copyWeekCmd(App *app.App) *cobra.Command {
+	var from, to string
+	cmd := &cobra.Command{
+		Use:   "copy-week",
+		Short: "Copy a week of pulled routes into plans",
+		Long: `Copies every route pulled for one week into plans on the same weekdays of
+another. By default last week's routes are copied to this week. --from and
+--to take any date in the week.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			target, err := App.ParseWeek(to)
+			if err != nil {
+				return fmt.Errorf("--to: %w", err)
+			}
+			source := target.AddDate(0, 0, -7)
+			if from != "" {
+				if source, err = App.ParseWeek(from); err != nil {
+					return fmt.Errorf("--from: %w", err)
+				}
+			}
+			plans, err := App.DuplicateWeek(source, target)
+			if len(plans) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Copied %d routes from the week of %s to plans for the week of %s.\n",
+					len(plans), source.Format("2006-01-02"), target.Format("2006-01-02"))
+			}
+			if err != nil {
+				return err
+			}
+			if len(plans) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No routes were pulled for the week of %s.\n", source.Format("2006-01-02"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "A date in the week to copy (default the week before --to)")
+	cmd.Flags().StringVar(&to, "to", "", "A date in the week to plan (default this week)")
+	return cmd
+}
+
+func planCmd(App *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan <plan-id>",
+		Short: "Show and edit a route plan",
+		Long: `Shows a plan with its stops in order. The subcommands edit a draft plan: add
+an account as a stop, remove or move stops, set a stop's appointment time, or
+change the plan's name, date, and addresses.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := planArg(App, args[0])
+			if err != nil {
+				return err
+			}
+			writePlan(cmd.OutOrStdout(), plan)
+			return nil
+		},
+	}
+	cmd.AddCommand(planAddCmd(App))
+	cmd.AddCommand(planRemoveCmd(App))
+	cmd.AddCommand(planMoveCmd(App))
+	cmd.AddCommand(planTimeCmd(App))
+	cmd.AddCommand(planSetCmd(App))
+	cmd.AddCommand(planDeleteCmd(App))
+	return cmd
+}
+
+func planArg(App *app.App, arg string) (*database.RoutePlan, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan ID: %s", arg)
+	}
+	return App.RoutePlan(id)
+}
+
+func writePlan(out io.Writer, plan *database.RoutePlan) {
+	c := utils.Colors
+	status := "draft"
+	if plan.Status == database.RoutePlanPushed {
+		status = fmt.Sprintf("pushed as route %d", plan.RouteId)
+	}
+	fmt.Fprintf(out, "Plan %d: %s on %s (%s)\n", plan.PlanId, plan.Name, plan.RouteDate, status)
+	if plan.TemplateRouteId > 0 {
+		fmt.Fprintln(out, c.Gray("Copied from route %d", plan.TemplateRouteId))
+	}
+	if plan.StartAddress != "" || plan.StartTime != "" {
+		fmt.Fprintf(out, "Start: %s %s\n", plan.StartTime, plan.StartAddress)
+	}
+	if plan.DestinationAddress != "" {
+		fmt.Fprintf(out, "End:   %s\n", plan.DestinationAddress)
+	}
+	if len(plan.Waypoints) == 0 {
+		fmt.Fprintln(out, "No stops yet. Add one with 'routes plan add'.")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\n#\tTime\tAccount\tStop\tAddress")
+	for i, stop := range plan.Waypoints {
+		account := ""
+		if stop.CustomerId > 0 {
+			account = strconv.Itoa(stop.CustomerId)
+		}
+		address := strings.TrimSpace(strings.Join([]string{stop.Address, stop.City, stop.State}, " "))
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, stop.ApptTime, account, stop.Name, address)
+	}
+	w.Flush()
+}
+
+// editStops applies edit to a plan's stops and prints the plan.
+func editStops(cmd *cobra.Command, App *app.App, arg string, edit func([]database.PlanWaypoint) ([]database.PlanWaypoint, error)) error {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("invalid plan ID: %s", arg)
+	}
+	cmd.SilenceUsage = true
+	plan, err := App.EditRoutePlanStops(id, edit)
+	if err != nil {
+		return err
+	}
+	writePlan(cmd.OutOrStdout(), plan)
+	return nil
+}
+
+func positionArgs(args []string) ([]int, error) {
+	positions := make([]int, len(args))
+	for i, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stop number: %s", arg)
+		}
+		positions[i] = n
+	}
+	return positions, nil
+}
+
+func planAddCmd(App *app.App) *cobra.Command {
+	var (
+		at       int
+		apptTime string
+		layover  int
+	)
+	cmd := &cobra.Command{
+		Use:   "add <plan-id> <account-id>",
+		Short: "Add an account as a stop",
+		Long:  `Adds a stop at the stored location of an account, at the end or at --at.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountID, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid account ID: %s", args[1])
+			}
+			return editStops(cmd, App, args[0], func(stops []database.PlanWaypoint) ([]database.PlanWaypoint, error) {
+				stop, err := App.AccountStop(accountID)
+				if err != nil {
+					return nil, err
+				}
+				stop.ApptTime = apptTime
+				stop.LayoverMinutes = layover
+				return app.InsertStop(stops, stop, at), nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&at, "at", 0, "Stop number to insert at (default the end)")
+	cmd.Flags().StringVar(&apptTime, "time", "", "Appointment time as HH:MM")
+	cmd.Flags().IntVar(&layover, "layover", 0, "Minutes spent at the stop")
+	return cmd
+}
+
+func planRemoveCmd(App *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <plan-id> <stop>",
+		Short: "Remove a stop",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			positions, err := positionArgs(args[1:])
+			if err != nil {
+				return err
+			}
+			return editStops(cmd, App, args[0], func(stops []database.PlanWaypoint) ([]database.PlanWaypoint, error) {
+				return app.RemoveStop(stops, positions[0])
+			})
+		},
+	}
+}
+
+func planMoveCmd(App *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "move <plan-id> <from> <to>",
+		Short: "Move a stop to another place in the order",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			positions, err := positionArgs(args[1:])
+			if err != nil {
+				return err
+			}
+			return editStops(cmd, App, args[0], func(stops []database.PlanWaypoint) ([]database.PlanWaypoint, error) {
+				return app.MoveStop(stops, positions[0], positions[1])
+			})
+		},
+	}
+}
+
+func planTimeCmd(App *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "time <plan-id> <stop> [HH:MM]",
+		Short: "Set or clear a stop's appointment time",
+		Args:  cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			positions, err := positionArgs(args[1:2])
+			if err != nil {
+				return err
+			}
+			apptTime := ""
+			if len(args) > 2 {
+				apptTime = args[2]
+			}
+			return editStops(cmd, App, args[0], func(stops []database.PlanWaypoint) ([]database.PlanWaypoint, error) {
+				if positions[0] < 1 || positions[0] > len(stops) {
+					return nil, fmt.Errorf("no stop %d; the plan has %d", positions[0], len(stops))
+				}
+				stops[positions[0]-1].ApptTime = apptTime
+				return stops, nil
+			})
+		},
+	}
+}
+
+func planSetCmd(App *app.App) *cobra.Command {
+	var name, date, startTime, start, destination string
+	cmd := &cobra.Command{
+		Use:   "set <plan-id>",
+		Short: "Change a plan's name, date, start, or destination",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := planArg(App, args[0])
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			flags := cmd.Flags()
+			if flags.Changed("name") {
+				plan.Name = name
+			}
+			if flags.Changed("date") {
+				plan.RouteDate = date
+			}
+			if flags.Changed("start-time") {
+				plan.StartTime = startTime
+			}
+			if flags.Changed("start") {
+				plan.StartAddress = start
+			}
+			if flags.Changed("destination") {
+				plan.DestinationAddress = destination
+			}
+			if err := App.SaveRoutePlan(*plan); err != nil {
+				return err
+			}
+			writePlan(cmd.OutOrStdout(), plan)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Route name")
+	cmd.Flags().StringVar(&date, "date", "", "Route date as YYYY-MM-DD")
+	cmd.Flags().StringVar(&startTime, "start-time", "", "Start time as HH:MM, or empty to clear it")
+	cmd.Flags().StringVar(&start, "start", "", "Start address")
+	cmd.Flags().StringVar(&destination, "destination", "", "Destination address")
+	return cmd
+}
+
+func planDeleteCmd(App *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <plan-id>",
+		Short: "Delete a plan",
+		Long:  `Deletes a plan. A route already pushed from it stays in BadgerMaps.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid plan ID: %s", args[0])
+			}
+			cmd.SilenceUsage = true
+			if err := App.DeleteRoutePlan(id); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted plan %d.\n", id)
+			return nil
+		},
+	}
+}
+
+func pushCmd(App *app.App) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "push <plan-id>",
+		Short: "Create a planned route in BadgerMaps",
+		Long: `Creates the route a draft plan describes in BadgerMaps, with its stops in
+order, and stores it locally as if it had been pulled. The plan is then marked
+pushed and can no longer be edited. Asks first unless --yes is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := planArg(App, args[0])
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			if !yes {
+				if App.State.NoInput {
+					return fmt.Errorf("pushing creates a route in BadgerMaps; pass --yes to push when --no-input is set")
+				}
+				reader := bufio.NewReader(os.Stdin)
+				prompt := fmt.Sprintf("Create route %q for %s with %d stops in BadgerMaps?", plan.Name, plan.RouteDate, len(plan.Waypoints))
+				if !utils.PromptBool(reader, prompt, false) {
+					fmt.Fprintln(cmd.OutOrStdout(), "Push cancelled.")
+					return nil
+				}
+			}
+			route, err := push.PushRoutePlan(App, plan.PlanId)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created route %d from plan %d.\n", route.RouteId.Int64, plan.PlanId)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Push without asking")
+	return cmd
+}
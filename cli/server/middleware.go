@@ -3,33 +3,102 @@ package server
 import (
 	"badgermaps/app"
 	"badgermaps/database"
+	"badgermaps/events"
+	"badgermaps/telemetry"
 	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
+// WebhookLoggingMiddleware checks webhook bodies against the schema of the
+// webhook served at the request path and, when request logging is on,
+// stores each request in WebhookLog with its parse status and entity ID.
+// Invalid bodies are rejected with 400 and dispatch a webhook.invalid event.
 func WebhookLoggingMiddleware(next http.Handler, a *app.App) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !a.Config.Server.LogRequests {
+		webhook := app.WebhookForPath(r.URL.Path)
+		if webhook == "" && !a.Config.Server.LogRequests {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "can't read body", http.StatusInternalServerError)
-			return
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "can't read body", http.StatusInternalServerError)
+				return
+			}
+			// Restore the body so the next handler can read it
+			r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 		}
 
-		// Restore the body so the next handler can read it
-		r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		validation := app.WebhookValidation{Status: app.WebhookParseUnchecked}
+		if webhook != "" && r.Method == http.MethodPost {
+			validation = app.ValidateWebhookPayload(webhook, body)
+		}
 
-		headers, _ := json.Marshal(r.Header)
+		if a.Config.Server.LogRequests && a.DB != nil {
+			headers, _ := json.Marshal(r.Header)
+			err := database.LogWebhookEntry(a.DB, database.WebhookLogEntry{
+				ReceivedAt:  time.Now(),
+				Method:      r.Method,
+				Uri:         r.RequestURI,
+				Headers:     string(headers),
+				Body:        string(body),
+				ParseStatus: validation.Status,
+				ParseError:  validation.Error(),
+				EntityId:    validation.EntityID,
+			})
+			if err != nil {
+				a.Events.Dispatch(events.Warningf("server", "Failed to log webhook %s: %v", r.RequestURI, err))
+			}
+		}
 
-		database.LogWebhook(a.DB, time.Now(), r.Method, r.RequestURI, string(headers), string(body))
+		if validation.Status == app.WebhookParseInvalid {
+			a.Events.Dispatch(events.Event{Type: "webhook.invalid", Source: "webhook", Payload: events.WebhookInvalidPayload{
+				Webhook:  webhook,
+				Uri:      r.RequestURI,
+				EntityID: validation.EntityID,
+				Errors:   validation.Errors,
+				Body:     string(body),
+			}})
+			a.Events.Dispatch(events.Warningf("server", "Rejected %s webhook: %s", webhook, validation.Error()))
+			http.Error(w, "invalid payload: "+strings.Join(validation.Errors, "; "), http.StatusBadRequest)
+			return
+		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// WebhookTracingMiddleware runs each request in a span named after its
+// path, continuing the trace of the sender when it passes a traceparent
+// header. The handler reaches the span through the request context.
+func WebhookTracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := telemetry.Start(telemetry.Extract(r.Context(), r.Header), "webhook "+r.URL.Path,
+			semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path))
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		telemetry.EndFailed(span, recorder.status >= http.StatusBadRequest)
+	})
+}
+
+// statusRecorder remembers the status code a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -4,17 +4,24 @@ import (
 	"badgermaps/api/models"
 	"badgermaps/app"
 	"badgermaps/app/pull"
+	"badgermaps/app/push"
+	appserver "badgermaps/app/server"
 	"badgermaps/database"
 	"badgermaps/events"
+	"badgermaps/telemetry"
+	"badgermaps/utils"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -22,6 +29,10 @@ import (
 // CliPresenter handles the presentation logic for the server command.
 type CliPresenter struct {
 	App *app.App
+	// accountWebhooks buffers account create webhooks while the server
+	// runs with server.webhook_buffer enabled; otherwise each webhook is
+	// stored as it arrives.
+	accountWebhooks *appserver.WebhookBuffer[*models.Account]
 }
 
 // NewCliPresenter creates a new presenter for the server command.
@@ -48,6 +59,43 @@ func (p *CliPresenter) HandleServerStop() {
 	p.App.Events.Dispatch(events.Infof("server", "Server stopped successfully."))
 }
 
+// HandleServerReload asks the background server to re-read its config file.
+func (p *CliPresenter) HandleServerReload() {
+	if err := p.App.Server.ReloadServer(); err != nil {
+		p.App.Events.Dispatch(events.Errorf("server", "Failed to reload server config: %v", err))
+		os.Exit(1)
+	}
+	p.App.Events.Dispatch(events.Infof("server", "Reload signal sent; check the server log for the applied changes."))
+}
+
+// HandleRotateKey rotates the API key and tells a running server to pick it
+// up.
+func (p *CliPresenter) HandleRotateKey(keyFromStdin bool, stdin io.Reader) {
+	var newKey string
+	if keyFromStdin {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			p.App.Events.Dispatch(events.Errorf("server", "Failed to read the new API key: %v", err))
+			os.Exit(1)
+		}
+		newKey = strings.TrimSpace(string(data))
+	}
+	rotation, err := p.App.RotateAPIKey(newKey)
+	if err != nil {
+		p.App.Events.Dispatch(events.Errorf("server", "API key rotation failed: %v", err))
+		os.Exit(1)
+	}
+	p.App.Events.Dispatch(events.Infof("server", "API key %s replaced by %s for profile %d.", rotation.OldKey, rotation.NewKey, rotation.ProfileID))
+	if _, running := p.App.Server.GetServerStatus(); !running {
+		return
+	}
+	if err := p.App.Server.ReloadServer(); err != nil {
+		p.App.Events.Dispatch(events.Warningf("server", "Could not signal the server (%v); it applies the new key when it next reads the config file.", err))
+		return
+	}
+	p.App.Events.Dispatch(events.Infof("server", "Reload signal sent; the server switches keys without a restart."))
+}
+
 // HandleServerStatus checks and prints the server's status.
 func (p *CliPresenter) HandleServerStatus() {
 	if pid, running := p.App.Server.GetServerStatus(); running {
@@ -63,64 +111,102 @@ func (p *CliPresenter) RunServer(config *ServerConfig) {
 		p.App.Events.Dispatch(events.Errorf("server", "Failed to schedule cron jobs: %v", err))
 		os.Exit(1)
 	}
+	flushStop := make(chan struct{})
+	defer close(flushStop)
+	if p.App.Config.PushWindow.Enabled {
+		p.App.Events.Dispatch(events.Infof("server", "Queued changes will be pushed when the push window opens: %s", p.App.Config.PushWindow))
+	}
+	push.StartWindowFlusher(p.App, flushStop)
+	p.App.WatchConfigFile(0, flushStop)
+	p.App.StartAlertMonitor(flushStop)
 	mux := http.NewServeMux()
 
-	logRequests := config.LogRequests
+	// Webhook toggles, the catch-all, and request logging are checked per
+	// request so a config reload takes effect without re-registering routes.
 	wrapWithLogging := func(handler http.Handler) http.Handler {
-		if logRequests {
-			return WebhookLoggingMiddleware(handler, p.App)
-		}
-		return handler
+		return WebhookTracingMiddleware(WebhookLoggingMiddleware(handler, p.App))
 	}
-
-	accountCreateHandler := wrapWithLogging(http.HandlerFunc(p.HandleAccountCreateWebhook))
-	checkinHandler := wrapWithLogging(http.HandlerFunc(p.HandleCheckinWebhook))
-
-	enabledWebhooks := p.App.Config.Server.Webhooks
-	if len(enabledWebhooks) == 0 {
-		enabledWebhooks = map[string]bool{
-			app.WebhookAccountCreate: true,
-			app.WebhookCheckin:       true,
+	webhookEnabled := func(name string) bool {
+		enabled := p.App.Config.Server.Webhooks
+		if len(enabled) == 0 {
+			return true
 		}
+		return enabled[name]
 	}
-
-	if enabledWebhooks[app.WebhookAccountCreate] {
-		mux.Handle("/webhook/account/create", accountCreateHandler)
-	} else {
-		p.App.Events.Dispatch(events.Infof("server", "Account create webhook disabled by configuration"))
+	toggled := func(name string, handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !webhookEnabled(name) {
+				http.NotFound(w, r)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
 	}
 
-	if enabledWebhooks[app.WebhookCheckin] {
-		mux.Handle("/webhook/checkin", checkinHandler)
-	} else {
-		p.App.Events.Dispatch(events.Infof("server", "Checkin webhook disabled by configuration"))
+	if cfg := p.App.Config.Server.WebhookBuffer; cfg.Enabled {
+		p.accountWebhooks = appserver.NewWebhookBuffer(cfg, p.applyAccountWebhooks)
+		defer p.stopWebhookBuffer()
+		p.App.Events.Dispatch(events.Infof("server", "Buffering account webhooks for %s (at most %d waiting)", cfg.Window(), cfg.Limit()))
 	}
 
-	if !enabledWebhooks[app.WebhookAccountCreate] && !enabledWebhooks[app.WebhookCheckin] {
-		p.App.Events.Dispatch(events.Warningf("server", "All webhooks are disabled; server will only serve /health"))
-	}
+	mux.Handle("/webhook/account/create", toggled(app.WebhookAccountCreate, wrapWithLogging(http.HandlerFunc(p.HandleAccountCreateWebhook))))
+	mux.Handle("/webhook/checkin", toggled(app.WebhookCheckin, wrapWithLogging(http.HandlerFunc(p.HandleCheckinWebhook))))
+	p.logWebhookStatus()
 
-	if p.App.Config.WebhookCatchAll {
-		catchAllHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			p.App.Events.Dispatch(events.Warningf("server", "Received request for unhandled path: %s", r.RequestURI))
-			http.NotFound(w, r)
-		})
-		mux.Handle("/", wrapWithLogging(catchAllHandler))
-	}
+	catchAllHandler := wrapWithLogging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.App.Events.Dispatch(events.Warningf("server", "Received request for unhandled path: %s", r.RequestURI))
+		http.NotFound(w, r)
+	}))
+	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.App.Config.WebhookCatchAll {
+			catchAllHandler.ServeHTTP(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
 
+	mux.HandleFunc("/reload", p.HandleReload)
+	mux.HandleFunc("/events", p.HandleEvents)
 	mux.HandleFunc("/health", p.HandleHealthCheck)
+	mux.HandleFunc("/metrics/webhooks", p.HandleWebhookMetrics)
 	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
 	server := &http.Server{Addr: addr, Handler: mux}
+	tlsCtx, stopTLS := context.WithCancel(context.Background())
+	defer stopTLS()
+	certs, serveTLS, err := p.configureTLS(tlsCtx, server, config)
+	if err != nil {
+		p.App.Events.Dispatch(events.Errorf("server", "Failed to set up TLS: %v", err))
+		os.Exit(1)
+	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	go func() {
+		for {
+			select {
+			case <-hangup:
+				p.App.Events.Dispatch(events.Infof("server", "Received SIGHUP; reloading configuration"))
+				p.reloadConfig()
+				if certs != nil {
+					if err := certs.Reload(); err != nil {
+						p.App.Events.Dispatch(events.Warningf("server", "%v; keeping the current certificate", err))
+					}
+				}
+			case <-flushStop:
+				return
+			}
+		}
+	}()
 
 	go func() {
 		p.App.Events.Dispatch(events.Infof("server", "Starting server on %s", addr))
 		var err error
-		if config.TLSEnabled {
+		if serveTLS {
 			p.App.Events.Dispatch(events.Infof("server", "TLS is enabled. Starting HTTPS server."))
-			err = server.ListenAndServeTLS(config.TLSCert, config.TLSKey)
+			err = server.ListenAndServeTLS("", "")
 		} else {
 			err = server.ListenAndServe()
 		}
@@ -129,6 +215,10 @@ func (p *CliPresenter) RunServer(config *ServerConfig) {
 		}
 	}()
 
+	tunnelCtx, stopTunnel := context.WithCancel(context.Background())
+	defer stopTunnel()
+	p.startTunnel(tunnelCtx, mux)
+
 	<-stop
 	p.App.Events.Dispatch(events.Infof("server", "Shutting down server..."))
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -139,6 +229,192 @@ func (p *CliPresenter) RunServer(config *ServerConfig) {
 	p.App.Events.Dispatch(events.Infof("server", "Server stopped"))
 }
 
+// configureTLS sets up the server's certificate: from Let's Encrypt when
+// server.acme is enabled, otherwise from the configured certificate and key
+// files, which are watched and reloaded when they are renewed. It reports
+// whether the server should serve HTTPS.
+func (p *CliPresenter) configureTLS(ctx context.Context, server *http.Server, config *ServerConfig) (*appserver.CertReloader, bool, error) {
+	if cfg := p.App.Config.Server.ACME; cfg.Enabled {
+		manager, err := appserver.NewACMEManager(cfg, utils.GetConfigDirFile("acme"))
+		if err != nil {
+			return nil, false, err
+		}
+		server.TLSConfig = manager.TLSConfig()
+		directory := cfg.DirectoryURL
+		if directory == "" {
+			directory = "Let's Encrypt"
+		}
+		p.App.Events.Dispatch(events.Infof("server", "Certificates for %s are obtained from %s", strings.Join(cfg.Domains, ", "), directory))
+		if cfg.HTTPPort > 0 {
+			challenges := &http.Server{Addr: fmt.Sprintf("%s:%d", config.Host, cfg.HTTPPort), Handler: manager.HTTPHandler()}
+			go func() {
+				if err := challenges.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					p.App.Events.Dispatch(events.Errorf("server", "ACME challenge server error: %v", err))
+				}
+			}()
+			go func() {
+				<-ctx.Done()
+				challenges.Close()
+			}()
+		}
+		return nil, true, nil
+	}
+	if !config.TLSEnabled {
+		return nil, false, nil
+	}
+	certs, err := appserver.NewCertReloader(config.TLSCert, config.TLSKey, p.App.Events)
+	if err != nil {
+		return nil, false, err
+	}
+	server.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+	go certs.Watch(ctx, appserver.CertReloadInterval)
+	return certs, true, nil
+}
+
+// startTunnel connects to the configured webhook relay, if any, and serves
+// the webhooks it forwards through handler until ctx is cancelled.
+func (p *CliPresenter) startTunnel(ctx context.Context, handler http.Handler) {
+	cfg := p.App.Config.Server.Tunnel
+	if !cfg.Enabled {
+		return
+	}
+	client, err := appserver.NewTunnelClient(cfg, handler, p.App.Events)
+	if err != nil {
+		p.App.Events.Dispatch(events.Errorf("server", "Webhook tunnel disabled: %v", err))
+		return
+	}
+	p.App.Events.Dispatch(events.Infof("server", "Receiving webhooks through relay %s", cfg.URL))
+	go client.Run(ctx)
+}
+
+// logWebhookStatus reports which webhooks the current config disables.
+func (p *CliPresenter) logWebhookStatus() {
+	enabled := p.App.Config.Server.Webhooks
+	if len(enabled) == 0 {
+		return
+	}
+	if !enabled[app.WebhookAccountCreate] {
+		p.App.Events.Dispatch(events.Infof("server", "Account create webhook disabled by configuration"))
+	}
+	if !enabled[app.WebhookCheckin] {
+		p.App.Events.Dispatch(events.Infof("server", "Checkin webhook disabled by configuration"))
+	}
+	if !enabled[app.WebhookAccountCreate] && !enabled[app.WebhookCheckin] {
+		p.App.Events.Dispatch(events.Warningf("server", "All webhooks are disabled; server will only serve /health"))
+	}
+}
+
+// reloadConfig re-reads the config file and logs the webhook state when it
+// changed.
+func (p *CliPresenter) reloadConfig() (*app.ConfigReload, error) {
+	reload, err := p.App.ReloadConfig()
+	if err != nil {
+		p.App.Events.Dispatch(events.Errorf("server", "Config reload failed; keeping current settings: %v", err))
+		return nil, err
+	}
+	for _, name := range reload.Applied {
+		if name == "server.webhooks" {
+			p.logWebhookStatus()
+		}
+	}
+	return reload, nil
+}
+
+// HandleReload re-reads the config file. Only POST requests from the local
+// machine are accepted.
+func (p *CliPresenter) HandleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isLoopbackRequest(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	reload, err := p.reloadConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{
+		"applied":          nonNil(reload.Applied),
+		"restart_required": nonNil(reload.RestartRequired),
+	})
+}
+
+// eventStreamBuffer is how many events a slow watcher may fall behind before
+// events are dropped for it.
+const eventStreamBuffer = 256
+
+// HandleEvents streams the server's events as JSON lines until the client
+// disconnects. Repeated pattern parameters, such as ?pattern=pull.*, select
+// the events; without them every event is sent. Only GET requests from the
+// local machine are accepted.
+func (p *CliPresenter) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isLoopbackRequest(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	patterns := r.URL.Query()["pattern"]
+
+	stream := make(chan events.StreamedEvent, eventStreamBuffer)
+	cancel := p.App.Events.SubscribeCancelable("*", func(e events.Event) {
+		if !events.MatchesAny(patterns, e.Type) {
+			return
+		}
+		select {
+		case stream <- events.NewStreamedEvent(e):
+		default:
+		}
+	})
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-stream:
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 func (p *CliPresenter) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	if p.App.DB != nil && p.App.DB.IsConnected() {
 		w.WriteHeader(http.StatusOK)
@@ -195,6 +471,45 @@ func (p *CliPresenter) HandleReplayWebhook(id int) {
 	}
 }
 
+// applyAccountWebhooks stores a batch of buffered account webhooks.
+func (p *CliPresenter) applyAccountWebhooks(accounts map[int]*models.Account) error {
+	if err := pull.StoreWebhookAccounts(p.App, accounts); err != nil {
+		p.App.Events.Dispatch(events.Errorf("server", "Failed to apply %d buffered account webhook(s): %v", len(accounts), err))
+		return err
+	}
+	p.App.Events.Dispatch(events.Infof("server", "Applied %d buffered account webhook(s): %v", len(accounts), appserver.SortedIDs(accounts)))
+	return nil
+}
+
+// stopWebhookBuffer applies the webhooks still waiting and logs the
+// buffer's counts.
+func (p *CliPresenter) stopWebhookBuffer() {
+	p.accountWebhooks.Stop()
+	stats := p.accountWebhooks.Stats()
+	p.App.Events.Dispatch(events.Infof("server", "Account webhooks: %d received, %d deduplicated, %d dropped, %d applied in %d batch(es), %d failed",
+		stats.Received, stats.Deduplicated, stats.Dropped, stats.Applied, stats.Batches, stats.Failed))
+}
+
+// HandleWebhookMetrics reports the webhook buffer's counts as JSON. Only GET
+// requests from the local machine are accepted.
+func (p *CliPresenter) HandleWebhookMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isLoopbackRequest(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	body := map[string]interface{}{"buffered": p.accountWebhooks != nil}
+	if p.accountWebhooks != nil {
+		body["account_create"] = p.accountWebhooks.Stats()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
 func (p *CliPresenter) HandleAccountCreateWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -215,7 +530,21 @@ func (p *CliPresenter) HandleAccountCreateWebhook(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := pull.StoreAccountDetailed(p.App, &acc); err != nil {
+	if p.accountWebhooks != nil && acc.AccountId.Valid {
+		if !p.accountWebhooks.Add(int(acc.AccountId.Int64), &acc) {
+			p.App.Events.Dispatch(events.Warningf("server", "Account webhook buffer is full; refused webhook for account %d", acc.AccountId.Int64))
+			http.Error(w, "webhook buffer is full; retry later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "Account webhook queued")
+		return
+	}
+
+	_, span := telemetry.Start(r.Context(), "db.StoreAccountDetailed", telemetry.EntityID.Int64(acc.AccountId.Int64))
+	err = pull.StoreAccountDetailed(p.App, &acc)
+	telemetry.End(span, err)
+	if err != nil {
 		http.Error(w, "failed to store account", http.StatusInternalServerError)
 		return
 	}
@@ -244,11 +573,31 @@ func (p *CliPresenter) HandleCheckinWebhook(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if err := pull.StoreCheckin(p.App, checkin); err != nil {
+	_, span := telemetry.Start(r.Context(), "db.StoreCheckin", telemetry.EntityID.Int64(checkin.CheckinId.Int64))
+	err = pull.StoreCheckin(p.App, checkin)
+	telemetry.End(span, err)
+	if err != nil {
 		http.Error(w, "failed to store checkin", http.StatusInternalServerError)
 		return
 	}
 	p.App.Events.Dispatch(events.Infof("server", "Received and processed checkin webhook for checkin: %d", checkin.CheckinId.Int64))
+	p.App.Events.Dispatch(events.Event{Type: "webhook.checkin", Source: "webhook", Payload: checkinWebhookPayload(checkin)})
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "Checkin webhook processed")
 }
+
+// checkinWebhookPayload describes a stored check-in for the actions that
+// follow up on it.
+func checkinWebhookPayload(checkin models.Checkin) events.CheckinWebhookPayload {
+	payload := events.CheckinWebhookPayload{
+		CheckinID: checkin.CheckinId.Int64,
+		AccountID: checkin.AccountId.Int64,
+		Type:      checkin.Type.String,
+		Comments:  checkin.Comments.String,
+		CreatedBy: checkin.CreatedBy.String,
+	}
+	if t, ok := checkin.LogDatetime.Time(); ok {
+		payload.LogDatetime = t.Format(time.RFC3339)
+	}
+	return payload
+}
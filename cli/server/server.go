@@ -49,6 +49,8 @@ func ServerCmd(a *app.App) *cobra.Command {
 	serverCmd.AddCommand(newServerStartCmd(presenter))
 	serverCmd.AddCommand(newServerStopCmd(presenter))
 	serverCmd.AddCommand(newServerStatusCmd(presenter))
+	serverCmd.AddCommand(newServerReloadCmd(presenter))
+	serverCmd.AddCommand(newServerRotateKeyCmd(presenter))
 	serverCmd.AddCommand(newServerSetupCmd(a))
 	serverCmd.AddCommand(newServerReplayWebhookCmd(presenter))
 
@@ -91,6 +93,39 @@ func newServerStopCmd(presenter *CliPresenter) *cobra.Command {
 	}
 }
 
+func newServerReloadCmd(presenter *CliPresenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Reload the running server's configuration without restarting it",
+		Long: `Signal the background server to re-read its config file. Cron schedules, webhook
+toggles, request logging, log level, and rate limits apply immediately; connection,
+address, TLS, and plugin changes still need a restart. The server also picks up
+config file changes on its own, so this is only needed to force a reload.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			presenter.HandleServerReload()
+		},
+	}
+}
+
+func newServerRotateKeyCmd(presenter *CliPresenter) *cobra.Command {
+	var keyFromStdin bool
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Switch to a new API key without restarting the server",
+		Long: `Check the key in api.secondary_api_key (or read from standard input with
+--key-stdin) against the API, make sure it belongs to the same user as the
+current key, and save it as api.api_key. A running server is told to reload
+and swaps the key into its API client, scheduler, and webhook handlers without
+dropping requests. Each attempt is recorded in CommandLog.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			presenter.HandleRotateKey(keyFromStdin, cmd.InOrStdin())
+		},
+	}
+	cmd.Flags().BoolVar(&keyFromStdin, "key-stdin", false, "Read the new API key from standard input instead of api.secondary_api_key")
+	return cmd
+}
+
 func newServerStatusCmd(presenter *CliPresenter) *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
@@ -5,8 +5,11 @@ import (
 	"badgermaps/app"
 	"badgermaps/app/state"
 	"badgermaps/database"
+	"badgermaps/events"
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -204,3 +207,137 @@ func TestMain(m *testing.M) {
 	}
 	os.Exit(m.Run())
 }
+
+func TestHandleReload(t *testing.T) {
+	a := app.NewApp()
+	a.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(a.ConfigFile, []byte("webhook_catch_all: true\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	presenter := NewCliPresenter(a)
+
+	req := httptest.NewRequest(http.MethodGet, "/reload", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rr := httptest.NewRecorder()
+	presenter.HandleReload(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /reload returned %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reload", nil)
+	req.RemoteAddr = "203.0.113.9:5000"
+	rr = httptest.NewRecorder()
+	presenter.HandleReload(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("remote POST /reload returned %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	if a.Config.WebhookCatchAll {
+		t.Fatal("remote reload request must not change the config")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reload", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rr = httptest.NewRecorder()
+	presenter.HandleReload(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("local POST /reload returned %d: %s", rr.Code, rr.Body.String())
+	}
+	var result map[string][]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !a.Config.WebhookCatchAll || len(result["applied"]) == 0 {
+		t.Errorf("expected catch-all to be applied, got config=%v response=%v", a.Config.WebhookCatchAll, result)
+	}
+}
+
+func TestWebhookLoggingMiddlewareRejectsInvalidPayload(t *testing.T) {
+	a := app.NewApp()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	a.DB = db
+	a.Config.Server.LogRequests = true
+
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+	for _, body := range []string{`{"id": 5, "customer": "five"}`, `{"id": 6, "customer": 12}`} {
+		req, _ := http.NewRequest("POST", "/webhook/checkin", bytes.NewBufferString(body))
+		req.RequestURI = "/webhook/checkin"
+		WebhookLoggingMiddleware(next, a).ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if !called {
+		t.Error("the valid webhook was not passed on")
+	}
+
+	rows, err := db.GetDB().Query("SELECT ParseStatus, COALESCE(ParseError, ''), EntityId FROM WebhookLog ORDER BY Id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var status, parseErr string
+		var entityID int64
+		if err := rows.Scan(&status, &parseErr, &entityID); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s|%s|%d", status, parseErr, entityID))
+	}
+	want := []string{"invalid|customer must be integer, got string|5", "valid||6"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("WebhookLog = %q, want %q", got, want)
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	a := app.NewApp()
+	presenter := NewCliPresenter(a)
+	srv := httptest.NewServer(http.HandlerFunc(presenter.HandleEvents))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events?pattern=pull.*&pattern=push.error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /events returned %d", resp.StatusCode)
+	}
+
+	a.Events.Dispatch(events.Event{Type: "push.start", Source: "accounts"})
+	a.Events.Dispatch(events.Event{Type: "pull.start", Source: "accounts"})
+	a.Events.Dispatch(events.Errorf("push", "boom"))
+	a.Events.Dispatch(events.Event{Type: "push.error", Source: "checkins"})
+
+	scanner := bufio.NewScanner(resp.Body)
+	var got []string
+	for len(got) < 2 && scanner.Scan() {
+		var e events.StreamedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+		got = append(got, fmt.Sprintf("%s/%s", e.Type, e.Source))
+	}
+	if fmt.Sprint(got) != "[pull.start/accounts push.error/checkins]" {
+		t.Errorf("streamed %v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.RemoteAddr = "203.0.113.9:5000"
+	rr := httptest.NewRecorder()
+	presenter.HandleEvents(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("remote GET /events returned %d, want %d", rr.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,126 @@
+package smartsync
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/smartsync"
+	"badgermaps/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// SyncCmd creates the sync command, which runs the pulls, pushes and
+// retries the local database needs, as the GUI's Smart Sync button does.
+func SyncCmd(App *app.App) *cobra.Command {
+	var dryRun, asJSON, confirmDeletes bool
+	var staleAfter time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pull, push and retry only what is needed, in order",
+		Long: `Inspect the local database and run only the work it needs: failed changes that can be retried are queued again, pending account and check-in changes are pushed, and accounts, check-ins, routes and the profile are pulled when their last pull failed or is older than pull_stale_after (default 24h).
+
+Changes BadgerMaps rejected, conflicts waiting for a decision, and pushes outside the push window are left alone and listed. The run stops at the first step that fails. Use --dry-run to see the plan without running it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if App.DB == nil || !App.DB.IsConnected() {
+				return fmt.Errorf("database is not connected. Please check your database configuration")
+			}
+			plan, err := smartsync.PlanSync(App, time.Now(), staleAfter)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if dryRun {
+				if asJSON {
+					return writeJSON(out, plan)
+				}
+				writePlan(out, plan)
+				return nil
+			}
+			if !plan.Empty() && (App.API == nil || App.API.APIKey == "") {
+				return fmt.Errorf("API key is not configured. Please run 'badgermaps config' to set up your API credentials")
+			}
+			if confirmDeletes {
+				App.State.ConfirmDeletes = true
+			}
+			result := smartsync.Run(App, plan, nil)
+			if asJSON {
+				if err := writeJSON(out, result); err != nil {
+					return err
+				}
+			} else if !App.State.Quiet || result.Err() != nil {
+				writeResult(out, result)
+			}
+			return result.Err()
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would run and why, without running it")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the plan or result as JSON")
+	cmd.Flags().DurationVar(&staleAfter, "stale-after", 0, "Pull entities last pulled longer ago than this (default pull_stale_after, or 24h)")
+	cmd.Flags().BoolVar(&confirmDeletes, "confirm-deletes", false, "Push pending account deletions too; deleted accounts go to the recycle bin")
+	return cmd
+}
+
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writePlan(out io.Writer, plan *smartsync.Plan) {
+	c := utils.Colors
+	if plan.Empty() {
+		fmt.Fprintln(out, "Everything is up to date.")
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for i, step := range plan.Steps {
+			fmt.Fprintf(w, "%d.\t%s\t%s\n", i+1, c.Bold(step.Label()), c.Gray("%s", step.Reason))
+		}
+		w.Flush()
+	}
+	writeDeferred(out, plan.Deferred)
+}
+
+func writeResult(out io.Writer, result *smartsync.Result) {
+	c := utils.Colors
+	if len(result.Steps) == 0 {
+		fmt.Fprintln(out, "Everything is up to date.")
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, step := range result.Steps {
+			status := c.Green("%s", step.Status)
+			switch step.Status {
+			case smartsync.StatusFailed:
+				status = c.Red("%s", step.Status)
+			case smartsync.StatusSkipped:
+				status = c.Yellow("%s", step.Status)
+			}
+			took := ""
+			if step.Status != smartsync.StatusSkipped {
+				took = step.Duration.Round(100 * time.Millisecond).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Bold(step.Label()), status, took, step.Detail)
+		}
+		w.Flush()
+		if result.Pushed+result.PushFailed+result.Held > 0 {
+			fmt.Fprintf(out, "\nPushed %d change(s), %d failed, %d held.\n", result.Pushed, result.PushFailed, result.Held)
+		}
+	}
+	writeDeferred(out, result.Deferred)
+}
+
+func writeDeferred(out io.Writer, deferred []string) {
+	if len(deferred) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s\n", utils.Colors.Bold("Left for later"))
+	for _, note := range deferred {
+		fmt.Fprintf(out, "  - %s\n", note)
+	}
+}
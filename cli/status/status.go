@@ -0,0 +1,112 @@
+package status
+
+import (
+	"badgermaps/app"
+	"badgermaps/errs"
+	"badgermaps/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// StatusCmd creates the status command, which prints the same health
+// summary as the Home tab.
+func StatusCmd(App *app.App) *cobra.Command {
+	var asJSON, check bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show API, database, sync, scheduler, and server health",
+		Long: `Print API and database connectivity, schema status, the last pull and push for each source, pending change counts, the next scheduled job, and the server PID.
+
+Use --json for scripts. With --check the command exits non-zero when the API or database is unreachable or the schema is invalid.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := App.Status()
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				writeStatus(out, report)
+			}
+			if check && !report.Healthy() {
+				cmd.SilenceUsage = true
+				if report.API.Connected && report.Database.Connected {
+					return errs.New(errs.ErrSchema, fmt.Errorf("badgermaps is not healthy: the database schema is invalid"))
+				}
+				return fmt.Errorf("badgermaps is not healthy")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the status as JSON")
+	cmd.Flags().BoolVar(&check, "check", false, "Exit non-zero when the API or database is not healthy")
+	return cmd
+}
+
+func writeStatus(out io.Writer, r app.StatusReport) {
+	c := utils.Colors
+	ok := func(healthy bool, good, bad string) string {
+		if healthy {
+			return c.Green("%s", good)
+		}
+		return c.Red("%s", bad)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\n", c.Bold("API"), ok(r.API.Connected, "connected", "not connected"), r.API.URL)
+	if r.API.Sandbox != "" {
+		fmt.Fprintf(w, "\t%s\t%s\n", c.Yellow("pushes go to sandbox"), r.API.Sandbox)
+	}
+	if r.API.Error != "" {
+		fmt.Fprintf(w, "\t%s\n", c.Gray("%s", r.API.Error))
+	}
+
+	fmt.Fprintf(w, "%s\t%s\t%s\n", c.Bold("Database"), ok(r.Database.Connected, "connected", "not connected"), r.Database.Type)
+	if r.Database.Connected {
+		fmt.Fprintf(w, "Schema\t%s\n", ok(r.Database.SchemaValid, "valid", "invalid"))
+	}
+	if r.Database.Error != "" {
+		fmt.Fprintf(w, "\t%s\n", c.Gray("%s", r.Database.Error))
+	}
+
+	fmt.Fprintf(w, "%s\t%s\n", c.Bold("Server"), ok(r.Server.Running, fmt.Sprintf("running (PID %d)", r.Server.PID), "stopped"))
+
+	switch {
+	case len(r.Scheduler.Jobs) == 0:
+		fmt.Fprintf(w, "%s\t%s\n", c.Bold("Scheduler"), "no cron jobs")
+	case r.Scheduler.NextRun != nil:
+		fmt.Fprintf(w, "%s\t%d jobs, next %s at %s\n", c.Bold("Scheduler"), len(r.Scheduler.Jobs), r.Scheduler.NextJob, r.Scheduler.NextRun.Format("2006-01-02 15:04 MST"))
+	default:
+		fmt.Fprintf(w, "%s\t%d jobs, none with a valid schedule\n", c.Bold("Scheduler"), len(r.Scheduler.Jobs))
+	}
+
+	fmt.Fprintf(w, "%s\t%d accounts, %d check-ins\n", c.Bold("Pending"), r.Pending.Accounts, r.Pending.Checkins)
+	w.Flush()
+
+	if len(r.LastRuns) == 0 {
+		fmt.Fprintln(out, "\nNo pulls or pushes recorded yet.")
+		return
+	}
+	fmt.Fprintf(out, "\n%s\n", c.Bold("Last runs"))
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Direction\tSource\tStatus\tWhen\tSummary")
+	for _, run := range r.LastRuns {
+		when := run.StartedAt
+		if run.FinishedAt != nil {
+			when = *run.FinishedAt
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", run.Direction, run.Source, run.Status, when.Local().Format("2006-01-02 15:04"), run.Summary)
+		if run.Note != "" {
+			fmt.Fprintf(w, "\t\t\t\t%s\n", c.Gray("Note: %s", run.Note))
+		}
+	}
+	w.Flush()
+}
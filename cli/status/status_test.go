@@ -0,0 +1,131 @@
+package status
+
+import (
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/app/server"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	wd, err := os.Getwd()
+	if err != nil {
+		os.Exit(1)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(wd, "go.mod")); err == nil {
+			break
+		}
+		if wd == filepath.Dir(wd) {
+			os.Exit(1)
+		}
+		wd = filepath.Dir(wd)
+	}
+	if err := os.Chdir(wd); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func newStatusTestApp(t *testing.T) *app.App {
+	t.Helper()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(apiServer.Close)
+
+	a := app.NewApp()
+	a.State.NoColor = true
+	a.State.PIDFile = filepath.Join(t.TempDir(), "server.pid")
+	a.Config.DB.Type = "sqlite3"
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "status.db")})
+	if err != nil {
+		t.Fatalf("Failed to create temporary database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect to temporary database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("Failed to enforce schema: %v", err)
+	}
+	db.SetConnected(true)
+	a.DB = db
+	a.Config.API.BaseURL = apiServer.URL
+	a.API = api.NewAPIClient(&a.Config.API)
+	return a
+}
+
+func TestStatusCmdJSON(t *testing.T) {
+	a := newStatusTestApp(t)
+	seed := []string{
+		`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, Summary, Details) VALUES ('1', 'pull', 'pull', 'accounts', 'manual', 'failed', 'old', '')`,
+		`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, Summary, Details) VALUES ('2', 'pull', 'pull', 'accounts', 'manual', 'completed', 'Pulled 3 Accounts', '')`,
+		`INSERT INTO SyncHistory (CorrelationId, RunType, Direction, Source, Initiator, Status, Summary, Details) VALUES ('3', 'push', 'push', 'checkins', 'manual', 'running', 'in flight', '')`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (5, 'UPDATE', '{}')`,
+	}
+	for _, stmt := range seed {
+		if _, err := a.DB.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	a.Config.CronJobs = []server.CronJob{
+		{Name: "hourly", Schedule: "0 * * * *"},
+		{Name: "broken", Schedule: "not a schedule"},
+	}
+
+	cmd := StatusCmd(a)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--json", "--check"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("status --json --check: %v", err)
+	}
+
+	var report app.StatusReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode %s: %v", out.String(), err)
+	}
+	if !report.API.Connected || !report.Database.Connected || !report.Database.SchemaValid {
+		t.Fatalf("expected a healthy report, got %+v", report)
+	}
+	if len(report.LastRuns) != 1 || report.LastRuns[0].Summary != "Pulled 3 Accounts" {
+		t.Fatalf("last runs = %+v", report.LastRuns)
+	}
+	if report.Pending.Accounts != 1 || report.Pending.Checkins != 0 {
+		t.Fatalf("pending = %+v", report.Pending)
+	}
+	if report.Scheduler.NextJob != "hourly" || report.Scheduler.NextRun == nil || report.Scheduler.Jobs[1].Error == "" {
+		t.Fatalf("scheduler = %+v", report.Scheduler)
+	}
+	if report.Server.Running {
+		t.Fatal("expected the server to be reported as stopped")
+	}
+}
+
+func TestStatusCmdCheckFailsWhenUnhealthy(t *testing.T) {
+	a := newStatusTestApp(t)
+	a.DB.Close()
+	a.DB.SetConnected(false)
+
+	cmd := StatusCmd(a)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--check"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --check to fail without a database")
+	}
+	if !strings.Contains(out.String(), "not connected") {
+		t.Fatalf("status output = %q", out.String())
+	}
+}
@@ -0,0 +1,175 @@
+package territories
+
+import (
+	"badgermaps/app"
+	"badgermaps/utils"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// TerritoriesCmd creates the territories command for checking account
+// owners against the configured territory rules.
+func TerritoriesCmd(a *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "territories",
+		Short: "Check and assign account owners by territory",
+		Long: `Territories are rules under 'territories' in the config that assign an owner
+to the accounts in a set of zip codes or ranges, states, or a radius around a
+point. A rule with several criteria needs all of them to match; the first
+matching rule wins.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(listCmd(a))
+	cmd.AddCommand(checkCmd(a))
+	cmd.AddCommand(assignCmd(a))
+	return cmd
+}
+
+func listCmd(a *app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the configured territory rules in the order they are applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(a.Config.Territories) == 0 {
+				fmt.Println("No territories are configured.")
+				return nil
+			}
+			for i, rule := range a.Config.Territories {
+				fmt.Printf("%d. %s -> %s\n", i+1, rule.Label(), rule.Owner)
+				if len(rule.Zips) > 0 {
+					fmt.Printf("   zips:   %s\n", strings.Join(rule.Zips, ", "))
+				}
+				if len(rule.States) > 0 {
+					fmt.Printf("   states: %s\n", strings.Join(rule.States, ", "))
+				}
+				if rule.Within != "" {
+					fmt.Printf("   within: %s\n", rule.Within)
+				}
+			}
+			return app.ValidateTerritories(a.Config.Territories)
+		},
+	}
+}
+
+func checkCmd(a *app.App) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Report accounts whose owner differs from their territory's",
+		Long: `Evaluates the territory rules against the local accounts and lists every
+account whose owner differs from the one its territory assigns. Pull accounts
+first so owners and locations are current.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			report, err := a.EvaluateTerritories()
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+			printReport(report)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	return cmd
+}
+
+func assignCmd(a *app.App) *cobra.Command {
+	var rule string
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "assign",
+		Short: "Stage owner changes for accounts outside their territory's owner",
+		Long: `Stages an owner change for every mismatched account that has none staged.
+The changes wait in the push queue; review them with 'push list' or
+'push preview' and send them with 'push accounts'. --rule limits the changes
+to one territory.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			report, err := a.EvaluateTerritories()
+			if err != nil {
+				return err
+			}
+			pending := 0
+			for _, m := range report.Pending() {
+				if rule == "" || strings.EqualFold(m.Rule, rule) {
+					pending++
+				}
+			}
+			if pending == 0 && rule == "" {
+				fmt.Println("Every account already has its territory's owner or a staged change to it.")
+				return nil
+			}
+			if pending > 0 && !yes {
+				if a.State.NoInput {
+					return fmt.Errorf("pass --yes to stage owner changes when --no-input is set")
+				}
+				if !utils.PromptBool(bufio.NewReader(os.Stdin), fmt.Sprintf("Stage owner changes for %d account(s)?", pending), false) {
+					fmt.Println("Nothing was staged.")
+					return nil
+				}
+			}
+			staged, err := a.StageTerritoryOwners(rule)
+			if err != nil {
+				return err
+			}
+			if len(staged) == 0 {
+				fmt.Printf("Every account in %s already has its owner or a staged change to it.\n", rule)
+				return nil
+			}
+			fmt.Printf("Staged %d owner change(s); review them with 'badgermaps push list' before pushing.\n", len(staged))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&rule, "rule", "", "Only stage changes for this territory")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+func printReport(report *app.TerritoryReport) {
+	fmt.Printf("%d accounts: %d in a territory, %d outside every territory, %d without a location.\n",
+		report.Accounts, report.Matched, report.Unmatched, report.NoLocation)
+	rules := make([]string, 0, len(report.ByRule))
+	for rule := range report.ByRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		fmt.Printf("  %-24s %d\n", rule, report.ByRule[rule])
+	}
+	if len(report.Mismatches) == 0 {
+		fmt.Println(utils.Colors.Green("Every account has its territory's owner."))
+		return
+	}
+	fmt.Printf("\n%d account(s) have a different owner:\n", len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		current := m.Current
+		if current == "" {
+			current = "(none)"
+		}
+		line := fmt.Sprintf("  %-8d %-30s %s -> %s [%s]", m.AccountId, m.Name, current, m.Expected, m.Rule)
+		if m.Staged {
+			line += utils.Colors.Gray(" staged")
+		}
+		fmt.Println(line)
+	}
+	if pending := len(report.Pending()); pending > 0 {
+		fmt.Printf("\nRun 'badgermaps territories assign' to stage %d owner change(s).\n", pending)
+	}
+}
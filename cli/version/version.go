@@ -1,39 +1,76 @@
 package version
 
 import (
+	"badgermaps/app"
+	"badgermaps/app/update"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-var (
-	// Version information - these will be set during build
-	Version = "0.1.0"
-	Commit  = "development"
-	Date    = "unknown"
-)
+// releasesURL is where --check looks for the latest release; tests point it
+// at a local server.
+var releasesURL = update.DefaultReleasesURL
 
 // VersionCmd creates a new version command
 func VersionCmd() *cobra.Command {
+	var check bool
 	versionCmd := &cobra.Command{
 		Use:   "version",
 		Short: "Display version information",
-		Long:  `Display detailed version information about the BadgerMaps CLI.`,
-		Run: func(cmd *cobra.Command, args []string) {
-			displayVersion()
+		Long: `Display detailed version information about the BadgerMaps CLI.
+
+With --check, also look up the latest release on GitHub and show its notes
+when it is newer.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			displayVersion(cmd.OutOrStdout())
+			if !check {
+				return nil
+			}
+			result, err := update.Checker{URL: releasesURL}.Check(cmd.Context(), app.Version)
+			if err != nil {
+				return err
+			}
+			displayUpdate(cmd.OutOrStdout(), result)
+			return nil
 		},
 	}
+	versionCmd.Flags().BoolVar(&check, "check", false, "Check GitHub for a newer release")
 
 	return versionCmd
 }
 
 // displayVersion shows the version information
-func displayVersion() {
+func displayVersion(w io.Writer) {
 	bold := color.New(color.Bold).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 
-	fmt.Printf("%s %s\n", bold("BadgerMaps CLI Version:"), cyan(Version))
-	fmt.Printf("%s %s\n", bold("Commit:"), Commit)
-	fmt.Printf("%s %s\n", bold("Build Date:"), Date)
+	fmt.Fprintf(w, "%s %s\n", bold("BadgerMaps CLI Version:"), cyan(app.Version))
+	fmt.Fprintf(w, "%s %s\n", bold("Commit:"), app.Commit)
+	fmt.Fprintf(w, "%s %s\n", bold("Build Date:"), app.Date)
+}
+
+// displayUpdate reports the result of an update check, with the release
+// notes of a newer release.
+func displayUpdate(w io.Writer, result *update.Result) {
+	if !result.Available {
+		fmt.Fprintf(w, "\nYou are running the latest release (%s).\n", result.Latest.Version())
+		return
+	}
+	green := color.New(color.FgGreen, color.Bold).SprintFunc()
+	latest := result.Latest
+	fmt.Fprintf(w, "\n%s %s", green("Update available:"), latest.Version())
+	if !latest.PublishedAt.IsZero() {
+		fmt.Fprintf(w, " (released %s)", latest.PublishedAt.Format("2006-01-02"))
+	}
+	fmt.Fprintln(w)
+	if notes := strings.TrimSpace(latest.Notes); notes != "" {
+		fmt.Fprintf(w, "\n%s\n", notes)
+	}
+	if latest.PageURL != "" {
+		fmt.Fprintf(w, "\nDownload: %s\n", latest.PageURL)
+	}
 }
@@ -0,0 +1,47 @@
+package version
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/utils"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVersionCheck(t *testing.T) {
+	utils.InitColors(&state.State{NoColor: true})
+	tag := "v99.0.0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tag_name": "` + tag + `", "body": "- Faster pulls", "html_url": "https://example.com/release", "published_at": "2026-01-02T00:00:00Z"}`))
+	}))
+	defer server.Close()
+	defer func(url string) { releasesURL = url }(releasesURL)
+	releasesURL = server.URL
+
+	run := func() string {
+		var out bytes.Buffer
+		cmd := VersionCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--check"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("version --check: %v", err)
+		}
+		return out.String()
+	}
+
+	out := run()
+	for _, want := range []string{app.Version, "Update available: 99.0.0 (released 2026-01-02)", "- Faster pulls", "Download: https://example.com/release"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+
+	tag = "v" + app.Version
+	if out := run(); !strings.Contains(out, "You are running the latest release") {
+		t.Errorf("output for the current release:\n%s", out)
+	}
+}
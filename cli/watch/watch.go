@@ -0,0 +1,142 @@
+package watch
+
+import (
+	"badgermaps/app"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// WatchCmd creates the watch command, which prints the running server's
+// events as they happen.
+func WatchCmd(App *app.App) *cobra.Command {
+	var asJSON bool
+	var serverURL string
+
+	cmd := &cobra.Command{
+		Use:   "watch [pattern...]",
+		Short: "Print the running server's events live",
+		Long: `Connect to the running server and print its events as they are dispatched,
+such as pulls, pushes, webhooks, scheduled jobs, and action runs. Patterns use
+the same wildcards as event actions; without patterns every event is shown.
+
+  badgermaps watch 'pull.*' push.error
+  badgermaps watch --json 'webhook.*' | jq .
+
+Text output shows the time, type, source, and the log message or payload.
+--json prints one JSON object per line for other tools. The server only
+streams events to connections from the same machine.`,
+		Example: `  badgermaps watch
+  badgermaps watch 'action.*' log`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			target, err := eventsURL(App, serverURL, args)
+			if err != nil {
+				return err
+			}
+
+			client := &http.Client{Transport: &http.Transport{
+				// The server only streams to loopback connections, where
+				// its certificate is often self-signed for a public name.
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+			resp, err := client.Get(target)
+			if err != nil {
+				return fmt.Errorf("could not connect to the server at %s; is it running? (%w)", target, err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+				return fmt.Errorf("server refused to stream events: %s %s", resp.Status, strings.TrimSpace(string(body)))
+			}
+
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			defer signal.Stop(interrupt)
+			go func() {
+				<-interrupt
+				resp.Body.Close()
+			}()
+
+			out := cmd.OutOrStdout()
+			if !asJSON && !App.State.Quiet {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Watching %s (Ctrl+C to stop)\n", strings.Join(patternsOrAll(args), ", "))
+			}
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+			for scanner.Scan() {
+				if asJSON {
+					fmt.Fprintln(out, scanner.Text())
+					continue
+				}
+				var e events.StreamedEvent
+				if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+					continue
+				}
+				fmt.Fprintln(out, FormatEvent(e))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print each event as a JSON line")
+	cmd.Flags().StringVar(&serverURL, "server", "", "Server base URL (default from the server config, e.g. http://127.0.0.1:8080)")
+	return cmd
+}
+
+// FormatEvent renders e as one line of text.
+func FormatEvent(e events.StreamedEvent) string {
+	c := utils.Colors
+	line := fmt.Sprintf("%s %s %s", c.Gray("%s", e.Time.Local().Format("15:04:05.000")), c.Bold("%s", e.Type), c.Gray("[%s]", e.Source))
+	switch {
+	case e.Message != "":
+		return fmt.Sprintf("%s %s %s", line, e.Level, e.Message)
+	case len(e.Payload) > 0 && string(e.Payload) != "{}":
+		return line + " " + string(e.Payload)
+	}
+	return line
+}
+
+func eventsURL(a *app.App, serverURL string, patterns []string) (string, error) {
+	if serverURL == "" {
+		host := a.Config.Server.Host
+		if ip := net.ParseIP(host); host == "" || host == "localhost" || (ip != nil && ip.IsUnspecified()) {
+			host = "127.0.0.1"
+		}
+		scheme := "http"
+		if a.Config.Server.TLSEnabled {
+			scheme = "https"
+		}
+		serverURL = fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(a.Config.Server.Port)))
+	}
+	u, err := url.Parse(strings.TrimRight(serverURL, "/") + "/events")
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	query := url.Values{}
+	for _, pattern := range patterns {
+		query.Add("pattern", pattern)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func patternsOrAll(patterns []string) []string {
+	if len(patterns) == 0 {
+		return []string{"all events"}
+	}
+	return patterns
+}
@@ -0,0 +1,43 @@
+package watch
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/events"
+	"badgermaps/utils"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventsURL(t *testing.T) {
+	a := app.NewApp()
+	a.Config.Server.Host = "0.0.0.0"
+	a.Config.Server.Port = 9090
+	got, err := eventsURL(a, "", []string{"pull.*", "push.error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://127.0.0.1:9090/events?pattern=pull.%2A&pattern=push.error"; got != want {
+		t.Errorf("eventsURL = %q, want %q", got, want)
+	}
+
+	got, _ = eventsURL(a, "https://localhost:8443/", nil)
+	if got != "https://localhost:8443/events" {
+		t.Errorf("eventsURL with --server = %q", got)
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	utils.InitColors(&state.State{NoColor: true})
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local)
+	log := FormatEvent(events.StreamedEvent{Time: at, Type: "log", Source: "server", Level: "INFO", Message: "started"})
+	if !strings.Contains(log, "09:30:00.000") || !strings.HasSuffix(log, "INFO started") {
+		t.Errorf("log line = %q", log)
+	}
+	payload := FormatEvent(events.StreamedEvent{Time: at, Type: "pull.complete", Source: "accounts", Payload: json.RawMessage(`{"Count":3}`)})
+	if !strings.HasSuffix(payload, `[accounts] {"Count":3}`) {
+		t.Errorf("payload line = %q", payload)
+	}
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"badgermaps/api/models"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultScoreRankingLimit is how many accounts GetAccountScoreRanking
+// returns when no limit is given.
+const DefaultScoreRankingLimit = 20
+
+// RankedAccount is an account with one of its derived scores.
+type RankedAccount struct {
+	AccountId  int
+	FullName   string
+	Score      float64
+	ComputedAt time.Time
+}
+
+// MergeAccountScoreCommand returns the command that stores a derived score
+// of an account, replacing the one computed before.
+func MergeAccountScoreCommand(accountID int, name string, score float64, at time.Time) Command {
+	return Command{Name: "MergeAccountScore", Args: []any{accountID, name, score, at.UTC()}}
+}
+
+// DeleteAccountScoresBeforeCommand returns the command that removes scores
+// not recomputed since before, such as those of a rule that was removed.
+func DeleteAccountScoresBeforeCommand(before time.Time) Command {
+	return Command{Name: "DeleteAccountScoresBefore", Args: []any{before.UTC()}}
+}
+
+// GetAccountScoreRanking returns the accounts with the highest score called
+// name, highest first.
+func GetAccountScoreRanking(db DB, name string, limit int) ([]RankedAccount, error) {
+	sqlText := db.GetSQL("GetAccountScoreRanking")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountScoreRanking")
+	}
+	if limit <= 0 {
+		limit = DefaultScoreRankingLimit
+	}
+	sqlText = strings.Replace(sqlText, "{{LIMIT}}", strconv.Itoa(limit), 1)
+	rows, err := db.GetDB().Query(sqlText, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ranking []RankedAccount
+	for rows.Next() {
+		var r RankedAccount
+		var fullName sql.NullString
+		if err := rows.Scan(&r.AccountId, &fullName, &r.Score, &r.ComputedAt); err != nil {
+			return nil, err
+		}
+		r.FullName = fullName.String
+		ranking = append(ranking, r)
+	}
+	return ranking, rows.Err()
+}
+
+// GetAllAccounts returns every stored account, by ID.
+func GetAllAccounts(db DB) ([]*models.Account, error) {
+	sqlText := db.GetSQL("GetAllAccounts")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAllAccounts")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var accounts []*models.Account
+	for rows.Next() {
+		account, err := ScanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
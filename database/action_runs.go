@@ -0,0 +1,160 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statuses recorded in ActionRuns.
+const (
+	ActionRunSuccess = "success"
+	ActionRunFailed  = "failed"
+)
+
+// MaxActionRunOutput caps the command output kept per run. Longer output
+// keeps its end, where errors usually are.
+const MaxActionRunOutput = 16 * 1024
+
+// DefaultActionRunLimit is used when GetActionRuns is not given a limit.
+const DefaultActionRunLimit = 20
+
+// ActionRun is one recorded execution of an action.
+type ActionRun struct {
+	RunId int
+	// ActionName is the event action or cron job the action belongs to.
+	// It is empty for actions run without one, such as from the CLI.
+	ActionName string
+	ActionType string
+	// TriggeredBy is the event type that ran the action, or "cron",
+	// "manual", or "rerun".
+	TriggeredBy string
+	Source      string
+	// Config is the action's config as JSON, used to run it again.
+	Config string
+	// Event is the triggering event as JSON, empty when there was none.
+	Event string
+	// Args are the arguments after templates were rendered, as JSON.
+	Args         string
+	Status       string
+	ExitCode     *int
+	Output       string
+	RowsAffected *int64
+	ErrorMessage string
+	// RerunOf is the run this one repeated, or zero.
+	RerunOf   int
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// InsertActionRun records an action execution.
+func InsertActionRun(db DB, run ActionRun) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("InsertActionRun")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: InsertActionRun")
+	}
+	output := run.Output
+	if len(output) > MaxActionRunOutput {
+		output = "...\n" + output[len(output)-MaxActionRunOutput:]
+	}
+	var rerunOf sql.NullInt64
+	if run.RerunOf > 0 {
+		rerunOf = sql.NullInt64{Int64: int64(run.RerunOf), Valid: true}
+	}
+	_, err := db.GetDB().Exec(sqlText,
+		run.ActionName, run.ActionType, run.TriggeredBy, emptyAsNull(run.Source),
+		emptyAsNull(run.Config), emptyAsNull(run.Event), emptyAsNull(run.Args),
+		run.Status, run.ExitCode, emptyAsNull(output), run.RowsAffected, emptyAsNull(run.ErrorMessage),
+		rerunOf, run.StartedAt.UTC(), run.Duration.Milliseconds(),
+	)
+	return err
+}
+
+// GetActionRuns returns the latest runs, newest first, of the action named
+// name, or of every action when name is empty.
+func GetActionRuns(db DB, name string, limit int) ([]ActionRun, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetActionRuns")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetActionRuns")
+	}
+	if limit <= 0 {
+		limit = DefaultActionRunLimit
+	}
+	sqlText = strings.Replace(sqlText, "{{LIMIT}}", strconv.Itoa(limit), 1)
+	rows, err := db.GetDB().Query(sqlText, name, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanActionRuns(rows)
+}
+
+// GetActionRun returns the run with the given ID, or nil when there is none.
+func GetActionRun(db DB, id int) (*ActionRun, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetActionRun")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetActionRun")
+	}
+	rows, err := db.GetDB().Query(sqlText, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	runs, err := scanActionRuns(rows)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+func scanActionRuns(rows *sql.Rows) ([]ActionRun, error) {
+	var runs []ActionRun
+	for rows.Next() {
+		var (
+			run                                                     ActionRun
+			name, source, config, event, args, output, errorMessage sql.NullString
+			exitCode, rowsAffected, rerunOf, duration               sql.NullInt64
+			startedAt                                               any
+		)
+		if err := rows.Scan(&run.RunId, &name, &run.ActionType, &run.TriggeredBy, &source, &config, &event, &args,
+			&run.Status, &exitCode, &output, &rowsAffected, &errorMessage, &rerunOf, &startedAt, &duration); err != nil {
+			return nil, err
+		}
+		run.ActionName = name.String
+		run.Source = source.String
+		run.Config = config.String
+		run.Event = event.String
+		run.Args = args.String
+		run.Output = output.String
+		run.ErrorMessage = errorMessage.String
+		run.RerunOf = int(rerunOf.Int64)
+		run.StartedAt = normaliseToTime(startedAt)
+		run.Duration = time.Duration(duration.Int64) * time.Millisecond
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			run.ExitCode = &code
+		}
+		if rowsAffected.Valid {
+			n := rowsAffected.Int64
+			run.RowsAffected = &n
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// emptyAsNull stores an empty string as NULL.
+func emptyAsNull(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
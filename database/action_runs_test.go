@@ -0,0 +1,50 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionRunsRoundTrip(t *testing.T) {
+	db := newBackupTestDB(t, "runs.db")
+	code, rows := 0, int64(3)
+	started := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	runs := []ActionRun{
+		{ActionName: "notify", ActionType: "exec", TriggeredBy: "pull.complete", Config: `{"type":"exec"}`, Args: `{"command":"echo hi"}`,
+			Status: ActionRunSuccess, ExitCode: &code, Output: strings.Repeat("x", MaxActionRunOutput+10), StartedAt: started, Duration: 1500 * time.Millisecond},
+		{ActionName: "cleanup", ActionType: "db", TriggeredBy: "cron", Status: ActionRunSuccess, RowsAffected: &rows, StartedAt: started},
+		{ActionName: "notify", ActionType: "exec", TriggeredBy: "rerun", Status: ActionRunFailed, ErrorMessage: "exit status 1", RerunOf: 1, StartedAt: started},
+	}
+	for _, run := range runs {
+		if err := InsertActionRun(db, run); err != nil {
+			t.Fatalf("InsertActionRun: %v", err)
+		}
+	}
+
+	got, err := GetActionRuns(db, "notify", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].RunId != 3 || got[0].RerunOf != 1 || got[0].Status != ActionRunFailed {
+		t.Fatalf("runs of notify = %+v", got)
+	}
+	first := got[1]
+	if first.ExitCode == nil || *first.ExitCode != 0 || first.RowsAffected != nil || first.Duration != 1500*time.Millisecond || !first.StartedAt.Equal(started) {
+		t.Errorf("first run = %+v", first)
+	}
+	if len(first.Output) != MaxActionRunOutput+4 || !strings.HasPrefix(first.Output, "...\n") {
+		t.Errorf("output was not cut to its end: %d bytes", len(first.Output))
+	}
+
+	if all, err := GetActionRuns(db, "", 2); err != nil || len(all) != 2 {
+		t.Fatalf("GetActionRuns(all, 2) = %d runs, %v", len(all), err)
+	}
+	run, err := GetActionRun(db, 2)
+	if err != nil || run == nil || run.RowsAffected == nil || *run.RowsAffected != 3 || run.ExitCode != nil {
+		t.Fatalf("GetActionRun(2) = %+v, %v", run, err)
+	}
+	if run, err := GetActionRun(db, 99); err != nil || run != nil {
+		t.Errorf("GetActionRun(99) = %+v, %v; want nil", run, err)
+	}
+}
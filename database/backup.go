@@ -0,0 +1,546 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backup formats understood by BackupDatabase and RestoreDatabase.
+const (
+	// BackupFormatSQLite is a byte-for-byte SQLite database file.
+	BackupFormatSQLite = "sqlite"
+	// BackupFormatJSON is a portable JSON-lines dump that can be restored into
+	// any supported database type.
+	BackupFormatJSON = "json"
+)
+
+const (
+	backupFormatName    = "badgermaps-backup"
+	backupFormatVersion = 1
+	sqliteFileHeader    = "SQLite format 3\x00"
+)
+
+// backupTables lists the tables included in a logical backup, parents before
+// children so rows can be restored without violating foreign keys.
+var backupTables = []string{
+	"Configurations",
+	"UserProfiles",
+	"DataSets",
+	"DataSetValues",
+	"FieldMaps",
+	"Accounts",
+	"AccountCheckins",
+	"AccountLocations",
+	"AccountsPendingChanges",
+	"AccountCheckinsPendingChanges",
+	"Routes",
+	"RouteWaypoints",
+	"RoutePlans",
+	"SyncHistory",
+	"CommandLog",
+	"WebhookLog",
+	"DeletedAccounts",
+	"IdRemap",
+	"AccountErasures",
+	"ActionRuns",
+}
+
+// backupRecord is one line of a JSON backup. The first line carries the
+// header fields, each table starts with a Table/Columns line, and every
+// following Row line belongs to the most recent table.
+type backupRecord struct {
+	Format      string        `json:"format,omitempty"`
+	Version     int           `json:"version,omitempty"`
+	Source      string        `json:"source,omitempty"`
+	CreatedAt   string        `json:"created_at,omitempty"`
+	Table       string        `json:"table,omitempty"`
+	Columns     []string      `json:"columns,omitempty"`
+	TimeColumns []string      `json:"time_columns,omitempty"`
+	Row         []interface{} `json:"row,omitempty"`
+}
+
+// DefaultBackupFormat returns the format used when none is requested:
+// SQLite databases are copied as files, server databases are dumped as JSON.
+func DefaultBackupFormat(db DB) string {
+	if db.GetType() == "sqlite3" {
+		return BackupFormatSQLite
+	}
+	return BackupFormatJSON
+}
+
+// BackupDatabase writes a consistent backup of db to out. An empty format
+// selects DefaultBackupFormat.
+func BackupDatabase(db DB, out, format string) error {
+	if db == nil || !db.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	if format == "" {
+		format = DefaultBackupFormat(db)
+	}
+	if dir := filepath.Dir(out); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+
+	switch format {
+	case BackupFormatSQLite:
+		if db.GetType() != "sqlite3" {
+			return fmt.Errorf("%s backups are only available for sqlite3 databases", BackupFormatSQLite)
+		}
+		return backupSQLiteFile(db, out)
+	case BackupFormatJSON:
+		tmp := out + ".tmp"
+		file, err := os.Create(tmp)
+		if err != nil {
+			return fmt.Errorf("failed to create backup file: %w", err)
+		}
+		if err := ExportJSON(db, file); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+		if err := file.Close(); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return os.Rename(tmp, out)
+	default:
+		return fmt.Errorf("unknown backup format: %s", format)
+	}
+}
+
+// backupSQLiteFile checkpoints the WAL into the main file and then uses
+// VACUUM INTO, which copies a transactionally consistent snapshot even while
+// other connections keep writing.
+func backupSQLiteFile(db DB, out string) error {
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+	// VACUUM INTO refuses to overwrite an existing file.
+	if err := os.Remove(out); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace existing backup: %w", err)
+	}
+	if _, err := sqlDB.Exec("VACUUM INTO ?", out); err != nil {
+		return fmt.Errorf("failed to copy database: %w", err)
+	}
+	return nil
+}
+
+// ExportJSON writes every backed-up table in db to w as a JSON-lines dump.
+func ExportJSON(db DB, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	header := backupRecord{
+		Format:    backupFormatName,
+		Version:   backupFormatVersion,
+		Source:    db.GetType(),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	for _, table := range backupTables {
+		exists, err := db.TableExists(table)
+		if err != nil {
+			return fmt.Errorf("failed to check table %s: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+		if err := exportTable(db, table, enc); err != nil {
+			return fmt.Errorf("failed to export %s: %w", table, err)
+		}
+	}
+	return buf.Flush()
+}
+
+func exportTable(db DB, table string, enc *json.Encoder) error {
+	return readTable(db, table, func(header backupRecord) error {
+		return enc.Encode(header)
+	}, func(row []interface{}) error {
+		for i, v := range row {
+			if t, ok := v.(time.Time); ok {
+				row[i] = t.Format(time.RFC3339Nano)
+			}
+		}
+		return enc.Encode(backupRecord{Row: row})
+	})
+}
+
+// readTable streams every row of table: onHeader gets its columns and which
+// of them hold times, then onRow each row with text as strings. A row is
+// not reused after onRow returns.
+func readTable(db DB, table string, onHeader func(backupRecord) error, onRow func([]interface{}) error) error {
+	rows, err := db.GetDB().Query(fmt.Sprintf("SELECT * FROM %s", QualifiedName(db, table)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	record := backupRecord{Table: table, Columns: columns}
+	for i, ct := range types {
+		name := strings.ToUpper(ct.DatabaseTypeName())
+		if strings.Contains(name, "DATE") || strings.Contains(name, "TIME") {
+			record.TimeColumns = append(record.TimeColumns, columns[i])
+		}
+	}
+	if err := onHeader(record); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make([]interface{}, len(values))
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				row[i] = string(b)
+			} else {
+				row[i] = v
+			}
+		}
+		if err := onRow(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// RestoreDatabase replaces the contents of db with the backup at in. SQLite
+// file backups are swapped in place and the connection is reopened; JSON
+// backups are loaded table by table inside a single transaction.
+func RestoreDatabase(db DB, in string) error {
+	if db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	file, err := os.Open(in)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	head, _ := reader.Peek(len(sqliteFileHeader))
+	if bytes.Equal(head, []byte(sqliteFileHeader)) {
+		sqliteDB, ok := db.(*SQLiteConfig)
+		if !ok {
+			return fmt.Errorf("backup is a SQLite file and can only be restored into a sqlite3 database; use a json backup for %s", db.GetType())
+		}
+		file.Close()
+		return restoreSQLiteFile(sqliteDB, in)
+	}
+
+	if !db.IsConnected() {
+		return fmt.Errorf("database is not connected")
+	}
+	return ImportJSON(db, reader)
+}
+
+func restoreSQLiteFile(db *SQLiteConfig, in string) error {
+	if db.Path == "" {
+		return fmt.Errorf("sqlite database path is not configured")
+	}
+	tmp := db.Path + ".restore"
+	if err := copyFile(in, tmp); err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+
+	if err := db.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		os.Remove(db.Path + suffix)
+	}
+	if err := os.Rename(tmp, db.Path); err != nil {
+		os.Remove(tmp)
+		db.Connect()
+		return fmt.Errorf("failed to replace database file: %w", err)
+	}
+	if err := db.Connect(); err != nil {
+		return fmt.Errorf("restored database could not be opened: %w", err)
+	}
+	return db.TestConnection()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// ImportJSON loads a dump produced by ExportJSON. Tables present in the dump
+// are emptied first; columns missing from the target schema are skipped so
+// older backups can be restored into newer schemas.
+func ImportJSON(db DB, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var header backupRecord
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("failed to read backup header: %w", err)
+	}
+	if header.Format != backupFormatName {
+		return fmt.Errorf("file is not a BadgerMaps backup")
+	}
+	if header.Version > backupFormatVersion {
+		return fmt.Errorf("backup version %d is newer than supported version %d", header.Version, backupFormatVersion)
+	}
+
+	// Inspect the target schema before opening the transaction; SQLite cannot
+	// serve these lookups on another connection while the write is pending.
+	targets := make(map[string][]string)
+	for _, table := range backupTables {
+		exists, err := db.TableExists(table)
+		if err != nil {
+			return fmt.Errorf("failed to check table %s: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+		columns, err := db.GetTableColumns(table)
+		if err != nil {
+			return fmt.Errorf("failed to read columns for %s: %w", table, err)
+		}
+		targets[table] = columns
+	}
+
+	tx, err := db.GetDB().Begin()
+	if err != nil {
+		return err
+	}
+	restorer := &tableRestorer{db: db, dbType: db.GetType(), tx: tx, targets: targets}
+	if err := restorer.clear(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for {
+		var record backupRecord
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to read backup: %w", err)
+		}
+		if record.Table != "" {
+			err = restorer.begin(record)
+		} else {
+			err = restorer.insert(record.Row)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := restorer.finish(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// tableRestorer inserts rows for one table at a time within tx.
+type tableRestorer struct {
+	db      DB
+	dbType  string
+	tx      *sql.Tx
+	targets map[string][]string // existing tables and their columns
+	table   string
+	stmt    *sql.Stmt
+	keep    []int  // indexes of backup columns present in the target table
+	isTime  []bool // parallel to keep
+	columns []string
+}
+
+// clear empties every backed-up table, children first.
+func (r *tableRestorer) clear() error {
+	return r.clearTables(backupTables)
+}
+
+// clearTables empties tables, which are listed parents first.
+func (r *tableRestorer) clearTables(tables []string) error {
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+		if _, ok := r.targets[table]; !ok {
+			continue
+		}
+		if _, err := r.tx.Exec(fmt.Sprintf("DELETE FROM %s", QualifiedName(r.db, table))); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func (r *tableRestorer) begin(record backupRecord) error {
+	if err := r.finish(); err != nil {
+		return err
+	}
+	targetColumns, ok := r.targets[record.Table]
+	if !ok {
+		return fmt.Errorf("table %s from the backup does not exist; initialize the schema first", record.Table)
+	}
+	target := make(map[string]bool, len(targetColumns))
+	for _, column := range targetColumns {
+		target[strings.ToLower(column)] = true
+	}
+	timeColumns := make(map[string]bool, len(record.TimeColumns))
+	for _, column := range record.TimeColumns {
+		timeColumns[column] = true
+	}
+
+	r.table = record.Table
+	r.keep, r.isTime, r.columns = nil, nil, nil
+	for i, column := range record.Columns {
+		if !target[strings.ToLower(column)] {
+			continue
+		}
+		r.keep = append(r.keep, i)
+		r.isTime = append(r.isTime, timeColumns[column])
+		r.columns = append(r.columns, column)
+	}
+	if len(r.columns) == 0 {
+		return nil
+	}
+
+	if r.dbType == "mssql" {
+		// Fails harmlessly for tables without an identity column.
+		r.tx.Exec(fmt.Sprintf("SET IDENTITY_INSERT %s ON", QualifiedName(r.db, r.table)))
+	}
+	placeholders := make([]string, len(r.columns))
+	for i := range placeholders {
+		placeholders[i] = placeholder(r.dbType, i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", QualifiedName(r.db, r.table), strings.Join(r.columns, ", "), strings.Join(placeholders, ", "))
+	stmt, err := r.tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare restore of %s: %w", r.table, err)
+	}
+	r.stmt = stmt
+	return nil
+}
+
+func (r *tableRestorer) insert(row []interface{}) error {
+	if r.table == "" {
+		return fmt.Errorf("backup row found before any table header")
+	}
+	if r.stmt == nil {
+		return nil
+	}
+	args := make([]interface{}, len(r.keep))
+	for i, idx := range r.keep {
+		if idx >= len(row) {
+			return fmt.Errorf("backup row for %s has %d values, expected at least %d", r.table, len(row), idx+1)
+		}
+		args[i] = restoreValue(row[idx], r.isTime[i])
+	}
+	if _, err := r.stmt.Exec(args...); err != nil {
+		return fmt.Errorf("failed to restore row into %s: %w", r.table, err)
+	}
+	return nil
+}
+
+// finish closes the current table and resynchronizes identity sequences.
+func (r *tableRestorer) finish() error {
+	if r.stmt == nil {
+		r.table = ""
+		return nil
+	}
+	r.stmt.Close()
+	r.stmt = nil
+	table := r.table
+	r.table = ""
+
+	switch r.dbType {
+	case "mssql":
+		r.tx.Exec(fmt.Sprintf("SET IDENTITY_INSERT %s OFF", QualifiedName(r.db, table)))
+	case "postgres":
+		for _, column := range r.columns {
+			var sequence sql.NullString
+			if err := r.tx.QueryRow("SELECT pg_get_serial_sequence($1, $2)", strings.ToLower(table), strings.ToLower(column)).Scan(&sequence); err != nil {
+				return err
+			}
+			if !sequence.Valid {
+				continue
+			}
+			query := fmt.Sprintf("SELECT setval($1, COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)", column, table)
+			if _, err := r.tx.Exec(query, sequence.String); err != nil {
+				return fmt.Errorf("failed to reset sequence for %s.%s: %w", table, column, err)
+			}
+		}
+	}
+	return nil
+}
+
+func restoreValue(v interface{}, isTime bool) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case string:
+		if isTime {
+			if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+				return t
+			}
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+func placeholder(dbType string, n int) string {
+	switch dbType {
+	case "postgres":
+		return fmt.Sprintf("$%d", n)
+	case "mssql":
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return "?"
+	}
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"badgermaps/app/state"
+	"path/filepath"
+	"testing"
+)
+
+func newBackupTestDB(t *testing.T, name string) DB {
+	t.Helper()
+	db, err := NewDB(&DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), name)})
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("EnforceSchema: %v", err)
+	}
+	if err := db.TestConnection(); err != nil {
+		t.Fatalf("TestConnection: %v", err)
+	}
+	return db
+}
+
+func countAccounts(t *testing.T, db DB) int {
+	t.Helper()
+	var n int
+	if err := db.GetDB().QueryRow("SELECT COUNT(*) FROM Accounts").Scan(&n); err != nil {
+		t.Fatalf("count accounts: %v", err)
+	}
+	return n
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "sqlite file", format: BackupFormatSQLite},
+		{name: "json dump", format: BackupFormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newBackupTestDB(t, "source.db")
+			sqlDB := db.GetDB()
+			if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, FullName, UpdatedAt) VALUES (1, 'Acme', '2024-01-01 00:00:00'), (2, 'Globex', '2024-01-02 00:00:00')"); err != nil {
+				t.Fatalf("seed accounts: %v", err)
+			}
+			if err := StageAccountChange(db, 1, "UPDATE", `{"notes":"x"}`); err != nil {
+				t.Fatalf("stage change: %v", err)
+			}
+
+			backup := filepath.Join(t.TempDir(), "backups", "backup")
+			if err := BackupDatabase(db, backup, tt.format); err != nil {
+				t.Fatalf("BackupDatabase: %v", err)
+			}
+
+			if _, err := sqlDB.Exec("DELETE FROM Accounts WHERE AccountId = 2"); err != nil {
+				t.Fatalf("delete account: %v", err)
+			}
+			if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (3, 'Initech')"); err != nil {
+				t.Fatalf("insert account: %v", err)
+			}
+
+			if err := RestoreDatabase(db, backup); err != nil {
+				t.Fatalf("RestoreDatabase: %v", err)
+			}
+			if !db.IsConnected() {
+				t.Fatal("expected database to be connected after restore")
+			}
+			if got := countAccounts(t, db); got != 2 {
+				t.Fatalf("expected 2 accounts after restore, got %d", got)
+			}
+			var name string
+			if err := db.GetDB().QueryRow("SELECT FullName FROM Accounts WHERE AccountId = 2").Scan(&name); err != nil || name != "Globex" {
+				t.Fatalf("expected Globex restored, got %q (%v)", name, err)
+			}
+			conflict, err := GetAccountChangeConflict(db, 1)
+			if err != nil {
+				t.Fatalf("GetAccountChangeConflict: %v", err)
+			}
+			if conflict != nil {
+				t.Fatalf("restored timestamps should still match, got %+v", conflict)
+			}
+		})
+	}
+}
+
+func TestRestoreJSONIntoDifferentDatabase(t *testing.T) {
+	source := newBackupTestDB(t, "source.db")
+	if _, err := source.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (7, 'Umbrella')"); err != nil {
+		t.Fatalf("seed account: %v", err)
+	}
+	backup := filepath.Join(t.TempDir(), "backup.json")
+	if err := BackupDatabase(source, backup, BackupFormatJSON); err != nil {
+		t.Fatalf("BackupDatabase: %v", err)
+	}
+
+	target := newBackupTestDB(t, "target.db")
+	if err := RestoreDatabase(target, backup); err != nil {
+		t.Fatalf("RestoreDatabase: %v", err)
+	}
+	if got := countAccounts(t, target); got != 1 {
+		t.Fatalf("expected 1 account in target, got %d", got)
+	}
+}
+
+func TestBackupRejectsFileFormatForServerDatabases(t *testing.T) {
+	db := &PostgreSQLConfig{}
+	db.connected.Store(true)
+	if err := BackupDatabase(db, filepath.Join(t.TempDir(), "x.db"), BackupFormatSQLite); err == nil {
+		t.Fatal("expected sqlite file backups to be rejected for postgres")
+	}
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// CheckinRecord is one AccountCheckins row as a pull stores it.
+type CheckinRecord struct {
+	CheckinId    int64
+	CrmId        sql.NullString
+	AccountId    sql.NullInt64
+	LogDatetime  sql.NullString
+	Type         sql.NullString
+	Comments     sql.NullString
+	ExtraFields  string
+	EndpointType string
+	CreatedBy    sql.NullString
+}
+
+func (r CheckinRecord) args() []interface{} {
+	return []interface{}{r.CheckinId, r.CrmId, r.AccountId, r.LogDatetime, r.Type, r.Comments, r.ExtraFields, r.EndpointType, r.CreatedBy}
+}
+
+// checkinBulkColumns are the columns of CheckinRecord in args order.
+var checkinBulkColumns = []string{"CheckinId", "CrmId", "AccountId", "LogDatetime", "Type", "Comments", "ExtraFields", "EndpointType", "CreatedBy"}
+
+// sqliteMaxVariables is the bound-parameter limit of the SQLite bundled
+// with go-sqlite3.
+const sqliteMaxVariables = 32766
+
+// MergeCheckin stores a single check-in, replacing any stored row with the
+// same CheckinId.
+func MergeCheckin(db DB, row CheckinRecord) error {
+	return RunCommand(db, "MergeAccountCheckins", row.args()...)
+}
+
+// MergeCheckinCommand returns the command MergeCheckin runs, to store a
+// check-in in one transaction with other rows.
+func MergeCheckinCommand(row CheckinRecord) Command {
+	return Command{Name: "MergeAccountCheckins", Args: row.args()}
+}
+
+// BulkMergeCheckins stores rows in one transaction using the fastest path
+// the database offers: multi-row VALUES on SQLite, COPY FROM on PostgreSQL,
+// and bulk copy on SQL Server, each into the same upsert MergeCheckin does.
+// When rows repeat a CheckinId the last one wins. Callers choose the batch
+// size by how many rows they pass.
+func BulkMergeCheckins(db DB, rows []CheckinRecord) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	rows = lastCheckinRecords(rows)
+	if len(rows) == 0 {
+		return nil
+	}
+	switch db.GetType() {
+	case "sqlite3":
+		return bulkMergeCheckinsSQLite(db, rows)
+	case "postgres":
+		return bulkMergeCheckinsCopy(db, rows, pq.CopyIn("bulkaccountcheckins", lowerAll(checkinBulkColumns)...))
+	case "mssql":
+		return bulkMergeCheckinsCopy(db, rows, mssql.CopyIn("#BulkAccountCheckins", mssql.BulkOptions{KeepNulls: true}, checkinBulkColumns...))
+	}
+	for _, row := range rows {
+		if err := MergeCheckin(db, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastCheckinRecords drops all but the last row for each CheckinId, since an
+// upsert cannot touch the same row twice in one statement.
+func lastCheckinRecords(rows []CheckinRecord) []CheckinRecord {
+	last := make(map[int64]int, len(rows))
+	for i, row := range rows {
+		last[row.CheckinId] = i
+	}
+	if len(last) == len(rows) {
+		return rows
+	}
+	unique := make([]CheckinRecord, 0, len(last))
+	for i, row := range rows {
+		if last[row.CheckinId] == i {
+			unique = append(unique, row)
+		}
+	}
+	return unique
+}
+
+func bulkMergeCheckinsSQLite(db DB, rows []CheckinRecord) error {
+	prefix := db.GetSQL("BulkMergeAccountCheckins")
+	if prefix == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: BulkMergeAccountCheckins")
+	}
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(checkinBulkColumns)), ", ") + ", CURRENT_TIMESTAMP)"
+	perStatement := sqliteMaxVariables / len(checkinBulkColumns)
+
+	return Work(db, "store.checkins", func(u *UnitOfWork) error {
+		for start := 0; start < len(rows); start += perStatement {
+			end := start + perStatement
+			if end > len(rows) {
+				end = len(rows)
+			}
+			chunk := rows[start:end]
+			tuples := make([]string, len(chunk))
+			args := make([]interface{}, 0, len(chunk)*len(checkinBulkColumns))
+			for i, row := range chunk {
+				tuples[i] = tuple
+				args = append(args, row.args()...)
+			}
+			if _, err := u.ExecSQL(prefix+"\n"+strings.Join(tuples, ",\n")+";", args...); err != nil {
+				return fmt.Errorf("failed to insert check-ins: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// bulkMergeCheckinsCopy streams rows into a temporary table with the
+// driver's copy statement, then upserts them into AccountCheckins.
+func bulkMergeCheckinsCopy(db DB, rows []CheckinRecord, copyStatement string) error {
+	createSQL := db.GetSQL("CreateCheckinBulkTable")
+	mergeSQL := db.GetSQL("MergeCheckinBulkTable")
+	if createSQL == "" || mergeSQL == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: CreateCheckinBulkTable or MergeCheckinBulkTable")
+	}
+
+	// The transaction keeps the temporary table and the copy on one
+	// connection.
+	return Work(db, "store.checkins", func(u *UnitOfWork) error {
+		if _, err := u.ExecSQL(createSQL); err != nil {
+			return fmt.Errorf("failed to create check-in staging table: %w", err)
+		}
+		stmt, err := u.Tx().Prepare(copyStatement)
+		if err != nil {
+			return fmt.Errorf("failed to start check-in copy: %w", err)
+		}
+		for _, row := range rows {
+			if _, err := stmt.Exec(row.args()...); err != nil {
+				stmt.Close()
+				return fmt.Errorf("failed to copy check-in %d: %w", row.CheckinId, err)
+			}
+		}
+		if _, err := stmt.Exec(); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy check-ins: %w", err)
+		}
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+		if _, err := u.ExecSQL(mergeSQL); err != nil {
+			return fmt.Errorf("failed to merge check-ins: %w", err)
+		}
+		return nil
+	})
+}
+
+func lowerAll(names []string) []string {
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+	return lower
+}
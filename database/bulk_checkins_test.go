@@ -0,0 +1,53 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestBulkMergeCheckins(t *testing.T) {
+	db := newBackupTestDB(t, "bulk.db")
+	if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, LastName) VALUES (1, 'Acme')`); err != nil {
+		t.Fatal(err)
+	}
+	row := func(id int64, comments string) CheckinRecord {
+		return CheckinRecord{
+			CheckinId:    id,
+			AccountId:    sql.NullInt64{Int64: 1, Valid: true},
+			Type:         sql.NullString{String: "Drop-in", Valid: true},
+			Comments:     sql.NullString{String: comments, Valid: true},
+			EndpointType: "standard",
+		}
+	}
+
+	if err := BulkMergeCheckins(db, []CheckinRecord{row(1, "first"), row(2, "second"), row(1, "first again")}); err != nil {
+		t.Fatalf("BulkMergeCheckins: %v", err)
+	}
+	if err := BulkMergeCheckins(db, []CheckinRecord{row(2, "updated"), row(3, "third")}); err != nil {
+		t.Fatalf("BulkMergeCheckins update: %v", err)
+	}
+
+	want := map[int64]string{1: "first again", 2: "updated", 3: "third"}
+	rows, err := db.GetDB().Query(`SELECT CheckinId, Comments FROM AccountCheckins`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	got := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var comments string
+		if err := rows.Scan(&id, &comments); err != nil {
+			t.Fatal(err)
+		}
+		got[id] = comments
+	}
+	if len(got) != len(want) {
+		t.Fatalf("stored %v, want %v", got, want)
+	}
+	for id, comments := range want {
+		if got[id] != comments {
+			t.Errorf("check-in %d comments = %q, want %q", id, got[id], comments)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import "fmt"
+
+// ApplicationName is the application name the app's PostgreSQL and SQL
+// Server connections report. The change capture triggers skip writes made
+// under it, so only changes from other systems become pending changes.
+const ApplicationName = "badgermaps-sync"
+
+// EnableChangeCapture installs triggers that record updates and deletes made
+// to Accounts by other systems as pending account changes, ready to push.
+func EnableChangeCapture(db DB) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	if db.GetType() == "sqlite3" {
+		if err := RunCommand(db, "CreateChangeCaptureStateTable"); err != nil {
+			return fmt.Errorf("failed to create change capture state: %w", err)
+		}
+	}
+	if err := RunCommand(db, "CreateAccountsChangeCaptureTrigger"); err != nil {
+		return fmt.Errorf("failed to create change capture triggers: %w", err)
+	}
+	return nil
+}
+
+// DisableChangeCapture removes the change capture triggers. Pending changes
+// already captured are kept.
+func DisableChangeCapture(db DB) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	if err := RunCommand(db, "DropAccountsChangeCaptureTrigger"); err != nil {
+		return fmt.Errorf("failed to drop change capture triggers: %w", err)
+	}
+	return nil
+}
+
+// ChangeCaptureEnabled reports whether the change capture triggers exist.
+func ChangeCaptureEnabled(db DB) (bool, error) {
+	if db == nil || db.GetDB() == nil {
+		return false, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetChangeCaptureStatus")
+	if sqlText == "" {
+		return false, fmt.Errorf("unknown or unavailable SQL command: GetChangeCaptureStatus")
+	}
+	var count int
+	if err := db.GetDB().QueryRow(sqlText).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// WithoutChangeCapture runs fn with change capture paused, for the app's own
+// writes to Accounts. SQLite connections carry no application name, so the
+// triggers there check a counter instead; on other databases fn runs as is.
+func WithoutChangeCapture(db DB, fn func() error) error {
+	if db == nil || db.GetDB() == nil || db.GetType() != "sqlite3" {
+		return fn()
+	}
+	if err := RunCommand(db, "SuppressChangeCapture"); err != nil {
+		return fmt.Errorf("failed to pause change capture: %w", err)
+	}
+	defer RunCommand(db, "ResumeChangeCapture")
+	return fn()
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChangeCaptureSQLite(t *testing.T) {
+	db := newBackupTestDB(t, "capture.db")
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec(`INSERT INTO Accounts (AccountId, FullName, PhoneNumber, CustomNumeric2) VALUES (1, 'Acme', '555-0100', 1), (2, 'Globex', NULL, NULL)`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := EnableChangeCapture(db); err != nil {
+		t.Fatalf("EnableChangeCapture: %v", err)
+	}
+	if enabled, err := ChangeCaptureEnabled(db); err != nil || !enabled {
+		t.Fatalf("ChangeCaptureEnabled = %v, %v", enabled, err)
+	}
+
+	// An external update queues only the changed fields, as strings.
+	if _, err := sqlDB.Exec(`UPDATE Accounts SET PhoneNumber = '555-0199', CustomNumeric2 = 2.5, FullName = 'Acme Corp' WHERE AccountId = 1`); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// The app's own writes are not captured.
+	err := WithoutChangeCapture(db, func() error {
+		_, err := sqlDB.Exec(`UPDATE Accounts SET PhoneNumber = '555-0200' WHERE AccountId = 2`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithoutChangeCapture: %v", err)
+	}
+	if _, err := sqlDB.Exec(`DELETE FROM Accounts WHERE AccountId = 2`); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	rows, err := sqlDB.Query(`SELECT AccountId, ChangeType, Changes FROM AccountsPendingChanges ORDER BY ChangeId`)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	type change struct {
+		id      int
+		kind    string
+		changes string
+	}
+	var got []change
+	for rows.Next() {
+		var c change
+		if err := rows.Scan(&c.id, &c.kind, &c.changes); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, c)
+	}
+	if len(got) != 2 {
+		t.Fatalf("pending changes = %+v, want an update and a delete", got)
+	}
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(got[0].changes), &fields); err != nil {
+		t.Fatalf("changes %q: %v", got[0].changes, err)
+	}
+	if got[0].id != 1 || got[0].kind != "UPDATE" || len(fields) != 2 || fields["phone_number"] != "555-0199" || fields["custom_numeric2"] != "2.5" {
+		t.Errorf("update = %+v (%v)", got[0], fields)
+	}
+	if got[1].id != 2 || got[1].kind != "DELETE" {
+		t.Errorf("delete = %+v", got[1])
+	}
+
+	if err := DisableChangeCapture(db); err != nil {
+		t.Fatalf("DisableChangeCapture: %v", err)
+	}
+	if enabled, err := ChangeCaptureEnabled(db); err != nil || enabled {
+		t.Fatalf("ChangeCaptureEnabled after disable = %v, %v", enabled, err)
+	}
+}
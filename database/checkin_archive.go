@@ -0,0 +1,474 @@
+package database
+
+import (
+	"badgermaps/utils"
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CheckinArchiveIndexFile is the name of the archive index inside the
+// archive directory. It lists the archived months and their aggregates.
+const CheckinArchiveIndexFile = "checkins-index.json"
+
+var archiveMonthPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+// ArchivedCheckin is one check-in row as written to an archive file.
+type ArchivedCheckin struct {
+	CheckinId    int64   `json:"checkin_id"`
+	CrmId        *string `json:"crm_id"`
+	AccountId    *int64  `json:"account_id"`
+	LogDatetime  *string `json:"log_datetime"`
+	Type         *string `json:"type"`
+	Comments     *string `json:"comments"`
+	ExtraFields  *string `json:"extra_fields"`
+	EndpointType string  `json:"endpoint_type"`
+	CreatedBy    *string `json:"created_by"`
+}
+
+// CheckinArchiveMonth summarises the check-ins archived for one month so
+// dashboards can still count them.
+type CheckinArchiveMonth struct {
+	File       string         `json:"file"`
+	Count      int            `json:"count"`
+	ByAccount  map[int64]int  `json:"by_account,omitempty"`
+	ByType     map[string]int `json:"by_type,omitempty"`
+	ArchivedAt time.Time      `json:"archived_at"`
+}
+
+// CheckinArchiveIndex lists the archived months keyed by "YYYY-MM".
+type CheckinArchiveIndex struct {
+	Months map[string]*CheckinArchiveMonth `json:"months"`
+}
+
+// CheckinArchiveResult reports what ArchiveCheckins or RestoreCheckins moved.
+type CheckinArchiveResult struct {
+	Months   []string
+	Checkins int
+	// Skipped counts restored rows that could not be written back, for
+	// example because their account no longer exists.
+	Skipped int
+}
+
+// LoadCheckinArchiveIndex reads the index in dir. A missing index is an
+// empty archive.
+func LoadCheckinArchiveIndex(dir string) (*CheckinArchiveIndex, error) {
+	index := &CheckinArchiveIndex{Months: make(map[string]*CheckinArchiveMonth)}
+	data, err := os.ReadFile(filepath.Join(dir, CheckinArchiveIndexFile))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("invalid check-in archive index: %w", err)
+	}
+	if index.Months == nil {
+		index.Months = make(map[string]*CheckinArchiveMonth)
+	}
+	return index, nil
+}
+
+// Count returns the number of archived check-ins.
+func (idx *CheckinArchiveIndex) Count() int {
+	total := 0
+	for _, month := range idx.Months {
+		total += month.Count
+	}
+	return total
+}
+
+// Contains reports whether the month ("YYYY-MM") is archived.
+func (idx *CheckinArchiveIndex) Contains(month string) bool {
+	_, ok := idx.Months[month]
+	return ok
+}
+
+// SortedMonths returns the archived months oldest first.
+func (idx *CheckinArchiveIndex) SortedMonths() []string {
+	months := make([]string, 0, len(idx.Months))
+	for month := range idx.Months {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+	return months
+}
+
+func (idx *CheckinArchiveIndex) save(dir string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, CheckinArchiveIndexFile), func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// DefaultCheckinArchiveDir is where check-ins are archived when no
+// directory is configured.
+func DefaultCheckinArchiveDir() string {
+	return utils.GetConfigDirFile("archive")
+}
+
+// CheckinArchiveCutoff returns the first day of the month that check-ins
+// must be older than to be archived when the last months whole months are
+// kept.
+func CheckinArchiveCutoff(now time.Time, months int) time.Time {
+	return time.Date(now.Year(), now.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CheckinMonth returns the "YYYY-MM" a stored LogDatetime falls in, or ""
+// when it cannot be read.
+func CheckinMonth(logDatetime string) string {
+	if len(logDatetime) < 7 || !archiveMonthPattern.MatchString(logDatetime[:7]) {
+		return ""
+	}
+	return logDatetime[:7]
+}
+
+// ParseMonthRange parses "YYYY-MM" or "YYYY-MM..YYYY-MM" into an inclusive
+// month range.
+func ParseMonthRange(text string) (from, to string, err error) {
+	from, to, found := strings.Cut(strings.TrimSpace(text), "..")
+	if !found {
+		to = from
+	}
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !archiveMonthPattern.MatchString(from) || !archiveMonthPattern.MatchString(to) {
+		return "", "", fmt.Errorf("range must be YYYY-MM or YYYY-MM..YYYY-MM, got %q", text)
+	}
+	if from > to {
+		return "", "", fmt.Errorf("range %q ends before it starts", text)
+	}
+	return from, to, nil
+}
+
+// ArchiveCheckins moves check-ins logged before the given time out of the
+// database into gzip-compressed JSON files in dir, one per month, and records
+// per-month aggregates in the index. Check-ins with unsent changes stay put.
+// Files are written before rows are deleted, so an interrupted run leaves
+// rows in both places rather than losing them.
+func ArchiveCheckins(db DB, dir string, before time.Time) (CheckinArchiveResult, error) {
+	var result CheckinArchiveResult
+	if db == nil || !db.IsConnected() {
+		return result, fmt.Errorf("database is not connected")
+	}
+	sqlText := db.GetSQL("GetCheckinsBefore")
+	if sqlText == "" {
+		return result, fmt.Errorf("unknown or unavailable SQL command: GetCheckinsBefore")
+	}
+	rows, err := db.GetDB().Query(sqlText, before.Format("2006-01-02"))
+	if err != nil {
+		return result, err
+	}
+	byMonth := make(map[string][]ArchivedCheckin)
+	for rows.Next() {
+		checkin, err := scanArchivedCheckin(rows)
+		if err != nil {
+			rows.Close()
+			return result, err
+		}
+		if checkin.LogDatetime == nil {
+			continue
+		}
+		if month := CheckinMonth(*checkin.LogDatetime); month != "" {
+			byMonth[month] = append(byMonth[month], checkin)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+	if len(byMonth) == 0 {
+		return result, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return result, err
+	}
+	index, err := LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		return result, err
+	}
+	months := make([]string, 0, len(byMonth))
+	for month := range byMonth {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+
+	for _, month := range months {
+		file := "checkins-" + month + ".json.gz"
+		merged, err := readCheckinArchiveFile(filepath.Join(dir, file))
+		if err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+		merged = mergeArchivedCheckins(merged, byMonth[month])
+		if err := writeCheckinArchiveFile(filepath.Join(dir, file), merged); err != nil {
+			return result, err
+		}
+		index.Months[month] = summariseCheckinMonth(file, merged)
+	}
+	if err := index.save(dir); err != nil {
+		return result, err
+	}
+
+	for _, month := range months {
+		for _, checkin := range byMonth[month] {
+			if err := RunCommand(db, "DeleteCheckin", checkin.CheckinId); err != nil {
+				return result, fmt.Errorf("archived %s but could not delete check-in %d: %w", month, checkin.CheckinId, err)
+			}
+			result.Checkins++
+		}
+		result.Months = append(result.Months, month)
+	}
+	return result, nil
+}
+
+// RestoreCheckins writes the archived check-ins for the inclusive month range
+// back into the database and removes those months from the archive.
+func RestoreCheckins(db DB, dir, from, to string) (CheckinArchiveResult, error) {
+	var result CheckinArchiveResult
+	if db == nil || !db.IsConnected() {
+		return result, fmt.Errorf("database is not connected")
+	}
+	index, err := LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		return result, err
+	}
+	for _, month := range index.SortedMonths() {
+		if month < from || month > to {
+			continue
+		}
+		path := filepath.Join(dir, index.Months[month].File)
+		checkins, err := readCheckinArchiveFile(path)
+		if err != nil {
+			return result, fmt.Errorf("could not read archive for %s: %w", month, err)
+		}
+		for _, c := range checkins {
+			if err := RunCommand(db, "MergeAccountCheckins",
+				c.CheckinId, c.CrmId, c.AccountId, c.LogDatetime, c.Type, c.Comments,
+				c.ExtraFields, c.EndpointType, c.CreatedBy,
+			); err != nil {
+				result.Skipped++
+				continue
+			}
+			result.Checkins++
+		}
+		delete(index.Months, month)
+		if err := index.save(dir); err != nil {
+			return result, err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+		result.Months = append(result.Months, month)
+	}
+	return result, nil
+}
+
+// CountArchivedCheckins returns how many archived check-ins of accountID the
+// index lists.
+func CountArchivedCheckins(dir string, accountID int) (int, error) {
+	index, err := LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range index.Months {
+		count += entry.ByAccount[int64(accountID)]
+	}
+	return count, nil
+}
+
+// EraseArchivedCheckins deletes (ErasureDelete) or anonymizes the archived
+// check-ins of accountID in every month the index lists the account in,
+// and returns how many it changed. A month left empty is removed.
+func EraseArchivedCheckins(dir string, accountID int, mode string) (int, error) {
+	index, err := LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		return 0, err
+	}
+	erased := 0
+	for _, month := range index.SortedMonths() {
+		entry := index.Months[month]
+		if entry.ByAccount[int64(accountID)] == 0 {
+			continue
+		}
+		path := filepath.Join(dir, entry.File)
+		checkins, err := readCheckinArchiveFile(path)
+		if err != nil {
+			return erased, fmt.Errorf("could not read archive for %s: %w", month, err)
+		}
+		kept := checkins[:0]
+		for _, c := range checkins {
+			if c.AccountId == nil || *c.AccountId != int64(accountID) {
+				kept = append(kept, c)
+				continue
+			}
+			erased++
+			if mode == ErasureAnonymize {
+				c.CrmId, c.Comments, c.ExtraFields, c.CreatedBy = nil, nil, nil, nil
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == 0 {
+			delete(index.Months, month)
+			if err := index.save(dir); err != nil {
+				return erased, err
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return erased, err
+			}
+			continue
+		}
+		if err := writeCheckinArchiveFile(path, kept); err != nil {
+			return erased, err
+		}
+		summary := summariseCheckinMonth(entry.File, kept)
+		summary.ArchivedAt = entry.ArchivedAt
+		index.Months[month] = summary
+		if err := index.save(dir); err != nil {
+			return erased, err
+		}
+	}
+	return erased, nil
+}
+
+func scanArchivedCheckin(rows *sql.Rows) (ArchivedCheckin, error) {
+	var (
+		c                                                  ArchivedCheckin
+		crmID, logDatetime, typ, comments, extra, endpoint sql.NullString
+		createdBy                                          sql.NullString
+		accountID                                          sql.NullInt64
+	)
+	if err := rows.Scan(&c.CheckinId, &crmID, &accountID, &logDatetime, &typ, &comments, &extra, &endpoint, &createdBy); err != nil {
+		return c, err
+	}
+	c.CrmId = nullStringPtr(crmID)
+	if accountID.Valid {
+		c.AccountId = &accountID.Int64
+	}
+	c.LogDatetime = nullStringPtr(logDatetime)
+	c.Type = nullStringPtr(typ)
+	c.Comments = nullStringPtr(comments)
+	c.ExtraFields = nullStringPtr(extra)
+	c.EndpointType = endpoint.String
+	if c.EndpointType == "" {
+		c.EndpointType = "standard"
+	}
+	c.CreatedBy = nullStringPtr(createdBy)
+	return c, nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// mergeArchivedCheckins adds newer rows to an existing month, replacing rows
+// with the same CheckinId.
+func mergeArchivedCheckins(existing, newer []ArchivedCheckin) []ArchivedCheckin {
+	byID := make(map[int64]int, len(existing))
+	for i, c := range existing {
+		byID[c.CheckinId] = i
+	}
+	for _, c := range newer {
+		if i, ok := byID[c.CheckinId]; ok {
+			existing[i] = c
+			continue
+		}
+		byID[c.CheckinId] = len(existing)
+		existing = append(existing, c)
+	}
+	return existing
+}
+
+func summariseCheckinMonth(file string, checkins []ArchivedCheckin) *CheckinArchiveMonth {
+	month := &CheckinArchiveMonth{
+		File:       file,
+		Count:      len(checkins),
+		ByAccount:  make(map[int64]int),
+		ByType:     make(map[string]int),
+		ArchivedAt: time.Now().UTC(),
+	}
+	for _, c := range checkins {
+		if c.AccountId != nil {
+			month.ByAccount[*c.AccountId]++
+		}
+		if c.Type != nil && *c.Type != "" {
+			month.ByType[*c.Type]++
+		}
+	}
+	return month
+}
+
+func readCheckinArchiveFile(path string) ([]ArchivedCheckin, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var checkins []ArchivedCheckin
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	for dec.More() {
+		var c ArchivedCheckin
+		if err := dec.Decode(&c); err != nil {
+			return nil, err
+		}
+		checkins = append(checkins, c)
+	}
+	return checkins, nil
+}
+
+func writeCheckinArchiveFile(path string, checkins []ArchivedCheckin) error {
+	return writeFileAtomic(path, func(f *os.File) error {
+		gz := gzip.NewWriter(f)
+		enc := json.NewEncoder(gz)
+		for _, c := range checkins {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return gz.Close()
+	})
+}
+
+// writeFileAtomic writes path through a temporary file in the same directory
+// so readers never see a partial file.
+func writeFileAtomic(path string, write func(*os.File) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
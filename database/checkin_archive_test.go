@@ -0,0 +1,109 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func countCheckins(t *testing.T, db DB) int {
+	t.Helper()
+	var n int
+	if err := db.GetDB().QueryRow("SELECT COUNT(*) FROM AccountCheckins").Scan(&n); err != nil {
+		t.Fatalf("count checkins: %v", err)
+	}
+	return n
+}
+
+func TestArchiveAndRestoreCheckins(t *testing.T) {
+	db := newBackupTestDB(t, "archive.db")
+	dir := filepath.Join(t.TempDir(), "archive")
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, FullName) VALUES (1, 'Acme'), (2, 'Globex')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, Type, Comments) VALUES
+			(10, 1, '2023-01-05T09:00:00', 'Visit', 'first'),
+			(11, 2, '2023-01-20T09:00:00', 'Call', NULL),
+			(12, 1, '2023-02-11T09:00:00', 'Visit', 'second'),
+			(13, 1, '2023-03-01T09:00:00', 'Visit', 'pending edit'),
+			(14, 2, '2024-06-01T09:00:00', 'Visit', 'recent')`,
+		`INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, ChangeType) VALUES (13, 1, 'UPDATE')`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	result, err := ArchiveCheckins(db, dir, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ArchiveCheckins: %v", err)
+	}
+	if result.Checkins != 3 || len(result.Months) != 2 {
+		t.Fatalf("archived %d check-ins in %v, want 3 in 2 months", result.Checkins, result.Months)
+	}
+	if n := countCheckins(t, db); n != 2 {
+		t.Fatalf("%d check-ins left, want the pending and recent ones", n)
+	}
+
+	index, err := LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jan := index.Months["2023-01"]
+	if jan == nil || jan.Count != 2 || jan.ByAccount[1] != 1 || jan.ByType["Call"] != 1 || index.Count() != 3 {
+		t.Fatalf("index = %+v", index.Months)
+	}
+
+	// Archiving the same month again merges into the existing file.
+	if _, err := db.GetDB().Exec(`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime) VALUES (15, 2, '2023-01-25T09:00:00')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ArchiveCheckins(db, dir, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("second ArchiveCheckins: %v", err)
+	}
+	if index, _ = LoadCheckinArchiveIndex(dir); index.Months["2023-01"].Count != 3 {
+		t.Fatalf("merged January count = %d, want 3", index.Months["2023-01"].Count)
+	}
+
+	from, to, err := ParseMonthRange("2023-01..2023-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreCheckins(db, dir, from, to)
+	if err != nil {
+		t.Fatalf("RestoreCheckins: %v", err)
+	}
+	if restored.Checkins != 3 || restored.Skipped != 0 {
+		t.Fatalf("restored = %+v", restored)
+	}
+	var comments string
+	if err := db.GetDB().QueryRow("SELECT Comments FROM AccountCheckins WHERE CheckinId = 10").Scan(&comments); err != nil || comments != "first" {
+		t.Fatalf("restored comments = %q, %v", comments, err)
+	}
+	if index, _ = LoadCheckinArchiveIndex(dir); index.Contains("2023-01") || !index.Contains("2023-02") {
+		t.Fatalf("months after restore = %v", index.SortedMonths())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "checkins-2023-01.json.gz")); !os.IsNotExist(err) {
+		t.Fatalf("restored month file still present: %v", err)
+	}
+}
+
+func TestParseMonthRange(t *testing.T) {
+	for _, tt := range []struct {
+		in       string
+		from, to string
+		wantErr  bool
+	}{
+		{"2023-04", "2023-04", "2023-04", false},
+		{"2023-01..2023-06", "2023-01", "2023-06", false},
+		{"2023-06..2023-01", "", "", true},
+		{"2023-13", "", "", true},
+		{"last year", "", "", true},
+	} {
+		from, to, err := ParseMonthRange(tt.in)
+		if (err != nil) != tt.wantErr || from != tt.from || to != tt.to {
+			t.Errorf("ParseMonthRange(%q) = %q, %q, %v", tt.in, from, to, err)
+		}
+	}
+}
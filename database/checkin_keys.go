@@ -0,0 +1,25 @@
+package database
+
+import "database/sql"
+
+// CheckinKey is the time and comments of a check-in logged or staged for
+// an account, which an import matches rows against to skip duplicates.
+type CheckinKey struct {
+	LogDatetime string
+	Comments    string
+}
+
+// GetAccountCheckinKeys returns the keys of the account's stored check-ins
+// and of the check-ins staged for it.
+func GetAccountCheckinKeys(db DB, accountID int) ([]CheckinKey, error) {
+	var keys []CheckinKey
+	err := queryTeam(db, "GetAccountCheckinKeys", []any{accountID, accountID}, func(rows *sql.Rows) error {
+		var logged, comments sql.NullString
+		if err := rows.Scan(&logged, &comments); err != nil {
+			return err
+		}
+		keys = append(keys, CheckinKey{LogDatetime: logged.String, Comments: comments.String})
+		return nil
+	})
+	return keys, err
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// RewriteAccountColumns passes every non-null value of the given Accounts
+// columns through fn and stores what it returns, in one transaction, so a
+// failure leaves no column half rewritten. It reports how many values
+// changed. Column names are put into the SQL as given, so callers check
+// them against the Accounts schema first. It is used by column encryption,
+// which is SQLite only.
+func RewriteAccountColumns(db DB, columns []string, fn func(string) (string, error)) (int, error) {
+	tx, err := db.GetDB().Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	changed := 0
+	for _, column := range columns {
+		values := make(map[int]string)
+		rows, err := tx.Query(fmt.Sprintf("SELECT AccountId, %s FROM Accounts WHERE %s IS NOT NULL", column, column))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read Accounts.%s: %w", column, err)
+		}
+		for rows.Next() {
+			var id int
+			var value sql.NullString
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			values[id] = value.String
+		}
+		if err := rows.Close(); err != nil {
+			return 0, err
+		}
+		update := fmt.Sprintf("UPDATE Accounts SET %s = ? WHERE AccountId = ?", column)
+		for id, value := range values {
+			rewritten, err := fn(value)
+			if err != nil {
+				return 0, fmt.Errorf("account %d, %s: %w", id, column, err)
+			}
+			if rewritten == value {
+				continue
+			}
+			if _, err := tx.Exec(update, rewritten, id); err != nil {
+				return 0, fmt.Errorf("failed to update Accounts.%s: %w", column, err)
+			}
+			changed++
+		}
+	}
+	return changed, tx.Commit()
+}
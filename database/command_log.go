@@ -0,0 +1,150 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandLogEntry is one recorded CLI command or app operation.
+type CommandLogEntry struct {
+	LogId   int
+	Command string
+	Args    string
+	// Flags holds the flags that were set, as --name=value.
+	Flags        []string
+	Timestamp    time.Time
+	Success      bool
+	ErrorMessage string
+	// Duration is zero for rows written before durations were recorded.
+	Duration time.Duration
+}
+
+// CommandLogFilter narrows GetCommandLog. Zero values match everything.
+type CommandLogFilter struct {
+	// Command matches the command and its subcommands, so "db" also
+	// matches "db stats".
+	Command string
+	Success *bool
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+// DefaultCommandLogLimit is used when the filter does not set a limit.
+const DefaultCommandLogLimit = 100
+
+// InsertCommandLog records a command run.
+func InsertCommandLog(db DB, entry CommandLogEntry) error {
+	sqlText := db.GetSQL("InsertCommandLog")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: InsertCommandLog")
+	}
+	var flags sql.NullString
+	if len(entry.Flags) > 0 {
+		data, err := json.Marshal(entry.Flags)
+		if err != nil {
+			return err
+		}
+		flags = sql.NullString{String: string(data), Valid: true}
+	}
+	var duration sql.NullInt64
+	if entry.Duration > 0 {
+		duration = sql.NullInt64{Int64: entry.Duration.Milliseconds(), Valid: true}
+	}
+	_, err := db.GetDB().Exec(sqlText, entry.Command, entry.Args, flags, entry.Success, entry.ErrorMessage, duration)
+	return err
+}
+
+// GetCommandLog returns the commands matching filter, newest first.
+func GetCommandLog(db DB, filter CommandLogFilter) ([]CommandLogEntry, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetCommandLog")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetCommandLog")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultCommandLogLimit
+	}
+	sqlText = strings.Replace(sqlText, "{{LIMIT}}", strconv.Itoa(limit), 1)
+
+	success := -1
+	if filter.Success != nil {
+		success = 0
+		if *filter.Success {
+			success = 1
+		}
+	}
+	until := filter.Until
+	if until.IsZero() {
+		until = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	const layout = "2006-01-02 15:04:05"
+	command := strings.TrimSpace(filter.Command)
+	rows, err := db.GetDB().Query(sqlText,
+		command, command, command+" %",
+		success, success,
+		filter.Since.UTC().Format(layout), until.UTC().Format(layout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanCommandLogRows(rows)
+}
+
+// GetCommandLogEntry returns the command with the given log ID, or nil when
+// there is none.
+func GetCommandLogEntry(db DB, id int) (*CommandLogEntry, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetCommandLogEntry")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetCommandLogEntry")
+	}
+	rows, err := db.GetDB().Query(sqlText, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries, err := scanCommandLogRows(rows)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+func scanCommandLogRows(rows *sql.Rows) ([]CommandLogEntry, error) {
+	var entries []CommandLogEntry
+	for rows.Next() {
+		var (
+			entry                     CommandLogEntry
+			args, flags, errorMessage sql.NullString
+			timestamp                 any
+			duration                  sql.NullInt64
+		)
+		if err := rows.Scan(&entry.LogId, &entry.Command, &args, &flags, &timestamp, &entry.Success, &errorMessage, &duration); err != nil {
+			return nil, err
+		}
+		entry.Args = args.String
+		entry.ErrorMessage = errorMessage.String
+		entry.Timestamp = normaliseToTime(timestamp)
+		if flags.String != "" {
+			if err := json.Unmarshal([]byte(flags.String), &entry.Flags); err != nil {
+				entry.Flags = []string{flags.String}
+			}
+		}
+		if duration.Valid {
+			entry.Duration = time.Duration(duration.Int64) * time.Millisecond
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
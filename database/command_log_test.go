@@ -0,0 +1,88 @@
+package database
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"badgermaps/app/state"
+)
+
+func TestCommandLogFilters(t *testing.T) {
+	db, err := NewDB(&DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "history.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := []CommandLogEntry{
+		{Command: "pull accounts", Args: "1 2", Flags: []string{"--force=true"}, Success: true, Duration: 1500 * time.Millisecond},
+		{Command: "db stats", Success: true, Duration: 20 * time.Millisecond},
+		{Command: "db migrate", Success: false, ErrorMessage: "boom"},
+		{Command: "pulls", Success: true},
+	}
+	for _, run := range runs {
+		if err := InsertCommandLog(db, run); err != nil {
+			t.Fatalf("InsertCommandLog: %v", err)
+		}
+	}
+	if err := LogCommand(db, "api_key_rotate", []string{"old", "new"}, true, ""); err != nil {
+		t.Fatalf("LogCommand: %v", err)
+	}
+
+	all, err := GetCommandLog(db, CommandLogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 || all[0].Command != "api_key_rotate" {
+		t.Fatalf("GetCommandLog = %+v, want 5 entries newest first", all)
+	}
+	pull := all[4]
+	if pull.Args != "1 2" || !reflect.DeepEqual(pull.Flags, []string{"--force=true"}) || pull.Duration != 1500*time.Millisecond || pull.Timestamp.IsZero() {
+		t.Errorf("pull entry = %+v", pull)
+	}
+
+	commands := func(filter CommandLogFilter) []string {
+		t.Helper()
+		entries, err := GetCommandLog(db, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Command)
+		}
+		return names
+	}
+	if got := commands(CommandLogFilter{Command: "db"}); !reflect.DeepEqual(got, []string{"db migrate", "db stats"}) {
+		t.Errorf("--command db = %v", got)
+	}
+	if got := commands(CommandLogFilter{Command: "pull"}); !reflect.DeepEqual(got, []string{"pull accounts"}) {
+		t.Errorf("--command pull = %v", got)
+	}
+	failed := false
+	if got := commands(CommandLogFilter{Success: &failed}); !reflect.DeepEqual(got, []string{"db migrate"}) {
+		t.Errorf("failed = %v", got)
+	}
+	if got := commands(CommandLogFilter{Since: time.Now().Add(time.Hour)}); got != nil {
+		t.Errorf("since an hour from now = %v", got)
+	}
+	if got := commands(CommandLogFilter{Limit: 2}); len(got) != 2 {
+		t.Errorf("limit 2 = %v", got)
+	}
+
+	entry, err := GetCommandLogEntry(db, pull.LogId)
+	if err != nil || entry == nil || entry.Command != "pull accounts" {
+		t.Fatalf("GetCommandLogEntry = %+v, %v", entry, err)
+	}
+	if missing, err := GetCommandLogEntry(db, 9999); err != nil || missing != nil {
+		t.Fatalf("missing entry = %+v, %v", missing, err)
+	}
+}
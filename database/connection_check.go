@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HostCheckTimeout bounds CheckDBHost, so an unreachable server is reported
+// well before a driver's own login timeout.
+const HostCheckTimeout = 5 * time.Second
+
+// DefaultPort returns the port a server database listens on unless told
+// otherwise, or 0 for SQLite.
+func DefaultPort(dbType string) int {
+	switch dbType {
+	case "postgres":
+		return 5432
+	case "mssql":
+		return 1433
+	}
+	return 0
+}
+
+// ParseDBPort reads a port as typed in a settings form. Empty is the
+// default port of dbType.
+func ParseDBPort(dbType, text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return DefaultPort(dbType), nil
+	}
+	port, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("port must be a number, such as %d", DefaultPort(dbType))
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port must be between 1 and 65535")
+	}
+	return port, nil
+}
+
+// ValidateDBHost checks that host is a host name or IP address rather than
+// a URL or a host with a port. SQL Server hosts may name an instance, as in
+// db01\SQLEXPRESS.
+func ValidateDBHost(dbType, host string) error {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if strings.Contains(host, "://") {
+		return fmt.Errorf("host takes a name or address without a scheme, such as db.example.com")
+	}
+	name, _, instance := strings.Cut(host, `\`)
+	if instance && dbType != "mssql" {
+		return fmt.Errorf("only SQL Server hosts can name an instance")
+	}
+	if strings.ContainsAny(name, " /@?#") {
+		return fmt.Errorf("host %q is not a host name or address", host)
+	}
+	if strings.Count(name, ":") == 1 {
+		return fmt.Errorf("enter the port in the Port field, not after the host")
+	}
+	return nil
+}
+
+// CheckDBHost resolves host and opens a TCP connection to port, returning
+// how long connecting took. A SQL Server host naming an instance is only
+// resolved, since its port comes from the SQL Server Browser.
+func CheckDBHost(ctx context.Context, dbType, host string, port int) (time.Duration, error) {
+	if err := ValidateDBHost(dbType, host); err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, HostCheckTimeout)
+	defer cancel()
+	name, _, instance := strings.Cut(strings.TrimSpace(host), `\`)
+	name = strings.Trim(name, "[]")
+	start := time.Now()
+	if instance {
+		if _, err := net.DefaultResolver.LookupHost(ctx, name); err != nil {
+			return 0, fmt.Errorf("cannot resolve %s: %w", name, err)
+		}
+		return time.Since(start), nil
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(name, strconv.Itoa(port)))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return 0, fmt.Errorf("cannot resolve %s: %w", name, err)
+		}
+		return 0, fmt.Errorf("nothing is accepting connections on %s port %d: %w", name, port, err)
+	}
+	conn.Close()
+	return time.Since(start), nil
+}
+
+// CheckSQLitePath checks that path can hold a SQLite database. It reports
+// whether the file already exists; when it does not, connecting creates it
+// and any missing directories.
+func CheckSQLitePath(path string) (bool, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return false, fmt.Errorf("path is required")
+	}
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return false, fmt.Errorf("%s is a directory; enter a file name such as %s", path, filepath.Join(path, "badgermaps.db"))
+		}
+		return true, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	// The nearest existing parent must be a directory the file can be
+	// created under.
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return false, fmt.Errorf("%s is a file, so %s cannot be created", dir, path)
+			}
+			return false, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false, nil
+		}
+	}
+}
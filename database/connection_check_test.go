@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDBPort(t *testing.T) {
+	for _, tt := range []struct {
+		dbType, text string
+		want         int
+		wantErr      bool
+	}{
+		{"postgres", "", 5432, false},
+		{"mssql", " 14330 ", 14330, false},
+		{"postgres", "54a2", 0, true},
+		{"postgres", "0", 0, true},
+		{"mssql", "70000", 0, true},
+	} {
+		got, err := ParseDBPort(tt.dbType, tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDBPort(%q, %q) = %d, %v; want %d, error %v", tt.dbType, tt.text, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateDBHost(t *testing.T) {
+	for _, tt := range []struct {
+		dbType, host string
+		wantErr      bool
+	}{
+		{"postgres", "db.example.com", false},
+		{"postgres", "10.0.0.5", false},
+		{"postgres", "::1", false},
+		{"mssql", `db01\SQLEXPRESS`, false},
+		{"postgres", "", true},
+		{"postgres", "postgres://db.example.com", true},
+		{"postgres", "db.example.com:5432", true},
+		{"postgres", `db01\SQLEXPRESS`, true},
+		{"mssql", "db 01", true},
+	} {
+		if err := ValidateDBHost(tt.dbType, tt.host); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateDBHost(%q, %q) = %v, wantErr %v", tt.dbType, tt.host, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckDBHost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if _, err := CheckDBHost(context.Background(), "postgres", "127.0.0.1", port); err != nil {
+		t.Errorf("CheckDBHost on a listening port: %v", err)
+	}
+	listener.Close()
+	if _, err := CheckDBHost(context.Background(), "postgres", "127.0.0.1", port); err == nil {
+		t.Error("CheckDBHost on a closed port succeeded")
+	}
+}
+
+func TestCheckSQLitePath(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.db")
+	if err := os.WriteFile(existing, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path            string
+		exists, wantErr bool
+	}{
+		{existing, true, false},
+		{filepath.Join(dir, "new.db"), false, false},
+		{filepath.Join(dir, "data", "nested", "new.db"), false, false},
+		{dir, false, true},
+		{filepath.Join(existing, "new.db"), false, true},
+		{"  ", false, true},
+	} {
+		exists, err := CheckSQLitePath(tt.path)
+		if (err != nil) != tt.wantErr || exists != tt.exists {
+			t.Errorf("CheckSQLitePath(%q) = %v, %v; want %v, error %v", tt.path, exists, err, tt.exists, tt.wantErr)
+		}
+	}
+}
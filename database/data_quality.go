@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DataQuality summarizes gaps in the locally synced account data.
+type DataQuality struct {
+	TotalAccounts        int
+	MissingEmail         int
+	MissingPhone         int
+	StaleDays            int
+	StaleAccounts        int
+	TotalLocations       int
+	ApproximateLocations int
+	// UnmappedCustomFields lists custom account columns that hold data but
+	// are not mapped to any data field in the user profile.
+	UnmappedCustomFields []string
+}
+
+// Percent returns count as a percentage of total, or 0 when total is 0.
+func Percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(total)
+}
+
+// GetDataQuality computes data quality KPIs. Accounts whose last check-in
+// is more than staleDays ago count as stale.
+func GetDataQuality(db DB, staleDays int) (*DataQuality, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetDataQualityCounts")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetDataQualityCounts")
+	}
+
+	q := &DataQuality{StaleDays: staleDays}
+	err := db.GetDB().QueryRow(sqlText, staleDays).Scan(
+		&q.TotalAccounts, &q.MissingEmail, &q.MissingPhone, &q.StaleAccounts,
+		&q.TotalLocations, &q.ApproximateLocations,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute data quality counts: %w", err)
+	}
+
+	if q.UnmappedCustomFields, err = getUnmappedCustomFields(db); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// GetMappedAccountFields returns the account fields a data field of the
+// user profile is stored in (DataSets.AccountField), as the API names them.
+func GetMappedAccountFields(db DB) ([]string, error) {
+	sqlText := db.GetSQL("GetMappedAccountFields")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetMappedAccountFields")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapped account fields: %w", err)
+	}
+	defer rows.Close()
+	var fields []string
+	for rows.Next() {
+		var field sql.NullString
+		if err := rows.Scan(&field); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field.String)
+	}
+	return fields, rows.Err()
+}
+
+// getUnmappedCustomFields returns the custom columns with data that no
+// DataSets.AccountField refers to. Field names are compared ignoring case
+// and underscores so "custom_text2" matches CustomText2.
+func getUnmappedCustomFields(db DB) ([]string, error) {
+	fields, err := GetMappedAccountFields(db)
+	if err != nil {
+		return nil, err
+	}
+	mapped := make(map[string]bool)
+	for _, field := range fields {
+		mapped[normalizeFieldName(field)] = true
+	}
+
+	sqlText := db.GetSQL("GetCustomFieldUsage")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetCustomFieldUsage")
+	}
+	usage, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom field usage: %w", err)
+	}
+	defer usage.Close()
+	columns, err := usage.Columns()
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(columns))
+	targets := make([]interface{}, len(columns))
+	for i := range counts {
+		targets[i] = &counts[i]
+	}
+	if usage.Next() {
+		if err := usage.Scan(targets...); err != nil {
+			return nil, err
+		}
+	}
+
+	canonical := make(map[string]string)
+	for i := 1; i <= 30; i++ {
+		suffix := ""
+		if i > 1 {
+			suffix = fmt.Sprint(i)
+		}
+		for _, name := range []string{"CustomNumeric" + suffix, "CustomText" + suffix} {
+			canonical[normalizeFieldName(name)] = name
+		}
+	}
+
+	var unmapped []string
+	for i, column := range columns {
+		key := normalizeFieldName(column)
+		if counts[i] == 0 || mapped[key] {
+			continue
+		}
+		if name, ok := canonical[key]; ok {
+			column = name
+		}
+		unmapped = append(unmapped, column)
+	}
+	return unmapped, usage.Err()
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
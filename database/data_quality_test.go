@@ -0,0 +1,43 @@
+package database
+
+import "testing"
+
+func TestGetDataQuality(t *testing.T) {
+	db := newBackupTestDB(t, "quality.db")
+	sqlDB := db.GetDB()
+	stmts := []string{
+		`INSERT INTO Accounts (AccountId, FullName, Email, PhoneNumber, DaysSinceLastCheckin, CustomText, CustomText2)
+			VALUES (1, 'Acme', 'a@example.com', '555', 10, 'mapped', 'orphan')`,
+		`INSERT INTO Accounts (AccountId, FullName, Email, PhoneNumber, DaysSinceLastCheckin) VALUES (2, 'Beta', '', NULL, 200)`,
+		`INSERT INTO Accounts (AccountId, FullName, Email, PhoneNumber) VALUES (3, 'Gamma', NULL, '  ')`,
+		`INSERT INTO AccountLocations (AccountId, City, IsApproximate) VALUES (1, 'Denver', 1)`,
+		`INSERT INTO AccountLocations (AccountId, City, IsApproximate) VALUES (2, 'Boulder', 0)`,
+		`INSERT INTO UserProfiles (ProfileId, Email) VALUES (1, 'me@example.com')`,
+		`INSERT INTO DataSets (Name, ProfileId, AccountField) VALUES ('segment', 1, 'custom_text')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+
+	q, err := GetDataQuality(db, 90)
+	if err != nil {
+		t.Fatalf("GetDataQuality: %v", err)
+	}
+	if q.TotalAccounts != 3 || q.MissingEmail != 2 || q.MissingPhone != 2 || q.StaleAccounts != 1 {
+		t.Errorf("unexpected account counts: %+v", q)
+	}
+	if q.TotalLocations != 2 || q.ApproximateLocations != 1 {
+		t.Errorf("unexpected location counts: %+v", q)
+	}
+	if len(q.UnmappedCustomFields) != 1 || q.UnmappedCustomFields[0] != "CustomText2" {
+		t.Errorf("UnmappedCustomFields = %v, want [CustomText2]", q.UnmappedCustomFields)
+	}
+	if got := Percent(q.MissingEmail, q.TotalAccounts); got < 66 || got > 67 {
+		t.Errorf("Percent = %v", got)
+	}
+	if Percent(1, 0) != 0 {
+		t.Error("Percent with zero total should be 0")
+	}
+}
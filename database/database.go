@@ -4,6 +4,7 @@ import (
 	"badgermaps/app/state"
 	"badgermaps/utils"
 	"bufio"
+	"crypto/tls"
 	"database/sql"
 	"embed"
 	"fmt"
@@ -12,12 +13,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
-	_ "github.com/lib/pq"               // PostgreSQL driver
-	_ "github.com/mattn/go-sqlite3"     // SQLite driver
-	_ "github.com/microsoft/go-mssqldb" // SQL Server driver
+	_ "github.com/lib/pq"                   // PostgreSQL driver
+	_ "github.com/mattn/go-sqlite3"         // SQLite driver
+	mssql "github.com/microsoft/go-mssqldb" // SQL Server driver
+	"github.com/microsoft/go-mssqldb/msdsn"
 )
 
 type DBConfig struct {
@@ -29,6 +32,23 @@ type DBConfig struct {
 	Password string `yaml:"password"`
 	SSLMode  string `yaml:"ssl_mode"`
 	Path     string `yaml:"path"`
+	// Optional TLS material for PostgreSQL and MSSQL connections.
+	SSLRootCert string `yaml:"ssl_root_cert,omitempty"`
+	SSLCert     string `yaml:"ssl_cert,omitempty"`
+	SSLKey      string `yaml:"ssl_key,omitempty"`
+	// Encrypted marks a SQLite database as SQLCipher-encrypted. The
+	// passphrase is never stored in the config.
+	Encrypted bool `yaml:"encrypted,omitempty"`
+	// EncryptedColumns are Accounts columns the app encrypts with AES-GCM
+	// under a key it manages, for SQLite databases on shared machines.
+	EncryptedColumns []string `yaml:"encrypted_columns,omitempty"`
+	// Schema keeps the app's objects in a schema of a shared PostgreSQL or
+	// MSSQL database instead of the default one.
+	Schema string `yaml:"schema,omitempty"`
+	// Auth selects SQL Server authentication: sql (the default), windows
+	// or azure-ad. AccessTokenCommand prints the Azure AD access token.
+	Auth               string `yaml:"auth,omitempty"`
+	AccessTokenCommand string `yaml:"access_token_command,omitempty"`
 }
 
 //go:embed mssql/*.sql
@@ -61,7 +81,9 @@ type DB interface {
 	Close() error
 	GetDB() *sql.DB
 	GetSQL(command string) string
-	RunAction(action ActionConfig) error
+	// RunAction runs a db action and returns the rows its statement
+	// affected, or -1 when the driver cannot tell.
+	RunAction(action ActionConfig) (int64, error)
 	GetTables() ([]string, error)
 	ExecuteQuery(query string) (*sql.Rows, error)
 	IsConnected() bool
@@ -72,15 +94,18 @@ type DB interface {
 type SQLiteConfig struct {
 	db        *sql.DB
 	Path      string `mapstructure:"DB_PATH"`
-	connected bool
+	Encrypted bool
+	// Key is the SQLCipher passphrase for an encrypted database.
+	Key       string
+	connected atomic.Bool
 }
 
 func (db *SQLiteConfig) IsConnected() bool {
-	return db.connected
+	return db.connected.Load()
 }
 
 func (db *SQLiteConfig) SetConnected(connected bool) {
-	db.connected = connected
+	db.connected.Store(connected)
 }
 
 func (db *SQLiteConfig) GetSQL(command string) string {
@@ -96,21 +121,28 @@ func (db *SQLiteConfig) Connect() error {
 	// Ensure the parent directory exists before attempting to create the database file
 	dir := filepath.Dir(db.Path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return fmt.Errorf("failed to create database directory: %w", err)
 	}
+	if db.Encrypted {
+		return db.connectEncrypted()
+	}
+	if encrypted, err := IsSQLiteFileEncrypted(db.Path); err == nil && encrypted {
+		db.connected.Store(false)
+		return ErrSQLiteEncrypted
+	}
 
 	var err error
-	db.db, err = sql.Open("sqlite3", db.DatabaseConnection())
+	db.db, err = sql.Open(sqliteDriverName, db.DatabaseConnection())
 	if err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return fmt.Errorf("failed to open SQLite database: %w", err)
 	}
 	return nil
 }
 
 func (db *SQLiteConfig) Close() error {
-	db.connected = false
+	db.connected.Store(false)
 	if db.db != nil {
 		return db.db.Close()
 	}
@@ -232,10 +264,10 @@ func (db *SQLiteConfig) EnforceSchema(s *state.State) error {
 func (db *SQLiteConfig) TestConnection() error {
 	err := db.GetDB().Ping()
 	if err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return err
 	}
-	db.connected = true
+	db.connected.Store(true)
 	return nil
 }
 
@@ -344,11 +376,13 @@ func (db *SQLiteConfig) GetType() string {
 
 func (db *SQLiteConfig) LoadConfig(config *DBConfig) error {
 	db.Path = config.Path
+	db.Encrypted = config.Encrypted
 	return nil
 }
 
 func (db *SQLiteConfig) SaveConfig(config *DBConfig) error {
 	config.Path = db.Path
+	config.Encrypted = db.Encrypted
 	return nil
 }
 
@@ -399,7 +433,7 @@ func (db *SQLiteConfig) ResetSchema(s *state.State) error {
 	return db.EnforceSchema(s)
 }
 
-func (db *SQLiteConfig) RunAction(action ActionConfig) error {
+func (db *SQLiteConfig) RunAction(action ActionConfig) (int64, error) {
 	var query string
 	var args []interface{}
 
@@ -408,19 +442,18 @@ func (db *SQLiteConfig) RunAction(action ActionConfig) error {
 	} else if q, ok := action.Args["query"].(string); ok {
 		query = q
 	} else {
-		return fmt.Errorf("sqlite action requires 'command' or 'query'")
+		return 0, fmt.Errorf("sqlite action requires 'command' or 'query'")
 	}
 
 	if query == "" {
-		return fmt.Errorf("SQL command not found or query is empty")
+		return 0, fmt.Errorf("SQL command not found or query is empty")
 	}
 
 	if params, ok := action.Args["args"].([]interface{}); ok {
 		args = params
 	}
 
-	_, err := db.db.Exec(query, args...)
-	return err
+	return rowsAffected(db.db.Exec(query, args...))
 }
 
 func (db *SQLiteConfig) GetTables() ([]string, error) {
@@ -445,24 +478,42 @@ func (db *SQLiteConfig) ExecuteQuery(query string) (*sql.Rows, error) {
 	return db.db.Query(query)
 }
 
+// rowsAffected returns the rows res reports, or -1 when the driver cannot
+// tell, as for a procedure call.
+func rowsAffected(res sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return -1, nil
+	}
+	return rows, nil
+}
+
 // PostgreSQLConfig represents a PostgreSQL database configuration
 type PostgreSQLConfig struct {
-	db        *sql.DB
-	Host      string `mapstructure:"DB_HOST"`
-	Port      int    `mapstructure:"DB_PORT"`
-	Database  string `mapstructure:"DB_NAME"`
-	Username  string `mapstructure:"DB_USER"`
-	Password  string `mapstructure:"DB_PASSWORD"`
-	SSLMode   string `mapstructure:"DB_SSL_MODE"`
-	connected bool
+	db       *sql.DB
+	Host     string `mapstructure:"DB_HOST"`
+	Port     int    `mapstructure:"DB_PORT"`
+	Database string `mapstructure:"DB_NAME"`
+	Username string `mapstructure:"DB_USER"`
+	Password string `mapstructure:"DB_PASSWORD"`
+	SSLMode  string `mapstructure:"DB_SSL_MODE"`
+	// SSLRootCert, SSLCert and SSLKey map to libpq's sslrootcert/sslcert/sslkey.
+	SSLRootCert string `mapstructure:"DB_SSL_ROOT_CERT"`
+	SSLCert     string `mapstructure:"DB_SSL_CERT"`
+	SSLKey      string `mapstructure:"DB_SSL_KEY"`
+	Schema      string `mapstructure:"DB_SCHEMA"`
+	connected   atomic.Bool
 }
 
 func (db *PostgreSQLConfig) IsConnected() bool {
-	return db.connected
+	return db.connected.Load()
 }
 
 func (db *PostgreSQLConfig) SetConnected(connected bool) {
-	db.connected = connected
+	db.connected.Store(connected)
 }
 
 func (db *PostgreSQLConfig) GetSQL(command string) string {
@@ -478,14 +529,14 @@ func (db *PostgreSQLConfig) Connect() error {
 	var err error
 	db.db, err = sql.Open("postgres", db.DatabaseConnection())
 	if err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return fmt.Errorf("failed to open PostgreSQL database: %w", err)
 	}
 	return nil
 }
 
 func (db *PostgreSQLConfig) Close() error {
-	db.connected = false
+	db.connected.Store(false)
 	if db.db != nil {
 		return db.db.Close()
 	}
@@ -519,6 +570,9 @@ func (db *PostgreSQLConfig) GetTableColumns(tableName string) ([]string, error)
 
 func (db *PostgreSQLConfig) EnforceSchema(s *state.State) error {
 	sqlDB := db.GetDB()
+	if err := db.ensureSchema(); err != nil {
+		return err
+	}
 
 	for _, tableName := range RequiredTables() {
 		if (s.Verbose || s.Debug) && !s.Quiet {
@@ -664,10 +718,10 @@ func (db *PostgreSQLConfig) EnforceSchema(s *state.State) error {
 func (db *PostgreSQLConfig) TestConnection() error {
 	err := db.GetDB().Ping()
 	if err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return err
 	}
-	db.connected = true
+	db.connected.Store(true)
 	return nil
 }
 func (db *PostgreSQLConfig) ValidateSchema(s *state.State) error {
@@ -832,6 +886,10 @@ func (db *PostgreSQLConfig) LoadConfig(config *DBConfig) error {
 	db.Username = config.Username
 	db.Password = config.Password
 	db.SSLMode = config.SSLMode
+	db.SSLRootCert = config.SSLRootCert
+	db.SSLCert = config.SSLCert
+	db.SSLKey = config.SSLKey
+	db.Schema = config.Schema
 	return nil
 }
 
@@ -842,6 +900,10 @@ func (db *PostgreSQLConfig) SaveConfig(config *DBConfig) error {
 	config.Username = db.Username
 	config.Password = db.Password
 	config.SSLMode = db.SSLMode
+	config.SSLRootCert = db.SSLRootCert
+	config.SSLCert = db.SSLCert
+	config.SSLKey = db.SSLKey
+	config.Schema = db.Schema
 	return nil
 }
 
@@ -857,9 +919,28 @@ func (db *PostgreSQLConfig) DatabaseConnection() string {
 	}
 	q := u.Query()
 	q.Set("sslmode", db.SSLMode)
+	q.Set("application_name", ApplicationName)
+	if db.Schema != "" {
+		q.Set("search_path", db.searchPath())
+	}
+	db.setTLSParams(q)
 	u.RawQuery = q.Encode()
 	return u.String()
 }
+
+// setTLSParams adds the optional certificate paths understood by lib/pq.
+func (db *PostgreSQLConfig) setTLSParams(q url.Values) {
+	if db.SSLRootCert != "" {
+		q.Set("sslrootcert", db.SSLRootCert)
+	}
+	if db.SSLCert != "" {
+		q.Set("sslcert", db.SSLCert)
+	}
+	if db.SSLKey != "" {
+		q.Set("sslkey", db.SSLKey)
+	}
+}
+
 func (db *PostgreSQLConfig) PromptDatabaseSettings() {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Println(utils.Colors.Cyan("PostgreSQL Database Configuration"))
@@ -869,6 +950,11 @@ func (db *PostgreSQLConfig) PromptDatabaseSettings() {
 	db.Username = utils.PromptString(reader, "Database Username", db.Username)
 	db.Password = utils.PromptPassword(reader, "Database Password", db.Password)
 	db.SSLMode = utils.PromptString(reader, "Database SSL Mode", db.SSLMode)
+	if db.SSLMode != "disable" {
+		db.SSLRootCert = utils.PromptString(reader, "CA Certificate Path (optional)", db.SSLRootCert)
+		db.SSLCert = utils.PromptString(reader, "Client Certificate Path (optional)", db.SSLCert)
+		db.SSLKey = utils.PromptString(reader, "Client Key Path (optional)", db.SSLKey)
+	}
 }
 
 func (db *PostgreSQLConfig) DropAllTables() error {
@@ -904,7 +990,7 @@ func (db *PostgreSQLConfig) ResetSchema(s *state.State) error {
 	return db.EnforceSchema(s)
 }
 
-func (db *PostgreSQLConfig) RunAction(action ActionConfig) error {
+func (db *PostgreSQLConfig) RunAction(action ActionConfig) (int64, error) {
 	var query string
 	var args []interface{}
 
@@ -919,30 +1005,29 @@ func (db *PostgreSQLConfig) RunAction(action ActionConfig) error {
 	} else if q, ok := action.Args["query"].(string); ok {
 		query = q
 	} else {
-		return fmt.Errorf("postgres action requires 'command', 'function', 'procedure', or 'query'")
+		return 0, fmt.Errorf("postgres action requires 'command', 'function', 'procedure', or 'query'")
 	}
 
 	if query == "" {
-		return fmt.Errorf("SQL command not found or query is empty")
+		return 0, fmt.Errorf("SQL command not found or query is empty")
 	}
 
 	if params, ok := action.Args["args"].([]interface{}); ok {
 		args = params
 	}
 
-	_, err := db.db.Exec(query, args...)
-	return err
+	return rowsAffected(db.db.Exec(query, args...))
 }
 
 func (db *PostgreSQLConfig) GetTables() ([]string, error) {
 	query := `
 		SELECT tablename AS name
 		FROM pg_catalog.pg_tables
-		WHERE schemaname NOT IN ('pg_catalog', 'information_schema')
+		WHERE schemaname = current_schema()
 		UNION
 		SELECT viewname AS name
 		FROM pg_catalog.pg_views
-		WHERE schemaname NOT IN ('pg_catalog', 'information_schema')
+		WHERE schemaname = current_schema()
 		ORDER BY name`
 	rows, err := db.db.Query(query)
 	if err != nil {
@@ -967,21 +1052,29 @@ func (db *PostgreSQLConfig) ExecuteQuery(query string) (*sql.Rows, error) {
 
 // MSSQLConfig represents a Microsoft SQL Server database configuration
 type MSSQLConfig struct {
-	db        *sql.DB
-	Host      string `mapstructure:"DB_HOST"`
-	Port      int    `mapstructure:"DB_PORT"`
-	Database  string `mapstructure:"DB_NAME"`
-	Username  string `mapstructure:"DB_USER"`
-	Password  string `mapstructure:"DB_PASSWORD"`
-	connected bool
+	db       *sql.DB
+	Host     string `mapstructure:"DB_HOST"`
+	Port     int    `mapstructure:"DB_PORT"`
+	Database string `mapstructure:"DB_NAME"`
+	Username string `mapstructure:"DB_USER"`
+	Password string `mapstructure:"DB_PASSWORD"`
+	// SSLRootCert pins a private CA; SSLCert/SSLKey enable client certificate auth.
+	SSLRootCert string `mapstructure:"DB_SSL_ROOT_CERT"`
+	SSLCert     string `mapstructure:"DB_SSL_CERT"`
+	SSLKey      string `mapstructure:"DB_SSL_KEY"`
+	Schema      string `mapstructure:"DB_SCHEMA"`
+	// Auth is one of MSSQLAuthMethods; SQL logins when empty.
+	Auth               string `mapstructure:"DB_AUTH"`
+	AccessTokenCommand string `mapstructure:"DB_ACCESS_TOKEN_COMMAND"`
+	connected          atomic.Bool
 }
 
 func (db *MSSQLConfig) IsConnected() bool {
-	return db.connected
+	return db.connected.Load()
 }
 
 func (db *MSSQLConfig) SetConnected(connected bool) {
-	db.connected = connected
+	db.connected.Store(connected)
 }
 
 func (db *MSSQLConfig) GetSQL(command string) string {
@@ -990,21 +1083,28 @@ func (db *MSSQLConfig) GetSQL(command string) string {
 	if err != nil {
 		return ""
 	}
-	return string(data)
+	return db.inSchema(string(data))
 }
 
 func (db *MSSQLConfig) Connect() error {
 	var err error
-	db.db, err = sql.Open("mssql", db.DatabaseConnection())
+	switch {
+	case db.authMethod() == MSSQLAuthAzureAD:
+		db.db, err = db.openWithAccessToken(db.DatabaseConnection())
+	case db.SSLCert != "" || db.SSLKey != "":
+		db.db, err = db.openWithClientCert(db.DatabaseConnection())
+	default:
+		db.db, err = sql.Open("mssql", db.DatabaseConnection())
+	}
 	if err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return fmt.Errorf("failed to open MSSQL database: %w", err)
 	}
 	return nil
 }
 
 func (db *MSSQLConfig) Close() error {
-	db.connected = false
+	db.connected.Store(false)
 	if db.db != nil {
 		return db.db.Close()
 	}
@@ -1019,7 +1119,7 @@ func (db *MSSQLConfig) GetTableColumns(tableName string) ([]string, error) {
 	sqlDB := db.GetDB()
 	query := db.GetSQL("GetTableColumns")
 
-	rows, err := sqlDB.Query(query, tableName)
+	rows, err := sqlDB.Query(query, QualifiedName(db, tableName))
 	if err != nil {
 		return nil, err
 	}
@@ -1038,6 +1138,9 @@ func (db *MSSQLConfig) GetTableColumns(tableName string) ([]string, error) {
 
 func (db *MSSQLConfig) EnforceSchema(s *state.State) error {
 	sqlDB := db.GetDB()
+	if err := db.ensureSchema(); err != nil {
+		return err
+	}
 
 	for _, tableName := range RequiredTables() {
 		if (s.Verbose || s.Debug) && !s.Quiet {
@@ -1183,10 +1286,10 @@ func (db *MSSQLConfig) EnforceSchema(s *state.State) error {
 func (db *MSSQLConfig) TestConnection() error {
 	err := db.GetDB().Ping()
 	if err != nil {
-		db.connected = false
+		db.connected.Store(false)
 		return err
 	}
-	db.connected = true
+	db.connected.Store(true)
 	return nil
 }
 func (db *MSSQLConfig) ValidateSchema(s *state.State) error {
@@ -1301,7 +1404,7 @@ func (db *MSSQLConfig) TableExists(tableName string) (bool, error) {
 	sqlDB := db.GetDB()
 	query := db.GetSQL("CheckTableExists")
 	var count int
-	err := sqlDB.QueryRow(query, tableName).Scan(&count)
+	err := sqlDB.QueryRow(query, QualifiedName(db, tableName)).Scan(&count)
 
 	if err != nil {
 		return false, err
@@ -1313,7 +1416,7 @@ func (db *MSSQLConfig) ViewExists(viewName string) (bool, error) {
 	sqlDB := db.GetDB()
 	query := db.GetSQL("CheckViewExists")
 	var count int
-	err := sqlDB.QueryRow(query, viewName).Scan(&count)
+	err := sqlDB.QueryRow(query, QualifiedName(db, viewName)).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -1324,7 +1427,7 @@ func (db *MSSQLConfig) ProcedureExists(procedureName string) (bool, error) {
 	sqlDB := db.GetDB()
 	query := db.GetSQL("CheckProcedureExists")
 	var count int
-	err := sqlDB.QueryRow(query, procedureName).Scan(&count)
+	err := sqlDB.QueryRow(query, QualifiedName(db, procedureName)).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -1335,7 +1438,7 @@ func (db *MSSQLConfig) TriggerExists(triggerName string) (bool, error) {
 	sqlDB := db.GetDB()
 	query := db.GetSQL("CheckTriggerExists")
 	var count int
-	err := sqlDB.QueryRow(query, triggerName).Scan(&count)
+	err := sqlDB.QueryRow(query, QualifiedName(db, triggerName)).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -1352,6 +1455,12 @@ func (db *MSSQLConfig) LoadConfig(config *DBConfig) error {
 	db.Database = config.Database
 	db.Username = config.Username
 	db.Password = config.Password
+	db.SSLRootCert = config.SSLRootCert
+	db.SSLCert = config.SSLCert
+	db.SSLKey = config.SSLKey
+	db.Schema = config.Schema
+	db.Auth = config.Auth
+	db.AccessTokenCommand = config.AccessTokenCommand
 	return nil
 }
 
@@ -1361,6 +1470,12 @@ func (db *MSSQLConfig) SaveConfig(config *DBConfig) error {
 	config.Database = db.Database
 	config.Username = db.Username
 	config.Password = db.Password
+	config.SSLRootCert = db.SSLRootCert
+	config.SSLCert = db.SSLCert
+	config.SSLKey = db.SSLKey
+	config.Schema = db.Schema
+	config.Auth = db.Auth
+	config.AccessTokenCommand = db.AccessTokenCommand
 	return nil
 }
 
@@ -1370,22 +1485,69 @@ func (db *MSSQLConfig) GetUsername() string {
 func (db *MSSQLConfig) DatabaseConnection() string {
 	u := &url.URL{
 		Scheme: "sqlserver",
-		User:   url.UserPassword(db.Username, db.Password),
+		User:   db.userInfo(),
 		Host:   fmt.Sprintf("%s:%d", db.Host, db.Port),
 	}
 	q := u.Query()
 	q.Set("database", db.Database)
+	q.Set("app name", ApplicationName)
+	db.setTLSParams(q)
 	u.RawQuery = q.Encode()
 	return u.String()
 }
+
+// setTLSParams requests an encrypted session verified against the configured CA.
+func (db *MSSQLConfig) setTLSParams(q url.Values) {
+	if db.SSLRootCert != "" {
+		q.Set("encrypt", "true")
+		q.Set("certificate", db.SSLRootCert)
+	}
+}
+
+// openWithClientCert opens the pool through a connector so the client
+// certificate can be attached to the TLS config; go-mssqldb has no DSN
+// parameter for it.
+func (db *MSSQLConfig) openWithClientCert(dsn string) (*sql.DB, error) {
+	cfg, err := msdsn.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.attachClientCert(&cfg); err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(mssql.NewConnectorConfig(cfg)), nil
+}
+
+// attachClientCert adds the client certificate to the TLS config of cfg and
+// requires encryption.
+func (db *MSSQLConfig) attachClientCert(cfg *msdsn.Config) error {
+	tlsCfg, err := utils.LoadTLSConfig(db.SSLRootCert, db.SSLCert, db.SSLKey)
+	if err != nil {
+		return err
+	}
+	if cfg.TLSConfig == nil {
+		cfg.TLSConfig = &tls.Config{ServerName: db.Host}
+	}
+	cfg.TLSConfig.Certificates = tlsCfg.Certificates
+	if tlsCfg.RootCAs != nil {
+		cfg.TLSConfig.RootCAs = tlsCfg.RootCAs
+	}
+	if cfg.Encryption == msdsn.EncryptionOff || cfg.Encryption == msdsn.EncryptionDisabled {
+		cfg.Encryption = msdsn.EncryptionRequired
+	}
+	return nil
+}
+
 func (db *MSSQLConfig) PromptDatabaseSettings() {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Println(utils.Colors.Cyan("Microsoft SQL Server Database Configuration"))
 	db.Host = utils.PromptString(reader, "Database Host", db.Host)
 	db.Port = utils.PromptInt(reader, "Database Port", db.Port)
 	db.Database = utils.PromptString(reader, "Database Name", db.Database)
-	db.Username = utils.PromptString(reader, "Database Username", db.Username)
-	db.Password = utils.PromptPassword(reader, "Database Password", db.Password)
+	var cfg DBConfig
+	db.SaveConfig(&cfg)
+	PromptMSSQLCredentials(reader, &cfg)
+	db.LoadConfig(&cfg)
 }
 
 func (db *MSSQLConfig) DropAllTables() error {
@@ -1395,7 +1557,7 @@ func (db *MSSQLConfig) DropAllTables() error {
 	// A more elegant solution would be to drop tables in the correct order
 	// but that requires parsing the schema, which is complex.
 
-	rows, err := sqlDB.Query("SELECT name, object_id FROM sys.foreign_keys")
+	rows, err := sqlDB.Query(db.inSchema("SELECT name, object_id FROM sys.foreign_keys WHERE schema_id = SCHEMA_ID()"))
 	if err != nil {
 		return fmt.Errorf("failed to query foreign keys: %w", err)
 	}
@@ -1411,21 +1573,21 @@ func (db *MSSQLConfig) DropAllTables() error {
 		if err := sqlDB.QueryRow(parentTableQuery).Scan(&parentTable); err != nil {
 			return fmt.Errorf("failed to get parent table for foreign key %s: %w", name, err)
 		}
-		query := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", parentTable, name)
+		query := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", QualifiedName(db, parentTable), name)
 		if _, err := sqlDB.Exec(query); err != nil {
 			return fmt.Errorf("failed to drop foreign key %s: %w", name, err)
 		}
 	}
 
 	for _, viewName := range requiredViews() {
-		query := fmt.Sprintf("IF OBJECT_ID('%s', 'V') IS NOT NULL DROP VIEW %s", viewName, viewName)
+		query := db.inSchema(fmt.Sprintf("IF OBJECT_ID('%s', 'V') IS NOT NULL DROP VIEW %s", viewName, viewName))
 		if _, err := sqlDB.Exec(query); err != nil {
 			return fmt.Errorf("failed to drop view %s: %w", viewName, err)
 		}
 	}
 
 	for _, tableName := range dropTableOrder() {
-		query := fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NOT NULL DROP TABLE %s", tableName, tableName)
+		query := db.inSchema(fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NOT NULL DROP TABLE %s", tableName, tableName))
 		if _, err := sqlDB.Exec(query); err != nil {
 			return fmt.Errorf("failed to drop table %s: %w", tableName, err)
 		}
@@ -1448,7 +1610,7 @@ func (db *MSSQLConfig) ResetSchema(s *state.State) error {
 	return db.EnforceSchema(s)
 }
 
-func (db *MSSQLConfig) RunAction(action ActionConfig) error {
+func (db *MSSQLConfig) RunAction(action ActionConfig) (int64, error) {
 	var query string
 	var args []interface{}
 
@@ -1459,23 +1621,22 @@ func (db *MSSQLConfig) RunAction(action ActionConfig) error {
 	} else if q, ok := action.Args["query"].(string); ok {
 		query = q
 	} else {
-		return fmt.Errorf("mssql action requires 'command', 'procedure', or 'query'")
+		return 0, fmt.Errorf("mssql action requires 'command', 'procedure', or 'query'")
 	}
 
 	if query == "" {
-		return fmt.Errorf("SQL command not found or query is empty")
+		return 0, fmt.Errorf("SQL command not found or query is empty")
 	}
 
 	if params, ok := action.Args["args"].([]interface{}); ok {
 		args = params
 	}
 
-	_, err := db.db.Exec(query, args...)
-	return err
+	return rowsAffected(db.db.Exec(query, args...))
 }
 
 func (db *MSSQLConfig) GetTables() ([]string, error) {
-	rows, err := db.db.Query("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE IN ('BASE TABLE','VIEW')")
+	rows, err := db.db.Query(db.inSchema("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE IN ('BASE TABLE','VIEW') AND TABLE_SCHEMA = SCHEMA_NAME()"))
 	if err != nil {
 		return nil, err
 	}
@@ -1504,11 +1665,11 @@ func NewDB(config *DBConfig) (DB, error) {
 		db = &SQLiteConfig{}
 	case "postgres":
 		db = &PostgreSQLConfig{
-			Port: 5432,
+			Port: DefaultPort("postgres"),
 		}
 	case "mssql":
 		db = &MSSQLConfig{
-			Port: 1433,
+			Port: DefaultPort("mssql"),
 		}
 	default:
 		db = &SQLiteConfig{}
@@ -1528,6 +1689,11 @@ func (db *PostgreSQLConfig) DatabaseConnectionWithTimeout() string {
 	}
 	q := u.Query()
 	q.Set("sslmode", db.SSLMode)
+	q.Set("application_name", ApplicationName)
+	if db.Schema != "" {
+		q.Set("search_path", db.searchPath())
+	}
+	db.setTLSParams(q)
 	q.Set("connect_timeout", "5")
 	u.RawQuery = q.Encode()
 	return u.String()
@@ -1536,11 +1702,13 @@ func (db *PostgreSQLConfig) DatabaseConnectionWithTimeout() string {
 func (db *MSSQLConfig) DatabaseConnectionWithTimeout() string {
 	u := &url.URL{
 		Scheme: "sqlserver",
-		User:   url.UserPassword(db.Username, db.Password),
+		User:   db.userInfo(),
 		Host:   fmt.Sprintf("%s:%d", db.Host, db.Port),
 	}
 	q := u.Query()
 	q.Set("database", db.Database)
+	q.Set("app name", ApplicationName)
+	db.setTLSParams(q)
 	q.Set("connect timeout", "5")
 	u.RawQuery = q.Encode()
 	return u.String()
@@ -1567,6 +1735,14 @@ func RequiredTables() []string {
 		"SyncHistory",
 		"CommandLog",
 		"WebhookLog",
+		"DeletedAccounts",
+		"IdRemap",
+		"AccountErasures",
+		"ActionRuns",
+		"SyncLocks",
+		"StaleAccounts",
+		"AccountScores",
+		"RoutePlans",
 	}
 }
 
@@ -1614,21 +1790,67 @@ func RunCommand(db DB, command string, args ...any) error {
 	return err
 }
 
+// Command is a named SQL command and its arguments, for RunCommands.
+type Command struct {
+	Name string
+	Args []any
+}
+
+// RunCommands runs commands in order in one unit of work. Nothing is kept
+// when one of them fails.
+func RunCommands(db DB, commands []Command) error {
+	return WorkCommands(db, "commands", commands)
+}
+
 func UpdateConfiguration(db DB, key string, value string) error {
 	return RunCommand(db, "UpdateConfiguration", value, key)
 }
 
 func LogCommand(db DB, command string, args []string, success bool, errorMessage string) error {
-	sqlText := "INSERT INTO CommandLog (Command, Args, Success, ErrorMessage) VALUES (?, ?, ?, ?)"
-	sqlDB := db.GetDB()
-	_, err := sqlDB.Exec(sqlText, command, strings.Join(args, " "), success, errorMessage)
-	return err
+	return InsertCommandLog(db, CommandLogEntry{
+		Command:      command,
+		Args:         strings.Join(args, " "),
+		Success:      success,
+		ErrorMessage: errorMessage,
+	})
 }
 
 func LogWebhook(db DB, receivedAt time.Time, method, uri, headers, body string) error {
-	sqlText := "INSERT INTO WebhookLog (ReceivedAt, Method, Uri, Headers, Body) VALUES (?, ?, ?, ?, ?)"
-	sqlDB := db.GetDB()
-	_, err := sqlDB.Exec(sqlText, receivedAt, method, uri, headers, body)
+	return LogWebhookEntry(db, WebhookLogEntry{
+		ReceivedAt:  receivedAt,
+		Method:      method,
+		Uri:         uri,
+		Headers:     headers,
+		Body:        body,
+		ParseStatus: "unchecked",
+	})
+}
+
+// WebhookLogEntry is one received webhook request and the result of
+// checking its body.
+type WebhookLogEntry struct {
+	ReceivedAt  time.Time
+	Method      string
+	Uri         string
+	Headers     string
+	Body        string
+	ParseStatus string
+	ParseError  string
+	// EntityId is the account or check-in ID the body carries; 0 is stored
+	// as NULL.
+	EntityId int64
+}
+
+// LogWebhookEntry stores a received webhook request in WebhookLog.
+func LogWebhookEntry(db DB, entry WebhookLogEntry) error {
+	sqlText := db.GetSQL("InsertWebhookLog")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: InsertWebhookLog")
+	}
+	parseError := sql.NullString{String: entry.ParseError, Valid: entry.ParseError != ""}
+	entityID := sql.NullInt64{Int64: entry.EntityId, Valid: entry.EntityId != 0}
+	_, err := db.GetDB().Exec(sqlText, entry.ReceivedAt, entry.Method, entry.Uri, entry.Headers, entry.Body,
+		entry.ParseStatus, parseError, entityID)
 	return err
 }
 
@@ -1671,10 +1893,12 @@ func GetExpectedSchema() map[string][]string {
 			"Latitude", "AddressLine1", "Location", "IsApproximate", "CreatedAt", "UpdatedAt",
 		},
 		"AccountsPendingChanges": {
-			"ChangeId", "AccountId", "ChangeType", "Changes", "Status", "CreatedAt", "ProcessedAt",
+			"ChangeId", "AccountId", "ChangeType", "Changes", "Status", "CreatedAt", "ProcessedAt", "BaseUpdatedAt",
+			"IdempotencyKey", "ContentHash", "ValidationErrors",
 		},
 		"AccountCheckinsPendingChanges": {
 			"ChangeId", "CheckinId", "AccountId", "CrmId", "LogDatetime", "Type", "Comments", "ExtraFields", "EndpointType", "CreatedBy", "ChangeType", "Status", "CreatedAt", "ProcessedAt",
+			"IdempotencyKey", "ContentHash",
 		},
 		"Routes": {
 			"RouteId", "Name", "RouteDate", "Duration", "StartAddress", "DestinationAddress", "StartTime",
@@ -1685,9 +1909,35 @@ func GetExpectedSchema() map[string][]string {
 			"Latitude", "Longitude", "LayoverMinutes", "Position", "CompleteAddress", "LocationId",
 			"CustomerId", "ApptTime", "Type", "PlaceId", "CreatedAt", "UpdatedAt",
 		},
+		"DeletedAccounts": {
+			"DeletedId", "AccountId", "ChangeId", "FullName", "Data", "DeletedAt", "RestoredAt", "RestoredAccountId",
+		},
+		"IdRemap": {
+			"RemapId", "EntityType", "OldId", "NewId", "Reason", "RowsRewritten", "RemappedAt",
+		},
+		"AccountErasures": {
+			"ErasureId", "AccountId", "Mode", "ReportId", "ReportDigest", "DeleteChangeId", "ErasedAt",
+		},
+		"SyncLocks": {
+			"LockName", "HolderId", "Host", "Pid", "Operation", "AcquiredAt", "HeartbeatAt",
+		},
+		"StaleAccounts": {
+			"AccountId", "RemoteModifiedDate", "MarkedAt",
+		},
+		"AccountScores": {
+			"AccountId", "ScoreName", "Score", "ComputedAt",
+		},
+		"RoutePlans": {
+			"PlanId", "Name", "RouteDate", "StartAddress", "DestinationAddress", "StartTime", "TemplateRouteId",
+			"Waypoints", "Status", "RouteId", "CreatedAt", "UpdatedAt", "PushedAt",
+		},
+		"ActionRuns": {
+			"RunId", "ActionName", "ActionType", "TriggeredBy", "Source", "Config", "Event", "Args", "Status",
+			"ExitCode", "CommandOutput", "RowsAffected", "ErrorMessage", "RerunOf", "StartedAt", "DurationMs",
+		},
 		"SyncHistory": {
 			"HistoryId", "CorrelationId", "RunType", "Direction", "Source", "Initiator", "Status", "ItemsProcessed", "ErrorCount",
-			"StartedAt", "CompletedAt", "DurationSeconds", "Summary", "Details",
+			"StartedAt", "CompletedAt", "DurationSeconds", "Summary", "Details", "Note",
 		},
 		"UserProfiles": {
 			"ProfileId", "Email", "FirstName", "LastName", "IsManager", "IsHideReferralIOSBanner",
@@ -1704,10 +1954,16 @@ func GetExpectedSchema() map[string][]string {
 			"DataSetName", "ProfileId", "Text", "Value", "DataSetPosition", "CreatedAt", "UpdatedAt",
 		},
 		"FieldMaps": {
-			"FieldName", "ObjectType", "JsonField", "DataSetName", "DataSetLabel",
+			"FieldName", "ObjectType", "JsonField", "DataSetName", "DataSetLabel", "SyncDirection", "MergeStrategy",
 		},
 		"Configurations": {
 			"SettingKey", "SettingValue", "LastModified",
 		},
+		"CommandLog": {
+			"LogId", "Command", "Args", "Timestamp", "Success", "ErrorMessage", "Flags", "DurationMs",
+		},
+		"WebhookLog": {
+			"Id", "ReceivedAt", "Method", "Uri", "Headers", "Body", "ParseStatus", "ParseError", "EntityId",
+		},
 	}
 }
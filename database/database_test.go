@@ -46,12 +46,142 @@ func TestSQLFiles(t *testing.T) {
 		"CreateSyncHistoryTable.sql",
 		"CreateUserProfilesTable.sql",
 		"DeleteAccountLocations.sql",
+		"DeleteOrphanedAccountCheckins.sql",
+		"DeleteOrphanedAccountLocations.sql",
+		"DeleteOrphanedRouteWaypoints.sql",
 		"DeleteDataSetValues.sql",
 		"DeleteDataSets.sql",
 		"DeleteRouteWaypoints.sql",
 		"GetAccountById.sql",
+		"GetCommandLog.sql",
+		"GetCommandLogEntry.sql",
+		"InsertCommandLog.sql",
+		"GetChangesByIdempotencyKey.sql",
+		"UpdateChangeIdempotencyKey.sql",
+		"GetProfileRole.sql",
+		"GetTeamAccounts.sql",
+		"GetTeamCheckins.sql",
+		"GetAccountOwners.sql",
+		"GetTerritoryAccounts.sql",
+		"GetAccountCheckinKeys.sql",
+		"GetTableSizes.sql",
+		"InsertWebhookLog.sql",
+		"GetFieldSyncDirections.sql",
+		"UpdateFieldSyncDirection.sql",
+		"AddColumnAccountsPendingChangesBaseUpdatedAt.sql",
+		"AddColumnAccountsPendingChangesIdempotencyKey.sql",
+		"AddColumnAccountsPendingChangesContentHash.sql",
+		"AddColumnAccountsPendingChangesValidationErrors.sql",
+		"SetAccountChangeValidationErrors.sql",
+		"AddColumnAccountCheckinsPendingChangesIdempotencyKey.sql",
+		"AddColumnAccountCheckinsPendingChangesContentHash.sql",
+		"AddColumnFieldMapsSyncDirection.sql",
+		"AddColumnCommandLogFlags.sql",
+		"AddColumnCommandLogDurationMs.sql",
+		"AddColumnWebhookLogParseStatus.sql",
+		"AddColumnWebhookLogParseError.sql",
+		"AddColumnWebhookLogEntityId.sql",
+		"AddColumnSyncHistoryNote.sql",
+		"SetSyncHistoryNote.sql",
+		"AddColumnFieldMapsMergeStrategy.sql",
+		"UpdateFieldMergeStrategy.sql",
+		"AccountsWithinRadius.sql",
+		"FindLocationsByAddress.sql",
+		"GetUnpushedAccountChanges.sql",
+		"InsertCheckinPendingChange.sql",
+		"DeleteBenchmarkAccountLocations.sql",
+		"DeleteBenchmarkAccounts.sql",
+		"DeleteSeedCheckins.sql",
+		"DeleteSeedAccountLocations.sql",
+		"DeleteSeedAccounts.sql",
+		"ClearFieldMapDataSets.sql",
+		"UpdateFieldMapDataSet.sql",
+		"CreateDeletedAccountsTable.sql",
+		"InsertDeletedAccount.sql",
+		"DiscardDeletedAccount.sql",
+		"GetDeletedAccounts.sql",
+		"MarkDeletedAccountRestored.sql",
+		"PurgeDeletedAccounts.sql",
+		"CreateIdRemapTable.sql",
+		"InsertIdRemap.sql",
+		"GetIdRemaps.sql",
+		"GetAccountCrmIds.sql",
+		"RemapCheckinAccountIds.sql",
+		"RemapWaypointAccountIds.sql",
+		"RemapPendingAccountChanges.sql",
+		"RemapPendingCheckinChanges.sql",
+		"DeleteMergedAccount.sql",
+		"CreateAccountErasuresTable.sql",
+		"InsertAccountErasure.sql",
+		"GetErasedAccountIds.sql",
+		"FindAccountIdsByEmail.sql",
+		"DeleteAccountWebhookLogs.sql",
+		"DeleteAccountCheckins.sql",
+		"AnonymizeAccountCheckins.sql",
+		"AnonymizeAccountWaypoints.sql",
+		"AnonymizeAccount.sql",
+		"DeleteErasedAccount.sql",
+		"DeleteAccountPendingChangesForErasure.sql",
+		"DeleteAccountPendingChange.sql",
+		"DeleteAccountCheckinPendingChanges.sql",
+		"DeleteAccountRecycleBinCopies.sql",
+		"CreateActionRunsTable.sql",
+		"InsertActionRun.sql",
+		"GetActionRuns.sql",
+		"GetActionRun.sql",
+		"CreateSyncLocksTable.sql",
+		"InsertSyncLock.sql",
+		"GetSyncLock.sql",
+		"HeartbeatSyncLock.sql",
+		"ReleaseSyncLock.sql",
+		"ForceReleaseSyncLock.sql",
+		"CreateStaleAccountsTable.sql",
+		"MarkAccountStale.sql",
+		"DeleteStaleAccount.sql",
+		"IsAccountStale.sql",
+		"GetStaleAccountIds.sql",
+		"CreateAccountScoresTable.sql",
+		"MergeAccountScore.sql",
+		"DeleteAccountScoresBefore.sql",
+		"GetAccountScoreRanking.sql",
+		"GetAllAccounts.sql",
+		"CreateRoutePlansTable.sql",
+		"InsertRoutePlan.sql",
+		"UpdateRoutePlan.sql",
+		"MarkRoutePlanPushed.sql",
+		"DeleteRoutePlan.sql",
+		"GetRoutePlan.sql",
+		"GetRoutePlansBetween.sql",
+		"GetRoutesBetween.sql",
+		"GetRouteWaypoints.sql",
+		"GetAccountStop.sql",
+		"UpdateAccountPendingChangeChanges.sql",
+		"RebaseAccountPendingChange.sql",
+		"UpdateCheckinPendingChangeFields.sql",
+		"CreateAccountsChangeCaptureTrigger.sql",
+		"DropAccountsChangeCaptureTrigger.sql",
+		"GetChangeCaptureStatus.sql",
 		"GetAllAccountIds.sql",
+		"GetAccountChanges.sql",
+		"GetAccountChangeConflict.sql",
+		"InsertAccountPendingChange.sql",
+		"GetAccountModifiedDates.sql",
+		"GetAllRouteIds.sql",
+		"GetOrphanedRows.sql",
+		"GetPullThroughput.sql",
+		"GetDataQualityCounts.sql",
+		"GetCustomFieldUsage.sql",
+		"GetMappedAccountFields.sql",
+		"GetCheckinChanges.sql",
 		"GetCheckinById.sql",
+		"GetCheckinsBefore.sql",
+		"GetAccountFieldLabels.sql",
+		"GetAppointmentTimes.sql",
+		"GetFollowUpDates.sql",
+		"GetRouteAppointmentTimes.sql",
+		"ListAccountsByOwner.sql",
+		"ListCheckinsForAccount.sql",
+		"DeleteCheckin.sql",
 		"GetPendingAccountChanges.sql",
 		"GetPendingCheckinChanges.sql",
 		"GetProfile.sql",
@@ -69,6 +199,11 @@ func TestSQLFiles(t *testing.T) {
 		"SearchAccounts.sql",
 		"SearchRoutes.sql",
 		"SearchCheckins.sql",
+		"GlobalSearchAccounts.sql",
+		"GlobalSearchCheckins.sql",
+		"GlobalSearchRoutes.sql",
+		"GlobalSearchAccountChanges.sql",
+		"GlobalSearchCheckinChanges.sql",
 		"UpdatePendingChangeStatus.sql",
 		"CreateAccountsWithLabelsView.sql",
 		"CreateFieldMapsTable.sql",
@@ -82,16 +217,38 @@ func TestSQLFiles(t *testing.T) {
 		"CreateCommandLogTable.sql",
 		"CompleteSyncHistory.sql",
 		"GetRecentSyncHistory.sql",
+		"GetDataSetValueKeys.sql",
+		"UpdateDataSetValue.sql",
+		"DeleteDataSetValue.sql",
+		"GetDataSetByAccountField.sql",
+		"GetDataSetValuesPage.sql",
+		"GetDataSetNames.sql",
+		"UpdateDataSet.sql",
+		"DeleteDataSet.sql",
+		"GetSyncHistorySince.sql",
+		"GetLastSyncHistoryRun.sql",
+		"GetLatestSyncRuns.sql",
 		"InsertSyncHistory.sql",
 		"CreateWebhookLogTable.sql",
 		"GetWebhookLog.sql",
 		"UpdateSyncHistoryMetrics.sql",
+		"SelectTableRows.sql",
+		"GetAccountColumnsByIds.sql",
 	}
 
 	postgresMssqlExtraFiles := []string{
 		"CreateDatasetsUpdateTrigger.sql",
 		"CheckProcedureExists.sql",
 		"CheckTriggerExists.sql",
+		"CreateCheckinBulkTable.sql",
+		"MergeCheckinBulkTable.sql",
+	}
+
+	sqliteExtraFiles := []string{
+		"CreateChangeCaptureStateTable.sql",
+		"SuppressChangeCapture.sql",
+		"ResumeChangeCapture.sql",
+		"BulkMergeAccountCheckins.sql",
 	}
 
 	checkFiles := func(t *testing.T, dir string, expected []string) {
@@ -130,7 +287,7 @@ func TestSQLFiles(t *testing.T) {
 	}
 
 	t.Run("sqlite3", func(t *testing.T) {
-		checkFiles(t, filepath.Join("database", "sqlite3"), baseExpectedFiles)
+		checkFiles(t, filepath.Join("database", "sqlite3"), append(baseExpectedFiles, sqliteExtraFiles...))
 	})
 
 	t.Run("postgres", func(t *testing.T) {
@@ -222,3 +379,122 @@ func TestIsConnected(t *testing.T) {
 		t.Errorf("Expected IsConnected to be false after closing the connection")
 	}
 }
+
+func TestDatabaseConnectionTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      DB
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "postgres without certificates",
+			db:      &PostgreSQLConfig{Host: "db", Port: 5432, Database: "bm", SSLMode: "require"},
+			want:    []string{"sslmode=require"},
+			notWant: []string{"sslrootcert", "sslcert", "sslkey"},
+		},
+		{
+			name: "postgres with mutual TLS",
+			db: &PostgreSQLConfig{Host: "db", Port: 5432, Database: "bm", SSLMode: "verify-full",
+				SSLRootCert: "/certs/ca.pem", SSLCert: "/certs/client.pem", SSLKey: "/certs/client.key"},
+			want: []string{"sslmode=verify-full", "sslrootcert=%2Fcerts%2Fca.pem", "sslcert=%2Fcerts%2Fclient.pem", "sslkey=%2Fcerts%2Fclient.key"},
+		},
+		{
+			name:    "mssql without certificates",
+			db:      &MSSQLConfig{Host: "db", Port: 1433, Database: "bm"},
+			notWant: []string{"certificate", "encrypt"},
+		},
+		{
+			name: "mssql with custom CA",
+			db:   &MSSQLConfig{Host: "db", Port: 1433, Database: "bm", SSLRootCert: "/certs/ca.pem"},
+			want: []string{"encrypt=true", "certificate=%2Fcerts%2Fca.pem"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := tt.db.DatabaseConnection()
+			for _, w := range tt.want {
+				if !strings.Contains(dsn, w) {
+					t.Errorf("expected %q in %q", w, dsn)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(dsn, nw) {
+					t.Errorf("did not expect %q in %q", nw, dsn)
+				}
+			}
+		})
+	}
+}
+
+func TestTLSConfigRoundTrip(t *testing.T) {
+	cfg := &DBConfig{Type: "mssql", Host: "db", SSLRootCert: "ca.pem", SSLCert: "client.pem", SSLKey: "client.key"}
+	db, err := NewDB(cfg)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	saved := &DBConfig{}
+	if err := db.SaveConfig(saved); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if saved.SSLRootCert != "ca.pem" || saved.SSLCert != "client.pem" || saved.SSLKey != "client.key" {
+		t.Fatalf("TLS settings not preserved: %+v", saved)
+	}
+}
+
+func TestAccountChangeConflict(t *testing.T) {
+	db, err := NewDB(&DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("EnforceSchema: %v", err)
+	}
+
+	sqlDB := db.GetDB()
+	if _, err := sqlDB.Exec("INSERT INTO Accounts (AccountId, FullName, UpdatedAt) VALUES (1, 'Acme', '2024-01-01 00:00:00'), (2, 'Globex', '2024-01-01 00:00:00')"); err != nil {
+		t.Fatalf("seed accounts: %v", err)
+	}
+	for _, id := range []int{1, 2} {
+		if err := StageAccountChange(db, id, "UPDATE", `{"notes":"staged"}`); err != nil {
+			t.Fatalf("StageAccountChange(%d): %v", id, err)
+		}
+	}
+	// Change created without a version (e.g. inserted by hand) never conflicts.
+	if _, err := sqlDB.Exec("INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (1, 'UPDATE', '{}')"); err != nil {
+		t.Fatalf("insert unversioned change: %v", err)
+	}
+	// A webhook refreshes account 2 after its change was staged.
+	if _, err := sqlDB.Exec("UPDATE Accounts SET UpdatedAt = '2024-02-01 00:00:00' WHERE AccountId = 2"); err != nil {
+		t.Fatalf("update account: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		changeID int
+		conflict bool
+	}{
+		{name: "unchanged account", changeID: 1, conflict: false},
+		{name: "account modified after staging", changeID: 2, conflict: true},
+		{name: "unversioned change", changeID: 3, conflict: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict, err := GetAccountChangeConflict(db, tt.changeID)
+			if err != nil {
+				t.Fatalf("GetAccountChangeConflict: %v", err)
+			}
+			if (conflict != nil) != tt.conflict {
+				t.Fatalf("expected conflict=%v, got %+v", tt.conflict, conflict)
+			}
+			if conflict != nil && conflict.BaseVersion == conflict.CurrentVersion {
+				t.Fatalf("conflict versions should differ: %+v", conflict)
+			}
+		})
+	}
+}
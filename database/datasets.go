@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DataSetValue is one picklist value of a data set.
+type DataSetValue struct {
+	Text  string
+	Value string
+}
+
+// DataSetValueKeys maps data set name, then value, to the DataSetValueId of
+// the stored row. It lets a pull update existing values in place.
+type DataSetValueKeys map[string]map[string]int
+
+// GetDataSetValueKeys returns the stored values for a profile keyed by data
+// set and value.
+func GetDataSetValueKeys(db DB, profileID int64) (DataSetValueKeys, error) {
+	sqlText := db.GetSQL("GetDataSetValueKeys")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetDataSetValueKeys")
+	}
+	rows, err := db.GetDB().Query(sqlText, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(DataSetValueKeys)
+	for rows.Next() {
+		var id int
+		var name, value sql.NullString
+		if err := rows.Scan(&id, &name, &value); err != nil {
+			return nil, err
+		}
+		if keys[name.String] == nil {
+			keys[name.String] = make(map[string]int)
+		}
+		keys[name.String][value.String] = id
+	}
+	return keys, rows.Err()
+}
+
+// GetDataSetNames returns the names of the data sets stored for a profile.
+func GetDataSetNames(db DB, profileID int64) (map[string]bool, error) {
+	sqlText := db.GetSQL("GetDataSetNames")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetDataSetNames")
+	}
+	rows, err := db.GetDB().Query(sqlText, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name sql.NullString
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name.String] = true
+	}
+	return names, rows.Err()
+}
+
+// GetDataSetForAccountField returns the name of the data set mapped to an
+// Accounts column, or "" when the column has no data set.
+func GetDataSetForAccountField(db DB, column string) (string, error) {
+	sqlText := db.GetSQL("GetDataSetByAccountField")
+	if sqlText == "" {
+		return "", fmt.Errorf("unknown or unavailable SQL command: GetDataSetByAccountField")
+	}
+	var name sql.NullString
+	err := db.GetDB().QueryRow(sqlText, column).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return name.String, err
+}
+
+// GetDataSetValuesPage returns up to limit values of a data set starting at
+// offset, ordered by text. A non-empty search keeps values whose text or
+// value contains it.
+func GetDataSetValuesPage(db DB, dataSet, search string, limit, offset int) ([]DataSetValue, error) {
+	sqlText := db.GetSQL("GetDataSetValuesPage")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetDataSetValuesPage")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	sqlText = strings.Replace(sqlText, "{{LIMIT}}", strconv.Itoa(limit), 1)
+	sqlText = strings.Replace(sqlText, "{{OFFSET}}", strconv.Itoa(offset), 1)
+
+	pattern := "%" + strings.TrimSpace(search) + "%"
+	rows, err := db.GetDB().Query(sqlText, dataSet, pattern, pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []DataSetValue
+	for rows.Next() {
+		var text, value sql.NullString
+		if err := rows.Scan(&text, &value); err != nil {
+			return nil, err
+		}
+		values = append(values, DataSetValue{Text: text.String, Value: value.String})
+	}
+	return values, rows.Err()
+}
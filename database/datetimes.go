@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DateTimeValue is a stored follow-up date or appointment time.
+type DateTimeValue struct {
+	Table string // Accounts or RouteWaypoints
+	Field string // FollowUpDate or ApptTime
+	ID    int
+	Value string
+}
+
+// GetDateTimeValues returns every non-empty follow-up date and appointment
+// time in the database.
+func GetDateTimeValues(db DB) ([]DateTimeValue, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	followUps, err := queryDateTimes(db, "GetFollowUpDates", "Accounts", "FollowUpDate")
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := queryDateTimes(db, "GetAppointmentTimes", "RouteWaypoints", "ApptTime")
+	if err != nil {
+		return nil, err
+	}
+	return append(followUps, appointments...), nil
+}
+
+// GetRouteAppointmentTimes returns the stored appointment times of a route's
+// waypoints, keyed by waypoint ID.
+func GetRouteAppointmentTimes(db DB, routeID int) (map[int]string, error) {
+	values, err := queryDateTimes(db, "GetRouteAppointmentTimes", "RouteWaypoints", "ApptTime", routeID)
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[int]string, len(values))
+	for _, v := range values {
+		times[v.ID] = v.Value
+	}
+	return times, nil
+}
+
+func queryDateTimes(db DB, command, table, field string, args ...interface{}) ([]DateTimeValue, error) {
+	sqlText := db.GetSQL(command)
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	rows, err := db.GetDB().Query(sqlText, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s.%s: %w", table, field, err)
+	}
+	defer rows.Close()
+
+	var values []DateTimeValue
+	for rows.Next() {
+		var id int
+		var value sql.NullString
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, err
+		}
+		if value.String == "" {
+			continue
+		}
+		values = append(values, DateTimeValue{Table: table, Field: field, ID: id, Value: value.String})
+	}
+	return values, rows.Err()
+}
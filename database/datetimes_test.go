@@ -0,0 +1,32 @@
+package database
+
+import "testing"
+
+func TestGetDateTimeValues(t *testing.T) {
+	db := newBackupTestDB(t, "datetimes.db")
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, FullName, FollowUpDate) VALUES (1, 'Acme', '2024-03-10'), (2, 'Globex', ''), (3, 'Initech', NULL)`,
+		`INSERT INTO RouteWaypoints (WaypointId, RouteId, ApptTime) VALUES (10, 5, '2024-03-10T15:00:00Z'), (11, 5, NULL), (12, 6, '2024-03-11T15:00:00Z')`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	values, err := GetDateTimeValues(db)
+	if err != nil {
+		t.Fatalf("GetDateTimeValues: %v", err)
+	}
+	if len(values) != 3 || values[0].Table != "Accounts" || values[0].ID != 1 || values[0].Value != "2024-03-10" {
+		t.Fatalf("values = %+v", values)
+	}
+
+	times, err := GetRouteAppointmentTimes(db, 5)
+	if err != nil {
+		t.Fatalf("GetRouteAppointmentTimes: %v", err)
+	}
+	if len(times) != 1 || times[10] == "" {
+		t.Fatalf("route 5 times = %v", times)
+	}
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeletedAccount is a copy of an account taken before its deletion was
+// pushed, kept so the account can be restored.
+type DeletedAccount struct {
+	DeletedId int
+	AccountId int
+	ChangeId  int
+	FullName  string
+	// Data is the JSON of the account as it was stored locally.
+	Data      string
+	DeletedAt time.Time
+}
+
+// ArchiveDeletedAccount copies an account into DeletedAccounts.
+func ArchiveDeletedAccount(db DB, entry DeletedAccount) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	return RunCommand(db, "InsertDeletedAccount", entry.AccountId, entry.ChangeId, entry.FullName, entry.Data, entry.DeletedAt.UTC())
+}
+
+// DiscardDeletedAccount removes the copy made for a change whose delete was
+// not carried out.
+func DiscardDeletedAccount(db DB, changeId int) error {
+	return RunCommand(db, "DiscardDeletedAccount", changeId)
+}
+
+// GetDeletedAccounts returns the deleted accounts not yet restored, deleted
+// at or after since, newest first.
+func GetDeletedAccounts(db DB, since time.Time) ([]DeletedAccount, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetDeletedAccounts")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetDeletedAccounts")
+	}
+	rows, err := db.GetDB().Query(sqlText, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []DeletedAccount
+	for rows.Next() {
+		var entry DeletedAccount
+		var changeID sql.NullInt64
+		var fullName sql.NullString
+		if err := rows.Scan(&entry.DeletedId, &entry.AccountId, &changeID, &fullName, &entry.Data, &entry.DeletedAt); err != nil {
+			return nil, err
+		}
+		entry.ChangeId = int(changeID.Int64)
+		entry.FullName = fullName.String
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// MarkDeletedAccountRestored records that a deleted account was re-created
+// as restoredAccountId.
+func MarkDeletedAccountRestored(db DB, deletedId, restoredAccountId int, restoredAt time.Time) error {
+	return RunCommand(db, "MarkDeletedAccountRestored", restoredAt.UTC(), restoredAccountId, deletedId)
+}
+
+// PurgeDeletedAccounts removes copies of accounts deleted before cutoff and
+// returns how many were removed.
+func PurgeDeletedAccounts(db DB, cutoff time.Time) (int64, error) {
+	if db == nil || db.GetDB() == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("PurgeDeletedAccounts")
+	if sqlText == "" {
+		return 0, fmt.Errorf("unknown or unavailable SQL command: PurgeDeletedAccounts")
+	}
+	result, err := db.GetDB().Exec(sqlText, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Erasure modes.
+const (
+	// ErasureDelete removes the account and everything stored about it.
+	ErasureDelete = "delete"
+	// ErasureAnonymize keeps the account and its check-ins for counts and
+	// history but clears every field that identifies a person.
+	ErasureAnonymize = "anonymize"
+)
+
+// ErasedName is the name an anonymized account and its route waypoints
+// are left with.
+const ErasedName = "Erased"
+
+// AccountErasure records that the personal data of an account was erased.
+// It holds no personal data itself, so it survives the erasure.
+type AccountErasure struct {
+	ErasureId int
+	AccountId int
+	Mode      string
+	ReportId  string
+	// ReportDigest is the SHA-256 of the signed report, linking the entry
+	// to the report kept for compliance records.
+	ReportDigest string
+	// DeleteChangeId is the staged DELETE of the account, or 0.
+	DeleteChangeId int
+	ErasedAt       time.Time
+}
+
+// erasureStep is one command EraseAccount runs. mode limits it to one
+// erasure mode; empty runs it in both.
+type erasureStep struct {
+	table   string
+	command string
+	mode    string
+	args    func(accountID, keepChangeId int) []any
+}
+
+func accountArg(accountID, _ int) []any { return []any{accountID} }
+
+// erasureSteps run in order. Webhook logs go first since they are matched
+// through the check-ins.
+var erasureSteps = []erasureStep{
+	{"WebhookLog", "DeleteAccountWebhookLogs", "", func(id, _ int) []any { return []any{id, id} }},
+	{"AccountCheckinsPendingChanges", "DeleteAccountCheckinPendingChanges", "", accountArg},
+	{"AccountsPendingChanges", "DeleteAccountPendingChangesForErasure", "", func(id, keep int) []any { return []any{id, keep} }},
+	{"DeletedAccounts", "DeleteAccountRecycleBinCopies", "", func(id, _ int) []any { return []any{id, id} }},
+	{"AccountLocations", "DeleteAccountLocations", "", accountArg},
+	{"RouteWaypoints", "AnonymizeAccountWaypoints", "", func(id, _ int) []any { return []any{ErasedName, id} }},
+	{"AccountCheckins", "DeleteAccountCheckins", ErasureDelete, accountArg},
+	{"Accounts", "DeleteErasedAccount", ErasureDelete, accountArg},
+	{"AccountCheckins", "AnonymizeAccountCheckins", ErasureAnonymize, accountArg},
+	{"Accounts", "AnonymizeAccount", ErasureAnonymize, func(id, _ int) []any { return []any{ErasedName, ErasedName, id} }},
+}
+
+// EraseAccount deletes or anonymizes the personal data stored for
+// accountID: the account, its check-ins, locations, route waypoints,
+// staged changes, recycle bin copies, and webhook logs. keepChangeId is a
+// staged DELETE of the account to leave in place, or 0.
+//
+// seal receives the rows changed per table and returns the entry recorded
+// in AccountErasures. It runs inside the transaction, so the erasure and
+// its record are committed together. EraseAccount returns the counts.
+func EraseAccount(db DB, accountID int, mode string, keepChangeId int, seal func(rows map[string]int64) (AccountErasure, error)) (map[string]int64, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	if mode != ErasureDelete && mode != ErasureAnonymize {
+		return nil, fmt.Errorf("unknown erasure mode %q (expected %s or %s)", mode, ErasureDelete, ErasureAnonymize)
+	}
+
+	tx, err := db.GetDB().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows := make(map[string]int64)
+	for _, step := range erasureSteps {
+		if step.mode != "" && step.mode != mode {
+			continue
+		}
+		sqlText := db.GetSQL(step.command)
+		if sqlText == "" {
+			return nil, fmt.Errorf("unknown or unavailable SQL command: %s", step.command)
+		}
+		result, err := tx.Exec(sqlText, step.args(accountID, keepChangeId)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", step.command, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		rows[step.table] += n
+	}
+
+	entry, err := seal(rows)
+	if err != nil {
+		return nil, err
+	}
+	sqlText := db.GetSQL("InsertAccountErasure")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: InsertAccountErasure")
+	}
+	var deleteChange any
+	if entry.DeleteChangeId > 0 {
+		deleteChange = entry.DeleteChangeId
+	}
+	if _, err := tx.Exec(sqlText, accountID, mode, entry.ReportId, entry.ReportDigest, deleteChange, entry.ErasedAt.UTC()); err != nil {
+		return nil, fmt.Errorf("failed to record the erasure: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit erasure of account %d: %w", accountID, err)
+	}
+	return rows, nil
+}
+
+// GetErasedAccountIds returns the accounts whose data was erased.
+func GetErasedAccountIds(db DB) (map[int]bool, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetErasedAccountIds")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetErasedAccountIds")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	erased := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		erased[id] = true
+	}
+	return erased, rows.Err()
+}
+
+// FindAccountIdsByEmail returns the accounts stored with email, ignoring
+// case.
+func FindAccountIdsByEmail(db DB, email string) ([]int, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("FindAccountIdsByEmail")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: FindAccountIdsByEmail")
+	}
+	rows, err := db.GetDB().Query(sqlText, strings.TrimSpace(email))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func seedErasure(t *testing.T, db DB) {
+	t.Helper()
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, FirstName, LastName, FullName, Email, CustomText) VALUES
+			(1, 'Ada', 'Lovelace', 'Ada Lovelace', 'Ada@example.com', 'vip'), (2, 'Alan', 'Turing', 'Alan Turing', 'alan@example.com', NULL)`,
+		`INSERT INTO AccountLocations (AccountId, City) VALUES (1, 'London'), (2, 'Wilmslow')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, Comments) VALUES (10, 1, '2024-05-01T09:00:00', 'met Ada'), (11, 2, '2024-05-02T09:00:00', 'met Alan')`,
+		`INSERT INTO Routes (RouteId, Name) VALUES (50, 'Monday')`,
+		`INSERT INTO RouteWaypoints (WaypointId, RouteId, CustomerId, Name, Address) VALUES (500, 50, 1, 'Ada Lovelace', '12 St James'), (501, 50, 2, 'Alan Turing', 'Hollymeade')`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (1, 'UPDATE', '{"email": "ada@new.example"}'), (1, 'DELETE', '{}')`,
+		`INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, ChangeType) VALUES (10, 1, 'UPDATE')`,
+		`INSERT INTO WebhookLog (ReceivedAt, Method, Uri, Body, EntityId) VALUES (CURRENT_TIMESTAMP, 'POST', '/webhook/account', '{}', 1), (CURRENT_TIMESTAMP, 'POST', '/webhook/checkin', '{}', 10)`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+}
+
+func countWhere(t *testing.T, db DB, query string) int {
+	t.Helper()
+	var n int
+	if err := db.GetDB().QueryRow(query).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestEraseAccount(t *testing.T) {
+	for _, mode := range []string{ErasureDelete, ErasureAnonymize} {
+		t.Run(mode, func(t *testing.T) {
+			db := newBackupTestDB(t, "erase.db")
+			seedErasure(t, db)
+			if ids, err := FindAccountIdsByEmail(db, " ada@EXAMPLE.com "); err != nil || fmt.Sprint(ids) != "[1]" {
+				t.Fatalf("FindAccountIdsByEmail = %v, %v", ids, err)
+			}
+			var keep int
+			if err := db.GetDB().QueryRow(`SELECT ChangeId FROM AccountsPendingChanges WHERE ChangeType = 'DELETE'`).Scan(&keep); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := EraseAccount(db, 1, mode, keep, func(rows map[string]int64) (AccountErasure, error) {
+				return AccountErasure{ReportId: "report-1", ReportDigest: "digest", DeleteChangeId: keep, ErasedAt: time.Now()}, nil
+			})
+			if err != nil {
+				t.Fatalf("EraseAccount: %v", err)
+			}
+			if rows["WebhookLog"] != 2 || rows["AccountsPendingChanges"] != 1 || rows["AccountCheckins"] != 1 || rows["Accounts"] != 1 {
+				t.Errorf("rows = %v", rows)
+			}
+
+			want := map[string]int{
+				`SELECT COUNT(*) FROM AccountLocations WHERE AccountId = 1`:                                        0,
+				`SELECT COUNT(*) FROM AccountCheckinsPendingChanges`:                                               0,
+				`SELECT COUNT(*) FROM AccountsPendingChanges WHERE ChangeType = 'DELETE'`:                          1,
+				`SELECT COUNT(*) FROM WebhookLog`:                                                                  0,
+				`SELECT COUNT(*) FROM RouteWaypoints WHERE CustomerId = 1 AND Name = 'Erased' AND Address IS NULL`: 1,
+				`SELECT COUNT(*) FROM Accounts WHERE AccountId = 2 AND Email IS NOT NULL`:                          1,
+				`SELECT COUNT(*) FROM AccountCheckins WHERE AccountId = 2 AND Comments IS NOT NULL`:                1,
+			}
+			if mode == ErasureDelete {
+				want[`SELECT COUNT(*) FROM Accounts WHERE AccountId = 1`] = 0
+				want[`SELECT COUNT(*) FROM AccountCheckins WHERE AccountId = 1`] = 0
+			} else {
+				want[`SELECT COUNT(*) FROM Accounts WHERE AccountId = 1 AND FullName = 'Erased' AND Email IS NULL AND CustomText IS NULL`] = 1
+				want[`SELECT COUNT(*) FROM AccountCheckins WHERE AccountId = 1 AND Comments IS NULL`] = 1
+			}
+			for query, n := range want {
+				if got := countWhere(t, db, query); got != n {
+					t.Errorf("%s = %d, want %d", query, got, n)
+				}
+			}
+
+			erased, err := GetErasedAccountIds(db)
+			if err != nil || !erased[1] || erased[2] {
+				t.Errorf("GetErasedAccountIds = %v, %v; want only 1", erased, err)
+			}
+		})
+	}
+}
+
+func TestEraseAccountRollsBackWhenSealFails(t *testing.T) {
+	db := newBackupTestDB(t, "erase.db")
+	seedErasure(t, db)
+	_, err := EraseAccount(db, 1, ErasureDelete, 0, func(map[string]int64) (AccountErasure, error) {
+		return AccountErasure{}, fmt.Errorf("no report")
+	})
+	if err == nil {
+		t.Fatal("expected the seal error")
+	}
+	if n := countWhere(t, db, `SELECT COUNT(*) FROM AccountCheckins WHERE AccountId = 1`); n != 1 {
+		t.Errorf("check-ins were erased without a report: %d left", n)
+	}
+}
+
+func TestEraseArchivedCheckins(t *testing.T) {
+	db := newBackupTestDB(t, "erase.db")
+	dir := filepath.Join(t.TempDir(), "archive")
+	seed := `INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, Comments) VALUES
+		(10, 1, '2023-01-05T09:00:00', 'first'), (11, 2, '2023-01-20T09:00:00', 'other'), (12, 1, '2023-02-11T09:00:00', 'second')`
+	if _, err := db.GetDB().Exec(seed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ArchiveCheckins(db, dir, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := EraseArchivedCheckins(dir, 1, ErasureAnonymize)
+	if err != nil || n != 2 {
+		t.Fatalf("anonymize = %d, %v; want 2", n, err)
+	}
+	checkins, err := readCheckinArchiveFile(filepath.Join(dir, "checkins-2023-01.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range checkins {
+		if *c.AccountId == 1 && c.Comments != nil {
+			t.Errorf("anonymized check-in kept its comments: %q", *c.Comments)
+		}
+		if *c.AccountId == 2 && (c.Comments == nil || *c.Comments != "other") {
+			t.Errorf("another account's check-in was changed")
+		}
+	}
+
+	if n, err = EraseArchivedCheckins(dir, 1, ErasureDelete); err != nil || n != 2 {
+		t.Fatalf("delete = %d, %v; want 2", n, err)
+	}
+	index, err := LoadCheckinArchiveIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Contains("2023-02") || index.Count() != 1 || index.Months["2023-01"].ByAccount[1] != 0 {
+		t.Errorf("index after delete = %v, count %d", index.SortedMonths(), index.Count())
+	}
+}
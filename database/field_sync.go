@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// FieldSyncRule is the sync direction and merge strategy of one FieldMaps
+// entry.
+type FieldSyncRule struct {
+	FieldName string
+	JsonField string
+	// Label is the data set label of custom fields, if the profile has one.
+	Label string
+	// Direction is "both", "pull" (local edits are never pushed), or "push"
+	// (pulls never overwrite the stored value).
+	Direction string
+	// MergeStrategy is how a pull merges into the stored value: "overwrite"
+	// (the default), "prefer-local", "append", or "longest".
+	MergeStrategy string
+}
+
+// GetFieldSyncRules returns the sync direction of every mapped field of an
+// object type, such as "Account", ordered by field name.
+func GetFieldSyncRules(db DB, objectType string) ([]FieldSyncRule, error) {
+	sqlText := db.GetSQL("GetFieldSyncDirections")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetFieldSyncDirections")
+	}
+	rows, err := db.GetDB().Query(sqlText, objectType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rules []FieldSyncRule
+	for rows.Next() {
+		var rule FieldSyncRule
+		var jsonField, label, direction, strategy sql.NullString
+		if err := rows.Scan(&rule.FieldName, &jsonField, &label, &direction, &strategy); err != nil {
+			return nil, err
+		}
+		rule.JsonField = jsonField.String
+		rule.Label = label.String
+		rule.Direction = direction.String
+		if rule.Direction == "" {
+			rule.Direction = "both"
+		}
+		rule.MergeStrategy = strategy.String
+		if rule.MergeStrategy == "" {
+			rule.MergeStrategy = "overwrite"
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// SetFieldSyncDirection changes the sync direction of a FieldMaps entry.
+func SetFieldSyncDirection(db DB, objectType, fieldName, direction string) error {
+	sqlText := db.GetSQL("UpdateFieldSyncDirection")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: UpdateFieldSyncDirection")
+	}
+	result, err := db.GetDB().Exec(sqlText, direction, fieldName, objectType)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no %s field named %s in FieldMaps", objectType, fieldName)
+	}
+	return nil
+}
+
+// SetFieldMergeStrategy changes the merge strategy of a FieldMaps entry.
+func SetFieldMergeStrategy(db DB, objectType, fieldName, strategy string) error {
+	sqlText := db.GetSQL("UpdateFieldMergeStrategy")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: UpdateFieldMergeStrategy")
+	}
+	result, err := db.GetDB().Exec(sqlText, strategy, fieldName, objectType)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no %s field named %s in FieldMaps", objectType, fieldName)
+	}
+	return nil
+}
+
+// FieldMapDataSet is the profile data set stored in an Accounts column.
+type FieldMapDataSet struct {
+	Name  string
+	Label string
+}
+
+// SetFieldMapDataSets records which data set each Accounts column holds,
+// keyed by column, and clears the data set of every other Account field.
+// The fields are replaced in one transaction.
+func SetFieldMapDataSets(db DB, dataSets map[string]FieldMapDataSet) error {
+	clearSQL := db.GetSQL("ClearFieldMapDataSets")
+	updateSQL := db.GetSQL("UpdateFieldMapDataSet")
+	if clearSQL == "" || updateSQL == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: UpdateFieldMapDataSet")
+	}
+	return Work(db, "store.field_maps", func(u *UnitOfWork) error {
+		if _, err := u.ExecSQL(clearSQL); err != nil {
+			return err
+		}
+		for column, dataSet := range dataSets {
+			if _, err := u.ExecSQL(updateSQL, dataSet.Name, dataSet.Label, column); err != nil {
+				return fmt.Errorf("failed to map data set %s to %s: %w", dataSet.Name, column, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// EarthRadiusKm is the mean Earth radius the distance calculations use.
+const EarthRadiusKm = 6371.0
+
+// GeoPoint is a latitude and longitude in degrees.
+type GeoPoint struct {
+	Lat float64
+	Lon float64
+}
+
+// Valid reports whether p is a coordinate on Earth.
+func (p GeoPoint) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+func (p GeoPoint) String() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lon, 'f', -1, 64)
+}
+
+// DistanceKm returns the great-circle distance between a and b with the
+// haversine formula, the same one AccountsWithinRadius uses.
+func DistanceKm(a, b GeoPoint) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// AccountsWithinRadiusSQL returns a subquery selecting the AccountId of every
+// stored location within km of center, for use as `AccountId IN (...)`. The
+// values are written into the SQL as numbers, so it can be combined with
+// other literal filters.
+func AccountsWithinRadiusSQL(db DB, center GeoPoint, km float64) (string, error) {
+	template := db.GetSQL("AccountsWithinRadius")
+	if template == "" {
+		return "", fmt.Errorf("unknown or unavailable SQL command: AccountsWithinRadius")
+	}
+	if !center.Valid() {
+		return "", fmt.Errorf("invalid coordinates %s", center)
+	}
+	if km <= 0 || math.IsInf(km, 0) || math.IsNaN(km) {
+		return "", fmt.Errorf("radius must be greater than zero")
+	}
+	lat := center.Lat * math.Pi / 180
+	lon := center.Lon * math.Pi / 180
+	return fmt.Sprintf(template, sqlFloat(lat), sqlFloat(math.Cos(lat)), sqlFloat(lon), sqlFloat(km)), nil
+}
+
+// AccountIDsWithinRadius returns the accounts with a stored location within
+// km of center.
+func AccountIDsWithinRadius(db DB, center GeoPoint, km float64) ([]int, error) {
+	query, err := AccountsWithinRadiusSQL(db, center, km)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.GetDB().Query(query + " ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// FindLocationsByAddress returns the coordinates of stored locations whose
+// full address or street contains address, or whose zip code or city is
+// address. Matching ignores case.
+func FindLocationsByAddress(db DB, address string) ([]GeoPoint, error) {
+	sqlText := db.GetSQL("FindLocationsByAddress")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: FindLocationsByAddress")
+	}
+	term := strings.ToLower(strings.TrimSpace(address))
+	if term == "" {
+		return nil, nil
+	}
+	like := "%" + term + "%"
+	rows, err := db.GetDB().Query(sqlText, like, like, term, term)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var points []GeoPoint
+	for rows.Next() {
+		var p GeoPoint
+		if err := rows.Scan(&p.Lat, &p.Lon); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// sqlFloat formats v as a decimal SQL literal. The trailing ".0" keeps SQL
+// Server from treating whole numbers as integers.
+func sqlFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// sqliteDriverName is the SQLite driver connections are opened with. It adds
+// the math functions AccountsWithinRadius needs, which go-sqlite3 only
+// compiles in with the sqlite_math_functions build tag.
+const sqliteDriverName = "sqlite3_badgermaps"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{ConnectHook: registerSQLiteMathFunctions})
+}
+
+func registerSQLiteMathFunctions(conn *sqlite3.SQLiteConn) error {
+	functions := map[string]func(float64) float64{
+		"radians": func(v float64) float64 { return v * math.Pi / 180 },
+		"sin":     math.Sin,
+		"cos":     math.Cos,
+		"asin":    math.Asin,
+		"sqrt":    math.Sqrt,
+	}
+	for name, fn := range functions {
+		fn := fn
+		// Arguments arrive as whatever type SQLite holds, so integers and
+		// NULLs are handled here rather than rejected by the driver.
+		err := conn.RegisterFunc(name, func(v interface{}) interface{} {
+			switch n := v.(type) {
+			case float64:
+				return fn(n)
+			case int64:
+				return fn(float64(n))
+			}
+			return nil
+		}, true)
+		if err != nil {
+			return fmt.Errorf("failed to register SQLite function %s: %w", name, err)
+		}
+	}
+	return nil
+}
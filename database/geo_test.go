@@ -0,0 +1,61 @@
+package database
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAccountIDsWithinRadius(t *testing.T) {
+	db := newBackupTestDB(t, "geo.db")
+	sqlDB := db.GetDB()
+	stmts := []string{
+		`INSERT INTO Accounts (AccountId, FullName) VALUES (1, 'Denver'), (2, 'Boulder'), (3, 'Colorado Springs'), (4, 'Unmapped'), (5, 'Whole Degrees')`,
+		`INSERT INTO AccountLocations (AccountId, City, Zipcode, Location, Latitude, Longitude) VALUES
+			(1, 'Denver', '80202', '1600 Glenarm Pl, Denver, CO 80202', 39.7439, -104.9907),
+			(2, 'Boulder', '80302', '1777 Broadway, Boulder, CO 80302', 40.0150, -105.2705),
+			(3, 'Colorado Springs', '80903', '30 S Nevada Ave, Colorado Springs, CO 80903', 38.8339, -104.8214),
+			(5, 'Denver', '80204', NULL, 40, -105)`,
+		`INSERT INTO AccountLocations (AccountId, City) VALUES (4, 'Denver')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+
+	denver := GeoPoint{Lat: 39.7392, Lon: -104.9903}
+	if d := DistanceKm(denver, GeoPoint{Lat: 40.0150, Lon: -105.2705}); math.Abs(d-38.9) > 0.5 {
+		t.Fatalf("DistanceKm(Denver, Boulder) = %.1f, want about 38.9", d)
+	}
+
+	ids, err := AccountIDsWithinRadius(db, denver, 50)
+	if err != nil {
+		t.Fatalf("AccountIDsWithinRadius: %v", err)
+	}
+	if want := []int{1, 2, 5}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("within 50 km = %v, want %v", ids, want)
+	}
+	ids, err = AccountIDsWithinRadius(db, denver, 5)
+	if err != nil {
+		t.Fatalf("AccountIDsWithinRadius: %v", err)
+	}
+	if want := []int{1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("within 5 km = %v, want %v", ids, want)
+	}
+	if _, err := AccountIDsWithinRadius(db, GeoPoint{Lat: 91}, 5); err == nil {
+		t.Error("expected an error for an invalid latitude")
+	}
+
+	points, err := FindLocationsByAddress(db, "DENVER")
+	if err != nil {
+		t.Fatalf("FindLocationsByAddress: %v", err)
+	}
+	if len(points) != 2 {
+		t.Errorf("FindLocationsByAddress(DENVER) = %v, want the two mapped Denver locations", points)
+	}
+	points, err = FindLocationsByAddress(db, "80302")
+	if err != nil || len(points) != 1 || points[0].Lat != 40.0150 {
+		t.Errorf("FindLocationsByAddress(80302) = %v, %v", points, err)
+	}
+}
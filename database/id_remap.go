@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Reasons recorded in IdRemap for why an ID was replaced.
+const (
+	// RemapReasonCrmId means another account with the same CRM ID is the
+	// one BadgerMaps still lists.
+	RemapReasonCrmId = "crm_id"
+	// RemapReasonRedirect means the API answered for the old ID with a
+	// different account.
+	RemapReasonRedirect = "redirect"
+	// RemapReasonGone means the API reported the old ID as gone and a local
+	// account with the same CRM ID took its place.
+	RemapReasonGone = "gone"
+)
+
+// IdRemap records that a local ID was replaced by the ID BadgerMaps merged
+// it into.
+type IdRemap struct {
+	RemapId    int
+	EntityType string
+	OldId      int
+	NewId      int
+	Reason     string
+	// RowsRewritten counts the check-ins, waypoints, and staged changes
+	// moved to the new ID.
+	RowsRewritten int64
+	RemappedAt    time.Time
+}
+
+// RemapAccountId moves everything that refers to account oldId over to
+// newId, removes the stale account and its locations, and records the
+// mapping in IdRemap, all in one transaction. Staged deletes of the old
+// account are left alone so they cannot delete the surviving account.
+func RemapAccountId(db DB, oldId, newId int, reason string, remappedAt time.Time) (IdRemap, error) {
+	remap := IdRemap{EntityType: "account", OldId: oldId, NewId: newId, Reason: reason, RemappedAt: remappedAt}
+	if db == nil || db.GetDB() == nil {
+		return remap, fmt.Errorf("database connection is not initialized")
+	}
+	if oldId == newId {
+		return remap, fmt.Errorf("account %d cannot be remapped to itself", oldId)
+	}
+
+	err := Work(db, "remap.account", func(u *UnitOfWork) error {
+		remap.RowsRewritten = 0
+		for _, name := range []string{"RemapCheckinAccountIds", "RemapWaypointAccountIds", "RemapPendingAccountChanges", "RemapPendingCheckinChanges"} {
+			n, err := u.Exec(name, newId, oldId)
+			if err != nil {
+				return err
+			}
+			remap.RowsRewritten += n
+		}
+		if _, err := u.Exec("DeleteAccountLocations", oldId); err != nil {
+			return err
+		}
+		if _, err := u.Exec("DeleteMergedAccount", oldId); err != nil {
+			return err
+		}
+		_, err := u.Exec("InsertIdRemap", remap.EntityType, oldId, newId, reason, remap.RowsRewritten, remappedAt.UTC())
+		return err
+	})
+	if err != nil {
+		return remap, fmt.Errorf("failed to remap account %d: %w", oldId, err)
+	}
+	return remap, nil
+}
+
+// GetIdRemaps returns every recorded remap, newest first.
+func GetIdRemaps(db DB) ([]IdRemap, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetIdRemaps")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetIdRemaps")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var remaps []IdRemap
+	for rows.Next() {
+		var remap IdRemap
+		var reason sql.NullString
+		var remappedAt any
+		if err := rows.Scan(&remap.RemapId, &remap.EntityType, &remap.OldId, &remap.NewId, &reason, &remap.RowsRewritten, &remappedAt); err != nil {
+			return nil, err
+		}
+		remap.Reason = reason.String
+		remap.RemappedAt = normaliseToTime(remappedAt)
+		remaps = append(remaps, remap)
+	}
+	return remaps, rows.Err()
+}
+
+// GetAccountIdsByCrmId returns the local account IDs sharing each CRM ID,
+// ignoring accounts without one. CRM IDs are compared without surrounding
+// whitespace.
+func GetAccountIdsByCrmId(db DB) (map[string][]int, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetAccountCrmIds")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountCrmIds")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCrmId := make(map[string][]int)
+	for rows.Next() {
+		var id int
+		var crmId string
+		if err := rows.Scan(&id, &crmId); err != nil {
+			return nil, err
+		}
+		crmId = strings.TrimSpace(crmId)
+		byCrmId[crmId] = append(byCrmId[crmId], id)
+	}
+	return byCrmId, rows.Err()
+}
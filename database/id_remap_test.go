@@ -0,0 +1,86 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemapAccountId(t *testing.T) {
+	db := newBackupTestDB(t, "remap.db")
+	sqlDB := db.GetDB()
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, FullName, CrmId) VALUES (1, 'Acme (old)', 'CRM-1'), (2, 'Acme', ' CRM-1 '), (3, 'Globex', '')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, Comments) VALUES (10, 1, 'first'), (11, 1, 'second'), (12, 3, 'other')`,
+		`INSERT INTO AccountLocations (AccountId, City) VALUES (1, 'Old Town'), (2, 'New Town')`,
+		`INSERT INTO Routes (RouteId, Name) VALUES (5, 'Monday')`,
+		`INSERT INTO RouteWaypoints (WaypointId, RouteId, CustomerId) VALUES (20, 5, 1)`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, Status) VALUES (1, 'UPDATE', '{}', 'pending'), (1, 'DELETE', '{}', 'pending'), (1, 'UPDATE', '{}', 'completed')`,
+		`INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, ChangeType, Status) VALUES (30, 1, 'CREATE', 'pending')`,
+	}
+	for _, stmt := range seed {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+
+	byCrmId, err := GetAccountIdsByCrmId(db)
+	if err != nil {
+		t.Fatalf("GetAccountIdsByCrmId: %v", err)
+	}
+	if ids := byCrmId["CRM-1"]; len(byCrmId) != 1 || len(ids) != 2 {
+		t.Fatalf("accounts by CRM ID = %v, want both Acme rows under CRM-1", byCrmId)
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	remap, err := RemapAccountId(db, 1, 2, RemapReasonCrmId, at)
+	if err != nil {
+		t.Fatalf("RemapAccountId: %v", err)
+	}
+	// Two check-ins, one waypoint, one staged update, one staged check-in.
+	if remap.RowsRewritten != 5 {
+		t.Errorf("rows rewritten = %d, want 5", remap.RowsRewritten)
+	}
+
+	count := func(query string) int {
+		t.Helper()
+		var n int
+		if err := sqlDB.QueryRow(query).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return n
+	}
+	checks := []struct {
+		query string
+		want  int
+	}{
+		{`SELECT COUNT(*) FROM Accounts WHERE AccountId = 1`, 0},
+		{`SELECT COUNT(*) FROM AccountLocations WHERE AccountId = 1`, 0},
+		{`SELECT COUNT(*) FROM AccountCheckins WHERE AccountId = 2`, 2},
+		{`SELECT COUNT(*) FROM RouteWaypoints WHERE CustomerId = 2`, 1},
+		{`SELECT COUNT(*) FROM AccountsPendingChanges WHERE AccountId = 2`, 1},
+		// The staged delete and the completed change keep the old ID.
+		{`SELECT COUNT(*) FROM AccountsPendingChanges WHERE AccountId = 1`, 2},
+		{`SELECT COUNT(*) FROM AccountCheckinsPendingChanges WHERE AccountId = 2`, 1},
+	}
+	for _, check := range checks {
+		if got := count(check.query); got != check.want {
+			t.Errorf("%s = %d, want %d", check.query, got, check.want)
+		}
+	}
+
+	remaps, err := GetIdRemaps(db)
+	if err != nil {
+		t.Fatalf("GetIdRemaps: %v", err)
+	}
+	if len(remaps) != 1 {
+		t.Fatalf("remaps = %+v, want one", remaps)
+	}
+	got := remaps[0]
+	if got.EntityType != "account" || got.OldId != 1 || got.NewId != 2 || got.Reason != RemapReasonCrmId || got.RowsRewritten != 5 || !got.RemappedAt.Equal(at) {
+		t.Errorf("remap = %+v", got)
+	}
+
+	if _, err := RemapAccountId(db, 2, 2, RemapReasonCrmId, at); err == nil {
+		t.Error("remapping an account to itself succeeded")
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// NewIdempotencyKey returns a random key for a staged change.
+func NewIdempotencyKey() string {
+	return uuid.NewString()
+}
+
+// AccountChangeHash hashes what an account change would send, so a key can
+// be matched to the content it was issued for. Field order in changes does
+// not matter.
+func AccountChangeHash(accountID int, changeType, changes string) string {
+	canonical := changes
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(changes), &fields); err == nil {
+		if data, err := json.Marshal(fields); err == nil {
+			canonical = string(data)
+		}
+	}
+	return contentHash(strconv.Itoa(accountID), changeType, canonical)
+}
+
+// CheckinChangeHash hashes what a check-in change would send.
+func CheckinChangeHash(change CheckinPendingChange) string {
+	return contentHash(
+		strconv.Itoa(change.CheckinId), strconv.Itoa(change.AccountId), change.ChangeType,
+		change.Type.String, change.Comments.String, change.LogDatetime.String, change.CrmId.String,
+		change.CreatedBy.String, change.EndpointType.String, change.ExtraFields.String,
+	)
+}
+
+func contentHash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyedChange is a staged change found by its idempotency key.
+type KeyedChange struct {
+	ChangeId    int
+	Status      string
+	ContentHash string
+}
+
+// GetChangesByIdempotencyKey returns the changes in table staged under key,
+// oldest first.
+func GetChangesByIdempotencyKey(db DB, table, key string) ([]KeyedChange, error) {
+	sqlText := db.GetSQL("GetChangesByIdempotencyKey")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetChangesByIdempotencyKey")
+	}
+	rows, err := db.GetDB().Query(fmt.Sprintf(sqlText, table), key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []KeyedChange
+	for rows.Next() {
+		var change KeyedChange
+		var hash sql.NullString
+		if err := rows.Scan(&change.ChangeId, &change.Status, &hash); err != nil {
+			return nil, err
+		}
+		change.ContentHash = hash.String
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// SetIdempotencyKey replaces the key of a staged change and the hash of the
+// content it was issued for.
+func SetIdempotencyKey(db DB, table string, changeId int, key, hash string) error {
+	sqlText := db.GetSQL("UpdateChangeIdempotencyKey")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: UpdateChangeIdempotencyKey")
+	}
+	_, err := db.GetDB().Exec(fmt.Sprintf(sqlText, table), key, hash, changeId)
+	return err
+}
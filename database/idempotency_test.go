@@ -0,0 +1,27 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestChangeHashes(t *testing.T) {
+	if AccountChangeHash(1, "UPDATE", `{"a":"1","b":"2"}`) != AccountChangeHash(1, "UPDATE", `{"b":"2","a":"1"}`) {
+		t.Error("field order changed the account change hash")
+	}
+	if AccountChangeHash(1, "UPDATE", `{"a":"1"}`) == AccountChangeHash(2, "UPDATE", `{"a":"1"}`) {
+		t.Error("the account change hash ignores the account")
+	}
+
+	checkin := CheckinPendingChange{AccountId: 1, ChangeType: "CREATE", Type: sql.NullString{String: "Call", Valid: true}}
+	edited := checkin
+	edited.Comments = sql.NullString{String: "later", Valid: true}
+	if CheckinChangeHash(checkin) == CheckinChangeHash(edited) {
+		t.Error("editing the comments kept the check-in change hash")
+	}
+	keyed := checkin
+	keyed.IdempotencyKey = sql.NullString{String: "k", Valid: true}
+	if CheckinChangeHash(checkin) != CheckinChangeHash(keyed) {
+		t.Error("the key is part of the check-in change hash")
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import "fmt"
+
+// OrphanedRow is a child row whose parent account or route is missing,
+// typically left behind by an interrupted pull.
+type OrphanedRow struct {
+	Table    string
+	ChildId  int
+	ParentId int
+}
+
+// orphanDeleteCommands maps each checked child table to the SQL command that
+// removes its orphans.
+var orphanDeleteCommands = map[string]string{
+	"AccountLocations": "DeleteOrphanedAccountLocations",
+	"AccountCheckins":  "DeleteOrphanedAccountCheckins",
+	"RouteWaypoints":   "DeleteOrphanedRouteWaypoints",
+}
+
+// OrphanParentTable returns the parent table a checked child table references.
+func OrphanParentTable(table string) string {
+	if table == "RouteWaypoints" {
+		return "Routes"
+	}
+	return "Accounts"
+}
+
+// GetOrphanedRows returns every location, check-in, and waypoint whose parent
+// row does not exist.
+func GetOrphanedRows(db DB) ([]OrphanedRow, error) {
+	sqlText := db.GetSQL("GetOrphanedRows")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetOrphanedRows")
+	}
+
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []OrphanedRow
+	for rows.Next() {
+		var o OrphanedRow
+		if err := rows.Scan(&o.Table, &o.ChildId, &o.ParentId); err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, rows.Err()
+}
+
+// DeleteOrphanedRows removes the orphans in table and returns how many rows
+// were deleted.
+func DeleteOrphanedRows(db DB, table string) (int64, error) {
+	command, ok := orphanDeleteCommands[table]
+	if !ok {
+		return 0, fmt.Errorf("orphan repair is not supported for table %s", table)
+	}
+	sqlText := db.GetSQL(command)
+	if sqlText == "" {
+		return 0, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	result, err := db.GetDB().Exec(sqlText)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableCopy is one table copied by CopyDatabase, with the row count and
+// checksum read back from the source and the target.
+type TableCopy struct {
+	Table          string
+	SourceRows     int
+	TargetRows     int
+	SourceChecksum string
+	TargetChecksum string
+}
+
+// Verified reports whether the target holds the same rows as the source.
+func (c TableCopy) Verified() bool {
+	return c.SourceRows == c.TargetRows && c.SourceChecksum == c.TargetChecksum
+}
+
+// CopyObserver follows a CopyDatabase run. StartTable is called with the
+// number of rows about to be copied, CopiedRow after each row, and
+// FinishTable once the table is done.
+type CopyObserver interface {
+	StartTable(table string, rows int)
+	CopiedRow()
+	FinishTable()
+}
+
+// CopyTables returns the tables CopyDatabase copies, parents before
+// children: those of a backup plus the marks and scores that would
+// otherwise only come back with the next pull. SyncLocks is left out since
+// a lock belongs to the database it was taken in.
+func CopyTables() []string {
+	return append(append([]string(nil), backupTables...), "StaleAccounts", "AccountScores")
+}
+
+// CopyDatabase copies the rows of every table in CopyTables from src into
+// dst, whose schema must already be initialized. The tables in dst are
+// emptied first, and the whole copy runs in one transaction on dst, so a
+// failure leaves it as it was. Rows are streamed; neither side is held in
+// memory. Tables src does not have yet are skipped. observer may be nil.
+func CopyDatabase(src, dst DB, observer CopyObserver) ([]string, error) {
+	if src == nil || !src.IsConnected() || dst == nil || !dst.IsConnected() {
+		return nil, fmt.Errorf("both databases must be connected")
+	}
+	tables := CopyTables()
+	var copied []string
+	targets := make(map[string][]string)
+	for _, table := range tables {
+		exists, err := dst.TableExists(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check table %s in the target: %w", table, err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("table %s does not exist in the target; initialize its schema first", table)
+		}
+		columns, err := dst.GetTableColumns(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns for %s in the target: %w", table, err)
+		}
+		targets[table] = columns
+
+		exists, err = src.TableExists(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check table %s in the source: %w", table, err)
+		}
+		if exists {
+			copied = append(copied, table)
+		}
+	}
+
+	tx, err := dst.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	restorer := &tableRestorer{db: dst, dbType: dst.GetType(), tx: tx, targets: targets}
+	if err := restorer.clearTables(tables); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, table := range copied {
+		if err := copyTable(src, restorer, table, observer); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to copy %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+func copyTable(src DB, restorer *tableRestorer, table string, observer CopyObserver) error {
+	if observer != nil {
+		rows, err := CountRows(src, table)
+		if err != nil {
+			return err
+		}
+		observer.StartTable(table, rows)
+		defer observer.FinishTable()
+	}
+	err := readTable(src, table, restorer.begin, func(row []interface{}) error {
+		if err := restorer.insert(row); err != nil {
+			return err
+		}
+		if observer != nil {
+			observer.CopiedRow()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return restorer.finish()
+}
+
+// CountRows returns the number of rows in table.
+func CountRows(db DB, table string) (int, error) {
+	var count int
+	if err := db.GetDB().QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", QualifiedName(db, table))).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows in %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// VerifyCopy counts and checksums each table in src and dst. Only the
+// columns both have are compared, since the copy leaves out the others.
+func VerifyCopy(src, dst DB, tables []string) ([]TableCopy, error) {
+	results := make([]TableCopy, 0, len(tables))
+	for _, table := range tables {
+		columns, err := sharedColumns(src, dst, table)
+		if err != nil {
+			return results, err
+		}
+		result := TableCopy{Table: table}
+		if result.SourceRows, result.SourceChecksum, err = tableChecksum(src, table, columns); err != nil {
+			return results, fmt.Errorf("failed to checksum %s in the source: %w", table, err)
+		}
+		if result.TargetRows, result.TargetChecksum, err = tableChecksum(dst, table, columns); err != nil {
+			return results, fmt.Errorf("failed to checksum %s in the target: %w", table, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// sharedColumns returns the lower-cased columns of table in both databases.
+func sharedColumns(src, dst DB, table string) (map[string]bool, error) {
+	srcColumns, err := src.GetTableColumns(table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns for %s in the source: %w", table, err)
+	}
+	dstColumns, err := dst.GetTableColumns(table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns for %s in the target: %w", table, err)
+	}
+	inTarget := make(map[string]bool, len(dstColumns))
+	for _, column := range dstColumns {
+		inTarget[strings.ToLower(column)] = true
+	}
+	shared := make(map[string]bool, len(srcColumns))
+	for _, column := range srcColumns {
+		if name := strings.ToLower(column); inTarget[name] {
+			shared[name] = true
+		}
+	}
+	return shared, nil
+}
+
+// tableChecksum returns the number of rows in table and a checksum of the
+// given columns that does not depend on row or column order or on the
+// database type. Values are compared as the drivers return them, normalized
+// where the types differ: times in UTC to the microsecond, booleans as 0
+// and 1, and fractions at single precision, since PostgreSQL's REAL is.
+func tableChecksum(db DB, table string, columns map[string]bool) (int, string, error) {
+	var count int
+	var sum uint64
+	var order []int
+	var names []string
+	err := readTable(db, table, func(header backupRecord) error {
+		names = make([]string, len(header.Columns))
+		for i, column := range header.Columns {
+			names[i] = strings.ToLower(column)
+			if columns[names[i]] {
+				order = append(order, i)
+			}
+		}
+		sort.Slice(order, func(a, b int) bool { return names[order[a]] < names[order[b]] })
+		return nil
+	}, func(row []interface{}) error {
+		h := sha256.New()
+		for _, i := range order {
+			fmt.Fprintf(h, "%s=%s\x00", names[i], checksumValue(row[i]))
+		}
+		digest := h.Sum(nil)
+		// Summing the row hashes makes the checksum independent of the
+		// order the rows come back in.
+		sum += binary.BigEndian.Uint64(digest[:8])
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], sum)
+	return count, hex.EncodeToString(buf[:]), nil
+}
+
+func checksumValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "\x01null"
+	case time.Time:
+		return val.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(float64(float32(val)), 'g', -1, 32)
+	case float32:
+		return checksumValue(float64(val))
+	case string:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
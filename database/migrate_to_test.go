@@ -0,0 +1,120 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	tables []string
+	rows   int
+}
+
+func (o *recordingObserver) StartTable(table string, rows int) { o.tables = append(o.tables, table) }
+func (o *recordingObserver) CopiedRow()                        { o.rows++ }
+func (o *recordingObserver) FinishTable()                      {}
+
+func TestCopyDatabase(t *testing.T) {
+	source := newBackupTestDB(t, "source.db")
+	if _, err := source.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName, UpdatedAt) VALUES (1, 'Acme', '2024-01-01 00:00:00'), (2, 'Globex', '2024-01-02 00:00:00')"); err != nil {
+		t.Fatalf("seed accounts: %v", err)
+	}
+	if err := StageAccountChange(source, 1, "UPDATE", `{"notes":"x"}`); err != nil {
+		t.Fatalf("stage change: %v", err)
+	}
+
+	target := newBackupTestDB(t, "target.db")
+	if _, err := target.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (9, 'Initech')"); err != nil {
+		t.Fatalf("seed target: %v", err)
+	}
+
+	observer := &recordingObserver{}
+	tables, err := CopyDatabase(source, target, observer)
+	if err != nil {
+		t.Fatalf("CopyDatabase: %v", err)
+	}
+	if len(observer.tables) != len(tables) {
+		t.Fatalf("expected a StartTable per copied table, got %v for %v", observer.tables, tables)
+	}
+	want := 0
+	for _, table := range tables {
+		rows, err := CountRows(source, table)
+		if err != nil {
+			t.Fatalf("CountRows: %v", err)
+		}
+		want += rows
+	}
+	if observer.rows != want {
+		t.Fatalf("expected %d rows copied, got %d", want, observer.rows)
+	}
+	if got := countAccounts(t, target); got != 2 {
+		t.Fatalf("expected the target's accounts replaced by the 2 copied, got %d", got)
+	}
+
+	results, err := VerifyCopy(source, target, tables)
+	if err != nil {
+		t.Fatalf("VerifyCopy: %v", err)
+	}
+	for _, result := range results {
+		if !result.Verified() {
+			t.Fatalf("expected %s to verify, got %+v", result.Table, result)
+		}
+	}
+
+	if _, err := target.GetDB().Exec("UPDATE Accounts SET FullName = 'Acme Corp' WHERE AccountId = 1"); err != nil {
+		t.Fatalf("update target: %v", err)
+	}
+	results, err = VerifyCopy(source, target, []string{"Accounts"})
+	if err != nil {
+		t.Fatalf("VerifyCopy: %v", err)
+	}
+	if results[0].Verified() || results[0].SourceRows != results[0].TargetRows {
+		t.Fatalf("expected matching counts with a differing checksum, got %+v", results[0])
+	}
+}
+
+func TestTableChecksumIgnoresRowOrder(t *testing.T) {
+	first := newBackupTestDB(t, "first.db")
+	second := newBackupTestDB(t, "second.db")
+	if _, err := first.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (1, 'Acme'), (2, 'Globex')"); err != nil {
+		t.Fatalf("seed first: %v", err)
+	}
+	if _, err := second.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName) VALUES (2, 'Globex'), (1, 'Acme')"); err != nil {
+		t.Fatalf("seed second: %v", err)
+	}
+	columns, err := sharedColumns(first, second, "Accounts")
+	if err != nil {
+		t.Fatalf("sharedColumns: %v", err)
+	}
+	_, a, err := tableChecksum(first, "Accounts", columns)
+	if err != nil {
+		t.Fatalf("tableChecksum: %v", err)
+	}
+	_, b, err := tableChecksum(second, "Accounts", columns)
+	if err != nil {
+		t.Fatalf("tableChecksum: %v", err)
+	}
+	if a != b {
+		t.Fatalf("expected equal checksums regardless of row order, got %s and %s", a, b)
+	}
+}
+
+func TestChecksumValueNormalizesDriverTypes(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 6789, time.FixedZone("", 3600))
+	tests := []struct {
+		a, b interface{}
+	}{
+		{true, int64(1)},
+		{float64(3), int64(3)},
+		{float64(1.1), float32(1.1)},
+		{when, when.UTC().Truncate(time.Microsecond)},
+	}
+	for _, tt := range tests {
+		if checksumValue(tt.a) != checksumValue(tt.b) {
+			t.Errorf("expected %#v and %#v to checksum alike, got %q and %q", tt.a, tt.b, checksumValue(tt.a), checksumValue(tt.b))
+		}
+	}
+	if checksumValue(nil) == checksumValue("") {
+		t.Error("expected NULL and an empty string to differ")
+	}
+}
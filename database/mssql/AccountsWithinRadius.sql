@@ -0,0 +1,7 @@
+SELECT l.AccountId FROM AccountLocations l
+CROSS APPLY (SELECT SQRT(
+    SIN((RADIANS(l.Latitude) - %[1]s) / 2) * SIN((RADIANS(l.Latitude) - %[1]s) / 2)
+    + %[2]s * COS(RADIANS(l.Latitude)) * SIN((RADIANS(l.Longitude) - %[3]s) / 2) * SIN((RADIANS(l.Longitude) - %[3]s) / 2)
+  ) AS H) h
+WHERE l.Latitude IS NOT NULL AND l.Longitude IS NOT NULL
+  AND 2 * 6371.0 * ASIN(CASE WHEN h.H > 1 THEN 1 ELSE h.H END) <= %[4]s
\ No newline at end of file
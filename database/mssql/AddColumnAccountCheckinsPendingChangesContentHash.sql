@@ -0,0 +1 @@
+ALTER TABLE AccountCheckinsPendingChanges ADD ContentHash NVARCHAR(64);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountCheckinsPendingChanges ADD IdempotencyKey NVARCHAR(64);
\ No newline at end of file
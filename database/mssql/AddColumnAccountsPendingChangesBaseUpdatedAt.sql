@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD BaseUpdatedAt DATETIME2;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD ContentHash NVARCHAR(64);
\ No newline at end of file
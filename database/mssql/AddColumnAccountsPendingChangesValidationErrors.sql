@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD ValidationErrors NVARCHAR(MAX);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE CommandLog ADD DurationMs INT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE CommandLog ADD Flags NVARCHAR(MAX);
\ No newline at end of file
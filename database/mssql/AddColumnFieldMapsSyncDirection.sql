@@ -0,0 +1 @@
+ALTER TABLE FieldMaps ADD SyncDirection NVARCHAR(16) NOT NULL DEFAULT 'both' CHECK(SyncDirection IN ('both', 'pull', 'push'));
\ No newline at end of file
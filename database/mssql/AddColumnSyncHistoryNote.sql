@@ -0,0 +1 @@
+ALTER TABLE SyncHistory ADD Note NVARCHAR(MAX);
\ No newline at end of file
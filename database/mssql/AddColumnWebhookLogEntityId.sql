@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD EntityId BIGINT;
\ No newline at end of file
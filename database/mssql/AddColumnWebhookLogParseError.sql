@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD ParseError NVARCHAR(MAX);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD ParseStatus NVARCHAR(16);
\ No newline at end of file
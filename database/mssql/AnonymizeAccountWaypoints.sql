@@ -0,0 +1,3 @@
+UPDATE RouteWaypoints SET Name = ?, Address = NULL, Suite = NULL, City = NULL, State = NULL, Zipcode = NULL,
+    Location = NULL, Latitude = NULL, Longitude = NULL, CompleteAddress = NULL, PlaceId = NULL
+WHERE CustomerId = ?;
\ No newline at end of file
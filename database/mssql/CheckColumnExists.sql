@@ -1,3 +1,3 @@
 SELECT COUNT(*) 
-FROM INFORMATION_SCHEMA.COLUMNS 
-WHERE TABLE_NAME = ? AND COLUMN_NAME = ? 
\ No newline at end of file
+FROM sys.columns 
+WHERE object_id = OBJECT_ID(?) AND name = ? 
\ No newline at end of file
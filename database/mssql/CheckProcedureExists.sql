@@ -1 +1 @@
-SELECT count(*) FROM sys.procedures WHERE name = ?;
+SELECT count(*) FROM sys.procedures WHERE object_id = OBJECT_ID(?);
\ No newline at end of file
@@ -1,4 +1 @@
- SELECT COUNT(*)                 
- FROM INFORMATION_SCHEMA.TABLES  
- WHERE TABLE_NAME = ?            
-
+SELECT COUNT(*) FROM sys.tables WHERE object_id = OBJECT_ID(?);
\ No newline at end of file
@@ -1 +1 @@
-SELECT count(*) FROM sys.triggers WHERE name = ?;
+SELECT count(*) FROM sys.triggers WHERE object_id = OBJECT_ID(?);
\ No newline at end of file
@@ -1 +1 @@
-SELECT count(*) FROM sys.views WHERE name = ?;
+SELECT count(*) FROM sys.views WHERE object_id = OBJECT_ID(?);
\ No newline at end of file
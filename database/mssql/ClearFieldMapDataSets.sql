@@ -0,0 +1 @@
+UPDATE FieldMaps SET DataSetName = NULL, DataSetLabel = NULL WHERE ObjectType = 'Account';
\ No newline at end of file
@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AccountCheckinsPendingChanges' AND xtype='U')
+IF OBJECT_ID('AccountCheckinsPendingChanges', 'U') IS NULL
 CREATE TABLE AccountCheckinsPendingChanges (
     ChangeId INT IDENTITY(1,1) PRIMARY KEY,
     CheckinId INT NOT NULL,
@@ -13,5 +13,7 @@ CREATE TABLE AccountCheckinsPendingChanges (
     ChangeType NVARCHAR(10) NOT NULL CHECK(ChangeType IN ('CREATE', 'UPDATE', 'DELETE')),
     Status NVARCHAR(10) NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
     CreatedAt DATETIME2 DEFAULT GETDATE(),
-    ProcessedAt DATETIME2
+    ProcessedAt DATETIME2,
+    IdempotencyKey NVARCHAR(64),
+    ContentHash NVARCHAR(64)
 );
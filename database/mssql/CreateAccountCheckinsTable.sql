@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AccountCheckins' AND xtype='U')
+IF OBJECT_ID('AccountCheckins', 'U') IS NULL
 CREATE TABLE AccountCheckins (
     CheckinId INT IDENTITY(1,1) PRIMARY KEY,
     CrmId NVARCHAR(255),
@@ -0,0 +1,10 @@
+IF OBJECT_ID('AccountErasures', 'U') IS NULL
+CREATE TABLE AccountErasures (
+    ErasureId INT IDENTITY(1,1) PRIMARY KEY,
+    AccountId INT NOT NULL,
+    Mode NVARCHAR(20) NOT NULL CHECK(Mode IN ('delete', 'anonymize')),
+    ReportId NVARCHAR(64) NOT NULL,
+    ReportDigest NVARCHAR(64) NOT NULL,
+    DeleteChangeId INT,
+    ErasedAt DATETIME2 NOT NULL
+);
\ No newline at end of file
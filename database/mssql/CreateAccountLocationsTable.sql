@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AccountLocations' AND xtype='U')
+IF OBJECT_ID('AccountLocations', 'U') IS NULL
 CREATE TABLE AccountLocations (
     LocationId INT IDENTITY(1,1) PRIMARY KEY,
     AccountId INT,
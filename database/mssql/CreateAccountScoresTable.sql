@@ -0,0 +1,8 @@
+IF OBJECT_ID('AccountScores', 'U') IS NULL
+CREATE TABLE AccountScores (
+    AccountId INT NOT NULL,
+    ScoreName NVARCHAR(100) NOT NULL,
+    Score FLOAT NOT NULL,
+    ComputedAt DATETIME2 NOT NULL,
+    PRIMARY KEY (AccountId, ScoreName)
+);
\ No newline at end of file
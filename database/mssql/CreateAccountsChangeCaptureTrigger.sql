@@ -0,0 +1,98 @@
+-- Captures changes made to Accounts outside the app as pending changes.
+-- The app connects with the application name badgermaps-sync, so its own
+-- writes are skipped.
+CREATE OR ALTER TRIGGER AccountsChangeCapture
+ON Accounts
+AFTER UPDATE, DELETE
+AS
+BEGIN
+    SET NOCOUNT ON;
+    IF APP_NAME() = 'badgermaps-sync'
+        RETURN;
+
+    INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes)
+    SELECT d.AccountId, 'DELETE', '{}'
+    FROM deleted d
+    WHERE NOT EXISTS (SELECT 1 FROM inserted);
+
+    INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, BaseUpdatedAt)
+    SELECT i.AccountId, 'UPDATE', c.Changes, i.UpdatedAt
+    FROM inserted i
+    JOIN deleted d ON d.AccountId = i.AccountId
+    CROSS APPLY (
+        SELECT '{' + STRING_AGG(CAST('"' + f.Field + '":"' + STRING_ESCAPE(COALESCE(CAST(f.Value AS NVARCHAR(MAX)), ''), 'json') + '"' AS NVARCHAR(MAX)), ',') + '}' AS Changes
+        FROM (VALUES
+            ('first_name', i.FirstName, CASE WHEN i.FirstName <> d.FirstName OR (i.FirstName IS NULL AND d.FirstName IS NOT NULL) OR (i.FirstName IS NOT NULL AND d.FirstName IS NULL) THEN 1 ELSE 0 END),
+            ('last_name', i.LastName, CASE WHEN i.LastName <> d.LastName OR (i.LastName IS NULL AND d.LastName IS NOT NULL) OR (i.LastName IS NOT NULL AND d.LastName IS NULL) THEN 1 ELSE 0 END),
+            ('phone_number', i.PhoneNumber, CASE WHEN i.PhoneNumber <> d.PhoneNumber OR (i.PhoneNumber IS NULL AND d.PhoneNumber IS NOT NULL) OR (i.PhoneNumber IS NOT NULL AND d.PhoneNumber IS NULL) THEN 1 ELSE 0 END),
+            ('email', i.Email, CASE WHEN i.Email <> d.Email OR (i.Email IS NULL AND d.Email IS NOT NULL) OR (i.Email IS NOT NULL AND d.Email IS NULL) THEN 1 ELSE 0 END),
+            ('customer_id', i.CustomerId, CASE WHEN i.CustomerId <> d.CustomerId OR (i.CustomerId IS NULL AND d.CustomerId IS NOT NULL) OR (i.CustomerId IS NOT NULL AND d.CustomerId IS NULL) THEN 1 ELSE 0 END),
+            ('notes', i.Notes, CASE WHEN i.Notes <> d.Notes OR (i.Notes IS NULL AND d.Notes IS NOT NULL) OR (i.Notes IS NOT NULL AND d.Notes IS NULL) THEN 1 ELSE 0 END),
+            ('crm_id', i.CrmId, CASE WHEN i.CrmId <> d.CrmId OR (i.CrmId IS NULL AND d.CrmId IS NOT NULL) OR (i.CrmId IS NOT NULL AND d.CrmId IS NULL) THEN 1 ELSE 0 END),
+            ('account_owner', i.AccountOwner, CASE WHEN i.AccountOwner <> d.AccountOwner OR (i.AccountOwner IS NULL AND d.AccountOwner IS NOT NULL) OR (i.AccountOwner IS NOT NULL AND d.AccountOwner IS NULL) THEN 1 ELSE 0 END),
+            ('follow_up_date', i.FollowUpDate, CASE WHEN i.FollowUpDate <> d.FollowUpDate OR (i.FollowUpDate IS NULL AND d.FollowUpDate IS NOT NULL) OR (i.FollowUpDate IS NOT NULL AND d.FollowUpDate IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric', FORMAT(i.CustomNumeric, 'G15', 'en-US'), CASE WHEN i.CustomNumeric <> d.CustomNumeric OR (i.CustomNumeric IS NULL AND d.CustomNumeric IS NOT NULL) OR (i.CustomNumeric IS NOT NULL AND d.CustomNumeric IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text', i.CustomText, CASE WHEN i.CustomText <> d.CustomText OR (i.CustomText IS NULL AND d.CustomText IS NOT NULL) OR (i.CustomText IS NOT NULL AND d.CustomText IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric2', FORMAT(i.CustomNumeric2, 'G15', 'en-US'), CASE WHEN i.CustomNumeric2 <> d.CustomNumeric2 OR (i.CustomNumeric2 IS NULL AND d.CustomNumeric2 IS NOT NULL) OR (i.CustomNumeric2 IS NOT NULL AND d.CustomNumeric2 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text2', i.CustomText2, CASE WHEN i.CustomText2 <> d.CustomText2 OR (i.CustomText2 IS NULL AND d.CustomText2 IS NOT NULL) OR (i.CustomText2 IS NOT NULL AND d.CustomText2 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric3', FORMAT(i.CustomNumeric3, 'G15', 'en-US'), CASE WHEN i.CustomNumeric3 <> d.CustomNumeric3 OR (i.CustomNumeric3 IS NULL AND d.CustomNumeric3 IS NOT NULL) OR (i.CustomNumeric3 IS NOT NULL AND d.CustomNumeric3 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text3', i.CustomText3, CASE WHEN i.CustomText3 <> d.CustomText3 OR (i.CustomText3 IS NULL AND d.CustomText3 IS NOT NULL) OR (i.CustomText3 IS NOT NULL AND d.CustomText3 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric4', FORMAT(i.CustomNumeric4, 'G15', 'en-US'), CASE WHEN i.CustomNumeric4 <> d.CustomNumeric4 OR (i.CustomNumeric4 IS NULL AND d.CustomNumeric4 IS NOT NULL) OR (i.CustomNumeric4 IS NOT NULL AND d.CustomNumeric4 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text4', i.CustomText4, CASE WHEN i.CustomText4 <> d.CustomText4 OR (i.CustomText4 IS NULL AND d.CustomText4 IS NOT NULL) OR (i.CustomText4 IS NOT NULL AND d.CustomText4 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric5', FORMAT(i.CustomNumeric5, 'G15', 'en-US'), CASE WHEN i.CustomNumeric5 <> d.CustomNumeric5 OR (i.CustomNumeric5 IS NULL AND d.CustomNumeric5 IS NOT NULL) OR (i.CustomNumeric5 IS NOT NULL AND d.CustomNumeric5 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text5', i.CustomText5, CASE WHEN i.CustomText5 <> d.CustomText5 OR (i.CustomText5 IS NULL AND d.CustomText5 IS NOT NULL) OR (i.CustomText5 IS NOT NULL AND d.CustomText5 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric6', FORMAT(i.CustomNumeric6, 'G15', 'en-US'), CASE WHEN i.CustomNumeric6 <> d.CustomNumeric6 OR (i.CustomNumeric6 IS NULL AND d.CustomNumeric6 IS NOT NULL) OR (i.CustomNumeric6 IS NOT NULL AND d.CustomNumeric6 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text6', i.CustomText6, CASE WHEN i.CustomText6 <> d.CustomText6 OR (i.CustomText6 IS NULL AND d.CustomText6 IS NOT NULL) OR (i.CustomText6 IS NOT NULL AND d.CustomText6 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric7', FORMAT(i.CustomNumeric7, 'G15', 'en-US'), CASE WHEN i.CustomNumeric7 <> d.CustomNumeric7 OR (i.CustomNumeric7 IS NULL AND d.CustomNumeric7 IS NOT NULL) OR (i.CustomNumeric7 IS NOT NULL AND d.CustomNumeric7 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text7', i.CustomText7, CASE WHEN i.CustomText7 <> d.CustomText7 OR (i.CustomText7 IS NULL AND d.CustomText7 IS NOT NULL) OR (i.CustomText7 IS NOT NULL AND d.CustomText7 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric8', FORMAT(i.CustomNumeric8, 'G15', 'en-US'), CASE WHEN i.CustomNumeric8 <> d.CustomNumeric8 OR (i.CustomNumeric8 IS NULL AND d.CustomNumeric8 IS NOT NULL) OR (i.CustomNumeric8 IS NOT NULL AND d.CustomNumeric8 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text8', i.CustomText8, CASE WHEN i.CustomText8 <> d.CustomText8 OR (i.CustomText8 IS NULL AND d.CustomText8 IS NOT NULL) OR (i.CustomText8 IS NOT NULL AND d.CustomText8 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric9', FORMAT(i.CustomNumeric9, 'G15', 'en-US'), CASE WHEN i.CustomNumeric9 <> d.CustomNumeric9 OR (i.CustomNumeric9 IS NULL AND d.CustomNumeric9 IS NOT NULL) OR (i.CustomNumeric9 IS NOT NULL AND d.CustomNumeric9 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text9', i.CustomText9, CASE WHEN i.CustomText9 <> d.CustomText9 OR (i.CustomText9 IS NULL AND d.CustomText9 IS NOT NULL) OR (i.CustomText9 IS NOT NULL AND d.CustomText9 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric10', FORMAT(i.CustomNumeric10, 'G15', 'en-US'), CASE WHEN i.CustomNumeric10 <> d.CustomNumeric10 OR (i.CustomNumeric10 IS NULL AND d.CustomNumeric10 IS NOT NULL) OR (i.CustomNumeric10 IS NOT NULL AND d.CustomNumeric10 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text10', i.CustomText10, CASE WHEN i.CustomText10 <> d.CustomText10 OR (i.CustomText10 IS NULL AND d.CustomText10 IS NOT NULL) OR (i.CustomText10 IS NOT NULL AND d.CustomText10 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric11', FORMAT(i.CustomNumeric11, 'G15', 'en-US'), CASE WHEN i.CustomNumeric11 <> d.CustomNumeric11 OR (i.CustomNumeric11 IS NULL AND d.CustomNumeric11 IS NOT NULL) OR (i.CustomNumeric11 IS NOT NULL AND d.CustomNumeric11 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text11', i.CustomText11, CASE WHEN i.CustomText11 <> d.CustomText11 OR (i.CustomText11 IS NULL AND d.CustomText11 IS NOT NULL) OR (i.CustomText11 IS NOT NULL AND d.CustomText11 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric12', FORMAT(i.CustomNumeric12, 'G15', 'en-US'), CASE WHEN i.CustomNumeric12 <> d.CustomNumeric12 OR (i.CustomNumeric12 IS NULL AND d.CustomNumeric12 IS NOT NULL) OR (i.CustomNumeric12 IS NOT NULL AND d.CustomNumeric12 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text12', i.CustomText12, CASE WHEN i.CustomText12 <> d.CustomText12 OR (i.CustomText12 IS NULL AND d.CustomText12 IS NOT NULL) OR (i.CustomText12 IS NOT NULL AND d.CustomText12 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric13', FORMAT(i.CustomNumeric13, 'G15', 'en-US'), CASE WHEN i.CustomNumeric13 <> d.CustomNumeric13 OR (i.CustomNumeric13 IS NULL AND d.CustomNumeric13 IS NOT NULL) OR (i.CustomNumeric13 IS NOT NULL AND d.CustomNumeric13 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text13', i.CustomText13, CASE WHEN i.CustomText13 <> d.CustomText13 OR (i.CustomText13 IS NULL AND d.CustomText13 IS NOT NULL) OR (i.CustomText13 IS NOT NULL AND d.CustomText13 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric14', FORMAT(i.CustomNumeric14, 'G15', 'en-US'), CASE WHEN i.CustomNumeric14 <> d.CustomNumeric14 OR (i.CustomNumeric14 IS NULL AND d.CustomNumeric14 IS NOT NULL) OR (i.CustomNumeric14 IS NOT NULL AND d.CustomNumeric14 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text14', i.CustomText14, CASE WHEN i.CustomText14 <> d.CustomText14 OR (i.CustomText14 IS NULL AND d.CustomText14 IS NOT NULL) OR (i.CustomText14 IS NOT NULL AND d.CustomText14 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric15', FORMAT(i.CustomNumeric15, 'G15', 'en-US'), CASE WHEN i.CustomNumeric15 <> d.CustomNumeric15 OR (i.CustomNumeric15 IS NULL AND d.CustomNumeric15 IS NOT NULL) OR (i.CustomNumeric15 IS NOT NULL AND d.CustomNumeric15 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text15', i.CustomText15, CASE WHEN i.CustomText15 <> d.CustomText15 OR (i.CustomText15 IS NULL AND d.CustomText15 IS NOT NULL) OR (i.CustomText15 IS NOT NULL AND d.CustomText15 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric16', FORMAT(i.CustomNumeric16, 'G15', 'en-US'), CASE WHEN i.CustomNumeric16 <> d.CustomNumeric16 OR (i.CustomNumeric16 IS NULL AND d.CustomNumeric16 IS NOT NULL) OR (i.CustomNumeric16 IS NOT NULL AND d.CustomNumeric16 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text16', i.CustomText16, CASE WHEN i.CustomText16 <> d.CustomText16 OR (i.CustomText16 IS NULL AND d.CustomText16 IS NOT NULL) OR (i.CustomText16 IS NOT NULL AND d.CustomText16 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric17', FORMAT(i.CustomNumeric17, 'G15', 'en-US'), CASE WHEN i.CustomNumeric17 <> d.CustomNumeric17 OR (i.CustomNumeric17 IS NULL AND d.CustomNumeric17 IS NOT NULL) OR (i.CustomNumeric17 IS NOT NULL AND d.CustomNumeric17 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text17', i.CustomText17, CASE WHEN i.CustomText17 <> d.CustomText17 OR (i.CustomText17 IS NULL AND d.CustomText17 IS NOT NULL) OR (i.CustomText17 IS NOT NULL AND d.CustomText17 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric18', FORMAT(i.CustomNumeric18, 'G15', 'en-US'), CASE WHEN i.CustomNumeric18 <> d.CustomNumeric18 OR (i.CustomNumeric18 IS NULL AND d.CustomNumeric18 IS NOT NULL) OR (i.CustomNumeric18 IS NOT NULL AND d.CustomNumeric18 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text18', i.CustomText18, CASE WHEN i.CustomText18 <> d.CustomText18 OR (i.CustomText18 IS NULL AND d.CustomText18 IS NOT NULL) OR (i.CustomText18 IS NOT NULL AND d.CustomText18 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric19', FORMAT(i.CustomNumeric19, 'G15', 'en-US'), CASE WHEN i.CustomNumeric19 <> d.CustomNumeric19 OR (i.CustomNumeric19 IS NULL AND d.CustomNumeric19 IS NOT NULL) OR (i.CustomNumeric19 IS NOT NULL AND d.CustomNumeric19 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text19', i.CustomText19, CASE WHEN i.CustomText19 <> d.CustomText19 OR (i.CustomText19 IS NULL AND d.CustomText19 IS NOT NULL) OR (i.CustomText19 IS NOT NULL AND d.CustomText19 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric20', FORMAT(i.CustomNumeric20, 'G15', 'en-US'), CASE WHEN i.CustomNumeric20 <> d.CustomNumeric20 OR (i.CustomNumeric20 IS NULL AND d.CustomNumeric20 IS NOT NULL) OR (i.CustomNumeric20 IS NOT NULL AND d.CustomNumeric20 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text20', i.CustomText20, CASE WHEN i.CustomText20 <> d.CustomText20 OR (i.CustomText20 IS NULL AND d.CustomText20 IS NOT NULL) OR (i.CustomText20 IS NOT NULL AND d.CustomText20 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric21', FORMAT(i.CustomNumeric21, 'G15', 'en-US'), CASE WHEN i.CustomNumeric21 <> d.CustomNumeric21 OR (i.CustomNumeric21 IS NULL AND d.CustomNumeric21 IS NOT NULL) OR (i.CustomNumeric21 IS NOT NULL AND d.CustomNumeric21 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text21', i.CustomText21, CASE WHEN i.CustomText21 <> d.CustomText21 OR (i.CustomText21 IS NULL AND d.CustomText21 IS NOT NULL) OR (i.CustomText21 IS NOT NULL AND d.CustomText21 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric22', FORMAT(i.CustomNumeric22, 'G15', 'en-US'), CASE WHEN i.CustomNumeric22 <> d.CustomNumeric22 OR (i.CustomNumeric22 IS NULL AND d.CustomNumeric22 IS NOT NULL) OR (i.CustomNumeric22 IS NOT NULL AND d.CustomNumeric22 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text22', i.CustomText22, CASE WHEN i.CustomText22 <> d.CustomText22 OR (i.CustomText22 IS NULL AND d.CustomText22 IS NOT NULL) OR (i.CustomText22 IS NOT NULL AND d.CustomText22 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric23', FORMAT(i.CustomNumeric23, 'G15', 'en-US'), CASE WHEN i.CustomNumeric23 <> d.CustomNumeric23 OR (i.CustomNumeric23 IS NULL AND d.CustomNumeric23 IS NOT NULL) OR (i.CustomNumeric23 IS NOT NULL AND d.CustomNumeric23 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text23', i.CustomText23, CASE WHEN i.CustomText23 <> d.CustomText23 OR (i.CustomText23 IS NULL AND d.CustomText23 IS NOT NULL) OR (i.CustomText23 IS NOT NULL AND d.CustomText23 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric24', FORMAT(i.CustomNumeric24, 'G15', 'en-US'), CASE WHEN i.CustomNumeric24 <> d.CustomNumeric24 OR (i.CustomNumeric24 IS NULL AND d.CustomNumeric24 IS NOT NULL) OR (i.CustomNumeric24 IS NOT NULL AND d.CustomNumeric24 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text24', i.CustomText24, CASE WHEN i.CustomText24 <> d.CustomText24 OR (i.CustomText24 IS NULL AND d.CustomText24 IS NOT NULL) OR (i.CustomText24 IS NOT NULL AND d.CustomText24 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric25', FORMAT(i.CustomNumeric25, 'G15', 'en-US'), CASE WHEN i.CustomNumeric25 <> d.CustomNumeric25 OR (i.CustomNumeric25 IS NULL AND d.CustomNumeric25 IS NOT NULL) OR (i.CustomNumeric25 IS NOT NULL AND d.CustomNumeric25 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text25', i.CustomText25, CASE WHEN i.CustomText25 <> d.CustomText25 OR (i.CustomText25 IS NULL AND d.CustomText25 IS NOT NULL) OR (i.CustomText25 IS NOT NULL AND d.CustomText25 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric26', FORMAT(i.CustomNumeric26, 'G15', 'en-US'), CASE WHEN i.CustomNumeric26 <> d.CustomNumeric26 OR (i.CustomNumeric26 IS NULL AND d.CustomNumeric26 IS NOT NULL) OR (i.CustomNumeric26 IS NOT NULL AND d.CustomNumeric26 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text26', i.CustomText26, CASE WHEN i.CustomText26 <> d.CustomText26 OR (i.CustomText26 IS NULL AND d.CustomText26 IS NOT NULL) OR (i.CustomText26 IS NOT NULL AND d.CustomText26 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric27', FORMAT(i.CustomNumeric27, 'G15', 'en-US'), CASE WHEN i.CustomNumeric27 <> d.CustomNumeric27 OR (i.CustomNumeric27 IS NULL AND d.CustomNumeric27 IS NOT NULL) OR (i.CustomNumeric27 IS NOT NULL AND d.CustomNumeric27 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text27', i.CustomText27, CASE WHEN i.CustomText27 <> d.CustomText27 OR (i.CustomText27 IS NULL AND d.CustomText27 IS NOT NULL) OR (i.CustomText27 IS NOT NULL AND d.CustomText27 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric28', FORMAT(i.CustomNumeric28, 'G15', 'en-US'), CASE WHEN i.CustomNumeric28 <> d.CustomNumeric28 OR (i.CustomNumeric28 IS NULL AND d.CustomNumeric28 IS NOT NULL) OR (i.CustomNumeric28 IS NOT NULL AND d.CustomNumeric28 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text28', i.CustomText28, CASE WHEN i.CustomText28 <> d.CustomText28 OR (i.CustomText28 IS NULL AND d.CustomText28 IS NOT NULL) OR (i.CustomText28 IS NOT NULL AND d.CustomText28 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric29', FORMAT(i.CustomNumeric29, 'G15', 'en-US'), CASE WHEN i.CustomNumeric29 <> d.CustomNumeric29 OR (i.CustomNumeric29 IS NULL AND d.CustomNumeric29 IS NOT NULL) OR (i.CustomNumeric29 IS NOT NULL AND d.CustomNumeric29 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text29', i.CustomText29, CASE WHEN i.CustomText29 <> d.CustomText29 OR (i.CustomText29 IS NULL AND d.CustomText29 IS NOT NULL) OR (i.CustomText29 IS NOT NULL AND d.CustomText29 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_numeric30', FORMAT(i.CustomNumeric30, 'G15', 'en-US'), CASE WHEN i.CustomNumeric30 <> d.CustomNumeric30 OR (i.CustomNumeric30 IS NULL AND d.CustomNumeric30 IS NOT NULL) OR (i.CustomNumeric30 IS NOT NULL AND d.CustomNumeric30 IS NULL) THEN 1 ELSE 0 END),
+            ('custom_text30', i.CustomText30, CASE WHEN i.CustomText30 <> d.CustomText30 OR (i.CustomText30 IS NULL AND d.CustomText30 IS NOT NULL) OR (i.CustomText30 IS NOT NULL AND d.CustomText30 IS NULL) THEN 1 ELSE 0 END)
+        ) AS f(Field, Value, Changed)
+        WHERE f.Changed = 1
+    ) c
+    WHERE c.Changes IS NOT NULL;
+END;
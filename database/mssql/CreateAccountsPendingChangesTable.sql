@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AccountsPendingChanges' AND xtype='U')
+IF OBJECT_ID('AccountsPendingChanges', 'U') IS NULL
 CREATE TABLE AccountsPendingChanges (
     ChangeId INT IDENTITY(1,1) PRIMARY KEY,
     AccountId INT NOT NULL,
@@ -6,5 +6,9 @@ CREATE TABLE AccountsPendingChanges (
     Changes NVARCHAR(MAX),
     Status NVARCHAR(10) NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
     CreatedAt DATETIME2 DEFAULT GETDATE(),
-    ProcessedAt DATETIME2
+    ProcessedAt DATETIME2,
+    BaseUpdatedAt DATETIME2,
+    IdempotencyKey NVARCHAR(64),
+    ContentHash NVARCHAR(64),
+    ValidationErrors NVARCHAR(MAX)
 );
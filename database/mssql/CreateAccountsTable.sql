@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Accounts' AND xtype='U')
+IF OBJECT_ID('Accounts', 'U') IS NULL
 CREATE TABLE Accounts (
     AccountId INT IDENTITY(1,1) PRIMARY KEY,
     FirstName NVARCHAR(255),
@@ -19,7 +19,7 @@ BEGIN
     )
     FROM information_schema.columns c
     LEFT JOIN DataSets ds ON c.COLUMN_NAME = ds.AccountField AND ds.ProfileId = @profileId
-    WHERE c.TABLE_NAME = 'Accounts';
+    WHERE c.TABLE_SCHEMA = SCHEMA_NAME() AND c.TABLE_NAME = 'Accounts';
 
     SET @view_sql = 'CREATE OR ALTER VIEW AccountsWithLabels AS SELECT ' + @select_list + ' FROM Accounts a;';
 
@@ -0,0 +1,19 @@
+IF OBJECT_ID('ActionRuns', 'U') IS NULL
+CREATE TABLE ActionRuns (
+    RunId INT IDENTITY(1,1) PRIMARY KEY,
+    ActionName NVARCHAR(255),
+    ActionType NVARCHAR(50) NOT NULL,
+    TriggeredBy NVARCHAR(255) NOT NULL,
+    Source NVARCHAR(255),
+    Config NVARCHAR(MAX),
+    Event NVARCHAR(MAX),
+    Args NVARCHAR(MAX),
+    Status NVARCHAR(20) NOT NULL CHECK(Status IN ('success', 'failed')),
+    ExitCode INT,
+    CommandOutput NVARCHAR(MAX),
+    RowsAffected BIGINT,
+    ErrorMessage NVARCHAR(MAX),
+    RerunOf INT,
+    StartedAt DATETIME2 NOT NULL,
+    DurationMs BIGINT
+);
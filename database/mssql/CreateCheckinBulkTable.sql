@@ -0,0 +1,11 @@
+CREATE TABLE #BulkAccountCheckins (
+    CheckinId INT NOT NULL,
+    CrmId NVARCHAR(255),
+    AccountId INT,
+    LogDatetime NVARCHAR(64),
+    Type NVARCHAR(100),
+    Comments NVARCHAR(MAX),
+    ExtraFields NVARCHAR(MAX),
+    EndpointType NVARCHAR(20),
+    CreatedBy NVARCHAR(255)
+);
\ No newline at end of file
@@ -1,9 +1,11 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='CommandLog' and xtype='U')
+IF OBJECT_ID('CommandLog', 'U') IS NULL
 CREATE TABLE CommandLog (
     LogId INT IDENTITY(1,1) PRIMARY KEY,
     Command NVARCHAR(255) NOT NULL,
     Args NVARCHAR(MAX),
     Timestamp DATETIME DEFAULT GETDATE(),
     Success BIT NOT NULL,
-    ErrorMessage NVARCHAR(MAX)
+    ErrorMessage NVARCHAR(MAX),
+    Flags NVARCHAR(MAX),
+    DurationMs INT
 );
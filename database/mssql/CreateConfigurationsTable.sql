@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Configurations' AND xtype='U')
+IF OBJECT_ID('Configurations', 'U') IS NULL
 CREATE TABLE Configurations (
     SettingKey NVARCHAR(255) PRIMARY KEY,
     SettingValue NVARCHAR(MAX),
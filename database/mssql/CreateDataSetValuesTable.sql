@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='DataSetValues' AND xtype='U')
+IF OBJECT_ID('DataSetValues', 'U') IS NULL
 CREATE TABLE DataSetValues (
     DataSetValueId INT IDENTITY(1,1) PRIMARY KEY,
     ProfileId INT,
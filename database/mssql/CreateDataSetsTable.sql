@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='DataSets' and xtype='U')
+IF OBJECT_ID('DataSets', 'U') IS NULL
 CREATE TABLE DataSets (
     Name NVARCHAR(255),
     ProfileId INT,
@@ -0,0 +1,11 @@
+IF OBJECT_ID('DeletedAccounts', 'U') IS NULL
+CREATE TABLE DeletedAccounts (
+    DeletedId INT IDENTITY(1,1) PRIMARY KEY,
+    AccountId INT NOT NULL,
+    ChangeId INT,
+    FullName NVARCHAR(MAX),
+    Data NVARCHAR(MAX) NOT NULL,
+    DeletedAt DATETIME2 NOT NULL,
+    RestoredAt DATETIME2,
+    RestoredAccountId INT
+);
@@ -1,9 +1,11 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='FieldMaps' and xtype='U')
+IF OBJECT_ID('FieldMaps', 'U') IS NULL
 CREATE TABLE FieldMaps (
     FieldName NVARCHAR(255),
     ObjectType NVARCHAR(255),
     JsonField NVARCHAR(255),
     DataSetName NVARCHAR(255),
     DataSetLabel NVARCHAR(255),
+    SyncDirection NVARCHAR(16) NOT NULL DEFAULT 'both' CHECK(SyncDirection IN ('both', 'pull', 'push')), -- both, pull (never pushed), or push (never overwritten by pulls)
+    MergeStrategy NVARCHAR(16) NOT NULL DEFAULT 'overwrite' CHECK(MergeStrategy IN ('overwrite', 'prefer-local', 'append', 'longest')), -- how a pulled value is merged into the stored one
     PRIMARY KEY (FieldName, ObjectType)
 );
@@ -0,0 +1,10 @@
+IF OBJECT_ID('IdRemap', 'U') IS NULL
+CREATE TABLE IdRemap (
+    RemapId INT IDENTITY(1,1) PRIMARY KEY,
+    EntityType NVARCHAR(50) NOT NULL,
+    OldId INT NOT NULL,
+    NewId INT NOT NULL,
+    Reason NVARCHAR(50),
+    RowsRewritten INT NOT NULL DEFAULT 0,
+    RemappedAt DATETIME2 NOT NULL
+);
\ No newline at end of file
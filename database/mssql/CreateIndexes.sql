@@ -1,24 +1,27 @@
 -- Create indexes for better performance
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountsCustomerId')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountsCustomerId' AND object_id = OBJECT_ID('Accounts'))
 CREATE INDEX IdxAccountsCustomerId ON Accounts(CustomerId);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountsCrmId')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountsCrmId' AND object_id = OBJECT_ID('Accounts'))
 CREATE INDEX IdxAccountsCrmId ON Accounts(CrmId);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountCheckinsAccountId')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountCheckinsAccountId' AND object_id = OBJECT_ID('AccountCheckins'))
 CREATE INDEX IdxAccountCheckinsAccountId ON AccountCheckins(AccountId);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountCheckinsCrmId')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountCheckinsCrmId' AND object_id = OBJECT_ID('AccountCheckins'))
 CREATE INDEX IdxAccountCheckinsCrmId ON AccountCheckins(CrmId);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxRoutesRouteDate')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxRoutesRouteDate' AND object_id = OBJECT_ID('Routes'))
 CREATE INDEX IdxRoutesRouteDate ON Routes(RouteDate);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxRouteWaypointsRouteId')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxRouteWaypointsRouteId' AND object_id = OBJECT_ID('RouteWaypoints'))
 CREATE INDEX IdxRouteWaypointsRouteId ON RouteWaypoints(RouteId);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountLocationsAccountId')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxAccountLocationsAccountId' AND object_id = OBJECT_ID('AccountLocations'))
 CREATE INDEX IdxAccountLocationsAccountId ON AccountLocations(AccountId);
 
-IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxSyncHistoryStartedAt')
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxSyncHistoryStartedAt' AND object_id = OBJECT_ID('SyncHistory'))
 CREATE INDEX IdxSyncHistoryStartedAt ON SyncHistory(StartedAt DESC);
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IdxActionRunsActionName' AND object_id = OBJECT_ID('ActionRuns'))
+CREATE INDEX IdxActionRunsActionName ON ActionRuns(ActionName, RunId);
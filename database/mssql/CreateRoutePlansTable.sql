@@ -0,0 +1,16 @@
+IF OBJECT_ID('RoutePlans', 'U') IS NULL
+CREATE TABLE RoutePlans (
+    PlanId INT IDENTITY(1,1) PRIMARY KEY,
+    Name NVARCHAR(255) NOT NULL,
+    RouteDate NVARCHAR(10) NOT NULL,
+    StartAddress NVARCHAR(MAX),
+    DestinationAddress NVARCHAR(MAX),
+    StartTime NVARCHAR(5),
+    TemplateRouteId INT,
+    Waypoints NVARCHAR(MAX),
+    Status NVARCHAR(20) NOT NULL CHECK(Status IN ('draft', 'pushed')),
+    RouteId INT,
+    CreatedAt DATETIME2 NOT NULL,
+    UpdatedAt DATETIME2 NOT NULL,
+    PushedAt DATETIME2
+);
\ No newline at end of file
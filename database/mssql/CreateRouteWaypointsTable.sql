@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='RouteWaypoints' AND xtype='U')
+IF OBJECT_ID('RouteWaypoints', 'U') IS NULL
 CREATE TABLE RouteWaypoints (
     WaypointId INT IDENTITY(1,1) PRIMARY KEY,
     RouteId INT,
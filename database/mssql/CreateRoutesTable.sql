@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Routes' AND xtype='U')
+IF OBJECT_ID('Routes', 'U') IS NULL
 CREATE TABLE Routes (
     RouteId INT IDENTITY(1,1) PRIMARY KEY,
     Name NVARCHAR(255),
@@ -0,0 +1,6 @@
+IF OBJECT_ID('StaleAccounts', 'U') IS NULL
+CREATE TABLE StaleAccounts (
+    AccountId INT PRIMARY KEY,
+    RemoteModifiedDate NVARCHAR(64),
+    MarkedAt DATETIME2 NOT NULL
+);
\ No newline at end of file
@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='SyncHistory' AND xtype='U')
+IF OBJECT_ID('SyncHistory', 'U') IS NULL
 CREATE TABLE SyncHistory (
     HistoryId INT IDENTITY(1,1) PRIMARY KEY,
     CorrelationId NVARCHAR(64) NOT NULL UNIQUE,
@@ -13,5 +13,6 @@ CREATE TABLE SyncHistory (
     CompletedAt DATETIME2,
     DurationSeconds INT,
     Summary NVARCHAR(MAX),
-    Details NVARCHAR(MAX)
+    Details NVARCHAR(MAX),
+    Note NVARCHAR(MAX)
 );
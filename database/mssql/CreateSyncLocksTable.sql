@@ -0,0 +1,10 @@
+IF OBJECT_ID('SyncLocks', 'U') IS NULL
+CREATE TABLE SyncLocks (
+    LockName NVARCHAR(64) PRIMARY KEY,
+    HolderId NVARCHAR(64) NOT NULL,
+    Host NVARCHAR(255),
+    Pid INT,
+    Operation NVARCHAR(255),
+    AcquiredAt DATETIME2 NOT NULL,
+    HeartbeatAt DATETIME2 NOT NULL
+);
\ No newline at end of file
@@ -1,4 +1,4 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='UserProfiles' AND xtype='U')
+IF OBJECT_ID('UserProfiles', 'U') IS NULL
 CREATE TABLE UserProfiles (
     ProfileId INT PRIMARY KEY,
     Email NVARCHAR(255),
@@ -1,9 +1,12 @@
-IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='WebhookLog' AND xtype='U')
+IF OBJECT_ID('WebhookLog', 'U') IS NULL
 CREATE TABLE WebhookLog (
     Id INT PRIMARY KEY IDENTITY,
     ReceivedAt DATETIME NOT NULL,
     Method NVARCHAR(10) NOT NULL,
     Uri NVARCHAR(255) NOT NULL,
     Headers NVARCHAR(MAX),
-    Body NVARCHAR(MAX)
+    Body NVARCHAR(MAX),
+    ParseStatus NVARCHAR(16),
+    ParseError NVARCHAR(MAX),
+    EntityId BIGINT
 );
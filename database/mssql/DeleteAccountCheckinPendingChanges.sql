@@ -0,0 +1 @@
+DELETE FROM AccountCheckinsPendingChanges WHERE AccountId = ?;
\ No newline at end of file
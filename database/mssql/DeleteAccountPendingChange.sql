@@ -0,0 +1 @@
+DELETE FROM AccountsPendingChanges WHERE ChangeId = ?;
\ No newline at end of file
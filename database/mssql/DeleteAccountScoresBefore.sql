@@ -0,0 +1 @@
+DELETE FROM AccountScores WHERE ComputedAt < ?;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM WebhookLog
+WHERE EntityId = ? OR EntityId IN (SELECT CheckinId FROM AccountCheckins WHERE AccountId = ?);
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM Accounts WHERE AccountId < 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM DataSetValues WHERE DataSetValueId = ?;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM AccountCheckins
+WHERE AccountId IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM Accounts a WHERE a.AccountId = AccountCheckins.AccountId);
@@ -0,0 +1 @@
+DELETE FROM [AccountLocations] WHERE [AccountId] >= ?;
\ No newline at end of file
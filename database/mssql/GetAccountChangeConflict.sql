@@ -0,0 +1,10 @@
+SELECT
+    pc.BaseUpdatedAt,
+    a.UpdatedAt
+FROM
+    AccountsPendingChanges pc
+    LEFT JOIN Accounts a ON a.AccountId = pc.AccountId
+WHERE
+    pc.ChangeId = ?
+    AND pc.BaseUpdatedAt IS NOT NULL
+    AND (a.UpdatedAt IS NULL OR a.UpdatedAt <> pc.BaseUpdatedAt);
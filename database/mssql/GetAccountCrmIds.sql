@@ -0,0 +1,3 @@
+SELECT AccountId, CrmId FROM Accounts
+WHERE CrmId IS NOT NULL AND LTRIM(RTRIM(CrmId)) <> ''
+ORDER BY CrmId, AccountId;
\ No newline at end of file
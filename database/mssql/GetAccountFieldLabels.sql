@@ -0,0 +1,5 @@
+SELECT AccountField, Label
+FROM DataSets
+WHERE AccountField IS NOT NULL AND AccountField <> ''
+  AND Label IS NOT NULL AND Label <> ''
+ORDER BY Position, Name;
\ No newline at end of file
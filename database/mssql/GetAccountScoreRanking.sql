@@ -0,0 +1,6 @@
+SELECT s.AccountId, a.FullName, s.Score, s.ComputedAt
+FROM AccountScores s
+JOIN Accounts a ON a.AccountId = s.AccountId
+WHERE s.ScoreName = ?
+ORDER BY s.Score DESC, s.AccountId
+OFFSET 0 ROWS FETCH NEXT {{LIMIT}} ROWS ONLY;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT a.AccountId, a.FullName, l.LocationId, l.AddressLine1, l.City, l.State, l.Zipcode, l.Latitude, l.Longitude
+FROM Accounts a
+LEFT JOIN AccountLocations l ON l.AccountId = a.AccountId
+WHERE a.AccountId = ?;
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT RouteId FROM Routes;
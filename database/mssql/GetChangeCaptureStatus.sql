@@ -0,0 +1 @@
+SELECT count(*) FROM sys.triggers WHERE object_id = OBJECT_ID('AccountsChangeCapture');
\ No newline at end of file
@@ -0,0 +1,21 @@
+SELECT
+    pc.ChangeId,
+    pc.CheckinId,
+    pc.AccountId,
+    pc.CrmId,
+    pc.LogDatetime,
+    pc.Type,
+    pc.Comments,
+    pc.ExtraFields,
+    pc.EndpointType,
+    pc.CreatedBy,
+    pc.ChangeType,
+    pc.Status,
+    pc.CreatedAt,
+    pc.ProcessedAt,
+    pc.IdempotencyKey,
+    pc.ContentHash
+FROM
+    AccountCheckinsPendingChanges pc
+ORDER BY
+    pc.CreatedAt;
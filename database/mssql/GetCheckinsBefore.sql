@@ -0,0 +1,8 @@
+SELECT CheckinId, CrmId, AccountId, LogDatetime, Type, Comments, ExtraFields, EndpointType, CreatedBy
+FROM AccountCheckins
+WHERE LogDatetime < ?
+  AND CheckinId NOT IN (
+    SELECT CheckinId FROM AccountCheckinsPendingChanges
+    WHERE CheckinId IS NOT NULL AND Status IN ('pending', 'processing')
+  )
+ORDER BY LogDatetime, CheckinId;
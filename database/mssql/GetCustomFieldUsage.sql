@@ -0,0 +1,63 @@
+SELECT
+    COUNT(CustomNumeric) AS CustomNumeric,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText)), '')) AS CustomText,
+    COUNT(CustomNumeric2) AS CustomNumeric2,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText2)), '')) AS CustomText2,
+    COUNT(CustomNumeric3) AS CustomNumeric3,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText3)), '')) AS CustomText3,
+    COUNT(CustomNumeric4) AS CustomNumeric4,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText4)), '')) AS CustomText4,
+    COUNT(CustomNumeric5) AS CustomNumeric5,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText5)), '')) AS CustomText5,
+    COUNT(CustomNumeric6) AS CustomNumeric6,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText6)), '')) AS CustomText6,
+    COUNT(CustomNumeric7) AS CustomNumeric7,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText7)), '')) AS CustomText7,
+    COUNT(CustomNumeric8) AS CustomNumeric8,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText8)), '')) AS CustomText8,
+    COUNT(CustomNumeric9) AS CustomNumeric9,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText9)), '')) AS CustomText9,
+    COUNT(CustomNumeric10) AS CustomNumeric10,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText10)), '')) AS CustomText10,
+    COUNT(CustomNumeric11) AS CustomNumeric11,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText11)), '')) AS CustomText11,
+    COUNT(CustomNumeric12) AS CustomNumeric12,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText12)), '')) AS CustomText12,
+    COUNT(CustomNumeric13) AS CustomNumeric13,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText13)), '')) AS CustomText13,
+    COUNT(CustomNumeric14) AS CustomNumeric14,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText14)), '')) AS CustomText14,
+    COUNT(CustomNumeric15) AS CustomNumeric15,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText15)), '')) AS CustomText15,
+    COUNT(CustomNumeric16) AS CustomNumeric16,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText16)), '')) AS CustomText16,
+    COUNT(CustomNumeric17) AS CustomNumeric17,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText17)), '')) AS CustomText17,
+    COUNT(CustomNumeric18) AS CustomNumeric18,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText18)), '')) AS CustomText18,
+    COUNT(CustomNumeric19) AS CustomNumeric19,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText19)), '')) AS CustomText19,
+    COUNT(CustomNumeric20) AS CustomNumeric20,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText20)), '')) AS CustomText20,
+    COUNT(CustomNumeric21) AS CustomNumeric21,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText21)), '')) AS CustomText21,
+    COUNT(CustomNumeric22) AS CustomNumeric22,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText22)), '')) AS CustomText22,
+    COUNT(CustomNumeric23) AS CustomNumeric23,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText23)), '')) AS CustomText23,
+    COUNT(CustomNumeric24) AS CustomNumeric24,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText24)), '')) AS CustomText24,
+    COUNT(CustomNumeric25) AS CustomNumeric25,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText25)), '')) AS CustomText25,
+    COUNT(CustomNumeric26) AS CustomNumeric26,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText26)), '')) AS CustomText26,
+    COUNT(CustomNumeric27) AS CustomNumeric27,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText27)), '')) AS CustomText27,
+    COUNT(CustomNumeric28) AS CustomNumeric28,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText28)), '')) AS CustomText28,
+    COUNT(CustomNumeric29) AS CustomNumeric29,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText29)), '')) AS CustomText29,
+    COUNT(CustomNumeric30) AS CustomNumeric30,
+    COUNT(NULLIF(LTRIM(RTRIM(CustomText30)), '')) AS CustomText30
+FROM
+    Accounts;
@@ -0,0 +1 @@
+SELECT TOP 1 Name FROM DataSets WHERE AccountField = ? ORDER BY ProfileId;
\ No newline at end of file
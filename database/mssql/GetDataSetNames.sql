@@ -0,0 +1 @@
+SELECT Name FROM DataSets WHERE ProfileId = ?;
\ No newline at end of file
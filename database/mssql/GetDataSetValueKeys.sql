@@ -0,0 +1 @@
+SELECT DataSetValueId, DataSetName, Value FROM DataSetValues WHERE ProfileId = ?;
\ No newline at end of file
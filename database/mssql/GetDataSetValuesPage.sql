@@ -0,0 +1,6 @@
+SELECT Text, Value
+FROM DataSetValues
+WHERE DataSetName = ?
+  AND (Text LIKE ? OR Value LIKE ?)
+ORDER BY Text, DataSetValueId
+OFFSET {{OFFSET}} ROWS FETCH NEXT {{LIMIT}} ROWS ONLY;
@@ -0,0 +1,4 @@
+SELECT DeletedId, AccountId, ChangeId, FullName, Data, DeletedAt
+FROM DeletedAccounts
+WHERE RestoredAt IS NULL AND DeletedAt >= ?
+ORDER BY DeletedAt DESC, DeletedId DESC;
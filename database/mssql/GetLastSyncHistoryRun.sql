@@ -0,0 +1,21 @@
+SELECT HistoryId,
+       CorrelationId,
+       RunType,
+       Direction,
+       Source,
+       Initiator,
+       Status,
+       ItemsProcessed,
+       ErrorCount,
+       StartedAt,
+       CompletedAt,
+       DurationSeconds,
+       Summary,
+       Details,
+       Note
+FROM SyncHistory
+WHERE RunType = ?
+  AND Source = ?
+  AND Status = ?
+ORDER BY StartedAt DESC
+OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY;
@@ -5,7 +5,10 @@ SELECT
     Changes,
     Status,
     CreatedAt,
-    ProcessedAt
+    ProcessedAt,
+    IdempotencyKey,
+    ContentHash,
+    ValidationErrors
 FROM
     AccountsPendingChanges
 WHERE
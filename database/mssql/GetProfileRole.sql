@@ -0,0 +1,3 @@
+SELECT TOP 1 ProfileId, Email, FirstName, LastName, IsManager
+FROM UserProfiles
+ORDER BY UpdatedAt DESC, ProfileId
\ No newline at end of file
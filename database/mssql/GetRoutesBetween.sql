@@ -0,0 +1,4 @@
+SELECT RouteId, Name, RouteDate, Duration, StartAddress, DestinationAddress, StartTime
+FROM Routes
+WHERE RouteDate >= CAST(? AS DATE) AND RouteDate < CAST(? AS DATE)
+ORDER BY RouteDate, StartTime, RouteId;
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT AccountId FROM StaleAccounts ORDER BY AccountId;
\ No newline at end of file
@@ -1 +1 @@
-SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME = ?;
\ No newline at end of file
+SELECT name FROM sys.columns WHERE object_id = OBJECT_ID(?) ORDER BY column_id;
\ No newline at end of file
@@ -0,0 +1,7 @@
+SELECT t.name, CAST(SUM(a.total_pages) AS BIGINT) * 8192 AS SizeBytes
+FROM sys.tables t
+JOIN sys.partitions p ON p.object_id = t.object_id
+JOIN sys.allocation_units a ON a.container_id = p.partition_id
+WHERE t.is_ms_shipped = 0 AND t.schema_id = SCHEMA_ID()
+GROUP BY t.name
+ORDER BY t.name
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT a.AccountId, a.FullName, a.AccountOwner, l.Zipcode, l.State, l.Latitude, l.Longitude
+FROM Accounts a
+LEFT JOIN AccountLocations l ON l.AccountId = a.AccountId
+ORDER BY a.AccountId
\ No newline at end of file
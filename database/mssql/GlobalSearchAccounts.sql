@@ -0,0 +1,28 @@
+WITH q AS (SELECT CAST(? AS NVARCHAR(400)) AS Term, CAST(? AS NVARCHAR(400)) AS Pattern, CAST(? AS NVARCHAR(400)) AS Prefix)
+SELECT TOP 25 a.AccountId, a.FullName,
+  COALESCE(NULLIF(a.OriginalAddress, ''), LTRIM(RTRIM(COALESCE(l.AddressLine1, '') + ' ' + COALESCE(l.City, '')))) AS Address,
+  CASE
+    WHEN CAST(a.AccountId AS NVARCHAR(50)) = q.Term THEN 'ID'
+    WHEN LOWER(a.FullName) LIKE q.Pattern THEN 'Name'
+    WHEN LOWER(a.OriginalAddress) LIKE q.Pattern OR LOWER(l.AddressLine1) LIKE q.Pattern
+      OR LOWER(l.City) LIKE q.Pattern OR LOWER(l.Zipcode) LIKE q.Pattern THEN 'Address'
+    ELSE 'Notes'
+  END AS MatchedField
+FROM Accounts a
+CROSS JOIN q
+LEFT JOIN AccountLocations l ON l.AccountId = a.AccountId
+WHERE CAST(a.AccountId AS NVARCHAR(50)) = q.Term
+   OR LOWER(a.FullName) LIKE q.Pattern
+   OR LOWER(a.OriginalAddress) LIKE q.Pattern
+   OR LOWER(l.AddressLine1) LIKE q.Pattern
+   OR LOWER(l.City) LIKE q.Pattern
+   OR LOWER(l.Zipcode) LIKE q.Pattern
+   OR LOWER(a.Notes) LIKE q.Pattern
+ORDER BY
+  CASE
+    WHEN CAST(a.AccountId AS NVARCHAR(50)) = q.Term THEN 0
+    WHEN LOWER(a.FullName) LIKE q.Prefix THEN 1
+    WHEN LOWER(a.FullName) LIKE q.Pattern THEN 2
+    ELSE 3
+  END,
+  a.FullName ASC
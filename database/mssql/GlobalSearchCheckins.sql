@@ -0,0 +1,22 @@
+WITH q AS (SELECT CAST(? AS NVARCHAR(400)) AS Term, CAST(? AS NVARCHAR(400)) AS Pattern, CAST(? AS NVARCHAR(400)) AS Prefix)
+SELECT TOP 25 c.CheckinId, c.AccountId, a.FullName, c.LogDatetime, c.Type, c.Comments,
+  CASE
+    WHEN CAST(c.CheckinId AS NVARCHAR(50)) = q.Term THEN 'ID'
+    WHEN LOWER(a.FullName) LIKE q.Pattern THEN 'Account'
+    WHEN LOWER(c.Type) LIKE q.Pattern THEN 'Type'
+    ELSE 'Comments'
+  END AS MatchedField
+FROM AccountCheckins c
+CROSS JOIN q
+LEFT JOIN Accounts a ON a.AccountId = c.AccountId
+WHERE CAST(c.CheckinId AS NVARCHAR(50)) = q.Term
+   OR LOWER(a.FullName) LIKE q.Pattern
+   OR LOWER(c.Type) LIKE q.Pattern
+   OR LOWER(c.Comments) LIKE q.Pattern
+ORDER BY
+  CASE
+    WHEN CAST(c.CheckinId AS NVARCHAR(50)) = q.Term THEN 0
+    WHEN LOWER(a.FullName) LIKE q.Prefix THEN 1
+    ELSE 2
+  END,
+  c.LogDatetime DESC
@@ -0,0 +1,21 @@
+WITH q AS (SELECT CAST(? AS NVARCHAR(400)) AS Term, CAST(? AS NVARCHAR(400)) AS Pattern, CAST(? AS NVARCHAR(400)) AS Prefix)
+SELECT TOP 25 r.RouteId, r.Name, r.RouteDate, r.StartAddress, r.DestinationAddress,
+  CASE
+    WHEN CAST(r.RouteId AS NVARCHAR(50)) = q.Term THEN 'ID'
+    WHEN LOWER(r.Name) LIKE q.Pattern THEN 'Name'
+    ELSE 'Address'
+  END AS MatchedField
+FROM Routes r
+CROSS JOIN q
+WHERE CAST(r.RouteId AS NVARCHAR(50)) = q.Term
+   OR LOWER(r.Name) LIKE q.Pattern
+   OR LOWER(r.StartAddress) LIKE q.Pattern
+   OR LOWER(r.DestinationAddress) LIKE q.Pattern
+ORDER BY
+  CASE
+    WHEN CAST(r.RouteId AS NVARCHAR(50)) = q.Term THEN 0
+    WHEN LOWER(r.Name) LIKE q.Prefix THEN 1
+    WHEN LOWER(r.Name) LIKE q.Pattern THEN 2
+    ELSE 3
+  END,
+  r.RouteDate DESC
@@ -0,0 +1,2 @@
+INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, CrmId, LogDatetime, Type, Comments, ExtraFields, EndpointType, CreatedBy, ChangeType, IdempotencyKey, ContentHash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
\ No newline at end of file
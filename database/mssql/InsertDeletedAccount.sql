@@ -0,0 +1,2 @@
+INSERT INTO DeletedAccounts (AccountId, ChangeId, FullName, Data, DeletedAt)
+VALUES (?, ?, ?, ?, ?);
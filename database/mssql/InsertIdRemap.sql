@@ -0,0 +1,2 @@
+INSERT INTO IdRemap (EntityType, OldId, NewId, Reason, RowsRewritten, RemappedAt)
+VALUES (?, ?, ?, ?, ?, ?);
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO RoutePlans (Name, RouteDate, StartAddress, DestinationAddress, StartTime, TemplateRouteId, Waypoints, Status, CreatedAt, UpdatedAt)
+OUTPUT INSERTED.PlanId
+VALUES (?, ?, ?, ?, ?, ?, ?, 'draft', ?, ?);
\ No newline at end of file
@@ -0,0 +1 @@
+INSERT INTO WebhookLog (ReceivedAt, Method, Uri, Headers, Body, ParseStatus, ParseError, EntityId) VALUES (?, ?, ?, ?, ?, ?, ?, ?);
\ No newline at end of file
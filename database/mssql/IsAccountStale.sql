@@ -0,0 +1 @@
+SELECT COUNT(*) FROM StaleAccounts WHERE AccountId = ?;
\ No newline at end of file
@@ -0,0 +1,7 @@
+MERGE StaleAccounts AS target
+USING (SELECT ? AS AccountId, ? AS RemoteModifiedDate, ? AS MarkedAt) AS source
+ON (target.AccountId = source.AccountId)
+WHEN MATCHED THEN
+    UPDATE SET RemoteModifiedDate = source.RemoteModifiedDate, MarkedAt = source.MarkedAt
+WHEN NOT MATCHED THEN
+    INSERT (AccountId, RemoteModifiedDate, MarkedAt) VALUES (source.AccountId, source.RemoteModifiedDate, source.MarkedAt);
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE DeletedAccounts SET RestoredAt = ?, RestoredAccountId = ? WHERE DeletedId = ? AND RestoredAt IS NULL;
@@ -0,0 +1,7 @@
+MERGE AccountScores AS target
+USING (SELECT ? AS AccountId, ? AS ScoreName, ? AS Score, ? AS ComputedAt) AS source
+ON (target.AccountId = source.AccountId AND target.ScoreName = source.ScoreName)
+WHEN MATCHED THEN
+    UPDATE SET Score = source.Score, ComputedAt = source.ComputedAt
+WHEN NOT MATCHED THEN
+    INSERT (AccountId, ScoreName, Score, ComputedAt) VALUES (source.AccountId, source.ScoreName, source.Score, source.ComputedAt);
\ No newline at end of file
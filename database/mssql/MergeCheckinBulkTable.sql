@@ -0,0 +1,21 @@
+SET IDENTITY_INSERT AccountCheckins ON;
+MERGE [AccountCheckins] AS target
+USING #BulkAccountCheckins AS source
+ON target.[CheckinId] = source.CheckinId
+WHEN MATCHED THEN
+	UPDATE SET
+		[CrmId] = source.CrmId,
+		[AccountId] = source.AccountId,
+		[LogDateTime] = source.LogDatetime,
+		[Type] = source.Type,
+		[Comments] = source.Comments,
+		[ExtraFields] = source.ExtraFields,
+		[EndpointType] = source.EndpointType,
+		[CreatedBy] = source.CreatedBy,
+		[UpdatedAt] = GETDATE()
+WHEN NOT MATCHED THEN
+	INSERT ([CheckinId], [CrmId], [AccountId], [LogDateTime], [Type], [Comments], [ExtraFields], [EndpointType], [CreatedBy])
+	VALUES (source.CheckinId, source.CrmId, source.AccountId, source.LogDatetime,
+	        source.Type, source.Comments, source.ExtraFields, source.EndpointType, source.CreatedBy);
+SET IDENTITY_INSERT AccountCheckins OFF;
+DROP TABLE #BulkAccountCheckins;
\ No newline at end of file
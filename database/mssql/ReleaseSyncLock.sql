@@ -0,0 +1,3 @@
+DELETE FROM SyncLocks
+WHERE LockName = ?
+  AND HolderId = ?;
\ No newline at end of file
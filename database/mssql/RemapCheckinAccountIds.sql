@@ -0,0 +1 @@
+UPDATE AccountCheckins SET AccountId = ? WHERE AccountId = ?;
\ No newline at end of file
@@ -0,0 +1,2 @@
+UPDATE AccountsPendingChanges SET AccountId = ?
+WHERE AccountId = ? AND Status = 'pending' AND ChangeType <> 'DELETE';
\ No newline at end of file
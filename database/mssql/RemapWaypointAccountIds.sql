@@ -0,0 +1 @@
+UPDATE RouteWaypoints SET CustomerId = ? WHERE CustomerId = ?;
\ No newline at end of file
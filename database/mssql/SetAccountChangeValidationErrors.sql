@@ -0,0 +1 @@
+UPDATE AccountsPendingChanges SET Status = 'failed', ValidationErrors = ?, ProcessedAt = GETDATE() WHERE ChangeId = ?;
\ No newline at end of file
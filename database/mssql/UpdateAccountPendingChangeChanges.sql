@@ -0,0 +1 @@
+UPDATE AccountsPendingChanges SET Changes = ?, Status = 'pending', ValidationErrors = NULL WHERE ChangeId = ? AND (Status = 'pending' OR (Status = 'failed' AND ValidationErrors IS NOT NULL));
@@ -0,0 +1 @@
+UPDATE DataSetValues SET Text = ?, DataSetPosition = ?, UpdatedAt = CURRENT_TIMESTAMP WHERE DataSetValueId = ?;
\ No newline at end of file
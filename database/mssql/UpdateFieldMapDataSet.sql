@@ -0,0 +1 @@
+UPDATE FieldMaps SET DataSetName = ?, DataSetLabel = ? WHERE FieldName = ? AND ObjectType = 'Account';
\ No newline at end of file
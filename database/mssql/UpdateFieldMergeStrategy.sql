@@ -0,0 +1 @@
+UPDATE FieldMaps SET MergeStrategy = ? WHERE FieldName = ? AND ObjectType = ?;
\ No newline at end of file
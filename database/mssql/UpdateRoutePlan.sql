@@ -0,0 +1,3 @@
+UPDATE RoutePlans
+SET Name = ?, RouteDate = ?, StartAddress = ?, DestinationAddress = ?, StartTime = ?, Waypoints = ?, UpdatedAt = ?
+WHERE PlanId = ? AND Status = 'draft';
\ No newline at end of file
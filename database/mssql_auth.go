@@ -0,0 +1,166 @@
+package database
+
+import (
+	"badgermaps/utils"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+)
+
+// SQL Server authentication methods, as db.auth takes them.
+const (
+	// MSSQLAuthSQL logs in with a SQL Server login and password.
+	MSSQLAuthSQL = "sql"
+	// MSSQLAuthWindows uses integrated authentication: the signed-in
+	// Windows user when no username is set, or a DOMAIN\user and password
+	// over NTLM.
+	MSSQLAuthWindows = "windows"
+	// MSSQLAuthAzureAD signs in to Azure SQL with a Microsoft Entra ID
+	// (Azure AD) access token from db.access_token_command.
+	MSSQLAuthAzureAD = "azure-ad"
+)
+
+// MSSQLAuthMethods lists the authentication methods, in the order the GUI
+// offers them.
+var MSSQLAuthMethods = []string{MSSQLAuthSQL, MSSQLAuthWindows, MSSQLAuthAzureAD}
+
+// DefaultAccessTokenCommand gets an Azure SQL access token from the Azure
+// CLI for the signed-in account, managed identity or service principal.
+const DefaultAccessTokenCommand = "az account get-access-token --resource https://database.windows.net/ --query accessToken --output tsv"
+
+// accessTokenTimeout bounds one run of the access token command.
+const accessTokenTimeout = 30 * time.Second
+
+// ValidateMSSQLAuth checks db.auth: it only applies to mssql databases,
+// Windows authentication takes a DOMAIN\user name or none, and outside
+// Windows there is no signed-in user to fall back on.
+func ValidateMSSQLAuth(cfg DBConfig) error {
+	if cfg.Auth == "" && cfg.AccessTokenCommand == "" {
+		return nil
+	}
+	if cfg.Type != "mssql" {
+		return fmt.Errorf("db.auth and db.access_token_command are only supported for mssql databases, not %s", cfg.Type)
+	}
+	switch strings.ToLower(cfg.Auth) {
+	case "", MSSQLAuthSQL:
+	case MSSQLAuthWindows:
+		if cfg.Username != "" && !strings.ContainsRune(cfg.Username, '\\') {
+			return fmt.Errorf("db.username must be DOMAIN\\user for windows authentication, or empty for the signed-in user")
+		}
+		if cfg.Username == "" && runtime.GOOS != "windows" {
+			return fmt.Errorf("windows authentication without a db.username only works on Windows; set DOMAIN\\user and its password")
+		}
+	case MSSQLAuthAzureAD:
+	default:
+		return fmt.Errorf("db.auth must be one of %s, got %q", strings.Join(MSSQLAuthMethods, ", "), cfg.Auth)
+	}
+	if cfg.AccessTokenCommand != "" && !strings.EqualFold(cfg.Auth, MSSQLAuthAzureAD) {
+		return fmt.Errorf("db.access_token_command is only used with db.auth %s", MSSQLAuthAzureAD)
+	}
+	return nil
+}
+
+// PromptMSSQLCredentials asks for the authentication method and the
+// credentials it takes.
+func PromptMSSQLCredentials(reader *bufio.Reader, cfg *DBConfig) {
+	cfg.Auth = utils.PromptChoice(reader, "Authentication", MSSQLAuthMethods)
+	switch cfg.Auth {
+	case MSSQLAuthWindows:
+		cfg.Username = utils.PromptString(reader, "Windows User (DOMAIN\\user, blank for the signed-in user)", cfg.Username)
+		if cfg.Username != "" {
+			cfg.Password = utils.PromptPassword(reader, "Windows Password", cfg.Password)
+		}
+	case MSSQLAuthAzureAD:
+		cfg.Username, cfg.Password = "", ""
+		cfg.AccessTokenCommand = utils.PromptString(reader, "Access Token Command (blank for the Azure CLI)", cfg.AccessTokenCommand)
+	default:
+		cfg.Username = utils.PromptString(reader, "Database Username", cfg.Username)
+		cfg.Password = utils.PromptPassword(reader, "Database Password", cfg.Password)
+	}
+}
+
+// authMethod returns the configured authentication method, SQL logins by
+// default.
+func (db *MSSQLConfig) authMethod() string {
+	if db.Auth == "" {
+		return MSSQLAuthSQL
+	}
+	return strings.ToLower(db.Auth)
+}
+
+// userInfo returns the credentials to put in the DSN. Integrated
+// authentication without a user name uses the signed-in Windows user, and
+// Azure AD sends a token instead.
+func (db *MSSQLConfig) userInfo() *url.Userinfo {
+	switch db.authMethod() {
+	case MSSQLAuthAzureAD:
+		return nil
+	case MSSQLAuthWindows:
+		if db.Username == "" {
+			return nil
+		}
+	}
+	return url.UserPassword(db.Username, db.Password)
+}
+
+// openWithAccessToken opens the pool through a connector that gets a new
+// access token for each connection.
+func (db *MSSQLConfig) openWithAccessToken(dsn string) (*sql.DB, error) {
+	cfg, err := msdsn.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if db.SSLCert != "" || db.SSLKey != "" {
+		if err := db.attachClientCert(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	connector, err := mssql.NewSecurityTokenConnector(cfg, func(ctx context.Context) (string, error) {
+		return db.accessToken(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// accessToken runs the access token command and returns what it prints.
+// The command is split on spaces; tools that cache tokens, such as the
+// Azure CLI, keep this cheap.
+func (db *MSSQLConfig) accessToken(ctx context.Context) (string, error) {
+	command := db.AccessTokenCommand
+	if command == "" {
+		command = DefaultAccessTokenCommand
+	}
+	args := strings.Fields(command)
+	ctx, cancel := context.WithTimeout(ctx, accessTokenTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("access token command %s did not finish within %s", args[0], accessTokenTimeout)
+	}
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return "", fmt.Errorf("access token command %s failed: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("access token command %s failed: %w", args[0], err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("access token command %s printed no token", args[0])
+	}
+	return token, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMSSQLAuthDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *MSSQLConfig
+		wantUser string
+	}{
+		{"sql login", &MSSQLConfig{Host: "db", Port: 1433, Username: "sa", Password: "pw"}, "sa"},
+		{"windows signed-in user", &MSSQLConfig{Host: "db", Port: 1433, Auth: MSSQLAuthWindows}, ""},
+		{"windows domain user", &MSSQLConfig{Host: "db", Port: 1433, Auth: MSSQLAuthWindows, Username: `CORP\sync`, Password: "pw"}, `CORP\sync`},
+		{"azure ad drops the login", &MSSQLConfig{Host: "db", Port: 1433, Auth: MSSQLAuthAzureAD, Username: "sa", Password: "pw"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, dsn := range []string{tt.db.DatabaseConnection(), tt.db.DatabaseConnectionWithTimeout()} {
+				u, err := url.Parse(dsn)
+				if err != nil {
+					t.Fatalf("parse %q: %v", dsn, err)
+				}
+				if got := u.User.Username(); got != tt.wantUser {
+					t.Errorf("user in %q = %q, want %q", dsn, got, tt.wantUser)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMSSQLAuth(t *testing.T) {
+	valid := []DBConfig{
+		{Type: "postgres"},
+		{Type: "mssql", Username: "sa"},
+		{Type: "mssql", Auth: MSSQLAuthWindows, Username: `CORP\sync`},
+		{Type: "mssql", Auth: MSSQLAuthAzureAD, AccessTokenCommand: "token-helper"},
+	}
+	for _, cfg := range valid {
+		if err := ValidateMSSQLAuth(cfg); err != nil {
+			t.Errorf("%+v: %v", cfg, err)
+		}
+	}
+	invalid := []DBConfig{
+		{Type: "postgres", Auth: MSSQLAuthWindows},
+		{Type: "mssql", Auth: "kerberos"},
+		{Type: "mssql", Auth: MSSQLAuthWindows, Username: "sync"},
+		{Type: "mssql", AccessTokenCommand: "token-helper"},
+	}
+	if runtime.GOOS != "windows" {
+		invalid = append(invalid, DBConfig{Type: "mssql", Auth: MSSQLAuthWindows})
+	}
+	for _, cfg := range invalid {
+		if err := ValidateMSSQLAuth(cfg); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
+
+func TestMSSQLAccessToken(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses echo and false")
+	}
+	db := &MSSQLConfig{AccessTokenCommand: "echo  eyJ0eXAi "}
+	token, err := db.accessToken(context.Background())
+	if err != nil || token != "eyJ0eXAi" {
+		t.Fatalf("accessToken = %q, %v", token, err)
+	}
+	db.AccessTokenCommand = "false"
+	if _, err := db.accessToken(context.Background()); err == nil || !strings.Contains(err.Error(), "false failed") {
+		t.Fatalf("expected the command failure, got %v", err)
+	}
+	db.AccessTokenCommand = "true"
+	if _, err := db.accessToken(context.Background()); err == nil {
+		t.Fatal("expected an error for an empty token")
+	}
+}
+
+func TestMSSQLAuthRoundTrip(t *testing.T) {
+	cfg := &DBConfig{Type: "mssql", Host: "db", Auth: MSSQLAuthAzureAD, AccessTokenCommand: "token-helper"}
+	db, err := NewDB(cfg)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	saved := &DBConfig{}
+	if err := db.SaveConfig(saved); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if saved.Auth != MSSQLAuthAzureAD || saved.AccessTokenCommand != "token-helper" {
+		t.Fatalf("auth settings not preserved: %+v", saved)
+	}
+}
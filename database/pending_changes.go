@@ -1,7 +1,10 @@
 package database
 
 import (
+	"badgermaps/errs"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -14,8 +17,22 @@ type AccountPendingChange struct {
 	Status      string
 	CreatedAt   time.Time
 	ProcessedAt sql.NullTime
+	// IdempotencyKey is sent with the push so a retried create is not
+	// applied twice; ContentHash is the hash of the change it was issued for.
+	IdempotencyKey sql.NullString
+	ContentHash    sql.NullString
+	// ValidationErrors maps each API field the push was rejected for to
+	// the API's messages. It is set only when Status is
+	// StatusValidationFailed.
+	ValidationErrors map[string][]string
 }
 
+// StatusValidationFailed is the Status of an account change the API
+// rejected with field errors. It is stored as 'failed' with the errors in
+// ValidationErrors, and unlike other failed changes it can be edited,
+// which queues it again.
+const StatusValidationFailed = "validation_failed"
+
 type CheckinPendingChange struct {
 	ChangeId     int
 	CheckinId    int
@@ -31,16 +48,34 @@ type CheckinPendingChange struct {
 	Status       string
 	CreatedAt    time.Time
 	ProcessedAt  sql.NullTime
+	// IdempotencyKey and ContentHash are as for AccountPendingChange.
+	IdempotencyKey sql.NullString
+	ContentHash    sql.NullString
 }
 
 func GetPendingAccountChanges(db DB) ([]AccountPendingChange, error) {
-	sqlText := db.GetSQL("GetPendingAccountChanges")
+	return queryAccountChanges(db, "GetPendingAccountChanges")
+}
+
+// GetAccountChanges returns every queued account change regardless of status.
+func GetAccountChanges(db DB) ([]AccountPendingChange, error) {
+	return queryAccountChanges(db, "GetAccountChanges")
+}
+
+// GetUnpushedAccountChanges returns the changes to an account that have not
+// reached BadgerMaps yet: pending, processing, or failed, oldest first.
+func GetUnpushedAccountChanges(db DB, accountID int) ([]AccountPendingChange, error) {
+	return queryAccountChanges(db, "GetUnpushedAccountChanges", accountID)
+}
+
+func queryAccountChanges(db DB, command string, args ...any) ([]AccountPendingChange, error) {
+	sqlText := db.GetSQL(command)
 	if sqlText == "" {
-		return nil, fmt.Errorf("unknown or unavailable SQL command: GetPendingAccountChanges")
+		return nil, fmt.Errorf("unknown or unavailable SQL command: %s", command)
 	}
 
 	sqlDB := db.GetDB()
-	rows, err := sqlDB.Query(sqlText)
+	rows, err := sqlDB.Query(sqlText, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,18 +84,34 @@ func GetPendingAccountChanges(db DB) ([]AccountPendingChange, error) {
 	var changes []AccountPendingChange
 	for rows.Next() {
 		var change AccountPendingChange
-		if err := rows.Scan(&change.ChangeId, &change.AccountId, &change.ChangeType, &change.Changes, &change.Status, &change.CreatedAt, &change.ProcessedAt); err != nil {
+		var validation sql.NullString
+		if err := rows.Scan(&change.ChangeId, &change.AccountId, &change.ChangeType, &change.Changes, &change.Status, &change.CreatedAt, &change.ProcessedAt, &change.IdempotencyKey, &change.ContentHash, &validation); err != nil {
 			return nil, err
 		}
+		if validation.Valid && change.Status == "failed" {
+			if err := json.Unmarshal([]byte(validation.String), &change.ValidationErrors); err != nil {
+				return nil, fmt.Errorf("invalid validation errors of change %d: %w", change.ChangeId, err)
+			}
+			change.Status = StatusValidationFailed
+		}
 		changes = append(changes, change)
 	}
 	return changes, nil
 }
 
 func GetPendingCheckinChanges(db DB) ([]CheckinPendingChange, error) {
-	sqlText := db.GetSQL("GetPendingCheckinChanges")
+	return queryCheckinChanges(db, "GetPendingCheckinChanges")
+}
+
+// GetCheckinChanges returns every queued check-in change regardless of status.
+func GetCheckinChanges(db DB) ([]CheckinPendingChange, error) {
+	return queryCheckinChanges(db, "GetCheckinChanges")
+}
+
+func queryCheckinChanges(db DB, command string) ([]CheckinPendingChange, error) {
+	sqlText := db.GetSQL(command)
 	if sqlText == "" {
-		return nil, fmt.Errorf("unknown or unavailable SQL command: GetPendingCheckinChanges")
+		return nil, fmt.Errorf("unknown or unavailable SQL command: %s", command)
 	}
 
 	sqlDB := db.GetDB()
@@ -88,6 +139,8 @@ func GetPendingCheckinChanges(db DB) ([]CheckinPendingChange, error) {
 			&change.Status,
 			&change.CreatedAt,
 			&change.ProcessedAt,
+			&change.IdempotencyKey,
+			&change.ContentHash,
 		); err != nil {
 			return nil, err
 		}
@@ -97,7 +150,7 @@ func GetPendingCheckinChanges(db DB) ([]CheckinPendingChange, error) {
 }
 
 func UpdatePendingChangeStatus(db DB, table string, changeId int, status string) error {
-	sqlText := fmt.Sprintf(db.GetSQL("UpdatePendingChangeStatus"), table)
+	sqlText := fmt.Sprintf(db.GetSQL("UpdatePendingChangeStatus"), QualifiedName(db, table))
 	if sqlText == "" {
 		return fmt.Errorf("unknown or unavailable SQL command: UpdatePendingChangeStatus")
 	}
@@ -106,3 +159,113 @@ func UpdatePendingChangeStatus(db DB, table string, changeId int, status string)
 	_, err := sqlDB.Exec(sqlText, status, changeId)
 	return err
 }
+
+// SetAccountChangeValidationErrors marks an account change failed with the
+// field errors the API rejected it for.
+func SetAccountChangeValidationErrors(db DB, changeId int, fields map[string][]string) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return RunCommand(db, "SetAccountChangeValidationErrors", string(data), changeId)
+}
+
+// DeleteAccountPendingChange removes a staged account change.
+func DeleteAccountPendingChange(db DB, changeId int) error {
+	return RunCommand(db, "DeleteAccountPendingChange", changeId)
+}
+
+// StageAccountChange queues an account change under a new idempotency key
+// and records the account's current UpdatedAt so the push can detect edits
+// made after staging.
+func StageAccountChange(db DB, accountID int, changeType, changes string) error {
+	return StageKeyedAccountChange(db, NewIdempotencyKey(), accountID, changeType, changes)
+}
+
+// StageKeyedAccountChange is StageAccountChange with the idempotency key
+// chosen by the caller.
+func StageKeyedAccountChange(db DB, key string, accountID int, changeType, changes string) error {
+	return RunCommand(db, "InsertAccountPendingChange", accountID, changeType, changes,
+		key, AccountChangeHash(accountID, changeType, changes), accountID)
+}
+
+// StageCheckinChange queues a check-in change under its IdempotencyKey, or
+// a new key when it has none. ChangeId, Status, ContentHash, and the
+// timestamps of change are ignored.
+func StageCheckinChange(db DB, change CheckinPendingChange) error {
+	key := change.IdempotencyKey.String
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+	return RunCommand(db, "InsertCheckinPendingChange",
+		change.CheckinId, change.AccountId, change.CrmId, change.LogDatetime, change.Type, change.Comments,
+		change.ExtraFields, change.EndpointType, change.CreatedBy, change.ChangeType,
+		key, CheckinChangeHash(change))
+}
+
+// ChangeConflict describes a staged change whose underlying row was modified
+// after it was staged.
+type ChangeConflict struct {
+	ChangeId       int
+	BaseVersion    string
+	CurrentVersion string
+}
+
+// GetAccountChangeConflict returns the conflict for a staged account change,
+// or nil when the account has not changed since the change was staged.
+// Changes staged without a version are never reported as conflicts.
+func GetAccountChangeConflict(db DB, changeId int) (*ChangeConflict, error) {
+	sqlText := db.GetSQL("GetAccountChangeConflict")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountChangeConflict")
+	}
+
+	var base, current sql.NullString
+	err := db.GetDB().QueryRow(sqlText, changeId).Scan(&base, &current)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ChangeConflict{ChangeId: changeId, BaseVersion: base.String, CurrentVersion: current.String}, nil
+}
+
+// ErrChangeNotPending is returned when editing a staged change that has
+// already been pushed or is being pushed. It is an errs.ErrConflict.
+var ErrChangeNotPending = errs.New(errs.ErrConflict, errors.New("change is no longer pending"))
+
+// UpdateAccountChangeChanges replaces the JSON of a pending account change.
+// A change that failed validation is queued again and its errors cleared.
+func UpdateAccountChangeChanges(db DB, changeId int, changes string) error {
+	return execPendingEdit(db, "UpdateAccountPendingChangeChanges", changes, changeId)
+}
+
+// RebaseAccountPendingChange replaces the JSON of a conflicting account
+// change and records the account's current UpdatedAt as its base, so the
+// next push no longer reports the conflict. The change is queued again.
+func RebaseAccountPendingChange(db DB, changeId int, changes string) error {
+	return execPendingEdit(db, "RebaseAccountPendingChange", changes, changeId)
+}
+
+// UpdateCheckinChangeFields replaces the editable fields of a pending
+// check-in change.
+func UpdateCheckinChangeFields(db DB, change CheckinPendingChange) error {
+	return execPendingEdit(db, "UpdateCheckinPendingChangeFields",
+		change.CrmId, change.LogDatetime, change.Type, change.Comments, change.ChangeId)
+}
+
+func execPendingEdit(db DB, command string, args ...any) error {
+	sqlText := db.GetSQL(command)
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	result, err := db.GetDB().Exec(sqlText, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrChangeNotPending
+	}
+	return nil
+}
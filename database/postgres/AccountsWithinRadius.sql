@@ -0,0 +1,6 @@
+SELECT AccountId FROM AccountLocations
+WHERE Latitude IS NOT NULL AND Longitude IS NOT NULL
+  AND 2 * 6371.0 * ASIN(LEAST(1.0, SQRT(
+    SIN((RADIANS(Latitude) - %[1]s) / 2) * SIN((RADIANS(Latitude) - %[1]s) / 2)
+    + %[2]s * COS(RADIANS(Latitude)) * SIN((RADIANS(Longitude) - %[3]s) / 2) * SIN((RADIANS(Longitude) - %[3]s) / 2)
+  ))) <= %[4]s
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountCheckinsPendingChanges ADD COLUMN IF NOT EXISTS IdempotencyKey VARCHAR(64);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD COLUMN IF NOT EXISTS BaseUpdatedAt TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD COLUMN IF NOT EXISTS ValidationErrors TEXT;
\ No newline at end of file
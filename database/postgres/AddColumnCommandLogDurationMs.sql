@@ -0,0 +1 @@
+ALTER TABLE CommandLog ADD COLUMN IF NOT EXISTS DurationMs INTEGER;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE CommandLog ADD COLUMN IF NOT EXISTS Flags TEXT;
\ No newline at end of file
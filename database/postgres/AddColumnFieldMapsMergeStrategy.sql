@@ -0,0 +1 @@
+ALTER TABLE FieldMaps ADD COLUMN IF NOT EXISTS MergeStrategy TEXT NOT NULL DEFAULT 'overwrite' CHECK(MergeStrategy IN ('overwrite', 'prefer-local', 'append', 'longest'));
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE SyncHistory ADD COLUMN IF NOT EXISTS Note TEXT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD COLUMN IF NOT EXISTS EntityId BIGINT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD COLUMN IF NOT EXISTS ParseError TEXT;
\ No newline at end of file
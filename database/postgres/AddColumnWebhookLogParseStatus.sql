@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD COLUMN IF NOT EXISTS ParseStatus VARCHAR(16);
\ No newline at end of file
@@ -0,0 +1,2 @@
+UPDATE AccountCheckins SET CrmId = NULL, Comments = NULL, ExtraFields = NULL, CreatedBy = NULL
+WHERE AccountId = $1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE RouteWaypoints SET Name = $1, Address = NULL, Suite = NULL, City = NULL, State = NULL, Zipcode = NULL,
+    Location = NULL, Latitude = NULL, Longitude = NULL, CompleteAddress = NULL, PlaceId = NULL
+WHERE CustomerId = $2;
\ No newline at end of file
@@ -1,3 +1,3 @@
 SELECT COUNT(*) 
 FROM information_schema.columns 
-WHERE table_name = ? AND column_name = ? 
\ No newline at end of file
+WHERE table_name = ? AND column_name = ? AND table_schema = current_schema() 
\ No newline at end of file
@@ -1,3 +1,3 @@
 SELECT COUNT(*) 
 FROM pg_indexes 
-WHERE indexname = ? 
\ No newline at end of file
+WHERE indexname = ? AND schemaname = current_schema() 
\ No newline at end of file
@@ -1 +1 @@
-SELECT count(*) FROM information_schema.routines WHERE routine_name = ?;
+SELECT count(*) FROM information_schema.routines WHERE routine_name = ? AND routine_schema = current_schema();
@@ -1 +1 @@
-SELECT count(*) FROM information_schema.triggers WHERE trigger_name = ?;
+SELECT count(*) FROM information_schema.triggers WHERE trigger_name = ? AND trigger_schema = current_schema();
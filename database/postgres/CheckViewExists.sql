@@ -1 +1 @@
-SELECT count(*) FROM information_schema.views WHERE table_name = ?;
+SELECT count(*) FROM information_schema.views WHERE table_name = ? AND table_schema = current_schema();
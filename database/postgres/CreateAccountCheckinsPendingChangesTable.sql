@@ -12,5 +12,7 @@ CREATE TABLE IF NOT EXISTS AccountCheckinsPendingChanges (
     ChangeType VARCHAR(10) NOT NULL CHECK(ChangeType IN ('CREATE', 'UPDATE', 'DELETE')),
     Status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
     CreatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    ProcessedAt TIMESTAMP
+    ProcessedAt TIMESTAMP,
+    IdempotencyKey VARCHAR(64),
+    ContentHash VARCHAR(64)
 );
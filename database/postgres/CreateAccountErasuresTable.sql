@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS AccountErasures (
+    ErasureId SERIAL PRIMARY KEY,
+    AccountId INTEGER NOT NULL,
+    Mode TEXT NOT NULL CHECK(Mode IN ('delete', 'anonymize')),
+    ReportId TEXT NOT NULL,
+    ReportDigest TEXT NOT NULL,
+    DeleteChangeId INTEGER,
+    ErasedAt TIMESTAMP NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,237 @@
+-- Captures changes made to Accounts outside the app as pending changes.
+-- The app connects with application_name badgermaps-sync, so its own
+-- writes are skipped.
+CREATE OR REPLACE FUNCTION CaptureAccountChange()
+RETURNS TRIGGER AS $$
+DECLARE
+    changes JSONB := '{}'::jsonb;
+BEGIN
+    IF current_setting('application_name', true) = 'badgermaps-sync' THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes)
+        VALUES (OLD.AccountId, 'DELETE', '{}');
+        RETURN NULL;
+    END IF;
+    IF NEW.FirstName IS DISTINCT FROM OLD.FirstName THEN
+        changes := changes || jsonb_build_object('first_name', COALESCE(NEW.FirstName::text, ''));
+    END IF;
+    IF NEW.LastName IS DISTINCT FROM OLD.LastName THEN
+        changes := changes || jsonb_build_object('last_name', COALESCE(NEW.LastName::text, ''));
+    END IF;
+    IF NEW.PhoneNumber IS DISTINCT FROM OLD.PhoneNumber THEN
+        changes := changes || jsonb_build_object('phone_number', COALESCE(NEW.PhoneNumber::text, ''));
+    END IF;
+    IF NEW.Email IS DISTINCT FROM OLD.Email THEN
+        changes := changes || jsonb_build_object('email', COALESCE(NEW.Email::text, ''));
+    END IF;
+    IF NEW.CustomerId IS DISTINCT FROM OLD.CustomerId THEN
+        changes := changes || jsonb_build_object('customer_id', COALESCE(NEW.CustomerId::text, ''));
+    END IF;
+    IF NEW.Notes IS DISTINCT FROM OLD.Notes THEN
+        changes := changes || jsonb_build_object('notes', COALESCE(NEW.Notes::text, ''));
+    END IF;
+    IF NEW.CrmId IS DISTINCT FROM OLD.CrmId THEN
+        changes := changes || jsonb_build_object('crm_id', COALESCE(NEW.CrmId::text, ''));
+    END IF;
+    IF NEW.AccountOwner IS DISTINCT FROM OLD.AccountOwner THEN
+        changes := changes || jsonb_build_object('account_owner', COALESCE(NEW.AccountOwner::text, ''));
+    END IF;
+    IF NEW.FollowUpDate IS DISTINCT FROM OLD.FollowUpDate THEN
+        changes := changes || jsonb_build_object('follow_up_date', COALESCE(NEW.FollowUpDate::text, ''));
+    END IF;
+    IF NEW.CustomNumeric IS DISTINCT FROM OLD.CustomNumeric THEN
+        changes := changes || jsonb_build_object('custom_numeric', COALESCE(NEW.CustomNumeric::text, ''));
+    END IF;
+    IF NEW.CustomText IS DISTINCT FROM OLD.CustomText THEN
+        changes := changes || jsonb_build_object('custom_text', COALESCE(NEW.CustomText::text, ''));
+    END IF;
+    IF NEW.CustomNumeric2 IS DISTINCT FROM OLD.CustomNumeric2 THEN
+        changes := changes || jsonb_build_object('custom_numeric2', COALESCE(NEW.CustomNumeric2::text, ''));
+    END IF;
+    IF NEW.CustomText2 IS DISTINCT FROM OLD.CustomText2 THEN
+        changes := changes || jsonb_build_object('custom_text2', COALESCE(NEW.CustomText2::text, ''));
+    END IF;
+    IF NEW.CustomNumeric3 IS DISTINCT FROM OLD.CustomNumeric3 THEN
+        changes := changes || jsonb_build_object('custom_numeric3', COALESCE(NEW.CustomNumeric3::text, ''));
+    END IF;
+    IF NEW.CustomText3 IS DISTINCT FROM OLD.CustomText3 THEN
+        changes := changes || jsonb_build_object('custom_text3', COALESCE(NEW.CustomText3::text, ''));
+    END IF;
+    IF NEW.CustomNumeric4 IS DISTINCT FROM OLD.CustomNumeric4 THEN
+        changes := changes || jsonb_build_object('custom_numeric4', COALESCE(NEW.CustomNumeric4::text, ''));
+    END IF;
+    IF NEW.CustomText4 IS DISTINCT FROM OLD.CustomText4 THEN
+        changes := changes || jsonb_build_object('custom_text4', COALESCE(NEW.CustomText4::text, ''));
+    END IF;
+    IF NEW.CustomNumeric5 IS DISTINCT FROM OLD.CustomNumeric5 THEN
+        changes := changes || jsonb_build_object('custom_numeric5', COALESCE(NEW.CustomNumeric5::text, ''));
+    END IF;
+    IF NEW.CustomText5 IS DISTINCT FROM OLD.CustomText5 THEN
+        changes := changes || jsonb_build_object('custom_text5', COALESCE(NEW.CustomText5::text, ''));
+    END IF;
+    IF NEW.CustomNumeric6 IS DISTINCT FROM OLD.CustomNumeric6 THEN
+        changes := changes || jsonb_build_object('custom_numeric6', COALESCE(NEW.CustomNumeric6::text, ''));
+    END IF;
+    IF NEW.CustomText6 IS DISTINCT FROM OLD.CustomText6 THEN
+        changes := changes || jsonb_build_object('custom_text6', COALESCE(NEW.CustomText6::text, ''));
+    END IF;
+    IF NEW.CustomNumeric7 IS DISTINCT FROM OLD.CustomNumeric7 THEN
+        changes := changes || jsonb_build_object('custom_numeric7', COALESCE(NEW.CustomNumeric7::text, ''));
+    END IF;
+    IF NEW.CustomText7 IS DISTINCT FROM OLD.CustomText7 THEN
+        changes := changes || jsonb_build_object('custom_text7', COALESCE(NEW.CustomText7::text, ''));
+    END IF;
+    IF NEW.CustomNumeric8 IS DISTINCT FROM OLD.CustomNumeric8 THEN
+        changes := changes || jsonb_build_object('custom_numeric8', COALESCE(NEW.CustomNumeric8::text, ''));
+    END IF;
+    IF NEW.CustomText8 IS DISTINCT FROM OLD.CustomText8 THEN
+        changes := changes || jsonb_build_object('custom_text8', COALESCE(NEW.CustomText8::text, ''));
+    END IF;
+    IF NEW.CustomNumeric9 IS DISTINCT FROM OLD.CustomNumeric9 THEN
+        changes := changes || jsonb_build_object('custom_numeric9', COALESCE(NEW.CustomNumeric9::text, ''));
+    END IF;
+    IF NEW.CustomText9 IS DISTINCT FROM OLD.CustomText9 THEN
+        changes := changes || jsonb_build_object('custom_text9', COALESCE(NEW.CustomText9::text, ''));
+    END IF;
+    IF NEW.CustomNumeric10 IS DISTINCT FROM OLD.CustomNumeric10 THEN
+        changes := changes || jsonb_build_object('custom_numeric10', COALESCE(NEW.CustomNumeric10::text, ''));
+    END IF;
+    IF NEW.CustomText10 IS DISTINCT FROM OLD.CustomText10 THEN
+        changes := changes || jsonb_build_object('custom_text10', COALESCE(NEW.CustomText10::text, ''));
+    END IF;
+    IF NEW.CustomNumeric11 IS DISTINCT FROM OLD.CustomNumeric11 THEN
+        changes := changes || jsonb_build_object('custom_numeric11', COALESCE(NEW.CustomNumeric11::text, ''));
+    END IF;
+    IF NEW.CustomText11 IS DISTINCT FROM OLD.CustomText11 THEN
+        changes := changes || jsonb_build_object('custom_text11', COALESCE(NEW.CustomText11::text, ''));
+    END IF;
+    IF NEW.CustomNumeric12 IS DISTINCT FROM OLD.CustomNumeric12 THEN
+        changes := changes || jsonb_build_object('custom_numeric12', COALESCE(NEW.CustomNumeric12::text, ''));
+    END IF;
+    IF NEW.CustomText12 IS DISTINCT FROM OLD.CustomText12 THEN
+        changes := changes || jsonb_build_object('custom_text12', COALESCE(NEW.CustomText12::text, ''));
+    END IF;
+    IF NEW.CustomNumeric13 IS DISTINCT FROM OLD.CustomNumeric13 THEN
+        changes := changes || jsonb_build_object('custom_numeric13', COALESCE(NEW.CustomNumeric13::text, ''));
+    END IF;
+    IF NEW.CustomText13 IS DISTINCT FROM OLD.CustomText13 THEN
+        changes := changes || jsonb_build_object('custom_text13', COALESCE(NEW.CustomText13::text, ''));
+    END IF;
+    IF NEW.CustomNumeric14 IS DISTINCT FROM OLD.CustomNumeric14 THEN
+        changes := changes || jsonb_build_object('custom_numeric14', COALESCE(NEW.CustomNumeric14::text, ''));
+    END IF;
+    IF NEW.CustomText14 IS DISTINCT FROM OLD.CustomText14 THEN
+        changes := changes || jsonb_build_object('custom_text14', COALESCE(NEW.CustomText14::text, ''));
+    END IF;
+    IF NEW.CustomNumeric15 IS DISTINCT FROM OLD.CustomNumeric15 THEN
+        changes := changes || jsonb_build_object('custom_numeric15', COALESCE(NEW.CustomNumeric15::text, ''));
+    END IF;
+    IF NEW.CustomText15 IS DISTINCT FROM OLD.CustomText15 THEN
+        changes := changes || jsonb_build_object('custom_text15', COALESCE(NEW.CustomText15::text, ''));
+    END IF;
+    IF NEW.CustomNumeric16 IS DISTINCT FROM OLD.CustomNumeric16 THEN
+        changes := changes || jsonb_build_object('custom_numeric16', COALESCE(NEW.CustomNumeric16::text, ''));
+    END IF;
+    IF NEW.CustomText16 IS DISTINCT FROM OLD.CustomText16 THEN
+        changes := changes || jsonb_build_object('custom_text16', COALESCE(NEW.CustomText16::text, ''));
+    END IF;
+    IF NEW.CustomNumeric17 IS DISTINCT FROM OLD.CustomNumeric17 THEN
+        changes := changes || jsonb_build_object('custom_numeric17', COALESCE(NEW.CustomNumeric17::text, ''));
+    END IF;
+    IF NEW.CustomText17 IS DISTINCT FROM OLD.CustomText17 THEN
+        changes := changes || jsonb_build_object('custom_text17', COALESCE(NEW.CustomText17::text, ''));
+    END IF;
+    IF NEW.CustomNumeric18 IS DISTINCT FROM OLD.CustomNumeric18 THEN
+        changes := changes || jsonb_build_object('custom_numeric18', COALESCE(NEW.CustomNumeric18::text, ''));
+    END IF;
+    IF NEW.CustomText18 IS DISTINCT FROM OLD.CustomText18 THEN
+        changes := changes || jsonb_build_object('custom_text18', COALESCE(NEW.CustomText18::text, ''));
+    END IF;
+    IF NEW.CustomNumeric19 IS DISTINCT FROM OLD.CustomNumeric19 THEN
+        changes := changes || jsonb_build_object('custom_numeric19', COALESCE(NEW.CustomNumeric19::text, ''));
+    END IF;
+    IF NEW.CustomText19 IS DISTINCT FROM OLD.CustomText19 THEN
+        changes := changes || jsonb_build_object('custom_text19', COALESCE(NEW.CustomText19::text, ''));
+    END IF;
+    IF NEW.CustomNumeric20 IS DISTINCT FROM OLD.CustomNumeric20 THEN
+        changes := changes || jsonb_build_object('custom_numeric20', COALESCE(NEW.CustomNumeric20::text, ''));
+    END IF;
+    IF NEW.CustomText20 IS DISTINCT FROM OLD.CustomText20 THEN
+        changes := changes || jsonb_build_object('custom_text20', COALESCE(NEW.CustomText20::text, ''));
+    END IF;
+    IF NEW.CustomNumeric21 IS DISTINCT FROM OLD.CustomNumeric21 THEN
+        changes := changes || jsonb_build_object('custom_numeric21', COALESCE(NEW.CustomNumeric21::text, ''));
+    END IF;
+    IF NEW.CustomText21 IS DISTINCT FROM OLD.CustomText21 THEN
+        changes := changes || jsonb_build_object('custom_text21', COALESCE(NEW.CustomText21::text, ''));
+    END IF;
+    IF NEW.CustomNumeric22 IS DISTINCT FROM OLD.CustomNumeric22 THEN
+        changes := changes || jsonb_build_object('custom_numeric22', COALESCE(NEW.CustomNumeric22::text, ''));
+    END IF;
+    IF NEW.CustomText22 IS DISTINCT FROM OLD.CustomText22 THEN
+        changes := changes || jsonb_build_object('custom_text22', COALESCE(NEW.CustomText22::text, ''));
+    END IF;
+    IF NEW.CustomNumeric23 IS DISTINCT FROM OLD.CustomNumeric23 THEN
+        changes := changes || jsonb_build_object('custom_numeric23', COALESCE(NEW.CustomNumeric23::text, ''));
+    END IF;
+    IF NEW.CustomText23 IS DISTINCT FROM OLD.CustomText23 THEN
+        changes := changes || jsonb_build_object('custom_text23', COALESCE(NEW.CustomText23::text, ''));
+    END IF;
+    IF NEW.CustomNumeric24 IS DISTINCT FROM OLD.CustomNumeric24 THEN
+        changes := changes || jsonb_build_object('custom_numeric24', COALESCE(NEW.CustomNumeric24::text, ''));
+    END IF;
+    IF NEW.CustomText24 IS DISTINCT FROM OLD.CustomText24 THEN
+        changes := changes || jsonb_build_object('custom_text24', COALESCE(NEW.CustomText24::text, ''));
+    END IF;
+    IF NEW.CustomNumeric25 IS DISTINCT FROM OLD.CustomNumeric25 THEN
+        changes := changes || jsonb_build_object('custom_numeric25', COALESCE(NEW.CustomNumeric25::text, ''));
+    END IF;
+    IF NEW.CustomText25 IS DISTINCT FROM OLD.CustomText25 THEN
+        changes := changes || jsonb_build_object('custom_text25', COALESCE(NEW.CustomText25::text, ''));
+    END IF;
+    IF NEW.CustomNumeric26 IS DISTINCT FROM OLD.CustomNumeric26 THEN
+        changes := changes || jsonb_build_object('custom_numeric26', COALESCE(NEW.CustomNumeric26::text, ''));
+    END IF;
+    IF NEW.CustomText26 IS DISTINCT FROM OLD.CustomText26 THEN
+        changes := changes || jsonb_build_object('custom_text26', COALESCE(NEW.CustomText26::text, ''));
+    END IF;
+    IF NEW.CustomNumeric27 IS DISTINCT FROM OLD.CustomNumeric27 THEN
+        changes := changes || jsonb_build_object('custom_numeric27', COALESCE(NEW.CustomNumeric27::text, ''));
+    END IF;
+    IF NEW.CustomText27 IS DISTINCT FROM OLD.CustomText27 THEN
+        changes := changes || jsonb_build_object('custom_text27', COALESCE(NEW.CustomText27::text, ''));
+    END IF;
+    IF NEW.CustomNumeric28 IS DISTINCT FROM OLD.CustomNumeric28 THEN
+        changes := changes || jsonb_build_object('custom_numeric28', COALESCE(NEW.CustomNumeric28::text, ''));
+    END IF;
+    IF NEW.CustomText28 IS DISTINCT FROM OLD.CustomText28 THEN
+        changes := changes || jsonb_build_object('custom_text28', COALESCE(NEW.CustomText28::text, ''));
+    END IF;
+    IF NEW.CustomNumeric29 IS DISTINCT FROM OLD.CustomNumeric29 THEN
+        changes := changes || jsonb_build_object('custom_numeric29', COALESCE(NEW.CustomNumeric29::text, ''));
+    END IF;
+    IF NEW.CustomText29 IS DISTINCT FROM OLD.CustomText29 THEN
+        changes := changes || jsonb_build_object('custom_text29', COALESCE(NEW.CustomText29::text, ''));
+    END IF;
+    IF NEW.CustomNumeric30 IS DISTINCT FROM OLD.CustomNumeric30 THEN
+        changes := changes || jsonb_build_object('custom_numeric30', COALESCE(NEW.CustomNumeric30::text, ''));
+    END IF;
+    IF NEW.CustomText30 IS DISTINCT FROM OLD.CustomText30 THEN
+        changes := changes || jsonb_build_object('custom_text30', COALESCE(NEW.CustomText30::text, ''));
+    END IF;
+    IF changes <> '{}'::jsonb THEN
+        INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, BaseUpdatedAt)
+        VALUES (NEW.AccountId, 'UPDATE', changes::text, NEW.UpdatedAt);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS AccountsChangeCapture ON Accounts;
+
+CREATE TRIGGER AccountsChangeCapture
+AFTER UPDATE OR DELETE ON Accounts
+FOR EACH ROW
+EXECUTE FUNCTION CaptureAccountChange();
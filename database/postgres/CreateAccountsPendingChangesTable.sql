@@ -5,5 +5,9 @@ CREATE TABLE IF NOT EXISTS AccountsPendingChanges (
     Changes TEXT,
     Status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
     CreatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    ProcessedAt TIMESTAMP
+    ProcessedAt TIMESTAMP,
+    BaseUpdatedAt TIMESTAMP,
+    IdempotencyKey VARCHAR(64),
+    ContentHash VARCHAR(64),
+    ValidationErrors TEXT
 );
@@ -25,7 +25,7 @@ BEGIN
     INTO select_list
     FROM information_schema.columns c
     LEFT JOIN "DataSets" ds ON c.column_name = ds."AccountField" AND ds."ProfileId" = profile_id
-    WHERE c.table_schema = 'public' AND c.table_name = 'Accounts';
+    WHERE c.table_schema = current_schema() AND c.table_name = 'Accounts';
 
     view_sql := 'CREATE OR REPLACE VIEW "AccountsWithLabels" AS SELECT ' || select_list || ' FROM "Accounts" a;';
 
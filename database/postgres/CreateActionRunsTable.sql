@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS ActionRuns (
+    RunId SERIAL PRIMARY KEY,
+    ActionName TEXT,
+    ActionType TEXT NOT NULL,
+    TriggeredBy TEXT NOT NULL,
+    Source TEXT,
+    Config TEXT,
+    Event TEXT,
+    Args TEXT,
+    Status TEXT NOT NULL CHECK(Status IN ('success', 'failed')),
+    ExitCode INTEGER,
+    CommandOutput TEXT,
+    RowsAffected BIGINT,
+    ErrorMessage TEXT,
+    RerunOf INTEGER,
+    StartedAt TIMESTAMP NOT NULL,
+    DurationMs BIGINT
+);
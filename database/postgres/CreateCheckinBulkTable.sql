@@ -0,0 +1 @@
+CREATE TEMP TABLE BulkAccountCheckins (LIKE AccountCheckins INCLUDING DEFAULTS) ON COMMIT DROP;
\ No newline at end of file
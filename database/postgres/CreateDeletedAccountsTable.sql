@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS DeletedAccounts (
+    DeletedId SERIAL PRIMARY KEY,
+    AccountId INTEGER NOT NULL,
+    ChangeId INTEGER,
+    FullName TEXT,
+    Data TEXT NOT NULL,
+    DeletedAt TIMESTAMP NOT NULL,
+    RestoredAt TIMESTAMP,
+    RestoredAccountId INTEGER
+);
@@ -4,5 +4,7 @@ CREATE TABLE IF NOT EXISTS FieldMaps (
     JsonField TEXT,
     DataSetName TEXT,
     DataSetLabel TEXT,
+    SyncDirection TEXT NOT NULL DEFAULT 'both' CHECK(SyncDirection IN ('both', 'pull', 'push')), -- both, pull (never pushed), or push (never overwritten by pulls)
+    MergeStrategy TEXT NOT NULL DEFAULT 'overwrite' CHECK(MergeStrategy IN ('overwrite', 'prefer-local', 'append', 'longest')), -- how a pulled value is merged into the stored one
     PRIMARY KEY (FieldName, ObjectType)
 );
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS IdRemap (
+    RemapId SERIAL PRIMARY KEY,
+    EntityType TEXT NOT NULL,
+    OldId INTEGER NOT NULL,
+    NewId INTEGER NOT NULL,
+    Reason TEXT,
+    RowsRewritten INTEGER NOT NULL DEFAULT 0,
+    RemappedAt TIMESTAMP NOT NULL
+);
\ No newline at end of file
@@ -7,3 +7,4 @@ CREATE INDEX IF NOT EXISTS IdxRoutesRouteDate ON Routes(RouteDate);
 CREATE INDEX IF NOT EXISTS IdxRouteWaypointsRouteId ON RouteWaypoints(RouteId);
 CREATE INDEX IF NOT EXISTS IdxAccountLocationsAccountId ON AccountLocations(AccountId);
 CREATE INDEX IF NOT EXISTS IdxSyncHistoryStartedAt ON SyncHistory(StartedAt DESC);
+CREATE INDEX IF NOT EXISTS IdxActionRunsActionName ON ActionRuns(ActionName, RunId);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS RoutePlans (
+    PlanId SERIAL PRIMARY KEY,
+    Name TEXT NOT NULL,
+    RouteDate VARCHAR(10) NOT NULL,
+    StartAddress TEXT,
+    DestinationAddress TEXT,
+    StartTime VARCHAR(5),
+    TemplateRouteId INTEGER,
+    Waypoints TEXT,
+    Status TEXT NOT NULL CHECK(Status IN ('draft', 'pushed')),
+    RouteId INTEGER,
+    CreatedAt TIMESTAMP NOT NULL,
+    UpdatedAt TIMESTAMP NOT NULL,
+    PushedAt TIMESTAMP
+);
\ No newline at end of file
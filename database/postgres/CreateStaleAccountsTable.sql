@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS StaleAccounts (
+    AccountId INTEGER PRIMARY KEY,
+    RemoteModifiedDate TEXT,
+    MarkedAt TIMESTAMP NOT NULL
+);
\ No newline at end of file
@@ -12,5 +12,6 @@ CREATE TABLE IF NOT EXISTS SyncHistory (
     CompletedAt TIMESTAMP,
     DurationSeconds INTEGER,
     Summary TEXT,
-    Details TEXT
+    Details TEXT,
+    Note TEXT
 );
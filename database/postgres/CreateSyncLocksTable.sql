@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS SyncLocks (
+    LockName VARCHAR(64) PRIMARY KEY,
+    HolderId VARCHAR(64) NOT NULL,
+    Host TEXT,
+    Pid INTEGER,
+    Operation TEXT,
+    AcquiredAt TIMESTAMP NOT NULL,
+    HeartbeatAt TIMESTAMP NOT NULL
+);
\ No newline at end of file
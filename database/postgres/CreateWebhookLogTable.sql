@@ -4,5 +4,8 @@ CREATE TABLE IF NOT EXISTS WebhookLog (
     Method VARCHAR(10) NOT NULL,
     Uri VARCHAR(255) NOT NULL,
     Headers TEXT,
-    Body TEXT
+    Body TEXT,
+    ParseStatus VARCHAR(16),
+    ParseError TEXT,
+    EntityId BIGINT
 );
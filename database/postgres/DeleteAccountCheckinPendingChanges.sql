@@ -0,0 +1 @@
+DELETE FROM AccountCheckinsPendingChanges WHERE AccountId = $1;
\ No newline at end of file
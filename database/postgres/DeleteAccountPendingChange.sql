@@ -0,0 +1 @@
+DELETE FROM AccountsPendingChanges WHERE ChangeId = $1;
\ No newline at end of file
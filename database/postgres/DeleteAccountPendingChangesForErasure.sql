@@ -0,0 +1,2 @@
+DELETE FROM AccountsPendingChanges
+WHERE AccountId = $1 AND ChangeId <> $2;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM DeletedAccounts WHERE AccountId = $1 OR RestoredAccountId = $2;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM AccountScores WHERE ComputedAt < $1;
\ No newline at end of file
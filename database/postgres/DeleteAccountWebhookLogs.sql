@@ -0,0 +1,2 @@
+DELETE FROM WebhookLog
+WHERE EntityId = $1 OR EntityId IN (SELECT CheckinId FROM AccountCheckins WHERE AccountId = $2);
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM AccountCheckins WHERE CheckinId = $1;
@@ -0,0 +1 @@
+DELETE FROM DataSets WHERE Name = $1 AND ProfileId = $2;
\ No newline at end of file
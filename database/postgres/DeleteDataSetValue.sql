@@ -0,0 +1 @@
+DELETE FROM DataSetValues WHERE DataSetValueId = $1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM RouteWaypoints
+WHERE RouteId IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM Routes r WHERE r.RouteId = RouteWaypoints.RouteId);
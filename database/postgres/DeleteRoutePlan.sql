@@ -0,0 +1 @@
+DELETE FROM RoutePlans WHERE PlanId = $1;
\ No newline at end of file
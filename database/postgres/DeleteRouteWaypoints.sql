@@ -1 +1 @@
-DELETE FROM RouteWaypoints WHERE RouteId = ? 
\ No newline at end of file
+DELETE FROM RouteWaypoints WHERE RouteId = $1 
\ No newline at end of file
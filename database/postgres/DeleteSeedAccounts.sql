@@ -0,0 +1 @@
+DELETE FROM Accounts WHERE AccountId >= $1;
\ No newline at end of file
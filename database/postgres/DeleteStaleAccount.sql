@@ -0,0 +1 @@
+DELETE FROM StaleAccounts WHERE AccountId = $1;
\ No newline at end of file
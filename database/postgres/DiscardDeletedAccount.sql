@@ -0,0 +1 @@
+DELETE FROM DeletedAccounts WHERE ChangeId = $1 AND RestoredAt IS NULL;
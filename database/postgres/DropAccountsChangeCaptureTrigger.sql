@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS AccountsChangeCapture ON Accounts;
+DROP FUNCTION IF EXISTS CaptureAccountChange();
@@ -0,0 +1,3 @@
+SELECT Latitude, Longitude FROM AccountLocations
+WHERE Latitude IS NOT NULL AND Longitude IS NOT NULL
+  AND (LOWER(Location) LIKE ? OR LOWER(AddressLine1) LIKE ? OR LOWER(Zipcode) = ? OR LOWER(City) = ?)
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM SyncLocks
+WHERE LockName = $1;
\ No newline at end of file
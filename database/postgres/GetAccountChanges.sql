@@ -0,0 +1,15 @@
+SELECT
+    ChangeId,
+    AccountId,
+    ChangeType,
+    Changes,
+    Status,
+    CreatedAt,
+    ProcessedAt,
+    IdempotencyKey,
+    ContentHash,
+    ValidationErrors
+FROM
+    AccountsPendingChanges
+ORDER BY
+    CreatedAt;
@@ -0,0 +1,3 @@
+SELECT LogDatetime, Comments FROM AccountCheckins WHERE AccountId = $1
+UNION ALL
+SELECT LogDatetime, Comments FROM AccountCheckinsPendingChanges WHERE AccountId = $2 AND ChangeType = 'CREATE';
\ No newline at end of file
@@ -0,0 +1,6 @@
+SELECT s.AccountId, a.FullName, s.Score, s.ComputedAt
+FROM AccountScores s
+JOIN Accounts a ON a.AccountId = s.AccountId
+WHERE s.ScoreName = $1
+ORDER BY s.Score DESC, s.AccountId
+LIMIT {{LIMIT}};
\ No newline at end of file
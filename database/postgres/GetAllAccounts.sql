@@ -0,0 +1 @@
+SELECT * FROM Accounts ORDER BY AccountId;
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT count(*) FROM information_schema.triggers WHERE lower(trigger_name) = 'accountschangecapture' AND trigger_schema = current_schema();
\ No newline at end of file
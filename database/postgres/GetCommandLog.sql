@@ -0,0 +1,8 @@
+SELECT LogId, Command, Args, Flags, Timestamp, Success, ErrorMessage, DurationMs
+FROM CommandLog
+WHERE ($1 = '' OR Command = $2 OR Command LIKE $3)
+  AND ($4 < 0 OR Success = ($5 = 1))
+  AND Timestamp >= $6
+  AND Timestamp < $7
+ORDER BY LogId DESC
+LIMIT {{LIMIT}};
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT LogId, Command, Args, Flags, Timestamp, Success, ErrorMessage, DurationMs
+FROM CommandLog
+WHERE LogId = $1;
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT Name FROM DataSets WHERE AccountField = $1 ORDER BY ProfileId LIMIT 1;
\ No newline at end of file
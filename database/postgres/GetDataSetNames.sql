@@ -0,0 +1 @@
+SELECT Name FROM DataSets WHERE ProfileId = $1;
\ No newline at end of file
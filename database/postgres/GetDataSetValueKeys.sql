@@ -0,0 +1 @@
+SELECT DataSetValueId, DataSetName, Value FROM DataSetValues WHERE ProfileId = $1;
\ No newline at end of file
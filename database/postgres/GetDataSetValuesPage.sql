@@ -0,0 +1,6 @@
+SELECT Text, Value
+FROM DataSetValues
+WHERE DataSetName = $1
+  AND (Text ILIKE $2 OR Value ILIKE $3)
+ORDER BY Text, DataSetValueId
+LIMIT {{LIMIT}} OFFSET {{OFFSET}};
@@ -0,0 +1 @@
+SELECT AccountId, FollowUpDate FROM Accounts WHERE FollowUpDate IS NOT NULL AND FollowUpDate <> ''
\ No newline at end of file
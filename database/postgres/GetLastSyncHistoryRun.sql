@@ -0,0 +1,21 @@
+SELECT "HistoryId",
+       "CorrelationId",
+       "RunType",
+       "Direction",
+       "Source",
+       "Initiator",
+       "Status",
+       "ItemsProcessed",
+       "ErrorCount",
+       "StartedAt",
+       "CompletedAt",
+       "DurationSeconds",
+       "Summary",
+       "Details",
+       "Note"
+FROM "SyncHistory"
+WHERE "RunType" = $1
+  AND "Source" = $2
+  AND "Status" = $3
+ORDER BY "StartedAt" DESC
+LIMIT 1;
@@ -0,0 +1,24 @@
+SELECT "HistoryId",
+       "CorrelationId",
+       "RunType",
+       "Direction",
+       "Source",
+       "Initiator",
+       "Status",
+       "ItemsProcessed",
+       "ErrorCount",
+       "StartedAt",
+       "CompletedAt",
+       "DurationSeconds",
+       "Summary",
+       "Details",
+       "Note"
+FROM "SyncHistory"
+WHERE "HistoryId" IN (
+    SELECT MAX("HistoryId")
+    FROM "SyncHistory"
+    WHERE "Status" <> 'running'
+      AND "Direction" IN ('pull', 'push')
+    GROUP BY "Direction", "Source"
+)
+ORDER BY "Direction", "Source";
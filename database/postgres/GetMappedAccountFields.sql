@@ -0,0 +1,6 @@
+SELECT DISTINCT
+    AccountField
+FROM
+    DataSets
+WHERE
+    AccountField IS NOT NULL;
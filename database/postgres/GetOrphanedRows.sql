@@ -0,0 +1,15 @@
+SELECT 'AccountLocations' AS TableName, l.LocationId AS ChildId, l.AccountId AS ParentId
+FROM AccountLocations l
+WHERE l.AccountId IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM Accounts a WHERE a.AccountId = l.AccountId)
+UNION ALL
+SELECT 'AccountCheckins', c.CheckinId, c.AccountId
+FROM AccountCheckins c
+WHERE c.AccountId IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM Accounts a WHERE a.AccountId = c.AccountId)
+UNION ALL
+SELECT 'RouteWaypoints', w.WaypointId, w.RouteId
+FROM RouteWaypoints w
+WHERE w.RouteId IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM Routes r WHERE r.RouteId = w.RouteId)
+ORDER BY TableName, ParentId, ChildId;
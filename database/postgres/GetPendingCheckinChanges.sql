@@ -12,7 +12,9 @@ SELECT
     pc.ChangeType,
     pc.Status,
     pc.CreatedAt,
-    pc.ProcessedAt
+    pc.ProcessedAt,
+    pc.IdempotencyKey,
+    pc.ContentHash
 FROM
     AccountCheckinsPendingChanges pc
 WHERE
@@ -0,0 +1 @@
+SELECT WaypointId, ApptTime FROM RouteWaypoints WHERE RouteId = $1 AND ApptTime IS NOT NULL
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT PlanId, Name, RouteDate, StartAddress, DestinationAddress, StartTime, TemplateRouteId, Waypoints, Status, RouteId, CreatedAt, UpdatedAt, PushedAt
+FROM RoutePlans
+WHERE PlanId = $1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT PlanId, Name, RouteDate, StartAddress, DestinationAddress, StartTime, TemplateRouteId, Waypoints, Status, RouteId, CreatedAt, UpdatedAt, PushedAt
+FROM RoutePlans
+WHERE RouteDate >= $1 AND RouteDate < $2
+ORDER BY RouteDate, PlanId;
\ No newline at end of file
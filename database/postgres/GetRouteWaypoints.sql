@@ -0,0 +1,5 @@
+SELECT WaypointId, Name, Address, Suite, City, State, Zipcode, Location, Latitude, Longitude, LayoverMinutes, Position,
+       CompleteAddress, LocationId, CustomerId, ApptTime, Type, PlaceId
+FROM RouteWaypoints
+WHERE RouteId = $1
+ORDER BY Position, WaypointId;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT RouteId, Name, RouteDate, Duration, StartAddress, DestinationAddress, StartTime
+FROM Routes
+WHERE RouteDate >= CAST($1 AS DATE) AND RouteDate < CAST($2 AS DATE)
+ORDER BY RouteDate, StartTime, RouteId;
\ No newline at end of file
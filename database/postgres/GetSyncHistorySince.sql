@@ -0,0 +1,19 @@
+SELECT "HistoryId",
+       "CorrelationId",
+       "RunType",
+       "Direction",
+       "Source",
+       "Initiator",
+       "Status",
+       "ItemsProcessed",
+       "ErrorCount",
+       "StartedAt",
+       "CompletedAt",
+       "DurationSeconds",
+       "Summary",
+       "Details",
+       "Note"
+FROM "SyncHistory"
+WHERE "StartedAt" >= $1
+  AND "RunType" <> $2
+ORDER BY "StartedAt" ASC;
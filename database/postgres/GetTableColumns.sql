@@ -1 +1 @@
-SELECT column_name FROM information_schema.columns WHERE table_name = ?;
\ No newline at end of file
+SELECT column_name FROM information_schema.columns WHERE table_name = ? AND table_schema = current_schema();
\ No newline at end of file
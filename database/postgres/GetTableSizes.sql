@@ -0,0 +1,5 @@
+SELECT c.relname AS name, pg_total_relation_size(c.oid) AS SizeBytes
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind = 'r' AND n.nspname = current_schema()
+ORDER BY c.relname
\ No newline at end of file
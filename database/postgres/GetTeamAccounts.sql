@@ -0,0 +1,4 @@
+SELECT AccountOwner, COUNT(*) AS Accounts,
+    SUM(CASE WHEN DaysSinceLastCheckin > $1 THEN 1 ELSE 0 END) AS StaleAccounts
+FROM Accounts
+GROUP BY AccountOwner
\ No newline at end of file
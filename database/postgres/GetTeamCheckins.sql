@@ -0,0 +1,4 @@
+SELECT CreatedBy, COUNT(*) AS Checkins, MAX(LogDatetime) AS LastCheckin
+FROM AccountCheckins
+WHERE LogDatetime >= $1
+GROUP BY CreatedBy
\ No newline at end of file
@@ -0,0 +1,22 @@
+WITH q AS (SELECT CAST(? AS TEXT) AS Term, CAST(? AS TEXT) AS Pattern, CAST(? AS TEXT) AS Prefix)
+SELECT r.RouteId, r.Name, r.RouteDate, r.StartAddress, r.DestinationAddress,
+  CASE
+    WHEN CAST(r.RouteId AS TEXT) = q.Term THEN 'ID'
+    WHEN LOWER(r.Name) LIKE q.Pattern THEN 'Name'
+    ELSE 'Address'
+  END AS MatchedField
+FROM Routes r
+CROSS JOIN q
+WHERE CAST(r.RouteId AS TEXT) = q.Term
+   OR LOWER(r.Name) LIKE q.Pattern
+   OR LOWER(r.StartAddress) LIKE q.Pattern
+   OR LOWER(r.DestinationAddress) LIKE q.Pattern
+ORDER BY
+  CASE
+    WHEN CAST(r.RouteId AS TEXT) = q.Term THEN 0
+    WHEN LOWER(r.Name) LIKE q.Prefix THEN 1
+    WHEN LOWER(r.Name) LIKE q.Pattern THEN 2
+    ELSE 3
+  END,
+  r.RouteDate DESC
+LIMIT 25
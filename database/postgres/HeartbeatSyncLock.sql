@@ -0,0 +1,4 @@
+UPDATE SyncLocks
+SET HeartbeatAt = $1
+WHERE LockName = $2
+  AND HolderId = $3;
\ No newline at end of file
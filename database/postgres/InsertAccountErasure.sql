@@ -0,0 +1,2 @@
+INSERT INTO AccountErasures (AccountId, Mode, ReportId, ReportDigest, DeleteChangeId, ErasedAt)
+VALUES ($1, $2, $3, $4, $5, $6);
\ No newline at end of file
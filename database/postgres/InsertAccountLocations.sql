@@ -7,5 +7,6 @@ INSERT INTO AccountLocations (
 	State, 
 	Latitude, 
 	AddressLine1, 
-	Location
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?); 
\ No newline at end of file
+	Location, 
+	IsApproximate
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?); 
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, IdempotencyKey, ContentHash, BaseUpdatedAt)
+VALUES ($1, $2, $3, $4, $5, (SELECT UpdatedAt FROM Accounts WHERE AccountId = $6));
@@ -0,0 +1,2 @@
+INSERT INTO ActionRuns (ActionName, ActionType, TriggeredBy, Source, Config, Event, Args, Status, ExitCode, CommandOutput, RowsAffected, ErrorMessage, RerunOf, StartedAt, DurationMs)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
\ No newline at end of file
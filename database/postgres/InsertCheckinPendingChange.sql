@@ -0,0 +1,2 @@
+INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, CrmId, LogDatetime, Type, Comments, ExtraFields, EndpointType, CreatedBy, ChangeType, IdempotencyKey, ContentHash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
\ No newline at end of file
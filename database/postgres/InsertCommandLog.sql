@@ -0,0 +1 @@
+INSERT INTO CommandLog (Command, Args, Flags, Success, ErrorMessage, DurationMs) VALUES ($1, $2, $3, $4, $5, $6);
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO DeletedAccounts (AccountId, ChangeId, FullName, Data, DeletedAt)
+VALUES ($1, $2, $3, $4, $5);
@@ -0,0 +1,2 @@
+INSERT INTO IdRemap (EntityType, OldId, NewId, Reason, RowsRewritten, RemappedAt)
+VALUES ($1, $2, $3, $4, $5, $6);
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO RoutePlans (Name, RouteDate, StartAddress, DestinationAddress, StartTime, TemplateRouteId, Waypoints, Status, CreatedAt, UpdatedAt)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 'draft', $8, $9)
+RETURNING PlanId;
\ No newline at end of file
@@ -1,4 +1,4 @@
 INSERT INTO RouteWaypoints (WaypointId, RouteId, Name, Address, Suite, City, State, Zipcode,
                            Location, Latitude, Longitude, LayoverMinutes, Position, CompleteAddress,
                            LocationId, CustomerId, ApptTime, Type, PlaceId)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) 
\ No newline at end of file
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) 
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO SyncLocks (LockName, HolderId, Host, Pid, Operation, AcquiredAt, HeartbeatAt)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
\ No newline at end of file
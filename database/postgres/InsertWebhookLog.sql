@@ -0,0 +1 @@
+INSERT INTO WebhookLog (ReceivedAt, Method, Uri, Headers, Body, ParseStatus, ParseError, EntityId) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM StaleAccounts WHERE AccountId = $1;
\ No newline at end of file
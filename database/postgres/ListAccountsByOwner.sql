@@ -0,0 +1 @@
+SELECT * FROM Accounts WHERE AccountOwner = $1 ORDER BY FullName, AccountId;
\ No newline at end of file
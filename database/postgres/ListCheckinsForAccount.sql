@@ -0,0 +1,4 @@
+SELECT CheckinId, CrmId, AccountId, LogDatetime, Type, Comments, ExtraFields, EndpointType, CreatedBy
+FROM AccountCheckins
+WHERE AccountId = $1
+ORDER BY LogDatetime DESC, CheckinId DESC;
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO StaleAccounts (AccountId, RemoteModifiedDate, MarkedAt) VALUES ($1, $2, $3)
+ON CONFLICT (AccountId) DO UPDATE SET RemoteModifiedDate = EXCLUDED.RemoteModifiedDate, MarkedAt = EXCLUDED.MarkedAt;
\ No newline at end of file
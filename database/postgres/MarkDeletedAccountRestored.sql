@@ -0,0 +1 @@
+UPDATE DeletedAccounts SET RestoredAt = $1, RestoredAccountId = $2 WHERE DeletedId = $3 AND RestoredAt IS NULL;
@@ -0,0 +1 @@
+UPDATE RoutePlans SET Status = 'pushed', RouteId = $1, PushedAt = $2, UpdatedAt = $3 WHERE PlanId = $4;
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO AccountScores (AccountId, ScoreName, Score, ComputedAt) VALUES ($1, $2, $3, $4)
+ON CONFLICT (AccountId, ScoreName) DO UPDATE SET Score = EXCLUDED.Score, ComputedAt = EXCLUDED.ComputedAt;
\ No newline at end of file
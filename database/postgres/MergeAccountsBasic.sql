@@ -1 +1 @@
-INSERT INTO Accounts (AccountId, FullName) VALUES (?, ?) ON CONFLICT (AccountId) DO UPDATE SET FullName = ?
\ No newline at end of file
+INSERT INTO Accounts (AccountId, FullName) VALUES ($1, $2) ON CONFLICT (AccountId) DO UPDATE SET FullName = EXCLUDED.FullName
\ No newline at end of file
@@ -5,15 +5,15 @@ INSERT INTO Accounts (
     FullName,
     PhoneNumber,
     Email,
-    AccountOwner,
     CustomerId,
     Notes,
     OriginalAddress,
     CrmId,
+    AccountOwner,
     DaysSinceLastCheckin,
-    FollowUpDate,
     LastCheckinDate,
     LastModifiedDate,
+    FollowUpDate,
     CustomNumeric,
     CustomText,
     CustomNumeric2,
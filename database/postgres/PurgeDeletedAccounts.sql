@@ -0,0 +1 @@
+DELETE FROM DeletedAccounts WHERE DeletedAt < $1;
@@ -0,0 +1,3 @@
+DELETE FROM SyncLocks
+WHERE LockName = $1
+  AND HolderId = $2;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE AccountCheckins SET AccountId = $1 WHERE AccountId = $2;
\ No newline at end of file
@@ -0,0 +1,2 @@
+UPDATE AccountsPendingChanges SET AccountId = $1
+WHERE AccountId = $2 AND Status = 'pending' AND ChangeType <> 'DELETE';
\ No newline at end of file
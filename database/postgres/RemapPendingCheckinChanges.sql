@@ -0,0 +1,2 @@
+UPDATE AccountCheckinsPendingChanges SET AccountId = $1
+WHERE AccountId = $2 AND Status = 'pending';
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE RouteWaypoints SET CustomerId = $1 WHERE CustomerId = $2;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE AccountsPendingChanges SET Status = 'failed', ValidationErrors = $1, ProcessedAt = CURRENT_TIMESTAMP WHERE ChangeId = $2;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE SyncHistory SET Note = $1 WHERE HistoryId = $2;
\ No newline at end of file
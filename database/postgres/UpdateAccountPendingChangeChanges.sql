@@ -0,0 +1 @@
+UPDATE AccountsPendingChanges SET Changes = $1, Status = 'pending', ValidationErrors = NULL WHERE ChangeId = $2 AND (Status = 'pending' OR (Status = 'failed' AND ValidationErrors IS NOT NULL));
@@ -0,0 +1 @@
+UPDATE %s SET IdempotencyKey = $1, ContentHash = $2 WHERE ChangeId = $3;
\ No newline at end of file
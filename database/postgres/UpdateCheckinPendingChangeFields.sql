@@ -0,0 +1,3 @@
+UPDATE AccountCheckinsPendingChanges
+SET CrmId = $1, LogDatetime = $2, Type = $3, Comments = $4
+WHERE ChangeId = $5 AND Status = 'pending';
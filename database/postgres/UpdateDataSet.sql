@@ -0,0 +1,4 @@
+UPDATE DataSets
+SET Filterable = $1, Label = $2, Position = $3, Type = $4, HasData = $5, IsUserCanAddNewTextValues = $6,
+    RawMin = $7, Min = $8, Max = $9, RawMax = $10, AccountField = $11, UpdatedAt = CURRENT_TIMESTAMP
+WHERE Name = $12 AND ProfileId = $13;
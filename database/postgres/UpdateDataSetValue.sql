@@ -0,0 +1 @@
+UPDATE DataSetValues SET Text = $1, DataSetPosition = $2, UpdatedAt = CURRENT_TIMESTAMP WHERE DataSetValueId = $3;
\ No newline at end of file
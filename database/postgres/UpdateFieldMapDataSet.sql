@@ -0,0 +1 @@
+UPDATE FieldMaps SET DataSetName = $1, DataSetLabel = $2 WHERE FieldName = $3 AND ObjectType = 'Account';
\ No newline at end of file
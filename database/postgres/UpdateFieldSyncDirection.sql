@@ -0,0 +1 @@
+UPDATE FieldMaps SET SyncDirection = $1 WHERE FieldName = $2 AND ObjectType = $3;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE RoutePlans
+SET Name = $1, RouteDate = $2, StartAddress = $3, DestinationAddress = $4, StartTime = $5, Waypoints = $6, UpdatedAt = $7
+WHERE PlanId = $8 AND Status = 'draft';
\ No newline at end of file
@@ -2,19 +2,22 @@ package database
 
 import (
 	"badgermaps/api/models"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
-func GetAccountByID(db DB, accountID int) (*models.Account, error) {
-	sqlText := db.GetSQL("GetAccountById")
-	if sqlText == "" {
-		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountById")
-	}
-
-	sqlDB := db.GetDB()
+// RowScanner is satisfied by *sql.Row and *sql.Rows.
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// ScanAccount reads an Accounts row selected with SELECT *.
+func ScanAccount(row RowScanner) (*models.Account, error) {
 	var account models.Account
-	err := sqlDB.QueryRow(sqlText, accountID).Scan(
+	err := row.Scan(
 		&account.AccountId, &account.FirstName, &account.LastName, &account.FullName, &account.PhoneNumber,
 		&account.Email, &account.CustomerId, &account.Notes, &account.OriginalAddress, &account.CrmId,
 		&account.AccountOwner, &account.DaysSinceLastCheckin, &account.LastCheckinDate, &account.LastModifiedDate,
@@ -41,25 +44,94 @@ func GetAccountByID(db DB, accountID int) (*models.Account, error) {
 	return &account, nil
 }
 
-func GetCheckinByID(db DB, checkinID int) (*models.Checkin, error) {
-	sqlText := db.GetSQL("GetCheckinById")
-	if sqlText == "" {
-		return nil, fmt.Errorf("unknown or unavailable SQL command: GetCheckinById")
-	}
-
-	sqlDB := db.GetDB()
-
+// ScanCheckin reads an AccountCheckins row selected with the columns of
+// GetCheckinById.
+func ScanCheckin(row RowScanner) (*models.Checkin, error) {
 	var checkin models.Checkin
-	err := sqlDB.QueryRow(sqlText, checkinID).Scan(
+	var extraFields sql.NullString
+	err := row.Scan(
 		&checkin.CheckinId, &checkin.CrmId, &checkin.AccountId, &checkin.LogDatetime, &checkin.Type,
-		&checkin.Comments, &checkin.ExtraFields, &checkin.EndpointType, &checkin.CreatedBy,
+		&checkin.Comments, &extraFields, &checkin.EndpointType, &checkin.CreatedBy,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if extraFields.String != "" {
+		checkin.ExtraFields = json.RawMessage(extraFields.String)
+	}
 	return &checkin, nil
 }
 
+// SelectTableRows returns every row of table with all of its columns, for
+// exports that read a whole table. The caller closes the rows.
+func SelectTableRows(db DB, table string) (*sql.Rows, error) {
+	sqlText := db.GetSQL("SelectTableRows")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: SelectTableRows")
+	}
+	return db.GetDB().Query(fmt.Sprintf(sqlText, QualifiedName(db, table)))
+}
+
+// GetAccountColumnsByIDs returns columns, as text, of the stored accounts
+// with ids; accounts that are not stored are left out. columns must be
+// Accounts column names. The ids are integers, so they are written into
+// the statement, which then runs unchanged on every database type.
+func GetAccountColumnsByIDs(db DB, columns []string, ids []int) ([][]string, error) {
+	sqlText := db.GetSQL("GetAccountColumnsByIds")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountColumnsByIds")
+	}
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = strconv.Itoa(id)
+	}
+	rows, err := db.GetDB().Query(fmt.Sprintf(sqlText, strings.Join(columns, ", "), QualifiedName(db, "Accounts"), strings.Join(list, ", ")))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values [][]string
+	for rows.Next() {
+		scanned := make([]any, len(columns))
+		for i := range scanned {
+			scanned[i] = new(any)
+		}
+		if err := rows.Scan(scanned...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(columns))
+		for i, value := range scanned {
+			switch v := (*value.(*any)).(type) {
+			case nil:
+			case []byte:
+				row[i] = string(v)
+			case float64:
+				row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		values = append(values, row)
+	}
+	return values, rows.Err()
+}
+
+func GetAccountByID(db DB, accountID int) (*models.Account, error) {
+	sqlText := db.GetSQL("GetAccountById")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountById")
+	}
+	return ScanAccount(db.GetDB().QueryRow(sqlText, accountID))
+}
+
+func GetCheckinByID(db DB, checkinID int) (*models.Checkin, error) {
+	sqlText := db.GetSQL("GetCheckinById")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetCheckinById")
+	}
+	return ScanCheckin(db.GetDB().QueryRow(sqlText, checkinID))
+}
+
 func GetRouteByID(db DB, routeID int) (*models.Route, error) {
 	sqlText := db.GetSQL("GetRouteById")
 	if sqlText == "" {
@@ -126,3 +198,51 @@ func GetAllAccountIDs(db DB) ([]int, error) {
 	}
 	return ids, nil
 }
+
+// GetAccountModifiedDates returns the stored LastModifiedDate keyed by account ID.
+func GetAccountModifiedDates(db DB) (map[int]string, error) {
+	sqlText := db.GetSQL("GetAccountModifiedDates")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountModifiedDates")
+	}
+
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dates := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var modified sql.NullString
+		if err := rows.Scan(&id, &modified); err != nil {
+			return nil, err
+		}
+		dates[id] = modified.String
+	}
+	return dates, rows.Err()
+}
+
+func GetAllRouteIDs(db DB) ([]int, error) {
+	sqlText := db.GetSQL("GetAllRouteIds")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAllRouteIds")
+	}
+
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"badgermaps/api/models"
+	"database/sql/driver"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AccountWithLabels is an account with the data set labels of its columns.
+type AccountWithLabels struct {
+	models.Account
+	// Labels maps Accounts column names to their data set label. Columns
+	// without a data set are not listed.
+	Labels map[string]string
+}
+
+// LabeledField is one labeled account column and its value as text.
+type LabeledField struct {
+	Column string
+	Label  string
+	Value  string
+}
+
+// Label returns the label of column, or the column name when it has none.
+func (a *AccountWithLabels) Label(column string) string {
+	if label := a.Labels[column]; label != "" {
+		return label
+	}
+	return column
+}
+
+// Value returns the value of an Accounts column as text, and false when the
+// account has no such column. Null values are "".
+func (a *AccountWithLabels) Value(column string) (string, bool) {
+	field := reflect.ValueOf(a.Account).FieldByName(column)
+	if !field.IsValid() {
+		return "", false
+	}
+	valuer, ok := field.Interface().(driver.Valuer)
+	if !ok {
+		return "", false
+	}
+	value, err := valuer.Value()
+	if err != nil || value == nil {
+		return "", true
+	}
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case string:
+		return v, true
+	}
+	return "", true
+}
+
+// LabeledFields returns the labeled columns that hold a value, sorted by
+// label.
+func (a *AccountWithLabels) LabeledFields() []LabeledField {
+	var fields []LabeledField
+	for column, label := range a.Labels {
+		if value, ok := a.Value(column); ok && value != "" {
+			fields = append(fields, LabeledField{Column: column, Label: label, Value: value})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Label != fields[j].Label {
+			return fields[i].Label < fields[j].Label
+		}
+		return fields[i].Column < fields[j].Column
+	})
+	return fields
+}
+
+// accountColumns maps normalized field names, from both the Go field and
+// its JSON tag, to the Accounts column name.
+var accountColumns = func() map[string]string {
+	columns := make(map[string]string)
+	t := reflect.TypeOf(models.Account{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := reflect.Zero(f.Type).Interface().(driver.Valuer); !ok {
+			continue
+		}
+		columns[fieldKey(f.Name)] = f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" {
+			columns[fieldKey(tag)] = f.Name
+		}
+	}
+	return columns
+}()
+
+// accountColumn resolves a DataSets.AccountField value, which may be
+// written as custom_text2 or CustomText2, to its Accounts column.
+func accountColumn(field string) (string, bool) {
+	column, ok := accountColumns[fieldKey(field)]
+	return column, ok
+}
+
+func fieldKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
@@ -0,0 +1,126 @@
+// Package repository provides typed reads over the local database so the
+// GUI and actions do not build SQL for common lookups. Queries come from
+// the dialect's SQL files through database.DB.GetSQL like the rest of the
+// database package.
+package repository
+
+import (
+	"badgermaps/api/models"
+	"badgermaps/database"
+	"fmt"
+)
+
+// Repository runs typed queries against a database.
+type Repository struct {
+	db database.DB
+}
+
+// New returns a Repository reading from db.
+func New(db database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) sql(command string) (string, error) {
+	if r.db == nil || r.db.GetDB() == nil {
+		return "", fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := r.db.GetSQL(command)
+	if sqlText == "" {
+		return "", fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	return sqlText, nil
+}
+
+// GetAccountWithLabels returns an account together with the labels its
+// profile gives to the Accounts columns, the same projection the
+// AccountsWithLabels view makes on PostgreSQL and SQL Server.
+func (r *Repository) GetAccountWithLabels(accountID int) (*AccountWithLabels, error) {
+	if _, err := r.sql("GetAccountById"); err != nil {
+		return nil, err
+	}
+	account, err := database.GetAccountByID(r.db, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account %d: %w", accountID, err)
+	}
+	labels, err := r.AccountFieldLabels()
+	if err != nil {
+		return nil, err
+	}
+	return &AccountWithLabels{Account: *account, Labels: labels}, nil
+}
+
+// AccountFieldLabels maps Accounts column names, such as CustomText2, to
+// the label of the data set stored in them.
+func (r *Repository) AccountFieldLabels() (map[string]string, error) {
+	sqlText, err := r.sql("GetAccountFieldLabels")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account field labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := make(map[string]string)
+	for rows.Next() {
+		var field, label string
+		if err := rows.Scan(&field, &label); err != nil {
+			return nil, err
+		}
+		column, ok := accountColumn(field)
+		if !ok {
+			continue
+		}
+		if _, seen := labels[column]; !seen {
+			labels[column] = label
+		}
+	}
+	return labels, rows.Err()
+}
+
+// ListAccountsByOwner returns the accounts owned by owner, ordered by name.
+func (r *Repository) ListAccountsByOwner(owner string) ([]models.Account, error) {
+	sqlText, err := r.sql("ListAccountsByOwner")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.GetDB().Query(sqlText, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts for owner %q: %w", owner, err)
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		account, err := database.ScanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, rows.Err()
+}
+
+// ListCheckinsForAccount returns an account's check-ins, newest first.
+func (r *Repository) ListCheckinsForAccount(accountID int) ([]models.Checkin, error) {
+	sqlText, err := r.sql("ListCheckinsForAccount")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.GetDB().Query(sqlText, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check-ins for account %d: %w", accountID, err)
+	}
+	defer rows.Close()
+
+	var checkins []models.Checkin
+	for rows.Next() {
+		checkin, err := database.ScanCheckin(rows)
+		if err != nil {
+			return nil, err
+		}
+		checkins = append(checkins, *checkin)
+	}
+	return checkins, rows.Err()
+}
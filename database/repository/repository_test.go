@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+	"badgermaps/database"
+)
+
+func newTestDB(t *testing.T) database.DB {
+	t.Helper()
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "repository.db")})
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatalf("EnforceSchema: %v", err)
+	}
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, FullName, AccountOwner, CustomText2, CustomNumeric) VALUES
+			(1, 'Acme', 'dana@example.com', 'Gold', 12.5),
+			(2, 'Bolt', 'dana@example.com', NULL, NULL),
+			(3, 'Crux', 'lee@example.com', NULL, NULL)`,
+		`INSERT INTO DataSets (Name, ProfileId, Label, Position, AccountField) VALUES
+			('tier', 1, 'Tier', 1, 'custom_text2'),
+			('score', 1, 'Score', 2, 'CustomNumeric'),
+			('unused', 1, 'Unused', 3, 'CustomText9')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, Type) VALUES
+			(10, 1, '2024-01-05T09:00:00', 'Visit'),
+			(11, 1, '2024-02-05T09:00:00', 'Call'),
+			(12, 2, '2024-02-06T09:00:00', 'Visit')`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return db
+}
+
+func TestGetAccountWithLabels(t *testing.T) {
+	repo := New(newTestDB(t))
+	account, err := repo.GetAccountWithLabels(1)
+	if err != nil {
+		t.Fatalf("GetAccountWithLabels: %v", err)
+	}
+	if account.FullName.String != "Acme" || account.Label("CustomText2") != "Tier" || account.Label("Email") != "Email" {
+		t.Fatalf("account = %+v, labels %v", account.Account, account.Labels)
+	}
+	fields := account.LabeledFields()
+	if len(fields) != 2 || fields[0].Label != "Score" || fields[0].Value != "12.5" || fields[1].Value != "Gold" {
+		t.Fatalf("labeled fields = %+v", fields)
+	}
+	if _, err := repo.GetAccountWithLabels(99); err == nil {
+		t.Fatal("missing account was found")
+	}
+}
+
+func TestListAccountsByOwnerAndCheckins(t *testing.T) {
+	repo := New(newTestDB(t))
+	accounts, err := repo.ListAccountsByOwner("dana@example.com")
+	if err != nil {
+		t.Fatalf("ListAccountsByOwner: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].FullName.String != "Acme" || accounts[1].FullName.String != "Bolt" {
+		t.Fatalf("accounts = %+v", accounts)
+	}
+
+	checkins, err := repo.ListCheckinsForAccount(1)
+	if err != nil {
+		t.Fatalf("ListCheckinsForAccount: %v", err)
+	}
+	if len(checkins) != 2 || checkins[0].CheckinId.Int64 != 11 {
+		t.Fatalf("check-ins = %+v", checkins)
+	}
+}
@@ -0,0 +1,261 @@
+package database
+
+import (
+	"badgermaps/api/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Statuses of a RoutePlan.
+const (
+	RoutePlanDraft  = "draft"
+	RoutePlanPushed = "pushed"
+)
+
+// RoutePlan is a route planned locally, usually copied from a pulled route,
+// until it is pushed to BadgerMaps as a new route.
+type RoutePlan struct {
+	PlanId int
+	Name   string
+	// RouteDate is the day of the route as YYYY-MM-DD.
+	RouteDate          string
+	StartAddress       string
+	DestinationAddress string
+	// StartTime is HH:MM in the display timezone, or empty.
+	StartTime string
+	// TemplateRouteId is the pulled route the plan was copied from, or
+	// zero.
+	TemplateRouteId int
+	Waypoints       []PlanWaypoint
+	Status          string
+	// RouteId is the route created in BadgerMaps once the plan is pushed.
+	RouteId   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PushedAt  time.Time
+}
+
+// PlanWaypoint is a stop of a RoutePlan. Stops are kept in order, so the
+// position of each is its index plus one.
+type PlanWaypoint struct {
+	Name           string   `json:"name,omitempty"`
+	Address        string   `json:"address,omitempty"`
+	Suite          string   `json:"suite,omitempty"`
+	City           string   `json:"city,omitempty"`
+	State          string   `json:"state,omitempty"`
+	Zipcode        string   `json:"zipcode,omitempty"`
+	Lat            *float64 `json:"lat,omitempty"`
+	Long           *float64 `json:"long,omitempty"`
+	LayoverMinutes int      `json:"layover_minutes,omitempty"`
+	LocationId     int      `json:"location_id,omitempty"`
+	CustomerId     int      `json:"customer_id,omitempty"`
+	// ApptTime is HH:MM in the display timezone, or empty.
+	ApptTime string `json:"appt_time,omitempty"`
+	Type     int    `json:"type,omitempty"`
+	PlaceId  string `json:"place_id,omitempty"`
+}
+
+// InsertRoutePlan stores plan as a draft and returns its ID.
+func InsertRoutePlan(db DB, plan RoutePlan) (int, error) {
+	if db == nil || db.GetDB() == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("InsertRoutePlan")
+	if sqlText == "" {
+		return 0, fmt.Errorf("unknown or unavailable SQL command: InsertRoutePlan")
+	}
+	waypoints, err := json.Marshal(plan.Waypoints)
+	if err != nil {
+		return 0, err
+	}
+	var templateID sql.NullInt64
+	if plan.TemplateRouteId > 0 {
+		templateID = sql.NullInt64{Int64: int64(plan.TemplateRouteId), Valid: true}
+	}
+	now := time.Now().UTC()
+	args := []any{
+		plan.Name, plan.RouteDate, emptyAsNull(plan.StartAddress), emptyAsNull(plan.DestinationAddress),
+		emptyAsNull(plan.StartTime), templateID, string(waypoints), now, now,
+	}
+	switch db.GetType() {
+	case "postgres", "mssql":
+		var id int
+		err := db.GetDB().QueryRow(sqlText, args...).Scan(&id)
+		return id, err
+	default:
+		result, err := db.GetDB().Exec(sqlText, args...)
+		if err != nil {
+			return 0, err
+		}
+		id, err := result.LastInsertId()
+		return int(id), err
+	}
+}
+
+// UpdateRoutePlan saves the edits of a draft plan. Pushed plans are not
+// changed.
+func UpdateRoutePlan(db DB, plan RoutePlan) error {
+	waypoints, err := json.Marshal(plan.Waypoints)
+	if err != nil {
+		return err
+	}
+	return RunCommand(db, "UpdateRoutePlan",
+		plan.Name, plan.RouteDate, emptyAsNull(plan.StartAddress), emptyAsNull(plan.DestinationAddress),
+		emptyAsNull(plan.StartTime), string(waypoints), time.Now().UTC(), plan.PlanId,
+	)
+}
+
+// MarkRoutePlanPushed records the route a plan was pushed as.
+func MarkRoutePlanPushed(db DB, planID, routeID int, at time.Time) error {
+	return RunCommand(db, "MarkRoutePlanPushed", routeID, at.UTC(), at.UTC(), planID)
+}
+
+// DeleteRoutePlan removes a plan.
+func DeleteRoutePlan(db DB, planID int) error {
+	return RunCommand(db, "DeleteRoutePlan", planID)
+}
+
+// GetRoutePlan returns the plan with the given ID, or nil when there is
+// none.
+func GetRoutePlan(db DB, planID int) (*RoutePlan, error) {
+	plans, err := queryRoutePlans(db, "GetRoutePlan", planID)
+	if err != nil || len(plans) == 0 {
+		return nil, err
+	}
+	return &plans[0], nil
+}
+
+// GetRoutePlansBetween returns the plans dated from from up to, but not
+// including, to, in date order. Dates are YYYY-MM-DD.
+func GetRoutePlansBetween(db DB, from, to string) ([]RoutePlan, error) {
+	return queryRoutePlans(db, "GetRoutePlansBetween", from, to)
+}
+
+func queryRoutePlans(db DB, command string, args ...any) ([]RoutePlan, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL(command)
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	rows, err := db.GetDB().Query(sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var plans []RoutePlan
+	for rows.Next() {
+		var (
+			plan                                     RoutePlan
+			start, destination, startTime, waypoints sql.NullString
+			templateID, routeID                      sql.NullInt64
+			createdAt, updatedAt, pushedAt           any
+		)
+		if err := rows.Scan(&plan.PlanId, &plan.Name, &plan.RouteDate, &start, &destination, &startTime, &templateID,
+			&waypoints, &plan.Status, &routeID, &createdAt, &updatedAt, &pushedAt); err != nil {
+			return nil, err
+		}
+		plan.StartAddress = start.String
+		plan.DestinationAddress = destination.String
+		plan.StartTime = startTime.String
+		plan.TemplateRouteId = int(templateID.Int64)
+		plan.RouteId = int(routeID.Int64)
+		plan.CreatedAt = normaliseToTime(createdAt)
+		plan.UpdatedAt = normaliseToTime(updatedAt)
+		plan.PushedAt = normaliseToTime(pushedAt)
+		if waypoints.String != "" {
+			if err := json.Unmarshal([]byte(waypoints.String), &plan.Waypoints); err != nil {
+				return nil, fmt.Errorf("route plan %d: reading waypoints: %w", plan.PlanId, err)
+			}
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+// GetRoutesBetween returns the pulled routes dated from from up to, but not
+// including, to, in date order. Dates are YYYY-MM-DD.
+func GetRoutesBetween(db DB, from, to string) ([]models.Route, error) {
+	sqlText := db.GetSQL("GetRoutesBetween")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetRoutesBetween")
+	}
+	rows, err := db.GetDB().Query(sqlText, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var routes []models.Route
+	for rows.Next() {
+		var route models.Route
+		if err := rows.Scan(&route.RouteId, &route.Name, &route.RouteDate, &route.Duration, &route.StartAddress,
+			&route.DestinationAddress, &route.StartTime); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, rows.Err()
+}
+
+// GetRouteWaypoints returns the stored waypoints of a route in order.
+func GetRouteWaypoints(db DB, routeID int) ([]models.Waypoint, error) {
+	sqlText := db.GetSQL("GetRouteWaypoints")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetRouteWaypoints")
+	}
+	rows, err := db.GetDB().Query(sqlText, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var waypoints []models.Waypoint
+	for rows.Next() {
+		var w models.Waypoint
+		var apptTime any
+		if err := rows.Scan(&w.WaypointID, &w.Name, &w.Address, &w.Suite, &w.City, &w.State, &w.Zipcode, &w.Location,
+			&w.Lat, &w.Long, &w.LayoverMinutes, &w.Position, &w.CompleteAddress, &w.LocationID, &w.CustomerID,
+			&apptTime, &w.Type, &w.PlaceID); err != nil {
+			return nil, err
+		}
+		if t := normaliseToTime(apptTime); !t.IsZero() {
+			w.ApptTime.SetValid(t.UTC().Format(time.RFC3339))
+		}
+		waypoints = append(waypoints, w)
+	}
+	return waypoints, rows.Err()
+}
+
+// GetAccountStop returns a stop at the stored location of an account, or
+// nil when the account is not stored.
+func GetAccountStop(db DB, accountID int) (*PlanWaypoint, error) {
+	sqlText := db.GetSQL("GetAccountStop")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetAccountStop")
+	}
+	var (
+		stop                                PlanWaypoint
+		name, address, city, state, zipcode sql.NullString
+		locationID                          sql.NullInt64
+		lat, long                           sql.NullFloat64
+	)
+	err := db.GetDB().QueryRow(sqlText, accountID).Scan(&stop.CustomerId, &name, &locationID, &address, &city, &state, &zipcode, &lat, &long)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stop.Name = name.String
+	stop.LocationId = int(locationID.Int64)
+	stop.Address = address.String
+	stop.City = city.String
+	stop.State = state.String
+	stop.Zipcode = zipcode.String
+	if lat.Valid && long.Valid {
+		stop.Lat, stop.Long = &lat.Float64, &long.Float64
+	}
+	return &stop, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import "fmt"
+
+// columnMigrations lists the columns added to tables that earlier versions
+// already created. Each one is nullable or has a default, so
+// AddMissingColumns can add it in place with its AddColumn<Table><Column>
+// command.
+var columnMigrations = map[string][]string{
+	"AccountsPendingChanges":        {"BaseUpdatedAt", "IdempotencyKey", "ContentHash", "ValidationErrors"},
+	"AccountCheckinsPendingChanges": {"IdempotencyKey", "ContentHash"},
+	"FieldMaps":                     {"SyncDirection", "MergeStrategy"},
+	"CommandLog":                    {"Flags", "DurationMs"},
+	"WebhookLog":                    {"ParseStatus", "ParseError", "EntityId"},
+	"SyncHistory":                   {"Note"},
+}
+
+// CanAddColumn reports whether AddMissingColumns adds column to an existing
+// table.
+func CanAddColumn(table, column string) bool {
+	for _, c := range columnMigrations[table] {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// AddMissingColumns adds the columns in columnMigrations that existing
+// tables lack and returns them per table. Tables that do not exist yet are
+// left to EnforceSchema.
+func AddMissingColumns(db DB) (map[string][]string, error) {
+	missing, err := MissingColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	added := make(map[string][]string)
+	for table, columns := range missing {
+		for _, column := range columns {
+			if !CanAddColumn(table, column) {
+				continue
+			}
+			command := "AddColumn" + table + column
+			sqlText := db.GetSQL(command)
+			if sqlText == "" {
+				return added, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+			}
+			if _, err := db.GetDB().Exec(sqlText); err != nil {
+				return added, fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+			}
+			added[table] = append(added[table], column)
+		}
+	}
+	return added, nil
+}
+
+// MissingColumns returns, for each required table that exists, the expected
+// columns it lacks. Columns in columnMigrations are added by
+// AddMissingColumns; a table lacking any other column has to be re-created
+// to match this version.
+func MissingColumns(db DB) (map[string][]string, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	expected := GetExpectedSchema()
+	missing := make(map[string][]string)
+	for _, table := range RequiredTables() {
+		exists, err := db.TableExists(table)
+		if err != nil {
+			return nil, fmt.Errorf("error checking if table %s exists: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+		columns, err := db.GetTableColumns(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get columns for table %s: %w", table, err)
+		}
+		have := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			have[column] = true
+		}
+		for _, column := range expected[table] {
+			if !have[column] {
+				missing[table] = append(missing[table], column)
+			}
+		}
+	}
+	return missing, nil
+}
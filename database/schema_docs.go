@@ -0,0 +1,376 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TableDoc describes one table of the expected schema for BI and reporting
+// users.
+type TableDoc struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Columns     []ColumnDoc `json:"columns"`
+	// References are the tables this one points to, declared with FOREIGN
+	// KEY or inferred from an ID column named like another table's key.
+	References []Reference `json:"references,omitempty"`
+}
+
+// ColumnDoc describes one column.
+type ColumnDoc struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	PrimaryKey bool   `json:"primary_key,omitempty"`
+	// Description comes from the comment in the table's CREATE statement
+	// and, for Accounts, from its FieldMaps entry.
+	Description string `json:"description,omitempty"`
+}
+
+// Reference links columns of a table to the key of another table.
+type Reference struct {
+	Columns  []string `json:"columns"`
+	Table    string   `json:"table"`
+	Target   []string `json:"target_columns"`
+	Declared bool     `json:"declared"`
+}
+
+// tableDescriptions says what each table of the expected schema holds.
+var tableDescriptions = map[string]string{
+	"Accounts":                      "Accounts (customers) pulled from BadgerMaps, one row per account, with the 30 numeric and 30 text custom fields.",
+	"AccountCheckins":               "Check-ins logged against accounts.",
+	"AccountLocations":              "The address and coordinates of each account; one row per account.",
+	"AccountsPendingChanges":        "Account creates, updates, and deletes staged locally and waiting to be pushed, with their push status.",
+	"AccountCheckinsPendingChanges": "Check-ins staged locally and waiting to be pushed, with their push status.",
+	"Routes":                        "Routes planned in BadgerMaps.",
+	"RouteWaypoints":                "The stops of each route, in order.",
+	"RoutePlans":                    "Routes planned locally, often copied from a pulled route, with their stops as JSON, until they are pushed as new routes.",
+	"DeletedAccounts":               "Copies of accounts deleted by a push, kept for the recycle bin's retention period.",
+	"IdRemap":                       "Account IDs replaced after BadgerMaps merged accounts.",
+	"AccountErasures":               "Accounts erased for privacy requests; pulls do not store them again.",
+	"ActionRuns":                    "Runs of configured actions and their outcome.",
+	"SyncHistory":                   "One row per pull or push run, with counts, errors, and an operator's note.",
+	"AccountScores":                 "Derived scores of accounts, such as a priority, computed by the enrichment rules and script after each account pull.",
+	"StaleAccounts":                 "Accounts a low-bandwidth pull found new or changed in BadgerMaps, whose details are fetched when they are next opened.",
+	"SyncLocks":                     "The lock a pull or push run holds so app instances sharing the database do not sync at once, with its holder and last heartbeat.",
+	"UserProfiles":                  "The BadgerMaps user profile of the API key.",
+	"DataSets":                      "Picklists and custom field definitions of the profile.",
+	"DataSetValues":                 "The values of each data set.",
+	"FieldMaps":                     "How each account column maps to the API and is synced: API field, data set label, sync direction, and merge strategy.",
+	"Configurations":                "Key-value settings stored in the database.",
+	"CommandLog":                    "Commands run through the CLI and GUI.",
+	"WebhookLog":                    "Webhooks received by the server, for replay and auditing.",
+}
+
+var (
+	foreignKeyPattern = regexp.MustCompile(`(?i)FOREIGN\s+KEY\s*\(([^)]*)\)\s*REFERENCES\s+"?(\w+)"?\s*\(([^)]*)\)`)
+	primaryKeyPattern = regexp.MustCompile(`(?i)^PRIMARY\s+KEY\s*\(([^)]*)\)`)
+	// tableConstraint matches definitions that constrain the table rather
+	// than define a column.
+	tableConstraint = regexp.MustCompile(`(?i)^(PRIMARY\s+KEY|FOREIGN\s+KEY|UNIQUE|CONSTRAINT|CHECK)\s*[\s(]`)
+	// columnTypeEnd is where the type of a column definition ends.
+	columnTypeEnd = regexp.MustCompile(`(?i)\s+(PRIMARY|NOT|NULL|DEFAULT|CHECK|REFERENCES|UNIQUE|IDENTITY|COLLATE|CONSTRAINT|GENERATED)\b|,\s*$|\s*$`)
+)
+
+// SchemaDocs describes every table of the expected schema with the column
+// types of db's dialect. When db is connected, the data set labels in
+// FieldMaps describe the Accounts columns.
+func SchemaDocs(db DB) ([]TableDoc, error) {
+	expected := GetExpectedSchema()
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	docs := make([]TableDoc, 0, len(names))
+	// keys maps a single-column primary key to its table, or to "" when
+	// several tables use the same key name.
+	keys := make(map[string]string)
+	for _, name := range names {
+		doc := TableDoc{Name: name, Description: tableDescriptions[name]}
+		parsed := parseCreateTable(db.GetSQL("Create" + name + "Table"))
+		for _, column := range expected[name] {
+			def := parsed.columns[column]
+			doc.Columns = append(doc.Columns, ColumnDoc{
+				Name:        column,
+				Type:        def.typ,
+				PrimaryKey:  def.primaryKey || containsFold(parsed.primaryKey, column),
+				Description: def.comment,
+			})
+		}
+		doc.References = parsed.references
+		var primary []string
+		for _, column := range doc.Columns {
+			if column.PrimaryKey {
+				primary = append(primary, column.Name)
+			}
+		}
+		if len(primary) == 1 && strings.HasSuffix(primary[0], "Id") {
+			if _, taken := keys[primary[0]]; taken {
+				keys[primary[0]] = ""
+			} else {
+				keys[primary[0]] = name
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	for i := range docs {
+		doc := &docs[i]
+		declared := make(map[string]bool)
+		for _, ref := range doc.References {
+			for _, column := range ref.Columns {
+				declared[column] = true
+			}
+		}
+		for _, column := range doc.Columns {
+			target, ok := keys[column.Name]
+			if !ok || target == "" || target == doc.Name || column.PrimaryKey || declared[column.Name] {
+				continue
+			}
+			doc.References = append(doc.References, Reference{Columns: []string{column.Name}, Table: target, Target: []string{column.Name}})
+		}
+	}
+
+	if db.IsConnected() {
+		if err := describeAccountColumns(db, docs); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// describeAccountColumns adds the API field and data set label of each
+// Accounts column from FieldMaps.
+func describeAccountColumns(db DB, docs []TableDoc) error {
+	rules, err := GetFieldSyncRules(db, "Account")
+	if err != nil {
+		return fmt.Errorf("failed to read FieldMaps: %w", err)
+	}
+	byColumn := make(map[string]FieldSyncRule, len(rules))
+	for _, rule := range rules {
+		byColumn[rule.FieldName] = rule
+	}
+	for i := range docs {
+		if docs[i].Name != "Accounts" {
+			continue
+		}
+		for j := range docs[i].Columns {
+			column := &docs[i].Columns[j]
+			rule, ok := byColumn[column.Name]
+			if !ok {
+				continue
+			}
+			var parts []string
+			if rule.Label != "" {
+				parts = append(parts, fmt.Sprintf("%q in BadgerMaps", rule.Label))
+			}
+			if rule.JsonField != "" {
+				parts = append(parts, "API field "+rule.JsonField)
+			}
+			if column.Description != "" {
+				parts = append(parts, column.Description)
+			}
+			column.Description = strings.Join(parts, "; ")
+		}
+	}
+	return nil
+}
+
+type columnDef struct {
+	typ        string
+	primaryKey bool
+	comment    string
+}
+
+type createTable struct {
+	columns    map[string]columnDef
+	primaryKey []string
+	references []Reference
+}
+
+// parseCreateTable reads the column definitions, primary key, and foreign
+// keys of a CREATE TABLE statement written one definition per line, as the
+// embedded SQL files are.
+func parseCreateTable(sqlText string) createTable {
+	parsed := createTable{columns: make(map[string]columnDef)}
+	for _, line := range strings.Split(sqlText, "\n") {
+		definition, comment, _ := strings.Cut(line, "--")
+		definition = strings.TrimSpace(definition)
+		comment = strings.TrimSpace(comment)
+		upper := strings.ToUpper(definition)
+		switch {
+		case definition == "" || definition == "(" || strings.HasPrefix(definition, ")"):
+			continue
+		case strings.HasPrefix(upper, "CREATE ") || strings.HasPrefix(upper, "IF "):
+			continue
+		case !tableConstraint.MatchString(definition):
+		case strings.HasPrefix(upper, "FOREIGN"):
+			if m := foreignKeyPattern.FindStringSubmatch(definition); m != nil {
+				parsed.references = append(parsed.references, Reference{
+					Columns:  splitColumns(m[1]),
+					Table:    m[2],
+					Target:   splitColumns(m[3]),
+					Declared: true,
+				})
+			}
+			continue
+		case strings.HasPrefix(upper, "PRIMARY"):
+			if m := primaryKeyPattern.FindStringSubmatch(definition); m != nil {
+				parsed.primaryKey = splitColumns(m[1])
+			}
+			continue
+		default:
+			continue
+		}
+		name, rest, ok := strings.Cut(definition, " ")
+		if !ok {
+			continue
+		}
+		name = strings.Trim(name, `"[]`)
+		rest = strings.TrimSpace(rest)
+		typ := rest
+		if loc := columnTypeEnd.FindStringIndex(rest); loc != nil {
+			typ = rest[:loc[0]]
+		}
+		parsed.columns[name] = columnDef{
+			typ:        strings.TrimSpace(typ),
+			primaryKey: strings.Contains(strings.ToUpper(rest), "PRIMARY KEY"),
+			comment:    comment,
+		}
+	}
+	return parsed
+}
+
+func splitColumns(list string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		if column = strings.Trim(strings.TrimSpace(column), `"[]`); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSchemaMarkdown writes docs as a Markdown document: a Mermaid ER
+// diagram followed by a section per table.
+func WriteSchemaMarkdown(w io.Writer, docs []TableDoc) error {
+	var b strings.Builder
+	b.WriteString("# Database Schema\n\n")
+	b.WriteString("Generated by `badgermaps db docs`. Declared foreign keys are solid lines; references inferred from ID column names are dashed.\n\n")
+	b.WriteString("```mermaid\n")
+	writeMermaid(&b, docs)
+	b.WriteString("```\n")
+	for _, doc := range docs {
+		fmt.Fprintf(&b, "\n## %s\n\n", doc.Name)
+		if doc.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", doc.Description)
+		}
+		b.WriteString("| Column | Type | Key | Description |\n|---|---|---|---|\n")
+		for _, column := range doc.Columns {
+			key := ""
+			if column.PrimaryKey {
+				key = "PK"
+			}
+			for _, ref := range doc.References {
+				if containsFold(ref.Columns, column.Name) {
+					key = strings.TrimPrefix(key+", FK "+ref.Table, ", ")
+				}
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", column.Name, column.Type, key, markdownCell(column.Description))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteSchemaMermaid writes docs as a Mermaid erDiagram.
+func WriteSchemaMermaid(w io.Writer, docs []TableDoc) error {
+	var b strings.Builder
+	writeMermaid(&b, docs)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMermaid(b *strings.Builder, docs []TableDoc) {
+	b.WriteString("erDiagram\n")
+	for _, doc := range docs {
+		for _, ref := range doc.References {
+			line := "}o--||"
+			if !ref.Declared {
+				line = "}o..||"
+			}
+			fmt.Fprintf(b, "    %s %s %s : %q\n", doc.Name, line, ref.Table, strings.Join(ref.Columns, ", "))
+		}
+	}
+	for _, doc := range docs {
+		fmt.Fprintf(b, "    %s {\n", doc.Name)
+		for _, column := range doc.Columns {
+			key := ""
+			if column.PrimaryKey {
+				key = " PK"
+			}
+			fmt.Fprintf(b, "        %s %s%s\n", mermaidType(column.Type), column.Name, key)
+		}
+		b.WriteString("    }\n")
+	}
+}
+
+// mermaidType makes a SQL type a single Mermaid word, such as
+// NVARCHAR(255) to NVARCHAR_255.
+func mermaidType(typ string) string {
+	if typ == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer("(", "_", ")", "", ",", "_", " ", "_").Replace(typ)
+}
+
+// WriteSchemaDot writes docs as a Graphviz digraph with one record node per
+// table.
+func WriteSchemaDot(w io.Writer, docs []TableDoc) error {
+	var b strings.Builder
+	b.WriteString("digraph schema {\n    rankdir=LR;\n    node [shape=record, fontname=\"Helvetica\"];\n")
+	for _, doc := range docs {
+		fields := make([]string, len(doc.Columns))
+		for i, column := range doc.Columns {
+			label := column.Name + ": " + column.Type
+			if column.PrimaryKey {
+				label += " (PK)"
+			}
+			fields[i] = dotEscape(label)
+		}
+		fmt.Fprintf(&b, "    %s [label=\"{%s|%s}\"];\n", doc.Name, doc.Name, strings.Join(fields, "\\l")+"\\l")
+	}
+	for _, doc := range docs {
+		for _, ref := range doc.References {
+			style := ""
+			if !ref.Declared {
+				style = ", style=dashed"
+			}
+			fmt.Fprintf(&b, "    %s -> %s [label=%q%s];\n", doc.Name, ref.Table, strings.Join(ref.Columns, ", "), style)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`).Replace(s)
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCreateTable(t *testing.T) {
+	parsed := parseCreateTable(`IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Things' AND xtype='U')
+CREATE TABLE Things (
+    ThingId INT IDENTITY(1,1) PRIMARY KEY,
+    CheckinId INT NOT NULL,
+    Name NVARCHAR(255), -- shown in reports
+    Status NVARCHAR(10) CHECK(Status IN ('a', 'b')),
+    FOREIGN KEY (CheckinId) REFERENCES AccountCheckins(CheckinId),
+    CHECK (Name <> '')
+)`)
+	want := map[string]columnDef{
+		"ThingId":   {typ: "INT", primaryKey: true},
+		"CheckinId": {typ: "INT"},
+		"Name":      {typ: "NVARCHAR(255)", comment: "shown in reports"},
+		"Status":    {typ: "NVARCHAR(10)"},
+	}
+	if len(parsed.columns) != len(want) {
+		t.Fatalf("columns = %v, want %v", parsed.columns, want)
+	}
+	for name, def := range want {
+		if parsed.columns[name] != def {
+			t.Errorf("column %s = %+v, want %+v", name, parsed.columns[name], def)
+		}
+	}
+	if len(parsed.references) != 1 || parsed.references[0].Table != "AccountCheckins" || !parsed.references[0].Declared {
+		t.Errorf("references = %+v, want the declared AccountCheckins key", parsed.references)
+	}
+}
+
+func TestSchemaDocs(t *testing.T) {
+	db := newBackupTestDB(t, "docs.db")
+	if _, err := db.GetDB().Exec(`UPDATE FieldMaps SET DataSetLabel = 'Region' WHERE FieldName = 'CustomText2' AND ObjectType = 'Account'`); err != nil {
+		t.Fatalf("label FieldMaps: %v", err)
+	}
+	docs, err := SchemaDocs(db)
+	if err != nil {
+		t.Fatalf("SchemaDocs: %v", err)
+	}
+	if len(docs) != len(GetExpectedSchema()) {
+		t.Fatalf("documented %d tables, want %d", len(docs), len(GetExpectedSchema()))
+	}
+	tables := make(map[string]TableDoc)
+	for _, doc := range docs {
+		tables[doc.Name] = doc
+		if doc.Description == "" {
+			t.Errorf("table %s has no description", doc.Name)
+		}
+		for _, column := range doc.Columns {
+			if column.Type == "" {
+				t.Errorf("column %s.%s has no type", doc.Name, column.Name)
+			}
+		}
+	}
+
+	for _, column := range tables["Accounts"].Columns {
+		if column.Name == "CustomText2" && column.Description != `"Region" in BadgerMaps; API field custom_text2` {
+			t.Errorf("CustomText2 description = %q", column.Description)
+		}
+	}
+	refs := func(table string) map[string]bool {
+		found := make(map[string]bool)
+		for _, ref := range tables[table].References {
+			found[strings.Join(ref.Columns, ",")+"->"+ref.Table] = ref.Declared
+		}
+		return found
+	}
+	if declared, ok := refs("AccountCheckins")["AccountId->Accounts"]; !ok || !declared {
+		t.Errorf("AccountCheckins references = %v, want the declared Accounts key", refs("AccountCheckins"))
+	}
+	if declared, ok := refs("RouteWaypoints")["RouteId->Routes"]; !ok || declared {
+		t.Errorf("RouteWaypoints references = %v, want an inferred Routes key", refs("RouteWaypoints"))
+	}
+	// ChangeId is the key of both pending changes tables, so it is not
+	// inferred to point at either.
+	if _, ok := refs("DeletedAccounts")["ChangeId->AccountsPendingChanges"]; ok {
+		t.Errorf("DeletedAccounts references = %v, want no ChangeId reference", refs("DeletedAccounts"))
+	}
+
+	var b strings.Builder
+	if err := WriteSchemaMarkdown(&b, docs); err != nil {
+		t.Fatalf("WriteSchemaMarkdown: %v", err)
+	}
+	for _, want := range []string{"erDiagram", "AccountCheckins }o--|| Accounts", "RouteWaypoints }o..|| Routes", "## Accounts", `| CustomText2 | TEXT |  | "Region" in BadgerMaps; API field custom_text2 |`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("markdown is missing %q", want)
+		}
+	}
+	b.Reset()
+	if err := WriteSchemaDot(&b, docs); err != nil {
+		t.Fatalf("WriteSchemaDot: %v", err)
+	}
+	if !strings.Contains(b.String(), `RouteWaypoints -> Routes [label="RouteId", style=dashed];`) {
+		t.Errorf("dot is missing the RouteWaypoints edge:\n%s", b.String())
+	}
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// schemaNamePattern is what db.schema accepts: a plain identifier, so it
+// needs no quoting in search_path or inside MSSQL dynamic SQL.
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// ValidateDBSchema checks db.schema: it applies to PostgreSQL and MSSQL
+// only and must be a plain identifier.
+func ValidateDBSchema(cfg DBConfig) error {
+	if cfg.Schema == "" {
+		return nil
+	}
+	if cfg.Type != "postgres" && cfg.Type != "mssql" {
+		return fmt.Errorf("db.schema is only supported for postgres and mssql databases, not %s", cfg.Type)
+	}
+	if !schemaNamePattern.MatchString(cfg.Schema) {
+		return fmt.Errorf("db.schema must be a plain identifier of letters, digits and underscores, got %q", cfg.Schema)
+	}
+	return nil
+}
+
+// QualifiedName returns name as queries built in Go must refer to it. On
+// PostgreSQL search_path already points at the configured schema, and
+// SQLite has none, so only MSSQL names get a prefix. name may be quoted.
+func QualifiedName(db DB, name string) string {
+	if mssql, ok := db.(*MSSQLConfig); ok && mssql.Schema != "" {
+		return "[" + mssql.Schema + "]." + name
+	}
+	return name
+}
+
+// searchPath returns the search_path runtime parameter for the configured
+// schema. Only the schema is on it, so nothing is created in public and a
+// missing schema is an error rather than a fallback to public.
+func (db *PostgreSQLConfig) searchPath() string {
+	return pq.QuoteIdentifier(db.Schema)
+}
+
+// ensureSchema creates the configured schema when it does not exist.
+func (db *PostgreSQLConfig) ensureSchema() error {
+	if db.Schema == "" {
+		return nil
+	}
+	if _, err := db.db.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(db.Schema)); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", db.Schema, err)
+	}
+	return nil
+}
+
+// ensureSchema creates the configured schema when it does not exist.
+func (db *MSSQLConfig) ensureSchema() error {
+	if db.Schema == "" {
+		return nil
+	}
+	query := fmt.Sprintf("IF SCHEMA_ID(N'%s') IS NULL EXEC('CREATE SCHEMA [%s]')", db.Schema, db.Schema)
+	if _, err := db.db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", db.Schema, err)
+	}
+	return nil
+}
+
+// inSchema qualifies the objects sqlText refers to with the configured
+// schema. SQL Server has no search_path; unqualified names resolve to the
+// login's default schema, usually dbo.
+func (db *MSSQLConfig) inSchema(sqlText string) string {
+	if db.Schema == "" {
+		return sqlText
+	}
+	return qualifyMSSQL(sqlText, db.Schema, false)
+}
+
+// mssqlObjects are the tables, views, procedures and triggers the MSSQL
+// SQL files create, by lower-case name.
+var mssqlObjects = func() map[string]bool {
+	objects := map[string]bool{}
+	names := append(RequiredTables(), requiredViews()...)
+	names = append(names, "AccountsWithLabelsView", "UpdateFieldMapsFromDatasets",
+		"AccountsChangeCapture", "DatasetsFieldMapsUpdateTrigger", "DatasetsUpdateTrigger")
+	for _, name := range names {
+		objects[strings.ToLower(name)] = true
+	}
+	return objects
+}()
+
+// mssqlObjectKeywords are the keywords an object name follows. A known name
+// anywhere else, such as a column alias or after a dot, is left alone.
+var mssqlObjectKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "DELETE": true,
+	"TABLE": true, "VIEW": true, "PROCEDURE": true, "PROC": true, "TRIGGER": true,
+	"EXEC": true, "EXECUTE": true, "MERGE": true, "USING": true, "ON": true,
+	"REFERENCES": true, "TRUNCATE": true, "IDENTITY_INSERT": true,
+}
+
+func isMSSQLWordByte(c byte) bool {
+	return c == '_' || c == '#' || c == '@' || c == '$' ||
+		c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// qualifyMSSQL prefixes the app's objects in sqlText with schema. Names in
+// string literals are qualified too, for dynamic SQL, as are the literals
+// given to OBJECT_ID. Outside literals SCHEMA_NAME() and SCHEMA_ID() are
+// pinned to schema, so catalog queries look where the objects are.
+func qualifyMSSQL(sqlText, schema string, inLiteral bool) string {
+	var b strings.Builder
+	prefix := "[" + schema + "]."
+	// prev and prev2 are the last two tokens: upper-cased words, or single
+	// punctuation characters.
+	prev, prev2 := "", ""
+	push := func(token string) { prev2, prev = prev, token }
+	nextNonSpace := func(i int) byte {
+		for ; i < len(sqlText); i++ {
+			if c := sqlText[i]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				return c
+			}
+		}
+		return 0
+	}
+	qualifies := func(name string, end int) bool {
+		return mssqlObjects[strings.ToLower(name)] && mssqlObjectKeywords[prev] && nextNonSpace(end) != '.'
+	}
+
+	for i := 0; i < len(sqlText); {
+		c := sqlText[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sqlText[i:], "--"):
+			end := strings.IndexByte(sqlText[i:], '\n')
+			if end < 0 {
+				end = len(sqlText) - i
+			}
+			b.WriteString(sqlText[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(sqlText[i:], "/*"):
+			end := strings.Index(sqlText[i+2:], "*/")
+			if end < 0 {
+				end = len(sqlText) - i - 2
+			} else {
+				end += 2
+			}
+			b.WriteString(sqlText[i : i+2+end])
+			i += 2 + end
+		case c == '\'':
+			end := i + 1
+			for end < len(sqlText) {
+				if sqlText[end] == '\'' {
+					if end+1 < len(sqlText) && sqlText[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			content := sqlText[i+1 : min(end, len(sqlText))]
+			switch {
+			case prev == "(" && prev2 == "OBJECT_ID" && mssqlObjects[strings.ToLower(content)]:
+				content = prefix + content
+			default:
+				content = qualifyMSSQL(content, schema, true)
+			}
+			b.WriteByte('\'')
+			b.WriteString(content)
+			if end < len(sqlText) {
+				b.WriteByte('\'')
+			}
+			i = end + 1
+			push("'")
+		case c == '[':
+			end := strings.IndexByte(sqlText[i:], ']')
+			if end < 0 {
+				b.WriteString(sqlText[i:])
+				i = len(sqlText)
+				continue
+			}
+			name := sqlText[i+1 : i+end]
+			if qualifies(name, i+end+1) {
+				b.WriteString(prefix)
+			}
+			b.WriteString(sqlText[i : i+end+1])
+			i += end + 1
+			push(strings.ToUpper(name))
+		case isMSSQLWordByte(c):
+			end := i
+			for end < len(sqlText) && isMSSQLWordByte(sqlText[end]) {
+				end++
+			}
+			word := sqlText[i:end]
+			upper := strings.ToUpper(word)
+			if !inLiteral && strings.HasPrefix(sqlText[end:], "()") && (upper == "SCHEMA_NAME" || upper == "SCHEMA_ID") {
+				if upper == "SCHEMA_NAME" {
+					fmt.Fprintf(&b, "N'%s'", schema)
+				} else {
+					fmt.Fprintf(&b, "SCHEMA_ID(N'%s')", schema)
+				}
+				i = end + 2
+				push("'")
+				continue
+			}
+			if qualifies(word, end) {
+				b.WriteString(prefix)
+			}
+			b.WriteString(word)
+			i = end
+			push(upper)
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			b.WriteByte(c)
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+			push(string(c))
+		}
+	}
+	return b.String()
+}
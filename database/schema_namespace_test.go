@@ -0,0 +1,107 @@
+package database
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestQualifyMSSQL(t *testing.T) {
+	tests := []struct {
+		name, sql, want string
+	}{
+		{
+			name: "table references",
+			sql:  "SELECT a.AccountId FROM Accounts a JOIN [AccountCheckins] c ON c.AccountId = a.AccountId",
+			want: "SELECT a.AccountId FROM [sync].Accounts a JOIN [sync].[AccountCheckins] c ON c.AccountId = a.AccountId",
+		},
+		{
+			name: "aliases, qualified columns and comments are left alone",
+			sql:  "-- FROM Accounts\nSELECT COUNT(*) AS Accounts FROM Accounts WHERE Accounts.AccountId > 0",
+			want: "-- FROM Accounts\nSELECT COUNT(*) AS Accounts FROM [sync].Accounts WHERE Accounts.AccountId > 0",
+		},
+		{
+			name: "existence checks and current schema",
+			sql:  "IF OBJECT_ID('Routes', 'U') IS NULL CREATE TABLE Routes (RouteId INT); SELECT SCHEMA_NAME(), SCHEMA_ID()",
+			want: "IF OBJECT_ID('[sync].Routes', 'U') IS NULL CREATE TABLE [sync].Routes (RouteId INT); SELECT N'sync', SCHEMA_ID(N'sync')",
+		},
+		{
+			name: "dynamic SQL",
+			sql:  "SET @v = 'CREATE OR ALTER VIEW AccountsWithLabels AS SELECT ' + @s + ' FROM Accounts a;'; EXEC sp_executesql @v",
+			want: "SET @v = 'CREATE OR ALTER VIEW [sync].AccountsWithLabels AS SELECT ' + @s + ' FROM [sync].Accounts a;'; EXEC sp_executesql @v",
+		},
+		{
+			name: "temporary tables and other names",
+			sql:  "MERGE [AccountCheckins] AS target USING #BulkAccountCheckins AS source ON target.CheckinId = source.CheckinId; SELECT 'Accounts' FROM sys.tables",
+			want: "MERGE [sync].[AccountCheckins] AS target USING #BulkAccountCheckins AS source ON target.CheckinId = source.CheckinId; SELECT 'Accounts' FROM sys.tables",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualifyMSSQL(tt.sql, "sync", false); got != tt.want {
+				t.Errorf("qualifyMSSQL\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMSSQLFilesQualified checks that every reference to one of the app's
+// objects after FROM, JOIN, INTO and the like is qualified, outside
+// comments.
+func TestMSSQLFilesQualified(t *testing.T) {
+	var names []string
+	for name := range mssqlObjects {
+		names = append(names, name)
+	}
+	comment := regexp.MustCompile(`--.*`)
+	unqualified := regexp.MustCompile(`(?i)\b(FROM|JOIN|INTO|UPDATE|TABLE|VIEW|MERGE|EXEC|IDENTITY_INSERT)\s+\[?(` + strings.Join(names, "|") + `)\b`)
+	db := &MSSQLConfig{Schema: "sync"}
+	files, err := fs.Glob(mssqlFS, "mssql/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		command := strings.TrimSuffix(strings.TrimPrefix(file, "mssql/"), ".sql")
+		sqlText := comment.ReplaceAllString(db.GetSQL(command), "")
+		if m := unqualified.FindString(sqlText); m != "" {
+			t.Errorf("%s: %q is not qualified", command, m)
+		}
+	}
+}
+
+func TestSchemaConnection(t *testing.T) {
+	pg := &PostgreSQLConfig{Host: "db", Port: 5432, Database: "bm", SSLMode: "disable", Schema: "sync"}
+	for _, dsn := range []string{pg.DatabaseConnection(), pg.DatabaseConnectionWithTimeout()} {
+		if !strings.Contains(dsn, "search_path=%22sync%22") {
+			t.Errorf("expected the search path in %q", dsn)
+		}
+	}
+	pg.Schema = ""
+	if dsn := pg.DatabaseConnection(); strings.Contains(dsn, "search_path") {
+		t.Errorf("did not expect a search path in %q", dsn)
+	}
+
+	if got := QualifiedName(&MSSQLConfig{Schema: "sync"}, "[Accounts]"); got != "[sync].[Accounts]" {
+		t.Errorf("QualifiedName = %q", got)
+	}
+	if got := QualifiedName(&PostgreSQLConfig{Schema: "sync"}, "Accounts"); got != "Accounts" {
+		t.Errorf("QualifiedName on postgres = %q", got)
+	}
+	if got := (&MSSQLConfig{}).GetSQL("CreateRoutesTable"); strings.Contains(got, "[") {
+		t.Errorf("expected SQL without a schema unchanged, got %q", got)
+	}
+}
+
+func TestValidateDBSchema(t *testing.T) {
+	for _, cfg := range []DBConfig{{Type: "sqlite3"}, {Type: "postgres", Schema: "sync"}, {Type: "mssql", Schema: "badger_maps"}} {
+		if err := ValidateDBSchema(cfg); err != nil {
+			t.Errorf("%+v: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []DBConfig{{Type: "sqlite3", Schema: "sync"}, {Type: "postgres", Schema: "sync; DROP"}, {Type: "mssql", Schema: "1sync"}} {
+		if err := ValidateDBSchema(cfg); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
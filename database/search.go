@@ -2,8 +2,10 @@ package database
 
 import (
 	"badgermaps/api/models"
+	"database/sql"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 type CheckinRow struct {
@@ -96,3 +98,190 @@ func SearchCheckins(db DB, q string) ([]CheckinRow, error) {
 	}
 	return list, nil
 }
+
+// GlobalSearchHit is one record matched by GlobalSearch. Table, IDColumn, and
+// ID identify the row so callers can open it in Explorer.
+type GlobalSearchHit struct {
+	Table    string
+	IDColumn string
+	ID       int
+	Title    string
+	Subtitle string
+	// Matched names the field the query matched, such as "Name" or "Comments".
+	Matched string
+}
+
+// GlobalSearchGroup holds the hits for one kind of record.
+type GlobalSearchGroup struct {
+	Name string
+	Hits []GlobalSearchHit
+}
+
+// globalSearchSource runs one GlobalSearch query and turns its rows into hits.
+type globalSearchSource struct {
+	group   string
+	command string
+	scan    func(rows *sql.Rows, q string) (GlobalSearchHit, error)
+}
+
+var globalSearchSources = []globalSearchSource{
+	{group: "Accounts", command: "GlobalSearchAccounts", scan: scanAccountHit},
+	{group: "Check-ins", command: "GlobalSearchCheckins", scan: scanCheckinHit},
+	{group: "Routes", command: "GlobalSearchRoutes", scan: scanRouteHit},
+	{group: "Pending Changes", command: "GlobalSearchAccountChanges", scan: scanAccountChangeHit},
+	{group: "Pending Changes", command: "GlobalSearchCheckinChanges", scan: scanCheckinChangeHit},
+}
+
+// GlobalSearch looks for q in accounts, check-ins, routes, and pending
+// changes at once, matching IDs exactly and names, addresses, and comments
+// by substring. Groups without hits are left out.
+func GlobalSearch(db DB, q string) ([]GlobalSearchGroup, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, nil
+	}
+	lower := strings.ToLower(q)
+
+	var groups []GlobalSearchGroup
+	for _, source := range globalSearchSources {
+		sqlText := db.GetSQL(source.command)
+		if sqlText == "" {
+			return nil, fmt.Errorf("unknown or unavailable SQL command: %s", source.command)
+		}
+		rows, err := db.GetDB().Query(sqlText, q, "%"+lower+"%", lower+"%")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.command, err)
+		}
+		var hits []GlobalSearchHit
+		for rows.Next() {
+			hit, err := source.scan(rows, lower)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("%s: %w", source.command, err)
+			}
+			hits = append(hits, hit)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.command, err)
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		if n := len(groups); n > 0 && groups[n-1].Name == source.group {
+			groups[n-1].Hits = append(groups[n-1].Hits, hits...)
+		} else {
+			groups = append(groups, GlobalSearchGroup{Name: source.group, Hits: hits})
+		}
+	}
+	return groups, nil
+}
+
+func scanAccountHit(rows *sql.Rows, q string) (GlobalSearchHit, error) {
+	var id int
+	var name, address sql.NullString
+	hit := GlobalSearchHit{Table: "Accounts", IDColumn: "AccountId"}
+	if err := rows.Scan(&id, &name, &address, &hit.Matched); err != nil {
+		return hit, err
+	}
+	hit.ID = id
+	hit.Title = fmt.Sprintf("%s (#%d)", name.String, id)
+	hit.Subtitle = address.String
+	return hit, nil
+}
+
+func scanCheckinHit(rows *sql.Rows, q string) (GlobalSearchHit, error) {
+	var id, accountID int
+	var name, logDatetime, checkinType, comments sql.NullString
+	hit := GlobalSearchHit{Table: "AccountCheckins", IDColumn: "CheckinId"}
+	if err := rows.Scan(&id, &accountID, &name, &logDatetime, &checkinType, &comments, &hit.Matched); err != nil {
+		return hit, err
+	}
+	hit.ID = id
+	hit.Title = fmt.Sprintf("%s (#%d)", name.String, id)
+	hit.Subtitle = joinNonEmpty(" • ", logDatetime.String, checkinType.String, searchSnippet(comments.String, q))
+	return hit, nil
+}
+
+func scanRouteHit(rows *sql.Rows, q string) (GlobalSearchHit, error) {
+	var id int
+	var name, routeDate, start, destination sql.NullString
+	hit := GlobalSearchHit{Table: "Routes", IDColumn: "RouteId"}
+	if err := rows.Scan(&id, &name, &routeDate, &start, &destination, &hit.Matched); err != nil {
+		return hit, err
+	}
+	hit.ID = id
+	hit.Title = fmt.Sprintf("%s (#%d)", name.String, id)
+	addresses := joinNonEmpty(" → ", start.String, destination.String)
+	hit.Subtitle = joinNonEmpty(" • ", routeDate.String, addresses)
+	return hit, nil
+}
+
+func scanAccountChangeHit(rows *sql.Rows, q string) (GlobalSearchHit, error) {
+	var id, accountID int
+	var name, changeType, status, changes sql.NullString
+	hit := GlobalSearchHit{Table: "AccountsPendingChanges", IDColumn: "ChangeId"}
+	if err := rows.Scan(&id, &accountID, &name, &changeType, &status, &changes, &hit.Matched); err != nil {
+		return hit, err
+	}
+	hit.ID = id
+	hit.Title = fmt.Sprintf("Account %s: %s (#%d)", changeType.String, name.String, accountID)
+	hit.Subtitle = joinNonEmpty(" • ", fmt.Sprintf("Change #%d", id), status.String, searchSnippet(changes.String, q))
+	return hit, nil
+}
+
+func scanCheckinChangeHit(rows *sql.Rows, q string) (GlobalSearchHit, error) {
+	var id, checkinID, accountID int
+	var name, changeType, status, comments sql.NullString
+	hit := GlobalSearchHit{Table: "AccountCheckinsPendingChanges", IDColumn: "ChangeId"}
+	if err := rows.Scan(&id, &checkinID, &accountID, &name, &changeType, &status, &comments, &hit.Matched); err != nil {
+		return hit, err
+	}
+	hit.ID = id
+	hit.Title = fmt.Sprintf("Check-in %s: %s (#%d)", changeType.String, name.String, checkinID)
+	hit.Subtitle = joinNonEmpty(" • ", fmt.Sprintf("Change #%d", id), status.String, searchSnippet(comments.String, q))
+	return hit, nil
+}
+
+// searchSnippet returns a single-line excerpt of text around the first match
+// of q, or the start of text when q does not appear in it.
+func searchSnippet(text, q string) string {
+	const radius = 40
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return ""
+	}
+	runes := []rune(text)
+	start := 0
+	// ToLower maps rune for rune, so rune offsets carry over to text.
+	lower := strings.ToLower(text)
+	if idx := strings.Index(lower, q); idx >= 0 && q != "" {
+		start = utf8.RuneCountInString(lower[:idx]) - radius
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + 2*radius
+	if end > len(runes) {
+		end = len(runes)
+	}
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := parts[:0:0]
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
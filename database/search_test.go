@@ -0,0 +1,80 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobalSearch(t *testing.T) {
+	db := newBackupTestDB(t, "search.db")
+	seed := []string{
+		`INSERT INTO Accounts (AccountId, FullName, OriginalAddress, Notes) VALUES (101, 'Acme Hardware', '1 Main St', 'Prefers morning visits')`,
+		`INSERT INTO Accounts (AccountId, FullName, OriginalAddress) VALUES (102, 'Blue Diner', '9 Elm Rd')`,
+		`INSERT INTO AccountLocations (AccountId, City, AddressLine1) VALUES (102, 'Springfield', '9 Elm Rd')`,
+		`INSERT INTO AccountCheckins (CheckinId, AccountId, LogDatetime, Type, Comments) VALUES (5001, 102, '2024-03-01', 'Visit', 'Asked about the springfield promo')`,
+		`INSERT INTO Routes (RouteId, Name, RouteDate, StartAddress, DestinationAddress) VALUES (77, 'Tuesday Loop', '2024-03-05', 'Depot', 'Springfield Mall')`,
+		`INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes) VALUES (101, 'UPDATE', '{"Notes":"Moved to Springfield"}')`,
+		`INSERT INTO AccountCheckinsPendingChanges (CheckinId, AccountId, ChangeType, Comments) VALUES (0, 101, 'CREATE', 'Dropped off samples')`,
+	}
+	for _, stmt := range seed {
+		if _, err := db.GetDB().Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+
+	groups, err := GlobalSearch(db, "Springfield")
+	if err != nil {
+		t.Fatalf("GlobalSearch: %v", err)
+	}
+	got := map[string][]GlobalSearchHit{}
+	var order []string
+	for _, g := range groups {
+		got[g.Name] = g.Hits
+		order = append(order, g.Name)
+	}
+	if strings.Join(order, ",") != "Accounts,Check-ins,Routes,Pending Changes" {
+		t.Fatalf("group order = %v", order)
+	}
+	if hits := got["Accounts"]; len(hits) != 1 || hits[0].ID != 102 || hits[0].Matched != "Address" {
+		t.Fatalf("account hits = %+v", hits)
+	}
+	if hits := got["Check-ins"]; len(hits) != 1 || hits[0].ID != 5001 || hits[0].Matched != "Comments" || hits[0].Table != "AccountCheckins" {
+		t.Fatalf("check-in hits = %+v", hits)
+	}
+	if hits := got["Routes"]; len(hits) != 1 || hits[0].IDColumn != "RouteId" || hits[0].Matched != "Address" {
+		t.Fatalf("route hits = %+v", hits)
+	}
+	if hits := got["Pending Changes"]; len(hits) != 1 || hits[0].Table != "AccountsPendingChanges" || !strings.Contains(hits[0].Subtitle, "Springfield") {
+		t.Fatalf("pending change hits = %+v", hits)
+	}
+
+	groups, err = GlobalSearch(db, "101")
+	if err != nil {
+		t.Fatalf("GlobalSearch by ID: %v", err)
+	}
+	var tables []string
+	for _, g := range groups {
+		for _, hit := range g.Hits {
+			tables = append(tables, hit.Table+":"+hit.Matched)
+		}
+	}
+	want := "Accounts:ID,AccountsPendingChanges:Account ID,AccountCheckinsPendingChanges:Account ID"
+	if strings.Join(tables, ",") != want {
+		t.Fatalf("ID search hits = %v, want %s", tables, want)
+	}
+
+	if groups, err := GlobalSearch(db, "  "); err != nil || groups != nil {
+		t.Fatalf("blank query = %v, %v", groups, err)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	text := strings.Repeat("a", 100) + " needle " + strings.Repeat("b", 100)
+	got := searchSnippet(text, "needle")
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "needle") {
+		t.Fatalf("searchSnippet = %q", got)
+	}
+	if got := searchSnippet("short\nnote", "x"); got != "short note" {
+		t.Fatalf("searchSnippet = %q", got)
+	}
+}
@@ -0,0 +1,24 @@
+package database
+
+import "fmt"
+
+// DeleteSeedRows removes the accounts with IDs of at least firstId, with
+// their locations and check-ins, and returns how many rows went. It clears
+// the rows written by 'dev seed'.
+func DeleteSeedRows(db DB, firstId int64) (int64, error) {
+	var removed int64
+	for _, command := range []string{"DeleteSeedCheckins", "DeleteSeedAccountLocations", "DeleteSeedAccounts"} {
+		sqlText := db.GetSQL(command)
+		if sqlText == "" {
+			return removed, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+		}
+		n, err := rowsAffected(db.GetDB().Exec(sqlText, firstId))
+		if err != nil {
+			return removed, err
+		}
+		if n > 0 {
+			removed += n
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrSQLiteKeyRequired is returned when an encrypted SQLite database is
+	// opened without a passphrase.
+	ErrSQLiteKeyRequired = errors.New("database is encrypted and no passphrase was provided")
+	// ErrSQLiteEncrypted is returned when the config does not mark the
+	// database as encrypted but the file on disk is not plain SQLite.
+	ErrSQLiteEncrypted = errors.New("database file is encrypted; set db.encrypted to true and provide the passphrase")
+	// ErrSQLiteNotEncrypted is returned when the config marks the database as
+	// encrypted but the file on disk is still plain SQLite.
+	ErrSQLiteNotEncrypted = errors.New("database file is not encrypted yet; run 'badgermaps db encrypt' to convert it")
+
+	errNoSQLCipher = errors.New("this build has no SQLCipher support; rebuild with -tags sqlcipher")
+)
+
+// SQLCipherEnabled reports whether this binary can open encrypted SQLite
+// databases.
+func SQLCipherEnabled() bool {
+	return sqlcipherBuild
+}
+
+// IsSQLiteFileEncrypted reports whether the file at path holds data that is
+// not a plain SQLite database. A missing or empty file is not encrypted.
+func IsSQLiteFileEncrypted(path string) (bool, error) {
+	header, err := readSQLiteHeader(path)
+	if err != nil || len(header) == 0 {
+		return false, err
+	}
+	return !bytes.Equal(header, []byte(sqliteFileHeader)), nil
+}
+
+// isPlainSQLiteFile reports whether path exists and starts with the SQLite
+// file header.
+func isPlainSQLiteFile(path string) (bool, error) {
+	header, err := readSQLiteHeader(path)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(header, []byte(sqliteFileHeader)), nil
+}
+
+func readSQLiteHeader(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	header := make([]byte, len(sqliteFileHeader))
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// connectEncrypted opens an SQLCipher database with db.Key.
+func (db *SQLiteConfig) connectEncrypted() error {
+	db.connected.Store(false)
+	if !sqlcipherBuild {
+		return errNoSQLCipher
+	}
+	if db.Key == "" {
+		return ErrSQLiteKeyRequired
+	}
+	plain, err := isPlainSQLiteFile(db.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read SQLite database: %w", err)
+	}
+	if plain {
+		return ErrSQLiteNotEncrypted
+	}
+
+	sqlDB, err := openSQLCipher(db.DatabaseConnection(), db.Key)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	if err := verifySQLCipher(sqlDB); err != nil {
+		sqlDB.Close()
+		return err
+	}
+	db.db = sqlDB
+	return nil
+}
+
+// verifySQLCipher checks that the driver is linked against SQLCipher and that
+// the key opens the database.
+func verifySQLCipher(sqlDB *sql.DB) error {
+	var version string
+	if err := sqlDB.QueryRow("PRAGMA cipher_version").Scan(&version); err != nil || version == "" {
+		return fmt.Errorf("SQLite driver is not linked against SQLCipher; see the build notes for -tags sqlcipher")
+	}
+	var count int
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM sqlite_master").Scan(&count); err != nil {
+		return fmt.Errorf("cannot open encrypted database, check the passphrase: %w", err)
+	}
+	return nil
+}
+
+// EncryptSQLiteFile converts the unencrypted SQLite database at path to
+// SQLCipher with key. The encrypted copy is written next to the original and
+// only replaces it once the export is complete. The database must not be open
+// elsewhere.
+func EncryptSQLiteFile(path, key string) error {
+	if !sqlcipherBuild {
+		return errNoSQLCipher
+	}
+	if key == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+	plain, err := isPlainSQLiteFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read SQLite database: %w", err)
+	}
+	if !plain {
+		return fmt.Errorf("%s is not an unencrypted SQLite database", path)
+	}
+
+	tmp := path + ".encrypting"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale %s: %w", tmp, err)
+	}
+	err = withSingleConn(path, "", func(ctx context.Context, conn *sql.Conn) error {
+		statements := []string{
+			"PRAGMA wal_checkpoint(TRUNCATE)",
+			fmt.Sprintf("ATTACH DATABASE %s AS encrypted KEY %s", quoteSQLiteString(tmp), quoteSQLiteString(key)),
+			"SELECT sqlcipher_export('encrypted')",
+			"DETACH DATABASE encrypted",
+		}
+		for _, stmt := range statements {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to encrypt database: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace database with encrypted copy: %w", err)
+	}
+	removeSQLiteSidecars(path)
+	return nil
+}
+
+// RekeySQLiteFile re-encrypts the SQLCipher database at path from oldKey to
+// newKey. The database must not be open elsewhere.
+func RekeySQLiteFile(path, oldKey, newKey string) error {
+	if !sqlcipherBuild {
+		return errNoSQLCipher
+	}
+	if oldKey == "" {
+		return ErrSQLiteKeyRequired
+	}
+	if newKey == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+	return withSingleConn(path, oldKey, func(ctx context.Context, conn *sql.Conn) error {
+		var count int
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master").Scan(&count); err != nil {
+			return fmt.Errorf("cannot open encrypted database, check the current passphrase: %w", err)
+		}
+		if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			return fmt.Errorf("failed to checkpoint WAL: %w", err)
+		}
+		// SQLCipher cannot rekey a database in WAL mode.
+		var journalMode string
+		if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode); err != nil {
+			return err
+		}
+		if strings.EqualFold(journalMode, "wal") {
+			if _, err := conn.ExecContext(ctx, "PRAGMA journal_mode = DELETE"); err != nil {
+				return err
+			}
+			defer conn.ExecContext(ctx, "PRAGMA journal_mode = WAL")
+		}
+		if _, err := conn.ExecContext(ctx, "PRAGMA rekey = "+quoteSQLiteString(newKey)); err != nil {
+			return fmt.Errorf("failed to change passphrase: %w", err)
+		}
+		return nil
+	})
+}
+
+// withSingleConn opens path through SQLCipher and runs fn on one connection,
+// so statements that depend on connection state see the same session.
+func withSingleConn(path, key string, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	sqlDB, err := openSQLCipher(fmt.Sprintf("file:%s", path), key)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	defer conn.Close()
+	return fn(ctx, conn)
+}
+
+// removeSQLiteSidecars deletes WAL and shared-memory files left by the
+// database that was replaced at path.
+func removeSQLiteSidecars(path string) {
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		os.Remove(path + suffix)
+	}
+}
+
+// quoteSQLiteString quotes s as an SQLite string literal. PRAGMA and ATTACH
+// do not accept bound parameters for keys.
+func quoteSQLiteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
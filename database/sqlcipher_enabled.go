@@ -0,0 +1,53 @@
+//go:build sqlcipher
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Building with -tags sqlcipher expects go-sqlite3 to link against the
+// SQLCipher library instead of its bundled SQLite, for example:
+//
+//	CGO_CFLAGS="-DSQLITE_HAS_CODEC -I/usr/include/sqlcipher" \
+//	CGO_LDFLAGS="-lsqlcipher" \
+//	go build -tags "sqlcipher libsqlite3" .
+//
+// verifySQLCipher rejects binaries where that linkage did not happen, because
+// plain SQLite silently ignores the key and would store data unencrypted.
+const sqlcipherBuild = true
+
+// sqlcipherConnector opens connections with a driver whose connect hook sets
+// the key, so every connection in the pool is unlocked.
+type sqlcipherConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+func (c *sqlcipherConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *sqlcipherConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// openSQLCipher opens dsn with key. An empty key opens the file unencrypted,
+// which EncryptSQLiteFile uses to read the plaintext source.
+func openSQLCipher(dsn, key string) (*sql.DB, error) {
+	drv := &sqlite3.SQLiteDriver{ConnectHook: registerSQLiteMathFunctions}
+	if key != "" {
+		pragma := "PRAGMA key = " + quoteSQLiteString(key)
+		drv.ConnectHook = func(conn *sqlite3.SQLiteConn) error {
+			if _, err := conn.Exec(pragma, nil); err != nil {
+				return err
+			}
+			return registerSQLiteMathFunctions(conn)
+		}
+	}
+	return sql.OpenDB(&sqlcipherConnector{dsn: dsn, driver: drv}), nil
+}
@@ -0,0 +1,13 @@
+//go:build !sqlcipher
+
+package database
+
+import "database/sql"
+
+const sqlcipherBuild = false
+
+// openSQLCipher always fails because this binary was built without the
+// sqlcipher build tag.
+func openSQLCipher(dsn, key string) (*sql.DB, error) {
+	return nil, errNoSQLCipher
+}
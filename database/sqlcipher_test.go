@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSQLiteFileEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	plain := newBackupTestDB(t, "plain.db").(*SQLiteConfig).Path
+	garbage := filepath.Join(dir, "cipher.db")
+	if err := os.WriteFile(garbage, []byte("\x8f\x12random-page-bytes-that-are-not-sqlite"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.db")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"plain", plain, false},
+		{"encrypted", garbage, true},
+		{"empty", empty, false},
+		{"missing", filepath.Join(dir, "missing.db"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsSQLiteFileEncrypted(tt.path)
+			if err != nil {
+				t.Fatalf("IsSQLiteFileEncrypted: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("IsSQLiteFileEncrypted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteConnectDetectsEncryptionMismatch(t *testing.T) {
+	dir := t.TempDir()
+	cipherPath := filepath.Join(dir, "cipher.db")
+	if err := os.WriteFile(cipherPath, []byte("\x8f\x12random-page-bytes-that-are-not-sqlite"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	db, _ := NewDB(&DBConfig{Type: "sqlite3", Path: cipherPath})
+	if err := db.Connect(); !errors.Is(err, ErrSQLiteEncrypted) {
+		t.Fatalf("Connect on encrypted file without db.encrypted = %v, want ErrSQLiteEncrypted", err)
+	}
+
+	if SQLCipherEnabled() {
+		t.Skip("remaining checks cover builds without SQLCipher")
+	}
+	db, _ = NewDB(&DBConfig{Type: "sqlite3", Path: cipherPath, Encrypted: true})
+	db.(*SQLiteConfig).Key = "secret"
+	if err := db.Connect(); !errors.Is(err, errNoSQLCipher) {
+		t.Fatalf("Connect with db.encrypted in a plain build = %v, want errNoSQLCipher", err)
+	}
+	if err := EncryptSQLiteFile(cipherPath, "secret"); !errors.Is(err, errNoSQLCipher) {
+		t.Fatalf("EncryptSQLiteFile in a plain build = %v, want errNoSQLCipher", err)
+	}
+}
+
+func TestSQLiteConfigKeepsEncryptedFlag(t *testing.T) {
+	db, _ := NewDB(&DBConfig{Type: "sqlite3", Path: "x.db", Encrypted: true})
+	var saved DBConfig
+	db.SaveConfig(&saved)
+	if !saved.Encrypted {
+		t.Fatal("SaveConfig dropped the encrypted flag")
+	}
+}
+
+func TestQuoteSQLiteString(t *testing.T) {
+	if got := quoteSQLiteString("it's"); got != "'it''s'" {
+		t.Fatalf("quoteSQLiteString = %s", got)
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE AccountCheckinsPendingChanges ADD COLUMN ContentHash TEXT;
\ No newline at end of file
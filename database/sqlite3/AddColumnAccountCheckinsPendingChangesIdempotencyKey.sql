@@ -0,0 +1 @@
+ALTER TABLE AccountCheckinsPendingChanges ADD COLUMN IdempotencyKey TEXT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD COLUMN BaseUpdatedAt DATETIME;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD COLUMN ContentHash TEXT;
\ No newline at end of file
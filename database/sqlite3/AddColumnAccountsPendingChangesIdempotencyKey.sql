@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD COLUMN IdempotencyKey TEXT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE AccountsPendingChanges ADD COLUMN ValidationErrors TEXT;
\ No newline at end of file
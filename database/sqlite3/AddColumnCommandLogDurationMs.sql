@@ -0,0 +1 @@
+ALTER TABLE CommandLog ADD COLUMN DurationMs INTEGER;
\ No newline at end of file
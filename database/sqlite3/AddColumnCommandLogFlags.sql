@@ -0,0 +1 @@
+ALTER TABLE CommandLog ADD COLUMN Flags TEXT;
\ No newline at end of file
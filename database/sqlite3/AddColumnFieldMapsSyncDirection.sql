@@ -0,0 +1 @@
+ALTER TABLE FieldMaps ADD COLUMN SyncDirection TEXT NOT NULL DEFAULT 'both' CHECK(SyncDirection IN ('both', 'pull', 'push'));
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE SyncHistory ADD COLUMN Note TEXT;
\ No newline at end of file
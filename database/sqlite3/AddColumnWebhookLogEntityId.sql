@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD COLUMN EntityId INTEGER;
\ No newline at end of file
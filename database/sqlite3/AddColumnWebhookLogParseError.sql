@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD COLUMN ParseError TEXT;
\ No newline at end of file
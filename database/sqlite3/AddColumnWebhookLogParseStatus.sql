@@ -0,0 +1 @@
+ALTER TABLE WebhookLog ADD COLUMN ParseStatus TEXT;
\ No newline at end of file
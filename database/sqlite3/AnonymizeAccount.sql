@@ -0,0 +1,74 @@
+UPDATE Accounts SET
+    LastName = ?,
+    FullName = ?,
+    FirstName = NULL,
+    PhoneNumber = NULL,
+    Email = NULL,
+    CustomerId = NULL,
+    Notes = NULL,
+    OriginalAddress = NULL,
+    CrmId = NULL,
+    AccountOwner = NULL,
+    FollowUpDate = NULL,
+    CustomNumeric = NULL,
+    CustomText = NULL,
+    CustomNumeric2 = NULL,
+    CustomText2 = NULL,
+    CustomNumeric3 = NULL,
+    CustomText3 = NULL,
+    CustomNumeric4 = NULL,
+    CustomText4 = NULL,
+    CustomNumeric5 = NULL,
+    CustomText5 = NULL,
+    CustomNumeric6 = NULL,
+    CustomText6 = NULL,
+    CustomNumeric7 = NULL,
+    CustomText7 = NULL,
+    CustomNumeric8 = NULL,
+    CustomText8 = NULL,
+    CustomNumeric9 = NULL,
+    CustomText9 = NULL,
+    CustomNumeric10 = NULL,
+    CustomText10 = NULL,
+    CustomNumeric11 = NULL,
+    CustomText11 = NULL,
+    CustomNumeric12 = NULL,
+    CustomText12 = NULL,
+    CustomNumeric13 = NULL,
+    CustomText13 = NULL,
+    CustomNumeric14 = NULL,
+    CustomText14 = NULL,
+    CustomNumeric15 = NULL,
+    CustomText15 = NULL,
+    CustomNumeric16 = NULL,
+    CustomText16 = NULL,
+    CustomNumeric17 = NULL,
+    CustomText17 = NULL,
+    CustomNumeric18 = NULL,
+    CustomText18 = NULL,
+    CustomNumeric19 = NULL,
+    CustomText19 = NULL,
+    CustomNumeric20 = NULL,
+    CustomText20 = NULL,
+    CustomNumeric21 = NULL,
+    CustomText21 = NULL,
+    CustomNumeric22 = NULL,
+    CustomText22 = NULL,
+    CustomNumeric23 = NULL,
+    CustomText23 = NULL,
+    CustomNumeric24 = NULL,
+    CustomText24 = NULL,
+    CustomNumeric25 = NULL,
+    CustomText25 = NULL,
+    CustomNumeric26 = NULL,
+    CustomText26 = NULL,
+    CustomNumeric27 = NULL,
+    CustomText27 = NULL,
+    CustomNumeric28 = NULL,
+    CustomText28 = NULL,
+    CustomNumeric29 = NULL,
+    CustomText29 = NULL,
+    CustomNumeric30 = NULL,
+    CustomText30 = NULL,
+    UpdatedAt = CURRENT_TIMESTAMP
+WHERE AccountId = ?;
\ No newline at end of file
@@ -0,0 +1,2 @@
+UPDATE AccountCheckins SET CrmId = NULL, Comments = NULL, ExtraFields = NULL, CreatedBy = NULL
+WHERE AccountId = ?;
\ No newline at end of file
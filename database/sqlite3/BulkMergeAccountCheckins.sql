@@ -0,0 +1,3 @@
+INSERT OR REPLACE INTO AccountCheckins (
+    CheckinId, CrmId, AccountId, LogDatetime, Type, Comments, ExtraFields, EndpointType, CreatedBy, UpdatedAt
+) VALUES
\ No newline at end of file
@@ -12,5 +12,7 @@ CREATE TABLE IF NOT EXISTS AccountCheckinsPendingChanges (
     ChangeType TEXT NOT NULL CHECK(ChangeType IN ('CREATE', 'UPDATE', 'DELETE')),
     Status TEXT NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
     CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
-    ProcessedAt DATETIME
+    ProcessedAt DATETIME,
+    IdempotencyKey TEXT,
+    ContentHash TEXT
 );
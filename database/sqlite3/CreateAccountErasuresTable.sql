@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS AccountErasures (
+    ErasureId INTEGER PRIMARY KEY AUTOINCREMENT,
+    AccountId INTEGER NOT NULL,
+    Mode TEXT NOT NULL CHECK(Mode IN ('delete', 'anonymize')),
+    ReportId TEXT NOT NULL,
+    ReportDigest TEXT NOT NULL, -- SHA-256 of the signed erasure report
+    DeleteChangeId INTEGER, -- staged DELETE sent to BadgerMaps, if any
+    ErasedAt DATETIME NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS AccountScores (
+    AccountId INTEGER NOT NULL,
+    ScoreName TEXT NOT NULL,
+    Score REAL NOT NULL,
+    ComputedAt DATETIME NOT NULL,
+    PRIMARY KEY (AccountId, ScoreName)
+);
\ No newline at end of file
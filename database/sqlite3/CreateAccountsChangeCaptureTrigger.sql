@@ -0,0 +1,91 @@
+-- Captures changes made to Accounts outside the app as pending changes.
+-- The app pauses capture around its own writes through ChangeCaptureState.
+CREATE TRIGGER IF NOT EXISTS AccountsChangeCapture
+AFTER UPDATE ON Accounts
+FOR EACH ROW
+WHEN COALESCE((SELECT Suppressed FROM ChangeCaptureState WHERE Id = 1), 0) = 0
+BEGIN
+    INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, BaseUpdatedAt)
+    SELECT NEW.AccountId, 'UPDATE', json_group_object(Field, Value), NEW.UpdatedAt
+    FROM (
+        SELECT 'first_name' AS Field, COALESCE(CAST(NEW.FirstName AS TEXT), '') AS Value WHERE NEW.FirstName IS NOT OLD.FirstName
+        UNION ALL SELECT 'last_name', COALESCE(CAST(NEW.LastName AS TEXT), '') WHERE NEW.LastName IS NOT OLD.LastName
+        UNION ALL SELECT 'phone_number', COALESCE(CAST(NEW.PhoneNumber AS TEXT), '') WHERE NEW.PhoneNumber IS NOT OLD.PhoneNumber
+        UNION ALL SELECT 'email', COALESCE(CAST(NEW.Email AS TEXT), '') WHERE NEW.Email IS NOT OLD.Email
+        UNION ALL SELECT 'customer_id', COALESCE(CAST(NEW.CustomerId AS TEXT), '') WHERE NEW.CustomerId IS NOT OLD.CustomerId
+        UNION ALL SELECT 'notes', COALESCE(CAST(NEW.Notes AS TEXT), '') WHERE NEW.Notes IS NOT OLD.Notes
+        UNION ALL SELECT 'crm_id', COALESCE(CAST(NEW.CrmId AS TEXT), '') WHERE NEW.CrmId IS NOT OLD.CrmId
+        UNION ALL SELECT 'account_owner', COALESCE(CAST(NEW.AccountOwner AS TEXT), '') WHERE NEW.AccountOwner IS NOT OLD.AccountOwner
+        UNION ALL SELECT 'follow_up_date', COALESCE(CAST(NEW.FollowUpDate AS TEXT), '') WHERE NEW.FollowUpDate IS NOT OLD.FollowUpDate
+        UNION ALL SELECT 'custom_numeric', COALESCE(CAST(NEW.CustomNumeric AS TEXT), '') WHERE NEW.CustomNumeric IS NOT OLD.CustomNumeric
+        UNION ALL SELECT 'custom_text', COALESCE(CAST(NEW.CustomText AS TEXT), '') WHERE NEW.CustomText IS NOT OLD.CustomText
+        UNION ALL SELECT 'custom_numeric2', COALESCE(CAST(NEW.CustomNumeric2 AS TEXT), '') WHERE NEW.CustomNumeric2 IS NOT OLD.CustomNumeric2
+        UNION ALL SELECT 'custom_text2', COALESCE(CAST(NEW.CustomText2 AS TEXT), '') WHERE NEW.CustomText2 IS NOT OLD.CustomText2
+        UNION ALL SELECT 'custom_numeric3', COALESCE(CAST(NEW.CustomNumeric3 AS TEXT), '') WHERE NEW.CustomNumeric3 IS NOT OLD.CustomNumeric3
+        UNION ALL SELECT 'custom_text3', COALESCE(CAST(NEW.CustomText3 AS TEXT), '') WHERE NEW.CustomText3 IS NOT OLD.CustomText3
+        UNION ALL SELECT 'custom_numeric4', COALESCE(CAST(NEW.CustomNumeric4 AS TEXT), '') WHERE NEW.CustomNumeric4 IS NOT OLD.CustomNumeric4
+        UNION ALL SELECT 'custom_text4', COALESCE(CAST(NEW.CustomText4 AS TEXT), '') WHERE NEW.CustomText4 IS NOT OLD.CustomText4
+        UNION ALL SELECT 'custom_numeric5', COALESCE(CAST(NEW.CustomNumeric5 AS TEXT), '') WHERE NEW.CustomNumeric5 IS NOT OLD.CustomNumeric5
+        UNION ALL SELECT 'custom_text5', COALESCE(CAST(NEW.CustomText5 AS TEXT), '') WHERE NEW.CustomText5 IS NOT OLD.CustomText5
+        UNION ALL SELECT 'custom_numeric6', COALESCE(CAST(NEW.CustomNumeric6 AS TEXT), '') WHERE NEW.CustomNumeric6 IS NOT OLD.CustomNumeric6
+        UNION ALL SELECT 'custom_text6', COALESCE(CAST(NEW.CustomText6 AS TEXT), '') WHERE NEW.CustomText6 IS NOT OLD.CustomText6
+        UNION ALL SELECT 'custom_numeric7', COALESCE(CAST(NEW.CustomNumeric7 AS TEXT), '') WHERE NEW.CustomNumeric7 IS NOT OLD.CustomNumeric7
+        UNION ALL SELECT 'custom_text7', COALESCE(CAST(NEW.CustomText7 AS TEXT), '') WHERE NEW.CustomText7 IS NOT OLD.CustomText7
+        UNION ALL SELECT 'custom_numeric8', COALESCE(CAST(NEW.CustomNumeric8 AS TEXT), '') WHERE NEW.CustomNumeric8 IS NOT OLD.CustomNumeric8
+        UNION ALL SELECT 'custom_text8', COALESCE(CAST(NEW.CustomText8 AS TEXT), '') WHERE NEW.CustomText8 IS NOT OLD.CustomText8
+        UNION ALL SELECT 'custom_numeric9', COALESCE(CAST(NEW.CustomNumeric9 AS TEXT), '') WHERE NEW.CustomNumeric9 IS NOT OLD.CustomNumeric9
+        UNION ALL SELECT 'custom_text9', COALESCE(CAST(NEW.CustomText9 AS TEXT), '') WHERE NEW.CustomText9 IS NOT OLD.CustomText9
+        UNION ALL SELECT 'custom_numeric10', COALESCE(CAST(NEW.CustomNumeric10 AS TEXT), '') WHERE NEW.CustomNumeric10 IS NOT OLD.CustomNumeric10
+        UNION ALL SELECT 'custom_text10', COALESCE(CAST(NEW.CustomText10 AS TEXT), '') WHERE NEW.CustomText10 IS NOT OLD.CustomText10
+        UNION ALL SELECT 'custom_numeric11', COALESCE(CAST(NEW.CustomNumeric11 AS TEXT), '') WHERE NEW.CustomNumeric11 IS NOT OLD.CustomNumeric11
+        UNION ALL SELECT 'custom_text11', COALESCE(CAST(NEW.CustomText11 AS TEXT), '') WHERE NEW.CustomText11 IS NOT OLD.CustomText11
+        UNION ALL SELECT 'custom_numeric12', COALESCE(CAST(NEW.CustomNumeric12 AS TEXT), '') WHERE NEW.CustomNumeric12 IS NOT OLD.CustomNumeric12
+        UNION ALL SELECT 'custom_text12', COALESCE(CAST(NEW.CustomText12 AS TEXT), '') WHERE NEW.CustomText12 IS NOT OLD.CustomText12
+        UNION ALL SELECT 'custom_numeric13', COALESCE(CAST(NEW.CustomNumeric13 AS TEXT), '') WHERE NEW.CustomNumeric13 IS NOT OLD.CustomNumeric13
+        UNION ALL SELECT 'custom_text13', COALESCE(CAST(NEW.CustomText13 AS TEXT), '') WHERE NEW.CustomText13 IS NOT OLD.CustomText13
+        UNION ALL SELECT 'custom_numeric14', COALESCE(CAST(NEW.CustomNumeric14 AS TEXT), '') WHERE NEW.CustomNumeric14 IS NOT OLD.CustomNumeric14
+        UNION ALL SELECT 'custom_text14', COALESCE(CAST(NEW.CustomText14 AS TEXT), '') WHERE NEW.CustomText14 IS NOT OLD.CustomText14
+        UNION ALL SELECT 'custom_numeric15', COALESCE(CAST(NEW.CustomNumeric15 AS TEXT), '') WHERE NEW.CustomNumeric15 IS NOT OLD.CustomNumeric15
+        UNION ALL SELECT 'custom_text15', COALESCE(CAST(NEW.CustomText15 AS TEXT), '') WHERE NEW.CustomText15 IS NOT OLD.CustomText15
+        UNION ALL SELECT 'custom_numeric16', COALESCE(CAST(NEW.CustomNumeric16 AS TEXT), '') WHERE NEW.CustomNumeric16 IS NOT OLD.CustomNumeric16
+        UNION ALL SELECT 'custom_text16', COALESCE(CAST(NEW.CustomText16 AS TEXT), '') WHERE NEW.CustomText16 IS NOT OLD.CustomText16
+        UNION ALL SELECT 'custom_numeric17', COALESCE(CAST(NEW.CustomNumeric17 AS TEXT), '') WHERE NEW.CustomNumeric17 IS NOT OLD.CustomNumeric17
+        UNION ALL SELECT 'custom_text17', COALESCE(CAST(NEW.CustomText17 AS TEXT), '') WHERE NEW.CustomText17 IS NOT OLD.CustomText17
+        UNION ALL SELECT 'custom_numeric18', COALESCE(CAST(NEW.CustomNumeric18 AS TEXT), '') WHERE NEW.CustomNumeric18 IS NOT OLD.CustomNumeric18
+        UNION ALL SELECT 'custom_text18', COALESCE(CAST(NEW.CustomText18 AS TEXT), '') WHERE NEW.CustomText18 IS NOT OLD.CustomText18
+        UNION ALL SELECT 'custom_numeric19', COALESCE(CAST(NEW.CustomNumeric19 AS TEXT), '') WHERE NEW.CustomNumeric19 IS NOT OLD.CustomNumeric19
+        UNION ALL SELECT 'custom_text19', COALESCE(CAST(NEW.CustomText19 AS TEXT), '') WHERE NEW.CustomText19 IS NOT OLD.CustomText19
+        UNION ALL SELECT 'custom_numeric20', COALESCE(CAST(NEW.CustomNumeric20 AS TEXT), '') WHERE NEW.CustomNumeric20 IS NOT OLD.CustomNumeric20
+        UNION ALL SELECT 'custom_text20', COALESCE(CAST(NEW.CustomText20 AS TEXT), '') WHERE NEW.CustomText20 IS NOT OLD.CustomText20
+        UNION ALL SELECT 'custom_numeric21', COALESCE(CAST(NEW.CustomNumeric21 AS TEXT), '') WHERE NEW.CustomNumeric21 IS NOT OLD.CustomNumeric21
+        UNION ALL SELECT 'custom_text21', COALESCE(CAST(NEW.CustomText21 AS TEXT), '') WHERE NEW.CustomText21 IS NOT OLD.CustomText21
+        UNION ALL SELECT 'custom_numeric22', COALESCE(CAST(NEW.CustomNumeric22 AS TEXT), '') WHERE NEW.CustomNumeric22 IS NOT OLD.CustomNumeric22
+        UNION ALL SELECT 'custom_text22', COALESCE(CAST(NEW.CustomText22 AS TEXT), '') WHERE NEW.CustomText22 IS NOT OLD.CustomText22
+        UNION ALL SELECT 'custom_numeric23', COALESCE(CAST(NEW.CustomNumeric23 AS TEXT), '') WHERE NEW.CustomNumeric23 IS NOT OLD.CustomNumeric23
+        UNION ALL SELECT 'custom_text23', COALESCE(CAST(NEW.CustomText23 AS TEXT), '') WHERE NEW.CustomText23 IS NOT OLD.CustomText23
+        UNION ALL SELECT 'custom_numeric24', COALESCE(CAST(NEW.CustomNumeric24 AS TEXT), '') WHERE NEW.CustomNumeric24 IS NOT OLD.CustomNumeric24
+        UNION ALL SELECT 'custom_text24', COALESCE(CAST(NEW.CustomText24 AS TEXT), '') WHERE NEW.CustomText24 IS NOT OLD.CustomText24
+        UNION ALL SELECT 'custom_numeric25', COALESCE(CAST(NEW.CustomNumeric25 AS TEXT), '') WHERE NEW.CustomNumeric25 IS NOT OLD.CustomNumeric25
+        UNION ALL SELECT 'custom_text25', COALESCE(CAST(NEW.CustomText25 AS TEXT), '') WHERE NEW.CustomText25 IS NOT OLD.CustomText25
+        UNION ALL SELECT 'custom_numeric26', COALESCE(CAST(NEW.CustomNumeric26 AS TEXT), '') WHERE NEW.CustomNumeric26 IS NOT OLD.CustomNumeric26
+        UNION ALL SELECT 'custom_text26', COALESCE(CAST(NEW.CustomText26 AS TEXT), '') WHERE NEW.CustomText26 IS NOT OLD.CustomText26
+        UNION ALL SELECT 'custom_numeric27', COALESCE(CAST(NEW.CustomNumeric27 AS TEXT), '') WHERE NEW.CustomNumeric27 IS NOT OLD.CustomNumeric27
+        UNION ALL SELECT 'custom_text27', COALESCE(CAST(NEW.CustomText27 AS TEXT), '') WHERE NEW.CustomText27 IS NOT OLD.CustomText27
+        UNION ALL SELECT 'custom_numeric28', COALESCE(CAST(NEW.CustomNumeric28 AS TEXT), '') WHERE NEW.CustomNumeric28 IS NOT OLD.CustomNumeric28
+        UNION ALL SELECT 'custom_text28', COALESCE(CAST(NEW.CustomText28 AS TEXT), '') WHERE NEW.CustomText28 IS NOT OLD.CustomText28
+        UNION ALL SELECT 'custom_numeric29', COALESCE(CAST(NEW.CustomNumeric29 AS TEXT), '') WHERE NEW.CustomNumeric29 IS NOT OLD.CustomNumeric29
+        UNION ALL SELECT 'custom_text29', COALESCE(CAST(NEW.CustomText29 AS TEXT), '') WHERE NEW.CustomText29 IS NOT OLD.CustomText29
+        UNION ALL SELECT 'custom_numeric30', COALESCE(CAST(NEW.CustomNumeric30 AS TEXT), '') WHERE NEW.CustomNumeric30 IS NOT OLD.CustomNumeric30
+        UNION ALL SELECT 'custom_text30', COALESCE(CAST(NEW.CustomText30 AS TEXT), '') WHERE NEW.CustomText30 IS NOT OLD.CustomText30
+    )
+    HAVING COUNT(*) > 0;
+END;
+
+CREATE TRIGGER IF NOT EXISTS AccountsChangeCaptureDelete
+AFTER DELETE ON Accounts
+FOR EACH ROW
+WHEN COALESCE((SELECT Suppressed FROM ChangeCaptureState WHERE Id = 1), 0) = 0
+BEGIN
+    INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes)
+    VALUES (OLD.AccountId, 'DELETE', '{}');
+END;
@@ -5,5 +5,9 @@ CREATE TABLE IF NOT EXISTS AccountsPendingChanges (
     Changes TEXT, -- JSON object with field changes, e.g., {"PhoneNumber": "123-456-7890", "Notes": "New notes"}
     Status TEXT NOT NULL DEFAULT 'pending' CHECK(Status IN ('pending', 'processing', 'completed', 'failed')),
     CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
-    ProcessedAt DATETIME
+    ProcessedAt DATETIME,
+    BaseUpdatedAt DATETIME, -- Accounts.UpdatedAt when the change was staged; used for optimistic locking
+    IdempotencyKey TEXT, -- sent with the push so a retried request is not applied twice
+    ContentHash TEXT, -- hash of the change the key was issued for
+    ValidationErrors TEXT -- JSON field errors of a push the API rejected; such changes are shown as validation_failed
 );
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS ActionRuns (
+    RunId INTEGER PRIMARY KEY AUTOINCREMENT,
+    ActionName TEXT, -- event action or cron job name; empty for unnamed runs
+    ActionType TEXT NOT NULL,
+    TriggeredBy TEXT NOT NULL, -- event type, 'cron', 'manual', or 'rerun'
+    Source TEXT,
+    Config TEXT, -- the action config as JSON, for re-runs
+    Event TEXT, -- the triggering event as JSON
+    Args TEXT, -- arguments after templates were rendered, as JSON
+    Status TEXT NOT NULL CHECK(Status IN ('success', 'failed')),
+    ExitCode INTEGER,
+    CommandOutput TEXT,
+    RowsAffected INTEGER,
+    ErrorMessage TEXT,
+    RerunOf INTEGER,
+    StartedAt DATETIME NOT NULL,
+    DurationMs INTEGER
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS ChangeCaptureState (
+    Id INTEGER PRIMARY KEY,
+    Suppressed INTEGER NOT NULL DEFAULT 0
+);
+DELETE FROM ChangeCaptureState;
+INSERT INTO ChangeCaptureState (Id, Suppressed) VALUES (1, 0);
@@ -4,5 +4,7 @@ CREATE TABLE IF NOT EXISTS CommandLog (
     Args TEXT,
     Timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
     Success BOOLEAN NOT NULL,
-    ErrorMessage TEXT
+    ErrorMessage TEXT,
+    Flags TEXT,
+    DurationMs INTEGER
 );
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS DeletedAccounts (
+    DeletedId INTEGER PRIMARY KEY AUTOINCREMENT,
+    AccountId INTEGER NOT NULL,
+    ChangeId INTEGER,
+    FullName TEXT,
+    Data TEXT NOT NULL, -- JSON of the account as stored locally before the delete was pushed
+    DeletedAt DATETIME NOT NULL,
+    RestoredAt DATETIME,
+    RestoredAccountId INTEGER
+);
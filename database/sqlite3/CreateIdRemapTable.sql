@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS IdRemap (
+    RemapId INTEGER PRIMARY KEY AUTOINCREMENT,
+    EntityType TEXT NOT NULL,
+    OldId INTEGER NOT NULL,
+    NewId INTEGER NOT NULL,
+    Reason TEXT, -- crm_id, redirect, or gone
+    RowsRewritten INTEGER NOT NULL DEFAULT 0,
+    RemappedAt DATETIME NOT NULL
+);
\ No newline at end of file
@@ -7,3 +7,4 @@ CREATE INDEX IF NOT EXISTS idx_route_waypoints_route_id ON RouteWaypoints(RouteI
 CREATE INDEX IF NOT EXISTS idx_account_locations_account_id ON AccountLocations(AccountId);
 CREATE UNIQUE INDEX IF NOT EXISTS idx_synchistory_correlation ON SyncHistory(CorrelationId);
 CREATE INDEX IF NOT EXISTS idx_synchistory_started_at ON SyncHistory(StartedAt DESC);
+CREATE INDEX IF NOT EXISTS idx_action_runs_action_name ON ActionRuns(ActionName, RunId);
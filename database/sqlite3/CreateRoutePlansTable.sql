@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS RoutePlans (
+    PlanId INTEGER PRIMARY KEY AUTOINCREMENT,
+    Name TEXT NOT NULL,
+    RouteDate TEXT NOT NULL, -- YYYY-MM-DD
+    StartAddress TEXT,
+    DestinationAddress TEXT,
+    StartTime TEXT, -- HH:MM in the display timezone
+    TemplateRouteId INTEGER, -- the pulled route the plan was copied from
+    Waypoints TEXT, -- the stops in order, as JSON
+    Status TEXT NOT NULL CHECK(Status IN ('draft', 'pushed')),
+    RouteId INTEGER, -- the route created in BadgerMaps when the plan was pushed
+    CreatedAt DATETIME NOT NULL,
+    UpdatedAt DATETIME NOT NULL,
+    PushedAt DATETIME
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS StaleAccounts (
+    AccountId INTEGER PRIMARY KEY,
+    RemoteModifiedDate TEXT, -- last_modified_date in the customers list when marked
+    MarkedAt DATETIME NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS SyncLocks (
+    LockName TEXT PRIMARY KEY,
+    HolderId TEXT NOT NULL, -- random ID of the running app instance
+    Host TEXT,
+    Pid INTEGER,
+    Operation TEXT, -- the run holding the lock, such as "pull accounts"
+    AcquiredAt DATETIME NOT NULL,
+    HeartbeatAt DATETIME NOT NULL
+);
\ No newline at end of file
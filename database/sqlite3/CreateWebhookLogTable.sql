@@ -4,5 +4,8 @@ CREATE TABLE IF NOT EXISTS WebhookLog (
     Method TEXT NOT NULL,
     Uri TEXT NOT NULL,
     Headers TEXT,
-    Body TEXT
+    Body TEXT,
+    ParseStatus TEXT, -- valid, invalid, or unchecked (no schema for the path)
+    ParseError TEXT,
+    EntityId INTEGER -- account or check-in ID the body carries
 );
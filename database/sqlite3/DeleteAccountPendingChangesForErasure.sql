@@ -0,0 +1,2 @@
+DELETE FROM AccountsPendingChanges
+WHERE AccountId = ? AND ChangeId <> ?;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM DeletedAccounts WHERE AccountId = ? OR RestoredAccountId = ?;
\ No newline at end of file
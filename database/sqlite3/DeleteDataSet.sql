@@ -0,0 +1 @@
+DELETE FROM DataSets WHERE Name = ? AND ProfileId = ?;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM Accounts WHERE AccountId = ?;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM RoutePlans WHERE PlanId = ?;
\ No newline at end of file
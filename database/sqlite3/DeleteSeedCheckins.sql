@@ -0,0 +1 @@
+DELETE FROM AccountCheckins WHERE AccountId >= ?;
\ No newline at end of file
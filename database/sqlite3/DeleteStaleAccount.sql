@@ -0,0 +1 @@
+DELETE FROM StaleAccounts WHERE AccountId = ?;
\ No newline at end of file
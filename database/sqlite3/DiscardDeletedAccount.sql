@@ -0,0 +1 @@
+DELETE FROM DeletedAccounts WHERE ChangeId = ? AND RestoredAt IS NULL;
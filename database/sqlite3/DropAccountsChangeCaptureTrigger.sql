@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS AccountsChangeCapture;
+DROP TRIGGER IF EXISTS AccountsChangeCaptureDelete;
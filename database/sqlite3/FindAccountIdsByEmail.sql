@@ -0,0 +1,3 @@
+SELECT AccountId FROM Accounts
+WHERE LOWER(Email) = LOWER(?)
+ORDER BY AccountId;
\ No newline at end of file
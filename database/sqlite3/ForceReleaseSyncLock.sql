@@ -0,0 +1,2 @@
+DELETE FROM SyncLocks
+WHERE LockName = ?;
\ No newline at end of file
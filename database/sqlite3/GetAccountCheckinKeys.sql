@@ -0,0 +1,3 @@
+SELECT LogDatetime, Comments FROM AccountCheckins WHERE AccountId = ?
+UNION ALL
+SELECT LogDatetime, Comments FROM AccountCheckinsPendingChanges WHERE AccountId = ? AND ChangeType = 'CREATE';
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT %s FROM %s WHERE AccountId IN (%s);
@@ -0,0 +1 @@
+SELECT AccountId, LastModifiedDate FROM Accounts;
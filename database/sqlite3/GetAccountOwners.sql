@@ -0,0 +1,3 @@
+SELECT AccountId, AccountOwner
+FROM Accounts
+WHERE AccountOwner IS NOT NULL
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT RunId, ActionName, ActionType, TriggeredBy, Source, Config, Event, Args, Status, ExitCode, CommandOutput, RowsAffected, ErrorMessage, RerunOf, StartedAt, DurationMs
+FROM ActionRuns
+WHERE RunId = ?;
\ No newline at end of file
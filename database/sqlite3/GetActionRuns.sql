@@ -0,0 +1,5 @@
+SELECT RunId, ActionName, ActionType, TriggeredBy, Source, Config, Event, Args, Status, ExitCode, CommandOutput, RowsAffected, ErrorMessage, RerunOf, StartedAt, DurationMs
+FROM ActionRuns
+WHERE (? = '' OR ActionName = ?)
+ORDER BY RunId DESC
+LIMIT {{LIMIT}};
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT count(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'AccountsChangeCapture';
\ No newline at end of file
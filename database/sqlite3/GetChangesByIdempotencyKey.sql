@@ -0,0 +1 @@
+SELECT ChangeId, Status, ContentHash FROM %s WHERE IdempotencyKey = ? ORDER BY ChangeId;
\ No newline at end of file
@@ -0,0 +1,21 @@
+SELECT
+    ChangeId,
+    CheckinId,
+    AccountId,
+    CrmId,
+    LogDatetime,
+    Type,
+    Comments,
+    ExtraFields,
+    EndpointType,
+    CreatedBy,
+    ChangeType,
+    Status,
+    CreatedAt,
+    ProcessedAt,
+    IdempotencyKey,
+    ContentHash
+FROM
+    AccountCheckinsPendingChanges
+ORDER BY
+    CreatedAt;
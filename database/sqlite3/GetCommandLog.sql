@@ -0,0 +1,8 @@
+SELECT LogId, Command, Args, Flags, Timestamp, Success, ErrorMessage, DurationMs
+FROM CommandLog
+WHERE (? = '' OR Command = ? OR Command LIKE ?)
+  AND (? < 0 OR Success = ?)
+  AND Timestamp >= ?
+  AND Timestamp < ?
+ORDER BY LogId DESC
+LIMIT {{LIMIT}};
\ No newline at end of file
@@ -0,0 +1,7 @@
+SELECT
+    (SELECT COUNT(*) FROM Accounts) AS TotalAccounts,
+    (SELECT COUNT(*) FROM Accounts WHERE Email IS NULL OR TRIM(Email) = '') AS MissingEmail,
+    (SELECT COUNT(*) FROM Accounts WHERE PhoneNumber IS NULL OR TRIM(PhoneNumber) = '') AS MissingPhone,
+    (SELECT COUNT(*) FROM Accounts WHERE DaysSinceLastCheckin > ?) AS StaleAccounts,
+    (SELECT COUNT(*) FROM AccountLocations) AS TotalLocations,
+    (SELECT COUNT(*) FROM AccountLocations WHERE IsApproximate = 1) AS ApproximateLocations;
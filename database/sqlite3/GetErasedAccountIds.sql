@@ -0,0 +1 @@
+SELECT DISTINCT AccountId FROM AccountErasures;
\ No newline at end of file
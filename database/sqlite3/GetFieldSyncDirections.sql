@@ -0,0 +1 @@
+SELECT FieldName, JsonField, DataSetLabel, SyncDirection, MergeStrategy FROM FieldMaps WHERE ObjectType = ? ORDER BY FieldName;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT RemapId, EntityType, OldId, NewId, Reason, RowsRewritten, RemappedAt
+FROM IdRemap
+ORDER BY RemappedAt DESC, RemapId DESC;
\ No newline at end of file
@@ -1 +1 @@
-SELECT ChangeId, AccountId, ChangeType, Changes, Status, CreatedAt, ProcessedAt FROM AccountsPendingChanges WHERE Status = 'pending' ORDER BY CreatedAt;
+SELECT ChangeId, AccountId, ChangeType, Changes, Status, CreatedAt, ProcessedAt, IdempotencyKey, ContentHash, ValidationErrors FROM AccountsPendingChanges WHERE Status = 'pending' ORDER BY CreatedAt;
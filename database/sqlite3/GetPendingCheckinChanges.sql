@@ -12,7 +12,9 @@ SELECT
     ChangeType,
     Status,
     CreatedAt,
-    ProcessedAt
+    ProcessedAt,
+    IdempotencyKey,
+    ContentHash
 FROM
     AccountCheckinsPendingChanges
 WHERE
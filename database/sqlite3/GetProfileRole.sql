@@ -0,0 +1,4 @@
+SELECT ProfileId, Email, FirstName, LastName, IsManager
+FROM UserProfiles
+ORDER BY UpdatedAt DESC, ProfileId
+LIMIT 1
\ No newline at end of file
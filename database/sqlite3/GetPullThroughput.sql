@@ -0,0 +1,10 @@
+SELECT
+    COALESCE(SUM(ItemsProcessed), 0),
+    COALESCE(SUM(DurationSeconds), 0)
+FROM
+    SyncHistory
+WHERE
+    Direction = 'pull'
+    AND Status = 'completed'
+    AND DurationSeconds > 0
+    AND ItemsProcessed > 0;
@@ -11,7 +11,8 @@ SELECT HistoryId,
        CompletedAt,
        DurationSeconds,
        Summary,
-       Details
+       Details,
+       Note
 FROM SyncHistory
 ORDER BY StartedAt DESC
 LIMIT {{LIMIT}};
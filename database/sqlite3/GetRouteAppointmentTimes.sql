@@ -0,0 +1 @@
+SELECT WaypointId, ApptTime FROM RouteWaypoints WHERE RouteId = ? AND ApptTime IS NOT NULL
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT LockName, HolderId, Host, Pid, Operation, AcquiredAt, HeartbeatAt
+FROM SyncLocks
+WHERE LockName = ?;
\ No newline at end of file
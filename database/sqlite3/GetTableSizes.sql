@@ -0,0 +1,4 @@
+SELECT name, 0 AS SizeBytes
+FROM sqlite_master
+WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+ORDER BY name
\ No newline at end of file
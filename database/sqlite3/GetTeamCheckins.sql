@@ -0,0 +1,4 @@
+SELECT CreatedBy, COUNT(*) AS Checkins, MAX(LogDatetime) AS LastCheckin
+FROM AccountCheckins
+WHERE LogDatetime >= ?
+GROUP BY CreatedBy
\ No newline at end of file
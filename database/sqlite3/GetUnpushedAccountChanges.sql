@@ -0,0 +1,18 @@
+SELECT
+    ChangeId,
+    AccountId,
+    ChangeType,
+    Changes,
+    Status,
+    CreatedAt,
+    ProcessedAt,
+    IdempotencyKey,
+    ContentHash,
+    ValidationErrors
+FROM
+    AccountsPendingChanges
+WHERE
+    AccountId = ?
+    AND Status IN ('pending', 'processing', 'failed')
+ORDER BY
+    ChangeId;
\ No newline at end of file
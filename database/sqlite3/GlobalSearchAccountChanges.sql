@@ -0,0 +1,19 @@
+WITH q AS (SELECT CAST(? AS TEXT) AS Term, CAST(? AS TEXT) AS Pattern, CAST(? AS TEXT) AS Prefix)
+SELECT p.ChangeId, p.AccountId, a.FullName, p.ChangeType, p.Status, p.Changes,
+  CASE
+    WHEN CAST(p.ChangeId AS TEXT) = q.Term THEN 'Change ID'
+    WHEN CAST(p.AccountId AS TEXT) = q.Term THEN 'Account ID'
+    WHEN LOWER(a.FullName) LIKE q.Pattern THEN 'Account'
+    ELSE 'Changes'
+  END AS MatchedField
+FROM AccountsPendingChanges p
+CROSS JOIN q
+LEFT JOIN Accounts a ON a.AccountId = p.AccountId
+WHERE CAST(p.ChangeId AS TEXT) = q.Term
+   OR CAST(p.AccountId AS TEXT) = q.Term
+   OR LOWER(a.FullName) LIKE q.Pattern
+   OR LOWER(p.Changes) LIKE q.Pattern
+ORDER BY
+  CASE WHEN p.Status = 'pending' THEN 0 ELSE 1 END,
+  p.ChangeId DESC
+LIMIT 25
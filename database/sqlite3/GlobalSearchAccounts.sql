@@ -0,0 +1,29 @@
+WITH q AS (SELECT CAST(? AS TEXT) AS Term, CAST(? AS TEXT) AS Pattern, CAST(? AS TEXT) AS Prefix)
+SELECT a.AccountId, a.FullName,
+  COALESCE(NULLIF(a.OriginalAddress, ''), TRIM(COALESCE(l.AddressLine1, '') || ' ' || COALESCE(l.City, ''))) AS Address,
+  CASE
+    WHEN CAST(a.AccountId AS TEXT) = q.Term THEN 'ID'
+    WHEN LOWER(a.FullName) LIKE q.Pattern THEN 'Name'
+    WHEN LOWER(a.OriginalAddress) LIKE q.Pattern OR LOWER(l.AddressLine1) LIKE q.Pattern
+      OR LOWER(l.City) LIKE q.Pattern OR LOWER(l.Zipcode) LIKE q.Pattern THEN 'Address'
+    ELSE 'Notes'
+  END AS MatchedField
+FROM Accounts a
+CROSS JOIN q
+LEFT JOIN AccountLocations l ON l.AccountId = a.AccountId
+WHERE CAST(a.AccountId AS TEXT) = q.Term
+   OR LOWER(a.FullName) LIKE q.Pattern
+   OR LOWER(a.OriginalAddress) LIKE q.Pattern
+   OR LOWER(l.AddressLine1) LIKE q.Pattern
+   OR LOWER(l.City) LIKE q.Pattern
+   OR LOWER(l.Zipcode) LIKE q.Pattern
+   OR LOWER(a.Notes) LIKE q.Pattern
+ORDER BY
+  CASE
+    WHEN CAST(a.AccountId AS TEXT) = q.Term THEN 0
+    WHEN LOWER(a.FullName) LIKE q.Prefix THEN 1
+    WHEN LOWER(a.FullName) LIKE q.Pattern THEN 2
+    ELSE 3
+  END,
+  a.FullName ASC
+LIMIT 25
@@ -0,0 +1,4 @@
+UPDATE SyncLocks
+SET HeartbeatAt = ?
+WHERE LockName = ?
+  AND HolderId = ?;
\ No newline at end of file
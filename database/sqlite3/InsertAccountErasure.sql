@@ -0,0 +1,2 @@
+INSERT INTO AccountErasures (AccountId, Mode, ReportId, ReportDigest, DeleteChangeId, ErasedAt)
+VALUES (?, ?, ?, ?, ?, ?);
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO AccountsPendingChanges (AccountId, ChangeType, Changes, IdempotencyKey, ContentHash, BaseUpdatedAt)
+VALUES (?, ?, ?, ?, ?, (SELECT UpdatedAt FROM Accounts WHERE AccountId = ?));
@@ -0,0 +1 @@
+INSERT INTO CommandLog (Command, Args, Flags, Success, ErrorMessage, DurationMs) VALUES (?, ?, ?, ?, ?, ?);
\ No newline at end of file
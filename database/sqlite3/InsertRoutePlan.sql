@@ -0,0 +1,2 @@
+INSERT INTO RoutePlans (Name, RouteDate, StartAddress, DestinationAddress, StartTime, TemplateRouteId, Waypoints, Status, CreatedAt, UpdatedAt)
+VALUES (?, ?, ?, ?, ?, ?, ?, 'draft', ?, ?);
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO SyncLocks (LockName, HolderId, Host, Pid, Operation, AcquiredAt, HeartbeatAt)
+VALUES (?, ?, ?, ?, ?, ?, ?);
\ No newline at end of file
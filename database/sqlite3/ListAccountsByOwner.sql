@@ -0,0 +1 @@
+SELECT * FROM Accounts WHERE AccountOwner = ? ORDER BY FullName, AccountId;
\ No newline at end of file
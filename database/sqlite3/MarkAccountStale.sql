@@ -0,0 +1 @@
+INSERT OR REPLACE INTO StaleAccounts (AccountId, RemoteModifiedDate, MarkedAt) VALUES (?, ?, ?);
\ No newline at end of file
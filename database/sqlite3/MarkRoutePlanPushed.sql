@@ -0,0 +1 @@
+UPDATE RoutePlans SET Status = 'pushed', RouteId = ?, PushedAt = ?, UpdatedAt = ? WHERE PlanId = ?;
\ No newline at end of file
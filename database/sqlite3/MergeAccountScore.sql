@@ -0,0 +1 @@
+INSERT OR REPLACE INTO AccountScores (AccountId, ScoreName, Score, ComputedAt) VALUES (?, ?, ?, ?);
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE AccountsPendingChanges SET Changes = ?, BaseUpdatedAt = (SELECT UpdatedAt FROM Accounts WHERE Accounts.AccountId = AccountsPendingChanges.AccountId), Status = 'pending', ValidationErrors = NULL WHERE ChangeId = ? AND Status IN ('pending', 'failed');
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Deletes are left alone: the old account is already gone, and the
+-- surviving account must not be deleted in its place.
+UPDATE AccountsPendingChanges SET AccountId = ?
+WHERE AccountId = ? AND Status = 'pending' AND ChangeType <> 'DELETE';
\ No newline at end of file
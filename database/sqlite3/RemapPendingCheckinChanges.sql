@@ -0,0 +1,2 @@
+UPDATE AccountCheckinsPendingChanges SET AccountId = ?
+WHERE AccountId = ? AND Status = 'pending';
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE ChangeCaptureState SET Suppressed = CASE WHEN Suppressed > 0 THEN Suppressed - 1 ELSE 0 END WHERE Id = 1;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE AccountsPendingChanges SET Status = 'failed', ValidationErrors = ?, ProcessedAt = CURRENT_TIMESTAMP WHERE ChangeId = ?;
\ No newline at end of file
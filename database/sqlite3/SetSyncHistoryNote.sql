@@ -0,0 +1 @@
+UPDATE SyncHistory SET Note = ? WHERE HistoryId = ?;
\ No newline at end of file
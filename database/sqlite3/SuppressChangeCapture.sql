@@ -0,0 +1 @@
+UPDATE ChangeCaptureState SET Suppressed = Suppressed + 1 WHERE Id = 1;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE %s SET IdempotencyKey = ?, ContentHash = ? WHERE ChangeId = ?;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE AccountCheckinsPendingChanges
+SET CrmId = ?, LogDatetime = ?, Type = ?, Comments = ?
+WHERE ChangeId = ? AND Status = 'pending';
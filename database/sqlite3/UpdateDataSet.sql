@@ -0,0 +1,4 @@
+UPDATE DataSets
+SET Filterable = ?, Label = ?, Position = ?, Type = ?, HasData = ?, IsUserCanAddNewTextValues = ?,
+    RawMin = ?, Min = ?, Max = ?, RawMax = ?, AccountField = ?, UpdatedAt = CURRENT_TIMESTAMP
+WHERE Name = ? AND ProfileId = ?;
@@ -0,0 +1 @@
+UPDATE FieldMaps SET SyncDirection = ? WHERE FieldName = ? AND ObjectType = ?;
\ No newline at end of file
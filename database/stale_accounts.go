@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// MarkAccountStaleCommand returns the command that marks an account as
+// needing its details fetched, with the last modified date the customers
+// list reported for it.
+func MarkAccountStaleCommand(accountID int, remoteModified string, at time.Time) Command {
+	return Command{Name: "MarkAccountStale", Args: []any{accountID, remoteModified, at.UTC()}}
+}
+
+// DeleteStaleAccountCommand returns the command that clears the stale mark
+// of an account, run whenever its details are stored.
+func DeleteStaleAccountCommand(accountID int) Command {
+	return Command{Name: "DeleteStaleAccount", Args: []any{accountID}}
+}
+
+// IsAccountStale reports whether a low-bandwidth pull left the details of
+// an account to be fetched.
+func IsAccountStale(db DB, accountID int) (bool, error) {
+	sqlText := db.GetSQL("IsAccountStale")
+	if sqlText == "" {
+		return false, fmt.Errorf("unknown or unavailable SQL command: IsAccountStale")
+	}
+	var count int
+	if err := db.GetDB().QueryRow(sqlText, accountID).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetStaleAccountIDs returns the accounts whose details are still to be
+// fetched.
+func GetStaleAccountIDs(db DB) ([]int, error) {
+	sqlText := db.GetSQL("GetStaleAccountIds")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetStaleAccountIds")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
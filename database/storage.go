@@ -0,0 +1,171 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"badgermaps/utils"
+)
+
+// TableStorage is the row count and approximate size of one table.
+type TableStorage struct {
+	Name  string
+	Rows  int64
+	Bytes int64
+}
+
+// StorageReport is the storage footprint of the database.
+type StorageReport struct {
+	Type string
+	// Tables are ordered by size, largest first.
+	Tables []TableStorage
+	// TotalBytes is the sum of the table sizes. For SQLite it is the size of
+	// the database file and its write-ahead log.
+	TotalBytes int64
+	TotalRows  int64
+	// Path is the SQLite file, empty for server databases.
+	Path string
+	// Warnings note SQLite files that approach a size limit.
+	Warnings []string
+}
+
+// fat32MaxFileBytes is the largest file FAT32 volumes, still common on USB
+// drives, can hold.
+const fat32MaxFileBytes = 4<<30 - 1
+
+// storageWarnRatio is how full a limit may get before the report warns.
+const storageWarnRatio = 0.8
+
+// GetStorageReport counts the rows of every table and estimates its size.
+// PostgreSQL and SQL Server report the size of each table with its indexes.
+// SQLite only knows the size of the whole file, so it is shared among the
+// tables by how many bytes of data each one stores.
+func GetStorageReport(db DB) (StorageReport, error) {
+	report := StorageReport{Type: db.GetType()}
+	if db.GetDB() == nil {
+		return report, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetTableSizes")
+	if sqlText == "" {
+		return report, fmt.Errorf("unknown or unavailable SQL command: GetTableSizes")
+	}
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return report, fmt.Errorf("failed to list table sizes: %w", err)
+	}
+	for rows.Next() {
+		var table TableStorage
+		if err := rows.Scan(&table.Name, &table.Bytes); err != nil {
+			rows.Close()
+			return report, err
+		}
+		report.Tables = append(report.Tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	for i := range report.Tables {
+		table := &report.Tables[i]
+		if err := db.GetDB().QueryRow("SELECT COUNT(*) FROM " + QualifiedName(db, quoteStorageIdent(db, table.Name))).Scan(&table.Rows); err != nil {
+			return report, fmt.Errorf("failed to count rows in %s: %w", table.Name, err)
+		}
+		report.TotalRows += table.Rows
+		report.TotalBytes += table.Bytes
+	}
+
+	if sqlite, ok := db.(*SQLiteConfig); ok {
+		if err := sqliteStorage(sqlite, &report); err != nil {
+			return report, err
+		}
+	}
+
+	sort.SliceStable(report.Tables, func(i, j int) bool {
+		if report.Tables[i].Bytes != report.Tables[j].Bytes {
+			return report.Tables[i].Bytes > report.Tables[j].Bytes
+		}
+		return report.Tables[i].Name < report.Tables[j].Name
+	})
+	return report, nil
+}
+
+// sqliteStorage sizes the SQLite file, shares it among the tables, and adds
+// warnings for files near a limit.
+func sqliteStorage(db *SQLiteConfig, report *StorageReport) error {
+	report.Path = db.Path
+	for _, suffix := range []string{"", "-wal"} {
+		if info, err := os.Stat(db.Path + suffix); err == nil {
+			report.TotalBytes += info.Size()
+		}
+	}
+
+	content := make([]int64, len(report.Tables))
+	var totalContent int64
+	for i, table := range report.Tables {
+		columns, err := db.GetTableColumns(table.Name)
+		if err != nil || len(columns) == 0 {
+			continue
+		}
+		lengths := make([]string, len(columns))
+		for j, column := range columns {
+			lengths[j] = fmt.Sprintf(`COALESCE(LENGTH(CAST("%s" AS BLOB)), 0)`, column)
+		}
+		query := fmt.Sprintf(`SELECT COALESCE(SUM(%s), 0) FROM "%s"`, strings.Join(lengths, " + "), table.Name)
+		if err := db.GetDB().QueryRow(query).Scan(&content[i]); err != nil {
+			return fmt.Errorf("failed to measure %s: %w", table.Name, err)
+		}
+		totalContent += content[i]
+	}
+	if totalContent > 0 {
+		for i := range report.Tables {
+			report.Tables[i].Bytes = report.TotalBytes * content[i] / totalContent
+		}
+	}
+
+	var pageCount, maxPageCount int64
+	if err := db.GetDB().QueryRow("PRAGMA page_count").Scan(&pageCount); err == nil {
+		if err := db.GetDB().QueryRow("PRAGMA max_page_count").Scan(&maxPageCount); err == nil && maxPageCount > 0 {
+			if float64(pageCount) >= storageWarnRatio*float64(maxPageCount) {
+				report.Warnings = append(report.Warnings, fmt.Sprintf(
+					"The database uses %d of the %d pages SQLite allows (max_page_count); writes fail once it is full.",
+					pageCount, maxPageCount))
+			}
+		}
+	}
+	if float64(report.TotalBytes) >= storageWarnRatio*fat32MaxFileBytes {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"The database is %s; files on FAT32 drives cannot grow past 4 GiB. Move it to an NTFS, APFS, or ext4 volume, or archive old check-ins.",
+			FormatBytes(report.TotalBytes)))
+	}
+	if available, _, err := utils.DiskUsage(filepath.Dir(db.Path)); err == nil && int64(available) < report.TotalBytes {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"Only %s is free next to the %s database; backups and VACUUM need about as much free space as the file.",
+			FormatBytes(int64(available)), FormatBytes(report.TotalBytes)))
+	}
+	return nil
+}
+
+func quoteStorageIdent(db DB, name string) string {
+	if db.GetType() == "mssql" {
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// FormatBytes formats a byte count with binary units, such as "1.5 MiB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"badgermaps/app/state"
+)
+
+func TestGetStorageReport(t *testing.T) {
+	db, err := NewDB(&DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "storage.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.EnforceSchema(&state.State{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 50; i++ {
+		if _, err := db.GetDB().Exec(`INSERT INTO Accounts (AccountId, FullName, Notes) VALUES (?, 'Account', 'a fairly long note that takes up some room')`, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := GetStorageReport(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Type != "sqlite3" || report.TotalBytes <= 0 || len(report.Tables) == 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	var sum int64
+	for _, table := range report.Tables {
+		sum += table.Bytes
+	}
+	if sum > report.TotalBytes {
+		t.Errorf("table sizes add up to %d, more than the file's %d bytes", sum, report.TotalBytes)
+	}
+	for i, table := range report.Tables {
+		if i > 0 && table.Bytes > report.Tables[i-1].Bytes {
+			t.Errorf("%s is larger than %s but sorts after it", table.Name, report.Tables[i-1].Name)
+		}
+		if table.Name == "Accounts" && (table.Rows != 50 || table.Bytes == 0) {
+			t.Errorf("Accounts = %+v, want 50 rows and a size", table)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"} {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -24,6 +24,9 @@ type SyncHistoryEntry struct {
 	DurationSeconds int
 	Summary         string
 	Details         string
+	// Note is an operator's annotation of the run, such as why it was run
+	// again.
+	Note string
 }
 
 // InsertSyncHistory creates a new sync history record and returns the new history ID.
@@ -144,6 +147,32 @@ func CompleteSyncHistory(db DB, correlationID, status string, itemsProcessed, er
 	}
 }
 
+// SetSyncHistoryNote sets the note on the run with historyID; an empty note
+// clears it. It returns sql.ErrNoRows when there is no such run.
+func SetSyncHistoryNote(db DB, historyID int64, note string) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	sqlText := db.GetSQL("SetSyncHistoryNote")
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: SetSyncHistoryNote")
+	}
+
+	var value any
+	if note != "" {
+		value = note
+	}
+	result, err := db.GetDB().Exec(sqlText, value, historyID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 // GetRecentSyncHistory returns the most recent sync history entries up to the provided limit.
 func GetRecentSyncHistory(db DB, limit int) ([]SyncHistoryEntry, error) {
 	if db == nil || db.GetDB() == nil {
@@ -167,6 +196,77 @@ func GetRecentSyncHistory(db DB, limit int) ([]SyncHistoryEntry, error) {
 	}
 	defer rows.Close()
 
+	return scanSyncHistoryRows(rows)
+}
+
+// GetSyncHistorySince returns the runs started at or after since, oldest
+// first, leaving out runs of excludeRunType.
+func GetSyncHistorySince(db DB, since time.Time, excludeRunType string) ([]SyncHistoryEntry, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	sqlText := db.GetSQL("GetSyncHistorySince")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetSyncHistorySince")
+	}
+
+	rows, err := db.GetDB().Query(sqlText, since.UTC().Format("2006-01-02 15:04:05"), excludeRunType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSyncHistoryRows(rows)
+}
+
+// GetLastSyncHistoryRun returns the latest run with the given run type,
+// source, and status, or nil when there is none.
+func GetLastSyncHistoryRun(db DB, runType, source, status string) (*SyncHistoryEntry, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	sqlText := db.GetSQL("GetLastSyncHistoryRun")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetLastSyncHistoryRun")
+	}
+
+	rows, err := db.GetDB().Query(sqlText, runType, source, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries, err := scanSyncHistoryRows(rows)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// GetLatestSyncRuns returns the most recent finished pull and push for
+// each source, ordered by direction and source.
+func GetLatestSyncRuns(db DB) ([]SyncHistoryEntry, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	sqlText := db.GetSQL("GetLatestSyncRuns")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetLatestSyncRuns")
+	}
+
+	rows, err := db.GetDB().Query(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSyncHistoryRows(rows)
+}
+
+func scanSyncHistoryRows(rows *sql.Rows) ([]SyncHistoryEntry, error) {
 	var entries []SyncHistoryEntry
 	for rows.Next() {
 		var (
@@ -174,6 +274,7 @@ func GetRecentSyncHistory(db DB, limit int) ([]SyncHistoryEntry, error) {
 			started   any
 			completed any
 			duration  sql.NullInt64
+			note      sql.NullString
 		)
 
 		if err := rows.Scan(
@@ -191,6 +292,7 @@ func GetRecentSyncHistory(db DB, limit int) ([]SyncHistoryEntry, error) {
 			&duration,
 			&entry.Summary,
 			&entry.Details,
+			&note,
 		); err != nil {
 			return nil, err
 		}
@@ -202,6 +304,7 @@ func GetRecentSyncHistory(db DB, limit int) ([]SyncHistoryEntry, error) {
 		if duration.Valid {
 			entry.DurationSeconds = int(duration.Int64)
 		}
+		entry.Note = note.String
 
 		entries = append(entries, entry)
 	}
@@ -268,3 +371,20 @@ func parseTimeString(value string) (time.Time, error) {
 	}
 	return time.Time{}, fmt.Errorf("unsupported time format: %s", value)
 }
+
+// GetPullThroughput sums items and seconds across completed pull runs so
+// callers can estimate how long a future pull will take.
+func GetPullThroughput(db DB) (items int, seconds int64, err error) {
+	if db == nil || db.GetDB() == nil {
+		return 0, 0, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetPullThroughput")
+	if sqlText == "" {
+		return 0, 0, fmt.Errorf("unknown or unavailable SQL command: GetPullThroughput")
+	}
+	var totalItems, totalSeconds sql.NullInt64
+	if err := db.GetDB().QueryRow(sqlText).Scan(&totalItems, &totalSeconds); err != nil {
+		return 0, 0, err
+	}
+	return int(totalItems.Int64), totalSeconds.Int64, nil
+}
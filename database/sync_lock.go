@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SyncLock is a row of SyncLocks: a named lock held by one app instance,
+// kept alive by updating HeartbeatAt while its run lasts.
+type SyncLock struct {
+	Name        string
+	HolderId    string
+	Host        string
+	Pid         int
+	Operation   string
+	AcquiredAt  time.Time
+	HeartbeatAt time.Time
+}
+
+// GetSyncLock returns the lock named name, or nil when it is free.
+func GetSyncLock(db DB, name string) (*SyncLock, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL("GetSyncLock")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetSyncLock")
+	}
+	var lock SyncLock
+	var host, operation sql.NullString
+	var pid sql.NullInt64
+	var acquiredAt, heartbeatAt any
+	err := db.GetDB().QueryRow(sqlText, name).Scan(&lock.Name, &lock.HolderId, &host, &pid, &operation, &acquiredAt, &heartbeatAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lock.Host = host.String
+	lock.Pid = int(pid.Int64)
+	lock.Operation = operation.String
+	lock.AcquiredAt = normaliseToTime(acquiredAt)
+	lock.HeartbeatAt = normaliseToTime(heartbeatAt)
+	return &lock, nil
+}
+
+// InsertSyncLock takes a free lock. It fails when the lock is held.
+func InsertSyncLock(db DB, lock SyncLock) error {
+	_, err := execSyncLock(db, "InsertSyncLock", lock.Name, lock.HolderId, lock.Host, lock.Pid, lock.Operation, lock.AcquiredAt.UTC(), lock.HeartbeatAt.UTC())
+	return err
+}
+
+// HeartbeatSyncLock records that holder still holds the lock. It returns
+// false when holder no longer does, such as after a forced release.
+func HeartbeatSyncLock(db DB, name, holder string, at time.Time) (bool, error) {
+	rows, err := execSyncLock(db, "HeartbeatSyncLock", at.UTC(), name, holder)
+	return rows > 0, err
+}
+
+// ReleaseSyncLock frees the lock if holder holds it.
+func ReleaseSyncLock(db DB, name, holder string) error {
+	_, err := execSyncLock(db, "ReleaseSyncLock", name, holder)
+	return err
+}
+
+// ForceReleaseSyncLock frees the lock whoever holds it. It returns false
+// when the lock was free.
+func ForceReleaseSyncLock(db DB, name string) (bool, error) {
+	rows, err := execSyncLock(db, "ForceReleaseSyncLock", name)
+	return rows > 0, err
+}
+
+// execSyncLock runs a SyncLocks command and returns the rows it changed.
+func execSyncLock(db DB, command string, args ...any) (int64, error) {
+	if db == nil || db.GetDB() == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+	sqlText := db.GetSQL(command)
+	if sqlText == "" {
+		return 0, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	result, err := db.GetDB().Exec(sqlText, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// ProfileRole is the part of the pulled user profile that decides which
+// features the GUI shows.
+type ProfileRole struct {
+	ProfileId int
+	Email     string
+	FirstName string
+	LastName  string
+	IsManager bool
+}
+
+// GetProfileRole returns the most recently pulled user profile. It returns
+// sql.ErrNoRows when no profile has been pulled yet.
+func GetProfileRole(db DB) (*ProfileRole, error) {
+	sqlText := db.GetSQL("GetProfileRole")
+	if sqlText == "" {
+		return nil, fmt.Errorf("unknown or unavailable SQL command: GetProfileRole")
+	}
+	var role ProfileRole
+	var email, firstName, lastName sql.NullString
+	var isManager sql.NullBool
+	if err := db.GetDB().QueryRow(sqlText).Scan(&role.ProfileId, &email, &firstName, &lastName, &isManager); err != nil {
+		return nil, err
+	}
+	role.Email = email.String
+	role.FirstName = firstName.String
+	role.LastName = lastName.String
+	role.IsManager = isManager.Bool
+	return &role, nil
+}
+
+// RepActivity is the activity of one rep, keyed by the account owner and
+// check-in author values BadgerMaps syncs. Rep is empty for accounts
+// without an owner.
+type RepActivity struct {
+	Rep            string
+	Accounts       int
+	StaleAccounts  int
+	RecentCheckins int
+	// LastCheckin is the rep's latest check-in time since the cutoff, as
+	// stored.
+	LastCheckin string
+}
+
+// GetTeamActivity returns the account and check-in counts of every rep,
+// ordered by rep. Accounts without a check-in in over staleDays days count
+// as stale, and check-ins logged on or after since (YYYY-MM-DD) as recent.
+func GetTeamActivity(db DB, staleDays int, since string) ([]RepActivity, error) {
+	if db == nil || db.GetDB() == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	byRep := make(map[string]*RepActivity)
+	rep := func(name string) *RepActivity {
+		if byRep[name] == nil {
+			byRep[name] = &RepActivity{Rep: name}
+		}
+		return byRep[name]
+	}
+
+	err := queryTeam(db, "GetTeamAccounts", []any{staleDays}, func(rows *sql.Rows) error {
+		var owner sql.NullString
+		var accounts int
+		var stale sql.NullInt64
+		if err := rows.Scan(&owner, &accounts, &stale); err != nil {
+			return err
+		}
+		r := rep(owner.String)
+		r.Accounts += accounts
+		r.StaleAccounts += int(stale.Int64)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = queryTeam(db, "GetTeamCheckins", []any{since}, func(rows *sql.Rows) error {
+		var author, last sql.NullString
+		var checkins int
+		if err := rows.Scan(&author, &checkins, &last); err != nil {
+			return err
+		}
+		r := rep(author.String)
+		r.RecentCheckins += checkins
+		if last.String > r.LastCheckin {
+			r.LastCheckin = last.String
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	team := make([]RepActivity, 0, len(byRep))
+	for _, r := range byRep {
+		team = append(team, *r)
+	}
+	sort.Slice(team, func(i, j int) bool { return team[i].Rep < team[j].Rep })
+	return team, nil
+}
+
+// GetAccountOwners maps account IDs to their owner, for accounts that have
+// one.
+func GetAccountOwners(db DB) (map[int]string, error) {
+	owners := make(map[int]string)
+	err := queryTeam(db, "GetAccountOwners", nil, func(rows *sql.Rows) error {
+		var id int
+		var owner sql.NullString
+		if err := rows.Scan(&id, &owner); err != nil {
+			return err
+		}
+		owners[id] = owner.String
+		return nil
+	})
+	return owners, err
+}
+
+func queryTeam(db DB, command string, args []any, scan func(*sql.Rows) error) error {
+	sqlText := db.GetSQL(command)
+	if sqlText == "" {
+		return fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	rows, err := db.GetDB().Query(sqlText, args...)
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", command, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
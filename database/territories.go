@@ -0,0 +1,38 @@
+package database
+
+import "database/sql"
+
+// TerritoryAccount is an account's owner and location, the inputs to
+// territory rules.
+type TerritoryAccount struct {
+	AccountId int
+	Name      string
+	Owner     string
+	Zipcode   string
+	State     string
+	// Point is nil when the account has no geocoded location.
+	Point *GeoPoint
+}
+
+// GetTerritoryAccounts returns every account with its owner and location.
+func GetTerritoryAccounts(db DB) ([]TerritoryAccount, error) {
+	var accounts []TerritoryAccount
+	err := queryTeam(db, "GetTerritoryAccounts", nil, func(rows *sql.Rows) error {
+		var acc TerritoryAccount
+		var name, owner, zip, state sql.NullString
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&acc.AccountId, &name, &owner, &zip, &state, &lat, &lon); err != nil {
+			return err
+		}
+		acc.Name = name.String
+		acc.Owner = owner.String
+		acc.Zipcode = zip.String
+		acc.State = state.String
+		if lat.Valid && lon.Valid {
+			acc.Point = &GeoPoint{Lat: lat.Float64, Lon: lon.Float64}
+		}
+		accounts = append(accounts, acc)
+		return nil
+	})
+	return accounts, err
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// MaxUnitOfWorkAttempts is how many times Work runs a unit the database
+// rolled back to break a deadlock.
+const MaxUnitOfWorkAttempts = 3
+
+// unitOfWorkBackoff is the wait before the first retry; each later retry
+// waits one step longer.
+var unitOfWorkBackoff = 50 * time.Millisecond
+
+// UnitOfWork is one transaction shared by the writes of a store path, such
+// as an account with its location or a profile with its data sets. It is
+// only valid inside the function passed to Work.
+type UnitOfWork struct {
+	db         DB
+	tx         *sql.Tx
+	statements int
+	savepoints int
+}
+
+// Work runs fn in one transaction and commits it when fn returns nil.
+// When PostgreSQL or SQL Server picks the transaction as a deadlock victim,
+// or PostgreSQL cannot serialize it, the whole unit is rolled back and run
+// again, up to MaxUnitOfWorkAttempts times, so fn must only touch the
+// database through u. name groups the runs in UnitOfWorkMetrics.
+func Work(db DB, name string, fn func(u *UnitOfWork) error) error {
+	if db == nil || db.GetDB() == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	start := time.Now()
+	run := unitOfWorkRun{}
+	var err error
+	for attempt := 1; ; attempt++ {
+		u := &UnitOfWork{db: db}
+		err = u.run(fn)
+		run.statements += u.statements
+		if err == nil || !IsDeadlock(err) || attempt == MaxUnitOfWorkAttempts {
+			break
+		}
+		run.retries++
+		time.Sleep(unitOfWorkBackoff * time.Duration(attempt))
+	}
+	run.duration = time.Since(start)
+	run.failed = err != nil
+	recordUnitOfWork(name, run)
+	return err
+}
+
+func (u *UnitOfWork) run(fn func(u *UnitOfWork) error) error {
+	tx, err := u.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	u.tx = tx
+	defer tx.Rollback()
+	if err := fn(u); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DB returns the database the unit writes to, for its dialect and SQL.
+func (u *UnitOfWork) DB() DB {
+	return u.db
+}
+
+// Tx returns the transaction, for statements such as bulk copies that need
+// it directly.
+func (u *UnitOfWork) Tx() *sql.Tx {
+	return u.tx
+}
+
+// Exec runs a named SQL command and returns how many rows it changed.
+func (u *UnitOfWork) Exec(command string, args ...any) (int64, error) {
+	sqlText := u.db.GetSQL(command)
+	if sqlText == "" {
+		return 0, fmt.Errorf("unknown or unavailable SQL command: %s", command)
+	}
+	result, err := u.ExecSQL(sqlText, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", command, err)
+	}
+	return result.RowsAffected()
+}
+
+// ExecSQL runs a statement in the transaction.
+func (u *UnitOfWork) ExecSQL(query string, args ...any) (sql.Result, error) {
+	u.statements++
+	return u.tx.Exec(query, args...)
+}
+
+// Run runs commands in order. Every command's SQL is looked up first, so an
+// unknown command fails before anything is written.
+func (u *UnitOfWork) Run(commands []Command) error {
+	statements := make([]string, len(commands))
+	for i, command := range commands {
+		statements[i] = u.db.GetSQL(command.Name)
+		if statements[i] == "" {
+			return fmt.Errorf("unknown or unavailable SQL command: %s", command.Name)
+		}
+	}
+	for i, command := range commands {
+		if _, err := u.ExecSQL(statements[i], command.Args...); err != nil {
+			return fmt.Errorf("%s: %w", command.Name, err)
+		}
+	}
+	return nil
+}
+
+// Savepoint runs fn so that when it fails only its writes are undone and
+// the unit can go on, as when one row of a batch is rejected. A deadlock
+// is returned as is, since the database has already rolled back the whole
+// transaction.
+func (u *UnitOfWork) Savepoint(fn func() error) error {
+	u.savepoints++
+	name := fmt.Sprintf("uow_%d", u.savepoints)
+	begin, rollback, release := "SAVEPOINT "+name, "ROLLBACK TO SAVEPOINT "+name, "RELEASE SAVEPOINT "+name
+	if u.db.GetType() == "mssql" {
+		begin, rollback, release = "SAVE TRANSACTION "+name, "ROLLBACK TRANSACTION "+name, ""
+	}
+	if _, err := u.ExecSQL(begin); err != nil {
+		return fmt.Errorf("failed to set savepoint: %w", err)
+	}
+	if err := fn(); err != nil {
+		if IsDeadlock(err) {
+			return err
+		}
+		if _, rbErr := u.ExecSQL(rollback); rbErr != nil {
+			return fmt.Errorf("%w (and rolling back to the savepoint failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	if release != "" {
+		if _, err := u.ExecSQL(release); err != nil {
+			return fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsDeadlock reports whether err is a PostgreSQL or SQL Server error that
+// rolled the transaction back to break a deadlock or a serialization
+// conflict, so running it again may succeed.
+func IsDeadlock(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40P01" || pqErr.Code == "40001"
+	}
+	var msErr mssql.Error
+	if errors.As(err, &msErr) {
+		return msErr.Number == 1205
+	}
+	var msErrPtr *mssql.Error
+	if errors.As(err, &msErrPtr) && msErrPtr != nil {
+		return msErrPtr.Number == 1205
+	}
+	return false
+}
+
+// UnitOfWorkStats sums the runs of the units of work sharing a name.
+// Statements counts every statement of every attempt, and Retries the
+// attempts repeated after a deadlock.
+type UnitOfWorkStats struct {
+	Name       string
+	Runs       int64
+	Failures   int64
+	Retries    int64
+	Statements int64
+	Total      time.Duration
+	Max        time.Duration
+}
+
+type unitOfWorkRun struct {
+	statements int
+	retries    int
+	failed     bool
+	duration   time.Duration
+}
+
+var (
+	unitOfWorkMu    sync.Mutex
+	unitOfWorkStats = make(map[string]*UnitOfWorkStats)
+)
+
+func recordUnitOfWork(name string, run unitOfWorkRun) {
+	unitOfWorkMu.Lock()
+	defer unitOfWorkMu.Unlock()
+	stats := unitOfWorkStats[name]
+	if stats == nil {
+		stats = &UnitOfWorkStats{Name: name}
+		unitOfWorkStats[name] = stats
+	}
+	stats.Runs++
+	if run.failed {
+		stats.Failures++
+	}
+	stats.Retries += int64(run.retries)
+	stats.Statements += int64(run.statements)
+	stats.Total += run.duration
+	if run.duration > stats.Max {
+		stats.Max = run.duration
+	}
+}
+
+// UnitOfWorkMetrics returns the statistics of every unit of work run in
+// this process, by name.
+func UnitOfWorkMetrics() []UnitOfWorkStats {
+	unitOfWorkMu.Lock()
+	defer unitOfWorkMu.Unlock()
+	metrics := make([]UnitOfWorkStats, 0, len(unitOfWorkStats))
+	for _, stats := range unitOfWorkStats {
+		metrics = append(metrics, *stats)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
+
+// WorkCommands runs commands in order as one unit of work named name.
+func WorkCommands(db DB, name string, commands []Command) error {
+	return Work(db, name, func(u *UnitOfWork) error {
+		return u.Run(commands)
+	})
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+func statsFor(name string) UnitOfWorkStats {
+	for _, stats := range UnitOfWorkMetrics() {
+		if stats.Name == name {
+			return stats
+		}
+	}
+	return UnitOfWorkStats{}
+}
+
+func TestWorkCommitsOrRollsBack(t *testing.T) {
+	db := newBackupTestDB(t, "uow.db")
+	insert := func(u *UnitOfWork, id int) error {
+		_, err := u.ExecSQL("INSERT INTO Accounts (AccountId, FullName) VALUES (?, 'x')", id)
+		return err
+	}
+
+	if err := Work(db, "test.commit", func(u *UnitOfWork) error { return insert(u, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	failure := errors.New("boom")
+	err := Work(db, "test.commit", func(u *UnitOfWork) error {
+		if err := insert(u, 2); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Work = %v, want the function's error", err)
+	}
+	if n := countAccounts(t, db); n != 1 {
+		t.Fatalf("%d accounts after a failed unit, want 1", n)
+	}
+
+	err = Work(db, "test.savepoint", func(u *UnitOfWork) error {
+		if err := insert(u, 3); err != nil {
+			return err
+		}
+		if err := u.Savepoint(func() error { return insert(u, 1) }); err == nil {
+			return fmt.Errorf("want a duplicate key error")
+		}
+		return u.Savepoint(func() error { return insert(u, 4) })
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countAccounts(t, db); n != 3 {
+		t.Fatalf("%d accounts after the savepoint unit, want 3", n)
+	}
+
+	stats := statsFor("test.commit")
+	if stats.Runs != 2 || stats.Failures != 1 || stats.Statements != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestWorkRetriesDeadlocks(t *testing.T) {
+	db := newBackupTestDB(t, "uow_retry.db")
+	defer func(backoff time.Duration) { unitOfWorkBackoff = backoff }(unitOfWorkBackoff)
+	unitOfWorkBackoff = 0
+	attempts := 0
+	err := Work(db, "test.retry", func(u *UnitOfWork) error {
+		attempts++
+		if _, err := u.ExecSQL("INSERT INTO Accounts (AccountId, FullName) VALUES (?, 'x')", attempts); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			return fmt.Errorf("MergeAccounts: %w", &pq.Error{Code: "40P01"})
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("Work = %v after %d attempts, want success on the second", err, attempts)
+	}
+	if n := countAccounts(t, db); n != 1 {
+		t.Fatalf("%d accounts, want only the retried attempt's", n)
+	}
+
+	attempts = 0
+	err = Work(db, "test.retry", func(u *UnitOfWork) error {
+		attempts++
+		return mssql.Error{Number: 1205}
+	})
+	if !IsDeadlock(err) || attempts != MaxUnitOfWorkAttempts {
+		t.Fatalf("Work = %v after %d attempts, want a deadlock after %d", err, attempts, MaxUnitOfWorkAttempts)
+	}
+	if stats := statsFor("test.retry"); stats.Retries != 1+MaxUnitOfWorkAttempts-1 || stats.Failures != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if IsDeadlock(errors.New("database is locked")) || IsDeadlock(&pq.Error{Code: "23505"}) {
+		t.Fatal("only deadlocks and serialization failures are retried")
+	}
+}
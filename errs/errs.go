@@ -0,0 +1,141 @@
+// Package errs classifies failures so the CLI and GUI can tell the user
+// what to do about them instead of showing only a wrapped error string.
+//
+// Errors keep their messages; a kind is attached with New and checked with
+// errors.Is, e.g. errors.Is(err, errs.ErrAuth). Hint returns the remediation
+// text for the first kind or hint found in an error chain.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kinds of failure the user can act on.
+var (
+	// ErrAuth means the API rejected the credentials.
+	ErrAuth = errors.New("authentication failed")
+	// ErrRateLimit means the API asked us to slow down.
+	ErrRateLimit = errors.New("rate limited")
+	// ErrSchema means the database does not match the schema this version
+	// writes.
+	ErrSchema = errors.New("database schema mismatch")
+	// ErrConflict means the data changed since the operation was prepared.
+	ErrConflict = errors.New("conflicting change")
+	// ErrNetwork means the API could not be reached or is unavailable.
+	ErrNetwork = errors.New("network error")
+	// ErrValidation means the API rejected values of the request.
+	ErrValidation = errors.New("validation failed")
+	// ErrPartial means some items of a pull or push failed while the rest
+	// were synced.
+	ErrPartial = errors.New("partial failure")
+)
+
+// Kinds lists every kind, in the order Kind checks them. ErrPartial comes
+// last so the hint names the cause of the failed items when it is known.
+var Kinds = []error{ErrAuth, ErrRateLimit, ErrSchema, ErrConflict, ErrNetwork, ErrValidation, ErrPartial}
+
+var hints = map[error]string{
+	ErrAuth:       "BadgerMaps rejected the API key. Regenerate your API key in BadgerMaps and save it on the Configuration tab or with 'badgermaps config'.",
+	ErrRateLimit:  "BadgerMaps is limiting requests. Wait a minute and try again, or lower requests_per_second, max_concurrent_requests, or the push workers in the config.",
+	ErrSchema:     "The database schema does not match this version. Run 'badgermaps db migrate', or back up, re-initialize the schema with 'badgermaps config', and restore.",
+	ErrConflict:   "The data changed after this change was staged. Pull the latest data, review the change, and stage it again.",
+	ErrNetwork:    "BadgerMaps could not be reached. Check your internet connection, proxy, and the API URL, then try again.",
+	ErrValidation: "BadgerMaps rejected some of the values. Correct the fields it names in the pending change and push again.",
+	ErrPartial:    "Some items failed and the rest were synced. Check the errors above and run the command again; items that already succeeded are not sent twice.",
+}
+
+// Error attaches a kind, and optionally a more specific hint, to Err. Its
+// message is Err's message.
+type Error struct {
+	Kind error
+	Err  error
+	// Remedy replaces the kind's hint when set.
+	Remedy string
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is the error's kind.
+func (e *Error) Is(target error) bool { return target == e.Kind }
+
+// Hint returns the specific remediation text, if any.
+func (e *Error) Hint() string { return e.Remedy }
+
+// New returns err classified as kind, or nil when err is nil.
+func New(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// WithHint returns err classified as kind with remediation text specific to
+// this failure.
+func WithHint(kind, err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err, Remedy: hint}
+}
+
+// Partial returns failures as one ErrPartial error whose message is
+// summary followed by each failure. The failures stay reachable, so
+// errors.Is still finds a rejected key among them.
+func Partial(summary string, failures []error) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &partialError{summary: summary, errs: failures}
+}
+
+type partialError struct {
+	summary string
+	errs    []error
+}
+
+func (e *partialError) Error() string {
+	messages := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%s:\n- %s", e.summary, strings.Join(messages, "\n- "))
+}
+
+func (e *partialError) Unwrap() []error { return e.errs }
+
+func (e *partialError) Is(target error) bool { return target == ErrPartial }
+
+// Hinter is implemented by errors that know how to fix themselves, such as
+// app.SchemaError.
+type Hinter interface {
+	Hint() string
+}
+
+// Kind returns the kind of err, or nil when it is not classified.
+func Kind(err error) error {
+	for _, kind := range Kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// Hint returns what the user can do about err, or "" when there is nothing
+// more specific to say than the error itself.
+func Hint(err error) string {
+	if err == nil {
+		return ""
+	}
+	var h Hinter
+	if errors.As(err, &h) {
+		if hint := h.Hint(); hint != "" {
+			return hint
+		}
+	}
+	return hints[Kind(err)]
+}
@@ -0,0 +1,93 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type fixableError struct{}
+
+func (fixableError) Error() string { return "broken" }
+func (fixableError) Hint() string  { return "fix it" }
+
+func TestKindSurvivesWrapping(t *testing.T) {
+	base := errors.New("unexpected status 401")
+	err := fmt.Errorf("customer 7 request failed: %w", New(ErrAuth, base))
+	if !errors.Is(err, ErrAuth) || errors.Is(err, ErrNetwork) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+	if !errors.Is(err, base) {
+		t.Fatal("the wrapped error is no longer reachable")
+	}
+	if err.Error() != "customer 7 request failed: unexpected status 401" {
+		t.Fatalf("message changed: %q", err.Error())
+	}
+	if Kind(err) != ErrAuth || Kind(base) != nil {
+		t.Fatalf("Kind = %v, %v", Kind(err), Kind(base))
+	}
+	joined := errors.Join(base, New(ErrRateLimit, errors.New("429")))
+	if Kind(joined) != ErrRateLimit {
+		t.Fatalf("Kind of joined errors = %v, want ErrRateLimit", Kind(joined))
+	}
+	if New(ErrAuth, nil) != nil {
+		t.Fatal("New(kind, nil) should be nil")
+	}
+}
+
+func TestHint(t *testing.T) {
+	if got := Hint(New(ErrAuth, errors.New("401"))); got != hints[ErrAuth] {
+		t.Errorf("auth hint = %q", got)
+	}
+	if got := Hint(WithHint(ErrNetwork, errors.New("dial"), "check the VPN")); got != "check the VPN" {
+		t.Errorf("specific hint = %q", got)
+	}
+	if got := Hint(fmt.Errorf("pull: %w", fixableError{})); got != "fix it" {
+		t.Errorf("Hinter hint = %q", got)
+	}
+	if got := Hint(errors.New("plain")); got != "" {
+		t.Errorf("unclassified hint = %q, want none", got)
+	}
+}
+
+func TestPartialKeepsFailures(t *testing.T) {
+	if Partial("2 changes failed", nil) != nil {
+		t.Fatal("Partial without failures should be nil")
+	}
+	err := Partial("2 changes failed", []error{errors.New("bad zip"), New(ErrNetwork, errors.New("timeout"))})
+	if !errors.Is(err, ErrPartial) || !errors.Is(err, ErrNetwork) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+	if err.Error() != "2 changes failed:\n- bad zip\n- timeout" {
+		t.Fatalf("message = %q", err.Error())
+	}
+	if Hint(err) != hints[ErrNetwork] {
+		t.Errorf("hint = %q, want the failed items' hint", Hint(err))
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	partial := func(failures ...error) error { return Partial("some items failed", failures) }
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"unclassified", errors.New("unknown flag"), ExitFailure},
+		{"partial", partial(errors.New("bad zip")), ExitPartial},
+		{"auth", fmt.Errorf("pull: %w", New(ErrAuth, errors.New("401"))), ExitAuth},
+		{"schema", New(ErrSchema, errors.New("missing column")), ExitSchema},
+		{"network", New(ErrNetwork, errors.New("dial")), ExitNetwork},
+		{"rate limit", New(ErrRateLimit, errors.New("429")), ExitRateLimit},
+		{"validation", New(ErrValidation, errors.New("422")), ExitValidation},
+		{"conflict", New(ErrConflict, errors.New("changed")), ExitConflict},
+		{"auth wins over partial", partial(errors.New("bad zip"), New(ErrAuth, errors.New("401"))), ExitAuth},
+		{"partial wins over network", partial(New(ErrNetwork, errors.New("dial"))), ExitPartial},
+		{"joined", errors.Join(partial(errors.New("bad zip")), New(ErrSchema, errors.New("old"))), ExitSchema},
+	} {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("%s: ExitCode = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
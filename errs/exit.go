@@ -0,0 +1,59 @@
+package errs
+
+import "errors"
+
+// Exit codes of the badgermaps command. They are part of its interface for
+// cron jobs and service wrappers, so existing values must not change.
+const (
+	// ExitOK means the command succeeded.
+	ExitOK = 0
+	// ExitFailure is any failure without a more specific code, including
+	// invalid flags and arguments.
+	ExitFailure = 1
+	// ExitPartial means some items failed and the rest were synced.
+	// Running the command again retries only what failed.
+	ExitPartial = 2
+	// ExitAuth means the API rejected the credentials; retrying will not
+	// help until the key is replaced.
+	ExitAuth = 3
+	// ExitSchema means the database needs a migration.
+	ExitSchema = 4
+	// ExitNetwork means the API could not be reached; a later run may
+	// succeed.
+	ExitNetwork = 5
+	// ExitRateLimit means the API kept limiting requests.
+	ExitRateLimit = 6
+	// ExitValidation means the API rejected values that need correcting.
+	ExitValidation = 7
+	// ExitConflict means data changed after a change was staged.
+	ExitConflict = 8
+)
+
+// exitCodes maps kinds to exit codes, in order of precedence: a failure
+// that needs the user, such as a rejected key, wins over a partial failure
+// it caused, which wins over the transient kinds.
+var exitCodes = []struct {
+	kind error
+	code int
+}{
+	{ErrAuth, ExitAuth},
+	{ErrSchema, ExitSchema},
+	{ErrPartial, ExitPartial},
+	{ErrRateLimit, ExitRateLimit},
+	{ErrNetwork, ExitNetwork},
+	{ErrValidation, ExitValidation},
+	{ErrConflict, ExitConflict},
+}
+
+// ExitCode returns the exit code for a command that failed with err.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.kind) {
+			return c.code
+		}
+	}
+	return ExitFailure
+}
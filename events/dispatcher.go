@@ -1,6 +1,7 @@
 package events
 
 import (
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,14 +16,26 @@ type EventDispatcher struct {
 }
 
 type queuedListener struct {
-	fn EventListener
-	d  *EventDispatcher
+	fn       EventListener
+	d        *EventDispatcher
+	priority int
+	once     bool
+	fired    atomic.Bool
 
 	mu         sync.Mutex
-	queue      []Event
+	queue      []delivery
 	processing bool
 }
 
+// delivery is an event queued for a listener. When listeners of a higher
+// priority also receive the event, after holds the listener back until
+// they have handled it, and done is released once it has.
+type delivery struct {
+	e     Event
+	after *sync.WaitGroup
+	done  *sync.WaitGroup
+}
+
 // NewEventDispatcher creates a new EventDispatcher.
 func NewEventDispatcher() *EventDispatcher {
 	return &EventDispatcher{
@@ -30,37 +43,144 @@ func NewEventDispatcher() *EventDispatcher {
 	}
 }
 
+// SubscribeOptions controls how a listener receives events.
+type SubscribeOptions struct {
+	// Priority orders the listeners of one event: a listener only receives
+	// it after every listener of a higher priority has handled it.
+	// Listeners of the same priority receive it concurrently, as do all
+	// listeners by default.
+	Priority int
+	// Once removes the listener after the first event it receives.
+	Once bool
+}
+
+// Subscription is a listener added to a dispatcher. Views that subscribe
+// when they are built unsubscribe when they are rebuilt, so they do not
+// collect duplicate handlers.
+type Subscription struct {
+	d    *EventDispatcher
+	l    *queuedListener
+	once sync.Once
+}
+
 // Subscribe adds a listener for a given event type pattern.
 // Patterns can include wildcards, e.g., "pull.*" or "*.accounts".
-func (d *EventDispatcher) Subscribe(eventType EventType, listener EventListener) {
+func (d *EventDispatcher) Subscribe(eventType EventType, listener EventListener) *Subscription {
+	return d.SubscribeWith(eventType, listener, SubscribeOptions{})
+}
+
+// SubscribeOnce adds a listener that is removed after the first event it
+// receives.
+func (d *EventDispatcher) SubscribeOnce(eventType EventType, listener EventListener) *Subscription {
+	return d.SubscribeWith(eventType, listener, SubscribeOptions{Once: true})
+}
+
+// SubscribeWith adds a listener like Subscribe with options.
+func (d *EventDispatcher) SubscribeWith(eventType EventType, listener EventListener, opts SubscribeOptions) *Subscription {
+	ql := &queuedListener{fn: listener, d: d, priority: opts.Priority, once: opts.Once}
 	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.listeners[eventType] = append(d.listeners[eventType], &queuedListener{
-		fn: listener,
-		d:  d,
+	d.listeners[eventType] = append(d.listeners[eventType], ql)
+	d.mu.Unlock()
+	return &Subscription{d: d, l: ql}
+}
+
+// SubscribeCancelable adds a listener like Subscribe and returns a function
+// that removes it again. Events already queued for the listener are still
+// delivered.
+func (d *EventDispatcher) SubscribeCancelable(eventType EventType, listener EventListener) (cancel func()) {
+	return d.Subscribe(eventType, listener).Unsubscribe
+}
+
+// Unsubscribe removes the listener. Events already queued for it are still
+// delivered. Calling it again, or on a nil subscription, does nothing.
+func (s *Subscription) Unsubscribe() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		s.d.remove(s.l)
 	})
 }
 
+// Listeners returns the number of listeners subscribed to any pattern.
+func (d *EventDispatcher) Listeners() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	n := 0
+	for _, listeners := range d.listeners {
+		n += len(listeners)
+	}
+	return n
+}
+
 // Dispatch sends an event to all listeners whose subscribed pattern matches the event type.
 func (d *EventDispatcher) Dispatch(e Event) {
 	d.mu.RLock()
 	var listenersToCall []*queuedListener
+	var fired []*queuedListener
 
 	for pattern, listeners := range d.listeners {
-		if match(pattern, e.Type) {
-			listenersToCall = append(listenersToCall, listeners...)
+		if !match(pattern, e.Type) {
+			continue
+		}
+		for _, l := range listeners {
+			if l.once {
+				if !l.fired.CompareAndSwap(false, true) {
+					continue
+				}
+				fired = append(fired, l)
+			}
+			listenersToCall = append(listenersToCall, l)
 		}
 	}
 	d.mu.RUnlock()
+	for _, l := range fired {
+		d.remove(l)
+	}
 
 	// Enqueue event delivery per listener so each listener processes events in-order
-	// without blocking the caller of Dispatch.
-	for _, listener := range listenersToCall {
-		listener.enqueue(e)
+	// without blocking the caller of Dispatch. Listeners of a lower priority
+	// wait for those of the tier above.
+	sort.SliceStable(listenersToCall, func(i, j int) bool {
+		return listenersToCall[i].priority > listenersToCall[j].priority
+	})
+	var after *sync.WaitGroup
+	for i := 0; i < len(listenersToCall); {
+		j := i
+		for j < len(listenersToCall) && listenersToCall[j].priority == listenersToCall[i].priority {
+			j++
+		}
+		var done *sync.WaitGroup
+		if j < len(listenersToCall) {
+			done = &sync.WaitGroup{}
+			done.Add(j - i)
+		}
+		for _, listener := range listenersToCall[i:j] {
+			listener.enqueue(delivery{e: e, after: after, done: done})
+		}
+		after = done
+		i = j
+	}
+}
+
+// remove drops l from every pattern it is subscribed to.
+func (d *EventDispatcher) remove(l *queuedListener) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for pattern, listeners := range d.listeners {
+		for i, candidate := range listeners {
+			if candidate == l {
+				d.listeners[pattern] = append(listeners[:i:i], listeners[i+1:]...)
+				if len(d.listeners[pattern]) == 0 {
+					delete(d.listeners, pattern)
+				}
+				return
+			}
+		}
 	}
 }
 
-func (l *queuedListener) enqueue(e Event) {
+func (l *queuedListener) enqueue(e delivery) {
 	l.d.pending.Add(1)
 
 	l.mu.Lock()
@@ -88,9 +208,15 @@ func (l *queuedListener) drain() {
 		l.queue = l.queue[1:]
 		l.mu.Unlock()
 
+		if e.after != nil {
+			e.after.Wait()
+		}
 		func() {
 			defer l.d.pending.Add(-1)
-			l.fn(e)
+			if e.done != nil {
+				defer e.done.Done()
+			}
+			l.fn(e.e)
 		}()
 	}
 }
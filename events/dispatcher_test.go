@@ -179,3 +179,106 @@ func TestEventDispatcher_WaitForDrainTimeout(t *testing.T) {
 		t.Fatal("expected wait to succeed once listener unblocks")
 	}
 }
+
+func TestEventDispatcher_SubscribeCancelable(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	var mu sync.Mutex
+	var got []EventType
+	cancel := dispatcher.SubscribeCancelable("pull.*", func(e Event) {
+		mu.Lock()
+		got = append(got, e.Type)
+		mu.Unlock()
+	})
+	dispatcher.Subscribe("pull.*", func(Event) {})
+
+	dispatcher.Dispatch(Event{Type: "pull.start"})
+	dispatcher.WaitForDrain(time.Second)
+	cancel()
+	cancel()
+	dispatcher.Dispatch(Event{Type: "pull.complete"})
+	dispatcher.WaitForDrain(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0] != "pull.start" {
+		t.Errorf("cancelled listener received %v", got)
+	}
+	if n := len(dispatcher.listeners["pull.*"]); n != 1 {
+		t.Errorf("expected the other listener to remain, found %d", n)
+	}
+}
+
+func TestEventDispatcher_SubscribeOnceAndUnsubscribe(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	var mu sync.Mutex
+	counts := map[string]int{}
+	count := func(name string) EventListener {
+		return func(Event) {
+			mu.Lock()
+			counts[name]++
+			mu.Unlock()
+		}
+	}
+	dispatcher.SubscribeOnce("pull.*", count("once"))
+	sub := dispatcher.Subscribe("*", count("all"))
+
+	dispatcher.Dispatch(Event{Type: "pull.start"})
+	dispatcher.Dispatch(Event{Type: "pull.complete"})
+	dispatcher.WaitForDrain(time.Second)
+	if n := dispatcher.Listeners(); n != 1 {
+		t.Fatalf("%d listeners after the once listener fired, want 1", n)
+	}
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	dispatcher.Dispatch(Event{Type: "pull.error"})
+	dispatcher.WaitForDrain(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counts["once"] != 1 || counts["all"] != 2 || dispatcher.Listeners() != 0 {
+		t.Fatalf("unexpected deliveries %v with %d listeners left", counts, dispatcher.Listeners())
+	}
+	var nilSub *Subscription
+	nilSub.Unsubscribe()
+}
+
+func TestEventDispatcher_PriorityOrdersListeners(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	var mu sync.Mutex
+	var got []string
+	record := func(name string, delay time.Duration) EventListener {
+		return func(e Event) {
+			time.Sleep(delay)
+			mu.Lock()
+			got = append(got, name+":"+string(e.Type))
+			mu.Unlock()
+		}
+	}
+	dispatcher.SubscribeWith("push.*", record("low", 0), SubscribeOptions{Priority: -1})
+	dispatcher.SubscribeWith("push.*", record("high", 30*time.Millisecond), SubscribeOptions{Priority: 10})
+	dispatcher.Subscribe("push.start", record("default", 10*time.Millisecond))
+
+	start := time.Now()
+	dispatcher.Dispatch(Event{Type: "push.start"})
+	dispatcher.Dispatch(Event{Type: "push.complete"})
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("dispatch should not wait for listeners, took %v", elapsed)
+	}
+	if !dispatcher.WaitForDrain(time.Second) {
+		t.Fatal("timed out waiting for listeners")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	index := map[string]int{}
+	for i, name := range got {
+		index[name] = i
+	}
+	if len(got) != 5 ||
+		index["high:push.start"] > index["default:push.start"] ||
+		index["default:push.start"] > index["low:push.start"] ||
+		index["high:push.complete"] > index["low:push.complete"] ||
+		index["low:push.start"] > index["low:push.complete"] {
+		t.Fatalf("listeners ran out of priority order: %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -31,6 +32,22 @@ func (l LogLevel) String() string {
 	}
 }
 
+// ParseLogLevel converts a configured level name such as "warn" into a
+// LogLevel.
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	}
+	return LogLevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn, or error)", name)
+}
+
 // EventType is a string-based identifier for an event (e.g., "pull.start").
 type EventType string
 
@@ -103,6 +120,50 @@ type CompletionPayload struct {
 
 func (p CompletionPayload) EventType() EventType { return "process.complete" }
 
+// ProgressPayload reports how far a pull or push of one entity type, or
+// the copy of one table, has got. It is sent as pull.progress,
+// push.progress or db.migrate.progress at most every ProgressInterval while
+// work is running, and once more with Done set when it finishes. Processed
+// includes Failed; Total is 0 until it is known.
+type ProgressPayload struct {
+	Entity    string
+	Processed int
+	Total     int
+	Failed    int
+	// Rate is items processed per second since the operation started.
+	Rate float64
+	Done bool
+}
+
+func (p ProgressPayload) EventType() EventType { return "progress" }
+
+// Fraction returns Processed/Total, or 0 when the total is unknown.
+func (p ProgressPayload) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	if p.Processed >= p.Total {
+		return 1
+	}
+	return float64(p.Processed) / float64(p.Total)
+}
+
+// Summary describes the progress in one line, for example
+// "accounts 120/500, 3 failed, 14.2/s".
+func (p ProgressPayload) Summary() string {
+	text := fmt.Sprintf("%s %d", p.Entity, p.Processed)
+	if p.Total > 0 {
+		text += fmt.Sprintf("/%d", p.Total)
+	}
+	if p.Failed > 0 {
+		text += fmt.Sprintf(", %d failed", p.Failed)
+	}
+	if p.Rate > 0 {
+		text += fmt.Sprintf(", %.1f/s", p.Rate)
+	}
+	return text
+}
+
 // ErrorPayload is for when an error occurs.
 type ErrorPayload struct {
 	Error      error
@@ -146,6 +207,16 @@ type PushItemErrorPayload struct {
 
 func (p PushItemErrorPayload) EventType() EventType { return "push.item.error" }
 
+// PushConflictPayload is for when a staged change is skipped because the
+// underlying record changed after it was staged.
+type PushConflictPayload struct {
+	Change         interface{}
+	BaseVersion    string
+	CurrentVersion string
+}
+
+func (p PushConflictPayload) EventType() EventType { return "push.conflict" }
+
 // PushCompletePayload is for when a push operation is complete.
 type PushCompletePayload struct {
 	ErrorCount int
@@ -156,20 +227,168 @@ func (p PushCompletePayload) EventType() EventType { return "push.complete" }
 // --- Action Config Payloads ---
 
 // ActionConfigCreatedPayload is for when an action config is created.
-type ActionConfigCreatedPayload struct{}
+type ActionConfigCreatedPayload struct {
+	Name string // the event action's name
+}
 
 func (p ActionConfigCreatedPayload) EventType() EventType { return "action.config.created" }
 
 // ActionConfigUpdatedPayload is for when an action config is updated.
-type ActionConfigUpdatedPayload struct{}
+type ActionConfigUpdatedPayload struct {
+	Name string // the event action's name
+}
 
 func (p ActionConfigUpdatedPayload) EventType() EventType { return "action.config.updated" }
 
 // ActionConfigDeletedPayload is for when an action config is deleted.
-type ActionConfigDeletedPayload struct{}
+type ActionConfigDeletedPayload struct {
+	Name string // the event action's name
+}
 
 func (p ActionConfigDeletedPayload) EventType() EventType { return "action.config.deleted" }
 
+// --- Database Maintenance Payloads ---
+
+// DatabaseBackupPayload is for when a database backup has been written.
+type DatabaseBackupPayload struct {
+	Path   string
+	Format string
+}
+
+func (p DatabaseBackupPayload) EventType() EventType { return "db.backup.complete" }
+
+// DatabaseRestorePayload is for when a database has been restored from a backup.
+type DatabaseRestorePayload struct {
+	Path string
+}
+
+func (p DatabaseRestorePayload) EventType() EventType { return "db.restore.complete" }
+
+// DatabaseEncryptPayload is for when an unencrypted SQLite database has been
+// converted to SQLCipher.
+type DatabaseEncryptPayload struct {
+	Path string
+}
+
+func (p DatabaseEncryptPayload) EventType() EventType { return "db.encrypt.complete" }
+
+// DatabaseRekeyPayload is for when an encrypted SQLite database has been
+// re-encrypted with a new passphrase.
+type DatabaseRekeyPayload struct {
+	Path string
+}
+
+func (p DatabaseRekeyPayload) EventType() EventType { return "db.rekey.complete" }
+
+// CheckinArchivePayload is for when old check-ins have been moved to the
+// archive.
+type CheckinArchivePayload struct {
+	Months   []string
+	Checkins int
+}
+
+func (p CheckinArchivePayload) EventType() EventType { return "db.archive.complete" }
+
+// CheckinArchiveRestorePayload is for when archived check-ins have been
+// restored to the database.
+type CheckinArchiveRestorePayload struct {
+	Months   []string
+	Checkins int
+	Skipped  int
+}
+
+func (p CheckinArchiveRestorePayload) EventType() EventType { return "db.archive.restore.complete" }
+
+// DatabaseMigratePayload is for when the data has been copied to another
+// database with `db migrate-to`. Switched is set when the config now uses
+// it.
+type DatabaseMigratePayload struct {
+	Type     string
+	Tables   int
+	Rows     int
+	Switched bool
+}
+
+func (p DatabaseMigratePayload) EventType() EventType { return "db.migrate.complete" }
+
+// --- Webhook Payloads ---
+
+// WebhookInvalidPayload is for when a webhook body does not match the schema
+// of its webhook. The request is logged and rejected.
+type WebhookInvalidPayload struct {
+	Webhook  string
+	Uri      string
+	EntityID int64
+	Errors   []string
+	Body     string
+}
+
+func (p WebhookInvalidPayload) EventType() EventType { return "webhook.invalid" }
+
+// CheckinWebhookPayload is for when a check-in webhook has been stored.
+// LogDatetime is the check-in time in RFC 3339, empty when the webhook had
+// none the app could read.
+type CheckinWebhookPayload struct {
+	CheckinID   int64
+	AccountID   int64
+	Type        string
+	Comments    string
+	CreatedBy   string
+	LogDatetime string
+}
+
+func (p CheckinWebhookPayload) EventType() EventType { return "webhook.checkin" }
+
+// --- Connection Payloads ---
+
+// ConnectionStatusPayload is for when the API or database connection status
+// changes. Seq increases with every change, so a listener can ignore an
+// update older than one it has already handled. APIChecking is set while
+// the API is tested in the background at startup. Schema is the cached
+// schema check of the database: empty until checked, then "checking",
+// "valid", or "invalid".
+type ConnectionStatusPayload struct {
+	API         bool
+	APIChecking bool
+	Database    bool
+	Schema      string
+	Seq         uint64
+}
+
+func (p ConnectionStatusPayload) EventType() EventType { return "connection.status.changed" }
+
+// --- Config Payloads ---
+
+// ConfigReloadPayload is for when the config file has been re-read and
+// applied to a running process.
+type ConfigReloadPayload struct {
+	Applied         []string
+	RestartRequired []string
+}
+
+func (p ConfigReloadPayload) EventType() EventType { return "config.reload" }
+
+// --- Alert Payloads ---
+
+// AlertPayload is for when an alert threshold is crossed. Rule names the
+// threshold, such as "push_error_rate", and Since is when it was first
+// crossed.
+type AlertPayload struct {
+	Rule      string
+	Message   string
+	Value     float64
+	Threshold float64
+	Since     time.Time
+}
+
+func (p AlertPayload) EventType() EventType { return "alert.fired" }
+
+// AlertResolvedPayload is for when a fired alert is back within its
+// threshold.
+type AlertResolvedPayload AlertPayload
+
+func (p AlertResolvedPayload) EventType() EventType { return "alert.resolved" }
+
 // --- Event Helper Functions ---
 
 // NewLogEvent creates a new log event.
@@ -217,7 +436,19 @@ var eventTypeOptions = []string{
 	"action.config.updated",
 	"action.error",
 	"action.success",
+	"alert.fired",
+	"alert.resolved",
+	"config.reload",
+	"config.reload.error",
 	"connection.status.changed",
+	"db.archive.complete",
+	"db.archive.restore.complete",
+	"db.backup.complete",
+	"db.encrypt.complete",
+	"db.migrate.complete",
+	"db.migrate.progress",
+	"db.rekey.complete",
+	"db.restore.complete",
 	"log",
 	"pull.complete",
 	"pull.error",
@@ -227,15 +458,20 @@ var eventTypeOptions = []string{
 	"pull.group.error",
 	"pull.group.start",
 	"pull.ids_fetched",
+	"pull.progress",
 	"pull.start",
 	"pull.store.success",
 	"push.complete",
+	"push.conflict",
 	"push.error",
 	"push.item.error",
 	"push.item.start",
 	"push.item.success",
+	"push.progress",
 	"push.scan.complete",
 	"push.scan.start",
+	"webhook.checkin",
+	"webhook.invalid",
 }
 
 var eventSourceOptions = []string{
@@ -244,10 +480,13 @@ var eventSourceOptions = []string{
 	"accounts",
 	"check-in",
 	"checkins",
+	"datasets",
 	"events",
+	"locations",
 	"route",
 	"routes",
 	"user profile",
+	"webhook",
 }
 
 // AllEventTypes returns a slice of all event type strings suitable for user configuration.
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +16,21 @@ type LogListener struct {
 	State  *state.State
 	writer io.Writer
 	file   *os.File
+	// minLevel holds the configured minimum level plus one so the zero
+	// value means "not configured".
+	minLevel atomic.Int32
+}
+
+// SetMinLevel drops log events below level. Setting LogLevelDebug shows
+// debug output even without --verbose or --debug. It is safe to call while
+// events are being handled.
+func (l *LogListener) SetMinLevel(level LogLevel) {
+	l.minLevel.Store(int32(level) + 1)
+}
+
+// ClearMinLevel restores the default filtering driven by the CLI flags.
+func (l *LogListener) ClearMinLevel() {
+	l.minLevel.Store(0)
 }
 
 // NewLogListener creates a new LogListener.
@@ -44,18 +60,23 @@ func (l *LogListener) Close() {
 }
 
 // Handle processes the log event.
+// With --quiet only errors are logged, and they go to stderr unless a log
+// file is set, so a scheduled run prints nothing when it succeeds.
 func (l *LogListener) Handle(e Event) {
-	if l.State.Quiet {
-		return
-	}
-
 	payload, ok := e.Payload.(LogPayload)
 	if !ok {
 		return // Not a log event we can handle
 	}
 
 	// Respect quiet and verbosity settings
-	if payload.Level == LogLevelDebug && !(l.State.Debug || l.State.Verbose) {
+	if l.State.Quiet && payload.Level < LogLevelError {
+		return
+	}
+	if configured := l.minLevel.Load(); configured > 0 {
+		if payload.Level < LogLevel(configured-1) {
+			return
+		}
+	} else if payload.Level == LogLevelDebug && !(l.State.Debug || l.State.Verbose) {
 		return
 	}
 
@@ -111,5 +132,9 @@ func (l *LogListener) Handle(e Event) {
 	sb.WriteString("\n")
 
 	// Write to the configured writer
-	fmt.Fprint(l.writer, sb.String())
+	writer := l.writer
+	if l.State.Quiet && l.file == nil {
+		writer = os.Stderr
+	}
+	fmt.Fprint(writer, sb.String())
 }
@@ -42,3 +42,33 @@ func TestLogListener_FileLogging(t *testing.T) {
 		t.Errorf("Log file does not contain the expected source. Got: %s", string(content))
 	}
 }
+
+func TestLogListener_QuietLogsOnlyErrors(t *testing.T) {
+	tmpfile, err := os.CreateTemp(t.TempDir(), "quiet.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+	s := state.NewState()
+	s.Quiet = true
+	listener, err := NewLogListener(s, tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	listener.Handle(Infof("pull", "Pulled 10 accounts"))
+	listener.Handle(Warningf("pull", "Skipped a location"))
+	listener.Handle(Errorf("main", "command execution failed"))
+
+	content, err := os.ReadFile(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "Pulled") || strings.Contains(string(content), "Skipped") {
+		t.Errorf("quiet mode logged more than errors: %s", content)
+	}
+	if !strings.Contains(string(content), "command execution failed") {
+		t.Errorf("quiet mode dropped an error: %s", content)
+	}
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StreamedEvent is the JSON form of an event sent to `badgermaps watch`.
+type StreamedEvent struct {
+	Time   time.Time `json:"time"`
+	Type   EventType `json:"type"`
+	Source string    `json:"source"`
+	// Level and Message are set for log events.
+	Level   string          `json:"level,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// NewStreamedEvent converts e, stamping it with the current time unless it is
+// a log event, which carries its own.
+func NewStreamedEvent(e Event) StreamedEvent {
+	streamed := StreamedEvent{Time: time.Now(), Type: e.Type, Source: e.Source}
+	if log, ok := e.Payload.(LogPayload); ok {
+		if !log.Timestamp.IsZero() {
+			streamed.Time = log.Timestamp
+		}
+		streamed.Level = log.Level.String()
+		streamed.Message = log.Message
+		return streamed
+	}
+	if e.Payload != nil {
+		if data, err := json.Marshal(e.Payload); err == nil {
+			streamed.Payload = data
+		}
+	}
+	return streamed
+}
+
+// MatchesAny reports whether eventType matches one of patterns, using the
+// same wildcards as Subscribe. No patterns match every event.
+func MatchesAny(patterns []string, eventType EventType) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if match(EventType(pattern), eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -30,6 +31,13 @@ var customPayloadOption = EventTokenOption{
 	Placeholder:  "e.g. Data.id",
 }
 
+var templatePathOption = EventTokenOption{
+	Label:        "Payload path template… ({{payload.path}})",
+	Format:       "{{payload.%s}}",
+	RequiresPath: true,
+	Placeholder:  "e.g. Data.locations[0].city",
+}
+
 type payloadDescriptor struct {
 	payloadType reflect.Type
 	payloadKind string
@@ -52,6 +60,9 @@ var payloadRegistry = map[EventType]eventPayloadRegistry{
 	"pull.ids_fetched": {
 		defaults: newDescriptor(ResourceIDsFetchedPayload{}),
 	},
+	"pull.progress": {
+		defaults: newDescriptor(ProgressPayload{}),
+	},
 	"pull.fetch_detail.start": {
 		defaults: newDescriptor(FetchDetailStartPayload{}),
 	},
@@ -117,9 +128,15 @@ var payloadRegistry = map[EventType]eventPayloadRegistry{
 			"checkins": newDescriptorWithKind(PushItemSuccessPayload{}, "checkin_change"),
 		},
 	},
+	"push.progress": {
+		defaults: newDescriptor(ProgressPayload{}),
+	},
 	"push.item.error": {
 		defaults: newDescriptor(PushItemErrorPayload{}),
 	},
+	"push.conflict": {
+		defaults: newDescriptor(PushConflictPayload{}),
+	},
 	"push.complete": {
 		defaults: newDescriptor(PushCompletePayload{}),
 	},
@@ -135,6 +152,51 @@ var payloadRegistry = map[EventType]eventPayloadRegistry{
 	"action.config.deleted": {
 		defaults: newDescriptor(ActionConfigDeletedPayload{}),
 	},
+	"connection.status.changed": {
+		defaults: newDescriptor(ConnectionStatusPayload{}),
+	},
+	"db.backup.complete": {
+		defaults: newDescriptor(DatabaseBackupPayload{}),
+	},
+	"db.restore.complete": {
+		defaults: newDescriptor(DatabaseRestorePayload{}),
+	},
+	"db.encrypt.complete": {
+		defaults: newDescriptor(DatabaseEncryptPayload{}),
+	},
+	"db.rekey.complete": {
+		defaults: newDescriptor(DatabaseRekeyPayload{}),
+	},
+	"db.archive.complete": {
+		defaults: newDescriptor(CheckinArchivePayload{}),
+	},
+	"db.archive.restore.complete": {
+		defaults: newDescriptor(CheckinArchiveRestorePayload{}),
+	},
+	"db.migrate.progress": {
+		defaults: newDescriptor(ProgressPayload{}),
+	},
+	"db.migrate.complete": {
+		defaults: newDescriptor(DatabaseMigratePayload{}),
+	},
+	"config.reload": {
+		defaults: newDescriptor(ConfigReloadPayload{}),
+	},
+	"config.reload.error": {
+		defaults: newDescriptor(ErrorPayload{}),
+	},
+	"webhook.invalid": {
+		defaults: newDescriptor(WebhookInvalidPayload{}),
+	},
+	"webhook.checkin": {
+		defaults: newDescriptor(CheckinWebhookPayload{}),
+	},
+	"alert.fired": {
+		defaults: newDescriptor(AlertPayload{}),
+	},
+	"alert.resolved": {
+		defaults: newDescriptor(AlertResolvedPayload{}),
+	},
 }
 
 func newDescriptor(payload interface{}) *payloadDescriptor {
@@ -154,10 +216,26 @@ func EventTokenOptions(eventType, source string) []EventTokenOption {
 		options = append(options, fields...)
 	}
 
-	options = append(options, customPayloadOption)
+	options = append(options, customPayloadOption, templatePathOption)
 	return options
 }
 
+// SamplePayloadJSON returns an indented JSON rendering of the zero value of
+// the payload registered for the event type and source, for previewing
+// templates. It returns "{}" when the payload type is unknown.
+func SamplePayloadJSON(eventType, source string) string {
+	descriptor := resolvePayloadDescriptor(EventType(eventType), source)
+	if descriptor == nil || descriptor.payloadType == nil {
+		return "{}"
+	}
+	sample := reflect.New(descriptor.payloadType).Elem().Interface()
+	data, err := json.MarshalIndent(sample, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
 func payloadFieldOptions(eventType EventType, source string) []EventTokenOption {
 	descriptor := resolvePayloadDescriptor(eventType, source)
 	if descriptor == nil || descriptor.payloadType == nil {
@@ -33,9 +33,18 @@ func TestEventTokenOptionsIncludesCustomOption(t *testing.T) {
 }
 
 func TestEventWithoutPayloadFallsBackToBaseTokens(t *testing.T) {
-	options := EventTokenOptions("connection.status.changed", "")
-	if len(options) != len(baseTokenOptions)+1 {
-		t.Fatalf("expected only base tokens and custom option, got %d", len(options))
+	options := EventTokenOptions("action.success", "")
+	if len(options) != len(baseTokenOptions)+2 {
+		t.Fatalf("expected only base tokens and custom options, got %d", len(options))
+	}
+}
+
+func TestSamplePayloadJSON(t *testing.T) {
+	if got := SamplePayloadJSON("db.backup.complete", ""); got != "{\n  \"Path\": \"\",\n  \"Format\": \"\"\n}" {
+		t.Fatalf("unexpected sample payload: %s", got)
+	}
+	if got := SamplePayloadJSON("no.such.event", ""); got != "{}" {
+		t.Fatalf("expected empty object for unknown events, got %s", got)
 	}
 }
 
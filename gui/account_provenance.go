@@ -0,0 +1,115 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+)
+
+// showAccountProvenance shows an account's editable fields in the details
+// pane, each marked as pulled from BadgerMaps or edited locally and not yet
+// pushed. Fields can be edited from here; the view refreshes once the edit
+// is staged.
+func (ui *Gui) showAccountProvenance(accountID int) {
+	provenance, err := ui.app.AccountFieldProvenance(accountID)
+	if err != nil {
+		ui.ShowDetails(NewWrappingLabel(fmt.Sprintf("Could not read account %d: %v", accountID, err)))
+		return
+	}
+
+	title := fmt.Sprintf("%s (account %d)", provenance.Name, accountID)
+	summary := "Every field matches the last pull."
+	if n := provenance.LocalCount(); n > 0 {
+		summary = fmt.Sprintf("%d field(s) edited locally and not yet in BadgerMaps.", n)
+	}
+	if provenance.PulledAt != "" {
+		summary += fmt.Sprintf(" Last pulled %s.", provenance.PulledAt)
+	}
+	refreshBtn := widget.NewButtonWithIcon("Pull with Check-ins & Routes", theme.DownloadIcon(), func() {
+		ui.presenter.HandlePullAccountBundle(accountID, func() {
+			fyne.Do(func() { ui.showAccountProvenance(accountID) })
+		})
+	})
+	rows := container.NewVBox(
+		widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		NewWrappingLabel(summary),
+		container.NewHBox(refreshBtn),
+	)
+	if stale, err := ui.app.AccountStale(accountID); err == nil && stale {
+		notice := widget.NewLabel("Changed in BadgerMaps since a low-bandwidth pull; fetching its details…")
+		notice.Wrapping = fyne.TextWrapWord
+		notice.Importance = widget.WarningImportance
+		rows.Add(notice)
+		ui.fetchStaleAccount(accountID)
+	}
+	if provenance.PendingDelete {
+		warning := widget.NewLabel("A delete of this account is waiting to be pushed.")
+		warning.Importance = widget.DangerImportance
+		rows.Add(warning)
+	}
+	rows.Add(widget.NewSeparator())
+
+	hidden := ui.app.HiddenAccountColumns()
+	for _, field := range provenance.Fields {
+		f := field
+		if f.Source == app.ProvenancePulled && f.Pulled == "" && f.Label == f.Column && isCustomColumn(f.Column) {
+			// Unused custom fields would bury the rest.
+			continue
+		}
+		if f.Source == app.ProvenancePulled && hidden[f.Column] {
+			// Not pulled, so always empty.
+			continue
+		}
+		marker := widget.NewLabel("Pulled")
+		marker.Importance = widget.LowImportance
+		values := container.NewVBox()
+		if f.Source == app.ProvenanceLocal {
+			marker.SetText(fmt.Sprintf("● Local edit (%s, change %d)", f.Status, f.ChangeID))
+			marker.Importance = widget.WarningImportance
+			values.Add(diffValueLabel("BadgerMaps: ", emptyAsDash(f.Pulled), widget.MediumImportance))
+			values.Add(diffValueLabel("Local: ", emptyAsDash(f.Staged), widget.WarningImportance))
+		} else {
+			values.Add(diffValueLabel("", emptyAsDash(f.Pulled), widget.MediumImportance))
+		}
+		header := container.NewHBox(widget.NewLabelWithStyle(f.Label, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), marker)
+		editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+			ui.showAccountFieldEditor(accountID, f.Column, f.Value(), f.Value(), func() {
+				ui.showAccountProvenance(accountID)
+			})
+		})
+		rows.Add(container.NewBorder(header, nil, nil, editBtn, values))
+		rows.Add(widget.NewSeparator())
+	}
+	ui.ShowDetails(container.NewVScroll(rows))
+}
+
+// fetchStaleAccount fetches the details of a stale account once per
+// session and shows the account again when they are stored.
+func (ui *Gui) fetchStaleAccount(accountID int) {
+	if ui.fetchedStaleAccounts[accountID] {
+		return
+	}
+	ui.fetchedStaleAccounts[accountID] = true
+	ui.presenter.HandleFetchStaleAccount(accountID, func() {
+		fyne.Do(func() { ui.showAccountProvenance(accountID) })
+	})
+}
+
+func isCustomColumn(column string) bool {
+	return strings.HasPrefix(column, "Custom")
+}
+
+func emptyAsDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
@@ -0,0 +1,166 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+// HandleShowActionRuns shows the latest runs of the event action named name
+// in the details pane, or of every action when name is empty.
+func (p *GuiPresenter) HandleShowActionRuns(name string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowActionRuns called for %q", name))
+	if p.app.DB == nil || !p.app.DB.IsConnected() {
+		p.view.ShowErrorDialog(fmt.Errorf("database is not connected"))
+		return
+	}
+	p.view.ShowDetails(p.actionRunsView(name))
+}
+
+// HandleRerunAction asks for confirmation, runs a recorded action again
+// with its recorded event, and shows the new run.
+func (p *GuiPresenter) HandleRerunAction(run database.ActionRun) {
+	message := fmt.Sprintf("Run this %s action again with the event it was triggered by? Commands and queries run again in full.", run.ActionType)
+	p.view.ShowConfirmDialog("Re-run Action?", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		p.view.ShowProgressBar("Re-running " + run.ActionType + " action...")
+		go func() {
+			rerun, err := p.app.RerunAction(run.RunId)
+			fyne.Do(func() {
+				p.view.HideProgressBar()
+				if err != nil {
+					p.view.ShowToast("Error: the action failed.")
+				} else {
+					p.view.ShowToast("Success: the action finished.")
+				}
+				if rerun.ActionType == "" {
+					// The run could not start, so nothing was recorded.
+					p.view.ShowErrorDialog(err)
+					return
+				}
+				p.view.ShowDetails(p.actionRunDetails(rerun))
+			})
+		}()
+	})
+}
+
+// actionRunsView lists the latest runs, newest first, each with its result
+// and a Details button.
+func (p *GuiPresenter) actionRunsView(name string) fyne.CanvasObject {
+	title := "Action Runs"
+	if name != "" {
+		title = "Runs of " + name
+	}
+	rows := container.NewVBox()
+	load := func() {
+		rows.Objects = nil
+		runs, err := p.app.ActionRuns(name, database.DefaultActionRunLimit)
+		switch {
+		case err != nil:
+			rows.Add(NewWrappingLabel(fmt.Sprintf("Error reading the action runs: %v", err)))
+		case len(runs) == 0:
+			rows.Add(widget.NewLabel("No runs recorded yet."))
+		}
+		for _, run := range runs {
+			rows.Add(p.actionRunRow(run))
+		}
+		rows.Refresh()
+	}
+	load()
+
+	header := container.NewVBox(
+		container.NewBorder(nil, nil, nil,
+			widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), load),
+			widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		),
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(rows))
+}
+
+func (p *GuiPresenter) actionRunRow(run database.ActionRun) fyne.CanvasObject {
+	summary := fmt.Sprintf("#%d %s, %s by %s", run.RunId, run.ActionType, run.Status, run.TriggeredBy)
+	if run.ActionName != "" {
+		summary = fmt.Sprintf("#%d %s (%s), %s by %s", run.RunId, run.ActionName, run.ActionType, run.Status, run.TriggeredBy)
+	}
+	label := widget.NewLabel(summary)
+	if run.Status == database.ActionRunFailed {
+		label.Importance = widget.DangerImportance
+	}
+	lines := container.NewVBox(label, widget.NewLabel(p.actionRunTiming(run)))
+	details := widget.NewButtonWithIcon("Details", theme.InfoIcon(), func() {
+		p.view.ShowDetails(p.actionRunDetails(run))
+	})
+	return container.NewBorder(nil, nil, nil, container.NewCenter(details), lines)
+}
+
+// actionRunTiming is when a run started, how long it took, and its exit
+// code or row count.
+func (p *GuiPresenter) actionRunTiming(run database.ActionRun) string {
+	text := fmt.Sprintf("%s, %s", run.StartedAt.In(p.app.DisplayLocation()).Format("2006-01-02 15:04:05"), run.Duration.Round(time.Millisecond))
+	if run.ExitCode != nil {
+		text += fmt.Sprintf(", exit code %d", *run.ExitCode)
+	}
+	if run.RowsAffected != nil && *run.RowsAffected >= 0 {
+		text += fmt.Sprintf(", %d rows", *run.RowsAffected)
+	}
+	return text
+}
+
+// actionRunDetails shows a run's rendered arguments, output, and error,
+// with a Re-run button when the config was recorded.
+func (p *GuiPresenter) actionRunDetails(run database.ActionRun) fyne.CanvasObject {
+	header := container.NewVBox(
+		widget.NewLabelWithStyle(fmt.Sprintf("Run #%d: %s", run.RunId, run.ActionType), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(p.actionRunTiming(run)),
+	)
+	trigger := "Triggered by " + run.TriggeredBy
+	if run.Source != "" {
+		trigger += " from " + run.Source
+	}
+	if run.RerunOf > 0 {
+		trigger += fmt.Sprintf(", repeating run #%d", run.RerunOf)
+	}
+	header.Add(NewWrappingLabel(trigger))
+	if run.ErrorMessage != "" {
+		errLabel := widget.NewLabel(run.ErrorMessage)
+		errLabel.Wrapping = fyne.TextWrapWord
+		errLabel.Importance = widget.DangerImportance
+		header.Add(errLabel)
+	}
+	if run.Config != "" {
+		rerun := widget.NewButtonWithIcon("Re-run", theme.MediaReplayIcon(), func() {
+			p.HandleRerunAction(run)
+		})
+		header.Add(container.NewHBox(rerun))
+	}
+	header.Add(widget.NewSeparator())
+
+	body := container.NewVBox()
+	for _, section := range []struct{ title, text string }{
+		{"Arguments", run.Args},
+		{"Output", run.Output},
+		{"Event", run.Event},
+	} {
+		if strings.TrimSpace(section.text) == "" {
+			continue
+		}
+		text := widget.NewLabelWithStyle(section.text, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		text.Wrapping = fyne.TextWrapWord
+		body.Add(widget.NewLabelWithStyle(section.title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		body.Add(text)
+	}
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(body))
+}
@@ -0,0 +1,41 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// alertBannerColor is the red behind the alert banner.
+var alertBannerColor = color.NRGBA{R: 0xc6, G: 0x28, B: 0x28, A: 0xff}
+
+// refreshAlertBanner shows the alerts firing as of the last check across
+// the top of the window, and hides the banner when none are.
+func (ui *Gui) refreshAlertBanner() {
+	if ui.alertBanner == nil {
+		return
+	}
+	alerts := ui.app.ActiveAlerts()
+	if len(alerts) == 0 {
+		ui.alertBanner.Objects = nil
+		ui.alertBanner.Hide()
+		return
+	}
+	texts := make([]fyne.CanvasObject, len(alerts))
+	for i, alert := range alerts {
+		line := fmt.Sprintf("Alert since %s: %s", alert.Since.In(ui.app.DisplayLocation()).Format("Jan 2 15:04"), alert.Message)
+		text := canvas.NewText(line, color.White)
+		text.TextStyle = fyne.TextStyle{Bold: true}
+		text.Alignment = fyne.TextAlignCenter
+		texts[i] = text
+	}
+	background := canvas.NewRectangle(alertBannerColor)
+	ui.alertBanner.Objects = []fyne.CanvasObject{background, container.NewPadded(container.NewVBox(texts...))}
+	ui.alertBanner.Show()
+	ui.alertBanner.Refresh()
+}
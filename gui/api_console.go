@@ -0,0 +1,246 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/events"
+)
+
+// HandleShowAPIConsole shows the API console in the details pane.
+func (p *GuiPresenter) HandleShowAPIConsole() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowAPIConsole called"))
+	p.view.ShowDetails(p.apiConsoleView())
+}
+
+// HandleSendAPIRequest sends req from the API console, asking first when
+// it changes data, and passes the response to onResponse on the UI
+// goroutine.
+func (p *GuiPresenter) HandleSendAPIRequest(req api.ConsoleRequest, onResponse func(*api.ConsoleResponse, error)) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSendAPIRequest called for %s %s", req.Method, req.Path))
+	send := func() {
+		p.view.ShowProgressBar(fmt.Sprintf("Sending %s %s...", req.Method, req.Path))
+		go func() {
+			resp, err := p.app.CallAPI(req)
+			fyne.Do(func() {
+				p.view.HideProgressBar()
+				if onResponse != nil {
+					onResponse(resp, err)
+				}
+			})
+		}()
+	}
+	if strings.EqualFold(req.Method, "GET") {
+		send()
+		return
+	}
+	message := fmt.Sprintf("Send %s %s? This changes data in BadgerMaps.", strings.ToUpper(req.Method), req.Path)
+	p.view.ShowConfirmDialog("Send Request?", message, func(ok bool) {
+		if ok {
+			send()
+		}
+	})
+}
+
+// apiConsoleView composes requests to the BadgerMaps API with the paths
+// completing from the known endpoints, and shows the responses.
+func (p *GuiPresenter) apiConsoleView() fyne.CanvasObject {
+	method := widget.NewSelect(api.ConsoleMethods, nil)
+	method.SetSelected("GET")
+
+	hint := widget.NewLabel("")
+	hint.Wrapping = fyne.TextWrapWord
+	params := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	params.Wrapping = fyne.TextWrapWord
+	path := widget.NewSelectEntry(nil)
+	path.SetPlaceHolder("customers/42/")
+	showHint := func(text string) {
+		var endpoints []api.EndpointInfo
+		if e, ok := api.LookupEndpoint(text); ok {
+			endpoints = []api.EndpointInfo{e}
+		} else {
+			endpoints = api.MatchEndpoints(text)
+		}
+		options := make([]string, 0, len(endpoints))
+		for _, e := range endpoints {
+			options = append(options, e.Path)
+		}
+		path.SetOptions(options)
+		switch len(endpoints) {
+		case 0:
+			hint.SetText("No known endpoint matches this path.")
+			params.SetText("")
+		case 1:
+			e := endpoints[0]
+			hint.SetText(e.Hint() + ": " + e.Description)
+			var lines []string
+			for _, param := range e.Params {
+				if param.Description != "" {
+					lines = append(lines, fmt.Sprintf("%s (%s): %s", param.Name, param.In, param.Description))
+				}
+			}
+			params.SetText(strings.Join(lines, "\n"))
+		default:
+			hint.SetText(fmt.Sprintf("%d endpoints match; pick one from the list.", len(endpoints)))
+			params.SetText("")
+		}
+	}
+	path.OnChanged = showHint
+	showHint("")
+
+	query := widget.NewMultiLineEntry()
+	query.SetPlaceHolder("key=value, one per line")
+	query.SetMinRowsVisible(2)
+	form := widget.NewMultiLineEntry()
+	form.SetPlaceHolder("key=value, one per line (POST and PATCH)")
+	form.SetMinRowsVisible(3)
+
+	status := widget.NewLabel("")
+	body := widget.NewMultiLineEntry()
+	body.TextStyle = fyne.TextStyle{Monospace: true}
+	body.Wrapping = fyne.TextWrapBreak
+	body.SetMinRowsVisible(12)
+	var lastBody string
+	body.OnChanged = func(text string) {
+		// The response is read-only but stays selectable, which a disabled
+		// entry is not.
+		if text != lastBody {
+			body.SetText(lastBody)
+		}
+	}
+
+	request := func() (api.ConsoleRequest, error) {
+		req := api.ConsoleRequest{Method: method.Selected, Path: strings.TrimSpace(path.Text)}
+		var err error
+		if req.Query, err = app.ParseAPIParams(strings.Split(query.Text, "\n")); err != nil {
+			return req, fmt.Errorf("query: %w", err)
+		}
+		if req.Form, err = app.ParseAPIParams(strings.Split(form.Text, "\n")); err != nil {
+			return req, fmt.Errorf("form: %w", err)
+		}
+		return req, nil
+	}
+
+	send := widget.NewButtonWithIcon("Send", theme.MailSendIcon(), func() {
+		req, err := request()
+		if err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		p.HandleSendAPIRequest(req, func(resp *api.ConsoleResponse, err error) {
+			if err != nil {
+				status.Importance = widget.DangerImportance
+				status.SetText("Error: " + err.Error())
+				lastBody = ""
+				body.SetText("")
+				return
+			}
+			status.Importance = widget.SuccessImportance
+			if resp.StatusCode >= 400 {
+				status.Importance = widget.DangerImportance
+			}
+			status.SetText(fmt.Sprintf("%s in %s", resp.Status, resp.Duration.Round(time.Millisecond)))
+			lastBody = resp.PrettyBody()
+			body.SetText(lastBody)
+		})
+	})
+	send.Importance = widget.HighImportance
+	copyBody := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		fyne.CurrentApp().Clipboard().SetContent(lastBody)
+		p.view.ShowToast("Response copied.")
+	})
+
+	var templates *widget.Select
+	loadTemplates := func() {
+		var names []string
+		for _, t := range p.app.APITemplates() {
+			names = append(names, t.Name)
+		}
+		templates.SetOptions(names)
+	}
+	templates = widget.NewSelect(nil, func(name string) {
+		t, err := p.app.APITemplate(name)
+		if err != nil {
+			return
+		}
+		req := t.Request()
+		method.SetSelected(req.Method)
+		path.SetText(req.Path)
+		query.SetText(app.FormatAPIParams(req.Query))
+		form.SetText(app.FormatAPIParams(req.Form))
+	})
+	templates.PlaceHolder = "Saved requests"
+	loadTemplates()
+
+	saveTemplate := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		req, err := request()
+		if err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		name := widget.NewEntry()
+		name.SetText(templates.Selected)
+		name.SetPlaceHolder("e.g. Acme check-ins")
+		items := []*widget.FormItem{widget.NewFormItem("Name", name)}
+		dialog.ShowForm("Save Request", "Save", "Cancel", items, func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := p.app.SaveAPITemplate(app.NewAPIRequestTemplate(name.Text, req)); err != nil {
+				p.view.ShowErrorDialog(err)
+				return
+			}
+			loadTemplates()
+			templates.Selected = strings.TrimSpace(name.Text)
+			templates.Refresh()
+			p.view.ShowToast("Request saved.")
+		}, p.view.GetMainWindow())
+	})
+	deleteTemplate := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		name := templates.Selected
+		if name == "" {
+			return
+		}
+		p.view.ShowConfirmDialog("Delete Saved Request?", fmt.Sprintf("Delete the saved request %q?", name), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := p.app.DeleteAPITemplate(name); err != nil {
+				p.view.ShowErrorDialog(err)
+				return
+			}
+			templates.ClearSelected()
+			loadTemplates()
+		})
+	})
+
+	header := container.NewVBox(
+		widget.NewLabelWithStyle("API Console", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		NewWrappingLabel("Send requests to the BadgerMaps API with the configured key. POST, PATCH, and DELETE change live data and ask first."),
+		container.NewBorder(nil, nil, nil, container.NewHBox(saveTemplate, deleteTemplate), templates),
+		widget.NewSeparator(),
+	)
+	compose := container.NewVBox(
+		container.NewBorder(nil, nil, method, send, path),
+		hint,
+		params,
+		widget.NewForm(
+			widget.NewFormItem("Query", query),
+			widget.NewFormItem("Form", form),
+		),
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, nil, copyBody, status),
+	)
+	return container.NewBorder(container.NewVBox(header, compose), nil, nil, nil, body)
+}
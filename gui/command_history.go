@@ -0,0 +1,165 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+// commandHistoryPeriods are the time filters of the history view.
+var commandHistoryPeriods = []struct {
+	label string
+	age   time.Duration
+}{
+	{"Any time", 0},
+	{"Last 24 hours", 24 * time.Hour},
+	{"Last 7 days", 7 * 24 * time.Hour},
+	{"Last 30 days", 30 * 24 * time.Hour},
+}
+
+// HandleShowCommandHistory shows the recorded commands in the details pane.
+func (p *GuiPresenter) HandleShowCommandHistory() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowCommandHistory called"))
+	if p.app.DB == nil || !p.app.DB.IsConnected() {
+		p.view.ShowErrorDialog(fmt.Errorf("database is not connected"))
+		return
+	}
+	p.view.ShowDetails(p.commandHistoryView())
+}
+
+// HandleRerunCommand runs a recorded command again and shows its output.
+func (p *GuiPresenter) HandleRerunCommand(entry database.CommandLogEntry) {
+	p.app.Events.Dispatch(events.Infof("presenter", "Re-running %s", app.CommandLine(entry)))
+	p.view.ShowProgressBar("Running " + entry.Command + "...")
+	go func() {
+		var output bytes.Buffer
+		err := p.app.RerunCommand(context.Background(), entry.LogId, &output, &output)
+		fyne.Do(func() {
+			p.view.HideProgressBar()
+			if err != nil {
+				p.app.Events.Dispatch(events.Errorf("presenter", "Re-run of %s failed: %v", entry.Command, err))
+				p.view.ShowToast("Error: " + entry.Command + " failed.")
+			} else {
+				p.view.ShowToast("Success: " + entry.Command + " finished.")
+			}
+			p.view.ShowDetails(commandOutputView(entry, output.String(), err))
+		})
+	}()
+}
+
+// commandHistoryView lists recorded commands, newest first, with filters by
+// command, result, and time, and a Re-run button on safe commands.
+func (p *GuiPresenter) commandHistoryView() fyne.CanvasObject {
+	commandEntry := widget.NewEntry()
+	commandEntry.SetPlaceHolder("Command, e.g. pull")
+	statusSelect := widget.NewSelect([]string{"All results", "Succeeded", "Failed"}, nil)
+	statusSelect.SetSelected("All results")
+	periods := make([]string, len(commandHistoryPeriods))
+	for i, period := range commandHistoryPeriods {
+		periods[i] = period.label
+	}
+	periodSelect := widget.NewSelect(periods, nil)
+	periodSelect.SetSelected(periods[0])
+
+	rows := container.NewVBox()
+	load := func() {
+		filter := database.CommandLogFilter{Command: strings.TrimSpace(commandEntry.Text)}
+		switch statusSelect.Selected {
+		case "Succeeded", "Failed":
+			succeeded := statusSelect.Selected == "Succeeded"
+			filter.Success = &succeeded
+		}
+		for _, period := range commandHistoryPeriods {
+			if period.label == periodSelect.Selected && period.age > 0 {
+				filter.Since = time.Now().Add(-period.age)
+			}
+		}
+		rows.Objects = nil
+		entries, err := p.app.CommandHistory(filter)
+		switch {
+		case err != nil:
+			rows.Add(NewWrappingLabel(fmt.Sprintf("Error reading the command history: %v", err)))
+		case len(entries) == 0:
+			rows.Add(widget.NewLabel("No commands found."))
+		}
+		for _, entry := range entries {
+			rows.Add(p.commandHistoryRow(entry))
+		}
+		rows.Refresh()
+	}
+	commandEntry.OnSubmitted = func(string) { load() }
+	statusSelect.OnChanged = func(string) { load() }
+	periodSelect.OnChanged = func(string) { load() }
+	load()
+
+	filters := container.NewBorder(nil, nil, nil,
+		widget.NewButtonWithIcon("", theme.SearchIcon(), load),
+		container.NewGridWithColumns(3, commandEntry, statusSelect, periodSelect),
+	)
+	header := container.NewVBox(
+		widget.NewLabelWithStyle("Command History", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		filters,
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(rows))
+}
+
+func (p *GuiPresenter) commandHistoryRow(entry database.CommandLogEntry) fyne.CanvasObject {
+	status := "succeeded"
+	if !entry.Success {
+		status = "failed"
+	}
+	details := fmt.Sprintf("#%d, %s, %s", entry.LogId, entry.Timestamp.In(p.app.DisplayLocation()).Format("2006-01-02 15:04:05"), status)
+	if entry.Duration > 0 {
+		details += fmt.Sprintf(" in %s", entry.Duration.Round(time.Millisecond))
+	}
+	lines := container.NewVBox(
+		widget.NewLabelWithStyle(app.CommandLine(entry), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true}),
+		widget.NewLabel(details),
+	)
+	if entry.ErrorMessage != "" {
+		errLabel := widget.NewLabel(entry.ErrorMessage)
+		errLabel.Wrapping = fyne.TextWrapWord
+		errLabel.Importance = widget.DangerImportance
+		lines.Add(errLabel)
+	}
+	if !app.CanRerun(entry) {
+		return lines
+	}
+	rerun := widget.NewButtonWithIcon("Re-run", theme.MediaReplayIcon(), func() {
+		p.HandleRerunCommand(entry)
+	})
+	return container.NewBorder(nil, nil, nil, container.NewCenter(rerun), lines)
+}
+
+// commandOutputView shows what a re-run command printed.
+func commandOutputView(entry database.CommandLogEntry, output string, err error) fyne.CanvasObject {
+	title := widget.NewLabelWithStyle(app.CommandLine(entry), fyne.TextAlignLeading, fyne.TextStyle{Bold: true, Monospace: true})
+	if strings.TrimSpace(output) == "" {
+		output = "(no output)"
+	}
+	text := widget.NewLabelWithStyle(output, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	text.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(title)
+	if err != nil {
+		errLabel := widget.NewLabel(err.Error())
+		errLabel.Wrapping = fyne.TextWrapWord
+		errLabel.Importance = widget.DangerImportance
+		content.Add(errLabel)
+	}
+	content.Add(widget.NewSeparator())
+	return container.NewBorder(content, nil, nil, nil, container.NewVScroll(text))
+}
@@ -0,0 +1,202 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/events"
+)
+
+// Choices of the conflict dialog's per-field radio groups.
+const (
+	conflictKeepLocal  = "Local"
+	conflictKeepRemote = "Remote"
+)
+
+// conflictResolutionLabels maps the Configuration tab's conflict radio to
+// conflict_resolution.
+var conflictResolutionLabels = map[string]string{
+	"Always use local changes":  app.ConflictLocal,
+	"Always use remote changes": app.ConflictRemote,
+	"Use most recent":           app.ConflictRecent,
+	"Ask every time":            app.ConflictAsk,
+}
+
+func conflictResolutionLabel(strategy string) string {
+	for label, value := range conflictResolutionLabels {
+		if value == strategy {
+			return label
+		}
+	}
+	return "Ask every time"
+}
+
+// showAccountConflicts asks how to resolve each conflicting account change
+// in turn. Changes whose every field has a choice remembered this session
+// are resolved without asking.
+func (ui *Gui) showAccountConflicts() {
+	conflicts, err := ui.app.AccountConflicts()
+	if err != nil {
+		ui.ShowErrorDialog(err)
+		return
+	}
+	if len(conflicts) == 0 {
+		ui.ShowToast("No conflicting changes to resolve.")
+		return
+	}
+	ui.resolveNextConflict(conflicts)
+}
+
+func (ui *Gui) resolveNextConflict(conflicts []app.AccountConflict) {
+	for len(conflicts) > 0 {
+		conflict := conflicts[0]
+		conflicts = conflicts[1:]
+		if choices, ok := ui.rememberedConflictChoices(conflict); ok {
+			ui.applyConflictChoices(conflict, choices)
+			continue
+		}
+		ui.showConflictDialog(conflict, len(conflicts), func() { ui.resolveNextConflict(conflicts) })
+		return
+	}
+	if ui.refreshPendingChanges != nil {
+		ui.refreshPendingChanges()
+	}
+}
+
+// rememberedConflictChoices returns the remembered choice of every
+// differing field of conflict, and false when a field has none.
+func (ui *Gui) rememberedConflictChoices(conflict app.AccountConflict) (map[string]bool, bool) {
+	choices := make(map[string]bool, len(conflict.Fields))
+	for _, field := range conflict.Fields {
+		keepLocal, ok := ui.conflictChoices[field.Field]
+		if !ok {
+			return nil, false
+		}
+		choices[field.Field] = keepLocal
+	}
+	return choices, true
+}
+
+func (ui *Gui) applyConflictChoices(conflict app.AccountConflict, choices map[string]bool) {
+	kept, err := ui.app.ResolveAccountConflict(conflict.Change.ChangeId, choices)
+	if err != nil {
+		ui.app.Events.Dispatch(events.Errorf("gui", "Could not resolve the conflict of change %d: %v", conflict.Change.ChangeId, err))
+		return
+	}
+	if kept {
+		ui.app.Events.Dispatch(events.Infof("gui", "Resolved the conflict of change %d; it is queued for the next push", conflict.Change.ChangeId))
+	} else {
+		ui.app.Events.Dispatch(events.Infof("gui", "Resolved the conflict of change %d by keeping the remote values", conflict.Change.ChangeId))
+	}
+}
+
+// showConflictDialog shows the differing fields of conflict side by side
+// with a Local or Remote pick for each. Up and Down move between fields, L
+// and R pick, and Enter applies. remaining is how many conflicts follow;
+// next is called once this one is applied or skipped.
+func (ui *Gui) showConflictDialog(conflict app.AccountConflict, remaining int, next func()) {
+	change := conflict.Change
+	selected := 0
+	markers := make([]*widget.Label, len(conflict.Fields))
+	radios := make([]*widget.RadioGroup, len(conflict.Fields))
+
+	grid := container.NewGridWithColumns(5,
+		widget.NewLabel(""),
+		widget.NewLabelWithStyle("Field", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Local (staged)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Remote", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Keep", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	for i, field := range conflict.Fields {
+		markers[i] = widget.NewLabel("")
+		radios[i] = widget.NewRadioGroup([]string{conflictKeepLocal, conflictKeepRemote}, nil)
+		radios[i].Horizontal = true
+		radios[i].Required = true
+		radios[i].SetSelected(conflictKeepLocal)
+		if keepLocal, ok := ui.conflictChoices[field.Field]; ok && !keepLocal {
+			radios[i].SetSelected(conflictKeepRemote)
+		}
+		grid.Add(markers[i])
+		grid.Add(NewWrappingLabel(field.Label))
+		grid.Add(diffValueLabel("", field.New, widget.SuccessImportance))
+		grid.Add(diffValueLabel("", field.Old, widget.DangerImportance))
+		grid.Add(radios[i])
+	}
+	selectField := func(i int) {
+		if i < 0 || i >= len(markers) {
+			return
+		}
+		markers[selected].SetText("")
+		selected = i
+		markers[selected].SetText("▶")
+	}
+	if len(markers) > 0 {
+		selectField(0)
+	}
+
+	remember := widget.NewCheck("Remember these choices for each field this session", nil)
+	account := fmt.Sprintf("Account %d", change.AccountId)
+	if names, err := ui.app.AccountDisplayNames([]int{change.AccountId}); err == nil && names[change.AccountId] != "" {
+		account = fmt.Sprintf("%s (account %d)", names[change.AccountId], change.AccountId)
+	}
+	summary := fmt.Sprintf("%s was changed in BadgerMaps after change %d (%s) was staged on %s. Pick which value to keep for each field.",
+		account, change.ChangeId, change.ChangeType, change.CreatedAt.In(ui.app.DisplayLocation()).Format("Jan 2 15:04"))
+	if len(conflict.Fields) == 0 {
+		summary = fmt.Sprintf("%s was changed in BadgerMaps after change %d was staged, and its values now match the staged ones. Applying discards the change.", account, change.ChangeId)
+	}
+	footer := "Keys: Up/Down select a field, L/R pick local or remote, Enter applies."
+	if remaining > 0 {
+		footer = fmt.Sprintf("%s %d more conflict(s) follow.", footer, remaining)
+	}
+	content := container.NewBorder(
+		NewWrappingLabel(summary),
+		container.NewVBox(remember, widget.NewLabelWithStyle(footer, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})),
+		nil, nil,
+		container.NewVScroll(grid),
+	)
+
+	canvas := ui.window.Canvas()
+	previousOnTypedKey := canvas.OnTypedKey()
+	var dlg *dialog.ConfirmDialog
+	dlg = dialog.NewCustomConfirm("Resolve Conflict", "Apply", "Skip", content, func(apply bool) {
+		canvas.SetOnTypedKey(previousOnTypedKey)
+		if apply {
+			choices := make(map[string]bool, len(conflict.Fields))
+			for i, field := range conflict.Fields {
+				choices[field.Field] = radios[i].Selected == conflictKeepLocal
+				if remember.Checked {
+					ui.conflictChoices[field.Field] = choices[field.Field]
+				}
+			}
+			ui.applyConflictChoices(conflict, choices)
+		}
+		next()
+	}, ui.window)
+	canvas.SetOnTypedKey(func(key *fyne.KeyEvent) {
+		switch key.Name {
+		case fyne.KeyUp:
+			selectField(selected - 1)
+		case fyne.KeyDown:
+			selectField(selected + 1)
+		case fyne.KeyL:
+			if len(radios) > 0 {
+				radios[selected].SetSelected(conflictKeepLocal)
+			}
+		case fyne.KeyR:
+			if len(radios) > 0 {
+				radios[selected].SetSelected(conflictKeepRemote)
+			}
+		case fyne.KeyReturn, fyne.KeyEnter:
+			dlg.Confirm()
+		}
+	})
+	dlg.Resize(fyne.NewSize(760, 460))
+	dlg.Show()
+}
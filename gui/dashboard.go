@@ -1,6 +1,10 @@
+//go:build !nogui
+
 package gui
 
 import (
+	"badgermaps/app"
+	"badgermaps/app/action"
 	"badgermaps/database"
 	"badgermaps/events"
 	"fmt"
@@ -19,6 +23,8 @@ import (
 type SmartDashboard struct {
 	ui        *Gui
 	presenter *GuiPresenter
+	// subs refresh the dashboard when a sync finishes, for the current build.
+	subs viewSubscriptions
 }
 
 // NewSmartDashboard creates a new smart dashboard
@@ -31,6 +37,15 @@ func NewSmartDashboard(ui *Gui, presenter *GuiPresenter) *SmartDashboard {
 
 // CreateContent builds the smart dashboard interface
 func (d *SmartDashboard) CreateContent() fyne.CanvasObject {
+	// Counts and the last sync go stale when a pull or push finishes. The
+	// listeners of the previous build are dropped so a rebuild does not add
+	// another refresh.
+	d.subs.reset()
+	d.subs.add(
+		d.ui.app.Events.Subscribe("pull.*", d.onSyncFinished),
+		d.ui.app.Events.Subscribe("push.complete", d.onSyncFinished),
+	)
+
 	// Header with greeting and status
 	header := d.createHeader()
 
@@ -69,6 +84,18 @@ func (d *SmartDashboard) CreateContent() fyne.CanvasObject {
 		widget.NewSeparator(),
 		insights,
 	)
+	if quality := d.createDataQuality(); quality != nil {
+		mainContent.Add(widget.NewSeparator())
+		mainContent.Add(quality)
+	}
+	if ranking := d.createScoreRanking(); ranking != nil {
+		mainContent.Add(widget.NewSeparator())
+		mainContent.Add(ranking)
+	}
+	if team := d.createTeamSection(); team != nil {
+		mainContent.Add(widget.NewSeparator())
+		mainContent.Add(team)
+	}
 
 	return container.NewVScroll(container.NewBorder(
 		header,
@@ -78,6 +105,14 @@ func (d *SmartDashboard) CreateContent() fyne.CanvasObject {
 	))
 }
 
+// onSyncFinished rebuilds the dashboard once a pull or push has finished.
+func (d *SmartDashboard) onSyncFinished(e events.Event) {
+	switch e.Type {
+	case "pull.complete", "pull.group.complete", "push.complete":
+		d.ui.refresh.Request("Home", "home", d.ui.RefreshHomeTab)
+	}
+}
+
 func (d *SmartDashboard) createHeader() fyne.CanvasObject {
 	// Dynamic greeting based on time of day
 	hour := time.Now().Hour()
@@ -91,7 +126,12 @@ func (d *SmartDashboard) createHeader() fyne.CanvasObject {
 	greetingLabel := widget.NewLabelWithStyle(greeting+"! Here's your sync overview:",
 		fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 
-	return container.NewVBox(greetingLabel)
+	tourButton := widget.NewButtonWithIcon("Take the Tour", theme.HelpIcon(), func() {
+		d.ui.replayTour(app.OnboardingTourID)
+	})
+	tourButton.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil, nil, tourButton, greetingLabel)
 }
 
 func (d *SmartDashboard) createStatusCards() fyne.CanvasObject {
@@ -100,6 +140,8 @@ func (d *SmartDashboard) createStatusCards() fyne.CanvasObject {
 	apiHeadline := "Not Connected"
 	if apiConnected {
 		apiHeadline = "Connected"
+	} else if d.ui.app.Connections().Status().APIChecking {
+		apiHeadline = "Checking..."
 	}
 	apiCard := d.createConnectionCard(
 		"API Connection",
@@ -112,12 +154,15 @@ func (d *SmartDashboard) createStatusCards() fyne.CanvasObject {
 	dbConnected := d.ui.app.DB != nil && d.ui.app.DB.IsConnected()
 	var dbStatusDetail string
 	if dbConnected {
-		// Check schema status
-		if err := d.ui.app.DB.ValidateSchema(d.ui.app.State); err == nil {
+		// The schema check is cached and runs in the background; the card
+		// is rebuilt when its result arrives.
+		switch status, _ := d.ui.app.Connections().Schema(); status {
+		case app.SchemaValid:
 			dbStatusDetail = "Connected (Schema Valid)"
-		} else {
+		case app.SchemaInvalid:
 			dbStatusDetail = "Connected (Schema Invalid)"
-			d.ui.app.Events.Dispatch(events.Debugf("dashboard", "Schema validation failed: %v", err))
+		default:
+			dbStatusDetail = "Connected (Checking Schema...)"
 		}
 	} else {
 		dbStatusDetail = "Not Connected"
@@ -271,15 +316,15 @@ func (d *SmartDashboard) getRecentActivities() []ActivityItem {
 			Type:    "connection",
 		})
 
-		// Check if schema is valid
-		if err := d.ui.app.DB.ValidateSchema(d.ui.app.State); err == nil {
+		switch status, _ := d.ui.app.Connections().Schema(); status {
+		case app.SchemaValid:
 			activities = append(activities, ActivityItem{
 				Icon:    theme.ConfirmIcon(),
 				Message: "Database schema validation passed",
 				Time:    "System startup",
 				Type:    "validation",
 			})
-		} else {
+		case app.SchemaInvalid:
 			activities = append(activities, ActivityItem{
 				Icon:    theme.WarningIcon(),
 				Message: "Database schema validation failed",
@@ -414,10 +459,15 @@ func (d *SmartDashboard) getSystemStatistics() []SystemStat {
 
 		// Get total check-ins
 		if checkinCount := d.getTableRowCount("AccountCheckins"); checkinCount >= 0 {
+			description := "Records in database"
+			if index, err := d.ui.app.CheckinArchiveIndex(); err == nil && index.Count() > 0 {
+				checkinCount += index.Count()
+				description = fmt.Sprintf("%d archived", index.Count())
+			}
 			stats = append(stats, SystemStat{
 				Label:       "Total Check-ins",
 				Value:       fmt.Sprintf("%d", checkinCount),
-				Description: "Records in database",
+				Description: description,
 			})
 		}
 
@@ -460,7 +510,7 @@ func (d *SmartDashboard) getTableRowCount(tableName string) int {
 		return -1
 	}
 
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", database.QualifiedName(d.ui.app.DB, tableName))
 	rows, err := d.ui.app.DB.ExecuteQuery(query)
 	if err != nil {
 		d.ui.app.Events.Dispatch(events.Debugf("dashboard", "Error counting rows in %s: %v", tableName, err))
@@ -542,6 +592,10 @@ func (d *SmartDashboard) getLastSyncInfo() LastSyncInfo {
 	var inProgress *database.SyncHistoryEntry
 	for i := range entries {
 		entry := entries[i]
+		// Digests report on syncs; they are not syncs themselves.
+		if entry.RunType == action.DigestRunType {
+			continue
+		}
 		switch strings.ToLower(entry.Status) {
 		case "running":
 			if inProgress == nil {
@@ -0,0 +1,134 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"strconv"
+	"strings"
+)
+
+// dataQualityStat is a dashboard stat that opens a filtered Explorer view
+// when clicked.
+type dataQualityStat struct {
+	SystemStat
+	Table string
+	Query ExplorerQueryOptions
+}
+
+// createDataQuality builds the data quality section, or nil when the
+// database is unavailable.
+func (d *SmartDashboard) createDataQuality() fyne.CanvasObject {
+	stats := d.getDataQualityStats()
+	if len(stats) == 0 {
+		return nil
+	}
+
+	title := widget.NewLabelWithStyle("Data Quality", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	hint := widget.NewLabel("Click a card to review the matching records in Explorer.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	cards := make([]fyne.CanvasObject, 0, len(stats))
+	for _, stat := range stats {
+		cards = append(cards, d.createDataQualityCard(stat))
+	}
+
+	return container.NewVBox(title, hint, container.NewGridWithColumns(2, cards...))
+}
+
+func (d *SmartDashboard) createDataQualityCard(stat dataQualityStat) fyne.CanvasObject {
+	card := d.createSystemStatCard(stat.SystemStat)
+	if stat.Table == "" {
+		return card
+	}
+	// The button sits behind the card so the whole card area is clickable
+	// while keeping the stat card styling.
+	open := widget.NewButton("", func() {
+		d.ui.OpenExplorerTableWithQuery(stat.Table, stat.Query)
+	})
+	open.Importance = widget.LowImportance
+	return container.NewStack(open, card)
+}
+
+// getDataQualityStats computes the data quality KPIs shown on the dashboard.
+func (d *SmartDashboard) getDataQualityStats() []dataQualityStat {
+	if d.ui.app.DB == nil || !d.ui.app.DB.IsConnected() {
+		return nil
+	}
+
+	staleDays := d.ui.app.StaleAccountDays()
+	quality, err := database.GetDataQuality(d.ui.app.DB, staleDays)
+	if err != nil {
+		d.ui.app.Events.Dispatch(events.Debugf("dashboard", "Data quality unavailable: %v", err))
+		return nil
+	}
+
+	percent := func(count, total int) string {
+		return fmt.Sprintf("%.0f%%", database.Percent(count, total))
+	}
+	emptyColumn := func(column string) ExplorerQueryOptions {
+		return ExplorerQueryOptions{Filters: []ExplorerFilterClause{{Column: column, Mode: FilterModeIsEmpty}}}
+	}
+
+	stats := []dataQualityStat{
+		{
+			SystemStat: SystemStat{
+				Label:       "Missing Email",
+				Value:       percent(quality.MissingEmail, quality.TotalAccounts),
+				Description: fmt.Sprintf("%d of %d accounts", quality.MissingEmail, quality.TotalAccounts),
+			},
+			Table: "Accounts",
+			Query: emptyColumn("Email"),
+		},
+		{
+			SystemStat: SystemStat{
+				Label:       "Missing Phone",
+				Value:       percent(quality.MissingPhone, quality.TotalAccounts),
+				Description: fmt.Sprintf("%d of %d accounts", quality.MissingPhone, quality.TotalAccounts),
+			},
+			Table: "Accounts",
+			Query: emptyColumn("PhoneNumber"),
+		},
+		{
+			SystemStat: SystemStat{
+				Label:       "Stale Accounts",
+				Value:       percent(quality.StaleAccounts, quality.TotalAccounts),
+				Description: fmt.Sprintf("%d accounts without a check-in in over %d days", quality.StaleAccounts, staleDays),
+			},
+			Table: "Accounts",
+			Query: ExplorerQueryOptions{
+				Filters:         []ExplorerFilterClause{{Column: "DaysSinceLastCheckin", Mode: FilterModeGreaterThan, Value: strconv.Itoa(staleDays)}},
+				OrderColumn:     "DaysSinceLastCheckin",
+				OrderDescending: true,
+			},
+		},
+		{
+			SystemStat: SystemStat{
+				Label:       "Approximate Geocodes",
+				Value:       percent(quality.ApproximateLocations, quality.TotalLocations),
+				Description: fmt.Sprintf("%d of %d locations", quality.ApproximateLocations, quality.TotalLocations),
+			},
+			Table: "AccountLocations",
+			Query: ExplorerQueryOptions{Filters: []ExplorerFilterClause{{Column: "IsApproximate", Mode: FilterModeEquals, Value: "1"}}},
+		},
+	}
+
+	unmapped := dataQualityStat{
+		SystemStat: SystemStat{
+			Label:       "Unmapped Custom Fields",
+			Value:       strconv.Itoa(len(quality.UnmappedCustomFields)),
+			Description: "All custom fields with data are mapped",
+		},
+	}
+	if len(quality.UnmappedCustomFields) > 0 {
+		unmapped.Description = strings.Join(quality.UnmappedCustomFields, ", ")
+		unmapped.Table = "Accounts"
+		unmapped.Query = ExplorerQueryOptions{Filters: []ExplorerFilterClause{{Column: quality.UnmappedCustomFields[0], Mode: FilterModeIsNotEmpty}}}
+	}
+	return append(stats, unmapped)
+}
@@ -0,0 +1,64 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/events"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"strconv"
+)
+
+// dashboardRankingSize is how many accounts the dashboard ranking lists.
+const dashboardRankingSize = 10
+
+// createScoreRanking lists the accounts with the highest enrichment score.
+// Each account opens its provenance in the details pane. It returns nil
+// when no score is configured.
+func (d *SmartDashboard) createScoreRanking() fyne.CanvasObject {
+	name, ranking, err := d.ui.app.ScoreRanking(dashboardRankingSize)
+	if name == "" {
+		return nil
+	}
+	if err != nil {
+		d.ui.app.Events.Dispatch(events.Debugf("dashboard", "Score ranking unavailable: %v", err))
+		return nil
+	}
+
+	title := widget.NewLabelWithStyle("Top Accounts by "+name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	if len(ranking) == 0 {
+		hint := widget.NewLabel("Pull accounts to compute their scores.")
+		hint.Wrapping = fyne.TextWrapWord
+		return container.NewVBox(title, hint)
+	}
+
+	bold := fyne.TextStyle{Bold: true}
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle("Rank", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Account", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Score", fyne.TextAlignTrailing, bold),
+	)
+	ids := make([]int, len(ranking))
+	for i, ranked := range ranking {
+		ids[i] = ranked.AccountId
+	}
+	names, _ := d.ui.app.AccountDisplayNames(ids)
+	for i, ranked := range ranking {
+		label := names[ranked.AccountId]
+		if label == "" {
+			label = ranked.FullName
+		}
+		if label == "" {
+			label = fmt.Sprintf("Account %d", ranked.AccountId)
+		}
+		open := widget.NewButton(label, func() { d.ui.showAccountProvenance(ranked.AccountId) })
+		open.Alignment = widget.ButtonAlignLeading
+		open.Importance = widget.LowImportance
+		grid.Add(widget.NewLabel(strconv.Itoa(i + 1)))
+		grid.Add(open)
+		grid.Add(widget.NewLabelWithStyle(fmt.Sprintf("%.2f", ranked.Score), fyne.TextAlignTrailing, fyne.TextStyle{}))
+	}
+	return container.NewVBox(title, grid)
+}
@@ -0,0 +1,225 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"strconv"
+)
+
+// allRepsOption is the team selector entry that shows the whole team.
+const allRepsOption = "All reps"
+
+// repLabel names a rep for display. Accounts without an owner and check-ins
+// without an author are grouped as Unassigned.
+func repLabel(rep string) string {
+	if rep == "" {
+		return "Unassigned"
+	}
+	return rep
+}
+
+// repFilter filters Explorer to the rows of rep in column.
+func repFilter(column, rep string) ExplorerFilterClause {
+	if rep == "" {
+		return ExplorerFilterClause{Column: column, Mode: FilterModeIsEmpty}
+	}
+	return ExplorerFilterClause{Column: column, Mode: FilterModeEquals, Value: rep}
+}
+
+// createTeamSection builds the manager section of the dashboard: a team
+// selector, the team overview or the selected rep's dashboard, and a review
+// of the team's pending changes. It returns nil unless the pulled profile
+// is a manager's.
+func (d *SmartDashboard) createTeamSection() fyne.CanvasObject {
+	if !d.ui.app.IsManager() {
+		return nil
+	}
+	team, err := d.ui.app.TeamActivity()
+	if err != nil {
+		d.ui.app.Events.Dispatch(events.Debugf("dashboard", "Team activity unavailable: %v", err))
+		return nil
+	}
+	pending, err := d.ui.app.TeamPendingChanges()
+	if err != nil {
+		d.ui.app.Events.Dispatch(events.Debugf("dashboard", "Team pending changes unavailable: %v", err))
+	}
+	pendingByRep := make(map[string]int, len(pending))
+	for _, group := range pending {
+		pendingByRep[group.Rep] = len(group.Accounts) + len(group.Checkins)
+	}
+
+	title := widget.NewLabelWithStyle("Team", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	if len(team) == 0 {
+		hint := widget.NewLabel("Pull accounts and check-ins to see your reps' activity.")
+		hint.Wrapping = fyne.TextWrapWord
+		return container.NewVBox(title, hint)
+	}
+
+	options := []string{allRepsOption}
+	reps := make(map[string]database.RepActivity, len(team))
+	for _, rep := range team {
+		options = append(options, repLabel(rep.Rep))
+		reps[repLabel(rep.Rep)] = rep
+	}
+
+	body := container.NewVBox()
+	selector := widget.NewSelect(options, nil)
+	selector.OnChanged = func(option string) {
+		if rep, ok := reps[option]; ok {
+			body.Objects = []fyne.CanvasObject{d.createRepDashboard(rep, pendingByRep[rep.Rep])}
+		} else {
+			body.Objects = []fyne.CanvasObject{d.createTeamOverview(team, pendingByRep, selector)}
+		}
+		body.Refresh()
+	}
+	selector.SetSelected(allRepsOption)
+
+	review := NewSecondaryButton("Review Team Changes", theme.DocumentIcon(), func() {
+		d.ui.showTeamPendingChanges("")
+	})
+	actions := container.NewHBox(review)
+	if len(d.ui.app.Config.Territories) > 0 {
+		actions.Add(NewSecondaryButton("Territories", theme.GridIcon(), d.presenter.HandleShowTerritories))
+	}
+	header := container.NewBorder(nil, nil, title, actions, selector)
+	return container.NewVBox(header, body)
+}
+
+// createTeamOverview lists every rep with their counts. Selecting a rep
+// opens their dashboard.
+func (d *SmartDashboard) createTeamOverview(team []database.RepActivity, pendingByRep map[string]int, selector *widget.Select) fyne.CanvasObject {
+	bold := fyne.TextStyle{Bold: true}
+	grid := container.NewGridWithColumns(5,
+		widget.NewLabelWithStyle("Rep", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Accounts", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Stale", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle(fmt.Sprintf("Check-ins (%dd)", app.TeamActivityDays), fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Pending", fyne.TextAlignTrailing, bold),
+	)
+	number := func(n int) fyne.CanvasObject {
+		return widget.NewLabelWithStyle(strconv.Itoa(n), fyne.TextAlignTrailing, fyne.TextStyle{})
+	}
+	for _, rep := range team {
+		label := repLabel(rep.Rep)
+		open := widget.NewButton(label, func() { selector.SetSelected(label) })
+		open.Alignment = widget.ButtonAlignLeading
+		open.Importance = widget.LowImportance
+		grid.Add(open)
+		grid.Add(number(rep.Accounts))
+		grid.Add(number(rep.StaleAccounts))
+		grid.Add(number(rep.RecentCheckins))
+		grid.Add(number(pendingByRep[rep.Rep]))
+	}
+	return grid
+}
+
+// createRepDashboard shows one rep's stats as cards that open the matching
+// records.
+func (d *SmartDashboard) createRepDashboard(rep database.RepActivity, pending int) fyne.CanvasObject {
+	staleDays := d.ui.app.StaleAccountDays()
+	lastCheckin := "No check-ins"
+	if rep.LastCheckin != "" {
+		lastCheckin = "Last on " + rep.LastCheckin
+	}
+	stats := []dataQualityStat{
+		{
+			SystemStat: SystemStat{Label: "Accounts", Value: strconv.Itoa(rep.Accounts), Description: "Accounts owned by " + repLabel(rep.Rep)},
+			Table:      "Accounts",
+			Query:      ExplorerQueryOptions{Filters: []ExplorerFilterClause{repFilter("AccountOwner", rep.Rep)}},
+		},
+		{
+			SystemStat: SystemStat{
+				Label:       "Stale Accounts",
+				Value:       fmt.Sprintf("%.0f%%", database.Percent(rep.StaleAccounts, rep.Accounts)),
+				Description: fmt.Sprintf("%d accounts without a check-in in over %d days", rep.StaleAccounts, staleDays),
+			},
+			Table: "Accounts",
+			Query: ExplorerQueryOptions{
+				Filters: []ExplorerFilterClause{
+					repFilter("AccountOwner", rep.Rep),
+					{Column: "DaysSinceLastCheckin", Mode: FilterModeGreaterThan, Value: strconv.Itoa(staleDays)},
+				},
+				OrderColumn:     "DaysSinceLastCheckin",
+				OrderDescending: true,
+			},
+		},
+		{
+			SystemStat: SystemStat{
+				Label:       fmt.Sprintf("Check-ins (%d days)", app.TeamActivityDays),
+				Value:       strconv.Itoa(rep.RecentCheckins),
+				Description: lastCheckin,
+			},
+			Table: "AccountCheckins",
+			Query: ExplorerQueryOptions{
+				Filters:         []ExplorerFilterClause{repFilter("CreatedBy", rep.Rep)},
+				OrderColumn:     "LogDatetime",
+				OrderDescending: true,
+			},
+		},
+	}
+
+	cards := make([]fyne.CanvasObject, 0, len(stats)+1)
+	for _, stat := range stats {
+		cards = append(cards, d.createDataQualityCard(stat))
+	}
+	pendingCard := d.createSystemStatCard(SystemStat{Label: "Pending Changes", Value: strconv.Itoa(pending), Description: "Unpushed edits to this rep's accounts and check-ins"})
+	review := widget.NewButton("", func() { d.ui.showTeamPendingChanges(rep.Rep) })
+	review.Importance = widget.LowImportance
+	cards = append(cards, container.NewStack(review, pendingCard))
+	return container.NewGridWithColumns(2, cards...)
+}
+
+// showTeamPendingChanges lists the team's pending changes grouped by rep in
+// the details pane, or only rep's when rep is not empty. Each change opens
+// its diff.
+func (ui *Gui) showTeamPendingChanges(rep string) {
+	groups, err := ui.app.TeamPendingChanges()
+	if err != nil {
+		ui.ShowErrorDialog(err)
+		return
+	}
+
+	content := container.NewVBox(widget.NewLabelWithStyle("Team Pending Changes", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	shown := 0
+	for _, group := range groups {
+		if rep != "" && group.Rep != rep {
+			continue
+		}
+		shown++
+		content.Add(widget.NewSeparator())
+		content.Add(widget.NewLabelWithStyle(
+			fmt.Sprintf("%s (%d)", repLabel(group.Rep), len(group.Accounts)+len(group.Checkins)),
+			fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, change := range group.Accounts {
+			c := change
+			content.Add(changeButton(fmt.Sprintf("Account %d: %s, staged %s", c.AccountId, c.ChangeType, c.CreatedAt.Format("Jan 2 15:04")), func() {
+				ui.showAccountChangeDiff(c)
+			}))
+		}
+		for _, change := range group.Checkins {
+			c := change
+			content.Add(changeButton(fmt.Sprintf("Check-in %d on account %d: %s, staged %s", c.CheckinId, c.AccountId, c.ChangeType, c.CreatedAt.Format("Jan 2 15:04")), func() {
+				ui.showCheckinChangeDiff(c)
+			}))
+		}
+	}
+	if shown == 0 {
+		content.Add(NewWrappingLabel("No pending changes."))
+	}
+	ui.ShowDetails(content)
+}
+
+func changeButton(text string, tapped func()) *widget.Button {
+	button := widget.NewButton(text, tapped)
+	button.Alignment = widget.ButtonAlignLeading
+	button.Importance = widget.LowImportance
+	return button
+}
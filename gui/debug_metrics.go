@@ -0,0 +1,137 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// debugMetricsInterval is how often the Debug tab's runtime panel refreshes
+// while the tab is selected.
+const debugMetricsInterval = 2 * time.Second
+
+// createRuntimeMetricsCard builds the Debug tab's live runtime panel and
+// starts refreshing it. Metrics are only read while the Debug tab is
+// selected, since reading memory statistics briefly pauses the program.
+func (ui *Gui) createRuntimeMetricsCard() fyne.CanvasObject {
+	goroutines := widget.NewLabel("")
+	memory := widget.NewLabel("")
+	gc := widget.NewLabel("")
+	pending := widget.NewLabel("")
+	apiInFlight := widget.NewLabel("")
+	dbPool := widget.NewLabel("")
+	dbWaits := widget.NewLabel("")
+	units := widget.NewLabel("")
+	pprofAddr := widget.NewLabel("")
+
+	refresh := func() {
+		m := ui.app.RuntimeMetrics()
+		goroutines.SetText(fmt.Sprintf("%d", m.Goroutines))
+		memory.SetText(fmt.Sprintf("%s heap, %s from the OS", formatMegabytes(m.HeapAlloc), formatMegabytes(m.Sys)))
+		gc.SetText(fmt.Sprintf("%d collections, last pause %s", m.NumGC, m.LastGCPause.Round(time.Microsecond)))
+		pending.SetText(fmt.Sprintf("%d listener calls queued or running", m.PendingEvents))
+		apiInFlight.SetText(fmt.Sprintf("%d", m.APIInFlight))
+		if s := m.DBStats; s != nil {
+			dbPool.SetText(fmt.Sprintf("%d open (%d in use, %d idle), max %d", s.OpenConnections, s.InUse, s.Idle, s.MaxOpenConnections))
+			dbWaits.SetText(fmt.Sprintf("%d waits, %s waiting", s.WaitCount, s.WaitDuration.Round(time.Millisecond)))
+		} else {
+			dbPool.SetText("Not connected")
+			dbWaits.SetText("")
+		}
+		units.SetText(unitsOfWorkSummary(m.UnitsOfWork))
+		if m.PprofAddr != "" {
+			pprofAddr.SetText(fmt.Sprintf("http://%s/debug/pprof/", m.PprofAddr))
+		} else {
+			pprofAddr.SetText("Off")
+		}
+	}
+	refresh()
+
+	var pprofCheck *widget.Check
+	pprofCheck = widget.NewCheck("Serve pprof on a loopback port", func(on bool) {
+		ui.presenter.HandleTogglePprof(on)
+		refresh()
+		if on && ui.app.RuntimeMetrics().PprofAddr == "" {
+			// It did not start; uncheck without calling back.
+			pprofCheck.Checked = false
+			pprofCheck.Refresh()
+		}
+	})
+	refreshButton := widget.NewButton("Refresh Now", refresh)
+	logButton := widget.NewButton("Log Snapshot", func() {
+		ui.app.Events.Dispatch(events.Infof("debug", "Runtime: %s", runtimeMetricsSummary(ui.app.RuntimeMetrics())))
+	})
+
+	go func() {
+		ticker := time.NewTicker(debugMetricsInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if ui.app.IsShuttingDown() {
+				return
+			}
+			fyne.Do(func() {
+				if ui.tabs == nil || ui.tabs.Selected() == nil || ui.tabs.Selected().Text != "Debug" {
+					return
+				}
+				refresh()
+			})
+		}
+	}()
+
+	return ui.newSectionCard(
+		"Runtime",
+		"Refreshes every few seconds while this tab is open. A goroutine count or event queue that keeps climbing points at a leak or a stuck listener.",
+		widget.NewForm(
+			widget.NewFormItem("Goroutines", goroutines),
+			widget.NewFormItem("Memory", memory),
+			widget.NewFormItem("Garbage Collection", gc),
+			widget.NewFormItem("Event Queue", pending),
+			widget.NewFormItem("API Requests In Flight", apiInFlight),
+			widget.NewFormItem("Database Pool", dbPool),
+			widget.NewFormItem("Pool Waits", dbWaits),
+			widget.NewFormItem("Store Transactions", units),
+			widget.NewFormItem("Profiling", pprofAddr),
+		),
+		pprofCheck,
+		container.NewGridWithColumns(2, refreshButton, logButton),
+	)
+}
+
+// formatMegabytes renders a byte count in megabytes.
+func formatMegabytes(bytes uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+}
+
+// unitsOfWorkSummary lists each kind of store transaction with its runs,
+// deadlock retries, failures, and average and longest time.
+func unitsOfWorkSummary(units []database.UnitOfWorkStats) string {
+	if len(units) == 0 {
+		return "None yet"
+	}
+	lines := make([]string, len(units))
+	for i, u := range units {
+		lines[i] = fmt.Sprintf("%s: %d runs, %d retries, %d failed, avg %s, max %s",
+			u.Name, u.Runs, u.Retries, u.Failures, (u.Total / time.Duration(u.Runs)).Round(time.Millisecond), u.Max.Round(time.Millisecond))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runtimeMetricsSummary is a one-line summary of m for the log, so a
+// snapshot reaches support with the log file.
+func runtimeMetricsSummary(m app.RuntimeMetrics) string {
+	var retries int64
+	for _, u := range m.UnitsOfWork {
+		retries += u.Retries
+	}
+	return fmt.Sprintf("%d goroutines, %s heap, %d queued events, %d API requests in flight, %d store transaction retries",
+		m.Goroutines, formatMegabytes(m.HeapAlloc), m.PendingEvents, m.APIInFlight, retries)
+}
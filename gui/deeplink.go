@@ -0,0 +1,131 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"badgermaps/app"
+	"badgermaps/events"
+
+	"fyne.io/fyne/v2"
+)
+
+// deepLinkRecords maps record links to the Explorer table and ID column
+// they open.
+var deepLinkRecords = map[string]struct{ table, idColumn string }{
+	app.DeepLinkAccount: {"Accounts", "AccountId"},
+	app.DeepLinkCheckin: {"AccountCheckins", "CheckinId"},
+	app.DeepLinkRoute:   {"Routes", "RouteId"},
+}
+
+// HandleDeepLink opens the view a badgermaps:// link points to. Links that
+// arrive during first-time setup are opened once setup completes.
+func (ui *Gui) HandleDeepLink(link app.DeepLink) {
+	if ui.showWelcome {
+		ui.pendingLink = &link
+		ui.ShowToast("Finish setup to open " + link.URL())
+		return
+	}
+	ui.app.Events.Dispatch(events.Infof("gui", "Opening link %s", link.URL()))
+
+	switch link.Kind {
+	case app.DeepLinkAccount, app.DeepLinkCheckin, app.DeepLinkRoute:
+		record := deepLinkRecords[link.Kind]
+		query := ExplorerQueryOptions{Filters: []ExplorerFilterClause{{
+			Column: record.idColumn,
+			Mode:   FilterModeEquals,
+			Value:  strconv.Itoa(link.ID),
+		}}}
+		if !ui.OpenExplorerTableWithQuery(record.table, query) {
+			return
+		}
+		if link.Kind == app.DeepLinkAccount {
+			ui.showAccountProvenance(link.ID)
+		}
+	case app.DeepLinkTable:
+		ui.OpenExplorerTable(link.Target)
+	case app.DeepLinkTab:
+		if !ui.openTab(link.Target) {
+			ui.ShowToast(fmt.Sprintf("There is no %s tab.", link.Target))
+		}
+	case app.DeepLinkRun:
+		ui.confirmDeepLinkRun(link.Target)
+	}
+}
+
+// confirmDeepLinkRun asks before running a job, since any page or email can
+// carry a run link.
+func (ui *Gui) confirmDeepLinkRun(name string) {
+	job, ok := ui.app.CronJobByName(name)
+	if !ok {
+		ui.ShowErrorDialog(fmt.Errorf("no scheduled job named %q", name))
+		return
+	}
+	message := fmt.Sprintf("A link asks to run the job %s (%s action) now. Run it?", job.Name, job.Action.Type)
+	ui.ShowConfirmDialog("Run Job", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		ui.ShowToast(fmt.Sprintf("Running %s...", job.Name))
+		go func() {
+			err := ui.app.RunCronJob(job.Name)
+			fyne.Do(func() {
+				if err != nil {
+					ui.ShowErrorDialog(err)
+					return
+				}
+				ui.ShowToast(fmt.Sprintf("Success: %s finished.", job.Name))
+			})
+		}()
+	})
+}
+
+// openTab selects the tab whose title matches name, ignoring case, spaces,
+// and dashes, so "sync-center" opens Sync Center. "config" opens
+// Configuration.
+func (ui *Gui) openTab(name string) bool {
+	if ui.tabs == nil {
+		return false
+	}
+	normalize := strings.NewReplacer(" ", "", "-", "", "_", "")
+	want := strings.ToLower(normalize.Replace(name))
+	if want == "config" {
+		want = "configuration"
+	}
+	for idx, tab := range ui.tabs.Items {
+		if strings.ToLower(normalize.Replace(tab.Text)) == want {
+			ui.tabs.SelectIndex(idx)
+			return true
+		}
+	}
+	return false
+}
+
+// openPendingLink opens a link that arrived before the main window was
+// ready.
+func (ui *Gui) openPendingLink() {
+	if ui.pendingLink == nil || ui.showWelcome {
+		return
+	}
+	link := *ui.pendingLink
+	ui.pendingLink = nil
+	ui.HandleDeepLink(link)
+}
+
+// listenForDeepLinks opens links forwarded by later launches in this window.
+func (ui *Gui) listenForDeepLinks() func() {
+	stop, err := app.ListenDeepLinks(func(link app.DeepLink) {
+		fyne.Do(func() {
+			ui.window.RequestFocus()
+			ui.HandleDeepLink(link)
+		})
+	})
+	if err != nil {
+		ui.app.Events.Dispatch(events.Warningf("gui", "Links will open new windows: %v", err))
+		return func() {}
+	}
+	return stop
+}
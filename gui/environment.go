@@ -0,0 +1,146 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/events"
+)
+
+// defaultEnvironmentOption is the environment select entry for the
+// top-level db settings.
+const defaultEnvironmentOption = "(default database)"
+
+// guardProduction calls run, first asking for the environment name when the
+// database belongs to an environment tagged production.
+func (p *GuiPresenter) guardProduction(operation string, run func()) {
+	env := p.app.ActiveEnvironment()
+	if env == nil || !env.Production {
+		run()
+		return
+	}
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(env.Name)
+	entry.Validator = func(typed string) error {
+		return p.app.GuardProduction(operation, typed)
+	}
+	message := NewWrappingLabel(fmt.Sprintf("You are %s in the production environment %q. Type the environment name to continue.", operation, env.Name))
+	items := []*widget.FormItem{
+		widget.NewFormItem("", message),
+		widget.NewFormItem("Environment", entry),
+	}
+	confirm := dialog.NewForm("Production Environment", "Continue", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := p.app.GuardProduction(operation, entry.Text); err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		p.app.Events.Dispatch(events.Warningf("presenter", "Confirmed %s in the production environment %q.", operation, env.Name))
+		run()
+	}, p.view.GetMainWindow())
+	confirm.Resize(fyne.NewSize(440, 0))
+	confirm.Show()
+}
+
+// HandleSelectEnvironment switches the database to the named environment,
+// or to the top-level db settings when name is empty.
+func (p *GuiPresenter) HandleSelectEnvironment(name string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSelectEnvironment called with %q", name))
+	if err := p.app.UseEnvironment(name); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "ERROR switching environment: %v", err))
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	if name == "" {
+		p.view.ShowToast("Using the default database.")
+	} else {
+		p.view.ShowToast(fmt.Sprintf("Using the %s environment.", name))
+	}
+	p.app.Connections().Refresh()
+	p.view.RefreshAllTabs()
+}
+
+// createEnvironmentSelect lists the configured environments, or returns nil
+// when there are none.
+func (ui *Gui) createEnvironmentSelect() fyne.CanvasObject {
+	if len(ui.app.Config.Environments) == 0 {
+		return nil
+	}
+	options := []string{defaultEnvironmentOption}
+	for _, env := range ui.app.Config.Environments {
+		options = append(options, env.Name)
+	}
+	envSelect := widget.NewSelect(options, nil)
+	envSelect.SetSelected(defaultEnvironmentOption)
+	if env := ui.app.ActiveEnvironment(); env != nil {
+		envSelect.SetSelected(env.Name)
+	}
+	envSelect.OnChanged = func(selected string) {
+		if selected == defaultEnvironmentOption {
+			selected = ""
+		}
+		ui.presenter.HandleSelectEnvironment(selected)
+	}
+	return container.NewGridWithColumns(2, widget.NewLabel("Environment"), envSelect)
+}
+
+// refreshEnvironmentBanner shows the active environment across the top of
+// the window in its color, and hides the banner when none is active.
+func (ui *Gui) refreshEnvironmentBanner() {
+	if ui.environmentBanner == nil {
+		return
+	}
+	env := ui.app.ActiveEnvironment()
+	if env == nil {
+		ui.environmentBanner.Objects = nil
+		ui.environmentBanner.Hide()
+		return
+	}
+	background := canvas.NewRectangle(bannerColor(env))
+	text := fmt.Sprintf("Environment: %s", env.Name)
+	if env.Production {
+		text += " (production)"
+	}
+	label := canvas.NewText(text, color.White)
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	label.Alignment = fyne.TextAlignCenter
+	ui.environmentBanner.Objects = []fyne.CanvasObject{background, container.NewPadded(label)}
+	ui.environmentBanner.Show()
+	ui.environmentBanner.Refresh()
+}
+
+// bannerColor parses the environment's #rrggbb color, falling back to the
+// default for its name when the configured value cannot be read.
+func bannerColor(env *app.DBEnvironment) color.Color {
+	if c, ok := parseHexColor(env.BannerColor()); ok {
+		return c
+	}
+	fallback := app.DBEnvironment{Name: env.Name, Production: env.Production}
+	if c, ok := parseHexColor(fallback.BannerColor()); ok {
+		return c
+	}
+	return theme.PrimaryColor()
+}
+
+func parseHexColor(value string) (color.NRGBA, bool) {
+	var r, g, b uint8
+	if len(value) != 7 {
+		return color.NRGBA{}, false
+	}
+	if _, err := fmt.Sscanf(value, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: r, G: g, B: b, A: 255}, true
+}
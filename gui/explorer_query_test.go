@@ -1,3 +1,5 @@
+//go:build !nogui
+
 package gui
 
 import (
@@ -62,6 +64,37 @@ func TestBuildExplorerWhereClause(t *testing.T) {
 			expectedSQL: "Name ILIKE '%Acme%'",
 			dbType:      "postgres",
 		},
+		{
+			name:        "greater than numeric",
+			column:      "DaysSinceLastCheckin",
+			mode:        FilterModeGreaterThan,
+			value:       "90",
+			expectedSQL: "DaysSinceLastCheckin > 90",
+			dbType:      "sqlite3",
+		},
+		{
+			name:        "less than text",
+			column:      "Name",
+			mode:        FilterModeLessThan,
+			value:       "M",
+			expectedSQL: "Name < 'M'",
+			dbType:      "sqlite3",
+		},
+		{
+			name:        "is empty ignores value",
+			column:      "Email",
+			mode:        FilterModeIsEmpty,
+			value:       "",
+			expectedSQL: "(Email IS NULL OR TRIM(CAST(Email AS TEXT)) = '')",
+			dbType:      "postgres",
+		},
+		{
+			name:        "is not empty mssql",
+			column:      "CustomText2",
+			mode:        FilterModeIsNotEmpty,
+			expectedSQL: "(CustomText2 IS NOT NULL AND LTRIM(RTRIM(CAST(CustomText2 AS NVARCHAR(MAX)))) <> '')",
+			dbType:      "mssql",
+		},
 	}
 
 	for _, tc := range tests {
@@ -233,3 +266,62 @@ func TestExplorerQueryCompositionAcrossDialects(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeExplorerOptionsKeepsEmptinessFilters(t *testing.T) {
+	opts := normalizeExplorerOptions(ExplorerQueryOptions{Filters: []ExplorerFilterClause{
+		{Column: "Email", Mode: FilterModeIsEmpty},
+		{Column: "Name", Mode: FilterModeEquals},
+	}})
+	if len(opts.Filters) != 1 || opts.Filters[0].Column != "Email" {
+		t.Fatalf("expected only the is-empty filter to survive, got %+v", opts.Filters)
+	}
+}
+
+func TestBuildExplorerWhereClauseWithinKm(t *testing.T) {
+	filters := []ExplorerFilterClause{
+		{Column: "AccountId", Mode: FilterModeWithinKm, Value: "25 km of 39.7,-104.9", radiusSQL: "SELECT AccountId FROM AccountLocations WHERE 1 = 1"},
+		{Column: "Name", Mode: FilterModeContains, Value: "Acme"},
+	}
+	got := buildExplorerWhereClause(filters, "sqlite3")
+	want := "AccountId IN (SELECT AccountId FROM AccountLocations WHERE 1 = 1) AND Name LIKE '%Acme%'"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	// The raw value never reaches the SQL; an unresolved clause is dropped.
+	filters[0].radiusSQL = ""
+	if got := buildExplorerWhereClause(filters[:1], "sqlite3"); got != "" {
+		t.Fatalf("expected unresolved radius clause to be skipped, got %q", got)
+	}
+}
+
+func TestWithoutColumnsHidesUnpulledFields(t *testing.T) {
+	headers := []string{"AccountId", "CustomText", "CustomText2", "FullName"}
+	data := [][]string{{"1", "", "a", "Acme"}, {"2", "", "b", "Globex"}}
+	gotHeaders, gotData := withoutColumns(headers, data, map[string]bool{"CustomText": true})
+	if strings.Join(gotHeaders, ",") != "AccountId,CustomText2,FullName" {
+		t.Fatalf("headers = %v", gotHeaders)
+	}
+	if strings.Join(gotData[1], ",") != "2,b,Globex" {
+		t.Fatalf("row = %v", gotData[1])
+	}
+	if gotHeaders, _ := withoutColumns(headers, data, nil); len(gotHeaders) != len(headers) {
+		t.Fatalf("nothing hidden dropped columns: %v", gotHeaders)
+	}
+}
+
+func TestLabeledColumnOptions(t *testing.T) {
+	labels := map[string]string{"CustomText3": "Territory"}
+	columns := []string{"AccountId", "CustomText3"}
+	option := labeledColumn(labels, "CustomText3")
+	if option != "Territory (CustomText3)" || labeledColumn(labels, "AccountId") != "AccountId" {
+		t.Fatalf("labeledColumn = %q", option)
+	}
+	if got := columnForLabeledOption(labels, columns, option); got != "CustomText3" {
+		t.Fatalf("columnForLabeledOption(%q) = %q", option, got)
+	}
+	config := TableConfig{Headers: columns, HeaderLabels: labels}
+	if got := strings.Join(config.headerTexts(), ","); got != "AccountId,Territory" {
+		t.Fatalf("headerTexts = %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+)
+
+// buildFieldMappingCard builds the config card that opens the per-field sync
+// direction editor.
+func (ui *Gui) buildFieldMappingCard() fyne.CanvasObject {
+	editButton := NewSecondaryButton("Edit Field Sync Directions", theme.SettingsIcon(), ui.showFieldSyncDirections)
+	customButton := NewSecondaryButton("Choose Pulled Custom Fields", theme.ListIcon(), ui.showPulledCustomFields)
+	return ui.newSectionCard(
+		"Field Mapping",
+		"Choose per account field whether it syncs both ways, only from BadgerMaps (local edits are never pushed), or only to BadgerMaps (pulls never overwrite the local value), how pulls merge into the local value, and which custom fields are pulled at all.",
+		container.NewCenter(container.NewHBox(editButton, customButton)),
+	)
+}
+
+// showFieldSyncDirections lists the account fields in the details pane, each
+// with its sync direction and merge strategy. Changing either saves it at
+// once.
+func (ui *Gui) showFieldSyncDirections() {
+	rules, err := ui.app.AccountSyncRules()
+	if err != nil {
+		ui.ShowDetails(NewWrappingLabel(fmt.Sprintf("Could not read field mappings: %v", err)))
+		return
+	}
+
+	directions := app.SyncDirections()
+	labels := make([]string, len(directions))
+	byLabel := make(map[string]string, len(directions))
+	for i, direction := range directions {
+		labels[i] = app.SyncDirectionLabel(direction)
+		byLabel[labels[i]] = direction
+	}
+	strategies := app.MergeStrategies()
+	strategyLabels := make([]string, len(strategies))
+	strategyByLabel := make(map[string]string, len(strategies))
+	for i, strategy := range strategies {
+		strategyLabels[i] = app.MergeStrategyLabel(strategy)
+		strategyByLabel[strategyLabels[i]] = strategy
+	}
+
+	form := widget.NewForm()
+	for _, rule := range rules {
+		r := rule
+		name := r.FieldName
+		if r.Label != "" && r.Label != r.FieldName {
+			name = fmt.Sprintf("%s (%s)", r.Label, r.FieldName)
+		}
+		sel := widget.NewSelect(labels, nil)
+		sel.SetSelected(app.SyncDirectionLabel(r.Direction))
+		sel.OnChanged = func(label string) {
+			ui.presenter.HandleSaveFieldSyncDirection(r.FieldName, byLabel[label])
+		}
+		merge := widget.NewSelect(strategyLabels, nil)
+		merge.SetSelected(app.MergeStrategyLabel(r.MergeStrategy))
+		merge.OnChanged = func(label string) {
+			ui.presenter.HandleSaveFieldMergeStrategy(r.FieldName, strategyByLabel[label])
+		}
+		form.Append(name, container.NewGridWithColumns(2, sel, merge))
+	}
+
+	ui.ShowDetails(container.NewVBox(
+		widget.NewLabelWithStyle("Field Sync Directions", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		NewWrappingLabel("Pull only: BadgerMaps wins and local edits of the field are not pushed. Push only: the local value is kept on every pull. The merge strategy decides what a pull stores: the pulled value (Overwrite), the local value unless it is empty (Prefer local), both texts joined by merge_separator (Append), or the longer text (Keep longest)."),
+		widget.NewSeparator(),
+		form,
+	))
+}
+
+// showPulledCustomFields lists the custom account fields in the details
+// pane with a check for each one that pulls store. Fields left out are
+// stored empty and hidden from account views.
+func (ui *Gui) showPulledCustomFields() {
+	rules, err := ui.app.AccountSyncRules()
+	if err != nil {
+		ui.ShowDetails(NewWrappingLabel(fmt.Sprintf("Could not read field mappings: %v", err)))
+		return
+	}
+	labels := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		if rule.Label != "" && rule.Label != rule.FieldName {
+			labels[rule.FieldName] = fmt.Sprintf("%s (%s)", rule.Label, rule.FieldName)
+		}
+	}
+
+	selected := make(map[string]bool)
+	includeMapped := false
+	for _, field := range ui.app.Config.PullCustomFields {
+		if strings.EqualFold(strings.TrimSpace(field), app.PullMappedCustomFields) {
+			includeMapped = true
+		} else if column, ok := app.ParseCustomColumn(field); ok {
+			selected[column] = true
+		}
+	}
+	pullAll := len(ui.app.Config.PullCustomFields) == 0
+
+	mappedCheck := widget.NewCheck("Fields mapped to a data field in BadgerMaps", nil)
+	mappedCheck.SetChecked(includeMapped)
+	columns := app.CustomAccountColumns()
+	checks := make([]*widget.Check, len(columns))
+	fields := container.NewGridWithColumns(2)
+	for i, column := range columns {
+		label := labels[column]
+		if label == "" {
+			label = column
+		}
+		checks[i] = widget.NewCheck(label, nil)
+		checks[i].SetChecked(pullAll || selected[column])
+		fields.Add(checks[i])
+	}
+	allCheck := widget.NewCheck("Pull every custom field", func(on bool) {
+		for _, check := range checks {
+			if on {
+				check.Disable()
+			} else {
+				check.Enable()
+			}
+		}
+		if on {
+			mappedCheck.Disable()
+		} else {
+			mappedCheck.Enable()
+		}
+	})
+	allCheck.SetChecked(pullAll)
+
+	save := widget.NewButtonWithIcon("Save Pulled Fields", theme.DocumentSaveIcon(), func() {
+		var chosen []string
+		if !allCheck.Checked {
+			if mappedCheck.Checked {
+				chosen = append(chosen, app.PullMappedCustomFields)
+			}
+			for i, check := range checks {
+				if check.Checked {
+					chosen = append(chosen, columns[i])
+				}
+			}
+		}
+		ui.presenter.HandleSavePullCustomFields(chosen)
+	})
+	save.Importance = widget.HighImportance
+
+	header := container.NewVBox(
+		widget.NewLabelWithStyle("Pulled Custom Fields", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		NewWrappingLabel("Fields that are not pulled are stored empty on the next pull and hidden in the Explorer and account details."),
+		allCheck,
+		mappedCheck,
+		widget.NewSeparator(),
+	)
+	ui.ShowDetails(container.NewBorder(header, container.NewCenter(save), nil, nil, container.NewVScroll(fields)))
+}
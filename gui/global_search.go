@@ -0,0 +1,227 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/database"
+	"badgermaps/events"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"strconv"
+	"strings"
+)
+
+// globalSearchView is the right-pane view that searches accounts, check-ins,
+// routes, and pending changes at once. It is built once so the last query
+// and its results survive switching to the log and back.
+type globalSearchView struct {
+	ui      *Gui
+	entry   *widget.Entry
+	status  *widget.Label
+	results *fyne.Container
+	content fyne.CanvasObject
+	// seq discards results from searches that were superseded.
+	seq int
+}
+
+func newGlobalSearchView(ui *Gui) *globalSearchView {
+	v := &globalSearchView{ui: ui}
+	v.entry = widget.NewEntry()
+	v.entry.SetPlaceHolder("Search names, IDs, addresses, comments…")
+	v.entry.OnSubmitted = func(string) { v.search() }
+	searchButton := widget.NewButtonWithIcon("", theme.SearchIcon(), v.search)
+
+	v.status = widget.NewLabel("Search every table at once. Press Enter to search.")
+	v.status.Wrapping = fyne.TextWrapWord
+	v.results = container.NewVBox()
+
+	header := container.NewVBox(
+		widget.NewLabelWithStyle("Global Search", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewBorder(nil, nil, nil, searchButton, v.entry),
+		v.status,
+	)
+	v.content = container.NewBorder(header, nil, nil, nil, container.NewVScroll(v.results))
+	return v
+}
+
+// ShowGlobalSearch opens the global search in the right-hand pane.
+func (ui *Gui) ShowGlobalSearch() {
+	if ui.globalSearch == nil {
+		ui.globalSearch = newGlobalSearchView(ui)
+	}
+	ui.showPaneContent(ui.globalSearch.content)
+	if ui.window != nil {
+		ui.window.Canvas().Focus(ui.globalSearch.entry)
+	}
+}
+
+// showPaneContent shows content in the details pane as is. Unlike
+// ShowDetails it does not wrap content in a scroll, so views that scroll
+// their own body keep their fixed headers.
+func (ui *Gui) showPaneContent(content fyne.CanvasObject) {
+	ui.detailsView = content
+	ui.terminalVisible = false
+	ui.setRightPaneContent(content)
+	ui.showRightPane()
+}
+
+// registerGlobalSearchShortcut opens the global search with Ctrl+K (Cmd+K
+// on macOS) from anywhere in the window.
+func (ui *Gui) registerGlobalSearchShortcut() {
+	if ui.window == nil {
+		return
+	}
+	shortcut := &desktop.CustomShortcut{KeyName: fyne.KeyK, Modifier: fyne.KeyModifierShortcutDefault}
+	ui.window.Canvas().AddShortcut(shortcut, func(fyne.Shortcut) {
+		ui.ShowGlobalSearch()
+	})
+}
+
+func (v *globalSearchView) search() {
+	query := strings.TrimSpace(v.entry.Text)
+	if query == "" {
+		return
+	}
+	db := v.ui.app.DB
+	if db == nil || !db.IsConnected() {
+		v.status.SetText("Connect to the database to search.")
+		return
+	}
+
+	v.seq++
+	seq := v.seq
+	v.status.SetText(fmt.Sprintf("Searching for %q…", query))
+	go func() {
+		groups, err := database.GlobalSearch(db, query)
+		if err == nil {
+			v.applyAccountDisplayNames(groups)
+		}
+		fyne.Do(func() {
+			if seq != v.seq {
+				return
+			}
+			if err != nil {
+				v.ui.app.Events.Dispatch(events.Errorf("search", "Global search failed: %v", err))
+				v.status.SetText("Search failed. See the log for details.")
+				v.results.Objects = nil
+				v.results.Refresh()
+				return
+			}
+			v.showResults(query, groups)
+		})
+	}()
+}
+
+func (v *globalSearchView) showResults(query string, groups []database.GlobalSearchGroup) {
+	total := 0
+	objects := make([]fyne.CanvasObject, 0, len(groups)*2)
+	for _, group := range groups {
+		total += len(group.Hits)
+		objects = append(objects, widget.NewLabelWithStyle(
+			fmt.Sprintf("%s (%d)", group.Name, len(group.Hits)),
+			fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, hit := range group.Hits {
+			objects = append(objects, v.createHitRow(hit))
+		}
+		objects = append(objects, widget.NewSeparator())
+	}
+
+	if total == 0 {
+		v.status.SetText(fmt.Sprintf("No matches for %q.", query))
+	} else {
+		v.status.SetText(fmt.Sprintf("%d matches for %q. Select one to see the record.", total, query))
+	}
+	v.results.Objects = objects
+	v.results.Refresh()
+}
+
+func (v *globalSearchView) createHitRow(hit database.GlobalSearchHit) fyne.CanvasObject {
+	open := widget.NewButton(hit.Title, func() {
+		v.showHit(hit)
+	})
+	open.Alignment = widget.ButtonAlignLeading
+	open.Importance = widget.LowImportance
+
+	meta := "Matched " + strings.ToLower(hit.Matched)
+	if hit.Subtitle != "" {
+		meta += " • " + hit.Subtitle
+	}
+	detail := widget.NewLabel(meta)
+	detail.Wrapping = fyne.TextWrapWord
+	detail.Importance = widget.LowImportance
+	return container.NewVBox(open, detail)
+}
+
+// showHit shows the full record for hit in the right-hand pane, with links
+// back to the results and into Explorer.
+func (v *globalSearchView) showHit(hit database.GlobalSearchHit) {
+	query := hitExplorerQuery(hit)
+	data := v.ui.loadPaginatedTableData(hit.Table, 0, 1, query)
+
+	var details strings.Builder
+	if len(data.Data) == 0 {
+		details.WriteString("This record is no longer in the database.")
+	} else {
+		row := data.Data[0]
+		for i, header := range data.Headers {
+			if i < len(row) {
+				details.WriteString(fmt.Sprintf("%s: %s\n", header, row[i]))
+			}
+		}
+	}
+
+	back := widget.NewButtonWithIcon("Results", theme.NavigateBackIcon(), func() {
+		v.ui.showPaneContent(v.content)
+	})
+	explore := widget.NewButtonWithIcon("Open in Explorer", theme.FolderOpenIcon(), func() {
+		if v.ui.OpenExplorerTableWithQuery(hit.Table, query) {
+			v.ui.hideRightPane()
+		}
+	})
+	explore.Importance = widget.HighImportance
+
+	title := widget.NewLabelWithStyle(hit.Title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Wrapping = fyne.TextWrapWord
+	v.ui.showPaneContent(container.NewBorder(
+		container.NewVBox(container.NewHBox(back, explore), title),
+		nil, nil, nil,
+		container.NewVScroll(NewWrappingLabel(details.String())),
+	))
+}
+
+// hitExplorerQuery filters Explorer down to the row behind hit.
+func hitExplorerQuery(hit database.GlobalSearchHit) ExplorerQueryOptions {
+	return ExplorerQueryOptions{Filters: []ExplorerFilterClause{{
+		Column: hit.IDColumn,
+		Mode:   FilterModeEquals,
+		Value:  strconv.Itoa(hit.ID),
+	}}}
+}
+
+// applyAccountDisplayNames titles account hits with the configured account
+// display name instead of the full name the search matched.
+func (v *globalSearchView) applyAccountDisplayNames(groups []database.GlobalSearchGroup) {
+	var ids []int
+	for _, group := range groups {
+		for _, hit := range group.Hits {
+			if hit.Table == "Accounts" {
+				ids = append(ids, hit.ID)
+			}
+		}
+	}
+	names, err := v.ui.app.AccountDisplayNames(ids)
+	if err != nil {
+		return
+	}
+	for _, group := range groups {
+		for i, hit := range group.Hits {
+			if name, ok := names[hit.ID]; ok && hit.Table == "Accounts" {
+				group.Hits[i].Title = fmt.Sprintf("%s (#%d)", name, hit.ID)
+			}
+		}
+	}
+}
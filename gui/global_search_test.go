@@ -0,0 +1,54 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/app"
+	"badgermaps/app/state"
+	"badgermaps/database"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlobalSearchViewShowsGroupedResultsAndRecord(t *testing.T) {
+	a := app.NewApp()
+	a.DB, _ = database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "search.db")})
+	a.DB.Connect()
+	a.DB.EnforceSchema(&state.State{})
+	a.DB.SetConnected(true)
+	if _, err := a.DB.GetDB().Exec("INSERT INTO Accounts (AccountId, FullName, Email) VALUES (7, 'Harbor Books', 'info@harbor.test')"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	ui := &Gui{app: a, fyneApp: test.NewApp(), logBinding: binding.NewStringList()}
+	ui.rightPaneContent = container.NewMax()
+	view := newGlobalSearchView(ui)
+
+	groups, err := database.GlobalSearch(a.DB, "harbor")
+	if err != nil {
+		t.Fatalf("GlobalSearch: %v", err)
+	}
+	view.showResults("harbor", groups)
+	if !strings.HasPrefix(view.status.Text, "1 matches") {
+		t.Fatalf("status = %q", view.status.Text)
+	}
+	header, ok := view.results.Objects[0].(*widget.Label)
+	if !ok || header.Text != "Accounts (1)" {
+		t.Fatalf("first result object = %#v", view.results.Objects[0])
+	}
+
+	view.showHit(groups[0].Hits[0])
+	if ui.detailsView == view.content {
+		t.Fatal("selecting a hit should replace the results with the record")
+	}
+
+	query := hitExplorerQuery(groups[0].Hits[0])
+	if len(query.Filters) != 1 || query.Filters[0].Column != "AccountId" || query.Filters[0].Value != "7" {
+		t.Fatalf("explorer query = %+v", query)
+	}
+}
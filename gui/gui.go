@@ -1,6 +1,9 @@
+//go:build !nogui
+
 package gui
 
 import (
+	"encoding/json"
 	"fmt"
 	"image/color"
 	"os"
@@ -8,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -17,6 +21,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
@@ -24,6 +29,7 @@ import (
 	"badgermaps/app/action"
 	"badgermaps/app/push"
 	"badgermaps/database"
+	"badgermaps/errs"
 	"badgermaps/events"
 )
 
@@ -288,6 +294,7 @@ type Gui struct {
 	toastMutex sync.Mutex
 
 	logBinding            binding.StringList
+	logPending            []string // log lines waiting for the next batched append
 	logView               *widget.List
 	detailsView           fyne.CanvasObject
 	rightPaneContent      *fyne.Container
@@ -299,10 +306,18 @@ type Gui struct {
 	progressBar           *widget.ProgressBar
 	progressContainer     *fyne.Container
 	progressTitle         *widget.Label
+	environmentBanner     *fyne.Container
+	alertBanner           *fyne.Container
 
 	terminalVisible bool
 	tabs            *container.AppTabs // Hold a reference to the tabs container
 
+	// refresh batches the view rebuilds event listeners ask for.
+	refresh *refreshBatcher
+	// actionCards holds the Actions tab's card of each event action by
+	// name, so a change to one action rebuilds only its card.
+	actionCards map[string]*fyne.Container
+
 	// Explorer references for cross-navigation
 	explorerTableSelect     *widget.Select
 	explorerLoadPage        func(tableName string, page, pageSize int, opts ExplorerQueryOptions)
@@ -316,6 +331,31 @@ type Gui struct {
 	smartDashboard *SmartDashboard
 	tableFactory   *TableFactory
 	showWelcome    bool
+
+	// Pending changes quick filters
+	refreshPendingChanges func()
+
+	globalSearch *globalSearchView
+
+	// pendingLink is a badgermaps:// link waiting for first-time setup or
+	// for the window to start.
+	pendingLink *app.DeepLink
+
+	// restoringSession is set at launch until the saved tab and details
+	// pane are restored; restoreExplorerTable waits for the table list.
+	restoringSession     bool
+	restoreExplorerTable string
+
+	// conflictChoices holds the side picked per account field in the
+	// conflict dialog with "remember" checked, for this session only.
+	conflictChoices map[string]bool
+	// pushHitConflicts is set when a push holds back a conflicting change,
+	// so the conflict dialog opens once the push completes.
+	pushHitConflicts atomic.Bool
+	// fetchedStaleAccounts holds the accounts whose details were fetched
+	// on opening after a low-bandwidth pull, so a failed fetch is not
+	// retried every time the account is shown.
+	fetchedStaleAccounts map[int]bool
 }
 
 func (ui *Gui) themeColor(name fyne.ThemeColorName) color.Color {
@@ -375,9 +415,11 @@ func (ui *Gui) FyneApp() fyne.App { return ui.fyneApp }
 // NewGuiForScreenshots builds a minimal GUI instance suitable for headless rendering (tests/screenshots).
 func NewGuiForScreenshots(a *app.App, fyApp fyne.App) *Gui {
 	ui := &Gui{
-		app:        a,
-		fyneApp:    fyApp,
-		logBinding: binding.NewStringList(),
+		app:                  a,
+		fyneApp:              fyApp,
+		logBinding:           binding.NewStringList(),
+		conflictChoices:      make(map[string]bool),
+		fetchedStaleAccounts: make(map[int]bool),
 	}
 	ui.presenter = NewGuiPresenter(a, ui)
 	ui.tableFactory = NewTableFactory(ui)
@@ -391,6 +433,15 @@ func NewGuiForScreenshots(a *app.App, fyApp fyne.App) *Gui {
 
 // Launch initializes and runs the GUI
 func Launch(a *app.App, icon fyne.Resource) {
+	launch(a, icon, nil)
+}
+
+// LaunchLink runs the GUI and opens link once the window is up.
+func LaunchLink(a *app.App, icon fyne.Resource, link app.DeepLink) {
+	launch(a, icon, &link)
+}
+
+func launch(a *app.App, icon fyne.Resource, link *app.DeepLink) {
 	a.Events.Dispatch(events.Debugf("gui", "GUI initiated"))
 	a.Events.Dispatch(events.Infof("gui", "Waiting for database connection to settle..."))
 
@@ -399,11 +450,15 @@ func Launch(a *app.App, icon fyne.Resource) {
 	window := fyneApp.NewWindow("Badger Maps Sync")
 
 	ui := &Gui{
-		app:             a,
-		fyneApp:         fyneApp,
-		window:          window,
-		logBinding:      binding.NewStringList(),
-		terminalVisible: false, // Default to details view
+		app:                  a,
+		fyneApp:              fyneApp,
+		window:               window,
+		logBinding:           binding.NewStringList(),
+		terminalVisible:      false, // Default to details view
+		pendingLink:          link,
+		restoringSession:     true,
+		conflictChoices:      make(map[string]bool),
+		fetchedStaleAccounts: make(map[int]bool),
 	}
 
 	ui.applyThemePreference()
@@ -424,53 +479,32 @@ func Launch(a *app.App, icon fyne.Resource) {
 	// Check if we should show welcome screen (first time setup or no config)
 	ui.showWelcome = (a.API == nil || a.API.APIKey == "") || (a.DB == nil || a.DB.GetType() == "")
 
-	// Subscribe to events to refresh the events tab
+	ui.refresh = newRefreshBatcher(uiRefreshDelay, fyne.Do, ui.isTabSelected)
+
+	// Changes to one event action rebuild its card; adding or removing
+	// actions rebuilds the Actions tab.
 	eventListener := func(e events.Event) {
 		if ui.app.State.Debug {
 			a.Events.Dispatch(events.Debugf("gui", "GUI received event: %s", e.Type))
 		}
-		fyne.Do(func() {
-			if ui.tabs != nil {
-				for _, tab := range ui.tabs.Items {
-					if tab.Text == "Actions" {
-						// Re-create the content of the events tab
-						tab.Content = ui.createActionsTab()
-						ui.tabs.Refresh()
-						break
-					}
-				}
-			}
+		name := actionConfigName(e)
+		ui.refresh.Request("Actions", "actions:"+name, func() {
+			ui.refreshActionCard(name)
 		})
 	}
 	a.Events.Subscribe("action.config.*", eventListener)
 
-	// Subscribe to logging and action events
+	// Log lines are appended in batches, with one scroll per batch.
 	logListener := func(e events.Event) {
-		var msg string
-		switch e.Type {
-		case "log":
-			logPayload, ok := e.Payload.(events.LogPayload)
-			if !ok {
-				return
-			}
-			msg = fmt.Sprintf("[%s] [%s] %s", logPayload.Level.String(), e.Source, logPayload.Message)
-		}
-
-		if msg != "" {
-			lines := strings.Split(msg, "\n")
-			fyne.Do(func() {
-				ui.logMutex.Lock()
-				defer ui.logMutex.Unlock()
-				for _, line := range lines {
-					ui.logBinding.Append(line)
-				}
-				// Avoid early crash if list is not yet fully initialised
-				if ui.logView != nil {
-					defer func() { _ = recover() }()
-					ui.logView.ScrollToBottom()
-				}
-			})
+		logPayload, ok := e.Payload.(events.LogPayload)
+		if e.Type != "log" || !ok {
+			return
 		}
+		msg := fmt.Sprintf("[%s] [%s] %s", logPayload.Level.String(), e.Source, logPayload.Message)
+		ui.logMutex.Lock()
+		ui.logPending = append(ui.logPending, strings.Split(msg, "\n")...)
+		ui.logMutex.Unlock()
+		ui.refresh.Request("", "log", ui.flushLog)
 	}
 	a.Events.Subscribe("log", logListener)
 
@@ -486,8 +520,12 @@ func Launch(a *app.App, icon fyne.Resource) {
 				ui.ShowToast(fmt.Sprintf("Successfully pulled %s.", e.Source))
 			})
 		case "pull.error":
+			message := fmt.Sprintf("Error pulling %s.", e.Source)
+			if payload, ok := e.Payload.(events.ErrorPayload); ok {
+				message = withHint(message, payload.Error)
+			}
 			fyne.Do(func() {
-				ui.ShowToast(fmt.Sprintf("Error pulling %s.", e.Source))
+				ui.ShowToast(message)
 			})
 		case "pull.group.start":
 			fyne.Do(func() {
@@ -505,15 +543,58 @@ func Launch(a *app.App, icon fyne.Resource) {
 	}
 	a.Events.Subscribe("pull.*", pullNotificationListener)
 
-	// Subscribe to connection status changes to refresh UI
+	// Subscribe to connection status changes to refresh UI. Updates are
+	// numbered; one that arrives after a newer one has been shown is skipped.
+	// Each time both connections come up, the profile's field labels are
+	// read again so the Explorer shows the current ones.
+	var shownConnectionSeq uint64
+	var fullyConnected bool
 	connectionListener := func(e events.Event) {
+		payload, ok := e.Payload.(events.ConnectionStatusPayload)
 		fyne.Do(func() {
-			ui.RefreshConfigTab()
-			ui.RefreshHomeTab()
+			if ok {
+				if payload.Seq <= shownConnectionSeq {
+					return
+				}
+				shownConnectionSeq = payload.Seq
+				connected := payload.API && payload.Database
+				if connected && !fullyConnected {
+					go ui.detectFieldSchema()
+				}
+				// The Sync Center and Explorer are only built while both
+				// connections are up, as when the API test that runs in
+				// the background at startup succeeds.
+				if connected != fullyConnected {
+					fullyConnected = connected
+					ui.refresh.Request("", "tabs", ui.RefreshAllTabs)
+					return
+				}
+			}
+			ui.refresh.Request("Configuration", "config", ui.RefreshConfigTab)
+			ui.refresh.Request("Home", "home", ui.RefreshHomeTab)
 		})
 	}
 	a.Events.Subscribe("connection.status.changed", connectionListener)
 
+	// A push that held back conflicting changes asks how to resolve them.
+	a.Events.Subscribe("push.conflict", func(e events.Event) {
+		ui.pushHitConflicts.Store(true)
+	})
+	a.Events.Subscribe("push.complete", func(e events.Event) {
+		if e.Source != "accounts" || !ui.pushHitConflicts.Swap(false) || a.ConflictResolution() != app.ConflictAsk {
+			return
+		}
+		fyne.Do(ui.showAccountConflicts)
+	})
+
+	// Alerts fired or resolved by the alert monitor show in a red banner.
+	a.Events.Subscribe("alert.*", func(e events.Event) {
+		ui.refresh.Request("", "alerts", ui.refreshAlertBanner)
+	})
+	stopAlerts := make(chan struct{})
+	defer close(stopAlerts)
+	a.StartAlertMonitor(stopAlerts)
+
 	// Optional launch scaling for screenshots or HiDPI preview
 	baseW, baseH := float32(1000), float32(600)
 	scale := float32(1.0)
@@ -532,11 +613,26 @@ func Launch(a *app.App, icon fyne.Resource) {
 		SetDefaultRightPaneWidth(360 * scale)
 	}
 
+	endGUIBuild := a.StartPhase(app.StartupGUIBuild)
 	window.SetContent(ui.createContent())
+	endGUIBuild()
+	ui.registerGlobalSearchShortcut()
 	// Set initial size and allow resizing
-	window.Resize(fyne.NewSize(baseW*scale, baseH*scale))
+	window.Resize(ui.restoredWindowSize(fyne.NewSize(baseW*scale, baseH*scale), scale != 1.0))
 	window.SetFixedSize(false) // Allow resizing
 	window.CenterOnScreen()
+	window.SetCloseIntercept(func() {
+		ui.saveSession()
+		window.Close()
+	})
+	stopLinks := ui.listenForDeepLinks()
+	defer stopLinks()
+	fyneApp.Lifecycle().SetOnStarted(func() {
+		a.LogStartupSummary("Window shown")
+		ui.openPendingLink()
+		ui.showPendingTours()
+		ui.startupUpdateCheck()
+	})
 	window.ShowAndRun()
 }
 
@@ -572,6 +668,8 @@ func (ui *Gui) createContent() fyne.CanvasObject {
 			// When welcome is complete, switch to main content
 			ui.showWelcome = false
 			ui.window.SetContent(ui.createMainContent())
+			ui.openPendingLink()
+			ui.showPendingTours()
 		})
 		return ui.welcomeScreen.CreateContent()
 	}
@@ -601,13 +699,12 @@ func (ui *Gui) createMainContent() fyne.CanvasObject {
 	actionsTab := container.NewTabItemWithIcon("Actions", theme.ViewRefreshIcon(), ui.createActionsTab())
 	serverTab := container.NewTabItemWithIcon("Server", theme.ComputerIcon(), ui.createServerTab())
 
-	tabs := []*container.TabItem{
-		homeTab,
-		syncTab,
-		explorerTab,
-		actionsTab,
-		serverTab,
-		configTab,
+	// gui_tabs may hide tabs; the rest keep their order.
+	var tabs []*container.TabItem
+	for _, tab := range []*container.TabItem{homeTab, syncTab, explorerTab, actionsTab, serverTab, configTab} {
+		if ui.app.GuiTabVisible(tab.Text) {
+			tabs = append(tabs, tab)
+		}
 	}
 
 	if ui.app.State.Debug {
@@ -615,13 +712,21 @@ func (ui *Gui) createMainContent() fyne.CanvasObject {
 	}
 
 	ui.tabs = container.NewAppTabs(tabs...)
+	ui.tabs.OnSelected = func(tab *container.TabItem) {
+		ui.refresh.TabShown(tab.Text)
+	}
 
 	ui.progressBar = widget.NewProgressBar()
 	ui.progressTitle = widget.NewLabel("")
 	ui.progressContainer = container.NewVBox(ui.progressTitle, ui.progressBar)
 	ui.progressContainer.Hide()
 
-	mainContent := container.NewBorder(nil, ui.progressContainer, nil, nil, ui.tabs)
+	ui.environmentBanner = container.NewStack()
+	ui.refreshEnvironmentBanner()
+	ui.alertBanner = container.NewStack()
+	ui.refreshAlertBanner()
+
+	mainContent := container.NewBorder(container.NewVBox(ui.environmentBanner, ui.alertBanner), ui.progressContainer, nil, nil, ui.tabs)
 
 	// Initialize log view
 	ui.logView = widget.NewListWithData(ui.logBinding,
@@ -677,6 +782,7 @@ func (ui *Gui) createMainContent() fyne.CanvasObject {
 	if ui.syncCenter != nil {
 		ui.syncCenter.applyStoredDetail()
 	}
+	ui.restoreSession()
 
 	return container.NewStack(mainContent, ui.rightPaneOverlay, floatingToggle)
 }
@@ -814,18 +920,21 @@ func (ui *Gui) createHomeTab() fyne.CanvasObject {
 	}
 	serverStatusLabel := widget.NewLabel(serverStatusText) // No color, it's just a state
 
-	// Schema Status
-	schemaValid := false
+	// Schema Status, from the cached check; the tab is rebuilt when a
+	// background check finishes.
+	schemaStatus := app.SchemaUnchecked
 	if dbConnected {
-		if err := ui.app.DB.ValidateSchema(ui.app.State); err == nil {
-			schemaValid = true
-		}
+		schemaStatus, _ = ui.app.Connections().Schema()
 	}
 	schemaStatusText := "Invalid"
 	schemaColor := theme.ErrorColor()
-	if schemaValid {
+	switch schemaStatus {
+	case app.SchemaValid:
 		schemaStatusText = "Valid"
 		schemaColor = theme.PrimaryColor()
+	case app.SchemaChecking:
+		schemaStatusText = "Checking..."
+		schemaColor = theme.DisabledColor()
 	}
 	schemaStatusLabel := canvas.NewText(schemaStatusText, schemaColor)
 
@@ -896,6 +1005,7 @@ func (ui *Gui) RefreshAllTabs() {
 		}
 	}
 
+	ui.refreshEnvironmentBanner()
 	ui.tabs.Refresh()
 }
 
@@ -926,14 +1036,25 @@ func (ui *Gui) createRightPaneHeader() fyne.CanvasObject {
 		ui.showRightPane()
 	})
 
-	buttonRow := container.NewHBox(detailsButton, logButton)
+	searchButton := widget.NewButtonWithIcon("Search", theme.SearchIcon(), ui.ShowGlobalSearch)
+
+	buttonRow := container.NewHBox(detailsButton, logButton, searchButton)
 	return container.NewBorder(nil, nil, nil, nil, buttonRow)
 }
 
 func (ui *Gui) createDisabledTabView(configTab *container.TabItem) fyne.CanvasObject {
+	if ui.app.Connections().Status().APIChecking {
+		label := widget.NewLabel("Checking the API connection...")
+		label.Alignment = fyne.TextAlignCenter
+		return container.NewCenter(container.NewVBox(widget.NewProgressBarInfinite(), label))
+	}
 	label := widget.NewLabel("API or Database not configured correctly.")
 	label.Alignment = fyne.TextAlignCenter
 	label.Wrapping = fyne.TextWrapWord
+	if configTab == nil || !ui.app.GuiTabVisible(app.GuiTabConfiguration) {
+		label.SetText("API or Database not configured correctly. Ask your administrator to check the configuration.")
+		return container.NewCenter(label)
+	}
 
 	button := widget.NewButton("Go to Configuration", func() {
 		ui.tabs.Select(configTab)
@@ -1072,12 +1193,16 @@ func (ui *Gui) createPullTab() fyne.CanvasObject {
 	pullCheckinsButton := widget.NewButtonWithIcon("Pull All Check-ins", theme.DownloadIcon(), ui.presenter.HandlePullCheckins)
 	pullRoutesButton := widget.NewButtonWithIcon("Pull All Routes", theme.DownloadIcon(), ui.presenter.HandlePullRoutes)
 	pullProfileButton := widget.NewButtonWithIcon("Pull User Profile", theme.AccountIcon(), ui.presenter.HandlePullProfile)
+	pullLocationsButton := widget.NewButtonWithIcon("Refresh Locations Only", theme.DownloadIcon(), ui.presenter.HandlePullLocations)
+	pullDatasetsButton := widget.NewButtonWithIcon("Refresh Datasets Only", theme.DownloadIcon(), ui.presenter.HandlePullDatasets)
 
 	bulkPullCard := widget.NewCard("Pull Data Sets", "", container.NewVBox(
 		pullAccountsButton,
 		pullCheckinsButton,
 		pullRoutesButton,
 		pullProfileButton,
+		pullLocationsButton,
+		pullDatasetsButton,
 	))
 
 	pullAllButton := widget.NewButtonWithIcon("Run Full Pull (All Data)", theme.ViewRefreshIcon(), ui.presenter.HandlePullGroup)
@@ -1095,31 +1220,28 @@ func (ui *Gui) createPushTab() fyne.CanvasObject {
 	pushCheckinsButton := widget.NewButtonWithIcon("Push Check-in Changes", theme.UploadIcon(), ui.presenter.HandlePushCheckins)
 	pushAllButton := widget.NewButtonWithIcon("Push All Changes", theme.ViewRefreshIcon(), ui.presenter.HandlePushAll)
 
+	resolveConflictsButton := widget.NewButtonWithIcon("Resolve Conflicts", theme.QuestionIcon(), ui.showAccountConflicts)
+	planButton := widget.NewButtonWithIcon("Plan Push", theme.InfoIcon(), ui.presenter.HandleShowPushPlan)
+
 	pushCard := widget.NewCard("Push Pending Changes", "", container.NewVBox(
 		pushAccountsButton,
 		pushCheckinsButton,
 		widget.NewSeparator(),
 		pushAllButton,
+		planButton,
+		resolveConflictsButton,
 	))
 
-	tableContainer := container.NewMax()
-	entityType := "accounts" // Default view
-
-	radio := widget.NewRadioGroup([]string{"accounts", "checkins"}, func(selected string) {
-		entityType = selected
-		tableContainer.Objects = []fyne.CanvasObject{ui.createPendingChangesTable(entityType)}
-		tableContainer.Refresh()
-	})
-	radio.SetSelected("accounts")
+	changesCard := widget.NewCard("View Pending Changes", "", ui.createPendingChangesView())
+	recycleCard := widget.NewCard("Recycle Bin", "Accounts deleted by a push, kept so they can be restored", ui.createRecycleBinView())
 
-	tableContainer.Objects = []fyne.CanvasObject{ui.createPendingChangesTable(entityType)}
-
-	changesCard := widget.NewCard("View Pending Changes", "", container.NewBorder(radio, nil, nil, nil, tableContainer))
-
-	return container.NewVScroll(container.NewBorder(pushCard, nil, nil, nil, changesCard))
+	return container.NewVScroll(container.NewBorder(pushCard, recycleCard, nil, nil, changesCard))
 }
 
 func (ui *Gui) RefreshPushTab() {
+	if ui.refreshPendingChanges != nil {
+		ui.refreshPendingChanges()
+	}
 	if ui.tabs != nil {
 		for _, tab := range ui.tabs.Items {
 			if tab.Text == "Push" {
@@ -1131,19 +1253,76 @@ func (ui *Gui) RefreshPushTab() {
 	}
 }
 
-func (ui *Gui) createPendingChangesTable(entityType string) fyne.CanvasObject {
-	options := push.PushFilterOptions{
-		Status:  "pending",
-		OrderBy: "date_desc",
+// createPendingChangesView builds the pending changes browser with quick
+// filter chips. The last selected chip is remembered in the config file.
+func (ui *Gui) createPendingChangesView() fyne.CanvasObject {
+	entityType := "accounts"
+	filter := push.ParseQuickFilter(ui.app.Config.PushQuickFilter)
+
+	chipsRow := container.NewHBox()
+	tableContainer := container.NewMax()
+	tableSizer := canvas.NewRectangle(color.Transparent)
+	tableSizer.SetMinSize(fyne.NewSize(0, 280))
+
+	var rebuild func()
+	rebuild = func() {
+		counts, err := push.CountQuickFilters(ui.app, entityType)
+		if err != nil {
+			ui.app.Events.Dispatch(events.Debugf("gui", "unable to count pending changes: %v", err))
+		}
+		chipsRow.Objects = nil
+		for _, f := range push.QuickFilters() {
+			current := f
+			label := current.Label()
+			if counts != nil {
+				label = fmt.Sprintf("%s (%d)", label, counts[current])
+			}
+			chip := widget.NewButton(label, func() {
+				if current == filter {
+					return
+				}
+				filter = current
+				ui.presenter.HandlePushQuickFilterChanged(string(current))
+				rebuild()
+			})
+			if current == filter {
+				chip.Importance = widget.HighImportance
+			} else {
+				chip.Importance = widget.LowImportance
+			}
+			chipsRow.Add(chip)
+		}
+		chipsRow.Refresh()
+		tableContainer.Objects = []fyne.CanvasObject{ui.createPendingChangesTable(entityType, filter)}
+		tableContainer.Refresh()
 	}
 
-	results, err := push.GetFilteredPendingChanges(ui.app, entityType, options)
+	radio := widget.NewRadioGroup([]string{"accounts", "checkins"}, func(selected string) {
+		if selected == "" || selected == entityType {
+			return
+		}
+		entityType = selected
+		rebuild()
+	})
+	radio.Horizontal = true
+	radio.SetSelected(entityType)
+
+	rebuild()
+	ui.refreshPendingChanges = func() { fyne.Do(rebuild) }
+
+	header := container.NewVBox(radio, container.NewHScroll(chipsRow))
+	return container.NewBorder(header, nil, nil, nil, container.NewStack(tableSizer, tableContainer))
+}
+
+func (ui *Gui) createPendingChangesTable(entityType string, filter push.QuickFilter) fyne.CanvasObject {
+	results, err := push.GetQuickFilteredChanges(ui.app, entityType, filter)
 	if err != nil {
 		return widget.NewLabel(fmt.Sprintf("Error fetching changes: %v", err))
 	}
 
 	var headers []string
 	var data [][]string
+	var showDiff func(row int)
 
 	switch entityType {
 	case "accounts":
@@ -1152,6 +1331,7 @@ func (ui *Gui) createPendingChangesTable(entityType string) fyne.CanvasObject {
 		if !ok {
 			return widget.NewLabel("Error: Could not load account changes.")
 		}
+		showDiff = func(row int) { ui.showAccountChangeDiff(changes[row]) }
 		for _, c := range changes {
 			data = append(data, []string{
 				fmt.Sprintf("%d", c.ChangeId),
@@ -1168,6 +1348,7 @@ func (ui *Gui) createPendingChangesTable(entityType string) fyne.CanvasObject {
 		if !ok {
 			return widget.NewLabel("Error: Could not load check-in changes.")
 		}
+		showDiff = func(row int) { ui.showCheckinChangeDiff(changes[row]) }
 		for _, c := range changes {
 			data = append(data, []string{
 				fmt.Sprintf("%d", c.ChangeId),
@@ -1184,7 +1365,10 @@ func (ui *Gui) createPendingChangesTable(entityType string) fyne.CanvasObject {
 	}
 
 	if len(data) == 0 {
-		return widget.NewLabel(fmt.Sprintf("No pending %s changes found.", entityType))
+		if filter == push.QuickFilterAll {
+			return widget.NewLabel(fmt.Sprintf("No %s changes found.", entityType))
+		}
+		return widget.NewLabel(fmt.Sprintf("No %s changes match \"%s\".", entityType, filter.Label()))
 	}
 
 	dataTable := widget.NewTable(
@@ -1210,18 +1394,7 @@ func (ui *Gui) createPendingChangesTable(entityType string) fyne.CanvasObject {
 			dataTable.Unselect(id)
 			return
 		}
-		selectedData := data[id.Row-1]
-
-		var details strings.Builder
-		for i, header := range headers {
-			details.WriteString(fmt.Sprintf("%s: %s\n", header, selectedData[i]))
-		}
-
-		detailsEntry := widget.NewMultiLineEntry()
-		detailsEntry.SetText(details.String())
-		detailsEntry.Disable()
-
-		ui.ShowDetails(detailsEntry)
+		showDiff(id.Row - 1)
 	}
 
 	return dataTable
@@ -1245,104 +1418,128 @@ func (ui *Gui) createActionsTab() fyne.CanvasObject {
 		actionsContent.Add(empty)
 	}
 
+	ui.actionCards = make(map[string]*fyne.Container, len(eventActions))
 	for _, eventAction := range eventActions {
-		ea := eventAction // Capture loop variable
-		actionsContainer := container.NewVBox()
-		for i, action := range ea.Run {
-			ac := action
-			idx := i
-			var iconResource fyne.Resource
-			var labelText string
-
-			switch ac.Type {
-			case "exec":
-				iconResource = theme.FileApplicationIcon()
-				labelText = fmt.Sprintf("Exec: %s", ac.Args["command"])
-			case "db":
-				iconResource = theme.StorageIcon()
-				labelText = func() string {
-					if ac.Args == nil {
-						return "DB action"
-					}
-					if cmd, ok := ac.Args["command"].(string); ok && cmd != "" {
-						return fmt.Sprintf("DB command: %s", cmd)
-					}
-					if fn, ok := ac.Args["function"].(string); ok && fn != "" {
-						return fmt.Sprintf("DB function: %s", fn)
-					}
-					if proc, ok := ac.Args["procedure"].(string); ok && proc != "" {
-						return fmt.Sprintf("DB procedure: %s", proc)
+		card := container.NewStack(ui.createActionCard(eventAction))
+		ui.actionCards[eventAction.Name] = card
+		actionsContent.Add(card)
+	}
+
+	addButton := widget.NewButtonWithIcon("Add Action", theme.ContentAddIcon(), func() {
+		ui.createActionPopup(nil, -1)
+	})
+	allRunsButton := widget.NewButtonWithIcon("All Runs", theme.HistoryIcon(), func() {
+		ui.presenter.HandleShowActionRuns("")
+	})
+
+	return container.NewBorder(nil, container.NewBorder(nil, nil, nil, allRunsButton, addButton), nil, nil, container.NewVScroll(actionsContent))
+}
+
+// createActionCard builds the card of one event action with its steps.
+func (ui *Gui) createActionCard(ea action.EventAction) fyne.CanvasObject {
+	actionsContainer := container.NewVBox()
+	for i, action := range ea.Run {
+		ac := action
+		idx := i
+		var iconResource fyne.Resource
+		var labelText string
+
+		switch ac.Type {
+		case "exec":
+			iconResource = theme.FileApplicationIcon()
+			labelText = fmt.Sprintf("Exec: %s", ac.Args["command"])
+		case "db":
+			iconResource = theme.StorageIcon()
+			labelText = func() string {
+				if ac.Args == nil {
+					return "DB action"
+				}
+				if cmd, ok := ac.Args["command"].(string); ok && cmd != "" {
+					return fmt.Sprintf("DB command: %s", cmd)
+				}
+				if fn, ok := ac.Args["function"].(string); ok && fn != "" {
+					return fmt.Sprintf("DB function: %s", fn)
+				}
+				if proc, ok := ac.Args["procedure"].(string); ok && proc != "" {
+					return fmt.Sprintf("DB procedure: %s", proc)
+				}
+				if query, ok := ac.Args["query"].(string); ok && query != "" {
+					trimmed := strings.TrimSpace(query)
+					runes := []rune(trimmed)
+					if len(runes) > 32 {
+						trimmed = string(runes[:32]) + "..."
 					}
-					if query, ok := ac.Args["query"].(string); ok && query != "" {
-						trimmed := strings.TrimSpace(query)
-						runes := []rune(trimmed)
-						if len(runes) > 32 {
-							trimmed = string(runes[:32]) + "..."
+					return fmt.Sprintf("DB query: %s", trimmed)
+				}
+				return "DB action"
+			}()
+		case "api":
+			iconResource = theme.ComputerIcon()
+			labelText = fmt.Sprintf("API: %s", ac.Args["endpoint"])
+		case "backup":
+			iconResource = theme.DownloadIcon()
+			labelText = fmt.Sprintf("Backup: %s", ac.Args["path"])
+		case "digest":
+			iconResource = theme.MailSendIcon()
+			labelText = fmt.Sprintf("Digest: %s", ac.Args["name"])
+		default:
+			iconResource = theme.HelpIcon()
+			labelText = "Unknown action"
+		}
+
+		label := widget.NewLabel(labelText)
+		icon := widget.NewIcon(iconResource)
+
+		toolbar := widget.NewToolbar(
+			widget.NewToolbarAction(theme.MediaPlayIcon(), func() {
+				ui.app.ExecuteNamedAction(ea.Name, app.ActionTriggerManual, ac, nil)
+			}),
+			widget.NewToolbarSeparator(),
+			widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
+				ui.createActionPopup(&ea, idx)
+			}),
+			widget.NewToolbarSeparator(),
+			widget.NewToolbarAction(theme.DeleteIcon(), func() {
+				dialog.ShowConfirm("Delete Action", "Are you sure you want to delete this action?", func(confirm bool) {
+					if confirm {
+						err := ui.app.RemoveEventAction(ea.Name, idx)
+						if err != nil {
+							ui.app.Events.Dispatch(events.Errorf("gui", "Error removing action: %v", err))
 						}
-						return fmt.Sprintf("DB query: %s", trimmed)
 					}
-					return "DB action"
-				}()
-			case "api":
-				iconResource = theme.ComputerIcon()
-				labelText = fmt.Sprintf("API: %s", ac.Args["endpoint"])
-			default:
-				iconResource = theme.HelpIcon()
-				labelText = "Unknown action"
-			}
-
-			label := widget.NewLabel(labelText)
-			icon := widget.NewIcon(iconResource)
-
-			toolbar := widget.NewToolbar(
-				widget.NewToolbarAction(theme.MediaPlayIcon(), func() {
-					ui.app.ExecuteAction(ac)
-				}),
-				widget.NewToolbarSeparator(),
-				widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
-					ui.createActionPopup(&ea, idx)
-				}),
-				widget.NewToolbarSeparator(),
-				widget.NewToolbarAction(theme.DeleteIcon(), func() {
-					dialog.ShowConfirm("Delete Action", "Are you sure you want to delete this action?", func(confirm bool) {
-						if confirm {
-							err := ui.app.RemoveEventAction(ea.Name, idx)
-							if err != nil {
-								ui.app.Events.Dispatch(events.Errorf("gui", "Error removing action: %v", err))
-							}
-						}
-					}, ui.window)
-				}),
-			)
-			actionsContainer.Add(container.NewBorder(nil, nil, icon, toolbar, label))
-		}
-
-		if len(actionsContainer.Objects) == 0 {
-			actionsContainer.Add(widget.NewLabel("No steps configured for this action."))
-		}
+				}, ui.window)
+			}),
+		)
+		actionsContainer.Add(container.NewBorder(nil, nil, icon, toolbar, label))
+	}
 
-		friendlyEvent := formatEventName(ea.Event)
-		friendlySource := "Any"
-		if strings.TrimSpace(ea.Source) != "" {
-			friendlySource = formatEventName(ea.Source)
-		}
+	if len(actionsContainer.Objects) == 0 {
+		actionsContainer.Add(widget.NewLabel("No steps configured for this action."))
+	}
 
-		cardTitle := friendlyEvent
-		subtitle := fmt.Sprintf("Source: %s", friendlySource)
+	friendlyEvent := formatEventName(ea.Event)
+	friendlySource := "Any"
+	if strings.TrimSpace(ea.Source) != "" {
+		friendlySource = formatEventName(ea.Source)
+	}
 
-		card := ui.newSectionCard(
-			cardTitle,
-			subtitle,
-			actionsContainer,
-		)
-		actionsContent.Add(card)
+	cardTitle := friendlyEvent
+	subtitle := fmt.Sprintf("Source: %s", friendlySource)
+	if ea.Disabled {
+		subtitle += " (disabled)"
 	}
 
-	addButton := widget.NewButtonWithIcon("Add Action", theme.ContentAddIcon(), func() {
-		ui.createActionPopup(nil, -1)
+	runsButton := widget.NewButtonWithIcon("Recent Runs", theme.HistoryIcon(), func() {
+		ui.presenter.HandleShowActionRuns(ea.Name)
 	})
+	runsButton.Importance = widget.LowImportance
 
-	return container.NewBorder(nil, addButton, nil, nil, container.NewVScroll(actionsContent))
+	return ui.newSectionCard(
+		cardTitle,
+		subtitle,
+		actionsContainer,
+		container.NewHBox(runsButton),
+	)
 }
 
 func (ui *Gui) createActionPopup(eventAction *action.EventAction, actionIndex int) {
@@ -1611,15 +1808,44 @@ func (ui *Gui) createActionPopup(eventAction *action.EventAction, actionIndex in
 		performInsert(token)
 	})
 
+	templateModeSelect := widget.NewSelect([]string{action.TemplateModeLenient, action.TemplateModeStrict}, nil)
+	templateModeSelect.SetSelected(action.TemplateModeLenient)
+	if mode, ok := actionConfig.Args["templates"].(string); ok && mode != "" {
+		templateModeSelect.SetSelected(mode)
+	}
+
+	var actionTabs *container.AppTabs
+	previewButton := widget.NewButtonWithIcon("Preview", theme.VisibilityIcon(), func() {
+		var inputs []string
+		switch actionTabs.Selected().Text {
+		case "Exec":
+			inputs = []string{strings.TrimSpace(execCommandEntry.Text + " " + execArgsEntry.Text)}
+		case "Database":
+			for _, entry := range []*widget.Entry{dbCommandEntry, dbFunctionEntry, dbProcedureEntry, dbQueryEntry} {
+				if text := strings.TrimSpace(entry.Text); text != "" {
+					inputs = append(inputs, text)
+				}
+			}
+		case "API":
+			inputs = []string{apiEndpointEntry.Text, apiDataEntry.Text}
+		}
+		ui.showTemplatePreview(strings.TrimSpace(eventEntry.Text), strings.TrimSpace(sourceEntry.Text), strings.Join(inputs, "\n"), templateModeSelect.Selected)
+	})
+
 	tokenControls := container.NewVBox(
 		widget.NewSeparator(),
 		container.NewHBox(
 			widget.NewLabel("Event tokens"),
 			container.NewAdaptiveGrid(2, tokenSelect, insertButton),
 		),
+		container.NewHBox(
+			widget.NewLabel("Templates"),
+			templateModeSelect,
+			previewButton,
+		),
 	)
 
-	actionTabs := container.NewAppTabs(execTab, dbTab, apiTab)
+	actionTabs = container.NewAppTabs(execTab, dbTab, apiTab)
 	switch actionConfig.Type {
 	case "db":
 		actionTabs.Select(dbTab)
@@ -1701,6 +1927,10 @@ func (ui *Gui) createActionPopup(eventAction *action.EventAction, actionIndex in
 			}
 		}
 
+		if (newAction.Type == "exec" || newAction.Type == "db") && templateModeSelect.Selected == action.TemplateModeStrict {
+			newAction.Args["templates"] = action.TemplateModeStrict
+		}
+
 		eventValue := strings.TrimSpace(eventEntry.Text)
 		if eventValue == "" {
 			ui.app.Events.Dispatch(events.Warningf("gui", "Event type is required"))
@@ -1739,6 +1969,43 @@ func (ui *Gui) createActionPopup(eventAction *action.EventAction, actionIndex in
 	d.Show()
 }
 
+// showTemplatePreview renders input against an editable sample payload for
+// the event so template paths can be checked before saving.
+func (ui *Gui) showTemplatePreview(eventType, source, input, mode string) {
+	payloadEntry := widget.NewMultiLineEntry()
+	payloadEntry.SetText(events.SamplePayloadJSON(eventType, source))
+	payloadEntry.SetMinRowsVisible(8)
+	output := widget.NewLabel("")
+	output.Wrapping = fyne.TextWrapWord
+
+	render := func() {
+		var payload interface{}
+		if err := json.Unmarshal([]byte(payloadEntry.Text), &payload); err != nil {
+			output.SetText(fmt.Sprintf("Sample payload is not valid JSON: %v", err))
+			return
+		}
+		ctx := &action.ExecutionContext{EventType: eventType, Source: source, Payload: payload}
+		rendered, err := action.RenderTemplate(input, ctx, mode)
+		if err != nil {
+			output.SetText(fmt.Sprintf("Error: %v", err))
+			return
+		}
+		output.SetText(rendered)
+	}
+	payloadEntry.OnChanged = func(string) { render() }
+	render()
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Sample payload (JSON)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		payloadEntry,
+		widget.NewLabelWithStyle(fmt.Sprintf("Rendered (%s)", mode), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		output,
+	)
+	dlg := dialog.NewCustom("Template Preview", "Close", container.NewVScroll(content), ui.window)
+	dlg.Resize(fyne.NewSize(520, 480))
+	dlg.Show()
+}
+
 type tokenInsertionTarget struct {
 	entry     *widget.Entry
 	row       int
@@ -1823,6 +2090,205 @@ func cursorForIndex(text string, index int) (int, int) {
 	return row, col
 }
 
+// showExplorerCellEditor edits one Accounts cell from the Explorer and stages
+// the edit as a pending change.
+func (ui *Gui) showExplorerCellEditor(headers []string, col int, rowData []string, value string) {
+	if col < 0 || col >= len(headers) || col >= len(rowData) {
+		return
+	}
+	column := headers[col]
+	if _, ok := app.AccountFieldForColumn(column); !ok {
+		ui.ShowToast(fmt.Sprintf("%s cannot be edited from the Explorer.", column))
+		return
+	}
+	accountID := 0
+	for i, header := range headers {
+		if header == "AccountId" && i < len(rowData) {
+			accountID, _ = strconv.Atoi(strings.TrimSpace(rowData[i]))
+			break
+		}
+	}
+	if accountID <= 0 {
+		ui.ShowToast("This row has no AccountId to stage a change for.")
+		return
+	}
+	ui.showAccountFieldEditor(accountID, column, rowData[col], value, nil)
+}
+
+// showCSVExportOptions asks for the CSV export options, starting from
+// csv_export in the config, saves them, and passes them to onExport.
+func (ui *Gui) showCSVExportOptions(onExport func(app.CSVExportConfig)) {
+	options := ui.app.CSVExport()
+	orDefault := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return strings.ToLower(value)
+	}
+	delimiter := widget.NewSelect(app.CSVDelimiters, nil)
+	delimiter.SetSelected(orDefault(options.Delimiter, app.CSVDelimiterComma))
+	encoding := widget.NewSelect(app.CSVEncodings, nil)
+	encoding.SetSelected(orDefault(options.Encoding, app.CSVEncodingUTF8))
+	dateFormat := widget.NewSelect(app.CSVDateFormats, nil)
+	dateFormat.SetSelected(orDefault(options.DateFormat, app.CSVDateAsStored))
+	bom := widget.NewCheck("Byte order mark (for Excel)", nil)
+	bom.SetChecked(options.BOM)
+	quoteHeaders := widget.NewCheck("Quote headers", nil)
+	quoteHeaders.SetChecked(options.QuoteHeaders)
+	encoding.OnChanged = func(value string) {
+		// UTF-16 always starts with a byte order mark.
+		if value == app.CSVEncodingUTF16LE {
+			bom.Disable()
+		} else {
+			bom.Enable()
+		}
+	}
+	encoding.OnChanged(encoding.Selected)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Delimiter", delimiter),
+		widget.NewFormItem("Encoding", encoding),
+		widget.NewFormItem("Dates", dateFormat),
+		widget.NewFormItem("", bom),
+		widget.NewFormItem("", quoteHeaders),
+	}
+	dlg := dialog.NewForm("Export CSV", "Export", "Cancel", items, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		chosen := app.CSVExportConfig{
+			Delimiter:    delimiter.Selected,
+			Encoding:     encoding.Selected,
+			BOM:          bom.Checked,
+			QuoteHeaders: quoteHeaders.Checked,
+			DateFormat:   dateFormat.Selected,
+		}
+		ui.presenter.HandleSaveCSVExport(chosen)
+		onExport(chosen)
+	}, ui.window)
+	dlg.Resize(fyne.NewSize(380, 0))
+	dlg.Show()
+}
+
+// showAccountFieldEditor edits one account field and stages the edit as a
+// pending change, calling onStaged once it is staged. A rejected edit
+// reopens the editor with the attempted value.
+func (ui *Gui) showAccountFieldEditor(accountID int, column, original, value string, onStaged func()) {
+	entry := widget.NewEntry()
+	entry.SetText(value)
+	label := labeledColumn(ui.app.AccountFieldLabels(), column)
+	title := fmt.Sprintf("Edit %s for account %d", label, accountID)
+	items := []*widget.FormItem{widget.NewFormItem(label, entry)}
+	dlgHeight := float32(0)
+	if ui.app.DB != nil && ui.app.DB.IsConnected() {
+		if dataSet, err := database.GetDataSetForAccountField(ui.app.DB, column); err == nil && dataSet != "" {
+			items = append(items, widget.NewFormItem("Values", ui.newDataSetValuePicker(dataSet, entry)))
+			dlgHeight = 420
+		}
+	}
+	dlg := dialog.NewForm(title, "Stage", "Cancel", items, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		if !ui.presenter.HandleStageFieldEdit(accountID, column, original, entry.Text) {
+			ui.showAccountFieldEditor(accountID, column, original, entry.Text, onStaged)
+			return
+		}
+		if onStaged != nil {
+			onStaged()
+		}
+	}, ui.window)
+	entry.OnSubmitted = func(string) { dlg.Submit() }
+	dlg.Resize(fyne.NewSize(420, dlgHeight))
+	dlg.Show()
+	ui.window.Canvas().Focus(entry)
+}
+
+// dataSetValuePageSize is how many picklist values the cell editor loads at
+// a time.
+const dataSetValuePageSize = 50
+
+// newDataSetValuePicker lists a data set's values for the cell editor. Values
+// are read a page at a time, filtered by the entry's text, and more are
+// loaded on request, so large picklists are never read up front. Picking a
+// value copies it into entry.
+func (ui *Gui) newDataSetValuePicker(dataSet string, entry *widget.Entry) fyne.CanvasObject {
+	var (
+		values    []database.DataSetValue
+		search    string
+		loadToken int
+	)
+	status := widget.NewLabel("Loading values...")
+	moreBtn := widget.NewButton("Load more", nil)
+	moreBtn.Hide()
+
+	list := widget.NewList(
+		func() int { return len(values) },
+		func() fyne.CanvasObject { return widget.NewLabel("template") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			label := values[i].Text
+			if values[i].Value != "" && values[i].Value != values[i].Text {
+				label = fmt.Sprintf("%s (%s)", values[i].Text, values[i].Value)
+			}
+			o.(*widget.Label).SetText(label)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if id < len(values) {
+			picked := values[id].Value
+			if picked == "" {
+				picked = values[id].Text
+			}
+			entry.SetText(picked)
+		}
+		list.UnselectAll()
+	}
+
+	load := func(reset bool) {
+		loadToken++
+		token, query, offset := loadToken, search, len(values)
+		if reset {
+			offset = 0
+		}
+		moreBtn.Disable()
+		go func() {
+			page, err := database.GetDataSetValuesPage(ui.app.DB, dataSet, query, dataSetValuePageSize, offset)
+			fyne.Do(func() {
+				if token != loadToken {
+					return
+				}
+				if err != nil {
+					status.SetText(fmt.Sprintf("Could not load values: %v", err))
+					return
+				}
+				if reset {
+					values = nil
+				}
+				values = append(values, page...)
+				list.Refresh()
+				moreBtn.Enable()
+				if len(page) == dataSetValuePageSize {
+					moreBtn.Show()
+				} else {
+					moreBtn.Hide()
+				}
+				status.SetText(fmt.Sprintf("%d values shown", len(values)))
+			})
+		}()
+	}
+	moreBtn.OnTapped = func() { load(false) }
+
+	filter := widget.NewEntry()
+	filter.SetPlaceHolder("Filter values")
+	filter.OnChanged = func(text string) {
+		search = text
+		load(true)
+	}
+	load(true)
+
+	return container.NewBorder(filter, container.NewHBox(status, layout.NewSpacer(), moreBtn), nil, nil, list)
+}
+
 // createExplorerTab creates the content for the "Explorer" tab with pagination support
 func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 	tableContainer := container.NewMax() // Use NewMax to fill available space
@@ -1845,6 +2311,19 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 		suppressPresetChange bool
 	)
 	var availableColumns []string
+	// columnLabels are the profile labels of the loaded table's columns.
+	// Column pickers show them next to the column names they stand for.
+	var columnLabels map[string]string
+	columnOptions := func() []string {
+		options := make([]string, len(availableColumns))
+		for i, column := range availableColumns {
+			options[i] = labeledColumn(columnLabels, column)
+		}
+		return options
+	}
+	optionColumn := func(option string) string {
+		return columnForLabeledOption(columnLabels, availableColumns, strings.TrimSpace(option))
+	}
 
 	queryOptions := ui.explorerCurrentQuery
 	pendingFilters := cloneExplorerFilters(queryOptions.Filters)
@@ -1866,6 +2345,11 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 		{"Not Equals", FilterModeNotEquals},
 		{"Starts With", FilterModeStartsWith},
 		{"Ends With", FilterModeEndsWith},
+		{"Greater Than", FilterModeGreaterThan},
+		{"Less Than", FilterModeLessThan},
+		{"Is Empty", FilterModeIsEmpty},
+		{"Is Not Empty", FilterModeIsNotEmpty},
+		{"Within km Of", FilterModeWithinKm},
 	}
 	modeLabels := make([]string, len(filterModeOptions))
 	modeLabelByMode := make(map[ExplorerFilterMode]string, len(filterModeOptions))
@@ -1890,12 +2374,12 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 
 		row := &explorerFilterRow{clause: clause}
 
-		columnSelect := widget.NewSelect(append([]string{}, availableColumns...), func(value string) {
-			clause.Column = strings.TrimSpace(value)
+		columnSelect := widget.NewSelect(columnOptions(), func(value string) {
+			clause.Column = optionColumn(value)
 		})
 		columnSelect.PlaceHolder = "Column"
 		if clause.Column != "" {
-			columnSelect.SetSelected(clause.Column)
+			columnSelect.SetSelected(labeledColumn(columnLabels, clause.Column))
 		}
 
 		modeSelect := widget.NewSelect(modeLabels, func(label string) {
@@ -1904,6 +2388,14 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 				mode = FilterModeContains
 			}
 			clause.Mode = mode
+			if row.value != nil {
+				row.value.SetPlaceHolder(filterValuePlaceholder(mode))
+				if filterModeTakesValue(mode) {
+					row.value.Enable()
+				} else {
+					row.value.Disable()
+				}
+			}
 		})
 		initialModeLabel := modeLabelByMode[clause.Mode]
 		if initialModeLabel == "" {
@@ -1913,11 +2405,14 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 		modeSelect.SetSelected(initialModeLabel)
 
 		valueEntry := widget.NewEntry()
-		valueEntry.SetPlaceHolder("Value")
+		valueEntry.SetPlaceHolder(filterValuePlaceholder(clause.Mode))
 		valueEntry.SetText(clause.Value)
 		valueEntry.OnChanged = func(val string) {
 			clause.Value = val
 		}
+		if !filterModeTakesValue(clause.Mode) {
+			valueEntry.Disable()
+		}
 
 		removeButton := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() {
 			for idx := range pendingFilters {
@@ -1965,25 +2460,25 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 	}
 
 	updateFilterRowOptions = func() {
-		options := append([]string{}, availableColumns...)
+		options := columnOptions()
 		for _, row := range filterRows {
 			row.column.Options = options
-			if row.clause.Column != "" && !containsString(options, row.clause.Column) {
+			if row.clause.Column != "" && !containsString(availableColumns, row.clause.Column) {
 				row.clause.Column = ""
 				row.column.ClearSelected()
 			} else if row.clause.Column != "" {
-				row.column.SetSelected(row.clause.Column)
+				row.column.SetSelected(labeledColumn(columnLabels, row.clause.Column))
 			}
 			row.column.Refresh()
 		}
 
 		if orderColumnSelect != nil {
-			orderOptions := append([]string{""}, availableColumns...)
+			orderOptions := append([]string{""}, options...)
 			orderColumnSelect.Options = orderOptions
 			if pendingOrderColumn == "" {
 				orderColumnSelect.ClearSelected()
 			} else if containsString(availableColumns, pendingOrderColumn) {
-				orderColumnSelect.SetSelected(pendingOrderColumn)
+				orderColumnSelect.SetSelected(labeledColumn(columnLabels, pendingOrderColumn))
 			} else {
 				pendingOrderColumn = ""
 				orderColumnSelect.ClearSelected()
@@ -2019,6 +2514,10 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 		currentPaginatedData = paginatedData
 		currentTableName = tableName
 
+		columnLabels = nil
+		if isAccountTable(tableName) {
+			columnLabels = ui.app.AccountFieldLabels()
+		}
 		availableColumns = paginatedData.Headers
 		if len(availableColumns) == 0 {
 			availableColumns = ui.getTableColumns(tableName)
@@ -2045,6 +2544,24 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 			Data:          paginatedData.Data,
 			HasCheckboxes: false, // Explorer doesn't need checkboxes
 			EmptyMessage:  fmt.Sprintf("No rows found in %s.", tableName),
+			HeaderLabels:  columnLabels,
+		}
+		if tableName == "Accounts" {
+			headers := paginatedData.Headers
+			config.OnCellEdit = func(_ int, col int, rowData []string) {
+				ui.showExplorerCellEditor(headers, col, rowData, rowData[col])
+			}
+			config.OnRowSelected = func(_ int, rowData []string) {
+				for i, header := range headers {
+					if header == "AccountId" && i < len(rowData) {
+						if accountID, err := strconv.Atoi(strings.TrimSpace(rowData[i])); err == nil {
+							ui.showAccountProvenance(accountID)
+							return
+						}
+					}
+				}
+				factory.showDefaultDetails(config.headerTexts(), rowData)
+			}
 		}
 
 		// Create auto-truncated table for better display
@@ -2153,6 +2670,7 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 			Data:          filteredData,
 			HasCheckboxes: false,
 			EmptyMessage:  fmt.Sprintf("No rows found in %s.", currentTableName),
+			HeaderLabels:  columnLabels,
 		}
 
 		table := factory.CreateAutoTruncatedTable(config)
@@ -2179,8 +2697,14 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 		"SyncHistory": {
 			{Label: "Pull Runs", Filters: []ExplorerFilterClause{{Column: "Direction", Mode: FilterModeEquals, Value: "pull"}}},
 			{Label: "Push Runs", Filters: []ExplorerFilterClause{{Column: "Direction", Mode: FilterModeEquals, Value: "push"}}},
+			{Label: "Sandbox Runs", Filters: []ExplorerFilterClause{{Column: "RunType", Mode: FilterModeEquals, Value: app.SandboxRunType}}},
 			{Label: "Failed Runs", Filters: []ExplorerFilterClause{{Column: "Status", Mode: FilterModeEquals, Value: "failed"}}},
 		},
+		"WebhookLog": {
+			{Label: "Failed Webhooks", Filters: []ExplorerFilterClause{{Column: "ParseStatus", Mode: FilterModeEquals, Value: app.WebhookParseInvalid}}},
+			{Label: "Valid Webhooks", Filters: []ExplorerFilterClause{{Column: "ParseStatus", Mode: FilterModeEquals, Value: app.WebhookParseValid}}},
+			{Label: "Unchecked Requests", Filters: []ExplorerFilterClause{{Column: "ParseStatus", Mode: FilterModeEquals, Value: app.WebhookParseUnchecked}}},
+		},
 	}
 
 	filtersMatchPreset := func(current []ExplorerFilterClause, preset []ExplorerFilterClause) bool {
@@ -2275,7 +2799,7 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 	quickPresetSelect.Hide()
 
 	orderColumnSelect = widget.NewSelect([]string{}, func(value string) {
-		pendingOrderColumn = strings.TrimSpace(value)
+		pendingOrderColumn = optionColumn(value)
 	})
 	orderColumnSelect.PlaceHolder = "Order column"
 
@@ -2418,47 +2942,28 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 			return
 		}
 
-		// Create CSV content
-		var csvContent strings.Builder
-
-		// Add headers
-		csvContent.WriteString(strings.Join(currentPaginatedData.Headers, ","))
-		csvContent.WriteString("\n")
-
-		// Add data rows
-		for _, row := range currentPaginatedData.Data {
-			// Escape fields that contain commas or quotes
-			var escapedRow []string
-			for _, field := range row {
-				if strings.Contains(field, ",") || strings.Contains(field, "\"") || strings.Contains(field, "\n") {
-					field = "\"" + strings.ReplaceAll(field, "\"", "\"\"") + "\""
+		data := currentPaginatedData
+		tableName := currentTableName
+		ui.showCSVExportOptions(func(options app.CSVExportConfig) {
+			dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+				if err != nil {
+					ui.app.Events.Dispatch(events.Errorf("gui", "Error opening file for export: %v", err))
+					return
 				}
-				escapedRow = append(escapedRow, field)
-			}
-			csvContent.WriteString(strings.Join(escapedRow, ","))
-			csvContent.WriteString("\n")
-		}
-
-		// Save dialog
-		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
-			if err != nil {
-				ui.app.Events.Dispatch(events.Errorf("gui", "Error opening file for export: %v", err))
-				return
-			}
-			if writer == nil {
-				return // User cancelled
-			}
-			defer writer.Close()
+				if writer == nil {
+					return // User cancelled
+				}
+				defer writer.Close()
 
-			_, err = writer.Write([]byte(csvContent.String()))
-			if err != nil {
-				ui.app.Events.Dispatch(events.Errorf("gui", "Error writing export file: %v", err))
-				return
-			}
+				if err := app.WriteCSV(writer, data.Headers, data.Data, options); err != nil {
+					ui.app.Events.Dispatch(events.Errorf("gui", "Error writing export file: %v", err))
+					return
+				}
 
-			ui.app.Events.Dispatch(events.Infof("gui", "Exported %d rows from %s (page %d) to %s",
-				len(currentPaginatedData.Data), currentTableName, currentPaginatedData.CurrentPage+1, writer.URI().Path()))
-		}, ui.window)
+				ui.app.Events.Dispatch(events.Infof("gui", "Exported %d rows from %s (page %d) to %s",
+					len(data.Data), tableName, data.CurrentPage+1, writer.URI().Path()))
+			}, ui.window)
+		})
 	})
 
 	refreshButton := widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), func() {
@@ -2493,6 +2998,9 @@ func (ui *Gui) createExplorerTab() fyne.CanvasObject {
 		fyne.Do(func() {
 			tableSelect.Options = tables
 			tableSelect.Refresh()
+			if name := ui.takeRestoredExplorerTable(tables); name != "" && tableSelect.Selected == "" {
+				tableSelect.SetSelected(name)
+			}
 		})
 	}()
 
@@ -2627,6 +3135,19 @@ func (ui *Gui) OpenExplorerPendingChanges() bool {
 
 // OpenExplorerTable activates the explorer tab and loads the specified table.
 func (ui *Gui) OpenExplorerTable(tableName string) bool {
+	if defaults, ok := defaultExplorerQuery(tableName); ok {
+		return ui.openExplorerTable(tableName, &defaults)
+	}
+	return ui.openExplorerTable(tableName, nil)
+}
+
+// OpenExplorerTableWithQuery activates the explorer tab and loads the
+// specified table with the given filters and ordering applied.
+func (ui *Gui) OpenExplorerTableWithQuery(tableName string, opts ExplorerQueryOptions) bool {
+	return ui.openExplorerTable(tableName, &opts)
+}
+
+func (ui *Gui) openExplorerTable(tableName string, query *ExplorerQueryOptions) bool {
 	if ui.app == nil || ui.app.DB == nil || !ui.app.DB.IsConnected() {
 		ui.ShowToast("Connect to the database to browse tables in Explorer.")
 		return false
@@ -2659,10 +3180,10 @@ func (ui *Gui) OpenExplorerTable(tableName string) bool {
 		return false
 	}
 
-	if defaults, ok := defaultExplorerQuery(tableName); ok {
-		ui.explorerCurrentQuery = defaults
+	if query != nil {
+		ui.explorerCurrentQuery = *query
 		if ui.explorerApplyQuery != nil {
-			ui.explorerApplyQuery(defaults, false)
+			ui.explorerApplyQuery(*query, false)
 		}
 	}
 
@@ -2842,6 +3363,9 @@ func (ui *Gui) createServerTab() fyne.CanvasObject {
 	statusValue.TextSize = theme.TextSize()
 	toggleServerButton := widget.NewButtonWithIcon("", nil, nil)
 	toggleServerButton.Importance = widget.HighImportance
+	reloadServerButton := NewSecondaryButton("Reload Config", theme.ViewRefreshIcon(), func() {
+		ui.presenter.HandleReloadServerConfig()
+	})
 
 	webhooks := ui.app.Config.Server.Webhooks
 	if webhooks == nil {
@@ -2879,6 +3403,7 @@ func (ui *Gui) createServerTab() fyne.CanvasObject {
 	var refreshServerStatus func()
 	setToggleButton := func(running bool) {
 		if running {
+			reloadServerButton.Enable()
 			toggleServerButton.SetText("Stop Server")
 			toggleServerButton.SetIcon(theme.MediaStopIcon())
 			toggleServerButton.OnTapped = func() {
@@ -2886,6 +3411,7 @@ func (ui *Gui) createServerTab() fyne.CanvasObject {
 				refreshServerStatus()
 			}
 		} else {
+			reloadServerButton.Disable()
 			toggleServerButton.SetText("Start Server")
 			toggleServerButton.SetIcon(theme.MediaPlayIcon())
 			toggleServerButton.OnTapped = func() {
@@ -2978,6 +3504,56 @@ func (ui *Gui) createServerTab() fyne.CanvasObject {
 		logRequestsCheck,
 	)
 
+	tunnelEnabledCheck := widget.NewCheck("Receive webhooks through a relay", nil)
+	tunnelEnabledCheck.SetChecked(ui.app.Config.Server.Tunnel.Enabled)
+	tunnelURLEntry := widget.NewEntry()
+	tunnelURLEntry.SetPlaceHolder("wss://relay.example.com/tunnel")
+	tunnelURLEntry.SetText(ui.app.Config.Server.Tunnel.URL)
+	tunnelTokenEntry := widget.NewPasswordEntry()
+	tunnelTokenEntry.SetText(ui.app.Config.Server.Tunnel.Token)
+	saveTunnelButton := NewSecondaryButton("Save Tunnel Settings", theme.DocumentSaveIcon(), func() {
+		ui.presenter.HandleSaveTunnelConfig(tunnelEnabledCheck.Checked, tunnelURLEntry.Text, tunnelTokenEntry.Text)
+	})
+	tunnelCard := ui.newSectionCard(
+		"Webhook Tunnel",
+		"Without a public address, BadgerMaps cannot reach this server. The tunnel connects out to a relay, which forwards webhooks back here.",
+		tunnelEnabledCheck,
+		widget.NewForm(
+			widget.NewFormItem("Relay URL", tunnelURLEntry),
+			widget.NewFormItem("Relay Token", tunnelTokenEntry),
+		),
+		container.NewCenter(saveTunnelButton),
+	)
+
+	acmeConfig := ui.app.Config.Server.ACME
+	acmeEnabledCheck := widget.NewCheck("Obtain the certificate from Let's Encrypt", nil)
+	acmeEnabledCheck.SetChecked(acmeConfig.Enabled)
+	acmeDomainsEntry := widget.NewEntry()
+	acmeDomainsEntry.SetPlaceHolder("hooks.example.com")
+	acmeDomainsEntry.SetText(strings.Join(acmeConfig.Domains, ", "))
+	acmeEmailEntry := widget.NewEntry()
+	acmeEmailEntry.SetPlaceHolder("admin@example.com")
+	acmeEmailEntry.SetText(acmeConfig.Email)
+	acmePortEntry := widget.NewEntry()
+	acmePortEntry.SetPlaceHolder("80 (optional)")
+	if acmeConfig.HTTPPort > 0 {
+		acmePortEntry.SetText(strconv.Itoa(acmeConfig.HTTPPort))
+	}
+	saveACMEButton := NewSecondaryButton("Save Let's Encrypt Settings", theme.DocumentSaveIcon(), func() {
+		ui.presenter.HandleSaveACMEConfig(acmeEnabledCheck.Checked, acmeDomainsEntry.Text, acmeEmailEntry.Text, acmePortEntry.Text)
+	})
+	acmeCard := ui.newSectionCard(
+		"Let's Encrypt",
+		"For a server BadgerMaps reaches directly. Certificates are obtained and renewed automatically and replace the TLS certificate and key above. The domains must resolve to this machine, and port 443 or the challenge port must be reachable from the internet.",
+		acmeEnabledCheck,
+		widget.NewForm(
+			widget.NewFormItem("Domains", acmeDomainsEntry),
+			widget.NewFormItem("Contact Email", acmeEmailEntry),
+			widget.NewFormItem("Challenge Port", acmePortEntry),
+		),
+		container.NewCenter(saveACMEButton),
+	)
+
 	autoSyncCard := ui.buildSyncAutomationCard()
 
 	scrollContent := container.NewVScroll(container.NewVBox(
@@ -2985,9 +3561,11 @@ func (ui *Gui) createServerTab() fyne.CanvasObject {
 		webhookCard,
 		autoSyncCard,
 		serverSettingsCard,
+		acmeCard,
+		tunnelCard,
 	))
 
-	buttonGrid := container.NewGridWithColumns(2, saveServerButton, toggleServerButton)
+	buttonGrid := container.NewGridWithColumns(3, saveServerButton, reloadServerButton, toggleServerButton)
 	footer := container.NewVBox(widget.NewSeparator(), buttonGrid)
 
 	return container.NewBorder(nil, footer, nil, nil, scrollContent)
@@ -3000,6 +3578,7 @@ func (ui *Gui) createDebugTab() fyne.CanvasObject {
 		widget.NewLabel(fmt.Sprintf("Debug Mode: %v", ui.app.State.Debug)),
 		widget.NewLabel(fmt.Sprintf("Verbose Mode: %v", ui.app.State.Verbose)),
 		widget.NewLabel(fmt.Sprintf("Config File: %s", ui.app.ConfigFile)),
+		ui.createRuntimeMetricsCard(),
 	))
 }
 
@@ -3036,6 +3615,29 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 		container.NewCenter(testApiButton),
 	)
 
+	// Sandbox Settings
+	sandboxURLEntry := widget.NewEntry()
+	sandboxURLEntry.SetPlaceHolder("https://sandbox.example.com/api/2")
+	sandboxURLEntry.SetText(ui.app.Config.API.SandboxURL)
+	sandboxKeyEntry := widget.NewPasswordEntry()
+	sandboxKeyEntry.SetPlaceHolder("Same as API key")
+	sandboxKeyEntry.SetText(ui.app.Config.API.SandboxAPIKey)
+	pushToSandboxCheck := widget.NewCheck("Send pushes to the sandbox", nil)
+	pushToSandboxCheck.SetChecked(ui.app.Config.PushToSandbox)
+	saveSandboxButton := NewSecondaryButton("Save Sandbox Settings", theme.DocumentSaveIcon(), func() {
+		ui.presenter.HandleSaveSandboxConfig(sandboxURLEntry.Text, sandboxKeyEntry.Text, pushToSandboxCheck.Checked)
+	})
+	sandboxCard := ui.newSectionCard(
+		"Push Sandbox",
+		"Rehearse pushes against a sandbox API. Pulls still use the Base URL, and pushed changes stay queued for production.",
+		widget.NewForm(
+			widget.NewFormItem("Sandbox URL", sandboxURLEntry),
+			widget.NewFormItem("Sandbox API Key", sandboxKeyEntry),
+			widget.NewFormItem("", pushToSandboxCheck),
+		),
+		container.NewCenter(saveSandboxButton),
+	)
+
 	// Database Settings
 	dbPathEntry := widget.NewEntry()
 	dbHostEntry := widget.NewEntry()
@@ -3050,21 +3652,55 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 	dbUserFormItem := widget.NewFormItem("User", dbUserEntry)
 	dbPassFormItem := widget.NewFormItem("Password", dbPassEntry)
 	dbNameFormItem := widget.NewFormItem("Database Name", dbNameEntry)
+	dbAuthSelect := widget.NewSelect(database.MSSQLAuthMethods, nil)
+	dbAuthSelect.SetSelected(database.MSSQLAuthSQL)
+	dbAuthFormItem := widget.NewFormItem("Authentication", dbAuthSelect)
+	dbAuthFormItem.HintText = "Windows takes DOMAIN\\user, or no user for the signed-in one; Azure AD gets a token from db.access_token_command."
 
 	dbForm := widget.NewForm()
-	dbTypeSelect := widget.NewSelect([]string{"sqlite3", "postgres", "mssql"}, func(selected string) {
+	dbTypeSelect := widget.NewSelect([]string{"sqlite3", "postgres", "mssql"}, nil)
+	layoutDBForm := func() {
 		dbForm.Items = []*widget.FormItem{}
-		if selected == "sqlite3" {
+		switch {
+		case dbTypeSelect.Selected == "sqlite3":
 			dbForm.AppendItem(dbPathFormItem)
-		} else {
+		case dbTypeSelect.Selected == "mssql" && dbAuthSelect.Selected == database.MSSQLAuthAzureAD:
+			dbForm.AppendItem(dbHostFormItem)
+			dbForm.AppendItem(dbPortFormItem)
+			dbForm.AppendItem(dbAuthFormItem)
+			dbForm.AppendItem(dbNameFormItem)
+		default:
 			dbForm.AppendItem(dbHostFormItem)
 			dbForm.AppendItem(dbPortFormItem)
+			if dbTypeSelect.Selected == "mssql" {
+				dbForm.AppendItem(dbAuthFormItem)
+			}
 			dbForm.AppendItem(dbUserFormItem)
 			dbForm.AppendItem(dbPassFormItem)
 			dbForm.AppendItem(dbNameFormItem)
 		}
 		dbForm.Refresh()
-	})
+	}
+	dbPathFormItem.HintText = "A new database file is created if there is none yet."
+	dbPathEntry.Validator = func(text string) error {
+		_, err := database.CheckSQLitePath(text)
+		return err
+	}
+	dbHostEntry.Validator = func(text string) error {
+		return database.ValidateDBHost(dbTypeSelect.Selected, text)
+	}
+	dbPortEntry.Validator = func(text string) error {
+		_, err := database.ParseDBPort(dbTypeSelect.Selected, text)
+		return err
+	}
+	dbTypeSelect.OnChanged = func(dbType string) {
+		if port := database.DefaultPort(dbType); port != 0 {
+			dbPortEntry.SetPlaceHolder(fmt.Sprintf("%d", port))
+			dbPortFormItem.HintText = fmt.Sprintf("Leave empty for the default, %d.", port)
+		}
+		layoutDBForm()
+	}
+	dbAuthSelect.OnChanged = func(string) { layoutDBForm() }
 
 	// Populate form with current config
 	switch config := ui.app.DB.(type) {
@@ -3082,6 +3718,9 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 		dbUserEntry.SetText(config.Username)
 		dbPassEntry.SetText(config.Password)
 		dbNameEntry.SetText(config.Database)
+		if config.Auth != "" {
+			dbAuthSelect.SetSelected(strings.ToLower(config.Auth))
+		}
 	}
 	dbTypeSelect.SetSelected(ui.app.DB.GetType())
 
@@ -3096,22 +3735,20 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 	testDbButton.OnTapped = func() {
 		ui.presenter.HandleTestDBConnection(
 			dbTypeSelect.Selected, dbPathEntry.Text, dbHostEntry.Text,
-			dbPortEntry.Text, dbUserEntry.Text, dbPassEntry.Text, dbNameEntry.Text,
+			dbPortEntry.Text, dbUserEntry.Text, dbPassEntry.Text, dbNameEntry.Text, dbAuthSelect.Selected,
 		)
 	}
 
 	// Schema Management
 	schemaLabel := "Initialize Schema"
 	if ui.app.DB != nil && ui.app.DB.IsConnected() {
-		if err := ui.app.DB.ValidateSchema(ui.app.State); err == nil {
+		if status, _ := ui.app.Connections().Schema(); status == app.SchemaValid {
 			schemaLabel = "Re-initialize Schema"
 		}
 	}
 	schemaButton := widget.NewButtonWithIcon(schemaLabel, theme.StorageIcon(), ui.presenter.HandleSchemaEnforcement)
 
-	dbCard := ui.newSectionCard(
-		"Database Configuration",
-		"Select your database type and connection information.",
+	dbCardContent := []fyne.CanvasObject{
 		container.NewGridWithColumns(2, widget.NewLabel("Database Type"), dbTypeSelect),
 		dbForm,
 		container.NewCenter(testDbButton),
@@ -3119,6 +3756,67 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 		widget.NewLabelWithStyle("Schema Management", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		widget.NewLabel("Initialize or rebuild the target database schema."),
 		container.NewCenter(schemaButton),
+	}
+	if envSelect := ui.createEnvironmentSelect(); envSelect != nil {
+		dbCardContent = append([]fyne.CanvasObject{envSelect}, dbCardContent...)
+	}
+	dbCard := ui.newSectionCard(
+		"Database Configuration",
+		"Select your database type and connection information.",
+		dbCardContent...,
+	)
+
+	// Maintenance
+	backupButton := widget.NewButtonWithIcon("Back Up Database", theme.DownloadIcon(), func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				ui.app.Events.Dispatch(events.Errorf("gui", "Error choosing backup file: %v", err))
+				return
+			}
+			if writer == nil {
+				return // User cancelled
+			}
+			path := writer.URI().Path()
+			writer.Close()
+			ui.presenter.HandleBackupDatabase(path)
+		}, ui.window)
+		extension := ".json"
+		if ui.app.DB != nil && database.DefaultBackupFormat(ui.app.DB) == database.BackupFormatSQLite {
+			extension = ".db"
+		}
+		saveDialog.SetFileName("badgermaps-backup-" + time.Now().Format("20060102-150405") + extension)
+		saveDialog.Show()
+	})
+	restoreButton := widget.NewButtonWithIcon("Restore from Backup", theme.UploadIcon(), func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				ui.app.Events.Dispatch(events.Errorf("gui", "Error choosing backup file: %v", err))
+				return
+			}
+			if reader == nil {
+				return // User cancelled
+			}
+			path := reader.URI().Path()
+			reader.Close()
+			ui.presenter.HandleRestoreDatabase(path)
+		}, ui.window)
+	})
+	checkTimesButton := widget.NewButtonWithIcon("Check Dates & Times", theme.SearchIcon(), ui.presenter.HandleCheckDateTimes)
+	storageButton := widget.NewButtonWithIcon("Storage Report", theme.StorageIcon(), ui.presenter.HandleShowStorageReport)
+	historyButton := widget.NewButtonWithIcon("Command History", theme.HistoryIcon(), ui.presenter.HandleShowCommandHistory)
+	if ui.app.DB == nil || !ui.app.DB.IsConnected() {
+		backupButton.Disable()
+		checkTimesButton.Disable()
+		storageButton.Disable()
+		historyButton.Disable()
+	}
+
+	maintenanceCard := ui.newSectionCard(
+		"Maintenance",
+		"Back up the local database, restore it from an earlier backup, look for follow-up dates and appointment times with timezone problems, see how much space each table takes, or review and re-run earlier commands.",
+		container.NewGridWithColumns(2, backupButton, restoreButton),
+		container.NewGridWithColumns(2, checkTimesButton, storageButton),
+		historyButton,
 	)
 
 	// Sync Preferences
@@ -3128,7 +3826,8 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 		"Use most recent",
 		"Ask every time",
 	}, nil)
-	conflictStrategyRadio.SetSelected("Ask every time")
+	conflictStrategyRadio.Required = true
+	conflictStrategyRadio.SetSelected(conflictResolutionLabel(ui.app.ConflictResolution()))
 
 	maxConcurrent := ui.app.Config.MaxConcurrentRequests
 	if maxConcurrent < 1 {
@@ -3178,7 +3877,11 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 	}
 
 	batchSizeEntry := widget.NewEntry()
-	batchSizeEntry.SetText("100")
+	batchSizeEntry.SetText(strconv.Itoa(ui.app.BatchSize()))
+
+	lowBandwidthCheck := widget.NewCheck("Pull the account list only and fetch details when opened, with fewer, compressed requests", nil)
+	lowBandwidthCheck.SetChecked(ui.app.Config.LowBandwidth)
+	lowBandwidthCheck.OnChanged = ui.presenter.HandleSaveLowBandwidth
 
 	verboseLoggingCheck := widget.NewCheck("Verbose logging", nil)
 	logRetentionSelect := widget.NewSelect([]string{
@@ -3190,7 +3893,24 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 	logRetentionSelect.SetSelected("30 days")
 
 	saveSyncPrefsBtn := widget.NewButtonWithIcon("Save Sync Preferences", theme.DocumentSaveIcon(), func() {
-		ui.ShowToast("Sync preferences saved (coming soon)")
+		ui.presenter.HandleSaveBatchSize(batchSizeEntry.Text)
+	})
+
+	throughputEntries := func(entity string) (*widget.Entry, *widget.Entry) {
+		throughput := ui.app.PushThroughput(entity)
+		workers := widget.NewEntry()
+		workers.SetText(strconv.Itoa(throughput.Workers))
+		rate := widget.NewEntry()
+		rate.SetPlaceHolder("No limit")
+		if throughput.RequestsPerSecond > 0 {
+			rate.SetText(strconv.FormatFloat(throughput.RequestsPerSecond, 'f', -1, 64))
+		}
+		return workers, rate
+	}
+	accountWorkersEntry, accountRateEntry := throughputEntries("accounts")
+	checkinWorkersEntry, checkinRateEntry := throughputEntries("checkins")
+	savePushThroughputBtn := widget.NewButtonWithIcon("Save Push Throughput", theme.DocumentSaveIcon(), func() {
+		ui.presenter.HandleSavePushThroughput(accountWorkersEntry.Text, accountRateEntry.Text, checkinWorkersEntry.Text, checkinRateEntry.Text)
 	})
 
 	syncPreferencesCard := ui.newSectionCard(
@@ -3199,12 +3919,22 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 		widget.NewForm(
 			widget.NewFormItem("Conflict Resolution", conflictStrategyRadio),
 			widget.NewFormItem("Batch Size", batchSizeEntry),
+			widget.NewFormItem("Low Bandwidth", lowBandwidthCheck),
 			widget.NewFormItem("Verbose Logging", verboseLoggingCheck),
 			widget.NewFormItem("Log Retention", logRetentionSelect),
 			widget.NewFormItem("Parallel Processing", parallelProcessingCheck),
 			widget.NewFormItem("Max Concurrent", maxConcurrentEntry),
 		),
 		container.NewCenter(saveSyncPrefsBtn),
+		widget.NewSeparator(),
+		NewWrappingLabel("Push throughput: changes of one account are always pushed one at a time and in order; check-ins can run fully in parallel. Leave a rate empty for no limit."),
+		widget.NewForm(
+			widget.NewFormItem("Account Push Workers", accountWorkersEntry),
+			widget.NewFormItem("Account Requests/sec", accountRateEntry),
+			widget.NewFormItem("Check-in Push Workers", checkinWorkersEntry),
+			widget.NewFormItem("Check-in Requests/sec", checkinRateEntry),
+		),
+		container.NewCenter(savePushThroughputBtn),
 	)
 
 	themeLabels := []string{"Auto (Follow System)", "Light", "Dark"}
@@ -3226,12 +3956,31 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 	}
 	themeRadio.SetSelected(currentThemeLabel)
 
+	timezoneEntry := widget.NewSelectEntry([]string{
+		"America/New_York", "America/Chicago", "America/Denver", "America/Phoenix",
+		"America/Los_Angeles", "America/Anchorage", "Pacific/Honolulu", "UTC",
+	})
+	timezoneEntry.SetPlaceHolder("Local time")
+	timezoneEntry.SetText(ui.app.Config.DisplayTimezone)
+	saveTimezoneButton := NewSecondaryButton("Save Timezone", theme.DocumentSaveIcon(), func() {
+		ui.presenter.HandleSaveDisplayTimezone(timezoneEntry.Text)
+	})
+	displayNameEntry := widget.NewSelectEntry(app.AccountDisplayNameExamples)
+	displayNameEntry.SetPlaceHolder(app.DefaultAccountDisplayName)
+	displayNameEntry.SetText(ui.app.Config.AccountDisplayName)
+	saveDisplayNameButton := NewSecondaryButton("Save Account Names", theme.DocumentSaveIcon(), func() {
+		ui.presenter.HandleSaveAccountDisplayName(displayNameEntry.Text)
+	})
+
 	appearanceCard := ui.newSectionCard(
 		"Appearance",
-		"Choose how BadgerMaps Sync looks.",
+		"Choose how BadgerMaps Sync looks, which timezone follow-up dates and appointment times are shown in, and how accounts are named. Account names are built from fields in {{...}}, such as {{last_name}}, {{first_name}}.",
 		widget.NewForm(
 			widget.NewFormItem("Theme", themeRadio),
+			widget.NewFormItem("Display Timezone", timezoneEntry),
+			widget.NewFormItem("Account Names", displayNameEntry),
 		),
+		container.NewCenter(container.NewHBox(saveTimezoneButton, saveDisplayNameButton)),
 	)
 
 	// Other Settings
@@ -3244,6 +3993,20 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 		testCustomCheckinsCheck,
 	))
 
+	// Updates
+	updateCheck := widget.NewCheck("Check for updates at startup", nil)
+	updateCheck.SetChecked(ui.app.UpdateCheckEnabled())
+	updateCheck.OnChanged = ui.presenter.HandleSetUpdateCheck
+	checkNowButton := NewSecondaryButton("Check Now", theme.DownloadIcon(), func() {
+		ui.presenter.HandleCheckForUpdates(true)
+	})
+	updatesCard := ui.newSectionCard(
+		"Updates",
+		fmt.Sprintf("You are running BadgerMaps %s. New releases are downloaded from GitHub.", app.Version),
+		updateCheck,
+		container.NewCenter(checkNowButton),
+	)
+
 	// Buttons
 	saveButton := NewSecondaryButton("Save Configuration", theme.ConfirmIcon(), func() {
 		selectedThemePreference := app.ThemePreferenceAuto
@@ -3252,9 +4015,11 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 				selectedThemePreference = value
 			}
 		}
+		ui.app.Config.ConflictResolution = conflictResolutionLabels[conflictStrategyRadio.Selected]
 		ui.presenter.HandleSaveConfig(
 			apiKeyEntry.Text, baseURLEntry.Text, dbTypeSelect.Selected, dbPathEntry.Text,
 			dbHostEntry.Text, dbPortEntry.Text, dbUserEntry.Text, dbPassEntry.Text, dbNameEntry.Text,
+			dbAuthSelect.Selected,
 			selectedThemePreference,
 			false, // verbose is deprecated in gui
 			verboseCheck.Checked,
@@ -3275,10 +4040,14 @@ func (ui *Gui) buildConfigTab() fyne.CanvasObject {
 	scrollContent := container.NewVScroll(container.NewVBox(
 		NewSpacer(fyne.NewSize(0, 10)),
 		apiCard,
+		sandboxCard,
 		dbCard,
+		maintenanceCard,
 		syncPreferencesCard,
+		ui.buildFieldMappingCard(),
 		appearanceCard,
 		otherCard,
+		updatesCard,
 		actionsCard,
 	))
 
@@ -3339,7 +4108,11 @@ func (ui *Gui) SetProgress(value float64) {
 	})
 }
 
+// ShowErrorDialog shows err with what the user can do about it, if known.
 func (ui *Gui) ShowErrorDialog(err error) {
+	if hint := errs.Hint(err); hint != "" {
+		err = fmt.Errorf("%w\n\n%s", err, hint)
+	}
 	fyne.Do(func() {
 		dialog.ShowError(err, ui.window)
 	})
@@ -3409,14 +4182,34 @@ type PaginatedTableData struct {
 type ExplorerFilterMode string
 
 const (
-	FilterModeNone       ExplorerFilterMode = ""
-	FilterModeContains   ExplorerFilterMode = "contains"
-	FilterModeEquals     ExplorerFilterMode = "equals"
-	FilterModeNotEquals  ExplorerFilterMode = "not_equals"
-	FilterModeStartsWith ExplorerFilterMode = "starts_with"
-	FilterModeEndsWith   ExplorerFilterMode = "ends_with"
+	FilterModeNone        ExplorerFilterMode = ""
+	FilterModeContains    ExplorerFilterMode = "contains"
+	FilterModeEquals      ExplorerFilterMode = "equals"
+	FilterModeNotEquals   ExplorerFilterMode = "not_equals"
+	FilterModeStartsWith  ExplorerFilterMode = "starts_with"
+	FilterModeEndsWith    ExplorerFilterMode = "ends_with"
+	FilterModeGreaterThan ExplorerFilterMode = "greater_than"
+	FilterModeLessThan    ExplorerFilterMode = "less_than"
+	FilterModeIsEmpty     ExplorerFilterMode = "is_empty"
+	FilterModeIsNotEmpty  ExplorerFilterMode = "is_not_empty"
+	// FilterModeWithinKm keeps rows whose account ID column names an account
+	// with a stored location inside a radius such as "25 km of 40.7,-74.0".
+	FilterModeWithinKm ExplorerFilterMode = "within_km"
 )
 
+// filterModeTakesValue reports whether mode compares against a value.
+// Emptiness checks ignore the value entirely.
+func filterModeTakesValue(mode ExplorerFilterMode) bool {
+	return mode != FilterModeIsEmpty && mode != FilterModeIsNotEmpty
+}
+
+func filterValuePlaceholder(mode ExplorerFilterMode) string {
+	if mode == FilterModeWithinKm {
+		return "25 km of 40.7128,-74.0060 or an address"
+	}
+	return "Value"
+}
+
 type ExplorerQueryOptions struct {
 	Filters         []ExplorerFilterClause
 	OrderColumn     string
@@ -3427,6 +4220,10 @@ type ExplorerFilterClause struct {
 	Column string
 	Mode   ExplorerFilterMode
 	Value  string
+
+	// radiusSQL is the resolved account subquery of a FilterModeWithinKm
+	// clause; see resolveRadiusFilters.
+	radiusSQL string
 }
 
 type explorerFilterRow struct {
@@ -3469,12 +4266,22 @@ func (ui *Gui) loadPaginatedTableData(tableName string, page, pageSize int, opts
 
 	resolvedFilters := resolveExplorerFilters(normalized.Filters, columns)
 	orderColumn := matchColumn(columns, normalized.OrderColumn)
+	if err := ui.resolveRadiusFilters(resolvedFilters); err != nil {
+		ui.app.Events.Dispatch(events.Errorf("gui", "Radius filter for %s: %v", tableName, err))
+		return &PaginatedTableData{
+			TableData:   TableData{Headers: []string{}, Data: [][]string{}},
+			TotalRows:   0,
+			CurrentPage: 0,
+			PageSize:    pageSize,
+			TotalPages:  0,
+		}
+	}
 
 	dbType := ui.app.DB.GetType()
 	whereClause := buildExplorerWhereClause(resolvedFilters, dbType)
 	orderClause := buildExplorerOrderClause(columns, orderColumn, normalized.OrderDescending, dbType)
 
-	countQuery := buildExplorerCountQuery(tableName, whereClause)
+	countQuery := buildExplorerCountQuery(database.QualifiedName(ui.app.DB, tableName), whereClause)
 
 	countRows, err := ui.app.DB.ExecuteQuery(countQuery)
 	if err != nil {
@@ -3507,7 +4314,7 @@ func (ui *Gui) loadPaginatedTableData(tableName string, page, pageSize int, opts
 	if page < 0 {
 		page = 0
 	}
-	selectQuery := buildExplorerSelectQuery(tableName, whereClause, orderClause, page, pageSize, dbType)
+	selectQuery := buildExplorerSelectQuery(database.QualifiedName(ui.app.DB, tableName), whereClause, orderClause, page, pageSize, dbType)
 
 	rows, err := ui.app.DB.ExecuteQuery(selectQuery)
 	if err != nil {
@@ -3564,6 +4371,11 @@ func (ui *Gui) loadPaginatedTableData(tableName string, page, pageSize int, opts
 		data = append(data, rowData)
 	}
 
+	if isAccountTable(tableName) {
+		resultColumns, data = withoutColumns(resultColumns, data, ui.app.HiddenAccountColumns())
+		ui.app.DecryptTableRows(resultColumns, data)
+	}
+
 	ui.app.Events.Dispatch(events.Infof("gui", "Explorer: Loaded page %d of %d (%d rows) from '%s'", page+1, totalPages, len(data), tableName))
 
 	return &PaginatedTableData{
@@ -3575,6 +4387,75 @@ func (ui *Gui) loadPaginatedTableData(tableName string, page, pageSize int, opts
 	}
 }
 
+// detectFieldSchema labels the custom Accounts columns from the profile and
+// reloads the Explorer when the labels changed.
+func (ui *Gui) detectFieldSchema() {
+	if !ui.presenter.HandleDetectFieldSchema() {
+		return
+	}
+	fyne.Do(func() {
+		if ui.explorerApplyQuery != nil && ui.explorerTableSelect != nil && isAccountTable(ui.explorerTableSelect.Selected) {
+			ui.explorerApplyQuery(ui.explorerCurrentQuery, true)
+		}
+	})
+}
+
+// isAccountTable reports whether the Explorer table holds account columns.
+func isAccountTable(tableName string) bool {
+	return strings.EqualFold(tableName, "Accounts") || strings.EqualFold(tableName, "AccountsWithLabels")
+}
+
+// withoutColumns drops the hidden columns, such as custom fields that are
+// not pulled, from a page of Explorer data.
+func withoutColumns(headers []string, data [][]string, hidden map[string]bool) ([]string, [][]string) {
+	if len(hidden) == 0 {
+		return headers, data
+	}
+	var keep []int
+	for i, header := range headers {
+		if !hidden[header] {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(headers) {
+		return headers, data
+	}
+	pick := func(row []string) []string {
+		kept := make([]string, 0, len(keep))
+		for _, i := range keep {
+			if i < len(row) {
+				kept = append(kept, row[i])
+			}
+		}
+		return kept
+	}
+	rows := make([][]string, len(data))
+	for i, row := range data {
+		rows[i] = pick(row)
+	}
+	return pick(headers), rows
+}
+
+// labeledColumn is how a column picker shows a column: its profile label
+// followed by the column name, or the bare name when it has no label.
+func labeledColumn(labels map[string]string, column string) string {
+	if label := labels[column]; label != "" && label != column {
+		return fmt.Sprintf("%s (%s)", label, column)
+	}
+	return column
+}
+
+// columnForLabeledOption returns the column a picker option made by
+// labeledColumn stands for.
+func columnForLabeledOption(labels map[string]string, columns []string, option string) string {
+	for _, column := range columns {
+		if labeledColumn(labels, column) == option {
+			return column
+		}
+	}
+	return option
+}
+
 func normalizeExplorerOptions(opts ExplorerQueryOptions) ExplorerQueryOptions {
 	cleaned := make([]ExplorerFilterClause, 0, len(opts.Filters))
 	for _, clause := range opts.Filters {
@@ -3589,7 +4470,7 @@ func normalizeExplorerOptions(opts ExplorerQueryOptions) ExplorerQueryOptions {
 			clause.Mode = FilterModeContains
 		}
 
-		if clause.Value == "" {
+		if clause.Value == "" && filterModeTakesValue(clause.Mode) {
 			// NotEquals with empty value is not meaningful
 			continue
 		}
@@ -3648,7 +4529,23 @@ func likeOperator(dbType string) string {
 	return "LIKE"
 }
 
+// textExpression renders column as trimmed text so emptiness checks work on
+// numeric columns too.
+func textExpression(column string, dbType string) string {
+	if strings.EqualFold(dbType, "mssql") {
+		return fmt.Sprintf("LTRIM(RTRIM(CAST(%s AS NVARCHAR(MAX))))", column)
+	}
+	return fmt.Sprintf("TRIM(CAST(%s AS TEXT))", column)
+}
+
 func buildFilterCondition(column string, mode ExplorerFilterMode, value string, dbType string) string {
+	switch mode {
+	case FilterModeIsEmpty:
+		return fmt.Sprintf("(%s IS NULL OR %s = '')", column, textExpression(column, dbType))
+	case FilterModeIsNotEmpty:
+		return fmt.Sprintf("(%s IS NOT NULL AND %s <> '')", column, textExpression(column, dbType))
+	}
+
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
 		return ""
@@ -3657,6 +4554,15 @@ func buildFilterCondition(column string, mode ExplorerFilterMode, value string,
 	escaped := escapeSQLLiteral(trimmed)
 
 	switch mode {
+	case FilterModeGreaterThan, FilterModeLessThan:
+		operator := ">"
+		if mode == FilterModeLessThan {
+			operator = "<"
+		}
+		if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return fmt.Sprintf("%s %s %s", column, operator, trimmed)
+		}
+		return fmt.Sprintf("%s %s '%s'", column, operator, escaped)
 	case FilterModeEquals:
 		return fmt.Sprintf("%s = '%s'", column, escaped)
 	case FilterModeNotEquals:
@@ -3699,6 +4605,29 @@ func resolveExplorerFilters(filters []ExplorerFilterClause, columns []string) []
 	return resolved
 }
 
+// resolveRadiusFilters turns the value of each FilterModeWithinKm clause
+// into the dialect's haversine subquery, looking addresses up among the
+// stored locations.
+func (ui *Gui) resolveRadiusFilters(filters []ExplorerFilterClause) error {
+	for i := range filters {
+		clause := &filters[i]
+		if clause.Mode != FilterModeWithinKm {
+			continue
+		}
+		radius, err := app.ParseRadiusFilter(clause.Value)
+		if err != nil {
+			return err
+		}
+		if radius, err = ui.app.ResolveRadiusFilter(radius, nil); err != nil {
+			return err
+		}
+		if clause.radiusSQL, err = database.AccountsWithinRadiusSQL(ui.app.DB, radius.Center, radius.Km); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func buildExplorerWhereClause(filters []ExplorerFilterClause, dbType string) string {
 	if len(filters) == 0 {
 		return ""
@@ -3709,6 +4638,12 @@ func buildExplorerWhereClause(filters []ExplorerFilterClause, dbType string) str
 		if clause.Column == "" || clause.Mode == FilterModeNone {
 			continue
 		}
+		if clause.Mode == FilterModeWithinKm {
+			if clause.radiusSQL != "" {
+				clauses = append(clauses, fmt.Sprintf("%s IN (%s)", clause.Column, clause.radiusSQL))
+			}
+			continue
+		}
 		condition := buildFilterCondition(clause.Column, clause.Mode, clause.Value, dbType)
 		if condition != "" {
 			clauses = append(clauses, condition)
@@ -3790,7 +4725,7 @@ func (ui *Gui) getTableRowCount(tableName string) int {
 		return 0
 	}
 
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", database.QualifiedName(ui.app.DB, tableName))
 	rows, err := ui.app.DB.ExecuteQuery(query)
 	if err != nil {
 		ui.app.Events.Dispatch(events.Debugf("gui", "Error counting rows in %s: %v", tableName, err))
@@ -3819,12 +4754,13 @@ func (ui *Gui) getTableColumns(tableName string) []string {
 		return columns
 	}
 
+	table := database.QualifiedName(db, tableName)
 	var query string
 	switch strings.ToLower(db.GetType()) {
 	case "mssql":
-		query = fmt.Sprintf("SELECT TOP 1 * FROM %s", tableName)
+		query = fmt.Sprintf("SELECT TOP 1 * FROM %s", table)
 	default:
-		query = fmt.Sprintf("SELECT * FROM %s LIMIT 1", tableName)
+		query = fmt.Sprintf("SELECT * FROM %s LIMIT 1", table)
 	}
 
 	rows, err := db.ExecuteQuery(query)
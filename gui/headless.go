@@ -0,0 +1,351 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/database"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Presenter is the set of GUI operations that stays stable for automation.
+// GuiPresenter implements it; end-to-end tests drive it through a
+// HeadlessDriver instead of clicking widgets.
+type Presenter interface {
+	HandlePullImpactPreview(onConfirm func())
+	HandlePullGroup()
+	HandlePullAccount(idStr string)
+	HandlePullAccounts()
+	HandlePullCheckin(idStr string)
+	HandlePullCheckins()
+	HandlePullCheckinsForAccount(accountID int)
+	HandlePullRoute(idStr string)
+	HandlePullRoutes()
+	HandlePullLocations()
+	HandlePullDatasets()
+	HandlePullProfile()
+	HandlePullAccountBundle(accountID int, onPulled func())
+
+	HandlePushAccounts()
+	HandlePushCheckins()
+	HandlePushAll()
+	HandleSmartSync()
+	HandleShowPushPlan()
+	HandlePushFirst(entity string, limit int)
+	HandlePushQuickFilterChanged(filter string)
+	HandleRestoreDeletedAccount(accountID int)
+
+	HandleSaveConfig(
+		apiKey, baseURL, dbType, dbPath, dbHost, dbPortStr, dbUser, dbPass, dbName, dbAuth string,
+		themePreference string,
+		verbose, debug bool,
+		maxConcurrentStr string,
+		parallelProcessing bool,
+		customCheckins bool,
+	)
+	HandleTestAPIConnection(apiKey, baseURL string)
+	HandleTestDBConnection(dbType, dbPath, dbHost, dbPortStr, dbUser, dbPass, dbName, dbAuth string)
+	HandleSchemaEnforcement()
+	HandleSaveDisplayTimezone(name string)
+	HandleSaveAccountDisplayName(text string)
+	HandleSaveBatchSize(value string)
+	HandleSaveLowBandwidth(on bool)
+	HandleSaveCSVExport(options app.CSVExportConfig)
+	HandleSavePushThroughput(accountWorkers, accountRate, checkinWorkers, checkinRate string)
+	HandleSaveFieldSyncDirection(column, direction string)
+	HandleSaveFieldMergeStrategy(column, strategy string)
+	HandleSavePullCustomFields(fields []string)
+
+	HandleStageFieldEdit(accountID int, column, oldValue, newValue string) bool
+	HandleEditPendingChange(entityType string, changeID int, field, value string) bool
+	HandleOmniSearch(query string, scope string)
+	HandleRefreshStatus()
+	HandleTogglePprof(on bool)
+	HandleAnnotateSyncRun(historyID int64, note string)
+	HandleShowAPIConsole()
+	HandleSendAPIRequest(req api.ConsoleRequest, onResponse func(*api.ConsoleResponse, error))
+	HandleShowRoutePlanner(week string)
+	HandlePushRoutePlan(planID int)
+	HandleShowTerritories()
+	HandleStageTerritoryOwners(rule string)
+}
+
+var _ Presenter = (*GuiPresenter)(nil)
+
+// HeadlessView is a GuiView that records what the presenter shows instead
+// of drawing it. Confirmation dialogs are answered with ConfirmAnswer.
+type HeadlessView struct {
+	// ConfirmAnswer is the answer given to every confirmation dialog.
+	ConfirmAnswer bool
+
+	window fyne.Window
+
+	mu        sync.Mutex
+	changed   *sync.Cond
+	toasts    []string
+	errors    []error
+	confirms  []string
+	details   fyne.CanvasObject
+	progress  float64
+	busy      bool
+	refreshes int
+	theme     string
+}
+
+// NewHeadlessView creates a view that answers confirmations with yes.
+func NewHeadlessView(window fyne.Window) *HeadlessView {
+	v := &HeadlessView{ConfirmAnswer: true, window: window}
+	v.changed = sync.NewCond(&v.mu)
+	return v
+}
+
+func (v *HeadlessView) record(update func()) {
+	v.mu.Lock()
+	update()
+	v.mu.Unlock()
+	v.changed.Broadcast()
+}
+
+func (v *HeadlessView) ShowToast(message string) {
+	v.record(func() { v.toasts = append(v.toasts, message) })
+}
+
+func (v *HeadlessView) ShowProgressBar(title string) {
+	v.record(func() { v.busy = true })
+}
+
+func (v *HeadlessView) HideProgressBar() {
+	v.record(func() { v.busy = false })
+}
+
+func (v *HeadlessView) SetProgress(value float64) {
+	v.record(func() { v.progress = value })
+}
+
+func (v *HeadlessView) ShowErrorDialog(err error) {
+	v.record(func() { v.errors = append(v.errors, err) })
+}
+
+func (v *HeadlessView) ShowConfirmDialog(title, message string, callback func(bool)) {
+	v.record(func() { v.confirms = append(v.confirms, title) })
+	if callback != nil {
+		callback(v.ConfirmAnswer)
+	}
+}
+
+func (v *HeadlessView) RefreshHomeTab()   { v.record(func() { v.refreshes++ }) }
+func (v *HeadlessView) RefreshConfigTab() { v.record(func() { v.refreshes++ }) }
+func (v *HeadlessView) RefreshPushTab()   { v.record(func() { v.refreshes++ }) }
+func (v *HeadlessView) RefreshAllTabs()   { v.record(func() { v.refreshes++ }) }
+
+func (v *HeadlessView) ApplyThemePreference(pref string) {
+	v.record(func() { v.theme = pref })
+}
+
+func (v *HeadlessView) ShowDetails(details fyne.CanvasObject) {
+	v.record(func() { v.details = details })
+}
+
+func (v *HeadlessView) GetMainWindow() fyne.Window { return v.window }
+
+// Toasts returns the toasts shown so far, oldest first.
+func (v *HeadlessView) Toasts() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]string(nil), v.toasts...)
+}
+
+// Errors returns the errors shown in dialogs so far.
+func (v *HeadlessView) Errors() []error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]error(nil), v.errors...)
+}
+
+// Confirms returns the titles of the confirmation dialogs shown so far.
+func (v *HeadlessView) Confirms() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]string(nil), v.confirms...)
+}
+
+// Details returns the content last shown in the details pane.
+func (v *HeadlessView) Details() fyne.CanvasObject {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.details
+}
+
+// Refreshes returns how many times a tab refresh was requested.
+func (v *HeadlessView) Refreshes() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.refreshes
+}
+
+// Theme returns the theme preference last applied.
+func (v *HeadlessView) Theme() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.theme
+}
+
+// Busy reports whether a progress bar is showing.
+func (v *HeadlessView) Busy() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.busy
+}
+
+// viewMark counts what a view had shown before an operation started.
+type viewMark struct{ toasts, errors int }
+
+func (v *HeadlessView) mark() viewMark {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return viewMark{toasts: len(v.toasts), errors: len(v.errors)}
+}
+
+// result returns the error an operation started at m reported, through an
+// error dialog or an error toast.
+func (v *HeadlessView) result(m viewMark) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.errors) > m.errors {
+		return v.errors[len(v.errors)-1]
+	}
+	for _, t := range v.toasts[m.toasts:] {
+		if strings.HasPrefix(t, "Error") {
+			return errors.New(t)
+		}
+	}
+	return nil
+}
+
+// waitForToast waits until a toast follows m and the progress bar is hidden,
+// and returns the newest toast.
+func (v *HeadlessView) waitForToast(m viewMark, timeout time.Duration) (string, error) {
+	timer := time.AfterFunc(timeout, v.changed.Broadcast)
+	defer timer.Stop()
+	deadline := time.Now().Add(timeout)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for len(v.toasts) <= m.toasts || v.busy {
+		if len(v.errors) > m.errors {
+			return "", v.errors[len(v.errors)-1]
+		}
+		if !time.Now().Before(deadline) {
+			return "", fmt.Errorf("no result after %s", timeout)
+		}
+		v.changed.Wait()
+	}
+	return v.toasts[len(v.toasts)-1], nil
+}
+
+// WelcomeSetup is what a user enters in the first-run setup wizard.
+type WelcomeSetup struct {
+	APIKey  string
+	BaseURL string
+	DB      database.DBConfig
+	Theme   string
+}
+
+// HeadlessDriver runs GUI flows against an app without a display server. It
+// uses the same presenter and Explorer queries as the windowed GUI, with a
+// HeadlessView in place of the widgets.
+type HeadlessDriver struct {
+	// Timeout bounds how long a background operation may take.
+	Timeout time.Duration
+
+	ui   *Gui
+	view *HeadlessView
+}
+
+// NewHeadlessDriver creates a driver for a, rendering into Fyne's in-memory
+// test driver.
+func NewHeadlessDriver(a *app.App) *HeadlessDriver {
+	fyApp := test.NewApp()
+	view := NewHeadlessView(fyApp.NewWindow("Badger Maps Sync"))
+	ui := &Gui{
+		app:        a,
+		fyneApp:    fyApp,
+		window:     view.window,
+		logBinding: binding.NewStringList(),
+	}
+	ui.presenter = NewGuiPresenter(a, view)
+	ui.showWelcome = (a.API == nil || a.API.APIKey == "") || (a.DB == nil || a.DB.GetType() == "")
+	ui.welcomeScreen = NewWelcomeScreen(a, ui.presenter, func() {
+		ui.showWelcome = false
+	})
+	return &HeadlessDriver{Timeout: 30 * time.Second, ui: ui, view: view}
+}
+
+// Presenter returns the presenter the driver operates.
+func (d *HeadlessDriver) Presenter() Presenter { return d.ui.presenter }
+
+// View returns the view that records what the presenter showed.
+func (d *HeadlessDriver) View() *HeadlessView { return d.view }
+
+// NeedsSetup reports whether the GUI would open on the setup wizard.
+func (d *HeadlessDriver) NeedsSetup() bool { return d.ui.showWelcome }
+
+// CompleteWelcome walks the setup wizard: it saves the API and database
+// settings the way the configuration tab does, checks each step the wizard
+// validates, and finishes setup.
+func (d *HeadlessDriver) CompleteWelcome(setup WelcomeSetup) error {
+	if err := validateAPIKey(setup.APIKey); err != nil {
+		return fmt.Errorf("api step: %w", err)
+	}
+	port := ""
+	if setup.DB.Port != 0 {
+		port = fmt.Sprint(setup.DB.Port)
+	}
+	if err := d.SaveConfig(func(p Presenter) {
+		p.HandleSaveConfig(setup.APIKey, setup.BaseURL, setup.DB.Type, setup.DB.Path, setup.DB.Host, port,
+			setup.DB.Username, setup.DB.Password, setup.DB.Database, setup.DB.Auth, setup.Theme, false, false, "1", false, false)
+	}); err != nil {
+		return err
+	}
+	for step, name := range []string{1: "api", 2: "database"} {
+		if step > 0 && !d.ui.welcomeScreen.validateStep(step) {
+			return fmt.Errorf("%s step did not validate", name)
+		}
+	}
+	d.ui.welcomeScreen.onComplete()
+	return nil
+}
+
+// SaveConfig runs save, which should call a config handler, and returns an
+// error when the handler reported a failure.
+func (d *HeadlessDriver) SaveConfig(save func(Presenter)) error {
+	m := d.view.mark()
+	save(d.Presenter())
+	return d.view.result(m)
+}
+
+// Pull runs trigger, which should start a pull, and waits for it to finish.
+// It returns the final toast, or an error when the pull failed.
+func (d *HeadlessDriver) Pull(trigger func(Presenter)) (string, error) {
+	m := d.view.mark()
+	trigger(d.Presenter())
+	toast, err := d.view.waitForToast(m, d.Timeout)
+	if err != nil {
+		return "", err
+	}
+	return toast, d.view.result(m)
+}
+
+// Explore loads one page of an Explorer table with the given filters and
+// sort, as the Explorer tab does when a user pages through it.
+func (d *HeadlessDriver) Explore(tableName string, page, pageSize int, opts ExplorerQueryOptions) *PaginatedTableData {
+	return d.ui.loadPaginatedTableData(tableName, page, pageSize, opts)
+}
@@ -0,0 +1,125 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/app"
+	"badgermaps/database"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeadlessDriverFlows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/customers/1/"):
+			w.Write([]byte(`{"id": 1, "first_name": "Ada", "last_name": "Lovelace", "full_name": "Ada Lovelace"}`))
+		case strings.HasSuffix(r.URL.Path, "/customers/2/"):
+			w.Write([]byte(`{"id": 2, "first_name": "Alan", "last_name": "Turing", "full_name": "Alan Turing"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := app.NewApp()
+	a.State.ConfigFile = &configPath
+	a.ConfigFile = configPath
+
+	driver := NewHeadlessDriver(a)
+	if !driver.NeedsSetup() {
+		t.Fatal("an unconfigured app should open on the setup wizard")
+	}
+	if err := driver.CompleteWelcome(WelcomeSetup{APIKey: "short"}); err == nil {
+		t.Error("expected the API step to reject a short key")
+	}
+	setup := WelcomeSetup{
+		APIKey:  "test-api-key-0123456789",
+		BaseURL: server.URL,
+		DB:      database.DBConfig{Type: "sqlite3", Path: filepath.Join(dir, "test.db")},
+		Theme:   "light",
+	}
+	if err := driver.CompleteWelcome(setup); err != nil {
+		t.Fatalf("CompleteWelcome: %v", err)
+	}
+	if driver.NeedsSetup() {
+		t.Error("setup should be complete")
+	}
+	if a.Config.API.APIKey != setup.APIKey || a.Config.DB.Path != setup.DB.Path {
+		t.Errorf("config not saved: api key %q, db path %q", a.Config.API.APIKey, a.Config.DB.Path)
+	}
+	if got := driver.View().Theme(); got != "light" {
+		t.Errorf("applied theme %q, want light", got)
+	}
+	if err := a.DB.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DB.TestConnection(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DB.EnforceSchema(a.State); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSaveBatchSize("0") }); err == nil {
+		t.Error("expected an invalid batch size to be reported")
+	}
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSaveBatchSize("250") }); err != nil {
+		t.Errorf("HandleSaveBatchSize: %v", err)
+	}
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSavePushThroughput("0", "", "4", "") }); err == nil {
+		t.Error("expected invalid push workers to be reported")
+	}
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSavePushThroughput("1", "2", "4", "") }); err != nil {
+		t.Errorf("HandleSavePushThroughput: %v", err)
+	}
+	if got := a.PushThroughput("checkins"); got.Workers != 4 {
+		t.Errorf("check-in push workers = %d, want 4", got.Workers)
+	}
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSavePullCustomFields([]string{"custom_bogus"}) }); err == nil {
+		t.Error("expected an unknown custom field to be reported")
+	}
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSavePullCustomFields([]string{"mapped", "CustomText3"}) }); err != nil {
+		t.Errorf("HandleSavePullCustomFields: %v", err)
+	}
+	if hidden := a.HiddenAccountColumns(); len(hidden) != 59 || hidden["CustomText3"] {
+		t.Errorf("hidden account columns = %d, CustomText3 hidden = %v", len(hidden), hidden["CustomText3"])
+	}
+	if err := driver.SaveConfig(func(p Presenter) { p.HandleSavePullCustomFields(nil) }); err != nil || a.HiddenAccountColumns() != nil {
+		t.Errorf("pulling every custom field: %v, hidden %v", err, a.HiddenAccountColumns())
+	}
+
+	for _, id := range []string{"1", "2"} {
+		toast, err := driver.Pull(func(p Presenter) { p.HandlePullAccount(id) })
+		if err != nil {
+			t.Fatalf("pull account %s: %v", id, err)
+		}
+		if !strings.HasPrefix(toast, "Success") {
+			t.Errorf("pull account %s toast = %q", id, toast)
+		}
+	}
+	if _, err := driver.Pull(func(p Presenter) { p.HandlePullAccount("3") }); err == nil {
+		t.Error("expected a failed pull to be reported")
+	}
+
+	page := driver.Explore("Accounts", 0, 1, ExplorerQueryOptions{OrderColumn: "AccountId"})
+	if page.TotalRows != 2 || page.TotalPages != 2 || len(page.Data) != 1 {
+		t.Fatalf("first page = %d rows of %d over %d pages", len(page.Data), page.TotalRows, page.TotalPages)
+	}
+	page = driver.Explore("Accounts", 0, 10, ExplorerQueryOptions{
+		Filters: []ExplorerFilterClause{{Column: "LastName", Mode: FilterModeContains, Value: "tur"}},
+	})
+	if page.TotalRows != 1 {
+		t.Errorf("filtered rows = %d, want 1", page.TotalRows)
+	}
+}
@@ -0,0 +1,221 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/app/push"
+	"badgermaps/database"
+)
+
+// showAccountChangeDiff shows a staged account change field by field in the
+// details pane.
+func (ui *Gui) showAccountChangeDiff(change database.AccountPendingChange) {
+	title := fmt.Sprintf("%s account %d (change %d, %s)", change.ChangeType, change.AccountId, change.ChangeId, change.Status)
+	diffs, err := ui.app.AccountChangeDiff(change)
+	if err != nil {
+		ui.ShowDetails(container.NewVBox(widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			NewWrappingLabel(fmt.Sprintf("Could not read the staged fields: %v", err)),
+			NewWrappingLabel(change.Changes)))
+		return
+	}
+	request, requestErr := push.PreviewAccountChange(ui.app, change)
+	ui.ShowDetails(ui.newChangeDiffView(title, diffs, change.ValidationErrors, request, requestErr, app.AccountChangeEditable(change), func(field, value string) {
+		if ui.presenter.HandleEditPendingChange("accounts", change.ChangeId, field, value) {
+			change.Changes = stagedAccountChanges(diffs, field, value)
+			change.Status, change.ValidationErrors = "pending", nil
+			ui.showAccountChangeDiff(change)
+		}
+	}))
+}
+
+// showCheckinChangeDiff shows a staged check-in change field by field in the
+// details pane.
+func (ui *Gui) showCheckinChangeDiff(change database.CheckinPendingChange) {
+	title := fmt.Sprintf("%s check-in for account %d (change %d, %s)", change.ChangeType, change.AccountId, change.ChangeId, change.Status)
+	diffs, err := ui.app.CheckinChangeDiff(change)
+	if err != nil {
+		ui.ShowDetails(NewWrappingLabel(fmt.Sprintf("%s\n\nCould not compare the staged fields: %v", title, err)))
+		return
+	}
+	request, requestErr := push.PreviewCheckinChange(ui.app, change)
+	ui.ShowDetails(ui.newChangeDiffView(title, diffs, nil, request, requestErr, change.Status == "pending", func(field, value string) {
+		if !ui.presenter.HandleEditPendingChange("checkins", change.ChangeId, field, value) {
+			return
+		}
+		valid := value != ""
+		switch field {
+		case "CrmId":
+			change.CrmId.String, change.CrmId.Valid = value, valid
+		case "LogDatetime":
+			change.LogDatetime.String, change.LogDatetime.Valid = value, valid
+		case "Type":
+			change.Type.String, change.Type.Valid = value, valid
+		case "Comments":
+			change.Comments.String, change.Comments.Valid = value, valid
+		}
+		ui.showCheckinChangeDiff(change)
+	}))
+}
+
+// newChangeDiffView lays out diffs with removed values in red and added
+// values in green. The messages of fieldErrors, keyed by field, are shown
+// under the field they name, and the rest above the fields. When editable,
+// each field has a button that asks for a new staged value and passes it
+// to onEdit. The request a push would send follows the fields, with a
+// button that copies it as a cURL command; requestErr says why there is
+// none.
+func (ui *Gui) newChangeDiffView(title string, diffs []app.FieldDiff, fieldErrors map[string][]string, request *api.RequestPreview, requestErr error, editable bool, onEdit func(field, value string)) fyne.CanvasObject {
+	copyBtn := widget.NewButtonWithIcon("Copy as JSON", theme.ContentCopyIcon(), func() {
+		text, err := app.DiffJSON(diffs)
+		if err != nil {
+			ui.ShowErrorDialog(err)
+			return
+		}
+		fyne.CurrentApp().Clipboard().SetContent(text)
+		ui.ShowToast("Copied change as JSON.")
+	})
+	buttons := container.NewHBox(copyBtn)
+	if request != nil {
+		buttons.Add(widget.NewButtonWithIcon("Copy as cURL", theme.ContentCopyIcon(), func() {
+			fyne.CurrentApp().Clipboard().SetContent(request.Curl())
+			ui.ShowToast("Copied request as cURL; set API_KEY before running it.")
+		}))
+	}
+	rows := container.NewVBox(
+		widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		buttons,
+		widget.NewSeparator(),
+	)
+	staged := make(map[string]bool, len(diffs))
+	for _, d := range diffs {
+		staged[d.Field] = true
+	}
+	var general []string
+	for field, messages := range fieldErrors {
+		if staged[field] {
+			continue
+		}
+		for _, message := range messages {
+			if field != "" {
+				message = field + ": " + message
+			}
+			general = append(general, message)
+		}
+	}
+	sort.Strings(general)
+	if len(fieldErrors) > 0 {
+		rows.Add(validationLabel("BadgerMaps rejected this change. Edit the fields below to queue it again."))
+	}
+	for _, message := range general {
+		rows.Add(validationLabel(message))
+	}
+	if len(diffs) == 0 {
+		rows.Add(widget.NewLabel("No fields are staged."))
+	}
+	for _, diff := range diffs {
+		d := diff
+		header := widget.NewLabelWithStyle(fmt.Sprintf("%s (%s, %s)", d.Label, d.Type, d.Kind), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		values := container.NewVBox()
+		if d.Kind == app.DiffUnchanged {
+			values.Add(diffValueLabel("  ", d.New, widget.MediumImportance))
+		} else {
+			if d.Old != "" {
+				values.Add(diffValueLabel("- ", d.Old, widget.DangerImportance))
+			}
+			if d.New != "" {
+				values.Add(diffValueLabel("+ ", d.New, widget.SuccessImportance))
+			}
+		}
+		for _, message := range fieldErrors[d.Field] {
+			values.Add(validationLabel(message))
+		}
+		var editBtn fyne.CanvasObject
+		if editable {
+			editBtn = widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+				ui.showChangeValueEditor(d, onEdit)
+			})
+		}
+		rows.Add(container.NewBorder(header, nil, nil, editBtn, values))
+		rows.Add(widget.NewSeparator())
+	}
+	rows.Add(widget.NewLabelWithStyle("Request", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	switch {
+	case requestErr != nil:
+		rows.Add(validationLabel(fmt.Sprintf("This change cannot be pushed as staged: %v", requestErr)))
+	case request == nil:
+		rows.Add(widget.NewLabel("Nothing is sent; every staged field is pull-only."))
+	default:
+		text := request.Method + " " + request.URL
+		if request.IdempotencyKey != "" {
+			text += "\nIdempotency-Key: " + request.IdempotencyKey
+		}
+		if request.Body != "" {
+			text += "\n\n" + request.Body
+		}
+		label := widget.NewLabelWithStyle(text, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		label.Wrapping = fyne.TextWrapBreak
+		rows.Add(label)
+	}
+	return container.NewVScroll(rows)
+}
+
+func diffValueLabel(prefix, value string, importance widget.Importance) *widget.Label {
+	label := widget.NewLabel(prefix + value)
+	label.Wrapping = fyne.TextWrapWord
+	label.Importance = importance
+	return label
+}
+
+// validationLabel shows an error the API returned for a pushed change.
+func validationLabel(message string) *widget.Label {
+	label := widget.NewLabelWithStyle(message, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	label.Wrapping = fyne.TextWrapWord
+	label.Importance = widget.DangerImportance
+	return label
+}
+
+// showChangeValueEditor asks for a new staged value of one field.
+func (ui *Gui) showChangeValueEditor(diff app.FieldDiff, onEdit func(field, value string)) {
+	entry := widget.NewEntry()
+	if diff.Field == "Comments" || diff.Field == "notes" {
+		entry = widget.NewMultiLineEntry()
+	}
+	entry.SetText(diff.New)
+	items := []*widget.FormItem{widget.NewFormItem(diff.Label, entry)}
+	if diff.Old != "" {
+		items = append(items, widget.NewFormItem("Current", NewWrappingLabel(diff.Old)))
+	}
+	dlg := dialog.NewForm(fmt.Sprintf("Edit staged %s", diff.Label), "Save", "Cancel", items, func(confirm bool) {
+		if confirm {
+			onEdit(diff.Field, entry.Text)
+		}
+	}, ui.window)
+	dlg.Resize(fyne.NewSize(420, 0))
+	dlg.Show()
+	ui.window.Canvas().Focus(entry)
+}
+
+// stagedAccountChanges returns the staged JSON after setting field to value,
+// so the view can be redrawn without reloading the change.
+func stagedAccountChanges(diffs []app.FieldDiff, field, value string) string {
+	fields := make(map[string]string, len(diffs))
+	for _, d := range diffs {
+		fields[d.Field] = d.New
+	}
+	fields[field] = strings.TrimSpace(value)
+	data, _ := json.Marshal(fields)
+	return string(data)
+}
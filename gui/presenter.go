@@ -1,3 +1,5 @@
+//go:build !nogui
+
 package gui
 
 import (
@@ -5,16 +7,26 @@ import (
 	"badgermaps/app"
 	"badgermaps/app/pull"
 	"badgermaps/app/push"
+	"badgermaps/app/server"
+	"badgermaps/app/smartsync"
 	"badgermaps/database"
 	"badgermaps/events"
+	"badgermaps/utils"
+	"context"
+	"errors"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"gopkg.in/yaml.v2"
+	"maps"
+	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // GuiPresenter handles the presentation logic for the GUI.
@@ -23,6 +35,19 @@ type GuiPresenter struct {
 	app *app.App
 	// view is an interface, allowing us to swap out the UI implementation or mock it for testing.
 	view GuiView
+
+	progressOnce  sync.Once
+	progressMu    sync.Mutex
+	progressStage progressStage
+}
+
+// progressStage maps the progress events of one entity onto a slice of the
+// shared progress bar, so a multi-stage pull fills the bar stage by stage.
+type progressStage struct {
+	entity string
+	verb   string
+	base   float64
+	weight float64
 }
 
 // NewGuiPresenter creates a new presenter.
@@ -32,56 +57,68 @@ func NewGuiPresenter(a *app.App, v GuiView) *GuiPresenter {
 
 // --- Pull Handlers ---
 
+// HandlePullImpactPreview estimates the effect of a full pull and asks the
+// user to confirm before onConfirm is invoked on the UI thread.
+func (p *GuiPresenter) HandlePullImpactPreview(onConfirm func()) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullImpactPreview called"))
+	go func() {
+		message := ""
+		impact, err := pull.EstimatePullImpact(p.app, 0)
+		if err != nil {
+			p.app.Events.Dispatch(events.Warningf("presenter", "Could not estimate pull impact: %v", err))
+			message = fmt.Sprintf("Impact preview unavailable: %v\n\nRun the full sync anyway?", err)
+		} else {
+			message = impact.String() + "\n\nRun the full sync now?"
+		}
+		fyne.Do(func() {
+			p.view.ShowConfirmDialog("Full Sync Preview", message, func(confirmed bool) {
+				if confirmed && onConfirm != nil {
+					onConfirm()
+				}
+			})
+		})
+	}()
+}
+
 // HandlePullGroup initiates a full data pull for all data types.
 func (p *GuiPresenter) HandlePullGroup() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullGroup called"))
+	if !p.schemaReady(p.HandlePullGroup) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting full data pull..."))
 	p.view.ShowProgressBar("Running Full Pull...")
 	p.view.SetProgress(0)
 
 	go func() {
 		defer p.view.HideProgressBar()
+		defer p.trackProgress("", "", 0, 0)
 
 		totalMajorSteps := 4.0
 		majorStepWeight := 1.0 / totalMajorSteps
 
-		p.app.Events.Dispatch(events.Infof("presenter", "Pulling accounts..."))
-		accountsCallback := func(current, total int) {
-			progress := (float64(current) / float64(total)) * majorStepWeight
-			p.view.SetProgress(progress)
-		}
-		if err := pull.PullGroupAccounts(p.app, 0, accountsCallback); err != nil {
+		p.trackProgress("accounts", "Pulling", 0, majorStepWeight)
+		if err := pull.PullGroupAccounts(p.app, 0); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "Error pulling accounts: %v", err))
 			p.view.ShowToast("Error: The data pull failed.")
 			return
 		}
-		p.view.SetProgress(majorStepWeight)
 
-		p.app.Events.Dispatch(events.Infof("presenter", "Pulling checkins..."))
-		checkinsCallback := func(current, total int) {
-			progress := majorStepWeight + (float64(current)/float64(total))*majorStepWeight
-			p.view.SetProgress(progress)
-		}
-		if err := pull.PullGroupCheckins(p.app, checkinsCallback); err != nil {
+		p.trackProgress("checkins", "Pulling", majorStepWeight, majorStepWeight)
+		if err := pull.PullGroupCheckins(p.app); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "Error pulling checkins: %v", err))
 			p.view.ShowToast("Error: The data pull failed.")
 			return
 		}
-		p.view.SetProgress(2 * majorStepWeight)
 
-		p.app.Events.Dispatch(events.Infof("presenter", "Pulling routes..."))
-		routesCallback := func(current, total int) {
-			progress := 2*majorStepWeight + (float64(current)/float64(total))*majorStepWeight
-			p.view.SetProgress(progress)
-		}
-		if err := pull.PullGroupRoutes(p.app, routesCallback); err != nil {
+		p.trackProgress("routes", "Pulling", 2*majorStepWeight, majorStepWeight)
+		if err := pull.PullGroupRoutes(p.app); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "Error pulling routes: %v", err))
 			p.view.ShowToast("Error: The data pull failed.")
 			return
 		}
-		p.view.SetProgress(3 * majorStepWeight)
 
-		p.app.Events.Dispatch(events.Infof("presenter", "Pulling user profile..."))
+		p.view.ShowProgressBar("Pulling user profile")
 		profileCallback := func(current, total int) {
 			progress := 3*majorStepWeight + (float64(current)/float64(total))*majorStepWeight
 			p.view.SetProgress(progress)
@@ -98,9 +135,41 @@ func (p *GuiPresenter) HandlePullGroup() {
 	}()
 }
 
+// trackProgress shows the progress events for entity on the progress bar,
+// titled with verb and the event summary, scaled into [base, base+weight]. An empty entity stops
+// tracking.
+func (p *GuiPresenter) trackProgress(entity, verb string, base, weight float64) {
+	p.progressOnce.Do(func() {
+		p.app.Events.Subscribe("pull.progress", p.onProgress)
+		p.app.Events.Subscribe("push.progress", p.onProgress)
+	})
+	p.progressMu.Lock()
+	p.progressStage = progressStage{entity: entity, verb: verb, base: base, weight: weight}
+	p.progressMu.Unlock()
+}
+
+func (p *GuiPresenter) onProgress(e events.Event) {
+	payload, ok := e.Payload.(events.ProgressPayload)
+	if !ok {
+		return
+	}
+	p.progressMu.Lock()
+	stage := p.progressStage
+	p.progressMu.Unlock()
+	// Events are delivered asynchronously; ignore late ones from an earlier stage.
+	if stage.entity == "" || stage.entity != payload.Entity {
+		return
+	}
+	p.view.ShowProgressBar(stage.verb + " " + payload.Summary())
+	p.view.SetProgress(stage.base + payload.Fraction()*stage.weight)
+}
+
 // HandlePullAccount pulls a single account by its ID.
 func (p *GuiPresenter) HandlePullAccount(idStr string) {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullAccount called with id: %s", idStr))
+	if !p.schemaReady(func() { p.HandlePullAccount(idStr) }) {
+		return
+	}
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		p.app.Events.Dispatch(events.Errorf("presenter", "Invalid Account ID: '%s'", idStr))
@@ -110,13 +179,49 @@ func (p *GuiPresenter) HandlePullAccount(idStr string) {
 	go func() {
 		if _, err := pull.PullAccount(p.app, id); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast(fmt.Sprintf("Error: Failed to pull account %d.", id))
+			p.errorToast(fmt.Sprintf("Error: Failed to pull account %d.", id), err)
 			return
 		}
 		p.view.ShowToast(fmt.Sprintf("Success: Pulled account %d.", id))
 	}()
 }
 
+// HandlePullAccountBundle pulls an account with its check-ins and the
+// routes that visit it in one transaction, and calls onPulled once they
+// are stored.
+func (p *GuiPresenter) HandlePullAccountBundle(accountID int, onPulled func()) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullAccountBundle called with id: %d", accountID))
+	if !p.schemaReady(func() { p.HandlePullAccountBundle(accountID, onPulled) }) {
+		return
+	}
+	go func() {
+		bundle, err := pull.PullAccountBundle(p.app, accountID, pull.BundleOptions{Checkins: true, Routes: true})
+		if err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+			p.errorToast(fmt.Sprintf("Error: Failed to pull account %d.", accountID), err)
+			return
+		}
+		p.view.ShowToast(fmt.Sprintf("Success: Pulled %s.", bundle))
+		if onPulled != nil {
+			onPulled()
+		}
+	}()
+}
+
+// HandleFetchStaleAccount fetches the details of an account a
+// low-bandwidth pull left stale, and calls onFetched once they are stored.
+func (p *GuiPresenter) HandleFetchStaleAccount(accountID int, onFetched func()) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleFetchStaleAccount called with id: %d", accountID))
+	go func() {
+		if _, err := pull.PullAccount(p.app, accountID); err != nil {
+			p.app.Events.Dispatch(events.Warningf("presenter", "Could not fetch the details of account %d: %v", accountID, err))
+			p.errorToast(fmt.Sprintf("Error: Failed to fetch account %d.", accountID), err)
+			return
+		}
+		onFetched()
+	}()
+}
+
 // HandleOmniSearch performs a unified search across Accounts, Check-ins, and Routes.
 func (p *GuiPresenter) HandleOmniSearch(query string, scope string) {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandleOmniSearch called: q='%s', scope='%s'", query, scope))
@@ -139,11 +244,21 @@ func (p *GuiPresenter) HandleOmniSearch(query string, scope string) {
 			if err != nil {
 				return err
 			}
+			ids := make([]int, len(rows))
+			for i, a := range rows {
+				ids[i] = int(a.AccountId.Int64)
+			}
+			names, _ := p.app.AccountDisplayNames(ids)
 			for _, a := range rows {
+				id := int(a.AccountId.Int64)
+				name := names[id]
+				if name == "" {
+					name = a.FullName.String
+				}
 				results = append(results, result{
 					Type: "account",
-					ID:   int(a.AccountId.Int64),
-					Name: a.FullName.String,
+					ID:   id,
+					Name: name,
 				})
 			}
 			return nil
@@ -271,18 +386,20 @@ func (p *GuiPresenter) HandleOmniSearch(query string, scope string) {
 // HandlePullAccounts pulls all accounts.
 func (p *GuiPresenter) HandlePullAccounts() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullAccounts called"))
+	if !p.schemaReady(p.HandlePullAccounts) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting pull for all accounts..."))
 	p.view.ShowProgressBar("Pulling Accounts...")
 	p.view.SetProgress(0)
 
 	go func() {
 		defer p.view.HideProgressBar()
-		callback := func(current, total int) {
-			p.view.SetProgress(float64(current) / float64(total))
-		}
-		if err := pull.PullGroupAccounts(p.app, 0, callback); err != nil {
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("accounts", "Pulling", 0, 1)
+		if err := pull.PullGroupAccounts(p.app, 0); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast("Error: Failed to pull all accounts.")
+			p.errorToast("Error: Failed to pull all accounts.", err)
 			return
 		}
 		p.view.SetProgress(1)
@@ -293,6 +410,9 @@ func (p *GuiPresenter) HandlePullAccounts() {
 // HandlePullCheckin pulls a single check-in by its ID.
 func (p *GuiPresenter) HandlePullCheckin(idStr string) {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullCheckin called with id: %s", idStr))
+	if !p.schemaReady(func() { p.HandlePullCheckin(idStr) }) {
+		return
+	}
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		p.app.Events.Dispatch(events.Errorf("presenter", "Invalid Check-in ID: '%s'", idStr))
@@ -302,7 +422,7 @@ func (p *GuiPresenter) HandlePullCheckin(idStr string) {
 	go func() {
 		if _, err := pull.PullCheckin(p.app, id); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast(fmt.Sprintf("Error: Failed to pull check-in %d.", id))
+			p.errorToast(fmt.Sprintf("Error: Failed to pull check-in %d.", id), err)
 			return
 		}
 		p.view.ShowToast(fmt.Sprintf("Success: Pulled check-in %d.", id))
@@ -312,17 +432,19 @@ func (p *GuiPresenter) HandlePullCheckin(idStr string) {
 // HandlePullCheckins pulls all check-ins.
 func (p *GuiPresenter) HandlePullCheckins() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullCheckins called"))
+	if !p.schemaReady(p.HandlePullCheckins) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting pull for all check-ins..."))
 	p.view.ShowProgressBar("Pulling Check-ins...")
 	p.view.SetProgress(0)
 	go func() {
 		defer p.view.HideProgressBar()
-		callback := func(current, total int) {
-			p.view.SetProgress(float64(current) / float64(total))
-		}
-		if err := pull.PullGroupCheckins(p.app, callback); err != nil {
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("checkins", "Pulling", 0, 1)
+		if err := pull.PullGroupCheckins(p.app); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast("Error: Failed to pull all check-ins.")
+			p.errorToast("Error: Failed to pull all check-ins.", err)
 			return
 		}
 		p.view.SetProgress(1)
@@ -333,12 +455,15 @@ func (p *GuiPresenter) HandlePullCheckins() {
 // HandlePullCheckinsForAccount pulls all check-ins for a specific account ID.
 func (p *GuiPresenter) HandlePullCheckinsForAccount(accountID int) {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullCheckinsForAccount called for account %d", accountID))
+	if !p.schemaReady(func() { p.HandlePullCheckinsForAccount(accountID) }) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Pulling check-ins for account %d...", accountID))
 
 	go func() {
 		if err := pull.PullCheckinsForAccount(p.app, accountID); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast(fmt.Sprintf("Error: Failed to pull check-ins for account %d.", accountID))
+			p.errorToast(fmt.Sprintf("Error: Failed to pull check-ins for account %d.", accountID), err)
 			return
 		}
 		p.view.ShowToast(fmt.Sprintf("Success: Pulled check-ins for account %d.", accountID))
@@ -348,6 +473,9 @@ func (p *GuiPresenter) HandlePullCheckinsForAccount(accountID int) {
 // HandlePullRoute pulls a single route by its ID.
 func (p *GuiPresenter) HandlePullRoute(idStr string) {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullRoute called with id: %s", idStr))
+	if !p.schemaReady(func() { p.HandlePullRoute(idStr) }) {
+		return
+	}
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		p.app.Events.Dispatch(events.Errorf("presenter", "Invalid Route ID: '%s'", idStr))
@@ -357,7 +485,7 @@ func (p *GuiPresenter) HandlePullRoute(idStr string) {
 	go func() {
 		if _, err := pull.PullRoute(p.app, id); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast(fmt.Sprintf("Error: Failed to pull route %d.", id))
+			p.errorToast(fmt.Sprintf("Error: Failed to pull route %d.", id), err)
 			return
 		}
 		p.view.ShowToast(fmt.Sprintf("Success: Pulled route %d.", id))
@@ -367,17 +495,19 @@ func (p *GuiPresenter) HandlePullRoute(idStr string) {
 // HandlePullRoutes pulls all routes.
 func (p *GuiPresenter) HandlePullRoutes() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullRoutes called"))
+	if !p.schemaReady(p.HandlePullRoutes) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting pull for all routes..."))
 	p.view.ShowProgressBar("Pulling Routes...")
 	p.view.SetProgress(0)
 	go func() {
 		defer p.view.HideProgressBar()
-		callback := func(current, total int) {
-			p.view.SetProgress(float64(current) / float64(total))
-		}
-		if err := pull.PullGroupRoutes(p.app, callback); err != nil {
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("routes", "Pulling", 0, 1)
+		if err := pull.PullGroupRoutes(p.app); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast("Error: Failed to pull all routes.")
+			p.errorToast("Error: Failed to pull all routes.", err)
 			return
 		}
 		p.view.SetProgress(1)
@@ -385,9 +515,57 @@ func (p *GuiPresenter) HandlePullRoutes() {
 	}()
 }
 
+// HandlePullLocations refreshes the stored account locations.
+func (p *GuiPresenter) HandlePullLocations() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullLocations called"))
+	if !p.schemaReady(p.HandlePullLocations) {
+		return
+	}
+	p.app.Events.Dispatch(events.Infof("presenter", "Starting location refresh..."))
+	p.view.ShowProgressBar("Refreshing Locations...")
+	p.view.SetProgress(0)
+	go func() {
+		defer p.view.HideProgressBar()
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("locations", "Refreshing", 0, 1)
+		if err := pull.PullGroupLocations(p.app); err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+			p.errorToast("Error: Failed to refresh locations.", err)
+			return
+		}
+		p.view.SetProgress(1)
+		p.view.ShowToast("Success: Refreshed locations.")
+	}()
+}
+
+// HandlePullDatasets refreshes the profile datasets.
+func (p *GuiPresenter) HandlePullDatasets() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullDatasets called"))
+	if !p.schemaReady(p.HandlePullDatasets) {
+		return
+	}
+	p.app.Events.Dispatch(events.Infof("presenter", "Starting dataset refresh..."))
+	p.view.ShowProgressBar("Refreshing Datasets...")
+	p.view.SetProgress(0)
+	go func() {
+		defer p.view.HideProgressBar()
+		count, err := pull.PullDatasets(p.app)
+		if err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+			p.errorToast("Error: Failed to refresh datasets.", err)
+			return
+		}
+		p.view.SetProgress(1)
+		p.view.ShowToast(fmt.Sprintf("Success: Refreshed %d datasets.", count))
+	}()
+}
+
 // HandlePullProfile pulls the user profile.
 func (p *GuiPresenter) HandlePullProfile() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePullProfile called"))
+	if !p.schemaReady(p.HandlePullProfile) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting pull for user profile..."))
 	p.view.ShowProgressBar("Pulling User Profile...")
 	p.view.SetProgress(0)
@@ -396,7 +574,7 @@ func (p *GuiPresenter) HandlePullProfile() {
 		if p.app.DB == nil || p.app.DB.GetDB() == nil {
 			if err := p.app.ReloadDB(); err != nil {
 				p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: Failed to connect to database: %v", err))
-				p.view.ShowToast("Error: Failed to connect to database.")
+				p.errorToast("Error: Failed to connect to database.", err)
 				return
 			}
 		}
@@ -405,7 +583,7 @@ func (p *GuiPresenter) HandlePullProfile() {
 		}
 		if _, err := pull.PullProfile(p.app, callback); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-			p.view.ShowToast("Error: Failed to pull user profile.")
+			p.errorToast("Error: Failed to pull user profile.", err)
 			return
 		}
 		p.view.SetProgress(1)
@@ -413,22 +591,58 @@ func (p *GuiPresenter) HandlePullProfile() {
 	}()
 }
 
+// HandleDetectFieldSchema reads the profile's data fields to label the
+// custom Accounts columns, warning about data fields with no column. It
+// blocks, and reports whether the labels changed.
+func (p *GuiPresenter) HandleDetectFieldSchema() bool {
+	if p.app.API == nil || p.app.DB == nil || !p.app.DB.IsConnected() {
+		return false
+	}
+	before := maps.Clone(p.app.AccountFieldLabels())
+	schema, err := pull.DetectFieldSchema(p.app)
+	if err != nil {
+		p.app.Events.Dispatch(events.Warningf("presenter", "Could not read field labels from the profile: %v", err))
+		return false
+	}
+	if n := len(schema.Unmapped); n > 0 {
+		p.view.ShowToast(fmt.Sprintf("%d profile field(s) have no Accounts column and are not stored; see the log.", n))
+	}
+	return !maps.Equal(before, schema.Labels)
+}
+
 // --- Push Handlers ---
 
 // HandlePushAccounts pushes pending account changes.
 func (p *GuiPresenter) HandlePushAccounts() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePushAccounts called"))
+	if !p.schemaReady(p.HandlePushAccounts) {
+		return
+	}
+	p.confirmPendingDeletes(p.pushAccounts)
+}
+
+func (p *GuiPresenter) pushAccounts(confirmDeletes bool) {
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting push for account changes..."))
 	go func() {
+		defer p.view.HideProgressBar()
+		defer p.allowDeletes(confirmDeletes)()
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("accounts", "Pushing", 0, 1)
+		hints, stop := p.collectPushHints()
+		defer stop()
 		if err := push.RunPushAccounts(p.app); err != nil {
+			if p.showPushQueued(err) {
+				return
+			}
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
 			fyne.Do(func() {
-				p.view.ShowToast("Error: Failed to push account changes.")
+				p.errorToast("Error: Failed to push account changes.", err)
 			})
 			return
 		}
+		result := pushResultToast("Success: Account changes pushed.", hints())
 		fyne.Do(func() {
-			p.view.ShowToast("Success: Account changes pushed.")
+			p.view.ShowToast(result)
 			p.view.RefreshPushTab()
 		})
 	}()
@@ -437,17 +651,29 @@ func (p *GuiPresenter) HandlePushAccounts() {
 // HandlePushCheckins pushes pending check-in changes.
 func (p *GuiPresenter) HandlePushCheckins() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePushCheckins called"))
+	if !p.schemaReady(p.HandlePushCheckins) {
+		return
+	}
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting push for check-in changes..."))
 	go func() {
+		defer p.view.HideProgressBar()
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("checkins", "Pushing", 0, 1)
+		hints, stop := p.collectPushHints()
+		defer stop()
 		if err := push.RunPushCheckins(p.app); err != nil {
+			if p.showPushQueued(err) {
+				return
+			}
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
 			fyne.Do(func() {
-				p.view.ShowToast("Error: Failed to push check-in changes.")
+				p.errorToast("Error: Failed to push check-in changes.", err)
 			})
 			return
 		}
+		result := pushResultToast("Success: Check-in changes pushed.", hints())
 		fyne.Do(func() {
-			p.view.ShowToast("Success: Check-in changes pushed.")
+			p.view.ShowToast(result)
 			p.view.RefreshPushTab()
 		})
 	}()
@@ -456,26 +682,203 @@ func (p *GuiPresenter) HandlePushCheckins() {
 // HandlePushAll pushes all pending changes.
 func (p *GuiPresenter) HandlePushAll() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePushAll called"))
+	if !p.schemaReady(p.HandlePushAll) {
+		return
+	}
+	p.confirmPendingDeletes(p.pushAll)
+}
+
+func (p *GuiPresenter) pushAll(confirmDeletes bool) {
 	p.app.Events.Dispatch(events.Infof("presenter", "Starting push for all changes..."))
 	go func() {
+		defer p.view.HideProgressBar()
+		defer p.allowDeletes(confirmDeletes)()
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress("accounts", "Pushing", 0, 0.5)
+		hints, stop := p.collectPushHints()
+		defer stop()
 		if err := push.RunPushAccounts(p.app); err != nil {
+			if p.showPushQueued(err) {
+				return
+			}
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR during account push: %v", err))
 		}
+		p.trackProgress("checkins", "Pushing", 0.5, 0.5)
 		if err := push.RunPushCheckins(p.app); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR during check-in push: %v", err))
 		}
+		result := pushResultToast("Success: All pending changes pushed.", hints())
+		fyne.Do(func() {
+			p.view.ShowToast(result)
+			p.view.RefreshPushTab()
+		})
+	}()
+}
+
+// HandleSmartSync runs only the retries, pushes and pulls the database
+// needs, in order, and reports what ran.
+func (p *GuiPresenter) HandleSmartSync() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSmartSync called"))
+	if !p.schemaReady(p.HandleSmartSync) {
+		return
+	}
+	plan, err := smartsync.PlanSync(p.app, time.Now(), 0)
+	if err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	if plan.Empty() {
+		message := "Everything is up to date."
+		if len(plan.Deferred) > 0 {
+			message += " Left for later: " + strings.Join(plan.Deferred, "; ")
+		}
+		p.view.ShowToast(message)
+		return
+	}
+	run := func(confirmDeletes bool) { p.smartSync(plan, confirmDeletes) }
+	if plan.Has(smartsync.StepPushAccounts) {
+		p.confirmPendingDeletes(run)
+		return
+	}
+	run(false)
+}
+
+func (p *GuiPresenter) smartSync(plan *smartsync.Plan, confirmDeletes bool) {
+	p.app.Events.Dispatch(events.Infof("presenter", "Starting Smart Sync (%d step(s))...", len(plan.Steps)))
+	go func() {
+		defer p.view.HideProgressBar()
+		defer p.allowDeletes(confirmDeletes)()
+		result := smartsync.Run(p.app, plan, func(step smartsync.Step, index, total int) {
+			p.view.ShowProgressBar(fmt.Sprintf("Smart Sync: %s (%d of %d)", step.Label(), index+1, total))
+			p.view.SetProgress(float64(index) / float64(total))
+		})
+		if err := result.Err(); err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR during Smart Sync: %v", err))
+			p.errorToast("Error: "+result.Summary(), err)
+		} else {
+			p.view.ShowToast("Success: " + result.Summary())
+		}
+		fyne.Do(p.view.RefreshPushTab)
+	}()
+}
+
+// schemaReady reports whether pull and push may run against the database.
+// When a migration is pending it offers to run it and then calls retry;
+// other schema problems are shown as an error.
+func (p *GuiPresenter) schemaReady(retry func()) bool {
+	err := p.app.CheckSchema()
+	if err == nil {
+		return true
+	}
+	var schemaErr *app.SchemaError
+	if !errors.As(err, &schemaErr) || !schemaErr.MigrationPending {
+		p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+		p.view.ShowErrorDialog(err)
+		return false
+	}
+	message := fmt.Sprintf("The database schema is out of date: %v.\n\nCreate the missing tables now? Existing data is kept.", schemaErr.Err)
+	p.view.ShowConfirmDialog("Database Migration Needed", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		p.guardProduction("migrating the schema", func() {
+			go func() {
+				if err := p.app.MigrateSchema(); err != nil {
+					p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+					fyne.Do(func() {
+						p.view.ShowErrorDialog(err)
+					})
+					return
+				}
+				fyne.Do(func() {
+					p.view.ShowToast("Success: Database schema migrated.")
+					retry()
+				})
+			}()
+		})
+	})
+	return false
+}
+
+// confirmPendingDeletes asks before pending account deletions are pushed
+// when the recycle bin policy requires it, then calls run with the answer.
+// Declined deletions stay queued while the other changes are pushed.
+func (p *GuiPresenter) confirmPendingDeletes(run func(confirmDeletes bool)) {
+	if !p.app.DeletesNeedConfirmation() {
+		run(false)
+		return
+	}
+	count, err := push.CountPendingDeletes(p.app)
+	if err != nil || count == 0 {
+		run(false)
+		return
+	}
+	days := int(p.app.RecycleBinRetention().Hours() / 24)
+	message := fmt.Sprintf("%d pending change(s) delete accounts in BadgerMaps.\n\nDeleted accounts are kept in the recycle bin and can be restored for %d days. Push the deletions too?", count, days)
+	p.view.ShowConfirmDialog("Push Account Deletions?", message, run)
+}
+
+// allowDeletes lets the push started by the caller send held deletions when
+// confirmed, and returns the function that withdraws the permission.
+func (p *GuiPresenter) allowDeletes(confirmed bool) func() {
+	if !confirmed {
+		return func() {}
+	}
+	p.app.State.ConfirmDeletes = true
+	return func() { p.app.State.ConfirmDeletes = false }
+}
+
+// HandleRestoreDeletedAccount re-creates a deleted account from the recycle
+// bin.
+func (p *GuiPresenter) HandleRestoreDeletedAccount(accountID int) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleRestoreDeletedAccount called for account %d", accountID))
+	go func() {
+		restored, err := push.RestoreDeletedAccount(p.app, accountID)
+		if err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+			fyne.Do(func() {
+				p.view.ShowErrorDialog(err)
+			})
+			return
+		}
 		fyne.Do(func() {
-			p.view.ShowToast("Success: All pending changes pushed.")
+			p.view.ShowToast(fmt.Sprintf("Restored account %d as account %d.", accountID, restored.AccountId.Int64))
 			p.view.RefreshPushTab()
 		})
 	}()
 }
 
+// showPushQueued reports a push deferred by the push window and returns true
+// when err is such a deferral.
+func (p *GuiPresenter) showPushQueued(err error) bool {
+	var outside *push.OutsideWindowError
+	if !errors.As(err, &outside) {
+		return false
+	}
+	fyne.Do(func() {
+		p.view.ShowToast(fmt.Sprintf("Outside push window; changes queued until %s.", outside.NextOpen.Format("Mon Jan 2 15:04")))
+	})
+	return true
+}
+
+// HandlePushQuickFilterChanged remembers the selected pending-changes quick filter.
+func (p *GuiPresenter) HandlePushQuickFilterChanged(filter string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePushQuickFilterChanged called with %s", filter))
+	if p.app.Config == nil {
+		return
+	}
+	p.app.Config.PushQuickFilter = string(push.ParseQuickFilter(filter))
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Warningf("presenter", "Could not save quick filter preference: %v", err))
+	}
+}
+
 // --- Config Handlers ---
 
 // HandleSaveConfig saves the application configuration.
 func (p *GuiPresenter) HandleSaveConfig(
-	apiKey, baseURL, dbType, dbPath, dbHost, dbPortStr, dbUser, dbPass, dbName string,
+	apiKey, baseURL, dbType, dbPath, dbHost, dbPortStr, dbUser, dbPass, dbName, dbAuth string,
 	themePreference string,
 	verbose, debug bool,
 	maxConcurrentStr string,
@@ -485,6 +888,13 @@ func (p *GuiPresenter) HandleSaveConfig(
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveConfig called"))
 	p.app.Events.Dispatch(events.Infof("presenter", "Saving configuration..."))
 
+	if dbType == "mssql" {
+		if err := database.ValidateMSSQLAuth(database.DBConfig{Type: dbType, Username: dbUser, Auth: dbAuth}); err != nil {
+			p.errorToast("Error: Invalid database authentication.", err)
+			return
+		}
+	}
+
 	// Update API config in memory
 	p.app.Config.API.APIKey = apiKey
 	p.app.Config.API.BaseURL = baseURL
@@ -513,7 +923,8 @@ func (p *GuiPresenter) HandleSaveConfig(
 
 	port, _ := strconv.Atoi(dbPortStr)
 
-	// Clear old DB config values
+	// Clear old DB config values, keeping TLS and schema settings that are only editable in the config file
+	previous := p.app.Config.DB
 	p.app.Config.DB = database.DBConfig{}
 
 	p.app.Config.DB.Type = dbType
@@ -527,36 +938,54 @@ func (p *GuiPresenter) HandleSaveConfig(
 		p.app.Config.DB.Password = dbPass
 		p.app.Config.DB.Database = dbName
 		p.app.Config.DB.SSLMode = "disable"
+		if previous.Type == dbType && previous.SSLMode != "" {
+			p.app.Config.DB.SSLMode = previous.SSLMode
+		}
+		p.app.Config.DB.SSLRootCert = previous.SSLRootCert
+		p.app.Config.DB.SSLCert = previous.SSLCert
+		p.app.Config.DB.SSLKey = previous.SSLKey
+		p.app.Config.DB.Schema = previous.Schema
 	case "mssql":
 		p.app.Config.DB.Host = dbHost
 		p.app.Config.DB.Port = port
 		p.app.Config.DB.Username = dbUser
 		p.app.Config.DB.Password = dbPass
 		p.app.Config.DB.Database = dbName
+		p.app.Config.DB.SSLRootCert = previous.SSLRootCert
+		p.app.Config.DB.SSLCert = previous.SSLCert
+		p.app.Config.DB.SSLKey = previous.SSLKey
+		p.app.Config.DB.Schema = previous.Schema
+		if dbAuth != database.MSSQLAuthSQL {
+			p.app.Config.DB.Auth = dbAuth
+		}
+		if dbAuth == database.MSSQLAuthAzureAD {
+			p.app.Config.DB.Username, p.app.Config.DB.Password = "", ""
+			p.app.Config.DB.AccessTokenCommand = previous.AccessTokenCommand
+		}
 	}
 
 	// Write the accumulated viper config to file
 	if err := p.app.SaveConfig(); err != nil {
 		p.app.Events.Dispatch(events.Errorf("presenter", "ERROR saving config file: %v", err))
-		p.view.ShowToast("Error: Failed to save configuration.")
+		p.errorToast("Error: Failed to save configuration.", err)
 		return
 	}
 
 	// Reload the application with the new config
 	if err := p.app.LoadConfig(); err != nil {
 		p.app.Events.Dispatch(events.Errorf("presenter", "ERROR reloading config: %v", err))
-		p.view.ShowToast("Error: Failed to reload new configuration.")
+		p.errorToast("Error: Failed to reload new configuration.", err)
 		return
 	}
 	if err := p.app.ReloadDB(); err != nil {
 		p.app.Events.Dispatch(events.Errorf("presenter", "ERROR reloading database: %v", err))
-		p.view.ShowToast("Error: Failed to reload database.")
+		p.errorToast("Error: Failed to reload database.", err)
 	}
 
 	p.view.ApplyThemePreference(p.app.Config.ThemePreference)
 
 	p.view.ShowToast("Success: Configuration saved successfully.")
-	p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+	p.app.Connections().Refresh()
 	p.view.RefreshAllTabs()
 }
 
@@ -567,120 +996,253 @@ func (p *GuiPresenter) HandleTestAPIConnection(apiKey, baseURL string) {
 
 	go func() {
 		// Create a temporary API client for testing
-		apiClient := api.NewAPIClient(&api.APIConfig{
-			APIKey:  apiKey,
-			BaseURL: baseURL,
-		})
-
-		if !apiClient.IsConnected() {
+		apiClient := api.NewAPIClientWithLimiter(&api.APIConfig{
+			APIKey:     apiKey,
+			BaseURL:    baseURL,
+			CACert:     p.app.Config.API.CACert,
+			ClientCert: p.app.Config.API.ClientCert,
+			ClientKey:  p.app.Config.API.ClientKey,
+			Headers:    p.app.Config.API.Headers,
+			UserAgent:  p.app.Config.API.UserAgent,
+		}, p.app.RateLimiter)
+
+		if err := apiClient.TestAPIConnection(); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "API connection failed"))
-			p.app.API.SetConnected(false)
-			p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+			if hint := utils.TLSErrorHint(err); hint != "" {
+				p.app.Events.Dispatch(events.Warningf("presenter", "%v. Hint: %s", err, hint))
+			}
+			p.app.Connections().SetAPIConnected(false)
 			return
 		}
 
 		p.app.Events.Dispatch(events.Infof("presenter", "API connection successful!"))
 		// Persist the tested values into the running app so validators see them
 		if p.app.API != nil {
-			p.app.API.APIKey = apiKey
+			p.app.API.SetAPIKey(apiKey)
 			p.app.API.BaseURL = baseURL
-			p.app.API.SetConnected(true)
 		}
 		// Also mirror into config so follow‑up steps use the same values
 		p.app.Config.API.APIKey = apiKey
 		p.app.Config.API.BaseURL = baseURL
-		p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+		p.app.Connections().SetAPIConnected(true)
 	}()
 }
 
-// HandleTestDBConnection tests the database connection.
-func (p *GuiPresenter) HandleTestDBConnection(dbType, dbPath, dbHost, dbPortStr, dbUser, dbPass, dbName string) {
+// HandleTestDBConnection tests the database connection. The fields are
+// checked first, and a SQLite file that does not exist yet is only created
+// once confirmed.
+func (p *GuiPresenter) HandleTestDBConnection(dbType, dbPath, dbHost, dbPortStr, dbUser, dbPass, dbName, dbAuth string) {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandleTestDBConnection called"))
+	port := 0
+	switch dbType {
+	case "sqlite3":
+		exists, err := database.CheckSQLitePath(dbPath)
+		if err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		if !exists {
+			message := fmt.Sprintf("%s does not exist yet. Create a new SQLite database there?", dbPath)
+			p.view.ShowConfirmDialog("Create Database?", message, func(ok bool) {
+				if ok {
+					p.testDBConnection(dbType, dbPath, dbHost, port, dbUser, dbPass, dbName, dbAuth)
+				}
+			})
+			return
+		}
+	case "postgres", "mssql":
+		if err := database.ValidateDBHost(dbType, dbHost); err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		var err error
+		if port, err = database.ParseDBPort(dbType, dbPortStr); err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+	}
+	p.testDBConnection(dbType, dbPath, dbHost, port, dbUser, dbPass, dbName, dbAuth)
+}
+
+// testDBConnection connects with the checked fields in the background. For
+// server databases the host is reached over TCP first, so an unreachable
+// server is reported quickly and apart from login failures.
+func (p *GuiPresenter) testDBConnection(dbType, dbPath, dbHost string, port int, dbUser, dbPass, dbName, dbAuth string) {
 	p.app.Events.Dispatch(events.Infof("presenter", "Testing connection for %s...", dbType))
 
 	go func() {
-		port, _ := strconv.Atoi(dbPortStr)
-
 		// Create a temporary DB object for testing
+		tlsCfg := p.app.Config.DB
 		var db database.DB
 		switch dbType {
 		case "sqlite3":
 			db = &database.SQLiteConfig{Path: dbPath}
 		case "postgres":
+			sslMode := "disable"
+			if tlsCfg.Type == dbType && tlsCfg.SSLMode != "" {
+				sslMode = tlsCfg.SSLMode
+			}
 			db = &database.PostgreSQLConfig{
-				Host: dbHost, Port: port, Username: dbUser, Password: dbPass, Database: dbName, SSLMode: "disable",
+				Host: dbHost, Port: port, Username: dbUser, Password: dbPass, Database: dbName, SSLMode: sslMode,
+				SSLRootCert: tlsCfg.SSLRootCert, SSLCert: tlsCfg.SSLCert, SSLKey: tlsCfg.SSLKey, Schema: tlsCfg.Schema,
 			}
 		case "mssql":
-			db = &database.MSSQLConfig{
-				Host: dbHost, Port: port, Username: dbUser, Password: dbPass, Database: dbName,
+			cfg := database.DBConfig{
+				Type: dbType, Host: dbHost, Port: port, Username: dbUser, Password: dbPass, Database: dbName,
+				SSLRootCert: tlsCfg.SSLRootCert, SSLCert: tlsCfg.SSLCert, SSLKey: tlsCfg.SSLKey, Schema: tlsCfg.Schema,
+			}
+			if dbAuth != database.MSSQLAuthSQL {
+				cfg.Auth = dbAuth
 			}
+			if dbAuth == database.MSSQLAuthAzureAD {
+				cfg.Username, cfg.Password = "", ""
+				cfg.AccessTokenCommand = tlsCfg.AccessTokenCommand
+			}
+			if err := database.ValidateMSSQLAuth(cfg); err != nil {
+				p.app.Events.Dispatch(events.Errorf("presenter", "Connection failed: %v", err))
+				p.app.Connections().SetDBConnected(false)
+				return
+			}
+			mssqlDB := &database.MSSQLConfig{}
+			mssqlDB.LoadConfig(&cfg)
+			db = mssqlDB
 		default:
 			p.app.Events.Dispatch(events.Errorf("presenter", "Unknown database type for testing: %s", dbType))
-			p.app.DB.SetConnected(false)
-			p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+			p.app.Connections().SetDBConnected(false)
 			return
 		}
 
+		if dbType != "sqlite3" {
+			reached, err := database.CheckDBHost(context.Background(), dbType, dbHost, port)
+			if err != nil {
+				p.app.Events.Dispatch(events.Errorf("presenter", "Connection failed: %v", err))
+				p.view.ShowToast("Error: The database server could not be reached.")
+				p.app.Connections().SetDBConnected(false)
+				return
+			}
+			p.app.Events.Dispatch(events.Debugf("presenter", "Reached %s in %s", dbHost, reached.Round(time.Millisecond)))
+		}
+
+		start := time.Now()
 		if err := db.Connect(); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "Failed to create connection: %v", err))
-			p.app.DB.SetConnected(false)
-			p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+			p.app.Connections().SetDBConnected(false)
 			return
 		}
 		defer db.Close()
 
 		if err := db.TestConnection(); err != nil {
 			p.app.Events.Dispatch(events.Errorf("presenter", "Connection failed: %v", err))
-			p.app.DB.SetConnected(false)
-			p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+			if hint := utils.TLSErrorHint(err); hint != "" {
+				p.app.Events.Dispatch(events.Warningf("presenter", "Hint: %s", hint))
+			}
+			p.app.Connections().SetDBConnected(false)
 			return
 		}
+		latency := time.Since(start).Round(time.Millisecond)
 
-		p.app.Events.Dispatch(events.Infof("presenter", "Connection successful!"))
-		p.app.DB.SetConnected(true)
-		p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+		p.app.Events.Dispatch(events.Infof("presenter", "Connection successful in %s!", latency))
+		p.view.ShowToast(fmt.Sprintf("Success: Connected to %s in %s.", dbType, latency))
+		p.app.Connections().SetDBConnected(true)
 	}()
 }
 
 // HandleSchemaEnforcement initializes or re-initializes the database schema.
+// The schema is validated again in the background first, so the choice is
+// not made on a stale result.
 func (p *GuiPresenter) HandleSchemaEnforcement() {
 	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSchemaEnforcement called"))
-	if err := p.app.DB.ValidateSchema(p.app.State); err == nil {
+	if p.app.DB == nil || !p.app.DB.IsConnected() {
+		p.view.ShowErrorDialog(fmt.Errorf("database is not connected"))
+		return
+	}
+	go func() {
+		status, _ := p.app.Connections().CheckSchema(true)
+		fyne.Do(func() {
+			p.enforceSchema(status == app.SchemaValid)
+		})
+	}()
+}
+
+// enforceSchema re-initializes a valid schema after confirmation, or
+// initializes a missing or invalid one.
+func (p *GuiPresenter) enforceSchema(valid bool) {
+	if valid {
 		// Schema exists, confirm re-initialization
 		p.view.ShowConfirmDialog("Re-initialize Schema?", "This will delete all existing data. Are you sure?", func(ok bool) {
 			if !ok {
 				return
 			}
-			p.app.Events.Dispatch(events.Warningf("presenter", "Re-initializing database schema and deleting all existing data..."))
+			p.guardProduction("re-initializing the schema", func() {
+				p.app.Events.Dispatch(events.Warningf("presenter", "Re-initializing database schema and deleting all existing data..."))
+				go func() {
+					err := p.app.DB.ResetSchema(p.app.State)
+					p.app.Connections().InvalidateSchema()
+					if err != nil {
+						p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+						p.errorToast("Error: Failed to re-initialize schema.", err)
+						return
+					}
+					p.app.Events.Dispatch(events.Infof("presenter", "Schema re-initialized successfully."))
+					p.view.ShowToast("Success: Schema re-initialized.")
+					p.view.RefreshConfigTab()
+					p.view.RefreshHomeTab()
+				}()
+			})
+		})
+	} else {
+		// Schema doesn't exist, just initialize it
+		p.guardProduction("initializing the schema", func() {
+			p.app.Events.Dispatch(events.Infof("presenter", "Initializing database schema..."))
 			go func() {
-				if err := p.app.DB.ResetSchema(p.app.State); err != nil {
+				err := p.app.DB.EnforceSchema(p.app.State)
+				p.app.Connections().InvalidateSchema()
+				if err != nil {
 					p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-					p.view.ShowToast("Error: Failed to re-initialize schema.")
+					p.errorToast("Error: Failed to initialize schema.", err)
 					return
 				}
-				p.app.Events.Dispatch(events.Infof("presenter", "Schema re-initialized successfully."))
-				p.view.ShowToast("Success: Schema re-initialized.")
+				p.app.Events.Dispatch(events.Infof("presenter", "Schema initialized successfully."))
+				p.view.ShowToast("Success: Schema initialized.")
 				p.view.RefreshConfigTab()
 				p.view.RefreshHomeTab()
 			}()
 		})
-	} else {
-		// Schema doesn't exist, just initialize it
-		p.app.Events.Dispatch(events.Infof("presenter", "Initializing database schema..."))
-		go func() {
-			if err := p.app.DB.EnforceSchema(p.app.State); err != nil {
-				p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
-				p.view.ShowToast("Error: Failed to initialize schema.")
-				return
-			}
-			p.app.Events.Dispatch(events.Infof("presenter", "Schema initialized successfully."))
-			p.view.ShowToast("Success: Schema initialized.")
-			p.view.RefreshConfigTab()
-			p.view.RefreshHomeTab()
-		}()
 	}
 }
 
+// HandleBackupDatabase writes a backup of the configured database to path.
+func (p *GuiPresenter) HandleBackupDatabase(path string) {
+	go func() {
+		if err := p.app.BackupDatabase(path, ""); err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "Backup failed: %v", err))
+			p.view.ShowToast("Error: Backup failed.")
+			return
+		}
+		p.view.ShowToast("Success: Backup saved.")
+	}()
+}
+
+// HandleRestoreDatabase confirms and then replaces the database with the backup at path.
+func (p *GuiPresenter) HandleRestoreDatabase(path string) {
+	p.view.ShowConfirmDialog("Restore Database?", fmt.Sprintf("This will replace all local data with the contents of %s. Are you sure?", filepath.Base(path)), func(ok bool) {
+		if !ok {
+			return
+		}
+		p.guardProduction("restoring a backup", func() {
+			go func() {
+				if err := p.app.RestoreDatabase(path); err != nil {
+					p.app.Events.Dispatch(events.Errorf("presenter", "Restore failed: %v", err))
+					p.view.ShowToast("Error: Restore failed.")
+					return
+				}
+				p.view.ShowToast("Success: Database restored.")
+				p.view.RefreshAllTabs()
+			}()
+		})
+	})
+}
+
 // HandleViewConfig marshals the current config to YAML and shows it in the details view.
 func (p *GuiPresenter) HandleViewConfig() {
 	configData, err := yaml.Marshal(p.app.Config)
@@ -695,6 +1257,323 @@ func (p *GuiPresenter) HandleViewConfig() {
 	p.view.ShowDetails(detailsLabel)
 }
 
+// HandleSaveDisplayTimezone persists the timezone follow-up dates and
+// appointment times are shown in. Empty means local time.
+func (p *GuiPresenter) HandleSaveDisplayTimezone(name string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveDisplayTimezone called with %q", name))
+	name = strings.TrimSpace(name)
+	if err := app.ValidateTimezone(name); err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.app.Config.DisplayTimezone = name
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save display timezone: %v", err))
+		p.view.ShowToast("Error: Failed to save display timezone.")
+		return
+	}
+	p.view.ShowToast(fmt.Sprintf("Success: Showing times in %s.", p.app.DisplayLocation()))
+}
+
+// HandleSaveAccountDisplayName persists the template accounts are named
+// with. Empty means the full name.
+func (p *GuiPresenter) HandleSaveAccountDisplayName(text string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveAccountDisplayName called with %q", text))
+	text = strings.TrimSpace(text)
+	if _, err := app.ParseDisplayNameTemplate(text); err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.app.Config.AccountDisplayName = text
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save account display name: %v", err))
+		p.view.ShowToast("Error: Failed to save account display name.")
+		return
+	}
+	p.view.ShowToast("Success: Account names updated. Open lists refresh when reloaded.")
+}
+
+// HandleSaveFieldSyncDirection sets whether an account field is pulled,
+// pushed, or synced both ways.
+func (p *GuiPresenter) HandleSaveFieldSyncDirection(column, direction string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveFieldSyncDirection called with %s=%s", column, direction))
+	if err := p.app.SetAccountFieldSyncDirection(column, direction); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save sync direction of %s: %v", column, err))
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.view.ShowToast(fmt.Sprintf("Success: %s now syncs %s.", column, strings.ToLower(app.SyncDirectionLabel(direction))))
+}
+
+// HandleSaveFieldMergeStrategy sets how pulls merge into an account field.
+func (p *GuiPresenter) HandleSaveFieldMergeStrategy(column, strategy string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveFieldMergeStrategy called with %s=%s", column, strategy))
+	if err := p.app.SetAccountFieldMergeStrategy(column, strategy); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save merge strategy of %s: %v", column, err))
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.view.ShowToast(fmt.Sprintf("Success: %s merge strategy set to %s.", column, strings.ToLower(app.MergeStrategyLabel(strategy))))
+}
+
+// HandleSaveBatchSize saves how many rows a pull writes to the database at
+// once. Running pulls keep their size; the next pull uses the new one.
+func (p *GuiPresenter) HandleSaveBatchSize(value string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveBatchSize called with %q", value))
+	size, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || size < 1 || size > app.MaxBatchSize {
+		p.view.ShowErrorDialog(fmt.Errorf("batch size must be a whole number from 1 to %d", app.MaxBatchSize))
+		return
+	}
+	p.app.Config.BatchSize = size
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save batch size: %v", err))
+		p.view.ShowToast("Error: Failed to save sync preferences.")
+		return
+	}
+	p.view.ShowToast(fmt.Sprintf("Success: Pulls will write %d rows at a time.", size))
+}
+
+// HandleSaveCSVExport saves the export options, so the next export and
+// 'db export' start from them.
+func (p *GuiPresenter) HandleSaveCSVExport(options app.CSVExportConfig) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveCSVExport called with %+v", options))
+	if err := options.Validate(); err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	if p.app.Config.CSVExport == options {
+		return
+	}
+	p.app.Config.CSVExport = options
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save CSV export options: %v", err))
+	}
+}
+
+// HandleSaveLowBandwidth turns low-bandwidth mode on or off and saves it.
+func (p *GuiPresenter) HandleSaveLowBandwidth(on bool) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveLowBandwidth called with %t", on))
+	p.app.SetLowBandwidth(on)
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save low bandwidth mode: %v", err))
+		p.view.ShowToast("Error: Failed to save sync preferences.")
+		return
+	}
+	if on {
+		p.view.ShowToast("Success: Low bandwidth mode is on; account details are fetched when opened.")
+	} else {
+		p.view.ShowToast("Success: Low bandwidth mode is off.")
+	}
+}
+
+// HandleTogglePprof starts or stops the profiling server of the Debug tab.
+func (p *GuiPresenter) HandleTogglePprof(on bool) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleTogglePprof called with %t", on))
+	if !on {
+		if err := p.app.StopPprof(); err != nil {
+			p.app.Events.Dispatch(events.Warningf("presenter", "failed to stop pprof: %v", err))
+		}
+		p.view.ShowToast("Profiling server stopped.")
+		return
+	}
+	addr, err := p.app.StartPprof()
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.app.Events.Dispatch(events.Infof("debug", "pprof listening on http://%s/debug/pprof/", addr))
+	p.view.ShowToast(fmt.Sprintf("Profiling at http://%s/debug/pprof/", addr))
+}
+
+// HandleSavePullCustomFields saves which custom account fields pulls store.
+// An empty list pulls every custom field.
+func (p *GuiPresenter) HandleSavePullCustomFields(fields []string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSavePullCustomFields called with %v", fields))
+	if err := app.ValidatePullCustomFields(fields); err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.app.Config.PullCustomFields = fields
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save pulled custom fields: %v", err))
+		p.view.ShowToast("Error: Failed to save pulled custom fields.")
+		return
+	}
+	if len(fields) == 0 {
+		p.view.ShowToast("Success: Every custom field will be pulled.")
+		return
+	}
+	p.view.ShowToast("Success: Saved pulled custom fields. They apply from the next pull.")
+}
+
+// HandleSavePushThroughput saves the workers and request rate used when
+// pushing account changes and check-ins. An empty rate means no limit
+// beyond the shared request budget.
+func (p *GuiPresenter) HandleSavePushThroughput(accountWorkers, accountRate, checkinWorkers, checkinRate string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSavePushThroughput called"))
+	accounts, err := parseEntityThroughput("account", accountWorkers, accountRate)
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	checkins, err := parseEntityThroughput("check-in", checkinWorkers, checkinRate)
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.app.SetPushThroughput("accounts", accounts)
+	p.app.SetPushThroughput("checkins", checkins)
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save push throughput: %v", err))
+		p.view.ShowToast("Error: Failed to save push throughput.")
+		return
+	}
+	p.view.ShowToast(fmt.Sprintf("Success: Pushing accounts with %d worker(s) and check-ins with %d.", accounts.Workers, checkins.Workers))
+}
+
+func parseEntityThroughput(label, workers, rate string) (app.EntityThroughput, error) {
+	var t app.EntityThroughput
+	n, err := strconv.Atoi(strings.TrimSpace(workers))
+	if err != nil || n < 1 || n > app.MaxPushWorkers {
+		return t, fmt.Errorf("%s push workers must be a whole number from 1 to %d", label, app.MaxPushWorkers)
+	}
+	t.Workers = n
+	if rate = strings.TrimSpace(rate); rate != "" {
+		rps, err := strconv.ParseFloat(rate, 64)
+		if err != nil || rps < 0 {
+			return t, fmt.Errorf("%s push rate must be a number of requests per second, or empty for no limit", label)
+		}
+		t.RequestsPerSecond = rps
+	}
+	return t, nil
+}
+
+// HandleCheckDateTimes lists stored follow-up dates and appointment times
+// with timezone problems in the details pane.
+func (p *GuiPresenter) HandleCheckDateTimes() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleCheckDateTimes called"))
+	go func() {
+		issues, err := p.app.CheckDateTimes()
+		if err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "Error checking dates and times: %v", err))
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		var report strings.Builder
+		fmt.Fprintf(&report, "Display timezone: %s\n\n", p.app.DisplayLocation())
+		if len(issues) == 0 {
+			report.WriteString("No date or time problems found.")
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(&report, "%s %d %s %q: %s\n", issue.Table, issue.ID, issue.Field, issue.Value, issue.Problem)
+		}
+		fyne.Do(func() {
+			p.view.ShowDetails(NewWrappingLabel(report.String()))
+		})
+	}()
+}
+
+// HandleShowStorageReport shows the row count and approximate size of every
+// table, with any warnings about the SQLite file nearing a size limit.
+func (p *GuiPresenter) HandleShowStorageReport() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowStorageReport called"))
+	if p.app.DB == nil || !p.app.DB.IsConnected() {
+		p.view.ShowErrorDialog(fmt.Errorf("database is not connected"))
+		return
+	}
+	go func() {
+		report, err := database.GetStorageReport(p.app.DB)
+		if err != nil {
+			p.app.Events.Dispatch(events.Errorf("presenter", "Error building storage report: %v", err))
+			fyne.Do(func() { p.view.ShowErrorDialog(err) })
+			return
+		}
+		fyne.Do(func() {
+			p.view.ShowDetails(storageReportView(report))
+		})
+	}()
+}
+
+// HandleSaveSandboxConfig persists the sandbox API settings and whether
+// pushes are sent there.
+func (p *GuiPresenter) HandleSaveSandboxConfig(sandboxURL, sandboxKey string, pushToSandbox bool) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleSaveSandboxConfig called"))
+
+	trimmedURL := strings.TrimSpace(sandboxURL)
+	if pushToSandbox && trimmedURL == "" {
+		p.view.ShowErrorDialog(app.ErrSandboxNotConfigured)
+		return
+	}
+	if trimmedURL != "" {
+		if _, err := url.ParseRequestURI(trimmedURL); err != nil {
+			p.view.ShowErrorDialog(fmt.Errorf("invalid sandbox URL: %w", err))
+			return
+		}
+	}
+
+	p.app.Config.API.SandboxURL = trimmedURL
+	p.app.Config.API.SandboxAPIKey = strings.TrimSpace(sandboxKey)
+	p.app.Config.PushToSandbox = pushToSandbox
+
+	if err := p.app.SaveConfig(); err != nil {
+		errWrapped := fmt.Errorf("failed to save sandbox configuration: %w", err)
+		p.app.Events.Dispatch(events.Errorf("presenter", errWrapped.Error()))
+		p.view.ShowToast("Error: Failed to save sandbox settings.")
+		return
+	}
+
+	if pushToSandbox {
+		p.app.Events.Dispatch(events.Warningf("presenter", "Pushes now go to the sandbox API at %s.", trimmedURL))
+		p.view.ShowToast("Success: Pushes will go to the sandbox.")
+	} else {
+		p.view.ShowToast("Success: Pushes will go to production.")
+	}
+}
+
+// HandleStageFieldEdit stages a single-field account edit made from the
+// Explorer. It returns false when the edit was rejected so the caller can
+// keep the editor open.
+func (p *GuiPresenter) HandleStageFieldEdit(accountID int, column, oldValue, newValue string) bool {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleStageFieldEdit called for account %d column %s", accountID, column))
+
+	if err := p.app.StageAccountFieldEdit(accountID, column, oldValue, newValue); err != nil {
+		if errors.Is(err, app.ErrFieldUnchanged) {
+			p.view.ShowToast("No change to stage.")
+			return true
+		}
+		p.view.ShowErrorDialog(err)
+		return false
+	}
+
+	p.app.Events.Dispatch(events.Infof("presenter", "Staged %s change for account %d", column, accountID))
+	p.view.ShowToast(fmt.Sprintf("Staged %s change for account %d.", column, accountID))
+	p.view.RefreshPushTab()
+	return true
+}
+
+// HandleEditPendingChange changes a staged value of a pending change before
+// it is pushed. It reports whether the edit was saved.
+func (p *GuiPresenter) HandleEditPendingChange(entityType string, changeID int, field, value string) bool {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleEditPendingChange called for %s change %d field %s", entityType, changeID, field))
+
+	var err error
+	if entityType == "checkins" {
+		err = p.app.EditCheckinChange(changeID, field, value)
+	} else {
+		err = p.app.EditAccountChange(changeID, field, value)
+	}
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return false
+	}
+
+	p.app.Events.Dispatch(events.Infof("presenter", "Edited %s of pending change %d", field, changeID))
+	p.view.ShowToast(fmt.Sprintf("Updated %s of change %d.", field, changeID))
+	p.view.RefreshPushTab()
+	return true
+}
+
 // --- Server Handlers ---
 
 // HandleSaveServerConfig persists server host, TLS, and logging settings.
@@ -737,6 +1616,77 @@ func (p *GuiPresenter) HandleSaveServerConfig(host, portStr string, tlsEnabled b
 	p.view.ShowToast("Success: Server settings saved.")
 }
 
+// HandleSaveTunnelConfig persists the webhook relay settings. They apply the
+// next time the server starts.
+func (p *GuiPresenter) HandleSaveTunnelConfig(enabled bool, relayURL, token string) {
+	tunnel := server.TunnelConfig{
+		Enabled: enabled,
+		URL:     strings.TrimSpace(relayURL),
+		Token:   strings.TrimSpace(token),
+	}
+	if tunnel.Enabled {
+		if err := tunnel.Validate(); err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+	}
+
+	p.app.Config.Server.Tunnel = tunnel
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save tunnel configuration: %v", err))
+		p.view.ShowToast("Error: Failed to save tunnel settings.")
+		return
+	}
+
+	if _, running := p.app.Server.GetServerStatus(); running {
+		p.view.ShowToast("Tunnel settings saved. Restart the server to apply them.")
+		return
+	}
+	p.view.ShowToast("Success: Tunnel settings saved.")
+}
+
+// HandleSaveACMEConfig persists the Let's Encrypt settings. They apply the
+// next time the server starts.
+func (p *GuiPresenter) HandleSaveACMEConfig(enabled bool, domains, email, httpPort string) {
+	acme := p.app.Config.Server.ACME
+	acme.Enabled = enabled
+	acme.Domains = nil
+	for _, domain := range strings.Split(domains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			acme.Domains = append(acme.Domains, domain)
+		}
+	}
+	acme.Email = strings.TrimSpace(email)
+	acme.HTTPPort = 0
+	if httpPort = strings.TrimSpace(httpPort); httpPort != "" {
+		port, err := strconv.Atoi(httpPort)
+		if err != nil {
+			p.view.ShowErrorDialog(fmt.Errorf("invalid challenge port: %s", httpPort))
+			return
+		}
+		acme.HTTPPort = port
+	}
+	if acme.Enabled {
+		if err := acme.Validate(); err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+	}
+
+	p.app.Config.Server.ACME = acme
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save Let's Encrypt configuration: %v", err))
+		p.view.ShowToast("Error: Failed to save Let's Encrypt settings.")
+		return
+	}
+
+	if _, running := p.app.Server.GetServerStatus(); running {
+		p.view.ShowToast("Let's Encrypt settings saved. Restart the server to apply them.")
+		return
+	}
+	p.view.ShowToast("Success: Let's Encrypt settings saved.")
+}
+
 // HandleStartServer starts the webhook server.
 func (p *GuiPresenter) HandleStartServer() {
 	if err := p.app.Server.StartServer(); err != nil {
@@ -755,6 +1705,16 @@ func (p *GuiPresenter) HandleStopServer() {
 	p.view.RefreshHomeTab()
 }
 
+// HandleReloadServerConfig asks the running server to re-read the config file.
+func (p *GuiPresenter) HandleReloadServerConfig() {
+	if err := p.app.Server.ReloadServer(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "Error reloading server config: %v", err))
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.view.ShowToast("Server is reloading its configuration.")
+}
+
 // HandleUpdateServerWebhooks persists the enabled webhook set.
 func (p *GuiPresenter) HandleUpdateServerWebhooks(accountEnabled, checkinEnabled bool) {
 	if p.app.Config.Server.Webhooks == nil {
@@ -787,8 +1747,10 @@ func (p *GuiPresenter) HandleRefreshStatus() {
 		go func() {
 			if err := p.app.API.TestAPIConnection(); err != nil {
 				p.app.Events.Dispatch(events.Errorf("presenter", "API connection test failed: %v", err))
+				p.app.Connections().SetAPIConnected(false)
+				return
 			}
-			p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+			p.app.Connections().Refresh()
 		}()
 	}
 
@@ -798,7 +1760,7 @@ func (p *GuiPresenter) HandleRefreshStatus() {
 			if err := p.app.DB.TestConnection(); err != nil {
 				p.app.Events.Dispatch(events.Errorf("presenter", "DB connection test failed: %v", err))
 			}
-			p.app.Events.Dispatch(events.Event{Type: "connection.status.changed"})
+			p.app.Connections().Refresh()
 		}()
 	}
 
@@ -0,0 +1,185 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/app/push"
+	"badgermaps/events"
+)
+
+// defaultPushBatch is the batch the push plan offers to send first.
+const defaultPushBatch = 100
+
+// HandleShowPushPlan shows, in the details pane, the API calls and time
+// pushing the pending changes takes, with a push of the first changes now.
+func (p *GuiPresenter) HandleShowPushPlan() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowPushPlan called"))
+	p.showPushPlan(defaultPushBatch)
+}
+
+func (p *GuiPresenter) showPushPlan(limit int) {
+	plans := make(map[string]*push.PushPlan, len(app.PushEntities))
+	for _, entity := range app.PushEntities {
+		plan, err := push.PlanPush(p.app, entity, push.PushSelection{Limit: limit})
+		if err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		plans[entity] = plan
+	}
+	p.view.ShowDetails(p.pushPlanView(plans, limit))
+}
+
+// HandlePushFirst pushes the first limit pending changes of entity
+// ("accounts" or "checkins") and leaves the rest queued.
+func (p *GuiPresenter) HandlePushFirst(entity string, limit int) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePushFirst called for %d %s", limit, entity))
+	if !p.schemaReady(func() { p.HandlePushFirst(entity, limit) }) {
+		return
+	}
+	sel := push.PushSelection{Limit: limit}
+	if entity == "checkins" {
+		p.pushFirst(entity, sel, false)
+		return
+	}
+	p.confirmPendingDeletes(func(confirmDeletes bool) {
+		p.pushFirst(entity, sel, confirmDeletes)
+	})
+}
+
+func (p *GuiPresenter) pushFirst(entity string, sel push.PushSelection, confirmDeletes bool) {
+	run, noun := push.RunPushAccountsSelected, "account"
+	if entity == "checkins" {
+		run, noun = push.RunPushCheckinsSelected, "check-in"
+	}
+	p.app.Events.Dispatch(events.Infof("presenter", "Starting push of the first %d %s changes...", sel.Limit, noun))
+	go func() {
+		defer p.view.HideProgressBar()
+		defer p.allowDeletes(confirmDeletes)()
+		defer p.trackProgress("", "", 0, 0)
+		p.trackProgress(entity, "Pushing", 0, 1)
+		hints, stop := p.collectPushHints()
+		defer stop()
+		if err := run(p.app, sel); err != nil {
+			if p.showPushQueued(err) {
+				return
+			}
+			p.app.Events.Dispatch(events.Errorf("presenter", "ERROR: %v", err))
+			fyne.Do(func() {
+				p.errorToast(fmt.Sprintf("Error: Failed to push %s changes.", noun), err)
+			})
+			return
+		}
+		result := pushResultToast(fmt.Sprintf("Success: Pushed the first %s changes; the rest stay queued.", noun), hints())
+		fyne.Do(func() {
+			p.view.ShowToast(result)
+			p.view.RefreshPushTab()
+			p.showPushPlan(sel.Limit)
+		})
+	}()
+}
+
+// pushPlanView shows the plan of each entity type, split at limit changes.
+func (p *GuiPresenter) pushPlanView(plans map[string]*push.PushPlan, limit int) fyne.CanvasObject {
+	bold := fyne.TextStyle{Bold: true}
+	limitEntry := widget.NewEntry()
+	limitEntry.SetText(strconv.Itoa(limit))
+	readLimit := func() (int, bool) {
+		n, err := strconv.Atoi(strings.TrimSpace(limitEntry.Text))
+		if err != nil || n < 1 {
+			p.view.ShowToast("Enter how many changes to push first, 1 or more.")
+			return 0, false
+		}
+		return n, true
+	}
+	recalculate := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if n, ok := readLimit(); ok {
+			p.showPushPlan(n)
+		}
+	})
+	limitRow := container.NewBorder(nil, nil, widget.NewLabel("Push first"), recalculate, limitEntry)
+
+	sections := container.NewVBox()
+	for _, entity := range app.PushEntities {
+		plan := plans[entity]
+		title, noun := "Account changes", "Account"
+		if entity == "checkins" {
+			title, noun = "Check-in changes", "Check-in"
+		}
+		sections.Add(widget.NewLabelWithStyle(fmt.Sprintf("%s (%d pending)", title, plan.Now.Changes+plan.Later.Changes), fyne.TextAlignLeading, bold))
+		if plan.Now.Changes == 0 {
+			sections.Add(widget.NewLabel("Nothing to push."))
+			sections.Add(widget.NewSeparator())
+			continue
+		}
+		sections.Add(NewWrappingLabel("Now: " + describePushEstimate(plan.Now)))
+		sections.Add(pushOperationsGrid(plan.Now))
+		if plan.Later.Changes > 0 {
+			later := "Later: " + describePushEstimate(plan.Later)
+			if !plan.LaterAt.IsZero() {
+				later += fmt.Sprintf(" They are pushed when the push window opens at %s.", plan.LaterAt.Format("Mon Jan 2 15:04"))
+			} else {
+				later += " They wait for the next push."
+			}
+			sections.Add(NewWrappingLabel(later))
+		}
+		pushNow := widget.NewButtonWithIcon(fmt.Sprintf("Push %d %s Change(s) Now", plan.Now.Changes, noun), theme.UploadIcon(), func() {
+			if n, ok := readLimit(); ok {
+				p.HandlePushFirst(entity, n)
+			}
+		})
+		pushNow.Importance = widget.HighImportance
+		sections.Add(pushNow)
+		sections.Add(widget.NewSeparator())
+	}
+
+	header := container.NewVBox(
+		widget.NewLabelWithStyle("Push Plan", fyne.TextAlignLeading, bold),
+		NewWrappingLabel(fmt.Sprintf("Estimates assume %s per request, the configured push workers, and the request rate limits.", push.EstimatedRequestTime)),
+		limitRow,
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(sections))
+}
+
+// describePushEstimate says how many changes and calls an estimate covers
+// and how long they take.
+func describePushEstimate(estimate push.PushEstimate) string {
+	text := fmt.Sprintf("%d change(s), %d API call(s), about %s", estimate.Changes, estimate.Calls, roughPushDuration(estimate.Duration))
+	if estimate.RequestsPerSecond > 0 {
+		text += fmt.Sprintf(" at %g request(s)/s", estimate.RequestsPerSecond)
+	}
+	text += fmt.Sprintf(" with %d worker(s).", estimate.Workers)
+	if estimate.Held > 0 {
+		text += fmt.Sprintf(" %d delete(s) wait for confirmation.", estimate.Held)
+	}
+	return text
+}
+
+func pushOperationsGrid(estimate push.PushEstimate) fyne.CanvasObject {
+	grid := container.NewGridWithColumns(3)
+	for _, op := range estimate.Operations {
+		grid.Add(widget.NewLabel(op.Operation))
+		grid.Add(widget.NewLabelWithStyle(fmt.Sprintf("%d change(s)", op.Changes), fyne.TextAlignTrailing, fyne.TextStyle{}))
+		grid.Add(widget.NewLabelWithStyle(fmt.Sprintf("%d call(s)", op.Calls), fyne.TextAlignTrailing, fyne.TextStyle{}))
+	}
+	return grid
+}
+
+func roughPushDuration(d time.Duration) string {
+	if d > 0 && d < time.Second {
+		return "a second"
+	}
+	return d.Round(time.Second).String()
+}
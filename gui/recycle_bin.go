@@ -0,0 +1,48 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createRecycleBinView lists deleted accounts that can still be restored,
+// each with a Restore button.
+func (ui *Gui) createRecycleBinView() fyne.CanvasObject {
+	if ui.app.DB == nil || !ui.app.DB.IsConnected() {
+		return widget.NewLabel("Connect a database to see deleted accounts.")
+	}
+	entries, err := ui.app.DeletedAccounts()
+	if err != nil {
+		return widget.NewLabel(fmt.Sprintf("Error reading the recycle bin: %v", err))
+	}
+	if len(entries) == 0 {
+		return widget.NewLabel("No deleted accounts.")
+	}
+	retention := ui.app.RecycleBinRetention()
+	rows := container.NewVBox()
+	for _, entry := range entries {
+		e := entry
+		name := e.FullName
+		if name == "" {
+			name = "(no name)"
+		}
+		label := widget.NewLabel(fmt.Sprintf("%s (account %d), deleted %s, restorable until %s",
+			name, e.AccountId, e.DeletedAt.Local().Format("2006-01-02 15:04"), e.DeletedAt.Add(retention).Local().Format("2006-01-02")))
+		label.Wrapping = fyne.TextWrapWord
+		restoreBtn := widget.NewButtonWithIcon("Restore", theme.ContentUndoIcon(), func() {
+			ui.ShowConfirmDialog("Restore Account?", fmt.Sprintf("Re-create %s in BadgerMaps? The restored account gets a new account ID.", name), func(ok bool) {
+				if ok {
+					ui.presenter.HandleRestoreDeletedAccount(e.AccountId)
+				}
+			})
+		})
+		rows.Add(container.NewBorder(nil, nil, nil, restoreBtn, label))
+	}
+	return rows
+}
@@ -0,0 +1,171 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"badgermaps/events"
+)
+
+// uiRefreshDelay is how long refresh requests are collected before they
+// run, so a burst of events rebuilds each view once.
+const uiRefreshDelay = 150 * time.Millisecond
+
+// refreshJob is a pending refresh of a view. A job with a tab only runs
+// while that tab is shown.
+type refreshJob struct {
+	tab string
+	fn  func()
+}
+
+// refreshBatcher collects UI refreshes requested by event listeners and
+// runs them together on the UI goroutine. Requests with the same key
+// within uiRefreshDelay run once, with the last function given. Refreshes
+// of a tab that is not shown wait until it is selected, so views nobody
+// is looking at are not rebuilt for every event.
+type refreshBatcher struct {
+	delay   time.Duration
+	do      func(func())      // runs a function on the UI goroutine
+	visible func(string) bool // reports whether a tab is shown
+
+	mu      sync.Mutex
+	pending map[string]refreshJob
+	order   []string
+	timer   *time.Timer
+
+	// stale holds refreshes of hidden tabs by tab and key. It is only
+	// used on the UI goroutine.
+	stale map[string]map[string]func()
+}
+
+func newRefreshBatcher(delay time.Duration, do func(func()), visible func(string) bool) *refreshBatcher {
+	return &refreshBatcher{
+		delay:   delay,
+		do:      do,
+		visible: visible,
+		pending: make(map[string]refreshJob),
+		stale:   make(map[string]map[string]func()),
+	}
+}
+
+// Request schedules fn under key. It is safe to call from any goroutine.
+// An empty tab runs fn whichever tab is shown.
+func (b *refreshBatcher) Request(tab, key string, fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.pending[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.pending[key] = refreshJob{tab: tab, fn: fn}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.delay, b.flush)
+	}
+}
+
+// flush runs the pending refreshes in the order they were first requested.
+func (b *refreshBatcher) flush() {
+	b.mu.Lock()
+	jobs := make([]refreshJob, 0, len(b.order))
+	keys := b.order
+	for _, key := range keys {
+		jobs = append(jobs, b.pending[key])
+	}
+	b.pending = make(map[string]refreshJob)
+	b.order = nil
+	b.timer = nil
+	b.mu.Unlock()
+
+	b.do(func() {
+		for i, job := range jobs {
+			if job.tab != "" && !b.visible(job.tab) {
+				if b.stale[job.tab] == nil {
+					b.stale[job.tab] = make(map[string]func())
+				}
+				b.stale[job.tab][keys[i]] = job.fn
+				continue
+			}
+			job.fn()
+		}
+	})
+}
+
+// TabShown runs the refreshes held back while tab was hidden. It must be
+// called on the UI goroutine.
+func (b *refreshBatcher) TabShown(tab string) {
+	if b == nil {
+		return
+	}
+	held := b.stale[tab]
+	delete(b.stale, tab)
+	for _, fn := range held {
+		fn()
+	}
+}
+
+// isTabSelected reports whether the tab titled text is the one shown.
+func (ui *Gui) isTabSelected(text string) bool {
+	if ui.tabs == nil {
+		return false
+	}
+	selected := ui.tabs.Selected()
+	return selected != nil && selected.Text == text
+}
+
+// flushLog appends the log lines collected since the last flush and
+// scrolls to the newest once.
+func (ui *Gui) flushLog() {
+	ui.logMutex.Lock()
+	defer ui.logMutex.Unlock()
+	for _, line := range ui.logPending {
+		ui.logBinding.Append(line)
+	}
+	ui.logPending = nil
+	// Avoid early crash if list is not yet fully initialised
+	if ui.logView != nil {
+		defer func() { _ = recover() }()
+		ui.logView.ScrollToBottom()
+	}
+}
+
+// actionConfigName returns the event action an action.config event is
+// about, or "" when it does not say.
+func actionConfigName(e events.Event) string {
+	switch payload := e.Payload.(type) {
+	case events.ActionConfigCreatedPayload:
+		return payload.Name
+	case events.ActionConfigUpdatedPayload:
+		return payload.Name
+	case events.ActionConfigDeletedPayload:
+		return payload.Name
+	}
+	return ""
+}
+
+// refreshActionCard rebuilds the card of the event action name. An action
+// without a card, or one that no longer exists, rebuilds the whole tab so
+// cards are added, removed, and kept in order.
+func (ui *Gui) refreshActionCard(name string) {
+	if ui.tabs == nil {
+		return
+	}
+	if card, ok := ui.actionCards[name]; ok {
+		for _, ea := range ui.app.Config.EventActions {
+			if ea.Name == name {
+				card.Objects = []fyne.CanvasObject{ui.createActionCard(ea)}
+				card.Refresh()
+				return
+			}
+		}
+	}
+	for _, tab := range ui.tabs.Items {
+		if tab.Text == "Actions" {
+			tab.Content = ui.createActionsTab()
+			ui.tabs.Refresh()
+			break
+		}
+	}
+}
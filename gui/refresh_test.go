@@ -0,0 +1,55 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefreshBatcherCoalescesAndHoldsHiddenTabs(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		ran   []string
+		shown = "Home"
+		done  = make(chan struct{}, 1)
+	)
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+		}
+	}
+	b := newRefreshBatcher(10*time.Millisecond, func(fn func()) {
+		fn()
+		done <- struct{}{}
+	}, func(tab string) bool { return tab == shown })
+
+	for i := 0; i < 5; i++ {
+		b.Request("", "log", record("log"))
+	}
+	b.Request("Home", "home", record("home"))
+	b.Request("Actions", "actions", record("stale actions"))
+	b.Request("Actions", "actions", record("actions"))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refreshes did not run")
+	}
+	mu.Lock()
+	if len(ran) != 2 || ran[0] != "log" || ran[1] != "home" {
+		t.Fatalf("ran %v, want one log and one home refresh", ran)
+	}
+	mu.Unlock()
+
+	b.TabShown("Actions")
+	b.TabShown("Actions")
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ran) != 3 || ran[2] != "actions" {
+		t.Fatalf("ran %v, want the latest Actions refresh once the tab is shown", ran)
+	}
+}
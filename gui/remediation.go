@@ -0,0 +1,63 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"badgermaps/errs"
+	"badgermaps/events"
+)
+
+// withHint appends what the user can do about err to message.
+func withHint(message string, err error) string {
+	if hint := errs.Hint(err); hint != "" {
+		return message + " " + hint
+	}
+	return message
+}
+
+// errorToast shows message with the remediation hint of err, if any.
+func (p *GuiPresenter) errorToast(message string, err error) {
+	p.view.ShowToast(withHint(message, err))
+}
+
+// collectPushHints gathers the distinct hints of changes that fail to push.
+// hints waits for queued events and returns them joined, or "" when no
+// failure had one; stop ends the collection.
+func (p *GuiPresenter) collectPushHints() (hints func() string, stop func()) {
+	var (
+		mu    sync.Mutex
+		found []string
+	)
+	stop = p.app.Events.SubscribeCancelable("push.item.error", func(e events.Event) {
+		payload, ok := e.Payload.(events.PushItemErrorPayload)
+		if !ok {
+			return
+		}
+		hint := errs.Hint(payload.Error)
+		mu.Lock()
+		defer mu.Unlock()
+		if hint != "" && !slices.Contains(found, hint) {
+			found = append(found, hint)
+		}
+	})
+	hints = func() string {
+		p.app.Events.WaitForDrain(time.Second)
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Join(found, " ")
+	}
+	return hints, stop
+}
+
+// pushResultToast is success, or the hints of the changes that failed.
+func pushResultToast(success, hints string) string {
+	if hints == "" {
+		return success
+	}
+	return "Error: Some changes failed to push. " + hints
+}
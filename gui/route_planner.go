@@ -0,0 +1,335 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/app/pull"
+	"badgermaps/app/push"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+// HandleShowRoutePlanner shows the routes and plans of the week containing
+// week, a date as YYYY-MM-DD, or of this week when week is empty.
+func (p *GuiPresenter) HandleShowRoutePlanner(week string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowRoutePlanner called for %q", week))
+	if p.app.DB == nil || !p.app.DB.IsConnected() {
+		p.view.ShowErrorDialog(fmt.Errorf("database is not connected"))
+		return
+	}
+	start, err := p.app.ParseWeek(week)
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.view.ShowDetails(p.routeWeekView(start))
+}
+
+// HandlePushRoutePlan asks for confirmation, creates the route of a draft
+// plan in BadgerMaps, and shows the plan's week again.
+func (p *GuiPresenter) HandlePushRoutePlan(planID int) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandlePushRoutePlan called for plan %d", planID))
+	plan, err := p.app.RoutePlan(planID)
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	message := fmt.Sprintf("Create route %q for %s with %d stops in BadgerMaps?", plan.Name, plan.RouteDate, len(plan.Waypoints))
+	p.view.ShowConfirmDialog("Push Route?", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		p.view.ShowProgressBar("Pushing route...")
+		go func() {
+			route, err := push.PushRoutePlan(p.app, planID)
+			fyne.Do(func() {
+				p.view.HideProgressBar()
+				if err != nil {
+					p.view.ShowErrorDialog(err)
+					return
+				}
+				p.view.ShowToast(fmt.Sprintf("Created route %d.", route.RouteId.Int64))
+				p.HandleShowRoutePlanner(plan.RouteDate)
+			})
+		}()
+	})
+}
+
+// routeWeekView lists a week's routes and plans by weekday, with buttons
+// to copy routes into plans and to edit and push plans.
+func (p *GuiPresenter) routeWeekView(start time.Time) fyne.CanvasObject {
+	showWeek := func(t time.Time) {
+		p.HandleShowRoutePlanner(t.Format("2006-01-02"))
+	}
+	days := container.NewVBox()
+	week, err := p.app.RouteWeek(start)
+	if err != nil {
+		days.Add(NewWrappingLabel(fmt.Sprintf("Error reading the routes: %v", err)))
+	} else {
+		for _, day := range week.Days {
+			days.Add(p.routeDayCard(day))
+		}
+	}
+
+	prev := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() { showWeek(start.AddDate(0, 0, -7)) })
+	next := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() { showWeek(start.AddDate(0, 0, 7)) })
+	today := widget.NewButton("This Week", func() { p.HandleShowRoutePlanner("") })
+	pullRoutes := widget.NewButtonWithIcon("Pull Routes", theme.DownloadIcon(), func() {
+		p.view.ShowProgressBar("Pulling Routes...")
+		go func() {
+			err := pull.PullGroupRoutes(p.app)
+			fyne.Do(func() {
+				p.view.HideProgressBar()
+				if err != nil {
+					p.view.ShowErrorDialog(err)
+					return
+				}
+				showWeek(start)
+			})
+		}()
+	})
+	copyWeek := widget.NewButtonWithIcon("Copy Last Week", theme.ContentCopyIcon(), func() {
+		from := start.AddDate(0, 0, -7)
+		message := fmt.Sprintf("Copy every route of the week of %s into plans for this week?", from.Format("Jan 2"))
+		p.view.ShowConfirmDialog("Copy Last Week?", message, func(ok bool) {
+			if !ok {
+				return
+			}
+			plans, err := p.app.DuplicateWeek(from, start)
+			if err != nil {
+				p.view.ShowErrorDialog(err)
+			} else if len(plans) == 0 {
+				p.view.ShowToast("No routes were pulled for last week.")
+			} else {
+				p.view.ShowToast(fmt.Sprintf("Copied %d routes.", len(plans)))
+			}
+			showWeek(start)
+		})
+	})
+
+	header := container.NewVBox(
+		container.NewBorder(nil, nil,
+			widget.NewLabelWithStyle("Week of "+start.Format("Jan 2, 2006"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.NewHBox(prev, today, next),
+		),
+		container.NewHBox(pullRoutes, copyWeek),
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(days))
+}
+
+func (p *GuiPresenter) routeDayCard(day app.RouteDay) fyne.CanvasObject {
+	rows := container.NewVBox()
+	if len(day.Routes) == 0 && len(day.Plans) == 0 {
+		rows.Add(widget.NewLabel("No routes"))
+	}
+	for _, route := range day.Routes {
+		routeID := int(route.RouteId.Int64)
+		summary := fmt.Sprintf("Route %d: %s", routeID, route.Name.String)
+		if route.StartTime.String != "" {
+			summary += ", starts " + route.StartTime.String
+		}
+		copyNext := widget.NewButtonWithIcon("Copy to Next Week", theme.ContentCopyIcon(), func() {
+			plan, err := p.app.DuplicateRoute(routeID, day.Date.AddDate(0, 0, 7))
+			if err != nil {
+				p.view.ShowErrorDialog(err)
+				return
+			}
+			p.view.ShowToast(fmt.Sprintf("Copied to plan %d for %s.", plan.PlanId, plan.RouteDate))
+		})
+		rows.Add(container.NewBorder(nil, nil, nil, copyNext, widget.NewLabel(summary)))
+	}
+	for _, plan := range day.Plans {
+		planID := plan.PlanId
+		label := widget.NewLabel(fmt.Sprintf("Plan %d: %s, %d stops", plan.PlanId, plan.Name, len(plan.Waypoints)))
+		if plan.Status == database.RoutePlanPushed {
+			label.SetText(label.Text + fmt.Sprintf(", pushed as route %d", plan.RouteId))
+			rows.Add(label)
+			continue
+		}
+		label.Importance = widget.WarningImportance
+		edit := widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), func() {
+			p.showRoutePlanEditor(planID)
+		})
+		pushPlan := widget.NewButtonWithIcon("Push", theme.UploadIcon(), func() {
+			p.HandlePushRoutePlan(planID)
+		})
+		rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(edit, pushPlan), label))
+	}
+	return widget.NewCard(day.Date.Format("Monday, Jan 2"), "", rows)
+}
+
+// showRoutePlanEditor shows a draft plan with its stops, which can be
+// reordered, removed, timed, and added to from stored accounts.
+func (p *GuiPresenter) showRoutePlanEditor(planID int) {
+	plan, err := p.app.RoutePlan(planID)
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	name := widget.NewEntry()
+	name.SetText(plan.Name)
+	date := widget.NewEntry()
+	date.SetText(plan.RouteDate)
+	date.SetPlaceHolder("YYYY-MM-DD")
+	startTime := widget.NewEntry()
+	startTime.SetText(plan.StartTime)
+	startTime.SetPlaceHolder("HH:MM")
+	startAddress := widget.NewEntry()
+	startAddress.SetText(plan.StartAddress)
+	destination := widget.NewEntry()
+	destination.SetText(plan.DestinationAddress)
+
+	stops := plan.Waypoints
+	times := make([]*widget.Entry, 0, len(stops))
+	list := container.NewVBox()
+	var render func()
+	// collect copies the edited fields into the plan.
+	collect := func() database.RoutePlan {
+		edited := *plan
+		edited.Name = name.Text
+		edited.RouteDate = strings.TrimSpace(date.Text)
+		edited.StartTime = strings.TrimSpace(startTime.Text)
+		edited.StartAddress = strings.TrimSpace(startAddress.Text)
+		edited.DestinationAddress = strings.TrimSpace(destination.Text)
+		for i, entry := range times {
+			stops[i].ApptTime = strings.TrimSpace(entry.Text)
+		}
+		edited.Waypoints = stops
+		return edited
+	}
+	reorder := func(edit func() ([]database.PlanWaypoint, error)) {
+		collect()
+		next, err := edit()
+		if err != nil {
+			p.view.ShowErrorDialog(err)
+			return
+		}
+		stops = next
+		render()
+	}
+	render = func() {
+		list.Objects = nil
+		times = times[:0]
+		if len(stops) == 0 {
+			list.Add(widget.NewLabel("No stops yet."))
+		}
+		for i, stop := range stops {
+			position := i + 1
+			apptTime := widget.NewEntry()
+			apptTime.SetText(stop.ApptTime)
+			apptTime.SetPlaceHolder("HH:MM")
+			times = append(times, apptTime)
+			text := fmt.Sprintf("%d. %s", position, stop.Name)
+			if address := strings.TrimSpace(stop.Address + " " + stop.City); address != "" {
+				text += " (" + address + ")"
+			}
+			up := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+				reorder(func() ([]database.PlanWaypoint, error) { return app.MoveStop(stops, position, position-1) })
+			})
+			down := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+				reorder(func() ([]database.PlanWaypoint, error) { return app.MoveStop(stops, position, position+1) })
+			})
+			if position == 1 {
+				up.Disable()
+			}
+			if position == len(stops) {
+				down.Disable()
+			}
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				reorder(func() ([]database.PlanWaypoint, error) { return app.RemoveStop(stops, position) })
+			})
+			timeBox := container.NewGridWrap(fyne.NewSize(80, apptTime.MinSize().Height), apptTime)
+			list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(timeBox, up, down, remove), widget.NewLabel(text)))
+		}
+		list.Refresh()
+	}
+	render()
+
+	accountEntry := widget.NewEntry()
+	accountEntry.SetPlaceHolder("Account ID")
+	addStop := widget.NewButtonWithIcon("Add Stop", theme.ContentAddIcon(), func() {
+		accountID, err := strconv.Atoi(strings.TrimSpace(accountEntry.Text))
+		if err != nil {
+			p.view.ShowErrorDialog(fmt.Errorf("enter the ID of a stored account"))
+			return
+		}
+		reorder(func() ([]database.PlanWaypoint, error) {
+			stop, err := p.app.AccountStop(accountID)
+			if err != nil {
+				return nil, err
+			}
+			return app.InsertStop(stops, stop, 0), nil
+		})
+		accountEntry.SetText("")
+	})
+
+	save := func() bool {
+		edited := collect()
+		if err := p.app.SaveRoutePlan(edited); err != nil {
+			p.view.ShowErrorDialog(err)
+			return false
+		}
+		*plan = edited
+		return true
+	}
+	saveButton := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		if save() {
+			p.view.ShowToast("Plan saved.")
+		}
+	})
+	saveButton.Importance = widget.HighImportance
+	pushButton := widget.NewButtonWithIcon("Save and Push", theme.UploadIcon(), func() {
+		if save() {
+			p.HandlePushRoutePlan(planID)
+		}
+	})
+	deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {
+		p.view.ShowConfirmDialog("Delete Plan?", fmt.Sprintf("Delete plan %d, %q?", planID, plan.Name), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := p.app.DeleteRoutePlan(planID); err != nil {
+				p.view.ShowErrorDialog(err)
+				return
+			}
+			p.HandleShowRoutePlanner(plan.RouteDate)
+		})
+	})
+	back := widget.NewButtonWithIcon("Week", theme.NavigateBackIcon(), func() {
+		p.HandleShowRoutePlanner(plan.RouteDate)
+	})
+
+	title := fmt.Sprintf("Plan %d", planID)
+	if plan.TemplateRouteId > 0 {
+		title += fmt.Sprintf(", copied from route %d", plan.TemplateRouteId)
+	}
+	header := container.NewVBox(
+		container.NewBorder(nil, nil, back, nil, widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})),
+		widget.NewForm(
+			widget.NewFormItem("Name", name),
+			widget.NewFormItem("Date", date),
+			widget.NewFormItem("Start Time", startTime),
+			widget.NewFormItem("Start", startAddress),
+			widget.NewFormItem("Destination", destination),
+		),
+		widget.NewLabelWithStyle("Stops", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	footer := container.NewVBox(
+		container.NewBorder(nil, nil, nil, addStop, accountEntry),
+		widget.NewSeparator(),
+		container.NewHBox(saveButton, pushButton, deleteButton),
+	)
+	p.view.ShowDetails(container.NewBorder(header, footer, nil, nil, container.NewVScroll(list)))
+}
@@ -1,15 +1,19 @@
 //go:build nogui
 
+// Package gui is compiled out of builds tagged nogui, which keeps Fyne and
+// its OpenGL dependencies out of slim CLI and server binaries.
 package gui
 
-import (
-	"badgermaps/app"
-	"fyne.io/fyne/v2"
-)
+import "badgermaps/app"
 
 const Enabled = false
 
 // Run is a stub function for when the GUI is disabled.
-func Run(a *app.App, icon fyne.Resource) {
+func Run(a *app.App, icon []byte) {
+	// No GUI support
+}
+
+// RunLink is a stub function for when the GUI is disabled.
+func RunLink(a *app.App, icon []byte, link app.DeepLink) {
 	// No GUI support
 }
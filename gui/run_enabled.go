@@ -9,9 +9,19 @@ import (
 
 const Enabled = true
 
-// Run launches the Fyne GUI.
+// Run launches the Fyne GUI with icon (PNG bytes) as the window icon.
 // It ensures the application configuration is loaded before starting the UI.
-func Run(a *app.App, icon fyne.Resource) {
+func Run(a *app.App, icon []byte) {
 	a.EnsureConfig(true)
-	Launch(a, icon)
+	Launch(a, appIcon(icon))
+}
+
+// RunLink launches the GUI like Run and opens link in it.
+func RunLink(a *app.App, icon []byte, link app.DeepLink) {
+	a.EnsureConfig(true)
+	LaunchLink(a, appIcon(icon), link)
+}
+
+func appIcon(icon []byte) fyne.Resource {
+	return &fyne.StaticResource{StaticName: "icon.png", StaticContent: icon}
 }
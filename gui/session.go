@@ -0,0 +1,84 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/app"
+	"fyne.io/fyne/v2"
+)
+
+// currentSession captures the window size, selected tab, Explorer table, and
+// details pane for the next start. Parts that were never built this run,
+// such as Explorer without a connection, keep their saved value.
+func (ui *Gui) currentSession() app.GuiSession {
+	session := ui.app.Config.GuiSession
+	if ui.window != nil {
+		size := ui.window.Canvas().Size()
+		session.Width, session.Height = size.Width, size.Height
+	}
+	if ui.tabs == nil {
+		return session
+	}
+	if selected := ui.tabs.Selected(); selected != nil {
+		session.Tab = selected.Text
+	}
+	if ui.explorerTableSelect != nil && ui.explorerTableSelect.Selected != "" {
+		session.ExplorerTable = ui.explorerTableSelect.Selected
+	}
+	session.RightPaneVisible = ui.rightPaneVisible
+	return session
+}
+
+// saveSession writes the current session to the config file.
+func (ui *Gui) saveSession() {
+	if ui.app == nil || ui.app.Config == nil || ui.showWelcome {
+		return
+	}
+	ui.app.SaveGuiSession(ui.currentSession())
+}
+
+// restoreSession selects the saved tab, or the gui_tabs start tab when one
+// is configured, and reopens the details pane. It runs once, for the first
+// main content built after launch.
+func (ui *Gui) restoreSession() {
+	if !ui.restoringSession || ui.tabs == nil {
+		return
+	}
+	ui.restoringSession = false
+	session := ui.app.Config.GuiSession
+	selected := session.Tab
+	if start := ui.app.GuiStartTab(); start != "" {
+		selected = start
+	}
+	for idx, tab := range ui.tabs.Items {
+		if tab.Text == selected {
+			ui.tabs.SelectIndex(idx)
+			break
+		}
+	}
+	if session.RightPaneVisible {
+		ui.toggleRightPane()
+	}
+	ui.restoreExplorerTable = session.ExplorerTable
+}
+
+// takeRestoredExplorerTable returns the saved Explorer table if it is one of
+// tables, once; later table list loads get "".
+func (ui *Gui) takeRestoredExplorerTable(tables []string) string {
+	name := ui.restoreExplorerTable
+	ui.restoreExplorerTable = ""
+	if name == "" || !containsString(tables, name) {
+		return ""
+	}
+	return name
+}
+
+// restoredWindowSize is the saved window size, or fallback when none is
+// saved or a launch scale is set.
+func (ui *Gui) restoredWindowSize(fallback fyne.Size, scaled bool) fyne.Size {
+	session := ui.app.Config.GuiSession
+	if scaled || !session.HasWindowSize() {
+		return fallback
+	}
+	return fyne.NewSize(session.Width, session.Height)
+}
@@ -0,0 +1,100 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"badgermaps/api"
+	"badgermaps/app"
+	"badgermaps/database"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newSessionTestApp returns an App with the API client and database the
+// tabs read their settings from, neither of them connected.
+func newSessionTestApp(t *testing.T) *app.App {
+	t.Helper()
+	a := app.NewApp()
+	a.API = api.NewAPIClient(&a.Config.API)
+	db, err := database.NewDB(&database.DBConfig{Type: "sqlite3", Path: filepath.Join(t.TempDir(), "session.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.DB = db
+	return a
+}
+
+func TestRestoreSession(t *testing.T) {
+	a := newSessionTestApp(t)
+	a.Config.GuiSession = app.GuiSession{Width: 1280, Height: 800, Tab: "Server", ExplorerTable: "Routes", RightPaneVisible: true}
+	ui := &Gui{
+		app:              a,
+		fyneApp:          test.NewApp(),
+		logBinding:       binding.NewStringList(),
+		restoringSession: true,
+	}
+	ui.presenter = NewGuiPresenter(a, ui)
+	ui.syncCenter = NewSyncCenter(ui, ui.presenter)
+	ui.window = test.NewWindow(ui.createContent())
+	defer ui.window.Close()
+
+	if got := ui.tabs.Selected().Text; got != "Server" {
+		t.Errorf("selected tab = %q, want Server", got)
+	}
+	if !ui.rightPaneVisible {
+		t.Error("details pane was not reopened")
+	}
+	if got := ui.restoredWindowSize(fyne.NewSize(1000, 600), false); got != fyne.NewSize(1280, 800) {
+		t.Errorf("window size = %v, want the saved size", got)
+	}
+	if got := ui.restoredWindowSize(fyne.NewSize(2000, 1200), true); got != fyne.NewSize(2000, 1200) {
+		t.Errorf("scaled window size = %v, want the scaled default", got)
+	}
+	if got := ui.takeRestoredExplorerTable([]string{"Accounts", "Routes"}); got != "Routes" {
+		t.Errorf("restored table = %q, want Routes", got)
+	}
+	if got := ui.takeRestoredExplorerTable([]string{"Routes"}); got != "" {
+		t.Errorf("table restored twice: %q", got)
+	}
+
+	ui.tabs.SelectIndex(0)
+	ui.hideRightPane()
+	session := ui.currentSession()
+	if session.Tab != "Home" || session.RightPaneVisible || session.ExplorerTable != "Routes" {
+		t.Errorf("current session = %+v", session)
+	}
+}
+
+func TestGuiTabsConfig(t *testing.T) {
+	a := newSessionTestApp(t)
+	a.Config.GuiSession = app.GuiSession{Tab: "Home"}
+	a.Config.GuiTabs = app.GuiTabsConfig{StartTab: "explorer", Hidden: []string{"server", "Actions"}}
+	ui := &Gui{
+		app:              a,
+		fyneApp:          test.NewApp(),
+		logBinding:       binding.NewStringList(),
+		restoringSession: true,
+	}
+	ui.presenter = NewGuiPresenter(a, ui)
+	ui.syncCenter = NewSyncCenter(ui, ui.presenter)
+	ui.window = test.NewWindow(ui.createContent())
+	defer ui.window.Close()
+
+	var titles []string
+	for _, tab := range ui.tabs.Items {
+		titles = append(titles, tab.Text)
+	}
+	if want := []string{"Home", "Sync Center", "Explorer", "Configuration"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("tabs = %v, want %v", titles, want)
+	}
+	if got := ui.tabs.Selected().Text; got != "Explorer" {
+		t.Errorf("selected tab = %q, want the start tab Explorer", got)
+	}
+	if ui.OpenServerTab() {
+		t.Error("OpenServerTab selected a hidden tab")
+	}
+}
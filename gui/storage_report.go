@@ -0,0 +1,48 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/database"
+)
+
+// storageReportView lists the tables of a storage report, largest first,
+// below any warnings.
+func storageReportView(report database.StorageReport) fyne.CanvasObject {
+	summary := fmt.Sprintf("%s database, %s in %d rows. Sizes are approximate.",
+		report.Type, database.FormatBytes(report.TotalBytes), report.TotalRows)
+	if report.Path != "" {
+		summary += "\n" + report.Path
+	}
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Storage Report", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		NewWrappingLabel(summary),
+	)
+	for _, warning := range report.Warnings {
+		label := widget.NewLabel(warning)
+		label.Wrapping = fyne.TextWrapWord
+		label.Importance = widget.DangerImportance
+		content.Add(label)
+	}
+	content.Add(widget.NewSeparator())
+
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle("Table", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Rows", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Size", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}),
+	)
+	for _, table := range report.Tables {
+		grid.Add(widget.NewLabel(table.Name))
+		grid.Add(widget.NewLabelWithStyle(strconv.FormatInt(table.Rows, 10), fyne.TextAlignTrailing, fyne.TextStyle{}))
+		grid.Add(widget.NewLabelWithStyle(database.FormatBytes(table.Bytes), fyne.TextAlignTrailing, fyne.TextStyle{}))
+	}
+	content.Add(grid)
+	return content
+}
@@ -1,7 +1,11 @@
+//go:build !nogui
+
 package gui
 
 import (
+	"badgermaps/api/models"
 	"badgermaps/database"
+	"badgermaps/database/repository"
 	"badgermaps/events"
 	"fmt"
 	"fyne.io/fyne/v2"
@@ -11,7 +15,6 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	xwidget "fyne.io/x/fyne/widget"
-	"github.com/guregu/null/v6"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -19,11 +22,13 @@ import (
 )
 
 const (
-	syncKindAll      = "All"
-	syncKindAccounts = "Accounts"
-	syncKindCheckins = "Checkins"
-	syncKindRoutes   = "Routes"
-	syncKindUser     = "User"
+	syncKindAll       = "All"
+	syncKindAccounts  = "Accounts"
+	syncKindCheckins  = "Checkins"
+	syncKindRoutes    = "Routes"
+	syncKindUser      = "User"
+	syncKindLocations = "Locations"
+	syncKindDatasets  = "Datasets"
 
 	scopeAll    = "All"
 	scopeSingle = "Single"
@@ -86,6 +91,8 @@ func NewSyncCenter(ui *Gui, presenter *GuiPresenter) *SyncCenter {
 		syncKindCheckins,
 		syncKindRoutes,
 		syncKindUser,
+		syncKindLocations,
+		syncKindDatasets,
 	}, sc.onSyncTypeChanged)
 
 	sc.scopeSelect = widget.NewSelect([]string{scopeAll, scopeSingle}, sc.onScopeChanged)
@@ -175,6 +182,7 @@ func (sc *SyncCenter) CreateContent() fyne.CanvasObject {
 		container.NewVBox(
 			sc.pushBody,
 			widget.NewSeparator(),
+			sc.ui.createPendingChangesView(),
 			pendingChangesRow,
 		),
 	)
@@ -188,11 +196,29 @@ func (sc *SyncCenter) CreateContent() fyne.CanvasObject {
 		}
 	})
 
+	syncRunsButton := widget.NewButtonWithIcon("Runs & Notes", theme.HistoryIcon(), sc.ui.presenter.HandleShowSyncRuns)
+	syncRunsButton.Importance = widget.LowImportance
+
+	apiConsoleButton := widget.NewButtonWithIcon("API Console", theme.ComputerIcon(), sc.ui.presenter.HandleShowAPIConsole)
+	apiConsoleButton.Importance = widget.LowImportance
+
+	routePlannerButton := widget.NewButtonWithIcon("Route Planner", theme.GridIcon(), func() {
+		sc.ui.presenter.HandleShowRoutePlanner("")
+	})
+	routePlannerButton.Importance = widget.LowImportance
+
+	smartSyncButton := widget.NewButtonWithIcon("Smart Sync", theme.ViewRefreshIcon(), func() {
+		if sc.ensureConnections() {
+			sc.presenter.HandleSmartSync()
+		}
+	})
+	smartSyncButton.Importance = widget.HighImportance
+
 	title := canvas.NewText("Sync Center", theme.ForegroundColor())
 	title.TextStyle = fyne.TextStyle{Bold: true}
 	title.TextSize = theme.TextSize() + 4
 
-	header := container.NewBorder(nil, nil, nil, syncHistoryButton, container.NewHBox(title))
+	header := container.NewBorder(nil, nil, nil, container.NewHBox(smartSyncButton, routePlannerButton, apiConsoleButton, syncRunsButton, syncHistoryButton), container.NewHBox(title))
 
 	content := container.NewVScroll(container.NewVBox(
 		sc.controlsCard,
@@ -270,6 +296,10 @@ func (sc *SyncCenter) updateActionLabel() {
 		text = "Sync Everything"
 	case syncKindUser:
 		text = "Pull User Profile"
+	case syncKindLocations:
+		text = "Refresh Locations"
+	case syncKindDatasets:
+		text = "Refresh Datasets"
 	case syncKindAccounts:
 		if sc.currentScope == scopeSingle {
 			text = "Pull Account"
@@ -532,13 +562,14 @@ func (sc *SyncCenter) loadAccountSuggestions(query string) {
 
 	items := make([]string, 0, len(rows))
 	lookup := make(map[string]omniSuggestion)
+	names := sc.accountDisplayNames(rows)
 
 	for _, row := range rows {
 		if !row.AccountId.Valid {
 			continue
 		}
 		id := int(row.AccountId.Int64)
-		name := cleanString(row.FullName.ValueOrZero())
+		name := fallback(names[id], cleanString(row.FullName.ValueOrZero()))
 		label := fmt.Sprintf("%s (#%d)", fallback(name, "Account"), id)
 
 		summary := fmt.Sprintf("Account #%d\nName: %s", id, fallback(name, "-"))
@@ -554,6 +585,22 @@ func (sc *SyncCenter) loadAccountSuggestions(query string) {
 	}
 }
 
+// accountDisplayNames returns the configured display names of the accounts
+// in rows; accounts it cannot name are left out.
+func (sc *SyncCenter) accountDisplayNames(rows []models.Account) map[int]string {
+	ids := make([]int, 0, len(rows))
+	for _, row := range rows {
+		if row.AccountId.Valid {
+			ids = append(ids, int(row.AccountId.Int64))
+		}
+	}
+	names, err := sc.ui.app.AccountDisplayNames(ids)
+	if err != nil {
+		return nil
+	}
+	return names
+}
+
 func (sc *SyncCenter) loadCheckinSuggestions(query string) {
 	rows, err := database.SearchAccounts(sc.ui.app.DB, query)
 	if err != nil {
@@ -567,13 +614,14 @@ func (sc *SyncCenter) loadCheckinSuggestions(query string) {
 
 	items := make([]string, 0, len(rows))
 	lookup := make(map[string]omniSuggestion)
+	names := sc.accountDisplayNames(rows)
 
 	for _, row := range rows {
 		if !row.AccountId.Valid {
 			continue
 		}
 		id := int(row.AccountId.Int64)
-		name := cleanString(row.FullName.ValueOrZero())
+		name := fallback(names[id], cleanString(row.FullName.ValueOrZero()))
 		label := fmt.Sprintf("%s (#%d)", fallback(name, "Account"), id)
 
 		summary := fmt.Sprintf("Account #%d\nName: %s\nPulls all check-ins for this account.", id, fallback(name, "-"))
@@ -647,34 +695,47 @@ func (sc *SyncCenter) loadDetailsForID(id int) {
 }
 
 func (sc *SyncCenter) loadAccountDetails(id int) {
-	account, err := database.GetAccountByID(sc.ui.app.DB, id)
+	account, err := repository.New(sc.ui.app.DB).GetAccountWithLabels(id)
 	if err != nil {
 		sc.setDetail(fmt.Sprintf("Account #%d not found.", id))
 		return
 	}
+	sc.ui.app.DecryptAccountForDisplay(&account.Account)
 
-	name := fallback(account.FullName.ValueOrZero(), "-")
-	owner := valueFromPointer(account.AccountOwner)
+	name := sc.ui.app.AccountDisplayName(account)
+	owner := cleanString(account.AccountOwner.ValueOrZero())
 	email := fallback(account.Email.ValueOrZero(), "-")
-	last := valueFromPointer(account.LastCheckinDate)
+	last := cleanString(account.LastCheckinDate.ValueOrZero())
 
 	summary := fmt.Sprintf("Account #%d\nName: %s\nOwner: %s\nEmail: %s\nLast Check-in: %s", id, name, fallback(owner, "-"), email, fallback(last, "-"))
+	for _, field := range account.LabeledFields() {
+		summary += fmt.Sprintf("\n%s: %s", field.Label, field.Value)
+	}
 	sc.setDetail(summary)
 }
 
 func (sc *SyncCenter) loadCheckinDetails(id int) {
 	accountID := id
-	account, err := database.GetAccountByID(sc.ui.app.DB, accountID)
+	repo := repository.New(sc.ui.app.DB)
+	account, err := repo.GetAccountWithLabels(accountID)
 	if err != nil {
 		sc.setDetail(fmt.Sprintf("Account #%d not found.", accountID))
 		return
 	}
+	sc.ui.app.DecryptAccountForDisplay(&account.Account)
 
-	name := fallback(account.FullName.ValueOrZero(), "-")
-	owner := valueFromPointer(account.AccountOwner)
-	last := valueFromPointer(account.LastCheckinDate)
+	name := sc.ui.app.AccountDisplayName(account)
+	owner := cleanString(account.AccountOwner.ValueOrZero())
+	last := cleanString(account.LastCheckinDate.ValueOrZero())
 
 	summary := fmt.Sprintf("Account #%d\nName: %s\nOwner: %s\nLast Check-in: %s", accountID, name, fallback(owner, "-"), fallback(last, "-"))
+	if checkins, err := repo.ListCheckinsForAccount(accountID); err == nil {
+		summary += fmt.Sprintf("\nStored Check-ins: %d", len(checkins))
+		if len(checkins) > 0 {
+			latest := checkins[0]
+			summary += fmt.Sprintf("\nLatest: %s %s", cleanString(latest.LogDatetime.ValueOrZero()), cleanString(latest.Type.ValueOrZero()))
+		}
+	}
 	sc.setDetail(summary)
 }
 
@@ -702,6 +763,14 @@ func (sc *SyncCenter) handleAction() {
 		if sc.ensureConnections() {
 			sc.presenter.HandlePullProfile()
 		}
+	case syncKindLocations:
+		if sc.ensureConnections() {
+			sc.presenter.HandlePullLocations()
+		}
+	case syncKindDatasets:
+		if sc.ensureConnections() {
+			sc.presenter.HandlePullDatasets()
+		}
 	case syncKindAccounts:
 		sc.runAccountOperation()
 	case syncKindCheckins:
@@ -716,6 +785,10 @@ func (sc *SyncCenter) runFullSync() {
 		return
 	}
 
+	sc.presenter.HandlePullImpactPreview(sc.startFullSync)
+}
+
+func (sc *SyncCenter) startFullSync() {
 	if !atomic.CompareAndSwapInt32(&sc.syncGate, 0, 1) {
 		sc.ui.ShowToast("Full sync is already running.")
 		return
@@ -789,6 +862,10 @@ func (sc *SyncCenter) detailMessageForType(syncType string) string {
 		return "Pull the complete routes list or a specific route."
 	case syncKindUser:
 		return "Refresh your user profile information."
+	case syncKindLocations:
+		return "Update only account addresses and geocodes without a full account pull."
+	case syncKindDatasets:
+		return "Update only the profile data fields and their picklist values."
 	default:
 		return "Choose a sync type to get started."
 	}
@@ -851,10 +928,3 @@ func fallback(value, alt string) string {
 	}
 	return value
 }
-
-func valueFromPointer(ns *null.String) string {
-	if ns == nil {
-		return ""
-	}
-	return cleanString(ns.ValueOrZero())
-}
@@ -0,0 +1,135 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/database"
+	"badgermaps/events"
+)
+
+// syncRunsLimit is how many runs the Sync Runs view lists.
+const syncRunsLimit = 50
+
+// HandleShowSyncRuns shows the latest pull and push runs, with their notes,
+// in the details pane.
+func (p *GuiPresenter) HandleShowSyncRuns() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowSyncRuns called"))
+	if p.app.DB == nil || !p.app.DB.IsConnected() {
+		p.view.ShowErrorDialog(fmt.Errorf("database is not connected"))
+		return
+	}
+	p.view.ShowDetails(p.syncRunsView())
+}
+
+// HandleAnnotateSyncRun saves note on the run with historyID, or clears it
+// when note is empty, and shows the runs again.
+func (p *GuiPresenter) HandleAnnotateSyncRun(historyID int64, note string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleAnnotateSyncRun called for run %d", historyID))
+	if err := p.app.AnnotateSyncRun(historyID, note); err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	if strings.TrimSpace(note) == "" {
+		p.view.ShowToast("Note cleared.")
+	} else {
+		p.view.ShowToast("Note saved.")
+	}
+	p.view.ShowDetails(p.syncRunsView())
+}
+
+// syncRunsView lists the latest runs, newest first, each with its note and
+// a button to edit it.
+func (p *GuiPresenter) syncRunsView() fyne.CanvasObject {
+	rows := container.NewVBox()
+	load := func() {
+		rows.Objects = nil
+		runs, err := p.app.SyncRuns(syncRunsLimit)
+		switch {
+		case err != nil:
+			rows.Add(NewWrappingLabel(fmt.Sprintf("Error reading the sync history: %v", err)))
+		case len(runs) == 0:
+			rows.Add(widget.NewLabel("No runs recorded yet."))
+		}
+		for _, run := range runs {
+			rows.Add(p.syncRunRow(run))
+		}
+		rows.Refresh()
+	}
+	load()
+
+	header := container.NewVBox(
+		container.NewBorder(nil, nil,
+			widget.NewLabelWithStyle("Sync Runs", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), load),
+		),
+		NewWrappingLabel("Leave a note on a run to explain it later, such as why it was run again or what a large import was."),
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(rows))
+}
+
+func (p *GuiPresenter) syncRunRow(run database.SyncHistoryEntry) fyne.CanvasObject {
+	details := fmt.Sprintf("#%d, %s, %s, %d items", run.HistoryID, run.StartedAt.In(p.app.DisplayLocation()).Format("2006-01-02 15:04:05"), humanizeToken(run.Status), run.ItemsProcessed)
+	if run.ErrorCount > 0 {
+		details += fmt.Sprintf(", %d errors", run.ErrorCount)
+	}
+	if run.DurationSeconds > 0 {
+		details += fmt.Sprintf(" in %s", (time.Duration(run.DurationSeconds) * time.Second).String())
+	}
+	summary := run.Summary
+	if summary == "" {
+		summary = fmt.Sprintf("%s %s", humanizeToken(run.Direction), humanizeToken(run.Source))
+	}
+	lines := container.NewVBox(
+		widget.NewLabelWithStyle(summary, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(details),
+	)
+	if run.Status == "failed" || run.Status == "completed_with_errors" {
+		lines.Objects[0].(*widget.Label).Importance = widget.DangerImportance
+	}
+	label := "Add Note"
+	if run.Note != "" {
+		note := widget.NewLabelWithStyle("Note: "+run.Note, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+		note.Wrapping = fyne.TextWrapWord
+		lines.Add(note)
+		label = "Edit Note"
+	}
+	edit := widget.NewButtonWithIcon(label, theme.DocumentCreateIcon(), func() {
+		p.showSyncRunNoteDialog(run)
+	})
+	return container.NewBorder(nil, nil, nil, container.NewCenter(edit), lines)
+}
+
+// showSyncRunNoteDialog asks for the note of run. Saving an empty note
+// clears it.
+func (p *GuiPresenter) showSyncRunNoteDialog(run database.SyncHistoryEntry) {
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(run.Note)
+	entry.SetPlaceHolder("e.g. Re-ran after fixing the API key")
+	entry.Wrapping = fyne.TextWrapWord
+	entry.Validator = func(text string) error {
+		if n := len([]rune(strings.TrimSpace(text))); n > app.MaxSyncRunNoteLength {
+			return fmt.Errorf("%d characters; the limit is %d", n, app.MaxSyncRunNoteLength)
+		}
+		return nil
+	}
+	items := []*widget.FormItem{widget.NewFormItem("Note", entry)}
+	dlg := dialog.NewForm(fmt.Sprintf("Note on Run #%d", run.HistoryID), "Save", "Cancel", items, func(ok bool) {
+		if ok {
+			p.HandleAnnotateSyncRun(run.HistoryID, entry.Text)
+		}
+	}, p.view.GetMainWindow())
+	dlg.Resize(fyne.NewSize(460, 240))
+	dlg.Show()
+}
@@ -1,3 +1,5 @@
+//go:build !nogui
+
 package gui
 
 import (
@@ -31,6 +33,30 @@ type TableConfig struct {
 	TruncateAt        map[int]int // Column index -> max characters before truncation
 	ShowTooltips      bool        // Whether to show full text in tooltips
 	EmptyMessage      string      // Message to display when no data is available
+	// OnCellEdit is called when Enter is pressed on a focused data cell.
+	OnCellEdit func(rowIndex, col int, rowData []string)
+	// HeaderLabels are shown instead of the column names in Headers, such
+	// as a custom field's profile label. Sorting and filtering still use
+	// the column names.
+	HeaderLabels map[string]string
+}
+
+// headerTexts returns the text shown for every header.
+func (c TableConfig) headerTexts() []string {
+	texts := make([]string, len(c.Headers))
+	for i := range c.Headers {
+		texts[i] = c.headerText(i)
+	}
+	return texts
+}
+
+// headerText returns the text shown for the header of column col.
+func (c TableConfig) headerText(col int) string {
+	header := c.Headers[col]
+	if label := c.HeaderLabels[header]; label != "" {
+		return label
+	}
+	return header
 }
 
 // TableFactory creates standardized tables across the application
@@ -170,6 +196,35 @@ func (r *columnResizeHandleRenderer) Objects() []fyne.CanvasObject {
 }
 func (r *columnResizeHandleRenderer) Destroy() {}
 
+// --- Keyboard-editable table ---
+
+// editableTable is a table that reports Enter on the focused cell. The
+// keypress reuses the table's own Space handling to select the focused cell,
+// and OnSelected routes that selection to the edit callback.
+type editableTable struct {
+	widget.Table
+	editing bool
+}
+
+func newEditableTable(length func() (int, int), create func() fyne.CanvasObject, update func(widget.TableCellID, fyne.CanvasObject)) *editableTable {
+	t := &editableTable{}
+	t.Length = length
+	t.CreateCell = create
+	t.UpdateCell = update
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+func (t *editableTable) TypedKey(event *fyne.KeyEvent) {
+	if event.Name == fyne.KeyReturn || event.Name == fyne.KeyEnter {
+		t.editing = true
+		t.Table.TypedKey(&fyne.KeyEvent{Name: fyne.KeySpace})
+		t.editing = false
+		return
+	}
+	t.Table.TypedKey(event)
+}
+
 // CreateTable creates a standardized table with consistent functionality
 func (tf *TableFactory) CreateTable(config TableConfig) fyne.CanvasObject {
 	if len(config.Data) == 0 {
@@ -182,27 +237,34 @@ func (tf *TableFactory) CreateTable(config TableConfig) fyne.CanvasObject {
 	// reset active widths for this table rendering
 	tf.activeColumnWidth = make(map[int]float32)
 
-	table := widget.NewTable(
-		func() (int, int) {
-			return len(config.Data) + 1, len(config.Headers)
-		},
-		func() fyne.CanvasObject {
-			if config.HasCheckboxes {
-				return container.NewHBox(
-					widget.NewCheck("", nil),
-					tf.newTableLabel(),
-				)
-			}
-			return tf.newTableLabel()
-		},
-		func(i widget.TableCellID, o fyne.CanvasObject) {
-			if config.HasCheckboxes {
-				tf.renderCheckboxRow(i, o, config, selectedRows)
-			} else {
-				tf.renderSimpleRow(i, o, config)
-			}
-		},
-	)
+	length := func() (int, int) {
+		return len(config.Data) + 1, len(config.Headers)
+	}
+	create := func() fyne.CanvasObject {
+		if config.HasCheckboxes {
+			return container.NewHBox(
+				widget.NewCheck("", nil),
+				tf.newTableLabel(),
+			)
+		}
+		return tf.newTableLabel()
+	}
+	update := func(i widget.TableCellID, o fyne.CanvasObject) {
+		if config.HasCheckboxes {
+			tf.renderCheckboxRow(i, o, config, selectedRows)
+		} else {
+			tf.renderSimpleRow(i, o, config)
+		}
+	}
+
+	var table *widget.Table
+	var editable *editableTable
+	if config.OnCellEdit != nil {
+		editable = newEditableTable(length, create, update)
+		table = &editable.Table
+	} else {
+		table = widget.NewTable(length, create, update)
+	}
 
 	// Store active table for interactive column resize
 	tf.activeTable = table
@@ -226,16 +288,21 @@ func (tf *TableFactory) CreateTable(config TableConfig) fyne.CanvasObject {
 
 		selectedData := config.Data[id.Row-1]
 
-		if config.OnRowSelected != nil {
+		if editable != nil && editable.editing {
+			config.OnCellEdit(id.Row-1, id.Col, selectedData)
+		} else if config.OnRowSelected != nil {
 			config.OnRowSelected(id.Row-1, selectedData)
 		} else {
-			tf.showDefaultDetails(config.Headers, selectedData)
+			tf.showDefaultDetails(config.headerTexts(), selectedData)
 		}
 
 		table.UnselectAll()
 	}
 
 	// Return plain table; sticky header will be handled by the parent view to avoid overlay crashes
+	if editable != nil {
+		return editable
+	}
 	return table
 }
 
@@ -246,10 +313,10 @@ func (tf *TableFactory) buildStickyHeader(config TableConfig) *fyne.Container {
 	}
 	// Build clickable headers that open column menu
 	cells := make([]fyne.CanvasObject, 0, len(config.Headers))
-	for _, h := range config.Headers {
-		hdr := newHeaderClickable(h, nil)
-		title := h
-		hdr.tapped = func() { tf.showHeaderMenu(title, hdr) }
+	for i, h := range config.Headers {
+		hdr := newHeaderClickable(config.headerText(i), nil)
+		column, title := h, config.headerText(i)
+		hdr.tapped = func() { tf.showHeaderMenu(column, title, hdr) }
 		cells = append(cells, hdr)
 	}
 	row := container.New(&columnHeaderLayout{tf: tf}, cells...)
@@ -325,7 +392,7 @@ func (tf *TableFactory) renderCheckboxRow(i widget.TableCellID, o fyne.CanvasObj
 			container.Objects[0].(*widget.Check).Hide()
 			label, background := tf.extractLabelAndBackground(container.Objects[1])
 			label.Show()
-			label.SetText(config.Headers[i.Col])
+			label.SetText(config.headerText(i.Col))
 			label.TextStyle = fyne.TextStyle{Bold: true}
 			background.FillColor = theme.Color(theme.ColorNameInputBackground)
 			background.Refresh()
@@ -403,10 +470,10 @@ func (tf *TableFactory) renderSimpleRow(i widget.TableCellID, o fyne.CanvasObjec
 
 	if i.Row == 0 {
 		// Header row
-		header := config.Headers[i.Col]
+		header, title := config.Headers[i.Col], config.headerText(i.Col)
 		// Build a full-width clickable header (no icon) with truncation
-		headerButton := newHeaderClickable(header, nil)
-		headerButton.tapped = func() { tf.showHeaderMenu(header, headerButton) }
+		headerButton := newHeaderClickable(title, nil)
+		headerButton.tapped = func() { tf.showHeaderMenu(header, title, headerButton) }
 		background.FillColor = theme.Color(theme.ColorNameInputBackground)
 
 		// Add resize handle at the far right edge
@@ -440,8 +507,9 @@ func (tf *TableFactory) renderSimpleRow(i widget.TableCellID, o fyne.CanvasObjec
 	background.Refresh()
 }
 
-// showHeaderMenu presents sorting and quick filter actions for the given column.
-func (tf *TableFactory) showHeaderMenu(column string, anchor fyne.CanvasObject) {
+// showHeaderMenu presents sorting and quick filter actions for the given
+// column, titled with its header text.
+func (tf *TableFactory) showHeaderMenu(column, title string, anchor fyne.CanvasObject) {
 	if tf.ui == nil || tf.ui.window == nil {
 		return
 	}
@@ -449,7 +517,7 @@ func (tf *TableFactory) showHeaderMenu(column string, anchor fyne.CanvasObject)
 	showFilterForm := func(mode ExplorerFilterMode, label string) {
 		entry := widget.NewEntry()
 		form := dialog.NewForm(
-			"Filter: "+column,
+			"Filter: "+title,
 			"Apply",
 			"Cancel",
 			[]*widget.FormItem{widget.NewFormItem(label, entry)},
@@ -540,7 +608,8 @@ func (tf *TableFactory) createEmptyTable(config TableConfig) fyne.CanvasObject {
 
 // setDefaultColumnWidths sets reasonable default column widths with overflow prevention
 func (tf *TableFactory) setDefaultColumnWidths(table *widget.Table, config TableConfig) {
-	for i, header := range config.Headers {
+	for i := range config.Headers {
+		header := config.headerText(i)
 		var width float32
 
 		switch strings.ToLower(header) {
@@ -0,0 +1,140 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/events"
+)
+
+// HandleShowTerritories evaluates the territory rules and shows the accounts
+// whose owner differs from their territory's in the details pane.
+func (p *GuiPresenter) HandleShowTerritories() {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleShowTerritories called"))
+	report, err := p.app.EvaluateTerritories()
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	p.view.ShowDetails(p.territoriesView(report))
+}
+
+// HandleStageTerritoryOwners stages owner changes for the mismatched
+// accounts of rule, or of every territory when rule is empty, after the
+// user confirms. The changes wait on the Push tab for review.
+func (p *GuiPresenter) HandleStageTerritoryOwners(rule string) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleStageTerritoryOwners called for %q", rule))
+	report, err := p.app.EvaluateTerritories()
+	if err != nil {
+		p.view.ShowErrorDialog(err)
+		return
+	}
+	pending := 0
+	for _, m := range report.Pending() {
+		if rule == "" || m.Rule == rule {
+			pending++
+		}
+	}
+	if pending == 0 {
+		p.view.ShowToast("No owner changes to stage.")
+		return
+	}
+	p.view.ShowConfirmDialog("Stage Owner Changes",
+		fmt.Sprintf("Stage owner changes for %d account(s)? They are sent on the next push.", pending),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			staged, err := p.app.StageTerritoryOwners(rule)
+			if err != nil {
+				p.view.ShowErrorDialog(err)
+				return
+			}
+			p.app.Events.Dispatch(events.Infof("presenter", "Staged %d territory owner changes", len(staged)))
+			p.view.ShowToast(fmt.Sprintf("Staged %d owner change(s); review them on the Push tab.", len(staged)))
+			p.view.RefreshPushTab()
+			p.HandleShowTerritories()
+		})
+}
+
+// territoriesView shows the accounts per territory and the accounts whose
+// owner differs from their territory's.
+func (p *GuiPresenter) territoriesView(report *app.TerritoryReport) fyne.CanvasObject {
+	bold := fyne.TextStyle{Bold: true}
+	summary := NewWrappingLabel(fmt.Sprintf("%d accounts: %d in a territory, %d outside every territory, %d without a location.",
+		report.Accounts, report.Matched, report.Unmatched, report.NoLocation))
+
+	counts := container.NewGridWithColumns(2,
+		widget.NewLabelWithStyle("Territory", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Accounts", fyne.TextAlignTrailing, bold),
+	)
+	for _, rule := range p.app.Config.Territories {
+		counts.Add(widget.NewLabel(fmt.Sprintf("%s (%s)", rule.Label(), rule.Owner)))
+		counts.Add(widget.NewLabelWithStyle(strconv.Itoa(report.ByRule[rule.Label()]), fyne.TextAlignTrailing, fyne.TextStyle{}))
+	}
+
+	rows := container.NewVBox()
+	if len(report.Mismatches) == 0 {
+		rows.Add(widget.NewLabel("Every account has its territory's owner."))
+	}
+	mismatches := append([]app.TerritoryMismatch(nil), report.Mismatches...)
+	sort.SliceStable(mismatches, func(i, j int) bool { return mismatches[i].Rule < mismatches[j].Rule })
+	ids := make([]int, len(mismatches))
+	for i, m := range mismatches {
+		ids[i] = m.AccountId
+	}
+	if names, err := p.app.AccountDisplayNames(ids); err == nil {
+		for i, m := range mismatches {
+			if name := names[m.AccountId]; name != "" {
+				mismatches[i].Name = name
+			}
+		}
+	}
+	for _, m := range mismatches {
+		current := m.Current
+		if current == "" {
+			current = "(none)"
+		}
+		name := widget.NewLabelWithStyle(fmt.Sprintf("%s (#%d)", m.Name, m.AccountId), fyne.TextAlignLeading, bold)
+		change := widget.NewLabel(fmt.Sprintf("%s → %s, by %s", current, m.Expected, m.Rule))
+		status := widget.NewLabel("")
+		if m.Staged {
+			status.SetText("Staged")
+			status.Importance = widget.LowImportance
+		}
+		rows.Add(container.NewBorder(nil, nil, nil, status, container.NewVBox(name, change)))
+	}
+
+	stage := widget.NewButtonWithIcon(fmt.Sprintf("Stage Owner Changes (%d)", len(report.Pending())), theme.UploadIcon(), func() {
+		p.HandleStageTerritoryOwners("")
+	})
+	stage.Importance = widget.HighImportance
+	if len(report.Pending()) == 0 {
+		stage.Disable()
+	}
+	header := container.NewVBox(
+		container.NewBorder(nil, nil,
+			widget.NewLabelWithStyle("Territories", fyne.TextAlignLeading, bold),
+			widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), p.HandleShowTerritories),
+		),
+		summary,
+		counts,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle(fmt.Sprintf("Owner mismatches (%d)", len(report.Mismatches)), fyne.TextAlignLeading, bold),
+	)
+	footer := container.NewVBox(
+		widget.NewSeparator(),
+		NewWrappingLabel("Staged owner changes wait on the Push tab, where they can be reviewed, edited, or discarded before they are pushed."),
+		stage,
+	)
+	return container.NewBorder(header, footer, nil, nil, container.NewVScroll(rows))
+}
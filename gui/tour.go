@@ -0,0 +1,121 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/events"
+)
+
+// The size of a coach mark card; steps are kept to a few sentences so the
+// text wraps into it.
+const (
+	tourCardWidth  = 380
+	tourCardHeight = 190
+)
+
+// showPendingTours shows the tours the user has not dismissed, one after
+// another, recording each as dismissed when it is finished or skipped.
+func (ui *Gui) showPendingTours() {
+	ui.runTours(ui.app.PendingTours(), true)
+}
+
+// replayTour shows a tour again on request without recording anything.
+func (ui *Gui) replayTour(id string) {
+	if tour, ok := ui.app.Tour(id); ok {
+		ui.runTours([]app.Tour{tour}, false)
+	}
+}
+
+func (ui *Gui) runTours(tours []app.Tour, dismiss bool) {
+	if len(tours) == 0 || ui.window == nil || ui.tabs == nil || ui.showWelcome {
+		return
+	}
+	tour := tours[0]
+	ui.app.Events.Dispatch(events.Debugf("gui", "Showing the %s tour", tour.ID))
+	t := &tourView{ui: ui, tour: tour, previous: ui.tabs.Selected()}
+	t.onDone = func() {
+		if dismiss {
+			ui.app.DismissTour(tour.ID)
+		}
+		ui.runTours(tours[1:], dismiss)
+	}
+	t.show(0)
+}
+
+// tourView shows a tour's coach marks one at a time: each step selects its
+// tab and explains it in a card under the tab bar. Skipping or finishing
+// returns to the tab that was open before.
+type tourView struct {
+	ui       *Gui
+	tour     app.Tour
+	previous *container.TabItem
+	popup    *widget.PopUp
+	onDone   func()
+}
+
+func (t *tourView) show(step int) {
+	if t.popup != nil {
+		t.popup.Hide()
+	}
+	current := t.tour.Steps[step]
+	for _, tab := range t.ui.tabs.Items {
+		if tab.Text == current.Tab {
+			t.ui.tabs.Select(tab)
+			break
+		}
+	}
+
+	title := widget.NewLabelWithStyle(current.Title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	progress := widget.NewLabel(fmt.Sprintf("%d of %d", step+1, len(t.tour.Steps)))
+	progress.Importance = widget.LowImportance
+	text := NewWrappingLabel(current.Text)
+
+	skip := widget.NewButton("Skip Tour", t.finish)
+	skip.Importance = widget.LowImportance
+	back := widget.NewButtonWithIcon("Back", theme.NavigateBackIcon(), func() { t.show(step - 1) })
+	if step == 0 {
+		back.Disable()
+	}
+	next := widget.NewButtonWithIcon("Next", theme.NavigateNextIcon(), func() { t.show(step + 1) })
+	if step == len(t.tour.Steps)-1 {
+		next = widget.NewButtonWithIcon("Done", theme.ConfirmIcon(), t.finish)
+	}
+	next.Importance = widget.HighImportance
+
+	card := container.NewBorder(
+		container.NewBorder(nil, nil, nil, progress, title),
+		container.NewVBox(widget.NewSeparator(), container.NewBorder(nil, nil, skip, container.NewHBox(back, next))),
+		nil, nil,
+		text,
+	)
+
+	t.popup = widget.NewModalPopUp(card, t.ui.window.Canvas())
+	t.popup.Resize(fyne.NewSize(tourCardWidth, tourCardHeight))
+	t.popup.ShowAtPosition(t.position())
+}
+
+// position places the card just under the tab bar, where the selected
+// tab's indicator points at it.
+func (t *tourView) position() fyne.Position {
+	tabs := fyne.CurrentApp().Driver().AbsolutePositionForObject(t.ui.tabs)
+	tabBar := theme.IconInlineSize() + theme.InnerPadding()*2
+	return fyne.NewPos(tabs.X+theme.Padding()*4, tabs.Y+tabBar+theme.Padding()*2)
+}
+
+func (t *tourView) finish() {
+	if t.popup != nil {
+		t.popup.Hide()
+	}
+	if t.previous != nil {
+		t.ui.tabs.Select(t.previous)
+	}
+	t.onDone()
+}
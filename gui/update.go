@@ -0,0 +1,143 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"badgermaps/app"
+	"badgermaps/app/update"
+	"badgermaps/events"
+)
+
+// HandleCheckForUpdates looks up the latest release and offers it when it
+// is newer. Startup checks (manual false) stay quiet unless there is an
+// update; checks the user asked for also report failures and "up to date".
+func (p *GuiPresenter) HandleCheckForUpdates(manual bool) {
+	p.app.Events.Dispatch(events.Debugf("presenter", "HandleCheckForUpdates called"))
+	go func() {
+		result, err := update.Checker{}.Check(context.Background(), app.Version)
+		if err != nil {
+			if manual {
+				p.app.Events.Dispatch(events.Errorf("update", "%v", err))
+				fyne.Do(func() { p.view.ShowErrorDialog(err) })
+			} else {
+				p.app.Events.Dispatch(events.Debugf("update", "Update check failed: %v", err))
+			}
+			return
+		}
+		if !result.Available {
+			if manual {
+				fyne.Do(func() { p.view.ShowToast("You are running the latest release.") })
+			}
+			return
+		}
+		p.app.Events.Dispatch(events.Infof("update", "BadgerMaps %s is available (running %s).", result.Latest.Version(), result.Current))
+		fyne.Do(func() { p.showUpdateDialog(result) })
+	}()
+}
+
+// HandleSetUpdateCheck saves whether updates are checked at startup.
+func (p *GuiPresenter) HandleSetUpdateCheck(enabled bool) {
+	p.app.Config.DisableUpdateCheck = !enabled
+	if err := p.app.SaveConfig(); err != nil {
+		p.app.Events.Dispatch(events.Errorf("presenter", "failed to save the update check setting: %v", err))
+		p.view.ShowToast("Error: Failed to save the update setting.")
+	}
+}
+
+// showUpdateDialog shows the release notes of a newer release and offers to
+// install it, or to open its download page where the build cannot replace
+// itself.
+func (p *GuiPresenter) showUpdateDialog(result *update.Result) {
+	latest := result.Latest
+	summary := fmt.Sprintf("BadgerMaps %s is available. You are running %s.", latest.Version(), result.Current)
+	if !latest.PublishedAt.IsZero() {
+		summary += fmt.Sprintf(" Released %s.", latest.PublishedAt.Format("Jan 2, 2006"))
+	}
+	notes := widget.NewRichTextFromMarkdown(latest.Notes)
+	notes.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(notes)
+	scroll.SetMinSize(fyne.NewSize(480, 280))
+	content := container.NewBorder(NewWrappingLabel(summary), nil, nil, nil, scroll)
+
+	window := p.view.GetMainWindow()
+	asset, found := latest.DesktopAsset(runtime.GOOS, runtime.GOARCH)
+	if !found || !update.CanApply(runtime.GOOS) {
+		dialog.ShowCustomConfirm("Update Available", "Open Download Page", "Later", content, func(open bool) {
+			if open {
+				p.openReleasePage(latest)
+			}
+		}, window)
+		return
+	}
+	dialog.ShowCustomConfirm("Update Available", "Update and Restart", "Later", content, func(install bool) {
+		if install {
+			p.applyUpdate(latest, asset)
+		}
+	}, window)
+}
+
+// applyUpdate installs asset over the running binary and restarts into it.
+func (p *GuiPresenter) applyUpdate(release update.Release, asset update.Asset) {
+	exe, err := os.Executable()
+	if err != nil {
+		p.view.ShowErrorDialog(fmt.Errorf("failed to locate the running binary: %w", err))
+		return
+	}
+	p.view.ShowProgressBar("Downloading " + release.Version() + "...")
+	go func() {
+		err := update.Apply(context.Background(), nil, release, asset, exe)
+		fyne.Do(func() {
+			p.view.HideProgressBar()
+			if errors.Is(err, update.ErrManualUpdate) {
+				p.openReleasePage(release)
+				return
+			}
+			if err != nil {
+				p.app.Events.Dispatch(events.Errorf("update", "Update to %s failed: %v", release.Version(), err))
+				p.view.ShowErrorDialog(err)
+				return
+			}
+			p.app.Events.Dispatch(events.Infof("update", "Installed BadgerMaps %s.", release.Version()))
+			if err := exec.Command(exe, os.Args[1:]...).Start(); err != nil {
+				p.view.ShowErrorDialog(fmt.Errorf("updated to %s; restart BadgerMaps to use it (%w)", release.Version(), err))
+				return
+			}
+			fyne.CurrentApp().Quit()
+		})
+	}()
+}
+
+func (p *GuiPresenter) openReleasePage(release update.Release) {
+	link, err := url.Parse(release.PageURL)
+	if err != nil || release.PageURL == "" {
+		p.view.ShowErrorDialog(fmt.Errorf("release %s has no download page", release.Version()))
+		return
+	}
+	if err := fyne.CurrentApp().OpenURL(link); err != nil {
+		p.view.ShowErrorDialog(err)
+	}
+}
+
+// startupUpdateCheck removes the binary a previous update replaced and,
+// unless disable_update_check is set, looks for a newer release.
+func (ui *Gui) startupUpdateCheck() {
+	if exe, err := os.Executable(); err == nil {
+		update.RemovePrevious(exe)
+	}
+	if ui.app.UpdateCheckEnabled() {
+		ui.presenter.HandleCheckForUpdates(false)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !nogui
+
+package gui
+
+import (
+	"sync"
+
+	"badgermaps/events"
+)
+
+// viewSubscriptions holds the event listeners of a view that is rebuilt, so
+// each build replaces the listeners of the last one instead of adding
+// another set.
+type viewSubscriptions struct {
+	mu   sync.Mutex
+	subs []*events.Subscription
+}
+
+// reset unsubscribes the listeners added since the last reset.
+func (v *viewSubscriptions) reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, sub := range v.subs {
+		sub.Unsubscribe()
+	}
+	v.subs = nil
+}
+
+// add keeps subs until the next reset.
+func (v *viewSubscriptions) add(subs ...*events.Subscription) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.subs = append(v.subs, subs...)
+}
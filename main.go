@@ -3,29 +3,49 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"badgermaps/app"
-	"badgermaps/app/action"
+	"badgermaps/cli/action"
+	"badgermaps/cli/apiconsole"
+	"badgermaps/cli/archive"
+	"badgermaps/cli/bench"
 	"badgermaps/cli/config"
+	"badgermaps/cli/db"
+	"badgermaps/cli/dev"
+	"badgermaps/cli/export"
+	"badgermaps/cli/history"
+	"badgermaps/cli/importer"
+	"badgermaps/cli/open"
+	"badgermaps/cli/privacy"
 	"badgermaps/cli/pull"
 	"badgermaps/cli/push"
+	"badgermaps/cli/restore"
+	"badgermaps/cli/routes"
 	"badgermaps/cli/server"
+	"badgermaps/cli/smartsync"
+	"badgermaps/cli/status"
+	"badgermaps/cli/territories"
 	"badgermaps/cli/test"
 	"badgermaps/cli/version"
-	"badgermaps/database"
+	"badgermaps/cli/watch"
+	"badgermaps/errs"
 	"badgermaps/events"
 	"badgermaps/gui"
 	"badgermaps/utils"
 
 	_ "embed"
 
-	"fyne.io/fyne/v2"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// AppIcon is the window icon passed to the GUI. Builds tagged nogui leave
+// the GUI, and with it Fyne, out of the binary.
+//
 //go:embed assets/icon.png
-var iconBytes []byte
-var AppIcon = &fyne.StaticResource{StaticName: "icon.png", StaticContent: iconBytes}
+var AppIcon []byte
 
 var (
 	// Global application instance
@@ -42,29 +62,22 @@ func createRootCmd() *cobra.Command {
 		Long: `BadgerMapsSync is a command line / gui interface for interacting with the BadgerMaps API.
 		It allows you to push and pull data, run in server mode, and perform various utility operations.`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			// Don't load config for version, help, or gui commands
-			if cmd.Name() == "version" || cmd.Name() == "help" || (cmd.Name() == "badgermaps" && guiFlag) {
+			// Don't load config for version, help, or gui commands; open loads it only when it runs a job itself
+			if cmd.Name() == "version" || cmd.Name() == "help" || cmd.Name() == "open" || (cmd.Name() == "badgermaps" && guiFlag) {
 				return
 			}
 			App.EnsureConfig(false)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			if guiFlag && gui.Enabled {
-				App.EnsureConfig(true) // Load config specifically for GUI
+				// gui.Run loads the config; IsGui must be set first so the
+				// API is tested in the background.
 				App.State.IsGui = true
 				gui.Run(App, AppIcon)
 			} else {
 				cmd.Help()
 			}
 		},
-		PostRun: func(cmd *cobra.Command, args []string) {
-			if App.DB != nil {
-				err := database.LogCommand(App.DB, cmd.Name(), args, true, "")
-				if err != nil {
-					fmt.Printf("Error logging command: %v\n", err)
-				}
-			}
-		},
 	}
 
 	// Create and add commands
@@ -74,18 +87,43 @@ func createRootCmd() *cobra.Command {
 	testCmd := test.TestCmd(App)
 	configCmd := config.ConfigCmd(App)
 	versionCmd := version.VersionCmd()
-	actionCmd := action.ActionCmd
-
-	rootCmd.AddCommand(pushCmd, pullCmd, serverCmd, testCmd, configCmd, versionCmd, actionCmd)
+	actionCmd := action.ActionCmd(App)
+	dbCmd := db.DbCmd(App)
+	statusCmd := status.StatusCmd(App)
+	syncCmd := smartsync.SyncCmd(App)
+	archiveCmd := archive.ArchiveCmd(App)
+	restoreCmd := restore.RestoreCmd(App)
+	benchCmd := bench.BenchCmd(App)
+	var openGUI func(app.DeepLink)
+	if gui.Enabled {
+		openGUI = func(link app.DeepLink) {
+			App.State.IsGui = true
+			gui.RunLink(App, AppIcon, link)
+		}
+	}
+	openCmd := open.OpenCmd(App, openGUI)
+	watchCmd := watch.WatchCmd(App)
+	historyCmd := history.HistoryCmd(App)
+	privacyCmd := privacy.PrivacyCmd(App)
+	devCmd := dev.DevCmd(App)
+	apiCmd := apiconsole.APICmd(App)
+	routesCmd := routes.RoutesCmd(App)
+	territoriesCmd := territories.TerritoriesCmd(App)
+	importCmd := importer.ImportCmd(App)
+	exportCmd := export.ExportCmd(App)
+
+	rootCmd.AddCommand(pushCmd, pullCmd, serverCmd, testCmd, configCmd, versionCmd, actionCmd, dbCmd, statusCmd, archiveCmd, restoreCmd, benchCmd, openCmd, watchCmd, historyCmd, privacyCmd, devCmd, apiCmd, routesCmd, territoriesCmd, importCmd, exportCmd, syncCmd)
 
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&App.State.Verbose, "verbose", "v", false, "Enable verbose output with additional details")
-	rootCmd.PersistentFlags().BoolVarP(&App.State.Quiet, "quiet", "q", false, "Suppress all non-essential output")
+	rootCmd.PersistentFlags().BoolVarP(&App.State.Quiet, "quiet", "q", false, "Only print errors, to stderr, so scheduled runs are silent when they succeed")
 	rootCmd.PersistentFlags().BoolVar(&App.State.Debug, "debug", false, "Enable debug mode with maximum verbosity")
 	rootCmd.PersistentFlags().BoolVar(&App.State.NoColor, "no-color", false, "Disable colored output")
 	rootCmd.PersistentFlags().BoolVar(&App.State.NoInput, "no-input", false, "Disable interactive prompts")
 	rootCmd.PersistentFlags().StringVar(App.State.ConfigFile, "config", "", "Config file (default is $HOME/.badgermaps.yaml)")
 	rootCmd.PersistentFlags().StringVar(&App.State.LogFile, "log-file", "", "Path to write log output to a file")
+	rootCmd.PersistentFlags().StringVar(&App.State.Environment, "env", "", "Database environment to use, from the environments list in the config")
+	rootCmd.PersistentFlags().StringVar(&App.State.ConfirmEnvironment, "confirm-env", "", "Name of the production environment, to confirm destructive operations without a prompt")
 	rootCmd.Flags().BoolVar(&guiFlag, "gui", false, "Launch the graphical user interface")
 
 	return rootCmd
@@ -110,8 +148,37 @@ func main() {
 
 	// Otherwise, run the command-line interface
 	rootCmd := createRootCmd()
-	if err := rootCmd.Execute(); err != nil {
+	started := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	recordCommand(cmd, started, err)
+	if err != nil {
 		App.Events.Dispatch(events.Errorf("main", "command execution failed: %v", err))
-		os.Exit(1)
+		if hint := errs.Hint(err); hint != "" {
+			App.Events.Dispatch(events.Infof("main", "Hint: %s", hint))
+		}
+		App.Events.WaitForDrain(time.Second)
+		os.Exit(errs.ExitCode(err))
+	}
+}
+
+// recordCommand writes the command that ran to CommandLog with the flags
+// that were set and how long it took.
+func recordCommand(cmd *cobra.Command, started time.Time, runErr error) {
+	if cmd == nil || cmd == cmd.Root() || cmd.Name() == "help" {
+		return
+	}
+	var flags []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				flags = append(flags, "--"+f.Name+"="+value)
+			}
+			return
+		}
+		flags = append(flags, "--"+f.Name+"="+f.Value.String())
+	})
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if err := App.RecordCommand(command, cmd.Flags().Args(), flags, time.Since(started), runErr); err != nil {
+		fmt.Printf("Error logging command: %v\n", err)
 	}
 }
@@ -0,0 +1,152 @@
+// Package telemetry traces the sync pipelines with OpenTelemetry so an
+// operator can see where a long pull or push spends its time.
+//
+// Spans are only exported when an OTLP endpoint is configured, either in
+// the telemetry section of the config or through the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variables. Otherwise the global no-op tracer
+// is used and Start costs next to nothing.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultServiceName is reported as service.name when none is configured.
+const DefaultServiceName = "badgermaps"
+
+const instrumentationName = "badgermaps"
+
+// Config selects where spans are exported.
+type Config struct {
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://collector:4318.
+	Endpoint string `yaml:"otlp_endpoint,omitempty"`
+	// Headers are sent with every export, e.g. an API key for a hosted
+	// backend.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// ServiceName defaults to DefaultServiceName.
+	ServiceName string `yaml:"service_name,omitempty"`
+	// SampleRatio is the share of traces kept, from 0 to 1. Zero keeps all.
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"`
+}
+
+// Enabled reports whether spans will be exported: an endpoint is set in the
+// config or in the OTEL_EXPORTER_OTLP environment variables.
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Validate checks the endpoint and sample ratio.
+func (c Config) Validate() error {
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("telemetry.otlp_endpoint %q must be an http or https URL", c.Endpoint)
+		}
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("telemetry.sample_ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+	return nil
+}
+
+// Setup installs a tracer provider that exports over OTLP/HTTP and returns
+// a function that flushes and stops it. Export failures are passed to
+// onError. When c is not Enabled nothing is installed and the returned
+// function does nothing.
+func Setup(c Config, version string, onError func(error)) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if !c.Enabled() {
+		return noop, nil
+	}
+	if err := c.Validate(); err != nil {
+		return noop, err
+	}
+
+	var options []otlptracehttp.Option
+	if c.Endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(c.Endpoint))
+	}
+	if len(c.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(c.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	name := strings.TrimSpace(c.ServiceName)
+	if name == "" {
+		name = DefaultServiceName
+	}
+	res := resource.NewSchemaless(semconv.ServiceName(name), semconv.ServiceVersion(version))
+	sampler := sdktrace.AlwaysSample()
+	if c.SampleRatio > 0 && c.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(c.SampleRatio)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+	if onError != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(onError))
+	}
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Start begins a span named name as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndFailed ends span, marking it failed when failed is set. It suits steps
+// that report failure without an error value.
+func EndFailed(span trace.Span, failed bool) {
+	if failed {
+		span.SetStatus(codes.Error, "failed")
+	}
+	span.End()
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns ctx with the trace context a caller sent in headers, so a
+// webhook joins the trace of the system that sent it.
+func Extract(ctx context.Context, headers http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+// Attribute keys shared by the pipelines.
+var (
+	EntityID   = attribute.Key("badgermaps.entity.id")
+	EntityType = attribute.Key("badgermaps.entity.type")
+	ChangeID   = attribute.Key("badgermaps.change.id")
+	ChangeType = attribute.Key("badgermaps.change.type")
+	Count      = attribute.Key("badgermaps.count")
+	Errors     = attribute.Key("badgermaps.errors")
+	ActionType = attribute.Key("badgermaps.action.type")
+)
@@ -0,0 +1,41 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		config Config
+		ok     bool
+	}{
+		{Config{}, true},
+		{Config{Endpoint: "http://collector:4318", SampleRatio: 0.25}, true},
+		{Config{Endpoint: "collector:4318"}, false},
+		{Config{Endpoint: "grpc://collector:4317"}, false},
+		{Config{SampleRatio: 1.5}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok %v", tt.config, err, tt.ok)
+		}
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	shutdown, err := Setup(Config{}, "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, span := Start(context.Background(), "noop")
+	if span.IsRecording() {
+		t.Error("spans are recorded without an endpoint")
+	}
+	span.End()
+}
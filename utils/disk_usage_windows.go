@@ -1,10 +1,12 @@
 //go:build windows
 
-package server
+package utils
 
 import "golang.org/x/sys/windows"
 
-func diskUsage(path string) (available uint64, total uint64, err error) {
+// DiskUsage returns the bytes available to the user and the total size of the
+// filesystem holding path.
+func DiskUsage(path string) (available uint64, total uint64, err error) {
 	ptr, err := windows.UTF16PtrFromString(path)
 	if err != nil {
 		return 0, 0, err
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// KeychainService is the service name BadgerMaps secrets are stored under.
+const KeychainService = "badgermaps"
+
+// ErrKeychainUnavailable is returned when the platform has no supported
+// keychain tool installed.
+var ErrKeychainUnavailable = errors.New("no supported keychain is available")
+
+// KeychainGet reads the secret stored for account. It returns an empty string
+// without error when no secret is stored.
+//
+// macOS uses the login keychain via `security`, Linux uses the Secret Service
+// via `secret-tool`, and Windows uses the Credential Locker via PowerShell.
+func KeychainGet(account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", KeychainService, "-a", account, "-w")
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVaultScript+
+			"try { $c = $v.Retrieve($env:BM_SERVICE, $env:BM_ACCOUNT); $c.RetrievePassword(); [Console]::Out.Write($c.Password) } catch { exit 44 }")
+		cmd.Env = append(cmd.Environ(), "BM_SERVICE="+KeychainService, "BM_ACCOUNT="+account)
+	default:
+		cmd = exec.Command("secret-tool", "lookup", "service", KeychainService, "account", account)
+	}
+	if _, err := exec.LookPath(cmd.Path); err != nil {
+		return "", ErrKeychainUnavailable
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Every tool signals "not found" with a non-zero exit.
+			return "", nil
+		}
+		return "", fmt.Errorf("keychain lookup failed: %w", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// KeychainSet stores secret for account, replacing any existing value.
+func KeychainSet(account, secret string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// Interactive mode reads the command from stdin, so the secret never
+		// shows up in the process list. -X takes the value hex-encoded.
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %q -a %q -X %s\n",
+			KeychainService, account, hex.EncodeToString([]byte(secret))))
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVaultScript+
+			"try { $v.Remove($v.Retrieve($env:BM_SERVICE, $env:BM_ACCOUNT)) } catch {}; "+
+			"$v.Add((New-Object Windows.Security.Credentials.PasswordCredential($env:BM_SERVICE, $env:BM_ACCOUNT, [Console]::In.ReadToEnd())))")
+		cmd.Env = append(cmd.Environ(), "BM_SERVICE="+KeychainService, "BM_ACCOUNT="+account)
+		cmd.Stdin = strings.NewReader(secret)
+	default:
+		cmd = exec.Command("secret-tool", "store", "--label=BadgerMaps "+account, "service", KeychainService, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+	}
+	if _, err := exec.LookPath(cmd.Path); err != nil {
+		return ErrKeychainUnavailable
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+const windowsVaultScript = "[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; " +
+	"$v = New-Object Windows.Security.Credentials.PasswordVault; "
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"badgermaps/app/state"
+	"io"
+	"os"
+)
+
+// ProgressWriter returns where progress bars are drawn: stderr, or nowhere
+// when --quiet is set so scheduled runs log only errors.
+func ProgressWriter(s *state.State) io.Writer {
+	if s != nil && s.Quiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
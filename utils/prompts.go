@@ -3,8 +3,11 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+
+	"golang.org/x/term"
 )
 
 // PromptString prompts for a string value with a default option
@@ -79,6 +82,26 @@ func PromptPassword(reader *bufio.Reader, prompt string, defaultValue string) st
 	return input
 }
 
+// PromptSecret reads a secret from the terminal without echoing it. When
+// stdin is not a terminal it reads one line from reader instead.
+func PromptSecret(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Print(Colors.Cyan("%s: ", prompt))
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", err
+		}
+		return string(secret), nil
+	}
+	input, err := reader.ReadString('\n')
+	if err != nil && input == "" {
+		return "", err
+	}
+	return strings.TrimRight(input, "\r\n"), nil
+}
+
 // PromptBool prompts for a boolean value with a default option
 func PromptBool(reader *bufio.Reader, prompt string, defaultValue bool) bool {
 	var defaultStr string
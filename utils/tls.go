@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadTLSConfig builds a client TLS configuration from optional PEM files.
+// caFile adds a private certificate authority on top of the system pool;
+// certFile and keyFile enable mutual TLS and must be supplied together.
+// It returns nil when no files are configured so callers keep Go's defaults.
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	caFile = strings.TrimSpace(caFile)
+	certFile = strings.TrimSpace(certFile)
+	keyFile = strings.TrimSpace(keyFile)
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate %s: %w", caFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid PEM certificates found in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("client certificate and key must both be set for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// TLSErrorHint inspects a connection error and returns remediation guidance
+// when the failure was caused by certificate validation. It returns an empty
+// string for errors that are not TLS related.
+func TLSErrorHint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthority) {
+		return "The server certificate is signed by an unknown authority. Set a CA certificate file that contains your organization's root CA."
+	}
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return "The server certificate does not match the host name. Connect using a host name listed in the certificate or reissue the certificate."
+	}
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		if invalidErr.Reason == x509.Expired {
+			return "The server certificate has expired or is not yet valid. Renew it or check the system clock."
+		}
+		return "The server certificate is invalid. Verify the certificate chain installed on the server."
+	}
+
+	// Database drivers frequently flatten TLS failures into plain strings.
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "unknown authority"):
+		return "The server certificate is signed by an unknown authority. Set a CA certificate file that contains your organization's root CA."
+	case strings.Contains(msg, "certificate is valid for"), strings.Contains(msg, "doesn't contain any ip sans"):
+		return "The server certificate does not match the host name. Connect using a host name listed in the certificate or reissue the certificate."
+	case strings.Contains(msg, "certificate has expired"), strings.Contains(msg, "not yet valid"):
+		return "The server certificate has expired or is not yet valid. Renew it or check the system clock."
+	case strings.Contains(msg, "certificate required"), strings.Contains(msg, "bad certificate"):
+		return "The server rejected the client certificate. Configure a client certificate and key that the server trusts."
+	case strings.Contains(msg, "client certificate"), strings.Contains(msg, "ca certificate"), strings.Contains(msg, "pem"):
+		return "A configured certificate file could not be used. Check that the paths are correct and the files are PEM encoded."
+	case strings.Contains(msg, "tls:"), strings.Contains(msg, "x509:"):
+		return "The TLS handshake failed. Check the server's TLS settings and the configured certificate files."
+	}
+	return ""
+}
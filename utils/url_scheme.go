@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrURLSchemeUnsupported is returned on platforms where a URL scheme is
+// declared by the app bundle rather than registered at run time.
+var ErrURLSchemeUnsupported = errors.New("URL schemes cannot be registered at run time on this platform")
+
+// RegisterURLScheme makes the desktop open links of scheme by running exe
+// with args followed by the link.
+//
+// Linux writes a desktop entry to ~/.local/share/applications and makes it
+// the default handler with `xdg-mime`. Windows writes the handler under
+// HKEY_CURRENT_USER\Software\Classes with `reg`. Neither needs admin rights.
+// macOS reads schemes from CFBundleURLTypes in the app bundle's Info.plist,
+// so ErrURLSchemeUnsupported is returned there.
+func RegisterURLScheme(scheme, exe string, args ...string) error {
+	switch runtime.GOOS {
+	case "windows":
+		return registerWindowsURLScheme(scheme, exe, args)
+	case "darwin":
+		return ErrURLSchemeUnsupported
+	default:
+		return registerXDGURLScheme(scheme, exe, args)
+	}
+}
+
+func registerXDGURLScheme(scheme, exe string, args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(home, ".local", "share", "applications")
+	if err := EnsureDirExists(dir); err != nil {
+		return err
+	}
+	name := scheme + "-url-handler.desktop"
+	command := []string{desktopEntryQuote(exe)}
+	for _, arg := range args {
+		command = append(command, desktopEntryQuote(arg))
+	}
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=BadgerMaps Sync
+Exec=%s %%u
+NoDisplay=true
+Terminal=false
+MimeType=x-scheme-handler/%s;
+`, strings.Join(command, " "), scheme)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(entry), 0644); err != nil {
+		return fmt.Errorf("failed to write desktop entry: %w", err)
+	}
+
+	if _, err := exec.LookPath("xdg-mime"); err != nil {
+		return fmt.Errorf("wrote %s, but xdg-mime is not installed to make it the %s:// handler", name, scheme)
+	}
+	if out, err := exec.Command("xdg-mime", "default", name, "x-scheme-handler/"+scheme).CombinedOutput(); err != nil {
+		return fmt.Errorf("xdg-mime failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// desktopEntryQuote quotes an Exec argument as the desktop entry spec asks.
+func desktopEntryQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"'\\$`") {
+		return arg
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	return `"` + r.Replace(arg) + `"`
+}
+
+func registerWindowsURLScheme(scheme, exe string, args []string) error {
+	key := `HKCU\Software\Classes\` + scheme
+	command := fmt.Sprintf("%q", exe)
+	for _, arg := range args {
+		command += fmt.Sprintf(" %q", arg)
+	}
+	command += ` "%1"`
+	for _, regArgs := range [][]string{
+		{"add", key, "/ve", "/d", "URL:BadgerMaps Sync", "/f"},
+		{"add", key, "/v", "URL Protocol", "/d", "", "/f"},
+		{"add", key + `\shell\open\command`, "/ve", "/d", command, "/f"},
+	} {
+		if out, err := exec.Command("reg", regArgs...).CombinedOutput(); err != nil {
+			return fmt.Errorf("reg %s failed: %w: %s", strings.Join(regArgs[:2], " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}